@@ -0,0 +1,87 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// ErrChainMismatch is returned when importing blocks of another chain, or
+// blocks which don't follow the stored ones.
+var ErrChainMismatch = errors.New("chain mismatch")
+
+// ImportChain imports the blocks of the chain file read from r on top of
+// state. Like fast synced blocks, their commits are verified against the
+// validators of the state before they are saved and applied. The blocks
+// already imported are checked against the stored ones and skipped, so an
+// interrupted import resumes by importing the same file again. Returns the
+// state after the last applied block and the number of imported blocks.
+func ImportChain(r io.Reader, state cstate.LatestBlockState, applier blockApplier,
+	store blockStore) (cstate.LatestBlockState, uint64, error) {
+	cr, err := kvstore.NewChainFileReader(r)
+	if err != nil {
+		return state, 0, err
+	}
+	if genesis := store.LoadBlock(0); genesis != nil && !genesis.Hash().Equal(cr.Genesis()) {
+		return state, 0, fmt.Errorf("%w: genesis %v, the file is of %v", ErrChainMismatch, genesis.Hash().Hex(),
+			cr.Genesis().Hex())
+	}
+	var (
+		imported uint64
+		start    = time.Now()
+		logged   = time.Now()
+	)
+	for {
+		block, commit, err := cr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return state, imported, err
+		}
+		height := block.Height()
+		if stored := store.LoadBlock(height); stored != nil && !stored.Hash().Equal(block.Hash()) {
+			return state, imported, fmt.Errorf("%w: block %d is %v, %v is stored", ErrChainMismatch, height,
+				block.Hash().Hex(), stored.Hash().Hex())
+		}
+		if height <= state.LastBlockHeight {
+			continue
+		}
+		if height != state.LastBlockHeight+1 {
+			return state, imported, fmt.Errorf("%w: block %d doesn't follow height %d", ErrChainMismatch, height,
+				state.LastBlockHeight)
+		}
+
+		parts := block.MakePartSet(types.BlockPartSizeBytes)
+		blockID := types.BlockID{Hash: block.Hash(), PartsHeader: parts.Header()}
+		if err := state.Validators.VerifyCommit(state.ChainID, blockID, height, commit); err != nil {
+			return state, imported, fmt.Errorf("invalid commit of block %d: %w", height, err)
+		}
+		// an interrupted import may have saved the block without applying it
+		if store.Height() < height {
+			store.SaveBlock(block, parts, commit)
+		}
+		newState, _, err := applier.ApplyBlock(state, blockID, block)
+		if err != nil {
+			return state, imported, fmt.Errorf("can't apply block %d: %w", height, err)
+		}
+		state = newState
+		imported++
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Importing chain", "height", height, "imported", imported,
+				"elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	log.Info("Imported chain", "height", state.LastBlockHeight, "imported", imported,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return state, imported, nil
+}
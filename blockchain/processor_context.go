@@ -1,6 +1,7 @@
 package blockchain
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/kardiachain/go-kardia/kai/state/cstate"
@@ -15,17 +16,24 @@ type processorContext interface {
 	setState(cstate.LatestBlockState)
 }
 
+// validatorStore loads the validator sets saved along with the state.
+type validatorStore interface {
+	LoadValidators(height uint64) (*types.ValidatorSet, error)
+}
+
 type pContext struct {
-	store   blockStore
-	applier blockApplier
-	state   cstate.LatestBlockState
+	store      blockStore
+	applier    blockApplier
+	validators validatorStore // nil to only verify against the state
+	state      cstate.LatestBlockState
 }
 
-func newProcessorContext(st blockStore, ex blockApplier, s cstate.LatestBlockState) *pContext {
+func newProcessorContext(st blockStore, ex blockApplier, vs validatorStore, s cstate.LatestBlockState) *pContext {
 	return &pContext{
-		store:   st,
-		applier: ex,
-		state:   s,
+		store:      st,
+		applier:    ex,
+		validators: vs,
+		state:      s,
 	}
 }
 
@@ -44,7 +52,31 @@ func (pc *pContext) setState(state cstate.LatestBlockState) {
 }
 
 func (pc pContext) verifyCommit(chainID string, blockID types.BlockID, height uint64, commit *types.Commit) error {
-	return pc.state.Validators.VerifyCommit(chainID, blockID, height, commit)
+	vals, err := pc.loadValidators(height)
+	if err != nil {
+		return err
+	}
+	return vals.VerifyCommit(chainID, blockID, height, commit)
+}
+
+// loadValidators returns the validators of the block at height. The state
+// validators are used for the next block when the store has not saved them,
+// such as right after a state sync.
+func (pc pContext) loadValidators(height uint64) (*types.ValidatorSet, error) {
+	if pc.validators != nil {
+		vals, err := pc.validators.LoadValidators(height)
+		if err == nil {
+			return vals, nil
+		}
+		var errNoVals cstate.ErrNoValSetForHeight
+		if !errors.As(err, &errNoVals) {
+			return nil, err
+		}
+	}
+	if height != pc.state.LastBlockHeight+1 {
+		return nil, cstate.ErrNoValSetForHeight{Height: height}
+	}
+	return pc.state.Validators, nil
 }
 
 func (pc *pContext) saveBlock(block *types.Block, blockParts *types.PartSet, seenCommit *types.Commit) {
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/p2p"
 	"github.com/kardiachain/go-kardia/types"
 )
@@ -304,3 +305,44 @@ func TestScFinishedEv(t *testing.T) {
 
 	executeProcessorTests(t, tests)
 }
+
+type mockValidatorStore map[uint64]*types.ValidatorSet
+
+func (s mockValidatorStore) LoadValidators(height uint64) (*types.ValidatorSet, error) {
+	if vals, ok := s[height]; ok {
+		return vals, nil
+	}
+	return nil, cstate.ErrNoValSetForHeight{Height: height}
+}
+
+func TestProcessorContextLoadValidators(t *testing.T) {
+	var (
+		stateVals  = types.NewValidatorSet([]*types.Validator{types.NewValidator(common.BytesToAddress([]byte{1}), 10)})
+		storedVals = types.NewValidatorSet([]*types.Validator{types.NewValidator(common.BytesToAddress([]byte{2}), 10)})
+		state      = cstate.LatestBlockState{LastBlockHeight: 10, Validators: stateVals}
+	)
+
+	// Without a store, only the next block can be verified with the state.
+	pc := newProcessorContext(nil, nil, nil, state)
+	vals, err := pc.loadValidators(11)
+	assert.NoError(t, err)
+	assert.Equal(t, stateVals, vals)
+	_, err = pc.loadValidators(12)
+	assert.Error(t, err)
+
+	// Stored validators take precedence, the state is the fallback.
+	pc = newProcessorContext(nil, nil, mockValidatorStore{11: storedVals, 12: storedVals}, state)
+	vals, err = pc.loadValidators(11)
+	assert.NoError(t, err)
+	assert.Equal(t, storedVals, vals)
+	vals, err = pc.loadValidators(12)
+	assert.NoError(t, err)
+	assert.Equal(t, storedVals, vals)
+
+	pc = newProcessorContext(nil, nil, mockValidatorStore{}, state)
+	vals, err = pc.loadValidators(11)
+	assert.NoError(t, err)
+	assert.Equal(t, stateVals, vals)
+	_, err = pc.loadValidators(13)
+	assert.Error(t, err)
+}
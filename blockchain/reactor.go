@@ -52,13 +52,13 @@ type blockApplier interface {
 
 // XXX: unify naming in this package around kaiState
 func newReactor(state cstate.LatestBlockState, store blockStore, reporter behaviour.Reporter,
-	blockApplier blockApplier, fastSync *configs.FastSyncConfig) *BlockchainReactor {
+	blockApplier blockApplier, validators validatorStore, fastSync *configs.FastSyncConfig) *BlockchainReactor {
 	initHeight := state.LastBlockHeight + 1
 	if initHeight == 1 {
 		initHeight = state.InitialHeight
 	}
 	scheduler := newScheduler(initHeight, time.Now(), fastSync)
-	pContext := newProcessorContext(store, blockApplier, state)
+	pContext := newProcessorContext(store, blockApplier, validators, state)
 	// newPcState requires a processorContext
 	processor := newPcState(pContext)
 	// Create a specific logger for blockchain reactor.
@@ -77,14 +77,16 @@ func newReactor(state cstate.LatestBlockState, store blockStore, reporter behavi
 	return bcR
 }
 
-// NewBlockchainReactor creates a new reactor instance.
+// NewBlockchainReactor creates a new reactor instance. Synced blocks are
+// verified against the validator sets of stateStore.
 func NewBlockchainReactor(
 	state cstate.LatestBlockState,
 	blockApplier blockApplier,
 	store blockStore,
+	stateStore cstate.Store,
 	fastSync *configs.FastSyncConfig) *BlockchainReactor {
 	reporter := behaviour.NewMockReporter()
-	return newReactor(state, store, reporter, blockApplier, fastSync)
+	return newReactor(state, store, reporter, blockApplier, stateStore, fastSync)
 }
 
 // SetSwitch implements Reactor interface.
@@ -158,6 +160,13 @@ func (r *BlockchainReactor) startSync(state *cstate.LatestBlockState) error {
 	return nil
 }
 
+// SwitchToFastSync is called by the state sync reactor when switching to fast
+// sync from the restored state.
+func (r *BlockchainReactor) SwitchToFastSync(state cstate.LatestBlockState) error {
+	r.setSyncHeight(state.LastBlockHeight)
+	return r.startSync(&state)
+}
+
 // endSync ends a fast sync
 func (r *BlockchainReactor) endSync() {
 	r.mtx.Lock()
@@ -374,6 +383,9 @@ func (r *BlockchainReactor) demux(events <-chan Event) {
 				if err := r.io.sendBlockRequest(event.peerID, event.height); err != nil {
 					r.logger.Error("Error sending block request", "err", err)
 				}
+				// Keep requesting blocks from the other peers until the
+				// window is full, rather than one request per tick.
+				r.scheduler.send(rTrySchedule{time: time.Now()})
 			case scFinishedEv:
 				r.logger.Info("Scheduler finish", "reason", event.reason)
 				r.processor.send(event)
@@ -403,6 +415,9 @@ func (r *BlockchainReactor) demux(events <-chan Event) {
 					lastHundred = time.Now()
 				}
 				r.scheduler.send(event)
+				// The next block may already be queued, process it
+				// without waiting for the next tick.
+				r.processor.send(rProcessBlock{})
 			case pcBlockVerificationFailure:
 				r.scheduler.send(event)
 			case pcFinished:
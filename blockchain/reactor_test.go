@@ -148,7 +148,10 @@ func newTestReactor(p testReactorParams) *BlockchainReactor {
 	reporter := behaviour.NewMockReporter()
 	logger := log.New()
 
-	var appl blockApplier
+	var (
+		appl       blockApplier
+		stateStore validatorStore
+	)
 
 	if p.mockA {
 		appl = &mockBlockApplier{}
@@ -167,18 +170,23 @@ func newTestReactor(p testReactorParams) *BlockchainReactor {
 			fmt.Println(genesisErr)
 			return nil
 		}
-		stateStore := cstate.NewStore(kaiDb.DB())
+		cstateStore := cstate.NewStore(kaiDb.DB())
+		stateStore = cstateStore
 		bc, err := blockchain.NewBlockChain(logger, kaiDb, chainConfig)
 		if err != nil {
 			fmt.Println(err)
 			return nil
 		}
 		txPool := tx_pool.NewTxPool(tx_pool.DefaultTxPoolConfig, chainConfig, bc)
-		bOper := blockchain.NewBlockOperations(logger, bc, txPool, nil, stakingUtil)
-		appl = cstate.NewBlockExecutor(stateStore, p.logger, cstate.EmptyEvidencePool{}, bOper)
-		stateStore.Save(state)
+		bOper, err := blockchain.NewBlockOperations(logger, bc, txPool, nil, stakingUtil)
+		if err != nil {
+			fmt.Println(err)
+			return nil
+		}
+		appl = cstate.NewBlockExecutor(cstateStore, p.logger, cstate.EmptyEvidencePool{}, bOper)
+		cstateStore.Save(state)
 	}
-	r := newReactor(state, store, reporter, appl, configs.TestFastSyncConfig())
+	r := newReactor(state, store, reporter, appl, stateStore, configs.TestFastSyncConfig())
 	return r
 }
 
@@ -544,7 +552,11 @@ func newReactorStore(
 		return nil, cstate.LatestBlockState{}, nil
 	}
 	txPool := tx_pool.NewTxPool(tx_pool.DefaultTxPoolConfig, chainConfig, bc)
-	bOper := blockchain.NewBlockOperations(logger, bc, txPool, nil, stakingUtil)
+	bOper, err := blockchain.NewBlockOperations(logger, bc, txPool, nil, stakingUtil)
+	if err != nil {
+		fmt.Println(err)
+		return nil, cstate.LatestBlockState{}, nil
+	}
 
 	state, err := stateStore.LoadStateFromDBOrGenesisDoc(genDoc)
 	if err != nil {
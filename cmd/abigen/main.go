@@ -91,7 +91,7 @@ var (
 )
 
 func init() {
-	app = flags.NewApp(gitCommit, gitDate, "kardia checkpoint helper tool")
+	app = flags.NewApp(gitCommit, gitDate, "KardiaChain ABI wrapper code generator")
 	app.Flags = []cli.Flag{
 		abiFlag,
 		binFlag,
@@ -0,0 +1,374 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kardiachain/go-kardia/cmd/flags"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/kai/storage"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
+	"github.com/kardiachain/go-kardia/kai/storage/maintenance"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/node"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	// Git SHA1 commit hash of the release (set via linker flags)
+	gitCommit = ""
+	gitDate   = ""
+
+	app *cli.App
+
+	// Flags needed by dbtool
+	dataDirFlag = cli.StringFlag{
+		Name:  "datadir",
+		Usage: "Instance directory of the node, containing its chain data",
+	}
+	fromFlag = cli.Uint64Flag{
+		Name:  "from",
+		Usage: "First height to check or export",
+	}
+	toFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Last height to check or export (default = head)",
+	}
+	backendFlag = cli.StringFlag{
+		Name:  "backend",
+		Usage: "Database backend of the chain data (" + strings.Join(storage.Backends, ", ") + ")",
+		Value: storage.LevelDBBackend,
+	}
+	storeFlag = cli.StringFlag{
+		Name:  "store",
+		Usage: "Compact only a store (" + strings.Join([]string{maintenance.StoreBlock, maintenance.StoreState, maintenance.StoreEvidence}, ", ") + ")",
+	}
+	targetDirFlag = cli.StringFlag{
+		Name:  "target.datadir",
+		Usage: "Instance directory receiving the migrated chain data",
+	}
+	targetBackendFlag = cli.StringFlag{
+		Name:  "target.backend",
+		Usage: "Database backend of the migrated chain data (" + strings.Join(storage.Backends, ", ") + ")",
+	}
+	fileFlag = cli.StringFlag{
+		Name:  "file",
+		Usage: "Backup file of the chain database, or chain file of its blocks",
+	}
+
+	inspectCommand = cli.Command{
+		Name:   "inspect",
+		Usage:  "Show the number and size of the keys of every category",
+		Action: flags.MigrateFlags(inspect),
+		Flags:  []cli.Flag{dataDirFlag},
+	}
+	orphansCommand = cli.Command{
+		Name:   "orphans",
+		Usage:  "List the block data which does not belong to the canonical chain",
+		Action: flags.MigrateFlags(orphans),
+		Flags:  []cli.Flag{dataDirFlag},
+	}
+	compactCommand = cli.Command{
+		Name:   "compact",
+		Usage:  "Compact the whole chain database, or one of its stores",
+		Action: flags.MigrateFlags(compact),
+		Flags:  []cli.Flag{dataDirFlag, storeFlag},
+	}
+	checkCommand = cli.Command{
+		Name:   "check",
+		Usage:  "Check the consistency of the block store, state store and tx index",
+		Action: flags.MigrateFlags(check),
+		Flags:  []cli.Flag{dataDirFlag, fromFlag, toFlag},
+	}
+	repairStateCommand = cli.Command{
+		Name:   "repair-state",
+		Usage:  "Roll the consensus state back to the last block whose state records are consistent",
+		Action: flags.MigrateFlags(repairState),
+		Flags:  []cli.Flag{dataDirFlag},
+	}
+	migrateCommand = cli.Command{
+		Name:   "migrate",
+		Usage:  "Copy the chain database into a new database of another backend",
+		Action: flags.MigrateFlags(migrate),
+		Flags:  []cli.Flag{dataDirFlag, backendFlag, targetDirFlag, targetBackendFlag},
+	}
+	backupCommand = cli.Command{
+		Name:   "backup",
+		Usage:  "Write a backup of the chain database to a file",
+		Action: flags.MigrateFlags(backup),
+		Flags:  []cli.Flag{dataDirFlag, backendFlag, fileFlag},
+	}
+	restoreCommand = cli.Command{
+		Name:   "restore",
+		Usage:  "Restore a backup into the chain database of a new data directory",
+		Action: flags.MigrateFlags(restore),
+		Flags:  []cli.Flag{dataDirFlag, backendFlag, fileFlag},
+	}
+	exportChainCommand = cli.Command{
+		Name:   "export-chain",
+		Usage:  "Write the blocks of the chain database with their commits to a chain file",
+		Action: flags.MigrateFlags(exportChain),
+		Flags:  []cli.Flag{dataDirFlag, backendFlag, fromFlag, toFlag, fileFlag},
+	}
+)
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "kardia chain database tool")
+	app.Flags = []cli.Flag{dataDirFlag, fromFlag, toFlag, backendFlag, storeFlag, targetDirFlag, targetBackendFlag, fileFlag}
+	app.Commands = []cli.Command{inspectCommand, orphansCommand, compactCommand, checkCommand, repairStateCommand,
+		migrateCommand, backupCommand, restoreCommand, exportChainCommand}
+	cli.CommandHelpTemplate = flags.OriginCommandHelpTemplate
+}
+
+// openDatabase opens the chain database of a stopped node.
+func openDatabase(c *cli.Context) kaidb.KeyValueStore {
+	dir := c.GlobalString(dataDirFlag.Name)
+	if dir == "" {
+		flags.Fatalf("No data directory specified (--datadir)")
+	}
+	path := filepath.Join(dir, node.MainChainDataDir)
+	if _, err := os.Stat(path); err != nil {
+		flags.Fatalf("Failed to find chain data: %v", err)
+	}
+	db, err := storage.NewKeyValueStore(c.GlobalString(backendFlag.Name), path, 256, 256)
+	if err != nil {
+		flags.Fatalf("Failed to open database: %v", err)
+	}
+	return db
+}
+
+func inspect(c *cli.Context) error {
+	db := openDatabase(c)
+	defer db.Close()
+
+	inspection, err := maintenance.Inspect(db)
+	if err != nil {
+		flags.Fatalf("Failed to inspect database: %v", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "CATEGORY\tCOUNT\tSIZE")
+	for _, stat := range inspection.Stats {
+		fmt.Fprintf(w, "%s\t%d\t%v\n", stat.Category, stat.Count, stat.Size)
+	}
+	fmt.Fprintf(w, "Total\t%d\t%v\n", inspection.Count, inspection.Size)
+	return w.Flush()
+}
+
+func orphans(c *cli.Context) error {
+	db := openDatabase(c)
+	defer db.Close()
+
+	report, err := maintenance.FindOrphans(db)
+	if err != nil {
+		flags.Fatalf("Failed to find orphans: %v", err)
+	}
+	for _, orphan := range report.Orphans {
+		fmt.Printf("%s %s: %s\n", orphan.Category, orphan.Key, orphan.Reason)
+	}
+	if report.Truncated {
+		fmt.Println("...")
+	}
+	for category, count := range report.Counts {
+		log.Warn("Found orphans", "category", category, "count", count)
+	}
+	log.Info("Searched orphans", "head", report.Head, "categories", len(report.Counts))
+	return nil
+}
+
+func compact(c *cli.Context) error {
+	db := openDatabase(c)
+	defer db.Close()
+
+	if store := c.String(storeFlag.Name); store != "" {
+		if err := maintenance.CompactStore(db, store); err != nil {
+			flags.Fatalf("Failed to compact store: %v", err)
+		}
+		return nil
+	}
+	if err := maintenance.Compact(db); err != nil {
+		flags.Fatalf("Failed to compact database: %v", err)
+	}
+	return nil
+}
+
+func check(c *cli.Context) error {
+	db := openDatabase(c)
+	defer db.Close()
+
+	report, err := maintenance.Check(db, c.GlobalUint64(fromFlag.Name), c.GlobalUint64(toFlag.Name))
+	if err != nil {
+		flags.Fatalf("Failed to check database: %v", err)
+	}
+	for _, problem := range report.Problems {
+		fmt.Printf("%d %s: %s\n", problem.Height, problem.Kind, problem.Detail)
+	}
+	if report.Truncated {
+		fmt.Println("...")
+	}
+	log.Info("Checked database", "from", report.From, "to", report.To, "problems", len(report.Problems))
+	if len(report.Problems) > 0 {
+		return fmt.Errorf("chain database is inconsistent")
+	}
+	return nil
+}
+
+func repairState(c *cli.Context) error {
+	db := openDatabase(c)
+	defer db.Close()
+
+	state, err := cstate.RepairState(db)
+	if err != nil {
+		flags.Fatalf("Failed to repair state: %v", err)
+	}
+	log.Info("Repaired state", "height", state.LastBlockHeight, "appHash", state.AppHash.Hex())
+	return nil
+}
+
+func migrate(c *cli.Context) error {
+	dir, backend := c.GlobalString(targetDirFlag.Name), c.GlobalString(targetBackendFlag.Name)
+	if dir == "" {
+		flags.Fatalf("No target data directory specified (--%s)", targetDirFlag.Name)
+	}
+	if backend == "" {
+		flags.Fatalf("No target database backend specified (--%s)", targetBackendFlag.Name)
+	}
+	path := filepath.Join(dir, node.MainChainDataDir)
+	if _, err := os.Stat(path); err == nil {
+		flags.Fatalf("Target chain data already exists: %s", path)
+	}
+	src := openDatabase(c)
+	defer src.Close()
+
+	dst, err := storage.NewKeyValueStore(backend, path, 256, 256)
+	if err != nil {
+		flags.Fatalf("Failed to create target database: %v", err)
+	}
+	defer dst.Close()
+
+	count, size, err := maintenance.Migrate(dst, src)
+	if err != nil {
+		flags.Fatalf("Failed to migrate database: %v", err)
+	}
+	log.Info("Migrated chain data", "backend", backend, "path", path, "count", count, "size", size)
+	return nil
+}
+
+// backupFile returns the path of the backup file.
+func backupFile(c *cli.Context) string {
+	file := c.GlobalString(fileFlag.Name)
+	if file == "" {
+		flags.Fatalf("No backup file specified (--%s)", fileFlag.Name)
+	}
+	return file
+}
+
+func backup(c *cli.Context) error {
+	file := backupFile(c)
+	db := openDatabase(c)
+	defer db.Close()
+
+	f, err := os.Create(file)
+	if err != nil {
+		flags.Fatalf("Failed to create backup file: %v", err)
+	}
+	stats, err := kaidb.Backup(db, f)
+	if err != nil {
+		f.Close()
+		os.Remove(file)
+		flags.Fatalf("Failed to back up database: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		flags.Fatalf("Failed to write backup file: %v", err)
+	}
+	log.Info("Backed up chain data", "file", file, "count", stats.Count, "size", stats.Size,
+		"checksum", stats.Checksum.Hex())
+	return nil
+}
+
+func restore(c *cli.Context) error {
+	file := backupFile(c)
+	dir := c.GlobalString(dataDirFlag.Name)
+	if dir == "" {
+		flags.Fatalf("No data directory specified (--datadir)")
+	}
+	path := filepath.Join(dir, node.MainChainDataDir)
+	if _, err := os.Stat(path); err == nil {
+		flags.Fatalf("Chain data already exists: %s", path)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		flags.Fatalf("Failed to open backup file: %v", err)
+	}
+	defer f.Close()
+
+	db, err := storage.NewKeyValueStore(c.GlobalString(backendFlag.Name), path, 256, 256)
+	if err != nil {
+		flags.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	stats, err := kaidb.Restore(db, f)
+	if err != nil {
+		flags.Fatalf("Failed to restore backup, the chain data at %s must be removed: %v", path, err)
+	}
+	log.Info("Restored chain data", "path", path, "count", stats.Count, "size", stats.Size,
+		"checksum", stats.Checksum.Hex())
+	return nil
+}
+
+func exportChain(c *cli.Context) error {
+	file := c.GlobalString(fileFlag.Name)
+	if file == "" {
+		flags.Fatalf("No chain file specified (--%s)", fileFlag.Name)
+	}
+	db := openDatabase(c)
+	defer db.Close()
+
+	f, err := os.Create(file)
+	if err != nil {
+		flags.Fatalf("Failed to create chain file: %v", err)
+	}
+	stats, err := kvstore.ExportChain(db, c.GlobalUint64(fromFlag.Name), c.GlobalUint64(toFlag.Name), f)
+	if err != nil {
+		f.Close()
+		os.Remove(file)
+		flags.Fatalf("Failed to export chain: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		flags.Fatalf("Failed to write chain file: %v", err)
+	}
+	log.Info("Exported chain", "file", file, "from", stats.From, "to", stats.To, "size", stats.Size,
+		"checksum", stats.Checksum.Hex())
+	return nil
+}
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -29,9 +29,11 @@ import (
 )
 
 type flags struct {
-	genesis string
-	kardia  string
-	network string
+	genesis     string
+	kardia      string
+	network     string
+	metrics     bool
+	importChain string
 }
 
 const (
@@ -61,6 +63,8 @@ func initFlag(args *flags) {
 	flag.StringVar(&args.genesis, "genesis", "", "Path to genesis config file. Default: ${wd}/cfg/genesis.yaml")
 	flag.StringVar(&args.kardia, "node", "", "Path to Kardia node config file. Default: ${wd}/cfg/kai_config.yaml")
 	flag.StringVar(&args.network, "network", "mainnet", "Target network, choose one [mainnet, testnet, devnet]. Default: \"mainnet\"")
+	flag.BoolVar(&args.metrics, "metrics", false, "Enable metrics collection from startup, including the tx pool meters")
+	flag.StringVar(&args.importChain, "import", "", "Path to a chain file whose blocks are imported at startup, importing it again resumes an interrupted import")
 }
 
 func init() {
@@ -0,0 +1,148 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kardiachain/go-kardia/cmd/flags"
+	"github.com/kardiachain/go-kardia/kai/kaidb/leveldb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/light"
+	"github.com/kardiachain/go-kardia/light/proxy"
+	"github.com/kardiachain/go-kardia/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	// Git SHA1 commit hash of the release (set via linker flags)
+	gitCommit = ""
+	gitDate   = ""
+
+	app *cli.App
+
+	// Flags needed by lightproxy
+	primaryFlag = cli.StringFlag{
+		Name:  "primary",
+		Usage: "RPC endpoint of the full node to verify",
+		Value: "http://127.0.0.1:8545",
+	}
+	chainIDFlag = cli.StringFlag{
+		Name:  "chain-id",
+		Usage: "Chain ID signed by the validators",
+	}
+	heightFlag = cli.Uint64Flag{
+		Name:  "trusted-height",
+		Usage: "Height of the trusted header",
+	}
+	hashFlag = cli.StringFlag{
+		Name:  "trusted-hash",
+		Usage: "Hash of the trusted header",
+	}
+	periodFlag = cli.DurationFlag{
+		Name:  "trusting-period",
+		Usage: "Period during which verified headers are trusted",
+		Value: 168 * time.Hour,
+	}
+	listenFlag = cli.StringFlag{
+		Name:  "laddr",
+		Usage: "Address the proxy listens on",
+		Value: "127.0.0.1:8888",
+	}
+	dataDirFlag = cli.StringFlag{
+		Name:  "datadir",
+		Usage: "Directory of the trusted headers database",
+		Value: "lightproxy",
+	}
+)
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "kardia light client RPC proxy")
+	app.Flags = []cli.Flag{
+		primaryFlag,
+		chainIDFlag,
+		heightFlag,
+		hashFlag,
+		periodFlag,
+		listenFlag,
+		dataDirFlag,
+	}
+	app.Action = flags.MigrateFlags(lightProxy)
+	cli.CommandHelpTemplate = flags.OriginCommandHelpTemplate
+}
+
+func lightProxy(c *cli.Context) error {
+	chainID := c.GlobalString(chainIDFlag.Name)
+	if chainID == "" {
+		flags.Fatalf("No chain ID specified (--chain-id)")
+	}
+	opts := light.TrustOptions{
+		Period: c.GlobalDuration(periodFlag.Name),
+		Height: c.GlobalUint64(heightFlag.Name),
+		Hash:   common.HexToHash(c.GlobalString(hashFlag.Name)),
+	}
+
+	db, err := leveldb.New(c.GlobalString(dataDirFlag.Name), 16, 16)
+	if err != nil {
+		flags.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	primary, err := rpc.DialContext(ctx, c.GlobalString(primaryFlag.Name))
+	if err != nil {
+		flags.Fatalf("Failed to dial primary: %v", err)
+	}
+	defer primary.Close()
+
+	logger := log.New()
+	client, err := light.NewClient(ctx, logger, chainID, opts, light.NewRPCProvider(primary), db)
+	if err != nil {
+		flags.Fatalf("Failed to create light client: %v", err)
+	}
+	p, err := proxy.New(logger, client, primary)
+	if err != nil {
+		flags.Fatalf("Failed to create proxy: %v", err)
+	}
+
+	go func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		<-sigs
+		if err := p.Stop(); err != nil {
+			logger.Error("Failed to stop proxy", "err", err)
+		}
+	}()
+	return p.ListenAndServe(c.GlobalString(listenFlag.Name))
+}
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
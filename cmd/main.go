@@ -27,19 +27,23 @@ import (
 	"net/http"
 	"net/http/pprof"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 
 	"github.com/kardiachain/go-kardia/configs"
-	"github.com/kardiachain/go-kardia/kai/accounts/keystore"
+	"github.com/kardiachain/go-kardia/kai/chainfeed"
 	"github.com/kardiachain/go-kardia/kai/storage"
+	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/crypto"
 	"github.com/kardiachain/go-kardia/lib/log"
 	"github.com/kardiachain/go-kardia/lib/metrics"
+	"github.com/kardiachain/go-kardia/lib/metrics/prometheus"
 	"github.com/kardiachain/go-kardia/lib/sysutils"
 	kai "github.com/kardiachain/go-kardia/mainchain"
 	"github.com/kardiachain/go-kardia/mainchain/genesis"
@@ -71,6 +75,7 @@ func (c *Config) getP2PConfig() (*configs.P2PConfig, error) {
 	p2pConfig := configs.DefaultP2PConfig()
 	p2pConfig.PrivateKey = privKey
 	p2pConfig.Seeds = c.MainChain.Seeds
+	p2pConfig.DNSSeeds = c.MainChain.DNSSeeds
 	p2pConfig.ListenAddress = c.P2P.ListenAddress
 	p2pConfig.MaxNumInboundPeers = c.P2P.InboundPeers
 	p2pConfig.MaxNumOutboundPeers = c.P2P.OutboundPeers
@@ -79,8 +84,8 @@ func (c *Config) getP2PConfig() (*configs.P2PConfig, error) {
 	return p2pConfig, nil
 }
 
-// getDbInfo gets database information from config. Currently, it only supports levelDb
-func (c *Config) getDbInfo() storage.DbInfo {
+// getDbInfo gets database information from config. The backend defaults to levelDb.
+func (c *Config) getDbInfo() (storage.DbInfo, error) {
 	database := c.MainChain.Database
 	nodeDir := filepath.Join(c.DataDir, c.Name, database.Dir)
 	if database.Drop == 1 {
@@ -89,7 +94,7 @@ func (c *Config) getDbInfo() storage.DbInfo {
 			panic(err)
 		}
 	}
-	return storage.NewLevelDbInfo(nodeDir, database.Caches, database.Handles)
+	return storage.NewDbInfo(database.Backend, nodeDir, database.Caches, database.Handles)
 }
 
 // getTxPoolConfig gets txPoolConfig from config, based on target network
@@ -99,14 +104,54 @@ func (c *Config) getTxPoolConfig() tx_pool.TxPoolConfig {
 		return tx_pool.DefaultTxPoolConfig
 	}
 	return tx_pool.TxPoolConfig{
-		AccountSlots: txPool.AccountSlots,
-		AccountQueue: txPool.AccountQueue,
-		GlobalSlots:  txPool.GlobalSlots,
-		GlobalQueue:  txPool.GlobalQueue,
-		Broadcast:    txPool.Broadcast,
+		NoLocals:        txPool.NoLocals,
+		Journal:         txPool.Journal,
+		Rejournal:       time.Duration(txPool.Rejournal) * time.Second,
+		AccountSlots:    txPool.AccountSlots,
+		AccountQueue:    txPool.AccountQueue,
+		GlobalSlots:     txPool.GlobalSlots,
+		GlobalQueue:     txPool.GlobalQueue,
+		AccountRate:     txPool.AccountRate,
+		AccountBytes:    txPool.AccountBytes,
+		GlobalBytes:     txPool.GlobalBytes,
+		Lifetime:        time.Duration(txPool.Lifetime) * time.Second,
+		PendingLifetime: time.Duration(txPool.PendingLifetime) * time.Second,
+		PriceLimit:      txPool.PriceLimit,
+		PriceBump:       txPool.PriceBump,
+		Broadcast:       txPool.Broadcast,
+		TxFilter:        txPool.Filter.txFilter(),
 	}
 }
 
+// txFilter builds the tx pool policy hook described by the filter config
+func (f *TxFilter) txFilter() tx_pool.TxFilter {
+	if f == nil {
+		return nil
+	}
+	senders, recipients := toAddresses(f.Senders), toAddresses(f.Recipients)
+	switch f.Mode {
+	case "allowlist":
+		return tx_pool.AllowlistFilter(senders, recipients, f.NoContractCreation)
+	case "denylist":
+		return tx_pool.DenylistFilter(senders, recipients, f.NoContractCreation)
+	default:
+		panic(fmt.Sprintf("unknown tx pool filter mode %q, choose one [allowlist, denylist]", f.Mode))
+	}
+}
+
+// toAddresses converts hex strings to addresses, panicking on malformed ones
+// so a broken access policy never silently lets transactions through
+func toAddresses(hexes []string) []common.Address {
+	addrs := make([]common.Address, 0, len(hexes))
+	for _, hex := range hexes {
+		if !common.IsHexAddress(hex) {
+			panic(fmt.Sprintf("invalid address %q in tx pool filter", hex))
+		}
+		addrs = append(addrs, common.HexToAddress(hex))
+	}
+	return addrs
+}
+
 // getGenesisConfig gets node data from config
 func (c *Config) getGenesisConfig() (*genesis.Genesis, error) {
 	var (
@@ -155,9 +200,9 @@ func (c *Config) getGenesisConfig() (*genesis.Genesis, error) {
 // getMainChainConfig gets mainchain's config from config
 func (c *Config) getMainChainConfig() (*node.MainChainConfig, error) {
 	chain := c.MainChain
-	dbInfo := c.getDbInfo()
-	if dbInfo == nil {
-		return nil, fmt.Errorf("cannot get dbInfo")
+	dbInfo, err := c.getDbInfo()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get dbInfo: %v", err)
 	}
 	genesisData, err := c.getGenesisConfig()
 	if err != nil {
@@ -171,7 +216,15 @@ func (c *Config) getMainChainConfig() (*node.MainChainConfig, error) {
 		ServiceName: chain.ServiceName,
 		Consensus:   genesisData.Consensus,
 		FastSync:    c.getFastSyncConfig(),
+		StateSync:   c.getStateSyncConfig(),
+		ImportChain: args.importChain,
+		Evidence:    c.getEvidenceConfig(),
+		State:       c.getStateConfig(),
+		Compaction:  c.getCompactionConfig(),
+		Pruning:     c.getPruningConfig(),
+		TraceIndex:  c.getTraceIndexConfig(),
 		GasOracle:   c.getGasOracleConfig(),
+		ChainFeed:   c.getChainFeedConfig(),
 	}
 	if args.network == Mainnet {
 		mainChainConfig.ChainId = configs.MainnetChainID
@@ -208,6 +261,10 @@ func (c *Config) getNodeConfig() (*node.Config, error) {
 		FastSync:         c.getFastSyncConfig(),
 		GasOracle:        c.getGasOracleConfig(),
 		KeyStoreDir:      n.KeyStoreDir,
+
+		UseLightweightKDF:     n.UseLightweightKDF,
+		InsecureUnlockAllowed: n.InsecureUnlockAllowed,
+		LogHandler:            c.logHandler,
 	}
 	mainChainConfig, err := c.getMainChainConfig()
 	if err != nil {
@@ -217,6 +274,12 @@ func (c *Config) getNodeConfig() (*node.Config, error) {
 		return nil, fmt.Errorf("mainChainConfig is empty")
 	}
 	nodeConfig.MainChainConfig = *mainChainConfig
+	if n.PrivValidator != nil {
+		nodeConfig.PrivValidatorKeyFile = n.PrivValidator.KeyFile
+		nodeConfig.PrivValidatorStateFile = n.PrivValidator.StateFile
+		nodeConfig.PrivValidatorBLSKeyFile = n.PrivValidator.BLSKeyFile
+		nodeConfig.PrivValidatorPassword = n.PrivValidator.Password
+	}
 	if c.TimeOutForStaticCall > 0 {
 		configs.TimeOutForStaticCall = c.TimeOutForStaticCall
 	} else {
@@ -239,6 +302,126 @@ func (c *Config) getFastSyncConfig() *configs.FastSyncConfig {
 	}
 }
 
+// getStateSyncConfig returns the state sync config of the node, or the
+// default one if it is not configured
+func (c *Config) getStateSyncConfig() *configs.StateSyncConfig {
+	config := configs.DefaultStateSyncConfig()
+	ss := c.StateSync
+	if ss == nil {
+		return config
+	}
+	config.Enable = ss.Enable
+	config.RPCServers = ss.RPCServers
+	config.TrustHeight = ss.TrustHeight
+	config.TrustHash = common.HexToHash(ss.TrustHash)
+	if ss.TrustPeriod > 0 {
+		config.TrustPeriod = time.Duration(ss.TrustPeriod) * time.Hour
+	}
+	if ss.DiscoveryTime > 0 {
+		config.DiscoveryTime = time.Duration(ss.DiscoveryTime) * time.Second
+	}
+	if ss.ChunkFetchers > 0 {
+		config.ChunkFetchers = ss.ChunkFetchers
+	}
+	if ss.ChunkRequestTimeout > 0 {
+		config.ChunkRequestTimeout = time.Duration(ss.ChunkRequestTimeout) * time.Second
+	}
+	config.SnapshotInterval = ss.SnapshotInterval
+	if ss.SnapshotKeepRecent > 0 {
+		config.SnapshotKeepRecent = ss.SnapshotKeepRecent
+	}
+	if ss.SnapshotDir != "" {
+		config.SnapshotDir = ss.SnapshotDir
+	}
+	return config
+}
+
+// getEvidenceConfig returns the evidence config of the node, or the default
+// one if it is not configured
+func (c *Config) getEvidenceConfig() *configs.EvidenceConfig {
+	config := configs.DefaultEvidenceConfig()
+	if c.Evidence != nil {
+		config.CommittedRetainBlocks = c.Evidence.CommittedRetainBlocks
+	}
+	return config
+}
+
+// getStateConfig returns the state store config of the node, or the default
+// one if it is not configured
+func (c *Config) getStateConfig() *configs.StateConfig {
+	config := configs.DefaultStateConfig()
+	if c.State != nil {
+		config.RetainBlocks = c.State.RetainBlocks
+	}
+	return config
+}
+
+// getCompactionConfig returns the compaction config of the node, or the
+// default one if it is not configured
+func (c *Config) getCompactionConfig() *configs.CompactionConfig {
+	config := configs.DefaultCompactionConfig()
+	if c.Compaction != nil {
+		config.BlockStoreInterval = time.Duration(c.Compaction.BlockStoreInterval) * time.Hour
+		config.StateStoreInterval = time.Duration(c.Compaction.StateStoreInterval) * time.Hour
+		config.EvidenceInterval = time.Duration(c.Compaction.EvidenceInterval) * time.Hour
+	}
+	return config
+}
+
+// getPruningConfig returns the pruning config of the node, or the default one
+// if it is not configured
+func (c *Config) getPruningConfig() *configs.PruningConfig {
+	config := configs.DefaultPruningConfig()
+	if c.Pruning != nil {
+		if c.Pruning.Mode != "" {
+			config.Mode = c.Pruning.Mode
+		}
+		if c.Pruning.KeepRecent > 0 {
+			config.KeepRecent = c.Pruning.KeepRecent
+		}
+		if c.Pruning.Interval > 0 {
+			config.Interval = time.Duration(c.Pruning.Interval) * time.Hour
+		}
+	}
+	return config
+}
+
+// getTraceIndexConfig returns the trace index config of the node, or nil if
+// the trace indexer is disabled
+func (c *Config) getTraceIndexConfig() *configs.TraceIndexConfig {
+	if c.TraceIndex == nil {
+		return nil
+	}
+	config := configs.DefaultTraceIndexConfig()
+	if c.TraceIndex.StartHeight > 0 {
+		config.StartHeight = c.TraceIndex.StartHeight
+	}
+	if c.TraceIndex.Reexec > 0 {
+		config.Reexec = c.TraceIndex.Reexec
+	}
+	return config
+}
+
+// getChainFeedConfig returns the chain feed of the main chain, or nil if none
+// is configured
+func (c *Config) getChainFeedConfig() *chainfeed.Config {
+	feed := c.MainChain.ChainFeed
+	if feed == nil {
+		return nil
+	}
+	return &chainfeed.Config{
+		Name:          feed.Name,
+		Sink:          feed.Sink,
+		Driver:        feed.Driver,
+		DSN:           feed.DSN,
+		Schema:        feed.Schema,
+		Brokers:       feed.Brokers,
+		Topic:         feed.Topic,
+		StartHeight:   feed.StartHeight,
+		RetryInterval: time.Duration(feed.RetryInterval) * time.Second,
+	}
+}
+
 func (c *Config) getGasOracleConfig() *oracles.Config {
 	if c.GasOracle == nil {
 		return oracles.DefaultOracleConfig()
@@ -252,10 +435,11 @@ func (c *Config) getGasOracleConfig() *oracles.Config {
 		maxGasPrice = oracles.DefaultOracleConfig().MaxPrice
 	}
 	return &oracles.Config{
-		Blocks:     c.GasOracle.Blocks,
-		Percentile: c.GasOracle.Percentile,
-		Default:    defaultGasPrice,
-		MaxPrice:   maxGasPrice,
+		Blocks:      c.GasOracle.Blocks,
+		Percentile:  c.GasOracle.Percentile,
+		PoolSamples: c.GasOracle.PoolSamples,
+		Default:     defaultGasPrice,
+		MaxPrice:    maxGasPrice,
 	}
 }
 
@@ -267,11 +451,58 @@ func (c *Config) newLog() log.Logger {
 		fmt.Printf("invalid log level argument, default to INFO: %v \n", err)
 		level = log.LvlInfo
 	}
-	log.Root().SetHandler(log.LvlFilterHandler(level,
-		log.StreamHandler(os.Stdout, log.TerminalFormat(true))))
+	var format log.Format
+	switch c.LogFormat {
+	case "json":
+		format = log.JSONFormat()
+	case "logfmt":
+		format = log.LogfmtFormat()
+	case "", "terminal":
+		format = log.TerminalFormat(true)
+	default:
+		fmt.Printf("invalid log format argument, default to terminal: %v \n", c.LogFormat)
+		format = log.TerminalFormat(true)
+	}
+	output := log.StreamHandler(os.Stdout, format)
+	if c.LogFile != nil && c.LogFile.Path != "" {
+		if c.LogFormat == "" || c.LogFormat == "terminal" {
+			format = log.TerminalFormat(false)
+		}
+		fileHandler, err := log.NewRotatingFileHandler(c.LogFile.Path, format, log.RotateConfig{
+			MaxSize:    c.LogFile.MaxSize * 1024 * 1024,
+			MaxAge:     time.Duration(c.LogFile.MaxAge) * time.Hour,
+			MaxBackups: c.LogFile.MaxBackups,
+			Compress:   c.LogFile.Compress,
+		})
+		if err != nil {
+			fmt.Printf("cannot open log file, logging to stdout: %v \n", err)
+		} else {
+			output = fileHandler
+			reopenOnSighup(fileHandler)
+		}
+	}
+	c.logHandler = log.NewModuleHandler(level, output)
+	if err := c.logHandler.SetModuleLevels(c.LogModules); err != nil {
+		fmt.Printf("invalid log modules argument, ignored: %v \n", err)
+	}
+	log.Root().SetHandler(c.logHandler)
 	return log.New()
 }
 
+// reopenOnSighup reopens the log file on SIGHUP, for external tools rotating
+// it.
+func reopenOnSighup(h *log.RotatingFileHandler) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	go func() {
+		for range sigs {
+			if err := h.Reopen(); err != nil {
+				fmt.Printf("cannot reopen log file: %v \n", err)
+			}
+		}
+	}()
+}
+
 // getConsensusConfig gets consensus timeout configs
 func (c *Config) getConsensusConfig() *configs.ConsensusConfig {
 	if args.network == Mainnet {
@@ -313,6 +544,15 @@ func (c *Config) getConsensusParams() *kaiproto.ConsensusParams {
 			MaxAgeDuration:  time.Duration(c.Genesis.ConsensusParams.Evidence.MaxAgeDuration) * time.Hour,
 			MaxBytes:        c.Genesis.ConsensusParams.Evidence.MaxBytes,
 		},
+		Timeout: kaiproto.TimeoutParams{
+			Propose:        time.Duration(c.Genesis.ConsensusParams.Timeout.Propose) * time.Millisecond,
+			ProposeDelta:   time.Duration(c.Genesis.ConsensusParams.Timeout.ProposeDelta) * time.Millisecond,
+			Prevote:        time.Duration(c.Genesis.ConsensusParams.Timeout.Prevote) * time.Millisecond,
+			PrevoteDelta:   time.Duration(c.Genesis.ConsensusParams.Timeout.PrevoteDelta) * time.Millisecond,
+			Precommit:      time.Duration(c.Genesis.ConsensusParams.Timeout.Precommit) * time.Millisecond,
+			PrecommitDelta: time.Duration(c.Genesis.ConsensusParams.Timeout.PrecommitDelta) * time.Millisecond,
+			Commit:         time.Duration(c.Genesis.ConsensusParams.Timeout.Commit) * time.Millisecond,
+		},
 	}
 }
 
@@ -358,17 +598,12 @@ func (c *Config) Start() {
 		return
 	}
 
-	if err = setAccountManagerBackends(n); err != nil {
-		logger.Error("Cannot set accounts manager backend to node", "err", err)
-		return
-	}
-
 	if err := n.Start(); err != nil {
 		logger.Error("error while starting node", "err", err)
 		return
 	}
 
-	if c.Metrics {
+	if c.Metrics || args.metrics {
 		logger.Warn("Collect metrics enabled")
 		metrics.Enabled = true
 	}
@@ -395,6 +630,7 @@ func (c *Config) StartDebug() error {
 		router.Handle("/debug/pprof/threadcreate", pprof.Handler("threadcreate"))
 		router.Handle("/debug/pprof/block", pprof.Handler("block"))
 		router.Handle("/debug/vars", http.DefaultServeMux)
+		router.Handle("/debug/metrics/prometheus", prometheus.Handler())
 
 		if err := http.ListenAndServe(c.Debug.Port, cors.AllowAll().Handler(router)); err != nil {
 			panic(err)
@@ -454,23 +690,6 @@ func waitForever() {
 	select {}
 }
 
-func setAccountManagerBackends(stack *node.Node) error {
-	conf := stack.Config()
-	am := stack.AccountManager()
-	keydir := stack.KeyStoreDir()
-	scryptN := keystore.StandardScryptN
-	scryptP := keystore.StandardScryptP
-	if conf.UseLightweightKDF {
-		scryptN = keystore.LightScryptN
-		scryptP = keystore.LightScryptP
-	}
-
-	// Assemble the supported backends
-	am.AddBackend(keystore.NewKeyStore(keydir, scryptN, scryptP))
-
-	return nil
-}
-
 func main() {
 	flag.Parse()
 	config, err := LoadConfig(args)
@@ -0,0 +1,160 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kardiachain/go-kardia/cmd/flags"
+	"github.com/kardiachain/go-kardia/kai/kaidb/leveldb"
+	"github.com/kardiachain/go-kardia/kai/snapshot"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/node"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	// Git SHA1 commit hash of the release (set via linker flags)
+	gitCommit = ""
+	gitDate   = ""
+
+	app *cli.App
+
+	// Flags needed by snapshot
+	dataDirFlag = cli.StringFlag{
+		Name:  "datadir",
+		Usage: "Instance directory of the node, containing its chain data",
+	}
+	fileFlag = cli.StringFlag{
+		Name:  "file",
+		Usage: "Path of the snapshot archive",
+	}
+	heightFlag = cli.Uint64Flag{
+		Name:  "height",
+		Usage: "Height to export (default = latest)",
+	}
+	checksumFlag = cli.StringFlag{
+		Name:  "checksum",
+		Usage: "Expected checksum of the archive to import",
+	}
+
+	exportCommand = cli.Command{
+		Name:   "export",
+		Usage:  "Export the chain data of a stopped node into an archive",
+		Action: flags.MigrateFlags(exportSnapshot),
+		Flags:  []cli.Flag{dataDirFlag, fileFlag, heightFlag},
+	}
+	importCommand = cli.Command{
+		Name:   "import",
+		Usage:  "Import an archive into the chain data of a fresh node",
+		Action: flags.MigrateFlags(importSnapshot),
+		Flags:  []cli.Flag{dataDirFlag, fileFlag, checksumFlag},
+	}
+)
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "kardia node snapshot tool")
+	app.Flags = []cli.Flag{dataDirFlag, fileFlag, heightFlag, checksumFlag}
+	app.Commands = []cli.Command{exportCommand, importCommand}
+	cli.CommandHelpTemplate = flags.OriginCommandHelpTemplate
+}
+
+// chainData returns the chain data path of the instance directory.
+func chainData(c *cli.Context) string {
+	dir := c.GlobalString(dataDirFlag.Name)
+	if dir == "" {
+		flags.Fatalf("No data directory specified (--datadir)")
+	}
+	if c.GlobalString(fileFlag.Name) == "" {
+		flags.Fatalf("No archive specified (--file)")
+	}
+	return filepath.Join(dir, node.MainChainDataDir)
+}
+
+func exportSnapshot(c *cli.Context) error {
+	path := chainData(c)
+	if _, err := os.Stat(path); err != nil {
+		flags.Fatalf("Failed to find chain data: %v", err)
+	}
+	db, err := leveldb.New(path, 16, 16)
+	if err != nil {
+		flags.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	file := c.GlobalString(fileFlag.Name)
+	f, err := os.Create(file)
+	if err != nil {
+		flags.Fatalf("Failed to create archive: %v", err)
+	}
+	manifest, err := snapshot.Export(db, c.GlobalUint64(heightFlag.Name), f)
+	if err != nil {
+		f.Close()
+		os.Remove(file)
+		flags.Fatalf("Failed to export snapshot: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		flags.Fatalf("Failed to write archive: %v", err)
+	}
+	log.Info("Exported snapshot", "height", manifest.Height, "block", manifest.BlockHash.Hex(),
+		"appHash", manifest.AppHash.Hex(), "checksum", manifest.Checksum.Hex())
+	return nil
+}
+
+func importSnapshot(c *cli.Context) error {
+	path := chainData(c)
+	if _, err := os.Stat(path); err == nil {
+		flags.Fatalf("Chain data already exists at %s", path)
+	}
+	f, err := os.Open(c.GlobalString(fileFlag.Name))
+	if err != nil {
+		flags.Fatalf("Failed to open archive: %v", err)
+	}
+	defer f.Close()
+
+	db, err := leveldb.New(path, 256, 256)
+	if err != nil {
+		flags.Fatalf("Failed to open database: %v", err)
+	}
+	var expected common.Hash
+	if checksum := c.GlobalString(checksumFlag.Name); checksum != "" {
+		expected = common.HexToHash(checksum)
+	}
+	manifest, err := snapshot.Import(db, f, expected)
+	db.Close()
+	if err != nil {
+		os.RemoveAll(path)
+		flags.Fatalf("Failed to import snapshot: %v", err)
+	}
+	log.Info("Imported snapshot", "height", manifest.Height, "block", manifest.BlockHash.Hex(),
+		"appHash", manifest.AppHash.Hex(), "checksum", manifest.Checksum.Hex())
+	return nil
+}
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
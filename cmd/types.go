@@ -20,6 +20,7 @@ package main
 
 import (
 	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/lib/log"
 	"github.com/kardiachain/go-kardia/mainchain/genesis"
 )
 
@@ -28,6 +29,8 @@ type (
 		Node      `yaml:"Node"`
 		MainChain *Chain `yaml:"MainChain"`
 		Debug     *Debug `yaml:"Debug"`
+
+		logHandler *log.ModuleHandler
 	}
 	Node struct {
 		P2P struct {
@@ -36,29 +39,47 @@ type (
 			InboundPeers  int    `yaml:"InboundPeers"`
 			OutboundPeers int    `yaml:"OutboundPeers"`
 		} `yaml:"P2P"`
-		LogLevel             string     `yaml:"LogLevel"`
-		Name                 string     `yaml:"Name"`
-		DataDir              string     `yaml:"DataDir"`
-		HTTPHost             string     `yaml:"HTTPHost"`
-		HTTPPort             int        `yaml:"HTTPPort"`
-		HTTPModules          []string   `yaml:"HTTPModules"`
-		HTTPVirtualHosts     []string   `yaml:"HTTPVirtualHosts"`
-		HTTPCors             []string   `yaml:"HTTPCors"`
-		WSHost               string     `yaml:"WSHost"`
-		WSPort               int        `yaml:"WSPort"`
-		WSOrigins            []string   `yaml:"WSOrigins"`
-		Metrics              bool       `yaml:"Metrics"`
-		FastSync             *FastSync  `yaml:"FastSync"`
-		GasOracle            *GasOracle `yaml:"GasOracle"`
-		Genesis              *Genesis   `yaml:"Genesis,omitempty"`
-		TimeOutForStaticCall int        `yaml:"TimeOutForStaticCall,omitempty"`
+		LogLevel             string      `yaml:"LogLevel"`
+		LogModules           string      `yaml:"LogModules"`
+		LogFormat            string      `yaml:"LogFormat"`
+		LogFile              *LogFile    `yaml:"LogFile,omitempty"`
+		Name                 string      `yaml:"Name"`
+		DataDir              string      `yaml:"DataDir"`
+		HTTPHost             string      `yaml:"HTTPHost"`
+		HTTPPort             int         `yaml:"HTTPPort"`
+		HTTPModules          []string    `yaml:"HTTPModules"`
+		HTTPVirtualHosts     []string    `yaml:"HTTPVirtualHosts"`
+		HTTPCors             []string    `yaml:"HTTPCors"`
+		WSHost               string      `yaml:"WSHost"`
+		WSPort               int         `yaml:"WSPort"`
+		WSOrigins            []string    `yaml:"WSOrigins"`
+		Metrics              bool        `yaml:"Metrics"`
+		FastSync             *FastSync   `yaml:"FastSync"`
+		StateSync            *StateSync  `yaml:"StateSync,omitempty"`
+		Evidence             *Evidence   `yaml:"Evidence,omitempty"`
+		State                *State      `yaml:"State,omitempty"`
+		Compaction           *Compaction `yaml:"Compaction,omitempty"`
+		Pruning              *Pruning    `yaml:"Pruning,omitempty"`
+		TraceIndex           *TraceIndex `yaml:"TraceIndex,omitempty"`
+		GasOracle            *GasOracle  `yaml:"GasOracle"`
+		Genesis              *Genesis    `yaml:"Genesis,omitempty"`
+		TimeOutForStaticCall int         `yaml:"TimeOutForStaticCall,omitempty"`
 		KeyStoreConfig       `yaml:"KeyStoreConfig,omitempty"`
+		PrivValidator        *PrivValidator `yaml:"PrivValidator,omitempty"`
 	}
 	GasOracle struct {
-		Blocks     int    `yaml:"Blocks"`
-		Percentile int    `yaml:"Percentile"`
-		Default    string `yaml:"Default"`
-		MaxPrice   string `yaml:"MaxPrice"`
+		Blocks      int    `yaml:"Blocks"`
+		Percentile  int    `yaml:"Percentile"`
+		PoolSamples int    `yaml:"PoolSamples,omitempty"`
+		Default     string `yaml:"Default"`
+		MaxPrice    string `yaml:"MaxPrice"`
+	}
+	LogFile struct {
+		Path       string `yaml:"Path"`
+		MaxSize    int64  `yaml:"MaxSize"`    // in megabytes
+		MaxAge     int    `yaml:"MaxAge"`     // in hours
+		MaxBackups int    `yaml:"MaxBackups"` // number of rotated files kept
+		Compress   bool   `yaml:"Compress"`
 	}
 	FastSync struct {
 		ServiceName   string `yaml:"ServiceName"`
@@ -68,6 +89,39 @@ type (
 		PeerTimeout   int    `yaml:"PeerTimeout"`
 		MinRecvRate   int64  `yaml:"MinRecvRate"`
 	}
+	StateSync struct {
+		Enable              bool     `yaml:"Enable"`
+		RPCServers          []string `yaml:"RPCServers"`
+		TrustHeight         uint64   `yaml:"TrustHeight"`
+		TrustHash           string   `yaml:"TrustHash"`
+		TrustPeriod         int      `yaml:"TrustPeriod"`   // in hours
+		DiscoveryTime       int      `yaml:"DiscoveryTime"` // in seconds
+		ChunkFetchers       int      `yaml:"ChunkFetchers"`
+		ChunkRequestTimeout int      `yaml:"ChunkRequestTimeout"` // in seconds
+		SnapshotInterval    uint64   `yaml:"SnapshotInterval"`    // in blocks, 0 to disable
+		SnapshotKeepRecent  int      `yaml:"SnapshotKeepRecent"`
+		SnapshotDir         string   `yaml:"SnapshotDir,omitempty"`
+	}
+	Evidence struct {
+		CommittedRetainBlocks uint64 `yaml:"CommittedRetainBlocks"` // 0 to keep all
+	}
+	State struct {
+		RetainBlocks uint64 `yaml:"RetainBlocks"` // 0 to keep all
+	}
+	Compaction struct {
+		BlockStoreInterval int `yaml:"BlockStoreInterval"` // in hours, 0 to disable
+		StateStoreInterval int `yaml:"StateStoreInterval"` // in hours, 0 to disable
+		EvidenceInterval   int `yaml:"EvidenceInterval"`   // in hours, 0 to disable
+	}
+	Pruning struct {
+		Mode       string `yaml:"Mode"`       // archive, default or everything-but-snapshots
+		KeepRecent uint64 `yaml:"KeepRecent"` // number of latest heights kept
+		Interval   int    `yaml:"Interval"`   // in hours
+	}
+	TraceIndex struct {
+		StartHeight uint64 `yaml:"StartHeight"`
+		Reexec      uint64 `yaml:"Reexec"`
+	}
 	Chain struct {
 		ServiceName        string     `yaml:"ServiceName"`
 		Protocol           *string    `yaml:"Protocol,omitempty"`
@@ -75,10 +129,23 @@ type (
 		Genesis            *Genesis   `yaml:"Genesis,omitempty"`
 		Database           *Database  `yaml:"Database,omitempty"`
 		Seeds              []string   `yaml:"Seeds"`
+		DNSSeeds           []string   `yaml:"DNSSeeds,omitempty"`
 		Events             []Event    `yaml:"Events"`
 		PublishedEndpoint  *string    `yaml:"PublishedEndpoint,omitempty"`
 		SubscribedEndpoint *string    `yaml:"SubscribedEndpoint,omitempty"`
 		Consensus          *Consensus `yaml:"Consensus"`
+		ChainFeed          *ChainFeed `yaml:"ChainFeed,omitempty"`
+	}
+	ChainFeed struct {
+		Name          string   `yaml:"Name"`
+		Sink          string   `yaml:"Sink"`
+		Driver        string   `yaml:"Driver,omitempty"`
+		DSN           string   `yaml:"DSN,omitempty"`
+		Schema        string   `yaml:"Schema,omitempty"`
+		Brokers       []string `yaml:"Brokers,omitempty"`
+		Topic         string   `yaml:"Topic,omitempty"`
+		StartHeight   uint64   `yaml:"StartHeight"`
+		RetryInterval int      `yaml:"RetryInterval"` // in seconds
 	}
 	Genesis struct {
 		Accounts        []Account                   `yaml:"Accounts"`
@@ -100,15 +167,33 @@ type (
 		ABI      string `yaml:"ABI,omitempty"`
 	}
 	Pool struct {
-		AccountSlots uint64 `yaml:"AccountSlots"`
-		AccountQueue uint64 `yaml:"AccountQueue"`
-		GlobalSlots  uint64 `yaml:"GlobalSlots"`
-		GlobalQueue  uint64 `yaml:"GlobalQueue"`
-		BlockSize    int    `yaml:"BlockSize,omitempty"`
-		Broadcast    bool   `yaml:"Broadcast"`
+		AccountSlots    uint64    `yaml:"AccountSlots"`
+		AccountQueue    uint64    `yaml:"AccountQueue"`
+		GlobalSlots     uint64    `yaml:"GlobalSlots"`
+		GlobalQueue     uint64    `yaml:"GlobalQueue"`
+		AccountRate     uint64    `yaml:"AccountRate,omitempty"`
+		AccountBytes    uint64    `yaml:"AccountBytes,omitempty"`
+		GlobalBytes     uint64    `yaml:"GlobalBytes,omitempty"`
+		Lifetime        int       `yaml:"Lifetime,omitempty"`        // in seconds
+		PendingLifetime int       `yaml:"PendingLifetime,omitempty"` // in seconds
+		PriceLimit      uint64    `yaml:"PriceLimit,omitempty"`
+		PriceBump       uint64    `yaml:"PriceBump,omitempty"`
+		NoLocals        bool      `yaml:"NoLocals,omitempty"`
+		Journal         string    `yaml:"Journal,omitempty"`
+		Rejournal       int       `yaml:"Rejournal,omitempty"` // in seconds
+		BlockSize       int       `yaml:"BlockSize,omitempty"`
+		Broadcast       bool      `yaml:"Broadcast"`
+		Filter          *TxFilter `yaml:"Filter,omitempty"`
+	}
+	TxFilter struct {
+		Mode               string   `yaml:"Mode"` // allowlist or denylist
+		Senders            []string `yaml:"Senders,omitempty"`
+		Recipients         []string `yaml:"Recipients,omitempty"`
+		NoContractCreation bool     `yaml:"NoContractCreation,omitempty"`
 	}
 	Database struct {
 		Type    uint   `yaml:"Type"`
+		Backend string `yaml:"Backend"`
 		Dir     string `yaml:"Dir"`
 		Caches  int    `yaml:"Caches"`
 		Handles int    `yaml:"Handles"`
@@ -144,6 +229,7 @@ type (
 	ConsensusParams struct {
 		Block    BlockParams    `yaml:"Block"`
 		Evidence EvidenceParams `yaml:"Evidence"`
+		Timeout  TimeoutParams  `yaml:"Timeout,omitempty"`
 	}
 	BlockParams struct {
 		MaxBytes   int64  `yaml:"MaxBytes"`
@@ -155,11 +241,27 @@ type (
 		MaxAgeDuration  int   `yaml:"MaxAgeDuration"`
 		MaxBytes        int64 `yaml:"MaxBytes"`
 	}
+	TimeoutParams struct {
+		// All timeouts are in milliseconds, unset ones use the Consensus config
+		Propose        int `yaml:"Propose"`
+		ProposeDelta   int `yaml:"ProposeDelta"`
+		Prevote        int `yaml:"Prevote"`
+		PrevoteDelta   int `yaml:"PrevoteDelta"`
+		Precommit      int `yaml:"Precommit"`
+		PrecommitDelta int `yaml:"PrecommitDelta"`
+		Commit         int `yaml:"Commit"`
+	}
 	KeyStoreConfig struct {
 		KeyStoreDir           string `yaml:"KeyStoreDir"`
 		UseLightweightKDF     bool   `yaml:"UseLightweightKDF"`
 		InsecureUnlockAllowed bool   `yaml:"InsecureUnlockAllowed"`
 	}
+	PrivValidator struct {
+		KeyFile    string `yaml:"KeyFile"`
+		StateFile  string `yaml:"StateFile,omitempty"`
+		BLSKeyFile string `yaml:"BLSKeyFile,omitempty"`
+		Password   string `yaml:"Password"`
+	}
 	Debug struct {
 		Port string `yaml:"Port"`
 	}
@@ -0,0 +1,125 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kardiachain/go-kardia/cmd/flags"
+	"github.com/kardiachain/go-kardia/consensus"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	// Git SHA1 commit hash of the release (set via linker flags)
+	gitCommit = ""
+	gitDate   = ""
+
+	app *cli.App
+
+	// Flags needed by waltool
+	walFlag = cli.StringFlag{
+		Name:  "wal",
+		Usage: "Path of the consensus WAL head file (e.g. <datadir>/cs.wal/wal)",
+	}
+	quietFlag = cli.BoolFlag{
+		Name:  "quiet",
+		Usage: "Only print the summary of every WAL file",
+	}
+
+	inspectCommand = cli.Command{
+		Name:   "inspect",
+		Usage:  "Decode the WAL messages and report corrupted entries",
+		Action: flags.MigrateFlags(inspect),
+		Flags:  []cli.Flag{walFlag, quietFlag},
+	}
+	repairCommand = cli.Command{
+		Name:   "repair",
+		Usage:  "Truncate corrupted WAL files at their last good entry",
+		Action: flags.MigrateFlags(repair),
+		Flags:  []cli.Flag{walFlag},
+	}
+)
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "kardia consensus WAL tool")
+	app.Flags = []cli.Flag{walFlag, quietFlag}
+	app.Commands = []cli.Command{inspectCommand, repairCommand}
+	cli.CommandHelpTemplate = flags.OriginCommandHelpTemplate
+}
+
+// walFile returns the WAL head file of a stopped node.
+func walFile(c *cli.Context) string {
+	file := c.GlobalString(walFlag.Name)
+	if file == "" {
+		flags.Fatalf("No WAL file specified (--%s)", walFlag.Name)
+	}
+	return file
+}
+
+func logReports(reports []consensus.WALFileReport) int {
+	corrupted := 0
+	for _, report := range reports {
+		if report.Corrupted() {
+			corrupted++
+			log.Warn("Corrupted WAL file", "file", report.File, "entries", report.Entries,
+				"good", report.GoodSize, "size", report.Size, "err", report.Err)
+			continue
+		}
+		log.Info("Checked WAL file", "file", report.File, "entries", report.Entries, "size", report.Size)
+	}
+	return corrupted
+}
+
+func inspect(c *cli.Context) error {
+	show := func(entry consensus.WALEntry) { fmt.Println(entry) }
+	if c.GlobalBool(quietFlag.Name) {
+		show = nil
+	}
+	reports, err := consensus.InspectWAL(walFile(c), show)
+	if err != nil {
+		flags.Fatalf("Failed to inspect WAL: %v", err)
+	}
+	if logReports(reports) > 0 {
+		return fmt.Errorf("WAL is corrupted, run repair with the node stopped")
+	}
+	return nil
+}
+
+func repair(c *cli.Context) error {
+	reports, err := consensus.RepairWAL(walFile(c))
+	if err != nil {
+		flags.Fatalf("Failed to repair WAL: %v", err)
+	}
+	if logReports(reports) > 0 {
+		log.Info("Truncated corrupted WAL files, originals are kept with a .CORRUPTED suffix")
+	}
+	return nil
+}
+
+func main() {
+	log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
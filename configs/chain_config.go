@@ -29,8 +29,9 @@ import (
 // that any network, identified by its genesis block, can have its own
 // set of configuration options.
 type ChainConfig struct {
-	ChainID       *big.Int `json:"chainId,omitempty" yaml:"ChainID"`             // chainId identifies the current chain and is used for replay protection
-	GalaxiasBlock *uint64  `json:"galaxiasBlock,omitempty" yaml:"galaxiasBlock"` // Mainnet Galaxias switch block (nil = no fork, 0 = already Galaxias)
+	ChainID         *big.Int `json:"chainId,omitempty" yaml:"ChainID"`                 // chainId identifies the current chain and is used for replay protection
+	GalaxiasBlock   *uint64  `json:"galaxiasBlock,omitempty" yaml:"galaxiasBlock"`     // Mainnet Galaxias switch block (nil = no fork, 0 = already Galaxias)
+	GovernanceBlock *uint64  `json:"governanceBlock,omitempty" yaml:"governanceBlock"` // On-chain governance switch block (nil = no fork, 0 = governance from genesis)
 
 	// Various consensus engines
 	Kaicon *KaiconConfig `json:"kaicon,omitempty" yaml:"KaiconConfig"`
@@ -74,6 +75,11 @@ func (c *ChainConfig) IsGalaxias(height *uint64) bool {
 	return isForked(c.GalaxiasBlock, height)
 }
 
+// IsGovernance returns whether on-chain governance is active at the given head block
+func (c *ChainConfig) IsGovernance(height *uint64) bool {
+	return isForked(c.GovernanceBlock, height)
+}
+
 // isForked returns whether a fork scheduled at block s is active at the given head block.
 func isForked(s, head *uint64) bool {
 	if s == nil || head == nil {
@@ -212,6 +212,25 @@ func (cfg *ConsensusConfig) WaitForTxs() bool {
 	return !cfg.IsCreateEmptyBlocks || cfg.CreateEmptyBlocksInterval > 0
 }
 
+// WithTimeoutParams returns a copy of cfg with the timeouts set by the consensus params.
+// Unset (zero) timeouts keep their configured value, along with their delta.
+func (cfg *ConsensusConfig) WithTimeoutParams(params kaiproto.TimeoutParams) *ConsensusConfig {
+	res := *cfg
+	if params.Propose > 0 {
+		res.TimeoutPropose, res.TimeoutProposeDelta = params.Propose, params.ProposeDelta
+	}
+	if params.Prevote > 0 {
+		res.TimeoutPrevote, res.TimeoutPrevoteDelta = params.Prevote, params.PrevoteDelta
+	}
+	if params.Precommit > 0 {
+		res.TimeoutPrecommit, res.TimeoutPrecommitDelta = params.Precommit, params.PrecommitDelta
+	}
+	if params.Commit > 0 {
+		res.TimeoutCommit = params.Commit
+	}
+	return &res
+}
+
 // Commit returns the amount of time to wait for straggler votes after receiving +2/3 precommits for a single block (ie. a commit).
 func (cfg *ConsensusConfig) Commit(t time.Time) time.Time {
 	return t.Add(cfg.TimeoutCommit)
@@ -280,6 +299,106 @@ func TestFastSyncConfig() *FastSyncConfig {
 	}
 }
 
+// StateSyncConfig defines how a node serves state snapshots to its peers and
+// how an empty node restores its state from them instead of replaying blocks.
+type StateSyncConfig struct {
+	Enable bool // true to restore the state from a snapshot on an empty node.
+
+	// Light client options verifying the snapshot, see light.TrustOptions.
+	RPCServers  []string      // RPC of the full nodes serving the headers.
+	TrustHeight uint64        // height of a header trusted by the operator.
+	TrustHash   common.Hash   // hash of the trusted header.
+	TrustPeriod time.Duration // time during which a verified header is trusted.
+
+	DiscoveryTime       time.Duration // time spent collecting snapshot offers.
+	ChunkFetchers       int           // number of chunks requested at a time.
+	ChunkRequestTimeout time.Duration // maximum response time from a peer.
+
+	SnapshotInterval   uint64 // create a snapshot every this many blocks, 0 to disable.
+	SnapshotKeepRecent int    // number of snapshots kept, 0 to keep all.
+	SnapshotDir        string // directory of the snapshots, relative to the data dir.
+}
+
+func DefaultStateSyncConfig() *StateSyncConfig {
+	return &StateSyncConfig{
+		Enable:              false,
+		TrustPeriod:         168 * time.Hour,
+		DiscoveryTime:       15 * time.Second,
+		ChunkFetchers:       4,
+		ChunkRequestTimeout: 10 * time.Second,
+		SnapshotInterval:    0,
+		SnapshotKeepRecent:  2,
+		SnapshotDir:         "snapshots",
+	}
+}
+
+// EvidenceConfig defines how the evidence pool manages its database.
+type EvidenceConfig struct {
+	CommittedRetainBlocks uint64 // committed evidence is kept for this many blocks, 0 to keep all.
+}
+
+func DefaultEvidenceConfig() *EvidenceConfig {
+	return &EvidenceConfig{
+		CommittedRetainBlocks: 0,
+	}
+}
+
+// StateConfig defines how the consensus state store manages its database.
+type StateConfig struct {
+	RetainBlocks uint64 // validators and consensus params records are kept for this many blocks, 0 to keep all.
+}
+
+func DefaultStateConfig() *StateConfig {
+	return &StateConfig{
+		RetainBlocks: 0,
+	}
+}
+
+// CompactionConfig defines how often the stores of the chain database are
+// compacted in the background to reclaim the space of pruned keys.
+type CompactionConfig struct {
+	BlockStoreInterval time.Duration // interval between compactions of the block store, 0 to disable.
+	StateStoreInterval time.Duration // interval between compactions of the consensus state store, 0 to disable.
+	EvidenceInterval   time.Duration // interval between compactions of the evidence store, 0 to disable.
+}
+
+func DefaultCompactionConfig() *CompactionConfig {
+	return &CompactionConfig{
+		BlockStoreInterval: 0,
+		StateStoreInterval: 0,
+		EvidenceInterval:   0,
+	}
+}
+
+// PruningConfig defines which blocks and states the pruning manager keeps,
+// see the pruning modes of the maintenance package.
+type PruningConfig struct {
+	Mode       string        // "archive", "default" or "everything-but-snapshots".
+	KeepRecent uint64        // number of latest heights whose blocks and states are kept.
+	Interval   time.Duration // interval between prunings.
+}
+
+func DefaultPruningConfig() *PruningConfig {
+	return &PruningConfig{
+		Mode:       "archive",
+		KeepRecent: 100000,
+		Interval:   24 * time.Hour,
+	}
+}
+
+// TraceIndexConfig defines which blocks the trace indexer runs the call tracer on.
+type TraceIndexConfig struct {
+	StartHeight uint64 // first block traced when the index is empty.
+	Reexec      uint64 // number of blocks re-executed to regenerate a missing state.
+}
+
+func DefaultTraceIndexConfig() *TraceIndexConfig {
+	return &TraceIndexConfig{
+		StartHeight: 1,
+		Reexec:      128,
+	}
+}
+
 // ======================= Genesis Utils Functions =======================
 
 type Contract struct {
@@ -43,6 +43,13 @@ type P2PConfig struct { //nolint: maligned
 	// We only use these if we can’t connect to peers in the addrbook
 	Seeds []string `mapstructure:"seeds"`
 
+	// List of "<signer address>@<domain>" entries whose TXT records publish
+	// signed seed lists, used alongside Seeds
+	DNSSeeds []string `mapstructure:"dns_seeds"`
+
+	// How often DNS seeds are resolved again
+	DNSSeedsRefreshPeriod time.Duration `mapstructure:"dns_seeds_refresh_period"`
+
 	// Comma separated list of nodes to keep persistent connections to
 	PersistentPeers string `mapstructure:"persistent_peers"`
 
@@ -96,6 +103,14 @@ type P2PConfig struct { //nolint: maligned
 	// Toggle to disable guard against peers connecting from the same ip.
 	AllowDuplicateIP bool `mapstructure:"allow_duplicate_ip"`
 
+	// List of CIDRs (or plain IPs) peers may connect from and be dialed at.
+	// Empty means any address is allowed
+	AllowedCIDRs []string `mapstructure:"allowed_cidrs"`
+
+	// List of CIDRs (or plain IPs) peers are never accepted from nor dialed
+	// at. Takes precedence over AllowedCIDRs
+	DeniedCIDRs []string `mapstructure:"denied_cidrs"`
+
 	// Peer connection configuration.
 	HandshakeTimeout time.Duration `mapstructure:"handshake_timeout"`
 	DialTimeout      time.Duration `mapstructure:"dial_timeout"`
@@ -119,6 +134,7 @@ func DefaultP2PConfig() *P2PConfig {
 		MaxNumInboundPeers:           40,
 		MaxNumOutboundPeers:          15,
 		PersistentPeersMaxDialPeriod: 0 * time.Second,
+		DNSSeedsRefreshPeriod:        30 * time.Minute,
 		FlushThrottleTimeout:         100 * time.Millisecond,
 		// MTU (Maximum Transmission Unit) for Etherenet is 1500 bytes
 		// IP header = 20 bytes, TCP header = 20 bytes
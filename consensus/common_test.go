@@ -339,7 +339,10 @@ func newState(vs types.PrivValidator, state cstate.LatestBlockState) (*Consensus
 	txPool := tx_pool.NewTxPool(txConfig, chainConfig, bc)
 	stateStore := cstate.NewStore(kaiDb.DB())
 	evPool, _ := evidence.NewPool(stateStore, kaiDb.DB(), bc)
-	bOper := blockchain.NewBlockOperations(logger, bc, txPool, evPool, staking)
+	bOper, err := blockchain.NewBlockOperations(logger, bc, txPool, evPool, staking)
+	if err != nil {
+		return nil, err
+	}
 
 	// evReactor := evidence.NewReactor(evPool)
 	blockExec := cstate.NewBlockExecutor(stateStore, logger, evPool, bOper)
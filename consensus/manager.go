@@ -102,6 +102,50 @@ func (conR *ConsensusManager) Validators() []*types.Validator {
 	return conR.conS.Validators.CurrentValidators()
 }
 
+// PeerStateDump is the known round state of a connected peer.
+type PeerStateDump struct {
+	ID         p2p.ID                  `json:"id"`
+	Address    string                  `json:"address"`
+	RoundState *cstypes.PeerRoundState `json:"round_state"`
+}
+
+// ConsensusStateDump is a snapshot of our round state and the round states
+// of our peers.
+type ConsensusStateDump struct {
+	RoundState *cstypes.RoundStateDump `json:"round_state"`
+	Peers      []PeerStateDump         `json:"peers"`
+}
+
+// RoundState returns a snapshot of the current round state, including the
+// votes received from each validator.
+func (conR *ConsensusManager) RoundState() *cstypes.RoundStateDump {
+	return conR.conS.GetRoundState().Dump()
+}
+
+// DumpConsensusState returns a snapshot of the current round state along with
+// the round state of every connected peer.
+func (conR *ConsensusManager) DumpConsensusState() *ConsensusStateDump {
+	dump := &ConsensusStateDump{
+		RoundState: conR.RoundState(),
+		Peers:      []PeerStateDump{},
+	}
+	if conR.Switch == nil {
+		return dump
+	}
+	for _, peer := range conR.Switch.Peers().List() {
+		peerState, ok := peer.Get(types.PeerStateKey).(*PeerState)
+		if !ok {
+			continue
+		}
+		dump.Peers = append(dump.Peers, PeerStateDump{
+			ID:         peer.ID(),
+			Address:    peer.SocketAddr().String(),
+			RoundState: peerState.GetRoundState(),
+		})
+	}
+	return dump
+}
+
 func (conR *ConsensusManager) OnStart() error {
 	conR.Logger.Info("Consensus manager ", "waitSync", conR.WaitSync())
 	conR.subscribeToBroadcastEvents()
@@ -178,6 +222,7 @@ func (conR *ConsensusManager) GetChannels() []*p2p.ChannelDescriptor {
 			SendQueueCapacity:   64,
 			RecvBufferCapacity:  8388608, // 8 Mbs
 			RecvMessageCapacity: maxMsgSize,
+			SendWindow:          262144, // 256 Kbs, yield to votes when saturated
 		},
 		{
 			ID:                  VoteChannel,
@@ -326,6 +371,15 @@ func (conR *ConsensusManager) Receive(chID byte, src p2p.Peer, msgBytes []byte)
 			ps.SetHasProposal(msg.Proposal)
 			conR.conS.peerMsgQueue <- msgInfo{msg, src.ID()}
 		case *ProposalPOLMessage:
+			cs := conR.conS
+			cs.mtx.RLock()
+			height, valSize := cs.Height, cs.Validators.Size()
+			cs.mtx.RUnlock()
+			if height == msg.Height && msg.ProposalPOL.Size() != valSize {
+				conR.Switch.StopPeerForError(src, fmt.Errorf("ProposalPOL bit array size %d not equal to validators count %d",
+					msg.ProposalPOL.Size(), valSize))
+				return
+			}
 			ps.ApplyProposalPOLMessage(msg)
 		case *BlockPartMessage:
 			ps.SetHasProposalBlockPart(msg.Height, msg.Round, int(msg.Part.Index))
@@ -356,10 +410,15 @@ func (conR *ConsensusManager) Receive(chID byte, src p2p.Peer, msgBytes []byte)
 		case *VoteSetBitsMessage:
 			cs := conR.conS
 			cs.mtx.Lock()
-			height, votes := cs.Height, cs.Votes
+			height, votes, valSize := cs.Height, cs.Votes, cs.Validators.Size()
 			cs.mtx.Unlock()
 
 			if height == msg.Height {
+				if size := msg.Votes.Size(); size != 0 && size != valSize {
+					conR.Switch.StopPeerForError(src, fmt.Errorf("votes bit array size %d not equal to validators count %d",
+						size, valSize))
+					return
+				}
 				var ourVotes *cmn.BitArray
 				switch msg.Type {
 				case kproto.PrevoteType:
@@ -409,11 +468,27 @@ func (conR *ConsensusManager) unsubscribeFromBroadcastEvents() {
 // ------------ Broadcast messages ------------
 
 func (conR *ConsensusManager) broadcastNewRoundStepMessages(rs *cstypes.RoundState) {
+	if rs.Step == cstypes.RoundStepNewHeight {
+		conR.updateValidatorPeers(rs.Validators)
+	}
 	nrsMsg := makeRoundStepMessage(rs)
 	conR.Logger.Trace("broadcastNewRoundStepMessage", "nrsMsg", nrsMsg, "height", rs.Height)
 	conR.Switch.Broadcast(StateChannel, MustEncode(nrsMsg))
 }
 
+// updateValidatorPeers tags the peers of the validators in vals so the switch
+// prioritizes them over ordinary full nodes.
+func (conR *ConsensusManager) updateValidatorPeers(vals *types.ValidatorSet) {
+	if vals == nil {
+		return
+	}
+	addrs := make([]cmn.Address, len(vals.Validators))
+	for i, val := range vals.Validators {
+		addrs[i] = val.Address
+	}
+	conR.Switch.SetValidatorAddresses(addrs)
+}
+
 // Broadcasts HasVoteMessage to peers that care.
 func (conR *ConsensusManager) broadcastHasVoteMessage(vote *types.Vote) {
 	msg := &HasVoteMessage{
@@ -648,7 +723,9 @@ OUTER_LOOP:
 			// Load the block commit for prs.Height,
 			// which contains precommit signatures for prs.Height.
 			commit := conR.conS.blockOperations.LoadBlockCommit(prs.Height)
-			if ps.PickSendVote(commit) {
+			// Precommits of an aggregated commit carry no signatures, the peer
+			// catches up through the block and its commit instead.
+			if commit != nil && !commit.IsAggregated() && ps.PickSendVote(commit) {
 				logger.Debug("Picked Catchup commit to send", "height", prs.Height)
 				continue OUTER_LOOP
 			}
@@ -861,6 +938,9 @@ func (m *ProposalPOLMessage) ValidateBasic() error {
 	if m.ProposalPOL.Size() == 0 {
 		return ErrEmptyProposalPOL
 	}
+	if m.ProposalPOL.Size() > types.MaxVotesCount {
+		return fmt.Errorf("ProposalPOL bit array is too big: %d, max: %d", m.ProposalPOL.Size(), types.MaxVotesCount)
+	}
 	return nil
 }
 
@@ -1116,8 +1196,12 @@ func (ps *PeerState) PickVoteToSend(votes types.VoteSetReader) (vote *types.Vote
 		return nil, false // Not something worth sending
 	}
 	if index, ok := votes.BitArray().Sub(psVotes).PickRandom(); ok {
+		vote := votes.GetByIndex(uint32(index))
+		if vote == nil {
+			return nil, false
+		}
 		ps.setHasVote(height, round, signedMsgType, uint32(index))
-		return votes.GetByIndex(uint32(index)), true
+		return vote, true
 	}
 	return nil, false
 }
@@ -0,0 +1,104 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package consensus
+
+import (
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/metrics"
+	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+var (
+	MetricHeight            = "height"
+	MetricRounds            = "rounds"
+	MetricValidators        = "validators"
+	MetricMissingValidators = "validators/missing"
+
+	MetricBlockInterval = "time/block_interval"
+	MetricProposalWait  = "time/proposal_wait"
+	MetricCommitLatency = "time/commit"
+
+	MetricTimeoutPropose   = "timeout/propose"
+	MetricTimeoutPrevote   = "timeout/prevote"
+	MetricTimeoutPrecommit = "timeout/precommit"
+
+	MetricMissedProposer = "proposer/missed"
+	MetricByzantineVotes = "votes/byzantine"
+)
+
+// Setup metrics
+var (
+	heightGauge            = metrics.NewRegisteredGauge(MetricHeight, metrics.ConsensusRegistry)
+	roundsHistogram        = metrics.NewRegisteredHistogram(MetricRounds, metrics.ConsensusRegistry, metrics.NewExpDecaySample(1028, 0.015)) // Rounds needed to commit a height
+	validatorsGauge        = metrics.NewRegisteredGauge(MetricValidators, metrics.ConsensusRegistry)
+	missingValidatorsGauge = metrics.NewRegisteredGauge(MetricMissingValidators, metrics.ConsensusRegistry) // Validators without a precommit in the last commit
+
+	blockIntervalTimer = metrics.NewRegisteredTimer(MetricBlockInterval, metrics.ConsensusRegistry) // Time between two committed blocks
+	proposalWaitTimer  = metrics.NewRegisteredTimer(MetricProposalWait, metrics.ConsensusRegistry)  // Time from entering propose to a complete proposal block
+	commitLatencyTimer = metrics.NewRegisteredTimer(MetricCommitLatency, metrics.ConsensusRegistry) // Time from the start of a height to its commit
+
+	timeoutProposeMeter   = metrics.NewRegisteredMeter(MetricTimeoutPropose, metrics.ConsensusRegistry)
+	timeoutPrevoteMeter   = metrics.NewRegisteredMeter(MetricTimeoutPrevote, metrics.ConsensusRegistry)
+	timeoutPrecommitMeter = metrics.NewRegisteredMeter(MetricTimeoutPrecommit, metrics.ConsensusRegistry)
+
+	missedProposerMeter = metrics.NewRegisteredMeter(MetricMissedProposer, metrics.ConsensusRegistry) // Propose timeouts without a complete proposal
+	byzantineVoteMeter  = metrics.NewRegisteredMeter(MetricByzantineVotes, metrics.ConsensusRegistry) // Conflicting votes turned into evidence
+)
+
+// validatorCounter returns the per validator counter of the given metric.
+func validatorCounter(metric string, addr common.Address) metrics.Counter {
+	return metrics.GetOrRegisterCounter(metric+"/"+addr.Hex(), metrics.ConsensusRegistry)
+}
+
+// recordVotes counts the votes received from each validator in the given
+// vote set, and returns how many validators did not vote.
+func recordVotes(voteSet *types.VoteSet, vals *types.ValidatorSet) int {
+	if voteSet == nil || vals == nil {
+		return 0
+	}
+	metric := "votes/prevote"
+	if voteSet.Type() == kproto.PrecommitType {
+		metric = "votes/precommit"
+	}
+	missing := 0
+	for i, val := range vals.Validators {
+		if voteSet.GetByIndex(uint32(i)) == nil {
+			missing++
+			continue
+		}
+		validatorCounter(metric, val.Address).Inc(1)
+	}
+	return missing
+}
+
+// recordCommitMetrics updates the metrics of the height about to be committed.
+func (cs *ConsensusState) recordCommitMetrics(block *types.Block) {
+	if !metrics.Enabled {
+		return
+	}
+	roundsHistogram.Update(int64(cs.CommitRound))
+	if cs.state.LastBlockHeight > 0 {
+		blockIntervalTimer.Update(block.Time().Sub(cs.state.LastBlockTime))
+	}
+	commitLatencyTimer.UpdateSince(cs.StartTime)
+
+	recordVotes(cs.Votes.Prevotes(cs.CommitRound), cs.Validators)
+	missingValidatorsGauge.Update(int64(recordVotes(cs.Votes.Precommits(cs.CommitRound), cs.Validators)))
+}
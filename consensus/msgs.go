@@ -166,8 +166,10 @@ func MsgFromProto(msg *kcons.Message) (Message, error) {
 			return nil, fmt.Errorf("parts to proto error: %w", err)
 		}
 
-		pbBits := new(common.BitArray)
-		pbBits.FromProto(msg.NewValidBlock.BlockParts)
+		pbBits, err := common.BitArrayFromProto(msg.NewValidBlock.BlockParts, int(pbPartSetHeader.Total))
+		if err != nil {
+			return nil, fmt.Errorf("block parts to proto error: %w", err)
+		}
 
 		pb = &NewValidBlockMessage{
 			Height:           msg.NewValidBlock.Height,
@@ -186,8 +188,10 @@ func MsgFromProto(msg *kcons.Message) (Message, error) {
 			Proposal: pbP,
 		}
 	case *kcons.Message_ProposalPol:
-		pbBits := new(common.BitArray)
-		pbBits.FromProto(&msg.ProposalPol.ProposalPol)
+		pbBits, err := common.BitArrayFromProto(&msg.ProposalPol.ProposalPol, types.MaxVotesCount)
+		if err != nil {
+			return nil, fmt.Errorf("proposal pol to proto error: %w", err)
+		}
 		pb = &ProposalPOLMessage{
 			Height:           msg.ProposalPol.Height,
 			ProposalPOLRound: msg.ProposalPol.ProposalPolRound,
@@ -235,8 +239,10 @@ func MsgFromProto(msg *kcons.Message) (Message, error) {
 		if err != nil {
 			return nil, fmt.Errorf("voteSetBits msg to proto error: %w", err)
 		}
-		bits := new(common.BitArray)
-		bits.FromProto(&msg.VoteSetBits.Votes)
+		bits, err := common.BitArrayFromProto(&msg.VoteSetBits.Votes, types.MaxVotesCount)
+		if err != nil {
+			return nil, fmt.Errorf("voteSetBits votes to proto error: %w", err)
+		}
 
 		pb = &VoteSetBitsMessage{
 			Height:  msg.VoteSetBits.Height,
@@ -105,6 +105,10 @@ type ConsensusState struct {
 	state         cstate.LatestBlockState // State until height-1.
 	timeoutTicker TimeoutTicker
 
+	// seen commit of height-1 when it is aggregated, as its votes can't be
+	// reconstructed into LastCommit.
+	lastAggregatedCommit *types.Commit
+
 	// State changes may be triggered by: msgs from peers,
 	// msgs from ourself, or by timeouts
 	peerMsgQueue     chan msgInfo
@@ -129,6 +133,9 @@ type ConsensusState struct {
 
 	// closed when we finish shutting down
 	done chan struct{}
+
+	// when we entered the propose step of the current round
+	proposeStartTime time.Time
 }
 
 // NewConsensusState returns a new ConsensusState.
@@ -368,6 +375,7 @@ func (cs *ConsensusState) updateToState(state cstate.LatestBlockState) {
 				cs.Votes.Precommits(cs.CommitRound)))
 		}
 		cs.LastCommit = cs.Votes.Precommits(cs.CommitRound)
+		cs.lastAggregatedCommit = nil
 	case cs.LastCommit == nil:
 		// NOTE: when consensus starts, it has no votes. reconstructLastCommit
 		// must be called to reconstruct LastCommit from SeenCommit.
@@ -393,11 +401,12 @@ func (cs *ConsensusState) updateToState(state cstate.LatestBlockState) {
 		// And alternative solution that relies on clocks:
 		//  cs.StartTime = state.LastBlockTime.Add(timeoutCommit)
 		//cs.Logger.Trace("cs.CommitTime is 0")
-		cs.StartTime = cs.config.Commit(ktime.Now())
+		cs.StartTime = cs.config.WithTimeoutParams(state.ConsensusParams.Timeout).Commit(ktime.Now())
 	} else {
-		cs.StartTime = cs.config.Commit(cs.CommitTime)
+		cs.StartTime = cs.config.WithTimeoutParams(state.ConsensusParams.Timeout).Commit(cs.CommitTime)
 	}
 	cs.Validators = validators
+	validatorsGauge.Update(int64(validators.Size()))
 	cs.Proposal = nil
 	cs.ProposalBlock = nil
 	cs.ProposalBlockParts = nil
@@ -549,13 +558,27 @@ func (cs *ConsensusState) reconstructLastCommit(state cstate.LatestBlockState) {
 		return
 	}
 	seenCommit := cs.blockOperations.LoadSeenCommit(state.LastBlockHeight)
+	if seenCommit.IsAggregated() {
+		// Fast synced blocks are saved with the commit of the next block, whose
+		// precommits may be aggregated. Start from no votes, the ones gossiped
+		// during the commit timeout are collected, and propose the seen commit.
+		cs.Logger.Info("Seen commit is aggregated, LastCommit starts empty", "height", state.LastBlockHeight)
+		cs.LastCommit = types.NewVoteSet(state.ChainID, state.LastBlockHeight, seenCommit.Round,
+			kproto.PrecommitType, state.LastValidators)
+		cs.lastAggregatedCommit = seenCommit
+		return
+	}
 
-	lastPrecommits := types.CommitToVoteSet(state.ChainID, seenCommit, state.LastValidators)
+	lastPrecommits, err := types.CommitToVoteSet(state.ChainID, seenCommit, state.LastValidators)
+	if err != nil {
+		cmn.PanicSanity(fmt.Sprintf("Failed to reconstruct LastCommit: %v", err))
+	}
 	if !lastPrecommits.HasTwoThirdsMajority() {
 		cmn.PanicSanity("Failed to reconstruct LastCommit: Does not have +2/3 maj")
 	}
 
 	cs.LastCommit = lastPrecommits
+	cs.lastAggregatedCommit = nil
 }
 
 // Attempt to add the vote. if its a duplicate signature, dupeout the validator
@@ -578,9 +601,10 @@ func (cs *ConsensusState) tryAddVote(vote *types.Vote, peerID p2p.ID) (bool, err
 			if voteErr.VoteA.Height == cs.state.InitialHeight {
 				timestamp = cs.state.LastBlockTime // genesis time
 			} else {
-				timestamp = cstate.MedianTime(cs.LastCommit.MakeCommit(), cs.LastValidators)
+				timestamp = cstate.MedianTime(cs.makeLastCommit(), cs.LastValidators)
 			}
 
+			byzantineVoteMeter.Mark(1)
 			evidence := types.NewDuplicateVoteEvidence(voteErr.VoteA, voteErr.VoteB, timestamp, cs.Validators)
 			evidenceErr := cs.evpool.AddEvidenceFromConsensus(evidence)
 			if evidenceErr != nil {
@@ -786,6 +810,7 @@ func (cs *ConsensusState) signVote(signedMsgType kproto.SignedMsgType, hash cmn.
 	v := vote.ToProto()
 	err := cs.privValidator.SignVote(cs.state.ChainID, v)
 	vote.Signature = v.Signature
+	vote.BLSSignature = v.BlsSignature
 	return vote, err
 }
 
@@ -900,8 +925,14 @@ func (cs *ConsensusState) SetProposalAndBlock(
 	return nil
 }
 
+// timeouts returns the consensus config with the timeouts set by the consensus
+// params of the current state.
+func (cs *ConsensusState) timeouts() *cfg.ConsensusConfig {
+	return cs.config.WithTimeoutParams(cs.state.ConsensusParams.Timeout)
+}
+
 func (cs *ConsensusState) updateHeight(height uint64) {
-	//namdoh@ cs.metrics.Height.Set(float64(height))
+	heightGauge.Update(int64(height))
 	cs.Height = height
 }
 
@@ -947,6 +978,9 @@ func (cs *ConsensusState) addProposalBlockPart(msg *BlockPartMessage, peerID p2p
 		}
 
 		cs.ProposalBlock = block
+		if round == cs.Round && cs.Step == cstypes.RoundStepPropose {
+			proposalWaitTimer.UpdateSince(cs.proposeStartTime)
+		}
 		// NOTE: it's possible to receive complete proposal blocks for future rounds without having the proposal
 		cs.Logger.Info("Received complete proposal block", "height", cs.ProposalBlock.Height(), "hash", cs.ProposalBlock.Hash())
 		if err := cs.eventBus.PublishEventCompleteProposal(cs.CompleteProposalEvent()); err != nil {
@@ -1093,7 +1127,8 @@ func (cs *ConsensusState) enterPropose(height uint64, round uint32) {
 	}()
 
 	// If we don't get the proposal quick enough, enterPrevote
-	cs.scheduleTimeout(cs.config.Propose(round), height, round, cstypes.RoundStepPropose)
+	cs.scheduleTimeout(cs.timeouts().Propose(round), height, round, cstypes.RoundStepPropose)
+	cs.proposeStartTime = time.Now()
 
 	// TODO(namdoh): For now this any node is a validator. Remove it once we
 	// restrict who can be validator.
@@ -1197,7 +1232,7 @@ func (cs *ConsensusState) enterPrevoteWait(height uint64, round uint32) {
 	}()
 
 	// Wait for some more prevotes; enterPrecommit
-	cs.scheduleTimeout(cs.config.Prevote(round), height, round, cstypes.RoundStepPrevoteWait)
+	cs.scheduleTimeout(cs.timeouts().Prevote(round), height, round, cstypes.RoundStepPrevoteWait)
 }
 
 // Enter: `timeoutPrevote` after any +2/3 prevotes.
@@ -1332,7 +1367,7 @@ func (cs *ConsensusState) enterPrecommitWait(height uint64, round uint32) {
 	}()
 
 	// Wait for some more precommits; enterNewRound
-	cs.scheduleTimeout(cs.config.Precommit(round), height, round, cstypes.RoundStepPrecommitWait)
+	cs.scheduleTimeout(cs.timeouts().Precommit(round), height, round, cstypes.RoundStepPrecommitWait)
 }
 
 // Enter: +2/3 precommits for block
@@ -1493,6 +1528,8 @@ func (cs *ConsensusState) finalizeCommit(height uint64) {
 	}
 
 	fail.Fail() // XXX
+	cs.recordCommitMetrics(block)
+
 	// NewHeightStep!
 	cs.updateToState(stateCopy)
 
@@ -1525,9 +1562,8 @@ func (cs *ConsensusState) createProposalBlock() (*types.Block, *types.PartSet) {
 		// The commit is empty, but not nil.
 		commit = types.NewCommit(0, 0, types.BlockID{}, nil)
 		cs.Logger.Trace("enterPropose: First height, use empty Commit.")
-	case cs.LastCommit.HasTwoThirdsMajority():
-		// Make the commit from LastCommit
-		commit = cs.LastCommit.MakeCommit()
+	case cs.LastCommit.HasTwoThirdsMajority() || cs.lastAggregatedCommit != nil:
+		commit = cs.makeLastCommit()
 		cs.Logger.Trace("enterPropose: Subsequent height, use last commit.", "commit", commit)
 	default: // This shouldn't happen.
 		cs.Logger.Error("enterPropose: Cannot propose anything: No commit for the previous block")
@@ -1542,6 +1578,22 @@ func (cs *ConsensusState) createProposalBlock() (*types.Block, *types.PartSet) {
 	)
 }
 
+// makeLastCommit makes the commit for the previous block from LastCommit,
+// aggregating its precommits if enabled by the consensus params, or returns
+// the aggregated seen commit if LastCommit lacks +2/3.
+func (cs *ConsensusState) makeLastCommit() *types.Commit {
+	if !cs.LastCommit.HasTwoThirdsMajority() {
+		return cs.lastAggregatedCommit
+	}
+	if cs.state.ConsensusParams.Validator.AggregatePrecommits {
+		if commit := cs.LastCommit.MakeAggregatedCommit(); commit != nil {
+			return commit
+		}
+		cs.Logger.Debug("Cannot aggregate last commit, some precommits have no BLS signature")
+	}
+	return cs.LastCommit.MakeCommit()
+}
+
 // Returns true if the proposal block is complete &&
 // (if POLRound was proposed, we have +2/3 prevotes from there).
 func (cs *ConsensusState) isProposalComplete() bool {
@@ -1730,16 +1782,25 @@ func (cs *ConsensusState) handleTimeout(ti timeoutInfo, rs cstypes.RoundState) {
 	case cstypes.RoundStepNewRound:
 		cs.enterPropose(ti.Height, 1)
 	case cstypes.RoundStepPropose:
+		timeoutProposeMeter.Mark(1)
+		if !cs.isProposalComplete() {
+			proposer := cs.Validators.GetProposer().Address
+			cs.Logger.Info("Proposer missed its turn", "height", ti.Height, "round", ti.Round, "proposer", proposer.Hex())
+			missedProposerMeter.Mark(1)
+			validatorCounter(MetricMissedProposer, proposer).Inc(1)
+		}
 		if err := cs.eventBus.PublishEventTimeoutPropose(cs.RoundStateEvent()); err != nil {
 			cs.Logger.Error("Error publishing timeout propose", "err", err)
 		}
 		cs.enterPrevote(ti.Height, ti.Round)
 	case cstypes.RoundStepPrevoteWait:
+		timeoutPrevoteMeter.Mark(1)
 		if err := cs.eventBus.PublishEventTimeoutWait(cs.RoundStateEvent()); err != nil {
 			cs.Logger.Error("Error publishing timeout wait", "err", err)
 		}
 		cs.enterPrecommit(ti.Height, ti.Round)
 	case cstypes.RoundStepPrecommitWait:
+		timeoutPrecommitMeter.Mark(1)
 		if err := cs.eventBus.PublishEventTimeoutWait(cs.RoundStateEvent()); err != nil {
 			cs.Logger.Error("Error publishing timeout wait", "err", err)
 		}
@@ -20,6 +20,7 @@ package types
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	cmn "github.com/kardiachain/go-kardia/lib/common"
@@ -189,6 +190,22 @@ func (hvs *HeightVoteSet) POLInfo() (polRound uint32, polBlockID types.BlockID)
 	return 0, types.BlockID{}
 }
 
+// RoundVoteSets returns the vote sets of all tracked rounds, ordered by round.
+func (hvs *HeightVoteSet) RoundVoteSets() []RoundVoteSet {
+	hvs.mtx.Lock()
+	defer hvs.mtx.Unlock()
+	rounds := make([]uint32, 0, len(hvs.roundVoteSets))
+	for r := range hvs.roundVoteSets {
+		rounds = append(rounds, r)
+	}
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i] < rounds[j] })
+	rvss := make([]RoundVoteSet, 0, len(rounds))
+	for _, r := range rounds {
+		rvss = append(rvss, hvs.roundVoteSets[r])
+	}
+	return rvss
+}
+
 func (hvs *HeightVoteSet) Precommits(round uint32) *types.VoteSet {
 	hvs.mtx.Lock()
 	defer hvs.mtx.Unlock()
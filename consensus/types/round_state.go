@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/types"
 )
 
@@ -165,3 +166,116 @@ func (rs *RoundState) CompleteProposalEvent() types.EventDataCompleteProposal {
 		BlockID: blockId,
 	}
 }
+
+//-----------------------------------------------------------------------------
+
+// RoundStateDump is a JSON friendly snapshot of the RoundState used to
+// inspect a stalled consensus.
+type RoundStateDump struct {
+	Height     uint64          `json:"height"`
+	Round      uint32          `json:"round"`
+	Step       string          `json:"step"`
+	StartTime  time.Time       `json:"start_time"`
+	CommitTime time.Time       `json:"commit_time"`
+	Proposer   common.Address  `json:"proposer"`
+	Proposal   *types.Proposal `json:"proposal"`
+
+	ProposalBlockHash common.Hash `json:"proposal_block_hash"`
+	LockedRound       uint32      `json:"locked_round"`
+	LockedBlockHash   common.Hash `json:"locked_block_hash"`
+	ValidRound        uint32      `json:"valid_round"`
+	ValidBlockHash    common.Hash `json:"valid_block_hash"`
+
+	Votes      []*RoundVotesDump `json:"votes"`
+	LastCommit *VoteSetDump      `json:"last_commit"`
+}
+
+// RoundVotesDump holds the prevotes and precommits received in a round.
+type RoundVotesDump struct {
+	Round      uint32       `json:"round"`
+	Prevotes   *VoteSetDump `json:"prevotes"`
+	Precommits *VoteSetDump `json:"precommits"`
+}
+
+// VoteSetDump lists the vote received from every validator of a vote set.
+type VoteSetDump struct {
+	Bits              string           `json:"bits"`
+	TwoThirdsMajority *types.BlockID   `json:"two_thirds_majority"`
+	Votes             []*ValidatorVote `json:"votes"`
+	Missing           []common.Address `json:"missing"`
+}
+
+// ValidatorVote is the vote received from a validator, if any.
+type ValidatorVote struct {
+	Address     common.Address `json:"address"`
+	VotingPower int64          `json:"voting_power"`
+	Voted       bool           `json:"voted"`
+	BlockHash   common.Hash    `json:"block_hash"` // Empty for a nil vote
+}
+
+// Dump returns a snapshot of the RoundState. It only reads through the vote
+// sets' own locks, so it is safe to call on the copy returned by
+// ConsensusState.GetRoundState.
+func (rs *RoundState) Dump() *RoundStateDump {
+	dump := &RoundStateDump{
+		Height:            rs.Height,
+		Round:             rs.Round,
+		Step:              rs.Step.String(),
+		StartTime:         rs.StartTime,
+		CommitTime:        rs.CommitTime,
+		Proposal:          rs.Proposal,
+		ProposalBlockHash: blockHash(rs.ProposalBlock),
+		LockedRound:       rs.LockedRound,
+		LockedBlockHash:   blockHash(rs.LockedBlock),
+		ValidRound:        rs.ValidRound,
+		ValidBlockHash:    blockHash(rs.ValidBlock),
+		LastCommit:        dumpVoteSet(rs.LastCommit, rs.LastValidators),
+	}
+	if rs.Validators != nil {
+		if proposer := rs.Validators.GetProposer(); proposer != nil {
+			dump.Proposer = proposer.Address
+		}
+	}
+	if rs.Votes != nil {
+		for _, rvs := range rs.Votes.RoundVoteSets() {
+			dump.Votes = append(dump.Votes, &RoundVotesDump{
+				Round:      rvs.Prevotes.GetRound(),
+				Prevotes:   dumpVoteSet(rvs.Prevotes, rs.Validators),
+				Precommits: dumpVoteSet(rvs.Precommits, rs.Validators),
+			})
+		}
+	}
+	return dump
+}
+
+func blockHash(block *types.Block) common.Hash {
+	if block == nil {
+		return common.Hash{}
+	}
+	return block.Hash()
+}
+
+func dumpVoteSet(voteSet *types.VoteSet, vals *types.ValidatorSet) *VoteSetDump {
+	if voteSet == nil || vals == nil {
+		return nil
+	}
+	dump := &VoteSetDump{
+		Bits:    voteSet.BitArray().String(),
+		Votes:   make([]*ValidatorVote, 0, len(vals.Validators)),
+		Missing: []common.Address{},
+	}
+	if blockID, ok := voteSet.TwoThirdsMajority(); ok {
+		dump.TwoThirdsMajority = &blockID
+	}
+	for i, val := range vals.Validators {
+		vv := &ValidatorVote{Address: val.Address, VotingPower: val.VotingPower}
+		if vote := voteSet.GetByIndex(uint32(i)); vote != nil {
+			vv.Voted = true
+			vv.BlockHash = vote.BlockID.Hash
+		} else {
+			dump.Missing = append(dump.Missing, val.Address)
+		}
+		dump.Votes = append(dump.Votes, vv)
+	}
+	return dump
+}
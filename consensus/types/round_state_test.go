@@ -0,0 +1,60 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/lib/p2p"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+func TestRoundStateDump(t *testing.T) {
+	valSet, privSet := types.RandValidatorSet(4, 1)
+	hvs := NewHeightVoteSet(log.New(), "kaicoin", 1, valSet)
+
+	votes := make(map[uint32]*types.Vote)
+	for _, idx := range []uint32{0, 2} {
+		vote := makeVoteHR(t, 1, idx, 0, privSet)
+		added, err := hvs.AddVote(vote, p2p.ID("peer1"))
+		require.NoError(t, err)
+		require.True(t, added)
+		votes[idx] = vote
+	}
+
+	rs := &RoundState{
+		Height:     1,
+		Round:      0,
+		Step:       RoundStepPrecommit,
+		Validators: valSet,
+		Votes:      hvs,
+	}
+	dump := rs.Dump()
+	assert.Equal(t, uint64(1), dump.Height)
+	assert.Equal(t, "RoundStepPrecommit", dump.Step)
+	assert.Equal(t, valSet.GetProposer().Address, dump.Proposer)
+	assert.Nil(t, dump.LastCommit)
+	// The next round is tracked too
+	require.Len(t, dump.Votes, 2)
+	assert.Equal(t, uint32(0), dump.Votes[0].Round)
+	assert.Equal(t, uint32(1), dump.Votes[1].Round)
+
+	prevotes, precommits := dump.Votes[0].Prevotes, dump.Votes[0].Precommits
+	assert.Len(t, prevotes.Missing, 4)
+	assert.Nil(t, precommits.TwoThirdsMajority)
+	require.Len(t, precommits.Votes, 4)
+	for i, vv := range precommits.Votes {
+		assert.Equal(t, valSet.Validators[i].Address, vv.Address)
+		if vote, ok := votes[uint32(i)]; ok {
+			assert.True(t, vv.Voted)
+			assert.Equal(t, vote.BlockID.Hash, vv.BlockHash)
+		} else {
+			assert.False(t, vv.Voted)
+			assert.Equal(t, common.Hash{}, vv.BlockHash)
+		}
+	}
+	assert.Equal(t, []common.Address{valSet.Validators[1].Address, valSet.Validators[3].Address}, precommits.Missing)
+}
@@ -91,7 +91,10 @@ func WALGenerateNBlocks(t *testing.T, wr io.Writer, numBlocks int) (err error) {
 	}
 	txPool := tx_pool.NewTxPool(txConfig, chainConfig, bc)
 	evPool := cstate.EmptyEvidencePool{}
-	bOper := blockchain.NewBlockOperations(log.New("block_operations"), bc, txPool, evPool, stakingUtil)
+	bOper, err := blockchain.NewBlockOperations(log.New("block_operations"), bc, txPool, evPool, stakingUtil)
+	if err != nil {
+		return err
+	}
 	blockExec := cstate.NewBlockExecutor(stateStore, logger, evPool, bOper)
 
 	csCfg := configs.TestConsensusConfig()
@@ -0,0 +1,203 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package consensus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	kos "github.com/kardiachain/go-kardia/lib/os"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// walIndexPattern matches the files rotated out of the head of a WAL group.
+var walIndexPattern = regexp.MustCompile(`\.([0-9]{3,})$`)
+
+// WALEntry is a message decoded from a WAL file along with its position.
+type WALEntry struct {
+	File   string
+	Offset int64
+	Msg    *TimedWALMessage
+}
+
+// String returns a human readable decode of the entry.
+func (e WALEntry) String() string {
+	return fmt.Sprintf("%s:%d %s %s", filepath.Base(e.File), e.Offset,
+		e.Msg.Time.UTC().Format("2006-01-02T15:04:05.000Z"), DescribeWALMessage(e.Msg.Msg))
+}
+
+// WALFileReport is the result of scanning a single WAL file.
+type WALFileReport struct {
+	File     string
+	Size     int64 // Size of the file
+	GoodSize int64 // Offset right after the last good record
+	Entries  int   // Number of good records
+	Err      error // Corruption found after the last good record, if any
+}
+
+// Corrupted reports whether the file has data past its last good record.
+func (r WALFileReport) Corrupted() bool {
+	return r.Err != nil
+}
+
+// DescribeWALMessage returns a human readable description of a WAL message.
+func DescribeWALMessage(msg WALMessage) string {
+	switch m := msg.(type) {
+	case EndHeightMessage:
+		return fmt.Sprintf("#ENDHEIGHT %d", m.Height)
+	case msgInfo:
+		peer := string(m.PeerID)
+		if peer == "" {
+			peer = "self"
+		}
+		return fmt.Sprintf("%T %v from %s", m.Msg, m.Msg, peer)
+	case timeoutInfo:
+		return fmt.Sprintf("timeout %v at %d/%d/%v", m.Duration, m.Height, m.Round, m.Step)
+	case types.EventDataRoundState:
+		return fmt.Sprintf("roundstate %d/%d/%s", m.Height, m.Round, m.Step)
+	default:
+		return fmt.Sprintf("%T %v", msg, msg)
+	}
+}
+
+// WALFiles returns the files of the WAL group whose head is walFile, oldest
+// first.
+func WALFiles(walFile string) ([]string, error) {
+	if _, err := os.Stat(walFile); err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(walFile + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var (
+		rotated []string
+		indexes = make(map[string]int)
+	)
+	for _, match := range matches {
+		submatch := walIndexPattern.FindStringSubmatch(match)
+		if submatch == nil {
+			continue
+		}
+		index, err := strconv.Atoi(submatch[1])
+		if err != nil {
+			return nil, err
+		}
+		rotated = append(rotated, match)
+		indexes[match] = index
+	}
+	sort.Slice(rotated, func(i, j int) bool { return indexes[rotated[i]] < indexes[rotated[j]] })
+	return append(rotated, walFile), nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	rd io.Reader
+	n  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.rd.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// InspectWALFile decodes every record of a single WAL file, calling fn for each
+// good one, until the end of the file or the first corrupted record.
+func InspectWALFile(file string, fn func(WALEntry)) (WALFileReport, error) {
+	report := WALFileReport{File: file}
+	f, err := os.Open(file)
+	if err != nil {
+		return report, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return report, err
+	}
+	report.Size = info.Size()
+
+	rd := &countingReader{rd: f}
+	dec := NewWALDecoder(rd)
+	for {
+		msg, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			report.Err = err
+			break
+		}
+		if fn != nil {
+			fn(WALEntry{File: file, Offset: report.GoodSize, Msg: msg})
+		}
+		report.GoodSize = rd.n
+		report.Entries++
+	}
+	// Don't mistake a short read for the end of the file.
+	if report.Err == nil && report.GoodSize != report.Size {
+		report.Err = DataCorruptionError{fmt.Errorf("%d trailing bytes", report.Size-report.GoodSize)}
+	}
+	return report, nil
+}
+
+// InspectWAL decodes every file of the WAL group whose head is walFile.
+func InspectWAL(walFile string, fn func(WALEntry)) ([]WALFileReport, error) {
+	files, err := WALFiles(walFile)
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]WALFileReport, 0, len(files))
+	for _, file := range files {
+		report, err := InspectWALFile(file, fn)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// RepairWAL truncates every corrupted file of the WAL group whose head is
+// walFile at its last good record. The original content of a repaired file is
+// kept in a ".CORRUPTED" copy next to it. The WAL must not be in use.
+func RepairWAL(walFile string) ([]WALFileReport, error) {
+	reports, err := InspectWAL(walFile, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, report := range reports {
+		if !report.Corrupted() {
+			continue
+		}
+		if err := kos.CopyFile(report.File, report.File+".CORRUPTED"); err != nil {
+			return nil, err
+		}
+		if err := os.Truncate(report.File, report.GoodSize); err != nil {
+			return nil, err
+		}
+	}
+	return reports, nil
+}
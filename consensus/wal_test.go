@@ -267,3 +267,71 @@ func TestWALPeriodicSync(t *testing.T) {
 // func BenchmarkWalDecode1GB(b *testing.B) {
 // 	benchmarkWalDecode(b, 1024*1024*1024)
 // }
+
+func writeWALFile(t *testing.T, file string, msgs []TimedWALMessage) {
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	defer f.Close()
+	enc := NewWALEncoder(f)
+	for i := range msgs {
+		require.NoError(t, enc.Encode(&msgs[i]))
+	}
+}
+
+func TestWALRepair(t *testing.T) {
+	walDir, err := ioutil.TempDir("", "wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(walDir)
+	walFile := filepath.Join(walDir, "wal")
+
+	now := ktime.Now()
+	msgs := []TimedWALMessage{
+		{Time: now, Msg: EndHeightMessage{1}},
+		{Time: now, Msg: timeoutInfo{Duration: time.Second, Height: 2, Round: 0, Step: types.RoundStepPropose}},
+		{Time: now, Msg: ktypes.EventDataRoundState{Height: 2, Round: 0, Step: "RoundStepPrevote"}},
+	}
+	writeWALFile(t, walFile+".000", msgs[:1])
+	writeWALFile(t, walFile, msgs[1:])
+	goodInfo, err := os.Stat(walFile)
+	require.NoError(t, err)
+
+	// Leftovers of a previous repair are not part of the group
+	require.NoError(t, ioutil.WriteFile(walFile+".CORRUPTED", []byte("garbage"), 0600))
+
+	files, err := WALFiles(walFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{walFile + ".000", walFile}, files)
+
+	// Simulate a write torn in the middle of the record
+	f, err := os.OpenFile(walFile, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0x1, 0x2, 0x3, 0x4, 0x0, 0x0, 0x0, 0x10, 0xff})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	var entries []WALEntry
+	reports, err := InspectWAL(walFile, func(entry WALEntry) { entries = append(entries, entry) })
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.False(t, reports[0].Corrupted())
+	assert.True(t, reports[1].Corrupted())
+	assert.True(t, IsDataCorruptionError(reports[1].Err))
+	assert.Equal(t, goodInfo.Size(), reports[1].GoodSize)
+	require.Len(t, entries, 3)
+	for i, entry := range entries {
+		assert.Equal(t, msgs[i].Msg, entry.Msg.Msg)
+	}
+	assert.Contains(t, entries[0].String(), "#ENDHEIGHT 1")
+
+	_, err = RepairWAL(walFile)
+	require.NoError(t, err)
+	assert.FileExists(t, walFile+".CORRUPTED")
+
+	reports, err = InspectWAL(walFile, nil)
+	require.NoError(t, err)
+	for _, report := range reports {
+		assert.False(t, report.Corrupted(), report.File)
+	}
+	assert.Equal(t, 2, reports[1].Entries)
+	assert.Equal(t, goodInfo.Size(), reports[1].Size)
+}
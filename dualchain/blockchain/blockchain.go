@@ -57,7 +57,7 @@ type DualBlockChain struct {
 	db types.StoreDB // Kai's database
 	hc *DualHeaderChain
 
-	chainHeadFeed event.Feed
+	chainHeadFeed events.ChainHeadFeed
 	scope         event.SubscriptionScope
 
 	genesisBlock *types.Block
@@ -21,6 +21,7 @@ package service
 import (
 	"github.com/kardiachain/go-kardia/configs"
 	"github.com/kardiachain/go-kardia/dualchain/event_pool"
+	"github.com/kardiachain/go-kardia/kai/chainfeed"
 	"github.com/kardiachain/go-kardia/kai/storage"
 	"github.com/kardiachain/go-kardia/mainchain/genesis"
 )
@@ -48,4 +49,6 @@ type DualConfig struct {
 	Consensus *configs.ConsensusConfig
 
 	FastSync *configs.FastSyncConfig
+
+	ChainFeed *chainfeed.Config
 }
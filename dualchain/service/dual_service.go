@@ -24,6 +24,10 @@ import (
 	"github.com/kardiachain/go-kardia/consensus"
 	"github.com/kardiachain/go-kardia/dualchain/blockchain"
 	"github.com/kardiachain/go-kardia/dualchain/event_pool"
+	"github.com/kardiachain/go-kardia/dualnode"
+	bridgeapi "github.com/kardiachain/go-kardia/dualnode/api"
+	"github.com/kardiachain/go-kardia/dualnode/proposal"
+	"github.com/kardiachain/go-kardia/kai/chainfeed"
 	"github.com/kardiachain/go-kardia/kai/state/cstate"
 	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/log"
@@ -58,6 +62,11 @@ type DualService struct {
 	csManager           *consensus.ConsensusManager
 	dualBlockOperations *blockchain.DualBlockOperations
 	bcR                 p2p.Reactor // for fast-syncing
+	chainFeed           *chainfeed.Feed
+
+	// Bridge served in the bridge RPC namespace, nil unless attached by SetBridge
+	bridgeState    *proposal.State
+	bridgeAdapters []dualnode.ChainAdapter
 
 	networkID uint64
 }
@@ -95,6 +104,12 @@ func newDualService(ctx *node.ServiceContext, config *DualConfig) (*DualService,
 	}
 
 	dualService.eventPool = event_pool.NewPool(logger, config.DualEventPool, dualService.blockchain)
+	if config.ChainFeed != nil {
+		dualService.chainFeed, err = chainfeed.NewFromConfig(logger, *config.ChainFeed, dualService.blockchain, dualService.chainConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	lastBlockState, err := ctx.StateDB.LoadStateFromDBOrGenesisDoc(config.DualGenesis)
 	if err != nil {
@@ -117,7 +132,7 @@ func newDualService(ctx *node.ServiceContext, config *DualConfig) (*DualService,
 	// app may modify the validator set, specifying ourself as the only validator.
 	config.FastSync.Enable = config.FastSync.Enable && !onlyValidatorIsUs(lastBlockState, privValidator.GetAddress())
 	// Make BlockchainReactor. Don't start fast sync if we're doing a state sync first.
-	bcR := bcReactor.NewBlockchainReactor(lastBlockState, blockExec, dualService.dualBlockOperations, config.FastSync)
+	bcR := bcReactor.NewBlockchainReactor(lastBlockState, blockExec, dualService.dualBlockOperations, ctx.StateDB, config.FastSync)
 	dualService.bcR = bcR
 
 	consensusState := consensus.NewConsensusState(
@@ -147,6 +162,7 @@ func NewDualService(ctx *node.ServiceContext) (node.Service, error) {
 		DualGenesis:   chainConfig.DualGenesis,
 		Consensus:     chainConfig.Consensus,
 		FastSync:      chainConfig.FastSync,
+		ChainFeed:     chainConfig.ChainFeed,
 	})
 
 	if err != nil {
@@ -160,6 +176,13 @@ func (s *DualService) SetDualBlockChainManager(bcManager *blockchain.DualBlockCh
 	s.dualBlockOperations.SetDualBlockChainManager(bcManager)
 }
 
+// SetBridge attaches the proposal state and the adapters of the external chains of the bridge, whose APIs are then
+// served in the bridge namespace. Must be called before the node starts.
+func (s *DualService) SetBridge(state *proposal.State, adapters []dualnode.ChainAdapter) {
+	s.bridgeState = state
+	s.bridgeAdapters = adapters
+}
+
 func (s *DualService) IsListening() bool  { return true } // Always listening
 func (s *DualService) NetVersion() uint64 { return s.networkID }
 func (s *DualService) DB() types.StoreDB  { return s.groupDb }
@@ -174,6 +197,9 @@ func onlyValidatorIsUs(state cstate.LatestBlockState, privValAddress common.Addr
 // Start implements Service, starting all internal goroutines needed by the
 // Kardia protocol implementation.
 func (s *DualService) Start(srvr *p2p.Switch) error {
+	if s.chainFeed != nil {
+		s.chainFeed.Start()
+	}
 	return nil
 }
 
@@ -181,6 +207,11 @@ func (s *DualService) Start(srvr *p2p.Switch) error {
 // Kardia protocol.
 func (s *DualService) Stop() error {
 	s.csManager.Stop()
+	if s.chainFeed != nil {
+		if err := s.chainFeed.Stop(); err != nil {
+			s.logger.Error("Failed to stop chain feed", "err", err)
+		}
+	}
 
 	close(s.shutdownChan)
 
@@ -188,7 +219,7 @@ func (s *DualService) Stop() error {
 }
 
 func (s *DualService) APIs() []rpc.API {
-	return []rpc.API{
+	apis := []rpc.API{
 		{
 			Namespace: "dual",
 			Version:   "1.0",
@@ -196,6 +227,10 @@ func (s *DualService) APIs() []rpc.API {
 			Public:    true,
 		},
 	}
+	if s.bridgeState != nil {
+		apis = append(apis, bridgeapi.APIs(s.bridgeState, s.bridgeAdapters)...)
+	}
+	return apis
 }
 
 func (s *DualService) EventPool() *event_pool.Pool            { return s.eventPool }
@@ -0,0 +1,272 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package api exposes the state of the bridge of a dual node in the bridge RPC namespace.
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/dualnode/proposal"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/rpc"
+)
+
+// DepositJSON is a deposit released by a proposal in JSON format.
+type DepositJSON struct {
+	Chain       string `json:"chain"`
+	TxHash      string `json:"txHash"`
+	LogIndex    uint   `json:"logIndex"`
+	BlockNumber uint64 `json:"blockNumber"`
+	Token       string `json:"token"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Amount      string `json:"amount"`
+	TokenID     string `json:"tokenId,omitempty"`
+}
+
+// ProposalJSON is the progress of a proposal in JSON format.
+type ProposalJSON struct {
+	Hash             string         `json:"hash"`
+	Type             string         `json:"type,omitempty"` // empty while only votes of the proposal are known
+	SourceChain      string         `json:"sourceChain,omitempty"`
+	DestChain        string         `json:"destChain,omitempty"`
+	Deposit          *DepositJSON   `json:"deposit,omitempty"`
+	BatchRoot        string         `json:"batchRoot,omitempty"`
+	Batch            []*DepositJSON `json:"batch,omitempty"` // deposits released by a batch
+	Status           string         `json:"status"`
+	Height           uint64         `json:"height"`
+	FinalHeight      uint64         `json:"finalHeight,omitempty"`
+	ExecutedTx       string         `json:"executedTx,omitempty"`
+	Votes            int            `json:"votes"`
+	Voters           []string       `json:"voters"`
+	VotingPower      int64          `json:"votingPower"`
+	TotalVotingPower int64          `json:"totalVotingPower"`
+	Invalidations    int            `json:"invalidations"`
+}
+
+// ProcessedDepositJSON is the proposal created for a deposit in JSON format.
+type ProcessedDepositJSON struct {
+	ProposalHash string        `json:"proposalHash"`
+	Height       uint64        `json:"height"`
+	Proposal     *ProposalJSON `json:"proposal"` // nil if the proposal is pruned
+}
+
+// ValidatorJSON is a bridge validator in JSON format.
+type ValidatorJSON struct {
+	Address     string `json:"address"`
+	VotingPower int64  `json:"votingPower"`
+}
+
+// HealthJSON is the health of an adapter in JSON format.
+type HealthJSON struct {
+	Chain      string    `json:"chain"`
+	Healthy    bool      `json:"healthy"`
+	Syncing    bool      `json:"syncing"`
+	Head       uint64    `json:"head"`
+	Scanned    uint64    `json:"scanned"`
+	HeadAge    string    `json:"headAge"`
+	PendingTxs int       `json:"pendingTxs"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+// HeightsJSON is the last block processed on each chain in JSON format.
+type HeightsJSON struct {
+	Height uint64            `json:"height"` // height of the proposal state on Kardia
+	Chains map[string]uint64 `json:"chains"` // latest block whose deposits are processed by each external chain
+}
+
+// PauseStatusJSON is the pause status of a chain in JSON format.
+type PauseStatusJSON struct {
+	Chain        string `json:"chain"` // empty if all chains are paused
+	Paused       bool   `json:"paused"`
+	Reason       string `json:"reason"`
+	Nonce        uint64 `json:"nonce"`
+	ProposalHash string `json:"proposalHash"`
+	Height       uint64 `json:"height"`
+}
+
+// PublicDualAPI provides APIs to access the state of the bridge: proposals and their votes, processed deposits,
+// bridge validators and adapters of external chains.
+type PublicDualAPI struct {
+	state    *proposal.State
+	adapters []dualnode.ChainAdapter
+}
+
+// NewPublicDualAPI creates a new API of the bridge.
+func NewPublicDualAPI(state *proposal.State, adapters []dualnode.ChainAdapter) *PublicDualAPI {
+	return &PublicDualAPI{state: state, adapters: adapters}
+}
+
+// Namespace is the RPC namespace of the bridge APIs, the dual namespace is served by the dual chain.
+const Namespace = "bridge"
+
+// APIs returns the APIs of the bridge, exposed in the bridge namespace.
+func APIs(state *proposal.State, adapters []dualnode.ChainAdapter) []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: Namespace,
+			Version:   "1.0",
+			Service:   NewPublicDualAPI(state, adapters),
+			Public:    true,
+		},
+	}
+}
+
+func (api *PublicDualAPI) newProposalJSON(info *proposal.ProposalInfo) *ProposalJSON {
+	result := &ProposalJSON{
+		Hash:             info.Hash.Hex(),
+		Status:           info.Status.String(),
+		Height:           info.Height,
+		FinalHeight:      info.FinalHeight,
+		ExecutedTx:       info.ExecutedTx,
+		Votes:            len(info.Votes),
+		Voters:           make([]string, len(info.Votes)),
+		VotingPower:      info.VotingPower,
+		TotalVotingPower: api.state.Validators().TotalVotingPower(),
+		Invalidations:    info.Invalidations,
+	}
+	for i, v := range info.Votes {
+		result.Voters[i] = v.ValidatorAddress.Hex()
+	}
+	if p := info.Proposal; p != nil {
+		result.Type = p.Type.String()
+		result.SourceChain = p.SourceChain
+		result.DestChain = p.DestChain
+		if d, err := p.Deposit(); err == nil {
+			result.Deposit = newDepositJSON(d)
+		}
+		if b, err := p.Batch(); err == nil {
+			result.BatchRoot = common.Encode(b.Root)
+			result.Batch = make([]*DepositJSON, len(b.Deposits))
+			for i, d := range b.Deposits {
+				result.Batch[i] = newDepositJSON(d)
+			}
+		}
+	}
+	return result
+}
+
+func newDepositJSON(d *dualnode.Deposit) *DepositJSON {
+	result := &DepositJSON{
+		Chain:       d.Chain,
+		TxHash:      d.TxHash,
+		LogIndex:    d.LogIndex,
+		BlockNumber: d.BlockNumber,
+		Token:       d.Token,
+		From:        d.From,
+		To:          d.To,
+		Amount:      d.Amount.String(),
+	}
+	if d.TokenID != nil {
+		result.TokenID = d.TokenID.String()
+	}
+	return result
+}
+
+// PendingProposals returns the proposals which are not executed, expired or cancelled, oldest first.
+func (api *PublicDualAPI) PendingProposals() []*ProposalJSON {
+	pending := api.state.Pending()
+	results := make([]*ProposalJSON, len(pending))
+	for i, info := range pending {
+		results[i] = api.newProposalJSON(info)
+	}
+	return results
+}
+
+// GetProposal returns the proposal with the given hash and its votes.
+func (api *PublicDualAPI) GetProposal(hash string) (*ProposalJSON, error) {
+	info, ok := api.state.Proposal(common.HexToHash(hash))
+	if !ok {
+		return nil, fmt.Errorf("%w %v", proposal.ErrUnknownProposal, hash)
+	}
+	return api.newProposalJSON(info), nil
+}
+
+// GetProcessedDeposit returns the proposal created for the deposit at logIndex of transaction txHash on chain, or
+// nil if the deposit hasn't been processed.
+func (api *PublicDualAPI) GetProcessedDeposit(chain string, txHash string, logIndex uint) (*ProcessedDepositJSON, error) {
+	processed, err := api.state.ProcessedDeposit(chain, txHash, logIndex)
+	if err != nil || processed == nil {
+		return nil, err
+	}
+	result := &ProcessedDepositJSON{ProposalHash: processed.ProposalHash.Hex(), Height: processed.Height}
+	if info, ok := api.state.Proposal(processed.ProposalHash); ok {
+		result.Proposal = api.newProposalJSON(info)
+	}
+	return result, nil
+}
+
+// BridgeValidators returns the validators voting for proposals.
+func (api *PublicDualAPI) BridgeValidators() []*ValidatorJSON {
+	vals := api.state.Validators().Validators
+	results := make([]*ValidatorJSON, len(vals))
+	for i, val := range vals {
+		results[i] = &ValidatorJSON{Address: val.Address.Hex(), VotingPower: val.VotingPower}
+	}
+	return results
+}
+
+// AdapterHealth returns the health of the adapter of each external chain.
+func (api *PublicDualAPI) AdapterHealth(ctx context.Context) []*HealthJSON {
+	results := make([]*HealthJSON, len(api.adapters))
+	for i, adapter := range api.adapters {
+		health := adapter.Health(ctx)
+		results[i] = &HealthJSON{
+			Chain:      health.Chain,
+			Healthy:    health.Healthy,
+			Syncing:    health.Syncing,
+			Head:       health.Head,
+			Scanned:    health.Scanned,
+			HeadAge:    health.HeadAge.String(),
+			PendingTxs: health.PendingTxs,
+			Error:      health.Error,
+			CheckedAt:  health.CheckedAt,
+		}
+	}
+	return results
+}
+
+// ProcessedHeights returns the height of the proposal state and the latest block processed on each external chain.
+func (api *PublicDualAPI) ProcessedHeights(ctx context.Context) *HeightsJSON {
+	result := &HeightsJSON{Height: api.state.Height(), Chains: make(map[string]uint64, len(api.adapters))}
+	for _, adapter := range api.adapters {
+		result.Chains[adapter.Name()] = adapter.Health(ctx).Scanned
+	}
+	return result
+}
+
+// PauseStatus returns whether the transfers of chain are paused, and the proposal pausing or resuming them.
+func (api *PublicDualAPI) PauseStatus(chain string) *PauseStatusJSON {
+	status := api.state.PauseStatus(chain)
+	result := &PauseStatusJSON{
+		Chain:  status.Chain,
+		Paused: status.Paused,
+		Reason: status.Reason,
+		Nonce:  status.Nonce,
+		Height: status.Height,
+	}
+	if status.ProposalHash != (common.Hash{}) {
+		result.ProposalHash = status.ProposalHash.Hex()
+	}
+	return result
+}
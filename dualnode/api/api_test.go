@@ -0,0 +1,164 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/dualnode/proposal"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/rpc"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// fakeAdapter implements the health of a chain adapter.
+type fakeAdapter struct {
+	dualnode.ChainAdapter
+	name    string
+	scanned uint64
+}
+
+func (a *fakeAdapter) Name() string { return a.name }
+
+func (a *fakeAdapter) Health(ctx context.Context) *dualnode.Health {
+	return &dualnode.Health{Chain: a.name, Healthy: true, Head: a.scanned + 12, Scanned: a.scanned}
+}
+
+func newTestAPI(t *testing.T) (*PublicDualAPI, *proposal.State, []*ecdsa.PrivateKey) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	vals := make([]*types.Validator, len(keys))
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		keys[i] = key
+		vals[i] = types.NewValidator(crypto.PubkeyToAddress(key.PublicKey), 10)
+	}
+	pool, err := proposal.NewPool(memorydb.New(), 0)
+	require.NoError(t, err)
+	state, err := proposal.NewState(pool, crypto.PubkeyToAddress(keys[0].PublicKey), types.NewValidatorSet(vals), 0)
+	require.NoError(t, err)
+	adapters := []dualnode.ChainAdapter{&fakeAdapter{name: "ETH", scanned: 100}, &fakeAdapter{name: "BSC", scanned: 200}}
+	return NewPublicDualAPI(state, adapters), state, keys
+}
+
+func TestPublicDualAPI_proposals(t *testing.T) {
+	api, state, keys := newTestAPI(t)
+	require.NoError(t, state.Update(7))
+	d := &dualnode.Deposit{
+		Chain:       "ETH",
+		TxHash:      "0x01",
+		LogIndex:    2,
+		BlockNumber: 10,
+		Token:       "0x0A",
+		From:        "0x0B",
+		To:          "0x0C",
+		Amount:      big.NewInt(100),
+	}
+	require.Nil(t, mustProcessedDeposit(t, api, d))
+	p, _, err := state.ProposeDeposit(d, "KAI")
+	require.NoError(t, err)
+	v, err := proposal.SignVote(p.Hash(), keys[1])
+	require.NoError(t, err)
+	_, err = state.AddVote(v)
+	require.NoError(t, err)
+
+	pending := api.PendingProposals()
+	require.Len(t, pending, 1)
+	require.Equal(t, p.Hash().Hex(), pending[0].Hash)
+	require.Equal(t, "ETH", pending[0].SourceChain)
+	require.Equal(t, "100", pending[0].Deposit.Amount)
+	require.Equal(t, uint64(7), pending[0].Height)
+	require.Equal(t, []string{crypto.PubkeyToAddress(keys[1].PublicKey).Hex()}, pending[0].Voters)
+	require.Equal(t, int64(10), pending[0].VotingPower)
+	require.Equal(t, int64(40), pending[0].TotalVotingPower)
+
+	got, err := api.GetProposal(p.Hash().Hex())
+	require.NoError(t, err)
+	require.Equal(t, pending[0], got)
+	_, err = api.GetProposal("0x02")
+	require.True(t, errors.Is(err, proposal.ErrUnknownProposal))
+
+	processed := mustProcessedDeposit(t, api, d)
+	require.Equal(t, p.Hash().Hex(), processed.ProposalHash)
+	require.Equal(t, got, processed.Proposal)
+}
+
+func mustProcessedDeposit(t *testing.T, api *PublicDualAPI, d *dualnode.Deposit) *ProcessedDepositJSON {
+	processed, err := api.GetProcessedDeposit(d.Chain, d.TxHash, d.LogIndex)
+	require.NoError(t, err)
+	return processed
+}
+
+func TestPublicDualAPI_adapters(t *testing.T) {
+	api, state, keys := newTestAPI(t)
+	require.NoError(t, state.Update(9))
+
+	vals := api.BridgeValidators()
+	require.Len(t, vals, len(keys))
+	require.Equal(t, int64(10), vals[0].VotingPower)
+
+	health := api.AdapterHealth(context.Background())
+	require.Len(t, health, 2)
+	require.Equal(t, "BSC", health[1].Chain)
+	require.Equal(t, uint64(212), health[1].Head)
+
+	heights := api.ProcessedHeights(context.Background())
+	require.Equal(t, uint64(9), heights.Height)
+	require.Equal(t, map[string]uint64{"ETH": 100, "BSC": 200}, heights.Chains)
+
+	require.False(t, api.PauseStatus("ETH").Paused)
+	require.Empty(t, api.PauseStatus("ETH").ProposalHash)
+}
+
+func TestAPIs_rpc(t *testing.T) {
+	api, state, keys := newTestAPI(t)
+	require.NoError(t, state.Update(9))
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	for _, a := range APIs(api.state, api.adapters) {
+		require.NoError(t, server.RegisterName(a.Namespace, a.Service))
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	var heights HeightsJSON
+	require.NoError(t, client.Call(&heights, "bridge_processedHeights"))
+	require.Equal(t, uint64(9), heights.Height)
+	require.Equal(t, map[string]uint64{"ETH": 100, "BSC": 200}, heights.Chains)
+
+	var vals []*ValidatorJSON
+	require.NoError(t, client.Call(&vals, "bridge_bridgeValidators"))
+	require.Len(t, vals, len(keys))
+
+	var pending []*ProposalJSON
+	require.NoError(t, client.Call(&pending, "bridge_pendingProposals"))
+	require.Empty(t, pending)
+
+	// The dual namespace belongs to the dual chain.
+	require.Error(t, client.Call(&heights, "dual_processedHeights"))
+}
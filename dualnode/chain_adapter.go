@@ -0,0 +1,119 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dualnode
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/kardiachain/go-kardia/lib/event"
+)
+
+// Deposit is a transfer to the dual node observed on an external chain. Addresses and hashes are encoded in the
+// external chain's format.
+type Deposit struct {
+	Chain       string   // name of the external chain (eg: ETH, NEO, TRX)
+	TxHash      string   // hash of the transaction containing the deposit
+	LogIndex    uint     // index of the deposit in the transaction's block, used to distinguish deposits of a transaction
+	BlockNumber uint64   // block containing the transaction
+	Token       string   // token contract, empty for the chain's native coin
+	From        string   // depositor
+	To          string   // deposit address watched by the adapter
+	Amount      *big.Int // deposited amount in the token's smallest unit, 1 for an NFT
+	Removed     bool     `rlp:"-"` // the deposit was sent before and is reorganised out of the chain
+
+	// NFT deposits
+	TokenID  *big.Int `rlp:"optional"` // id of the deposited NFT of Token, nil for fungible tokens
+	TokenURI string   `rlp:"optional"` // metadata URI of the NFT, passed through to the NFT minted for it
+}
+
+// Release is a transfer from the dual node which is submitted to an external chain.
+type Release struct {
+	ID     string   // unique id of the release, submitting a release with the same id twice returns its first transaction
+	Token  string   // token contract, empty for the chain's native coin
+	To     string   // receiver
+	Amount *big.Int // released amount in the token's smallest unit
+
+	// NFT releases
+	TokenID  *big.Int // id of the NFT of Token minted or unlocked, nil for fungible tokens
+	TokenURI string   // metadata URI of a minted NFT
+
+	// batch releases, Amount is the total of the batch and To is unused
+	BatchRoot []byte // Merkle root of the deposits of a batch, claimed by each receiver with a proof of its deposit
+}
+
+// Finality is the finality of a transaction on an external chain.
+type Finality struct {
+	TxHash        string
+	Included      bool   // transaction is included in a block of the canonical chain
+	Succeeded     bool   // transaction is executed successfully, only relevant if it is included
+	BlockNumber   uint64 // block containing the transaction, only relevant if it is included
+	Confirmations uint64 // number of blocks on top of the transaction's block including it
+	Final         bool   // confirmations reached the adapter's confirmation depth
+}
+
+// Health is the status of an adapter's connection to its external chain.
+type Health struct {
+	Chain      string
+	Healthy    bool
+	Syncing    bool          // node of the external chain is still syncing
+	Head       uint64        // latest block number known by the node
+	Scanned    uint64        // latest block whose deposits are processed by the adapter
+	HeadAge    time.Duration // time since the latest block was produced
+	PendingTxs int           // releases submitted but not final yet
+	Error      string        // reason why the adapter is not healthy if any
+	CheckedAt  time.Time     // time the health is checked
+}
+
+// ChainAdapter connects the dual node to an external chain: it watches deposits made to the dual node, submits
+// releases, and reports finality of transactions and health of the connection.
+type ChainAdapter interface {
+	// Name returns name of the external chain (eg: ETH, NEO, TRX)
+	Name() string
+
+	// Start connects to the external chain and starts watching deposits and pending releases.
+	Start() error
+
+	// Stop stops the adapter, subscriptions returned by WatchDeposits are closed.
+	Stop() error
+
+	// WatchDeposits sends deposits which reached the confirmation depth to sink. A deposit which is reorganised out
+	// of the chain after it has been sent is sent again with Removed set.
+	WatchDeposits(sink chan<- *Deposit) event.Subscription
+
+	// SubmitRelease sends a transaction executing release and returns its hash. The transaction is resubmitted
+	// if it is dropped before being included.
+	SubmitRelease(ctx context.Context, release *Release) (string, error)
+
+	// Finality returns the finality of the transaction txHash. If txHash is a release which has been resubmitted,
+	// the finality of its included transaction is returned.
+	Finality(ctx context.Context, txHash string) (*Finality, error)
+
+	// Health returns the status of the connection to the external chain.
+	Health(ctx context.Context) *Health
+}
+
+// DepositReader is implemented by adapters which look up the deposits of a transaction, to verify evidence of bridge
+// validators releasing deposits which don't exist.
+type DepositReader interface {
+	// Deposits returns the deposits of the transaction txHash, nil if it isn't included or failed. An error is
+	// returned if the transaction hasn't reached the confirmation depth.
+	Deposits(ctx context.Context, txHash string) ([]*Deposit, error)
+}
@@ -0,0 +1,85 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"sync"
+
+	"github.com/kardiachain/go-kardia/dualnode/evm_adapter"
+)
+
+// ChainPreset is the default configuration of a known EVM chain, it is applied to chains enabled in config which
+// have the same chain id.
+type ChainPreset struct {
+	Name               string
+	ConfirmationDepth  uint64
+	GasStrategy        string
+	GasPrice           uint64 // wei
+	GasPriceMultiplier uint64 // percent
+}
+
+var (
+	presetsMtx   sync.RWMutex
+	chainPresets = map[int64]ChainPreset{
+		1:     {Name: "ETH", ConfirmationDepth: 12, GasStrategy: evm_adapter.GasStrategySuggested},
+		4:     {Name: "ETH-RINKEBY", ConfirmationDepth: 12, GasStrategy: evm_adapter.GasStrategySuggested},
+		56:    {Name: "BSC", ConfirmationDepth: 15, GasStrategy: evm_adapter.GasStrategyFixed, GasPrice: 5000000000},
+		97:    {Name: "BSC-TESTNET", ConfirmationDepth: 15, GasStrategy: evm_adapter.GasStrategyFixed, GasPrice: 10000000000},
+		137:   {Name: "MATIC", ConfirmationDepth: 128, GasStrategy: evm_adapter.GasStrategyMultiplier, GasPriceMultiplier: 120},
+		80001: {Name: "MATIC-MUMBAI", ConfirmationDepth: 128, GasStrategy: evm_adapter.GasStrategyMultiplier, GasPriceMultiplier: 120},
+	}
+)
+
+// RegisterChain adds or replaces the preset of chainId.
+func RegisterChain(chainId int64, preset ChainPreset) {
+	presetsMtx.Lock()
+	defer presetsMtx.Unlock()
+	chainPresets[chainId] = preset
+}
+
+// Chain returns the preset of chainId.
+func Chain(chainId int64) (ChainPreset, bool) {
+	presetsMtx.RLock()
+	defer presetsMtx.RUnlock()
+	preset, ok := chainPresets[chainId]
+	return preset, ok
+}
+
+// applyPreset sets fields of c which are not configured with the preset of its chain id if any.
+func applyPreset(c *evm_adapter.Config) {
+	preset, ok := Chain(c.ChainId)
+	if !ok {
+		return
+	}
+	if c.Name == "" {
+		c.Name = preset.Name
+	}
+	if c.ConfirmationDepth == 0 {
+		c.ConfirmationDepth = preset.ConfirmationDepth
+	}
+	if c.GasStrategy == "" {
+		c.GasStrategy = preset.GasStrategy
+		if c.GasPrice == 0 {
+			c.GasPrice = preset.GasPrice
+		}
+		if c.GasPriceMultiplier == 0 {
+			c.GasPriceMultiplier = preset.GasPriceMultiplier
+		}
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package config loads external chains enabled for the dual node.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/dualnode/evm_adapter"
+	"github.com/kardiachain/go-kardia/dualnode/proposal"
+	"github.com/kardiachain/go-kardia/dualnode/token_registry"
+	"github.com/kardiachain/go-kardia/dualnode/valset_sync"
+	"github.com/kardiachain/go-kardia/dualnode/watchtower"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+)
+
+type (
+	Config struct {
+		Chains     []*evm_adapter.Config     `yaml:"Chains"`     // enabled EVM chains, presets of known chain ids are applied
+		Tokens     []*token_registry.Mapping `yaml:"Tokens"`     // genesis token mappings, see token_registry.Registry.Init
+		RateLimits []*proposal.RateLimit     `yaml:"RateLimits"` // limits of transfers, see proposal.State.SetRateLimits
+		Fees       []*proposal.Fee           `yaml:"Fees"`       // fees of transfers, see proposal.State.SetFees
+		Batching   []*proposal.Batching      `yaml:"Batching"`   // assets whose small deposits are batched, see proposal.Batcher
+		Treasuries map[string]string         `yaml:"Treasuries"` // treasury address of each chain receiving withdrawn fees
+		Watchtower *watchtower.Config        `yaml:"Watchtower"` // runs the node as a watchtower which never votes if set
+		Validators *valset_sync.Config       `yaml:"Validators"` // epochs of the bridge validator set read from staking
+	}
+)
+
+// Load attempts to load the config from given path and filename.
+func Load(path string, name string) (*Config, error) {
+	filename := fmt.Sprintf("%s.yml", name)
+	configPath := filepath.Join(path, filename)
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "Unable to load config")
+	}
+	configData, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read config")
+	}
+
+	config := Config{}
+	if err := yaml.Unmarshal(configData, &config); err != nil {
+		return nil, errors.Wrap(err, "Problem unmarshaling config yaml data")
+	}
+	return &config, nil
+}
+
+// Adapters creates an adapter for each enabled chain. Chains must have distinct names and chain ids.
+func (c *Config) Adapters() ([]dualnode.ChainAdapter, error) {
+	names := make(map[string]struct{}, len(c.Chains))
+	chainIds := make(map[int64]struct{}, len(c.Chains))
+	adapters := make([]dualnode.ChainAdapter, 0, len(c.Chains))
+	for _, chain := range c.Chains {
+		applyPreset(chain)
+		if _, ok := names[chain.Name]; ok {
+			return nil, fmt.Errorf("chain %v is enabled twice", chain.Name)
+		}
+		if _, ok := chainIds[chain.ChainId]; ok {
+			return nil, fmt.Errorf("chain id %v is enabled twice", chain.ChainId)
+		}
+		names[chain.Name] = struct{}{}
+		chainIds[chain.ChainId] = struct{}{}
+
+		adapter, err := evm_adapter.NewEvmAdapter(chain)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to create adapter of chain id %v", chain.ChainId)
+		}
+		adapters = append(adapters, adapter)
+	}
+	return adapters, nil
+}
+
+// TokenRegistry returns the token registry stored in db, it is initialized with Tokens if it is empty.
+func (c *Config) TokenRegistry(db kaidb.Database) (*token_registry.Registry, error) {
+	registry, err := token_registry.New(db)
+	if err != nil {
+		return nil, err
+	}
+	if err := registry.Init(c.Tokens); err != nil {
+		return nil, errors.Wrap(err, "Unable to load token mappings")
+	}
+	return registry, nil
+}
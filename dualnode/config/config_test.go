@@ -0,0 +1,130 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/dualnode/evm_adapter"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+)
+
+const testConfig = `
+Chains:
+  - ChainId: 56
+    RPCEndpoint: ws://127.0.0.1:8546
+    SignedTxPrivateKey: 8843ebcb1021b00ae9a644db6617f9c6d870e5fd53624cefe374c1d2d710fd06
+    BridgeContract: "0x000000000000000000000000000000000000000D"
+  - ChainId: 137
+    RPCEndpoint: ws://127.0.0.1:8547
+    SignedTxPrivateKey: 8843ebcb1021b00ae9a644db6617f9c6d870e5fd53624cefe374c1d2d710fd06
+    BridgeContract: "0x000000000000000000000000000000000000000E"
+    ConfirmationDepth: 256
+Tokens:
+  - Source: {Chain: BSC, Address: "0x55d398326f99059fF775485246999027B3197955", Decimals: 18}
+    Dest: {Chain: KAI, Address: "0x00000000000000000000000000000000000000AA", Decimals: 6}
+RateLimits:
+  - Chain: BSC
+    Token: "0x55d398326f99059fF775485246999027B3197955"
+    MaxAmount: "1000000000000000000000000"
+    Window: 28800
+Fees:
+  - Chain: BSC
+    Token: "0x55d398326f99059fF775485246999027B3197955"
+    Flat: "1000000000000000000"
+    BasisPoints: 10
+Treasuries:
+  KAI: "0x00000000000000000000000000000000000000Fe"
+`
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dualnode-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "chains.yml"), []byte(testConfig), 0644))
+
+	config, err := Load(dir, "chains")
+	require.NoError(t, err)
+	require.Len(t, config.Chains, 2)
+
+	adapters, err := config.Adapters()
+	require.NoError(t, err)
+	require.Len(t, adapters, 2)
+	require.Equal(t, "BSC", adapters[0].Name())
+	require.Equal(t, "MATIC", adapters[1].Name())
+
+	bsc := config.Chains[0]
+	require.Equal(t, uint64(15), bsc.ConfirmationDepth)
+	require.Equal(t, evm_adapter.GasStrategyFixed, bsc.GasStrategy)
+	require.Equal(t, uint64(5000000000), bsc.GasPrice)
+
+	// configured fields are not overridden by presets
+	matic := config.Chains[1]
+	require.Equal(t, uint64(256), matic.ConfirmationDepth)
+	require.Equal(t, evm_adapter.GasStrategyMultiplier, matic.GasStrategy)
+
+	registry, err := config.TokenRegistry(memorydb.New())
+	require.NoError(t, err)
+	dest, amount, err := registry.Convert("BSC", "0x55d398326f99059ff775485246999027b3197955", "KAI", big.NewInt(1e18))
+	require.NoError(t, err)
+	require.Equal(t, uint8(6), dest.Decimals)
+	require.Equal(t, int64(1e6), amount.Int64())
+
+	require.Len(t, config.RateLimits, 1)
+	require.Equal(t, "1000000000000000000000000", config.RateLimits[0].MaxAmount)
+	require.Equal(t, uint64(28800), config.RateLimits[0].Window)
+	require.Len(t, config.Fees, 1)
+	require.Equal(t, uint64(10), config.Fees[0].BasisPoints)
+	require.Equal(t, "0x00000000000000000000000000000000000000Fe", config.Treasuries["KAI"])
+
+	_, err = Load(dir, "missing")
+	require.Error(t, err)
+}
+
+func TestAdapters_errors(t *testing.T) {
+	chain := func(chainId int64, name string) *evm_adapter.Config {
+		return &evm_adapter.Config{
+			Name:               name,
+			ChainId:            chainId,
+			RPCEndpoint:        "ws://127.0.0.1:8546",
+			SignedTxPrivateKey: "8843ebcb1021b00ae9a644db6617f9c6d870e5fd53624cefe374c1d2d710fd06",
+			DepositAddress:     "0x000000000000000000000000000000000000000B",
+		}
+	}
+
+	// unknown chain ids must be named
+	_, err := (&Config{Chains: []*evm_adapter.Config{chain(1337, "")}}).Adapters()
+	require.Error(t, err)
+
+	RegisterChain(1337, ChainPreset{Name: "LOCAL", ConfirmationDepth: 1})
+	adapters, err := (&Config{Chains: []*evm_adapter.Config{chain(1337, "")}}).Adapters()
+	require.NoError(t, err)
+	require.Equal(t, "LOCAL", adapters[0].Name())
+
+	_, err = (&Config{Chains: []*evm_adapter.Config{chain(56, ""), chain(56, "BSC2")}}).Adapters()
+	require.Error(t, err)
+	_, err = (&Config{Chains: []*evm_adapter.Config{chain(56, "ETH"), chain(1, "")}}).Adapters()
+	require.Error(t, err)
+}
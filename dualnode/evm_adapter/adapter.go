@@ -0,0 +1,396 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package evm_adapter implements dualnode.ChainAdapter for EVM chains (Ethereum, BSC, Polygon...) through the RPC
+// endpoint of a node of the chain.
+//
+// Deposits are ERC-20 transfers to the bridge contract, or to the deposit address if no bridge contract is
+// configured. Releases call release(address token, address receiver, uint256 amount) of the bridge contract with a
+// zero token for the chain's native coin. Without bridge contract, releases are ERC-20 or native coin transfers sent
+// by the adapter's account.
+package evm_adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/lib/event"
+	"github.com/kardiachain/go-kardia/lib/log"
+)
+
+const (
+	// headChannelSize is the size of channel listening to new heads.
+	headChannelSize = 10
+	// logChannelSize is the size of channel listening to deposit logs.
+	logChannelSize = 100
+)
+
+var errAdapterStopped = errors.New("evm adapter is stopped")
+
+// Client is the subset of ethclient.Client used by the adapter.
+type Client interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+	SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error)
+	Close()
+}
+
+var (
+	_ dualnode.ChainAdapter  = (*EvmAdapter)(nil)
+	_ dualnode.DepositReader = (*EvmAdapter)(nil)
+)
+
+// EvmAdapter watches ERC-20 and ERC-721 deposits and submits releases on an EVM chain.
+type EvmAdapter struct {
+	config *Config
+	logger log.Logger
+
+	dial      func(endpoint string) (Client, error)
+	client    Client
+	clientMtx sync.RWMutex
+
+	txSigner       TxSigner
+	sender         common.Address
+	signer         types.Signer
+	depositAddress common.Address
+	bridge         *common.Address  // nil if releases are sent as transfers
+	tokens         []common.Address // ERC-20 and ERC-721 contracts whose deposits are watched
+	nfts           map[common.Address]struct{}
+
+	depositFeed event.Feed
+	scope       event.SubscriptionScope
+
+	// deposits
+	mtx             sync.Mutex
+	lastScanned     uint64 // latest block whose deposit logs have been received
+	pendingDeposits map[depositKey]*types.Log
+	delivered       map[depositKey]uint64 // block numbers of deposits sent to subscribers
+
+	// releases
+	releaseMtx sync.Mutex
+	nonce      uint64                   // nonce of the next release
+	releases   map[string]*release      // releases by id
+	txs        map[common.Hash]*release // releases by hash of any of their transactions
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewEvmAdapter returns an adapter connecting to config.RPCEndpoint when it is started. Releases are signed with
+// config.SignedTxPrivateKey.
+func NewEvmAdapter(config *Config) (*EvmAdapter, error) {
+	return NewEvmAdapterWithSigner(config, nil)
+}
+
+// NewEvmAdapterWithSigner returns an adapter whose releases are signed by txSigner (eg: a tss.Manager, once a
+// threshold protocol is provided). config.SignedTxPrivateKey is used if txSigner is nil.
+func NewEvmAdapterWithSigner(config *Config, txSigner TxSigner) (*EvmAdapter, error) {
+	return newEvmAdapter(config, txSigner, func(endpoint string) (Client, error) {
+		return ethclient.Dial(endpoint)
+	})
+}
+
+func newEvmAdapter(config *Config, txSigner TxSigner, dial func(endpoint string) (Client, error)) (*EvmAdapter, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if txSigner == nil {
+		keySigner, err := newKeySigner(config.SignedTxPrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SignedTxPrivateKey: %v", err)
+		}
+		txSigner = keySigner
+	}
+	tokens := make([]common.Address, 0, len(config.TokenContracts)+len(config.NFTContracts))
+	for _, token := range config.TokenContracts {
+		tokens = append(tokens, common.HexToAddress(token))
+	}
+	nfts := make(map[common.Address]struct{}, len(config.NFTContracts))
+	for _, nft := range config.NFTContracts {
+		address := common.HexToAddress(nft)
+		tokens = append(tokens, address)
+		nfts[address] = struct{}{}
+	}
+	var bridge *common.Address
+	if config.BridgeContract != "" {
+		address := common.HexToAddress(config.BridgeContract)
+		bridge = &address
+	}
+	return &EvmAdapter{
+		config:          config,
+		logger:          log.New("chain", config.Name),
+		dial:            dial,
+		txSigner:        txSigner,
+		sender:          txSigner.Address(),
+		signer:          types.NewEIP155Signer(big.NewInt(config.ChainId)),
+		depositAddress:  config.depositAddress(),
+		bridge:          bridge,
+		tokens:          tokens,
+		nfts:            nfts,
+		lastScanned:     config.StartBlock,
+		pendingDeposits: make(map[depositKey]*types.Log),
+		delivered:       make(map[depositKey]uint64),
+		releases:        make(map[string]*release),
+		txs:             make(map[common.Hash]*release),
+	}, nil
+}
+
+// Name returns name of the adapter's chain.
+func (a *EvmAdapter) Name() string {
+	return a.config.Name
+}
+
+// Start connects to the node and starts watching deposits and pending releases.
+func (a *EvmAdapter) Start() error {
+	client, err := a.dial(a.config.RPCEndpoint)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.requestContext()
+	defer cancel()
+	nonce, err := client.PendingNonceAt(ctx, a.sender)
+	if err != nil {
+		client.Close()
+		return err
+	}
+	a.setClient(client)
+	a.releaseMtx.Lock()
+	a.nonce = nonce
+	a.releaseMtx.Unlock()
+
+	a.quit = make(chan struct{})
+	a.wg.Add(1)
+	go a.loop()
+	a.logger.Info("EVM adapter started", "chainId", a.config.ChainId, "sender", a.sender.Hex(), "depositAddress", a.depositAddress.Hex(), "nonce", nonce)
+	return nil
+}
+
+// Stop stops watching deposits and releases, and closes the connection to the node.
+func (a *EvmAdapter) Stop() error {
+	if a.quit == nil {
+		return errAdapterStopped
+	}
+	close(a.quit)
+	a.wg.Wait()
+	a.quit = nil
+	a.scope.Close()
+	a.rpc().Close()
+	a.logger.Info("EVM adapter stopped")
+	return nil
+}
+
+// WatchDeposits sends deposits which reached the confirmation depth to sink.
+func (a *EvmAdapter) WatchDeposits(sink chan<- *dualnode.Deposit) event.Subscription {
+	return a.scope.Track(a.depositFeed.Subscribe(sink))
+}
+
+// Finality returns the finality of the transaction txHash, or the transaction included for the release sent
+// as txHash.
+func (a *EvmAdapter) Finality(ctx context.Context, txHash string) (*dualnode.Finality, error) {
+	hash := common.HexToHash(txHash)
+	a.releaseMtx.Lock()
+	if rel, ok := a.txs[hash]; ok && rel.receipt != nil {
+		hash = rel.receipt.TxHash
+	}
+	a.releaseMtx.Unlock()
+
+	client := a.rpc()
+	if client == nil {
+		return nil, errAdapterStopped
+	}
+	finality := &dualnode.Finality{TxHash: hash.Hex()}
+	receipt, err := client.TransactionReceipt(ctx, hash)
+	if err == ethereum.NotFound {
+		return finality, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	finality.Included = true
+	finality.Succeeded = receipt.Status == types.ReceiptStatusSuccessful
+	finality.BlockNumber = receipt.BlockNumber.Uint64()
+	finality.Confirmations = confirmations(head.Number.Uint64(), finality.BlockNumber)
+	finality.Final = finality.Confirmations >= a.config.ConfirmationDepth
+	return finality, nil
+}
+
+// Health returns the status of the connection to the node.
+func (a *EvmAdapter) Health(ctx context.Context) *dualnode.Health {
+	health := &dualnode.Health{
+		Chain:     a.config.Name,
+		CheckedAt: time.Now(),
+	}
+	a.mtx.Lock()
+	health.Scanned = a.lastScanned
+	a.mtx.Unlock()
+	a.releaseMtx.Lock()
+	for _, rel := range a.releases {
+		if !rel.final {
+			health.PendingTxs++
+		}
+	}
+	a.releaseMtx.Unlock()
+
+	client := a.rpc()
+	if client == nil {
+		health.Error = errAdapterStopped.Error()
+		return health
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	health.Head = head.Number.Uint64()
+	health.HeadAge = health.CheckedAt.Sub(time.Unix(int64(head.Time), 0))
+	progress, err := client.SyncProgress(ctx)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	health.Syncing = progress != nil
+	switch {
+	case health.Syncing:
+		health.Error = fmt.Sprintf("node is syncing, current block %v highest block %v", progress.CurrentBlock, progress.HighestBlock)
+	case health.HeadAge > a.config.MaxHeadAge:
+		health.Error = fmt.Sprintf("latest block %v is produced %v ago", health.Head, health.HeadAge)
+	default:
+		health.Healthy = true
+	}
+	return health
+}
+
+// loop watches the node until the adapter is stopped, and reconnects if the connection is lost.
+func (a *EvmAdapter) loop() {
+	defer a.wg.Done()
+	for {
+		err := a.watch()
+		if err == nil {
+			return
+		}
+		a.logger.Error("Connection to node is lost", "err", err)
+		select {
+		case <-a.quit:
+			return
+		case <-time.After(a.config.RetryInterval):
+		}
+		client, err := a.dial(a.config.RPCEndpoint)
+		if err != nil {
+			a.logger.Error("Failed to reconnect to node", "err", err)
+			continue
+		}
+		a.rpc().Close()
+		a.setClient(client)
+	}
+}
+
+// watch subscribes new heads and deposit logs, it returns nil when the adapter is stopped or an error if a
+// subscription fails.
+func (a *EvmAdapter) watch() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := a.rpc()
+
+	heads := make(chan *types.Header, headChannelSize)
+	headSub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return err
+	}
+	defer headSub.Unsubscribe()
+
+	logs := make(chan types.Log, logChannelSize)
+	logSub, err := client.SubscribeFilterLogs(ctx, a.depositQuery(nil, nil), logs)
+	if err != nil {
+		return err
+	}
+	defer logSub.Unsubscribe()
+
+	// deposits made while the adapter was not subscribed are fetched after subscribing so that none is missed
+	if err := a.backfill(ctx); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-a.quit:
+			return nil
+		case err := <-headSub.Err():
+			return subscriptionErr(err)
+		case err := <-logSub.Err():
+			return subscriptionErr(err)
+		case l := <-logs:
+			a.handleLog(l)
+		case head := <-heads:
+			a.handleHead(ctx, head.Number.Uint64())
+		}
+	}
+}
+
+func subscriptionErr(err error) error {
+	if err == nil {
+		return errors.New("subscription is closed")
+	}
+	return err
+}
+
+func (a *EvmAdapter) rpc() Client {
+	a.clientMtx.RLock()
+	defer a.clientMtx.RUnlock()
+	return a.client
+}
+
+func (a *EvmAdapter) setClient(client Client) {
+	a.clientMtx.Lock()
+	defer a.clientMtx.Unlock()
+	a.client = client
+}
+
+func (a *EvmAdapter) requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), a.config.RequestTimeout)
+}
+
+// confirmations returns the number of blocks including block on top of head.
+func confirmations(head, block uint64) uint64 {
+	if head < block {
+		return 0
+	}
+	return head - block + 1
+}
@@ -0,0 +1,490 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package evm_adapter
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+)
+
+const testPrivateKey = "8843ebcb1021b00ae9a644db6617f9c6d870e5fd53624cefe374c1d2d710fd06"
+
+var (
+	testToken          = common.HexToAddress("0x0A")
+	testNFT            = common.HexToAddress("0x0E")
+	testDepositAddress = common.HexToAddress("0x0B")
+	testReceiver       = common.HexToAddress("0x0C")
+	testBridge         = common.HexToAddress("0x0D")
+)
+
+// fakeClient is an in-memory Ethereum node.
+type fakeClient struct {
+	Client
+	head     uint64
+	headTime time.Time
+	gasPrice *big.Int
+	nonce    uint64
+	pool     map[common.Hash]*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+	sent     []*types.Transaction
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		headTime: time.Now(),
+		gasPrice: big.NewInt(100),
+		pool:     make(map[common.Hash]*types.Transaction),
+		receipts: make(map[common.Hash]*types.Receipt),
+	}
+}
+
+func (c *fakeClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: new(big.Int).SetUint64(c.head), Time: uint64(c.headTime.Unix())}, nil
+}
+
+func (c *fakeClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if receipt, ok := c.receipts[txHash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *fakeClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if tx, ok := c.pool[hash]; ok {
+		return tx, true, nil
+	}
+	return nil, false, ethereum.NotFound
+}
+
+func (c *fakeClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return c.nonce, nil
+}
+
+func (c *fakeClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return c.nonce, nil
+}
+
+func (c *fakeClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return c.gasPrice, nil
+}
+
+func (c *fakeClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return 50000, nil
+}
+
+func (c *fakeClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.pool[tx.Hash()] = tx
+	c.sent = append(c.sent, tx)
+	return nil
+}
+
+// CallContract returns the URI of NFTs, the only contract call made by adapters.
+func (c *fakeClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	offset := common.LeftPadBytes([]byte{common.HashLength}, common.HashLength)
+	id := new(big.Int).SetBytes(msg.Data[len(tokenURIMethod):])
+	return append(offset, abiString("ipfs://nft/"+id.String())...), nil
+}
+
+func (c *fakeClient) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error) {
+	return nil, nil
+}
+
+// include adds tx into block number.
+func (c *fakeClient) include(tx common.Hash, number uint64, blockHash common.Hash) {
+	delete(c.pool, tx)
+	c.receipts[tx] = &types.Receipt{
+		TxHash:      tx,
+		BlockHash:   blockHash,
+		BlockNumber: new(big.Int).SetUint64(number),
+		Status:      types.ReceiptStatusSuccessful,
+	}
+}
+
+func newTestAdapter(t *testing.T) (*EvmAdapter, *fakeClient) {
+	client := newFakeClient()
+	a, err := newEvmAdapter(&Config{
+		Name:               "ETH",
+		RPCEndpoint:        "ws://127.0.0.1:8546",
+		ChainId:            4,
+		SignedTxPrivateKey: testPrivateKey,
+		DepositAddress:     testDepositAddress.Hex(),
+		TokenContracts:     []string{testToken.Hex()},
+		NFTContracts:       []string{testNFT.Hex()},
+		ConfirmationDepth:  3,
+	}, nil, func(endpoint string) (Client, error) {
+		return client, nil
+	})
+	require.NoError(t, err)
+	a.setClient(client)
+	return a, client
+}
+
+func depositLog(txHash, blockHash common.Hash, number uint64, amount int64) types.Log {
+	return types.Log{
+		Address: testToken,
+		Topics: []common.Hash{
+			transferTopic,
+			common.BytesToHash(common.HexToAddress("0x0C").Bytes()),
+			common.BytesToHash(testDepositAddress.Bytes()),
+		},
+		Data:        common.LeftPadBytes(big.NewInt(amount).Bytes(), common.HashLength),
+		BlockNumber: number,
+		TxHash:      txHash,
+		BlockHash:   blockHash,
+	}
+}
+
+func TestConfig_validate(t *testing.T) {
+	config := &Config{
+		Name:           "ETH",
+		RPCEndpoint:    "ws://127.0.0.1:8546",
+		ChainId:        1,
+		DepositAddress: testDepositAddress.Hex(),
+	}
+	require.NoError(t, config.validate())
+	require.Equal(t, uint64(defaultConfirmationDepth), config.ConfirmationDepth)
+	require.Equal(t, GasStrategySuggested, config.GasStrategy)
+	require.Equal(t, testDepositAddress, config.depositAddress())
+
+	config.BridgeContract = testBridge.Hex()
+	require.NoError(t, config.validate())
+	require.Equal(t, testBridge, config.depositAddress())
+
+	config.GasStrategy = GasStrategyFixed
+	require.Error(t, config.validate())
+	config.GasPrice = 5000000000
+	require.NoError(t, config.validate())
+
+	config.GasStrategy = "unknown"
+	require.Error(t, config.validate())
+	config.GasStrategy = GasStrategyFixed
+
+	config.TokenContracts = []string{"invalid"}
+	require.Error(t, config.validate())
+
+	require.Error(t, (&Config{RPCEndpoint: "ws://127.0.0.1:8546", ChainId: 1, DepositAddress: testDepositAddress.Hex()}).validate())
+	require.Error(t, (&Config{Name: "ETH", ChainId: 1, DepositAddress: testDepositAddress.Hex()}).validate())
+}
+
+func TestNewRelease(t *testing.T) {
+	rel, err := newRelease(&dualnode.Release{ID: "1", Token: testToken.Hex(), To: testReceiver.Hex(), Amount: big.NewInt(10)}, nil)
+	require.NoError(t, err)
+	require.Equal(t, testToken, rel.to)
+	require.Equal(t, int64(0), rel.value.Int64())
+	require.Equal(t, common.FromHex("0xa9059cbb"+
+		"000000000000000000000000000000000000000000000000000000000000000c"+
+		"000000000000000000000000000000000000000000000000000000000000000a"), rel.data)
+
+	rel, err = newRelease(&dualnode.Release{ID: "2", To: testReceiver.Hex(), Amount: big.NewInt(10)}, nil)
+	require.NoError(t, err)
+	require.Equal(t, testReceiver, rel.to)
+	require.Equal(t, int64(10), rel.value.Int64())
+	require.Empty(t, rel.data)
+
+	for _, r := range []*dualnode.Release{
+		{To: testReceiver.Hex(), Amount: big.NewInt(10)},
+		{ID: "3", To: "invalid", Amount: big.NewInt(10)},
+		{ID: "4", To: testReceiver.Hex(), Amount: big.NewInt(0)},
+		{ID: "5", Token: "invalid", To: testReceiver.Hex(), Amount: big.NewInt(10)},
+	} {
+		_, err := newRelease(r, nil)
+		require.Error(t, err)
+	}
+}
+
+func TestNewRelease_bridge(t *testing.T) {
+	bridge := testBridge
+	rel, err := newRelease(&dualnode.Release{ID: "1", Token: testToken.Hex(), To: testReceiver.Hex(), Amount: big.NewInt(10)}, &bridge)
+	require.NoError(t, err)
+	require.Equal(t, bridge, rel.to)
+	require.Equal(t, int64(0), rel.value.Int64())
+	require.Equal(t, common.FromHex(common.Bytes2Hex(releaseMethod)+
+		"000000000000000000000000000000000000000000000000000000000000000a"+
+		"000000000000000000000000000000000000000000000000000000000000000c"+
+		"000000000000000000000000000000000000000000000000000000000000000a"), rel.data)
+
+	// native coin is released by the bridge with the zero address as token
+	rel, err = newRelease(&dualnode.Release{ID: "2", To: testReceiver.Hex(), Amount: big.NewInt(10)}, &bridge)
+	require.NoError(t, err)
+	require.Equal(t, bridge, rel.to)
+	require.Equal(t, int64(0), rel.value.Int64())
+	require.Equal(t, common.FromHex(common.Bytes2Hex(releaseMethod)+
+		"0000000000000000000000000000000000000000000000000000000000000000"+
+		"000000000000000000000000000000000000000000000000000000000000000c"+
+		"000000000000000000000000000000000000000000000000000000000000000a"), rel.data)
+}
+
+func TestGasPrice(t *testing.T) {
+	a, client := newTestAdapter(t)
+	ctx := context.Background()
+
+	price, err := a.gasPrice(ctx, client)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(100), price)
+
+	a.config.GasStrategy = GasStrategyMultiplier
+	a.config.GasPriceMultiplier = 150
+	price, err = a.gasPrice(ctx, client)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(150), price)
+
+	a.config.GasStrategy = GasStrategyFixed
+	a.config.GasPrice = 80
+	price, err = a.gasPrice(ctx, client)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(80), price)
+
+	// the bumped price replaces the previous transaction even if the fixed price is lower
+	price, err = a.bumpGasPrice(ctx, client, big.NewInt(100))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(120), price)
+
+	a.config.MaxGasPrice = 110
+	price, err = a.bumpGasPrice(ctx, client, big.NewInt(100))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(110), price)
+}
+
+func TestDeposits(t *testing.T) {
+	a, client := newTestAdapter(t)
+	sink := make(chan *dualnode.Deposit, 10)
+	sub := a.WatchDeposits(sink)
+	defer sub.Unsubscribe()
+
+	confirmed := depositLog(common.HexToHash("0x01"), common.HexToHash("0xb1"), 10, 100)
+	reorganised := depositLog(common.HexToHash("0x02"), common.HexToHash("0xb1"), 10, 200)
+	removed := depositLog(common.HexToHash("0x03"), common.HexToHash("0xb1"), 10, 300)
+	a.handleLog(confirmed)
+	a.handleLog(reorganised)
+	a.handleLog(removed)
+	removed.Removed = true
+	a.handleLog(removed)
+	client.include(confirmed.TxHash, 10, confirmed.BlockHash)
+	client.include(reorganised.TxHash, 11, common.HexToHash("0xb2"))
+
+	// deposits are not confirmed yet
+	a.handleHead(context.Background(), 11)
+	require.Len(t, sink, 0)
+
+	a.handleHead(context.Background(), 12)
+	require.Len(t, sink, 1)
+	deposit := <-sink
+	require.Equal(t, confirmed.TxHash.Hex(), deposit.TxHash)
+	require.Equal(t, testToken.Hex(), deposit.Token)
+	require.Equal(t, common.HexToAddress("0x0C").Hex(), deposit.From)
+	require.Equal(t, testDepositAddress.Hex(), deposit.To)
+	require.Equal(t, big.NewInt(100), deposit.Amount)
+
+	// delivered deposits are not sent twice
+	a.handleLog(confirmed)
+	a.handleHead(context.Background(), 13)
+	require.Len(t, sink, 0)
+
+	// a confirmed deposit reorganised out of the chain is sent again as removed
+	confirmed.Removed = true
+	a.handleLog(confirmed)
+	require.Len(t, sink, 1)
+	deposit = <-sink
+	require.True(t, deposit.Removed)
+	require.Equal(t, confirmed.TxHash.Hex(), deposit.TxHash)
+}
+
+func TestDeposits_nft(t *testing.T) {
+	a, client := newTestAdapter(t)
+	sink := make(chan *dualnode.Deposit, 10)
+	sub := a.WatchDeposits(sink)
+	defer sub.Unsubscribe()
+
+	l := types.Log{
+		Address: testNFT,
+		Topics: []common.Hash{
+			transferTopic,
+			common.BytesToHash(common.HexToAddress("0x0C").Bytes()),
+			common.BytesToHash(testDepositAddress.Bytes()),
+			common.BigToHash(big.NewInt(42)),
+		},
+		BlockNumber: 10,
+		TxHash:      common.HexToHash("0x01"),
+		BlockHash:   common.HexToHash("0xb1"),
+	}
+	a.handleLog(l)
+	// an ERC-20 transfer of an NFT contract is not a deposit
+	fungible := depositLog(common.HexToHash("0x02"), common.HexToHash("0xb1"), 10, 100)
+	fungible.Address = testNFT
+	a.handleLog(fungible)
+	client.include(l.TxHash, 10, l.BlockHash)
+	client.include(fungible.TxHash, 10, l.BlockHash)
+
+	a.handleHead(context.Background(), 12)
+	require.Len(t, sink, 1)
+	deposit := <-sink
+	require.Equal(t, testNFT.Hex(), deposit.Token)
+	require.Equal(t, big.NewInt(42), deposit.TokenID)
+	require.Equal(t, big.NewInt(1), deposit.Amount)
+	require.Equal(t, "ipfs://nft/42", deposit.TokenURI)
+}
+
+func TestDeposits_ofTransaction(t *testing.T) {
+	a, client := newTestAdapter(t)
+	txHash := common.HexToHash("0x01")
+	deposits, err := a.Deposits(context.Background(), txHash.Hex())
+	require.NoError(t, err)
+	require.Empty(t, deposits)
+
+	l := depositLog(txHash, common.HexToHash("0xb1"), 10, 100)
+	l.Index = 1
+	other := depositLog(txHash, common.HexToHash("0xb1"), 10, 200)
+	other.Topics[2] = common.BytesToHash(common.HexToAddress("0x0D").Bytes())
+	client.include(txHash, 10, l.BlockHash)
+	client.receipts[txHash].Logs = []*types.Log{&other, &l}
+
+	// deposits are returned once they reach the confirmation depth
+	client.head = 11
+	_, err = a.Deposits(context.Background(), txHash.Hex())
+	require.Error(t, err)
+	client.head = 12
+	deposits, err = a.Deposits(context.Background(), txHash.Hex())
+	require.NoError(t, err)
+	require.Len(t, deposits, 1)
+	require.Equal(t, uint(1), deposits[0].LogIndex)
+	require.Equal(t, uint64(10), deposits[0].BlockNumber)
+	require.Equal(t, big.NewInt(100), deposits[0].Amount)
+}
+
+func TestNewRelease_nft(t *testing.T) {
+	release := &dualnode.Release{ID: "1", Token: testNFT.Hex(), To: testReceiver.Hex(), TokenID: big.NewInt(42), TokenURI: "ipfs://nft/42"}
+	_, err := newRelease(release, nil)
+	require.Error(t, err)
+
+	bridge := testBridge
+	rel, err := newRelease(release, &bridge)
+	require.NoError(t, err)
+	require.Equal(t, bridge, rel.to)
+	require.Equal(t, common.FromHex(common.Bytes2Hex(releaseNFTMethod)+
+		"000000000000000000000000000000000000000000000000000000000000000e"+
+		"000000000000000000000000000000000000000000000000000000000000000c"+
+		"000000000000000000000000000000000000000000000000000000000000002a"+
+		"0000000000000000000000000000000000000000000000000000000000000080"+
+		"000000000000000000000000000000000000000000000000000000000000000d"+
+		"697066733a2f2f6e66742f343200000000000000000000000000000000000000"), rel.data)
+
+	release.Token = ""
+	_, err = newRelease(release, &bridge)
+	require.Error(t, err)
+}
+
+func TestNewRelease_batch(t *testing.T) {
+	root := common.HexToHash("0x01").Bytes()
+	release := &dualnode.Release{ID: "1", Token: testToken.Hex(), Amount: big.NewInt(300), BatchRoot: root}
+	_, err := newRelease(release, nil)
+	require.Error(t, err)
+
+	bridge := testBridge
+	rel, err := newRelease(release, &bridge)
+	require.NoError(t, err)
+	require.Equal(t, bridge, rel.to)
+	require.Equal(t, common.FromHex(common.Bytes2Hex(releaseBatchMethod)+
+		"000000000000000000000000000000000000000000000000000000000000000a"+
+		"0000000000000000000000000000000000000000000000000000000000000001"+
+		"000000000000000000000000000000000000000000000000000000000000012c"), rel.data)
+
+	release.BatchRoot = root[1:]
+	_, err = newRelease(release, &bridge)
+	require.Error(t, err)
+}
+
+func TestDecodeABIString(t *testing.T) {
+	offset := common.LeftPadBytes([]byte{common.HashLength}, common.HashLength)
+	for _, s := range []string{"", "ipfs://nft/42", string(make([]byte, 70))} {
+		decoded, err := decodeABIString(append(offset, abiString(s)...))
+		require.NoError(t, err)
+		require.Equal(t, s, decoded)
+	}
+	_, err := decodeABIString(offset)
+	require.Error(t, err)
+	_, err = decodeABIString(append(offset, common.LeftPadBytes([]byte{0xff}, common.HashLength)...))
+	require.Error(t, err)
+}
+
+func TestSubmitRelease(t *testing.T) {
+	a, client := newTestAdapter(t)
+	client.nonce = 5
+	a.nonce = 5
+	release := &dualnode.Release{ID: "1", Token: testToken.Hex(), To: testReceiver.Hex(), Amount: big.NewInt(10)}
+
+	txHash, err := a.SubmitRelease(context.Background(), release)
+	require.NoError(t, err)
+	require.Len(t, client.sent, 1)
+	require.Equal(t, uint64(5), client.sent[0].Nonce())
+
+	// a release is submitted once
+	again, err := a.SubmitRelease(context.Background(), release)
+	require.NoError(t, err)
+	require.Equal(t, txHash, again)
+	require.Len(t, client.sent, 1)
+
+	// the transaction is dropped from the pool, the release is resubmitted with the same nonce and a higher gas price
+	delete(client.pool, common.HexToHash(txHash))
+	a.checkReleases(context.Background(), 10)
+	require.Len(t, client.sent, 2)
+	require.Equal(t, uint64(5), client.sent[1].Nonce())
+	require.Equal(t, big.NewInt(120), client.sent[1].GasPrice())
+
+	// the resubmitted transaction is included, finality of the release is queried with its first transaction
+	client.include(client.sent[1].Hash(), 11, common.HexToHash("0xb1"))
+	client.head = 12
+	a.checkReleases(context.Background(), 12)
+	finality, err := a.Finality(context.Background(), txHash)
+	require.NoError(t, err)
+	require.Equal(t, client.sent[1].Hash().Hex(), finality.TxHash)
+	require.True(t, finality.Included)
+	require.True(t, finality.Succeeded)
+	require.Equal(t, uint64(2), finality.Confirmations)
+	require.False(t, finality.Final)
+	require.Equal(t, 1, a.Health(context.Background()).PendingTxs)
+
+	client.head = 13
+	a.checkReleases(context.Background(), 13)
+	finality, err = a.Finality(context.Background(), txHash)
+	require.NoError(t, err)
+	require.True(t, finality.Final)
+	require.Equal(t, 0, a.Health(context.Background()).PendingTxs)
+}
+
+func TestHealth(t *testing.T) {
+	a, client := newTestAdapter(t)
+	client.head = 100
+	health := a.Health(context.Background())
+	require.True(t, health.Healthy)
+	require.Equal(t, uint64(100), health.Head)
+
+	client.headTime = time.Now().Add(-time.Hour)
+	health = a.Health(context.Background())
+	require.False(t, health.Healthy)
+	require.NotEmpty(t, health.Error)
+}
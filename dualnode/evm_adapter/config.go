@@ -0,0 +1,131 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package evm_adapter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	defaultConfirmationDepth = 12
+	defaultResubmitTimeout   = 5 * time.Minute
+	defaultGasPriceBump      = 20 // percent, nodes require at least 10% to replace a pending transaction
+	defaultMaxHeadAge        = 2 * time.Minute
+	defaultRetryInterval     = 5 * time.Second
+	defaultRequestTimeout    = 10 * time.Second
+)
+
+type (
+	Config struct {
+		Name               string        `yaml:"Name"`               // name of the chain (eg: ETH, BSC, MATIC)
+		RPCEndpoint        string        `yaml:"RPCEndpoint"`        // websocket or IPC endpoint, log subscription is not supported over HTTP
+		ChainId            int64         `yaml:"ChainId"`            // chain id used to sign transactions (EIP-155)
+		SignedTxPrivateKey string        `yaml:"SignedTxPrivateKey"` // hex private key of the account sending releases, unused with a TxSigner
+		BridgeContract     string        `yaml:"BridgeContract"`     // bridge contract receiving deposits and executing releases, see EvmAdapter
+		DepositAddress     string        `yaml:"DepositAddress"`     // address receiving deposits if BridgeContract is empty
+		TokenContracts     []string      `yaml:"TokenContracts"`     // ERC-20 contracts whose transfers to the deposit address are deposits
+		NFTContracts       []string      `yaml:"NFTContracts"`       // ERC-721 contracts whose transfers to the deposit address are deposits
+		StartBlock         uint64        `yaml:"StartBlock"`         // first block scanned for deposits, 0 starts from the current head
+		ConfirmationDepth  uint64        `yaml:"ConfirmationDepth"`  // number of blocks including a transaction's block for it to be final
+		GasStrategy        string        `yaml:"GasStrategy"`        // GasStrategySuggested, GasStrategyFixed or GasStrategyMultiplier
+		GasPrice           uint64        `yaml:"GasPrice"`           // gas price in wei of GasStrategyFixed
+		GasPriceMultiplier uint64        `yaml:"GasPriceMultiplier"` // percentage of the suggested gas price used by GasStrategyMultiplier
+		MaxGasPrice        uint64        `yaml:"MaxGasPrice"`        // maximum gas price in wei of releases, 0 means unlimited
+		ResubmitTimeout    time.Duration `yaml:"ResubmitTimeout"`    // time after which a release which is not included is resubmitted
+		GasPriceBump       uint64        `yaml:"GasPriceBump"`       // percentage added to the gas price of a resubmitted release
+		MaxHeadAge         time.Duration `yaml:"MaxHeadAge"`         // the adapter is unhealthy if the latest block is older
+		RetryInterval      time.Duration `yaml:"RetryInterval"`      // interval between reconnections to RPCEndpoint
+		RequestTimeout     time.Duration `yaml:"RequestTimeout"`     // timeout of a RPC request
+	}
+)
+
+// validate checks required fields of c and sets default values of the others.
+func (c *Config) validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("Name is required")
+	}
+	if c.RPCEndpoint == "" {
+		return fmt.Errorf("RPCEndpoint of %v is required", c.Name)
+	}
+	if c.ChainId <= 0 {
+		return fmt.Errorf("invalid ChainId %v of %v", c.ChainId, c.Name)
+	}
+	if c.BridgeContract != "" {
+		if !common.IsHexAddress(c.BridgeContract) {
+			return fmt.Errorf("invalid BridgeContract %v of %v", c.BridgeContract, c.Name)
+		}
+	} else if !common.IsHexAddress(c.DepositAddress) {
+		return fmt.Errorf("invalid DepositAddress %v of %v", c.DepositAddress, c.Name)
+	}
+	for _, token := range c.TokenContracts {
+		if !common.IsHexAddress(token) {
+			return fmt.Errorf("invalid token contract %v of %v", token, c.Name)
+		}
+	}
+	for _, nft := range c.NFTContracts {
+		if !common.IsHexAddress(nft) {
+			return fmt.Errorf("invalid NFT contract %v of %v", nft, c.Name)
+		}
+	}
+	switch c.GasStrategy {
+	case "":
+		c.GasStrategy = GasStrategySuggested
+	case GasStrategySuggested:
+	case GasStrategyFixed:
+		if c.GasPrice == 0 {
+			return fmt.Errorf("GasPrice of %v is required by %v gas strategy", c.Name, c.GasStrategy)
+		}
+	case GasStrategyMultiplier:
+		if c.GasPriceMultiplier == 0 {
+			return fmt.Errorf("GasPriceMultiplier of %v is required by %v gas strategy", c.Name, c.GasStrategy)
+		}
+	default:
+		return fmt.Errorf("unknown gas strategy %v of %v", c.GasStrategy, c.Name)
+	}
+	if c.ConfirmationDepth == 0 {
+		c.ConfirmationDepth = defaultConfirmationDepth
+	}
+	if c.ResubmitTimeout <= 0 {
+		c.ResubmitTimeout = defaultResubmitTimeout
+	}
+	if c.GasPriceBump == 0 {
+		c.GasPriceBump = defaultGasPriceBump
+	}
+	if c.MaxHeadAge <= 0 {
+		c.MaxHeadAge = defaultMaxHeadAge
+	}
+	if c.RetryInterval <= 0 {
+		c.RetryInterval = defaultRetryInterval
+	}
+	if c.RequestTimeout <= 0 {
+		c.RequestTimeout = defaultRequestTimeout
+	}
+	return nil
+}
+
+// depositAddress returns the address receiving deposits.
+func (c *Config) depositAddress() common.Address {
+	if c.BridgeContract != "" {
+		return common.HexToAddress(c.BridgeContract)
+	}
+	return common.HexToAddress(c.DepositAddress)
+}
@@ -0,0 +1,273 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package evm_adapter
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+)
+
+// transferTopic is the signature of ERC-20 Transfer(address indexed from, address indexed to, uint256 value) event,
+// and of ERC-721 Transfer(address indexed from, address indexed to, uint256 indexed tokenId) event.
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// tokenURIMethod is the selector of ERC-721 tokenURI(uint256 tokenId) method.
+var tokenURIMethod = crypto.Keccak256([]byte("tokenURI(uint256)"))[:4]
+
+// depositKey identifies a deposit log.
+type depositKey struct {
+	txHash common.Hash
+	index  uint
+}
+
+// depositQuery returns the filter of Transfer logs to the deposit address emitted by watched tokens.
+func (a *EvmAdapter) depositQuery(from, to *big.Int) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		FromBlock: from,
+		ToBlock:   to,
+		Addresses: a.tokens,
+		Topics: [][]common.Hash{
+			{transferTopic},
+			nil,
+			{common.BytesToHash(a.depositAddress.Bytes())},
+		},
+	}
+}
+
+// backfill fetches deposit logs emitted since the last scanned block. The last ConfirmationDepth blocks are scanned
+// again since their logs may have been reorganised while the adapter was not subscribed.
+func (a *EvmAdapter) backfill(ctx context.Context) error {
+	client := a.rpc()
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	headNumber := head.Number.Uint64()
+
+	a.mtx.Lock()
+	from := a.lastScanned
+	if from == 0 {
+		from = headNumber
+	} else if from > a.config.ConfirmationDepth && from-a.config.ConfirmationDepth >= a.config.StartBlock {
+		from -= a.config.ConfirmationDepth
+	}
+	a.mtx.Unlock()
+
+	if from <= headNumber {
+		logs, err := client.FilterLogs(ctx, a.depositQuery(new(big.Int).SetUint64(from), head.Number))
+		if err != nil {
+			return err
+		}
+		for _, l := range logs {
+			a.handleLog(l)
+		}
+		a.logger.Info("Deposits are backfilled", "from", from, "to", headNumber, "logs", len(logs))
+	}
+	a.handleHead(ctx, headNumber)
+	return nil
+}
+
+// handleLog adds a deposit log which is waiting for its confirmations, or removes it if it is reorganised.
+func (a *EvmAdapter) handleLog(l types.Log) {
+	if !a.isTransfer(&l) {
+		return
+	}
+	key := depositKey{txHash: l.TxHash, index: l.Index}
+	a.mtx.Lock()
+	if l.Removed {
+		delete(a.pendingDeposits, key)
+		_, delivered := a.delivered[key]
+		delete(a.delivered, key)
+		a.mtx.Unlock()
+		if delivered {
+			// the chain is reorganised deeper than the confirmation depth, the deposit must be cancelled
+			deposit := a.newDeposit(&l)
+			deposit.Removed = true
+			a.logger.Warn("Confirmed deposit is reorganised", "txHash", deposit.TxHash, "block", l.BlockNumber)
+			a.depositFeed.Send(deposit)
+		}
+		return
+	}
+	defer a.mtx.Unlock()
+	if _, ok := a.delivered[key]; ok {
+		return
+	}
+	a.pendingDeposits[key] = &l
+}
+
+// handleHead sends deposits which reached the confirmation depth at head, and checks pending releases.
+func (a *EvmAdapter) handleHead(ctx context.Context, head uint64) {
+	a.mtx.Lock()
+	if head > a.lastScanned {
+		a.lastScanned = head
+	}
+	ready := make([]*types.Log, 0)
+	for _, l := range a.pendingDeposits {
+		if confirmations(head, l.BlockNumber) >= a.config.ConfirmationDepth {
+			ready = append(ready, l)
+		}
+	}
+	// delivered deposits are kept until they cannot be received again by backfill
+	for key, block := range a.delivered {
+		if block+2*a.config.ConfirmationDepth < head {
+			delete(a.delivered, key)
+		}
+	}
+	a.mtx.Unlock()
+
+	for _, l := range ready {
+		a.confirmDeposit(ctx, l)
+	}
+	a.checkReleases(ctx, head)
+}
+
+// confirmDeposit sends the deposit of l to subscribers if its transaction is still in the block of l.
+func (a *EvmAdapter) confirmDeposit(ctx context.Context, l *types.Log) {
+	key := depositKey{txHash: l.TxHash, index: l.Index}
+	receipt, err := a.rpc().TransactionReceipt(ctx, l.TxHash)
+	if err != nil && err != ethereum.NotFound {
+		// the deposit is confirmed again at next head
+		a.logger.Warn("Failed to get receipt of deposit", "txHash", l.TxHash.Hex(), "err", err)
+		return
+	}
+	a.mtx.Lock()
+	delete(a.pendingDeposits, key)
+	if err == ethereum.NotFound || receipt.BlockHash != l.BlockHash || receipt.Status != types.ReceiptStatusSuccessful {
+		// the transaction is reorganised, its log in the new block is received by the subscription
+		a.mtx.Unlock()
+		a.logger.Warn("Deposit is reorganised", "txHash", l.TxHash.Hex(), "block", l.BlockNumber)
+		return
+	}
+	a.delivered[key] = l.BlockNumber
+	a.mtx.Unlock()
+
+	deposit := a.newDeposit(l)
+	if deposit.TokenID != nil {
+		// the metadata is optional, an NFT without URI is still bridged
+		if deposit.TokenURI, err = a.tokenURI(ctx, l.Address, deposit.TokenID); err != nil {
+			a.logger.Warn("Failed to get URI of NFT", "token", deposit.Token, "id", deposit.TokenID, "err", err)
+		}
+	}
+	a.logger.Info("Deposit is confirmed", "txHash", deposit.TxHash, "token", deposit.Token, "from", deposit.From, "amount", deposit.Amount)
+	a.depositFeed.Send(deposit)
+}
+
+// Deposits returns the deposits of the transaction txHash once it reached the confirmation depth, the metadata URI of
+// deposited NFTs is not fetched.
+func (a *EvmAdapter) Deposits(ctx context.Context, txHash string) ([]*dualnode.Deposit, error) {
+	client := a.rpc()
+	if client == nil {
+		return nil, errAdapterStopped
+	}
+	receipt, err := client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if err == ethereum.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if n := confirmations(head.Number.Uint64(), receipt.BlockNumber.Uint64()); n < a.config.ConfirmationDepth {
+		return nil, fmt.Errorf("transaction %v has %d confirmations, expected %d", txHash, n,
+			a.config.ConfirmationDepth)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, nil
+	}
+	var deposits []*dualnode.Deposit
+	for _, l := range receipt.Logs {
+		if a.isDepositLog(l) && a.isTransfer(l) {
+			deposits = append(deposits, a.newDeposit(l))
+		}
+	}
+	return deposits, nil
+}
+
+// isDepositLog reports whether l matches depositQuery.
+func (a *EvmAdapter) isDepositLog(l *types.Log) bool {
+	if len(l.Topics) < 3 || l.Topics[0] != transferTopic ||
+		l.Topics[2] != common.BytesToHash(a.depositAddress.Bytes()) {
+		return false
+	}
+	for _, token := range a.tokens {
+		if l.Address == token {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransfer reports whether a Transfer log has the fields of the standard of its token. Transfer of ERC-721 tokens
+// has the same signature with the token id indexed.
+func (a *EvmAdapter) isTransfer(l *types.Log) bool {
+	if _, nft := a.nfts[l.Address]; nft {
+		return len(l.Topics) == 4
+	}
+	return len(l.Topics) == 3 && len(l.Data) == common.HashLength
+}
+
+func (a *EvmAdapter) newDeposit(l *types.Log) *dualnode.Deposit {
+	if len(l.Topics) == 4 {
+		return &dualnode.Deposit{
+			Chain:       a.config.Name,
+			TxHash:      l.TxHash.Hex(),
+			LogIndex:    l.Index,
+			BlockNumber: l.BlockNumber,
+			Token:       l.Address.Hex(),
+			From:        common.BytesToAddress(l.Topics[1].Bytes()).Hex(),
+			To:          common.BytesToAddress(l.Topics[2].Bytes()).Hex(),
+			Amount:      big.NewInt(1),
+			TokenID:     l.Topics[3].Big(),
+		}
+	}
+	return &dualnode.Deposit{
+		Chain:       a.config.Name,
+		TxHash:      l.TxHash.Hex(),
+		LogIndex:    l.Index,
+		BlockNumber: l.BlockNumber,
+		Token:       l.Address.Hex(),
+		From:        common.BytesToAddress(l.Topics[1].Bytes()).Hex(),
+		To:          common.BytesToAddress(l.Topics[2].Bytes()).Hex(),
+		Amount:      new(big.Int).SetBytes(l.Data),
+	}
+}
+
+// tokenURI returns the metadata URI of the NFT id of contract nft.
+func (a *EvmAdapter) tokenURI(ctx context.Context, nft common.Address, id *big.Int) (string, error) {
+	client := a.rpc()
+	if client == nil {
+		return "", errAdapterStopped
+	}
+	out, err := client.CallContract(ctx, ethereum.CallMsg{To: &nft, Data: abiCall(tokenURIMethod, id.Bytes())}, nil)
+	if err != nil {
+		return "", err
+	}
+	return decodeABIString(out)
+}
@@ -0,0 +1,79 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package evm_adapter
+
+import (
+	"context"
+	"math/big"
+)
+
+const (
+	GasStrategySuggested  = "suggested"  // gas price suggested by the node
+	GasStrategyFixed      = "fixed"      // Config.GasPrice
+	GasStrategyMultiplier = "multiplier" // Config.GasPriceMultiplier percent of the gas price suggested by the node
+)
+
+// gasPrice returns the gas price of a new transaction according to the gas strategy of the adapter, capped by
+// MaxGasPrice.
+func (a *EvmAdapter) gasPrice(ctx context.Context, client Client) (*big.Int, error) {
+	var price *big.Int
+	switch a.config.GasStrategy {
+	case GasStrategyFixed:
+		price = new(big.Int).SetUint64(a.config.GasPrice)
+	default:
+		suggested, err := client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		price = suggested
+		if a.config.GasStrategy == GasStrategyMultiplier {
+			price = percentOf(suggested, a.config.GasPriceMultiplier)
+		}
+	}
+	return a.capGasPrice(price), nil
+}
+
+// bumpGasPrice returns the gas price replacing a transaction sent with previous, it is at least GasPriceBump percent
+// higher than previous unless MaxGasPrice is reached.
+func (a *EvmAdapter) bumpGasPrice(ctx context.Context, client Client, previous *big.Int) (*big.Int, error) {
+	price, err := a.gasPrice(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	bumped := percentOf(previous, 100+a.config.GasPriceBump)
+	if price.Cmp(bumped) < 0 {
+		price = bumped
+	}
+	return a.capGasPrice(price), nil
+}
+
+func (a *EvmAdapter) capGasPrice(price *big.Int) *big.Int {
+	if a.config.MaxGasPrice > 0 {
+		max := new(big.Int).SetUint64(a.config.MaxGasPrice)
+		if price.Cmp(max) > 0 {
+			return max
+		}
+	}
+	return price
+}
+
+func percentOf(x *big.Int, percent uint64) *big.Int {
+	result := new(big.Int).Mul(x, new(big.Int).SetUint64(percent))
+	return result.Div(result, big.NewInt(100))
+}
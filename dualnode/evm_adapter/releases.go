@@ -0,0 +1,322 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package evm_adapter
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+)
+
+var (
+	// transferMethod is the selector of ERC-20 transfer(address to, uint256 value) method.
+	transferMethod = crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
+	// releaseMethod is the selector of release(address token, address receiver, uint256 amount) method of bridge contracts.
+	releaseMethod = crypto.Keccak256([]byte("release(address,address,uint256)"))[:4]
+	// releaseNFTMethod is the selector of releaseNFT(address token, address receiver, uint256 tokenId, string tokenURI)
+	// method of bridge contracts, which unlocks the NFT or mints its wrapped NFT with the given URI.
+	releaseNFTMethod = crypto.Keccak256([]byte("releaseNFT(address,address,uint256,string)"))[:4]
+	// releaseBatchMethod is the selector of releaseBatch(address token, bytes32 root, uint256 total) method of bridge
+	// contracts, which locks total for the receivers of the batch with the given Merkle root to claim.
+	releaseBatchMethod = crypto.Keccak256([]byte("releaseBatch(address,bytes32,uint256)"))[:4]
+)
+
+// release is a release submitted by the adapter.
+type release struct {
+	*dualnode.Release
+	to       common.Address // recipient of the transaction, the bridge or token contract for contract calls
+	value    *big.Int
+	data     []byte
+	nonce    uint64
+	gasLimit uint64
+	gasPrice *big.Int
+	txs      []*types.Transaction // transactions sent for the release, the last one is the current transaction
+	sentAt   time.Time            // time the current transaction is sent
+	receipt  *types.Receipt       // receipt of the included transaction
+	final    bool
+}
+
+func (r *release) current() *types.Transaction {
+	return r.txs[len(r.txs)-1]
+}
+
+// newRelease validates r and builds the call executing it, through bridge if it is not nil.
+func newRelease(r *dualnode.Release, bridge *common.Address) (*release, error) {
+	if r.ID == "" {
+		return nil, fmt.Errorf("release id is required")
+	}
+	if r.TokenID != nil {
+		return newNFTRelease(r, bridge)
+	}
+	if r.BatchRoot != nil {
+		return newBatchRelease(r, bridge)
+	}
+	if r.Amount == nil || r.Amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid amount %v of release %v", r.Amount, r.ID)
+	}
+	if !common.IsHexAddress(r.To) {
+		return nil, fmt.Errorf("invalid receiver %v of release %v", r.To, r.ID)
+	}
+	if r.Token != "" && !common.IsHexAddress(r.Token) {
+		return nil, fmt.Errorf("invalid token %v of release %v", r.Token, r.ID)
+	}
+	receiver := common.HexToAddress(r.To)
+	token := common.HexToAddress(r.Token) // zero address for native coin
+	switch {
+	case bridge != nil:
+		data := abiCall(releaseMethod, token.Bytes(), receiver.Bytes(), r.Amount.Bytes())
+		return &release{Release: r, to: *bridge, value: new(big.Int), data: data}, nil
+	case r.Token == "":
+		return &release{Release: r, to: receiver, value: r.Amount}, nil
+	default:
+		data := abiCall(transferMethod, receiver.Bytes(), r.Amount.Bytes())
+		return &release{Release: r, to: token, value: new(big.Int), data: data}, nil
+	}
+}
+
+// newNFTRelease validates r, the release of an NFT, and builds the bridge call executing it.
+func newNFTRelease(r *dualnode.Release, bridge *common.Address) (*release, error) {
+	if bridge == nil {
+		return nil, fmt.Errorf("NFT release %v requires a bridge contract", r.ID)
+	}
+	if r.TokenID.Sign() < 0 || r.TokenID.BitLen() > 256 {
+		return nil, fmt.Errorf("invalid token id %v of release %v", r.TokenID, r.ID)
+	}
+	if !common.IsHexAddress(r.To) {
+		return nil, fmt.Errorf("invalid receiver %v of release %v", r.To, r.ID)
+	}
+	if !common.IsHexAddress(r.Token) {
+		return nil, fmt.Errorf("invalid NFT %v of release %v", r.Token, r.ID)
+	}
+	receiver := common.HexToAddress(r.To)
+	token := common.HexToAddress(r.Token)
+	// the string is encoded after the static arguments, at the offset given in its head slot
+	offset := big.NewInt(4 * common.HashLength)
+	data := abiCall(releaseNFTMethod, token.Bytes(), receiver.Bytes(), r.TokenID.Bytes(), offset.Bytes())
+	data = append(data, abiString(r.TokenURI)...)
+	return &release{Release: r, to: *bridge, value: new(big.Int), data: data}, nil
+}
+
+// newBatchRelease validates r, the release of a batch, and builds the bridge call executing it.
+func newBatchRelease(r *dualnode.Release, bridge *common.Address) (*release, error) {
+	if bridge == nil {
+		return nil, fmt.Errorf("batch release %v requires a bridge contract", r.ID)
+	}
+	if len(r.BatchRoot) != common.HashLength {
+		return nil, fmt.Errorf("invalid root %x of release %v", r.BatchRoot, r.ID)
+	}
+	if r.Amount == nil || r.Amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid amount %v of release %v", r.Amount, r.ID)
+	}
+	if r.Token != "" && !common.IsHexAddress(r.Token) {
+		return nil, fmt.Errorf("invalid token %v of release %v", r.Token, r.ID)
+	}
+	token := common.HexToAddress(r.Token) // zero address for native coin
+	data := abiCall(releaseBatchMethod, token.Bytes(), r.BatchRoot, r.Amount.Bytes())
+	return &release{Release: r, to: *bridge, value: new(big.Int), data: data}, nil
+}
+
+// abiCall returns the input calling method with static arguments which are left padded to 32 bytes.
+func abiCall(method []byte, args ...[]byte) []byte {
+	data := make([]byte, 0, len(method)+len(args)*common.HashLength)
+	data = append(data, method...)
+	for _, arg := range args {
+		data = append(data, common.LeftPadBytes(arg, common.HashLength)...)
+	}
+	return data
+}
+
+// SubmitRelease sends a transaction executing r and returns its hash. If a release with the same id has been
+// submitted, its current transaction is returned.
+func (a *EvmAdapter) SubmitRelease(ctx context.Context, r *dualnode.Release) (string, error) {
+	rel, err := newRelease(r, a.bridge)
+	if err != nil {
+		return "", err
+	}
+	client := a.rpc()
+	if client == nil {
+		return "", errAdapterStopped
+	}
+
+	a.releaseMtx.Lock()
+	defer a.releaseMtx.Unlock()
+	if submitted, ok := a.releases[r.ID]; ok {
+		return submitted.current().Hash().Hex(), nil
+	}
+	rel.gasPrice, err = a.gasPrice(ctx, client)
+	if err != nil {
+		return "", err
+	}
+	rel.gasLimit, err = client.EstimateGas(ctx, ethereum.CallMsg{
+		From:  a.sender,
+		To:    &rel.to,
+		Value: rel.value,
+		Data:  rel.data,
+	})
+	if err != nil {
+		return "", err
+	}
+	rel.nonce = a.nonce
+	err = a.send(ctx, client, rel)
+	if err != nil && isNonceTooLow(err) {
+		// the account sent transactions which are not known by the adapter, the nonce is synced with the node
+		if rel.nonce, err = client.PendingNonceAt(ctx, a.sender); err != nil {
+			return "", err
+		}
+		err = a.send(ctx, client, rel)
+	}
+	if err != nil {
+		return "", err
+	}
+	a.nonce = rel.nonce + 1
+	a.releases[r.ID] = rel
+	a.logger.Info("Release is submitted", "id", r.ID, "txHash", rel.current().Hash().Hex(), "nonce", rel.nonce)
+	return rel.current().Hash().Hex(), nil
+}
+
+// send signs and sends a transaction of rel with its current nonce and gas price.
+func (a *EvmAdapter) send(ctx context.Context, client Client, rel *release) error {
+	tx, err := a.txSigner.SignTx(ctx, types.NewTransaction(rel.nonce, rel.to, rel.value, rel.gasLimit, rel.gasPrice, rel.data), a.signer)
+	if err != nil {
+		return err
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	rel.txs = append(rel.txs, tx)
+	rel.sentAt = time.Now()
+	a.txs[tx.Hash()] = rel
+	return nil
+}
+
+// checkReleases updates releases which are not final at head. A release whose transaction has been dropped, or is
+// not included after ResubmitTimeout, is resubmitted with a higher gas price.
+func (a *EvmAdapter) checkReleases(ctx context.Context, head uint64) {
+	client := a.rpc()
+	a.releaseMtx.Lock()
+	defer a.releaseMtx.Unlock()
+	for _, rel := range a.releases {
+		if rel.final {
+			continue
+		}
+		receipt, err := includedReceipt(ctx, client, rel)
+		if err != nil {
+			a.logger.Warn("Failed to get receipt of release", "id", rel.ID, "err", err)
+			continue
+		}
+		if receipt != nil {
+			rel.receipt = receipt
+			if confirmations(head, receipt.BlockNumber.Uint64()) >= a.config.ConfirmationDepth {
+				rel.final = true
+				if receipt.Status != types.ReceiptStatusSuccessful {
+					a.logger.Error("Release failed", "id", rel.ID, "txHash", receipt.TxHash.Hex())
+				} else {
+					a.logger.Info("Release is final", "id", rel.ID, "txHash", receipt.TxHash.Hex())
+				}
+			}
+			continue
+		}
+		// the release is not included, or its block has been reorganised
+		rel.receipt = nil
+		_, _, err = client.TransactionByHash(ctx, rel.current().Hash())
+		dropped := err == ethereum.NotFound
+		if !dropped && time.Since(rel.sentAt) < a.config.ResubmitTimeout {
+			continue
+		}
+		if err := a.resubmit(ctx, client, rel); err != nil {
+			a.logger.Error("Failed to resubmit release", "id", rel.ID, "err", err)
+			continue
+		}
+		a.logger.Info("Release is resubmitted", "id", rel.ID, "dropped", dropped, "txHash", rel.current().Hash().Hex(),
+			"nonce", rel.nonce, "gasPrice", rel.gasPrice)
+	}
+}
+
+// resubmit sends rel again with a gas price high enough to replace its current transaction.
+func (a *EvmAdapter) resubmit(ctx context.Context, client Client, rel *release) error {
+	nonce, err := client.NonceAt(ctx, a.sender, nil)
+	if err != nil {
+		return err
+	}
+	if nonce > rel.nonce {
+		// the nonce is used by a transaction which is not a release
+		rel.nonce = a.nonce
+		a.nonce++
+	}
+	gasPrice, err := a.bumpGasPrice(ctx, client, rel.gasPrice)
+	if err != nil {
+		return err
+	}
+	rel.gasPrice = gasPrice
+	return a.send(ctx, client, rel)
+}
+
+// includedReceipt returns the receipt of the transaction of rel which is included, or nil if none is included.
+func includedReceipt(ctx context.Context, client Client, rel *release) (*types.Receipt, error) {
+	for _, tx := range rel.txs {
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		if err == ethereum.NotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return receipt, nil
+	}
+	return nil, nil
+}
+
+// abiString returns the ABI encoding of s in the tail of call data: its length followed by its bytes right padded to
+// 32 bytes.
+func abiString(s string) []byte {
+	length := common.LeftPadBytes(big.NewInt(int64(len(s))).Bytes(), common.HashLength)
+	padded := make([]byte, (len(s)+common.HashLength-1)/common.HashLength*common.HashLength)
+	copy(padded, s)
+	return append(length, padded...)
+}
+
+// decodeABIString decodes a string returned by a contract call.
+func decodeABIString(out []byte) (string, error) {
+	if len(out) < 2*common.HashLength {
+		return "", fmt.Errorf("invalid string of %v bytes", len(out))
+	}
+	offset := new(big.Int).SetBytes(out[:common.HashLength])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(out)-common.HashLength) {
+		return "", fmt.Errorf("invalid string offset %v", offset)
+	}
+	start := offset.Uint64() + common.HashLength
+	length := new(big.Int).SetBytes(out[start-common.HashLength : start])
+	if !length.IsUint64() || length.Uint64() > uint64(len(out))-start {
+		return "", fmt.Errorf("invalid string length %v", length)
+	}
+	return string(out[start : start+length.Uint64()]), nil
+}
+
+func isNonceTooLow(err error) bool {
+	return strings.Contains(err.Error(), "nonce too low")
+}
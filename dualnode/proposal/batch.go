@@ -0,0 +1,305 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/lib/merkle"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+var ErrInvalidBatch = errors.New("invalid batch")
+
+// Batch is the set of deposits released at once by a ProposalBatch. The destination chain only records the Merkle
+// root of the deposits, then each receiver claims its transfer with a proof of its deposit (see Batch.Proof).
+type Batch struct {
+	Root     []byte
+	Deposits []*dualnode.Deposit
+}
+
+// NewBatchProposal returns the proposal releasing deposits, fungible transfers of the same asset of a source chain,
+// on destChain at once. Its arguments are the Merkle root of the deposits followed by the RLP encoded deposits,
+// ordered by block and log index so that every validator builds the same proposal.
+func NewBatchProposal(deposits []*dualnode.Deposit, destChain string) (*Proposal, error) {
+	if len(deposits) == 0 {
+		return nil, fmt.Errorf("%w: no deposit", ErrInvalidBatch)
+	}
+	sorted := append([]*dualnode.Deposit{}, deposits...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].BlockNumber != sorted[j].BlockNumber {
+			return sorted[i].BlockNumber < sorted[j].BlockNumber
+		}
+		return sorted[i].LogIndex < sorted[j].LogIndex
+	})
+	first := sorted[0]
+	leaves := make([][]byte, len(sorted))
+	for i, d := range sorted {
+		if d.Chain != first.Chain || !strings.EqualFold(d.Token, first.Token) {
+			return nil, fmt.Errorf("%w: deposits of %v %v and %v %v", ErrInvalidBatch, first.Chain, first.Token,
+				d.Chain, d.Token)
+		}
+		if d.TokenID != nil {
+			return nil, fmt.Errorf("%w: NFT deposit %v is not batched", ErrInvalidBatch, d.TxHash)
+		}
+		data, err := rlp.EncodeToBytes(d)
+		if err != nil {
+			return nil, err
+		}
+		leaves[i] = data
+	}
+	root := merkle.SimpleHashFromByteSlices(leaves)
+	return &Proposal{
+		Type:        ProposalBatch,
+		SourceChain: first.Chain,
+		DestChain:   destChain,
+		Args:        append([][]byte{root}, leaves...),
+	}, nil
+}
+
+// Batch returns the deposits released by a ProposalBatch, after checking their root.
+func (p *Proposal) Batch() (*Batch, error) {
+	if p.Type != ProposalBatch || len(p.Args) < 2 {
+		return nil, fmt.Errorf("%v is not a batch proposal", p)
+	}
+	b := &Batch{Root: p.Args[0], Deposits: make([]*dualnode.Deposit, len(p.Args)-1)}
+	for i, data := range p.Args[1:] {
+		var d dualnode.Deposit
+		if err := rlp.DecodeBytes(data, &d); err != nil {
+			return nil, fmt.Errorf("invalid deposit %v of %v: %w", i, p, err)
+		}
+		b.Deposits[i] = &d
+	}
+	if !bytes.Equal(merkle.SimpleHashFromByteSlices(p.Args[1:]), b.Root) {
+		return nil, fmt.Errorf("%w: root of %v doesn't match its deposits", ErrInvalidBatch, p)
+	}
+	return b, nil
+}
+
+// Transfers returns the deposits released by a deposit or batch proposal.
+func (p *Proposal) Transfers() ([]*dualnode.Deposit, error) {
+	if p.Type == ProposalBatch {
+		b, err := p.Batch()
+		if err != nil {
+			return nil, err
+		}
+		return b.Deposits, nil
+	}
+	d, err := p.Deposit()
+	if err != nil {
+		return nil, err
+	}
+	return []*dualnode.Deposit{d}, nil
+}
+
+// Total returns the amount of the batch.
+func (b *Batch) Total() *big.Int {
+	total := new(big.Int)
+	for _, d := range b.Deposits {
+		total.Add(total, d.Amount)
+	}
+	return total
+}
+
+// Proof returns the proof of the deposit at index i, which its receiver presents to claim it.
+func (b *Batch) Proof(i int) (*merkle.SimpleProof, error) {
+	if i < 0 || i >= len(b.Deposits) {
+		return nil, fmt.Errorf("deposit %v is out of the batch of %v", i, len(b.Deposits))
+	}
+	leaves := make([][]byte, len(b.Deposits))
+	for j, d := range b.Deposits {
+		data, err := rlp.EncodeToBytes(d)
+		if err != nil {
+			return nil, err
+		}
+		leaves[j] = data
+	}
+	_, proofs := merkle.SimpleProofsFromByteSlices(leaves)
+	return proofs[i], nil
+}
+
+// VerifyBatchDeposit returns an error if proof doesn't prove that d is released by the batch with the given root.
+func VerifyBatchDeposit(root []byte, d *dualnode.Deposit, proof *merkle.SimpleProof) error {
+	data, err := rlp.EncodeToBytes(d)
+	if err != nil {
+		return err
+	}
+	return proof.Verify(root, data)
+}
+
+// Batching batches the small deposits of an asset of a source chain, sparing the gas of a release per deposit.
+type Batching struct {
+	Chain     string `yaml:"Chain"`     // source chain of the asset
+	Token     string `yaml:"Token"`     // token contract, empty for the chain's native coin
+	MaxAmount string `yaml:"MaxAmount"` // deposits up to this amount, in the smallest unit of the asset, are batched
+	Blocks    uint64 `yaml:"Blocks"`    // deposits of Blocks consecutive blocks of the source chain are batched together
+
+	maxAmount *big.Int
+}
+
+func (c *Batching) validate() error {
+	if c.Chain == "" {
+		return fmt.Errorf("Chain of batching is required")
+	}
+	if c.Blocks == 0 {
+		return fmt.Errorf("Blocks of %v is required", c)
+	}
+	var err error
+	if c.maxAmount, err = parseAmount(c.MaxAmount); err != nil {
+		return fmt.Errorf("invalid MaxAmount of %v: %w", c, err)
+	}
+	if c.maxAmount == nil {
+		return fmt.Errorf("MaxAmount of %v is required", c)
+	}
+	return nil
+}
+
+func (c *Batching) String() string {
+	return fmt.Sprintf("Batching{%v %v}", c.Chain, c.Token)
+}
+
+// batchKey identifies the batch of a deposit: its asset, destination and window of source blocks.
+type batchKey struct {
+	asset     string
+	destChain string
+	window    uint64
+}
+
+// Batcher groups the small deposits of source chains into batches. Validators observe the same deposits, so each
+// batch is built once the source chain is processed past its window, and every validator proposes the same batch.
+type Batcher struct {
+	mtx      sync.Mutex
+	configs  map[string]*Batching
+	pending  map[batchKey][]*dualnode.Deposit
+	deposits map[batchKey]map[string]struct{} // deposits of pending batches, received again by restarted adapters
+}
+
+// NewBatcher returns a batcher of the assets of configs.
+func NewBatcher(configs []*Batching) (*Batcher, error) {
+	b := &Batcher{
+		configs:  make(map[string]*Batching, len(configs)),
+		pending:  make(map[batchKey][]*dualnode.Deposit),
+		deposits: make(map[batchKey]map[string]struct{}),
+	}
+	for _, c := range configs {
+		if err := c.validate(); err != nil {
+			return nil, err
+		}
+		key := rateLimitKey(c.Chain, c.Token)
+		if _, ok := b.configs[key]; ok {
+			return nil, fmt.Errorf("%v is set twice", c)
+		}
+		b.configs[key] = c
+	}
+	return b, nil
+}
+
+// Add keeps d for the batch of its window if it is a small deposit of a batched asset, and reports whether it is
+// batched. Deposits which are not batched are proposed one by one.
+func (b *Batcher) Add(d *dualnode.Deposit, destChain string) bool {
+	key := rateLimitKey(d.Chain, d.Token)
+	c, ok := b.configs[key]
+	if !ok || d.TokenID != nil || d.Amount.Cmp(c.maxAmount) > 0 {
+		return false
+	}
+	bk := batchKey{asset: key, destChain: destChain, window: d.BlockNumber / c.Blocks}
+	id := fmt.Sprintf("%v/%v", strings.ToLower(d.TxHash), d.LogIndex)
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if _, ok := b.deposits[bk][id]; ok {
+		return true
+	}
+	if b.deposits[bk] == nil {
+		b.deposits[bk] = make(map[string]struct{})
+	}
+	b.deposits[bk][id] = struct{}{}
+	b.pending[bk] = append(b.pending[bk], d)
+	return true
+}
+
+// Flush returns the deposits of chain whose window ends at or before scanned, the latest block processed on chain,
+// grouped by batch. Each group is proposed with State.ProposeBatch.
+func (b *Batcher) Flush(chain string, scanned uint64) [][]*dualnode.Deposit {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	var keys []batchKey
+	for bk, deposits := range b.pending {
+		c := b.configs[bk.asset]
+		if deposits[0].Chain == chain && (bk.window+1)*c.Blocks <= scanned+1 {
+			keys = append(keys, bk)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].window != keys[j].window {
+			return keys[i].window < keys[j].window
+		}
+		return keys[i].asset+keys[i].destChain < keys[j].asset+keys[j].destChain
+	})
+	batches := make([][]*dualnode.Deposit, len(keys))
+	for i, bk := range keys {
+		batches[i] = b.pending[bk]
+		delete(b.pending, bk)
+		delete(b.deposits, bk)
+	}
+	return batches
+}
+
+// ProposeBatch creates and adds the proposal releasing deposits on destChain, and indexes each deposit as processed
+// by it. A single deposit is proposed with a deposit proposal instead. ErrDepositProcessed is returned if a deposit
+// has already been proposed.
+func (s *State) ProposeBatch(deposits []*dualnode.Deposit, destChain string) (*Proposal, Status, error) {
+	if len(deposits) == 1 {
+		return s.ProposeDeposit(deposits[0], destChain)
+	}
+	for _, d := range deposits {
+		processed, err := s.deposits.get(d.Chain, d.TxHash, d.LogIndex)
+		if err != nil {
+			return nil, StatusPending, err
+		}
+		if processed != nil {
+			return nil, StatusPending, fmt.Errorf("%w: %v %v %v by %v", ErrDepositProcessed, d.Chain, d.TxHash,
+				d.LogIndex, processed.ProposalHash.Hex())
+		}
+	}
+	p, err := NewBatchProposal(deposits, destChain)
+	if err != nil {
+		return nil, StatusPending, err
+	}
+	status, err := s.AddProposal(p)
+	if err != nil {
+		return nil, status, err
+	}
+	s.mtx.Lock()
+	height := s.height
+	s.mtx.Unlock()
+	processed := &ProcessedDeposit{ProposalHash: p.Hash(), Height: height}
+	for _, d := range deposits {
+		if err := s.deposits.put(d, processed); err != nil {
+			return nil, status, err
+		}
+	}
+	return p, status, nil
+}
@@ -0,0 +1,134 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+)
+
+func smallDeposit(txHash string, block uint64, amount int64) *dualnode.Deposit {
+	return &dualnode.Deposit{
+		Chain:       "ETH",
+		TxHash:      txHash,
+		BlockNumber: block,
+		Token:       "0x0A",
+		To:          "0x0C",
+		Amount:      big.NewInt(amount),
+	}
+}
+
+func TestBatchProposal(t *testing.T) {
+	deposits := []*dualnode.Deposit{smallDeposit("0x02", 12, 20), smallDeposit("0x01", 11, 10), smallDeposit("0x03", 12, 30)}
+	deposits[2].LogIndex = 1
+	p, err := NewBatchProposal(deposits, "KAI")
+	require.NoError(t, err)
+	reordered, err := NewBatchProposal([]*dualnode.Deposit{deposits[2], deposits[1], deposits[0]}, "KAI")
+	require.NoError(t, err)
+	require.Equal(t, p.Hash(), reordered.Hash())
+
+	b, err := p.Batch()
+	require.NoError(t, err)
+	require.Equal(t, []*dualnode.Deposit{deposits[1], deposits[0], deposits[2]}, b.Deposits)
+	require.Equal(t, int64(60), b.Total().Int64())
+	for i, d := range b.Deposits {
+		proof, err := b.Proof(i)
+		require.NoError(t, err)
+		require.NoError(t, VerifyBatchDeposit(b.Root, d, proof))
+	}
+	proof, err := b.Proof(0)
+	require.NoError(t, err)
+	require.Error(t, VerifyBatchDeposit(b.Root, deposits[0], proof))
+
+	// the root must match the deposits
+	p.Args[1] = p.Args[2]
+	_, err = p.Batch()
+	require.True(t, errors.Is(err, ErrInvalidBatch))
+
+	// deposits of other assets aren't batched together
+	other := smallDeposit("0x04", 12, 10)
+	other.Token = "0x0B"
+	_, err = NewBatchProposal([]*dualnode.Deposit{deposits[0], other}, "KAI")
+	require.True(t, errors.Is(err, ErrInvalidBatch))
+}
+
+func TestBatcher(t *testing.T) {
+	b, err := NewBatcher([]*Batching{{Chain: "ETH", Token: "0x0a", MaxAmount: "100", Blocks: 10}})
+	require.NoError(t, err)
+
+	require.False(t, b.Add(smallDeposit("0x01", 5, 101), "KAI"))
+	other := smallDeposit("0x02", 5, 10)
+	other.Token = "0x0B"
+	require.False(t, b.Add(other, "KAI"))
+
+	first := []*dualnode.Deposit{smallDeposit("0x03", 5, 10), smallDeposit("0x04", 9, 100)}
+	for _, d := range append(first, first[0]) {
+		require.True(t, b.Add(d, "KAI"))
+	}
+	second := smallDeposit("0x05", 10, 10)
+	require.True(t, b.Add(second, "KAI"))
+
+	// a batch is built once the source chain is processed past its window
+	require.Empty(t, b.Flush("ETH", 8))
+	require.Empty(t, b.Flush("BSC", 20))
+	require.Equal(t, [][]*dualnode.Deposit{first}, b.Flush("ETH", 9))
+	require.Empty(t, b.Flush("ETH", 9))
+	require.Equal(t, [][]*dualnode.Deposit{{second}}, b.Flush("ETH", 25))
+}
+
+func TestState_proposeBatch(t *testing.T) {
+	keys, vals := newValidators(t, 4)
+	s := newTestState(t, memorydb.New(), keys[0], vals, 0)
+	require.NoError(t, s.SetRateLimits([]*RateLimit{{Chain: "ETH", Token: "0x0A", MaxAmount: "50", Window: 10}}))
+	deposits := []*dualnode.Deposit{smallDeposit("0x01", 11, 20), smallDeposit("0x02", 12, 20)}
+
+	p, status, err := s.ProposeBatch(deposits, "KAI")
+	require.NoError(t, err)
+	require.Equal(t, ProposalBatch, p.Type)
+	require.Equal(t, StatusPending, status)
+	for _, d := range deposits {
+		processed, err := s.ProcessedDeposit(d.Chain, d.TxHash, d.LogIndex)
+		require.NoError(t, err)
+		require.Equal(t, p.Hash(), processed.ProposalHash)
+	}
+	_, _, err = s.ProposeBatch(deposits, "KAI")
+	require.True(t, errors.Is(err, ErrDepositProcessed))
+
+	// the deposits of a batch count in rate limits
+	_, status, err = s.ProposeDeposit(smallDeposit("0x03", 13, 20), "KAI")
+	require.NoError(t, err)
+	require.Equal(t, StatusQueued, status)
+
+	// a reorganised deposit cancels its batch, whose other deposits are batched again
+	hash, err := s.CancelDeposit(deposits[0])
+	require.NoError(t, err)
+	require.Equal(t, p.Hash(), hash)
+	processed, err := s.ProcessedDeposit("ETH", "0x02", 0)
+	require.NoError(t, err)
+	require.Nil(t, processed)
+	reproposed, _, err := s.ProposeBatch(deposits[1:], "KAI")
+	require.NoError(t, err)
+	require.Equal(t, ProposalDeposit, reproposed.Type)
+}
@@ -0,0 +1,164 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+// Processed deposits are stored in the baseKeyDeposit namespace of the pool under chain + "/" + tx hash + "/" + log
+// index. Unlike proposals, they are never pruned.
+const baseKeyDeposit = "deposit/"
+
+var ErrDepositProcessed = errors.New("deposit is already processed")
+
+// ProcessedDeposit is the proposal created for a deposit.
+type ProcessedDeposit struct {
+	ProposalHash common.Hash
+	Height       uint64 // height at which the proposal is created
+}
+
+// depositIndex is the durable index of deposits for which a proposal has been created, it prevents a restarted or
+// re-synced watcher from proposing a deposit twice.
+type depositIndex struct {
+	db kaidb.KeyValueStore
+}
+
+func (idx *depositIndex) get(chain, txHash string, logIndex uint) (*ProcessedDeposit, error) {
+	data, _ := idx.db.Get(keyDeposit(chain, txHash, logIndex))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var processed ProcessedDeposit
+	if err := rlp.DecodeBytes(data, &processed); err != nil {
+		return nil, fmt.Errorf("invalid processed deposit %v %v %v: %w", chain, txHash, logIndex, err)
+	}
+	return &processed, nil
+}
+
+func (idx *depositIndex) put(d *dualnode.Deposit, processed *ProcessedDeposit) error {
+	data, err := rlp.EncodeToBytes(processed)
+	if err != nil {
+		return err
+	}
+	return idx.db.Put(keyDeposit(d.Chain, d.TxHash, d.LogIndex), data)
+}
+
+func (idx *depositIndex) delete(d *dualnode.Deposit) error {
+	return idx.db.Delete(keyDeposit(d.Chain, d.TxHash, d.LogIndex))
+}
+
+func keyDeposit(chain, txHash string, logIndex uint) []byte {
+	key := []byte(chain + "/" + strings.ToLower(txHash) + "/")
+	return append(key, encodeUint64(uint64(logIndex))...)
+}
+
+func encodeUint64(n uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, n)
+	return b
+}
+
+// ProposeDeposit creates and adds the proposal releasing d on destChain, unless a proposal has already been created
+// for d, in which case ErrDepositProcessed is returned.
+func (s *State) ProposeDeposit(d *dualnode.Deposit, destChain string) (*Proposal, Status, error) {
+	processed, err := s.deposits.get(d.Chain, d.TxHash, d.LogIndex)
+	if err != nil {
+		return nil, StatusPending, err
+	}
+	if processed != nil {
+		return nil, StatusPending, fmt.Errorf("%w: %v %v %v by %v", ErrDepositProcessed, d.Chain, d.TxHash, d.LogIndex,
+			processed.ProposalHash.Hex())
+	}
+	p, err := NewDepositProposal(d, destChain)
+	if err != nil {
+		return nil, StatusPending, err
+	}
+	// adding a proposal twice is harmless, so the deposit is indexed once its proposal is stored
+	status, err := s.AddProposal(p)
+	if err != nil {
+		return nil, status, err
+	}
+	s.mtx.Lock()
+	height := s.height
+	s.mtx.Unlock()
+	if err := s.deposits.put(d, &ProcessedDeposit{ProposalHash: p.Hash(), Height: height}); err != nil {
+		return nil, status, err
+	}
+	return p, status, nil
+}
+
+// ProcessedDeposit returns the proposal created for the deposit at logIndex of transaction txHash on chain, or nil
+// if the deposit hasn't been handled.
+func (s *State) ProcessedDeposit(chain, txHash string, logIndex uint) (*ProcessedDeposit, error) {
+	return s.deposits.get(chain, txHash, logIndex)
+}
+
+// CancelDeposit cancels the proposal of d, a deposit reorganised out of its source chain, and returns the hash of
+// the proposal for the node to invalidate it. The deposit can be proposed again if its transaction is included in
+// the new chain. ErrProposalExecuted is returned if the proposal is already executed. If the proposal is a batch,
+// its other deposits are removed from the index too, for the caller to propose them again.
+func (s *State) CancelDeposit(d *dualnode.Deposit) (common.Hash, error) {
+	processed, err := s.deposits.get(d.Chain, d.TxHash, d.LogIndex)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if processed == nil {
+		return common.Hash{}, fmt.Errorf("%w of deposit %v %v %v", ErrUnknownProposal, d.Chain, d.TxHash, d.LogIndex)
+	}
+	hash := processed.ProposalHash
+	var batched []*dualnode.Deposit
+	s.mtx.Lock()
+	if r, ok := s.records[hash]; ok {
+		if r.Status == StatusExecuted {
+			s.mtx.Unlock()
+			return hash, fmt.Errorf("%w: deposit %v %v is reorganised after its release %v", ErrProposalExecuted,
+				d.Chain, d.TxHash, r.ExecutedTx)
+		}
+		if !r.Status.final() {
+			r.Status = StatusCancelled
+			r.FinalHeight = s.height
+			if err := s.save(hash, r, nil); err != nil {
+				s.mtx.Unlock()
+				return hash, err
+			}
+			s.logger.Warn("Proposal of reorganised deposit is cancelled", "hash", hash.Hex(), "txHash", d.TxHash)
+		}
+		if r.Proposal != nil && r.Proposal.Type == ProposalBatch {
+			if b, err := r.Proposal.Batch(); err == nil {
+				batched = b.Deposits
+			}
+		}
+	}
+	s.mtx.Unlock()
+	for _, other := range batched {
+		if err := s.deposits.delete(other); err != nil {
+			return hash, err
+		}
+	}
+	return hash, s.deposits.delete(d)
+}
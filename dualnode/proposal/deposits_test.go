@@ -0,0 +1,131 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+)
+
+func TestState_proposeDeposit(t *testing.T) {
+	keys, vals := newValidators(t, 4)
+	db := memorydb.New()
+	s := newTestState(t, db, keys[0], vals, 0)
+	require.NoError(t, s.Update(7))
+
+	p := testProposal(t)
+	d, err := p.Deposit()
+	require.NoError(t, err)
+	processed, err := s.ProcessedDeposit(d.Chain, d.TxHash, d.LogIndex)
+	require.NoError(t, err)
+	require.Nil(t, processed)
+
+	created, status, err := s.ProposeDeposit(d, "KAI")
+	require.NoError(t, err)
+	require.Equal(t, p.Hash(), created.Hash())
+	require.Equal(t, StatusPending, status)
+
+	// the index survives restarts, tx hashes are matched case-insensitively
+	restarted := newTestState(t, db, keys[0], vals, 0)
+	_, _, err = restarted.ProposeDeposit(d, "KAI")
+	require.True(t, errors.Is(err, ErrDepositProcessed))
+	processed, err = restarted.ProcessedDeposit(d.Chain, "0X01", d.LogIndex)
+	require.NoError(t, err)
+	require.Equal(t, p.Hash(), processed.ProposalHash)
+	require.Equal(t, uint64(7), processed.Height)
+
+	// other deposits of the transaction are not processed
+	d.LogIndex++
+	_, _, err = restarted.ProposeDeposit(d, "KAI")
+	require.NoError(t, err)
+}
+
+func TestState_cancelDeposit(t *testing.T) {
+	keys, vals := newValidators(t, 4)
+	s := newTestState(t, memorydb.New(), keys[0], vals, 0)
+	d, err := testProposal(t).Deposit()
+	require.NoError(t, err)
+
+	_, err = s.CancelDeposit(d)
+	require.True(t, errors.Is(err, ErrUnknownProposal))
+
+	p, _, err := s.ProposeDeposit(d, "KAI")
+	require.NoError(t, err)
+	_, err = s.AddVote(vote(t, p, keys[1]))
+	require.NoError(t, err)
+
+	hash, err := s.CancelDeposit(d)
+	require.NoError(t, err)
+	require.Equal(t, p.Hash(), hash)
+	status, _ := s.Status(hash)
+	require.Equal(t, StatusCancelled, status)
+
+	// the invalidation of the node is gossiped, votes of the cancelled proposal are not
+	inv, err := SignInvalidation(hash, keys[0])
+	require.NoError(t, err)
+	status, err = s.AddInvalidation(inv)
+	require.NoError(t, err)
+	require.Equal(t, StatusCancelled, status)
+	require.Equal(t, []message{inv}, gossipedMessages(s.pool))
+
+	// the deposit included in another block of the new chain is proposed again
+	d.BlockNumber++
+	reproposed, status, err := s.ProposeDeposit(d, "KAI")
+	require.NoError(t, err)
+	require.NotEqual(t, p.Hash(), reproposed.Hash())
+	require.Equal(t, StatusPending, status)
+}
+
+func TestState_addInvalidation(t *testing.T) {
+	keys, vals := newValidators(t, 4)
+	s := newTestState(t, memorydb.New(), keys[0], vals, 0)
+	p := testProposal(t)
+	_, err := s.AddProposal(p)
+	require.NoError(t, err)
+	for _, key := range keys[:3] {
+		_, err = s.AddVote(vote(t, p, key))
+		require.NoError(t, err)
+	}
+
+	// more than two thirds of the voting power cancels an executable proposal
+	for i, key := range keys[1:] {
+		inv, err := SignInvalidation(p.Hash(), key)
+		require.NoError(t, err)
+		status, err := s.AddInvalidation(inv)
+		require.NoError(t, err)
+		if i < 2 {
+			require.Equal(t, StatusExecutable, status)
+		} else {
+			require.Equal(t, StatusCancelled, status)
+		}
+	}
+	require.Empty(t, s.Executable())
+
+	// a validator invalidates a proposal once, and an invalidation is not a vote
+	inv, err := SignInvalidation(p.Hash(), keys[1])
+	require.NoError(t, err)
+	_, err = s.AddInvalidation(inv)
+	require.True(t, errors.Is(err, ErrDuplicateVote))
+	forged := &Vote{ProposalHash: inv.ProposalHash, ValidatorAddress: inv.ValidatorAddress, Signature: inv.Signature}
+	require.True(t, errors.Is(forged.Verify(), ErrInvalidSignature))
+}
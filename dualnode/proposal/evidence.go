@@ -0,0 +1,153 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+	"github.com/kardiachain/go-kardia/types"
+	"github.com/kardiachain/go-kardia/types/evidence"
+)
+
+// depositReadTimeout is the timeout of reading the deposits of a transaction from its chain.
+const depositReadTimeout = 10 * time.Second
+
+// Bytes returns the RLP encoding of p, whose hash identifies it.
+func (p *Proposal) Bytes() []byte {
+	data, _ := rlp.EncodeToBytes(p)
+	return data
+}
+
+// BridgeVote returns v as the vote of a bridge validator, which evidence of misbehavior is made of.
+func (v *Vote) BridgeVote() *types.BridgeVote {
+	return &types.BridgeVote{ProposalHash: v.ProposalHash, ValidatorAddress: v.ValidatorAddress, Signature: v.Signature}
+}
+
+// ConflictingVote returns a vote of the validator of v for another proposal releasing a deposit of the proposal of
+// v, and that proposal. Cancelled proposals are skipped since their deposits are proposed again honestly when their
+// batch is cancelled. Nil is returned if there is no such vote or the proposal of v is unknown.
+func (s *State) ConflictingVote(v *Vote) (*Vote, *Proposal) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	r, ok := s.records[v.ProposalHash]
+	if !ok || r.Proposal == nil || !r.Proposal.Type.transfer() {
+		return nil, nil
+	}
+	deposits, err := r.Proposal.Transfers()
+	if err != nil {
+		return nil, nil
+	}
+	released := make(map[string]bool, len(deposits))
+	for _, d := range deposits {
+		released[releaseKey(d)] = true
+	}
+	for hash, other := range s.records {
+		if hash == v.ProposalHash || other.Proposal == nil || !other.Proposal.Type.transfer() ||
+			other.Status == StatusCancelled {
+			continue
+		}
+		var vote *Vote
+		for _, ov := range other.Votes {
+			if ov.ValidatorAddress.Equal(v.ValidatorAddress) {
+				vote = ov
+				break
+			}
+		}
+		if vote == nil {
+			continue
+		}
+		deposits, err := other.Proposal.Transfers()
+		if err != nil {
+			continue
+		}
+		for _, d := range deposits {
+			if released[releaseKey(d)] {
+				return vote, other.Proposal
+			}
+		}
+	}
+	return nil, nil
+}
+
+// releaseKey identifies d in a block of its chain, like types.BridgeDeposit.
+func releaseKey(d *dualnode.Deposit) string {
+	return fmt.Sprintf("%s/%s/%d/%d", d.Chain, strings.ToLower(d.TxHash), d.LogIndex, d.BlockNumber)
+}
+
+// DepositVerifier checks the deposits of bridge evidence with the adapters of their source chains.
+type DepositVerifier struct {
+	readers map[string]dualnode.DepositReader // by chain name
+}
+
+var _ evidence.BridgeVerifier = (*DepositVerifier)(nil)
+
+// NewDepositVerifier returns a verifier reading deposits with the adapters which implement dualnode.DepositReader.
+func NewDepositVerifier(adapters []dualnode.ChainAdapter) *DepositVerifier {
+	readers := make(map[string]dualnode.DepositReader)
+	for _, adapter := range adapters {
+		if reader, ok := adapter.(dualnode.DepositReader); ok {
+			readers[adapter.Name()] = reader
+		}
+	}
+	return &DepositVerifier{readers: readers}
+}
+
+// HasDeposit reports whether the transaction of d contains a deposit identical to d. An undecodable deposit is
+// reported as missing.
+func (v *DepositVerifier) HasDeposit(d *types.BridgeDeposit) (bool, error) {
+	reader, ok := v.readers[d.Chain]
+	if !ok {
+		return false, fmt.Errorf("no adapter reads deposits of %v", d.Chain)
+	}
+	var proposed dualnode.Deposit
+	if err := rlp.DecodeBytes(d.Data, &proposed); err != nil {
+		return false, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), depositReadTimeout)
+	defer cancel()
+	deposits, err := reader.Deposits(ctx, proposed.TxHash)
+	if err != nil {
+		return false, err
+	}
+	for _, found := range deposits {
+		if found.LogIndex == proposed.LogIndex {
+			return sameDeposit(found, &proposed), nil
+		}
+	}
+	return false, nil
+}
+
+// sameDeposit reports whether a deposit read from its chain matches a proposed one. The metadata URI of an NFT is
+// not compared since it is read from its contract, which may change it.
+func sameDeposit(found, proposed *dualnode.Deposit) bool {
+	if found.BlockNumber != proposed.BlockNumber || !strings.EqualFold(found.Token, proposed.Token) ||
+		!strings.EqualFold(found.From, proposed.From) || !strings.EqualFold(found.To, proposed.To) ||
+		found.Amount.Cmp(proposed.Amount) != 0 {
+		return false
+	}
+	if found.TokenID == nil || proposed.TokenID == nil {
+		return found.TokenID == nil && proposed.TokenID == nil
+	}
+	return found.TokenID.Cmp(proposed.TokenID) == 0
+}
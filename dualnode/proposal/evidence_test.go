@@ -0,0 +1,138 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// readerAdapter is an adapter of ETH returning fixed deposits.
+type readerAdapter struct {
+	dualnode.ChainAdapter
+	deposits []*dualnode.Deposit
+}
+
+func (a *readerAdapter) Name() string { return "ETH" }
+
+func (a *readerAdapter) Deposits(ctx context.Context, txHash string) ([]*dualnode.Deposit, error) {
+	return a.deposits, nil
+}
+
+func TestBridgeEvidence_encoding(t *testing.T) {
+	keys, _ := newValidators(t, 1)
+	p := testProposal(t)
+	require.NoError(t, vote(t, p, keys[0]).BridgeVote().Verify())
+	inv, err := SignInvalidation(p.Hash(), keys[0])
+	require.NoError(t, err)
+	forged := &types.BridgeVote{ProposalHash: inv.ProposalHash, ValidatorAddress: inv.ValidatorAddress, Signature: inv.Signature}
+	require.Error(t, forged.Verify())
+
+	hash, deposits, err := types.DecodeBridgeProposal(p.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, p.Hash(), hash)
+	require.Len(t, deposits, 1)
+	require.Equal(t, "0x01", deposits[0].TxHash)
+	require.Equal(t, uint(2), deposits[0].LogIndex)
+	require.Equal(t, uint64(10), deposits[0].BlockNumber)
+
+	batch, err := NewBatchProposal([]*dualnode.Deposit{smallDeposit("0x01", 11, 10), smallDeposit("0x02", 12, 20)}, "KAI")
+	require.NoError(t, err)
+	hash, deposits, err = types.DecodeBridgeProposal(batch.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, batch.Hash(), hash)
+	require.Len(t, deposits, 2)
+	require.Equal(t, "0x02", deposits[1].TxHash)
+}
+
+func TestState_conflictingVote(t *testing.T) {
+	keys, vals := newValidators(t, 4)
+	s := newTestState(t, memorydb.New(), keys[0], vals, 0)
+	p := testProposal(t)
+	d, err := p.Deposit()
+	require.NoError(t, err)
+	other, err := NewDepositProposal(d, "TRX")
+	require.NoError(t, err)
+	for _, proposal := range []*Proposal{p, other} {
+		_, err = s.AddProposal(proposal)
+		require.NoError(t, err)
+	}
+	_, err = s.AddVote(vote(t, p, keys[1]))
+	require.NoError(t, err)
+
+	found, proposal := s.ConflictingVote(vote(t, other, keys[2]))
+	require.Nil(t, found)
+	require.Nil(t, proposal)
+
+	v := vote(t, other, keys[1])
+	found, proposal = s.ConflictingVote(v)
+	require.NotNil(t, found)
+	require.Equal(t, p.Hash(), proposal.Hash())
+	ev := types.NewConflictingBridgeVoteEvidence(found.BridgeVote(), v.BridgeVote(), proposal.Bytes(), other.Bytes(), 1,
+		time.Now(), vals)
+	require.NotNil(t, ev)
+	require.NoError(t, ev.ValidateBasic())
+
+	// the deposit proposed again in another block doesn't conflict
+	d.BlockNumber++
+	reproposed, err := NewDepositProposal(d, "KAI")
+	require.NoError(t, err)
+	_, err = s.AddProposal(reproposed)
+	require.NoError(t, err)
+	found, _ = s.ConflictingVote(vote(t, reproposed, keys[1]))
+	require.Nil(t, found)
+}
+
+func TestDepositVerifier(t *testing.T) {
+	p := testProposal(t)
+	d, err := p.Deposit()
+	require.NoError(t, err)
+	found := *d
+	found.Token = "0x0a"
+	verifier := NewDepositVerifier([]dualnode.ChainAdapter{&readerAdapter{deposits: []*dualnode.Deposit{&found}}})
+
+	_, deposits, err := types.DecodeBridgeProposal(p.Bytes())
+	require.NoError(t, err)
+	ok, err := verifier.HasDeposit(deposits[0])
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// a deposit with another amount is not in the chain
+	d.Amount = big.NewInt(1000)
+	forged, err := NewDepositProposal(d, "KAI")
+	require.NoError(t, err)
+	_, deposits, err = types.DecodeBridgeProposal(forged.Bytes())
+	require.NoError(t, err)
+	ok, err = verifier.HasDeposit(deposits[0])
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// deposits of chains without a reader can't be verified
+	deposits[0].Chain = "NEO"
+	_, err = verifier.HasDeposit(deposits[0])
+	require.Error(t, err)
+}
@@ -0,0 +1,246 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+// Accrued fees are stored in the baseKeyFee namespace of the pool under chain + "/" + token.
+const baseKeyFee = "fee/"
+
+// maxBasisPoints is 100%.
+const maxBasisPoints = 10000
+
+var ErrInvalidWithdrawal = errors.New("invalid fee withdrawal")
+
+// Fee is the fee deducted from the transfers of an asset of a source chain, it is accrued in the asset.
+type Fee struct {
+	Chain       string `yaml:"Chain"`       // source chain of the asset
+	Token       string `yaml:"Token"`       // token contract, empty for the chain's native coin
+	Flat        string `yaml:"Flat"`        // decimal amount charged per transfer in the smallest unit of the asset
+	BasisPoints uint64 `yaml:"BasisPoints"` // part of the amount charged, in hundredths of a percent
+
+	flat *big.Int
+}
+
+func (f *Fee) validate() error {
+	if f.Chain == "" {
+		return fmt.Errorf("Chain of fee is required")
+	}
+	var err error
+	if f.flat, err = parseAmount(f.Flat); err != nil {
+		return fmt.Errorf("invalid Flat of %v: %w", f, err)
+	}
+	if f.BasisPoints > maxBasisPoints {
+		return fmt.Errorf("BasisPoints of %v are above %v", f, maxBasisPoints)
+	}
+	return nil
+}
+
+func (f *Fee) String() string {
+	return fmt.Sprintf("Fee{%v %v}", f.Chain, f.Token)
+}
+
+// deduct returns the part of amount which is transferred and the fee, which never exceeds amount.
+func (f *Fee) deduct(amount *big.Int) (*big.Int, *big.Int) {
+	fee := new(big.Int).Mul(amount, new(big.Int).SetUint64(f.BasisPoints))
+	fee.Div(fee, big.NewInt(maxBasisPoints))
+	if f.flat != nil {
+		fee.Add(fee, f.flat)
+	}
+	if fee.Cmp(amount) > 0 {
+		fee.Set(amount)
+	}
+	return new(big.Int).Sub(amount, fee), fee
+}
+
+// FeeWithdrawal is the argument of ProposalWithdrawFees, which sweeps fees accrued in an asset to the treasury of a
+// chain, the source chain of the asset or the chain it is transferred to. The executor converts the amount to the
+// asset of the destination chain, see token_registry.Registry.Convert.
+type FeeWithdrawal struct {
+	Chain     string // source chain of the asset
+	Token     string
+	Amount    *big.Int
+	DestChain string
+	Treasury  string // treasury address of DestChain
+	Nonce     uint64 // distinguishes withdrawals of the same amount
+}
+
+// NewWithdrawFeesProposal returns the proposal executing w.
+func NewWithdrawFeesProposal(w *FeeWithdrawal) (*Proposal, error) {
+	data, err := rlp.EncodeToBytes(w)
+	if err != nil {
+		return nil, err
+	}
+	return &Proposal{Type: ProposalWithdrawFees, SourceChain: w.Chain, DestChain: w.DestChain, Args: [][]byte{data}}, nil
+}
+
+// FeeWithdrawal returns the argument of a ProposalWithdrawFees.
+func (p *Proposal) FeeWithdrawal() (*FeeWithdrawal, error) {
+	if p.Type != ProposalWithdrawFees || len(p.Args) != 1 {
+		return nil, fmt.Errorf("%v is not a fee withdrawal proposal", p)
+	}
+	var w FeeWithdrawal
+	if err := rlp.DecodeBytes(p.Args[0], &w); err != nil {
+		return nil, fmt.Errorf("invalid fee withdrawal of %v: %w", p, err)
+	}
+	if w.Chain != p.SourceChain || w.DestChain != p.DestChain {
+		return nil, fmt.Errorf("fee withdrawal of %v doesn't match its chains", p)
+	}
+	return &w, nil
+}
+
+// accruedFee is the stored amount of fees accrued in an asset.
+type accruedFee struct {
+	Chain  string
+	Token  string
+	Amount *big.Int
+}
+
+func feeKey(chain, token string) string {
+	return chain + "/" + strings.ToLower(token)
+}
+
+// SetFees replaces the fees of transfers and the treasury addresses of chains receiving fee withdrawals.
+func (s *State) SetFees(fees []*Fee, treasuries map[string]string) error {
+	byAsset := make(map[string]*Fee, len(fees))
+	for _, f := range fees {
+		if err := f.validate(); err != nil {
+			return err
+		}
+		key := feeKey(f.Chain, f.Token)
+		if _, ok := byAsset[key]; ok {
+			return fmt.Errorf("%v is set twice", f)
+		}
+		byAsset[key] = f
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.fees = byAsset
+	s.treasuries = treasuries
+	return nil
+}
+
+// TransferFee returns the amount of d which is released and the fee deducted from it. NFTs are not charged.
+func (s *State) TransferFee(d *dualnode.Deposit) (*big.Int, *big.Int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.transferFee(d)
+}
+
+func (s *State) transferFee(d *dualnode.Deposit) (*big.Int, *big.Int) {
+	if d.TokenID != nil {
+		// an NFT can't be split, it is transferred without fee
+		return new(big.Int).Set(d.Amount), new(big.Int)
+	}
+	if f, ok := s.fees[feeKey(d.Chain, d.Token)]; ok {
+		return f.deduct(d.Amount)
+	}
+	return new(big.Int).Set(d.Amount), new(big.Int)
+}
+
+// AccruedFees returns the fees accrued in an asset which are not withdrawn.
+func (s *State) AccruedFees(chain, token string) *big.Int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if fee, ok := s.accrued[feeKey(chain, token)]; ok {
+		return new(big.Int).Set(fee.Amount)
+	}
+	return new(big.Int)
+}
+
+func (s *State) loadAccruedFees() error {
+	return s.pool.iterate(s.pool.fees, func(key, value []byte) error {
+		var fee accruedFee
+		if err := rlp.DecodeBytes(value, &fee); err != nil {
+			return fmt.Errorf("invalid accrued fee %q: %w", key, err)
+		}
+		s.accrued[feeKey(fee.Chain, fee.Token)] = &fee
+		return nil
+	})
+}
+
+// checkWithdrawal returns an error if w doesn't pay the treasury of its destination chain or exceeds the fees
+// accrued in its asset.
+func (s *State) checkWithdrawal(w *FeeWithdrawal) error {
+	treasury, ok := s.treasuries[w.DestChain]
+	if !ok || !strings.EqualFold(treasury, w.Treasury) {
+		return fmt.Errorf("%w: %v is not the treasury of %v", ErrInvalidWithdrawal, w.Treasury, w.DestChain)
+	}
+	if w.Amount == nil || w.Amount.Sign() <= 0 {
+		return fmt.Errorf("%w: invalid amount %v", ErrInvalidWithdrawal, w.Amount)
+	}
+	if accrued := s.accrued[feeKey(w.Chain, w.Token)]; accrued == nil || accrued.Amount.Cmp(w.Amount) < 0 {
+		return fmt.Errorf("%w: %v of %v %v is above accrued fees", ErrInvalidWithdrawal, w.Amount, w.Chain, w.Token)
+	}
+	return nil
+}
+
+// feeUpdate returns the accrued fee changed by executing p, or nil if p doesn't change fees.
+func (s *State) feeUpdate(p *Proposal) (*accruedFee, error) {
+	var chain, token string
+	var delta *big.Int
+	switch p.Type {
+	case ProposalDeposit:
+		d, err := p.Deposit()
+		if err != nil {
+			return nil, err
+		}
+		chain, token = d.Chain, d.Token
+		_, delta = s.transferFee(d)
+	case ProposalWithdrawFees:
+		w, err := p.FeeWithdrawal()
+		if err != nil {
+			return nil, err
+		}
+		chain, token = w.Chain, w.Token
+		delta = new(big.Int).Neg(w.Amount)
+	default:
+		return nil, nil
+	}
+	if delta.Sign() == 0 {
+		return nil, nil
+	}
+	fee := &accruedFee{Chain: chain, Token: token, Amount: new(big.Int)}
+	if accrued, ok := s.accrued[feeKey(chain, token)]; ok {
+		fee.Amount.Set(accrued.Amount)
+	}
+	if fee.Amount.Add(fee.Amount, delta).Sign() < 0 {
+		s.logger.Error("Fee withdrawal is above accrued fees", "proposal", p, "missing", new(big.Int).Neg(fee.Amount))
+		fee.Amount.SetUint64(0)
+	}
+	return fee, nil
+}
+
+// write returns the write storing fee in store.
+func (fee *accruedFee) write(store *kaidb.PrefixStore) (write, error) {
+	data, err := rlp.EncodeToBytes(fee)
+	if err != nil {
+		return write{}, err
+	}
+	return write{store: store, key: []byte(feeKey(fee.Chain, fee.Token)), value: data}, nil
+}
@@ -0,0 +1,114 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+)
+
+func TestFee_deduct(t *testing.T) {
+	fee := &Fee{Chain: "ETH", Flat: "10", BasisPoints: 30}
+	require.NoError(t, fee.validate())
+	net, charged := fee.deduct(big.NewInt(10000))
+	require.Equal(t, big.NewInt(9960), net)
+	require.Equal(t, big.NewInt(40), charged)
+
+	// the fee never exceeds the transfer
+	net, charged = fee.deduct(big.NewInt(5))
+	require.Equal(t, int64(0), net.Int64())
+	require.Equal(t, big.NewInt(5), charged)
+
+	require.Error(t, (&Fee{Chain: "ETH", BasisPoints: maxBasisPoints + 1}).validate())
+	require.Error(t, (&Fee{Chain: "ETH", Flat: "0x10"}).validate())
+}
+
+func TestState_fees(t *testing.T) {
+	keys, vals := newValidators(t, 4)
+	db := memorydb.New()
+	s := newTestState(t, db, keys[0], vals, 0)
+	treasuries := map[string]string{"KAI": "0x00000000000000000000000000000000000000Fe"}
+	require.NoError(t, s.SetFees([]*Fee{{Chain: "ETH", Token: "0x0A", BasisPoints: 100}}, treasuries))
+
+	execute := func(s *State, p *Proposal) {
+		_, err := s.AddProposal(p)
+		require.NoError(t, err)
+		for _, key := range keys[:3] {
+			_, err = s.AddVote(vote(t, p, key))
+			require.NoError(t, err)
+		}
+		require.NoError(t, s.MarkExecuted(p.Hash(), "0xabc"))
+	}
+
+	transfer := testProposal(t)
+	d, err := transfer.Deposit()
+	require.NoError(t, err)
+	net, fee := s.TransferFee(d)
+	require.Equal(t, big.NewInt(99), net)
+	require.Equal(t, big.NewInt(1), fee)
+	execute(s, transfer)
+	execute(s, transferProposal(t, "0x02", 1000))
+	require.Equal(t, big.NewInt(11), s.AccruedFees("ETH", "0x0a"))
+
+	// transfers of assets without fee are not charged
+	net, fee = s.TransferFee(&dualnode.Deposit{Chain: "BSC", Amount: big.NewInt(100)})
+	require.Equal(t, big.NewInt(100), net)
+	require.Equal(t, int64(0), fee.Int64())
+	net, fee = s.TransferFee(&dualnode.Deposit{Chain: "ETH", Token: "0x0A", Amount: big.NewInt(1), TokenID: big.NewInt(7)})
+	require.Equal(t, big.NewInt(1), net)
+	require.Equal(t, int64(0), fee.Int64())
+
+	restarted := newTestState(t, db, keys[0], vals, 0)
+	require.NoError(t, restarted.SetFees(nil, treasuries))
+	require.Equal(t, big.NewInt(11), restarted.AccruedFees("ETH", "0x0A"))
+
+	withdrawal := &FeeWithdrawal{
+		Chain:     "ETH",
+		Token:     "0x0A",
+		Amount:    big.NewInt(12),
+		DestChain: "KAI",
+		Treasury:  "0x00000000000000000000000000000000000000fe",
+	}
+	p, err := NewWithdrawFeesProposal(withdrawal)
+	require.NoError(t, err)
+	_, err = restarted.AddProposal(p)
+	require.True(t, errors.Is(err, ErrInvalidWithdrawal))
+
+	withdrawal.Amount = big.NewInt(10)
+	withdrawal.Treasury = "0x00000000000000000000000000000000000000Ff"
+	p, err = NewWithdrawFeesProposal(withdrawal)
+	require.NoError(t, err)
+	_, err = restarted.AddProposal(p)
+	require.True(t, errors.Is(err, ErrInvalidWithdrawal))
+
+	withdrawal.Treasury = treasuries["KAI"]
+	p, err = NewWithdrawFeesProposal(withdrawal)
+	require.NoError(t, err)
+	decoded, err := p.FeeWithdrawal()
+	require.NoError(t, err)
+	require.Equal(t, withdrawal, decoded)
+	execute(restarted, p)
+	require.Equal(t, big.NewInt(1), restarted.AccruedFees("ETH", "0x0A"))
+}
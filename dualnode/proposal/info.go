@@ -0,0 +1,102 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"sort"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// ProposalInfo is the progress of a proposal.
+type ProposalInfo struct {
+	Hash          common.Hash
+	Proposal      *Proposal // nil while only votes of the proposal are known
+	Status        Status
+	Height        uint64 // height at which the proposal or its first vote is received
+	FinalHeight   uint64
+	ExecutedTx    string
+	Votes         []*Vote
+	VotingPower   int64 // voting power of the validators which voted, out of the total of the validator set
+	Invalidations int
+}
+
+func (s *State) info(hash common.Hash, r *record) *ProposalInfo {
+	info := &ProposalInfo{
+		Hash:          hash,
+		Proposal:      r.Proposal,
+		Status:        r.Status,
+		Height:        r.Height,
+		FinalHeight:   r.FinalHeight,
+		ExecutedTx:    r.ExecutedTx,
+		Votes:         append([]*Vote{}, r.Votes...),
+		Invalidations: len(r.Invalidations),
+	}
+	for _, v := range r.Votes {
+		if _, val := s.validators.GetByAddress(v.ValidatorAddress); val != nil {
+			info.VotingPower += val.VotingPower
+		}
+	}
+	return info
+}
+
+// Proposal returns the progress of the proposal with the given hash.
+func (s *State) Proposal(hash common.Hash) (*ProposalInfo, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	r, ok := s.records[hash]
+	if !ok {
+		return nil, false
+	}
+	return s.info(hash, r), true
+}
+
+// Pending returns the progress of the proposals which are not final, oldest first.
+func (s *State) Pending() []*ProposalInfo {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var pending []*ProposalInfo
+	for hash, r := range s.records {
+		if !r.Status.final() {
+			pending = append(pending, s.info(hash, r))
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pending[i].Height != pending[j].Height {
+			return pending[i].Height < pending[j].Height
+		}
+		return pending[i].Hash.Hex() < pending[j].Hash.Hex()
+	})
+	return pending
+}
+
+// Validators returns the validator set votes are tallied against.
+func (s *State) Validators() *types.ValidatorSet {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.validators.Copy()
+}
+
+// Height returns the current height of the state.
+func (s *State) Height() uint64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.height
+}
@@ -0,0 +1,170 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+// The pause status of a chain is stored in the baseKeyPause namespace of the pool under the chain.
+const baseKeyPause = "pause/"
+
+// AllChains is the chain of a Pause halting the transfers of every chain.
+const AllChains = ""
+
+var ErrPaused = errors.New("transfers are paused")
+
+// Pause is the argument of ProposalPause and ProposalResume. Validators responding to an incident agree on its nonce
+// out of band so that they build the same proposal, and a pause or resume is ignored if one with a higher nonce has
+// already been executed for its chain.
+type Pause struct {
+	Chain  string // chain whose transfers, from or to it, are halted, AllChains for every chain
+	Reason string
+	Nonce  uint64
+}
+
+// NewPauseProposal returns the proposal halting the transfers of pause.Chain.
+func NewPauseProposal(pause *Pause) (*Proposal, error) {
+	return newPauseProposal(ProposalPause, pause)
+}
+
+// NewResumeProposal returns the proposal resuming the transfers of pause.Chain.
+func NewResumeProposal(pause *Pause) (*Proposal, error) {
+	return newPauseProposal(ProposalResume, pause)
+}
+
+func newPauseProposal(t ProposalType, pause *Pause) (*Proposal, error) {
+	data, err := rlp.EncodeToBytes(pause)
+	if err != nil {
+		return nil, err
+	}
+	return &Proposal{Type: t, SourceChain: pause.Chain, Args: [][]byte{data}}, nil
+}
+
+// Pause returns the argument of a ProposalPause or ProposalResume.
+func (p *Proposal) Pause() (*Pause, error) {
+	if !p.Type.governance() || len(p.Args) != 1 {
+		return nil, fmt.Errorf("%v is not a pause or resume proposal", p)
+	}
+	var pause Pause
+	if err := rlp.DecodeBytes(p.Args[0], &pause); err != nil {
+		return nil, fmt.Errorf("invalid pause of %v: %w", p, err)
+	}
+	if pause.Chain != p.SourceChain {
+		return nil, fmt.Errorf("pause of %v doesn't match its chain", p)
+	}
+	return &pause, nil
+}
+
+// PauseStatus is the result of the last pause or resume proposal executed for a chain.
+type PauseStatus struct {
+	Chain        string
+	Paused       bool
+	Reason       string
+	Nonce        uint64
+	ProposalHash common.Hash // hash of the executed proposal
+	Height       uint64      // height at which the proposal is executed
+}
+
+// loadPauses loads the stored pause statuses.
+func (s *State) loadPauses() error {
+	return s.pool.iterate(s.pool.pauses, func(key, value []byte) error {
+		var status PauseStatus
+		if err := rlp.DecodeBytes(value, &status); err != nil {
+			return fmt.Errorf("invalid pause status %q: %w", key, err)
+		}
+		s.pauses[status.Chain] = &status
+		return nil
+	})
+}
+
+// executePause executes r, an executable pause or resume proposal, and marks it executed.
+func (s *State) executePause(hash common.Hash, r *record) error {
+	pause, err := r.Proposal.Pause()
+	if err != nil {
+		return err
+	}
+	if current, ok := s.pauses[pause.Chain]; ok && current.Nonce >= pause.Nonce {
+		s.logger.Warn("Outdated pause proposal is ignored", "proposal", r.Proposal, "nonce", pause.Nonce,
+			"currentNonce", current.Nonce)
+	} else {
+		status := &PauseStatus{
+			Chain:        pause.Chain,
+			Paused:       r.Proposal.Type == ProposalPause,
+			Reason:       pause.Reason,
+			Nonce:        pause.Nonce,
+			ProposalHash: hash,
+			Height:       s.height,
+		}
+		data, err := rlp.EncodeToBytes(status)
+		if err != nil {
+			return err
+		}
+		if err := s.pool.pauses.Put([]byte(pause.Chain), data); err != nil {
+			return err
+		}
+		s.pauses[pause.Chain] = status
+		if status.Paused {
+			s.logger.Warn("Transfers are paused", "chain", pause.Chain, "reason", pause.Reason, "nonce", pause.Nonce)
+		} else {
+			s.logger.Info("Transfers are resumed", "chain", pause.Chain, "nonce", pause.Nonce)
+		}
+	}
+	r.Status = StatusExecuted
+	r.FinalHeight = s.height
+	return nil
+}
+
+// paused returns the status pausing the transfers of one of chains, or nil if they are not paused.
+func (s *State) paused(chains ...string) *PauseStatus {
+	if status, ok := s.pauses[AllChains]; ok && status.Paused {
+		return status
+	}
+	for _, chain := range chains {
+		if status, ok := s.pauses[chain]; ok && status.Paused {
+			return status
+		}
+	}
+	return nil
+}
+
+// halted returns the status pausing p if it is a transfer of a paused chain.
+func (s *State) halted(p *Proposal) *PauseStatus {
+	if !p.Type.transfer() {
+		return nil
+	}
+	return s.paused(p.SourceChain, p.DestChain)
+}
+
+// PauseStatus returns the status of the transfers of chain, which are paused if chain or all chains are paused.
+func (s *State) PauseStatus(chain string) PauseStatus {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if status := s.paused(chain); status != nil {
+		return *status
+	}
+	if status, ok := s.pauses[chain]; ok {
+		return *status
+	}
+	return PauseStatus{Chain: chain}
+}
@@ -0,0 +1,129 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+)
+
+func TestState_pause(t *testing.T) {
+	keys, vals := newValidators(t, 4)
+	db := memorydb.New()
+	s := newTestState(t, db, keys[0], vals, 0)
+	transfer := testProposal(t)
+	_, err := s.AddProposal(transfer)
+	require.NoError(t, err)
+	for _, key := range keys[:3] {
+		_, err = s.AddVote(vote(t, transfer, key))
+		require.NoError(t, err)
+	}
+	require.Equal(t, []*Proposal{transfer}, s.Executable())
+
+	// a pause is executed once more than two thirds of the voting power voted for it
+	pause, err := NewPauseProposal(&Pause{Chain: "ETH", Reason: "incident", Nonce: 1})
+	require.NoError(t, err)
+	_, err = s.AddProposal(pause)
+	require.NoError(t, err)
+	for i, key := range keys[:3] {
+		status, err := s.AddVote(vote(t, pause, key))
+		require.NoError(t, err)
+		if i < 2 {
+			require.False(t, s.PauseStatus("ETH").Paused)
+		} else {
+			require.Equal(t, StatusExecuted, status)
+		}
+	}
+	status := s.PauseStatus("ETH")
+	require.True(t, status.Paused)
+	require.Equal(t, "incident", status.Reason)
+	require.Equal(t, pause.Hash(), status.ProposalHash)
+	require.False(t, s.PauseStatus("BSC").Paused)
+
+	// transfers of the chain are held and new ones are rejected
+	require.Empty(t, s.Executable())
+	other := testProposal(t)
+	other.DestChain = "ETH"
+	other.SourceChain = "KAI"
+	_, err = s.AddProposal(other)
+	require.True(t, errors.Is(err, ErrPaused))
+
+	// votes of executed governance proposals are still gossiped
+	var gossiped int
+	for _, msg := range gossipedMessages(s.pool) {
+		if msg.proposalHash() == pause.Hash() {
+			gossiped++
+		}
+	}
+	require.Equal(t, 3, gossiped)
+
+	restarted := newTestState(t, db, keys[0], vals, 0)
+	require.True(t, restarted.PauseStatus("ETH").Paused)
+
+	// an outdated proposal is executed without changing the status
+	resume, err := NewResumeProposal(&Pause{Chain: "ETH", Nonce: 1})
+	require.NoError(t, err)
+	for _, key := range keys[:3] {
+		_, err = restarted.AddVote(vote(t, resume, key))
+		require.NoError(t, err)
+	}
+	status2, err := restarted.AddProposal(resume)
+	require.NoError(t, err)
+	require.Equal(t, StatusExecuted, status2)
+	require.True(t, restarted.PauseStatus("ETH").Paused)
+
+	resume, err = NewResumeProposal(&Pause{Chain: "ETH", Nonce: 2})
+	require.NoError(t, err)
+	_, err = restarted.AddProposal(resume)
+	require.NoError(t, err)
+	for _, key := range keys[1:] {
+		_, err = restarted.AddVote(vote(t, resume, key))
+		require.NoError(t, err)
+	}
+	require.False(t, restarted.PauseStatus("ETH").Paused)
+	require.Equal(t, []*Proposal{transfer}, restarted.Executable())
+}
+
+func TestState_pauseAllChains(t *testing.T) {
+	keys, vals := newValidators(t, 4)
+	s := newTestState(t, memorydb.New(), keys[0], vals, 0)
+	pause, err := NewPauseProposal(&Pause{Chain: AllChains, Reason: "upgrade", Nonce: 1})
+	require.NoError(t, err)
+	_, err = s.AddProposal(pause)
+	require.NoError(t, err)
+	for _, key := range keys[:3] {
+		_, err = s.AddVote(vote(t, pause, key))
+		require.NoError(t, err)
+	}
+
+	status := s.PauseStatus("BSC")
+	require.True(t, status.Paused)
+	require.Equal(t, AllChains, status.Chain)
+	require.Equal(t, pause.Hash(), status.ProposalHash)
+	_, err = s.AddProposal(testProposal(t))
+	require.True(t, errors.Is(err, ErrPaused))
+
+	// the argument of a governance proposal is checked when it is added
+	_, err = s.AddProposal(&Proposal{Type: ProposalPause, SourceChain: "ETH", Args: [][]byte{{0x01}}})
+	require.Error(t, err)
+}
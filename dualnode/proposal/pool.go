@@ -0,0 +1,319 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/lib/clist"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+// The pool is stored in the namespace prefixed by baseKeyPool, proposals, votes and invalidations in namespaces of
+// it:
+//
+//	baseKeyProposal: proposal hash                            -> entry
+//	baseKeyVote: proposal hash + validator address            -> vote
+//	baseKeyInvalidation: proposal hash + validator address    -> invalidation
+const (
+	baseKeyPool         = "dual/"
+	baseKeyProposal     = "proposal/"
+	baseKeyVote         = "vote/"
+	baseKeyInvalidation = "invalidation/"
+)
+
+// entry is the stored progress of a proposal, its votes and invalidations are stored separately.
+type entry struct {
+	Proposal    *Proposal `rlp:"nil"` // nil while only votes of the proposal are known
+	Status      Status
+	Height      uint64 // height at which the proposal or its first vote is received
+	FinalHeight uint64 // height at which the proposal is executed, expired or cancelled
+	ExecutedTx  string // hash of the transaction executing the proposal
+}
+
+// gossipVotes reports whether the votes of e are gossiped. Governance proposals are executed by every node, so their
+// votes are gossiped until they are pruned for lagging validators to execute them too.
+func (e *entry) gossipVotes() bool {
+	if e.Status == StatusExecuted && e.Proposal != nil && e.Proposal.Type.governance() {
+		return true
+	}
+	return !e.Status.final()
+}
+
+// message is a vote or an invalidation of a proposal, messages are stored and gossiped to peers.
+type message interface {
+	proposalHash() common.Hash
+	key() []byte
+}
+
+func (v *Vote) proposalHash() common.Hash { return v.ProposalHash }
+func (v *Vote) key() []byte               { return keyMessage(v.ProposalHash, v.ValidatorAddress) }
+
+func (inv *Invalidation) proposalHash() common.Hash { return inv.ProposalHash }
+func (inv *Invalidation) key() []byte               { return keyMessage(inv.ProposalHash, inv.ValidatorAddress) }
+
+// Pool stores proposals with their votes and invalidations, and keeps a list of the messages gossiped to peers: votes
+// of proposals which are not final or are executed governance proposals, and invalidations until they are pruned. Final proposals are pruned after a
+// retention period.
+type Pool struct {
+	logger log.Logger
+	db     *kaidb.PrefixStore
+
+	proposals     *kaidb.PrefixStore
+	votes         *kaidb.PrefixStore
+	invalidations *kaidb.PrefixStore
+	deposits      *kaidb.PrefixStore
+	fees          *kaidb.PrefixStore
+	pauses        *kaidb.PrefixStore
+
+	msgList *clist.CList // gossiped *Vote and *Invalidation
+	msgSize uint32
+
+	// number of blocks final proposals are kept for, 0 to keep all
+	retainBlocks uint64
+}
+
+// NewPool creates a pool of proposals. If using an existing store, the gossiped messages are added back to the list.
+func NewPool(db kaidb.Database, retainBlocks uint64) (*Pool, error) {
+	ns := kaidb.NewPrefixStore(db, []byte(baseKeyPool))
+	pool := &Pool{
+		logger:        log.New("module", "dual_pool"),
+		db:            ns,
+		proposals:     kaidb.NewPrefixStore(ns, []byte(baseKeyProposal)),
+		votes:         kaidb.NewPrefixStore(ns, []byte(baseKeyVote)),
+		invalidations: kaidb.NewPrefixStore(ns, []byte(baseKeyInvalidation)),
+		deposits:      kaidb.NewPrefixStore(ns, []byte(baseKeyDeposit)),
+		fees:          kaidb.NewPrefixStore(ns, []byte(baseKeyFee)),
+		pauses:        kaidb.NewPrefixStore(ns, []byte(baseKeyPause)),
+		msgList:       clist.New(),
+		retainBlocks:  retainBlocks,
+	}
+	entries, votes, invalidations, err := pool.load()
+	if err != nil {
+		return nil, err
+	}
+	for hash, e := range entries {
+		if e.gossipVotes() {
+			for _, v := range votes[hash] {
+				pool.msgList.PushBack(v)
+			}
+		}
+		for _, inv := range invalidations[hash] {
+			pool.msgList.PushBack(inv)
+		}
+	}
+	atomic.StoreUint32(&pool.msgSize, uint32(pool.msgList.Len()))
+	return pool, nil
+}
+
+// load returns the stored proposals with their votes and invalidations.
+func (pool *Pool) load() (map[common.Hash]*entry, map[common.Hash][]*Vote, map[common.Hash][]*Invalidation, error) {
+	entries := make(map[common.Hash]*entry)
+	err := pool.iterate(pool.proposals, func(key, value []byte) error {
+		var e entry
+		if err := rlp.DecodeBytes(value, &e); err != nil {
+			return fmt.Errorf("invalid proposal entry %x: %w", key, err)
+		}
+		entries[common.BytesToHash(key)] = &e
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	votes := make(map[common.Hash][]*Vote)
+	err = pool.iterate(pool.votes, func(key, value []byte) error {
+		var v Vote
+		if err := rlp.DecodeBytes(value, &v); err != nil {
+			return fmt.Errorf("invalid vote %x: %w", key, err)
+		}
+		votes[v.ProposalHash] = append(votes[v.ProposalHash], &v)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	invalidations := make(map[common.Hash][]*Invalidation)
+	err = pool.iterate(pool.invalidations, func(key, value []byte) error {
+		var inv Invalidation
+		if err := rlp.DecodeBytes(value, &inv); err != nil {
+			return fmt.Errorf("invalid invalidation %x: %w", key, err)
+		}
+		invalidations[inv.ProposalHash] = append(invalidations[inv.ProposalHash], &inv)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return entries, votes, invalidations, nil
+}
+
+func (pool *Pool) iterate(store *kaidb.PrefixStore, fn func(key, value []byte) error) error {
+	it := store.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		if err := fn(it.Key(), it.Value()); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// write is a key and value of a namespace of the pool stored at once with a proposal, eg: the result of its
+// execution.
+type write struct {
+	store *kaidb.PrefixStore
+	key   []byte
+	value []byte
+}
+
+// messageStore returns the namespace storing msg.
+func (pool *Pool) messageStore(msg message) *kaidb.PrefixStore {
+	if _, ok := msg.(*Invalidation); ok {
+		return pool.invalidations
+	}
+	return pool.votes
+}
+
+// save stores e, a new message of its proposal if it is not nil, and writes at once.
+func (pool *Pool) save(hash common.Hash, e *entry, msg message, writes ...write) error {
+	batch := pool.db.NewBatch()
+	data, err := rlp.EncodeToBytes(e)
+	if err != nil {
+		return err
+	}
+	if err := pool.proposals.WrapBatch(batch).Put(hash.Bytes(), data); err != nil {
+		return err
+	}
+	if msg != nil {
+		data, err := rlp.EncodeToBytes(msg)
+		if err != nil {
+			return err
+		}
+		if err := pool.messageStore(msg).WrapBatch(batch).Put(msg.key(), data); err != nil {
+			return err
+		}
+	}
+	for _, w := range writes {
+		if err := w.store.WrapBatch(batch).Put(w.key, w.value); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if !e.gossipVotes() {
+		pool.removeVotesFromList(hash)
+	}
+	switch msg.(type) {
+	case *Vote:
+		if e.gossipVotes() {
+			pool.pushBack(msg)
+		}
+	case *Invalidation:
+		pool.pushBack(msg)
+	}
+	return nil
+}
+
+func (pool *Pool) pushBack(msg message) {
+	pool.msgList.PushBack(msg)
+	atomic.AddUint32(&pool.msgSize, 1)
+}
+
+// prune deletes proposals which have been final for more than retainBlocks at height, with their votes and
+// invalidations.
+func (pool *Pool) prune(height uint64, entries map[common.Hash]*entry) ([]common.Hash, error) {
+	if pool.retainBlocks == 0 {
+		return nil, nil
+	}
+	var pruned []common.Hash
+	batch := pool.proposals.NewBatch()
+	for hash, e := range entries {
+		if !e.Status.final() || e.FinalHeight+pool.retainBlocks >= height {
+			continue
+		}
+		if err := batch.Delete(hash.Bytes()); err != nil {
+			return nil, err
+		}
+		pruned = append(pruned, hash)
+	}
+	if len(pruned) == 0 {
+		return nil, nil
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+	for _, hash := range pruned {
+		for _, store := range []*kaidb.PrefixStore{pool.votes, pool.invalidations} {
+			if err := store.DeleteRange(hash.Bytes(), kaidb.PrefixLimit(hash.Bytes())); err != nil {
+				return nil, fmt.Errorf("can't delete messages of proposal %v: %w", hash.Hex(), err)
+			}
+		}
+		pool.removeFromList(hash, func(message) bool { return true })
+	}
+	pool.logger.Info("Pruned final proposals", "count", len(pruned), "height", height)
+	return pruned, nil
+}
+
+// Size returns the number of gossiped messages.
+func (pool *Pool) Size() uint32 {
+	return atomic.LoadUint32(&pool.msgSize)
+}
+
+// MessageFront returns the first gossiped message, its value is a *Vote or an *Invalidation.
+func (pool *Pool) MessageFront() *clist.CElement {
+	return pool.msgList.Front()
+}
+
+// MessageWaitChan is closed when the gossiped list becomes non empty.
+func (pool *Pool) MessageWaitChan() <-chan struct{} {
+	return pool.msgList.WaitChan()
+}
+
+// SetLogger sets the Logger.
+func (pool *Pool) SetLogger(l log.Logger) {
+	pool.logger = l
+}
+
+// removeVotesFromList stops gossiping the votes of a final proposal.
+func (pool *Pool) removeVotesFromList(hash common.Hash) {
+	pool.removeFromList(hash, func(msg message) bool {
+		_, ok := msg.(*Vote)
+		return ok
+	})
+}
+
+func (pool *Pool) removeFromList(hash common.Hash, match func(message) bool) {
+	for e := pool.msgList.Front(); e != nil; e = e.Next() {
+		msg := e.Value.(message)
+		if msg.proposalHash() == hash && match(msg) {
+			pool.msgList.Remove(e)
+			e.DetachPrev()
+			atomic.AddUint32(&pool.msgSize, ^uint32(0))
+		}
+	}
+}
+
+func keyMessage(hash common.Hash, validator common.Address) []byte {
+	return append(hash.Bytes(), validator.Bytes()...)
+}
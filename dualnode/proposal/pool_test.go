@@ -0,0 +1,76 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+)
+
+func gossipedMessages(pool *Pool) []message {
+	var msgs []message
+	for e := pool.MessageFront(); e != nil; e = e.Next() {
+		msgs = append(msgs, e.Value.(message))
+	}
+	return msgs
+}
+
+func TestPool_reloadAndPrune(t *testing.T) {
+	keys, vals := newValidators(t, 4)
+	db := memorydb.New()
+	pool, err := NewPool(db, 10)
+	require.NoError(t, err)
+	s, err := NewState(pool, testAddress(keys[0]), vals, 0)
+	require.NoError(t, err)
+	p := testProposal(t)
+	_, err = s.AddProposal(p)
+	require.NoError(t, err)
+	for _, key := range keys[:2] {
+		_, err = s.AddVote(vote(t, p, key))
+		require.NoError(t, err)
+	}
+	require.Equal(t, uint32(2), pool.Size())
+
+	// votes are gossiped again after a restart
+	pool, err = NewPool(db, 10)
+	require.NoError(t, err)
+	require.Len(t, gossipedMessages(pool), 2)
+	s, err = NewState(pool, testAddress(keys[0]), vals, 0)
+	require.NoError(t, err)
+	require.Len(t, s.Votes(p.Hash()), 2)
+
+	// votes of executed proposals are not gossiped, and are pruned after the retention period
+	require.NoError(t, s.Update(100))
+	_, err = s.AddVote(vote(t, p, keys[2]))
+	require.NoError(t, err)
+	require.NoError(t, s.MarkExecuted(p.Hash(), "0xabc"))
+	require.Zero(t, pool.Size())
+	require.Empty(t, gossipedMessages(pool))
+
+	require.NoError(t, s.Update(110))
+	_, ok := s.Status(p.Hash())
+	require.True(t, ok)
+	require.NoError(t, s.Update(111))
+	_, ok = s.Status(p.Hash())
+	require.False(t, ok)
+	require.Zero(t, db.Len())
+}
@@ -0,0 +1,191 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package proposal tracks the proposals of bridge validators (eg: releasing a deposit on its destination chain) and
+// their votes, from creation to execution or expiry.
+package proposal
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+// ProposalType is the action executed once a proposal is voted.
+type ProposalType uint8
+
+const (
+	ProposalDeposit      ProposalType = iota // release a deposit of the source chain on the destination chain
+	ProposalTokenMapping                     // apply a token_registry.Update
+	ProposalPause                            // halt the transfers of a chain, or of all chains, see Pause
+	ProposalResume                           // resume the transfers halted by ProposalPause
+	ProposalWithdrawFees                     // sweep accrued fees to a treasury, see FeeWithdrawal
+	ProposalBatch                            // release deposits of the source chain at once, see Batch
+)
+
+func (t ProposalType) String() string {
+	switch t {
+	case ProposalDeposit:
+		return "deposit"
+	case ProposalTokenMapping:
+		return "tokenMapping"
+	case ProposalPause:
+		return "pause"
+	case ProposalResume:
+		return "resume"
+	case ProposalWithdrawFees:
+		return "withdrawFees"
+	case ProposalBatch:
+		return "batch"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(t))
+	}
+}
+
+// governance reports whether proposals of type t are executed by every node when they are voted, instead of by a
+// transaction on their destination chain.
+func (t ProposalType) governance() bool {
+	return t == ProposalPause || t == ProposalResume
+}
+
+// transfer reports whether proposals of type t release deposits of their source chain.
+func (t ProposalType) transfer() bool {
+	return t == ProposalDeposit || t == ProposalBatch
+}
+
+// Domains separate the signatures of votes and invalidations from each other and from other signatures of
+// validators.
+var (
+	voteDomain         = []byte("dualnode-vote")
+	invalidationDomain = []byte("dualnode-invalidation")
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid vote signature")
+	ErrNotValidator     = errors.New("vote of a non validator")
+	ErrDuplicateVote    = errors.New("duplicate vote")
+	ErrUnknownProposal  = errors.New("unknown proposal")
+	ErrProposalExecuted = errors.New("proposal is executed")
+)
+
+// Proposal is an action proposed by bridge validators. Every validator builds the same proposal from what it
+// observes (eg: a deposit on an external chain), so it is identified by its hash.
+type Proposal struct {
+	Type        ProposalType
+	SourceChain string
+	DestChain   string
+	Args        [][]byte // arguments of the type, eg: the RLP encoded deposit of ProposalDeposit
+}
+
+// Hash returns the hash identifying p.
+func (p *Proposal) Hash() common.Hash {
+	data, _ := rlp.EncodeToBytes(p)
+	return crypto.Keccak256Hash(data)
+}
+
+func (p *Proposal) String() string {
+	return fmt.Sprintf("Proposal{%v %v->%v %v}", p.Type, p.SourceChain, p.DestChain, p.Hash().Hex())
+}
+
+// NewDepositProposal returns the proposal releasing d on destChain.
+func NewDepositProposal(d *dualnode.Deposit, destChain string) (*Proposal, error) {
+	data, err := rlp.EncodeToBytes(d)
+	if err != nil {
+		return nil, err
+	}
+	return &Proposal{Type: ProposalDeposit, SourceChain: d.Chain, DestChain: destChain, Args: [][]byte{data}}, nil
+}
+
+// Deposit returns the deposit released by a ProposalDeposit.
+func (p *Proposal) Deposit() (*dualnode.Deposit, error) {
+	if p.Type != ProposalDeposit || len(p.Args) != 1 {
+		return nil, fmt.Errorf("%v is not a deposit proposal", p)
+	}
+	var d dualnode.Deposit
+	if err := rlp.DecodeBytes(p.Args[0], &d); err != nil {
+		return nil, fmt.Errorf("invalid deposit of %v: %w", p, err)
+	}
+	return &d, nil
+}
+
+// Vote is the approval of a proposal by a validator.
+type Vote struct {
+	ProposalHash     common.Hash
+	ValidatorAddress common.Address
+	Signature        []byte // [R || S || V] signature of the proposal hash in the vote domain
+}
+
+// SignVote returns the vote of the validator owning key for the proposal with the given hash.
+func SignVote(proposalHash common.Hash, key *ecdsa.PrivateKey) (*Vote, error) {
+	sig, err := crypto.Sign(signBytes(voteDomain, proposalHash), key)
+	if err != nil {
+		return nil, err
+	}
+	return &Vote{ProposalHash: proposalHash, ValidatorAddress: crypto.PubkeyToAddress(key.PublicKey), Signature: sig}, nil
+}
+
+// Verify checks that v is signed by ValidatorAddress.
+func (v *Vote) Verify() error {
+	return verifySignature(voteDomain, v.ProposalHash, v.ValidatorAddress, v.Signature)
+}
+
+func (v *Vote) String() string {
+	return fmt.Sprintf("Vote{%v on %v}", v.ValidatorAddress.Hex(), v.ProposalHash.Hex())
+}
+
+// Invalidation withdraws the approval of a proposal whose deposit is reorganised out of its source chain.
+type Invalidation struct {
+	ProposalHash     common.Hash
+	ValidatorAddress common.Address
+	Signature        []byte // [R || S || V] signature of the proposal hash in the invalidation domain
+}
+
+// SignInvalidation returns the invalidation of the validator owning key for the proposal with the given hash.
+func SignInvalidation(proposalHash common.Hash, key *ecdsa.PrivateKey) (*Invalidation, error) {
+	sig, err := crypto.Sign(signBytes(invalidationDomain, proposalHash), key)
+	if err != nil {
+		return nil, err
+	}
+	return &Invalidation{ProposalHash: proposalHash, ValidatorAddress: crypto.PubkeyToAddress(key.PublicKey), Signature: sig}, nil
+}
+
+// Verify checks that inv is signed by ValidatorAddress.
+func (inv *Invalidation) Verify() error {
+	return verifySignature(invalidationDomain, inv.ProposalHash, inv.ValidatorAddress, inv.Signature)
+}
+
+func (inv *Invalidation) String() string {
+	return fmt.Sprintf("Invalidation{%v on %v}", inv.ValidatorAddress.Hex(), inv.ProposalHash.Hex())
+}
+
+// signBytes returns the hash signed by validators for a proposal in domain.
+func signBytes(domain []byte, proposalHash common.Hash) []byte {
+	return crypto.Keccak256(domain, proposalHash.Bytes())
+}
+
+func verifySignature(domain []byte, proposalHash common.Hash, validator common.Address, sig []byte) error {
+	if len(sig) != 65 || !crypto.VerifySignature(validator, signBytes(domain, proposalHash), sig) {
+		return fmt.Errorf("%w of %v on %v", ErrInvalidSignature, validator.Hex(), proposalHash.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/lib/common"
+)
+
+var ErrRateLimited = errors.New("transfer exceeds rate limit")
+
+// RateLimit caps the transfers of an asset of a source chain. Amounts are decimal strings in the smallest unit of
+// the asset, empty for no limit.
+type RateLimit struct {
+	Chain       string `yaml:"Chain"`       // source chain of the asset
+	Token       string `yaml:"Token"`       // token contract, empty for the chain's native coin
+	MaxTransfer string `yaml:"MaxTransfer"` // maximum amount of a single transfer
+	MaxAmount   string `yaml:"MaxAmount"`   // maximum amount transferred in Window
+	Window      uint64 `yaml:"Window"`      // number of blocks of the rolling window of MaxAmount
+
+	maxTransfer *big.Int
+	maxAmount   *big.Int
+}
+
+func (l *RateLimit) validate() error {
+	if l.Chain == "" {
+		return fmt.Errorf("Chain of rate limit is required")
+	}
+	var err error
+	if l.maxTransfer, err = parseAmount(l.MaxTransfer); err != nil {
+		return fmt.Errorf("invalid MaxTransfer of %v: %w", l, err)
+	}
+	if l.maxAmount, err = parseAmount(l.MaxAmount); err != nil {
+		return fmt.Errorf("invalid MaxAmount of %v: %w", l, err)
+	}
+	if l.maxAmount != nil && l.Window == 0 {
+		return fmt.Errorf("Window of %v is required by MaxAmount", l)
+	}
+	return nil
+}
+
+func (l *RateLimit) String() string {
+	return fmt.Sprintf("RateLimit{%v %v}", l.Chain, l.Token)
+}
+
+func parseAmount(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	amount, ok := new(big.Int).SetString(s, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("%q is not an amount", s)
+	}
+	return amount, nil
+}
+
+func rateLimitKey(chain, token string) string {
+	return chain + "/" + strings.ToLower(token)
+}
+
+// SetRateLimits replaces the rate limits of transfers. A deposit proposal exceeding the limit of its asset is queued
+// for manual approval when it is added, and the node's vote of a queued proposal is rejected until it is approved.
+// Transfers are counted in the window of MaxAmount from their proposals, which must be retained by the pool for at
+// least the window.
+func (s *State) SetRateLimits(limits []*RateLimit) error {
+	byAsset := make(map[string]*RateLimit, len(limits))
+	for _, l := range limits {
+		if err := l.validate(); err != nil {
+			return err
+		}
+		key := rateLimitKey(l.Chain, l.Token)
+		if _, ok := byAsset[key]; ok {
+			return fmt.Errorf("%v is set twice", l)
+		}
+		byAsset[key] = l
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.limits = byAsset
+	return nil
+}
+
+// checkRateLimit returns an error if deposits, released by the proposal with the given hash, exceed the rate limit
+// of their asset.
+func (s *State) checkRateLimit(hash common.Hash, deposits []*dualnode.Deposit) error {
+	d := deposits[0]
+	l, ok := s.limits[rateLimitKey(d.Chain, d.Token)]
+	if !ok {
+		return nil
+	}
+	amount := new(big.Int)
+	for _, d := range deposits {
+		if l.maxTransfer != nil && d.Amount.Cmp(l.maxTransfer) > 0 {
+			return fmt.Errorf("%w: %v of %v is above the maximum transfer %v", ErrRateLimited, d.Amount, l, l.maxTransfer)
+		}
+		amount.Add(amount, d.Amount)
+	}
+	if l.maxAmount == nil {
+		return nil
+	}
+	used := new(big.Int)
+	for other, r := range s.records {
+		if other == hash || r.Proposal == nil || !r.Proposal.Type.transfer() || r.Height+l.Window <= s.height {
+			continue
+		}
+		if r.Status == StatusQueued || r.Status == StatusExpired || r.Status == StatusCancelled {
+			continue
+		}
+		transfers, err := r.Proposal.Transfers()
+		if err != nil || rateLimitKey(transfers[0].Chain, transfers[0].Token) != rateLimitKey(d.Chain, d.Token) {
+			continue
+		}
+		for _, transfer := range transfers {
+			used.Add(used, transfer.Amount)
+		}
+	}
+	if used.Add(used, amount).Cmp(l.maxAmount) > 0 {
+		return fmt.Errorf("%w: %v of %v in %v blocks is above %v", ErrRateLimited, used, l, l.Window, l.maxAmount)
+	}
+	return nil
+}
+
+// Queued returns the proposals waiting for manual approval, oldest first.
+func (s *State) Queued() []*Proposal {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var records []*record
+	for _, r := range s.records {
+		if r.Status == StatusQueued {
+			records = append(records, r)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Height < records[j].Height })
+	proposals := make([]*Proposal, len(records))
+	for i, r := range records {
+		proposals[i] = r.Proposal
+	}
+	return proposals
+}
+
+// Approve approves the queued proposal with the given hash, which the node can vote for afterwards.
+func (s *State) Approve(hash common.Hash) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	r, ok := s.records[hash]
+	if !ok {
+		return fmt.Errorf("%w %v", ErrUnknownProposal, hash.Hex())
+	}
+	if r.Status != StatusQueued {
+		return fmt.Errorf("proposal %v is %v, not queued", hash.Hex(), r.Status)
+	}
+	r.Status = StatusPending
+	s.tally(r)
+	if err := s.save(hash, r, nil); err != nil {
+		return err
+	}
+	s.logger.Info("Queued proposal is approved", "proposal", r.Proposal)
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+)
+
+func transferProposal(t *testing.T, txHash string, amount int64) *Proposal {
+	p, err := NewDepositProposal(&dualnode.Deposit{
+		Chain:  "ETH",
+		TxHash: txHash,
+		Token:  "0x0a",
+		To:     "0x0C",
+		Amount: big.NewInt(amount),
+	}, "KAI")
+	require.NoError(t, err)
+	return p
+}
+
+func TestState_rateLimits(t *testing.T) {
+	keys, vals := newValidators(t, 4)
+	s := newTestState(t, memorydb.New(), keys[0], vals, 0)
+	require.NoError(t, s.SetRateLimits([]*RateLimit{
+		{Chain: "ETH", Token: "0x0A", MaxTransfer: "100", MaxAmount: "150", Window: 10},
+	}))
+
+	// a transfer above the maximum is queued and the node doesn't vote for it until it is approved
+	large := transferProposal(t, "0x01", 101)
+	status, err := s.AddProposal(large)
+	require.NoError(t, err)
+	require.Equal(t, StatusQueued, status)
+	_, err = s.AddVote(vote(t, large, keys[0]))
+	require.True(t, errors.Is(err, ErrRateLimited))
+	status, err = s.AddVote(vote(t, large, keys[1]))
+	require.NoError(t, err)
+	require.Equal(t, StatusQueued, status)
+	require.Equal(t, []*Proposal{large}, s.Queued())
+
+	require.NoError(t, s.Approve(large.Hash()))
+	require.Error(t, s.Approve(large.Hash()))
+	status, err = s.AddVote(vote(t, large, keys[0]))
+	require.NoError(t, err)
+	require.Equal(t, StatusVoted, status)
+	require.Empty(t, s.Queued())
+
+	// approved transfers count in the window
+	status, err = s.AddProposal(transferProposal(t, "0x02", 50))
+	require.NoError(t, err)
+	require.Equal(t, StatusQueued, status)
+
+	// the window rolls
+	require.NoError(t, s.Update(10))
+	status, err = s.AddProposal(transferProposal(t, "0x03", 100))
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, status)
+	status, err = s.AddProposal(transferProposal(t, "0x04", 50))
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, status)
+	status, err = s.AddProposal(transferProposal(t, "0x05", 1))
+	require.NoError(t, err)
+	require.Equal(t, StatusQueued, status)
+
+	// other assets are not limited
+	other, err := NewDepositProposal(&dualnode.Deposit{Chain: "BSC", TxHash: "0x06", Amount: big.NewInt(1000)}, "KAI")
+	require.NoError(t, err)
+	status, err = s.AddProposal(other)
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, status)
+}
+
+func TestState_setRateLimits(t *testing.T) {
+	keys, vals := newValidators(t, 1)
+	s := newTestState(t, memorydb.New(), keys[0], vals, 0)
+	for _, limits := range [][]*RateLimit{
+		{{Token: "0x0A", MaxTransfer: "1"}},
+		{{Chain: "ETH", MaxTransfer: "-1"}},
+		{{Chain: "ETH", MaxTransfer: "1e18"}},
+		{{Chain: "ETH", MaxAmount: "100"}},
+		{{Chain: "ETH", Token: "0x0a"}, {Chain: "ETH", Token: "0x0A"}},
+	} {
+		require.Error(t, s.SetRateLimits(limits))
+	}
+	require.NoError(t, s.SetRateLimits(nil))
+}
@@ -0,0 +1,457 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// Status is the stage of a proposal in its lifecycle:
+//
+//	queued -> pending -> voted -> executable -> executed
+//	   \         \         \           \
+//	    \         \         +-----------+--> cancelled
+//	     +---------+---------+--> expired
+type Status uint8
+
+const (
+	StatusPending    Status = iota // known by the node, which hasn't voted yet
+	StatusVoted                    // voted by the node, waiting for votes of other validators
+	StatusExecutable               // voted by more than two thirds of the voting power
+	StatusExecuted                 // executed on the destination chain, or by every node for governance proposals
+	StatusExpired                  // not executable after the expiry period
+	StatusCancelled                // the deposit of the proposal is reorganised out of its source chain
+	StatusQueued                   // the transfer exceeds a rate limit, the node votes once it is approved
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusPending:
+		return "pending"
+	case StatusVoted:
+		return "voted"
+	case StatusExecutable:
+		return "executable"
+	case StatusExecuted:
+		return "executed"
+	case StatusExpired:
+		return "expired"
+	case StatusCancelled:
+		return "cancelled"
+	case StatusQueued:
+		return "queued"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(s))
+	}
+}
+
+// final reports whether s doesn't change anymore.
+func (s Status) final() bool {
+	return s == StatusExecuted || s == StatusExpired || s == StatusCancelled
+}
+
+// waiting reports whether a proposal with status s waits for votes, it expires after the expiry period.
+func (s Status) waiting() bool {
+	return s == StatusQueued || s == StatusPending || s == StatusVoted
+}
+
+// record is the progress of a proposal.
+type record struct {
+	entry
+	Votes         []*Vote
+	Invalidations []*Invalidation
+}
+
+func (r *record) hasVoted(address common.Address) bool {
+	for _, v := range r.Votes {
+		if v.ValidatorAddress.Equal(address) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *record) hasInvalidated(address common.Address) bool {
+	for _, inv := range r.Invalidations {
+		if inv.ValidatorAddress.Equal(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// State tallies the votes of proposals against the validator set and moves them through their lifecycle. Progress
+// is stored in a Pool so that it survives restarts.
+type State struct {
+	mtx    sync.Mutex
+	pool   *Pool
+	logger log.Logger
+
+	self         common.Address // validator address of the node, its vote moves proposals to StatusVoted
+	validators   *types.ValidatorSet
+	expiryBlocks uint64 // number of blocks after which a proposal which is not executable expires
+	height       uint64
+
+	records  map[common.Hash]*record
+	deposits *depositIndex
+	pauses   map[string]*PauseStatus // by chain
+	limits   map[string]*RateLimit   // by chain and token, see SetRateLimits
+
+	fees       map[string]*Fee // by chain and token, see SetFees
+	treasuries map[string]string
+	accrued    map[string]*accruedFee // by chain and token
+}
+
+// NewState returns the state of the proposals stored in pool.
+func NewState(pool *Pool, self common.Address, validators *types.ValidatorSet, expiryBlocks uint64) (*State, error) {
+	if validators.IsNilOrEmpty() {
+		return nil, fmt.Errorf("empty validator set")
+	}
+	s := &State{
+		pool:         pool,
+		logger:       log.New("module", "dual_proposal"),
+		self:         self,
+		validators:   validators,
+		expiryBlocks: expiryBlocks,
+		records:      make(map[common.Hash]*record),
+		deposits:     &depositIndex{db: pool.deposits},
+		pauses:       make(map[string]*PauseStatus),
+		accrued:      make(map[string]*accruedFee),
+	}
+	entries, votes, invalidations, err := pool.load()
+	if err != nil {
+		return nil, err
+	}
+	for hash, e := range entries {
+		s.records[hash] = &record{entry: *e, Votes: votes[hash], Invalidations: invalidations[hash]}
+	}
+	if err := s.loadPauses(); err != nil {
+		return nil, err
+	}
+	if err := s.loadAccruedFees(); err != nil {
+		return nil, err
+	}
+	// a governance proposal is executable only if the node stopped before executing it
+	for hash, r := range s.records {
+		if r.Status == StatusExecutable && r.Proposal.Type.governance() {
+			if err := s.save(hash, r, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// AddProposal adds p, which the node has verified against the source chain, and returns its status. Deposit proposals
+// are rejected with ErrPaused while their source or destination chain is paused, the caller keeps them to propose
+// them once transfers are resumed.
+func (s *State) AddProposal(p *Proposal) (Status, error) {
+	if p.Type.governance() {
+		if _, err := p.Pause(); err != nil {
+			return StatusPending, err
+		}
+	}
+	hash := p.Hash()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if pause := s.halted(p); pause != nil {
+		return StatusPending, fmt.Errorf("%w by %v: %v", ErrPaused, pause.ProposalHash.Hex(), p)
+	}
+	r, ok := s.records[hash]
+	if !ok {
+		r = &record{entry: entry{Height: s.height}}
+	} else if r.Proposal != nil {
+		return r.Status, nil
+	}
+	if p.Type.transfer() {
+		deposits, err := p.Transfers()
+		if err != nil {
+			return StatusPending, err
+		}
+		if err := s.checkRateLimit(hash, deposits); err != nil {
+			r.Status = StatusQueued
+			s.logger.Warn("Proposal is queued for approval", "proposal", p, "err", err)
+		}
+	}
+	if p.Type == ProposalWithdrawFees {
+		w, err := p.FeeWithdrawal()
+		if err != nil {
+			return StatusPending, err
+		}
+		if err := s.checkWithdrawal(w); err != nil {
+			return StatusPending, err
+		}
+	}
+	r.Proposal = p
+	s.tally(r)
+	if err := s.save(hash, r, nil); err != nil {
+		return r.Status, err
+	}
+	s.logger.Debug("Proposal is added", "proposal", p, "status", r.Status)
+	return r.Status, nil
+}
+
+// AddVote verifies and adds v, then returns the status of its proposal. Votes may be received before their
+// proposal, they are tallied once it is added.
+func (s *State) AddVote(v *Vote) (Status, error) {
+	if err := v.Verify(); err != nil {
+		return StatusPending, err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if !s.validators.HasAddress(v.ValidatorAddress) {
+		return StatusPending, fmt.Errorf("%w %v", ErrNotValidator, v.ValidatorAddress.Hex())
+	}
+	r, ok := s.records[v.ProposalHash]
+	if !ok {
+		r = &record{entry: entry{Height: s.height}}
+	}
+	if r.hasVoted(v.ValidatorAddress) {
+		return r.Status, fmt.Errorf("%w: %v", ErrDuplicateVote, v)
+	}
+	if r.Status == StatusQueued && v.ValidatorAddress.Equal(s.self) {
+		return r.Status, fmt.Errorf("%w: %v is not approved", ErrRateLimited, r.Proposal)
+	}
+	if r.Status.final() {
+		return r.Status, nil
+	}
+	r.Votes = append(r.Votes, v)
+	s.tally(r)
+	if err := s.save(v.ProposalHash, r, v); err != nil {
+		return r.Status, err
+	}
+	return r.Status, nil
+}
+
+// AddInvalidation verifies and adds inv, then returns the status of its proposal. Invalidations of executed or
+// expired proposals are ignored.
+func (s *State) AddInvalidation(inv *Invalidation) (Status, error) {
+	if err := inv.Verify(); err != nil {
+		return StatusPending, err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if !s.validators.HasAddress(inv.ValidatorAddress) {
+		return StatusPending, fmt.Errorf("%w %v", ErrNotValidator, inv.ValidatorAddress.Hex())
+	}
+	r, ok := s.records[inv.ProposalHash]
+	if !ok {
+		r = &record{entry: entry{Height: s.height}}
+	}
+	if r.hasInvalidated(inv.ValidatorAddress) {
+		return r.Status, fmt.Errorf("%w: %v", ErrDuplicateVote, inv)
+	}
+	if r.Status == StatusExecuted || r.Status == StatusExpired {
+		return r.Status, nil
+	}
+	r.Invalidations = append(r.Invalidations, inv)
+	status := r.Status
+	s.tally(r)
+	if err := s.save(inv.ProposalHash, r, inv); err != nil {
+		return r.Status, err
+	}
+	if r.Status != status && r.Status == StatusCancelled {
+		s.logger.Warn("Proposal is cancelled", "hash", inv.ProposalHash.Hex(), "invalidations", len(r.Invalidations))
+	}
+	return r.Status, nil
+}
+
+// tally updates the status of r from its votes and invalidations. A proposal is cancelled if the node or more than
+// two thirds of the voting power invalidated it.
+func (s *State) tally(r *record) {
+	if r.Status.final() {
+		return
+	}
+	invalidators := make([]common.Address, len(r.Invalidations))
+	for i, inv := range r.Invalidations {
+		invalidators[i] = inv.ValidatorAddress
+	}
+	if r.hasInvalidated(s.self) || s.hasTwoThirds(invalidators) {
+		r.Status = StatusCancelled
+		r.FinalHeight = s.height
+		return
+	}
+	if r.Status == StatusPending && r.hasVoted(s.self) {
+		r.Status = StatusVoted
+	}
+	voters := make([]common.Address, len(r.Votes))
+	for i, v := range r.Votes {
+		voters[i] = v.ValidatorAddress
+	}
+	if r.Proposal != nil && s.hasTwoThirds(voters) {
+		r.Status = StatusExecutable
+	}
+}
+
+// hasTwoThirds reports whether validators have more than two thirds of the voting power.
+func (s *State) hasTwoThirds(validators []common.Address) bool {
+	var power int64
+	for _, address := range validators {
+		if _, val := s.validators.GetByAddress(address); val != nil {
+			power += val.VotingPower
+		}
+	}
+	return power*3 > s.validators.TotalVotingPower()*2
+}
+
+// Status returns the status of the proposal with the given hash.
+func (s *State) Status(hash common.Hash) (Status, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	r, ok := s.records[hash]
+	if !ok {
+		return StatusPending, false
+	}
+	return r.Status, true
+}
+
+// Votes returns the votes of the proposal with the given hash.
+func (s *State) Votes(hash common.Hash) []*Vote {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	r, ok := s.records[hash]
+	if !ok {
+		return nil
+	}
+	return append([]*Vote{}, r.Votes...)
+}
+
+// Executable returns the proposals to execute, oldest first. Deposit proposals of paused chains are held until their
+// transfers are resumed.
+func (s *State) Executable() []*Proposal {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var records []*record
+	for _, r := range s.records {
+		if r.Status == StatusExecutable && s.halted(r.Proposal) == nil {
+			records = append(records, r)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Height < records[j].Height })
+	proposals := make([]*Proposal, len(records))
+	for i, r := range records {
+		proposals[i] = r.Proposal
+	}
+	return proposals
+}
+
+// MarkExecuted records that the proposal with the given hash is executed by transaction txHash. The fee of an
+// executed transfer is accrued, and the amount of an executed fee withdrawal is deducted from accrued fees.
+func (s *State) MarkExecuted(hash common.Hash, txHash string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	r, ok := s.records[hash]
+	if !ok {
+		return fmt.Errorf("%w %v", ErrUnknownProposal, hash.Hex())
+	}
+	if r.Status != StatusExecutable {
+		return fmt.Errorf("proposal %v is %v, not executable", hash.Hex(), r.Status)
+	}
+	fee, err := s.feeUpdate(r.Proposal)
+	if err != nil {
+		return err
+	}
+	var writes []write
+	if fee != nil {
+		w, err := fee.write(s.pool.fees)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, w)
+	}
+	r.Status = StatusExecuted
+	r.FinalHeight = s.height
+	r.ExecutedTx = txHash
+	if err := s.save(hash, r, nil, writes...); err != nil {
+		return err
+	}
+	if fee != nil {
+		s.accrued[feeKey(fee.Chain, fee.Token)] = fee
+	}
+	s.logger.Info("Proposal is executed", "proposal", r.Proposal, "txHash", txHash)
+	return nil
+}
+
+// SetValidators replaces the validator set votes are tallied against.
+func (s *State) SetValidators(validators *types.ValidatorSet) error {
+	if validators.IsNilOrEmpty() {
+		return fmt.Errorf("empty validator set")
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.validators = validators
+	for hash, r := range s.records {
+		status := r.Status
+		s.tally(r)
+		if r.Status != status {
+			if err := s.save(hash, r, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Update sets the current height, expires the proposals which are not executable after expiryBlocks and prunes
+// the final proposals which are out of the pool's retention period.
+func (s *State) Update(height uint64) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.height = height
+	entries := make(map[common.Hash]*entry, len(s.records))
+	for hash, r := range s.records {
+		if s.expiryBlocks > 0 && r.Status.waiting() && height > r.Height+s.expiryBlocks {
+			r.Status = StatusExpired
+			r.FinalHeight = height
+			if err := s.save(hash, r, nil); err != nil {
+				return err
+			}
+			s.logger.Info("Proposal is expired", "hash", hash.Hex(), "votes", len(r.Votes))
+		}
+		entries[hash] = &r.entry
+	}
+	pruned, err := s.pool.prune(height, entries)
+	if err != nil {
+		return err
+	}
+	for _, hash := range pruned {
+		delete(s.records, hash)
+	}
+	return nil
+}
+
+// save stores r and writes, executing r first if it is an executable governance proposal.
+func (s *State) save(hash common.Hash, r *record, msg message, writes ...write) error {
+	if r.Status == StatusExecutable && r.Proposal.Type.governance() {
+		if err := s.executePause(hash, r); err != nil {
+			return err
+		}
+	}
+	if err := s.pool.save(hash, &r.entry, msg, writes...); err != nil {
+		return err
+	}
+	s.records[hash] = r
+	return nil
+}
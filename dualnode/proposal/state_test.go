@@ -0,0 +1,206 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+func newValidators(t *testing.T, n int) ([]*ecdsa.PrivateKey, *types.ValidatorSet) {
+	keys := make([]*ecdsa.PrivateKey, n)
+	vals := make([]*types.Validator, n)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		keys[i] = key
+		vals[i] = types.NewValidator(crypto.PubkeyToAddress(key.PublicKey), 10)
+	}
+	return keys, types.NewValidatorSet(vals)
+}
+
+func testAddress(key *ecdsa.PrivateKey) common.Address {
+	return crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func newTestState(t *testing.T, db *memorydb.Database, self *ecdsa.PrivateKey, vals *types.ValidatorSet, expiryBlocks uint64) *State {
+	pool, err := NewPool(db, 0)
+	require.NoError(t, err)
+	s, err := NewState(pool, testAddress(self), vals, expiryBlocks)
+	require.NoError(t, err)
+	return s
+}
+
+func testProposal(t *testing.T) *Proposal {
+	p, err := NewDepositProposal(&dualnode.Deposit{
+		Chain:       "ETH",
+		TxHash:      "0x01",
+		LogIndex:    2,
+		BlockNumber: 10,
+		Token:       "0x0A",
+		From:        "0x0B",
+		To:          "0x0C",
+		Amount:      big.NewInt(100),
+	}, "KAI")
+	require.NoError(t, err)
+	return p
+}
+
+func vote(t *testing.T, p *Proposal, key *ecdsa.PrivateKey) *Vote {
+	v, err := SignVote(p.Hash(), key)
+	require.NoError(t, err)
+	return v
+}
+
+func TestProposal_deposit(t *testing.T) {
+	p := testProposal(t)
+	d, err := p.Deposit()
+	require.NoError(t, err)
+	require.Equal(t, "0x01", d.TxHash)
+	require.Equal(t, uint(2), d.LogIndex)
+	require.Equal(t, big.NewInt(100), d.Amount)
+	require.Equal(t, p.Hash(), testProposal(t).Hash())
+
+	// NFT fields are optional, so that fungible deposits keep their encoding
+	nft, err := NewDepositProposal(&dualnode.Deposit{
+		Chain:    "ETH",
+		TxHash:   "0x01",
+		Token:    "0x0E",
+		Amount:   big.NewInt(1),
+		TokenID:  big.NewInt(42),
+		TokenURI: "ipfs://nft/42",
+	}, "KAI")
+	require.NoError(t, err)
+	d, err = nft.Deposit()
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(42), d.TokenID)
+	require.Equal(t, "ipfs://nft/42", d.TokenURI)
+	require.NotEqual(t, p.Hash(), nft.Hash())
+
+	_, err = (&Proposal{Type: ProposalTokenMapping}).Deposit()
+	require.Error(t, err)
+}
+
+func TestState_lifecycle(t *testing.T) {
+	keys, vals := newValidators(t, 4)
+	db := memorydb.New()
+	s := newTestState(t, db, keys[0], vals, 100)
+	p := testProposal(t)
+
+	// votes may arrive before the proposal
+	status, err := s.AddVote(vote(t, p, keys[1]))
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, status)
+
+	status, err = s.AddProposal(p)
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, status)
+
+	status, err = s.AddVote(vote(t, p, keys[0]))
+	require.NoError(t, err)
+	require.Equal(t, StatusVoted, status)
+
+	_, err = s.AddVote(vote(t, p, keys[0]))
+	require.True(t, errors.Is(err, ErrDuplicateVote))
+
+	other, _ := newValidators(t, 1)
+	_, err = s.AddVote(vote(t, p, other[0]))
+	require.True(t, errors.Is(err, ErrNotValidator))
+	forged := vote(t, p, keys[2])
+	forged.ValidatorAddress = crypto.PubkeyToAddress(keys[3].PublicKey)
+	_, err = s.AddVote(forged)
+	require.True(t, errors.Is(err, ErrInvalidSignature))
+	require.Empty(t, s.Executable())
+
+	status, err = s.AddVote(vote(t, p, keys[2]))
+	require.NoError(t, err)
+	require.Equal(t, StatusExecutable, status)
+	require.Equal(t, []*Proposal{p}, s.Executable())
+	require.Len(t, s.Votes(p.Hash()), 3)
+
+	// progress survives restarts
+	restarted := newTestState(t, db, keys[0], vals, 100)
+	status, ok := restarted.Status(p.Hash())
+	require.True(t, ok)
+	require.Equal(t, StatusExecutable, status)
+
+	require.NoError(t, restarted.MarkExecuted(p.Hash(), "0xabc"))
+	require.Error(t, restarted.MarkExecuted(p.Hash(), "0xabc"))
+	require.Empty(t, restarted.Executable())
+
+	// late votes don't change executed proposals
+	status, err = restarted.AddVote(vote(t, p, keys[3]))
+	require.NoError(t, err)
+	require.Equal(t, StatusExecuted, status)
+}
+
+func TestState_expiry(t *testing.T) {
+	keys, vals := newValidators(t, 4)
+	s := newTestState(t, memorydb.New(), keys[0], vals, 10)
+	require.NoError(t, s.Update(5))
+	p := testProposal(t)
+	_, err := s.AddProposal(p)
+	require.NoError(t, err)
+	_, err = s.AddVote(vote(t, p, keys[0]))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Update(15))
+	status, _ := s.Status(p.Hash())
+	require.Equal(t, StatusVoted, status)
+
+	require.NoError(t, s.Update(16))
+	status, _ = s.Status(p.Hash())
+	require.Equal(t, StatusExpired, status)
+
+	status, err = s.AddVote(vote(t, p, keys[1]))
+	require.NoError(t, err)
+	require.Equal(t, StatusExpired, status)
+}
+
+func TestState_setValidators(t *testing.T) {
+	keys, vals := newValidators(t, 4)
+	s := newTestState(t, memorydb.New(), keys[0], vals, 0)
+	p := testProposal(t)
+	_, err := s.AddProposal(p)
+	require.NoError(t, err)
+	for _, key := range keys[:2] {
+		_, err = s.AddVote(vote(t, p, key))
+		require.NoError(t, err)
+	}
+
+	// the votes are more than two thirds of the remaining validators
+	remaining := types.NewValidatorSet([]*types.Validator{
+		types.NewValidator(crypto.PubkeyToAddress(keys[0].PublicKey), 10),
+		types.NewValidator(crypto.PubkeyToAddress(keys[1].PublicKey), 10),
+		types.NewValidator(crypto.PubkeyToAddress(keys[2].PublicKey), 5),
+	})
+	require.NoError(t, s.SetValidators(remaining))
+	status, _ := s.Status(p.Hash())
+	require.Equal(t, StatusExecutable, status)
+}
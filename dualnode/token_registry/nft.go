@@ -0,0 +1,81 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package token_registry
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+// Wrapped NFTs are stored under wrappedNFTPrefix + chain + "/" + contract + "/" + token id.
+var wrappedNFTPrefix = []byte("dualnft/")
+
+// WrappedNFT is an NFT minted on a chain for an NFT locked on its origin chain, with the same token id.
+type WrappedNFT struct {
+	Chain   string   // chain of the wrapped NFT
+	Address string   // contract of the wrapped NFT
+	TokenID *big.Int // id of both NFTs
+	Origin  Asset    // contract of the locked NFT
+	URI     string   // metadata URI of the locked NFT
+}
+
+// AddWrappedNFT records an NFT minted for a deposit of its origin NFT, so that the origin NFT is unlocked when the
+// wrapped one is deposited back.
+func (r *Registry) AddWrappedNFT(w *WrappedNFT) error {
+	if w.TokenID == nil || w.TokenID.Sign() < 0 {
+		return fmt.Errorf("invalid token id %v of wrapped NFT", w.TokenID)
+	}
+	wrapped := *w
+	wrapped.Address = normalizeAddress(wrapped.Address)
+	wrapped.Origin.Address = normalizeAddress(wrapped.Origin.Address)
+	if !wrapped.Origin.NFT || wrapped.Origin.Chain == wrapped.Chain {
+		return fmt.Errorf("invalid origin %v:%v of wrapped NFT", wrapped.Origin.Chain, wrapped.Origin.Address)
+	}
+	data, err := rlp.EncodeToBytes(&wrapped)
+	if err != nil {
+		return err
+	}
+	return r.db.Put(wrappedNFTKey(wrapped.Chain, wrapped.Address, wrapped.TokenID), data)
+}
+
+// WrappedNFT returns the wrapped NFT tokenID of contract address on chain, or nil if it is not a wrapped NFT.
+func (r *Registry) WrappedNFT(chain, address string, tokenID *big.Int) (*WrappedNFT, error) {
+	data, _ := r.db.Get(wrappedNFTKey(chain, normalizeAddress(address), tokenID))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var w WrappedNFT
+	if err := rlp.DecodeBytes(data, &w); err != nil {
+		return nil, fmt.Errorf("invalid wrapped NFT %v:%v %v: %w", chain, address, tokenID, err)
+	}
+	return &w, nil
+}
+
+// RemoveWrappedNFT deletes the record of a wrapped NFT once it is burned and its origin NFT is unlocked.
+func (r *Registry) RemoveWrappedNFT(chain, address string, tokenID *big.Int) error {
+	return r.db.Delete(wrappedNFTKey(chain, normalizeAddress(address), tokenID))
+}
+
+func wrappedNFTKey(chain, address string, tokenID *big.Int) []byte {
+	key := append([]byte{}, wrappedNFTPrefix...)
+	return append(key, strings.Join([]string{chain, address, tokenID.String()}, keySeparator)...)
+}
@@ -0,0 +1,72 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package token_registry
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+)
+
+func TestRegistry_nft(t *testing.T) {
+	db := memorydb.New()
+	r, err := New(db)
+	require.NoError(t, err)
+	origin := Asset{Chain: "ETH", Address: "0x0E", NFT: true}
+	wrapped := Asset{Chain: "KAI", Address: "0x0F", NFT: true}
+	require.NoError(t, r.Init([]*Mapping{{Source: origin, Dest: wrapped}}))
+
+	// NFTs are converted without changing their amount
+	dest, amount, err := r.Convert("ETH", "0x0e", "KAI", big.NewInt(1))
+	require.NoError(t, err)
+	require.True(t, dest.NFT)
+	require.Equal(t, int64(1), amount.Int64())
+
+	require.NoError(t, r.AddWrappedNFT(&WrappedNFT{
+		Chain:   "KAI",
+		Address: "0x0F",
+		TokenID: big.NewInt(42),
+		Origin:  origin,
+		URI:     "ipfs://nft/42",
+	}))
+	restarted, err := New(db)
+	require.NoError(t, err)
+	require.Len(t, restarted.Mappings(), 1)
+	w, err := restarted.WrappedNFT("KAI", "0x0f", big.NewInt(42))
+	require.NoError(t, err)
+	require.Equal(t, "ETH", w.Origin.Chain)
+	require.Equal(t, "0x0e", w.Origin.Address)
+	require.Equal(t, "ipfs://nft/42", w.URI)
+
+	w, err = restarted.WrappedNFT("KAI", "0x0f", big.NewInt(43))
+	require.NoError(t, err)
+	require.Nil(t, w)
+
+	require.NoError(t, restarted.RemoveWrappedNFT("KAI", "0x0F", big.NewInt(42)))
+	w, err = restarted.WrappedNFT("KAI", "0x0f", big.NewInt(42))
+	require.NoError(t, err)
+	require.Nil(t, w)
+
+	// the origin of a wrapped NFT is an NFT of another chain
+	require.Error(t, r.AddWrappedNFT(&WrappedNFT{Chain: "KAI", Address: "0x0F", TokenID: big.NewInt(1), Origin: usdtEth}))
+	require.Error(t, r.AddWrappedNFT(&WrappedNFT{Chain: "ETH", Address: "0x0F", TokenID: big.NewInt(1), Origin: origin}))
+}
@@ -0,0 +1,312 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package token_registry maps assets of a chain to the assets minted or unlocked for them on other chains.
+package token_registry
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+// MaxDecimals is the maximum number of decimals of an asset, 10^77 is the largest power of 10 fitting in 256 bits.
+const MaxDecimals = 77
+
+// Mappings are stored under mappingPrefix + source chain + "/" + source asset + "/" + destination chain.
+var (
+	mappingPrefix = []byte("dualtoken/")
+	keySeparator  = "/"
+)
+
+var (
+	ErrUnknownMapping = errors.New("unknown token mapping")
+	ErrZeroAmount     = errors.New("amount is zero after decimals conversion")
+)
+
+type (
+	// Asset is a token of a chain, Address is empty for the native coin of the chain.
+	Asset struct {
+		Chain    string `yaml:"Chain"`
+		Address  string `yaml:"Address"`
+		Decimals uint8  `yaml:"Decimals"`
+		NFT      bool   `yaml:"NFT" rlp:"optional"` // ERC-721 contract, whose tokens keep their id on every chain
+	}
+
+	// Mapping is the asset Dest minted or unlocked on Dest.Chain for deposits of Source.
+	Mapping struct {
+		Source Asset `yaml:"Source"`
+		Dest   Asset `yaml:"Dest"`
+	}
+)
+
+// UpdateAction is the change made by a token mapping proposal.
+type UpdateAction uint8
+
+const (
+	UpdateSet    UpdateAction = iota // add or replace the mapping
+	UpdateRemove                     // remove the mapping of the source asset to the destination chain
+)
+
+// Update is the content of a token mapping proposal.
+type Update struct {
+	Action  UpdateAction
+	Mapping Mapping
+}
+
+// Encode returns the RLP encoding of u carried by proposals.
+func (u *Update) Encode() ([]byte, error) {
+	return rlp.EncodeToBytes(u)
+}
+
+// DecodeUpdate decodes the update of a token mapping proposal.
+func DecodeUpdate(data []byte) (*Update, error) {
+	var u Update
+	if err := rlp.DecodeBytes(data, &u); err != nil {
+		return nil, fmt.Errorf("invalid token mapping update: %w", err)
+	}
+	return &u, nil
+}
+
+// validate checks m and normalizes its addresses.
+func (m *Mapping) validate() error {
+	for _, asset := range []*Asset{&m.Source, &m.Dest} {
+		if asset.Chain == "" || strings.Contains(asset.Chain, keySeparator) {
+			return fmt.Errorf("invalid chain %q of token mapping", asset.Chain)
+		}
+		if strings.Contains(asset.Address, keySeparator) {
+			return fmt.Errorf("invalid asset %q of token mapping", asset.Address)
+		}
+		if asset.Decimals > MaxDecimals {
+			return fmt.Errorf("decimals %v of %v exceeds %v", asset.Decimals, asset.Address, MaxDecimals)
+		}
+		asset.Address = normalizeAddress(asset.Address)
+	}
+	if m.Source.Chain == m.Dest.Chain {
+		return fmt.Errorf("token mapping from %v to the same chain", m.Source.Chain)
+	}
+	if m.Source.NFT != m.Dest.NFT {
+		return fmt.Errorf("token mapping %v between an NFT and a fungible token", m)
+	}
+	if m.Source.NFT && (m.Source.Address == "" || m.Dest.Address == "" || m.Source.Decimals != 0 || m.Dest.Decimals != 0) {
+		return fmt.Errorf("NFT mapping %v requires contracts without decimals", m)
+	}
+	return nil
+}
+
+func (m *Mapping) String() string {
+	return fmt.Sprintf("Mapping{%v:%v(%v) -> %v:%v(%v)}", m.Source.Chain, m.Source.Address, m.Source.Decimals,
+		m.Dest.Chain, m.Dest.Address, m.Dest.Decimals)
+}
+
+// Convert returns amount of the source asset expressed with the decimals of the destination asset. Digits lost
+// when the destination has less decimals are returned as dust, in units of the source asset.
+func (m *Mapping) Convert(amount *big.Int) (converted *big.Int, dust *big.Int) {
+	return ConvertDecimals(amount, m.Source.Decimals, m.Dest.Decimals)
+}
+
+// ConvertDecimals converts amount from an asset with from decimals to an asset with to decimals. The remainder
+// which can't be represented with to decimals is returned as dust.
+func ConvertDecimals(amount *big.Int, from, to uint8) (converted *big.Int, dust *big.Int) {
+	switch {
+	case from < to:
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(to-from)), nil)
+		return new(big.Int).Mul(amount, scale), new(big.Int)
+	case from > to:
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(from-to)), nil)
+		return new(big.Int).QuoRem(amount, scale, new(big.Int))
+	default:
+		return new(big.Int).Set(amount), new(big.Int)
+	}
+}
+
+// store is the part of a database the registry needs.
+type store interface {
+	kaidb.KeyValueReader
+	kaidb.KeyValueWriter
+	kaidb.Batcher
+	kaidb.Iteratee
+}
+
+// Registry is the persistent set of token mappings used to compute the amounts minted or unlocked for deposits.
+type Registry struct {
+	mtx      sync.RWMutex
+	db       store
+	mappings map[string]*Mapping
+}
+
+// New returns the registry stored in db.
+func New(db store) (*Registry, error) {
+	r := &Registry{db: db, mappings: make(map[string]*Mapping)}
+	it := db.NewIterator(mappingPrefix, nil)
+	defer it.Release()
+	for it.Next() {
+		var m Mapping
+		if err := rlp.DecodeBytes(it.Value(), &m); err != nil {
+			return nil, fmt.Errorf("invalid token mapping %q: %w", it.Key(), err)
+		}
+		r.mappings[string(it.Key())] = &m
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Init stores genesis mappings if the registry is empty, mappings updated by proposals are kept on restart.
+func (r *Registry) Init(genesis []*Mapping) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if len(r.mappings) > 0 {
+		return nil
+	}
+	batch := r.db.NewBatch()
+	mappings := make(map[string]*Mapping, len(genesis))
+	for _, m := range genesis {
+		m := *m
+		if err := m.validate(); err != nil {
+			return err
+		}
+		key := mappingKey(m.Source.Chain, m.Source.Address, m.Dest.Chain)
+		if _, ok := mappings[string(key)]; ok {
+			return fmt.Errorf("duplicate genesis token mapping %v", &m)
+		}
+		data, err := rlp.EncodeToBytes(&m)
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(key, data); err != nil {
+			return err
+		}
+		mappings[string(key)] = &m
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	r.mappings = mappings
+	return nil
+}
+
+// Get returns the mapping of asset of sourceChain to destChain.
+func (r *Registry) Get(sourceChain, asset, destChain string) (*Mapping, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	m, ok := r.mappings[string(mappingKey(sourceChain, normalizeAddress(asset), destChain))]
+	if !ok {
+		return nil, false
+	}
+	mapping := *m
+	return &mapping, true
+}
+
+// Mappings returns all mappings ordered by source chain, source asset and destination chain.
+func (r *Registry) Mappings() []*Mapping {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	keys := make([]string, 0, len(r.mappings))
+	for key := range r.mappings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	mappings := make([]*Mapping, len(keys))
+	for i, key := range keys {
+		m := *r.mappings[key]
+		mappings[i] = &m
+	}
+	return mappings
+}
+
+// Convert returns the destination asset and amount minted or unlocked on destChain for a deposit of amount of
+// asset on sourceChain. It fails if the asset is not mapped or if the amount is lost by the conversion.
+func (r *Registry) Convert(sourceChain, asset, destChain string, amount *big.Int) (*Asset, *big.Int, error) {
+	m, ok := r.Get(sourceChain, asset, destChain)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %v:%v to %v", ErrUnknownMapping, sourceChain, asset, destChain)
+	}
+	converted, _ := m.Convert(amount)
+	if converted.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("%w: %v of %v", ErrZeroAmount, amount, m)
+	}
+	return &m.Dest, converted, nil
+}
+
+// Apply executes the update of a token mapping proposal voted by validators.
+func (r *Registry) Apply(u *Update) error {
+	switch u.Action {
+	case UpdateSet:
+		return r.Set(&u.Mapping)
+	case UpdateRemove:
+		return r.Remove(u.Mapping.Source.Chain, u.Mapping.Source.Address, u.Mapping.Dest.Chain)
+	default:
+		return fmt.Errorf("unknown token mapping update action %v", u.Action)
+	}
+}
+
+// Set adds or replaces a mapping.
+func (r *Registry) Set(m *Mapping) error {
+	mapping := *m
+	if err := mapping.validate(); err != nil {
+		return err
+	}
+	data, err := rlp.EncodeToBytes(&mapping)
+	if err != nil {
+		return err
+	}
+	key := mappingKey(mapping.Source.Chain, mapping.Source.Address, mapping.Dest.Chain)
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if err := r.db.Put(key, data); err != nil {
+		return err
+	}
+	r.mappings[string(key)] = &mapping
+	return nil
+}
+
+// Remove deletes the mapping of asset of sourceChain to destChain.
+func (r *Registry) Remove(sourceChain, asset, destChain string) error {
+	key := mappingKey(sourceChain, normalizeAddress(asset), destChain)
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, ok := r.mappings[string(key)]; !ok {
+		return fmt.Errorf("%w: %v:%v to %v", ErrUnknownMapping, sourceChain, asset, destChain)
+	}
+	if err := r.db.Delete(key); err != nil {
+		return err
+	}
+	delete(r.mappings, string(key))
+	return nil
+}
+
+func mappingKey(sourceChain, asset, destChain string) []byte {
+	key := append([]byte{}, mappingPrefix...)
+	return append(key, strings.Join([]string{sourceChain, asset, destChain}, keySeparator)...)
+}
+
+// normalizeAddress lower cases hex addresses so that checksummed and plain addresses are the same asset.
+func normalizeAddress(address string) string {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
@@ -0,0 +1,112 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package token_registry
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+)
+
+var (
+	usdtEth = Asset{Chain: "ETH", Address: "0xdAC17F958D2ee523a2206206994597C13D831ec7", Decimals: 6}
+	usdtKai = Asset{Chain: "KAI", Address: "0x00000000000000000000000000000000000000AA", Decimals: 18}
+)
+
+func TestConvertDecimals(t *testing.T) {
+	converted, dust := ConvertDecimals(big.NewInt(1500000), 6, 18)
+	require.Equal(t, "1500000000000000000", converted.String())
+	require.Zero(t, dust.Sign())
+
+	converted, dust = ConvertDecimals(big.NewInt(1500000000000000123), 18, 6)
+	require.Equal(t, int64(1500000), converted.Int64())
+	require.Equal(t, int64(123), dust.Int64())
+
+	converted, dust = ConvertDecimals(big.NewInt(42), 8, 8)
+	require.Equal(t, int64(42), converted.Int64())
+	require.Zero(t, dust.Sign())
+}
+
+func TestRegistry(t *testing.T) {
+	db := memorydb.New()
+	r, err := New(db)
+	require.NoError(t, err)
+	require.NoError(t, r.Init([]*Mapping{
+		{Source: usdtEth, Dest: usdtKai},
+		{Source: usdtKai, Dest: usdtEth},
+	}))
+
+	// addresses are matched case-insensitively
+	dest, amount, err := r.Convert("ETH", "0xdac17f958d2ee523a2206206994597c13d831ec7", "KAI", big.NewInt(2000000))
+	require.NoError(t, err)
+	require.Equal(t, "0x00000000000000000000000000000000000000aa", dest.Address)
+	require.Equal(t, "2000000000000000000", amount.String())
+
+	dest, amount, err = r.Convert("KAI", usdtKai.Address, "ETH", big.NewInt(2000000000000000001))
+	require.NoError(t, err)
+	require.Equal(t, "ETH", dest.Chain)
+	require.Equal(t, int64(2000000), amount.Int64())
+
+	_, _, err = r.Convert("KAI", usdtKai.Address, "ETH", big.NewInt(999999999999))
+	require.True(t, errors.Is(err, ErrZeroAmount))
+	_, _, err = r.Convert("ETH", usdtEth.Address, "BSC", big.NewInt(1))
+	require.True(t, errors.Is(err, ErrUnknownMapping))
+
+	// proposals update the registry, genesis mappings don't override them on restart
+	update := &Update{Action: UpdateSet, Mapping: Mapping{Source: usdtEth, Dest: Asset{Chain: "BSC", Address: "0x55", Decimals: 18}}}
+	data, err := update.Encode()
+	require.NoError(t, err)
+	decoded, err := DecodeUpdate(data)
+	require.NoError(t, err)
+	require.NoError(t, r.Apply(decoded))
+	require.NoError(t, r.Apply(&Update{Action: UpdateRemove, Mapping: Mapping{Source: usdtKai, Dest: usdtEth}}))
+	require.Error(t, r.Apply(&Update{Action: UpdateRemove, Mapping: Mapping{Source: usdtKai, Dest: usdtEth}}))
+
+	reloaded, err := New(db)
+	require.NoError(t, err)
+	require.NoError(t, reloaded.Init([]*Mapping{{Source: usdtKai, Dest: usdtEth}}))
+	mappings := reloaded.Mappings()
+	require.Len(t, mappings, 2)
+	require.Equal(t, "BSC", mappings[0].Dest.Chain)
+	require.Equal(t, "KAI", mappings[1].Dest.Chain)
+	_, ok := reloaded.Get("KAI", usdtKai.Address, "ETH")
+	require.False(t, ok)
+}
+
+func TestRegistry_invalidMappings(t *testing.T) {
+	r, err := New(memorydb.New())
+	require.NoError(t, err)
+	for _, m := range []*Mapping{
+		{Source: usdtEth, Dest: Asset{Chain: "ETH", Address: "0x01"}},
+		{Source: usdtEth, Dest: Asset{Address: "0x01"}},
+		{Source: usdtEth, Dest: Asset{Chain: "KAI/2", Address: "0x01"}},
+		{Source: usdtEth, Dest: Asset{Chain: "KAI", Address: "0x01", Decimals: MaxDecimals + 1}},
+		{Source: usdtEth, Dest: Asset{Chain: "KAI", Address: "0x01", NFT: true}},
+		{Source: Asset{Chain: "ETH", Address: "0x02", Decimals: 18, NFT: true}, Dest: Asset{Chain: "KAI", Address: "0x01", NFT: true}},
+	} {
+		require.Error(t, r.Set(m))
+	}
+	require.Error(t, r.Init([]*Mapping{{Source: usdtEth, Dest: usdtKai}, {Source: usdtEth, Dest: usdtKai}}))
+	require.Empty(t, r.Mappings())
+	require.Error(t, r.Apply(&Update{Action: 2}))
+}
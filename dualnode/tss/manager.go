@@ -0,0 +1,405 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tss
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+const (
+	// maxPendingSessions is the maximum number of sessions whose messages are kept until the node joins them.
+	maxPendingSessions = 64
+	// maxPendingMessages is the maximum number of kept messages of a session.
+	maxPendingMessages = 256
+)
+
+var keyShareKey = []byte("dualtss/keyshare")
+
+var (
+	ErrNoKey          = errors.New("no tss key share")
+	ErrInvalidParties = errors.New("invalid tss parties")
+)
+
+// store is the part of a database the manager needs.
+type store interface {
+	kaidb.KeyValueReader
+	kaidb.KeyValueWriter
+}
+
+// Manager runs the tss sessions of party self and signs release transactions with the group key.
+type Manager struct {
+	self      string
+	protocol  Protocol
+	transport Transport
+	db        store
+	logger    log.Logger
+
+	mtx      sync.Mutex
+	key      *KeyShare
+	sessions map[string]Session
+	pending  map[string][]*Message // messages of sessions the node has not joined yet
+}
+
+// NewManager returns the manager of party self, with the key share stored in db if any.
+func NewManager(self string, protocol Protocol, transport Transport, db store) (*Manager, error) {
+	m := &Manager{
+		self:      self,
+		protocol:  protocol,
+		transport: transport,
+		db:        db,
+		logger:    log.New("tss", self),
+		sessions:  make(map[string]Session),
+		pending:   make(map[string][]*Message),
+	}
+	data, _ := db.Get(keyShareKey)
+	if len(data) > 0 {
+		var key KeyShare
+		if err := rlp.DecodeBytes(data, &key); err != nil {
+			return nil, fmt.Errorf("invalid tss key share: %w", err)
+		}
+		m.key = &key
+	}
+	return m, nil
+}
+
+// Group returns the group of the node's key share, or nil if it has none.
+func (m *Manager) Group() *Group {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.key == nil {
+		return nil
+	}
+	group := m.key.Group
+	return &group
+}
+
+// Address returns the address of the group key, releases signed by the manager are sent from it.
+func (m *Manager) Address() common.Address {
+	group := m.Group()
+	if group == nil {
+		return common.Address{}
+	}
+	pub, err := crypto.UnmarshalPubkey(group.PublicKey)
+	if err != nil {
+		return common.Address{}
+	}
+	return crypto.PubkeyToAddress(*pub)
+}
+
+// HandleMessage passes a message received from a peer to its session. Messages of sessions which are not started
+// yet are kept until the node joins them.
+func (m *Manager) HandleMessage(msg *Message) error {
+	if msg.From == m.self {
+		return nil
+	}
+	if len(msg.To) > 0 && !contains(msg.To, m.self) {
+		return nil
+	}
+	m.mtx.Lock()
+	session, ok := m.sessions[msg.SessionID]
+	if !ok {
+		defer m.mtx.Unlock()
+		pending, known := m.pending[msg.SessionID]
+		if !known && len(m.pending) >= maxPendingSessions {
+			return fmt.Errorf("too many pending tss sessions, message of %v is dropped", msg.SessionID)
+		}
+		if len(pending) >= maxPendingMessages {
+			return fmt.Errorf("too many pending messages of tss session %v", msg.SessionID)
+		}
+		m.pending[msg.SessionID] = append(pending, msg)
+		return nil
+	}
+	m.mtx.Unlock()
+	return session.Update(msg)
+}
+
+// Keygen runs the ceremony generating a key shared by parties, which any threshold+1 of them can use to sign, and
+// stores the key share of the node.
+func (m *Manager) Keygen(ctx context.Context, parties []string, threshold uint64) (*KeyShare, error) {
+	parties, err := checkParties(parties, threshold)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(parties, m.self) {
+		return nil, fmt.Errorf("%w: %v is not a party", ErrInvalidParties, m.self)
+	}
+	id := sessionID(KindKeygen, nil, parties, threshold)
+	session, err := m.protocol.Keygen(id, m.self, parties, threshold)
+	if err != nil {
+		return nil, err
+	}
+	result, err := m.run(ctx, id, KindKeygen, session)
+	if err != nil {
+		return nil, err
+	}
+	if result.KeyShare == nil {
+		return nil, fmt.Errorf("tss keygen %v returned no key share", id)
+	}
+	if err := m.setKey(result.KeyShare); err != nil {
+		return nil, err
+	}
+	m.logger.Info("TSS key is generated", "address", m.Address().Hex(), "parties", len(parties), "threshold", threshold)
+	return result.KeyShare, nil
+}
+
+// Reshare runs the ceremony moving the key of group to parties without changing its public key. The node takes part
+// if it belongs to group or to parties, and its key share is replaced or deleted.
+func (m *Manager) Reshare(ctx context.Context, group *Group, parties []string, threshold uint64) (*KeyShare, error) {
+	m.mtx.Lock()
+	key := m.key
+	m.mtx.Unlock()
+	if key != nil && !bytes.Equal(key.Group.PublicKey, group.PublicKey) {
+		return nil, fmt.Errorf("tss reshare of group %x, but the key share is of %x", group.PublicKey, key.Group.PublicKey)
+	}
+	sorted, err := checkParties(parties, threshold)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(group.Parties, m.self) && !contains(sorted, m.self) {
+		return nil, fmt.Errorf("%w: %v is neither in the current nor in the new parties", ErrInvalidParties, m.self)
+	}
+	id := sessionID(KindReshare, group.PublicKey, sorted, threshold)
+	session, err := m.protocol.Reshare(id, m.self, group, key, sorted, threshold)
+	if err != nil {
+		return nil, err
+	}
+	result, err := m.run(ctx, id, KindReshare, session)
+	if err != nil {
+		return nil, err
+	}
+	if result.KeyShare == nil {
+		if contains(sorted, m.self) {
+			return nil, fmt.Errorf("tss reshare %v returned no key share", id)
+		}
+		if err := m.db.Delete(keyShareKey); err != nil {
+			return nil, err
+		}
+		m.mtx.Lock()
+		m.key = nil
+		m.mtx.Unlock()
+		m.logger.Info("TSS key share is removed, the node left the parties")
+		return nil, nil
+	}
+	if !bytes.Equal(result.KeyShare.Group.PublicKey, group.PublicKey) {
+		return nil, fmt.Errorf("tss reshare %v changed the group public key", id)
+	}
+	if err := m.setKey(result.KeyShare); err != nil {
+		return nil, err
+	}
+	m.logger.Info("TSS key is reshared", "parties", len(sorted), "threshold", threshold)
+	return result.KeyShare, nil
+}
+
+// UpdateParties generates the group key if there is none, or reshares it if the parties or the threshold change.
+// It is called when the bridge validator set changes.
+func (m *Manager) UpdateParties(ctx context.Context, parties []string, threshold uint64) error {
+	group := m.Group()
+	if group == nil {
+		_, err := m.Keygen(ctx, parties, threshold)
+		return err
+	}
+	if group.Threshold == threshold && equalParties(group.Parties, sortedParties(parties)) {
+		return nil
+	}
+	_, err := m.Reshare(ctx, group, parties, threshold)
+	return err
+}
+
+// Sign runs the ceremony signing digest with the group key and returns the [R || S || V] signature.
+func (m *Manager) Sign(ctx context.Context, digest []byte) ([]byte, error) {
+	m.mtx.Lock()
+	key := m.key
+	m.mtx.Unlock()
+	if key == nil {
+		return nil, ErrNoKey
+	}
+	id := sessionID(KindSign, key.Group.PublicKey, nil, 0, digest)
+	session, err := m.protocol.Sign(id, m.self, key, digest)
+	if err != nil {
+		return nil, err
+	}
+	result, err := m.run(ctx, id, KindSign, session)
+	if err != nil {
+		return nil, err
+	}
+	// a faulty protocol or party must not make the node send an invalid release
+	pub, err := crypto.Ecrecover(digest, result.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tss signature of %v: %w", id, err)
+	}
+	if !bytes.Equal(pub, key.Group.PublicKey) {
+		return nil, fmt.Errorf("tss signature of %v is not signed by the group key", id)
+	}
+	return result.Signature, nil
+}
+
+// SignTx signs tx with the group key, it implements evm_adapter.TxSigner.
+func (m *Manager) SignTx(ctx context.Context, tx *types.Transaction, signer types.Signer) (*types.Transaction, error) {
+	h := signer.Hash(tx)
+	sig, err := m.Sign(ctx, h[:])
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// run starts session, sends its messages until it is done and returns its result.
+func (m *Manager) run(ctx context.Context, id string, kind SessionKind, session Session) (*Result, error) {
+	if err := session.Start(); err != nil {
+		return nil, err
+	}
+	m.mtx.Lock()
+	if _, ok := m.sessions[id]; ok {
+		m.mtx.Unlock()
+		return nil, fmt.Errorf("tss session %v is already running", id)
+	}
+	m.sessions[id] = session
+	pending := m.pending[id]
+	delete(m.pending, id)
+	m.mtx.Unlock()
+	defer func() {
+		m.mtx.Lock()
+		delete(m.sessions, id)
+		m.mtx.Unlock()
+	}()
+
+	for _, msg := range pending {
+		if err := session.Update(msg); err != nil {
+			m.logger.Warn("Invalid tss message", "session", id, "from", msg.From, "err", err)
+		}
+	}
+	for {
+		select {
+		case msg := <-session.Outgoing():
+			m.send(id, kind, msg)
+		case result := <-session.Done():
+			// messages queued before completion may still be needed by other parties
+			for flushed := false; !flushed; {
+				select {
+				case msg := <-session.Outgoing():
+					m.send(id, kind, msg)
+				default:
+					flushed = true
+				}
+			}
+			if result.Err != nil {
+				return nil, fmt.Errorf("tss %v %v failed: %w", kind, id, result.Err)
+			}
+			return result, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (m *Manager) send(id string, kind SessionKind, msg *Message) {
+	msg.SessionID = id
+	msg.Kind = kind
+	msg.From = m.self
+	if err := m.transport.Send(msg); err != nil {
+		m.logger.Warn("Failed to send tss message", "session", id, "round", msg.Round, "err", err)
+	}
+}
+
+func (m *Manager) setKey(key *KeyShare) error {
+	data, err := rlp.EncodeToBytes(key)
+	if err != nil {
+		return err
+	}
+	if err := m.db.Put(keyShareKey, data); err != nil {
+		return err
+	}
+	m.mtx.Lock()
+	m.key = key
+	m.mtx.Unlock()
+	return nil
+}
+
+// checkParties returns the sorted parties of a key, which must be distinct and more than threshold.
+func checkParties(parties []string, threshold uint64) ([]string, error) {
+	sorted := sortedParties(parties)
+	if threshold >= uint64(len(sorted)) || hasDuplicates(sorted) {
+		return nil, fmt.Errorf("%w: threshold %v of %v parties", ErrInvalidParties, threshold, len(sorted))
+	}
+	return sorted, nil
+}
+
+// sessionID returns the id of a ceremony, it is the same for all of its parties.
+func sessionID(kind SessionKind, publicKey []byte, parties []string, threshold uint64, data ...[]byte) string {
+	var buf bytes.Buffer
+	buf.Write(publicKey)
+	buf.WriteString(strings.Join(parties, ","))
+	_ = binary.Write(&buf, binary.BigEndian, threshold)
+	for _, d := range data {
+		buf.Write(d)
+	}
+	return kind.String() + "/" + hexutil.Encode(crypto.Keccak256(buf.Bytes()))
+}
+
+func sortedParties(parties []string) []string {
+	sorted := append([]string{}, parties...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func equalParties(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func hasDuplicates(sorted []string) bool {
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i] == sorted[i-1] {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(parties []string, party string) bool {
+	for _, p := range parties {
+		if p == party {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,287 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tss
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/dualnode/evm_adapter"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+)
+
+var _ evm_adapter.TxSigner = (*Manager)(nil)
+
+// fakeProtocol simulates a threshold scheme: the group key is known by the protocol and a session completes once
+// it has received a message from every other party.
+type fakeProtocol struct {
+	key *ecdsa.PrivateKey
+}
+
+func (p *fakeProtocol) Keygen(sessionID, self string, parties []string, threshold uint64) (Session, error) {
+	return newFakeSession(self, parties, func() *Result {
+		return &Result{KeyShare: &KeyShare{
+			Group: Group{PublicKey: crypto.FromECDSAPub(&p.key.PublicKey), Threshold: threshold, Parties: parties},
+			Data:  []byte(self),
+		}}
+	}), nil
+}
+
+func (p *fakeProtocol) Sign(sessionID, self string, key *KeyShare, digest []byte) (Session, error) {
+	return newFakeSession(self, key.Group.Parties, func() *Result {
+		sig, err := crypto.Sign(digest, p.key)
+		return &Result{Signature: sig, Err: err}
+	}), nil
+}
+
+func (p *fakeProtocol) Reshare(sessionID, self string, group *Group, key *KeyShare, parties []string, threshold uint64) (Session, error) {
+	all := append([]string{}, parties...)
+	for _, party := range group.Parties {
+		if !contains(all, party) {
+			all = append(all, party)
+		}
+	}
+	return newFakeSession(self, all, func() *Result {
+		if !contains(parties, self) {
+			return &Result{}
+		}
+		return &Result{KeyShare: &KeyShare{
+			Group: Group{PublicKey: group.PublicKey, Threshold: threshold, Parties: parties},
+			Data:  []byte(self),
+		}}
+	}), nil
+}
+
+type fakeSession struct {
+	mtx      sync.Mutex
+	self     string
+	waiting  map[string]bool
+	finish   func() *Result
+	outgoing chan *Message
+	done     chan *Result
+}
+
+func newFakeSession(self string, parties []string, finish func() *Result) *fakeSession {
+	s := &fakeSession{
+		self:     self,
+		waiting:  make(map[string]bool),
+		finish:   finish,
+		outgoing: make(chan *Message, 1),
+		done:     make(chan *Result, 1),
+	}
+	for _, party := range parties {
+		if party != self {
+			s.waiting[party] = true
+		}
+	}
+	return s
+}
+
+func (s *fakeSession) Start() error {
+	s.outgoing <- &Message{Round: 1, Payload: []byte(s.self)}
+	if len(s.waiting) == 0 {
+		s.done <- s.finish()
+	}
+	return nil
+}
+
+func (s *fakeSession) Update(msg *Message) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if !s.waiting[msg.From] {
+		return fmt.Errorf("unexpected message from %v", msg.From)
+	}
+	delete(s.waiting, msg.From)
+	if len(s.waiting) == 0 {
+		s.done <- s.finish()
+	}
+	return nil
+}
+
+func (s *fakeSession) Outgoing() <-chan *Message { return s.outgoing }
+func (s *fakeSession) Done() <-chan *Result      { return s.done }
+
+// network delivers messages between managers, encoding them as the p2p layer does.
+type network struct {
+	mtx      sync.Mutex
+	managers map[string]*Manager
+}
+
+func (n *network) Send(msg *Message) error {
+	data, err := msg.Encode()
+	if err != nil {
+		return err
+	}
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	for party, m := range n.managers {
+		if party == msg.From {
+			continue
+		}
+		decoded, err := DecodeMessage(data)
+		if err != nil {
+			return err
+		}
+		go m.HandleMessage(decoded)
+	}
+	return nil
+}
+
+func newNetwork(t *testing.T, protocol Protocol, parties ...string) *network {
+	n := &network{managers: make(map[string]*Manager)}
+	for _, party := range parties {
+		n.add(t, protocol, party)
+	}
+	return n
+}
+
+func (n *network) add(t *testing.T, protocol Protocol, party string) *Manager {
+	m, err := NewManager(party, protocol, n, memorydb.New())
+	require.NoError(t, err)
+	n.mtx.Lock()
+	n.managers[party] = m
+	n.mtx.Unlock()
+	return m
+}
+
+// runAll runs fn for each party concurrently.
+func runAll(t *testing.T, parties []string, fn func(party string) error) {
+	var wg sync.WaitGroup
+	errs := make(chan error, len(parties))
+	for _, party := range parties {
+		wg.Add(1)
+		go func(party string) {
+			defer wg.Done()
+			errs <- fn(party)
+		}(party)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}
+
+func TestManager_keygenAndSignTx(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	protocol := &fakeProtocol{key: key}
+	parties := []string{"v3", "v1", "v2"}
+	n := newNetwork(t, protocol, parties...)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	runAll(t, parties, func(party string) error {
+		_, err := n.managers[party].Keygen(ctx, parties, 1)
+		return err
+	})
+	for _, m := range n.managers {
+		require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), m.Address())
+		require.Equal(t, []string{"v1", "v2", "v3"}, m.Group().Parties)
+	}
+
+	signer := types.NewEIP155Signer(big.NewInt(56))
+	tx := types.NewTransaction(1, common.HexToAddress("0x0C"), big.NewInt(10), 21000, big.NewInt(1), nil)
+	signed := make([]*types.Transaction, 0, len(parties))
+	var mtx sync.Mutex
+	runAll(t, parties, func(party string) error {
+		signedTx, err := n.managers[party].SignTx(ctx, tx, signer)
+		mtx.Lock()
+		signed = append(signed, signedTx)
+		mtx.Unlock()
+		return err
+	})
+	for _, signedTx := range signed {
+		sender, err := types.Sender(signer, signedTx)
+		require.NoError(t, err)
+		require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), sender)
+	}
+
+	// the key share is reloaded on restart
+	restarted, err := NewManager("v1", protocol, n, n.managers["v1"].db)
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), restarted.Address())
+}
+
+func TestManager_reshare(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	protocol := &fakeProtocol{key: key}
+	parties := []string{"v1", "v2", "v3"}
+	n := newNetwork(t, protocol, parties...)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	runAll(t, parties, func(party string) error {
+		return n.managers[party].UpdateParties(ctx, parties, 1)
+	})
+	group := n.managers["v1"].Group()
+
+	// v3 leaves and v4 joins the bridge validators
+	n.add(t, protocol, "v4")
+	newParties := []string{"v1", "v2", "v4"}
+	runAll(t, []string{"v1", "v2", "v3", "v4"}, func(party string) error {
+		m := n.managers[party]
+		if party == "v4" {
+			_, err := m.Reshare(ctx, group, newParties, 1)
+			return err
+		}
+		return m.UpdateParties(ctx, newParties, 1)
+	})
+	require.Nil(t, n.managers["v3"].Group())
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), n.managers["v4"].Address())
+	require.Equal(t, newParties, n.managers["v1"].Group().Parties)
+
+	// unchanged parties don't start a ceremony
+	require.NoError(t, n.managers["v1"].UpdateParties(ctx, newParties, 1))
+}
+
+func TestManager_invalidSessions(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	n := newNetwork(t, &fakeProtocol{key: key}, "v1")
+	m := n.managers["v1"]
+	ctx := context.Background()
+
+	_, err = m.Sign(ctx, make([]byte, 32))
+	require.Equal(t, ErrNoKey, err)
+	_, err = m.Keygen(ctx, []string{"v2", "v3"}, 1)
+	require.Error(t, err)
+	_, err = m.Keygen(ctx, []string{"v1", "v2"}, 2)
+	require.Error(t, err)
+	_, err = m.Keygen(ctx, []string{"v1", "v1"}, 1)
+	require.Error(t, err)
+
+	// a signature which is not made by the group key is rejected
+	require.NoError(t, m.setKey(&KeyShare{Group: Group{PublicKey: crypto.FromECDSAPub(&key.PublicKey), Parties: []string{"v1"}}}))
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	m.protocol = &fakeProtocol{key: other}
+	_, err = m.Sign(ctx, make([]byte, 32))
+	require.Error(t, err)
+}
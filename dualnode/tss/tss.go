@@ -0,0 +1,121 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package tss defines how bridge validators would sign release transactions with a threshold ECDSA key, so that
+// destination chains see a single signature of the group instead of a vote of each validator.
+//
+// Only the interfaces and the session Manager live here: the package ships no threshold protocol (eg: GG18/GG20)
+// and no Transport, and the dual node p2p layer has no channel for Message yet. Until a Protocol implementation and
+// a Transport are provided, nothing signs with a Manager and releases keep being signed by the adapter key.
+package tss
+
+import (
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+// SessionKind is the kind of ceremony run by a session.
+type SessionKind uint8
+
+const (
+	KindKeygen  SessionKind = iota // generate a new group key
+	KindSign                       // sign a digest with the group key
+	KindReshare                    // redistribute the group key to a new set of parties
+)
+
+func (k SessionKind) String() string {
+	switch k {
+	case KindKeygen:
+		return "keygen"
+	case KindSign:
+		return "sign"
+	case KindReshare:
+		return "reshare"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(k))
+	}
+}
+
+// Message is a message of a session sent between parties, it is broadcast to all parties if To is empty. It is sent
+// by the dual node p2p layer alongside votes.
+type Message struct {
+	SessionID string
+	Kind      SessionKind
+	From      string   // party id of the sender
+	To        []string // party ids of the receivers, empty for a broadcast
+	Round     uint32
+	Payload   []byte // protocol specific content
+}
+
+// Encode returns the RLP encoding of msg.
+func (msg *Message) Encode() ([]byte, error) {
+	return rlp.EncodeToBytes(msg)
+}
+
+// DecodeMessage decodes a session message received from a peer.
+func DecodeMessage(data []byte) (*Message, error) {
+	var msg Message
+	if err := rlp.DecodeBytes(data, &msg); err != nil {
+		return nil, fmt.Errorf("invalid tss message: %w", err)
+	}
+	return &msg, nil
+}
+
+// Group is the public part of a threshold key: any Threshold+1 of Parties can sign for PublicKey.
+type Group struct {
+	PublicKey []byte   // uncompressed secp256k1 public key
+	Threshold uint64   // maximum number of parties which can't sign together
+	Parties   []string // party ids, the addresses of the bridge validators
+}
+
+// KeyShare is the share of a threshold key held by a party.
+type KeyShare struct {
+	Group Group
+	Data  []byte // protocol specific secret share
+}
+
+// Result is the outcome of a session.
+type Result struct {
+	KeyShare  *KeyShare // keygen and reshare, nil if the party is not in the new group
+	Signature []byte    // sign, 65 bytes [R || S || V] signature with V being 0 or 1
+	Err       error
+}
+
+// Session is a running ceremony of a party. Messages to other parties are read from Outgoing, messages from them are
+// passed to Update, and the result is sent on Done once the ceremony is complete.
+type Session interface {
+	Start() error
+	Update(msg *Message) error
+	Outgoing() <-chan *Message
+	Done() <-chan *Result
+}
+
+// Protocol creates the sessions of a threshold ECDSA scheme for party self. All parties of a ceremony create their
+// session with the same sessionID.
+type Protocol interface {
+	Keygen(sessionID, self string, parties []string, threshold uint64) (Session, error)
+	Sign(sessionID, self string, key *KeyShare, digest []byte) (Session, error)
+	// Reshare moves the key of group to parties, key is nil if self is not in group.
+	Reshare(sessionID, self string, group *Group, key *KeyShare, parties []string, threshold uint64) (Session, error)
+}
+
+// Transport sends session messages to other parties.
+type Transport interface {
+	Send(msg *Message) error
+}
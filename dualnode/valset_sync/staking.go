@@ -0,0 +1,72 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package valset_sync
+
+import (
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/kai/events"
+	"github.com/kardiachain/go-kardia/kai/state"
+	"github.com/kardiachain/go-kardia/kvm"
+	"github.com/kardiachain/go-kardia/lib/event"
+	vm "github.com/kardiachain/go-kardia/mainchain/kvm"
+	"github.com/kardiachain/go-kardia/mainchain/staking"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// Chain is the part of the Kardia blockchain the staking contract is read from.
+type Chain interface {
+	vm.ChainContext
+	GetHeaderByHeight(height uint64) *types.Header
+	StateAt(height uint64) (*state.StateDB, error)
+	SubscribeChainHeadEvent(ch chan<- events.ChainHeadEvent) event.Subscription
+}
+
+// StakingSource is the Source reading the validator set of the canonical staking contract.
+type StakingSource struct {
+	chain   Chain
+	staking *staking.StakingSmcUtil
+}
+
+// NewStakingSource returns the source of validators of the staking contract of chain.
+func NewStakingSource(chain Chain) (*StakingSource, error) {
+	util, err := staking.NewSmcStakingUtil()
+	if err != nil {
+		return nil, err
+	}
+	return &StakingSource{chain: chain, staking: util}, nil
+}
+
+// Validators implements Source by calling getValidatorSets of the staking contract on the state of height.
+func (s *StakingSource) Validators(height uint64) ([]*types.Validator, error) {
+	header := s.chain.GetHeaderByHeight(height)
+	if header == nil {
+		return nil, fmt.Errorf("unknown block %v", height)
+	}
+	st, err := s.chain.StateAt(height)
+	if err != nil {
+		return nil, err
+	}
+	return s.staking.ApplyAndReturnValidatorSets(st, header, s.chain, kvm.Config{})
+}
+
+// SubscribeChainHeadEvent implements Source.
+func (s *StakingSource) SubscribeChainHeadEvent(ch chan<- events.ChainHeadEvent) event.Subscription {
+	return s.chain.SubscribeChainHeadEvent(ch)
+}
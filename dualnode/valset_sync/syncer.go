@@ -0,0 +1,229 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package valset_sync keeps the bridge validator set in sync with the staking contract of Kardia.
+package valset_sync
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/kardiachain/go-kardia/kai/events"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/event"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/lib/service"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+const (
+	defaultEpochBlocks = 17280
+	chainHeadChanSize  = 16
+)
+
+type (
+	Config struct {
+		EpochBlocks   uint64 `yaml:"EpochBlocks"`   // blocks between transitions of the bridge validator set
+		MaxValidators int    `yaml:"MaxValidators"` // validators with the most voting power in the bridge set, 0 for all
+	}
+)
+
+// Update is a change of the bridge validator set.
+type Update struct {
+	Height     uint64              // Kardia block from which the validator set applies
+	Epoch      uint64              // epoch of Height
+	Validators *types.ValidatorSet // bridge validator set from Height
+	Changes    []*types.Validator  // changes from the previous set, removed validators have no voting power
+	Slashing   bool                // the update removes validators dropped by the staking contract during the epoch
+}
+
+// Source reads validators from the staking contract of Kardia.
+type Source interface {
+	// Validators returns the bonded validators of the staking contract and their voting power at height.
+	Validators(height uint64) ([]*types.Validator, error)
+
+	// SubscribeChainHeadEvent sends the new blocks of Kardia to ch.
+	SubscribeChainHeadEvent(ch chan<- events.ChainHeadEvent) event.Subscription
+}
+
+// Syncer reads the validators of the staking contract on each Kardia block and sends the changes of the bridge
+// validator set to a channel. Additions and voting power changes are applied at epoch boundaries, so that votes of
+// an epoch are tallied against the same set, while validators dropped by the staking contract mid-epoch, eg: jailed
+// for slashing, are removed at once.
+type Syncer struct {
+	service.BaseService
+	config *Config
+	source Source
+	sink   chan<- *Update
+
+	mtx     sync.Mutex
+	current *types.ValidatorSet
+	height  uint64 // last synced height, 0 until the first sync
+
+	headCh chan events.ChainHeadEvent
+	sub    event.Subscription
+}
+
+// New returns a syncer of current, the bridge validator set in use, sending its updates to sink.
+func New(config *Config, source Source, current *types.ValidatorSet, sink chan<- *Update) *Syncer {
+	if config.EpochBlocks == 0 {
+		config.EpochBlocks = defaultEpochBlocks
+	}
+	s := &Syncer{
+		config:  config,
+		source:  source,
+		sink:    sink,
+		current: current,
+		headCh:  make(chan events.ChainHeadEvent, chainHeadChanSize),
+	}
+	s.BaseService = *service.NewBaseService(log.New("module", "dual_valset"), "ValSetSyncer", s)
+	return s
+}
+
+// OnStart implements service.Service by syncing on the new blocks of Kardia.
+func (s *Syncer) OnStart() error {
+	s.sub = s.source.SubscribeChainHeadEvent(s.headCh)
+	go s.run()
+	return nil
+}
+
+// OnStop implements service.Service.
+func (s *Syncer) OnStop() {
+	s.sub.Unsubscribe()
+}
+
+func (s *Syncer) run() {
+	for {
+		select {
+		case ev := <-s.headCh:
+			if err := s.Sync(ev.Block.Height()); err != nil {
+				s.Logger.Error("Failed to sync bridge validators", "height", ev.Block.Height(), "err", err)
+			}
+		case err := <-s.sub.Err():
+			if err != nil {
+				s.Logger.Error("Chain head subscription failed", "err", err)
+			}
+			return
+		case <-s.Quit():
+			return
+		}
+	}
+}
+
+// Validators returns the bridge validator set in use.
+func (s *Syncer) Validators() *types.ValidatorSet {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.current.Copy()
+}
+
+// Sync reads the staking contract at height and sends the update of the bridge validator set, if any. The first
+// sync and the first block of each epoch apply all changes, other blocks only remove the validators dropped by the
+// staking contract. Heights which are not above the last synced one are ignored.
+func (s *Syncer) Sync(height uint64) error {
+	s.mtx.Lock()
+	if s.height != 0 && height <= s.height {
+		s.mtx.Unlock()
+		return nil
+	}
+	epoch := height / s.config.EpochBlocks
+	transition := s.height == 0 || epoch > s.height/s.config.EpochBlocks
+	vals, err := s.source.Validators(height)
+	if err != nil {
+		s.mtx.Unlock()
+		return fmt.Errorf("can't read validators at %v: %w", height, err)
+	}
+	changes := Diff(s.current, BridgeValidators(vals, s.config.MaxValidators))
+	if !transition {
+		changes = removals(changes)
+	}
+	s.height = height
+	if len(changes) == 0 {
+		s.mtx.Unlock()
+		return nil
+	}
+	next := s.current.Copy()
+	if err := next.UpdateWithChangeSet(changes); err != nil {
+		s.mtx.Unlock()
+		return fmt.Errorf("invalid bridge validator changes at %v: %w", height, err)
+	}
+	s.current = next
+	s.mtx.Unlock()
+
+	u := &Update{Height: height, Epoch: epoch, Validators: next.Copy(), Changes: changes, Slashing: !transition}
+	s.Logger.Info("Bridge validator set changed", "height", height, "epoch", epoch, "changes", len(changes),
+		"slashing", u.Slashing)
+	select {
+	case s.sink <- u:
+	case <-s.Quit():
+	}
+	return nil
+}
+
+// BridgeValidators returns the validators of the bridge among vals, the validators of the staking contract: the
+// max ones with the most voting power, or all if max is 0. Validators without voting power are excluded.
+func BridgeValidators(vals []*types.Validator, max int) []*types.Validator {
+	bridge := make([]*types.Validator, 0, len(vals))
+	for _, val := range vals {
+		if val.VotingPower > 0 {
+			bridge = append(bridge, types.NewValidator(val.Address, val.VotingPower))
+		}
+	}
+	sort.SliceStable(bridge, func(i, j int) bool {
+		if bridge[i].VotingPower != bridge[j].VotingPower {
+			return bridge[i].VotingPower > bridge[j].VotingPower
+		}
+		return bytes.Compare(bridge[i].Address.Bytes(), bridge[j].Address.Bytes()) < 0
+	})
+	if max > 0 && len(bridge) > max {
+		bridge = bridge[:max]
+	}
+	return bridge
+}
+
+// Diff returns the changes turning current into the set of vals, ordered by address. Validators of current missing
+// from vals are removed with a voting power of 0.
+func Diff(current *types.ValidatorSet, vals []*types.Validator) []*types.Validator {
+	var changes []*types.Validator
+	next := make(map[common.Address]struct{}, len(vals))
+	for _, val := range vals {
+		next[val.Address] = struct{}{}
+		if _, cur := current.GetByAddress(val.Address); cur == nil || cur.VotingPower != val.VotingPower {
+			changes = append(changes, types.NewValidator(val.Address, val.VotingPower))
+		}
+	}
+	for _, cur := range current.Validators {
+		if _, ok := next[cur.Address]; !ok {
+			changes = append(changes, types.NewValidator(cur.Address, 0))
+		}
+	}
+	sort.Sort(types.ValidatorsByAddress(changes))
+	return changes
+}
+
+func removals(changes []*types.Validator) []*types.Validator {
+	var removed []*types.Validator
+	for _, val := range changes {
+		if val.VotingPower == 0 {
+			removed = append(removed, val)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,92 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package valset_sync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/events"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/event"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// fakeSource returns the validators set for each height, or the ones of the closest lower height.
+type fakeSource struct {
+	vals map[uint64][]*types.Validator
+	feed event.Feed
+}
+
+func (s *fakeSource) Validators(height uint64) ([]*types.Validator, error) {
+	for h := height; ; h-- {
+		if vals, ok := s.vals[h]; ok || h == 0 {
+			return vals, nil
+		}
+	}
+}
+
+func (s *fakeSource) SubscribeChainHeadEvent(ch chan<- events.ChainHeadEvent) event.Subscription {
+	return s.feed.Subscribe(ch)
+}
+
+func validator(b byte, power int64) *types.Validator {
+	return types.NewValidator(common.BytesToAddress([]byte{b}), power)
+}
+
+func TestSyncer(t *testing.T) {
+	source := &fakeSource{vals: map[uint64][]*types.Validator{
+		1:  {validator(1, 10), validator(2, 10), validator(3, 10)},
+		5:  {validator(1, 10), validator(2, 20), validator(3, 10), validator(4, 10)}, // changes wait for the epoch
+		7:  {validator(1, 10), validator(2, 20), validator(4, 10)},                   // 3 is jailed
+		12: {validator(1, 10), validator(2, 20), validator(4, 10), validator(5, 0)},
+	}}
+	sink := make(chan *Update, 4)
+	s := New(&Config{EpochBlocks: 10}, source, types.NewValidatorSet([]*types.Validator{validator(1, 10),
+		validator(2, 10), validator(3, 10)}), sink)
+
+	// the first sync applies the changes of the staking contract, there is none
+	for _, height := range []uint64{1, 5, 5} {
+		require.NoError(t, s.Sync(height))
+	}
+	require.Empty(t, sink)
+
+	// a validator dropped mid-epoch is removed at once
+	require.NoError(t, s.Sync(7))
+	u := <-sink
+	require.True(t, u.Slashing)
+	require.Equal(t, []*types.Validator{validator(3, 0)}, u.Changes)
+	require.Equal(t, 2, u.Validators.Size())
+
+	// other changes are applied at the next epoch, even if its first block is skipped
+	require.NoError(t, s.Sync(12))
+	u = <-sink
+	require.False(t, u.Slashing)
+	require.Equal(t, uint64(1), u.Epoch)
+	require.Equal(t, []*types.Validator{validator(2, 20), validator(4, 10)}, u.Changes)
+	require.Equal(t, int64(40), s.Validators().TotalVotingPower())
+	require.Equal(t, u.Validators.Hash(), s.Validators().Hash())
+}
+
+func TestBridgeValidators(t *testing.T) {
+	vals := []*types.Validator{validator(1, 10), validator(2, 30), validator(3, 0), validator(4, 20), validator(5, 20)}
+	require.Equal(t, []*types.Validator{validator(2, 30), validator(4, 20), validator(5, 20)}, BridgeValidators(vals, 3))
+	require.Len(t, BridgeValidators(vals, 0), 4)
+}
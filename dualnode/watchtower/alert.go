@@ -0,0 +1,110 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package watchtower
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/lib/metrics"
+)
+
+// AlertKind is the misbehavior detected by a watchtower.
+type AlertKind string
+
+const (
+	AlertUnknownDeposit     AlertKind = "unknownDeposit"     // a proposal is voted but its deposit isn't observed
+	AlertDepositMismatch    AlertKind = "depositMismatch"    // a proposal doesn't match the deposit it releases
+	AlertReorganisedDeposit AlertKind = "reorganisedDeposit" // a proposal of a deposit reorganised out of its chain is voted
+)
+
+// Alert is a proposal of bridge validators which doesn't match the source chain.
+type Alert struct {
+	Kind         AlertKind `json:"kind"`
+	ProposalHash string    `json:"proposalHash"`
+	Chain        string    `json:"chain,omitempty"`
+	TxHash       string    `json:"txHash,omitempty"`
+	LogIndex     uint      `json:"logIndex"`
+	Validators   []string  `json:"validators"` // validators which voted for the proposal
+	Height       uint64    `json:"height"`
+	Message      string    `json:"message"`
+}
+
+func (a *Alert) String() string {
+	return fmt.Sprintf("Alert{%v %v %v}", a.Kind, a.ProposalHash, a.Message)
+}
+
+// Alerter raises alerts of a watchtower.
+type Alerter interface {
+	Alert(a *Alert) error
+}
+
+// logAlerter logs alerts as errors.
+type logAlerter struct {
+	logger log.Logger
+}
+
+func (l *logAlerter) Alert(a *Alert) error {
+	l.logger.Error("Bridge validators misbehave", "kind", a.Kind, "proposal", a.ProposalHash, "chain", a.Chain,
+		"txHash", a.TxHash, "validators", a.Validators, "message", a.Message)
+	return nil
+}
+
+// metricsAlerter counts alerts by kind.
+type metricsAlerter struct{}
+
+func (metricsAlerter) Alert(a *Alert) error {
+	metrics.GetOrRegisterCounter("dualnode/watchtower/alerts/"+string(a.Kind), nil).Inc(1)
+	return nil
+}
+
+// webhookAlerter posts alerts in JSON to a URL.
+type webhookAlerter struct {
+	url     string
+	timeout time.Duration
+	client  *http.Client
+}
+
+func (w *webhookAlerter) Alert(a *Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook %v responded %v", w.url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package watchtower monitors bridge validators without voting: it verifies the proposals they vote for against the
+// deposits observed on source chains and raises alerts when they don't match.
+package watchtower
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/dualnode/proposal"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/event"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/lib/service"
+)
+
+const (
+	defaultGracePeriod    = 20
+	defaultWebhookTimeout = 10 * time.Second
+	depositChanSize       = 64
+)
+
+type (
+	Config struct {
+		GracePeriod    uint64        `yaml:"GracePeriod"`    // blocks a voted proposal may wait for its deposit to be observed
+		WebhookURL     string        `yaml:"WebhookURL"`     // URL receiving alerts in JSON, empty to disable
+		WebhookTimeout time.Duration `yaml:"WebhookTimeout"` // timeout of a webhook request
+	}
+)
+
+// depositKey identifies a deposit.
+type depositKey struct {
+	chain    string
+	txHash   string
+	logIndex uint
+}
+
+func keyOf(d *dualnode.Deposit) depositKey {
+	return depositKey{chain: d.Chain, txHash: d.TxHash, logIndex: d.LogIndex}
+}
+
+// observed is a deposit observed by an adapter.
+type observed struct {
+	deposit      *dualnode.Deposit
+	proposalHash common.Hash // hash of the proposal releasing the deposit
+	removed      bool        // the deposit is reorganised out of its chain
+}
+
+// alertKey deduplicates alerts.
+type alertKey struct {
+	kind AlertKind
+	hash common.Hash
+}
+
+// Watchtower runs the proposal pipeline of a dual node without a validator key. Deposits of its adapters are
+// proposed to its proposal.State as a validator would, and the proposals and votes received from validators are
+// checked against them.
+type Watchtower struct {
+	service.BaseService
+	config    *Config
+	state     *proposal.State
+	adapters  []dualnode.ChainAdapter
+	destChain func(d *dualnode.Deposit) (string, error) // destination chain of a deposit
+	alerters  []Alerter
+
+	mtx        sync.Mutex
+	height     uint64
+	deposits   map[depositKey]*observed
+	byProposal map[common.Hash]*observed
+	unverified map[common.Hash]uint64 // heights at which proposals without observed deposit are received
+	alerted    map[alertKey]struct{}
+	queued     []*Alert // alerts raised once the lock is released
+
+	depositCh chan *dualnode.Deposit
+	subs      event.SubscriptionScope
+}
+
+// New returns a watchtower of state, whose node must not be a validator. destChain returns the chain a deposit is
+// released on, as computed by validators.
+func New(config *Config, state *proposal.State, adapters []dualnode.ChainAdapter,
+	destChain func(d *dualnode.Deposit) (string, error)) *Watchtower {
+	if config.GracePeriod == 0 {
+		config.GracePeriod = defaultGracePeriod
+	}
+	if config.WebhookTimeout <= 0 {
+		config.WebhookTimeout = defaultWebhookTimeout
+	}
+	logger := log.New("module", "dual_watchtower")
+	w := &Watchtower{
+		config:     config,
+		state:      state,
+		adapters:   adapters,
+		destChain:  destChain,
+		alerters:   []Alerter{&logAlerter{logger: logger}, metricsAlerter{}},
+		deposits:   make(map[depositKey]*observed),
+		byProposal: make(map[common.Hash]*observed),
+		unverified: make(map[common.Hash]uint64),
+		alerted:    make(map[alertKey]struct{}),
+		depositCh:  make(chan *dualnode.Deposit, depositChanSize),
+	}
+	if config.WebhookURL != "" {
+		w.alerters = append(w.alerters, &webhookAlerter{
+			url:     config.WebhookURL,
+			timeout: config.WebhookTimeout,
+			client:  &http.Client{},
+		})
+	}
+	w.BaseService = *service.NewBaseService(logger, "Watchtower", w)
+	return w
+}
+
+// AddAlerter adds a destination of alerts.
+func (w *Watchtower) AddAlerter(a Alerter) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.alerters = append(w.alerters, a)
+}
+
+// OnStart implements service.Service by watching the deposits of adapters, which must be started.
+func (w *Watchtower) OnStart() error {
+	for _, adapter := range w.adapters {
+		w.subs.Track(adapter.WatchDeposits(w.depositCh))
+	}
+	go w.run()
+	return nil
+}
+
+// OnStop implements service.Service.
+func (w *Watchtower) OnStop() {
+	w.subs.Close()
+}
+
+func (w *Watchtower) run() {
+	for {
+		select {
+		case d := <-w.depositCh:
+			w.HandleDeposit(d)
+		case <-w.Quit():
+			return
+		}
+	}
+}
+
+// HandleDeposit proposes d, or checks that its proposal isn't voted if it is reorganised out of its chain.
+func (w *Watchtower) HandleDeposit(d *dualnode.Deposit) {
+	destChain, err := w.destChain(d)
+	if err != nil {
+		w.Logger.Warn("Deposit without destination", "chain", d.Chain, "txHash", d.TxHash, "err", err)
+		return
+	}
+	p, err := proposal.NewDepositProposal(d, destChain)
+	if err != nil {
+		w.Logger.Warn("Invalid deposit", "chain", d.Chain, "txHash", d.TxHash, "err", err)
+		return
+	}
+	hash := p.Hash()
+	defer w.raise()
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if d.Removed {
+		if obs, ok := w.deposits[keyOf(d)]; ok {
+			obs.removed = true
+		}
+		if _, err := w.state.CancelDeposit(d); err != nil && !errors.Is(err, proposal.ErrProposalExecuted) {
+			w.Logger.Warn("Failed to cancel proposal of reorganised deposit", "txHash", d.TxHash, "err", err)
+		}
+		if votes := w.state.Votes(hash); len(votes) > 0 {
+			w.alert(AlertReorganisedDeposit, hash, d, "proposal of a reorganised deposit is voted")
+		}
+		return
+	}
+	obs := &observed{deposit: d, proposalHash: hash}
+	w.deposits[keyOf(d)] = obs
+	w.byProposal[hash] = obs
+	delete(w.unverified, hash)
+	if _, _, err := w.state.ProposeDeposit(d, destChain); err != nil && !errors.Is(err, proposal.ErrDepositProcessed) {
+		w.Logger.Warn("Failed to propose deposit", "txHash", d.TxHash, "err", err)
+	}
+}
+
+// HandleProposal verifies p, a proposal received from validators, against observed deposits.
+func (w *Watchtower) HandleProposal(p *proposal.Proposal) {
+	if p.Type != proposal.ProposalDeposit {
+		return
+	}
+	hash := p.Hash()
+	d, err := p.Deposit()
+	defer w.raise()
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if err != nil {
+		w.alert(AlertDepositMismatch, hash, nil, err.Error())
+		return
+	}
+	obs, ok := w.deposits[keyOf(d)]
+	switch {
+	case !ok:
+		w.track(hash)
+	case obs.removed:
+		w.alert(AlertReorganisedDeposit, hash, d, "proposal of a reorganised deposit")
+	case obs.proposalHash != hash:
+		w.alert(AlertDepositMismatch, hash, d, fmt.Sprintf("proposal differs from %v releasing the observed deposit",
+			obs.proposalHash.Hex()))
+	}
+}
+
+// HandleVote adds v to the state, a vote for a proposal without observed deposit is verified once the grace
+// period is over.
+func (w *Watchtower) HandleVote(v *proposal.Vote) error {
+	if _, err := w.state.AddVote(v); err != nil && !errors.Is(err, proposal.ErrDuplicateVote) {
+		return err
+	}
+	defer w.raise()
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	obs, ok := w.byProposal[v.ProposalHash]
+	switch {
+	case !ok:
+		w.track(v.ProposalHash)
+	case obs.removed:
+		w.alert(AlertReorganisedDeposit, v.ProposalHash, obs.deposit, "proposal of a reorganised deposit is voted")
+	}
+	return nil
+}
+
+// Update sets the current height and raises alerts for voted proposals whose deposit isn't observed within the grace
+// period.
+func (w *Watchtower) Update(height uint64) error {
+	w.mtx.Lock()
+	w.height = height
+	for hash, since := range w.unverified {
+		if height >= since+w.config.GracePeriod && len(w.state.Votes(hash)) > 0 {
+			w.alert(AlertUnknownDeposit, hash, nil, fmt.Sprintf("no deposit observed since height %v", since))
+		}
+	}
+	w.mtx.Unlock()
+	w.raise()
+	return w.state.Update(height)
+}
+
+func (w *Watchtower) track(hash common.Hash) {
+	if _, ok := w.unverified[hash]; !ok {
+		w.unverified[hash] = w.height
+	}
+}
+
+// alert queues an alert of kind for the proposal with the given hash, once.
+func (w *Watchtower) alert(kind AlertKind, hash common.Hash, d *dualnode.Deposit, message string) {
+	key := alertKey{kind: kind, hash: hash}
+	if _, ok := w.alerted[key]; ok {
+		return
+	}
+	w.alerted[key] = struct{}{}
+	a := &Alert{Kind: kind, ProposalHash: hash.Hex(), Height: w.height, Message: message}
+	if d != nil {
+		a.Chain, a.TxHash, a.LogIndex = d.Chain, d.TxHash, d.LogIndex
+	}
+	for _, v := range w.state.Votes(hash) {
+		a.Validators = append(a.Validators, v.ValidatorAddress.Hex())
+	}
+	w.queued = append(w.queued, a)
+}
+
+// raise sends queued alerts to alerters, without holding the lock since they may be slow (eg: webhooks).
+func (w *Watchtower) raise() {
+	w.mtx.Lock()
+	alerts, alerters := w.queued, w.alerters
+	w.queued = nil
+	w.mtx.Unlock()
+	for _, a := range alerts {
+		for _, alerter := range alerters {
+			if err := alerter.Alert(a); err != nil {
+				w.Logger.Warn("Failed to raise alert", "alert", a, "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package watchtower
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/dualnode"
+	"github.com/kardiachain/go-kardia/dualnode/proposal"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+type recorder struct {
+	mtx    sync.Mutex
+	alerts []*Alert
+}
+
+func (r *recorder) Alert(a *Alert) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.alerts = append(r.alerts, a)
+	return nil
+}
+
+func (r *recorder) kinds() []AlertKind {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	kinds := make([]AlertKind, len(r.alerts))
+	for i, a := range r.alerts {
+		kinds[i] = a.Kind
+	}
+	return kinds
+}
+
+func newTestWatchtower(t *testing.T, config *Config) (*Watchtower, []*ecdsa.PrivateKey, *recorder) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	vals := make([]*types.Validator, len(keys))
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		keys[i] = key
+		vals[i] = types.NewValidator(crypto.PubkeyToAddress(key.PublicKey), 10)
+	}
+	pool, err := proposal.NewPool(memorydb.New(), 0)
+	require.NoError(t, err)
+	state, err := proposal.NewState(pool, common.Address{}, types.NewValidatorSet(vals), 0)
+	require.NoError(t, err)
+	w := New(config, state, nil, func(d *dualnode.Deposit) (string, error) { return "KAI", nil })
+	r := &recorder{}
+	w.AddAlerter(r)
+	return w, keys, r
+}
+
+func testDeposit(txHash string, amount int64) *dualnode.Deposit {
+	return &dualnode.Deposit{Chain: "ETH", TxHash: txHash, Token: "0x0A", To: "0x0C", Amount: big.NewInt(amount)}
+}
+
+func vote(t *testing.T, p *proposal.Proposal, key *ecdsa.PrivateKey) *proposal.Vote {
+	v, err := proposal.SignVote(p.Hash(), key)
+	require.NoError(t, err)
+	return v
+}
+
+func depositProposal(t *testing.T, d *dualnode.Deposit) *proposal.Proposal {
+	p, err := proposal.NewDepositProposal(d, "KAI")
+	require.NoError(t, err)
+	return p
+}
+
+func TestWatchtower(t *testing.T) {
+	w, keys, r := newTestWatchtower(t, &Config{GracePeriod: 5})
+
+	// proposals of observed deposits are not alerted, even if votes are received first
+	honest := testDeposit("0x01", 100)
+	require.NoError(t, w.HandleVote(vote(t, depositProposal(t, honest), keys[0])))
+	w.HandleDeposit(honest)
+	w.HandleProposal(depositProposal(t, honest))
+	require.NoError(t, w.HandleVote(vote(t, depositProposal(t, honest), keys[1])))
+	require.NoError(t, w.Update(10))
+	require.Empty(t, r.kinds())
+
+	// a proposal of an observed deposit with another amount
+	forged := depositProposal(t, testDeposit("0x01", 1000))
+	w.HandleProposal(forged)
+	w.HandleProposal(forged)
+	require.Equal(t, []AlertKind{AlertDepositMismatch}, r.kinds())
+
+	// votes for a proposal whose deposit isn't observed within the grace period
+	unknown := depositProposal(t, testDeposit("0x02", 100))
+	require.NoError(t, w.HandleVote(vote(t, unknown, keys[2])))
+	require.NoError(t, w.Update(14))
+	require.Len(t, r.kinds(), 1)
+	require.NoError(t, w.Update(15))
+	require.NoError(t, w.Update(16))
+	require.Equal(t, []AlertKind{AlertDepositMismatch, AlertUnknownDeposit}, r.kinds())
+	require.Equal(t, []string{crypto.PubkeyToAddress(keys[2].PublicKey).Hex()}, r.alerts[1].Validators)
+
+	// votes for a proposal of a deposit reorganised out of its chain
+	removed := *honest
+	removed.Removed = true
+	w.HandleDeposit(&removed)
+	require.Equal(t, AlertReorganisedDeposit, r.alerts[2].Kind)
+	require.Len(t, r.alerts[2].Validators, 2)
+	require.Equal(t, "0x01", r.alerts[2].TxHash)
+}
+
+func TestWatchtower_webhook(t *testing.T) {
+	received := make(chan *Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var a Alert
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&a))
+		received <- &a
+	}))
+	defer server.Close()
+
+	w, _, _ := newTestWatchtower(t, &Config{WebhookURL: server.URL})
+	w.HandleProposal(&proposal.Proposal{Type: proposal.ProposalDeposit, SourceChain: "ETH", Args: [][]byte{{0x01}}})
+	a := <-received
+	require.Equal(t, AlertDepositMismatch, a.Kind)
+}
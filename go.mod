@@ -8,9 +8,12 @@ require (
 	github.com/aristanetworks/goarista v0.0.0-20190712234253-ed1100a1c015
 	github.com/btcsuite/btcd v0.21.0-beta
 	github.com/cespare/cp v1.1.1 // indirect
+	github.com/cockroachdb/pebble v0.0.0-20210331181633-27fc006b8bfb
 	github.com/davecgh/go-spew v1.1.1
 	github.com/deckarep/golang-set v1.7.1
+	github.com/dgraph-io/badger/v2 v2.2007.2
 	github.com/docker/docker v17.12.0-ce-rc1.0.20200531234253-77e06fda0c94+incompatible // indirect
+	github.com/dop251/goja v0.0.0-20230605162241-28ee0ee714f3
 	github.com/ebuchman/fail-test v0.0.0-20170303061230-95f809107225
 	github.com/ethereum/go-ethereum v1.9.15
 	github.com/fjl/memsize v0.0.0-20190710130421-bcb5799ab5e5 // indirect
@@ -44,14 +47,12 @@ require (
 	github.com/stretchr/testify v1.7.0
 	github.com/syndtr/goleveldb v1.0.1-0.20200815110645-5c35d600f0ca
 	github.com/tyler-smith/go-bip39 v1.0.2 // indirect
-	golang.org/x/crypto v0.0.0-20201117144127-c1f2f97bffc9
-	golang.org/x/net v0.0.0-20210405180319-a5a99cb37ef4
-	golang.org/x/sys v0.0.0-20210510120138-977fb7262007
-	golang.org/x/tools v0.0.0-20210106214847-113979e3529a
+	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519
+	golang.org/x/net v0.0.0-20220722155237-a158d28d115b
+	golang.org/x/sys v0.0.0-20220722155257-8c9f86f7a55f
+	golang.org/x/tools v0.1.12
 	google.golang.org/genproto v0.0.0-20201111145450-ac7456db90a6
-	gopkg.in/check.v1 v1.0.0-20200902074654-038fdea0a05b
-	gopkg.in/olebedev/go-duktape.v3 v3.0.0-20200603215123-a4a8cb9d2cbc
+	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c
 	gopkg.in/urfave/cli.v1 v1.20.0
-	gopkg.in/yaml.v2 v2.3.0
-
+	gopkg.in/yaml.v2 v2.4.0
 )
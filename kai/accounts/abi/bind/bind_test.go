@@ -0,0 +1,158 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bind_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kardiachain/go-kardia/kai/accounts/abi/bind"
+)
+
+var bindTests = []struct {
+	name  string
+	abi   string
+	bin   string
+	decls []string // Declarations expected in the binding
+}{
+	{
+		name: "Calculator",
+		abi: `[
+			{"constant":true,"inputs":[],"name":"getV1","outputs":[{"name":"v1","type":"uint8"}],"stateMutability":"pure","type":"function"},
+			{"constant":true,"inputs":[{"name":"v1","type":"uint8"},{"name":"v2","type":"uint8"}],"name":"Calculate","outputs":[{"name":"data","type":"uint8"}],"stateMutability":"pure","type":"function"}
+		]`,
+		decls: []string{
+			"CalculatorABI", "Calculator", "NewCalculator", "NewCalculatorCaller",
+			"CalculatorCaller.GetV1", "CalculatorCaller.Calculate", "CalculatorSession.Calculate", "CalculatorCallerSession.GetV1",
+		},
+	},
+	{
+		name: "Token",
+		abi: `[
+			{"inputs":[{"name":"supply","type":"uint256"}],"stateMutability":"nonpayable","type":"constructor"},
+			{"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+			{"inputs":[],"name":"info","outputs":[{"name":"name","type":"string"},{"name":"decimals","type":"uint8"}],"stateMutability":"view","type":"function"},
+			{"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},
+			{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}
+		]`,
+		bin: "0x6080604052",
+		decls: []string{
+			"TokenBin", "DeployToken", "TokenCaller.BalanceOf", "TokenCaller.Info",
+			"TokenTransactor.Transfer", "TokenSession.Transfer", "TokenTransactorSession.Transfer",
+			"TokenTransfer", "TokenTransferIterator", "TokenTransferIterator.Next",
+			"TokenFilterer.FilterTransfer", "TokenFilterer.WatchTransfer", "TokenFilterer.ParseTransfer",
+		},
+	},
+	{
+		name: "Registry",
+		abi: `[
+			{"inputs":[{"components":[{"name":"owner","type":"address"},{"name":"amount","type":"uint256"}],"name":"entry","type":"tuple"}],"name":"register","outputs":[],"stateMutability":"nonpayable","type":"function"},
+			{"inputs":[{"name":"id","type":"uint256"}],"name":"entries","outputs":[{"components":[{"name":"owner","type":"address"},{"name":"amount","type":"uint256"}],"name":"","type":"tuple[]"}],"stateMutability":"view","type":"function"},
+			{"stateMutability":"payable","type":"receive"}
+		]`,
+		decls: []string{
+			"RegistryTransactor.Register", "RegistryCaller.Entries", "RegistryTransactor.Receive",
+		},
+	},
+}
+
+func TestBindGo(t *testing.T) {
+	for _, tt := range bindTests {
+		code, err := bind.Bind([]string{tt.name}, []string{tt.abi}, []string{tt.bin}, nil, "bindtest", bind.LangGo, nil, nil)
+		if err != nil {
+			t.Fatalf("%s: failed to generate binding: %v", tt.name, err)
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), tt.name+".go", code, 0)
+		if err != nil {
+			t.Fatalf("%s: generated invalid Go code: %v\n%s", tt.name, err, code)
+		}
+		for _, spec := range file.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			if strings.HasPrefix(path, "github.com/kardiachain/go-kardia/") && !knownPackages[path] {
+				t.Errorf("%s: binding imports unknown package %s", tt.name, path)
+			}
+		}
+		decls := declarations(file)
+		for _, want := range tt.decls {
+			if !decls[want] {
+				t.Errorf("%s: binding does not declare %s", tt.name, want)
+			}
+		}
+	}
+}
+
+func TestBindDuplicateIdentifier(t *testing.T) {
+	abi := `[
+		{"inputs":[],"name":"_get","outputs":[],"stateMutability":"nonpayable","type":"function"},
+		{"inputs":[],"name":"__get","outputs":[],"stateMutability":"nonpayable","type":"function"}
+	]`
+	if _, err := bind.Bind([]string{"Dup"}, []string{abi}, []string{""}, nil, "bindtest", bind.LangGo, nil, nil); err == nil {
+		t.Fatal("no error for duplicated identifiers")
+	}
+	aliases := map[string]string{"__get": "getOther"}
+	if _, err := bind.Bind([]string{"Dup"}, []string{abi}, []string{""}, nil, "bindtest", bind.LangGo, nil, aliases); err != nil {
+		t.Fatalf("failed to generate binding with aliases: %v", err)
+	}
+}
+
+// knownPackages are the packages of this module generated bindings may import.
+var knownPackages = map[string]bool{
+	"github.com/kardiachain/go-kardia":                       true,
+	"github.com/kardiachain/go-kardia/kai/accounts/abi":      true,
+	"github.com/kardiachain/go-kardia/kai/accounts/abi/bind": true,
+	"github.com/kardiachain/go-kardia/lib/common":            true,
+	"github.com/kardiachain/go-kardia/lib/event":             true,
+	"github.com/kardiachain/go-kardia/types":                 true,
+}
+
+// declarations returns the names of the types, values and functions declared
+// by file, with methods named as Receiver.Method.
+func declarations(file *ast.File) map[string]bool {
+	decls := make(map[string]bool)
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			name := decl.Name.Name
+			if decl.Recv != nil {
+				recv := decl.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				name = recv.(*ast.Ident).Name + "." + name
+			}
+			decls[name] = true
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					decls[spec.Name.Name] = true
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						decls[name.Name] = true
+					}
+				}
+			}
+		}
+	}
+	return decls
+}
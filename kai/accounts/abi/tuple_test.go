@@ -0,0 +1,280 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package abi
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+)
+
+// roundTripTypes are argument lists, as in an ABI definition, whose values
+// must survive packing and unpacking.
+var roundTripTypes = []string{
+	// Static and dynamic tuples
+	`[{"type":"tuple","components":[{"name":"a","type":"uint256"},{"name":"b","type":"bool"}]}]`,
+	`[{"type":"tuple","components":[{"name":"a","type":"string"},{"name":"b","type":"int64"},{"name":"c","type":"bytes"}]}]`,
+	`[{"type":"uint8"},{"type":"tuple","components":[{"name":"a","type":"address"},{"name":"b","type":"bytes32"}]},{"type":"string"}]`,
+	// Nested tuples
+	`[{"type":"tuple","components":[{"name":"a","type":"uint256"},{"name":"inner","type":"tuple","components":[{"name":"x","type":"int16"},{"name":"y","type":"uint256[2]"}]}]}]`,
+	`[{"type":"tuple","components":[{"name":"inner","type":"tuple","components":[{"name":"s","type":"string"},{"name":"deep","type":"tuple","components":[{"name":"b","type":"bytes"},{"name":"n","type":"uint32"}]}]},{"name":"z","type":"bool"}]}]`,
+	// Arrays of tuples
+	`[{"type":"tuple[]","components":[{"name":"a","type":"uint256"},{"name":"b","type":"address"}]}]`,
+	`[{"type":"tuple[2]","components":[{"name":"a","type":"uint256"},{"name":"b","type":"bool"}]},{"type":"uint256"}]`,
+	`[{"type":"tuple[3]","components":[{"name":"a","type":"string"},{"name":"b","type":"uint64"}]}]`,
+	`[{"type":"tuple[][2]","components":[{"name":"a","type":"bytes"}]}]`,
+	`[{"type":"tuple[2][]","components":[{"name":"a","type":"int256"},{"name":"b","type":"uint8[3]"}]}]`,
+	`[{"type":"tuple","components":[{"name":"list","type":"tuple[]","components":[{"name":"id","type":"uint256"},{"name":"tags","type":"string[]"}]},{"name":"owner","type":"address"}]}]`,
+	// Multi-dimensional arrays
+	`[{"type":"uint256[][]"}]`,
+	`[{"type":"string[][]"}]`,
+	`[{"type":"bytes[2][]"}]`,
+	`[{"type":"uint8[][3]"}]`,
+	`[{"type":"int32[2][3]"},{"type":"bool"}]`,
+	`[{"type":"address[][2][]"}]`,
+	`[{"type":"uint16[3][2]"},{"type":"string[2][2]"},{"type":"bytes32[][]"}]`,
+}
+
+func TestTupleRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, def := range roundTripTypes {
+		var args Arguments
+		if err := json.Unmarshal([]byte(def), &args); err != nil {
+			t.Fatalf("%s: %v", def, err)
+		}
+		for i := 0; i < 50; i++ {
+			values := make([]interface{}, len(args))
+			for j, arg := range args {
+				values[j] = randomValue(r, arg.Type).Interface()
+			}
+			packed, err := args.Pack(values...)
+			if err != nil {
+				t.Fatalf("%s: failed to pack %v: %v", def, values, err)
+			}
+			unpacked, err := args.Unpack(packed)
+			if err != nil {
+				t.Fatalf("%s: failed to unpack %v: %v", def, values, err)
+			}
+			if !equalValues(values, unpacked) {
+				t.Fatalf("%s: round trip mismatch\nhave %v\nwant %v", def, unpacked, values)
+			}
+			repacked, err := args.Pack(unpacked...)
+			if err != nil {
+				t.Fatalf("%s: failed to repack %v: %v", def, unpacked, err)
+			}
+			if hex.EncodeToString(repacked) != hex.EncodeToString(packed) {
+				t.Fatalf("%s: repacked encoding differs", def)
+			}
+		}
+	}
+}
+
+// TestTupleEncoding checks encodings against the ones of solidity.
+func TestTupleEncoding(t *testing.T) {
+	type pair struct {
+		A *big.Int
+		B bool
+	}
+	type named struct {
+		S string
+		N uint32
+	}
+	tests := []struct {
+		def    string
+		values []interface{}
+		want   string
+	}{
+		{
+			// Static tuples are encoded in place, also in static arrays.
+			`[{"type":"tuple[2]","components":[{"name":"a","type":"uint256"},{"name":"b","type":"bool"}]},{"type":"uint256"}]`,
+			[]interface{}{[2]pair{{big.NewInt(1), true}, {big.NewInt(2), false}}, big.NewInt(3)},
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000002" +
+				"0000000000000000000000000000000000000000000000000000000000000000" +
+				"0000000000000000000000000000000000000000000000000000000000000003",
+		},
+		{
+			// Dynamic tuples in a slice are referenced by offsets from the
+			// start of the slice contents.
+			`[{"type":"tuple[]","components":[{"name":"s","type":"string"},{"name":"n","type":"uint32"}]}]`,
+			[]interface{}{[]named{{"a", 1}, {"b", 2}}},
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000002" +
+				"0000000000000000000000000000000000000000000000000000000000000040" +
+				"00000000000000000000000000000000000000000000000000000000000000c0" +
+				"0000000000000000000000000000000000000000000000000000000000000040" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"6100000000000000000000000000000000000000000000000000000000000000" +
+				"0000000000000000000000000000000000000000000000000000000000000040" +
+				"0000000000000000000000000000000000000000000000000000000000000002" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"6200000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			`[{"type":"uint8[][]"}]`,
+			[]interface{}{[][]uint8{{1}, {}}},
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000002" +
+				"0000000000000000000000000000000000000000000000000000000000000040" +
+				"0000000000000000000000000000000000000000000000000000000000000080" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000000",
+		},
+	}
+	for _, test := range tests {
+		var args Arguments
+		if err := json.Unmarshal([]byte(test.def), &args); err != nil {
+			t.Fatalf("%s: %v", test.def, err)
+		}
+		packed, err := args.Pack(test.values...)
+		if err != nil {
+			t.Fatalf("%s: failed to pack: %v", test.def, err)
+		}
+		if have := hex.EncodeToString(packed); have != test.want {
+			t.Fatalf("%s: wrong encoding\nhave %s\nwant %s", test.def, have, test.want)
+		}
+		unpacked, err := args.Unpack(packed)
+		if err != nil {
+			t.Fatalf("%s: failed to unpack: %v", test.def, err)
+		}
+		for i, value := range unpacked {
+			// Unpack returns anonymous structs, convert them back.
+			out := reflect.New(reflect.TypeOf(test.values[i]))
+			if err := set(out.Elem(), reflect.ValueOf(value)); err != nil {
+				t.Fatalf("%s: failed to copy %v: %v", test.def, value, err)
+			}
+			if !equalValues([]interface{}{test.values[i]}, []interface{}{out.Elem().Interface()}) {
+				t.Fatalf("%s: have %v, want %v", test.def, out.Elem().Interface(), test.values[i])
+			}
+		}
+	}
+}
+
+// TestTupleMalformedInput checks that corrupted encodings of nested types are
+// rejected without panicking.
+func TestTupleMalformedInput(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for _, def := range roundTripTypes {
+		var args Arguments
+		if err := json.Unmarshal([]byte(def), &args); err != nil {
+			t.Fatalf("%s: %v", def, err)
+		}
+		values := make([]interface{}, len(args))
+		for j, arg := range args {
+			values[j] = randomValue(r, arg.Type).Interface()
+		}
+		packed, err := args.Pack(values...)
+		if err != nil {
+			t.Fatalf("%s: failed to pack: %v", def, err)
+		}
+		for i := 0; i < 200; i++ {
+			corrupted := make([]byte, len(packed))
+			copy(corrupted, packed)
+			switch r.Intn(3) {
+			case 0:
+				corrupted = corrupted[:r.Intn(len(corrupted))]
+			case 1:
+				word := r.Intn(len(corrupted)/32) * 32
+				copy(corrupted[word:word+32], common.LeftPadBytes(big.NewInt(r.Int63()).Bytes(), 32))
+			case 2:
+				corrupted[r.Intn(len(corrupted))] = 0xff
+			}
+			func() {
+				defer func() {
+					if err := recover(); err != nil {
+						t.Fatalf("%s: panic unpacking %x: %v", def, corrupted, err)
+					}
+				}()
+				args.Unpack(corrupted)
+			}()
+		}
+	}
+}
+
+// randomValue returns a random Go value of the ABI type t.
+func randomValue(r *rand.Rand, t Type) reflect.Value {
+	value := reflect.New(t.GetType()).Elem()
+	switch t.T {
+	case IntTy, UintTy:
+		if t.Size > 64 {
+			n := new(big.Int).Rand(r, new(big.Int).Lsh(common.Big1, uint(t.Size-1)))
+			if t.T == IntTy && r.Intn(2) == 0 {
+				n.Neg(n)
+			}
+			value.Set(reflect.ValueOf(n))
+		} else if t.T == IntTy {
+			value.SetInt(r.Int63() >> uint(64-t.Size))
+			if r.Intn(2) == 0 {
+				value.SetInt(-value.Int())
+			}
+		} else {
+			value.SetUint(r.Uint64() >> uint(64-t.Size))
+		}
+	case BoolTy:
+		value.SetBool(r.Intn(2) == 0)
+	case StringTy:
+		value.SetString(strings.Repeat("k", r.Intn(70)))
+	case BytesTy:
+		b := make([]byte, r.Intn(70))
+		r.Read(b)
+		value.SetBytes(b)
+	case AddressTy, FixedBytesTy:
+		for i := 0; i < value.Len(); i++ {
+			value.Index(i).SetUint(uint64(r.Intn(256)))
+		}
+	case SliceTy:
+		value.Set(reflect.MakeSlice(value.Type(), r.Intn(4), 4))
+		fallthrough
+	case ArrayTy:
+		for i := 0; i < value.Len(); i++ {
+			value.Index(i).Set(randomValue(r, *t.Elem))
+		}
+	case TupleTy:
+		for i, elem := range t.TupleElems {
+			value.Field(i).Set(randomValue(r, *elem))
+		}
+	default:
+		panic(fmt.Sprintf("no random values of type %v", t))
+	}
+	return value
+}
+
+// equalValues reports whether the values are equal, comparing big integers
+// by value.
+func equalValues(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if reflect.TypeOf(a[i]) != reflect.TypeOf(b[i]) || fmt.Sprintf("%v", a[i]) != fmt.Sprintf("%v", b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -388,11 +388,9 @@ func isDynamicType(t Type) bool {
 // to store the location reference for actual value storage.
 func getTypeSize(t Type) int {
 	if t.T == ArrayTy && !isDynamicType(*t.Elem) {
-		// Recursively calculate type size if it is a nested array
-		if t.Elem.T == ArrayTy {
-			return t.Size * getTypeSize(*t.Elem)
-		}
-		return t.Size * 32
+		// Static arrays are encoded in place, recursively calculate the size
+		// of their elements, which may be nested arrays or static tuples.
+		return t.Size * getTypeSize(*t.Elem)
 	} else if t.T == TupleTy && !isDynamicType(t) {
 		total := 0
 		for _, elem := range t.TupleElems {
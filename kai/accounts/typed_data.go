@@ -0,0 +1,312 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package accounts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/math"
+)
+
+// domainType is the type of the domain of typed data.
+const domainType = "EIP712Domain"
+
+var (
+	typedArrayRegexp = regexp.MustCompile(`^(.+)\[(\d*)\]$`)
+	typedIntRegexp   = regexp.MustCompile(`^(u?)int(\d*)$`)
+	typedBytesRegexp = regexp.MustCompile(`^bytes(\d+)$`)
+)
+
+// TypedDataField is a named field of a typed data struct.
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedDataTypes are the struct types of typed data, by name.
+type TypedDataTypes map[string][]TypedDataField
+
+// TypedData is structured data signed as defined by EIP-712.
+type TypedData struct {
+	Types       TypedDataTypes         `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      map[string]interface{} `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+// TypedDataAndHash returns the hash to sign for typed data, which is the
+// keccak256 of "\x19\x01" ‖ hashStruct(domain) ‖ hashStruct(message), along
+// with the signed bytes.
+func TypedDataAndHash(data *TypedData) ([]byte, []byte, error) {
+	if _, ok := data.Types[domainType]; !ok {
+		return nil, nil, fmt.Errorf("missing %s type", domainType)
+	}
+	domain, err := data.HashStruct(domainType, data.Domain)
+	if err != nil {
+		return nil, nil, fmt.Errorf("domain: %w", err)
+	}
+	message, err := data.HashStruct(data.PrimaryType, data.Message)
+	if err != nil {
+		return nil, nil, fmt.Errorf("message: %w", err)
+	}
+	raw := append(append([]byte("\x19\x01"), domain...), message...)
+	return crypto.Keccak256(raw), raw, nil
+}
+
+// HashStruct returns the hash of value as a struct of type name.
+func (data *TypedData) HashStruct(name string, value map[string]interface{}) ([]byte, error) {
+	enc, err := data.EncodeData(name, value, 1)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(enc), nil
+}
+
+// TypeHash returns the hash of the encoded type name.
+func (data *TypedData) TypeHash(name string) []byte {
+	return crypto.Keccak256([]byte(data.EncodeType(name)))
+}
+
+// EncodeType returns the encoding of type name, followed by the encodings of
+// the struct types it references sorted by name, such as
+// "Mail(Person from,Person to,string contents)Person(string name,address wallet)".
+func (data *TypedData) EncodeType(name string) string {
+	deps := data.dependencies(name, nil)
+	sort.Strings(deps[1:])
+
+	var buf bytes.Buffer
+	for _, dep := range deps {
+		buf.WriteString(dep)
+		buf.WriteString("(")
+		for i, field := range data.Types[dep] {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString(field.Type + " " + field.Name)
+		}
+		buf.WriteString(")")
+	}
+	return buf.String()
+}
+
+// dependencies returns name followed by the struct types it references,
+// directly or not, which are not already in found.
+func (data *TypedData) dependencies(name string, found []string) []string {
+	name = baseType(name)
+	for _, dep := range found {
+		if dep == name {
+			return found
+		}
+	}
+	if _, ok := data.Types[name]; !ok {
+		return found
+	}
+	found = append(found, name)
+	for _, field := range data.Types[name] {
+		found = data.dependencies(field.Type, found)
+	}
+	return found
+}
+
+// EncodeData returns the type hash of name followed by the encoding of every
+// field of value.
+func (data *TypedData) EncodeData(name string, value map[string]interface{}, depth int) ([]byte, error) {
+	fields, ok := data.Types[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", name)
+	}
+	if len(value) > len(fields) {
+		return nil, fmt.Errorf("%s has %d fields, got %d values", name, len(fields), len(value))
+	}
+	if depth > 64 {
+		return nil, errors.New("typed data nested too deep")
+	}
+	buf := bytes.NewBuffer(data.TypeHash(name))
+	for _, field := range fields {
+		enc, err := data.encodeValue(field.Type, value[field.Name], depth)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", name, field.Name, err)
+		}
+		buf.Write(enc)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeValue returns the 32 bytes encoding of a value of type typ.
+func (data *TypedData) encodeValue(typ string, value interface{}, depth int) ([]byte, error) {
+	if match := typedArrayRegexp.FindStringSubmatch(typ); match != nil {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v is not an array", value)
+		}
+		if match[2] != "" {
+			if size, _ := strconv.Atoi(match[2]); size != len(items) {
+				return nil, fmt.Errorf("array of %d items instead of %d", len(items), size)
+			}
+		}
+		var buf bytes.Buffer
+		for _, item := range items {
+			enc, err := data.encodeValue(match[1], item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(enc)
+		}
+		return crypto.Keccak256(buf.Bytes()), nil
+	}
+	if _, ok := data.Types[typ]; ok {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v is not a %s", value, typ)
+		}
+		enc, err := data.EncodeData(typ, fields, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(enc), nil
+	}
+
+	switch typ {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a string", value)
+		}
+		return crypto.Keccak256([]byte(s)), nil
+	case "bytes":
+		b, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(b), nil
+	case "address":
+		s, ok := value.(string)
+		if !ok || !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("%v is not an address", value)
+		}
+		return common.LeftPadBytes(common.HexToAddress(s).Bytes(), 32), nil
+	case "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a bool", value)
+		}
+		if b {
+			return math.PaddedBigBytes(common.Big1, 32), nil
+		}
+		return make([]byte, 32), nil
+	}
+	if match := typedBytesRegexp.FindStringSubmatch(typ); match != nil {
+		size, _ := strconv.Atoi(match[1])
+		b, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if size < 1 || size > 32 || len(b) != size {
+			return nil, fmt.Errorf("%d bytes instead of %s", len(b), typ)
+		}
+		return common.RightPadBytes(b, 32), nil
+	}
+	if match := typedIntRegexp.FindStringSubmatch(typ); match != nil {
+		bits := 256
+		if match[2] != "" {
+			bits, _ = strconv.Atoi(match[2])
+		}
+		if bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, fmt.Errorf("invalid type %s", typ)
+		}
+		n, err := parseInteger(value)
+		if err != nil {
+			return nil, err
+		}
+		// Signed values range over [-2^(bits-1), 2^(bits-1)), unsigned ones
+		// over [0, 2^bits).
+		min, max := new(big.Int), new(big.Int).Lsh(common.Big1, uint(bits))
+		if match[1] == "" {
+			max.Rsh(max, 1)
+			min.Neg(max)
+		}
+		if n.Cmp(min) < 0 || n.Cmp(max) >= 0 {
+			return nil, fmt.Errorf("%v overflows %s", n, typ)
+		}
+		return math.U256Bytes(n), nil
+	}
+	return nil, fmt.Errorf("unknown type %q", typ)
+}
+
+// baseType returns the type of the items of an array type, or typ.
+func baseType(typ string) string {
+	for {
+		match := typedArrayRegexp.FindStringSubmatch(typ)
+		if match == nil {
+			return typ
+		}
+		typ = match[1]
+	}
+}
+
+func parseBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		if !strings.HasPrefix(v, "0x") {
+			return nil, fmt.Errorf("%q is not hex encoded", v)
+		}
+		return common.FromHex(v), nil
+	}
+	return nil, fmt.Errorf("%v is not bytes", value)
+}
+
+func parseInteger(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return new(big.Int).Set(v), nil
+	case json.Number:
+		return parseInteger(v.String())
+	case float64:
+		// JSON numbers are only exact up to 2^53.
+		if v != float64(int64(v)) || v > 1<<53 || v < -(1<<53) {
+			return nil, fmt.Errorf("%v is not an exact integer", v)
+		}
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case string:
+		negative := strings.HasPrefix(v, "-")
+		n, ok := math.ParseBig256(strings.TrimPrefix(v, "-"))
+		if !ok {
+			return nil, fmt.Errorf("%q is not an integer", v)
+		}
+		if negative {
+			n.Neg(n)
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("%v is not an integer", value)
+}
@@ -0,0 +1,100 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package accounts
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+)
+
+// mailTypedData is the example of EIP-712.
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestTypedDataHash(t *testing.T) {
+	var data TypedData
+	require.NoError(t, json.Unmarshal([]byte(mailTypedData), &data))
+
+	require.Equal(t, "Mail(Person from,Person to,string contents)Person(string name,address wallet)", data.EncodeType("Mail"))
+	require.Equal(t, "0xa0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2", common.ToHex(data.TypeHash("Mail")))
+
+	domain, err := data.HashStruct(domainType, data.Domain)
+	require.NoError(t, err)
+	require.Equal(t, "0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f", common.ToHex(domain))
+
+	message, err := data.HashStruct("Mail", data.Message)
+	require.NoError(t, err)
+	require.Equal(t, "0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e", common.ToHex(message))
+
+	hash, _, err := TypedDataAndHash(&data)
+	require.NoError(t, err)
+	require.Equal(t, "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", common.ToHex(hash))
+}
+
+func TestTypedDataRejectsInvalidValues(t *testing.T) {
+	var data TypedData
+	require.NoError(t, json.Unmarshal([]byte(mailTypedData), &data))
+
+	data.Message["to"] = map[string]interface{}{"name": "Bob", "wallet": "0x1234"}
+	_, _, err := TypedDataAndHash(&data)
+	require.Error(t, err)
+
+	data.Types["Mail"] = append(data.Types["Mail"], TypedDataField{Name: "priority", Type: "uint8"})
+	data.Message["to"] = map[string]interface{}{"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"}
+	data.Message["priority"] = float64(256)
+	_, _, err = TypedDataAndHash(&data)
+	require.Error(t, err)
+
+	data.Message["priority"] = float64(255)
+	_, _, err = TypedDataAndHash(&data)
+	require.NoError(t, err)
+}
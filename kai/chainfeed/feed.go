@@ -0,0 +1,297 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package chainfeed streams committed blocks, receipts, logs and dual events
+// to an external sink such as a Kafka topic or a PostgreSQL schema.
+//
+// Delivery is at least once: the records of a block are written to the sink
+// before the cursor of the feed is advanced past it, so a block may be
+// delivered again after a crash or a sink error, but never skipped. Records
+// carry a key unique within their kind for consumers to deduplicate.
+package chainfeed
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/events"
+	"github.com/kardiachain/go-kardia/lib/event"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+const (
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+
+	// DefaultRetryInterval is the delay before retrying a failed write.
+	DefaultRetryInterval = 5 * time.Second
+)
+
+// Sink types of a Config.
+const (
+	SinkPostgres = "postgres"
+	SinkKafka    = "kafka"
+)
+
+// cursorPrefix + feed name -> height of the last delivered block
+var cursorPrefix = []byte("chainfeed-cursor-")
+
+// IsCursorKey reports whether key is the cursor of a feed.
+func IsCursorKey(key []byte) bool {
+	return bytes.HasPrefix(key, cursorPrefix)
+}
+
+// Sink is an external store records are delivered to.
+type Sink interface {
+	// Write stores the records of a block. It must either store all of them
+	// or return an error, and must tolerate records it already stored.
+	Write(ctx context.Context, records []*Record) error
+	// Close releases the resources of the sink.
+	Close() error
+}
+
+// Chain is the blockchain a feed streams.
+type Chain interface {
+	CurrentBlock() *types.Block
+	GetBlockByHeight(height uint64) *types.Block
+	DB() types.StoreDB
+	SubscribeChainHeadEvent(ch chan<- events.ChainHeadEvent) event.Subscription
+}
+
+// Config is the configuration of a feed.
+type Config struct {
+	// Name identifies the cursor of the feed, so that several feeds of a
+	// node resume independently.
+	Name string
+	// Sink is the type of the sink, SinkPostgres or SinkKafka.
+	Sink string
+
+	// Driver and DSN open the database of a SQL sink, whose tables are
+	// created in Schema. The driver must be registered by the binary.
+	Driver string
+	DSN    string
+	Schema string
+
+	// Brokers and Topic configure a Kafka sink.
+	Brokers []string
+	Topic   string
+
+	// StartHeight is the first block delivered when the feed has no cursor.
+	StartHeight uint64
+	// RetryInterval is the delay before retrying a failed write.
+	RetryInterval time.Duration
+}
+
+// NewSink returns the sink described by config.
+func NewSink(ctx context.Context, config Config) (Sink, error) {
+	switch config.Sink {
+	case SinkPostgres:
+		driver := config.Driver
+		if driver == "" {
+			driver = "postgres"
+		}
+		db, err := sql.Open(driver, config.DSN)
+		if err != nil {
+			return nil, err
+		}
+		sink, err := NewSQLSink(ctx, db, config.Schema)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return sink, nil
+	case SinkKafka:
+		kafkaMu.Lock()
+		factory := kafkaFactory
+		kafkaMu.Unlock()
+		if factory == nil {
+			return nil, errors.New("no kafka producer registered")
+		}
+		producer, err := factory(config.Brokers)
+		if err != nil {
+			return nil, err
+		}
+		return NewKafkaSink(producer, config.Topic)
+	default:
+		return nil, fmt.Errorf("unknown chain feed sink %q", config.Sink)
+	}
+}
+
+// Feed delivers the blocks of a chain to a sink in height order.
+type Feed struct {
+	logger      log.Logger
+	config      Config
+	chain       Chain
+	chainConfig *configs.ChainConfig
+	sink        Sink
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New returns a feed delivering the blocks of chain to sink.
+func New(logger log.Logger, config Config, chain Chain, chainConfig *configs.ChainConfig, sink Sink) (*Feed, error) {
+	if config.Name == "" {
+		return nil, errors.New("chain feed name is empty")
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = DefaultRetryInterval
+	}
+	return &Feed{
+		logger:      logger.New("module", "chainfeed", "feed", config.Name),
+		config:      config,
+		chain:       chain,
+		chainConfig: chainConfig,
+		sink:        sink,
+		quit:        make(chan struct{}),
+	}, nil
+}
+
+// NewFromConfig returns a feed delivering the blocks of chain to the sink
+// described by config.
+func NewFromConfig(logger log.Logger, config Config, chain Chain, chainConfig *configs.ChainConfig) (*Feed, error) {
+	sink, err := NewSink(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+	feed, err := New(logger, config, chain, chainConfig, sink)
+	if err != nil {
+		sink.Close()
+		return nil, err
+	}
+	return feed, nil
+}
+
+// Start catches up with the chain head and keeps following it.
+func (f *Feed) Start() {
+	f.wg.Add(1)
+	go f.loop()
+}
+
+// Stop waits for the block being delivered, then closes the sink.
+func (f *Feed) Stop() error {
+	close(f.quit)
+	f.wg.Wait()
+	return f.sink.Close()
+}
+
+// Cursor returns the height of the last delivered block, or false if no
+// block was delivered yet.
+func (f *Feed) Cursor() (uint64, bool) {
+	data, _ := f.chain.DB().DB().Get(f.cursorKey())
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+func (f *Feed) setCursor(height uint64) error {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, height)
+	return f.chain.DB().DB().Put(f.cursorKey(), enc)
+}
+
+func (f *Feed) cursorKey() []byte {
+	return append(append([]byte{}, cursorPrefix...), f.config.Name...)
+}
+
+func (f *Feed) loop() {
+	defer f.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-f.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	headCh := make(chan events.ChainHeadEvent, chainHeadChanSize)
+	sub := f.chain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	for {
+		var retry <-chan time.Time
+		if err := f.catchUp(ctx); err != nil && ctx.Err() == nil {
+			f.logger.Error("Failed to deliver blocks", "err", err, "retry", f.config.RetryInterval)
+			retry = time.After(f.config.RetryInterval)
+		}
+		select {
+		case <-headCh:
+		case <-retry:
+		case <-sub.Err():
+			return
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+// catchUp delivers the blocks from the cursor to the chain head.
+func (f *Feed) catchUp(ctx context.Context) error {
+	next := f.config.StartHeight
+	if cursor, ok := f.Cursor(); ok {
+		next = cursor + 1
+	}
+	head := f.chain.CurrentBlock().Height()
+	for height := next; height <= head; height++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		block := f.chain.GetBlockByHeight(height)
+		if block == nil {
+			return fmt.Errorf("block %d not found", height)
+		}
+		records, err := f.records(block)
+		if err != nil {
+			return err
+		}
+		if err := f.sink.Write(ctx, records); err != nil {
+			return fmt.Errorf("write block %d: %w", height, err)
+		}
+		if err := f.setCursor(height); err != nil {
+			return err
+		}
+		f.logger.Trace("Delivered block", "height", height, "records", len(records))
+	}
+	return nil
+}
+
+func (f *Feed) records(block *types.Block) ([]*Record, error) {
+	var receipts types.Receipts
+	if block.Height() > 0 {
+		info := f.chain.DB().ReadBlockInfo(block.Hash(), block.Height(), f.chainConfig)
+		if info == nil && len(block.Transactions()) > 0 {
+			return nil, fmt.Errorf("receipts of block %d not found", block.Height())
+		}
+		if info != nil {
+			receipts = info.Receipts
+		}
+	}
+	return BlockRecords(block, receipts)
+}
@@ -0,0 +1,194 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chainfeed
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/events"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/event"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+type testStore struct {
+	types.StoreDB
+	db    kaidb.Database
+	mu    sync.Mutex
+	infos map[uint64]*types.BlockInfo
+}
+
+func (s *testStore) DB() kaidb.Database { return s.db }
+
+func (s *testStore) ReadBlockInfo(hash common.Hash, height uint64, config *configs.ChainConfig) *types.BlockInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.infos[height]
+}
+
+type testChain struct {
+	mu       sync.Mutex
+	blocks   []*types.Block
+	store    *testStore
+	headFeed event.Feed
+}
+
+func newTestChain() *testChain {
+	chain := &testChain{store: &testStore{db: memorydb.New(), infos: make(map[uint64]*types.BlockInfo)}}
+	chain.blocks = []*types.Block{types.NewBlock(&types.Header{Height: 0}, nil, nil, nil)}
+	return chain
+}
+
+// addBlock appends a block with one transaction emitting one log.
+func (c *testChain) addBlock() *types.Block {
+	c.mu.Lock()
+	height := uint64(len(c.blocks))
+	tx := types.NewTransaction(height, common.HexToAddress("0x1"), big.NewInt(1), 21000, big.NewInt(1), nil)
+	block := types.NewBlock(&types.Header{Height: height}, []*types.Transaction{tx}, nil, nil)
+	c.blocks = append(c.blocks, block)
+	c.store.mu.Lock()
+	c.store.infos[height] = &types.BlockInfo{Receipts: types.Receipts{{
+		TxHash: tx.Hash(),
+		Status: types.ReceiptStatusSuccessful,
+		Logs:   []*types.Log{{Address: common.HexToAddress("0x2"), Data: []byte{1}}},
+	}}}
+	c.store.mu.Unlock()
+	c.mu.Unlock()
+	c.headFeed.Send(events.ChainHeadEvent{Block: block})
+	return block
+}
+
+func (c *testChain) CurrentBlock() *types.Block {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.blocks[len(c.blocks)-1]
+}
+
+func (c *testChain) GetBlockByHeight(height uint64) *types.Block {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if height >= uint64(len(c.blocks)) {
+		return nil
+	}
+	return c.blocks[height]
+}
+
+func (c *testChain) DB() types.StoreDB { return c.store }
+
+func (c *testChain) SubscribeChainHeadEvent(ch chan<- events.ChainHeadEvent) event.Subscription {
+	return c.headFeed.Subscribe(ch)
+}
+
+type testSink struct {
+	mu       sync.Mutex
+	failures int
+	records  []*Record
+}
+
+func (s *testSink) Write(ctx context.Context, records []*Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *testSink) Close() error { return nil }
+
+func (s *testSink) heights(kind Kind) []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var heights []uint64
+	for _, r := range s.records {
+		if r.Kind == kind {
+			heights = append(heights, r.Height)
+		}
+	}
+	return heights
+}
+
+func waitCursor(t *testing.T, feed *Feed, height uint64) {
+	require.Eventually(t, func() bool {
+		cursor, ok := feed.Cursor()
+		return ok && cursor == height
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestFeedDeliversAndResumes(t *testing.T) {
+	chain := newTestChain()
+	chain.addBlock()
+	chain.addBlock()
+
+	config := Config{Name: "test", StartHeight: 1, RetryInterval: 10 * time.Millisecond}
+	sink := &testSink{failures: 2}
+	feed, err := New(log.New(), config, chain, configs.TestnetChainConfig, sink)
+	require.NoError(t, err)
+	feed.Start()
+
+	// Blocks are delivered once the sink recovers, then followed as they come.
+	waitCursor(t, feed, 2)
+	chain.addBlock()
+	waitCursor(t, feed, 3)
+	require.NoError(t, feed.Stop())
+
+	require.Equal(t, []uint64{1, 2, 3}, sink.heights(KindBlock))
+	require.Equal(t, []uint64{1, 2, 3}, sink.heights(KindReceipt))
+	require.Equal(t, []uint64{1, 2, 3}, sink.heights(KindLog))
+
+	// A new feed with the same name resumes after the cursor.
+	chain.addBlock()
+	sink = &testSink{}
+	feed, err = New(log.New(), config, chain, configs.TestnetChainConfig, sink)
+	require.NoError(t, err)
+	feed.Start()
+	waitCursor(t, feed, 4)
+	require.NoError(t, feed.Stop())
+	require.Equal(t, []uint64{4}, sink.heights(KindBlock))
+}
+
+func TestBlockRecords(t *testing.T) {
+	chain := newTestChain()
+	block := chain.addBlock()
+	receipts := chain.store.ReadBlockInfo(block.Hash(), block.Height(), nil).Receipts
+
+	records, err := BlockRecords(block, receipts)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, KindBlock, records[0].Kind)
+	require.Equal(t, block.Hash().Hex(), records[0].Key)
+	require.Equal(t, KindReceipt, records[1].Kind)
+	require.Equal(t, receipts[0].TxHash.Hex(), records[1].Key)
+	require.Equal(t, KindLog, records[2].Kind)
+	require.Equal(t, receipts[0].TxHash.Hex()+":0", records[2].Key)
+	require.Contains(t, string(records[2].Data), block.Hash().Hex()[2:])
+}
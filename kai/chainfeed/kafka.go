@@ -0,0 +1,96 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chainfeed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// KafkaMessage is a message produced to a Kafka topic.
+type KafkaMessage struct {
+	Topic string
+	Key   []byte
+	Value []byte
+}
+
+// KafkaProducer produces messages to Kafka. It wraps a client library, for
+// instance a sarama.SyncProducer with acks from all in-sync replicas.
+type KafkaProducer interface {
+	// SendMessages produces msgs and returns once all of them are
+	// acknowledged, or returns an error.
+	SendMessages(msgs []*KafkaMessage) error
+	Close() error
+}
+
+// KafkaProducerFactory connects a producer to brokers.
+type KafkaProducerFactory func(brokers []string) (KafkaProducer, error)
+
+var (
+	kafkaMu      sync.Mutex
+	kafkaFactory KafkaProducerFactory
+)
+
+// RegisterKafkaProducer makes a Kafka client available to kafka sinks built
+// from a Config. It is typically called from the init function of the
+// package wrapping the client.
+func RegisterKafkaProducer(factory KafkaProducerFactory) {
+	kafkaMu.Lock()
+	defer kafkaMu.Unlock()
+	kafkaFactory = factory
+}
+
+// KafkaSink writes records to a Kafka topic, keyed by record key so that the
+// records of a key keep their order within a partition. Values are the JSON
+// encoded records.
+type KafkaSink struct {
+	producer KafkaProducer
+	topic    string
+}
+
+// NewKafkaSink returns a sink producing to topic.
+func NewKafkaSink(producer KafkaProducer, topic string) (*KafkaSink, error) {
+	if topic == "" {
+		return nil, errors.New("kafka topic is empty")
+	}
+	return &KafkaSink{producer: producer, topic: topic}, nil
+}
+
+// Write implements Sink.
+func (s *KafkaSink) Write(ctx context.Context, records []*Record) error {
+	msgs := make([]*KafkaMessage, 0, len(records))
+	for _, r := range records {
+		value, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, &KafkaMessage{Topic: s.topic, Key: []byte(string(r.Kind) + "/" + r.Key), Value: value})
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.producer.SendMessages(msgs)
+}
+
+// Close implements Sink.
+func (s *KafkaSink) Close() error {
+	return s.producer.Close()
+}
@@ -0,0 +1,123 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chainfeed
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// Kind is the kind of a record.
+type Kind string
+
+const (
+	KindBlock     Kind = "block"
+	KindReceipt   Kind = "receipt"
+	KindLog       Kind = "log"
+	KindDualEvent Kind = "dual_event"
+)
+
+// Kinds lists all record kinds.
+var Kinds = []Kind{KindBlock, KindReceipt, KindLog, KindDualEvent}
+
+// Record is a unit of data delivered to a sink.
+type Record struct {
+	Kind   Kind   `json:"kind"`
+	Height uint64 `json:"height"`
+	// Key is unique among the records of a kind: the hash of blocks,
+	// receipts and dual events, and the tx hash and log index of logs.
+	Key  string          `json:"key"`
+	Data json.RawMessage `json:"data"`
+}
+
+// blockData is the data of a block record.
+type blockData struct {
+	Hash       common.Hash    `json:"hash"`
+	Header     *types.Header  `json:"header"`
+	TxHashes   []common.Hash  `json:"transactions"`
+	DualEvents []common.Hash  `json:"dualEvents,omitempty"`
+	Evidence   []common.Hash  `json:"evidence,omitempty"`
+	Proposer   common.Address `json:"proposer"`
+}
+
+// BlockRecords returns the records of block, with the receipts of its
+// transactions, in delivery order: the block, then the receipt and logs of
+// each transaction, then its dual events.
+func BlockRecords(block *types.Block, receipts types.Receipts) ([]*Record, error) {
+	var (
+		height  = block.Height()
+		hash    = block.Hash()
+		records []*Record
+	)
+	add := func(kind Kind, key string, v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("encode %s %s: %w", kind, key, err)
+		}
+		records = append(records, &Record{Kind: kind, Height: height, Key: key, Data: data})
+		return nil
+	}
+
+	b := blockData{
+		Hash:     hash,
+		Header:   block.Header(),
+		Proposer: block.ProposerAddress(),
+	}
+	for _, tx := range block.Transactions() {
+		b.TxHashes = append(b.TxHashes, tx.Hash())
+	}
+	for _, ev := range block.DualEvents() {
+		b.DualEvents = append(b.DualEvents, ev.Hash())
+	}
+	if evidence := block.Evidence(); evidence != nil {
+		for _, ev := range evidence.Evidence {
+			b.Evidence = append(b.Evidence, ev.Hash())
+		}
+	}
+	if err := add(KindBlock, hash.Hex(), &b); err != nil {
+		return nil, err
+	}
+
+	for i, receipt := range receipts {
+		if err := add(KindReceipt, receipt.TxHash.Hex(), receipt); err != nil {
+			return nil, err
+		}
+		for _, l := range receipt.Logs {
+			// Receipts are stored without the derived fields of their logs.
+			entry := *l
+			entry.BlockHeight = height
+			entry.BlockHash = hash
+			entry.TxHash = receipt.TxHash
+			entry.TxIndex = uint(i)
+			if err := add(KindLog, fmt.Sprintf("%s:%d", receipt.TxHash.Hex(), l.Index), &entry); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, ev := range block.DualEvents() {
+		if err := add(KindDualEvent, ev.Hash().Hex(), ev); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,99 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chainfeed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+)
+
+// DefaultSchema is the schema of a SQL sink when none is configured.
+const DefaultSchema = "kardia"
+
+var schemaPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// SQLSink writes records to a PostgreSQL schema with one table per kind:
+//
+//	<schema>.block, <schema>.receipt, <schema>.log, <schema>.dual_event
+//	  (key TEXT PRIMARY KEY, height BIGINT, data JSONB)
+//
+// The records of a block are inserted in a single transaction, and records
+// already stored are ignored.
+type SQLSink struct {
+	db     *sql.DB
+	schema string
+}
+
+// NewSQLSink returns a sink writing to db, creating its tables if needed.
+func NewSQLSink(ctx context.Context, db *sql.DB, schema string) (*SQLSink, error) {
+	if schema == "" {
+		schema = DefaultSchema
+	}
+	if !schemaPattern.MatchString(schema) {
+		return nil, fmt.Errorf("invalid schema name %q", schema)
+	}
+	s := &SQLSink{db: db, schema: schema}
+	if err := s.migrate(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SQLSink) migrate(ctx context.Context) error {
+	stmts := []string{fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", s.schema)}
+	for _, kind := range Kinds {
+		stmts = append(stmts,
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (key TEXT PRIMARY KEY, height BIGINT NOT NULL, data JSONB NOT NULL)", s.table(kind)),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_height ON %s (height)", kind, s.table(kind)),
+		)
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migrate schema %s: %w", s.schema, err)
+		}
+	}
+	return nil
+}
+
+// Write implements Sink.
+func (s *SQLSink) Write(ctx context.Context, records []*Record) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		stmt := fmt.Sprintf("INSERT INTO %s (key, height, data) VALUES ($1, $2, $3) ON CONFLICT (key) DO NOTHING", s.table(r.Kind))
+		if _, err := tx.ExecContext(ctx, stmt, r.Key, int64(r.Height), string(r.Data)); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Close implements Sink.
+func (s *SQLSink) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLSink) table(kind Kind) string {
+	return s.schema + "." + string(kind)
+}
@@ -28,5 +28,31 @@ type NewDualEventsEvent struct{ Events []*types.DualEvent }
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// TxDropReason describes why a transaction left the transaction pool.
+type TxDropReason string
+
+const (
+	TxDropUnderpriced TxDropReason = "underpriced" // Evicted by better priced transactions or a raised price limit
+	TxDropReplaced    TxDropReason = "replaced"    // Superseded by another transaction with the same nonce
+	TxDropIncluded    TxDropReason = "included"    // Nonce already used by the chain head state
+	TxDropExpired     TxDropReason = "expired"     // Queued for longer than the pool lifetime
+	TxDropInvalid     TxDropReason = "invalid"     // No longer payable with the account balance or block gas limit
+	TxDropOverflow    TxDropReason = "overflow"    // Over the account or global pool limits
+)
+
+// DroppedTxEvent is posted when a batch of transactions leave the transaction
+// pool, along with the reason why.
+type DroppedTxEvent struct {
+	Txs    []*types.Transaction
+	Reason TxDropReason
+}
+
+// ReplacedTxEvent is posted when a pooled transaction is replaced by a new one
+// with the same nonce. The old transaction is also reported as dropped.
+type ReplacedTxEvent struct {
+	Old *types.Transaction
+	New *types.Transaction
+}
+
 // ChainHeadEvent is posted when a new head block is saved to the block chain.
 type ChainHeadEvent struct{ Block *types.Block }
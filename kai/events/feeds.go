@@ -0,0 +1,171 @@
+/*
+ *  Copyright 2018 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package events
+
+import (
+	"github.com/kardiachain/go-kardia/lib/event"
+)
+
+// NewTxsFeed is a feed of NewTxsEvent. The zero value is ready to use.
+type NewTxsFeed struct {
+	feed event.TypedFeed
+}
+
+// Subscribe adds ch to the feed, blocking Send until ch receives the events.
+func (f *NewTxsFeed) Subscribe(ch chan<- NewTxsEvent) event.Subscription {
+	return f.SubscribeWithPolicy(ch, event.BlockPolicy)
+}
+
+// SubscribeWithPolicy adds ch to the feed, handling it with policy when it
+// is full.
+func (f *NewTxsFeed) SubscribeWithPolicy(ch chan<- NewTxsEvent, policy event.DropPolicy) event.Subscription {
+	return f.feed.Subscribe(func(ev interface{}, quit <-chan struct{}) bool {
+		if quit == nil {
+			select {
+			case ch <- ev.(NewTxsEvent):
+				return true
+			default:
+				return false
+			}
+		}
+		select {
+		case ch <- ev.(NewTxsEvent):
+			return true
+		case <-quit:
+			return false
+		}
+	}, policy)
+}
+
+// Send delivers ev to all subscribers and returns the number of subscribers
+// it was sent to.
+func (f *NewTxsFeed) Send(ev NewTxsEvent) int {
+	return f.feed.Send(ev)
+}
+
+// ChainHeadFeed is a feed of ChainHeadEvent. The zero value is ready to use.
+type ChainHeadFeed struct {
+	feed event.TypedFeed
+}
+
+// Subscribe adds ch to the feed, blocking Send until ch receives the events.
+func (f *ChainHeadFeed) Subscribe(ch chan<- ChainHeadEvent) event.Subscription {
+	return f.SubscribeWithPolicy(ch, event.BlockPolicy)
+}
+
+// SubscribeWithPolicy adds ch to the feed, handling it with policy when it
+// is full.
+func (f *ChainHeadFeed) SubscribeWithPolicy(ch chan<- ChainHeadEvent, policy event.DropPolicy) event.Subscription {
+	return f.feed.Subscribe(func(ev interface{}, quit <-chan struct{}) bool {
+		if quit == nil {
+			select {
+			case ch <- ev.(ChainHeadEvent):
+				return true
+			default:
+				return false
+			}
+		}
+		select {
+		case ch <- ev.(ChainHeadEvent):
+			return true
+		case <-quit:
+			return false
+		}
+	}, policy)
+}
+
+// Send delivers ev to all subscribers and returns the number of subscribers
+// it was sent to.
+func (f *ChainHeadFeed) Send(ev ChainHeadEvent) int {
+	return f.feed.Send(ev)
+}
+
+// DroppedTxFeed is a feed of DroppedTxEvent. The zero value is ready to use.
+type DroppedTxFeed struct {
+	feed event.TypedFeed
+}
+
+// Subscribe adds ch to the feed, blocking Send until ch receives the events.
+func (f *DroppedTxFeed) Subscribe(ch chan<- DroppedTxEvent) event.Subscription {
+	return f.SubscribeWithPolicy(ch, event.BlockPolicy)
+}
+
+// SubscribeWithPolicy adds ch to the feed, handling it with policy when it
+// is full.
+func (f *DroppedTxFeed) SubscribeWithPolicy(ch chan<- DroppedTxEvent, policy event.DropPolicy) event.Subscription {
+	return f.feed.Subscribe(func(ev interface{}, quit <-chan struct{}) bool {
+		if quit == nil {
+			select {
+			case ch <- ev.(DroppedTxEvent):
+				return true
+			default:
+				return false
+			}
+		}
+		select {
+		case ch <- ev.(DroppedTxEvent):
+			return true
+		case <-quit:
+			return false
+		}
+	}, policy)
+}
+
+// Send delivers ev to all subscribers and returns the number of subscribers
+// it was sent to.
+func (f *DroppedTxFeed) Send(ev DroppedTxEvent) int {
+	return f.feed.Send(ev)
+}
+
+// ReplacedTxFeed is a feed of ReplacedTxEvent. The zero value is ready to use.
+type ReplacedTxFeed struct {
+	feed event.TypedFeed
+}
+
+// Subscribe adds ch to the feed, blocking Send until ch receives the events.
+func (f *ReplacedTxFeed) Subscribe(ch chan<- ReplacedTxEvent) event.Subscription {
+	return f.SubscribeWithPolicy(ch, event.BlockPolicy)
+}
+
+// SubscribeWithPolicy adds ch to the feed, handling it with policy when it
+// is full.
+func (f *ReplacedTxFeed) SubscribeWithPolicy(ch chan<- ReplacedTxEvent, policy event.DropPolicy) event.Subscription {
+	return f.feed.Subscribe(func(ev interface{}, quit <-chan struct{}) bool {
+		if quit == nil {
+			select {
+			case ch <- ev.(ReplacedTxEvent):
+				return true
+			default:
+				return false
+			}
+		}
+		select {
+		case ch <- ev.(ReplacedTxEvent):
+			return true
+		case <-quit:
+			return false
+		}
+	}, policy)
+}
+
+// Send delivers ev to all subscribers and returns the number of subscribers
+// it was sent to.
+func (f *ReplacedTxFeed) Send(ev ReplacedTxEvent) int {
+	return f.feed.Send(ev)
+}
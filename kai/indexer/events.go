@@ -0,0 +1,122 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package indexer
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// Event keys of transactions. Addresses and hashes are lower case hex.
+const (
+	TxHashKey     = "tx.hash"
+	TxHeightKey   = "tx.height"
+	TxFromKey     = "tx.from"
+	TxToKey       = "tx.to"
+	TxStatusKey   = "tx.status"
+	TxContractKey = "tx.contract"
+
+	// LogAddressKey is the address of a contract emitting a log, and
+	// LogTopicKey followed by the topic position one of its topics.
+	LogAddressKey = "log.address"
+	LogTopicKey   = "log.topic"
+
+	// Keys of ERC20 and KRC20 Transfer logs.
+	TransferTokenKey = "transfer.token"
+	TransferFromKey  = "transfer.from"
+	TransferToKey    = "transfer.to"
+	TransferValueKey = "transfer.value"
+)
+
+// Event keys of blocks.
+const (
+	BlockHeightKey       = "block.height"
+	BlockProposerKey     = "block.proposer"
+	BlockNumTxsKey       = "block.num_txs"
+	EvidenceValidatorKey = "evidence.validator"
+)
+
+// transferTopic is the topic of Transfer(address,address,uint256) logs.
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// TxEvents returns the events of a transaction sent by from and executed
+// with the given receipt.
+func TxEvents(tx *types.Transaction, receipt *types.Receipt, from common.Address, height uint64) map[string][]string {
+	events := make(map[string][]string)
+	add := func(key, value string) {
+		events[key] = append(events[key], value)
+	}
+
+	add(TxHashKey, hexString(tx.Hash().Bytes()))
+	add(TxHeightKey, strconv.FormatUint(height, 10))
+	add(TxFromKey, addressString(from))
+	if tx.To() != nil {
+		add(TxToKey, addressString(*tx.To()))
+	}
+	if receipt == nil {
+		return events
+	}
+	add(TxStatusKey, strconv.FormatUint(receipt.Status, 10))
+	if receipt.ContractAddress != (common.Address{}) {
+		add(TxContractKey, addressString(receipt.ContractAddress))
+	}
+	for _, l := range receipt.Logs {
+		add(LogAddressKey, addressString(l.Address))
+		for i, topic := range l.Topics {
+			add(LogTopicKey+strconv.Itoa(i), hexString(topic.Bytes()))
+		}
+		// Transfer(address indexed from, address indexed to, uint256 value)
+		if len(l.Topics) == 3 && l.Topics[0] == transferTopic && len(l.Data) == 32 {
+			add(TransferTokenKey, addressString(l.Address))
+			add(TransferFromKey, addressString(common.BytesToAddress(l.Topics[1].Bytes())))
+			add(TransferToKey, addressString(common.BytesToAddress(l.Topics[2].Bytes())))
+			add(TransferValueKey, new(big.Int).SetBytes(l.Data).String())
+		}
+	}
+	return events
+}
+
+// BlockEvents returns the events of a block.
+func BlockEvents(block *types.Block) map[string][]string {
+	events := map[string][]string{
+		BlockHeightKey:   {strconv.FormatUint(block.Height(), 10)},
+		BlockProposerKey: {addressString(block.ProposerAddress())},
+		BlockNumTxsKey:   {strconv.Itoa(len(block.Transactions()))},
+	}
+	if evidence := block.Evidence(); evidence != nil {
+		for _, ev := range evidence.Evidence {
+			for _, e := range ev.VM() {
+				events[EvidenceValidatorKey] = append(events[EvidenceValidatorKey], addressString(e.Address))
+			}
+		}
+	}
+	return events
+}
+
+func addressString(addr common.Address) string {
+	return hexString(addr.Bytes())
+}
+
+func hexString(b []byte) string {
+	return common.Encode(b)
+}
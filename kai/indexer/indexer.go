@@ -0,0 +1,101 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package indexer indexes the events emitted by transactions and blocks as
+// key-value tags, and searches them with pubsub queries such as
+// "transfer.to='0x…' AND tx.height>5".
+package indexer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/pubsub/query"
+)
+
+const (
+	// DefaultPerPage is the number of results per page when none is given.
+	DefaultPerPage = 30
+	// MaxPerPage is the maximum number of results per page.
+	MaxPerPage = 100
+	// MaxSearchScan is the maximum number of index entries a search scans.
+	// Broader queries are rejected rather than loaded and sorted.
+	MaxSearchScan = 10000
+)
+
+var (
+	ErrInvalidPage    = errors.New("page must be greater than 0")
+	ErrPageOutOfRange = errors.New("page is out of range")
+	ErrQueryTooBroad  = errors.New("query matches too many events, narrow it down")
+)
+
+// TxResult is an indexed transaction with the events it emitted.
+type TxResult struct {
+	Hash   common.Hash
+	Height uint64
+	Index  uint32
+	Events map[string][]string
+}
+
+// BlockResult is an indexed block with the events it emitted.
+type BlockResult struct {
+	Height uint64
+	Events map[string][]string
+}
+
+// EventIndexer indexes and searches the events of transactions and blocks.
+type EventIndexer interface {
+	// IndexTxs indexes the transactions of a block.
+	IndexTxs(results []*TxResult) error
+	// IndexBlock indexes the events of a block.
+	IndexBlock(result *BlockResult) error
+	// GetTx returns the indexed transaction with the given hash, or nil if
+	// there is none.
+	GetTx(hash common.Hash) (*TxResult, error)
+	// SearchTxs returns a page of the transactions matching q, ordered by
+	// height and index, and the total number of matches.
+	SearchTxs(ctx context.Context, q *query.Query, page, perPage int) ([]*TxResult, int, error)
+	// SearchBlocks returns a page of the blocks matching q, ordered by
+	// height, and the total number of matches.
+	SearchBlocks(ctx context.Context, q *query.Query, page, perPage int) ([]*BlockResult, int, error)
+}
+
+// paginate returns the bounds of the given page among total results.
+func paginate(total, page, perPage int) (int, int, error) {
+	if page < 1 {
+		return 0, 0, ErrInvalidPage
+	}
+	if perPage < 1 {
+		perPage = DefaultPerPage
+	} else if perPage > MaxPerPage {
+		perPage = MaxPerPage
+	}
+	start := (page - 1) * perPage
+	if start >= total {
+		if total == 0 && page == 1 {
+			return 0, 0, nil
+		}
+		return 0, 0, ErrPageOutOfRange
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+	return start, end, nil
+}
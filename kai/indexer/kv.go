@@ -0,0 +1,357 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/pubsub/query"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+// The indexer shares the chain database, so all its keys live under
+// indexPrefix.
+//
+//	indexPrefix + "tx/" + hash                                         -> tx entry
+//	indexPrefix + "txtag/" + key + "/" + value + "/" + height + index  -> hash
+//	indexPrefix + "blk/" + height                                      -> block entry
+//	indexPrefix + "blktag/" + key + "/" + value + "/" + height         -> height
+//
+// Heights and indexes are big endian so that tags of a value iterate in
+// chain order.
+var (
+	indexPrefix    = []byte("evidx/")
+	txPrefix       = append(append([]byte{}, indexPrefix...), "tx/"...)
+	txTagPrefix    = append(append([]byte{}, indexPrefix...), "txtag/"...)
+	blockPrefix    = append(append([]byte{}, indexPrefix...), "blk/"...)
+	blockTagPrefix = append(append([]byte{}, indexPrefix...), "blktag/"...)
+	tagSeparator   = []byte("/")
+)
+
+// IsIndexKey reports whether key belongs to the event index.
+func IsIndexKey(key []byte) bool {
+	return bytes.HasPrefix(key, indexPrefix)
+}
+
+// contextChecksAt is the number of scanned keys between checks of the search
+// context.
+const contextChecksAt = 1000
+
+// Tag is a key-value event attribute.
+type Tag struct {
+	Key   string
+	Value string
+}
+
+type txEntry struct {
+	Hash   common.Hash
+	Height uint64
+	Index  uint32
+	Tags   []Tag
+}
+
+type blockEntry struct {
+	Height uint64
+	Tags   []Tag
+}
+
+// store is the part of a database the indexer needs.
+type store interface {
+	kaidb.KeyValueReader
+	kaidb.Batcher
+	kaidb.Iteratee
+}
+
+// KVIndexer is an EventIndexer storing its indexes in a key-value database.
+type KVIndexer struct {
+	db store
+}
+
+// NewKVIndexer returns an indexer storing its indexes in db.
+func NewKVIndexer(db store) *KVIndexer {
+	return &KVIndexer{db: db}
+}
+
+// IndexTxs implements EventIndexer.
+func (idx *KVIndexer) IndexTxs(results []*TxResult) error {
+	batch := idx.db.NewBatch()
+	for _, r := range results {
+		entry := txEntry{Hash: r.Hash, Height: r.Height, Index: r.Index, Tags: toTags(r.Events)}
+		data, err := rlp.EncodeToBytes(&entry)
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(txKey(r.Hash), data); err != nil {
+			return err
+		}
+		suffix := append(encodeUint64(r.Height), encodeUint32(r.Index)...)
+		for _, tag := range entry.Tags {
+			if err := batch.Put(tagKey(txTagPrefix, tag.Key, tag.Value, suffix), r.Hash.Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+	return batch.Write()
+}
+
+// IndexBlock implements EventIndexer.
+func (idx *KVIndexer) IndexBlock(result *BlockResult) error {
+	entry := blockEntry{Height: result.Height, Tags: toTags(result.Events)}
+	data, err := rlp.EncodeToBytes(&entry)
+	if err != nil {
+		return err
+	}
+	batch := idx.db.NewBatch()
+	height := encodeUint64(result.Height)
+	if err := batch.Put(blockKey(result.Height), data); err != nil {
+		return err
+	}
+	for _, tag := range entry.Tags {
+		if err := batch.Put(tagKey(blockTagPrefix, tag.Key, tag.Value, height), height); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// GetTx implements EventIndexer.
+func (idx *KVIndexer) GetTx(hash common.Hash) (*TxResult, error) {
+	data, _ := idx.db.Get(txKey(hash))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var entry txEntry
+	if err := rlp.DecodeBytes(data, &entry); err != nil {
+		return nil, fmt.Errorf("invalid tx index entry %x: %w", hash, err)
+	}
+	return &TxResult{Hash: entry.Hash, Height: entry.Height, Index: entry.Index, Events: fromTags(entry.Tags)}, nil
+}
+
+func (idx *KVIndexer) getBlock(height uint64) (*BlockResult, error) {
+	data, _ := idx.db.Get(blockKey(height))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var entry blockEntry
+	if err := rlp.DecodeBytes(data, &entry); err != nil {
+		return nil, fmt.Errorf("invalid block index entry %d: %w", height, err)
+	}
+	return &BlockResult{Height: entry.Height, Events: fromTags(entry.Tags)}, nil
+}
+
+// SearchTxs implements EventIndexer. Candidates are looked up with the most
+// selective condition of q, then matched against all of its conditions.
+func (idx *KVIndexer) SearchTxs(ctx context.Context, q *query.Query, page, perPage int) ([]*TxResult, int, error) {
+	conditions, err := q.Conditions()
+	if err != nil {
+		return nil, 0, err
+	}
+	var results []*TxResult
+	if hash, ok := lookupCondition(conditions, TxHashKey); ok {
+		// Shortcut the lookup by hash.
+		r, err := idx.GetTx(common.HexToHash(hash))
+		if err != nil {
+			return nil, 0, err
+		}
+		if r != nil {
+			results = append(results, r)
+		}
+	} else {
+		candidates, err := idx.candidates(ctx, txTagPrefix, conditions)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, value := range candidates {
+			r, err := idx.GetTx(common.BytesToHash(value))
+			if err != nil {
+				return nil, 0, err
+			}
+			if r != nil {
+				results = append(results, r)
+			}
+		}
+	}
+
+	matches := results[:0]
+	for _, r := range results {
+		ok, err := q.Matches(r.Events)
+		if err != nil {
+			return nil, 0, err
+		}
+		if ok {
+			matches = append(matches, r)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Height != matches[j].Height {
+			return matches[i].Height < matches[j].Height
+		}
+		return matches[i].Index < matches[j].Index
+	})
+	start, end, err := paginate(len(matches), page, perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	return matches[start:end], len(matches), nil
+}
+
+// SearchBlocks implements EventIndexer.
+func (idx *KVIndexer) SearchBlocks(ctx context.Context, q *query.Query, page, perPage int) ([]*BlockResult, int, error) {
+	conditions, err := q.Conditions()
+	if err != nil {
+		return nil, 0, err
+	}
+	candidates, err := idx.candidates(ctx, blockTagPrefix, conditions)
+	if err != nil {
+		return nil, 0, err
+	}
+	var matches []*BlockResult
+	for _, value := range candidates {
+		r, err := idx.getBlock(binary.BigEndian.Uint64(value))
+		if err != nil {
+			return nil, 0, err
+		}
+		if r == nil {
+			continue
+		}
+		ok, err := q.Matches(r.Events)
+		if err != nil {
+			return nil, 0, err
+		}
+		if ok {
+			matches = append(matches, r)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Height < matches[j].Height })
+	start, end, err := paginate(len(matches), page, perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	return matches[start:end], len(matches), nil
+}
+
+// candidates returns the distinct values of the tags under prefix which may
+// match conditions. An equality condition narrows the scan to one value,
+// otherwise all values of the first condition key are scanned. Scans of more
+// than MaxSearchScan entries fail with ErrQueryTooBroad.
+func (idx *KVIndexer) candidates(ctx context.Context, prefix []byte, conditions []query.Condition) ([][]byte, error) {
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+	scan := tagKey(prefix, conditions[0].CompositeKey, "", nil)
+	scan = scan[:len(scan)-len(tagSeparator)] // keep the key separator only
+	for _, c := range conditions {
+		if c.Op == query.OpEqual {
+			scan = tagKey(prefix, c.CompositeKey, fmt.Sprintf("%v", c.Operand), nil)
+			break
+		}
+	}
+
+	it := idx.db.NewIterator(scan, nil)
+	defer it.Release()
+
+	var (
+		seen   = make(map[string]struct{})
+		values [][]byte
+	)
+	for i := 0; it.Next(); i++ {
+		if i == MaxSearchScan {
+			return nil, ErrQueryTooBroad
+		}
+		if i%contextChecksAt == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		value := common.CopyBytes(it.Value())
+		if _, ok := seen[string(value)]; ok {
+			continue
+		}
+		seen[string(value)] = struct{}{}
+		values = append(values, value)
+	}
+	return values, it.Error()
+}
+
+// lookupCondition returns the operand of an equality condition on key.
+func lookupCondition(conditions []query.Condition, key string) (string, bool) {
+	for _, c := range conditions {
+		if c.CompositeKey == key && c.Op == query.OpEqual {
+			if s, ok := c.Operand.(string); ok {
+				return s, true
+			}
+		}
+	}
+	return "", false
+}
+
+func txKey(hash common.Hash) []byte {
+	return append(append([]byte{}, txPrefix...), hash.Bytes()...)
+}
+
+func blockKey(height uint64) []byte {
+	return append(append([]byte{}, blockPrefix...), encodeUint64(height)...)
+}
+
+// tagKey = prefix + key + "/" + value + "/" + suffix
+func tagKey(prefix []byte, key, value string, suffix []byte) []byte {
+	return bytes.Join([][]byte{prefix, []byte(key), tagSeparator, []byte(value), tagSeparator, suffix}, nil)
+}
+
+func encodeUint64(n uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, n)
+	return enc
+}
+
+func encodeUint32(n uint32) []byte {
+	enc := make([]byte, 4)
+	binary.BigEndian.PutUint32(enc, n)
+	return enc
+}
+
+// toTags flattens events into tags sorted by key.
+func toTags(events map[string][]string) []Tag {
+	keys := make([]string, 0, len(events))
+	for key := range events {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var tags []Tag
+	for _, key := range keys {
+		for _, value := range events[key] {
+			tags = append(tags, Tag{Key: key, Value: value})
+		}
+	}
+	return tags
+}
+
+func fromTags(tags []Tag) map[string][]string {
+	events := make(map[string][]string, len(tags))
+	for _, tag := range tags {
+		events[tag.Key] = append(events[tag.Key], tag.Value)
+	}
+	return events
+}
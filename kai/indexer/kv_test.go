@@ -0,0 +1,157 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package indexer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/pubsub/query"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+var (
+	alice = common.HexToAddress("0xa1")
+	bob   = common.HexToAddress("0xb0b")
+	token = common.HexToAddress("0x70c3")
+)
+
+// transferReceipt returns a receipt with a Transfer log of value from alice to bob.
+func transferReceipt(value int64) *types.Receipt {
+	return &types.Receipt{
+		Status: types.ReceiptStatusSuccessful,
+		Logs: []*types.Log{{
+			Address: token,
+			Topics:  []common.Hash{transferTopic, common.BytesToHash(alice.Bytes()), common.BytesToHash(bob.Bytes())},
+			Data:    common.BigToHash(big.NewInt(value)).Bytes(),
+		}},
+	}
+}
+
+func indexTestTxs(t *testing.T, idx *KVIndexer) []common.Hash {
+	var hashes []common.Hash
+	for height := uint64(1); height <= 5; height++ {
+		tx := types.NewTransaction(height, token, new(big.Int), 0, new(big.Int), nil)
+		var receipt *types.Receipt
+		if height%2 == 1 {
+			receipt = transferReceipt(int64(height * 100))
+		} else {
+			receipt = &types.Receipt{Status: types.ReceiptStatusFailed}
+		}
+		require.NoError(t, idx.IndexTxs([]*TxResult{{
+			Hash:   tx.Hash(),
+			Height: height,
+			Events: TxEvents(tx, receipt, alice, height),
+		}}))
+		hashes = append(hashes, tx.Hash())
+	}
+	return hashes
+}
+
+func TestSearchTxs(t *testing.T) {
+	idx := NewKVIndexer(memorydb.New())
+	hashes := indexTestTxs(t, idx)
+
+	tests := []struct {
+		query string
+		want  []common.Hash
+	}{
+		{"transfer.to='" + addressString(bob) + "'", []common.Hash{hashes[0], hashes[2], hashes[4]}},
+		{"transfer.to='" + addressString(bob) + "' AND tx.height>1", []common.Hash{hashes[2], hashes[4]}},
+		{"tx.height>=2 AND tx.height<=4", []common.Hash{hashes[1], hashes[2], hashes[3]}},
+		{"tx.status=0", []common.Hash{hashes[1], hashes[3]}},
+		{"transfer.value>250", []common.Hash{hashes[2], hashes[4]}},
+		{"tx.hash='" + hexString(hashes[3].Bytes()) + "'", []common.Hash{hashes[3]}},
+		{"tx.from='" + addressString(bob) + "'", nil},
+		{"transfer.token EXISTS AND tx.height<3", []common.Hash{hashes[0]}},
+	}
+	for _, tt := range tests {
+		results, total, err := idx.SearchTxs(context.Background(), query.MustParse(tt.query), 1, 10)
+		require.NoError(t, err, tt.query)
+		var got []common.Hash
+		for _, r := range results {
+			got = append(got, r.Hash)
+		}
+		assert.Equal(t, tt.want, got, tt.query)
+		assert.Equal(t, len(tt.want), total, tt.query)
+	}
+}
+
+func TestSearchTxsPagination(t *testing.T) {
+	idx := NewKVIndexer(memorydb.New())
+	hashes := indexTestTxs(t, idx)
+	q := query.MustParse("tx.height>0")
+
+	results, total, err := idx.SearchTxs(context.Background(), q, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+	require.Len(t, results, 2)
+	assert.Equal(t, hashes[2], results[0].Hash)
+	assert.Equal(t, hashes[3], results[1].Hash)
+
+	_, _, err = idx.SearchTxs(context.Background(), q, 4, 2)
+	assert.Equal(t, ErrPageOutOfRange, err)
+	_, _, err = idx.SearchTxs(context.Background(), q, 0, 2)
+	assert.Equal(t, ErrInvalidPage, err)
+}
+
+func TestSearchBlocks(t *testing.T) {
+	idx := NewKVIndexer(memorydb.New())
+	for height := uint64(1); height <= 4; height++ {
+		proposer := alice
+		if height > 2 {
+			proposer = bob
+		}
+		block := types.NewBlockWithHeader(&types.Header{Height: height, ProposerAddress: proposer})
+		require.NoError(t, idx.IndexBlock(&BlockResult{Height: height, Events: BlockEvents(block)}))
+	}
+
+	results, total, err := idx.SearchBlocks(context.Background(), query.MustParse("block.proposer='"+addressString(bob)+"'"), 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, uint64(3), results[0].Height)
+	assert.Equal(t, uint64(4), results[1].Height)
+
+	results, total, err = idx.SearchBlocks(context.Background(), query.MustParse("block.height<2"), 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, uint64(1), results[0].Height)
+}
+
+func TestSearchTooBroad(t *testing.T) {
+	idx := NewKVIndexer(memorydb.New())
+	for height := uint64(1); height <= MaxSearchScan+1; height++ {
+		block := types.NewBlockWithHeader(&types.Header{Height: height, ProposerAddress: alice})
+		require.NoError(t, idx.IndexBlock(&BlockResult{Height: height, Events: BlockEvents(block)}))
+	}
+
+	for _, q := range []string{"block.height>0", "block.proposer='" + addressString(alice) + "'"} {
+		_, _, err := idx.SearchBlocks(context.Background(), query.MustParse(q), 1, 10)
+		assert.Equal(t, ErrQueryTooBroad, err, q)
+	}
+	_, total, err := idx.SearchBlocks(context.Background(), query.MustParse("block.height=42"), 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
@@ -0,0 +1,198 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+// The trace index lives under tracePrefix of the chain database.
+//
+//	tracePrefix + "cursor"                                          -> height of the last indexed block
+//	tracePrefix + "blk/" + height + txIndex + callIndex             -> call record
+//	tracePrefix + "addr/" + address + height + txIndex + callIndex  -> empty
+//
+// An address entry points to the block entry sharing its suffix, it is
+// written for both the caller and the callee of a call.
+var (
+	tracePrefix      = []byte("trcidx/")
+	traceCursorKey   = append(append([]byte{}, tracePrefix...), "cursor"...)
+	traceBlockPrefix = append(append([]byte{}, tracePrefix...), "blk/"...)
+	traceAddrPrefix  = append(append([]byte{}, tracePrefix...), "addr/"...)
+)
+
+// traceSuffixLength is the length of height + txIndex + callIndex.
+const traceSuffixLength = 8 + 4 + 4
+
+// IsTraceKey reports whether key belongs to the trace index.
+func IsTraceKey(key []byte) bool {
+	return bytes.HasPrefix(key, tracePrefix)
+}
+
+// CallRecord is a call made by a transaction, flattened out of its call
+// trace. The first call of a transaction is the transaction itself, the
+// others follow in the order they were entered.
+type CallRecord struct {
+	Height    uint64
+	TxHash    common.Hash
+	TxIndex   uint32
+	CallIndex uint32
+	Type      string
+	From      common.Address
+	To        common.Address
+	Value     *big.Int
+	Selector  []byte // first 4 bytes of the call input
+	Error     string
+}
+
+// TraceIndexer stores the call records of blocks in a key-value database.
+type TraceIndexer struct {
+	db store
+}
+
+// NewTraceIndexer returns a trace indexer storing its index in db.
+func NewTraceIndexer(db store) *TraceIndexer {
+	return &TraceIndexer{db: db}
+}
+
+// IndexBlock stores the call records of the block at height, and moves the
+// cursor to it.
+func (idx *TraceIndexer) IndexBlock(height uint64, records []*CallRecord) error {
+	batch := idx.db.NewBatch()
+	for _, r := range records {
+		if r.Value == nil {
+			r.Value = new(big.Int)
+		}
+		data, err := rlp.EncodeToBytes(r)
+		if err != nil {
+			return err
+		}
+		suffix := traceSuffix(r.Height, r.TxIndex, r.CallIndex)
+		if err := batch.Put(traceKey(traceBlockPrefix, suffix), data); err != nil {
+			return err
+		}
+		if err := batch.Put(traceAddrKey(r.From, suffix), []byte{}); err != nil {
+			return err
+		}
+		if r.To != r.From && r.To != (common.Address{}) {
+			if err := batch.Put(traceAddrKey(r.To, suffix), []byte{}); err != nil {
+				return err
+			}
+		}
+	}
+	if err := batch.Put(traceCursorKey, encodeUint64(height)); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// Cursor returns the height of the last indexed block, or false if no block
+// was indexed yet.
+func (idx *TraceIndexer) Cursor() (uint64, bool) {
+	data, _ := idx.db.Get(traceCursorKey)
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// GetTracesByBlock returns the call records of the block at height, ordered
+// by transaction and call.
+func (idx *TraceIndexer) GetTracesByBlock(height uint64) ([]*CallRecord, error) {
+	it := idx.db.NewIterator(traceKey(traceBlockPrefix, encodeUint64(height)), nil)
+	defer it.Release()
+
+	var records []*CallRecord
+	for it.Next() {
+		r, err := decodeCallRecord(it.Value())
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, it.Error()
+}
+
+// GetTracesByAddress returns the call records from or to addr between the
+// heights from and to inclusive, ordered by height, transaction and call.
+func (idx *TraceIndexer) GetTracesByAddress(ctx context.Context, addr common.Address, from, to uint64) ([]*CallRecord, error) {
+	if from > to {
+		return nil, nil
+	}
+	prefix := traceAddrKey(addr, nil)
+	it := idx.db.NewIterator(prefix, encodeUint64(from))
+	defer it.Release()
+
+	var records []*CallRecord
+	for i := 0; it.Next(); i++ {
+		if i%contextChecksAt == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		suffix := it.Key()[len(prefix):]
+		if len(suffix) != traceSuffixLength {
+			continue
+		}
+		if binary.BigEndian.Uint64(suffix) > to {
+			break
+		}
+		data, _ := idx.db.Get(traceKey(traceBlockPrefix, suffix))
+		if len(data) == 0 {
+			continue
+		}
+		r, err := decodeCallRecord(data)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, it.Error()
+}
+
+func decodeCallRecord(data []byte) (*CallRecord, error) {
+	r := new(CallRecord)
+	if err := rlp.DecodeBytes(data, r); err != nil {
+		return nil, fmt.Errorf("invalid call record: %w", err)
+	}
+	return r, nil
+}
+
+func traceSuffix(height uint64, txIndex, callIndex uint32) []byte {
+	suffix := make([]byte, 0, traceSuffixLength)
+	suffix = append(suffix, encodeUint64(height)...)
+	suffix = append(suffix, encodeUint32(txIndex)...)
+	return append(suffix, encodeUint32(callIndex)...)
+}
+
+func traceKey(prefix, suffix []byte) []byte {
+	return append(append([]byte{}, prefix...), suffix...)
+}
+
+func traceAddrKey(addr common.Address, suffix []byte) []byte {
+	key := append(append([]byte{}, traceAddrPrefix...), addr.Bytes()...)
+	return append(key, suffix...)
+}
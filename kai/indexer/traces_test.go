@@ -0,0 +1,76 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package indexer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/lib/common"
+)
+
+func TestTraceIndexer(t *testing.T) {
+	idx := NewTraceIndexer(memorydb.New())
+	_, ok := idx.Cursor()
+	assert.False(t, ok)
+
+	// Every block has a transaction from alice to the token, which calls bob.
+	for height := uint64(1); height <= 5; height++ {
+		txHash := common.BigToHash(new(big.Int).SetUint64(height))
+		records := []*CallRecord{
+			{Height: height, TxHash: txHash, Type: "CALL", From: alice, To: token, Value: big.NewInt(int64(height)), Selector: []byte{0xa9, 0x05, 0x9c, 0xbb}},
+			{Height: height, TxHash: txHash, CallIndex: 1, Type: "CALL", From: token, To: bob, Error: "execution reverted"},
+		}
+		require.NoError(t, idx.IndexBlock(height, records))
+	}
+	cursor, ok := idx.Cursor()
+	require.True(t, ok)
+	assert.Equal(t, uint64(5), cursor)
+
+	records, err := idx.GetTracesByBlock(3)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, alice, records[0].From)
+	assert.Equal(t, big.NewInt(3), records[0].Value)
+	assert.Equal(t, []byte{0xa9, 0x05, 0x9c, 0xbb}, records[0].Selector)
+	assert.Equal(t, uint32(1), records[1].CallIndex)
+	assert.Equal(t, "execution reverted", records[1].Error)
+
+	records, err = idx.GetTracesByAddress(context.Background(), bob, 2, 4)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	for i, r := range records {
+		assert.Equal(t, uint64(i+2), r.Height)
+		assert.Equal(t, bob, r.To)
+	}
+
+	// The token is both a callee and a caller.
+	records, err = idx.GetTracesByAddress(context.Background(), token, 0, 100)
+	require.NoError(t, err)
+	assert.Len(t, records, 10)
+
+	records, err = idx.GetTracesByAddress(context.Background(), common.HexToAddress("0xdead"), 0, 100)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
@@ -0,0 +1,301 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kaidb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto/sha3"
+	"github.com/kardiachain/go-kardia/lib/log"
+)
+
+// A backup is a stream of the key-value pairs of a database in key order. It
+// starts with backupMagic and the version of the format, every pair is a
+// recordPair byte followed by the length prefixed key and value, and the
+// stream ends with a recordEnd byte, the number of pairs and the keccak256
+// checksum of the stream before it.
+const (
+	backupVersion = 1
+
+	recordEnd  byte = 0
+	recordPair byte = 1
+
+	// maxBackupRecordSize bounds the keys and values read from a backup.
+	maxBackupRecordSize = 64 * 1024 * 1024
+)
+
+var backupMagic = []byte("kaidb-backup")
+
+var (
+	// ErrNotEmpty is returned when restoring a backup into a database which
+	// already holds keys.
+	ErrNotEmpty = errors.New("database is not empty")
+	// ErrBadBackup is returned when a backup stream is malformed, truncated or
+	// doesn't match its checksum.
+	ErrBadBackup = errors.New("bad backup")
+)
+
+// BackupStats is the number and size of the key-value pairs of a backup, and
+// its checksum.
+type BackupStats struct {
+	Count    uint64             `json:"count"`
+	Size     common.StorageSize `json:"size"`
+	Checksum common.Hash        `json:"checksum"`
+}
+
+// Backup writes the whole key space of db to w. If db is a Snapshotter, the
+// keys are read from a snapshot so that the backup is consistent while db is
+// being written to.
+func Backup(db Iteratee, w io.Writer) (*BackupStats, error) {
+	if snapshotter, ok := db.(Snapshotter); ok {
+		snap, err := snapshotter.NewSnapshot()
+		if err != nil {
+			return nil, fmt.Errorf("can't take snapshot: %w", err)
+		}
+		defer snap.Release()
+		db = snap
+	}
+	var (
+		bw     = bufio.NewWriter(w)
+		hasher = sha3.NewKeccak256()
+		out    = io.MultiWriter(bw, hasher)
+		stats  = new(BackupStats)
+		start  = time.Now()
+		logged = time.Now()
+	)
+	if err := writeBackupHeader(out); err != nil {
+		return nil, err
+	}
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		if err := writeBackupPair(out, it.Key(), it.Value()); err != nil {
+			return nil, err
+		}
+		stats.Count++
+		stats.Size += common.StorageSize(len(it.Key()) + len(it.Value()))
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Backing up database", "count", stats.Count, "size", stats.Size,
+				"elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	var trailer [1 + binary.MaxVarintLen64]byte
+	trailer[0] = recordEnd
+	n := 1 + binary.PutUvarint(trailer[1:], stats.Count)
+	if _, err := out.Write(trailer[:n]); err != nil {
+		return nil, err
+	}
+	stats.Checksum = common.BytesToHash(hasher.Sum(nil))
+	if _, err := bw.Write(stats.Checksum.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	log.Info("Backed up database", "count", stats.Count, "size", stats.Size,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
+
+// Restore writes the key-value pairs of the backup read from r into db, in
+// batches of IdealBatchSize. db must be empty, ErrNotEmpty is returned
+// otherwise. If the backup turns out to be bad, ErrBadBackup is returned and
+// db is left with part of the backup, it should be discarded.
+func Restore(db KeyValueStore, r io.Reader) (*BackupStats, error) {
+	it := db.NewIterator(nil, nil)
+	empty := !it.Next()
+	it.Release()
+	if !empty {
+		return nil, ErrNotEmpty
+	}
+	var (
+		hasher = sha3.NewKeccak256()
+		br     = bufio.NewReader(r)
+		in     = &hashingReader{r: br, h: hasher}
+		batch  = db.NewBatch()
+		stats  = new(BackupStats)
+		start  = time.Now()
+		logged = time.Now()
+	)
+	if err := readBackupHeader(in); err != nil {
+		return nil, err
+	}
+	for {
+		kind, err := in.ReadByte()
+		if err != nil {
+			return nil, backupError(err)
+		}
+		if kind == recordEnd {
+			break
+		}
+		if kind != recordPair {
+			return nil, fmt.Errorf("%w: unknown record %d", ErrBadBackup, kind)
+		}
+		key, err := readBackupBytes(in)
+		if err != nil {
+			return nil, err
+		}
+		value, err := readBackupBytes(in)
+		if err != nil {
+			return nil, err
+		}
+		if err := batch.Put(key, value); err != nil {
+			return nil, err
+		}
+		stats.Count++
+		stats.Size += common.StorageSize(len(key) + len(value))
+
+		if batch.ValueSize() >= IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return nil, err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Restoring database", "count", stats.Count, "size", stats.Size,
+				"elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	count, err := binary.ReadUvarint(in)
+	if err != nil {
+		return nil, backupError(err)
+	}
+	if count != stats.Count {
+		return nil, fmt.Errorf("%w: %d pairs read, %d expected", ErrBadBackup, stats.Count, count)
+	}
+	stats.Checksum = common.BytesToHash(hasher.Sum(nil))
+	var checksum common.Hash
+	if _, err := io.ReadFull(br, checksum[:]); err != nil {
+		return nil, backupError(err)
+	}
+	if checksum != stats.Checksum {
+		return nil, fmt.Errorf("%w: checksum %v, expected %v", ErrBadBackup, stats.Checksum.Hex(), checksum.Hex())
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+	log.Info("Restored database", "count", stats.Count, "size", stats.Size,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
+
+func writeBackupHeader(w io.Writer) error {
+	var header [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(header[:], backupVersion)
+	if _, err := w.Write(backupMagic); err != nil {
+		return err
+	}
+	_, err := w.Write(header[:n])
+	return err
+}
+
+func readBackupHeader(r *hashingReader) error {
+	magic := make([]byte, len(backupMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return backupError(err)
+	}
+	if !bytes.Equal(magic, backupMagic) {
+		return fmt.Errorf("%w: not a database backup", ErrBadBackup)
+	}
+	version, err := binary.ReadUvarint(r)
+	if err != nil {
+		return backupError(err)
+	}
+	if version != backupVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrBadBackup, version)
+	}
+	return nil
+}
+
+// writeBackupPair writes the recordPair byte and the length prefixed key and
+// value.
+func writeBackupPair(w io.Writer, key, value []byte) error {
+	var buf [1 + binary.MaxVarintLen64]byte
+	buf[0] = recordPair
+	n := 1 + binary.PutUvarint(buf[1:], uint64(len(key)))
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+	if _, err := w.Write(key); err != nil {
+		return err
+	}
+	n = binary.PutUvarint(buf[:], uint64(len(value)))
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(value)
+	return err
+}
+
+func readBackupBytes(r *hashingReader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, backupError(err)
+	}
+	if size > maxBackupRecordSize {
+		return nil, fmt.Errorf("%w: record of %d bytes exceeds the limit", ErrBadBackup, size)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, backupError(err)
+	}
+	return b, nil
+}
+
+// backupError reports a truncated backup as ErrBadBackup.
+func backupError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: truncated", ErrBadBackup)
+	}
+	return err
+}
+
+// hashingReader hashes the bytes read from r along the way.
+type hashingReader struct {
+	r *bufio.Reader
+	h hash.Hash
+}
+
+func (hr *hashingReader) Read(p []byte) (int, error) {
+	n, err := hr.r.Read(p)
+	hr.h.Write(p[:n])
+	return n, err
+}
+
+func (hr *hashingReader) ReadByte() (byte, error) {
+	b, err := hr.r.ReadByte()
+	if err == nil {
+		hr.h.Write([]byte{b})
+	}
+	return b, err
+}
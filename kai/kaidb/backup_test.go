@@ -0,0 +1,75 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kaidb_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+)
+
+func TestBackup(t *testing.T) {
+	src := memorydb.New()
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, src.Put([]byte(fmt.Sprintf("key%04d", i)), bytes.Repeat([]byte{byte(i)}, i)))
+	}
+	var buf bytes.Buffer
+	stats, err := kaidb.Backup(src, &buf)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1000), stats.Count)
+	backup := buf.Bytes()
+
+	t.Run("Restore", func(t *testing.T) {
+		dst := memorydb.New()
+		restored, err := kaidb.Restore(dst, bytes.NewReader(backup))
+		require.NoError(t, err)
+		require.Equal(t, stats, restored)
+		require.Equal(t, src.Len(), dst.Len())
+		it := src.NewIterator(nil, nil)
+		defer it.Release()
+		for it.Next() {
+			value, err := dst.Get(it.Key())
+			require.NoError(t, err)
+			require.Equal(t, it.Value(), value)
+		}
+
+		// a backup is only restored into an empty database
+		_, err = kaidb.Restore(dst, bytes.NewReader(backup))
+		require.True(t, errors.Is(err, kaidb.ErrNotEmpty))
+	})
+
+	t.Run("Bad", func(t *testing.T) {
+		corrupted := append([]byte{}, backup...)
+		corrupted[len(corrupted)/2] ^= 0xff
+		for name, data := range map[string][]byte{
+			"truncated": backup[:len(backup)-1],
+			"corrupted": corrupted,
+			"empty":     nil,
+		} {
+			_, err := kaidb.Restore(memorydb.New(), bytes.NewReader(data))
+			require.True(t, errors.Is(err, kaidb.ErrBadBackup), name)
+		}
+	})
+}
@@ -0,0 +1,496 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+// Package badger implements the key-value database layer based on Badger.
+package badger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+)
+
+// minCache is the minimum amount of memory in megabytes to allocate to the
+// badger block cache.
+const minCache = 16
+
+// valueLogGCDiscardRatio is the ratio of discardable data a value log file
+// must reach to be rewritten on compaction.
+const valueLogGCDiscardRatio = 0.5
+
+// errEmptyKey is returned when writing an empty key, which badger doesn't
+// support.
+var errEmptyKey = errors.New("empty key")
+
+// Database is a persistent key-value store based on Badger. Apart from basic
+// data storage functionality it also supports batch writes, snapshots and
+// iterating over the keyspace in binary-alphabetical order.
+type Database struct {
+	fn string     // filename for reporting
+	db *badger.DB // Badger instance
+
+	log log.Logger // Contextual logger tracking the database path
+}
+
+// New returns a wrapped Badger object. Badger keeps its files open, so unlike
+// the other backends it takes no file handle allowance.
+func New(file string, cache int) (*Database, error) {
+	if cache < minCache {
+		cache = minCache
+	}
+	logger := log.New("database", file)
+	logger.Info("Allocated cache", "cache", common.StorageSize(cache*1024*1024))
+
+	opts := badger.DefaultOptions(file).
+		WithBlockCacheSize(int64(cache) * 1024 * 1024).
+		WithLogger(badgerLogger{logger})
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Database{
+		fn:  file,
+		db:  db,
+		log: logger,
+	}, nil
+}
+
+// Close flushes any pending data to disk and closes all io accesses to the
+// underlying key-value store.
+func (db *Database) Close() error {
+	return db.db.Close()
+}
+
+// Has retrieves if a key is present in the key-value store.
+func (db *Database) Has(key []byte) (bool, error) {
+	err := db.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(key)
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Get retrieves the given key if it's present in the key-value store.
+func (db *Database) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := db.db.View(func(txn *badger.Txn) (err error) {
+		value, err = get(txn, key)
+		return err
+	})
+	return value, err
+}
+
+// Put inserts the given value into the key-value store.
+func (db *Database) Put(key []byte, value []byte) error {
+	if len(key) == 0 {
+		return errEmptyKey
+	}
+	return db.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(common.CopyBytes(key), common.CopyBytes(value))
+	})
+}
+
+// Delete removes the key from the key-value store.
+func (db *Database) Delete(key []byte) error {
+	if len(key) == 0 {
+		return errEmptyKey
+	}
+	return db.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(common.CopyBytes(key))
+	})
+}
+
+// DeleteRange removes all the keys in the range [start, limit) from the
+// key-value store. The deletion is atomic unless the range is too large for a
+// single badger transaction, it is then committed in several transactions.
+func (db *Database) DeleteRange(start []byte, limit []byte) error {
+	var keys [][]byte
+	err := db.db.View(func(txn *badger.Txn) error {
+		it := newIterator(txn, nil, start, limit, false, false)
+		defer it.Release()
+		for it.Next() {
+			keys = append(keys, common.CopyBytes(it.Key()))
+		}
+		return it.Error()
+	})
+	if err != nil {
+		return err
+	}
+	txn := db.db.NewTransaction(true)
+	defer func() { txn.Discard() }()
+	for _, key := range keys {
+		err := txn.Delete(key)
+		if err == badger.ErrTxnTooBig {
+			if err := txn.Commit(); err != nil {
+				return err
+			}
+			txn = db.db.NewTransaction(true)
+			err = txn.Delete(key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return txn.Commit()
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to its host
+// database until a final write is called.
+func (db *Database) NewBatch() kaidb.Batch {
+	return &batch{
+		db: db.db,
+	}
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (db *Database) NewIterator(prefix []byte, start []byte) kaidb.Iterator {
+	return newIterator(db.db.NewTransaction(false), prefix, append(common.CopyBytes(prefix), start...), kaidb.PrefixLimit(prefix), false, true)
+}
+
+// NewRangeIterator creates a binary-alphabetical iterator over the keys in the
+// range [start, limit).
+func (db *Database) NewRangeIterator(start []byte, limit []byte) kaidb.Iterator {
+	return newIterator(db.db.NewTransaction(false), nil, start, limit, false, true)
+}
+
+// NewReverseIterator creates an iterator over the keys in the range
+// [start, limit) in descending order.
+func (db *Database) NewReverseIterator(start []byte, limit []byte) kaidb.Iterator {
+	return newIterator(db.db.NewTransaction(false), nil, start, limit, true, true)
+}
+
+// NewSnapshot creates a database snapshot based on the current state, as a
+// read-only transaction.
+func (db *Database) NewSnapshot() (kaidb.Snapshot, error) {
+	return &snapshot{txn: db.db.NewTransaction(false)}, nil
+}
+
+// Stat returns a particular internal stat of the database. Badger has no named
+// properties, the sizes of the LSM tree and of the value log are returned for
+// any property.
+func (db *Database) Stat(property string) (string, error) {
+	lsm, vlog := db.db.Size()
+	return fmt.Sprintf("LSM size: %v, value log size: %v", common.StorageSize(lsm), common.StorageSize(vlog)), nil
+}
+
+// Compact flattens the LSM tree to a single level and rewrites the value log
+// files which are mostly made of deleted or overwritten values. Badger cannot
+// compact a key range, so the whole data store is compacted whatever the range.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	if err := db.db.Flatten(1); err != nil {
+		return err
+	}
+	for {
+		err := db.db.RunValueLogGC(valueLogGCDiscardRatio)
+		if err == badger.ErrNoRewrite {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Path returns the path to the database directory.
+func (db *Database) Path() string {
+	return db.fn
+}
+
+// get returns a copy of the value of key in txn.
+func get(txn *badger.Txn, key []byte) ([]byte, error) {
+	item, err := txn.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+// snapshot wraps a read-only badger transaction for implementing the Snapshot
+// interface.
+type snapshot struct {
+	txn *badger.Txn
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (snap *snapshot) Has(key []byte) (bool, error) {
+	_, err := snap.txn.Get(key)
+	if err == badger.ErrKeyNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (snap *snapshot) Get(key []byte) ([]byte, error) {
+	return get(snap.txn, key)
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of the
+// snapshot content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (snap *snapshot) NewIterator(prefix []byte, start []byte) kaidb.Iterator {
+	return newIterator(snap.txn, prefix, append(common.CopyBytes(prefix), start...), kaidb.PrefixLimit(prefix), false, false)
+}
+
+// NewRangeIterator creates a binary-alphabetical iterator over the keys of the
+// snapshot in the range [start, limit).
+func (snap *snapshot) NewRangeIterator(start []byte, limit []byte) kaidb.Iterator {
+	return newIterator(snap.txn, nil, start, limit, false, false)
+}
+
+// NewReverseIterator creates an iterator over the keys of the snapshot in the
+// range [start, limit) in descending order.
+func (snap *snapshot) NewReverseIterator(start []byte, limit []byte) kaidb.Iterator {
+	return newIterator(snap.txn, nil, start, limit, true, false)
+}
+
+// Release releases associated resources.
+func (snap *snapshot) Release() {
+	snap.txn.Discard()
+}
+
+// keyvalue is a key-value tuple tagged with a deletion field to allow creating
+// badger write batches.
+type keyvalue struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+// batch is a write-only batch that commits changes to its host database
+// when Write is called. A batch cannot be used concurrently.
+//
+// Badger write batches cannot be reused after a flush, so the changes are
+// buffered and written with a new badger batch every time.
+type batch struct {
+	db     *badger.DB
+	writes []keyvalue
+	size   int
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *batch) Put(key, value []byte) error {
+	if len(key) == 0 {
+		return errEmptyKey
+	}
+	b.writes = append(b.writes, keyvalue{common.CopyBytes(key), common.CopyBytes(value), false})
+	b.size += len(value)
+	return nil
+}
+
+// Delete inserts the a key removal into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	if len(key) == 0 {
+		return errEmptyKey
+	}
+	b.writes = append(b.writes, keyvalue{common.CopyBytes(key), nil, true})
+	b.size++
+	return nil
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int {
+	return b.size
+}
+
+// Write flushes any accumulated data to disk.
+func (b *batch) Write() error {
+	wb := b.db.NewWriteBatch()
+	for _, keyvalue := range b.writes {
+		var err error
+		if keyvalue.delete {
+			err = wb.Delete(keyvalue.key)
+		} else {
+			err = wb.Set(keyvalue.key, keyvalue.value)
+		}
+		if err != nil {
+			wb.Cancel()
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
+
+// Replay replays the batch contents.
+func (b *batch) Replay(w kaidb.KeyValueWriter) error {
+	for _, keyvalue := range b.writes {
+		if keyvalue.delete {
+			if err := w.Delete(keyvalue.key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.Put(keyvalue.key, keyvalue.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iterator wraps a badger iterator and its read-only transaction for
+// implementing the Iterator interface, whose Next must also be called to move
+// to the first key. Badger iterators have no upper bound, the iterator is
+// exhausted once it moves out of the [start, limit) range.
+type iterator struct {
+	txn     *badger.Txn
+	ownsTxn bool // Whether the transaction is discarded on release
+	iter    *badger.Iterator
+	start   []byte
+	limit   []byte
+	reverse bool
+	moved   bool
+	value   []byte
+	err     error
+}
+
+// newIterator creates an iterator over the keys with the given prefix in the
+// range [start, limit) of txn, positioned before its first key.
+func newIterator(txn *badger.Txn, prefix, start, limit []byte, reverse, ownsTxn bool) *iterator {
+	it := &iterator{
+		txn:     txn,
+		ownsTxn: ownsTxn,
+		iter: txn.NewIterator(badger.IteratorOptions{
+			PrefetchValues: true,
+			PrefetchSize:   100,
+			Reverse:        reverse,
+			Prefix:         common.CopyBytes(prefix),
+		}),
+		start:   common.CopyBytes(start),
+		limit:   common.CopyBytes(limit),
+		reverse: reverse,
+		moved:   true,
+	}
+	switch {
+	case !reverse:
+		it.iter.Seek(it.start)
+	case limit == nil:
+		it.iter.Rewind()
+	default:
+		// Reverse seeks stop at the first key lower than or equal to limit,
+		// which is excluded from the range.
+		it.iter.Seek(it.limit)
+		if it.iter.Valid() && bytes.Equal(it.iter.Item().Key(), it.limit) {
+			it.iter.Next()
+		}
+	}
+	return it
+}
+
+// valid returns whether the badger iterator is positioned at a key of the range.
+func (it *iterator) valid() bool {
+	if !it.iter.Valid() {
+		return false
+	}
+	key := it.iter.Item().Key()
+	if it.reverse {
+		return bytes.Compare(key, it.start) >= 0
+	}
+	return it.limit == nil || bytes.Compare(key, it.limit) < 0
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.iter == nil || it.err != nil {
+		return false
+	}
+	if it.moved {
+		it.moved = false
+	} else {
+		it.iter.Next()
+	}
+	it.value = nil
+	if !it.valid() {
+		return false
+	}
+	it.value, it.err = it.iter.Item().ValueCopy(it.value)
+	return it.err == nil
+}
+
+// Error returns any accumulated error. Exhausting all the key/value pairs
+// is not considered to be an error.
+func (it *iterator) Error() error {
+	return it.err
+}
+
+// Key returns the key of the current key/value pair, or nil if done. The caller
+// should not modify the contents of the returned slice, and its contents may
+// change on the next call to Next.
+func (it *iterator) Key() []byte {
+	if it.iter == nil || it.err != nil || !it.valid() {
+		return nil
+	}
+	return it.iter.Item().Key()
+}
+
+// Value returns the value of the current key/value pair, or nil if done. The
+// caller should not modify the contents of the returned slice, and its contents
+// may change on the next call to Next.
+func (it *iterator) Value() []byte {
+	return it.value
+}
+
+// Release releases associated resources. Release should always succeed and can
+// be called multiple times without causing error.
+func (it *iterator) Release() {
+	if it.iter != nil {
+		it.iter.Close()
+		if it.ownsTxn {
+			it.txn.Discard()
+		}
+		it.iter, it.txn, it.value = nil, nil, nil
+	}
+}
+
+// badgerLogger forwards the badger logs to the database logger.
+type badgerLogger struct {
+	log log.Logger
+}
+
+func (l badgerLogger) Errorf(format string, args ...interface{}) {
+	l.log.Error(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (l badgerLogger) Warningf(format string, args ...interface{}) {
+	l.log.Warn(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (l badgerLogger) Infof(format string, args ...interface{}) {
+	l.log.Debug(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (l badgerLogger) Debugf(format string, args ...interface{}) {
+	l.log.Trace(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
@@ -38,6 +38,15 @@ type KeyValueWriter interface {
 	Delete(key []byte) error
 }
 
+// RangeDeleter wraps the DeleteRange method of a backing data store.
+type RangeDeleter interface {
+	// DeleteRange atomically removes all the keys in the range [start, limit)
+	// from the key-value data store. A nil start is treated as a key before all
+	// keys in the data store; a nil limit is treated as a key after all keys in
+	// the data store.
+	DeleteRange(start []byte, limit []byte) error
+}
+
 // Stater wraps the Stat method of a backing data store.
 type Stater interface {
 	// Stat returns a particular internal stat of the database.
@@ -56,13 +65,45 @@ type Compacter interface {
 	Compact(start []byte, limit []byte) error
 }
 
+// Sizer wraps the SizeOf method of a backing data store. It is optional, the
+// data stores which can't estimate the size of a key range don't implement it.
+type Sizer interface {
+	// SizeOf returns the approximate disk space used by the keys in the range
+	// [start, limit). A nil start is treated as a key before all keys in the
+	// data store; a nil limit is treated as a key after all keys in the data
+	// store.
+	SizeOf(start []byte, limit []byte) (uint64, error)
+}
+
+// Snapshot is a read-only view of a key-value data store at the time it was
+// taken, unaffected by later writes.
+type Snapshot interface {
+	KeyValueReader
+	Iteratee
+	RangeIteratee
+
+	// Release releases associated resources. Release should always succeed and
+	// can be called multiple times without causing error.
+	Release()
+}
+
+// Snapshotter wraps the NewSnapshot method of a backing data store.
+type Snapshotter interface {
+	// NewSnapshot creates a snapshot of the current state of the data store.
+	// The snapshot must be released after use.
+	NewSnapshot() (Snapshot, error)
+}
+
 // KeyValueStore contains all the methods required to allow handling different
 // key-value data stores backing the high level database.
 type KeyValueStore interface {
 	KeyValueReader
 	KeyValueWriter
+	RangeDeleter
 	Batcher
 	Iteratee
+	RangeIteratee
+	Snapshotter
 	Stater
 	Compacter
 	io.Closer
@@ -91,8 +132,11 @@ type AncientStore interface {
 type Database interface {
 	Reader
 	Writer
+	RangeDeleter
 	Batcher
 	Iteratee
+	RangeIteratee
+	Snapshotter
 	Stater
 	Compacter
 	io.Closer
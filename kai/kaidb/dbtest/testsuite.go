@@ -305,6 +305,152 @@ func TestDatabaseSuite(t *testing.T, New func() kaidb.KeyValueStore) {
 		it.Release()
 	})
 
+	t.Run("Snapshot", func(t *testing.T) {
+		db := New()
+		defer db.Close()
+
+		for _, k := range []string{"1", "2", "3"} {
+			if err := db.Put([]byte(k), []byte("v"+k)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		snap, err := db.NewSnapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer snap.Release()
+
+		// Writes after the snapshot must not be visible in it
+		db.Put([]byte("1"), []byte("changed"))
+		db.Delete([]byte("2"))
+		db.Put([]byte("4"), []byte("v4"))
+
+		for _, k := range []string{"1", "2", "3"} {
+			if got, err := snap.Get([]byte(k)); err != nil {
+				t.Errorf("key %s: %v", k, err)
+			} else if string(got) != "v"+k {
+				t.Errorf("key %s: wrong value %q", k, got)
+			}
+		}
+		if has, err := snap.Has([]byte("4")); err != nil {
+			t.Fatal(err)
+		} else if has {
+			t.Error("snapshot contains element written after it")
+		}
+		if _, err := snap.Get([]byte("4")); err == nil {
+			t.Error("no error getting element written after snapshot")
+		}
+		if got, err := db.Get([]byte("1")); err != nil || string(got) != "changed" {
+			t.Errorf("database not updated: %q, %v", got, err)
+		}
+
+		it := snap.NewIterator(nil, nil)
+		if got, want := iterateKeys(it), []string{"1", "2", "3"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("snapshot iterator: got %s; want %s", got, want)
+		}
+		it.Release()
+
+		it = snap.NewReverseIterator([]byte("2"), nil)
+		if got, want := iterateOrderedKeys(it), []string{"3", "2"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("snapshot reverse iterator: got %s; want %s", got, want)
+		}
+		it.Release()
+	})
+
+	t.Run("RangeIterator", func(t *testing.T) {
+		db := New()
+		defer db.Close()
+
+		for _, k := range []string{"a", "b1", "b2", "b3", "c", "d"} {
+			if err := db.Put([]byte(k), []byte("v"+k)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tests := []struct {
+			start, limit string
+			forward      []string
+		}{
+			{"", "", []string{"a", "b1", "b2", "b3", "c", "d"}},
+			{"b", "", []string{"b1", "b2", "b3", "c", "d"}},
+			{"", "c", []string{"a", "b1", "b2", "b3"}},
+			{"b1", "b3", []string{"b1", "b2"}},
+			{"b2", "c0", []string{"b2", "b3", "c"}},
+			{"b", "b", []string{}},
+			{"e", "", []string{}},
+		}
+		bound := func(s string) []byte {
+			if s == "" {
+				return nil
+			}
+			return []byte(s)
+		}
+		for i, tt := range tests {
+			it := db.NewRangeIterator(bound(tt.start), bound(tt.limit))
+			if got := iterateOrderedKeys(it); !reflect.DeepEqual(got, tt.forward) {
+				t.Errorf("test %d: forward: got %s; want %s", i, got, tt.forward)
+			}
+			it.Release()
+
+			reverse := make([]string, 0, len(tt.forward))
+			for j := len(tt.forward) - 1; j >= 0; j-- {
+				reverse = append(reverse, tt.forward[j])
+			}
+			it = db.NewReverseIterator(bound(tt.start), bound(tt.limit))
+			if got := iterateOrderedKeys(it); !reflect.DeepEqual(got, reverse) {
+				t.Errorf("test %d: reverse: got %s; want %s", i, got, reverse)
+			}
+			it.Release()
+		}
+	})
+
+	t.Run("DeleteRange", func(t *testing.T) {
+		db := New()
+		defer db.Close()
+
+		keys := []string{"a", "b1", "b2", "b3", "c", "d"}
+		for _, k := range keys {
+			if err := db.Put([]byte(k), []byte("v"+k)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := db.DeleteRange([]byte("b1"), []byte("c")); err != nil {
+			t.Fatal(err)
+		}
+		it := db.NewIterator(nil, nil)
+		if got, want := iterateKeys(it), []string{"a", "c", "d"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %s; want %s", got, want)
+		}
+		it.Release()
+
+		if err := db.DeleteRange([]byte("c"), nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.DeleteRange(nil, []byte("b")); err != nil {
+			t.Fatal(err)
+		}
+		it = db.NewIterator(nil, nil)
+		if got := iterateKeys(it); len(got) != 0 {
+			t.Errorf("got %s; want empty database", got)
+		}
+		it.Release()
+
+		// Deleting an empty range must succeed
+		if err := db.DeleteRange([]byte("x"), []byte("y")); err != nil {
+			t.Errorf("error deleting empty range: %v", err)
+		}
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		db := New()
+		defer db.Close()
+
+		if _, err := db.Get([]byte("missing")); err == nil {
+			t.Error("no error getting missing key")
+		}
+		if err := db.Delete([]byte("missing")); err != nil {
+			t.Errorf("error deleting missing key: %v", err)
+		}
+	})
 }
 
 func iterateKeys(it kaidb.Iterator) []string {
@@ -315,3 +461,11 @@ func iterateKeys(it kaidb.Iterator) []string {
 	sort.Strings(keys)
 	return keys
 }
+
+func iterateOrderedKeys(it kaidb.Iterator) []string {
+	keys := []string{}
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+	}
+	return keys
+}
@@ -18,7 +18,8 @@
 
 package kaidb
 
-// Iterator iterates over a database's key/value pairs in ascending key order.
+// Iterator iterates over a database's key/value pairs in ascending key order,
+// or in descending key order for reverse iterators.
 //
 // When it encounters an error any seek will return false and will yield no key/
 // value pairs. The error can be queried by calling the Error method. Calling
@@ -61,3 +62,32 @@ type Iteratee interface {
 	// no need for the caller to prepend the prefix to the start
 	NewIterator(prefix []byte, start []byte) Iterator
 }
+
+// RangeIteratee wraps the methods creating iterators over a key range of a
+// backing data store.
+type RangeIteratee interface {
+	// NewRangeIterator creates a binary-alphabetical iterator over the keys in
+	// the range [start, limit). A nil start is treated as a key before all keys
+	// in the data store; a nil limit is treated as a key after all keys in the
+	// data store.
+	NewRangeIterator(start []byte, limit []byte) Iterator
+
+	// NewReverseIterator creates an iterator over the keys in the range
+	// [start, limit), as NewRangeIterator, in descending order.
+	NewReverseIterator(start []byte, limit []byte) Iterator
+}
+
+// PrefixLimit returns the smallest key greater than all the keys with the given
+// prefix, to be used as the limit of a key range. It returns nil if there is
+// no such key, i.e. the prefix is empty or made of 0xff bytes only.
+func PrefixLimit(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xff {
+			limit := make([]byte, i+1)
+			copy(limit, prefix)
+			limit[i]++
+			return limit
+		}
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/errors"
 	"github.com/syndtr/goleveldb/leveldb/filter"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/syndtr/goleveldb/leveldb/util"
 
@@ -135,6 +136,23 @@ func (db *Database) Delete(key []byte) error {
 	return db.db.Delete(key, nil)
 }
 
+// DeleteRange atomically removes all the keys in the range [start, limit) from
+// the key-value store. LevelDB has no range deletion, the keys are collected
+// and deleted in a single write batch.
+func (db *Database) DeleteRange(start []byte, limit []byte) error {
+	it := db.db.NewIterator(&util.Range{Start: start, Limit: limit}, nil)
+	defer it.Release()
+
+	b := new(leveldb.Batch)
+	for it.Next() {
+		b.Delete(it.Key())
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return db.db.Write(b, nil)
+}
+
 // NewBatch creates a write-only key-value store that buffers changes to its host
 // database until a final write is called.
 func (db *Database) NewBatch() kaidb.Batch {
@@ -150,6 +168,27 @@ func (db *Database) NewIterator(prefix []byte, start []byte) kaidb.Iterator {
 	return db.db.NewIterator(bytesPrefixRange(prefix, start), nil)
 }
 
+// NewRangeIterator creates a binary-alphabetical iterator over the keys in the
+// range [start, limit).
+func (db *Database) NewRangeIterator(start []byte, limit []byte) kaidb.Iterator {
+	return db.db.NewIterator(&util.Range{Start: start, Limit: limit}, nil)
+}
+
+// NewReverseIterator creates an iterator over the keys in the range
+// [start, limit) in descending order.
+func (db *Database) NewReverseIterator(start []byte, limit []byte) kaidb.Iterator {
+	return &reverseIterator{Iterator: db.db.NewIterator(&util.Range{Start: start, Limit: limit}, nil)}
+}
+
+// NewSnapshot creates a database snapshot based on the current state.
+func (db *Database) NewSnapshot() (kaidb.Snapshot, error) {
+	snap, err := db.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot{db: snap}, nil
+}
+
 // Stat returns a particular internal stat of the database.
 func (db *Database) Stat(property string) (string, error) {
 	return db.db.GetProperty(property)
@@ -166,11 +205,77 @@ func (db *Database) Compact(start []byte, limit []byte) error {
 	return db.db.CompactRange(util.Range{Start: start, Limit: limit})
 }
 
+// SizeOf returns the approximate disk space used by the keys in the range
+// [start, limit).
+func (db *Database) SizeOf(start []byte, limit []byte) (uint64, error) {
+	sizes, err := db.db.SizeOf([]util.Range{{Start: start, Limit: limit}})
+	if err != nil {
+		return 0, err
+	}
+	return uint64(sizes.Sum()), nil
+}
+
 // Path returns the path to the database directory.
 func (db *Database) Path() string {
 	return db.fn
 }
 
+// snapshot wraps a leveldb snapshot for implementing the Snapshot interface.
+type snapshot struct {
+	db *leveldb.Snapshot
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (snap *snapshot) Has(key []byte) (bool, error) {
+	return snap.db.Has(key, nil)
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (snap *snapshot) Get(key []byte) ([]byte, error) {
+	return snap.db.Get(key, nil)
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of the
+// snapshot content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (snap *snapshot) NewIterator(prefix []byte, start []byte) kaidb.Iterator {
+	return snap.db.NewIterator(bytesPrefixRange(prefix, start), nil)
+}
+
+// NewRangeIterator creates a binary-alphabetical iterator over the keys of the
+// snapshot in the range [start, limit).
+func (snap *snapshot) NewRangeIterator(start []byte, limit []byte) kaidb.Iterator {
+	return snap.db.NewIterator(&util.Range{Start: start, Limit: limit}, nil)
+}
+
+// NewReverseIterator creates an iterator over the keys of the snapshot in the
+// range [start, limit) in descending order.
+func (snap *snapshot) NewReverseIterator(start []byte, limit []byte) kaidb.Iterator {
+	return &reverseIterator{Iterator: snap.db.NewIterator(&util.Range{Start: start, Limit: limit}, nil)}
+}
+
+// Release releases associated resources.
+func (snap *snapshot) Release() {
+	snap.db.Release()
+}
+
+// reverseIterator wraps a leveldb iterator to walk it from its last key
+// backwards.
+type reverseIterator struct {
+	iterator.Iterator
+	moved bool
+}
+
+// Next moves the iterator to the previous key/value pair. It returns whether
+// the iterator is exhausted.
+func (it *reverseIterator) Next() bool {
+	if !it.moved {
+		it.moved = true
+		return it.Iterator.Last()
+	}
+	return it.Iterator.Prev()
+}
+
 // batch is a write-only leveldb batch that commits changes to its host database
 // when Write is called. A batch cannot be used concurrently.
 type batch struct {
@@ -112,6 +112,23 @@ func (db *Database) Delete(key []byte) error {
 	return nil
 }
 
+// DeleteRange atomically removes all the keys in the range [start, limit) from
+// the key-value store.
+func (db *Database) DeleteRange(start []byte, limit []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return errMemorydbClosed
+	}
+	for key := range db.db {
+		if inRange(key, start, limit) {
+			delete(db.db, key)
+		}
+	}
+	return nil
+}
+
 // NewBatch creates a write-only key-value store that buffers changes to its host
 // database until a final write is called.
 func (db *Database) NewBatch() kaidb.Batch {
@@ -154,6 +171,61 @@ func (db *Database) NewIterator(prefix []byte, start []byte) kaidb.Iterator {
 	}
 }
 
+// NewRangeIterator creates a binary-alphabetical iterator over the keys in the
+// range [start, limit).
+func (db *Database) NewRangeIterator(start []byte, limit []byte) kaidb.Iterator {
+	return db.newRangeIterator(start, limit, false)
+}
+
+// NewReverseIterator creates an iterator over the keys in the range
+// [start, limit) in descending order.
+func (db *Database) NewReverseIterator(start []byte, limit []byte) kaidb.Iterator {
+	return db.newRangeIterator(start, limit, true)
+}
+
+func (db *Database) newRangeIterator(start []byte, limit []byte, reverse bool) *iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	var (
+		keys   = make([]string, 0, len(db.db))
+		values = make([][]byte, 0, len(db.db))
+	)
+	for key := range db.db {
+		if inRange(key, start, limit) {
+			keys = append(keys, key)
+		}
+	}
+	if reverse {
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	} else {
+		sort.Strings(keys)
+	}
+	for _, key := range keys {
+		values = append(values, db.db[key])
+	}
+	return &iterator{
+		keys:   keys,
+		values: values,
+	}
+}
+
+// NewSnapshot creates a snapshot of the database by copying its current
+// content.
+func (db *Database) NewSnapshot() (kaidb.Snapshot, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return nil, errMemorydbClosed
+	}
+	snap := &Database{db: make(map[string][]byte, len(db.db))}
+	for key, value := range db.db {
+		snap.db[key] = value
+	}
+	return &snapshot{db: snap}, nil
+}
+
 // Stat returns a particular internal stat of the database.
 func (db *Database) Stat(property string) (string, error) {
 	return "", errors.New("unknown property")
@@ -165,6 +237,23 @@ func (db *Database) Compact(start []byte, limit []byte) error {
 	return nil
 }
 
+// SizeOf returns the size of the keys and values in the range [start, limit).
+func (db *Database) SizeOf(start []byte, limit []byte) (uint64, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return 0, errMemorydbClosed
+	}
+	var size uint64
+	for key, value := range db.db {
+		if key >= string(start) && (limit == nil || key < string(limit)) {
+			size += uint64(len(key) + len(value))
+		}
+	}
+	return size, nil
+}
+
 // Len returns the number of entries currently present in the memory database.
 //
 // Note, this method is only used for testing (i.e. not public in general) and
@@ -176,6 +265,12 @@ func (db *Database) Len() int {
 	return len(db.db)
 }
 
+// inRange returns whether key is in the range [start, limit), a nil limit
+// meaning no upper bound.
+func inRange(key string, start []byte, limit []byte) bool {
+	return key >= string(start) && (limit == nil || key < string(limit))
+}
+
 // keyvalue is a key-value tuple tagged with a deletion field to allow creating
 // memory-database write batches.
 type keyvalue struct {
@@ -304,3 +399,43 @@ func (it *iterator) Value() []byte {
 func (it *iterator) Release() {
 	it.keys, it.values = nil, nil
 }
+
+// snapshot is a read-only copy of a memory database.
+type snapshot struct {
+	db *Database
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (snap *snapshot) Has(key []byte) (bool, error) {
+	return snap.db.Has(key)
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (snap *snapshot) Get(key []byte) ([]byte, error) {
+	return snap.db.Get(key)
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of the
+// snapshot content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (snap *snapshot) NewIterator(prefix []byte, start []byte) kaidb.Iterator {
+	return snap.db.NewIterator(prefix, start)
+}
+
+// NewRangeIterator creates a binary-alphabetical iterator over the keys of the
+// snapshot in the range [start, limit).
+func (snap *snapshot) NewRangeIterator(start []byte, limit []byte) kaidb.Iterator {
+	return snap.db.NewRangeIterator(start, limit)
+}
+
+// NewReverseIterator creates an iterator over the keys of the snapshot in the
+// range [start, limit) in descending order.
+func (snap *snapshot) NewReverseIterator(start []byte, limit []byte) kaidb.Iterator {
+	return snap.db.NewReverseIterator(start, limit)
+}
+
+// Release releases the copied content. Accessing a released snapshot fails
+// with an error.
+func (snap *snapshot) Release() {
+	snap.db.Close()
+}
@@ -263,3 +263,24 @@ func UpdateDBMeter(refresh time.Duration, propertyPrefix string, getProperty Get
 func propertyName(prefix, p string) string {
 	return fmt.Sprintf("%s.%s", prefix, p)
 }
+
+// namespaceMeters meter the reads and writes of a namespace of a database.
+type namespaceMeters struct {
+	read   metrics.Meter // bytes read
+	write  metrics.Meter // bytes written
+	delete metrics.Meter // keys deleted
+}
+
+// newNamespaceMeters returns the meters of a namespace, and registers the
+// gauge of its size.
+func newNamespaceMeters(namespace string, size func() int64) *namespaceMeters {
+	name := func(metric string) string {
+		return metricName("namespace/"+namespace, metric)
+	}
+	metrics.DBRegistry.GetOrRegister(name("size"), func() metrics.Gauge { return metrics.NewFunctionalGauge(size) })
+	return &namespaceMeters{
+		read:   metrics.GetOrRegisterMeter(name("read"), metrics.DBRegistry),
+		write:  metrics.GetOrRegisterMeter(name("write"), metrics.DBRegistry),
+		delete: metrics.GetOrRegisterMeter(name("delete"), metrics.DBRegistry),
+	}
+}
@@ -0,0 +1,438 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+// Package pebble implements the key-value database layer based on Pebble.
+package pebble
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/bloom"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+)
+
+const (
+	// minCache is the minimum amount of memory in megabytes to allocate to pebble
+	// read and write caching, split half and half.
+	minCache = 16
+
+	// minHandles is the minimum number of files handles to allocate to the open
+	// database files.
+	minHandles = 16
+)
+
+// Database is a persistent key-value store based on the pebble storage engine.
+// Apart from basic data storage functionality it also supports batch writes,
+// snapshots and iterating over the keyspace in binary-alphabetical order.
+type Database struct {
+	fn string     // filename for reporting
+	db *pebble.DB // Underlying pebble storage engine
+
+	log log.Logger // Contextual logger tracking the database path
+}
+
+// New returns a wrapped pebble DB object.
+func New(file string, cache int, handles int) (*Database, error) {
+	// Ensure we have some minimal caching and file guarantees
+	if cache < minCache {
+		cache = minCache
+	}
+	if handles < minHandles {
+		handles = minHandles
+	}
+	logger := log.New("database", file)
+	logger.Info("Allocated cache and file handles", "cache", common.StorageSize(cache*1024*1024), "handles", handles)
+
+	// Two memory tables are used internally, each taking a quarter of the cache,
+	// like the write buffers of leveldb.
+	memTableSize := cache * 1024 * 1024 / 4
+	cacheSize := int64(cache * 1024 * 1024 / 2)
+
+	pcache := pebble.NewCache(cacheSize)
+	defer pcache.Unref()
+
+	opt := &pebble.Options{
+		Cache:                       pcache,
+		MaxOpenFiles:                handles,
+		MemTableSize:                memTableSize,
+		MemTableStopWritesThreshold: 2,
+		Levels: []pebble.LevelOptions{
+			{TargetFileSize: 2 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 4 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 8 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 16 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 32 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 64 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+			{TargetFileSize: 128 * 1024 * 1024, FilterPolicy: bloom.FilterPolicy(10)},
+		},
+	}
+	db, err := pebble.Open(file, opt)
+	if err != nil {
+		return nil, err
+	}
+	return &Database{
+		fn:  file,
+		db:  db,
+		log: logger,
+	}, nil
+}
+
+// Close flushes any pending data to disk and closes all io accesses to the
+// underlying key-value store.
+func (d *Database) Close() error {
+	return d.db.Close()
+}
+
+// Has retrieves if a key is present in the key-value store.
+func (d *Database) Has(key []byte) (bool, error) {
+	_, closer, err := d.db.Get(key)
+	if err == pebble.ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	closer.Close()
+	return true, nil
+}
+
+// Get retrieves the given key if it's present in the key-value store.
+func (d *Database) Get(key []byte) ([]byte, error) {
+	dat, closer, err := d.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	ret := common.CopyBytes(dat)
+	closer.Close()
+	return ret, nil
+}
+
+// Put inserts the given value into the key-value store.
+func (d *Database) Put(key []byte, value []byte) error {
+	return d.db.Set(key, value, pebble.NoSync)
+}
+
+// Delete removes the key from the key-value store.
+func (d *Database) Delete(key []byte) error {
+	return d.db.Delete(key, pebble.NoSync)
+}
+
+// DeleteRange atomically removes all the keys in the range [start, limit) from
+// the key-value store, with a single range tombstone.
+func (d *Database) DeleteRange(start []byte, limit []byte) error {
+	if limit == nil {
+		var err error
+		if limit, err = d.lastLimit(); err != nil || limit == nil {
+			return err
+		}
+	}
+	if start == nil {
+		start = []byte{}
+	}
+	return d.db.DeleteRange(start, limit, pebble.NoSync)
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to its host
+// database until a final write is called.
+func (d *Database) NewBatch() kaidb.Batch {
+	return &batch{
+		b: d.db.NewBatch(),
+	}
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (d *Database) NewIterator(prefix []byte, start []byte) kaidb.Iterator {
+	return newIterator(d.db.NewIter(iterOptions(prefix, start)), false)
+}
+
+// NewRangeIterator creates a binary-alphabetical iterator over the keys in the
+// range [start, limit).
+func (d *Database) NewRangeIterator(start []byte, limit []byte) kaidb.Iterator {
+	return newIterator(d.db.NewIter(rangeOptions(start, limit)), false)
+}
+
+// NewReverseIterator creates an iterator over the keys in the range
+// [start, limit) in descending order.
+func (d *Database) NewReverseIterator(start []byte, limit []byte) kaidb.Iterator {
+	return newIterator(d.db.NewIter(rangeOptions(start, limit)), true)
+}
+
+// NewSnapshot creates a database snapshot based on the current state.
+func (d *Database) NewSnapshot() (kaidb.Snapshot, error) {
+	return &snapshot{db: d.db.NewSnapshot()}, nil
+}
+
+// Stat returns a particular internal stat of the database. Pebble has no named
+// properties, the metrics of the database are returned for any property.
+func (d *Database) Stat(property string) (string, error) {
+	return d.db.Metrics().String(), nil
+}
+
+// Compact flattens the underlying data store for the given key range. In essence,
+// deleted and overwritten versions are discarded, and the data is rearranged to
+// reduce the cost of operations needed to access them.
+//
+// A nil start is treated as a key before all keys in the data store; a nil limit
+// is treated as a key after all keys in the data store. If both is nil then it
+// will compact entire data store.
+func (d *Database) Compact(start []byte, limit []byte) error {
+	// Pebble requires an upper bound, use the key after the last one instead.
+	if limit == nil {
+		var err error
+		if limit, err = d.lastLimit(); err != nil || limit == nil {
+			return err
+		}
+	}
+	return d.db.Compact(start, limit)
+}
+
+// SizeOf returns the approximate disk space used by the keys in the range
+// [start, limit).
+func (d *Database) SizeOf(start []byte, limit []byte) (uint64, error) {
+	if limit == nil {
+		var err error
+		if limit, err = d.lastLimit(); err != nil || limit == nil {
+			return 0, err
+		}
+	}
+	return d.db.EstimateDiskUsage(start, limit)
+}
+
+// lastLimit returns the key right after the last one of the database, or nil if
+// the database is empty.
+func (d *Database) lastLimit() ([]byte, error) {
+	var limit []byte
+	it := d.db.NewIter(nil)
+	if it.Last() {
+		limit = append(common.CopyBytes(it.Key()), 0x00)
+	}
+	if err := it.Close(); err != nil {
+		return nil, err
+	}
+	return limit, nil
+}
+
+// Path returns the path to the database directory.
+func (d *Database) Path() string {
+	return d.fn
+}
+
+// snapshot wraps a pebble snapshot for implementing the Snapshot interface.
+type snapshot struct {
+	db *pebble.Snapshot
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (snap *snapshot) Has(key []byte) (bool, error) {
+	_, closer, err := snap.db.Get(key)
+	if err == pebble.ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	closer.Close()
+	return true, nil
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (snap *snapshot) Get(key []byte) ([]byte, error) {
+	dat, closer, err := snap.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	ret := common.CopyBytes(dat)
+	closer.Close()
+	return ret, nil
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of the
+// snapshot content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist).
+func (snap *snapshot) NewIterator(prefix []byte, start []byte) kaidb.Iterator {
+	return newIterator(snap.db.NewIter(iterOptions(prefix, start)), false)
+}
+
+// NewRangeIterator creates a binary-alphabetical iterator over the keys of the
+// snapshot in the range [start, limit).
+func (snap *snapshot) NewRangeIterator(start []byte, limit []byte) kaidb.Iterator {
+	return newIterator(snap.db.NewIter(rangeOptions(start, limit)), false)
+}
+
+// NewReverseIterator creates an iterator over the keys of the snapshot in the
+// range [start, limit) in descending order.
+func (snap *snapshot) NewReverseIterator(start []byte, limit []byte) kaidb.Iterator {
+	return newIterator(snap.db.NewIter(rangeOptions(start, limit)), true)
+}
+
+// Release releases associated resources.
+func (snap *snapshot) Release() {
+	if snap.db != nil {
+		snap.db.Close()
+		snap.db = nil
+	}
+}
+
+// batch is a write-only batch that commits changes to its host database
+// when Write is called. A batch cannot be used concurrently.
+type batch struct {
+	b    *pebble.Batch
+	size int
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *batch) Put(key, value []byte) error {
+	b.b.Set(key, value, nil)
+	b.size += len(value)
+	return nil
+}
+
+// Delete inserts the a key removal into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	b.b.Delete(key, nil)
+	b.size++
+	return nil
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int {
+	return b.size
+}
+
+// Write flushes any accumulated data to disk.
+func (b *batch) Write() error {
+	return b.b.Commit(pebble.NoSync)
+}
+
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.b.Reset()
+	b.size = 0
+}
+
+// Replay replays the batch contents.
+func (b *batch) Replay(w kaidb.KeyValueWriter) error {
+	reader := b.b.Reader()
+	for {
+		kind, k, v, ok := reader.Next()
+		if !ok {
+			break
+		}
+		switch kind {
+		case pebble.InternalKeyKindSet:
+			if err := w.Put(k, v); err != nil {
+				return err
+			}
+		case pebble.InternalKeyKindDelete:
+			if err := w.Delete(k); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unhandled operation, keytype: %v", kind)
+		}
+	}
+	return nil
+}
+
+// iterOptions returns the bounds of the keys with the given prefix, starting
+// at prefix+start.
+func iterOptions(prefix, start []byte) *pebble.IterOptions {
+	return rangeOptions(append(common.CopyBytes(prefix), start...), kaidb.PrefixLimit(prefix))
+}
+
+// rangeOptions returns the bounds of the keys in the range [start, limit).
+func rangeOptions(start, limit []byte) *pebble.IterOptions {
+	return &pebble.IterOptions{
+		LowerBound: common.CopyBytes(start),
+		UpperBound: common.CopyBytes(limit),
+	}
+}
+
+// iterator wraps a pebble iterator for implementing the Iterator interface,
+// whose Next must also be called to move to the first key. Reverse iterators
+// move from the last key backwards.
+type iterator struct {
+	iter     *pebble.Iterator
+	reverse  bool
+	moved    bool
+	released bool
+}
+
+func newIterator(iter *pebble.Iterator, reverse bool) *iterator {
+	return &iterator{iter: iter, reverse: reverse, moved: true}
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.released {
+		return false
+	}
+	if it.moved {
+		it.moved = false
+		if it.reverse {
+			return it.iter.Last()
+		}
+		return it.iter.First()
+	}
+	if it.reverse {
+		return it.iter.Prev()
+	}
+	return it.iter.Next()
+}
+
+// Error returns any accumulated error. Exhausting all the key/value pairs
+// is not considered to be an error.
+func (it *iterator) Error() error {
+	if it.released {
+		return nil
+	}
+	return it.iter.Error()
+}
+
+// Key returns the key of the current key/value pair, or nil if done. The caller
+// should not modify the contents of the returned slice, and its contents may
+// change on the next call to Next.
+func (it *iterator) Key() []byte {
+	if it.released || !it.iter.Valid() {
+		return nil
+	}
+	return it.iter.Key()
+}
+
+// Value returns the value of the current key/value pair, or nil if done. The
+// caller should not modify the contents of the returned slice, and its contents
+// may change on the next call to Next.
+func (it *iterator) Value() []byte {
+	if it.released || !it.iter.Valid() {
+		return nil
+	}
+	return it.iter.Value()
+}
+
+// Release releases associated resources. Release should always succeed and can
+// be called multiple times without causing error.
+func (it *iterator) Release() {
+	if !it.released {
+		it.iter.Close()
+		it.released = true
+	}
+}
@@ -0,0 +1,326 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kaidb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// errSizeUnsupported is returned when the size of a namespace is requested and
+// its parent database can't estimate it.
+var errSizeUnsupported = errors.New("size estimation is not supported by the database")
+
+// PrefixStore is a namespace of a database. Its keys are stored in the parent
+// database under the prefix of the namespace, which is left out of the keys
+// it reads and iterates. The reads, writes and deletions of every namespace
+// are metered, as well as its size if the parent database is a Sizer.
+//
+// Closing a PrefixStore is a no-op, its parent database is left open.
+type PrefixStore struct {
+	prefixReader
+	db   Database
+	name string
+}
+
+// NewPrefixStore creates the namespace of db whose keys start with prefix.
+// The namespace is named after the prefix in the metrics, nested namespaces
+// after the prefixes of their parents too.
+func NewPrefixStore(db Database, prefix []byte) *PrefixStore {
+	s := &PrefixStore{
+		prefixReader: prefixReader{r: db, prefix: append([]byte{}, prefix...)},
+		db:           db,
+		name:         namespaceName(prefix),
+	}
+	if parent, ok := db.(*PrefixStore); ok {
+		s.name = parent.name + "/" + s.name
+	}
+	s.meters = newNamespaceMeters(s.name, s.size)
+	return s
+}
+
+// namespaceName returns the name of the namespace of prefix: the prefix
+// without its trailing separator, or its hex encoding if it isn't readable.
+func namespaceName(prefix []byte) string {
+	name := strings.TrimRightFunc(string(prefix), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, r := range name {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return hex.EncodeToString(prefix)
+		}
+	}
+	if name == "" {
+		return hex.EncodeToString(prefix)
+	}
+	return name
+}
+
+// Prefix returns the prefix of the keys of the namespace.
+func (s *PrefixStore) Prefix() []byte {
+	return append([]byte{}, s.prefix...)
+}
+
+// Put inserts the given value into the namespace.
+func (s *PrefixStore) Put(key []byte, value []byte) error {
+	s.meters.write.Mark(int64(len(key) + len(value)))
+	return s.db.Put(s.key(key), value)
+}
+
+// Delete removes the key from the namespace.
+func (s *PrefixStore) Delete(key []byte) error {
+	s.meters.delete.Mark(1)
+	return s.db.Delete(s.key(key))
+}
+
+// DeleteRange removes all the keys of the namespace in the range [start,
+// limit).
+func (s *PrefixStore) DeleteRange(start []byte, limit []byte) error {
+	start, limit = s.keyRange(start, limit)
+	return s.db.DeleteRange(start, limit)
+}
+
+// NewBatch creates a write-only batch of the namespace.
+func (s *PrefixStore) NewBatch() Batch {
+	return s.WrapBatch(s.db.NewBatch())
+}
+
+// WrapBatch returns a batch of the namespace queuing its writes in b, a batch
+// of the parent database. Writing b writes at once the keys of the namespaces
+// sharing it.
+func (s *PrefixStore) WrapBatch(b Batch) Batch {
+	return &prefixBatch{batch: b, store: s}
+}
+
+// NewSnapshot creates a snapshot of the namespace.
+func (s *PrefixStore) NewSnapshot() (Snapshot, error) {
+	snap, err := s.db.NewSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &prefixSnapshot{
+		prefixReader: prefixReader{r: snap, prefix: s.prefix, meters: s.meters},
+		snap:         snap,
+	}, nil
+}
+
+// Stat returns a particular internal stat of the parent database.
+func (s *PrefixStore) Stat(property string) (string, error) {
+	return s.db.Stat(property)
+}
+
+// Compact compacts the keys of the namespace in the range [start, limit).
+func (s *PrefixStore) Compact(start []byte, limit []byte) error {
+	start, limit = s.keyRange(start, limit)
+	return s.db.Compact(start, limit)
+}
+
+// SizeOf returns the approximate disk space used by the keys of the namespace
+// in the range [start, limit).
+func (s *PrefixStore) SizeOf(start []byte, limit []byte) (uint64, error) {
+	sizer, ok := s.db.(Sizer)
+	if !ok {
+		return 0, errSizeUnsupported
+	}
+	start, limit = s.keyRange(start, limit)
+	return sizer.SizeOf(start, limit)
+}
+
+// size returns the approximate disk space used by the namespace, 0 if it
+// can't be estimated.
+func (s *PrefixStore) size() int64 {
+	size, err := s.SizeOf(nil, nil)
+	if err != nil {
+		return 0
+	}
+	return int64(size)
+}
+
+// Close doesn't close the parent database, which is shared with the other
+// namespaces.
+func (s *PrefixStore) Close() error {
+	return nil
+}
+
+// prefixReader reads the keys of a namespace from its parent database or a
+// snapshot of it.
+type prefixReader struct {
+	r interface {
+		KeyValueReader
+		Iteratee
+		RangeIteratee
+	}
+	prefix []byte
+	meters *namespaceMeters
+}
+
+// Has retrieves if a key is present in the namespace.
+func (pr *prefixReader) Has(key []byte) (bool, error) {
+	return pr.r.Has(pr.key(key))
+}
+
+// Get retrieves the given key if it's present in the namespace.
+func (pr *prefixReader) Get(key []byte) ([]byte, error) {
+	value, err := pr.r.Get(pr.key(key))
+	pr.meters.read.Mark(int64(len(value)))
+	return value, err
+}
+
+// NewIterator creates an iterator over the keys of the namespace with a
+// particular prefix, starting at a particular key.
+func (pr *prefixReader) NewIterator(prefix []byte, start []byte) Iterator {
+	return pr.iterator(pr.r.NewIterator(pr.key(prefix), start))
+}
+
+// NewRangeIterator creates an iterator over the keys of the namespace in the
+// range [start, limit).
+func (pr *prefixReader) NewRangeIterator(start []byte, limit []byte) Iterator {
+	start, limit = pr.keyRange(start, limit)
+	return pr.iterator(pr.r.NewRangeIterator(start, limit))
+}
+
+// NewReverseIterator creates an iterator over the keys of the namespace in the
+// range [start, limit), in descending order.
+func (pr *prefixReader) NewReverseIterator(start []byte, limit []byte) Iterator {
+	start, limit = pr.keyRange(start, limit)
+	return pr.iterator(pr.r.NewReverseIterator(start, limit))
+}
+
+func (pr *prefixReader) iterator(it Iterator) Iterator {
+	return &prefixIterator{Iterator: it, prefix: pr.prefix, meters: pr.meters}
+}
+
+// key returns the key of the parent database under which key is stored.
+func (pr *prefixReader) key(key []byte) []byte {
+	k := make([]byte, len(pr.prefix)+len(key))
+	copy(k, pr.prefix)
+	copy(k[len(pr.prefix):], key)
+	return k
+}
+
+// keyRange returns the range of the parent database holding the keys of the
+// namespace in the range [start, limit).
+func (pr *prefixReader) keyRange(start []byte, limit []byte) ([]byte, []byte) {
+	if limit == nil {
+		return pr.key(start), PrefixLimit(pr.prefix)
+	}
+	return pr.key(start), pr.key(limit)
+}
+
+// prefixIterator iterates over the keys of a namespace, without its prefix.
+type prefixIterator struct {
+	Iterator
+	prefix []byte
+	meters *namespaceMeters
+}
+
+// Next moves the iterator to the next key/value pair.
+func (it *prefixIterator) Next() bool {
+	if !it.Iterator.Next() {
+		return false
+	}
+	it.meters.read.Mark(int64(len(it.Iterator.Key()) + len(it.Iterator.Value())))
+	return true
+}
+
+// Key returns the key of the current key/value pair without the prefix of the
+// namespace, or nil if done.
+func (it *prefixIterator) Key() []byte {
+	key := it.Iterator.Key()
+	if key == nil {
+		return nil
+	}
+	return key[len(it.prefix):]
+}
+
+// prefixSnapshot is a snapshot of a namespace.
+type prefixSnapshot struct {
+	prefixReader
+	snap Snapshot
+}
+
+// Release releases the snapshot of the parent database.
+func (s *prefixSnapshot) Release() {
+	s.snap.Release()
+}
+
+// prefixBatch is a batch of a namespace queuing its writes in a batch of the
+// parent database.
+type prefixBatch struct {
+	batch Batch
+	store *PrefixStore
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *prefixBatch) Put(key []byte, value []byte) error {
+	b.store.meters.write.Mark(int64(len(key) + len(value)))
+	return b.batch.Put(b.store.key(key), value)
+}
+
+// Delete inserts the key removal into the batch for later committing.
+func (b *prefixBatch) Delete(key []byte) error {
+	b.store.meters.delete.Mark(1)
+	return b.batch.Delete(b.store.key(key))
+}
+
+// ValueSize retrieves the amount of data queued up for writing in the batch
+// of the parent database.
+func (b *prefixBatch) ValueSize() int {
+	return b.batch.ValueSize()
+}
+
+// Write flushes the batch of the parent database.
+func (b *prefixBatch) Write() error {
+	return b.batch.Write()
+}
+
+// Reset resets the batch of the parent database for reuse.
+func (b *prefixBatch) Reset() {
+	b.batch.Reset()
+}
+
+// Replay replays the writes of the namespace queued in the batch of the parent
+// database, without the prefix of the namespace.
+func (b *prefixBatch) Replay(w KeyValueWriter) error {
+	return b.batch.Replay(&prefixReplayer{w: w, prefix: b.store.prefix})
+}
+
+// prefixReplayer replays the writes of a namespace, the keys of the other
+// namespaces sharing a batch are skipped.
+type prefixReplayer struct {
+	w      KeyValueWriter
+	prefix []byte
+}
+
+func (r *prefixReplayer) Put(key []byte, value []byte) error {
+	if !bytes.HasPrefix(key, r.prefix) {
+		return nil
+	}
+	return r.w.Put(key[len(r.prefix):], value)
+}
+
+func (r *prefixReplayer) Delete(key []byte) error {
+	if !bytes.HasPrefix(key, r.prefix) {
+		return nil
+	}
+	return r.w.Delete(key[len(r.prefix):])
+}
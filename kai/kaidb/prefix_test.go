@@ -0,0 +1,100 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kaidb_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/kaidb/dbtest"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+)
+
+// newNeighbouredDB returns a database with keys around the namespace "ns/".
+func newNeighbouredDB(t *testing.T) *memorydb.Database {
+	db := memorydb.New()
+	for _, key := range []string{"nr", "ns", "ns0", "nt"} {
+		require.NoError(t, db.Put([]byte(key), []byte(key)))
+	}
+	return db
+}
+
+func TestPrefixStore(t *testing.T) {
+	t.Run("DatabaseSuite", func(t *testing.T) {
+		dbtest.TestDatabaseSuite(t, func() kaidb.KeyValueStore {
+			return kaidb.NewPrefixStore(newNeighbouredDB(t), []byte("ns/"))
+		})
+	})
+
+	t.Run("Namespaces", func(t *testing.T) {
+		db := newNeighbouredDB(t)
+		a := kaidb.NewPrefixStore(db, []byte("ns/"))
+		b := kaidb.NewPrefixStore(db, []byte("ns/b/"))
+
+		// keys are stored under the prefix of their namespace
+		require.NoError(t, a.Put([]byte("key"), []byte("a")))
+		require.NoError(t, b.Put([]byte("key"), []byte("b")))
+		value, err := db.Get([]byte("ns/key"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("a"), value)
+		value, err = a.Get([]byte("b/key"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("b"), value)
+
+		// a batch of the parent database is shared by namespaces
+		batch := db.NewBatch()
+		require.NoError(t, a.WrapBatch(batch).Put([]byte("x"), []byte("a")))
+		require.NoError(t, b.WrapBatch(batch).Delete([]byte("key")))
+		_, err = a.Get([]byte("x"))
+		require.Error(t, err)
+		require.NoError(t, batch.Write())
+		_, err = a.Get([]byte("x"))
+		require.NoError(t, err)
+		ok, err := b.Has([]byte("key"))
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		// deleting a namespace leaves its neighbours
+		require.NoError(t, a.DeleteRange(nil, nil))
+		require.Equal(t, 4, db.Len())
+		require.NoError(t, a.Close())
+		ok, err = db.Has([]byte("ns"))
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("SizeOf", func(t *testing.T) {
+		db := newNeighbouredDB(t)
+		s := kaidb.NewPrefixStore(db, []byte("ns/"))
+		size, err := s.SizeOf(nil, nil)
+		require.NoError(t, err)
+		require.Zero(t, size)
+
+		require.NoError(t, s.Put([]byte("1"), []byte("one")))
+		require.NoError(t, s.Put([]byte("2"), []byte("two")))
+		size, err = s.SizeOf(nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2*(len("ns/1")+len("one"))), size)
+		size, err = s.SizeOf([]byte("2"), nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(len("ns/2")+len("two")), size)
+	})
+}
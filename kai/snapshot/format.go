@@ -0,0 +1,170 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package snapshot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto/sha3"
+)
+
+// Record kinds of an archive.
+const (
+	kindManifest    byte = iota // manifest JSON
+	kindChainConfig             // genesis hash -> chain config JSON
+	kindBlock                   // height -> proto encoded block
+	kindSeenCommit              // height -> proto encoded seen commit
+	kindCommit                  // height -> proto encoded commit
+	kindBlockInfo               // height -> RLP encoded block info
+	kindAppHash                 // height -> app hash
+	kindConsensus               // validators or consensus params record
+	kindState                   // proto encoded consensus state
+	kindNode                    // hash -> trie node or contract code
+	kindChecksum                // hash of every record before
+)
+
+// maxRecordSize bounds the keys and values read from an archive.
+const maxRecordSize = 64 * 1024 * 1024
+
+var errTruncated = errors.New("truncated archive")
+
+// recordWriter writes records and hashes them along the way.
+type recordWriter struct {
+	w      *bufio.Writer
+	hasher hash.Hash
+}
+
+func newRecordWriter(w io.Writer) *recordWriter {
+	return &recordWriter{w: bufio.NewWriter(w), hasher: sha3.NewKeccak256()}
+}
+
+func (w *recordWriter) write(kind byte, key, value []byte) error {
+	hashRecord(w.hasher, kind, key, value)
+	return encodeRecord(w.w, kind, key, value)
+}
+
+// close writes the checksum record and flushes the writer.
+func (w *recordWriter) close() (common.Hash, error) {
+	checksum := common.BytesToHash(w.hasher.Sum(nil))
+	if err := encodeRecord(w.w, kindChecksum, nil, checksum.Bytes()); err != nil {
+		return common.Hash{}, err
+	}
+	return checksum, w.w.Flush()
+}
+
+// recordReader reads records and hashes them along the way.
+type recordReader struct {
+	r      *bufio.Reader
+	hasher hash.Hash
+}
+
+func newRecordReader(r io.Reader) *recordReader {
+	return &recordReader{r: bufio.NewReader(r), hasher: sha3.NewKeccak256()}
+}
+
+// read returns the next record. The checksum record is not hashed.
+func (r *recordReader) read() (byte, []byte, []byte, error) {
+	kind, err := r.r.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			return 0, nil, nil, errTruncated
+		}
+		return 0, nil, nil, err
+	}
+	key, err := r.readBytes()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	value, err := r.readBytes()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if kind != kindChecksum {
+		hashRecord(r.hasher, kind, key, value)
+	}
+	return kind, key, value, nil
+}
+
+func (r *recordReader) readBytes() ([]byte, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		if err == io.EOF {
+			return nil, errTruncated
+		}
+		return nil, err
+	}
+	if size > maxRecordSize {
+		return nil, fmt.Errorf("record of %d bytes exceeds the limit", size)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errTruncated
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// checksum returns the hash of the records read so far.
+func (r *recordReader) checksum() common.Hash {
+	return common.BytesToHash(r.hasher.Sum(nil))
+}
+
+// encodeRecord writes the kind and the length prefixed key and value.
+func encodeRecord(w io.Writer, kind byte, key, value []byte) error {
+	var buf [1 + 2*binary.MaxVarintLen64]byte
+	buf[0] = kind
+	n := 1 + binary.PutUvarint(buf[1:], uint64(len(key)))
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+	if _, err := w.Write(key); err != nil {
+		return err
+	}
+	n = binary.PutUvarint(buf[:], uint64(len(value)))
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(value)
+	return err
+}
+
+func hashRecord(h hash.Hash, kind byte, key, value []byte) {
+	_ = encodeRecord(h, kind, key, value)
+}
+
+func encodeHeight(height uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, height)
+	return enc
+}
+
+func decodeHeight(key []byte) (uint64, error) {
+	if len(key) != 8 {
+		return 0, fmt.Errorf("invalid height key %x", key)
+	}
+	return binary.BigEndian.Uint64(key), nil
+}
@@ -0,0 +1,347 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package snapshot
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	kstate "github.com/kardiachain/go-kardia/proto/kardiachain/state"
+	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
+	"github.com/kardiachain/go-kardia/trie"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// ErrChecksumMismatch is returned when an archive does not match its
+// checksum or the expected one.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// importer writes the records of an archive into a database.
+type importer struct {
+	db       kaidb.Database
+	batch    kaidb.Batch
+	manifest *Manifest
+	config   *configs.ChainConfig
+
+	next        uint64        // height of the next block
+	lastBlockID types.BlockID // id of the last block
+	seen        *types.Commit // seen commit of the next block
+	lastSeen    *types.Commit // seen commit of the last block
+	state       *cstate.LatestBlockState
+}
+
+// Import reads an archive from r into db, which must not contain any chain
+// beyond the genesis block of the archive.
+//
+// Blocks must link up to the block of the manifest, which must be signed by
+// its validators. Trie nodes and codes must match their hash and make up the
+// whole state at the app hash of the manifest. The archive must match its
+// checksum, and expected unless it is empty. The head block is only written
+// once everything is verified; db must be discarded if the import fails.
+func Import(db kaidb.Database, r io.Reader, expected common.Hash) (*Manifest, error) {
+	genesis := kvstore.ReadCanonicalHash(db, 0)
+	if head := kvstore.ReadHeadBlockHash(db); head != (common.Hash{}) && !head.Equal(genesis) {
+		return nil, errors.New("database already contains a chain")
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	rr := newRecordReader(gz)
+
+	manifest, err := readManifest(rr)
+	if err != nil {
+		return nil, err
+	}
+	if genesis != (common.Hash{}) && !genesis.Equal(manifest.GenesisHash) {
+		return nil, fmt.Errorf("database contains genesis %v instead of %v", genesis.Hex(), manifest.GenesisHash.Hex())
+	}
+
+	im := &importer{db: db, batch: db.NewBatch(), manifest: manifest}
+	for {
+		kind, key, value, err := rr.read()
+		if err != nil {
+			return nil, err
+		}
+		if kind == kindChecksum {
+			manifest.Checksum = rr.checksum()
+			if !manifest.Checksum.Equal(common.BytesToHash(value)) {
+				return nil, ErrChecksumMismatch
+			}
+			if expected != (common.Hash{}) && !manifest.Checksum.Equal(expected) {
+				return nil, ErrChecksumMismatch
+			}
+			break
+		}
+		if err := im.put(kind, key, value); err != nil {
+			return nil, err
+		}
+	}
+	if err := im.finish(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// ReadManifest reads the manifest at the start of an archive, without
+// verifying the rest of the archive. Its checksum is not set.
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return readManifest(newRecordReader(gz))
+}
+
+func readManifest(rr *recordReader) (*Manifest, error) {
+	kind, _, value, err := rr.read()
+	if err != nil {
+		return nil, err
+	}
+	if kind != kindManifest {
+		return nil, errors.New("archive does not start with a manifest")
+	}
+	manifest := new(Manifest)
+	if err := json.Unmarshal(value, manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.Version != Version {
+		return nil, fmt.Errorf("unsupported archive version %d", manifest.Version)
+	}
+	return manifest, nil
+}
+
+// put verifies and writes a record.
+func (im *importer) put(kind byte, key, value []byte) error {
+	switch kind {
+	case kindChainConfig:
+		config := new(configs.ChainConfig)
+		if err := json.Unmarshal(value, config); err != nil {
+			return fmt.Errorf("invalid chain config: %w", err)
+		}
+		if !common.BytesToHash(key).Equal(im.manifest.GenesisHash) {
+			return errors.New("chain config of another genesis")
+		}
+		im.config = config
+		kvstore.WriteChainConfig(im.batch, im.manifest.GenesisHash, config)
+
+	case kindSeenCommit:
+		commit, err := decodeCommit(value)
+		if err != nil {
+			return err
+		}
+		im.seen = commit
+
+	case kindBlock:
+		if err := im.putBlock(key, value); err != nil {
+			return err
+		}
+
+	case kindCommit:
+		height, err := decodeHeight(key)
+		if err != nil {
+			return err
+		}
+		if height != im.manifest.Height {
+			return fmt.Errorf("unexpected commit %d", height)
+		}
+		commit, err := decodeCommit(value)
+		if err != nil {
+			return err
+		}
+		kvstore.WriteCommit(im.batch, height, commit)
+
+	case kindBlockInfo, kindAppHash:
+		height, err := decodeHeight(key)
+		if err != nil {
+			return err
+		}
+		if im.next == 0 || height != im.next-1 {
+			return fmt.Errorf("record of block %d out of order", height)
+		}
+		if kind == kindBlockInfo {
+			kvstore.WriteBlockInfoRLP(im.batch, im.lastBlockID.Hash, height, value)
+		} else {
+			if len(value) != common.HashLength {
+				return fmt.Errorf("invalid app hash %x", value)
+			}
+			kvstore.WriteAppHash(im.batch, height, common.BytesToHash(value))
+		}
+
+	case kindConsensus:
+		if !cstate.IsRecordKey(key) {
+			return fmt.Errorf("invalid consensus record %q", key)
+		}
+		if err := im.batch.Put(key, value); err != nil {
+			return err
+		}
+
+	case kindState:
+		pb := new(kstate.State)
+		if err := proto.Unmarshal(value, pb); err != nil {
+			return fmt.Errorf("invalid state: %w", err)
+		}
+		state, err := cstate.StateFromProto(pb)
+		if err != nil {
+			return fmt.Errorf("invalid state: %w", err)
+		}
+		im.state = state
+
+	case kindNode:
+		if hash := crypto.Keccak256Hash(value); !hash.Equal(common.BytesToHash(key)) {
+			return fmt.Errorf("node %x does not match its hash %v", key, hash.Hex())
+		}
+		if err := im.batch.Put(key, value); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unknown record kind %d", kind)
+	}
+	return im.flush(false)
+}
+
+func (im *importer) putBlock(key, value []byte) error {
+	height, err := decodeHeight(key)
+	if err != nil {
+		return err
+	}
+	if height != im.next {
+		return fmt.Errorf("expected block %d, got %d", im.next, height)
+	}
+	if height > im.manifest.Height {
+		return fmt.Errorf("block %d is above the manifest height", height)
+	}
+	if im.config == nil {
+		return errors.New("missing chain config")
+	}
+	pb := new(kproto.Block)
+	if err := proto.Unmarshal(value, pb); err != nil {
+		return fmt.Errorf("invalid block %d: %w", height, err)
+	}
+	block, err := types.BlockFromProto(pb)
+	if err != nil {
+		return fmt.Errorf("invalid block %d: %w", height, err)
+	}
+	if block.Height() != height {
+		return fmt.Errorf("block %d has height %d", height, block.Height())
+	}
+	hash := block.Hash()
+	// Block 1 links to the genesis state rather than to the genesis block.
+	if height > 1 {
+		if last := block.Header().LastBlockID; !last.Equal(im.lastBlockID) {
+			return fmt.Errorf("block %d links to %v instead of %v", height, last, im.lastBlockID)
+		}
+	}
+	if height == im.manifest.Height && !hash.Equal(im.manifest.BlockHash) {
+		return fmt.Errorf("block %d hash %v does not match the manifest %v", height, hash.Hex(), im.manifest.BlockHash.Hex())
+	}
+	if height == 0 && !hash.Equal(im.manifest.GenesisHash) {
+		return fmt.Errorf("genesis hash %v does not match the manifest %v", hash.Hex(), im.manifest.GenesisHash.Hex())
+	}
+
+	seen := im.seen
+	if seen == nil {
+		seen = &types.Commit{}
+	} else if height > 0 && !seen.BlockID.Hash.Equal(hash) {
+		return fmt.Errorf("seen commit of block %d is for %v", height, seen.BlockID.Hash.Hex())
+	}
+	parts := block.MakePartSet(types.BlockPartSizeBytes)
+	kvstore.WriteBlock(im.db, block, parts, seen)
+	kvstore.WriteTxLookupEntries(im.batch, block)
+	kvstore.WriteAddressTxIndex(im.batch, block, types.MakeSigner(im.config, &height))
+
+	im.lastBlockID = types.BlockID{Hash: hash, PartsHeader: parts.Header()}
+	im.lastSeen, im.seen = seen, nil
+	im.next++
+	return nil
+}
+
+// flush writes the batch once it is large enough, or always if force.
+func (im *importer) flush(force bool) error {
+	if !force && im.batch.ValueSize() < kaidb.IdealBatchSize {
+		return nil
+	}
+	if err := im.batch.Write(); err != nil {
+		return err
+	}
+	im.batch.Reset()
+	return nil
+}
+
+// finish verifies the imported chain and makes it the head of db.
+func (im *importer) finish() error {
+	m := im.manifest
+	if im.next != m.Height+1 {
+		return fmt.Errorf("archive ends at block %d instead of %d", im.next-1, m.Height)
+	}
+	if err := im.flush(true); err != nil {
+		return err
+	}
+	if hash := kvstore.ReadAppHash(im.db, m.Height); !hash.Equal(m.AppHash) {
+		return fmt.Errorf("app hash %v does not match the manifest %v", hash.Hex(), m.AppHash.Hex())
+	}
+	if err := walkState(trie.NewDatabase(im.db), m.AppHash, func(common.Hash, []byte) error { return nil }); err != nil {
+		return fmt.Errorf("incomplete state: %w", err)
+	}
+
+	state := im.state
+	switch {
+	case state == nil:
+		return errors.New("missing consensus state")
+	case state.ChainID != m.ChainID:
+		return fmt.Errorf("consensus state of chain %s instead of %s", state.ChainID, m.ChainID)
+	case state.LastBlockHeight != m.Height:
+		return fmt.Errorf("consensus state at %d instead of %d", state.LastBlockHeight, m.Height)
+	case !state.LastBlockID.Equal(im.lastBlockID):
+		return fmt.Errorf("consensus state at block %v instead of %v", state.LastBlockID, im.lastBlockID)
+	case !state.AppHash.Equal(m.AppHash):
+		return fmt.Errorf("consensus state app hash %v does not match the manifest %v", state.AppHash.Hex(), m.AppHash.Hex())
+	}
+	if m.Height > 0 {
+		if err := state.LastValidators.VerifyCommit(m.ChainID, im.lastBlockID, m.Height, im.lastSeen); err != nil {
+			return fmt.Errorf("invalid commit of block %d: %w", m.Height, err)
+		}
+	}
+
+	cstate.NewStore(im.db).Save(*state)
+	kvstore.WriteHeadBlockHash(im.db, m.BlockHash)
+	return nil
+}
+
+func decodeCommit(value []byte) (*types.Commit, error) {
+	pb := new(kproto.Commit)
+	if err := proto.Unmarshal(value, pb); err != nil {
+		return nil, fmt.Errorf("invalid commit: %w", err)
+	}
+	return types.CommitFromProto(pb)
+}
@@ -0,0 +1,182 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package snapshot exports a node's chain data at a height into a portable
+// archive and imports it into a fresh database.
+//
+// An archive is a gzip stream of records: the manifest, the chain config, the
+// blocks with their commits, block infos and app hashes, the consensus state
+// records and the state trie at the height. It ends with the keccak256
+// checksum of every record before it.
+package snapshot
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/trie"
+)
+
+// Version is the version of the archive format.
+const Version = 1
+
+// Manifest describes the content of an archive.
+type Manifest struct {
+	Version     uint32      `json:"version"`
+	ChainID     string      `json:"chainId"`
+	Height      uint64      `json:"height"`
+	BlockHash   common.Hash `json:"blockHash"`
+	AppHash     common.Hash `json:"appHash"`
+	GenesisHash common.Hash `json:"genesisHash"`
+
+	// Checksum is the hash of the archive records. It is only known once
+	// the archive is complete, so it is not part of the manifest record.
+	Checksum common.Hash `json:"checksum,omitempty"`
+}
+
+// Export writes an archive of the chain data of db at height to w. Height 0
+// exports the latest height.
+func Export(db kaidb.Database, height uint64, w io.Writer) (*Manifest, error) {
+	latest, err := cstate.NewStore(db).Load()
+	if err != nil {
+		return nil, err
+	}
+	if latest.IsEmpty() {
+		return nil, errors.New("no chain data to export")
+	}
+	if height == 0 {
+		height = latest.LastBlockHeight
+	}
+	meta := kvstore.ReadBlockMeta(db, height)
+	if meta == nil {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+	appHash := kvstore.ReadAppHash(db, height)
+	state, err := cstate.StateAt(db, meta, appHash)
+	if err != nil {
+		return nil, err
+	}
+	genesisHash := kvstore.ReadCanonicalHash(db, 0)
+	config := kvstore.ReadChainConfig(db, genesisHash)
+	if config == nil {
+		return nil, fmt.Errorf("chain config of genesis %v not found", genesisHash.Hex())
+	}
+
+	manifest := &Manifest{
+		Version:     Version,
+		ChainID:     state.ChainID,
+		Height:      height,
+		BlockHash:   meta.BlockID.Hash,
+		AppHash:     appHash,
+		GenesisHash: genesisHash,
+	}
+	gz := gzip.NewWriter(w)
+	rw := newRecordWriter(gz)
+
+	enc, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err := rw.write(kindManifest, nil, enc); err != nil {
+		return nil, err
+	}
+	if enc, err = json.Marshal(config); err != nil {
+		return nil, err
+	}
+	if err := rw.write(kindChainConfig, genesisHash.Bytes(), enc); err != nil {
+		return nil, err
+	}
+	for h := uint64(0); h <= height; h++ {
+		if err := exportBlock(db, rw, h); err != nil {
+			return nil, err
+		}
+	}
+	// The commit of the last block is otherwise only stored with the next one.
+	if commit := kvstore.ReadCommit(db, height); commit != nil {
+		if err := writeProto(rw, kindCommit, encodeHeight(height), commit.ToProto()); err != nil {
+			return nil, err
+		}
+	}
+	err = cstate.Records(db, height, func(key, value []byte) error {
+		return rw.write(kindConsensus, key, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := rw.write(kindState, nil, state.Bytes()); err != nil {
+		return nil, err
+	}
+	err = walkState(trie.NewDatabase(db), appHash, func(hash common.Hash, blob []byte) error {
+		return rw.write(kindNode, hash.Bytes(), blob)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("state at %d: %w", height, err)
+	}
+
+	if manifest.Checksum, err = rw.close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func exportBlock(db kaidb.Database, rw *recordWriter, height uint64) error {
+	block := kvstore.ReadBlock(db, height)
+	if block == nil {
+		return fmt.Errorf("block %d not found", height)
+	}
+	key := encodeHeight(height)
+	// The seen commit comes first as it is stored along with the block.
+	if seen := kvstore.ReadSeenCommit(db, height); seen != nil && height > 0 {
+		if err := writeProto(rw, kindSeenCommit, key, seen.ToProto()); err != nil {
+			return err
+		}
+	}
+	pb, err := block.ToProto()
+	if err != nil {
+		return err
+	}
+	if err := writeProto(rw, kindBlock, key, pb); err != nil {
+		return err
+	}
+	if info := kvstore.ReadBlockInfoRLP(db, block.Hash(), height); len(info) > 0 {
+		if err := rw.write(kindBlockInfo, key, info); err != nil {
+			return err
+		}
+	}
+	return rw.write(kindAppHash, key, kvstore.ReadAppHash(db, height).Bytes())
+}
+
+func writeProto(rw *recordWriter, kind byte, key []byte, pb proto.Message) error {
+	enc, err := proto.Marshal(pb)
+	if err != nil {
+		return err
+	}
+	return rw.write(kind, key, enc)
+}
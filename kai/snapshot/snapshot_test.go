@@ -0,0 +1,220 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/kai/state"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+const testChainID = "kai-test"
+
+var (
+	testAddr     = common.HexToAddress("0x1234")
+	testContract = common.HexToAddress("0x5678")
+)
+
+// newTestChain writes a chain of n blocks with a state at every height.
+func newTestChain(t *testing.T, n uint64) kaidb.Database {
+	db := memorydb.New()
+	vals, privVals := types.RandValidatorSet(4, 10)
+	start := time.Now().Add(-time.Hour)
+
+	commitState := func(parent common.Hash, height uint64) common.Hash {
+		statedb, err := state.New(log.New(), parent, state.NewDatabase(db))
+		require.NoError(t, err)
+		statedb.AddBalance(testAddr, big.NewInt(int64(height+1)))
+		statedb.SetCode(testContract, []byte{0x60, 0x00, byte(height)})
+		statedb.SetState(testContract, common.BigToHash(big.NewInt(int64(height))), common.BytesToHash([]byte{1}))
+		root, err := statedb.Commit(false)
+		require.NoError(t, err)
+		require.NoError(t, statedb.Database().TrieDB().Commit(root, false))
+		return root
+	}
+
+	root := commitState(common.Hash{}, 0)
+	genesis := types.NewBlock(&types.Header{Time: start, GasLimit: configs.BlockGasLimit}, nil, &types.Commit{}, nil)
+	kvstore.WriteBlock(db, genesis, genesis.MakePartSet(types.BlockPartSizeBytes), &types.Commit{})
+	kvstore.WriteBlockInfo(db, genesis.Hash(), 0, nil)
+	kvstore.WriteAppHash(db, 0, root)
+	kvstore.WriteHeadBlockHash(db, genesis.Hash())
+	kvstore.WriteChainConfig(db, genesis.Hash(), configs.TestnetChainConfig)
+
+	store := cstate.NewStore(db)
+	st := cstate.LatestBlockState{
+		ChainID:                          testChainID,
+		InitialHeight:                    1,
+		LastBlockTime:                    start,
+		Validators:                       vals,
+		NextValidators:                   vals.CopyIncrementProposerPriority(1),
+		LastValidators:                   types.NewValidatorSet(nil),
+		LastHeightValidatorsChanged:      1,
+		ConsensusParams:                  *configs.DefaultConsensusParams(),
+		LastHeightConsensusParamsChanged: 1,
+	}
+	store.Save(st)
+
+	var lastCommit = &types.Commit{}
+	for h := uint64(1); h <= n; h++ {
+		header := &types.Header{
+			Height:             h,
+			Time:               start.Add(time.Duration(h) * time.Second),
+			LastBlockID:        st.LastBlockID,
+			GasLimit:           configs.BlockGasLimit,
+			ValidatorsHash:     st.Validators.Hash(),
+			NextValidatorsHash: st.NextValidators.Hash(),
+			AppHash:            st.AppHash,
+		}
+		block := types.NewBlock(header, nil, lastCommit, nil)
+		parts := block.MakePartSet(types.BlockPartSizeBytes)
+		blockID := types.BlockID{Hash: block.Hash(), PartsHeader: parts.Header()}
+		voteSet := types.NewVoteSet(testChainID, h, 0, kproto.PrecommitType, st.Validators)
+		commit, err := types.MakeCommit(blockID, h, 0, voteSet, privVals, header.Time.Add(time.Second))
+		require.NoError(t, err)
+
+		kvstore.WriteBlock(db, block, parts, commit)
+		kvstore.WriteBlockInfo(db, block.Hash(), h, &types.BlockInfo{GasUsed: h})
+		root = commitState(root, h)
+		kvstore.WriteAppHash(db, h, root)
+		kvstore.WriteHeadBlockHash(db, block.Hash())
+
+		st.LastBlockHeight = h
+		st.LastBlockID = blockID
+		st.LastBlockTime = header.Time
+		st.LastValidators = st.Validators
+		st.Validators = st.NextValidators
+		st.NextValidators = st.NextValidators.CopyIncrementProposerPriority(1)
+		st.AppHash = root
+		store.Save(st)
+		lastCommit = commit
+	}
+	return db
+}
+
+func TestExportImport(t *testing.T) {
+	src := newTestChain(t, 5)
+	for _, height := range []uint64{0, 3} {
+		var buf bytes.Buffer
+		manifest, err := Export(src, height, &buf)
+		require.NoError(t, err)
+		want := height
+		if height == 0 {
+			want = 5
+		}
+		require.Equal(t, want, manifest.Height)
+
+		dst := memorydb.New()
+		imported, err := Import(dst, bytes.NewReader(buf.Bytes()), manifest.Checksum)
+		require.NoError(t, err)
+		require.Equal(t, manifest, imported)
+
+		require.Equal(t, kvstore.ReadHeadBlockHash(dst), manifest.BlockHash)
+		require.Equal(t, kvstore.ReadBlock(src, want).Hash(), kvstore.ReadBlock(dst, want).Hash())
+		require.Nil(t, kvstore.ReadBlock(dst, want+1))
+		require.Equal(t, kvstore.ReadSeenCommit(src, want).BlockID, kvstore.ReadSeenCommit(dst, want).BlockID)
+		require.Equal(t, kvstore.ReadBlockInfoRLP(src, manifest.BlockHash, want), kvstore.ReadBlockInfoRLP(dst, manifest.BlockHash, want))
+
+		loaded, err := cstate.NewStore(dst).Load()
+		require.NoError(t, err)
+		require.Equal(t, want, loaded.LastBlockHeight)
+		require.Equal(t, manifest.AppHash, loaded.AppHash)
+		vals, err := cstate.NewStore(dst).LoadValidators(want)
+		require.NoError(t, err)
+		require.Equal(t, loaded.LastValidators.Hash(), vals.Hash())
+
+		statedb, err := state.New(log.New(), manifest.AppHash, state.NewDatabase(dst))
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(int64(want*(want+1)/2+want+1)), statedb.GetBalance(testAddr))
+		require.Equal(t, []byte{0x60, 0x00, byte(want)}, statedb.GetCode(testContract))
+	}
+}
+
+func TestImportRejectsChecksumMismatch(t *testing.T) {
+	src := newTestChain(t, 2)
+	var buf bytes.Buffer
+	_, err := Export(src, 0, &buf)
+	require.NoError(t, err)
+
+	_, err = Import(memorydb.New(), bytes.NewReader(buf.Bytes()), common.BytesToHash([]byte("other")))
+	require.Equal(t, ErrChecksumMismatch, err)
+}
+
+func TestImportRejectsTruncatedArchive(t *testing.T) {
+	src := newTestChain(t, 2)
+	var buf bytes.Buffer
+	_, err := Export(src, 0, &buf)
+	require.NoError(t, err)
+
+	dst := memorydb.New()
+	_, err = Import(dst, bytes.NewReader(buf.Bytes()[:buf.Len()/2]), common.Hash{})
+	require.Error(t, err)
+	require.Equal(t, common.Hash{}, kvstore.ReadHeadBlockHash(dst))
+}
+
+func TestImportRejectsExistingChain(t *testing.T) {
+	src := newTestChain(t, 2)
+	var buf bytes.Buffer
+	_, err := Export(src, 0, &buf)
+	require.NoError(t, err)
+
+	_, err = Import(src, bytes.NewReader(buf.Bytes()), common.Hash{})
+	require.Error(t, err)
+}
+
+func TestImportOverGenesis(t *testing.T) {
+	src := newTestChain(t, 3)
+	var buf bytes.Buffer
+	manifest, err := Export(src, 2, &buf)
+	require.NoError(t, err)
+	read, err := ReadManifest(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, manifest.BlockHash, read.BlockHash)
+
+	// A node which only initialized the same genesis can import
+	dst := memorydb.New()
+	genesis := kvstore.ReadBlock(src, 0)
+	kvstore.WriteBlock(dst, genesis, genesis.MakePartSet(types.BlockPartSizeBytes), &types.Commit{})
+	kvstore.WriteHeadBlockHash(dst, genesis.Hash())
+	_, err = Import(dst, bytes.NewReader(buf.Bytes()), manifest.Checksum)
+	require.NoError(t, err)
+	require.Equal(t, manifest.BlockHash, kvstore.ReadHeadBlockHash(dst))
+
+	// But not one of another genesis
+	other := memorydb.New()
+	otherGenesis := types.NewBlock(&types.Header{Time: time.Now()}, nil, &types.Commit{}, nil)
+	kvstore.WriteBlock(other, otherGenesis, otherGenesis.MakePartSet(types.BlockPartSizeBytes), &types.Commit{})
+	kvstore.WriteHeadBlockHash(other, otherGenesis.Hash())
+	_, err = Import(other, bytes.NewReader(buf.Bytes()), manifest.Checksum)
+	require.Error(t, err)
+}
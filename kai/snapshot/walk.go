@@ -0,0 +1,99 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/kai/state"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+	"github.com/kardiachain/go-kardia/trie"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+var emptyCodeHash = crypto.Keccak256(nil)
+
+// walkState calls fn with every trie node and contract code reachable from
+// the state root, failing if any of them is missing.
+func walkState(triedb *trie.TrieDatabase, root common.Hash, fn func(hash common.Hash, blob []byte) error) error {
+	var (
+		storageRoots = make(map[common.Hash]struct{})
+		codes        = make(map[common.Hash]struct{})
+	)
+	accounts, err := trie.New(root, triedb)
+	if err != nil {
+		return err
+	}
+	it := accounts.NodeIterator(nil)
+	for it.Next(true) {
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			if err := walkNode(triedb, hash, fn); err != nil {
+				return err
+			}
+		}
+		if !it.Leaf() {
+			continue
+		}
+		var account state.Account
+		if err := rlp.DecodeBytes(it.LeafBlob(), &account); err != nil {
+			return fmt.Errorf("invalid account %x: %w", it.LeafKey(), err)
+		}
+		if _, ok := storageRoots[account.Root]; !ok && account.Root != types.EmptyRootHash {
+			storageRoots[account.Root] = struct{}{}
+			if err := walkStorage(triedb, account.Root, fn); err != nil {
+				return err
+			}
+		}
+		codeHash := common.BytesToHash(account.CodeHash)
+		if _, ok := codes[codeHash]; !ok && !bytes.Equal(account.CodeHash, emptyCodeHash) {
+			codes[codeHash] = struct{}{}
+			if err := walkNode(triedb, codeHash, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Error()
+}
+
+func walkStorage(triedb *trie.TrieDatabase, root common.Hash, fn func(hash common.Hash, blob []byte) error) error {
+	storage, err := trie.New(root, triedb)
+	if err != nil {
+		return err
+	}
+	it := storage.NodeIterator(nil)
+	for it.Next(true) {
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			if err := walkNode(triedb, hash, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Error()
+}
+
+func walkNode(triedb *trie.TrieDatabase, hash common.Hash, fn func(hash common.Hash, blob []byte) error) error {
+	blob, err := triedb.Node(hash)
+	if err != nil {
+		return fmt.Errorf("missing node %v: %w", hash.Hex(), err)
+	}
+	return fn(hash, blob)
+}
@@ -0,0 +1,46 @@
+//go:build gofuzz
+// +build gofuzz
+
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"bytes"
+
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+// Fuzz implements a go-fuzz fuzzer method to test the RLP decoding of state
+// trie accounts. Since integers are decoded strictly, a decoded account must
+// encode back to the input. The seed corpus is in testdata/fuzz/account/corpus.
+func Fuzz(data []byte) int {
+	var account Account
+	if err := rlp.DecodeBytes(data, &account); err != nil {
+		return 0
+	}
+	enc, err := rlp.EncodeToBytes(&account)
+	if err != nil {
+		panic(err)
+	}
+	if !bytes.Equal(data, enc) {
+		panic("account encoding is not canonical")
+	}
+	return 1
+}
@@ -31,6 +31,17 @@ type (
 	ErrNoConsensusParamsForHeight struct {
 		Height uint64
 	}
+	ErrUnknownStoreVersion struct {
+		Version uint32
+	}
+	// ErrCorruptState is returned when a record of the state store can't be
+	// decoded or a record it relies on is missing. Height is 0 for the state
+	// record itself.
+	ErrCorruptState struct {
+		Height uint64
+		Key    []byte
+		Err    error
+	}
 )
 
 func (e ErrNoValSetForHeight) Error() string {
@@ -41,7 +52,21 @@ func (e ErrNoConsensusParamsForHeight) Error() string {
 	return fmt.Sprintf("could not find consensus params for height #%d", e.Height)
 }
 
+func (e ErrUnknownStoreVersion) Error() string {
+	return fmt.Sprintf("state record version %d is newer than the supported version %d", e.Version, storeVersion)
+}
+
+func (e ErrCorruptState) Error() string {
+	return fmt.Sprintf("corrupted state record %q at height #%d: %v", e.Key, e.Height, e.Err)
+}
+
+func (e ErrCorruptState) Unwrap() error {
+	return e.Err
+}
+
 var (
-	ErrNilState      = errors.New("nil state")
-	ErrLastCommitSig = errors.New("initial block can't have LastCommit signatures")
+	ErrNilState             = errors.New("nil state")
+	ErrLastCommitSig        = errors.New("initial block can't have LastCommit signatures")
+	ErrAggregatedLastCommit = errors.New("aggregated LastCommit is not enabled by the consensus params")
+	ErrLegacyRecord         = errors.New("state record is in the legacy RLP layout, it can't be converted")
 )
@@ -43,6 +43,17 @@ type BlockStore interface {
 	Config() *configs.ChainConfig
 }
 
+// ConsensusParamsUpdater is implemented by block stores whose application can
+// change the consensus params, e.g. through on-chain governance.
+type ConsensusParamsUpdater interface {
+	// ConsensusParamUpdates returns the changes to apply after the block at
+	// height has been committed.
+	ConsensusParamUpdates(height uint64) ([]types.ConsensusParamChange, error)
+}
+
+// maximum number of heights whose records are pruned after a block
+const maxPrunedHeights = 1000
+
 //-----------------------------------------------------------------------------
 // BlockExecutor handles block execution and state updates.
 // It exposes ApplyBlock(), which validates & executes the block, updates state w/ ABCI responses,
@@ -59,6 +70,10 @@ type BlockExecutor struct {
 
 	// cache the verification results over a single height
 	cache map[common.Hash]struct{}
+
+	// number of blocks the validators and consensus params records are kept
+	// for, 0 to keep all
+	retainBlocks uint64
 }
 
 // NewBlockExecutor returns a new BlockExecutor with a NopEventBus.
@@ -78,6 +93,13 @@ func (blockExec *BlockExecutor) SetEventBus(b *types.EventBus) {
 	blockExec.eventBus = b
 }
 
+// SetRetention sets the number of blocks the validators and consensus params
+// records are kept for, the older ones are pruned as blocks are applied. They
+// are kept at least until the evidence of their heights expires. 0 keeps all.
+func (blockExec *BlockExecutor) SetRetention(blocks uint64) {
+	blockExec.retainBlocks = blocks
+}
+
 // ValidateBlock validates the given block against the given state.
 // If the block is invalid, it returns an error.
 // Validation does not mutate state, but does require historical information from the stateDB,
@@ -117,14 +139,23 @@ func (blockExec *BlockExecutor) ApplyBlock(state LatestBlockState, blockID types
 		return state, block.Height(), fmt.Errorf("commit failed for application: %v", err)
 	}
 
+	var paramUpdates []types.ConsensusParamChange
+	if updater, ok := blockExec.bc.(ConsensusParamsUpdater); ok {
+		paramUpdates, err = updater.ConsensusParamUpdates(block.Height())
+		if err != nil {
+			return state, block.Height(), fmt.Errorf("error loading consensus param updates: %v", err)
+		}
+	}
+
 	valUpdates = calculateValidatorSetUpdates(state.NextValidators.Validators, valUpdates)
 	// update the state with the block and responses
-	state, err = updateState(blockExec.logger, state, blockID, block.Header(), valUpdates)
+	state, err = updateState(blockExec.logger, state, blockID, block.Header(), valUpdates, paramUpdates)
 	if err != nil {
 		return state, block.Height(), fmt.Errorf("commit failed for application: %v", err)
 	}
 	state.AppHash = appHash
 	blockExec.store.Save(state)
+	blockExec.pruneStates(state)
 
 	// Update evpool with the block and state.
 	blockExec.evpool.Update(state, block.Evidence().Evidence)
@@ -139,8 +170,36 @@ func (blockExec *BlockExecutor) ApplyBlock(state LatestBlockState, blockID types
 	return state, block.Height(), nil
 }
 
+// pruneStates prunes the records falling out of the retention window, at most
+// maxPrunedHeights at a time so that enabling the retention on a long chain
+// doesn't hold the block back.
+func (blockExec *BlockExecutor) pruneStates(state LatestBlockState) {
+	retain := blockExec.retainBlocks
+	if maxAge := uint64(state.ConsensusParams.Evidence.MaxAgeNumBlocks); maxAge > retain {
+		retain = maxAge
+	}
+	if blockExec.retainBlocks == 0 || state.LastBlockHeight <= retain {
+		return
+	}
+	from := blockExec.store.Base()
+	if from < state.InitialHeight {
+		from = state.InitialHeight
+	}
+	to := state.LastBlockHeight - retain
+	if to > from+maxPrunedHeights {
+		to = from + maxPrunedHeights
+	}
+	if from >= to {
+		return
+	}
+	if err := blockExec.store.PruneStates(from, to); err != nil {
+		blockExec.logger.Error("Unable to prune states", "from", from, "to", to, "err", err)
+	}
+}
+
 // updateState returns a new State updated according to the header and responses.
-func updateState(logger log.Logger, state LatestBlockState, blockID types.BlockID, header *types.Header, validatorUpdates []*types.Validator) (LatestBlockState, error) {
+func updateState(logger log.Logger, state LatestBlockState, blockID types.BlockID, header *types.Header,
+	validatorUpdates []*types.Validator, paramUpdates []types.ConsensusParamChange) (LatestBlockState, error) {
 	logger.Trace("updateState", "state", state, "blockID", blockID, "header", header)
 	// Copy the valset so we can apply changes from EndBlock
 	// and update s.LastValidators and s.Validators.
@@ -158,18 +217,36 @@ func updateState(logger log.Logger, state LatestBlockState, blockID types.BlockI
 		}
 
 	}
+
+	// Update the params with the latest changes decided by the application.
+	nextParams := state.ConsensusParams
+	lastHeightParamsChanged := state.LastHeightConsensusParamsChanged
+	if len(paramUpdates) > 0 {
+		params, err := types.UpdateConsensusParams(state.ConsensusParams, paramUpdates)
+		if err != nil {
+			// Changes are validated when proposed, so this should not happen.
+			// Keep the current params rather than halting the chain.
+			logger.Error("Invalid consensus param updates, ignoring", "height", header.Height, "err", err)
+		} else {
+			nextParams = params
+			// Change results from this height but only applies to the next height.
+			lastHeightParamsChanged = header.Height + 1
+		}
+	}
+
 	nValSet.IncrementProposerPriority(1)
 	return LatestBlockState{
-		ChainID:                     state.ChainID,
-		InitialHeight:               state.InitialHeight,
-		LastBlockHeight:             header.Height,
-		LastBlockID:                 blockID,
-		LastBlockTime:               header.Time,
-		NextValidators:              nValSet,
-		Validators:                  state.NextValidators.Copy(),
-		LastValidators:              state.Validators.Copy(),
-		LastHeightValidatorsChanged: lastHeightValsChanged,
-		ConsensusParams:             state.ConsensusParams,
+		ChainID:                          state.ChainID,
+		InitialHeight:                    state.InitialHeight,
+		LastBlockHeight:                  header.Height,
+		LastBlockID:                      blockID,
+		LastBlockTime:                    header.Time,
+		NextValidators:                   nValSet,
+		Validators:                       state.NextValidators.Copy(),
+		LastValidators:                   state.Validators.Copy(),
+		LastHeightValidatorsChanged:      lastHeightValsChanged,
+		ConsensusParams:                  nextParams,
+		LastHeightConsensusParamsChanged: lastHeightParamsChanged,
 	}, nil
 }
 
@@ -201,7 +278,7 @@ func getBeginBlockValidatorInfo(cfg *configs.ChainConfig, b *types.Block, store
 			voteInfos[i] = stypes.VoteInfo{
 				Address:         val.Address,
 				VotingPower:     big.NewInt(int64(val.VotingPower)),
-				SignedLastBlock: commitSig.Signature != nil,
+				SignedLastBlock: !commitSig.Absent(),
 			}
 			// v1.5 ugly hacks
 			if cfg.Is1p5(&b.Header().Height) {
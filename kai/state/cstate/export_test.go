@@ -23,10 +23,13 @@ import (
 	"github.com/kardiachain/go-kardia/types"
 )
 
-const ValSetCheckpointInterval = valSetCheckpointInterval
+const (
+	ValSetCheckpointInterval = valSetCheckpointInterval
+	StoreVersion             = storeVersion
+)
 
 // SaveValidatorsInfo is an alias for the private saveValidatorsInfo method in
 // store.go, exported exclusively and explicitly for testing.
 func SaveValidatorsInfo(db kaidb.Database, height, lastHeightChanged uint64, valSet *types.ValidatorSet) {
-	saveValidatorsInfo(db, height, lastHeightChanged, valSet)
+	saveValidatorsInfo(kaidb.NewPrefixStore(db, validatorsKeyPrefix), height, lastHeightChanged, valSet)
 }
@@ -18,8 +18,22 @@ type Store struct {
 	mock.Mock
 }
 
+// Base provides a mock function with given fields:
+func (_m *Store) Base() uint64 {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	return r0
+}
+
 // Load provides a mock function with given fields:
-func (_m *Store) Load() cstate.LatestBlockState {
+func (_m *Store) Load() (cstate.LatestBlockState, error) {
 	ret := _m.Called()
 
 	var r0 cstate.LatestBlockState
@@ -29,7 +43,14 @@ func (_m *Store) Load() cstate.LatestBlockState {
 		r0 = ret.Get(0).(cstate.LatestBlockState)
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // LoadConsensusParams provides a mock function with given fields: height
@@ -97,6 +118,20 @@ func (_m *Store) LoadValidators(height uint64) (*go_kardiamaintypes.ValidatorSet
 	return r0, r1
 }
 
+// PruneStates provides a mock function with given fields: from, to
+func (_m *Store) PruneStates(from uint64, to uint64) error {
+	ret := _m.Called(from, to)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64, uint64) error); ok {
+		r0 = rf(from, to)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Save provides a mock function with given fields: _a0
 func (_m *Store) Save(_a0 cstate.LatestBlockState) {
 	_m.Called(_a0)
@@ -0,0 +1,107 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cstate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// RepairState rolls the state of db back to the most recent block of the block
+// store whose state can be rebuilt from consistent validators and consensus
+// params records, and saves it. It's meant to be run with the node stopped
+// when loading the state fails with ErrCorruptState. The blocks above the
+// returned state are left in the block store.
+func RepairState(db kaidb.Database) (LatestBlockState, error) {
+	hash := kvstore.ReadHeadBlockHash(db)
+	if hash.Equal(common.Hash{}) {
+		return LatestBlockState{}, errors.New("no head block")
+	}
+	head := kvstore.ReadHeaderHeight(db, hash)
+	if head == nil {
+		return LatestBlockState{}, fmt.Errorf("height of head block %v not found", hash.Hex())
+	}
+
+	// the state may not be readable, its chain ID and initial height are
+	// those of a genesis state then
+	var (
+		store         = newStore(db)
+		chainID       string
+		initialHeight = uint64(1)
+		height        = *head
+	)
+	if latest, err := readState(db, stateKey); err == nil && !latest.IsEmpty() {
+		chainID, initialHeight = latest.ChainID, latest.InitialHeight
+		if latest.LastBlockHeight < height {
+			height = latest.LastBlockHeight
+		}
+	}
+	base := store.Base()
+	for {
+		state, err := consistentStateAt(store, chainID, initialHeight, height)
+		if err == nil {
+			store.saveState(state, stateKey)
+			return state, nil
+		}
+		log.Warn("Inconsistent state", "height", height, "err", err)
+		if height == 0 || height <= base {
+			return LatestBlockState{}, fmt.Errorf("no consistent state down to height %d: %w", height, err)
+		}
+		height--
+	}
+}
+
+// consistentStateAt rebuilds the state after the block at height, and checks it
+// against the blocks of the block store.
+func consistentStateAt(store *dbStore, chainID string, initialHeight, height uint64) (LatestBlockState, error) {
+	meta := kvstore.ReadBlockMeta(store.db, height)
+	if meta == nil {
+		return LatestBlockState{}, fmt.Errorf("missing block meta")
+	}
+	appHash := kvstore.ReadAppHash(store.db, height)
+	if appHash.Equal(common.Hash{}) {
+		return LatestBlockState{}, fmt.Errorf("missing app hash")
+	}
+	if !appHash.Equal(types.EmptyRootHash) {
+		if ok, _ := store.db.Has(appHash.Bytes()); !ok {
+			return LatestBlockState{}, fmt.Errorf("missing state root %v", appHash.Hex())
+		}
+	}
+	state, err := stateAt(store, chainID, initialHeight, meta, appHash)
+	if err != nil {
+		return LatestBlockState{}, err
+	}
+	if height > 0 {
+		if hash := state.LastValidators.Hash(); !hash.Equal(meta.Header.ValidatorsHash) {
+			return LatestBlockState{}, fmt.Errorf("validators hash %v instead of %v", hash.Hex(),
+				meta.Header.ValidatorsHash.Hex())
+		}
+		if hash := state.Validators.Hash(); !hash.Equal(meta.Header.NextValidatorsHash) {
+			return LatestBlockState{}, fmt.Errorf("next validators hash %v instead of %v", hash.Hex(),
+				meta.Header.NextValidatorsHash.Hex())
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,116 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cstate_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// newTestChain writes a chain of n blocks with a state saved at every height.
+func newTestChain(t *testing.T, n uint64) kaidb.Database {
+	db := memorydb.New()
+	vals, _ := types.RandValidatorSet(4, 10)
+	start := time.Now().Add(-time.Hour)
+
+	genesis := types.NewBlock(&types.Header{Time: start, GasLimit: configs.BlockGasLimit}, nil, &types.Commit{}, nil)
+	kvstore.WriteBlock(db, genesis, genesis.MakePartSet(types.BlockPartSizeBytes), &types.Commit{})
+	kvstore.WriteAppHash(db, 0, types.EmptyRootHash)
+	kvstore.WriteHeadBlockHash(db, genesis.Hash())
+
+	store := cstate.NewStore(db)
+	st := cstate.LatestBlockState{
+		InitialHeight:                    1,
+		LastBlockTime:                    start,
+		Validators:                       vals,
+		NextValidators:                   vals.CopyIncrementProposerPriority(1),
+		LastValidators:                   types.NewValidatorSet(nil),
+		LastHeightValidatorsChanged:      1,
+		ConsensusParams:                  *configs.DefaultConsensusParams(),
+		LastHeightConsensusParamsChanged: 1,
+	}
+	store.Save(st)
+
+	for h := uint64(1); h <= n; h++ {
+		header := &types.Header{
+			Height:             h,
+			Time:               start.Add(time.Duration(h) * time.Second),
+			LastBlockID:        st.LastBlockID,
+			GasLimit:           configs.BlockGasLimit,
+			ValidatorsHash:     st.Validators.Hash(),
+			NextValidatorsHash: st.NextValidators.Hash(),
+			AppHash:            st.AppHash,
+		}
+		block := types.NewBlock(header, nil, &types.Commit{}, nil)
+		parts := block.MakePartSet(types.BlockPartSizeBytes)
+		kvstore.WriteBlock(db, block, parts, &types.Commit{})
+		kvstore.WriteAppHash(db, h, types.EmptyRootHash)
+		kvstore.WriteHeadBlockHash(db, block.Hash())
+
+		st.LastBlockHeight = h
+		st.LastBlockID = types.BlockID{Hash: block.Hash(), PartsHeader: parts.Header()}
+		st.LastBlockTime = header.Time
+		st.LastValidators = st.Validators
+		st.Validators = st.NextValidators
+		st.NextValidators = st.NextValidators.CopyIncrementProposerPriority(1)
+		st.AppHash = types.EmptyRootHash
+		store.Save(st)
+	}
+	return db
+}
+
+func TestRepairState(t *testing.T) {
+	db := newTestChain(t, 5)
+	store := cstate.NewStore(db)
+	want, err := store.LoadValidators(5)
+	require.NoError(t, err)
+
+	// the state and the next validators saved with it are corrupted
+	require.NoError(t, db.Put([]byte("stateKey"), []byte{0x0a, 0xff}))
+	require.NoError(t, db.Put([]byte("validatorsKey:7"), []byte{0x0a, 0xff}))
+	_, err = store.Load()
+	var corrupt cstate.ErrCorruptState
+	require.True(t, errors.As(err, &corrupt))
+	assert.Equal(t, []byte("stateKey"), corrupt.Key)
+	_, err = cstate.NewStore(db).LoadValidators(7)
+	require.True(t, errors.As(err, &corrupt))
+	assert.EqualValues(t, 7, corrupt.Height)
+
+	// the state is rolled back to the last height whose records are consistent
+	repaired, err := cstate.RepairState(db)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, repaired.LastBlockHeight)
+	assert.Equal(t, want.Hash(), repaired.Validators.Hash())
+
+	loaded, err := cstate.NewStore(db).Load()
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, loaded.LastBlockHeight)
+	assert.Equal(t, kvstore.ReadBlockMeta(db, 4).BlockID, loaded.LastBlockID)
+}
@@ -0,0 +1,136 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cstate
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+var (
+	validatorsKeyPrefix      = []byte("validatorsKey:")
+	consensusParamsKeyPrefix = []byte("consensusParamsKey:")
+)
+
+// StateAt rebuilds the state after the block of meta was committed from the
+// validators and consensus params records, so that a node can resume from
+// it. appHash is the state root after the block.
+func StateAt(db kaidb.Database, meta *types.BlockMeta, appHash common.Hash) (LatestBlockState, error) {
+	latest, err := readState(db, stateKey)
+	if err != nil {
+		return LatestBlockState{}, err
+	}
+	if latest.IsEmpty() {
+		return latest, ErrNilState
+	}
+	height := meta.Header.Height
+	if height > latest.LastBlockHeight {
+		return LatestBlockState{}, fmt.Errorf("height %d is above the latest height %d", height, latest.LastBlockHeight)
+	}
+	if height == latest.LastBlockHeight {
+		return latest, nil
+	}
+	return stateAt(newStore(db), latest.ChainID, latest.InitialHeight, meta, appHash)
+}
+
+// stateAt rebuilds the state after the block of meta from the records of
+// store.
+func stateAt(store *dbStore, chainID string, initialHeight uint64, meta *types.BlockMeta,
+	appHash common.Hash) (LatestBlockState, error) {
+	height := meta.Header.Height
+	state := LatestBlockState{
+		ChainID:         chainID,
+		InitialHeight:   initialHeight,
+		LastBlockHeight: height,
+		LastBlockID:     meta.BlockID,
+		LastBlockTime:   meta.Header.Time,
+		AppHash:         appHash,
+	}
+	var err error
+	if height > 0 {
+		if state.LastValidators, err = store.LoadValidators(height); err != nil {
+			return LatestBlockState{}, err
+		}
+	}
+	if state.Validators, err = store.LoadValidators(height + 1); err != nil {
+		return LatestBlockState{}, err
+	}
+	if state.NextValidators, err = store.LoadValidators(height + 2); err != nil {
+		return LatestBlockState{}, err
+	}
+	// The validators of height+2 were saved along with the state after
+	// height, so they carry its last height of change.
+	valInfo, err := loadValidatorsInfo(store.validators, height+2)
+	if err != nil {
+		return LatestBlockState{}, err
+	}
+	state.LastHeightValidatorsChanged = valInfo.LastHeightChanged
+
+	if state.ConsensusParams, err = store.LoadConsensusParams(height + 1); err != nil {
+		return LatestBlockState{}, err
+	}
+	paramsInfo, err := loadConsensusParamsInfo(store.params, height+1)
+	if err != nil {
+		return LatestBlockState{}, err
+	}
+	state.LastHeightConsensusParamsChanged = paramsInfo.LastHeightChanged
+	return state, nil
+}
+
+// Records calls fn with the raw validators and consensus params records
+// needed by the state after the block at height and by every block before.
+func Records(db kaidb.Database, height uint64, fn func(key, value []byte) error) error {
+	for h := uint64(0); h <= height+2; h++ {
+		keys := [][]byte{calcValidatorsKey(h)}
+		if h <= height+1 {
+			keys = append(keys, calcConsensusParamsKey(h))
+		}
+		for _, key := range keys {
+			value, err := db.Get(key)
+			if err != nil || len(value) == 0 {
+				continue
+			}
+			if err := fn(key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// IsRecordKey reports whether key is the key of a validators or consensus
+// params record.
+func IsRecordKey(key []byte) bool {
+	return bytes.HasPrefix(key, validatorsKeyPrefix) || bytes.HasPrefix(key, consensusParamsKeyPrefix)
+}
+
+// KeyPrefixes returns the prefixes of the keys of the consensus state store.
+func KeyPrefixes() [][]byte {
+	return [][]byte{stateKey, storeVersionKey, baseKey, validatorsKeyPrefix, consensusParamsKeyPrefix}
+}
+
+// IsStateKey reports whether key belongs to the consensus state store.
+func IsStateKey(key []byte) bool {
+	return bytes.Equal(key, stateKey) || bytes.Equal(key, storeVersionKey) || bytes.Equal(key, baseKey) ||
+		IsRecordKey(key)
+}
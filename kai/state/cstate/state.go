@@ -61,9 +61,9 @@ type LatestBlockState struct {
 	// so we can query for historical validator sets.
 	// Note that if s.LastBlockHeight causes a valset change,
 	// we set s.LastHeightValidatorsChanged = s.LastBlockHeight + 1
-	NextValidators              *types.ValidatorSet `rlp:"nil"`
-	Validators                  *types.ValidatorSet `rlp:"nil"`
-	LastValidators              *types.ValidatorSet `rlp:"nil"`
+	NextValidators              *types.ValidatorSet
+	Validators                  *types.ValidatorSet
+	LastValidators              *types.ValidatorSet
 	LastHeightValidatorsChanged uint64
 
 	LastHeightConsensusParamsChanged uint64
@@ -87,7 +87,9 @@ func (state LatestBlockState) Copy() LatestBlockState {
 		LastValidators:              state.LastValidators.Copy(),
 		LastHeightValidatorsChanged: state.LastHeightValidatorsChanged,
 		AppHash:                     state.AppHash,
-		ConsensusParams:             state.ConsensusParams,
+
+		ConsensusParams:                  state.ConsensusParams,
+		LastHeightConsensusParamsChanged: state.LastHeightConsensusParamsChanged,
 	}
 }
 
@@ -103,12 +105,13 @@ func (state LatestBlockState) String() string {
 		state.Validators, state.LastValidators, state.LastHeightValidatorsChanged)
 }
 
-// Bytes ...
+// Bytes serializes the State in the storeVersion layout.
 func (state *LatestBlockState) Bytes() []byte {
 	sm, err := state.ToProto()
 	if err != nil {
 		panic(err)
 	}
+	sm.Version = storeVersion
 	bz, err := proto.Marshal(sm)
 	if err != nil {
 		panic(err)
@@ -19,17 +19,21 @@
 package cstate
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"math/big"
+	"strconv"
 
 	"github.com/kardiachain/go-kardia/configs"
 
 	"github.com/gogo/protobuf/proto"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/kardiachain/go-kardia/mainchain/genesis"
 
 	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
 
-	"github.com/kardiachain/go-kardia/lib/rlp"
 	"github.com/kardiachain/go-kardia/types"
 
 	"github.com/kardiachain/go-kardia/kai/kaidb"
@@ -42,14 +46,32 @@ const (
 	// persist validators every valSetCheckpointInterval blocks to avoid
 	// LoadValidators taking too much time.
 	valSetCheckpointInterval = 100000
+
+	// number of validator sets cached by LoadValidators.
+	valSetCacheSize = 256
+
+	// storeVersion is the version of the layout of the state, validators and
+	// consensus params records. Records written before the layout was
+	// versioned have version 0, they are upgraded by migrate.
+	storeVersion = 1
+)
+
+var (
+	// storeVersionKey holds the version the records of the store are migrated to.
+	storeVersionKey = []byte("stateStoreVersion")
+	// baseKey holds the lowest height whose records are kept, the records
+	// below it are pruned.
+	baseKey = []byte("stateStoreBase")
 )
 
 type Store interface {
 	LoadStateFromDBOrGenesisDoc(genesisDoc *genesis.Genesis) (LatestBlockState, error)
-	Load() LatestBlockState
+	Load() (LatestBlockState, error)
 	Save(LatestBlockState)
 	LoadValidators(height uint64) (*types.ValidatorSet, error)
 	LoadConsensusParams(height uint64) (kproto.ConsensusParams, error)
+	Base() uint64
+	PruneStates(from, to uint64) error
 }
 
 //------------------------------------------------------------------------
@@ -62,117 +84,232 @@ func calcConsensusParamsKey(height uint64) []byte {
 	return []byte(fmt.Sprintf("consensusParamsKey:%v", height))
 }
 
+// heightKey is the key of the record of height in the validators and consensus
+// params namespaces.
+func heightKey(height uint64) []byte {
+	return []byte(strconv.FormatUint(height, 10))
+}
+
 type dbStore struct {
 	db kaidb.Database
+
+	validators *kaidb.PrefixStore // validators records, under validatorsKeyPrefix
+	params     *kaidb.PrefixStore // consensus params records, under consensusParamsKeyPrefix
+
+	valSetCache *lru.Cache // validator sets by height
 }
 
 func NewStore(db kaidb.Database) Store {
-	return &dbStore{db: db}
+	return newStore(db)
+}
+
+func newStore(db kaidb.Database) *dbStore {
+	valSetCache, _ := lru.New(valSetCacheSize)
+	return &dbStore{
+		db:          db,
+		validators:  kaidb.NewPrefixStore(db, validatorsKeyPrefix),
+		params:      kaidb.NewPrefixStore(db, consensusParamsKeyPrefix),
+		valSetCache: valSetCache,
+	}
 }
 
 // LoadStateFromDBOrGenesisDoc loads the most recent state from the database,
 // or creates a new one from the given genesisDoc and persists the result
 // to the database.
 func (s *dbStore) LoadStateFromDBOrGenesisDoc(genesisDoc *genesis.Genesis) (LatestBlockState, error) {
-	state := s.Load()
+	if err := migrate(s.db); err != nil {
+		return LatestBlockState{}, fmt.Errorf("can't migrate state store: %w", err)
+	}
+	state, err := readState(s.db, stateKey)
+	if err != nil {
+		return state, err
+	}
 
 	if state.IsEmpty() {
-		var err error
 		state, err = MakeGenesisState(genesisDoc)
 		if err != nil {
 			return state, err
 		}
 		s.Save(state)
+		return state, nil
+	}
+	// stores written by former versions saved the records and the state
+	// separately, a crash in between may have left them inconsistent.
+	if err := s.checkState(state); err != nil {
+		log.Warn("Inconsistent state store, saving the records of the state again", "height", state.LastBlockHeight,
+			"err", err)
+		s.Save(state)
 	}
 	return state, nil
 }
 
 // SaveState persists the State, the ValidatorsInfo, and the ConsensusParamsInfo to the database.
-// This flushes the writes (e.g. calls SetSync).
+// They are written at once in a batch.
 func (s *dbStore) Save(state LatestBlockState) {
-	saveState(s.db, state, stateKey)
+	s.saveState(state, stateKey)
+	if state.LastBlockHeight == 0 {
+		// the validators of the initial height may be overwritten
+		s.valSetCache.Purge()
+		return
+	}
+	s.valSetCache.Remove(recordsHeight(state) + 1)
 }
 
-func saveState(db kaidb.KeyValueStore, state LatestBlockState, key []byte) {
-	nextHeight := state.LastBlockHeight + 1
+func (s *dbStore) saveState(state LatestBlockState, key []byte) {
+	var (
+		batch      = s.db.NewBatch()
+		validators = s.validators.WrapBatch(batch)
+		params     = s.params.WrapBatch(batch)
+	)
+	nextHeight := recordsHeight(state)
 	// If first block, save validators for block 1.
-	if nextHeight == 1 {
-		nextHeight = state.InitialHeight
+	if state.LastBlockHeight == 0 {
 		// This extra logic due to validator set changes being delayed 1 block.
 		// It may get overwritten due to InitChain validator updates.
-		saveValidatorsInfo(db, nextHeight, nextHeight, state.Validators)
+		saveValidatorsInfo(validators, nextHeight, nextHeight, state.Validators)
 	}
 	// Save next validators.
-	saveValidatorsInfo(db, nextHeight+1, state.LastHeightValidatorsChanged, state.NextValidators)
+	saveValidatorsInfo(validators, nextHeight+1, state.LastHeightValidatorsChanged, state.NextValidators)
 	// Save next consensus params.
-	saveConsensusParamsInfo(db, uint64(nextHeight), state.LastHeightConsensusParamsChanged, state.ConsensusParams)
-	_ = db.Put(key, state.Bytes())
+	saveConsensusParamsInfo(params, nextHeight, state.LastHeightConsensusParamsChanged, state.ConsensusParams)
+	if err := batch.Put(key, state.Bytes()); err != nil {
+		panic(err)
+	}
+	if err := batch.Write(); err != nil {
+		panic(err)
+	}
+}
+
+// recordsHeight returns the height of the block following state, its
+// validators and consensus params records are saved along with state.
+func recordsHeight(state LatestBlockState) uint64 {
+	if state.LastBlockHeight == 0 {
+		return state.InitialHeight
+	}
+	return state.LastBlockHeight + 1
+}
+
+// checkState returns an error if the validators and consensus params records
+// saved along with state are missing or don't match it.
+func (s *dbStore) checkState(state LatestBlockState) error {
+	nextHeight := recordsHeight(state)
+	valInfo, err := loadValidatorsInfo(s.validators, nextHeight+1)
+	if err != nil {
+		return err
+	}
+	if valInfo == nil {
+		return ErrNoValSetForHeight{nextHeight + 1}
+	}
+	if valInfo.LastHeightChanged != state.LastHeightValidatorsChanged {
+		return fmt.Errorf("validators at height %d last changed at %d, expected %d", nextHeight+1,
+			valInfo.LastHeightChanged, state.LastHeightValidatorsChanged)
+	}
+	paramsInfo, err := loadConsensusParamsInfo(s.params, nextHeight)
+	if err != nil {
+		return err
+	}
+	if paramsInfo == nil {
+		return ErrNoConsensusParamsForHeight{nextHeight}
+	}
+	if paramsInfo.LastHeightChanged != state.LastHeightConsensusParamsChanged {
+		return fmt.Errorf("consensus params at height %d last changed at %d, expected %d", nextHeight,
+			paramsInfo.LastHeightChanged, state.LastHeightConsensusParamsChanged)
+	}
+	return nil
 }
 
-// LoadState loads the State from the database.
-func (s *dbStore) Load() LatestBlockState {
-	return loadState(s.db, stateKey)
+// Load loads the State from the database, the empty State is returned if
+// there is none. Returns ErrCorruptState if it can't be decoded.
+func (s *dbStore) Load() (LatestBlockState, error) {
+	return readState(s.db, stateKey)
 }
 
-func loadState(db kaidb.Database, key []byte) (state LatestBlockState) {
+// readState reads the State stored at key, the empty State is returned if
+// there is none.
+func readState(db kaidb.KeyValueReader, key []byte) (state LatestBlockState, err error) {
 	buf, _ := db.Get(key)
 
 	if len(buf) == 0 {
-		return state
+		return state, nil
 	}
-	sp := new(kstate.State)
-	err := proto.Unmarshal(buf, sp)
-
+	sp, err := decodeState(buf)
 	if err != nil {
-		// DATA HAS BEEN CORRUPTED OR THE SPEC HAS CHANGED
-		panic(fmt.Sprintf(`LoadState: Data has been corrupted or its spec has changed:
-		%v\n`, err))
+		return state, ErrCorruptState{Key: key, Err: err}
 	}
-
 	sm, err := StateFromProto(sp)
 	if err != nil {
-		panic(err)
+		return state, ErrCorruptState{Key: key, Err: err}
 	}
 	if sm.InitialHeight == 0 {
 		sm.InitialHeight = 1
 	}
-	return *sm
+	return *sm, nil
 }
 
-//-----------------------------------------------------------------------------
+func decodeState(buf []byte) (*kstate.State, error) {
+	if err := checkLegacy(buf); err != nil {
+		return nil, err
+	}
+	sp := new(kstate.State)
+	if err := proto.Unmarshal(buf, sp); err != nil {
+		return nil, err
+	}
+	return sp, checkVersion(sp.Version)
+}
 
-// ValidatorsInfo represents the latest validator set, or the last height it changed
-type ValidatorsInfo struct {
-	ValidatorSet      *types.ValidatorSet `rlp:"nil"`
-	LastHeightChanged uint64
+// checkLegacy returns ErrLegacyRecord if buf is an RLP list. Its first byte
+// would be the key of a protobuf field numbered 24 or more, which none of the
+// records have.
+func checkLegacy(buf []byte) error {
+	if len(buf) > 0 && buf[0] >= 0xc0 {
+		return ErrLegacyRecord
+	}
+	return nil
 }
 
-// Bytes serializes the ValidatorsInfo
-func (valInfo *ValidatorsInfo) Bytes() []byte {
-	b, err := rlp.EncodeToBytes(valInfo)
-	if err != nil {
-		panic(err)
+func checkVersion(version uint32) error {
+	if version > storeVersion {
+		return ErrUnknownStoreVersion{Version: version}
 	}
-	return b
+	return nil
 }
 
+//-----------------------------------------------------------------------------
+
 // LoadValidators loads the ValidatorSet for a given height.
 // Returns ErrNoValSetForHeight if the validator set can't be found for this height.
 func (s *dbStore) LoadValidators(height uint64) (*types.ValidatorSet, error) {
-	valInfo := loadValidatorsInfo(s.db, uint64(height))
+	if cached, ok := s.valSetCache.Get(height); ok {
+		return cached.(*types.ValidatorSet).Copy(), nil
+	}
+	vs, err := s.loadValidators(height)
+	if err != nil {
+		return nil, err
+	}
+	s.valSetCache.Add(height, vs.Copy())
+	return vs, nil
+}
+
+func (s *dbStore) loadValidators(height uint64) (*types.ValidatorSet, error) {
+	valInfo, err := loadValidatorsInfo(s.validators, height)
+	if err != nil {
+		return nil, err
+	}
 	if valInfo == nil {
 		return nil, ErrNoValSetForHeight{height}
 	}
 	if valInfo.ValidatorSet == nil {
 		lastStoredHeight := lastStoredHeightFor(height, valInfo.LastHeightChanged)
-		valInfo2 := loadValidatorsInfo(s.db, uint64(lastStoredHeight))
+		valInfo2, err := loadValidatorsInfo(s.validators, uint64(lastStoredHeight))
+		if err != nil {
+			return nil, err
+		}
 		if valInfo2 == nil || valInfo2.ValidatorSet == nil {
-			panic(
-				fmt.Sprintf("Couldn't find validators at height %d (height %d was originally requested)",
-					lastStoredHeight,
-					height,
-				),
-			)
+			return nil, ErrCorruptState{
+				Height: uint64(lastStoredHeight),
+				Key:    calcValidatorsKey(uint64(lastStoredHeight)),
+				Err:    fmt.Errorf("missing validators of height %d", height),
+			}
 		}
 		vs, err := types.ValidatorSetFromProto(valInfo2.ValidatorSet)
 		if err != nil {
@@ -199,38 +336,47 @@ func lastStoredHeightFor(height, lastHeightChanged uint64) int64 {
 	return kmath.MaxInt64(int64(checkpointHeight), int64(lastHeightChanged))
 }
 
+// loadValidatorsInfo returns nil if there is no record at height in db, the
+// validators namespace, and ErrCorruptState if it can't be decoded.
 // CONTRACT: Returned ValidatorsInfo can be mutated.
-func loadValidatorsInfo(db kaidb.Database, height uint64) *kstate.ValidatorsInfo {
-	buf, err := db.Get(calcValidatorsKey(height))
-	if err != nil {
-		panic(err)
-	}
+func loadValidatorsInfo(db kaidb.KeyValueReader, height uint64) (*kstate.ValidatorsInfo, error) {
+	// the records of pruned heights are missing
+	buf, _ := db.Get(heightKey(height))
 	if len(buf) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	v := new(kstate.ValidatorsInfo)
-	err = v.Unmarshal(buf)
+	v, err := decodeValidatorsInfo(buf)
 	if err != nil {
-		// DATA HAS BEEN CORRUPTED OR THE SPEC HAS CHANGED
-		panic(fmt.Sprintf(`LoadValidators: Data has been corrupted or its spec has changed:
-                %v\n`, err))
+		return nil, ErrCorruptState{Height: height, Key: calcValidatorsKey(height), Err: err}
 	}
+	return v, nil
+}
 
-	return v
+func decodeValidatorsInfo(buf []byte) (*kstate.ValidatorsInfo, error) {
+	if err := checkLegacy(buf); err != nil {
+		return nil, err
+	}
+	v := new(kstate.ValidatorsInfo)
+	if err := v.Unmarshal(buf); err != nil {
+		return nil, err
+	}
+	return v, checkVersion(v.Version)
 }
 
-// saveValidatorsInfo persists the validator set.
+// saveValidatorsInfo persists the validator set in db, the validators
+// namespace.
 //
 // `height` is the effective height for which the validator is responsible for
 // signing. It should be called from s.Save(), right before the state itself is
 // persisted.
-func saveValidatorsInfo(db kaidb.Database, height, lastHeightChanged uint64, valSet *types.ValidatorSet) {
+func saveValidatorsInfo(db kaidb.KeyValueWriter, height, lastHeightChanged uint64, valSet *types.ValidatorSet) {
 	if lastHeightChanged > height {
 		panic("LastHeightChanged cannot be greater than ValidatorsInfo height")
 	}
 	valInfo := &kstate.ValidatorsInfo{
 		LastHeightChanged: lastHeightChanged,
+		Version:           storeVersion,
 	}
 
 	if height == lastHeightChanged || height%valSetCheckpointInterval == 0 {
@@ -245,7 +391,7 @@ func saveValidatorsInfo(db kaidb.Database, height, lastHeightChanged uint64, val
 		panic(err)
 	}
 
-	err = db.Put(calcValidatorsKey(height), bz)
+	err = db.Put(heightKey(height), bz)
 	if err != nil {
 		panic(err)
 	}
@@ -257,20 +403,25 @@ func saveValidatorsInfo(db kaidb.Database, height, lastHeightChanged uint64, val
 func (s *dbStore) LoadConsensusParams(height uint64) (kproto.ConsensusParams, error) {
 	empty := kproto.ConsensusParams{}
 
-	paramsInfo, err := loadConsensusParamsInfo(s.db, height)
+	paramsInfo, err := loadConsensusParamsInfo(s.params, height)
 	if err != nil {
-		return empty, fmt.Errorf("could not find consensus params for height #%d: %w", height, err)
+		return empty, err
+	}
+	if paramsInfo == nil {
+		return empty, ErrNoConsensusParamsForHeight{height}
 	}
 
 	if paramsInfo.ConsensusParams.Equal(&empty) {
-		paramsInfo2, err := loadConsensusParamsInfo(s.db, paramsInfo.LastHeightChanged)
+		paramsInfo2, err := loadConsensusParamsInfo(s.params, paramsInfo.LastHeightChanged)
 		if err != nil {
-			return empty, fmt.Errorf(
-				"couldn't find consensus params at height %d as last changed from height %d: %w",
-				paramsInfo.LastHeightChanged,
-				height,
-				err,
-			)
+			return empty, err
+		}
+		if paramsInfo2 == nil {
+			return empty, ErrCorruptState{
+				Height: paramsInfo.LastHeightChanged,
+				Key:    calcConsensusParamsKey(paramsInfo.LastHeightChanged),
+				Err:    fmt.Errorf("missing consensus params of height %d", height),
+			}
 		}
 
 		paramsInfo = paramsInfo2
@@ -279,31 +430,41 @@ func (s *dbStore) LoadConsensusParams(height uint64) (kproto.ConsensusParams, er
 	return paramsInfo.ConsensusParams, nil
 }
 
-func loadConsensusParamsInfo(db kaidb.Database, height uint64) (*kstate.ConsensusParamsInfo, error) {
-	buf, err := db.Get(calcConsensusParamsKey(uint64(height)))
-	if err != nil {
-		return nil, err
-	}
+// loadConsensusParamsInfo returns nil if there is no record at height in db,
+// the consensus params namespace, and ErrCorruptState if it can't be decoded.
+func loadConsensusParamsInfo(db kaidb.KeyValueReader, height uint64) (*kstate.ConsensusParamsInfo, error) {
+	buf, _ := db.Get(heightKey(height))
 	if len(buf) == 0 {
 		return nil, nil
 	}
 
+	paramsInfo, err := decodeConsensusParamsInfo(buf)
+	if err != nil {
+		return nil, ErrCorruptState{Height: height, Key: calcConsensusParamsKey(height), Err: err}
+	}
+	return paramsInfo, nil
+}
+
+func decodeConsensusParamsInfo(buf []byte) (*kstate.ConsensusParamsInfo, error) {
+	if err := checkLegacy(buf); err != nil {
+		return nil, err
+	}
 	paramsInfo := new(kstate.ConsensusParamsInfo)
-	if err = paramsInfo.Unmarshal(buf); err != nil {
+	if err := paramsInfo.Unmarshal(buf); err != nil {
 		return nil, err
 	}
-	// TODO: ensure that buf is completely read.
-
-	return paramsInfo, nil
+	return paramsInfo, checkVersion(paramsInfo.Version)
 }
 
-// saveConsensusParamsInfo persists the consensus params for the next block to disk.
+// saveConsensusParamsInfo persists the consensus params for the next block in db,
+// the consensus params namespace.
 // It should be called from s.Save(), right before the state itself is persisted.
 // If the consensus params did not change after processing the latest block,
 // only the last height for which they changed is persisted.
-func saveConsensusParamsInfo(db kaidb.Database, nextHeight, changeHeight uint64, params kproto.ConsensusParams) {
+func saveConsensusParamsInfo(db kaidb.KeyValueWriter, nextHeight, changeHeight uint64, params kproto.ConsensusParams) {
 	paramsInfo := &kstate.ConsensusParamsInfo{
 		LastHeightChanged: changeHeight,
+		Version:           storeVersion,
 	}
 
 	if changeHeight == nextHeight {
@@ -314,12 +475,244 @@ func saveConsensusParamsInfo(db kaidb.Database, nextHeight, changeHeight uint64,
 	if err != nil {
 		panic(err)
 	}
-	err = db.Put(calcConsensusParamsKey(nextHeight), bz)
+	err = db.Put(heightKey(nextHeight), bz)
 	if err != nil {
 		panic(err)
 	}
 }
 
+//-----------------------------------------------------------------------------
+
+// migrate upgrades the state, validators and consensus params records written
+// by former versions to storeVersion. It's done once, storeVersionKey is set
+// when all the records are upgraded.
+func migrate(db kaidb.Database) error {
+	if buf, _ := db.Get(storeVersionKey); len(buf) == 4 && binary.BigEndian.Uint32(buf) >= storeVersion {
+		return nil
+	}
+	var (
+		batch = db.NewBatch()
+		count int
+	)
+	upgrade := func(key, buf []byte) error {
+		data, err := upgradeRecord(key, buf)
+		if err != nil {
+			return ErrCorruptState{Height: keyHeight(key), Key: common.CopyBytes(key), Err: err}
+		}
+		if data == nil {
+			return nil
+		}
+		if err := batch.Put(common.CopyBytes(key), data); err != nil {
+			return err
+		}
+		count++
+		if batch.ValueSize() >= kaidb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	}
+	if buf, _ := db.Get(stateKey); len(buf) > 0 {
+		if err := upgrade(stateKey, buf); err != nil {
+			return err
+		}
+	}
+	for _, prefix := range [][]byte{validatorsKeyPrefix, consensusParamsKeyPrefix} {
+		iter := db.NewIterator(prefix, nil)
+		for iter.Next() {
+			if err := upgrade(iter.Key(), iter.Value()); err != nil {
+				iter.Release()
+				return err
+			}
+		}
+		err := iter.Error()
+		iter.Release()
+		if err != nil {
+			return err
+		}
+	}
+	version := make([]byte, 4)
+	binary.BigEndian.PutUint32(version, storeVersion)
+	if err := batch.Put(storeVersionKey, version); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Info("Migrated state store", "version", storeVersion, "records", count)
+	}
+	return nil
+}
+
+// upgradeRecord returns the record stored at key in the storeVersion layout,
+// or nil if it is already in this layout.
+func upgradeRecord(key, buf []byte) ([]byte, error) {
+	switch {
+	case bytes.Equal(key, stateKey):
+		sp, err := decodeState(buf)
+		if err != nil || sp.Version == storeVersion {
+			return nil, err
+		}
+		sp.Version = storeVersion
+		return proto.Marshal(sp)
+	case bytes.HasPrefix(key, validatorsKeyPrefix):
+		v, err := decodeValidatorsInfo(buf)
+		if err != nil || v.Version == storeVersion {
+			return nil, err
+		}
+		v.Version = storeVersion
+		return v.Marshal()
+	default:
+		paramsInfo, err := decodeConsensusParamsInfo(buf)
+		if err != nil || paramsInfo.Version == storeVersion {
+			return nil, err
+		}
+		paramsInfo.Version = storeVersion
+		return paramsInfo.Marshal()
+	}
+}
+
+//-----------------------------------------------------------------------------
+
+// Base returns the lowest height whose validators and consensus params records
+// are kept, 0 if the store has never been pruned.
+func (s *dbStore) Base() uint64 {
+	buf, _ := s.db.Get(baseKey)
+	if len(buf) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(buf)
+}
+
+// PruneStates deletes the validators and consensus params records of the
+// heights from from to to, to excluded. The checkpoints and the records of the
+// last changes the heights from to onwards rely on are kept, with the full
+// validator set or consensus params.
+func (s *dbStore) PruneStates(from, to uint64) error {
+	if from == 0 || to == 0 {
+		return fmt.Errorf("from height %d and to height %d must be greater than 0", from, to)
+	}
+	if from >= to {
+		return fmt.Errorf("from height %d must be lower than to height %d", from, to)
+	}
+	valInfo, err := loadValidatorsInfo(s.validators, to)
+	if err != nil {
+		return err
+	}
+	if valInfo == nil {
+		return ErrNoValSetForHeight{to}
+	}
+	paramsInfo, err := loadConsensusParamsInfo(s.params, to)
+	if err != nil {
+		return err
+	}
+	if paramsInfo == nil {
+		return ErrNoConsensusParamsForHeight{to}
+	}
+
+	keepVals := make(map[uint64]bool)
+	if valInfo.ValidatorSet == nil {
+		keepVals[valInfo.LastHeightChanged] = true
+		keepVals[uint64(lastStoredHeightFor(to, valInfo.LastHeightChanged))] = true
+	}
+	keepParams := make(map[uint64]bool)
+	if paramsInfo.ConsensusParams.Equal(&kproto.ConsensusParams{}) {
+		keepParams[paramsInfo.LastHeightChanged] = true
+	}
+
+	// Heights are pruned downwards, so that the records a kept height is
+	// completed from are still there.
+	var (
+		batch      = s.db.NewBatch()
+		validators = s.validators.WrapBatch(batch)
+		params     = s.params.WrapBatch(batch)
+	)
+	for h := to - 1; h >= from; h-- {
+		if keepVals[h] {
+			if err := s.keepValidatorsInfo(validators, h); err != nil {
+				return err
+			}
+		} else if err := validators.Delete(heightKey(h)); err != nil {
+			return err
+		}
+		if keepParams[h] {
+			if err := s.keepConsensusParamsInfo(params, h); err != nil {
+				return err
+			}
+		} else if err := params.Delete(heightKey(h)); err != nil {
+			return err
+		}
+		s.valSetCache.Remove(h)
+
+		if batch.ValueSize() >= kaidb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if base := s.Base(); to > base {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, to)
+		if err := batch.Put(baseKey, b); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// keepValidatorsInfo writes the validators record of the kept height with the
+// full validator set if it is only a pointer to the last change, batch is a
+// batch of the validators namespace.
+func (s *dbStore) keepValidatorsInfo(batch kaidb.KeyValueWriter, height uint64) error {
+	if v, err := loadValidatorsInfo(s.validators, height); err == nil && v != nil && v.ValidatorSet != nil {
+		return nil
+	}
+	vs, err := s.loadValidators(height)
+	if err != nil {
+		return err
+	}
+	pv, err := vs.ToProto()
+	if err != nil {
+		return err
+	}
+	bz, err := (&kstate.ValidatorsInfo{ValidatorSet: pv, LastHeightChanged: height, Version: storeVersion}).Marshal()
+	if err != nil {
+		return err
+	}
+	return batch.Put(heightKey(height), bz)
+}
+
+// keepConsensusParamsInfo writes the consensus params record of the kept height
+// with the full consensus params if it is only a pointer to the last change,
+// batch is a batch of the consensus params namespace.
+func (s *dbStore) keepConsensusParamsInfo(batch kaidb.KeyValueWriter, height uint64) error {
+	paramsInfo, err := loadConsensusParamsInfo(s.params, height)
+	if err == nil && paramsInfo != nil && !paramsInfo.ConsensusParams.Equal(&kproto.ConsensusParams{}) {
+		return nil
+	}
+	params, err := s.LoadConsensusParams(height)
+	if err != nil {
+		return err
+	}
+	bz, err := (&kstate.ConsensusParamsInfo{ConsensusParams: params, LastHeightChanged: height, Version: storeVersion}).Marshal()
+	if err != nil {
+		return err
+	}
+	return batch.Put(heightKey(height), bz)
+}
+
+// keyHeight returns the height of a validators or consensus params record key,
+// 0 for the state key.
+func keyHeight(key []byte) uint64 {
+	i := bytes.IndexByte(key, ':')
+	height, _ := strconv.ParseUint(string(key[i+1:]), 10, 64)
+	return height
+}
+
 // MakeGenesisState creates state from types.GenesisDoc.
 func MakeGenesisState(genDoc *genesis.Genesis) (LatestBlockState, error) {
 	if genDoc.InitialHeight == 0 {
@@ -337,7 +730,11 @@ func MakeGenesisState(genDoc *genesis.Genesis) (LatestBlockState, error) {
 			if val.StartWithGenesis {
 				tokens, _ := big.NewInt(0).SetString(val.SelfDelegate, 10)
 				power := tokens.Div(tokens, configs.PowerReduction)
-				validators = append(validators, types.NewValidator(common.HexToAddress(val.Address), power.Int64()))
+				validator := types.NewValidator(common.HexToAddress(val.Address), power.Int64())
+				if val.BLSPubKey != "" {
+					validator.BLSPubKey = common.FromHex(val.BLSPubKey)
+				}
+				validators = append(validators, validator)
 			}
 		}
 		validatorSet = types.NewValidatorSet(validators)
@@ -19,10 +19,17 @@
 package cstate_test
 
 import (
+	"errors"
+	"fmt"
 	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
 
 	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
 	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+	kstate "github.com/kardiachain/go-kardia/proto/kardiachain/state"
 	"github.com/kardiachain/go-kardia/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -49,3 +56,224 @@ func TestStoreLoadValidators(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotZero(t, loadedVals.Size())
 }
+
+func TestStoreLoadValidatorsCache(t *testing.T) {
+	stateStore := cstate.NewStore(memorydb.New())
+	vals, _ := types.RandValidatorSet(4, 10)
+	state := cstate.LatestBlockState{
+		InitialHeight:                    1,
+		LastBlockHeight:                  5,
+		Validators:                       vals,
+		NextValidators:                   vals.CopyIncrementProposerPriority(1),
+		LastHeightValidatorsChanged:      7,
+		ConsensusParams:                  *types.DefaultConsensusParams(),
+		LastHeightConsensusParamsChanged: 6,
+	}
+	stateStore.Save(state)
+
+	loaded, err := stateStore.LoadValidators(7)
+	require.NoError(t, err)
+	// the cached set isn't mutated through a loaded one
+	loaded.IncrementProposerPriority(1)
+	cached, err := stateStore.LoadValidators(7)
+	require.NoError(t, err)
+	assert.Equal(t, state.NextValidators.GetProposer(), cached.GetProposer())
+
+	// saving the validators of a height again invalidates them
+	others, _ := types.RandValidatorSet(4, 10)
+	state.NextValidators = others
+	stateStore.Save(state)
+	loaded, err = stateStore.LoadValidators(7)
+	require.NoError(t, err)
+	assert.Equal(t, others.Hash(), loaded.Hash())
+}
+
+func BenchmarkLoadValidators(b *testing.B) {
+	const valSetSize = 100
+
+	stateDB := memorydb.New()
+	stateStore := cstate.NewStore(stateDB)
+	vals, _ := types.RandValidatorSet(valSetSize, 10)
+	cstate.SaveValidatorsInfo(stateDB, 1, 1, vals)
+
+	for i := uint64(10); i < 10000000000; i *= 10 { // 10, 100, 1000, ...
+		i := i
+		cstate.SaveValidatorsInfo(stateDB, i, 1, vals)
+
+		b.Run(fmt.Sprintf("height=%d", i), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				_, err := stateStore.LoadValidators(i)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreLoadConsensusParams(t *testing.T) {
+	stateStore := cstate.NewStore(memorydb.New())
+	val, _ := types.RandValidator(true, 10)
+	vals := types.NewValidatorSet([]*types.Validator{val})
+
+	params := *types.DefaultConsensusParams()
+	params.Timeout.Propose = 5 * time.Second
+	params.Timeout.Commit = 2 * time.Second
+	stateStore.Save(cstate.LatestBlockState{
+		InitialHeight:                    1,
+		Validators:                       vals,
+		NextValidators:                   vals.CopyIncrementProposerPriority(1),
+		LastHeightValidatorsChanged:      1,
+		ConsensusParams:                  params,
+		LastHeightConsensusParamsChanged: 1,
+	})
+
+	loaded, err := stateStore.LoadConsensusParams(1)
+	require.NoError(t, err)
+	assert.Equal(t, params.Timeout, loaded.Timeout)
+	state, err := stateStore.Load()
+	require.NoError(t, err)
+	assert.Equal(t, params.Timeout, state.ConsensusParams.Timeout)
+}
+
+func TestStoreMigrate(t *testing.T) {
+	stateDB := memorydb.New()
+	stateStore := cstate.NewStore(stateDB)
+	val, _ := types.RandValidator(true, 10)
+	vals := types.NewValidatorSet([]*types.Validator{val})
+	state := cstate.LatestBlockState{
+		InitialHeight:                    1,
+		LastBlockHeight:                  5,
+		Validators:                       vals,
+		NextValidators:                   vals.CopyIncrementProposerPriority(1),
+		LastHeightValidatorsChanged:      1,
+		ConsensusParams:                  *types.DefaultConsensusParams(),
+		LastHeightConsensusParamsChanged: 1,
+	}
+
+	// records written before versioning have version 0
+	sp, err := state.ToProto()
+	require.NoError(t, err)
+	bz, err := proto.Marshal(sp)
+	require.NoError(t, err)
+	require.NoError(t, stateDB.Put([]byte("stateKey"), bz))
+	pv, err := vals.ToProto()
+	require.NoError(t, err)
+	bz, err = (&kstate.ValidatorsInfo{ValidatorSet: pv, LastHeightChanged: 1}).Marshal()
+	require.NoError(t, err)
+	require.NoError(t, stateDB.Put([]byte("validatorsKey:1"), bz))
+	bz, err = (&kstate.ConsensusParamsInfo{ConsensusParams: state.ConsensusParams, LastHeightChanged: 1}).Marshal()
+	require.NoError(t, err)
+	require.NoError(t, stateDB.Put([]byte("consensusParamsKey:1"), bz))
+
+	loaded, err := stateStore.LoadStateFromDBOrGenesisDoc(nil)
+	require.NoError(t, err)
+	assert.Equal(t, state.LastBlockHeight, loaded.LastBlockHeight)
+
+	bz, err = stateDB.Get([]byte("stateKey"))
+	require.NoError(t, err)
+	require.NoError(t, proto.Unmarshal(bz, sp))
+	assert.EqualValues(t, cstate.StoreVersion, sp.Version)
+	bz, err = stateDB.Get([]byte("validatorsKey:1"))
+	require.NoError(t, err)
+	valInfo := new(kstate.ValidatorsInfo)
+	require.NoError(t, valInfo.Unmarshal(bz))
+	assert.EqualValues(t, cstate.StoreVersion, valInfo.Version)
+	bz, err = stateDB.Get([]byte("consensusParamsKey:1"))
+	require.NoError(t, err)
+	paramsInfo := new(kstate.ConsensusParamsInfo)
+	require.NoError(t, paramsInfo.Unmarshal(bz))
+	assert.EqualValues(t, cstate.StoreVersion, paramsInfo.Version)
+	loadedVals, err := stateStore.LoadValidators(1)
+	require.NoError(t, err)
+	assert.Equal(t, vals.Hash(), loadedVals.Hash())
+
+	// records of a newer version are rejected
+	sp.Version = cstate.StoreVersion + 1
+	bz, err = proto.Marshal(sp)
+	require.NoError(t, err)
+	require.NoError(t, stateDB.Put([]byte("stateKey"), bz))
+	_, err = stateStore.LoadStateFromDBOrGenesisDoc(nil)
+	assert.True(t, errors.Is(err, cstate.ErrUnknownStoreVersion{Version: cstate.StoreVersion + 1}))
+
+	// legacy RLP records are detected
+	bz, err = rlp.EncodeToBytes([]uint64{state.LastBlockHeight})
+	require.NoError(t, err)
+	require.NoError(t, stateDB.Put([]byte("stateKey"), bz))
+	_, err = stateStore.LoadStateFromDBOrGenesisDoc(nil)
+	assert.True(t, errors.Is(err, cstate.ErrLegacyRecord))
+}
+
+func TestStoreLoadStateRepairsRecords(t *testing.T) {
+	stateDB := memorydb.New()
+	stateStore := cstate.NewStore(stateDB)
+	val, _ := types.RandValidator(true, 10)
+	vals := types.NewValidatorSet([]*types.Validator{val})
+	state := cstate.LatestBlockState{
+		InitialHeight:                    1,
+		LastBlockHeight:                  5,
+		Validators:                       vals,
+		NextValidators:                   vals.CopyIncrementProposerPriority(1),
+		LastHeightValidatorsChanged:      7,
+		ConsensusParams:                  *types.DefaultConsensusParams(),
+		LastHeightConsensusParamsChanged: 6,
+	}
+	stateStore.Save(state)
+
+	// a crash of a former version left the records of the state unwritten
+	require.NoError(t, stateDB.Delete([]byte("validatorsKey:7")))
+	require.NoError(t, stateDB.Delete([]byte("consensusParamsKey:6")))
+	_, err := stateStore.LoadConsensusParams(6)
+	require.Error(t, err)
+
+	loaded, err := stateStore.LoadStateFromDBOrGenesisDoc(nil)
+	require.NoError(t, err)
+	assert.Equal(t, state.LastBlockHeight, loaded.LastBlockHeight)
+	loadedVals, err := stateStore.LoadValidators(7)
+	require.NoError(t, err)
+	assert.Equal(t, state.NextValidators.Hash(), loadedVals.Hash())
+	_, err = stateStore.LoadConsensusParams(6)
+	require.NoError(t, err)
+}
+
+func TestStorePruneStates(t *testing.T) {
+	stateStore := cstate.NewStore(memorydb.New())
+	vals, _ := types.RandValidatorSet(4, 10)
+	state := cstate.LatestBlockState{
+		InitialHeight:                    1,
+		Validators:                       vals,
+		NextValidators:                   vals.CopyIncrementProposerPriority(1),
+		LastHeightValidatorsChanged:      1,
+		ConsensusParams:                  *types.DefaultConsensusParams(),
+		LastHeightConsensusParamsChanged: 1,
+	}
+	for h := uint64(0); h <= 20; h++ {
+		state.LastBlockHeight = h
+		stateStore.Save(state)
+	}
+	_, err := stateStore.LoadValidators(15)
+	require.NoError(t, err)
+
+	require.Error(t, stateStore.PruneStates(10, 10))
+	require.Error(t, stateStore.PruneStates(1, 30))
+	require.NoError(t, stateStore.PruneStates(1, 10))
+	assert.EqualValues(t, 10, stateStore.Base())
+
+	for h := uint64(2); h < 10; h++ {
+		_, err = stateStore.LoadValidators(h)
+		assert.Equal(t, cstate.ErrNoValSetForHeight{Height: h}, err)
+		_, err = stateStore.LoadConsensusParams(h)
+		assert.Error(t, err)
+	}
+	// the heights from 10 rely on the records of the last changes, which are kept
+	for h := uint64(10); h <= 22; h++ {
+		loaded, err := stateStore.LoadValidators(h)
+		require.NoError(t, err)
+		assert.Equal(t, vals.Hash(), loaded.Hash())
+	}
+	for h := uint64(10); h <= 21; h++ {
+		params, err := stateStore.LoadConsensusParams(h)
+		require.NoError(t, err)
+		assert.Equal(t, state.ConsensusParams, params)
+	}
+}
@@ -77,6 +77,9 @@ func validateBlock(evidencePool EvidencePool, store Store, state LatestBlockStat
 			return ErrLastCommitSig
 		}
 	} else {
+		if block.LastCommit().IsAggregated() && !state.ConsensusParams.Validator.AggregatePrecommits {
+			return ErrAggregatedLastCommit
+		}
 		// LastCommit.Signatures length is checked in VerifyCommit.
 		if err := state.LastValidators.VerifyCommit(
 			state.ChainID, state.LastBlockID, block.Height()-1, block.LastCommit()); err != nil {
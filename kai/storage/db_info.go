@@ -19,12 +19,55 @@
 package storage
 
 import (
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/kaidb/badger"
 	"github.com/kardiachain/go-kardia/kai/kaidb/leveldb"
 	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/kai/kaidb/pebble"
 	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
 	"github.com/kardiachain/go-kardia/types"
 )
 
+// Key-value store backends of a persistent database.
+const (
+	LevelDBBackend = "leveldb"
+	PebbleBackend  = "pebble"
+	BadgerBackend  = "badger"
+)
+
+// Backends lists the supported key-value store backends.
+var Backends = []string{LevelDBBackend, PebbleBackend, BadgerBackend}
+
+// NewKeyValueStore opens the persistent key-value store of the given backend in
+// directory file, creating it if it doesn't exist. An empty backend selects
+// LevelDB. Cache is in megabytes; handles is the number of open files, which
+// is ignored by Badger.
+func NewKeyValueStore(backend string, file string, cache int, handles int) (kaidb.KeyValueStore, error) {
+	switch backend {
+	case "", LevelDBBackend:
+		db, err := leveldb.New(file, cache, handles)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	case PebbleBackend:
+		db, err := pebble.New(file, cache, handles)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	case BadgerBackend:
+		db, err := badger.New(file, cache)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+	return nil, fmt.Errorf("unknown database backend %q", backend)
+}
+
 // DbInfo is used to start new database
 type DbInfo interface {
 	Name() string
@@ -59,6 +102,44 @@ func (info *LevelDbInfo) Start() (types.StoreDB, error) {
 	return kvstore.NewStoreDB(db), nil
 }
 
+// KeyValueDbInfo implements DbInfo to start chain using any backend supported
+// by NewKeyValueStore.
+type KeyValueDbInfo struct {
+	Backend   string
+	ChainData string
+	DbCaches  int
+	DbHandles int
+}
+
+// NewDbInfo returns the DbInfo of a chain database stored in chainData with
+// the given backend.
+func NewDbInfo(backend string, chainData string, dbCaches, dbHandles int) (DbInfo, error) {
+	switch backend {
+	case "", LevelDBBackend:
+		return NewLevelDbInfo(chainData, dbCaches, dbHandles), nil
+	case PebbleBackend, BadgerBackend:
+		return &KeyValueDbInfo{
+			Backend:   backend,
+			ChainData: chainData,
+			DbCaches:  dbCaches,
+			DbHandles: dbHandles,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown database backend %q", backend)
+}
+
+func (info *KeyValueDbInfo) Name() string {
+	return info.Backend
+}
+
+func (info *KeyValueDbInfo) Start() (types.StoreDB, error) {
+	db, err := NewKeyValueStore(info.Backend, info.ChainData, info.DbCaches, info.DbHandles)
+	if err != nil {
+		return nil, err
+	}
+	return kvstore.NewStoreDB(db), nil
+}
+
 func NewMemoryDatabase() types.StoreDB {
 	return kvstore.NewStoreDB(memorydb.New())
 }
@@ -226,6 +226,14 @@ func ReadCommit(db kaidb.Reader, height uint64) *types.Commit {
 	return commit
 }
 
+// WriteCommit stores the commit at a given height. Commits are otherwise
+// only written along with the block of the next height.
+func WriteCommit(db kaidb.Writer, height uint64, commit *types.Commit) {
+	if err := db.Put(commitKey(height), mustEncode(commit.ToProto())); err != nil {
+		panic(fmt.Errorf("failed to store block commit err: %s", err))
+	}
+}
+
 // DeleteBody removes all block body data associated with a hash.
 func DeleteBody(db kaidb.KeyValueWriter, hash common.Hash, height uint64) {
 	if err := db.Delete(blockBodyKey(height, hash)); err != nil {
@@ -250,6 +258,19 @@ func DeleteCanonicalHash(db kaidb.KeyValueWriter, number uint64) {
 	}
 }
 
+// ReadBlockInfoRLP retrieves the block info belonging to a block in RLP encoding.
+func ReadBlockInfoRLP(db kaidb.Reader, hash common.Hash, height uint64) rlp.RawValue {
+	data, _ := db.Get(blockInfoKey(height, hash))
+	return data
+}
+
+// WriteBlockInfoRLP stores the RLP encoded block info belonging to a block.
+func WriteBlockInfoRLP(db kaidb.Writer, hash common.Hash, height uint64, data rlp.RawValue) {
+	if err := db.Put(blockInfoKey(height, hash), data); err != nil {
+		log.Crit("Failed to store block receipts", "err", err)
+	}
+}
+
 // ReadBlockInfo retrieves blockReward, gasUsed and all the transaction receipts belonging to a block.
 func ReadBlockInfo(db kaidb.Reader, hash common.Hash, number uint64, config *configs.ChainConfig) *types.BlockInfo {
 	// Retrieve the flattened receipt slice
@@ -308,6 +329,44 @@ func WriteTxLookupEntries(db kaidb.Writer, block *types.Block) {
 	}
 }
 
+// WriteAddressTxIndex indexes every transaction of a block under its sender
+// and recipient, enabling address based transaction lookups.
+func WriteAddressTxIndex(db kaidb.KeyValueWriter, block *types.Block, signer types.Signer) {
+	for i, tx := range block.Transactions() {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			log.Error("Failed to derive transaction sender", "hash", tx.Hash(), "err", err)
+			continue
+		}
+		if err := db.Put(addrTxKey(from, block.Height(), uint32(i)), tx.Hash().Bytes()); err != nil {
+			log.Crit("Failed to store address transaction index", "err", err)
+		}
+		if to := tx.To(); to != nil && *to != from {
+			if err := db.Put(addrTxKey(*to, block.Height(), uint32(i)), tx.Hash().Bytes()); err != nil {
+				log.Crit("Failed to store address transaction index", "err", err)
+			}
+		}
+	}
+}
+
+// ReadAddressTxHashes returns the hashes of the transactions sent or received
+// by addr, latest first, skipping the first offset ones and returning at most
+// limit of them.
+func ReadAddressTxHashes(db kaidb.Iteratee, addr common.Address, offset, limit int) []common.Hash {
+	it := db.NewIterator(addrTxPrefixKey(addr), nil)
+	defer it.Release()
+
+	var hashes []common.Hash
+	for it.Next() && len(hashes) < limit {
+		if offset > 0 {
+			offset--
+			continue
+		}
+		hashes = append(hashes, common.BytesToHash(it.Value()))
+	}
+	return hashes
+}
+
 // DeleteTxLookupEntry removes all transaction data associated with a hash.
 func DeleteTxLookupEntry(db kaidb.KeyValueWriter, hash common.Hash) error {
 	if err := db.Delete(txLookupKey(hash)); err != nil {
@@ -19,6 +19,7 @@
 package kvstore
 
 import (
+	"math/big"
 	"testing"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 
 	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
 	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
 	"github.com/kardiachain/go-kardia/types"
 )
 
@@ -151,3 +153,46 @@ func TestCanonicalMappingStorage(t *testing.T) {
 		t.Fatalf("Deleted canonical mapping returned: %v", entry)
 	}
 }
+
+// Tests that transactions are indexed under their sender and recipient.
+func TestAddressTxIndex(t *testing.T) {
+	db := memorydb.New()
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x1337")
+
+	var hashes []common.Hash
+	for height := uint64(1); height <= 3; height++ {
+		var txs []*types.Transaction
+		for i := uint64(0); i < 2; i++ {
+			tx, err := types.SignTx(types.HomesteadSigner{}, types.NewTransaction(height*2+i, to, new(big.Int), 0, new(big.Int), nil), key)
+			if err != nil {
+				t.Fatalf("Failed to sign tx: %v", err)
+			}
+			txs = append(txs, tx)
+			hashes = append(hashes, tx.Hash())
+		}
+		block := types.NewBlock(&types.Header{Height: height}, txs, nil, nil)
+		WriteAddressTxIndex(db, block, types.HomesteadSigner{})
+	}
+
+	// Latest txs come first
+	for _, addr := range []common.Address{from, to} {
+		got := ReadAddressTxHashes(db, addr, 0, 10)
+		if len(got) != len(hashes) {
+			t.Fatalf("Indexed txs mismatch: have %d, want %d", len(got), len(hashes))
+		}
+		for i, hash := range got {
+			if want := hashes[len(hashes)-1-i]; hash != want {
+				t.Fatalf("Tx %d mismatch: have %x, want %x", i, hash, want)
+			}
+		}
+	}
+	// Paging
+	if got := ReadAddressTxHashes(db, from, 4, 10); len(got) != 2 || got[0] != hashes[1] || got[1] != hashes[0] {
+		t.Fatalf("Unexpected last page: %x", got)
+	}
+	if got := ReadAddressTxHashes(db, common.HexToAddress("0xdead"), 0, 10); len(got) != 0 {
+		t.Fatalf("Unexpected txs for unknown address: %x", got)
+	}
+}
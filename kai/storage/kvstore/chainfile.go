@@ -0,0 +1,378 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kvstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto/sha3"
+	"github.com/kardiachain/go-kardia/lib/log"
+	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// A chain file is a stream of consecutive blocks with their commits. It
+// starts with chainFileMagic, the version of the format and the genesis hash
+// of the chain, every block is a recordBlock byte followed by the length
+// prefixed protobuf encodings of the block and of its commit, and the stream
+// ends with a recordEnd byte, the number of blocks and the keccak256 checksum
+// of the stream before it. Every block links to the hash of the previous one.
+const (
+	chainFileVersion = 1
+
+	recordEnd   byte = 0
+	recordBlock byte = 1
+
+	// maxChainRecordSize bounds the blocks and commits read from a chain file.
+	maxChainRecordSize = 64 * 1024 * 1024
+)
+
+var chainFileMagic = []byte("kai-chain")
+
+var (
+	// ErrBadChainFile is returned when a chain file is malformed, truncated,
+	// doesn't match its checksum or its blocks don't link to each other.
+	ErrBadChainFile = errors.New("bad chain file")
+	// ErrPrunedHeight is returned when exporting blocks which are pruned.
+	ErrPrunedHeight = errors.New("height is pruned")
+)
+
+// ChainFileStats is the range of the blocks of a chain file, and its
+// checksum.
+type ChainFileStats struct {
+	From     uint64             `json:"from"`
+	To       uint64             `json:"to"`
+	Count    uint64             `json:"count"`
+	Size     common.StorageSize `json:"size"`
+	Checksum common.Hash        `json:"checksum"`
+}
+
+// ExportChain writes the blocks of db from height from to height to, or up to
+// the head block if to is 0, with their commits to w. The commit of the head
+// block is the one seen by the node.
+func ExportChain(db kaidb.Reader, from, to uint64, w io.Writer) (*ChainFileStats, error) {
+	head := ReadHeaderHeight(db, ReadHeadBlockHash(db))
+	if head == nil {
+		return nil, errors.New("no head block")
+	}
+	if from == 0 {
+		from = 1
+	}
+	if to == 0 || to > *head {
+		to = *head
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid range %d-%d, head is %d", from, to, *head)
+	}
+	if base := ReadBlockStoreBase(db); from < base {
+		return nil, fmt.Errorf("%w: %d, the block store starts at %d", ErrPrunedHeight, from, base)
+	}
+	var (
+		bw     = bufio.NewWriter(w)
+		hasher = sha3.NewKeccak256()
+		out    = io.MultiWriter(bw, hasher)
+		stats  = &ChainFileStats{From: from, To: to}
+		start  = time.Now()
+		logged = time.Now()
+	)
+	if err := writeChainFileHeader(out, ReadCanonicalHash(db, 0)); err != nil {
+		return nil, err
+	}
+	for height := from; height <= to; height++ {
+		block := ReadBlock(db, height)
+		if block == nil {
+			return nil, fmt.Errorf("missing block %d", height)
+		}
+		commit := ReadCommit(db, height)
+		if commit == nil {
+			commit = ReadSeenCommit(db, height)
+		}
+		if commit == nil {
+			return nil, fmt.Errorf("missing commit of block %d", height)
+		}
+		n, err := writeChainBlock(out, block, commit)
+		if err != nil {
+			return nil, err
+		}
+		stats.Count++
+		stats.Size += common.StorageSize(n)
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Exporting chain", "height", height, "to", to, "size", stats.Size,
+				"elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	var trailer [1 + binary.MaxVarintLen64]byte
+	trailer[0] = recordEnd
+	n := 1 + binary.PutUvarint(trailer[1:], stats.Count)
+	if _, err := out.Write(trailer[:n]); err != nil {
+		return nil, err
+	}
+	stats.Checksum = common.BytesToHash(hasher.Sum(nil))
+	if _, err := bw.Write(stats.Checksum.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	log.Info("Exported chain", "from", from, "to", to, "size", stats.Size,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return stats, nil
+}
+
+// ChainFileReader reads the blocks of a chain file one at a time, checking
+// that they link to each other and that their commits are theirs. The count
+// and checksum of the file are only checked after its last block, a reader
+// should not trust more than the blocks it verifies on its own until then.
+type ChainFileReader struct {
+	br      *bufio.Reader
+	in      *hashingReader
+	hasher  hash.Hash
+	genesis common.Hash
+
+	last  *types.Block
+	count uint64
+	done  bool
+}
+
+// NewChainFileReader reads the header of the chain file read from r.
+func NewChainFileReader(r io.Reader) (*ChainFileReader, error) {
+	hasher := sha3.NewKeccak256()
+	br := bufio.NewReader(r)
+	cr := &ChainFileReader{br: br, in: &hashingReader{r: br, h: hasher}, hasher: hasher}
+	genesis, err := readChainFileHeader(cr.in)
+	if err != nil {
+		return nil, err
+	}
+	cr.genesis = genesis
+	return cr, nil
+}
+
+// Genesis returns the genesis hash of the chain of the file.
+func (cr *ChainFileReader) Genesis() common.Hash {
+	return cr.genesis
+}
+
+// Next returns the next block of the file with its commit, or io.EOF once the
+// end of the file is read and checked.
+func (cr *ChainFileReader) Next() (*types.Block, *types.Commit, error) {
+	if cr.done {
+		return nil, nil, io.EOF
+	}
+	kind, err := cr.in.ReadByte()
+	if err != nil {
+		return nil, nil, chainFileError(err)
+	}
+	switch kind {
+	case recordEnd:
+		if err := cr.readEnd(); err != nil {
+			return nil, nil, err
+		}
+		cr.done = true
+		return nil, nil, io.EOF
+	case recordBlock:
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown record %d", ErrBadChainFile, kind)
+	}
+
+	data, err := readChainBytes(cr.in)
+	if err != nil {
+		return nil, nil, err
+	}
+	pbb := new(kproto.Block)
+	if err := proto.Unmarshal(data, pbb); err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid block: %v", ErrBadChainFile, err)
+	}
+	block, err := types.BlockFromProto(pbb)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid block: %v", ErrBadChainFile, err)
+	}
+	if data, err = readChainBytes(cr.in); err != nil {
+		return nil, nil, err
+	}
+	pbc := new(kproto.Commit)
+	if err := proto.Unmarshal(data, pbc); err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid commit of block %d: %v", ErrBadChainFile, block.Height(), err)
+	}
+	commit, err := types.CommitFromProto(pbc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid commit of block %d: %v", ErrBadChainFile, block.Height(), err)
+	}
+
+	blockHash := block.Hash()
+	if commit.Height != block.Height() || !commit.BlockID.Hash.Equal(blockHash) {
+		return nil, nil, fmt.Errorf("%w: commit of block %d/%v is for %d/%v", ErrBadChainFile, block.Height(),
+			blockHash.Hex(), commit.Height, commit.BlockID.Hash.Hex())
+	}
+	if cr.last != nil {
+		if block.Height() != cr.last.Height()+1 {
+			return nil, nil, fmt.Errorf("%w: block %d follows block %d", ErrBadChainFile, block.Height(), cr.last.Height())
+		}
+		if parent := block.Header().LastBlockID.Hash; !parent.Equal(cr.last.Hash()) {
+			return nil, nil, fmt.Errorf("%w: block %d links to %v instead of %v", ErrBadChainFile, block.Height(),
+				parent.Hex(), cr.last.Hash().Hex())
+		}
+	}
+	cr.last = block
+	cr.count++
+	return block, commit, nil
+}
+
+// readEnd checks the number of blocks and the checksum of the file.
+func (cr *ChainFileReader) readEnd() error {
+	count, err := binary.ReadUvarint(cr.in)
+	if err != nil {
+		return chainFileError(err)
+	}
+	if count != cr.count {
+		return fmt.Errorf("%w: %d blocks read, %d expected", ErrBadChainFile, cr.count, count)
+	}
+	sum := common.BytesToHash(cr.hasher.Sum(nil))
+	var checksum common.Hash
+	if _, err := io.ReadFull(cr.br, checksum[:]); err != nil {
+		return chainFileError(err)
+	}
+	if checksum != sum {
+		return fmt.Errorf("%w: checksum %v, expected %v", ErrBadChainFile, sum.Hex(), checksum.Hex())
+	}
+	return nil
+}
+
+func writeChainFileHeader(w io.Writer, genesis common.Hash) error {
+	var header [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(header[:], chainFileVersion)
+	if _, err := w.Write(chainFileMagic); err != nil {
+		return err
+	}
+	if _, err := w.Write(header[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(genesis.Bytes())
+	return err
+}
+
+func readChainFileHeader(r *hashingReader) (common.Hash, error) {
+	magic := make([]byte, len(chainFileMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return common.Hash{}, chainFileError(err)
+	}
+	if !bytes.Equal(magic, chainFileMagic) {
+		return common.Hash{}, fmt.Errorf("%w: not a chain file", ErrBadChainFile)
+	}
+	version, err := binary.ReadUvarint(r)
+	if err != nil {
+		return common.Hash{}, chainFileError(err)
+	}
+	if version != chainFileVersion {
+		return common.Hash{}, fmt.Errorf("%w: unsupported version %d", ErrBadChainFile, version)
+	}
+	var genesis common.Hash
+	if _, err := io.ReadFull(r, genesis[:]); err != nil {
+		return common.Hash{}, chainFileError(err)
+	}
+	return genesis, nil
+}
+
+// writeChainBlock writes the recordBlock byte and the length prefixed block
+// and commit, it returns the number of bytes written.
+func writeChainBlock(w io.Writer, block *types.Block, commit *types.Commit) (int, error) {
+	pbb, err := block.ToProto()
+	if err != nil {
+		return 0, err
+	}
+	blockData, err := proto.Marshal(pbb)
+	if err != nil {
+		return 0, err
+	}
+	commitData, err := proto.Marshal(commit.ToProto())
+	if err != nil {
+		return 0, err
+	}
+	size := 1
+	if _, err := w.Write([]byte{recordBlock}); err != nil {
+		return size, err
+	}
+	for _, data := range [][]byte{blockData, commitData} {
+		var prefix [binary.MaxVarintLen64]byte
+		n := binary.PutUvarint(prefix[:], uint64(len(data)))
+		if _, err := w.Write(prefix[:n]); err != nil {
+			return size, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return size, err
+		}
+		size += n + len(data)
+	}
+	return size, nil
+}
+
+func readChainBytes(r *hashingReader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, chainFileError(err)
+	}
+	if size > maxChainRecordSize {
+		return nil, fmt.Errorf("%w: record of %d bytes exceeds the limit", ErrBadChainFile, size)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, chainFileError(err)
+	}
+	return b, nil
+}
+
+// chainFileError reports a truncated chain file as ErrBadChainFile.
+func chainFileError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: truncated", ErrBadChainFile)
+	}
+	return err
+}
+
+// hashingReader hashes the bytes read from r along the way.
+type hashingReader struct {
+	r *bufio.Reader
+	h hash.Hash
+}
+
+func (hr *hashingReader) Read(p []byte) (int, error) {
+	n, err := hr.r.Read(p)
+	hr.h.Write(p[:n])
+	return n, err
+}
+
+func (hr *hashingReader) ReadByte() (byte, error) {
+	b, err := hr.r.ReadByte()
+	if err == nil {
+		hr.h.Write([]byte{b})
+	}
+	return b, err
+}
@@ -0,0 +1,121 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kvstore
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// writeTestChain writes a genesis block and n blocks, each committed by the
+// next one, the head by a seen commit.
+func writeTestChain(db kaidb.Database, n uint64) []*types.Block {
+	var (
+		blocks     []*types.Block
+		lastID     types.BlockID
+		lastCommit = &types.Commit{}
+		start      = time.Now()
+	)
+	for h := uint64(0); h <= n; h++ {
+		block := types.NewBlock(&types.Header{Height: h, Time: start.Add(time.Duration(h) * time.Second),
+			LastBlockID: lastID}, nil, lastCommit, nil)
+		parts := block.MakePartSet(types.BlockPartSizeBytes)
+		lastID = types.BlockID{Hash: block.Hash(), PartsHeader: parts.Header()}
+		lastCommit = types.NewCommit(h, 0, lastID, []types.CommitSig{
+			types.NewCommitSigForBlock([]byte{1}, common.HexToAddress("0x1"), block.Header().Time)})
+		WriteBlock(db, block, parts, lastCommit)
+		WriteHeadBlockHash(db, block.Hash())
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func readChainFile(t *testing.T, data []byte) ([]*types.Block, error) {
+	cr, err := NewChainFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	var blocks []*types.Block
+	for {
+		block, commit, err := cr.Next()
+		if err == io.EOF {
+			return blocks, nil
+		}
+		if err != nil {
+			return blocks, err
+		}
+		require.Equal(t, block.Hash(), commit.BlockID.Hash)
+		blocks = append(blocks, block)
+	}
+}
+
+func TestChainFile(t *testing.T) {
+	db := memorydb.New()
+	blocks := writeTestChain(db, 4)
+
+	var buf bytes.Buffer
+	stats, err := ExportChain(db, 0, 0, &buf)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), stats.From)
+	require.Equal(t, uint64(4), stats.To)
+	require.Equal(t, uint64(4), stats.Count)
+	data := buf.Bytes()
+
+	cr, err := NewChainFileReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, blocks[0].Hash(), cr.Genesis())
+	read, err := readChainFile(t, data)
+	require.NoError(t, err)
+	require.Len(t, read, 4)
+	for i, block := range read {
+		require.Equal(t, blocks[i+1].Hash(), block.Hash())
+	}
+
+	// a range of the chain
+	buf.Reset()
+	_, err = ExportChain(db, 2, 3, &buf)
+	require.NoError(t, err)
+	read, err = readChainFile(t, buf.Bytes())
+	require.NoError(t, err)
+	require.Len(t, read, 2)
+	require.Equal(t, uint64(2), read[0].Height())
+
+	// truncated and corrupted files are rejected
+	_, err = readChainFile(t, data[:len(data)-1])
+	require.True(t, errors.Is(err, ErrBadChainFile))
+	corrupted := common.CopyBytes(data)
+	corrupted[len(corrupted)-1] ^= 0xff
+	_, err = readChainFile(t, corrupted)
+	require.True(t, errors.Is(err, ErrBadChainFile))
+
+	// pruned blocks can't be exported
+	_, err = PruneBlocks(db, 1, 3, configs.TestChainConfig)
+	require.NoError(t, err)
+	_, err = ExportChain(db, 1, 0, &buf)
+	require.True(t, errors.Is(err, ErrPrunedHeight))
+}
@@ -0,0 +1,230 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kvstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+// Key categories of the block store.
+const (
+	CategoryHead            = "Head block"
+	CategoryBase            = "Block store base"
+	CategoryCanonicalHash   = "Canonical hashes"
+	CategoryHeader          = "Headers"
+	CategoryHeaderHeight    = "Header heights"
+	CategoryBody            = "Bodies"
+	CategoryBlockInfo       = "Block infos"
+	CategoryBlockMeta       = "Block metas"
+	CategoryBlockPart       = "Block parts"
+	CategoryCommit          = "Commits"
+	CategorySeenCommit      = "Seen commits"
+	CategoryAppHash         = "App hashes"
+	CategoryChainConfig     = "Chain configs"
+	CategoryTxLookup        = "Transaction lookups"
+	CategoryAddressTx       = "Address transaction index"
+	CategoryDualEventLookup = "Dual event lookups"
+	CategoryBloomBits       = "Bloom bits"
+	CategoryBloomBitsIndex  = "Bloom bits index"
+	CategoryEvent           = "Smart contract events"
+	CategoryDualAction      = "Dual actions"
+	CategoryContractAbi     = "Contract ABIs"
+)
+
+// BlockStorePrefixes returns the prefixes of the block store keys pruned
+// along with their blocks: headers, bodies, block infos and metas, parts,
+// commits, app hashes and the transaction lookups and address index.
+func BlockStorePrefixes() [][]byte {
+	return [][]byte{headerPrefix, headerHeightPrefix, blockBodyPrefix, blockPartPrefix, commitPrefix, seenCommitPrefix,
+		appHashPrefix, txLookupPrefix, addrTxPrefix}
+}
+
+// KeyCategory returns the category of a block store key, or an empty string
+// if key does not belong to the block store.
+func KeyCategory(key []byte) string {
+	size := len(key)
+	switch {
+	case bytes.Equal(key, headBlockKey):
+		return CategoryHead
+	case bytes.Equal(key, blockStoreBaseKey):
+		return CategoryBase
+	case bytes.HasPrefix(key, headerPrefix) && size == len(headerPrefix)+8+len(headerHashSuffix) && bytes.HasSuffix(key, headerHashSuffix):
+		return CategoryCanonicalHash
+	case bytes.HasPrefix(key, headerPrefix) && size == len(headerPrefix)+8+common.HashLength:
+		return CategoryHeader
+	case bytes.HasPrefix(key, headerHeightPrefix) && size == len(headerHeightPrefix)+common.HashLength:
+		return CategoryHeaderHeight
+	case bytes.HasPrefix(key, blockInfoPrefix) && size == len(blockInfoPrefix)+8+common.HashLength:
+		return CategoryBlockInfo
+	case bytes.HasPrefix(key, blockBodyPrefix) && size == len(blockBodyPrefix)+8+common.HashLength:
+		return CategoryBody
+	case bytes.HasPrefix(key, blockMetaPrefix) && size == len(blockMetaPrefix)+8:
+		return CategoryBlockMeta
+	case bytes.HasPrefix(key, blockPartPrefix) && size == len(blockPartPrefix)+8+4:
+		return CategoryBlockPart
+	case bytes.HasPrefix(key, commitPrefix) && size == len(commitPrefix)+8:
+		return CategoryCommit
+	case bytes.HasPrefix(key, seenCommitPrefix) && size == len(seenCommitPrefix)+8:
+		return CategorySeenCommit
+	case bytes.HasPrefix(key, appHashPrefix) && size == len(appHashPrefix)+8:
+		return CategoryAppHash
+	case bytes.HasPrefix(key, configPrefix) && size == len(configPrefix)+common.HashLength:
+		return CategoryChainConfig
+	case bytes.HasPrefix(key, txLookupPrefix) && size == len(txLookupPrefix)+common.HashLength:
+		return CategoryTxLookup
+	case bytes.HasPrefix(key, addrTxPrefix) && size == len(addrTxPrefix)+common.AddressLength+8+4:
+		return CategoryAddressTx
+	case bytes.HasPrefix(key, dualEventLookupPrefix) && size == len(dualEventLookupPrefix)+common.HashLength:
+		return CategoryDualEventLookup
+	case bytes.HasPrefix(key, bloomBitsPrefix) && size == len(bloomBitsPrefix)+10+common.HashLength:
+		return CategoryBloomBits
+	case bytes.HasPrefix(key, BloomBitsIndexPrefix):
+		return CategoryBloomBitsIndex
+	case bytes.HasPrefix(key, eventPrefix):
+		return CategoryEvent
+	case bytes.HasPrefix(key, dualActionPrefix):
+		return CategoryDualAction
+	case bytes.HasPrefix(key, contractAbiPrefix) && size > len(contractAbiPrefix) && key[len(contractAbiPrefix)] == '0':
+		// Contract addresses are hex strings.
+		return CategoryContractAbi
+	}
+	return ""
+}
+
+// Orphan is block store data which is not reachable from the canonical chain.
+type Orphan struct {
+	Category string `json:"category"`
+	Key      string `json:"key"`
+	Reason   string `json:"reason"`
+}
+
+// FindOrphans calls fn with the block store data which does not belong to
+// the canonical chain up to head: block data above the block being applied
+// after head, app hashes above head, and block infos, header heights and
+// transaction lookups of non canonical blocks. It stops when fn returns
+// false.
+func FindOrphans(db kaidb.Database, head uint64, fn func(Orphan) bool) error {
+	report := func(category string, key []byte, reason string) bool {
+		return fn(Orphan{Category: category, Key: common.Encode(key), Reason: reason})
+	}
+	// aboveHeight reports the keys of prefix above limit.
+	aboveHeight := func(prefix []byte, size int, limit uint64, category string) (bool, error) {
+		it := db.NewIterator(prefix, encodeBlockHeight(limit+1))
+		defer it.Release()
+		for it.Next() {
+			if len(it.Key()) != size {
+				continue
+			}
+			// The genesis block stores its empty last commit at height -1.
+			if binary.BigEndian.Uint64(it.Key()[len(prefix):]) == math.MaxUint64 {
+				continue
+			}
+			if !report(category, it.Key(), "above the head block") {
+				return false, nil
+			}
+		}
+		return true, it.Error()
+	}
+	// The block after head may be stored but not applied yet.
+	ranges := []struct {
+		prefix   []byte
+		size     int
+		limit    uint64
+		category string
+	}{
+		{blockMetaPrefix, len(blockMetaPrefix) + 8, head + 1, CategoryBlockMeta},
+		{blockPartPrefix, len(blockPartPrefix) + 8 + 4, head + 1, CategoryBlockPart},
+		{seenCommitPrefix, len(seenCommitPrefix) + 8, head + 1, CategorySeenCommit},
+		{commitPrefix, len(commitPrefix) + 8, head, CategoryCommit},
+		{appHashPrefix, len(appHashPrefix) + 8, head, CategoryAppHash},
+	}
+	for _, r := range ranges {
+		if more, err := aboveHeight(r.prefix, r.size, r.limit, r.category); err != nil || !more {
+			return err
+		}
+	}
+
+	canonical := func(height uint64, hash common.Hash) bool {
+		return height <= head+1 && ReadCanonicalHash(db, height) == hash
+	}
+	it := db.NewIterator(blockInfoPrefix, nil)
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(blockInfoPrefix)+8+common.HashLength {
+			continue
+		}
+		height := binary.BigEndian.Uint64(key[len(blockInfoPrefix):])
+		if !canonical(height, common.BytesToHash(key[len(blockInfoPrefix)+8:])) {
+			if !report(CategoryBlockInfo, key, "not a canonical block") {
+				it.Release()
+				return nil
+			}
+		}
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	it = db.NewIterator(headerHeightPrefix, nil)
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(headerHeightPrefix)+common.HashLength || len(it.Value()) != 8 {
+			continue
+		}
+		if !canonical(binary.BigEndian.Uint64(it.Value()), common.BytesToHash(key[len(headerHeightPrefix):])) {
+			if !report(CategoryHeaderHeight, key, "not a canonical block") {
+				it.Release()
+				return nil
+			}
+		}
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	it = db.NewIterator(txLookupPrefix, nil)
+	defer it.Release()
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(txLookupPrefix)+common.HashLength {
+			continue
+		}
+		var entry TxLookupEntry
+		if err := rlp.DecodeBytes(it.Value(), &entry); err != nil {
+			if !report(CategoryTxLookup, key, "invalid entry") {
+				return nil
+			}
+			continue
+		}
+		if entry.BlockIndex > head || !canonical(entry.BlockIndex, entry.BlockHash) {
+			if !report(CategoryTxLookup, key, "not in a canonical block") {
+				return nil
+			}
+		}
+	}
+	return it.Error()
+}
@@ -85,6 +85,12 @@ func (s *StoreDB) WriteTxLookupEntries(block *types.Block) {
 	WriteTxLookupEntries(s.db, block)
 }
 
+// WriteAddressTxIndex indexes every transaction of a block under its sender
+// and recipient, enabling address based transaction lookups.
+func (s *StoreDB) WriteAddressTxIndex(block *types.Block, signer types.Signer) {
+	WriteAddressTxIndex(s.db, block, signer)
+}
+
 // WriteHeadBlockHash stores head blockhash to db
 func (s *StoreDB) WriteHeadBlockHash(hash common.Hash) {
 	WriteHeadBlockHash(s.db, hash)
@@ -159,6 +165,12 @@ func (s *StoreDB) ReadHeaderHeight(hash common.Hash) *uint64 {
 	return ReadHeaderHeight(s.db, hash)
 }
 
+// ReadAddressTxHashes returns the hashes of the transactions sent or received
+// by addr, latest first.
+func (s *StoreDB) ReadAddressTxHashes(addr common.Address, offset, limit int) []common.Hash {
+	return ReadAddressTxHashes(s.db, addr, offset, limit)
+}
+
 // ReadTransaction retrieves a specific transaction from the database, along with
 // its added positional metadata.
 func (s *StoreDB) ReadTransaction(hash common.Hash) (*types.Transaction, common.Hash, uint64, uint64) {
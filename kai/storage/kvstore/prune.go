@@ -0,0 +1,119 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kvstore
+
+import (
+	"encoding/binary"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// ReadBlockStoreBase returns the lowest height whose block is kept, 0 if the
+// block store has never been pruned.
+func ReadBlockStoreBase(db kaidb.KeyValueReader) uint64 {
+	data, _ := db.Get(blockStoreBaseKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// PruneBlocks deletes the blocks of the heights from from to to, to excluded,
+// with their parts, commits, app hashes, block infos holding their receipts,
+// and the lookup entries and address index of their transactions, whose
+// senders are derived with config. The genesis block is never pruned. The
+// block store base is moved along, so that an interrupted pruning resumes
+// where it stopped. Returns the number of pruned blocks.
+func PruneBlocks(db kaidb.Database, from, to uint64, config *configs.ChainConfig) (uint64, error) {
+	if from == 0 {
+		from = 1
+	}
+	var (
+		batch  = db.NewBatch()
+		pruned uint64
+	)
+	for height := from; height < to; height++ {
+		ok, err := pruneBlock(db, batch, height, config)
+		if err != nil {
+			return pruned, err
+		}
+		if ok {
+			pruned++
+		}
+		if batch.ValueSize() >= kaidb.IdealBatchSize || height == to-1 {
+			if err := batch.Put(blockStoreBaseKey, encodeBlockHeight(height+1)); err != nil {
+				return pruned, err
+			}
+			if err := batch.Write(); err != nil {
+				return pruned, err
+			}
+			batch.Reset()
+		}
+	}
+	return pruned, nil
+}
+
+// pruneBlock queues the deletion of the block at height in batch, it reports
+// false if the block is already pruned.
+func pruneBlock(db kaidb.Reader, batch kaidb.Batch, height uint64, config *configs.ChainConfig) (bool, error) {
+	meta := ReadBlockMeta(db, height)
+	if meta == nil {
+		return false, nil
+	}
+	hash := meta.BlockID.Hash
+	keys := [][]byte{
+		// the commit of a block is stored with the block of the next height
+		commitKey(height - 1),
+		seenCommitKey(height),
+		blockInfoKey(height, hash),
+		blockBodyKey(height, hash),
+		headerKey(height, hash),
+		headerHeightKey(hash),
+		headerHashKey(height),
+		calcAppHashKey(height),
+		blockMetaKey(height),
+	}
+	for i := 0; i < int(meta.BlockID.PartsHeader.Total); i++ {
+		keys = append(keys, blockPartKey(height, i))
+	}
+	if block := ReadBlock(db, height); block != nil {
+		signer := types.MakeSigner(config, &height)
+		for i, tx := range block.Transactions() {
+			if _, txHeight, _ := ReadTxLookupEntry(db, tx.Hash()); txHeight == height {
+				keys = append(keys, txLookupKey(tx.Hash()))
+			}
+			from, err := types.Sender(signer, tx)
+			if err != nil {
+				continue
+			}
+			keys = append(keys, addrTxKey(from, height, uint32(i)))
+			if to := tx.To(); to != nil && *to != from {
+				keys = append(keys, addrTxKey(*to, height, uint32(i)))
+			}
+		}
+	}
+	for _, key := range keys {
+		if err := batch.Delete(key); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
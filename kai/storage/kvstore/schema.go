@@ -30,6 +30,10 @@ var (
 	// headBlockKey tracks the latest known full block's hash.
 	headBlockKey = []byte("LastBlock")
 
+	// blockStoreBaseKey tracks the lowest height whose block is kept, the
+	// blocks below it are pruned.
+	blockStoreBaseKey = []byte("BlockStoreBase")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerHashSuffix   = []byte("n") // headerPrefix + num (uint64 big endian) + headerHashSuffix -> hash
@@ -47,6 +51,7 @@ var (
 
 	configPrefix          = []byte("kardia-config-") // config prefix for the db
 	txLookupPrefix        = []byte("l")              // txLookupPrefix + hash -> transaction/receipt lookup metadata
+	addrTxPrefix          = []byte("at")             // addrTxPrefix + address + ^num (uint64 big endian) + ^index (uint32 big endian) -> tx hash
 	dualEventLookupPrefix = []byte("de")             // dualEventLookupPrefix + hash -> dual's event lookup metadata
 	bloomBitsPrefix       = []byte("B")              // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
 
@@ -129,6 +134,17 @@ func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
 }
 
+// addrTxKey = addrTxPrefix + address + ^num (uint64 big endian) + ^index (uint32 big endian)
+// Height and index are inverted so that iterating yields the latest txs first.
+func addrTxKey(addr common.Address, height uint64, index uint32) []byte {
+	return append(append(addrTxPrefixKey(addr), encodeBlockHeight(^height)...), encodeIndex(^index)...)
+}
+
+// addrTxPrefixKey = addrTxPrefix + address
+func addrTxPrefixKey(addr common.Address) []byte {
+	return append(append([]byte{}, addrTxPrefix...), addr.Bytes()...)
+}
+
 // dualEventLookupKey = dualEventLookupPrefix + hash
 func dualEventLookupKey(hash common.Hash) []byte {
 	return append(dualEventLookupPrefix, hash.Bytes()...)
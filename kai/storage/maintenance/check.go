@@ -0,0 +1,184 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package maintenance
+
+import (
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// Kinds of consistency problems.
+const (
+	ProblemCanonicalHash = "canonical hash"
+	ProblemBlock         = "block"
+	ProblemLink          = "link"
+	ProblemHeaderHeight  = "header height"
+	ProblemBlockInfo     = "block info"
+	ProblemAppHash       = "app hash"
+	ProblemState         = "state"
+	ProblemTxIndex       = "tx index"
+	ProblemValidators    = "validators"
+)
+
+// Problem is an inconsistency found at a height.
+type Problem struct {
+	Height uint64 `json:"height"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// Report is the result of a consistency check.
+type Report struct {
+	From      uint64    `json:"from"`
+	To        uint64    `json:"to"`
+	Checked   uint64    `json:"checked"`
+	Problems  []Problem `json:"problems"`
+	Truncated bool      `json:"truncated"`
+}
+
+func (r *Report) add(height uint64, kind string, format string, args ...interface{}) {
+	if len(r.Problems) >= maxReported {
+		r.Truncated = true
+		return
+	}
+	r.Problems = append(r.Problems, Problem{Height: height, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+}
+
+// Check verifies that the block store, the consensus state store and the
+// transaction index of db agree with each other from height from to height
+// to, or up to the head block if to is 0. The pruned heights are skipped.
+func Check(db kaidb.Database, from, to uint64) (*Report, error) {
+	head, err := headHeight(db)
+	if err != nil {
+		return nil, err
+	}
+	if base := kvstore.ReadBlockStoreBase(db); from < base {
+		from = base
+	}
+	if to == 0 || to > head {
+		to = head
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid range %d-%d, head is %d", from, to, head)
+	}
+	var (
+		report = &Report{From: from, To: to}
+		store  = cstate.NewStore(db)
+		last   *types.BlockMeta
+	)
+	if from > 0 {
+		last = kvstore.ReadBlockMeta(db, from-1)
+	}
+	for height := from; height <= to; height++ {
+		last = checkHeight(db, store, report, height, last)
+		report.Checked++
+	}
+	return report, nil
+}
+
+// checkHeight checks the block at height, whose parent has meta last, and
+// returns its meta.
+func checkHeight(db kaidb.Database, store cstate.Store, r *Report, height uint64, last *types.BlockMeta) *types.BlockMeta {
+	hash := kvstore.ReadCanonicalHash(db, height)
+	if hash.Equal(common.Hash{}) {
+		r.add(height, ProblemCanonicalHash, "missing canonical hash")
+		return nil
+	}
+	meta, block, err := readBlock(db, height)
+	if err != nil {
+		r.add(height, ProblemBlock, "%v", err)
+		return nil
+	}
+	if !meta.BlockID.Hash.Equal(hash) {
+		r.add(height, ProblemBlock, "meta of block %v instead of %v", meta.BlockID.Hash.Hex(), hash.Hex())
+	}
+	if blockHash := block.Hash(); !blockHash.Equal(hash) {
+		r.add(height, ProblemBlock, "block hash %v instead of %v", blockHash.Hex(), hash.Hex())
+	}
+	// Block 1 links to the genesis state rather than to the genesis block.
+	if height > 1 && last != nil && !block.Header().LastBlockID.Equal(last.BlockID) {
+		r.add(height, ProblemLink, "links to %v instead of %v", block.Header().LastBlockID, last.BlockID)
+	}
+	if stored := kvstore.ReadHeaderHeight(db, hash); stored == nil {
+		r.add(height, ProblemHeaderHeight, "missing header height")
+	} else if *stored != height {
+		r.add(height, ProblemHeaderHeight, "header height %d", *stored)
+	}
+	if info := kvstore.ReadBlockInfoRLP(db, hash, height); len(info) == 0 {
+		r.add(height, ProblemBlockInfo, "missing block info")
+	}
+
+	appHash := kvstore.ReadAppHash(db, height)
+	if appHash.Equal(common.Hash{}) {
+		r.add(height, ProblemAppHash, "missing app hash")
+	} else if !appHash.Equal(types.EmptyRootHash) {
+		if ok, _ := db.Has(appHash.Bytes()); !ok {
+			r.add(height, ProblemState, "missing state root %v", appHash.Hex())
+		}
+	}
+	if height > 0 {
+		// a missing parent app hash is reported at its height, or pruned
+		if parent := kvstore.ReadAppHash(db, height-1); !parent.Equal(common.Hash{}) &&
+			!block.Header().AppHash.Equal(parent) {
+			r.add(height, ProblemAppHash, "header app hash %v instead of %v", block.Header().AppHash.Hex(), parent.Hex())
+		}
+		vals, err := store.LoadValidators(height)
+		if err != nil {
+			r.add(height, ProblemValidators, "%v", err)
+		} else if valsHash := vals.Hash(); !valsHash.Equal(block.Header().ValidatorsHash) {
+			r.add(height, ProblemValidators, "validators hash %v instead of %v", valsHash.Hex(), block.Header().ValidatorsHash.Hex())
+		}
+	}
+
+	for i, tx := range block.Transactions() {
+		blockHash, blockHeight, index := kvstore.ReadTxLookupEntry(db, tx.Hash())
+		switch {
+		case blockHash.Equal(common.Hash{}):
+			r.add(height, ProblemTxIndex, "missing lookup of tx %v", tx.Hash().Hex())
+		case !blockHash.Equal(hash) || blockHeight != height || index != uint64(i):
+			r.add(height, ProblemTxIndex, "tx %v indexed at %d/%d in %v", tx.Hash().Hex(), blockHeight, index, blockHash.Hex())
+		}
+	}
+	return meta
+}
+
+// readBlock reads the meta and the block at height, reporting missing or
+// corrupted data as an error.
+func readBlock(db kaidb.Database, height uint64) (meta *types.BlockMeta, block *types.Block, err error) {
+	// The block store panics on corrupted data.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("corrupted block: %v", r)
+		}
+	}()
+	if meta = kvstore.ReadBlockMeta(db, height); meta == nil {
+		return nil, nil, fmt.Errorf("missing block meta")
+	}
+	for i := 0; i < int(meta.BlockID.PartsHeader.Total); i++ {
+		if kvstore.ReadBlockPart(db, height, i) == nil {
+			return nil, nil, fmt.Errorf("missing block part %d", i)
+		}
+	}
+	return meta, kvstore.ReadBlock(db, height), nil
+}
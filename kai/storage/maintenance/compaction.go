@@ -0,0 +1,152 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/types/evidence"
+)
+
+// Stores of the chain database compacted on their own.
+const (
+	StoreBlock    = "block"
+	StoreState    = "state"
+	StoreEvidence = "evidence"
+)
+
+// ErrUnknownStore is returned when compacting a store which doesn't exist.
+var ErrUnknownStore = errors.New("unknown store")
+
+// storePrefixes returns the key prefixes of a store.
+func storePrefixes(store string) ([][]byte, error) {
+	switch store {
+	case StoreBlock:
+		return kvstore.BlockStorePrefixes(), nil
+	case StoreState:
+		return cstate.KeyPrefixes(), nil
+	case StoreEvidence:
+		return evidence.KeyPrefixes(), nil
+	}
+	return nil, fmt.Errorf("%w: %v", ErrUnknownStore, store)
+}
+
+// CompactStore compacts the key ranges of a store of db, one prefix at a
+// time. Pruning deletes the keys of a store, compacting it reclaims their
+// space.
+func CompactStore(db kaidb.Compacter, store string) error {
+	prefixes, err := storePrefixes(store)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	for _, prefix := range prefixes {
+		if err := db.Compact(prefix, kaidb.PrefixLimit(prefix)); err != nil {
+			return fmt.Errorf("can't compact %v store range %v: %w", store, common.Encode(prefix), err)
+		}
+	}
+	log.Info("Compacted store", "store", store, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// Compactor compacts the stores of a database in the background, each one at
+// its own interval. Compactions run one at a time.
+type Compactor struct {
+	db        kaidb.Compacter
+	intervals map[string]time.Duration
+	logger    log.Logger
+
+	mtx  sync.Mutex // held while compacting
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewCompactor creates a compactor of the stores of db, a store whose
+// interval is 0 is not compacted.
+func NewCompactor(db kaidb.Compacter, config configs.CompactionConfig) *Compactor {
+	intervals := make(map[string]time.Duration)
+	for store, interval := range map[string]time.Duration{
+		StoreBlock:    config.BlockStoreInterval,
+		StoreState:    config.StateStoreInterval,
+		StoreEvidence: config.EvidenceInterval,
+	} {
+		if interval > 0 {
+			intervals[store] = interval
+		}
+	}
+	return &Compactor{
+		db:        db,
+		intervals: intervals,
+		logger:    log.New("module", "compactor"),
+		quit:      make(chan struct{}),
+	}
+}
+
+// Start starts compacting the stores.
+func (c *Compactor) Start() {
+	for store, interval := range c.intervals {
+		c.wg.Add(1)
+		go c.loop(store, interval)
+	}
+}
+
+// Stop stops compacting the stores, it waits for the running compaction to
+// finish.
+func (c *Compactor) Stop() {
+	close(c.quit)
+	c.wg.Wait()
+}
+
+// SetLogger sets the Logger.
+func (c *Compactor) SetLogger(l log.Logger) {
+	c.logger = l
+}
+
+func (c *Compactor) loop(store string, interval time.Duration) {
+	defer c.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Compact(store); err != nil {
+				c.logger.Error("Failed to compact store", "store", store, "err", err)
+			}
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+// Compact compacts a store, waiting for the running compaction to finish
+// first.
+func (c *Compactor) Compact(store string) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return CompactStore(c.db, store)
+}
@@ -0,0 +1,240 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package maintenance inspects, checks, compacts and migrates the chain
+// database of a node: the block store, the consensus state store, the state
+// trie and the indexes sharing it.
+package maintenance
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/kardiachain/go-kardia/kai/chainfeed"
+	"github.com/kardiachain/go-kardia/kai/indexer"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/trie"
+)
+
+// Key categories outside of the block store.
+const (
+	CategoryConsensusState = "Consensus state"
+	CategoryEventIndex     = "Event index"
+	CategoryTraceIndex     = "Trace index"
+	CategoryFeedCursor     = "Chain feed cursors"
+	CategoryTrie           = "Trie nodes and codes"
+	CategoryPreimage       = "Trie preimages"
+	CategoryUnaccounted    = "Unaccounted"
+)
+
+// maxReported is the maximum number of orphans or problems listed in a
+// report; the rest are only counted.
+const maxReported = 1000
+
+// errNoHead is returned when the database has no head block.
+var errNoHead = errors.New("no head block")
+
+// Stat is the number and total size of the keys of a category.
+type Stat struct {
+	Category string             `json:"category"`
+	Count    uint64             `json:"count"`
+	Size     common.StorageSize `json:"size"`
+}
+
+// Inspection is the key space statistics of a database.
+type Inspection struct {
+	Stats []Stat             `json:"stats"`
+	Count uint64             `json:"count"`
+	Size  common.StorageSize `json:"size"`
+}
+
+// category returns the category of a key.
+func category(key []byte) string {
+	if category := kvstore.KeyCategory(key); category != "" {
+		return category
+	}
+	switch {
+	case cstate.IsStateKey(key):
+		return CategoryConsensusState
+	case indexer.IsIndexKey(key):
+		return CategoryEventIndex
+	case indexer.IsTraceKey(key):
+		return CategoryTraceIndex
+	case chainfeed.IsCursorKey(key):
+		return CategoryFeedCursor
+	case trie.IsPreimageKey(key):
+		return CategoryPreimage
+	case len(key) == common.HashLength:
+		return CategoryTrie
+	}
+	return CategoryUnaccounted
+}
+
+// Inspect iterates over the whole database and returns the number and size
+// of the keys of every category.
+func Inspect(db kaidb.Database) (*Inspection, error) {
+	var (
+		stats  = make(map[string]*Stat)
+		result = new(Inspection)
+		start  = time.Now()
+		logged = time.Now()
+	)
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		name := category(it.Key())
+		stat, ok := stats[name]
+		if !ok {
+			stat = &Stat{Category: name}
+			stats[name] = stat
+		}
+		size := common.StorageSize(len(it.Key()) + len(it.Value()))
+		stat.Count++
+		stat.Size += size
+		result.Count++
+		result.Size += size
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Inspecting database", "count", result.Count, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	for _, stat := range stats {
+		result.Stats = append(result.Stats, *stat)
+	}
+	sort.Slice(result.Stats, func(i, j int) bool {
+		return result.Stats[i].Category < result.Stats[j].Category
+	})
+	return result, nil
+}
+
+// OrphanReport lists the block store data which does not belong to the
+// canonical chain.
+type OrphanReport struct {
+	Head      uint64            `json:"head"`
+	Counts    map[string]uint64 `json:"counts"`
+	Orphans   []kvstore.Orphan  `json:"orphans"`
+	Truncated bool              `json:"truncated"`
+}
+
+// FindOrphans returns the block store data of db which does not belong to
+// its canonical chain.
+func FindOrphans(db kaidb.Database) (*OrphanReport, error) {
+	head, err := headHeight(db)
+	if err != nil {
+		return nil, err
+	}
+	report := &OrphanReport{Head: head, Counts: make(map[string]uint64)}
+	err = kvstore.FindOrphans(db, head, func(orphan kvstore.Orphan) bool {
+		report.Counts[orphan.Category]++
+		if len(report.Orphans) < maxReported {
+			report.Orphans = append(report.Orphans, orphan)
+		} else {
+			report.Truncated = true
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Compact compacts the whole key space of db, one range of leading bytes at
+// a time.
+func Compact(db kaidb.Compacter) error {
+	for b := 0x00; b <= 0xf0; b += 0x10 {
+		var (
+			start = []byte{byte(b)}
+			end   = []byte{byte(b + 0x10)}
+		)
+		if b == 0xf0 {
+			end = nil
+		}
+		log.Info("Compacting database", "range", common.Encode(start)+"-"+common.Encode(end))
+		begin := time.Now()
+		if err := db.Compact(start, end); err != nil {
+			return err
+		}
+		log.Info("Compacted database range", "elapsed", common.PrettyDuration(time.Since(begin)))
+	}
+	return nil
+}
+
+// Migrate copies the whole key space of src into dst, in batches of
+// kaidb.IdealBatchSize, and returns the number and size of the copied keys.
+// It is used to move a chain database to another key-value store backend, so
+// dst is expected to be empty: its other keys are left as they are.
+func Migrate(dst kaidb.Batcher, src kaidb.Iteratee) (uint64, common.StorageSize, error) {
+	var (
+		count  uint64
+		size   common.StorageSize
+		start  = time.Now()
+		logged = time.Now()
+		batch  = dst.NewBatch()
+	)
+	it := src.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			return count, size, err
+		}
+		count++
+		size += common.StorageSize(len(it.Key()) + len(it.Value()))
+
+		if batch.ValueSize() >= kaidb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return count, size, err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Migrating database", "count", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return count, size, err
+	}
+	if err := batch.Write(); err != nil {
+		return count, size, err
+	}
+	log.Info("Migrated database", "count", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return count, size, nil
+}
+
+// headHeight returns the height of the head block of db.
+func headHeight(db kaidb.Reader) (uint64, error) {
+	hash := kvstore.ReadHeadBlockHash(db)
+	if hash.Equal(common.Hash{}) {
+		return 0, errNoHead
+	}
+	height := kvstore.ReadHeaderHeight(db, hash)
+	if height == nil {
+		return 0, errNoHead
+	}
+	return *height, nil
+}
@@ -0,0 +1,320 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package maintenance
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/kai/state"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// newTestChain writes a chain of n blocks with a state at every height.
+func newTestChain(t *testing.T, n uint64) kaidb.Database {
+	db := memorydb.New()
+	vals, _ := types.RandValidatorSet(4, 10)
+	start := time.Now().Add(-time.Hour)
+
+	commitState := func(parent common.Hash, height uint64) common.Hash {
+		statedb, err := state.New(log.New(), parent, state.NewDatabase(db))
+		require.NoError(t, err)
+		statedb.AddBalance(common.HexToAddress("0x1234"), big.NewInt(int64(height+1)))
+		root, err := statedb.Commit(false)
+		require.NoError(t, err)
+		require.NoError(t, statedb.Database().TrieDB().Commit(root, false))
+		return root
+	}
+
+	root := commitState(common.Hash{}, 0)
+	genesis := types.NewBlock(&types.Header{Time: start, GasLimit: configs.BlockGasLimit}, nil, &types.Commit{}, nil)
+	kvstore.WriteBlock(db, genesis, genesis.MakePartSet(types.BlockPartSizeBytes), &types.Commit{})
+	kvstore.WriteBlockInfo(db, genesis.Hash(), 0, nil)
+	kvstore.WriteAppHash(db, 0, root)
+	kvstore.WriteHeadBlockHash(db, genesis.Hash())
+
+	store := cstate.NewStore(db)
+	st := cstate.LatestBlockState{
+		ChainID:                          "kai-test",
+		InitialHeight:                    1,
+		LastBlockTime:                    start,
+		Validators:                       vals,
+		NextValidators:                   vals.CopyIncrementProposerPriority(1),
+		LastValidators:                   types.NewValidatorSet(nil),
+		LastHeightValidatorsChanged:      1,
+		ConsensusParams:                  *configs.DefaultConsensusParams(),
+		LastHeightConsensusParamsChanged: 1,
+		AppHash:                          root,
+	}
+	store.Save(st)
+
+	for h := uint64(1); h <= n; h++ {
+		header := &types.Header{
+			Height:             h,
+			Time:               start.Add(time.Duration(h) * time.Second),
+			LastBlockID:        st.LastBlockID,
+			GasLimit:           configs.BlockGasLimit,
+			ValidatorsHash:     st.Validators.Hash(),
+			NextValidatorsHash: st.NextValidators.Hash(),
+			AppHash:            st.AppHash,
+		}
+		block := types.NewBlock(header, nil, &types.Commit{}, nil)
+		parts := block.MakePartSet(types.BlockPartSizeBytes)
+		kvstore.WriteBlock(db, block, parts, &types.Commit{})
+		kvstore.WriteBlockInfo(db, block.Hash(), h, &types.BlockInfo{GasUsed: h})
+		root = commitState(root, h)
+		kvstore.WriteAppHash(db, h, root)
+		kvstore.WriteHeadBlockHash(db, block.Hash())
+
+		st.LastBlockHeight = h
+		st.LastBlockID = types.BlockID{Hash: block.Hash(), PartsHeader: parts.Header()}
+		st.LastBlockTime = header.Time
+		st.LastValidators = st.Validators
+		st.Validators = st.NextValidators
+		st.NextValidators = st.NextValidators.CopyIncrementProposerPriority(1)
+		st.AppHash = root
+		store.Save(st)
+	}
+	return db
+}
+
+func TestInspect(t *testing.T) {
+	db := newTestChain(t, 3)
+	inspection, err := Inspect(db)
+	require.NoError(t, err)
+
+	counts := make(map[string]uint64)
+	var total uint64
+	for _, stat := range inspection.Stats {
+		counts[stat.Category] = stat.Count
+		total += stat.Count
+	}
+	require.Equal(t, inspection.Count, total)
+	require.Equal(t, uint64(4), counts[kvstore.CategoryBlockMeta])
+	require.Equal(t, uint64(4), counts[kvstore.CategoryCanonicalHash])
+	require.Equal(t, uint64(4), counts[kvstore.CategoryAppHash])
+	require.Equal(t, uint64(1), counts[kvstore.CategoryHead])
+	require.NotZero(t, counts[CategoryConsensusState])
+	require.NotZero(t, counts[CategoryTrie])
+	require.Zero(t, counts[CategoryUnaccounted])
+}
+
+func TestCheck(t *testing.T) {
+	db := newTestChain(t, 4)
+	report, err := Check(db, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), report.Checked)
+	require.Empty(t, report.Problems)
+
+	kvstore.DeleteCanonicalHash(db, 2)
+	require.NoError(t, db.Delete(kvstore.ReadAppHash(db, 3).Bytes()))
+
+	report, err = Check(db, 1, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), report.Checked)
+	require.Equal(t, []Problem{
+		{Height: 2, Kind: ProblemCanonicalHash, Detail: "missing canonical hash"},
+		{Height: 3, Kind: ProblemState, Detail: "missing state root " + kvstore.ReadAppHash(db, 3).Hex()},
+	}, report.Problems)
+}
+
+func TestFindOrphans(t *testing.T) {
+	db := newTestChain(t, 3)
+	report, err := FindOrphans(db)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), report.Head)
+	require.Empty(t, report.Orphans)
+
+	kvstore.WriteBlockInfo(db, common.BytesToHash([]byte("fork")), 2, &types.BlockInfo{})
+	kvstore.WriteAppHash(db, 4, common.BytesToHash([]byte("next")))
+
+	report, err = FindOrphans(db)
+	require.NoError(t, err)
+	require.Len(t, report.Orphans, 2)
+	require.Equal(t, uint64(1), report.Counts[kvstore.CategoryBlockInfo])
+	require.Equal(t, uint64(1), report.Counts[kvstore.CategoryAppHash])
+}
+
+func TestMigrate(t *testing.T) {
+	src := newTestChain(t, 3)
+	// Add enough data to need several batches.
+	for i := 0; i < 100; i++ {
+		require.NoError(t, src.Put(append([]byte("migrate"), byte(i)), make([]byte, kaidb.IdealBatchSize/10)))
+	}
+	want, err := Inspect(src)
+	require.NoError(t, err)
+
+	dst := memorydb.New()
+	count, size, err := Migrate(dst, src)
+	require.NoError(t, err)
+	require.Equal(t, want.Count, count)
+	require.Equal(t, want.Size, size)
+
+	have, err := Inspect(dst)
+	require.NoError(t, err)
+	require.Equal(t, want, have)
+
+	report, err := Check(dst, 0, 0)
+	require.NoError(t, err)
+	require.Empty(t, report.Problems)
+}
+
+// compactRecorder records the ranges it is asked to compact.
+type compactRecorder struct {
+	mtx    sync.Mutex
+	ranges [][2]string
+}
+
+func (r *compactRecorder) Compact(start, limit []byte) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.ranges = append(r.ranges, [2]string{string(start), string(limit)})
+	return nil
+}
+
+func (r *compactRecorder) count() int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return len(r.ranges)
+}
+
+func TestCompactStore(t *testing.T) {
+	db := new(compactRecorder)
+	require.NoError(t, CompactStore(db, StoreEvidence))
+	require.Equal(t, [][2]string{
+		{"evidence-committed", "evidence-committee"},
+		{"evidence-pending", "evidence-pendinh"},
+	}, db.ranges)
+
+	// every key of a store is in one of its ranges
+	db.ranges = nil
+	require.NoError(t, CompactStore(db, StoreState))
+	chain := newTestChain(t, 3)
+	it := chain.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		if !cstate.IsStateKey(it.Key()) {
+			continue
+		}
+		var found bool
+		for _, r := range db.ranges {
+			found = found || (string(it.Key()) >= r[0] && string(it.Key()) < r[1])
+		}
+		require.True(t, found, "key %q is not compacted", it.Key())
+	}
+
+	require.True(t, errors.Is(CompactStore(db, "trie"), ErrUnknownStore))
+}
+
+func TestCompactor(t *testing.T) {
+	db := new(compactRecorder)
+	c := NewCompactor(db, configs.CompactionConfig{BlockStoreInterval: 10 * time.Millisecond})
+	c.Start()
+	require.Eventually(t, func() bool { return db.count() >= 2*len(kvstore.BlockStorePrefixes()) }, time.Second, 10*time.Millisecond)
+	c.Stop()
+
+	// disabled stores are not compacted
+	for _, r := range db.ranges {
+		require.False(t, cstate.IsStateKey([]byte(r[0])))
+	}
+}
+
+type evidenceRecorder struct {
+	heights []uint64
+}
+
+func (r *evidenceRecorder) Prune(height uint64) error {
+	r.heights = append(r.heights, height)
+	return nil
+}
+
+func TestPruner(t *testing.T) {
+	newPruner := func(config configs.PruningConfig) (kaidb.Database, *Pruner, *evidenceRecorder) {
+		db := newTestChain(t, 20)
+		store := cstate.NewStore(db)
+		st, err := store.Load()
+		require.NoError(t, err)
+		st.ConsensusParams.Evidence.MaxAgeNumBlocks = 2
+		st.ConsensusParams.Evidence.MaxAgeDuration = time.Nanosecond
+		store.Save(st)
+		evidence := new(evidenceRecorder)
+		p, err := NewPruner(db, config, configs.TestChainConfig, store, evidence)
+		require.NoError(t, err)
+		return db, p, evidence
+	}
+	hasState := func(db kaidb.Database, height uint64) bool {
+		ok, _ := db.Has(kvstore.ReadAppHash(db, height).Bytes())
+		return ok
+	}
+
+	_, err := NewPruner(memorydb.New(), configs.PruningConfig{Mode: "nothing"}, configs.TestChainConfig, nil, nil)
+	require.True(t, errors.Is(err, ErrUnknownPruningMode))
+
+	// the default mode keeps the latest heights
+	db, p, evidence := newPruner(configs.PruningConfig{Mode: PruningDefault, KeepRecent: 5})
+	report, err := p.Prune()
+	require.NoError(t, err)
+	require.Equal(t, uint64(15), report.Base)
+	require.Equal(t, uint64(14), report.Blocks)
+	require.NotZero(t, report.StateNodes)
+	require.Equal(t, []uint64{15}, evidence.heights)
+	require.Equal(t, uint64(15), kvstore.ReadBlockStoreBase(db))
+	require.NotNil(t, kvstore.ReadBlockMeta(db, 0))
+	require.Nil(t, kvstore.ReadBlockMeta(db, 14))
+	for height := uint64(15); height <= 20; height++ {
+		require.True(t, hasState(db, height))
+	}
+	report, err = Check(db, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), report.Checked)
+	require.Empty(t, report.Problems)
+
+	// the window covers the evidence which hasn't expired
+	_, p, _ = newPruner(configs.PruningConfig{Mode: PruningDefault, KeepRecent: 1})
+	report, err = p.Prune()
+	require.NoError(t, err)
+	require.Equal(t, uint64(17), report.Base)
+
+	// everything but snapshots keeps the blocks from the oldest snapshot and
+	// the states of the snapshots and the latest heights
+	db, p, _ = newPruner(configs.PruningConfig{Mode: PruningEverythingButSnapshots, KeepRecent: 3})
+	_, err = p.Prune()
+	require.True(t, errors.Is(err, errNoSnapshots))
+	p.SetSnapshots(func() ([]uint64, error) { return []uint64{12, 20}, nil })
+	report, err = p.Prune()
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), report.Base)
+	require.Equal(t, uint64(17), report.StateBase)
+	require.NotNil(t, kvstore.ReadBlockMeta(db, 12))
+	require.True(t, hasState(db, 12))
+	require.False(t, hasState(db, 14))
+	require.True(t, hasState(db, 17))
+}
@@ -0,0 +1,301 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+)
+
+// Pruning modes.
+const (
+	// PruningArchive keeps everything.
+	PruningArchive = "archive"
+	// PruningDefault keeps the blocks, records and states of the KeepRecent
+	// latest heights.
+	PruningDefault = "default"
+	// PruningEverythingButSnapshots keeps the blocks and records from the
+	// oldest state sync snapshot on, the states of the KeepRecent latest
+	// heights and of the snapshot heights.
+	PruningEverythingButSnapshots = "everything-but-snapshots"
+)
+
+var (
+	// ErrUnknownPruningMode is returned when creating a pruner with an unknown
+	// mode.
+	ErrUnknownPruningMode = errors.New("unknown pruning mode")
+	// errNoSnapshots is returned when pruning everything but snapshots
+	// without snapshot heights.
+	errNoSnapshots = errors.New("no state sync snapshot heights")
+)
+
+// EvidencePruner deletes the committed evidence below a height.
+type EvidencePruner interface {
+	Prune(height uint64) error
+}
+
+// PruneReport is the result of a pruning.
+type PruneReport struct {
+	Base       uint64             `json:"base"`       // lowest height whose block is kept
+	StateBase  uint64             `json:"stateBase"`  // lowest height whose state is kept
+	Blocks     uint64             `json:"blocks"`     // number of pruned blocks
+	StateNodes uint64             `json:"stateNodes"` // number of pruned trie nodes and codes
+	StateSize  common.StorageSize `json:"stateSize"`  // size of the pruned trie nodes and codes
+}
+
+// Pruner prunes the chain database in the background: the blocks with their
+// receipts and indexes, the consensus state records, the committed evidence
+// and the state tries below a retention window set by its mode. The window
+// always covers the heights consensus still needs to verify evidence.
+// Prunings run one at a time.
+type Pruner struct {
+	db          kaidb.Database
+	config      configs.PruningConfig
+	chainConfig *configs.ChainConfig
+	states      cstate.Store
+	evidence    EvidencePruner
+	tries       *statePruner
+	snapshots   func() ([]uint64, error)
+	logger      log.Logger
+
+	mtx  sync.Mutex // held while pruning
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewPruner creates a pruner of db, whose transaction senders are derived
+// with chainConfig.
+func NewPruner(db kaidb.Database, config configs.PruningConfig, chainConfig *configs.ChainConfig, states cstate.Store,
+	evidence EvidencePruner) (*Pruner, error) {
+	switch config.Mode {
+	case PruningArchive, PruningDefault, PruningEverythingButSnapshots:
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownPruningMode, config.Mode)
+	}
+	return &Pruner{
+		db:          db,
+		config:      config,
+		chainConfig: chainConfig,
+		states:      states,
+		evidence:    evidence,
+		tries:       newStatePruner(db),
+		logger:      log.New("module", "pruner"),
+		quit:        make(chan struct{}),
+	}, nil
+}
+
+// SetSnapshots sets the function returning the heights of the state sync
+// snapshots, taken or being taken, required by the everything-but-snapshots
+// mode.
+func (p *Pruner) SetSnapshots(snapshots func() ([]uint64, error)) {
+	p.snapshots = snapshots
+}
+
+// CommitTrie commits the state trie of root with commit. The state tries of
+// the node must be committed through it for a pruning not to delete the
+// nodes they share with pruned tries.
+func (p *Pruner) CommitTrie(root common.Hash, commit func() error) error {
+	return p.tries.commit(root, commit)
+}
+
+// Start starts pruning at the configured interval.
+func (p *Pruner) Start() {
+	if p.config.Mode == PruningArchive || p.config.Interval <= 0 {
+		return
+	}
+	p.wg.Add(1)
+	go p.loop()
+}
+
+// Stop stops pruning, it waits for the running pruning to finish.
+func (p *Pruner) Stop() {
+	close(p.quit)
+	p.wg.Wait()
+}
+
+// SetLogger sets the Logger.
+func (p *Pruner) SetLogger(l log.Logger) {
+	p.logger = l
+}
+
+func (p *Pruner) loop() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := p.Prune(); err != nil {
+				p.logger.Error("Failed to prune", "mode", p.config.Mode, "err", err)
+			}
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// Prune prunes the database below the retention window, waiting for the
+// running pruning to finish first. The evidence, the consensus state records
+// and the blocks are pruned in that order, each store being consistent with
+// the blocks it relies on, then the state tries.
+func (p *Pruner) Prune() (*PruneReport, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	base := kvstore.ReadBlockStoreBase(p.db)
+	report := &PruneReport{Base: base, StateBase: base}
+	if p.config.Mode == PruningArchive {
+		return report, nil
+	}
+	// the tries committed from now on are kept
+	p.tries.begin()
+	defer p.tries.end()
+	state, err := p.states.Load()
+	if err != nil {
+		return nil, err
+	}
+	head := state.LastBlockHeight
+	to, stateTo, snapshots, err := p.window(state, base)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	if to > base && to > 1 {
+		if err := p.evidence.Prune(to); err != nil {
+			return nil, fmt.Errorf("can't prune evidence below %d: %w", to, err)
+		}
+		if from := p.states.Base(); from < to {
+			if from == 0 {
+				from = 1
+			}
+			if err := p.states.PruneStates(from, to); err != nil {
+				return nil, fmt.Errorf("can't prune consensus state below %d: %w", to, err)
+			}
+		}
+		pruned, err := kvstore.PruneBlocks(p.db, base, to, p.chainConfig)
+		report.Blocks = pruned
+		if err != nil {
+			return report, fmt.Errorf("can't prune blocks below %d: %w", to, err)
+		}
+		report.Base = to
+	}
+
+	roots := []common.Hash{state.AppHash}
+	for height := stateTo; height <= head; height++ {
+		roots = append(roots, kvstore.ReadAppHash(p.db, height))
+	}
+	for _, height := range snapshots {
+		roots = append(roots, kvstore.ReadAppHash(p.db, height))
+	}
+	report.StateBase = stateTo
+	report.StateNodes, report.StateSize, err = p.tries.prune(roots)
+	if err != nil {
+		return report, fmt.Errorf("can't prune state tries: %w", err)
+	}
+	p.logger.Info("Pruned database", "mode", p.config.Mode, "base", report.Base, "stateBase", report.StateBase,
+		"blocks", report.Blocks, "stateNodes", report.StateNodes, "stateSize", report.StateSize,
+		"elapsed", common.PrettyDuration(time.Since(start)))
+	return report, nil
+}
+
+// window returns the lowest heights whose blocks and states are kept, and the
+// snapshot heights whose states are kept too.
+func (p *Pruner) window(state cstate.LatestBlockState, base uint64) (uint64, uint64, []uint64, error) {
+	head := state.LastBlockHeight
+	to := p.evidenceBase(state, base)
+	stateTo := uint64(1)
+	if head > p.config.KeepRecent {
+		stateTo = head - p.config.KeepRecent
+	}
+	var snapshots []uint64
+	switch p.config.Mode {
+	case PruningDefault:
+		if stateTo < to {
+			to = stateTo
+		}
+		stateTo = to
+	case PruningEverythingButSnapshots:
+		if p.snapshots == nil {
+			return 0, 0, nil, errNoSnapshots
+		}
+		heights, err := p.snapshots()
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		if len(heights) == 0 {
+			return 0, 0, nil, errNoSnapshots
+		}
+		for _, height := range heights {
+			if height < to {
+				to = height
+			}
+			if height > 0 && height <= head {
+				snapshots = append(snapshots, height)
+			}
+		}
+	}
+	if to < base {
+		to = base
+	}
+	if stateTo < to {
+		stateTo = to
+	}
+	return to, stateTo, snapshots, nil
+}
+
+// evidenceBase returns the lowest height the blocks must be kept from for
+// consensus to verify evidence: the evidence of lower heights has expired by
+// number of blocks and by time. The pool may not be updated with the head
+// block yet, so the evidence is aged from the block below it.
+func (p *Pruner) evidenceBase(state cstate.LatestBlockState, base uint64) uint64 {
+	params := state.ConsensusParams.Evidence
+	head := state.LastBlockHeight
+	maxAge := uint64(params.MaxAgeNumBlocks) + 1
+	if head <= maxAge+1 {
+		return 0
+	}
+	to := head - maxAge
+	meta := kvstore.ReadBlockMeta(p.db, head-1)
+	if meta == nil {
+		return 0
+	}
+	now := meta.Header.Time
+	if base == 0 {
+		base = 1
+	}
+	if to <= base {
+		return to
+	}
+	// block times increase with heights, the expired ones are the lowest
+	n := sort.Search(int(to-base), func(i int) bool {
+		meta := kvstore.ReadBlockMeta(p.db, base+uint64(i))
+		return meta == nil || now.Sub(meta.Header.Time) <= params.MaxAgeDuration
+	})
+	return base + uint64(n)
+}
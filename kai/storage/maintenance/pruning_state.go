@@ -0,0 +1,266 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package maintenance
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/state"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+	"github.com/kardiachain/go-kardia/trie"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// recentRoots is the number of the latest committed state roots kept by a
+// pruning, they cover the blocks being written while it starts.
+const recentRoots = 128
+
+var emptyCodeHash = crypto.Keccak256(nil)
+
+// statePruner deletes the trie nodes and codes which are not reachable from
+// the retained state roots, marking the reachable ones then sweeping the
+// others. The tries committed while it prunes are marked too, so that blocks
+// keep being written during a pruning.
+type statePruner struct {
+	db     kaidb.Database
+	triedb *trie.TrieDatabase
+
+	commitMtx sync.Mutex // held while committing a trie or deleting nodes
+	recent    []common.Hash
+
+	mtx    sync.Mutex               // protects marked and failed
+	marked map[common.Hash]struct{} // nil while not pruning
+	failed error                    // set when a committed trie can't be marked
+}
+
+func newStatePruner(db kaidb.Database) *statePruner {
+	return &statePruner{db: db, triedb: trie.NewDatabase(db)}
+}
+
+// commit commits the trie of root with commit, and marks it if a pruning is
+// running. A trie which can't be marked aborts the pruning, not the commit.
+func (sp *statePruner) commit(root common.Hash, commit func() error) error {
+	sp.commitMtx.Lock()
+	defer sp.commitMtx.Unlock()
+	if err := commit(); err != nil {
+		return err
+	}
+	sp.recent = append(sp.recent, root)
+	if len(sp.recent) > recentRoots {
+		sp.recent = sp.recent[1:]
+	}
+	if !sp.pruning() {
+		return nil
+	}
+	if err := sp.markState(root); err != nil {
+		sp.mtx.Lock()
+		sp.failed = fmt.Errorf("can't mark committed state %v: %w", root.Hex(), err)
+		sp.mtx.Unlock()
+	}
+	return nil
+}
+
+// begin starts marking the committed tries.
+func (sp *statePruner) begin() {
+	sp.mtx.Lock()
+	defer sp.mtx.Unlock()
+	sp.marked = make(map[common.Hash]struct{})
+	sp.failed = nil
+}
+
+// end stops marking the committed tries.
+func (sp *statePruner) end() {
+	sp.mtx.Lock()
+	defer sp.mtx.Unlock()
+	sp.marked = nil
+	sp.failed = nil
+}
+
+func (sp *statePruner) pruning() bool {
+	sp.mtx.Lock()
+	defer sp.mtx.Unlock()
+	return sp.marked != nil
+}
+
+// prune deletes the trie nodes and codes unreachable from roots and from the
+// latest committed roots, it must be called between begin and end. Returns
+// the number and size of the deleted ones.
+func (sp *statePruner) prune(roots []common.Hash) (uint64, common.StorageSize, error) {
+	sp.commitMtx.Lock()
+	roots = append(roots, sp.recent...)
+	sp.commitMtx.Unlock()
+	for _, root := range roots {
+		if err := sp.markState(root); err != nil {
+			return 0, 0, fmt.Errorf("can't mark state %v: %w", root.Hex(), err)
+		}
+	}
+	return sp.sweep()
+}
+
+// mark adds hash to the marked set, it reports false if it was already there.
+func (sp *statePruner) mark(hash common.Hash) bool {
+	sp.mtx.Lock()
+	defer sp.mtx.Unlock()
+	if _, ok := sp.marked[hash]; ok {
+		return false
+	}
+	sp.marked[hash] = struct{}{}
+	return true
+}
+
+func (sp *statePruner) isMarked(hash common.Hash) bool {
+	sp.mtx.Lock()
+	defer sp.mtx.Unlock()
+	_, ok := sp.marked[hash]
+	return ok
+}
+
+// markState marks the nodes of the account trie of root, with the storage
+// tries and codes of its accounts.
+func (sp *statePruner) markState(root common.Hash) error {
+	if root.Equal(common.Hash{}) {
+		return nil
+	}
+	return sp.markTrie(root, func(blob []byte) error {
+		var account state.Account
+		if err := rlp.DecodeBytes(blob, &account); err != nil {
+			return fmt.Errorf("invalid account: %w", err)
+		}
+		if err := sp.markTrie(account.Root, nil); err != nil {
+			return err
+		}
+		if !bytes.Equal(account.CodeHash, emptyCodeHash) {
+			sp.mark(common.BytesToHash(account.CodeHash))
+		}
+		return nil
+	})
+}
+
+// markTrie marks the nodes of the trie of root and calls leaf with the
+// leaves found. The subtries already marked are skipped, they have been
+// walked in full.
+func (sp *statePruner) markTrie(root common.Hash, leaf func(blob []byte) error) error {
+	if root == types.EmptyRootHash || sp.isMarked(root) {
+		return nil
+	}
+	t, err := trie.New(root, sp.triedb)
+	if err != nil {
+		return err
+	}
+	it := t.NodeIterator(nil)
+	for descend := true; it.Next(descend); {
+		descend = true
+		if hash := it.Hash(); !hash.Equal(common.Hash{}) {
+			if descend = sp.mark(hash); !descend {
+				continue
+			}
+		}
+		if leaf != nil && it.Leaf() {
+			if err := leaf(it.LeafBlob()); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Error()
+}
+
+// sweep deletes the unmarked trie nodes and codes, the keys whose value
+// hashes to them. The candidates are read from a snapshot and checked again
+// while holding the commit lock, as a trie committed meanwhile may share them.
+func (sp *statePruner) sweep() (uint64, common.StorageSize, error) {
+	snap, err := sp.db.NewSnapshot()
+	if err != nil {
+		return 0, 0, fmt.Errorf("can't take snapshot: %w", err)
+	}
+	defer snap.Release()
+
+	type candidate struct {
+		key  []byte
+		size int
+	}
+	var (
+		candidates []candidate
+		pending    int
+		count      uint64
+		size       common.StorageSize
+	)
+	flush := func() error {
+		sp.commitMtx.Lock()
+		defer sp.commitMtx.Unlock()
+		sp.mtx.Lock()
+		failed := sp.failed
+		sp.mtx.Unlock()
+		if failed != nil {
+			return failed
+		}
+		batch := sp.db.NewBatch()
+		var (
+			batchCount uint64
+			batchSize  common.StorageSize
+		)
+		for _, c := range candidates {
+			if sp.isMarked(common.BytesToHash(c.key)) {
+				continue
+			}
+			if err := batch.Delete(c.key); err != nil {
+				return err
+			}
+			batchCount++
+			batchSize += common.StorageSize(c.size)
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		count += batchCount
+		size += batchSize
+		candidates, pending = candidates[:0], 0
+		return nil
+	}
+
+	it := snap.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		key := it.Key()
+		if len(key) != common.HashLength || sp.isMarked(common.BytesToHash(key)) {
+			continue
+		}
+		if !bytes.Equal(crypto.Keccak256(it.Value()), key) {
+			continue
+		}
+		candidates = append(candidates, candidate{key: common.CopyBytes(key), size: len(key) + len(it.Value())})
+		pending += len(key)
+		if pending >= kaidb.IdealBatchSize {
+			if err := flush(); err != nil {
+				return count, size, err
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		return count, size, err
+	}
+	if err := flush(); err != nil {
+		return count, size, err
+	}
+	return count, size, nil
+}
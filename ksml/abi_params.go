@@ -0,0 +1,307 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ksml
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/common/types/ref"
+
+	"github.com/kardiachain/go-kardia/kai/accounts/abi"
+	"github.com/kardiachain/go-kardia/lib/common"
+)
+
+var bigIntType = reflect.TypeOf(&big.Int{})
+
+// isCompositeType reports whether t is an array, a slice or a tuple.
+func isCompositeType(t abi.Type) bool {
+	return t.T == abi.SliceTy || t.T == abi.ArrayTy || t.T == abi.TupleTy
+}
+
+// convertABIValue converts val into the Go type accepted by lib/abi for t.
+// Arrays and tuples can be given as a list (CEL list, slice or array), a map keyed by tuple field names,
+// or a string in bracketed or CSV syntax, eg: "[1,2,3]", "1,2,3", "(0x0A,[1,2],true)".
+func convertABIValue(t abi.Type, val interface{}) (interface{}, error) {
+	if r, ok := val.(ref.Val); ok {
+		val = r.Value()
+	}
+	if val == nil {
+		return nil, paramValueNotCorrect
+	}
+	typ := t.GetType()
+	if reflect.TypeOf(val) == typ {
+		return val, nil
+	}
+	switch t.T {
+	case abi.SliceTy, abi.ArrayTy:
+		elems, err := abiListElements(val)
+		if err != nil {
+			return nil, err
+		}
+		var result reflect.Value
+		if t.T == abi.SliceTy {
+			result = reflect.MakeSlice(typ, len(elems), len(elems))
+		} else {
+			if len(elems) != t.Size {
+				return nil, fmt.Errorf("invalid length of %v, expect %v got %v", t.String(), t.Size, len(elems))
+			}
+			result = reflect.New(typ).Elem()
+		}
+		for i, elem := range elems {
+			v, err := convertABIValue(*t.Elem, elem)
+			if err != nil {
+				return nil, err
+			}
+			result.Index(i).Set(reflect.ValueOf(v))
+		}
+		return result.Interface(), nil
+	case abi.TupleTy:
+		elems, err := abiTupleElements(t, val)
+		if err != nil {
+			return nil, err
+		}
+		result := reflect.New(typ).Elem()
+		for i, elem := range elems {
+			v, err := convertABIValue(*t.TupleElems[i], elem)
+			if err != nil {
+				return nil, err
+			}
+			result.Field(i).Set(reflect.ValueOf(v))
+		}
+		return result.Interface(), nil
+	case abi.BytesTy, abi.FixedBytesTy:
+		b, err := abiBytes(val)
+		if err != nil {
+			return nil, err
+		}
+		if t.T == abi.BytesTy {
+			return b, nil
+		}
+		if len(b) != t.Size {
+			return nil, paramValueNotCorrect
+		}
+		result := reflect.New(typ).Elem()
+		reflect.Copy(result, reflect.ValueOf(b))
+		return result.Interface(), nil
+	}
+
+	str, err := InterfaceToString(val)
+	if err != nil {
+		return nil, err
+	}
+	switch t.T {
+	case abi.StringTy:
+		return str, nil
+	case abi.BoolTy:
+		return strconv.ParseBool(str)
+	case abi.AddressTy:
+		if !common.IsHexAddress(str) {
+			return nil, fmt.Errorf("invalid address %v", str)
+		}
+		return common.HexToAddress(str), nil
+	case abi.IntTy, abi.UintTy:
+		if typ == bigIntType {
+			result, ok := big.NewInt(0).SetString(str, 10)
+			if !ok {
+				return nil, fmt.Errorf("cannot convert %v to big.Int", str)
+			}
+			return result, nil
+		}
+		if t.T == abi.UintTy {
+			result, err := strconv.ParseUint(str, 10, typ.Bits())
+			if err != nil {
+				return nil, err
+			}
+			return reflect.ValueOf(result).Convert(typ).Interface(), nil
+		}
+		result, err := strconv.ParseInt(str, 10, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(result).Convert(typ).Interface(), nil
+	}
+	return nil, unsupportedType
+}
+
+// abiListElements returns elements of val which is either a list or a string in bracketed or CSV syntax.
+func abiListElements(val interface{}) ([]interface{}, error) {
+	if str, ok := val.(string); ok {
+		return splitABIList(str)
+	}
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("invalid list type, expect slice or array, got %v", v.Kind().String())
+	}
+	elems := make([]interface{}, v.Len())
+	for i := range elems {
+		elems[i] = v.Index(i).Interface()
+	}
+	return elems, nil
+}
+
+// abiTupleElements returns fields of tuple t in order from val which is either a list, a string or a map keyed by
+// field names.
+func abiTupleElements(t abi.Type, val interface{}) ([]interface{}, error) {
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Map {
+		elems, err := abiListElements(val)
+		if err != nil {
+			return nil, err
+		}
+		if len(elems) != len(t.TupleElems) {
+			return nil, fmt.Errorf("invalid number of fields of %v, expect %v got %v", t.String(), len(t.TupleElems), len(elems))
+		}
+		return elems, nil
+	}
+	fields := make(map[string]interface{}, v.Len())
+	for _, key := range v.MapKeys() {
+		name, err := InterfaceToString(key.Interface())
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = v.MapIndex(key).Interface()
+	}
+	elems := make([]interface{}, len(t.TupleElems))
+	for i, name := range t.TupleRawNames {
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("field %v of %v is not found", name, t.String())
+		}
+		elems[i] = field
+	}
+	return elems, nil
+}
+
+// abiBytes converts val into bytes. Strings with 0x prefix are decoded as hex, other strings are used as they are.
+func abiBytes(val interface{}) ([]byte, error) {
+	if b, ok := val.([]byte); ok {
+		return b, nil
+	}
+	str, err := InterfaceToString(val)
+	if err != nil {
+		return nil, err
+	}
+	if len(str) >= 2 && str[0] == '0' && (str[1] == 'x' || str[1] == 'X') {
+		return common.Decode(str)
+	}
+	return []byte(str), nil
+}
+
+// splitABIList splits a string in bracketed or CSV syntax into its top level elements, eg: "[1,[2,3],(4,5)]" returns
+// "1", "[2,3]" and "(4,5)". Elements can be quoted to contain commas or brackets, quotes are removed.
+func splitABIList(str string) ([]interface{}, error) {
+	str = strings.TrimSpace(str)
+	if len(str) >= 2 && ((str[0] == '[' && str[len(str)-1] == ']') || (str[0] == '(' && str[len(str)-1] == ')')) {
+		str = strings.TrimSpace(str[1 : len(str)-1])
+	}
+	elems := make([]interface{}, 0)
+	if str == "" {
+		return elems, nil
+	}
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("invalid list %v", str)
+			}
+		case c == ',' && depth == 0:
+			elems = append(elems, unquoteABIElement(str[start:i]))
+			start = i + 1
+		}
+	}
+	if depth != 0 || quote != 0 {
+		return nil, fmt.Errorf("invalid list %v", str)
+	}
+	return append(elems, unquoteABIElement(str[start:])), nil
+}
+
+func unquoteABIElement(elem string) string {
+	elem = strings.TrimSpace(elem)
+	if len(elem) >= 2 && (elem[0] == '"' || elem[0] == '\'') && elem[len(elem)-1] == elem[0] {
+		return elem[1 : len(elem)-1]
+	}
+	return elem
+}
+
+// abiValueToString converts a value decoded by lib/abi into a string which can be used in message.params.
+// Addresses, hashes and bytes are hex encoded, arrays are formatted as "[a,b]" and tuples as "(a,b)" so that they
+// can be converted back by convertABIValue.
+func abiValueToString(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case *big.Int:
+		return v.String(), nil
+	case common.Address:
+		return v.Hex(), nil
+	case common.Hash:
+		return v.Hex(), nil
+	case []byte:
+		return common.Encode(v), nil
+	}
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return common.Encode(b), nil
+		}
+		return joinABIValues(v.Len(), v.Index, "[", "]")
+	case reflect.Slice:
+		return joinABIValues(v.Len(), v.Index, "[", "]")
+	case reflect.Struct:
+		return joinABIValues(v.NumField(), v.Field, "(", ")")
+	}
+	return InterfaceToString(val)
+}
+
+func joinABIValues(n int, get func(int) reflect.Value, open, close string) (string, error) {
+	elems := make([]string, n)
+	for i := range elems {
+		str, err := abiValueToString(get(i).Interface())
+		if err != nil {
+			return "", err
+		}
+		// quote strings which would be split by splitABIList
+		if get(i).Kind() == reflect.String && strings.ContainsAny(str, ",[]()\"'") {
+			quote := `"`
+			if strings.Contains(str, quote) {
+				quote = "'"
+			}
+			str = quote + str + quote
+		}
+		elems[i] = str
+	}
+	return open + strings.Join(elems, ",") + close, nil
+}
@@ -0,0 +1,112 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ksml
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+const (
+	BudgetSteps     = "steps"     // number of evaluated patterns exceeds Budget.MaxSteps
+	BudgetCELTime   = "celTime"   // a CEL evaluation takes longer than Budget.MaxCELTime
+	BudgetCallDepth = "callDepth" // nested fn:call exceeds Budget.MaxCallDepth
+)
+
+// Budget limits the execution of patterns by a parser, zero values mean unlimited.
+type Budget struct {
+	MaxSteps     int           // maximum number of evaluated patterns, including patterns of nested blocks and loop iterations
+	MaxCELTime   time.Duration // maximum duration of a single CEL evaluation
+	MaxCallDepth int           // maximum depth of nested fn:call
+}
+
+// DefaultBudget is the budget of new parsers.
+var DefaultBudget = Budget{
+	MaxSteps:     10000,
+	MaxCELTime:   time.Second,
+	MaxCallDepth: 32,
+}
+
+// BudgetExceededError is returned when the execution of patterns exceeds a limit of the parser's budget.
+type BudgetExceededError struct {
+	Limit   string      // BudgetSteps, BudgetCELTime or BudgetCallDepth
+	Max     interface{} // value of the exceeded limit
+	Pattern string      // pattern being executed
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("execution budget exceeded: %v limit %v reached at %v", e.Limit, e.Max, e.Pattern)
+}
+
+// budgetUsage is the budget used by a parser and its nested parsers.
+type budgetUsage struct {
+	steps     int
+	callDepth int
+}
+
+// useStep counts pattern as evaluated.
+func (p *Parser) useStep(pattern string) error {
+	p.usage.steps++
+	if p.Budget.MaxSteps > 0 && p.usage.steps > p.Budget.MaxSteps {
+		return &BudgetExceededError{Limit: BudgetSteps, Max: p.Budget.MaxSteps, Pattern: pattern}
+	}
+	return nil
+}
+
+// enterCall increases depth of nested fn:call, exitCall must be called when the function returns.
+func (p *Parser) enterCall(method string) error {
+	if p.Budget.MaxCallDepth > 0 && p.usage.callDepth >= p.Budget.MaxCallDepth {
+		return &BudgetExceededError{Limit: BudgetCallDepth, Max: p.Budget.MaxCallDepth, Pattern: fmt.Sprintf("%v:%v(%v)", builtInFn, callFunc, method)}
+	}
+	p.usage.callDepth++
+	return nil
+}
+
+func (p *Parser) exitCall() {
+	p.usage.callDepth--
+}
+
+// evalCEL evaluates prg within Budget.MaxCELTime. CEL evaluation cannot be interrupted, if it times out, it keeps
+// running in background until it is done but its result is dropped.
+func (p *Parser) evalCEL(prg cel.Program, evalArg map[string]interface{}, src string) (ref.Val, error) {
+	if p.Budget.MaxCELTime <= 0 {
+		out, _, err := prg.Eval(evalArg)
+		return out, err
+	}
+	type result struct {
+		out ref.Val
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		out, _, err := prg.Eval(evalArg)
+		done <- result{out, err}
+	}()
+	timer := time.NewTimer(p.Budget.MaxCELTime)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.out, r.err
+	case <-timer.C:
+		return nil, &BudgetExceededError{Limit: BudgetCELTime, Max: p.Budget.MaxCELTime, Pattern: src}
+	}
+}
@@ -19,6 +19,7 @@
 package ksml
 
 import (
+	"errors"
 	"fmt"
 	"github.com/kardiachain/go-kardia/dualnode/message"
 	"reflect"
@@ -44,6 +45,14 @@ func init() {
 		endForEach:         emptyFunc,
 		addVarFunc:         addVar,
 		forEachFunc:        forEach,
+		whileFunc:          executeWhile,
+		endWhile:           emptyFunc,
+		breakFunc:          breakLoop,
+		continueFunc:       continueLoop,
+		tryFunc:            executeTry,
+		catchFunc:          emptyFunc,
+		finallyFunc:        emptyFunc,
+		endTry:             emptyFunc,
 		splitFunc:          split,
 		defineFunc:         defineFunction,
 		endDefineFunc:      emptyFunc,
@@ -60,6 +69,16 @@ func init() {
 		format:             FormatFloat,
 		round:              Round,
 		replaceFunc:        Replace,
+		jsonParseFunc:      jsonParse,
+		jsonGetFunc:        jsonGet,
+		jsonStringFunc:     jsonString,
+		mapFunc:            executeMap,
+		filterFunc:         executeFilter,
+		reduceFunc:         executeReduce,
+		keccak256Func:      Keccak256,
+		sha256Func:         Sha256,
+		verifySigFunc:      VerifySig,
+		recoverFunc:        Recover,
 	}
 }
 
@@ -186,6 +205,9 @@ func executeIf(p *Parser, extras ...interface{}) ([]interface{}, error) {
 	for _, cond := range listCond {
 		// if cond is el
 		if strings.Contains(cond, el) {
+			if p.simulation != nil {
+				p.simulation.addBranch(p, name, el)
+			}
 			return parseBlockPatterns(p, patternBlocks[cond], nil)
 		} else {
 			val, err := p.handleContent(cond)
@@ -196,10 +218,16 @@ func executeIf(p *Parser, extras ...interface{}) ([]interface{}, error) {
 				return nil, incorrectReturnedValueInIFFunc
 			}
 			if val[0].(bool) {
+				if p.simulation != nil {
+					p.simulation.addBranch(p, name, cond)
+				}
 				return parseBlockPatterns(p, patternBlocks[cond], nil)
 			}
 		}
 	}
+	if p.simulation != nil {
+		p.simulation.addBranch(p, name, "")
+	}
 	return nil, nil
 }
 
@@ -221,9 +249,20 @@ func parseBlockPatterns(p *Parser, patterns []string, extrasVar map[string]inter
 	for k, v := range p.UserDefinedFunction {
 		newParser.UserDefinedFunction[k] = v
 	}
+	newParser.MaxLoopIterations = p.MaxLoopIterations
+	newParser.Budget = p.Budget
+	newParser.usage = p.usage
+	newParser.depth = p.depth + 1
+	newParser.library = p.library
+	if p.simulation != nil {
+		// nested blocks are simulated on the same state copy
+		newParser.StateDb = p.StateDb
+		newParser.simulation = p.simulation
+	}
 
 	err := newParser.ParseParams()
-	if err != nil {
+	ctrl, isLoopControl := err.(*loopControl)
+	if err != nil && !isLoopControl {
 		return nil, err
 	}
 	// update updated variables in newParser
@@ -232,6 +271,11 @@ func parseBlockPatterns(p *Parser, patterns []string, extrasVar map[string]inter
 			p.UserDefinedVariables[k] = v
 		}
 	}
+	if isLoopControl {
+		// pass processed params along with break/continue signal to the while loop
+		ctrl.params = newParser.GlobalParams
+		return nil, ctrl
+	}
 	return newParser.GlobalParams, nil
 }
 
@@ -294,6 +338,172 @@ func forEach(p *Parser, extras ...interface{}) ([]interface{}, error) {
 	return results, nil
 }
 
+// executeWhile executes all logics inside while(name, condition)...endWhile(name) pair as long as condition is true.
+// fn:break(name) and fn:continue(name) can be used to quit the loop or skip to its next iteration.
+func executeWhile(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 2 {
+		return nil, invalidWhileParam
+	}
+	name, condition := extras[0].(string), extras[1].(string)
+
+	newPatterns := make([]string, 0)
+	validWhile := false
+	for _, pattern := range p.GlobalPatterns[p.Pc+1:] {
+		if strings.Contains(pattern, name) && strings.Contains(pattern, endWhile) {
+			_, method, results, err := p.GetPrefix(strings.ReplaceAll(strings.ReplaceAll(pattern, "}", ""), "${", ""))
+			if err != nil {
+				return nil, err
+			}
+			if method == endWhile && len(results) > 0 && results[0] == name {
+				// move program counter to the next position then break
+				p.Pc++
+				validWhile = true
+				break
+			}
+		}
+		newPatterns = append(newPatterns, pattern)
+		p.Pc++
+	}
+	if !validWhile {
+		return nil, invalidWhileStatement
+	}
+
+	results := make([]interface{}, 0)
+	for i := 0; ; i++ {
+		val, err := p.handleContent(condition)
+		if err != nil {
+			return nil, err
+		}
+		if len(val) != 1 || reflect.TypeOf(val[0]).Kind() != reflect.Bool {
+			return nil, incorrectReturnedValueInWhile
+		}
+		if !val[0].(bool) {
+			break
+		}
+		if i >= p.MaxLoopIterations {
+			return nil, fmt.Errorf("while loop %v exceeds maximum %v iterations", name, p.MaxLoopIterations)
+		}
+		val, err = parseBlockPatterns(p, newPatterns, nil)
+		if ctrl, ok := err.(*loopControl); ok {
+			results = append(results, ctrl.params...)
+			if ctrl.name != name {
+				// signal belongs to an outer loop
+				ctrl.params = results
+				return nil, ctrl
+			}
+			if ctrl.signal == breakFunc {
+				break
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(val) > 0 {
+			results = append(results, val...)
+		}
+	}
+	return results, nil
+}
+
+// breakLoop quits the while loop with the given name: fn:break(name)
+func breakLoop(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 1 {
+		return nil, invalidLoopControlParam
+	}
+	return nil, &loopControl{name: extras[0].(string), signal: breakFunc}
+}
+
+// continueLoop skips the rest of the current iteration of the while loop with the given name: fn:continue(name)
+func continueLoop(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 1 {
+		return nil, invalidLoopControlParam
+	}
+	return nil, &loopControl{name: extras[0].(string), signal: continueFunc}
+}
+
+// executeTry executes try blocks. a try structure is start with fn:try(name)...fn:catch(name, errVar)...fn:finally(name)...fn:endTry(name)
+// where catch and finally are optional. If try block fails, its params are dropped, the error message is saved into errVar
+// and catch block is executed instead. finally block is always executed afterwards, except when SIGNAL_STOP is applied.
+func executeTry(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 1 {
+		return nil, invalidTryParam
+	}
+	name := extras[0].(string)
+
+	// split patterns into try, catch and finally blocks, they must be in that order.
+	blocks := map[string][]string{tryFunc: {}}
+	order := []string{tryFunc, catchFunc, finallyFunc, endTry}
+	current := 0
+	errVar := ""
+	validTryStatement := false
+	for _, pattern := range p.GlobalPatterns[p.Pc+1:] {
+		if strings.Contains(pattern, name) && (strings.Contains(pattern, catchFunc) ||
+			strings.Contains(pattern, finallyFunc) || strings.Contains(pattern, endTry)) {
+			_, method, results, err := p.GetPrefix(strings.ReplaceAll(strings.ReplaceAll(pattern, "}", ""), "${", ""))
+			if err != nil {
+				return nil, err
+			}
+			if len(results) > 0 && results[0] == name && (method == catchFunc || method == finallyFunc || method == endTry) {
+				next := current + 1
+				for order[next] != method {
+					next++
+					if next == len(order) {
+						return nil, invalidTryStatement
+					}
+				}
+				current = next
+				if method == endTry {
+					// move program counter to the next position then break
+					p.Pc++
+					validTryStatement = true
+					break
+				}
+				if method == catchFunc {
+					if len(results) != 2 {
+						return nil, invalidTryParam
+					}
+					errVar = results[1]
+				}
+				blocks[method] = make([]string, 0)
+				p.Pc++
+				continue
+			}
+		}
+		blocks[order[current]] = append(blocks[order[current]], pattern)
+		p.Pc++
+	}
+	if !validTryStatement { // cannot find endTry
+		return nil, invalidTryStatement
+	}
+
+	results, err := parseBlockPatterns(p, blocks[tryFunc], nil)
+	var budgetErr *BudgetExceededError
+	if _, isLoopControl := err.(*loopControl); err != nil && !isLoopControl && !errors.Is(err, stopSignal) && !errors.As(err, &budgetErr) {
+		if catchPatterns, ok := blocks[catchFunc]; ok {
+			p.UserDefinedVariables[errVar] = err.Error()
+			results, err = parseBlockPatterns(p, catchPatterns, nil)
+		}
+	}
+	if errors.Is(err, stopSignal) {
+		return nil, err
+	}
+	if finallyPatterns, ok := blocks[finallyFunc]; ok {
+		val, finallyErr := parseBlockPatterns(p, finallyPatterns, nil)
+		if finallyErr != nil {
+			return nil, finallyErr
+		}
+		if ctrl, ok := err.(*loopControl); ok {
+			ctrl.params = append(ctrl.params, val...)
+		}
+		results = append(results, val...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 // split splits given string(maybe expression) with a separator
 func split(p *Parser, extras ...interface{}) ([]interface{}, error) {
 	if len(extras) != 2 {
@@ -400,10 +610,10 @@ func callFunction(p *Parser, extras ...interface{}) ([]interface{}, error) {
 	if len(extras) > 1 {
 		args = append(args, extras[1:]...)
 	}
-	if _, ok := p.UserDefinedFunction[method]; !ok {
+	f, ok := p.findFunction(method)
+	if !ok {
 		return nil, methodNotFound
 	}
-	f := p.UserDefinedFunction[method]
 	// validate length of args
 	if len(args) != len(f.args) {
 		return nil, invalidVariables
@@ -419,6 +629,14 @@ func callFunction(p *Parser, extras ...interface{}) ([]interface{}, error) {
 			vars[arg] = val[0]
 		}
 	}
+	if err := p.enterCall(method); err != nil {
+		return nil, err
+	}
+	defer p.exitCall()
+	// patterns of a library function can call other functions of the library without namespace
+	library := p.library
+	p.library = f.library
+	defer func() { p.library = library }()
 	results, err := parseBlockPatterns(p, f.patterns, vars)
 	if err != nil {
 		return nil, err
@@ -540,6 +758,10 @@ func publishFunc(p *Parser, extras ...interface{}) ([]interface{}, error) {
 		}
 	}
 	msg.CallBacks = callBacks
+	if p.simulation != nil {
+		p.simulation.result.Published = append(p.simulation.result.Published, msg)
+		return nil, nil
+	}
 	if err := p.PublishFunction(p.PublishEndpoint, KARDIA_CALL, *msg); err != nil {
 		return nil, err
 	}
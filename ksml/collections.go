@@ -0,0 +1,145 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ksml
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// executeMap applies expr to every element of list and saves returned values into a new list variable: fn:map(name, list, expr)
+// expr can use ITEM and INDEX variables to access current element and its position.
+func executeMap(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 3 {
+		return nil, fmt.Errorf("invalid arguments for map function, expect 3 got %v", len(extras))
+	}
+	name, expr := extras[0].(string), extras[2].(string)
+	list, err := evalList(p, extras[1].(string))
+	if err != nil {
+		return nil, err
+	}
+	results := make([]interface{}, 0, len(list))
+	for i, item := range list {
+		val, err := evalWithVars(p, expr, map[string]interface{}{itemVar: item, indexVar: i})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, val)
+	}
+	p.UserDefinedVariables[name] = results
+	return nil, nil
+}
+
+// executeFilter saves elements of list which make expr returns true into a new list variable: fn:filter(name, list, expr)
+// expr can use ITEM and INDEX variables to access current element and its position.
+func executeFilter(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 3 {
+		return nil, fmt.Errorf("invalid arguments for filter function, expect 3 got %v", len(extras))
+	}
+	name, expr := extras[0].(string), extras[2].(string)
+	list, err := evalList(p, extras[1].(string))
+	if err != nil {
+		return nil, err
+	}
+	results := make([]interface{}, 0)
+	for i, item := range list {
+		val, err := evalWithVars(p, expr, map[string]interface{}{itemVar: item, indexVar: i})
+		if err != nil {
+			return nil, err
+		}
+		if reflect.TypeOf(val).Kind() != reflect.Bool {
+			return nil, incorrectReturnedValueInFilter
+		}
+		if val.(bool) {
+			results = append(results, item)
+		}
+	}
+	p.UserDefinedVariables[name] = results
+	return nil, nil
+}
+
+// executeReduce accumulates elements of list into a single value and saves it into a variable: fn:reduce(name, list, acc, expr)
+// acc is the initial value, expr can use ACC, ITEM and INDEX variables to access accumulated value, current element and its position.
+func executeReduce(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 4 {
+		return nil, fmt.Errorf("invalid arguments for reduce function, expect 4 got %v", len(extras))
+	}
+	name, expr := extras[0].(string), extras[3].(string)
+	list, err := evalList(p, extras[1].(string))
+	if err != nil {
+		return nil, err
+	}
+	acc, err := evalWithVars(p, extras[2].(string), nil)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range list {
+		if acc, err = evalWithVars(p, expr, map[string]interface{}{accVar: acc, itemVar: item, indexVar: i}); err != nil {
+			return nil, err
+		}
+	}
+	p.UserDefinedVariables[name] = acc
+	return nil, nil
+}
+
+// evalList executes content and converts its returned value into a list.
+func evalList(p *Parser, content string) ([]interface{}, error) {
+	val, err := p.handleContent(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(val) == 0 {
+		return nil, fmt.Errorf("returned value is empty")
+	}
+	return interfaceToSlice(val[0])
+}
+
+// evalWithVars executes expr with given variables added into parser.UserDefinedVariables, overwritten variables are restored afterwards.
+func evalWithVars(p *Parser, expr string, vars map[string]interface{}) (interface{}, error) {
+	backup := make(map[string]interface{})
+	for k, v := range vars {
+		if old, ok := p.UserDefinedVariables[k]; ok {
+			backup[k] = old
+		}
+		p.UserDefinedVariables[k] = v
+	}
+	defer func() {
+		for k := range vars {
+			if old, ok := backup[k]; ok {
+				p.UserDefinedVariables[k] = old
+			} else {
+				delete(p.UserDefinedVariables, k)
+			}
+		}
+	}()
+
+	val, err := p.handleContent(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(val) != 1 {
+		return nil, fmt.Errorf("expression %v must returns only 1 value, got %v", expr, len(val))
+	}
+	if r, ok := val[0].(ref.Val); ok {
+		return r.Value(), nil
+	}
+	return val[0], nil
+}
@@ -0,0 +1,262 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ksml
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+)
+
+// builtInSpec describes the arguments of a built-in function.
+type builtInSpec struct {
+	minArgs  int
+	maxArgs  int   // negative if the number of arguments is unlimited
+	exprArgs []int // positions of arguments which are evaluated as CEL expressions or nested built-in functions
+}
+
+var builtInSpecs = map[string]builtInSpec{
+	ping:               {0, -1, nil},
+	currentTimeStamp:   {0, 0, nil},
+	currentBlockHeight: {0, 0, nil},
+	validate:           {3, 3, []int{0}},
+	ifFunc:             {2, 2, []int{1}},
+	elif:               {2, 2, []int{1}},
+	el:                 {1, 1, nil},
+	endIf:              {1, 1, nil},
+	addVarFunc:         {3, 3, []int{2}},
+	forEachFunc:        {3, 3, []int{1}},
+	endForEach:         {1, 1, nil},
+	whileFunc:          {2, 2, []int{1}},
+	endWhile:           {1, 1, nil},
+	breakFunc:          {1, 1, nil},
+	continueFunc:       {1, 1, nil},
+	tryFunc:            {1, 1, nil},
+	catchFunc:          {2, 2, nil},
+	finallyFunc:        {1, 1, nil},
+	endTry:             {1, 1, nil},
+	splitFunc:          {2, 2, []int{0, 1}},
+	replaceFunc:        {3, 3, []int{0, 1, 2}},
+	defineFunc:         {1, -1, nil},
+	endDefineFunc:      {1, 1, nil},
+	callFunc:           {1, -1, nil},
+	getData:            {1, -1, nil},
+	trigger:            {1, -1, nil},
+	publish:            {3, 4, []int{0, 1, 2, 3}},
+	compare:            {4, 4, []int{0, 1}},
+	mul:                {2, 2, []int{0, 1}},
+	div:                {2, 2, []int{0, 1}},
+	toInt:              {1, 1, []int{0}},
+	toFloat:            {1, 1, []int{0}},
+	exp:                {2, 2, []int{0, 1}},
+	format:             {2, 2, []int{0, 1}},
+	round:              {1, 1, []int{0}},
+	jsonParseFunc:      {2, 2, []int{1}},
+	jsonGetFunc:        {2, 2, nil},
+	jsonStringFunc:     {1, 1, []int{0}},
+	mapFunc:            {3, 3, []int{1, 2}},
+	filterFunc:         {3, 3, []int{1, 2}},
+	reduceFunc:         {4, 4, []int{1, 2, 3}},
+	keccak256Func:      {1, 1, []int{0}},
+	sha256Func:         {1, 1, []int{0}},
+	verifySigFunc:      {3, 3, []int{0, 1, 2}},
+	recoverFunc:        {2, 2, []int{0, 1}},
+}
+
+// blockStatements maps functions which open, continue or close a block statement to the function opening it.
+var blockStatements = map[string]string{
+	ifFunc:        ifFunc,
+	elif:          ifFunc,
+	el:            ifFunc,
+	endIf:         ifFunc,
+	forEachFunc:   forEachFunc,
+	endForEach:    forEachFunc,
+	whileFunc:     whileFunc,
+	endWhile:      whileFunc,
+	defineFunc:    defineFunc,
+	endDefineFunc: defineFunc,
+	tryFunc:       tryFunc,
+	catchFunc:     tryFunc,
+	finallyFunc:   tryFunc,
+	endTry:        tryFunc,
+}
+
+// blockStages is the order of functions within a block statement, a function cannot follow another one of a higher stage.
+var blockStages = map[string]int{
+	elif:        1,
+	el:          2,
+	catchFunc:   1,
+	finallyFunc: 2,
+}
+
+// CompiledPatterns is a list of patterns which have passed static validation.
+type CompiledPatterns struct {
+	Patterns    []string            // validated patterns
+	Expressions map[string]*cel.Ast // parsed CEL expressions found in patterns, keyed by their source
+}
+
+// openBlock is a block statement which has not been closed yet while compiling patterns.
+type openBlock struct {
+	kind  string
+	name  string
+	line  int
+	stage int
+}
+
+type compiler struct {
+	env       *cel.Env
+	compiled  *CompiledPatterns
+	blocks    []*openBlock
+	functions map[string]struct{}
+	calls     map[string]int
+	errs      []error
+}
+
+// Compile validates patterns without executing them: ${...} expressions are parsed, built-in functions are checked
+// against their number of arguments, block statements (if, forEach, while, try, defineFunc) must be properly opened
+// and closed, and called functions must be defined in patterns or libs. All found errors are returned with their
+// pattern positions.
+func Compile(patterns []string, libs ...*Library) (*CompiledPatterns, []error) {
+	env, err := cel.NewEnv()
+	if err != nil {
+		return nil, []error{err}
+	}
+	c := &compiler{
+		env: env,
+		compiled: &CompiledPatterns{
+			Patterns:    patterns,
+			Expressions: make(map[string]*cel.Ast),
+		},
+		functions: make(map[string]struct{}),
+		calls:     make(map[string]int),
+	}
+	for _, lib := range libs {
+		for name := range lib.functions {
+			c.functions[lib.Name+librarySeparator+name] = struct{}{}
+		}
+	}
+	for line, pattern := range patterns {
+		// patterns are executed the same way in ParseParams, the others are returned as they are
+		if len(pattern) >= elMinLength && strings.HasPrefix(pattern, "${") && strings.HasSuffix(pattern, "}") {
+			c.compileContent(line, pattern[2:len(pattern)-1], true)
+		}
+	}
+	for _, block := range c.blocks {
+		c.errs = append(c.errs, fmt.Errorf("pattern %v: %v(%v) is not closed", block.line, block.kind, block.name))
+	}
+	for name, line := range c.calls {
+		if _, ok := c.functions[name]; !ok {
+			c.errs = append(c.errs, fmt.Errorf("pattern %v: function %v is not defined", line, name))
+		}
+	}
+	if len(c.errs) > 0 {
+		return nil, c.errs
+	}
+	return c.compiled, nil
+}
+
+// compileContent validates the content of a ${...} pattern, or an argument of a built-in function.
+// Block statements can only be used at top level of a pattern.
+func (c *compiler) compileContent(line int, content string, topLevel bool) {
+	if !hasBuiltIn(content) {
+		ast, iss := c.env.Parse(content)
+		if iss != nil && iss.Err() != nil {
+			c.errs = append(c.errs, fmt.Errorf("pattern %v: invalid expression %v: %v", line, content, iss.Err()))
+			return
+		}
+		c.compiled.Expressions[content] = ast
+		return
+	}
+	_, method, args, err := getPrefix(content)
+	if err != nil {
+		c.errs = append(c.errs, fmt.Errorf("pattern %v: %v: %v", line, content, err))
+		return
+	}
+	if _, ok := BuiltInFuncMap[method]; !ok {
+		c.errs = append(c.errs, fmt.Errorf("pattern %v: unknown built-in function %v", line, method))
+		return
+	}
+	if spec, ok := builtInSpecs[method]; ok {
+		if len(args) < spec.minArgs || (spec.maxArgs >= 0 && len(args) > spec.maxArgs) {
+			c.errs = append(c.errs, fmt.Errorf("pattern %v: invalid number of arguments for %v, got %v", line, method, len(args)))
+			return
+		}
+		for _, i := range spec.exprArgs {
+			if i < len(args) {
+				c.compileContent(line, args[i], false)
+			}
+		}
+	}
+
+	switch method {
+	case callFunc:
+		if _, ok := c.calls[args[0]]; !ok {
+			c.calls[args[0]] = line
+		}
+	case defineFunc:
+		c.functions[args[0]] = struct{}{}
+	case breakFunc, continueFunc:
+		if !c.inBlock(whileFunc, args[0]) {
+			c.errs = append(c.errs, fmt.Errorf("pattern %v: %v(%v) is used outside of while loop %v", line, method, args[0], args[0]))
+		}
+	}
+
+	kind, isBlock := blockStatements[method]
+	if !isBlock {
+		return
+	}
+	if !topLevel {
+		c.errs = append(c.errs, fmt.Errorf("pattern %v: %v cannot be nested in another function", line, method))
+		return
+	}
+	name := args[0]
+	if method == kind {
+		c.blocks = append(c.blocks, &openBlock{kind: kind, name: name, line: line})
+		return
+	}
+	if len(c.blocks) == 0 {
+		c.errs = append(c.errs, fmt.Errorf("pattern %v: %v(%v) does not match any %v", line, method, name, kind))
+		return
+	}
+	top := c.blocks[len(c.blocks)-1]
+	if top.kind != kind || top.name != name {
+		c.errs = append(c.errs, fmt.Errorf("pattern %v: %v(%v) does not match %v(%v) opened at pattern %v", line, method, name, top.kind, top.name, top.line))
+		return
+	}
+	if stage, ok := blockStages[method]; ok {
+		if stage < top.stage || (stage == top.stage && method != elif) {
+			c.errs = append(c.errs, fmt.Errorf("pattern %v: %v(%v) is out of order", line, method, name))
+		}
+		top.stage = stage
+		return
+	}
+	// block is closed
+	c.blocks = c.blocks[:len(c.blocks)-1]
+}
+
+// inBlock reports whether a block of kind with name is opened.
+func (c *compiler) inBlock(kind, name string) bool {
+	for _, block := range c.blocks {
+		if block.kind == kind && block.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ksml
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/google/cel-go/common/types/ref"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+)
+
+const (
+	hashLength      = 32
+	signatureLength = 65 // [R || S || V] format
+)
+
+// Keccak256 returns keccak256 hash of the value returned by expr as a hex string: fn:keccak256(expr)
+func Keccak256(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 1 {
+		return nil, fmt.Errorf("invalid arguments for keccak256 function, expect 1 got %v", len(extras))
+	}
+	data, err := evalBytes(p, extras[0].(string))
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{common.Encode(crypto.Keccak256(data))}, nil
+}
+
+// Sha256 returns sha256 hash of the value returned by expr as a hex string: fn:sha256(expr)
+func Sha256(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 1 {
+		return nil, fmt.Errorf("invalid arguments for sha256 function, expect 1 got %v", len(extras))
+	}
+	data, err := evalBytes(p, extras[0].(string))
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(data)
+	return []interface{}{common.Encode(hash[:])}, nil
+}
+
+// VerifySig returns true if signature over hash is created by address: fn:verifySig(address, hash, signature)
+func VerifySig(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 3 {
+		return nil, fmt.Errorf("invalid arguments for verifySig function, expect 3 got %v", len(extras))
+	}
+	val, err := p.handleContent(extras[0].(string))
+	if err != nil {
+		return nil, err
+	}
+	if len(val) == 0 {
+		return nil, fmt.Errorf("returned value is empty")
+	}
+	addr, err := InterfaceToString(val[0])
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(addr) {
+		return nil, fmt.Errorf("invalid address %v", addr)
+	}
+	signer, err := recoverSigner(p, extras[1].(string), extras[2].(string))
+	if err == invalidSignature {
+		return []interface{}{false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{signer == common.HexToAddress(addr)}, nil
+}
+
+// Recover returns the address which created signature over hash: fn:recover(hash, signature)
+func Recover(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 2 {
+		return nil, fmt.Errorf("invalid arguments for recover function, expect 2 got %v", len(extras))
+	}
+	signer, err := recoverSigner(p, extras[0].(string), extras[1].(string))
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{signer.Hex()}, nil
+}
+
+// recoverSigner recovers the address which created the signature returned by sigExpr over the hash returned by hashExpr.
+// V of the signature can be either 0/1 or 27/28.
+func recoverSigner(p *Parser, hashExpr, sigExpr string) (common.Address, error) {
+	hash, err := evalBytes(p, hashExpr)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(hash) != hashLength {
+		return common.Address{}, fmt.Errorf("invalid hash length, expect %v got %v", hashLength, len(hash))
+	}
+	sig, err := evalBytes(p, sigExpr)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(sig) != signatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length, expect %v got %v", signatureLength, len(sig))
+	}
+	sig = common.CopyBytes(sig)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, invalidSignature
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// evalBytes executes content and converts its returned value into bytes.
+// Strings with 0x prefix are decoded as hex, other values are converted into their string representation.
+func evalBytes(p *Parser, content string) ([]byte, error) {
+	val, err := p.handleContent(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(val) == 0 {
+		return nil, fmt.Errorf("returned value is empty")
+	}
+	v := val[0]
+	if r, ok := v.(ref.Val); ok {
+		v = r.Value()
+	}
+	switch b := v.(type) {
+	case []byte:
+		return b, nil
+	case common.Hash:
+		return b.Bytes(), nil
+	}
+	str, err := InterfaceToString(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(str) >= 2 && str[0] == '0' && (str[1] == 'x' || str[1] == 'X') {
+		return common.Decode(str)
+	}
+	return []byte(str), nil
+}
@@ -0,0 +1,191 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ksml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// jsonParse decodes a JSON string returned by expr and saves it into a user defined variable: fn:jsonParse(var, expr)
+// Objects are decoded as map[string]interface{}, arrays as []interface{}. Integer numbers which do not fit in int64 are
+// decoded as decimal strings to keep their precision.
+func jsonParse(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 2 {
+		return nil, fmt.Errorf("invalid arguments for jsonParse function, expect 2 got %v", len(extras))
+	}
+	varName := extras[0].(string)
+	val, err := p.handleContent(extras[1].(string))
+	if err != nil {
+		return nil, err
+	}
+	if len(val) == 0 {
+		return nil, fmt.Errorf("returned value is empty")
+	}
+	str, err := InterfaceToString(val[0])
+	if err != nil {
+		return nil, err
+	}
+	data, err := decodeJSON(str)
+	if err != nil {
+		return nil, err
+	}
+	p.UserDefinedVariables[varName] = data
+	return nil, nil
+}
+
+// jsonGet returns the value of a json variable at the given path: fn:jsonGet(var, path)
+// path is a list of keys separated by dots with optional array indexes, eg: $.data.items[0].amount
+// var can also be a JSON string which is decoded before the lookup.
+func jsonGet(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 2 {
+		return nil, fmt.Errorf("invalid arguments for jsonGet function, expect 2 got %v", len(extras))
+	}
+	varName, path := extras[0].(string), strings.Trim(extras[1].(string), `'"`)
+	data, ok := p.UserDefinedVariables[varName]
+	if !ok {
+		return nil, variableNotFound
+	}
+	if str, ok := data.(string); ok {
+		var err error
+		if data, err = decodeJSON(str); err != nil {
+			return nil, err
+		}
+	}
+	selectors, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, selector := range selectors {
+		switch s := selector.(type) {
+		case string:
+			obj, ok := data.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("json path %v not found: %v is not an object", path, s)
+			}
+			if data, ok = obj[s]; !ok {
+				return nil, fmt.Errorf("json path %v not found: missing key %v", path, s)
+			}
+		case int:
+			arr, ok := data.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("json path %v not found: [%v] is not an array", path, s)
+			}
+			if s >= len(arr) {
+				return nil, fmt.Errorf("json path %v not found: index %v out of range", path, s)
+			}
+			data = arr[s]
+		}
+	}
+	return []interface{}{data}, nil
+}
+
+// jsonString encodes the value returned by expr into a JSON string: fn:jsonString(expr)
+func jsonString(p *Parser, extras ...interface{}) ([]interface{}, error) {
+	if len(extras) != 1 {
+		return nil, fmt.Errorf("invalid arguments for jsonString function, expect 1 got %v", len(extras))
+	}
+	val, err := p.handleContent(extras[0].(string))
+	if err != nil {
+		return nil, err
+	}
+	if len(val) == 0 {
+		return nil, fmt.Errorf("returned value is empty")
+	}
+	v := val[0]
+	if r, ok := v.(ref.Val); ok {
+		v = r.Value()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{string(data)}, nil
+}
+
+func decodeJSON(str string) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(str)))
+	decoder.UseNumber()
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("invalid json: %v", err)
+	}
+	return convertJSONNumbers(data), nil
+}
+
+// convertJSONNumbers replaces json.Number with int64, float64 or decimal string for big integers.
+func convertJSONNumbers(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for k, el := range v {
+			v[k] = convertJSONNumbers(el)
+		}
+	case []interface{}:
+		for i, el := range v {
+			v[i] = convertJSONNumbers(el)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if _, ok := big.NewInt(0).SetString(v.String(), 10); ok {
+			return v.String()
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	}
+	return data
+}
+
+// parseJSONPath splits path into keys (string) and array indexes (int).
+func parseJSONPath(path string) ([]interface{}, error) {
+	path = strings.TrimPrefix(path, "$")
+	selectors := make([]interface{}, 0)
+	for _, part := range strings.Split(path, ".") {
+		key := part
+		if i := strings.Index(part, "["); i >= 0 {
+			key = part[:i]
+		}
+		if key != "" {
+			selectors = append(selectors, key)
+		}
+		rest := part[len(key):]
+		for rest != "" {
+			end := strings.Index(rest, "]")
+			if !strings.HasPrefix(rest, "[") || end < 0 {
+				return nil, fmt.Errorf("invalid json path %v", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid json path %v", path)
+			}
+			selectors = append(selectors, index)
+			rest = rest[end+1:]
+		}
+	}
+	return selectors, nil
+}
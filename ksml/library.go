@@ -0,0 +1,130 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ksml
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// librarySeparator separates library name and function name in fn:call, eg: fn:call(lib.sum, a, b)
+const librarySeparator = "."
+
+// Library is a named set of functions defined with fn:defineFunc which can be shared by parsers.
+// Functions of a library are called with their namespaced name: fn:call(libName.funcName, args...)
+// Within a library, its functions can also be called without the namespace.
+type Library struct {
+	Name      string
+	functions map[string]*function
+}
+
+// LoadLibrary reads function definitions from patterns. patterns must only contain
+// fn:defineFunc(...)...fn:endDefineFunc(...) blocks.
+func LoadLibrary(name string, patterns []string) (*Library, error) {
+	if name == "" || strings.Contains(name, librarySeparator) {
+		return nil, fmt.Errorf("invalid library name %q", name)
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("library %v: %w", name, sourceIsEmpty)
+	}
+	// patterns are copied since defineFunction removes function definitions from GlobalPatterns
+	p := &Parser{
+		GlobalPatterns:       append([]string{}, patterns...),
+		UserDefinedFunction:  make(map[string]*function),
+		UserDefinedVariables: make(map[string]interface{}),
+		usage:                &budgetUsage{},
+	}
+	// addFunction returns sourceIsEmpty once all patterns are removed
+	if err := p.addFunction(); err != nil && err != sourceIsEmpty {
+		return nil, fmt.Errorf("library %v: %w", name, err)
+	}
+	// patterns of defined functions are removed by addFunction
+	if len(p.GlobalPatterns) > 0 {
+		return nil, fmt.Errorf("library %v: pattern %v is not in a function definition", name, p.GlobalPatterns[0])
+	}
+	lib := &Library{
+		Name:      name,
+		functions: make(map[string]*function, len(p.UserDefinedFunction)),
+	}
+	for fname, f := range p.UserDefinedFunction {
+		if strings.Contains(fname, librarySeparator) {
+			return nil, fmt.Errorf("library %v: invalid function name %v", name, fname)
+		}
+		f.library = name
+		lib.functions[fname] = f
+	}
+	return lib, nil
+}
+
+// LoadLibraryFile reads a library from a file which has one pattern per line.
+// Empty lines and lines starting with # are ignored.
+func LoadLibraryFile(name, path string) (*Library, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	patterns := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return LoadLibrary(name, patterns)
+}
+
+// Functions returns the names of functions defined in the library.
+func (l *Library) Functions() []string {
+	names := make([]string, 0, len(l.functions))
+	for name := range l.functions {
+		names = append(names, name)
+	}
+	return names
+}
+
+// addLibraries adds functions of libs into p.UserDefinedFunction with their namespaced name.
+func (p *Parser) addLibraries(libs ...*Library) {
+	for _, lib := range libs {
+		for name, f := range lib.functions {
+			p.UserDefinedFunction[lib.Name+librarySeparator+name] = f
+		}
+	}
+}
+
+// findFunction returns the user defined function called method. Within a library function, functions of the same
+// library can be called without their namespace.
+func (p *Parser) findFunction(method string) (*function, bool) {
+	if f, ok := p.UserDefinedFunction[method]; ok {
+		return f, true
+	}
+	if p.library != "" {
+		f, ok := p.UserDefinedFunction[p.library+librarySeparator+method]
+		return f, ok
+	}
+	return nil, false
+}
@@ -54,14 +54,21 @@ type Parser struct {
 	Pc                   int                    // program counter is used to count and get current read position in globalPatterns
 	Nonce                uint64
 	CanTrigger           bool
+	MaxLoopIterations    int    // maximum iterations of a while loop, patterns exceeding it fail
+	Budget               Budget // execution limits of patterns, shared with nested blocks
 	mtx                  sync.Mutex
+
+	simulation *simulation  // not nil if parser is simulating patterns, see Simulate
+	depth      int          // depth of nested blocks the parser is executing
+	library    string       // name of the library whose function is being executed by the parser
+	usage      *budgetUsage // budget used by the parser and its nested blocks
 }
 
 func NewParser(proxyName, publishedEndpoint string, publishFunction func(endpoint string, topic string, msg dualMsg.TriggerMessage) error,
 	bc base.BaseBlockChain, txPool *tx_pool.TxPool,
-	smartContractAddress *common.Address, globalPatterns []string, globalMessage *message.EventMessage, canTrigger bool) *Parser {
+	smartContractAddress *common.Address, globalPatterns []string, globalMessage *message.EventMessage, canTrigger bool, libs ...*Library) *Parser {
 	stateDb := txPool.State()
-	return &Parser{
+	p := &Parser{
 		ProxyName:            proxyName,
 		PublishEndpoint:      publishedEndpoint,
 		PublishFunction:      publishFunction,
@@ -77,7 +84,12 @@ func NewParser(proxyName, publishedEndpoint string, publishFunction func(endpoin
 		Nonce:                0,
 		Pc:                   0,
 		CanTrigger:           canTrigger,
+		MaxLoopIterations:    DefaultMaxLoopIterations,
+		Budget:               DefaultBudget,
+		usage:                &budgetUsage{},
 	}
+	p.addLibraries(libs...)
+	return p
 }
 
 func addPrimitiveIdent(name string, v interface{}) (interface{}, *expr.Decl) {
@@ -101,7 +113,7 @@ func addPrimitiveIdent(name string, v interface{}) (interface{}, *expr.Decl) {
 		return v, decls.NewIdent(name, decls.String, nil)
 	case reflect.Bool:
 		return v, decls.NewIdent(name, decls.Bool, nil)
-	case reflect.Array, reflect.Slice, reflect.Ptr:
+	case reflect.Array, reflect.Slice, reflect.Ptr, reflect.Map:
 		return v, decls.NewIdent(name, decls.Dyn, nil)
 	default:
 		return v, nil
@@ -139,6 +151,14 @@ func (p *Parser) CEL(src string) ([]interface{}, error) {
 				evalArg[globalContractAddress] = p.SmartContractAddress.Hex()
 			case globalProxyName:
 				evalArg[globalProxyName] = p.ProxyName
+			case globalBlock:
+				evalArg[globalBlock] = p.blockContext()
+			case globalTx:
+				evalArg[globalTx] = p.txContext()
+			case globalSmc:
+				evalArg[globalSmc] = map[string]interface{}{
+					"address": p.SmartContractAddress.Hex(),
+				}
 			}
 		}
 	}
@@ -188,13 +208,37 @@ func (p *Parser) CEL(src string) ([]interface{}, error) {
 		return nil, err
 	}
 
-	out, _, err := prg.Eval(evalArg)
+	out, err := p.evalCEL(prg, evalArg, src)
 	if err != nil {
 		return nil, err
 	}
 	return []interface{}{out.Value()}, nil
 }
 
+// blockContext returns read-only attributes of current kardia block which are accessible in CEL as block.
+func (p *Parser) blockContext() map[string]interface{} {
+	block := p.Bc.CurrentBlock()
+	return map[string]interface{}{
+		"height": int64(block.Height()),
+		"time":   block.Header().Time.Unix(),
+	}
+}
+
+// txContext returns read-only attributes of the transaction which emits GlobalMessage, they are accessible in CEL as tx.
+func (p *Parser) txContext() map[string]interface{} {
+	ctx := map[string]interface{}{
+		"hash":   "",
+		"from":   "",
+		"height": int64(0),
+	}
+	if p.GlobalMessage != nil {
+		ctx["hash"] = p.GlobalMessage.TransactionId
+		ctx["from"] = p.GlobalMessage.From
+		ctx["height"] = int64(p.GlobalMessage.BlockNumber)
+	}
+	return ctx
+}
+
 func (p *Parser) GetNonce() uint64 {
 	nonce := p.TxPool.Nonce(*p.Bc.P2P().Address())
 
@@ -215,6 +259,10 @@ func hasBuiltIn(content string) bool {
 
 // GetPrefix reads content to get prefix if any, if prefix exists, then it returns method and a list of params
 func (p *Parser) GetPrefix(content string) (string, string, []string, error) {
+	return getPrefix(content)
+}
+
+func getPrefix(content string) (string, string, []string, error) {
 	invalidBuiltInFuncSync := fmt.Errorf("invalid built-in function syntax")
 	if hasBuiltIn(content) {
 		// content has built-in function.
@@ -349,6 +397,11 @@ func (p *Parser) ParseParams() error {
 		return sourceIsEmpty
 	}
 
+	if p.depth == 0 {
+		// nested blocks share the budget used by their top level parser
+		p.usage = &budgetUsage{}
+	}
+
 	// check and add userDefinedFunction
 	if err := p.addFunction(); err != nil {
 		return err
@@ -358,15 +411,41 @@ func (p *Parser) ParseParams() error {
 		pattern := p.GlobalPatterns[p.Pc]
 		var val []interface{}
 		var err error
+		var step *SimulationStep
+		if p.simulation != nil {
+			step = p.simulation.beginStep(p, pattern)
+		}
+		if err := p.useStep(pattern); err != nil {
+			if step != nil {
+				p.simulation.endStep(p, step, nil, err)
+			}
+			return err
+		}
 		// if src is greater or equals minLength and has structure ${...} then CEL is applied
 		if len(pattern) >= elMinLength && strings.HasPrefix(pattern, "${") && strings.HasSuffix(pattern, "}") {
 			content := pattern[2 : len(pattern)-1]
 			val, err = p.handleContent(content)
+			if step != nil {
+				p.simulation.endStep(p, step, val, err)
+			}
+			if ctrl, ok := err.(*loopControl); ok {
+				// break/continue: keep processed params and let the while loop handle the signal
+				p.GlobalParams = append(p.GlobalParams, ctrl.params...)
+				ctrl.params = nil
+				return ctrl
+			}
+			if budgetErr, ok := err.(*BudgetExceededError); ok {
+				// return budget error as it is to let callers know which limit is exceeded
+				return budgetErr
+			}
 			if err != nil {
-				return fmt.Errorf("error while handling content at line %v - %v", p.Pc, err)
+				return fmt.Errorf("error while handling content at line %v - %w", p.Pc, err)
 			}
 		} else {
 			val = []interface{}{pattern}
+			if step != nil {
+				p.simulation.endStep(p, step, val, nil)
+			}
 		}
 		if val != nil && len(val) > 0 {
 			// evaluate signals
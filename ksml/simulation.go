@@ -0,0 +1,113 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ksml
+
+import (
+	dualMsg "github.com/kardiachain/go-kardia/dualnode/message"
+	message "github.com/kardiachain/go-kardia/ksml/proto"
+)
+
+// SimulationStep is the result of executing a pattern during a simulation.
+type SimulationStep struct {
+	Depth     int                    // 0 for global patterns, nested blocks (if, forEach, while...) increase it by 1
+	Line      int                    // position of the pattern in its block
+	Pattern   string                 // executed pattern
+	Results   []interface{}          // values returned by the pattern
+	Variables map[string]interface{} // snapshot of user defined variables after the pattern is executed
+	Error     string                 // error returned by the pattern if any
+}
+
+// SimulationBranch is the branch taken by an if statement during a simulation.
+type SimulationBranch struct {
+	Depth     int
+	Name      string // name of the if statement
+	Condition string // condition of the taken branch, "else" or empty if no branch is taken
+}
+
+// SimulationResult is the trace of a simulation.
+type SimulationResult struct {
+	Params       []interface{}             // params returned by all patterns
+	Steps        []*SimulationStep         // executed patterns in order, a pattern comes before patterns of its nested blocks
+	Branches     []*SimulationBranch       // branches taken by if statements in order
+	Transactions []string                  // hashes of transactions which would have been sent by smc:trigger
+	Published    []*dualMsg.TriggerMessage // messages which would have been published by fn:publish
+}
+
+// simulation collects the trace of a simulation, it is shared by a parser and its nested parsers.
+type simulation struct {
+	result *SimulationResult
+}
+
+// Simulate evaluates all patterns against msg and a copy of the current state without sending any transaction or
+// publishing any message, and returns the trace of the execution. If msg is nil, parser.GlobalMessage is used.
+// The returned trace contains all patterns executed before an error if any.
+func (p *Parser) Simulate(msg *message.EventMessage) (*SimulationResult, error) {
+	if msg == nil {
+		msg = p.GlobalMessage
+	}
+	sim := NewParser(p.ProxyName, p.PublishEndpoint, p.PublishFunction, p.Bc, p.TxPool, p.SmartContractAddress, p.GlobalPatterns, msg, p.CanTrigger)
+	sim.StateDb = sim.StateDb.Copy()
+	sim.MaxLoopIterations = p.MaxLoopIterations
+	sim.Budget = p.Budget
+	for name, f := range p.UserDefinedFunction {
+		if f.library != "" {
+			sim.UserDefinedFunction[name] = f
+		}
+	}
+	sim.simulation = &simulation{result: &SimulationResult{
+		Steps:        make([]*SimulationStep, 0),
+		Branches:     make([]*SimulationBranch, 0),
+		Transactions: make([]string, 0),
+		Published:    make([]*dualMsg.TriggerMessage, 0),
+	}}
+
+	err := sim.ParseParams()
+	sim.simulation.result.Params = sim.GlobalParams
+	return sim.simulation.result, err
+}
+
+// beginStep adds a step for pattern to the trace, its results are filled by endStep after the pattern is executed.
+func (s *simulation) beginStep(p *Parser, pattern string) *SimulationStep {
+	step := &SimulationStep{
+		Depth:   p.depth,
+		Line:    p.Pc,
+		Pattern: pattern,
+	}
+	s.result.Steps = append(s.result.Steps, step)
+	return step
+}
+
+func (s *simulation) endStep(p *Parser, step *SimulationStep, results []interface{}, err error) {
+	step.Results = results
+	if err != nil {
+		step.Error = err.Error()
+	}
+	step.Variables = make(map[string]interface{}, len(p.UserDefinedVariables))
+	for k, v := range p.UserDefinedVariables {
+		step.Variables[k] = v
+	}
+}
+
+func (s *simulation) addBranch(p *Parser, name, condition string) {
+	s.result.Branches = append(s.result.Branches, &SimulationBranch{
+		Depth:     p.depth,
+		Name:      name,
+		Condition: condition,
+	})
+}
@@ -107,6 +107,13 @@ func triggerSmc(p *Parser, extras ...interface{}) ([]interface{}, error) {
 		return nil, err
 	}
 
+	if p.simulation != nil {
+		// the transaction is not sent while simulating
+		p.simulation.result.Transactions = append(p.simulation.result.Transactions, tx.Hash().Hex())
+		p.Nonce += 1
+		return []interface{}{tx.Hash().Hex()}, nil
+	}
+
 	// add tx to txPool
 	if err := p.TxPool.AddLocal(tx); err != nil {
 		return nil, err
@@ -178,6 +185,15 @@ func ConvertParams(p *Parser, arguments abi.Arguments, patterns []string) ([]int
 		arg := arguments[i]
 		t := arg.Type.GetType().Kind()
 		for _, val := range vals {
+			// arrays and tuples are converted recursively from a list or a string in bracketed or CSV syntax
+			if isCompositeType(arg.Type) {
+				result, err := convertABIValue(arg.Type, val)
+				if err != nil {
+					return nil, err
+				}
+				abiInputs = append(abiInputs, result)
+				continue
+			}
 			v, err := InterfaceToString(val)
 			if err != nil {
 				return nil, err
@@ -19,7 +19,10 @@
 package tests
 
 import (
+	"crypto/sha256"
+	"errors"
 	"math/big"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -27,6 +30,7 @@ import (
 	"github.com/kardiachain/go-kardia/ksml"
 	message "github.com/kardiachain/go-kardia/ksml/proto"
 	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -1019,6 +1023,175 @@ func TestConvertParams_getAddress(t *testing.T) {
 	require.Equal(t, expectedResult, results)
 }
 
+const sampleArrayDefinition = `[
+	{
+		"inputs": [
+			{"name": "amounts", "type": "uint256[]"},
+			{"name": "addresses", "type": "address[]"},
+			{"name": "values", "type": "uint8[2]"},
+			{"name": "matrix", "type": "int64[][]"},
+			{"name": "names", "type": "string[]"},
+			{"name": "hashes", "type": "bytes2[]"}
+		],
+		"name": "getArrays",
+		"outputs": [],
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"name": "order",
+				"type": "tuple",
+				"components": [
+					{"name": "owner", "type": "address"},
+					{"name": "amounts", "type": "uint256[]"},
+					{"name": "active", "type": "bool"}
+				]
+			}
+		],
+		"name": "getTuple",
+		"outputs": [],
+		"type": "function"
+	}
+]`
+
+func TestConvertParams_getArrays(t *testing.T) {
+	parser := &ksml.Parser{
+		GlobalMessage: &message.EventMessage{
+			Params: []string{
+				"[1, 2, 3]",
+				"0x0A,0x0B",
+				"[4,5]",
+				"[[1,-2],[3]]",
+				`["a,b", c]`,
+				"[0x0102,0x0304]",
+			},
+		},
+		GlobalPatterns: []string{
+			"message.params[0]",
+			"message.params[1]",
+			"message.params[2]",
+			"message.params[3]",
+			"message.params[4]",
+			"message.params[5]",
+		},
+		GlobalParams: []interface{}{0},
+	}
+	kAbi, err := abi.JSON(strings.NewReader(sampleArrayDefinition))
+	require.NoError(t, err)
+	method := "getArrays"
+	args := kAbi.Methods[method].Inputs
+	results, err := ksml.ConvertParams(parser, args, parser.GlobalPatterns)
+	require.NoError(t, err)
+
+	expectedResult := []interface{}{
+		[]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)},
+		[]common.Address{common.HexToAddress("0x0A"), common.HexToAddress("0x0B")},
+		[2]uint8{4, 5},
+		[][]int64{{1, -2}, {3}},
+		[]string{"a,b", "c"},
+		[][2]byte{{1, 2}, {3, 4}},
+	}
+	require.Equal(t, expectedResult, results)
+
+	_, err = kAbi.Pack(method, results...)
+	require.NoError(t, err)
+}
+
+func TestConvertParams_getArraysFromCEL(t *testing.T) {
+	parser := &ksml.Parser{
+		GlobalMessage: &message.EventMessage{
+			Params: []string{"1", "2"},
+		},
+		GlobalPatterns: []string{
+			"[uint(message.params[0]), uint(message.params[1])]",
+			"['0x0A']",
+			"[4, 5]",
+			"[[1], [2, 3]]",
+			"['a']",
+			"['0x0102']",
+		},
+		GlobalParams: []interface{}{0},
+	}
+	kAbi, err := abi.JSON(strings.NewReader(sampleArrayDefinition))
+	require.NoError(t, err)
+	args := kAbi.Methods["getArrays"].Inputs
+	results, err := ksml.ConvertParams(parser, args, parser.GlobalPatterns)
+	require.NoError(t, err)
+
+	expectedResult := []interface{}{
+		[]*big.Int{big.NewInt(1), big.NewInt(2)},
+		[]common.Address{common.HexToAddress("0x0A")},
+		[2]uint8{4, 5},
+		[][]int64{{1}, {2, 3}},
+		[]string{"a"},
+		[][2]byte{{1, 2}},
+	}
+	require.Equal(t, expectedResult, results)
+}
+
+func TestConvertParams_invalidArrays(t *testing.T) {
+	kAbi, err := abi.JSON(strings.NewReader(sampleArrayDefinition))
+	require.NoError(t, err)
+	args := kAbi.Methods["getArrays"].Inputs
+
+	for _, params := range [][]string{
+		{"[1,a]", "[]", "[4,5]", "[]", "[]", "[]"},            // invalid uint256
+		{"[]", "[0x0A]", "[4,5,6]", "[]", "[]", "[]"},         // invalid length of fixed-size array
+		{"[]", "[]", "[4,256]", "[]", "[]", "[]"},             // overflow uint8
+		{"[]", "[]", "[4,5]", "[[1,2]", "[]", "[]"},           // unbalanced brackets
+		{"[]", "[]", "[4,5]", "[]", "[]", "[0x010203]"},       // invalid length of bytes2
+		{"[]", "[not an address]", "[4,5]", "[]", "[]", "[]"}, // invalid address
+	} {
+		parser := &ksml.Parser{
+			GlobalMessage: &message.EventMessage{Params: params},
+			GlobalPatterns: []string{
+				"message.params[0]",
+				"message.params[1]",
+				"message.params[2]",
+				"message.params[3]",
+				"message.params[4]",
+				"message.params[5]",
+			},
+			GlobalParams: []interface{}{0},
+		}
+		_, err := ksml.ConvertParams(parser, args, parser.GlobalPatterns)
+		require.Error(t, err, params)
+	}
+}
+
+func TestConvertParams_getTuple(t *testing.T) {
+	kAbi, err := abi.JSON(strings.NewReader(sampleArrayDefinition))
+	require.NoError(t, err)
+	method := "getTuple"
+	args := kAbi.Methods[method].Inputs
+
+	for _, pattern := range []string{
+		"message.params[0]",
+		"{'owner': '0x0A', 'amounts': [1, 2], 'active': true}",
+		"['0x0A', [1, 2], true]",
+	} {
+		parser := &ksml.Parser{
+			GlobalMessage: &message.EventMessage{
+				Params: []string{"(0x0A,[1,2],true)"},
+			},
+			GlobalPatterns: []string{pattern},
+			GlobalParams:   []interface{}{0},
+		}
+		results, err := ksml.ConvertParams(parser, args, parser.GlobalPatterns)
+		require.NoError(t, err, pattern)
+		require.Len(t, results, 1)
+
+		order := reflect.ValueOf(results[0])
+		require.Equal(t, common.HexToAddress("0x0A"), order.Field(0).Interface())
+		require.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(2)}, order.Field(1).Interface())
+		require.Equal(t, true, order.Field(2).Interface())
+
+		_, err = kAbi.Pack(method, results...)
+		require.NoError(t, err)
+	}
+}
+
 func TestExecuteIfElse(t *testing.T) {
 	parser, err := setup(sampleCode2, sampleDefinition2, []string{
 		"${message.params[0]}",
@@ -1164,6 +1337,344 @@ func TestForEach1(t *testing.T) {
 	require.Equal(t, expectedParams, parser.GlobalParams)
 }
 
+func TestWhile(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:var(testVar,uint64,0)}",
+		"${fn:while(name1,testVar<uint(3))}",
+		"${fn:var(testVar,uint64,testVar+uint(1))}",
+		"${testVar}",
+		"${fn:endWhile(name1)}",
+		"hello",
+	}, &message.EventMessage{})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.NoError(t, err)
+
+	expectedParams := []interface{}{uint64(1), uint64(2), uint64(3), "hello"}
+	require.Equal(t, expectedParams, parser.GlobalParams)
+
+	expectedDefinedVar := map[string]interface{}{
+		"testVar": uint64(3),
+	}
+	require.Equal(t, expectedDefinedVar, parser.UserDefinedVariables)
+}
+
+func TestWhileBreak(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:var(testVar,uint64,0)}",
+		"${fn:while(name1,true)}",
+		"${fn:var(testVar,uint64,testVar+uint(1))}",
+		"${testVar}",
+		"${fn:if(cond1,testVar==uint(2))}",
+		"${fn:break(name1)}",
+		"${fn:endif(cond1)}",
+		"${fn:endWhile(name1)}",
+		"hello",
+	}, &message.EventMessage{})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.NoError(t, err)
+
+	expectedParams := []interface{}{uint64(1), uint64(2), "hello"}
+	require.Equal(t, expectedParams, parser.GlobalParams)
+}
+
+func TestWhileContinue(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:var(testVar,uint64,0)}",
+		"${fn:while(name1,testVar<uint(4))}",
+		"${fn:var(testVar,uint64,testVar+uint(1))}",
+		"${fn:if(cond1,testVar%uint(2)==uint(1))}",
+		"${fn:continue(name1)}",
+		"${fn:endif(cond1)}",
+		"${testVar}",
+		"${fn:endWhile(name1)}",
+	}, &message.EventMessage{})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.NoError(t, err)
+
+	expectedParams := []interface{}{uint64(2), uint64(4)}
+	require.Equal(t, expectedParams, parser.GlobalParams)
+}
+
+func TestWhileMaxIterations(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:var(testVar,uint64,0)}",
+		"${fn:while(name1,true)}",
+		"${fn:var(testVar,uint64,testVar+uint(1))}",
+		"${fn:endWhile(name1)}",
+	}, &message.EventMessage{})
+	require.NoError(t, err)
+	parser.MaxLoopIterations = 10
+
+	err = parser.ParseParams()
+	require.Error(t, err)
+	require.Equal(t, uint64(10), parser.UserDefinedVariables["testVar"])
+}
+
+func TestBreakOutsideWhile(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:break(name1)}",
+	}, &message.EventMessage{})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.Error(t, err)
+}
+
+func TestTryCatch(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:var(testVar,uint64,1)}",
+		"${fn:try(name1)}",
+		"${testVar}",
+		"${fn:var(testVar,uint64,message.params[0])}",
+		"${fn:catch(name1,errMsg)}",
+		"${fn:var(testVar,uint64,2)}",
+		"fallback",
+		"${fn:finally(name1)}",
+		"done",
+		"${fn:endTry(name1)}",
+		"hello",
+	}, &message.EventMessage{
+		Params: []string{"abc"},
+	})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.NoError(t, err)
+
+	// params of the failed try block are dropped
+	expectedParams := []interface{}{"fallback", "done", "hello"}
+	require.Equal(t, expectedParams, parser.GlobalParams)
+	require.Equal(t, uint64(2), parser.UserDefinedVariables["testVar"])
+	require.NotEmpty(t, parser.UserDefinedVariables["errMsg"])
+}
+
+func TestTryWithoutError(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:try(name1)}",
+		"${message.params[0]}",
+		"${fn:catch(name1,errMsg)}",
+		"fallback",
+		"${fn:finally(name1)}",
+		"done",
+		"${fn:endTry(name1)}",
+	}, &message.EventMessage{
+		Params: []string{"abc"},
+	})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.NoError(t, err)
+
+	expectedParams := []interface{}{"abc", "done"}
+	require.Equal(t, expectedParams, parser.GlobalParams)
+	_, ok := parser.UserDefinedVariables["errMsg"]
+	require.False(t, ok)
+}
+
+func TestTryFinallyWithoutCatch(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:var(testVar,uint64,1)}",
+		"${fn:try(name1)}",
+		"${fn:var(testVar,uint64,message.params[0])}",
+		"${fn:finally(name1)}",
+		"${fn:var(testVar,uint64,3)}",
+		"${fn:endTry(name1)}",
+	}, &message.EventMessage{
+		Params: []string{"abc"},
+	})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.Error(t, err)
+	require.Equal(t, uint64(3), parser.UserDefinedVariables["testVar"])
+}
+
+func TestTryStopSignal(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:try(name1)}",
+		"${fn:validate(false,SIGNAL_CONTINUE,SIGNAL_STOP)}",
+		"${fn:catch(name1,errMsg)}",
+		"fallback",
+		"${fn:endTry(name1)}",
+	}, &message.EventMessage{})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.Error(t, err)
+	require.Empty(t, parser.GlobalParams)
+}
+
+func TestInvalidTryStatement(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:try(name1)}",
+		"${fn:finally(name1)}",
+		"${fn:catch(name1,errMsg)}",
+		"${fn:endTry(name1)}",
+	}, &message.EventMessage{})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.Error(t, err)
+}
+
+func TestJson(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:jsonParse(obj,message.params[0])}",
+		"${obj.name}",
+		"${fn:jsonGet(obj,$.data.items[1].id)}",
+		"${fn:jsonGet(obj,data.amount)}",
+		"${fn:jsonString(fn:jsonGet(obj,data.items[0]))}",
+	}, &message.EventMessage{
+		Params: []string{`{"name":"kai","data":{"items":[{"id":1},{"id":2}],"amount":1000000000000000000000000}}`},
+	})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.NoError(t, err)
+
+	expectedParams := []interface{}{"kai", int64(2), "1000000000000000000000000", `{"id":1}`}
+	require.Equal(t, expectedParams, parser.GlobalParams)
+}
+
+func TestJsonGetNotFound(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:jsonParse(obj,message.params[0])}",
+		"${fn:jsonGet(obj,data.items[2])}",
+	}, &message.EventMessage{
+		Params: []string{`{"data":{"items":[1,2]}}`},
+	})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.Error(t, err)
+}
+
+func TestMapFilterReduce(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:var(l1,list,fn:split(message.params[0],';'))}",
+		"${fn:map(doubled,l1,uint(ITEM)*uint(2))}",
+		"${fn:filter(evens,l1,uint(ITEM)%uint(2)==uint(0))}",
+		"${fn:reduce(sum,l1,uint(0),ACC+uint(ITEM))}",
+		"${fn:map(indexes,l1,INDEX)}",
+	}, &message.EventMessage{
+		Params: []string{"1;2;3;4"},
+	})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.NoError(t, err)
+
+	expectedDefinedVar := map[string]interface{}{
+		"l1":      []interface{}{"1", "2", "3", "4"},
+		"doubled": []interface{}{uint64(2), uint64(4), uint64(6), uint64(8)},
+		"evens":   []interface{}{"2", "4"},
+		"sum":     uint64(10),
+		"indexes": []interface{}{int64(0), int64(1), int64(2), int64(3)},
+	}
+	require.Equal(t, expectedDefinedVar, parser.UserDefinedVariables)
+}
+
+func TestFilterInvalidExpression(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:var(l1,list,fn:split(message.params[0],';'))}",
+		"${fn:filter(evens,l1,uint(ITEM))}",
+	}, &message.EventMessage{
+		Params: []string{"1;2;3;4"},
+	})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.Error(t, err)
+}
+
+func TestHashAndSignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	hash := crypto.Keccak256([]byte("hello"))
+	sig, err := crypto.Sign(hash, key)
+	require.NoError(t, err)
+	// signatures from other chains usually have V in 27/28
+	sig27 := common.CopyBytes(sig)
+	sig27[64] += 27
+
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:keccak256(message.params[0])}",
+		"${fn:sha256(message.params[0])}",
+		"${fn:recover(message.params[1],message.params[2])}",
+		"${fn:verifySig(message.params[3],fn:keccak256(message.params[0]),message.params[2])}",
+		"${fn:verifySig(message.params[3],message.params[1],message.params[4])}",
+		"${fn:verifySig(contractAddress,message.params[1],message.params[2])}",
+	}, &message.EventMessage{
+		Params: []string{"hello", common.Encode(hash), common.Encode(sig), addr.Hex(), common.Encode(sig27)},
+	})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.NoError(t, err)
+
+	sha := sha256.Sum256([]byte("hello"))
+	expectedParams := []interface{}{common.Encode(hash), common.Encode(sha[:]), addr.Hex(), true, true, false}
+	require.Equal(t, expectedParams, parser.GlobalParams)
+}
+
+func TestRecoverInvalidSignature(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:recover(message.params[0],message.params[1])}",
+	}, &message.EventMessage{
+		Params: []string{common.Encode(crypto.Keccak256([]byte("hello"))), "0x1234"},
+	})
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.Error(t, err)
+}
+
+func TestBudgetSteps(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:var(testVar,uint64,0)}",
+		"${fn:try(name1)}",
+		"${fn:while(loop1,true)}",
+		"${fn:var(testVar,uint64,testVar+uint(1))}",
+		"${fn:endWhile(loop1)}",
+		"${fn:catch(name1,errMsg)}",
+		"caught",
+		"${fn:endTry(name1)}",
+	}, &message.EventMessage{})
+	require.NoError(t, err)
+	parser.Budget.MaxSteps = 20
+
+	err = parser.ParseParams()
+	var budgetErr *ksml.BudgetExceededError
+	require.True(t, errors.As(err, &budgetErr))
+	require.Equal(t, ksml.BudgetSteps, budgetErr.Limit)
+	// budget errors cannot be caught
+	require.Empty(t, parser.GlobalParams)
+}
+
+func TestBudgetCallDepth(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{
+		"${fn:defineFunc(recursive,n)}",
+		"${fn:call(recursive,n)}",
+		"${fn:endDefineFunc(recursive)}",
+		"${fn:call(recursive,1)}",
+	}, &message.EventMessage{})
+	require.NoError(t, err)
+	parser.Budget.MaxCallDepth = 3
+
+	err = parser.ParseParams()
+	var budgetErr *ksml.BudgetExceededError
+	require.True(t, errors.As(err, &budgetErr))
+	require.Equal(t, ksml.BudgetCallDepth, budgetErr.Limit)
+	require.Equal(t, 3, budgetErr.Max)
+}
+
 func TestSplit(t *testing.T) {
 	parser, err := setup(sampleCode2, sampleDefinition2, []string{
 		"${fn:split(message.params[0],';')}",
@@ -1235,6 +1746,75 @@ func TestCallFunc(t *testing.T) {
 	require.Equal(t, expectedParams, parser.GetGlobalParams())
 }
 
+func TestCallLibraryFunc(t *testing.T) {
+	lib, err := ksml.LoadLibrary("math", []string{
+		"${fn:defineFunc(sum,a,b)}",
+		"${uint(a)+uint(b)}",
+		"${fn:endDefineFunc(sum)}",
+		"${fn:defineFunc(double,a)}",
+		"${fn:call(sum,a,a)}",
+		"${fn:endDefineFunc(double)}",
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"sum", "double"}, lib.Functions())
+
+	parser, err := setupWithLibs(sampleCode2, sampleDefinition2, []string{
+		"${fn:call(math.sum,message.params[0],message.params[1])}",
+		"${fn:call(math.double,message.params[2])}",
+	}, &message.EventMessage{
+		Params: []string{"1", "2", "3"},
+	}, lib)
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.NoError(t, err)
+
+	expectedParams := []interface{}{uint64(3), uint64(6)}
+	require.Equal(t, expectedParams, parser.GetGlobalParams())
+}
+
+func TestCallLibraryFuncWithoutNamespace(t *testing.T) {
+	lib, err := ksml.LoadLibrary("math", []string{
+		"${fn:defineFunc(sum,a,b)}",
+		"${uint(a)+uint(b)}",
+		"${fn:endDefineFunc(sum)}",
+	})
+	require.NoError(t, err)
+
+	parser, err := setupWithLibs(sampleCode2, sampleDefinition2, []string{
+		"${fn:call(sum,message.params[0],message.params[1])}",
+	}, &message.EventMessage{
+		Params: []string{"1", "2"},
+	}, lib)
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.Error(t, err)
+}
+
+func TestLoadLibrary_errors(t *testing.T) {
+	_, err := ksml.LoadLibrary("math.v1", []string{
+		"${fn:defineFunc(sum,a,b)}",
+		"${uint(a)+uint(b)}",
+		"${fn:endDefineFunc(sum)}",
+	})
+	require.Error(t, err)
+
+	_, err = ksml.LoadLibrary("math", []string{
+		"${fn:defineFunc(sum,a,b)}",
+		"${uint(a)+uint(b)}",
+	})
+	require.Error(t, err)
+
+	_, err = ksml.LoadLibrary("math", []string{
+		"${fn:defineFunc(sum,a,b)}",
+		"${uint(a)+uint(b)}",
+		"${fn:endDefineFunc(sum)}",
+		"${message.params[0]}",
+	})
+	require.Error(t, err)
+}
+
 // @todo thangn test failed
 // func TestTriggerSmc(t *testing.T) {
 // 	parser, err := setup(sampleCode5, sampleDefinition5, []string{
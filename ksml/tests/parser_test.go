@@ -112,6 +112,10 @@ func TestGetPrefix_WithNestedBuiltIn(t *testing.T) {
 }
 
 func setup(sampleCode []byte, sampleDefinition string, globalPatterns []string, globalMessage *message.EventMessage) (*ksml.Parser, error) {
+	return setupWithLibs(sampleCode, sampleDefinition, globalPatterns, globalMessage)
+}
+
+func setupWithLibs(sampleCode []byte, sampleDefinition string, globalPatterns []string, globalMessage *message.EventMessage, libs ...*ksml.Library) (*ksml.Parser, error) {
 	dbInfo := NewMemoryDbInfo()
 	db, _ := dbInfo.Start()
 
@@ -171,7 +175,7 @@ func setup(sampleCode []byte, sampleDefinition string, globalPatterns []string,
 		return nil
 	}
 
-	return ksml.NewParser("ETH", "0.0.0.0:5555", publishFunc, bc, txPool, &contractAddress, globalPatterns, globalMessage, true), nil
+	return ksml.NewParser("ETH", "0.0.0.0:5555", publishFunc, bc, txPool, &contractAddress, globalPatterns, globalMessage, true, libs...), nil
 }
 
 func TestParseParams_withReturn(t *testing.T) {
@@ -460,3 +464,128 @@ func TestSimulateDexDepositNEO(t *testing.T) {
 	err = parser.ParseParams()
 	require.NoError(t, err)
 }
+
+func TestParser_Simulate(t *testing.T) {
+	patterns := []string{
+		"${fn:var(testVar,uint64,1)}",
+		"${fn:if(name1,uint(message.params[1])==uint(3))}",
+		"${fn:var(testVar,uint64,2)}",
+		"${uint(message.params[0])+uint(message.params[1])}",
+		"${fn:elif(name1,uint(message.params[1])==uint(2))}",
+		"${uint(message.params[2])==uint(2)}",
+		"${fn:endif(name1)}",
+		"${fn:publish(message.params[0],message.params[1],[message.params[2]])}",
+		"hello",
+	}
+	parser, err := setup(sampleCode2, sampleDefinition2, patterns, &message.EventMessage{
+		Params: []string{"1", "2", "3", "4"},
+	})
+	require.NoError(t, err)
+	// publish must not be called while simulating
+	parser.PublishFunction = func(endpoint string, topic string, msg message2.TriggerMessage) error {
+		return fmt.Errorf("message is published")
+	}
+
+	result, err := parser.Simulate(nil)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{false, "hello"}, result.Params)
+	require.Equal(t, []*ksml.SimulationBranch{
+		{Depth: 0, Name: "name1", Condition: "uint(message.params[1])==uint(2)"},
+	}, result.Branches)
+	require.Len(t, result.Published, 1)
+
+	require.Len(t, result.Steps, 5)
+	require.Equal(t, patterns[1], result.Steps[1].Pattern)
+	require.Equal(t, []interface{}{false}, result.Steps[1].Results)
+	require.Equal(t, 1, result.Steps[2].Depth)
+	require.Equal(t, patterns[5], result.Steps[2].Pattern)
+	require.Equal(t, uint64(1), result.Steps[4].Variables["testVar"])
+
+	// simulating with another message takes the if branch
+	result, err = parser.Simulate(&message.EventMessage{
+		Params: []string{"1", "3", "3", "4"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{uint64(4), "hello"}, result.Params)
+	require.Equal(t, "uint(message.params[1])==uint(3)", result.Branches[0].Condition)
+	require.Equal(t, uint64(2), result.Steps[len(result.Steps)-1].Variables["testVar"])
+
+	// parser itself is not changed
+	require.Empty(t, parser.GetGlobalParams())
+	require.Empty(t, parser.UserDefinedVariables)
+}
+
+func TestCompile(t *testing.T) {
+	compiled, errs := ksml.Compile([]string{
+		"${fn:var(testVar,uint64,1)}",
+		"${fn:defineFunc(add,a,b)}",
+		"${uint(a)+uint(b)}",
+		"${fn:endDefineFunc(add)}",
+		"${fn:var(l1,list,fn:split(message.params[0],';'))}",
+		"${fn:if(name1,uint(message.params[1])==uint(3))}",
+		"${fn:call(add,message.params[0],message.params[1])}",
+		"${fn:elif(name1,uint(message.params[1])==uint(2))}",
+		"${fn:else(name1)}",
+		"${fn:endif(name1)}",
+		"${fn:while(loop1,testVar<uint(3))}",
+		"${fn:var(testVar,uint64,testVar+uint(1))}",
+		"${fn:if(name2,testVar==uint(2))}",
+		"${fn:break(loop1)}",
+		"${fn:endif(name2)}",
+		"${fn:endWhile(loop1)}",
+		"hello",
+	})
+	require.Empty(t, errs)
+	require.Len(t, compiled.Patterns, 17)
+	require.Contains(t, compiled.Expressions, "uint(message.params[1])==uint(3)")
+}
+
+func TestCompile_errors(t *testing.T) {
+	_, errs := ksml.Compile([]string{
+		"${fn:unknown(a)}",
+		"${fn:split(message.params[0])}",
+		"${uint(message.params[0]+}",
+		"${fn:if(name1,true)}",
+		"${fn:endForEach(name1)}",
+		"${fn:else(name1)}",
+		"${fn:elif(name1,false)}",
+		"${fn:endif(name1)}",
+		"${fn:break(loop1)}",
+		"${fn:call(notDefined)}",
+		"${fn:forEach(name2,message.params,i)}",
+	})
+	require.Len(t, errs, 8)
+	require.Contains(t, errs[0].Error(), "pattern 0: unknown built-in function unknown")
+	require.Contains(t, errs[1].Error(), "pattern 1: invalid number of arguments for split")
+	require.Contains(t, errs[2].Error(), "pattern 2: invalid expression")
+	require.Contains(t, errs[3].Error(), "pattern 4: endForEach(name1) does not match if(name1)")
+	require.Contains(t, errs[4].Error(), "pattern 6: elif(name1) is out of order")
+	require.Contains(t, errs[5].Error(), "pattern 8: break(loop1) is used outside of while loop")
+	require.Contains(t, errs[6].Error(), "pattern 10: forEach(name2) is not closed")
+	require.Contains(t, errs[7].Error(), "pattern 9: function notDefined is not defined")
+}
+
+func TestParseParams_withContext(t *testing.T) {
+	patterns := []string{
+		"${block.height}",
+		"${tx.hash}",
+		"${tx.from}",
+		"${smc.address}",
+		"${fn:validate(block.height>=tx.height+6,SIGNAL_CONTINUE,SIGNAL_RETURN)}",
+		"confirmed",
+	}
+	msg := &message.EventMessage{
+		TransactionId: "0xabcd",
+		From:          "0xc1fe56E3F58D3244F606306611a5d10c8333f1f6",
+		BlockNumber:   5,
+	}
+	parser, err := setup(sampleCode2, sampleDefinition2, patterns, msg)
+	require.NoError(t, err)
+
+	err = parser.ParseParams()
+	require.NoError(t, err)
+
+	// current block is the genesis block, the transaction has not been confirmed yet
+	expectedResult := []interface{}{int64(0), "0xabcd", msg.From, common.HexToAddress("0x0A").Hex()}
+	require.Equal(t, expectedResult, parser.GetGlobalParams())
+}
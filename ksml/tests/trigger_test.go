@@ -0,0 +1,137 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tests
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/accounts/abi"
+	"github.com/kardiachain/go-kardia/ksml"
+	message "github.com/kardiachain/go-kardia/ksml/proto"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+const sampleEventDefinition = `[
+	{
+		"anonymous": false,
+		"inputs": [
+			{"indexed": true, "name": "from", "type": "address"},
+			{"indexed": true, "name": "to", "type": "address"},
+			{"indexed": false, "name": "value", "type": "uint256"},
+			{"indexed": false, "name": "ids", "type": "uint8[]"}
+		],
+		"name": "Transfer",
+		"type": "event"
+	}
+]`
+
+func sampleTransferLog(t *testing.T, event abi.Event, contract, from, to common.Address, value int64, ids []uint8) *types.Log {
+	data, err := event.Inputs.NonIndexed().Pack(big.NewInt(value), ids)
+	require.NoError(t, err)
+	return &types.Log{
+		Address: contract,
+		Topics: []common.Hash{
+			event.ID,
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data:   data,
+		TxHash: common.HexToHash("0xabcd"),
+	}
+}
+
+func TestDecodeLog(t *testing.T) {
+	kAbi, err := abi.JSON(strings.NewReader(sampleEventDefinition))
+	require.NoError(t, err)
+	event := kAbi.Events["Transfer"]
+	from, to := common.HexToAddress("0x0B"), common.HexToAddress("0x0C")
+	log := sampleTransferLog(t, event, common.HexToAddress("0x0A"), from, to, 100, []uint8{1, 2})
+
+	params, err := ksml.DecodeLog(event, log)
+	require.NoError(t, err)
+	require.Equal(t, []string{from.Hex(), to.Hex(), "100", "[1,2]"}, params)
+
+	log.Topics[0] = common.HexToHash("0x01")
+	_, err = ksml.DecodeLog(event, log)
+	require.Error(t, err)
+}
+
+func TestTriggerRegistry_Match(t *testing.T) {
+	kAbi, err := abi.JSON(strings.NewReader(sampleEventDefinition))
+	require.NoError(t, err)
+	event := kAbi.Events["Transfer"]
+	contract := common.HexToAddress("0x0A")
+
+	registry := ksml.NewTriggerRegistry("ETH", "", nil, nil, nil, false)
+	t1, err := registry.Register(&contract, event, []string{"${message.params[2]}"})
+	require.NoError(t, err)
+	t2, err := registry.Register(nil, event, []string{"${message.params[3]}"})
+	require.NoError(t, err)
+	t3, err := registry.Register(&contract, event, []string{"${message.params[0]}"})
+	require.NoError(t, err)
+
+	log := sampleTransferLog(t, event, contract, common.HexToAddress("0x0B"), common.HexToAddress("0x0C"), 100, nil)
+	require.Equal(t, []*ksml.Trigger{t1, t3, t2}, registry.Match(log))
+
+	// wildcard triggers match any address
+	log.Address = common.HexToAddress("0x0D")
+	require.Equal(t, []*ksml.Trigger{t2}, registry.Match(log))
+
+	registry.Unregister(t2)
+	require.Empty(t, registry.Match(log))
+
+	_, err = registry.Register(&contract, event, nil)
+	require.Error(t, err)
+}
+
+func TestTriggerRegistry_HandleLog(t *testing.T) {
+	parser, err := setup(sampleCode2, sampleDefinition2, []string{}, &message.EventMessage{})
+	require.NoError(t, err)
+	kAbi, err := abi.JSON(strings.NewReader(sampleEventDefinition))
+	require.NoError(t, err)
+	event := kAbi.Events["Transfer"]
+	contract := common.HexToAddress("0x0A")
+	from, to := common.HexToAddress("0x0B"), common.HexToAddress("0x0C")
+
+	registry := ksml.NewTriggerRegistry("ETH", "", nil, parser.Bc, parser.TxPool, false)
+	_, err = registry.Register(&contract, event, []string{
+		"${message.params[0]}",
+		"${uint(message.params[2])*uint(2)}",
+	})
+	require.NoError(t, err)
+	_, err = registry.Register(nil, event, []string{
+		"${message.method}",
+		"${fn:validate(message.params[1]=='" + from.Hex() + "',SIGNAL_CONTINUE,SIGNAL_STOP)}",
+	})
+	require.NoError(t, err)
+
+	results := registry.HandleLog(sampleTransferLog(t, event, contract, from, to, 100, []uint8{1}))
+	require.Len(t, results, 2)
+	require.NoError(t, results[0].Err)
+	require.Equal(t, "0x000000000000000000000000000000000000000000000000000000000000abcd", results[0].Message.TransactionId)
+	require.Equal(t, []interface{}{from.Hex(), uint64(200)}, results[0].Params)
+	// message.params[1] is not the sender, patterns are stopped
+	require.Error(t, results[1].Err)
+	require.Equal(t, "Transfer", results[1].Message.Method)
+}
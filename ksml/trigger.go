@@ -0,0 +1,218 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ksml
+
+import (
+	"fmt"
+	"sync"
+
+	dualMsg "github.com/kardiachain/go-kardia/dualnode/message"
+	"github.com/kardiachain/go-kardia/kai/accounts/abi"
+	"github.com/kardiachain/go-kardia/kai/base"
+	message "github.com/kardiachain/go-kardia/ksml/proto"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/mainchain/tx_pool"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// Trigger is a set of patterns executed when a contract emits an event.
+type Trigger struct {
+	Address  *common.Address // address of the contract emitting the event, nil matches any address
+	Event    abi.Event       // event whose signature is matched against topic0 of logs
+	Patterns []string        // patterns executed with the decoded event as message
+}
+
+// TriggerResult is the result of executing a trigger for a log.
+type TriggerResult struct {
+	Trigger *Trigger
+	Message *message.EventMessage // message built from the log, its params are the decoded event arguments
+	Params  []interface{}         // params returned by the patterns
+	Err     error                 // error returned while decoding the log or executing the patterns if any
+}
+
+// TriggerRegistry maps contract addresses and event signatures (topic0) to triggers. When a log is handled, it is
+// decoded into message.params and patterns of all matching triggers are executed by a new parser.
+type TriggerRegistry struct {
+	proxyName       string
+	publishEndpoint string
+	publishFunction func(endpoint string, topic string, msg dualMsg.TriggerMessage) error
+	bc              base.BaseBlockChain
+	txPool          *tx_pool.TxPool
+	canTrigger      bool
+	libs            []*Library
+
+	mtx       sync.RWMutex
+	triggers  map[common.Hash]map[common.Address][]*Trigger // triggers of a contract address by event signature
+	wildcards map[common.Hash][]*Trigger                    // triggers of any address by event signature
+}
+
+// NewTriggerRegistry returns an empty registry, its arguments are used to create parsers executing triggers.
+func NewTriggerRegistry(proxyName, publishedEndpoint string, publishFunction func(endpoint string, topic string, msg dualMsg.TriggerMessage) error,
+	bc base.BaseBlockChain, txPool *tx_pool.TxPool, canTrigger bool, libs ...*Library) *TriggerRegistry {
+	return &TriggerRegistry{
+		proxyName:       proxyName,
+		publishEndpoint: publishedEndpoint,
+		publishFunction: publishFunction,
+		bc:              bc,
+		txPool:          txPool,
+		canTrigger:      canTrigger,
+		libs:            libs,
+		triggers:        make(map[common.Hash]map[common.Address][]*Trigger),
+		wildcards:       make(map[common.Hash][]*Trigger),
+	}
+}
+
+// Register adds a trigger executing patterns when event is emitted by address, or by any contract if address is nil.
+// Many triggers can be registered for the same event, they are executed in order of registration.
+func (r *TriggerRegistry) Register(address *common.Address, event abi.Event, patterns []string) (*Trigger, error) {
+	if event.Anonymous {
+		return nil, fmt.Errorf("anonymous event %v cannot be triggered", event.Name)
+	}
+	if len(patterns) == 0 {
+		return nil, sourceIsEmpty
+	}
+	t := &Trigger{Address: address, Event: event, Patterns: patterns}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if address == nil {
+		r.wildcards[event.ID] = append(r.wildcards[event.ID], t)
+		return t, nil
+	}
+	if _, ok := r.triggers[event.ID]; !ok {
+		r.triggers[event.ID] = make(map[common.Address][]*Trigger)
+	}
+	r.triggers[event.ID][*address] = append(r.triggers[event.ID][*address], t)
+	return t, nil
+}
+
+// Unregister removes t from the registry.
+func (r *TriggerRegistry) Unregister(t *Trigger) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if t.Address == nil {
+		r.wildcards[t.Event.ID] = removeTrigger(r.wildcards[t.Event.ID], t)
+		return
+	}
+	if byAddress, ok := r.triggers[t.Event.ID]; ok {
+		byAddress[*t.Address] = removeTrigger(byAddress[*t.Address], t)
+	}
+}
+
+func removeTrigger(triggers []*Trigger, t *Trigger) []*Trigger {
+	results := make([]*Trigger, 0, len(triggers))
+	for _, trigger := range triggers {
+		if trigger != t {
+			results = append(results, trigger)
+		}
+	}
+	return results
+}
+
+// Match returns triggers matching address and topic0 of log, triggers of the address come before wildcard triggers.
+func (r *TriggerRegistry) Match(log *types.Log) []*Trigger {
+	if len(log.Topics) == 0 {
+		return nil
+	}
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	matched := make([]*Trigger, 0)
+	if byAddress, ok := r.triggers[log.Topics[0]]; ok {
+		matched = append(matched, byAddress[log.Address]...)
+	}
+	return append(matched, r.wildcards[log.Topics[0]]...)
+}
+
+// HandleLog executes all triggers matching log. A trigger failing does not prevent the others from being executed,
+// its error is returned in its result.
+func (r *TriggerRegistry) HandleLog(log *types.Log) []*TriggerResult {
+	results := make([]*TriggerResult, 0)
+	for _, t := range r.Match(log) {
+		result := &TriggerResult{Trigger: t}
+		results = append(results, result)
+
+		msg, err := r.newEventMessage(t, log)
+		if err != nil {
+			result.Err = err
+			continue
+		}
+		result.Message = msg
+		contractAddress := log.Address
+		parser := NewParser(r.proxyName, r.publishEndpoint, r.publishFunction, r.bc, r.txPool, &contractAddress, t.Patterns, msg, r.canTrigger, r.libs...)
+		if err := parser.ParseParams(); err != nil {
+			result.Err = err
+			continue
+		}
+		result.Params = parser.GetGlobalParams()
+	}
+	return results
+}
+
+// newEventMessage builds the message of log for t, params are the decoded event arguments in order.
+func (r *TriggerRegistry) newEventMessage(t *Trigger, log *types.Log) (*message.EventMessage, error) {
+	params, err := DecodeLog(t.Event, log)
+	if err != nil {
+		return nil, err
+	}
+	msg := &message.EventMessage{
+		TransactionId: log.TxHash.Hex(),
+		To:            log.Address.Hex(),
+		Method:        t.Event.Name,
+		Params:        params,
+		BlockNumber:   log.BlockHeight,
+	}
+	if block := r.bc.GetBlockByHeight(log.BlockHeight); block != nil {
+		msg.Timestamp = block.Header().Time
+	}
+	return msg, nil
+}
+
+// DecodeLog decodes indexed arguments of event from topics of log and the others from its data. Arguments are returned
+// in order as strings: numbers in decimal, addresses, hashes and bytes in hex, arrays as "[a,b]" and tuples as "(a,b)".
+// Indexed arguments of dynamic types (string, bytes, arrays) are returned as the hash stored in topics.
+func DecodeLog(event abi.Event, log *types.Log) ([]string, error) {
+	if len(log.Topics) == 0 || log.Topics[0] != event.ID {
+		return nil, fmt.Errorf("log does not match event %v", event.Sig)
+	}
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	values := make(map[string]interface{}, len(event.Inputs))
+	if err := abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:]); err != nil {
+		return nil, err
+	}
+	if nonIndexed := event.Inputs.NonIndexed(); len(nonIndexed) > 0 {
+		if err := nonIndexed.UnpackIntoMap(values, log.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	params := make([]string, len(event.Inputs))
+	for i, arg := range event.Inputs {
+		str, err := abiValueToString(values[arg.Name])
+		if err != nil {
+			return nil, err
+		}
+		params[i] = str
+	}
+	return params, nil
+}
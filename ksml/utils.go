@@ -42,6 +42,24 @@ const (
 	ifFunc             = "if"
 	forEachFunc        = "forEach"
 	endForEach         = "endForEach"
+	whileFunc          = "while"
+	endWhile           = "endWhile"
+	breakFunc          = "break"
+	continueFunc       = "continue"
+	tryFunc            = "try"
+	catchFunc          = "catch"
+	finallyFunc        = "finally"
+	endTry             = "endTry"
+	jsonParseFunc      = "jsonParse"
+	jsonGetFunc        = "jsonGet"
+	jsonStringFunc     = "jsonString"
+	mapFunc            = "map"
+	filterFunc         = "filter"
+	reduceFunc         = "reduce"
+	keccak256Func      = "keccak256"
+	sha256Func         = "sha256"
+	verifySigFunc      = "verifySig"
+	recoverFunc        = "recover"
 	splitFunc          = "split"
 	replaceFunc        = "replace"
 	defineFunc         = "defineFunc"
@@ -60,6 +78,7 @@ const (
 	round              = "round"
 
 	MaximumGasToCallFunction = uint(5000000)
+	DefaultMaxLoopIterations = 1000 // maximum iterations of a while loop before the parser gives up
 	intType                  = "int"
 	int8Type                 = "int8"
 	int16Type                = "int16"
@@ -85,7 +104,13 @@ const (
 	globalParams          = "params"
 	globalContractAddress = "contractAddress"
 	globalProxyName       = "proxyName"
+	globalBlock           = "block" // current kardia block: block.height, block.time
+	globalTx              = "tx"    // transaction which emits the event message: tx.hash, tx.from, tx.height
+	globalSmc             = "smc"   // smart contract which is executing patterns: smc.address
 	prefixSeparator       = ":"
+	itemVar               = "ITEM"  // current element in map, filter and reduce expressions
+	indexVar              = "INDEX" // position of current element in map, filter and reduce expressions
+	accVar                = "ACC"   // accumulated value in reduce expression
 	messagePackage        = "protocol.EventMessage"
 
 	signalContinue = "SIGNAL_CONTINUE"
@@ -99,6 +124,19 @@ type function struct {
 	name     string
 	args     []string
 	patterns []string
+	library  string // name of the library defining the function, empty if it is defined in patterns
+}
+
+// loopControl is returned as an error by fn:break and fn:continue to unwind nested blocks up to the while loop
+// with the same name. params holds the values returned by the unwound blocks before the signal.
+type loopControl struct {
+	name   string
+	signal string
+	params []interface{}
+}
+
+func (c *loopControl) Error() string {
+	return fmt.Sprintf("fn:%v(%v) is used outside of while loop %v", c.signal, c.name, c.name)
 }
 
 var (
@@ -119,6 +157,14 @@ var (
 	variableNotFound               = fmt.Errorf("variable not found")
 	invalidForEachParam            = fmt.Errorf("invalid for each param")
 	invalidForEachStatement        = fmt.Errorf("invalid for each statement")
+	invalidWhileParam              = fmt.Errorf("invalid while param")
+	invalidWhileStatement          = fmt.Errorf("invalid while statement")
+	invalidLoopControlParam        = fmt.Errorf("break/continue must have the name of its while loop")
+	incorrectReturnedValueInWhile  = fmt.Errorf("while condition must returns only 1 bool value")
+	invalidTryParam                = fmt.Errorf("invalid try param")
+	invalidTryStatement            = fmt.Errorf("invalid try statement")
+	incorrectReturnedValueInFilter = fmt.Errorf("filter expression must returns only 1 bool value")
+	invalidSignature               = fmt.Errorf("invalid signature")
 	notEnoughArgsForSplit          = fmt.Errorf("not enough arguments for split function")
 	notEnoughArgsForFunc           = fmt.Errorf("not enough arguments for create/call Func function")
 	invalidSplitArgs               = fmt.Errorf("invalid split arguments")
@@ -130,6 +176,9 @@ var (
 		globalParams:          decls.NewIdent(globalParams, decls.Dyn, nil),
 		globalContractAddress: decls.NewIdent(globalContractAddress, decls.String, nil),
 		globalProxyName:       decls.NewIdent(globalProxyName, decls.String, nil),
+		globalBlock:           decls.NewIdent(globalBlock, decls.NewMapType(decls.String, decls.Dyn), nil),
+		globalTx:              decls.NewIdent(globalTx, decls.NewMapType(decls.String, decls.Dyn), nil),
+		globalSmc:             decls.NewIdent(globalSmc, decls.NewMapType(decls.String, decls.Dyn), nil),
 	}
 	signals = map[string]struct{}{
 		signalContinue: {},
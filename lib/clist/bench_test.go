@@ -18,7 +18,10 @@
 
 package clist
 
-import "testing"
+import (
+	"sync"
+	"testing"
+)
 
 func BenchmarkDetaching(b *testing.B) {
 	lst := New()
@@ -62,3 +65,58 @@ func BenchmarkPushBack(b *testing.B) {
 		lst.PushBack(i)
 	}
 }
+
+// BenchmarkPushRemoveParallel measures the throughput of pushing and removing
+// elements from concurrent goroutines, while readers traverse the list.
+func BenchmarkPushRemoveParallel(b *testing.B) {
+	lst := New()
+	done := make(chan struct{})
+	defer close(done)
+	for i := 0; i < 4; i++ {
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				case <-lst.WaitChan():
+				}
+				for e := lst.Front(); e != nil; e = e.Next() {
+					e.Removed()
+				}
+			}
+		}()
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			e := lst.PushBack(0)
+			lst.Remove(e)
+			e.DetachPrev()
+		}
+	})
+}
+
+// BenchmarkWaiters measures the cost of waking up many goroutines waiting
+// for the next element.
+func BenchmarkWaiters(b *testing.B) {
+	const waiters = 100
+	lst := New()
+	e := lst.PushBack(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		wg.Add(waiters)
+		for j := 0; j < waiters; j++ {
+			go func(e *CElement) {
+				e.NextWait()
+				wg.Done()
+			}(e)
+		}
+		next := lst.PushBack(i)
+		wg.Wait()
+		lst.Remove(e)
+		e.DetachNext()
+		e = next
+	}
+}
@@ -31,7 +31,7 @@ to ensure garbage collection of removed elements.
 
 import (
 	"fmt"
-	"sync"
+	"sync/atomic"
 
 	ksync "github.com/kardiachain/go-kardia/lib/sync"
 )
@@ -46,29 +46,18 @@ const MaxLength = int(^uint(0) >> 1)
 CElement is an element of a linked-list
 Traversal from a CElement is goroutine-safe.
 
-We can't avoid using WaitGroups or for-loops given the documentation
-spec without re-implementing the primitives that already exist in
-golang/sync. Notice that WaitGroup allows many go-routines to be
-simultaneously released, which is what we want. Mutex doesn't do
-this. RWMutex does this, but it's clumsy to use in the way that a
-WaitGroup would be used -- and we'd end up having two RWMutex's for
-prev/next each, which is doubly confusing.
-
-sync.Cond would be sort-of useful, but we don't need a write-lock in
-the for-loop. Use sync.Cond when you need serial access to the
-"condition". In our case our condition is if `next != nil || removed`,
-and there's no reason to serialize that condition for goroutines
-waiting on NextWait() (since it's just a read operation).
+Goroutines waiting for the next or previous element of a CElement, or for
+the first element of a CList, wait on a channel which is only created when
+somebody waits, and which is closed once, releasing all of them together.
+Elements nobody waits on thus cost no allocation besides the element itself.
 
 */
 type CElement struct {
 	mtx        ksync.RWMutex
 	prev       *CElement
-	prevWg     *sync.WaitGroup
-	prevWaitCh chan struct{}
+	prevWaitCh chan struct{} // Created on demand, closed once prev is set or e is removed
 	next       *CElement
-	nextWg     *sync.WaitGroup
-	nextWaitCh chan struct{}
+	nextWaitCh chan struct{} // Created on demand, closed once next is set or e is removed
 	removed    bool
 
 	Value interface{} // immutable
@@ -80,7 +69,6 @@ func (e *CElement) NextWait() *CElement {
 	for {
 		e.mtx.RLock()
 		next := e.next
-		nextWg := e.nextWg
 		removed := e.removed
 		e.mtx.RUnlock()
 
@@ -88,7 +76,7 @@ func (e *CElement) NextWait() *CElement {
 			return next
 		}
 
-		nextWg.Wait()
+		<-e.NextWaitChan()
 		// e.next doesn't necessarily exist here.
 		// That's why we need to continue a for-loop.
 	}
@@ -100,7 +88,6 @@ func (e *CElement) PrevWait() *CElement {
 	for {
 		e.mtx.RLock()
 		prev := e.prev
-		prevWg := e.prevWg
 		removed := e.removed
 		e.mtx.RUnlock()
 
@@ -108,7 +95,7 @@ func (e *CElement) PrevWait() *CElement {
 			return prev
 		}
 
-		prevWg.Wait()
+		<-e.PrevWaitChan()
 	}
 }
 
@@ -116,18 +103,38 @@ func (e *CElement) PrevWait() *CElement {
 // channel will be closed.
 func (e *CElement) PrevWaitChan() <-chan struct{} {
 	e.mtx.RLock()
-	defer e.mtx.RUnlock()
+	if e.prev != nil || e.removed {
+		e.mtx.RUnlock()
+		return closedCh
+	}
+	ch := e.prevWaitCh
+	e.mtx.RUnlock()
+	if ch != nil {
+		return ch
+	}
 
-	return e.prevWaitCh
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return waitChan(&e.prevWaitCh, e.prev != nil || e.removed)
 }
 
 // NextWaitChan can be used to wait until Next becomes not nil. Once it does,
 // channel will be closed.
 func (e *CElement) NextWaitChan() <-chan struct{} {
 	e.mtx.RLock()
-	defer e.mtx.RUnlock()
+	if e.next != nil || e.removed {
+		e.mtx.RUnlock()
+		return closedCh
+	}
+	ch := e.nextWaitCh
+	e.mtx.RUnlock()
+	if ch != nil {
+		return ch
+	}
 
-	return e.nextWaitCh
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return waitChan(&e.nextWaitCh, e.next != nil || e.removed)
 }
 
 // Nonblocking, may return nil if at the end.
@@ -174,42 +181,27 @@ func (e *CElement) DetachPrev() {
 }
 
 // NOTE: This function needs to be safe for
-// concurrent goroutines waiting on nextWg.
+// concurrent goroutines waiting on NextWaitChan.
 func (e *CElement) SetNext(newNext *CElement) {
 	e.mtx.Lock()
 
 	oldNext := e.next
 	e.next = newNext
-	if oldNext != nil && newNext == nil {
-		// See https://golang.org/pkg/sync/:
-		//
-		// If a WaitGroup is reused to wait for several independent sets of
-		// events, new Add calls must happen after all previous Wait calls have
-		// returned.
-		e.nextWg = waitGroup1() // WaitGroups are difficult to re-use.
-		e.nextWaitCh = make(chan struct{})
-	}
 	if oldNext == nil && newNext != nil {
-		e.nextWg.Done()
-		close(e.nextWaitCh)
+		release(&e.nextWaitCh)
 	}
 	e.mtx.Unlock()
 }
 
 // NOTE: This function needs to be safe for
-// concurrent goroutines waiting on prevWg
+// concurrent goroutines waiting on PrevWaitChan.
 func (e *CElement) SetPrev(newPrev *CElement) {
 	e.mtx.Lock()
 
 	oldPrev := e.prev
 	e.prev = newPrev
-	if oldPrev != nil && newPrev == nil {
-		e.prevWg = waitGroup1() // WaitGroups are difficult to re-use.
-		e.prevWaitCh = make(chan struct{})
-	}
 	if oldPrev == nil && newPrev != nil {
-		e.prevWg.Done()
-		close(e.prevWaitCh)
+		release(&e.prevWaitCh)
 	}
 	e.mtx.Unlock()
 }
@@ -220,14 +212,8 @@ func (e *CElement) SetRemoved() {
 	e.removed = true
 
 	// This wakes up anyone waiting in either direction.
-	if e.prev == nil {
-		e.prevWg.Done()
-		close(e.prevWaitCh)
-	}
-	if e.next == nil {
-		e.nextWg.Done()
-		close(e.nextWaitCh)
-	}
+	release(&e.prevWaitCh)
+	release(&e.nextWaitCh)
 	e.mtx.Unlock()
 }
 
@@ -237,24 +223,25 @@ func (e *CElement) SetRemoved() {
 // The zero value for CList is an empty list ready to use.
 // Operations are goroutine-safe.
 // Panics if length grows beyond the max.
+//
+// The list lock only guards its head and tail, and the length is read
+// without it, so traversals and Len don't contend with PushBack and Remove.
 type CList struct {
+	len    int64 // list length, atomically accessible, first for alignment
 	mtx    ksync.RWMutex
-	wg     *sync.WaitGroup
-	waitCh chan struct{}
-	head   *CElement // first element
-	tail   *CElement // last element
-	len    int       // list length
-	maxLen int       // max list length
+	waitCh chan struct{} // Created on demand, closed once the list is not empty
+	head   *CElement     // first element
+	tail   *CElement     // last element
+	maxLen int           // max list length
 }
 
 func (l *CList) Init() *CList {
 	l.mtx.Lock()
 
-	l.wg = waitGroup1()
-	l.waitCh = make(chan struct{})
+	l.waitCh = nil
 	l.head = nil
 	l.tail = nil
-	l.len = 0
+	atomic.StoreInt64(&l.len, 0)
 	l.mtx.Unlock()
 	return l
 }
@@ -271,10 +258,7 @@ func newWithMax(maxLength int) *CList {
 }
 
 func (l *CList) Len() int {
-	l.mtx.RLock()
-	len := l.len
-	l.mtx.RUnlock()
-	return len
+	return int(atomic.LoadInt64(&l.len))
 }
 
 func (l *CList) Front() *CElement {
@@ -289,13 +273,12 @@ func (l *CList) FrontWait() *CElement {
 	for {
 		l.mtx.RLock()
 		head := l.head
-		wg := l.wg
 		l.mtx.RUnlock()
 
 		if head != nil {
 			return head
 		}
-		wg.Wait()
+		<-l.WaitChan()
 		// NOTE: If you think l.head exists here, think harder.
 	}
 }
@@ -311,13 +294,12 @@ func (l *CList) BackWait() *CElement {
 	for {
 		l.mtx.RLock()
 		tail := l.tail
-		wg := l.wg
 		l.mtx.RUnlock()
 
 		if tail != nil {
 			return tail
 		}
-		wg.Wait()
+		<-l.WaitChan()
 		// l.tail doesn't necessarily exist here.
 		// That's why we need to continue a for-loop.
 	}
@@ -326,42 +308,40 @@ func (l *CList) BackWait() *CElement {
 // WaitChan can be used to wait until Front or Back becomes not nil. Once it
 // does, channel will be closed.
 func (l *CList) WaitChan() <-chan struct{} {
+	l.mtx.RLock()
+	if l.head != nil {
+		l.mtx.RUnlock()
+		return closedCh
+	}
+	ch := l.waitCh
+	l.mtx.RUnlock()
+	if ch != nil {
+		return ch
+	}
+
 	l.mtx.Lock()
 	defer l.mtx.Unlock()
-
-	return l.waitCh
+	return waitChan(&l.waitCh, l.head != nil)
 }
 
 // Panics if list grows beyond its max length.
 func (l *CList) PushBack(v interface{}) *CElement {
-	l.mtx.Lock()
-
 	// Construct a new element
-	e := &CElement{
-		prev:       nil,
-		prevWg:     waitGroup1(),
-		prevWaitCh: make(chan struct{}),
-		next:       nil,
-		nextWg:     waitGroup1(),
-		nextWaitCh: make(chan struct{}),
-		removed:    false,
-		Value:      v,
-	}
+	e := &CElement{Value: v}
 
-	// Release waiters on FrontWait/BackWait maybe
-	if l.len == 0 {
-		l.wg.Done()
-		close(l.waitCh)
-	}
-	if l.len >= l.maxLen {
+	l.mtx.Lock()
+	if l.Len() >= l.maxLen {
+		l.mtx.Unlock()
 		panic(fmt.Sprintf("clist: maximum length list reached %d", l.maxLen))
 	}
-	l.len++
+	atomic.AddInt64(&l.len, 1)
 
 	// Modify the tail
 	if l.tail == nil {
 		l.head = e
 		l.tail = e
+		// Release waiters on FrontWait/BackWait
+		release(&l.waitCh)
 	} else {
 		e.SetPrev(l.tail) // We must init e first.
 		l.tail.SetNext(e) // This will make e accessible.
@@ -392,14 +372,8 @@ func (l *CList) Remove(e *CElement) interface{} {
 		panic("Remove(e) with false tail")
 	}
 
-	// If we're removing the only item, make CList FrontWait/BackWait wait.
-	if l.len == 1 {
-		l.wg = waitGroup1() // WaitGroups are difficult to re-use.
-		l.waitCh = make(chan struct{})
-	}
-
 	// Update l.len
-	l.len--
+	atomic.AddInt64(&l.len, -1)
 
 	// Connect next/prev and set head/tail
 	if prev == nil {
@@ -413,15 +387,37 @@ func (l *CList) Remove(e *CElement) interface{} {
 		next.SetPrev(prev)
 	}
 
-	// Set .Done() on e, otherwise waiters will wait forever.
+	// Wake up the waiters of e, otherwise they will wait forever.
 	e.SetRemoved()
 
 	l.mtx.Unlock()
 	return e.Value
 }
 
-func waitGroup1() (wg *sync.WaitGroup) {
-	wg = &sync.WaitGroup{}
-	wg.Add(1)
-	return
+// closedCh is returned to those waiting for a condition which already holds.
+var closedCh = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// waitChan returns the channel *ch, creating it if needed, or closedCh if
+// done. The lock guarding *ch must be held.
+func waitChan(ch *chan struct{}, done bool) <-chan struct{} {
+	if done {
+		return closedCh
+	}
+	if *ch == nil {
+		*ch = make(chan struct{})
+	}
+	return *ch
+}
+
+// release closes the channel *ch if somebody waits on it, and resets it so
+// the next waiter creates a new one. The lock guarding *ch must be held.
+func release(ch *chan struct{}) {
+	if *ch != nil {
+		close(*ch)
+		*ch = nil
+	}
 }
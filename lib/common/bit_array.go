@@ -20,6 +20,7 @@ package common
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -347,15 +348,120 @@ func (bA *BitArray) ToProto() *kprotobits.BitArray {
 	}
 }
 
-// FromProto sets a protobuf BitArray to the given pointer.
-func (bA *BitArray) FromProto(protoBitArray *kprotobits.BitArray) {
-	if protoBitArray == nil {
-		bA = nil
-		return
+// ValidateBasic checks that the words of bA match its size and that no bit
+// past its size is set.
+func (bA *BitArray) ValidateBasic() error {
+	if bA == nil {
+		return nil
 	}
+	bA.mtx.Lock()
+	defer bA.mtx.Unlock()
+	return validateBits(int64(bA.Bits), bA.Elems)
+}
 
+// FromProto sets bA to a protobuf BitArray, which must be well formed. The
+// words of protoBitArray are copied.
+func (bA *BitArray) FromProto(protoBitArray *kprotobits.BitArray) error {
+	if protoBitArray == nil {
+		return errors.New("bit array: nil protobuf")
+	}
+	if err := validateBits(protoBitArray.Bits, protoBitArray.Elems); err != nil {
+		return err
+	}
+	bA.mtx.Lock()
+	defer bA.mtx.Unlock()
 	bA.Bits = uint(protoBitArray.Bits)
-	if len(protoBitArray.Elems) > 0 {
-		bA.Elems = protoBitArray.Elems
+	bA.Elems = make([]uint64, len(protoBitArray.Elems))
+	copy(bA.Elems, protoBitArray.Elems)
+	return nil
+}
+
+// BitArrayFromProto returns the bit array of a protobuf BitArray received
+// from a peer, which must hold at most maxBits bits. Like NewBitArray, it
+// returns nil for an empty bit array.
+func BitArrayFromProto(protoBitArray *kprotobits.BitArray, maxBits int) (*BitArray, error) {
+	if protoBitArray == nil || (protoBitArray.Bits == 0 && len(protoBitArray.Elems) == 0) {
+		return nil, nil
+	}
+	if protoBitArray.Bits > int64(maxBits) {
+		return nil, fmt.Errorf("bit array: size %d exceeds %d", protoBitArray.Bits, maxBits)
+	}
+	bA := new(BitArray)
+	if err := bA.FromProto(protoBitArray); err != nil {
+		return nil, err
+	}
+	return bA, nil
+}
+
+// MarshalCompact encodes bA as its size, as a uvarint, followed by its words
+// up to the last non-zero one, little endian. Sparse bit arrays, such as the
+// votes or parts of a peer early in a round, take a few bytes.
+func (bA *BitArray) MarshalCompact() []byte {
+	if bA == nil {
+		return []byte{0}
+	}
+	bA.mtx.Lock()
+	defer bA.mtx.Unlock()
+
+	words := len(bA.Elems)
+	for words > 0 && bA.Elems[words-1] == 0 {
+		words--
+	}
+	bz := make([]byte, binary.MaxVarintLen64+8*words)
+	n := binary.PutUvarint(bz, uint64(bA.Bits))
+	for _, elem := range bA.Elems[:words] {
+		binary.LittleEndian.PutUint64(bz[n:], elem)
+		n += 8
+	}
+	return bz[:n]
+}
+
+// UnmarshalCompact decodes a bit array encoded by MarshalCompact, which must
+// hold exactly bits bits, such as the number of validators or block parts.
+// Encodings which are not the canonical one of their bit array are rejected.
+func UnmarshalCompact(bz []byte, bits int) (*BitArray, error) {
+	size, n := binary.Uvarint(bz)
+	if n <= 0 {
+		return nil, errors.New("bit array: invalid size")
+	}
+	if bits < 0 || size != uint64(bits) {
+		return nil, fmt.Errorf("bit array: size %d, expected %d", size, bits)
+	}
+	bz = bz[n:]
+	if len(bz)%8 != 0 {
+		return nil, fmt.Errorf("bit array: %d trailing bytes", len(bz)%8)
+	}
+	words := len(bz) / 8
+	if words > (bits+63)/64 {
+		return nil, fmt.Errorf("bit array: %d words for %d bits", words, bits)
+	}
+	if words > 0 && binary.LittleEndian.Uint64(bz[len(bz)-8:]) == 0 {
+		return nil, errors.New("bit array: trailing zero word")
+	}
+	bA := NewBitArray(bits)
+	if bA == nil {
+		return nil, nil
+	}
+	for i := 0; i < words; i++ {
+		bA.Elems[i] = binary.LittleEndian.Uint64(bz[i*8:])
+	}
+	if err := validateBits(int64(bA.Bits), bA.Elems); err != nil {
+		return nil, err
+	}
+	return bA, nil
+}
+
+// validateBits checks that elems are the words of a bit array of the given
+// size.
+func validateBits(bits int64, elems []uint64) error {
+	if bits < 0 {
+		return fmt.Errorf("bit array: negative size %d", bits)
+	}
+	if words := (bits + 63) / 64; int64(len(elems)) != words {
+		return fmt.Errorf("bit array: %d words for %d bits, expected %d", len(elems), bits, words)
+	}
+	if rem := bits % 64; rem != 0 && elems[len(elems)-1]>>uint(rem) != 0 {
+		return fmt.Errorf("bit array: bits set past size %d", bits)
 	}
+	return nil
 }
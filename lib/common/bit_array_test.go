@@ -0,0 +1,104 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	kprotobits "github.com/kardiachain/go-kardia/proto/kardiachain/libs/bits"
+)
+
+func TestBitArrayProtoRoundTrip(t *testing.T) {
+	for _, bits := range []int{1, 63, 64, 65, 200} {
+		bA := NewBitArray(bits)
+		bA.SetIndex(0, true)
+		bA.SetIndex(bits-1, true)
+
+		got, err := BitArrayFromProto(bA.ToProto(), bits)
+		if err != nil {
+			t.Fatalf("%d bits: %v", bits, err)
+		}
+		if got.String() != bA.String() {
+			t.Fatalf("%d bits: got %v, want %v", bits, got, bA)
+		}
+	}
+	if bA, err := BitArrayFromProto(nil, 10); bA != nil || err != nil {
+		t.Fatalf("nil protobuf: got %v, %v", bA, err)
+	}
+}
+
+func TestBitArrayFromProtoInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		pb   *kprotobits.BitArray
+	}{
+		{"negative size", &kprotobits.BitArray{Bits: -1, Elems: []uint64{1}}},
+		{"too big", &kprotobits.BitArray{Bits: 11, Elems: []uint64{1}}},
+		{"missing words", &kprotobits.BitArray{Bits: 10}},
+		{"extra words", &kprotobits.BitArray{Bits: 10, Elems: []uint64{1, 0}}},
+		{"bits past size", &kprotobits.BitArray{Bits: 10, Elems: []uint64{1 << 10}}},
+	}
+	for _, test := range tests {
+		if _, err := BitArrayFromProto(test.pb, 10); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
+
+func TestBitArrayCompact(t *testing.T) {
+	bA := NewBitArray(130)
+	if bz := bA.MarshalCompact(); !bytes.Equal(bz, []byte{130, 1}) {
+		t.Fatalf("empty array encoded as %x", bz)
+	}
+	bA.SetIndex(3, true)
+	bz := bA.MarshalCompact()
+	if len(bz) != 2+8 {
+		t.Fatalf("encoded %d bytes, want 10", len(bz))
+	}
+	got, err := UnmarshalCompact(bz, 130)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Elems, bA.Elems) || got.Bits != bA.Bits {
+		t.Fatalf("got %v, want %v", got, bA)
+	}
+	if got, err := UnmarshalCompact(NewBitArray(0).MarshalCompact(), 0); got != nil || err != nil {
+		t.Fatalf("nil array: got %v, %v", got, err)
+	}
+
+	tests := []struct {
+		name string
+		bz   []byte
+		bits int
+	}{
+		{"empty", nil, 130},
+		{"wrong size", []byte{129, 1}, 130},
+		{"partial word", []byte{130, 1, 8, 0, 0}, 130},
+		{"zero word", []byte{130, 1, 0, 0, 0, 0, 0, 0, 0, 0}, 130},
+		{"too many words", []byte{2, 1, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}, 2},
+		{"bits past size", []byte{2, 4, 0, 0, 0, 0, 0, 0, 0}, 2},
+	}
+	for _, test := range tests {
+		if _, err := UnmarshalCompact(test.bz, test.bits); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
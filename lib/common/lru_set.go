@@ -0,0 +1,246 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// EvictReason is the reason an item left an LRUSet on its own.
+type EvictReason int
+
+const (
+	// EvictCapacity is the reason of items evicted to make room for new ones.
+	EvictCapacity EvictReason = iota
+	// EvictExpired is the reason of items which outlived the TTL of the set.
+	EvictExpired
+)
+
+// lruItem is an item of an LRUSet, with the time it was last added.
+type lruItem struct {
+	value interface{}
+	added time.Time
+}
+
+// LRUSet is a goroutine-safe set holding up to a fixed number of items. It
+// offers the methods of mapset.Set used by the node, but it is bounded:
+//
+// Adding an item to a full set evicts the least recently added one, and items
+// added more than the TTL ago are evicted, if the set has a TTL. Adding an
+// item already in the set refreshes it. Contains does not.
+type LRUSet struct {
+	capacity int
+	ttl      time.Duration
+	onEvict  func(item interface{}, reason EvictReason)
+	now      func() time.Time
+
+	mu    sync.Mutex
+	items map[interface{}]*list.Element
+	order *list.List // Items from the most to the least recently added
+}
+
+// NewLRUSet returns a set holding up to capacity items, for ttl or forever
+// if ttl is 0. It panics if capacity is not positive.
+func NewLRUSet(capacity int, ttl time.Duration) *LRUSet {
+	if capacity <= 0 {
+		panic("common: LRUSet capacity must be positive")
+	}
+	return &LRUSet{
+		capacity: capacity,
+		ttl:      ttl,
+		now:      time.Now,
+		items:    make(map[interface{}]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// SetEvictCallback sets a function called with the items evicted because the
+// set was full or they expired. It is not called for removed or popped items.
+// The set is not locked during the calls.
+func (s *LRUSet) SetEvictCallback(fn func(item interface{}, reason EvictReason)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onEvict = fn
+}
+
+// Capacity returns the maximum number of items of the set.
+func (s *LRUSet) Capacity() int {
+	return s.capacity
+}
+
+// Add adds an item to the set, evicting the least recently added item if the
+// set is full. It reports whether the item was not in the set.
+func (s *LRUSet) Add(item interface{}) bool {
+	s.mu.Lock()
+	now := s.now()
+	evicted := s.expire(now)
+
+	if elem, ok := s.items[item]; ok {
+		elem.Value.(*lruItem).added = now
+		s.order.MoveToFront(elem)
+		s.mu.Unlock()
+		s.evicted(evicted, EvictExpired)
+		return false
+	}
+	var full []interface{}
+	for s.order.Len() >= s.capacity {
+		full = append(full, s.removeElement(s.order.Back()))
+	}
+	s.items[item] = s.order.PushFront(&lruItem{value: item, added: now})
+	s.mu.Unlock()
+
+	s.evicted(evicted, EvictExpired)
+	s.evicted(full, EvictCapacity)
+	return true
+}
+
+// Contains reports whether all the items are in the set.
+func (s *LRUSet) Contains(items ...interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for _, item := range items {
+		elem, ok := s.items[item]
+		if !ok || s.expired(elem.Value.(*lruItem), now) {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove removes an item from the set.
+func (s *LRUSet) Remove(item interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.items[item]; ok {
+		s.removeElement(elem)
+	}
+}
+
+// Pop removes and returns the least recently added item, or nil if the set
+// is empty.
+func (s *LRUSet) Pop() interface{} {
+	s.mu.Lock()
+	evicted := s.expire(s.now())
+	var item interface{}
+	if elem := s.order.Back(); elem != nil {
+		item = s.removeElement(elem)
+	}
+	s.mu.Unlock()
+
+	s.evicted(evicted, EvictExpired)
+	return item
+}
+
+// Cardinality returns the number of unexpired items of the set.
+func (s *LRUSet) Cardinality() int {
+	s.mu.Lock()
+	evicted := s.expire(s.now())
+	n := s.order.Len()
+	s.mu.Unlock()
+
+	s.evicted(evicted, EvictExpired)
+	return n
+}
+
+// Clear removes all the items.
+func (s *LRUSet) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = make(map[interface{}]*list.Element)
+	s.order.Init()
+}
+
+// ToSlice returns the unexpired items, from the most to the least recently
+// added.
+func (s *LRUSet) ToSlice() []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	items := make([]interface{}, 0, s.order.Len())
+	for elem := s.order.Front(); elem != nil; elem = elem.Next() {
+		if item := elem.Value.(*lruItem); !s.expired(item, now) {
+			items = append(items, item.value)
+		}
+	}
+	return items
+}
+
+// Each calls fn with the unexpired items, from the most to the least recently
+// added, until it returns true.
+func (s *LRUSet) Each(fn func(interface{}) bool) {
+	for _, item := range s.ToSlice() {
+		if fn(item) {
+			return
+		}
+	}
+}
+
+// Expire evicts the expired items and returns their number.
+func (s *LRUSet) Expire() int {
+	s.mu.Lock()
+	evicted := s.expire(s.now())
+	s.mu.Unlock()
+
+	s.evicted(evicted, EvictExpired)
+	return len(evicted)
+}
+
+// expire removes the expired items and returns them. The lock must be held.
+func (s *LRUSet) expire(now time.Time) []interface{} {
+	if s.ttl <= 0 {
+		return nil
+	}
+	var evicted []interface{}
+	for elem := s.order.Back(); elem != nil && s.expired(elem.Value.(*lruItem), now); elem = s.order.Back() {
+		evicted = append(evicted, s.removeElement(elem))
+	}
+	return evicted
+}
+
+func (s *LRUSet) expired(item *lruItem, now time.Time) bool {
+	return s.ttl > 0 && now.Sub(item.added) >= s.ttl
+}
+
+// removeElement removes elem and returns its item. The lock must be held.
+func (s *LRUSet) removeElement(elem *list.Element) interface{} {
+	item := s.order.Remove(elem).(*lruItem).value
+	delete(s.items, item)
+	return item
+}
+
+// evicted calls the eviction callback with the evicted items.
+func (s *LRUSet) evicted(items []interface{}, reason EvictReason) {
+	if len(items) == 0 {
+		return
+	}
+	s.mu.Lock()
+	fn := s.onEvict
+	s.mu.Unlock()
+	if fn == nil {
+		return
+	}
+	for _, item := range items {
+		fn(item, reason)
+	}
+}
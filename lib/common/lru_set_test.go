@@ -0,0 +1,127 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLRUSetCapacity(t *testing.T) {
+	var evicted []interface{}
+	s := NewLRUSet(3, 0)
+	s.SetEvictCallback(func(item interface{}, reason EvictReason) {
+		if reason != EvictCapacity {
+			t.Errorf("evicted %v with reason %v", item, reason)
+		}
+		evicted = append(evicted, item)
+	})
+
+	for i := 0; i < 3; i++ {
+		if !s.Add(i) {
+			t.Fatalf("%d already in set", i)
+		}
+	}
+	// Refresh 0, making 1 the least recently added.
+	if s.Add(0) {
+		t.Fatal("0 not in set")
+	}
+	s.Add(3)
+	s.Add(4)
+	if !reflect.DeepEqual(evicted, []interface{}{1, 2}) {
+		t.Fatalf("evicted %v, want [1 2]", evicted)
+	}
+	if n := s.Cardinality(); n != 3 {
+		t.Fatalf("cardinality %d, want 3", n)
+	}
+	if !s.Contains(0, 3, 4) || s.Contains(1) {
+		t.Fatalf("wrong items %v", s.ToSlice())
+	}
+	if item := s.Pop(); item != 0 {
+		t.Fatalf("popped %v, want 0", item)
+	}
+	s.Remove(3)
+	if items := s.ToSlice(); !reflect.DeepEqual(items, []interface{}{4}) {
+		t.Fatalf("items %v, want [4]", items)
+	}
+	s.Clear()
+	if s.Cardinality() != 0 || s.Pop() != nil {
+		t.Fatal("set not empty after Clear")
+	}
+	if len(evicted) != 2 {
+		t.Fatalf("callback called for removed items: %v", evicted)
+	}
+}
+
+func TestLRUSetTTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewLRUSet(10, time.Minute)
+	s.now = func() time.Time { return now }
+
+	var expired []interface{}
+	s.SetEvictCallback(func(item interface{}, reason EvictReason) {
+		if reason != EvictExpired {
+			t.Errorf("evicted %v with reason %v", item, reason)
+		}
+		expired = append(expired, item)
+	})
+
+	s.Add("a")
+	now = now.Add(30 * time.Second)
+	s.Add("b")
+	now = now.Add(30 * time.Second)
+	if s.Contains("a") || !s.Contains("b") {
+		t.Fatalf("wrong items %v", s.ToSlice())
+	}
+	// Re-adding refreshes the TTL of b.
+	s.Add("b")
+	now = now.Add(45 * time.Second)
+	if n := s.Cardinality(); n != 1 {
+		t.Fatalf("cardinality %d, want 1", n)
+	}
+	now = now.Add(15 * time.Second)
+	if n := s.Expire(); n != 1 {
+		t.Fatalf("expired %d items, want 1", n)
+	}
+	if !reflect.DeepEqual(expired, []interface{}{"a", "b"}) {
+		t.Fatalf("expired %v, want [a b]", expired)
+	}
+}
+
+func TestLRUSetInvalidCapacity(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewLRUSet did not panic")
+		}
+	}()
+	NewLRUSet(0, 0)
+}
+
+func BenchmarkLRUSetAdd(b *testing.B) {
+	s := NewLRUSet(32768, time.Minute)
+	hashes := make([]Hash, 65536)
+	for i := range hashes {
+		hashes[i] = BytesToHash([]byte{byte(i >> 8), byte(i)})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Add(hashes[i%len(hashes)])
+	}
+}
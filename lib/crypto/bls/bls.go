@@ -0,0 +1,237 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package bls implements BLS signatures on the BLS12-381 curve, which can be
+// aggregated into a single signature.
+//
+// Public keys are points of G1 and signatures points of G2, both serialized
+// uncompressed. Every message is prefixed with the public key of its signer
+// before being hashed to G2 (the message augmentation scheme), so aggregates
+// of signatures over identical messages are safe without a proof of
+// possession of the keys.
+package bls
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+const (
+	// SecretKeyLength is the length of a serialized secret key.
+	SecretKeyLength = 32
+	// PublicKeyLength is the length of a serialized public key.
+	PublicKeyLength = 96
+	// SignatureLength is the length of a serialized signature.
+	SignatureLength = 192
+)
+
+// dst separates the hashes to G2 of this package from other protocols.
+var dst = []byte("KARDIA_BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_AUG_")
+
+// fieldModulus is the modulus of the base field of BLS12-381.
+var fieldModulus, _ = new(big.Int).SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", 16)
+
+var (
+	errInvalidSecretKey = errors.New("invalid BLS secret key")
+	errInvalidPublicKey = errors.New("invalid BLS public key")
+	errInvalidSignature = errors.New("invalid BLS signature")
+	errNoSignatures     = errors.New("no BLS signatures to aggregate")
+)
+
+// SecretKey is a BLS secret key.
+type SecretKey struct {
+	k *big.Int
+}
+
+// GenerateKey generates a random secret key.
+func GenerateKey() (*SecretKey, error) {
+	order := bls12381.NewG1().Q()
+	for {
+		k, err := rand.Int(rand.Reader, order)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() > 0 {
+			return &SecretKey{k: k}, nil
+		}
+	}
+}
+
+// SecretKeyFromBytes decodes a serialized secret key.
+func SecretKeyFromBytes(b []byte) (*SecretKey, error) {
+	if len(b) != SecretKeyLength {
+		return nil, errInvalidSecretKey
+	}
+	k := new(big.Int).SetBytes(b)
+	if k.Sign() == 0 || k.Cmp(bls12381.NewG1().Q()) >= 0 {
+		return nil, errInvalidSecretKey
+	}
+	return &SecretKey{k: k}, nil
+}
+
+// Bytes returns the serialized secret key.
+func (sk *SecretKey) Bytes() []byte {
+	return leftPad(sk.k.Bytes(), SecretKeyLength)
+}
+
+// PublicKey returns the serialized public key of sk.
+func (sk *SecretKey) PublicKey() []byte {
+	g1 := bls12381.NewG1()
+	return g1.ToBytes(g1.MulScalar(g1.New(), g1.One(), sk.k))
+}
+
+// Sign signs msg with sk.
+func (sk *SecretKey) Sign(msg []byte) []byte {
+	g2 := bls12381.NewG2()
+	h := hashToG2(sk.PublicKey(), msg)
+	return g2.ToBytes(g2.MulScalar(g2.New(), h, sk.k))
+}
+
+// Verify reports whether sig is a signature of msg by the key pub.
+func Verify(pub, msg, sig []byte) bool {
+	return VerifyAggregate([][]byte{pub}, [][]byte{msg}, sig)
+}
+
+// Aggregate aggregates signatures into a single one.
+func Aggregate(sigs [][]byte) ([]byte, error) {
+	if len(sigs) == 0 {
+		return nil, errNoSignatures
+	}
+	g2 := bls12381.NewG2()
+	agg := g2.Zero()
+	for _, sig := range sigs {
+		p, err := decodeSignature(g2, sig)
+		if err != nil {
+			return nil, err
+		}
+		g2.Add(agg, agg, p)
+	}
+	return g2.ToBytes(agg), nil
+}
+
+// VerifyAggregate reports whether sig aggregates the signatures of msgs[i]
+// by the keys pubs[i].
+func VerifyAggregate(pubs, msgs [][]byte, sig []byte) bool {
+	if len(pubs) == 0 || len(pubs) != len(msgs) {
+		return false
+	}
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+	s, err := decodeSignature(g2, sig)
+	if err != nil {
+		return false
+	}
+	engine := bls12381.NewPairingEngine()
+	for i, pub := range pubs {
+		p, err := decodePublicKey(g1, pub)
+		if err != nil {
+			return false
+		}
+		engine.AddPair(p, hashToG2(pub, msgs[i]))
+	}
+	engine.AddPairInv(g1.One(), s)
+	return engine.Check()
+}
+
+// ValidatePublicKey returns an error if pub is not a valid public key.
+func ValidatePublicKey(pub []byte) error {
+	_, err := decodePublicKey(bls12381.NewG1(), pub)
+	return err
+}
+
+func decodePublicKey(g1 *bls12381.G1, pub []byte) (*bls12381.PointG1, error) {
+	if len(pub) != PublicKeyLength {
+		return nil, errInvalidPublicKey
+	}
+	p, err := g1.FromBytes(pub)
+	if err != nil || g1.IsZero(p) || !g1.InCorrectSubgroup(p) {
+		return nil, errInvalidPublicKey
+	}
+	return p, nil
+}
+
+func decodeSignature(g2 *bls12381.G2, sig []byte) (*bls12381.PointG2, error) {
+	if len(sig) != SignatureLength {
+		return nil, errInvalidSignature
+	}
+	p, err := g2.FromBytes(sig)
+	if err != nil || !g2.InCorrectSubgroup(p) {
+		return nil, errInvalidSignature
+	}
+	return p, nil
+}
+
+// hashToG2 hashes the message augmented with the public key of its signer
+// to G2, following the hash_to_curve construction of the IETF draft.
+func hashToG2(pub, msg []byte) *bls12381.PointG2 {
+	g2 := bls12381.NewG2()
+	uniform := expandMessage(append(append([]byte{}, pub...), msg...), 256)
+	u0, err := g2.MapToCurve(fieldElement2(uniform[:128]))
+	if err != nil {
+		panic(err)
+	}
+	u1, err := g2.MapToCurve(fieldElement2(uniform[128:]))
+	if err != nil {
+		panic(err)
+	}
+	return g2.Affine(g2.Add(g2.New(), u0, u1))
+}
+
+// fieldElement2 reduces 128 uniform bytes to an element of the quadratic
+// extension field, serialized as the c1 then c0 coefficients.
+func fieldElement2(uniform []byte) []byte {
+	c0 := new(big.Int).Mod(new(big.Int).SetBytes(uniform[:64]), fieldModulus)
+	c1 := new(big.Int).Mod(new(big.Int).SetBytes(uniform[64:]), fieldModulus)
+	return append(leftPad(c1.Bytes(), 48), leftPad(c0.Bytes(), 48)...)
+}
+
+func leftPad(b []byte, size int) []byte {
+	out := make([]byte, size)
+	copy(out[size-len(b):], b)
+	return out
+}
+
+// expandMessage implements expand_message_xmd with SHA-256.
+func expandMessage(msg []byte, length int) []byte {
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	h := sha256.New()
+	h.Write(make([]byte, h.BlockSize()))
+	h.Write(msg)
+	h.Write([]byte{byte(length >> 8), byte(length), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, length)
+	bi := make([]byte, sha256.Size)
+	for i := 1; len(out) < length; i++ {
+		h.Reset()
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+	return out[:length]
+}
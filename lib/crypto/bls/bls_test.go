@@ -0,0 +1,87 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bls
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	sk, err := GenerateKey()
+	require.NoError(t, err)
+	pub := sk.PublicKey()
+	require.Len(t, pub, PublicKeyLength)
+	require.NoError(t, ValidatePublicKey(pub))
+
+	msg := []byte("precommit")
+	sig := sk.Sign(msg)
+	require.Len(t, sig, SignatureLength)
+	assert.True(t, Verify(pub, msg, sig))
+	assert.False(t, Verify(pub, []byte("prevote"), sig))
+
+	other, err := GenerateKey()
+	require.NoError(t, err)
+	assert.False(t, Verify(other.PublicKey(), msg, sig))
+
+	decoded, err := SecretKeyFromBytes(sk.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, pub, decoded.PublicKey())
+}
+
+func TestAggregate(t *testing.T) {
+	var (
+		pubs, msgs, sigs [][]byte
+	)
+	for i := 0; i < 4; i++ {
+		sk, err := GenerateKey()
+		require.NoError(t, err)
+		// The last two validators sign the same message.
+		msg := []byte{byte(i)}
+		if i == 3 {
+			msg = msgs[2]
+		}
+		pubs = append(pubs, sk.PublicKey())
+		msgs = append(msgs, msg)
+		sigs = append(sigs, sk.Sign(msg))
+	}
+
+	agg, err := Aggregate(sigs)
+	require.NoError(t, err)
+	assert.True(t, VerifyAggregate(pubs, msgs, agg))
+
+	// Missing signers and swapped messages must be detected.
+	assert.False(t, VerifyAggregate(pubs[1:], msgs[1:], agg))
+	assert.False(t, VerifyAggregate(pubs, [][]byte{msgs[1], msgs[0], msgs[2], msgs[3]}, agg))
+	assert.False(t, VerifyAggregate(pubs, msgs[1:], agg))
+
+	_, err = Aggregate(nil)
+	assert.Error(t, err)
+	_, err = Aggregate([][]byte{[]byte("bad")})
+	assert.Error(t, err)
+}
+
+func TestInvalidKeys(t *testing.T) {
+	_, err := SecretKeyFromBytes(make([]byte, SecretKeyLength))
+	assert.Error(t, err)
+	assert.Error(t, ValidatePublicKey(make([]byte, PublicKeyLength)))
+	assert.Error(t, ValidatePublicKey([]byte{1}))
+}
@@ -0,0 +1,187 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package event
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrSlowSubscriber is sent on the error channel of a subscription with the
+// UnsubscribePolicy when it ends because its channel was full.
+var ErrSlowSubscriber = errors.New("event: subscriber too slow")
+
+// DropPolicy decides what a TypedFeed does when the channel of a subscriber
+// is full.
+type DropPolicy int
+
+const (
+	// BlockPolicy makes Send wait for the subscriber, like Feed does.
+	BlockPolicy DropPolicy = iota
+	// DropEventPolicy drops the event for the subscriber.
+	DropEventPolicy
+	// UnsubscribePolicy ends the subscription with ErrSlowSubscriber.
+	UnsubscribePolicy
+)
+
+// DeliverFunc sends an event to the channel of a subscriber. If quit is nil
+// it must not block, otherwise it must block until the event is sent or quit
+// is closed. It reports whether the event was sent.
+type DeliverFunc func(ev interface{}, quit <-chan struct{}) bool
+
+// TypedFeed implements one-to-many subscriptions like Feed, without
+// reflection. It is the event type independent part of typed feeds, which
+// wrap it with a Subscribe method taking a channel of their event type and
+// a Send method taking their event type, such as:
+//
+//	func (f *HeadFeed) Subscribe(ch chan<- Head) Subscription {
+//		return f.feed.Subscribe(func(ev interface{}, quit <-chan struct{}) bool {
+//			if quit == nil {
+//				select {
+//				case ch <- ev.(Head):
+//					return true
+//				default:
+//					return false
+//				}
+//			}
+//			select {
+//			case ch <- ev.(Head):
+//				return true
+//			case <-quit:
+//				return false
+//			}
+//		}, event.BlockPolicy)
+//	}
+//
+// Subscribers with the BlockPolicy are sent events one after the other, so
+// they should have ample buffer space. The zero value is ready to use.
+type TypedFeed struct {
+	mu   sync.Mutex
+	subs atomic.Value // Current []*typedSub, replaced on every change
+}
+
+// Subscribe adds a subscriber, sending it future events with deliver until
+// the subscription is canceled.
+func (f *TypedFeed) Subscribe(deliver DeliverFunc, policy DropPolicy) Subscription {
+	sub := &typedSub{
+		feed:    f,
+		deliver: deliver,
+		policy:  policy,
+		quit:    make(chan struct{}),
+		err:     make(chan error, 1),
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	current := f.load()
+	subs := make([]*typedSub, len(current), len(current)+1)
+	copy(subs, current)
+	f.subs.Store(append(subs, sub))
+	return sub
+}
+
+// Send delivers ev to all subscribers, according to their drop policy. It
+// returns the number of subscribers ev was sent to.
+func (f *TypedFeed) Send(ev interface{}) (nsent int) {
+	for _, sub := range f.load() {
+		switch sub.policy {
+		case BlockPolicy:
+			if sub.deliver(ev, sub.quit) {
+				nsent++
+			}
+		default:
+			select {
+			case <-sub.quit:
+				continue
+			default:
+			}
+			if sub.deliver(ev, nil) {
+				nsent++
+				continue
+			}
+			atomic.AddUint64(&sub.dropped, 1)
+			if sub.policy == UnsubscribePolicy {
+				sub.close(ErrSlowSubscriber)
+			}
+		}
+	}
+	return nsent
+}
+
+// Len returns the number of subscribers.
+func (f *TypedFeed) Len() int {
+	return len(f.load())
+}
+
+func (f *TypedFeed) load() []*typedSub {
+	subs, _ := f.subs.Load().([]*typedSub)
+	return subs
+}
+
+func (f *TypedFeed) remove(sub *typedSub) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	current := f.load()
+	subs := make([]*typedSub, 0, len(current))
+	for _, s := range current {
+		if s != sub {
+			subs = append(subs, s)
+		}
+	}
+	f.subs.Store(subs)
+}
+
+type typedSub struct {
+	dropped uint64 // Number of dropped events, atomically accessible, first for alignment
+	feed    *TypedFeed
+	deliver DeliverFunc
+	policy  DropPolicy
+
+	once sync.Once
+	quit chan struct{}
+	err  chan error
+}
+
+func (sub *typedSub) Err() <-chan error {
+	return sub.err
+}
+
+func (sub *typedSub) Unsubscribe() {
+	sub.close(nil)
+}
+
+// DroppedEvents returns the number of events a subscription of a TypedFeed
+// missed because its channel was full, or 0 for other subscriptions.
+func DroppedEvents(sub Subscription) uint64 {
+	if sub, ok := sub.(*typedSub); ok {
+		return atomic.LoadUint64(&sub.dropped)
+	}
+	return 0
+}
+
+func (sub *typedSub) close(err error) {
+	sub.once.Do(func() {
+		sub.feed.remove(sub)
+		close(sub.quit)
+		if err != nil {
+			sub.err <- err
+		}
+		close(sub.err)
+	})
+}
@@ -0,0 +1,177 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package event
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// intFeed is a typed feed of ints.
+type intFeed struct {
+	feed TypedFeed
+}
+
+func (f *intFeed) Subscribe(ch chan<- int, policy DropPolicy) Subscription {
+	return f.feed.Subscribe(func(ev interface{}, quit <-chan struct{}) bool {
+		if quit == nil {
+			select {
+			case ch <- ev.(int):
+				return true
+			default:
+				return false
+			}
+		}
+		select {
+		case ch <- ev.(int):
+			return true
+		case <-quit:
+			return false
+		}
+	}, policy)
+}
+
+func (f *intFeed) Send(ev int) int {
+	return f.feed.Send(ev)
+}
+
+func TestTypedFeedPolicies(t *testing.T) {
+	var feed intFeed
+	var (
+		blockCh       = make(chan int, 2)
+		dropCh        = make(chan int, 1)
+		unsubscribeCh = make(chan int, 1)
+	)
+	block := feed.Subscribe(blockCh, BlockPolicy)
+	drop := feed.Subscribe(dropCh, DropEventPolicy)
+	unsubscribe := feed.Subscribe(unsubscribeCh, UnsubscribePolicy)
+	require.Equal(t, 3, feed.feed.Len())
+
+	require.Equal(t, 3, feed.Send(1))
+	require.Equal(t, 1, feed.Send(2))
+	require.Equal(t, uint64(1), DroppedEvents(drop))
+	require.Equal(t, ErrSlowSubscriber, <-unsubscribe.Err())
+	_, open := <-unsubscribe.Err()
+	require.False(t, open)
+	require.Equal(t, 2, feed.feed.Len())
+
+	require.Equal(t, []int{1, 2}, []int{<-blockCh, <-blockCh})
+	require.Equal(t, 1, <-dropCh)
+	require.Equal(t, 1, <-unsubscribeCh)
+
+	drop.Unsubscribe()
+	block.Unsubscribe()
+	require.Equal(t, 0, feed.feed.Len())
+	require.Equal(t, 0, feed.Send(3))
+}
+
+func TestTypedFeedUnsubscribeUnblocksSend(t *testing.T) {
+	var feed intFeed
+	sub := feed.Subscribe(make(chan int), BlockPolicy)
+
+	done := make(chan int)
+	go func() { done <- feed.Send(1) }()
+	time.Sleep(10 * time.Millisecond)
+	sub.Unsubscribe()
+
+	select {
+	case n := <-done:
+		require.Equal(t, 0, n)
+	case <-time.After(time.Second):
+		t.Fatal("Send still blocked after Unsubscribe")
+	}
+	_, open := <-sub.Err()
+	require.False(t, open)
+}
+
+func TestTypedFeedConcurrentSubscribe(t *testing.T) {
+	var (
+		feed intFeed
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			ch := make(chan int, 100)
+			sub := feed.Subscribe(ch, DropEventPolicy)
+			time.Sleep(time.Millisecond)
+			sub.Unsubscribe()
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				feed.Send(j)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 0, feed.feed.Len())
+}
+
+func BenchmarkTypedFeedSend1000(b *testing.B) {
+	var (
+		done  sync.WaitGroup
+		feed  intFeed
+		nsubs = 1000
+	)
+	subscriber := func(ch <-chan int) {
+		for i := 0; i < b.N; i++ {
+			<-ch
+		}
+		done.Done()
+	}
+	done.Add(nsubs)
+	for i := 0; i < nsubs; i++ {
+		ch := make(chan int, 200)
+		feed.Subscribe(ch, BlockPolicy)
+		go subscriber(ch)
+	}
+
+	// The actual benchmark.
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if feed.Send(i) != nsubs {
+			panic("wrong number of sends")
+		}
+	}
+
+	b.StopTimer()
+	done.Wait()
+}
+
+func BenchmarkTypedFeedSendDrop1000(b *testing.B) {
+	var (
+		feed  intFeed
+		nsubs = 1000
+	)
+	for i := 0; i < nsubs; i++ {
+		// Nobody reads the channels, every event past the first one is
+		// dropped.
+		feed.Subscribe(make(chan int, 1), DropEventPolicy)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		feed.Send(i)
+	}
+}
@@ -266,6 +266,9 @@ func JSONFormatEx(pretty, lineSeparated bool) Format {
 		props[r.KeyNames.Time] = r.Time
 		props[r.KeyNames.Lvl] = r.Lvl.String()
 		props[r.KeyNames.Msg] = r.Msg
+		if r.Tag != nil && len(r.Tag.tags) > 0 {
+			props[tagKey] = strings.Join(r.Tag.tags, ",")
+		}
 
 		for i := 0; i < len(r.Ctx); i += 2 {
 			k, ok := r.Ctx[i].(string)
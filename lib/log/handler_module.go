@@ -0,0 +1,162 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ModuleKey is the context key naming the module of a logger, such as
+// log.New(log.ModuleKey, "consensus"). Child loggers inherit it.
+const ModuleKey = "module"
+
+// errModuleSyntax is returned when a user module level list is invalid.
+var errModuleSyntax = errors.New("expect comma-separated list of module=level")
+
+// ModuleHandler is a log handler filtering records by level, with per-module
+// levels overriding the default one. Levels may be changed at runtime.
+//
+// The module of a record is the last value of its ModuleKey context, or else
+// its last tag. Modules are case insensitive.
+type ModuleHandler struct {
+	origin Handler // The origin handler this wraps
+
+	level   uint32       // Default log level, atomically accessible
+	modules atomic.Value // Current map[string]Lvl of module levels
+	lock    sync.Mutex   // Lock serializing module level updates
+}
+
+// NewModuleHandler creates a new log handler writing the records of level up
+// to level to h.
+func NewModuleHandler(level Lvl, h Handler) *ModuleHandler {
+	mh := &ModuleHandler{origin: h, level: uint32(level)}
+	mh.modules.Store(map[string]Lvl{})
+	return mh
+}
+
+// SetHandler updates the handler to write records to the specified sub-handler.
+func (h *ModuleHandler) SetHandler(nh Handler) {
+	h.origin = nh
+}
+
+// Level returns the default log level.
+func (h *ModuleHandler) Level() Lvl {
+	return Lvl(atomic.LoadUint32(&h.level))
+}
+
+// SetLevel sets the default log level, used by modules without a level.
+func (h *ModuleHandler) SetLevel(level Lvl) {
+	atomic.StoreUint32(&h.level, uint32(level))
+}
+
+// SetModuleLevel sets the log level of module.
+func (h *ModuleHandler) SetModuleLevel(module string, level Lvl) {
+	h.update(func(modules map[string]Lvl) {
+		modules[strings.ToLower(module)] = level
+	})
+}
+
+// ResetModuleLevel makes module use the default log level again.
+func (h *ModuleHandler) ResetModuleLevel(module string) {
+	h.update(func(modules map[string]Lvl) {
+		delete(modules, strings.ToLower(module))
+	})
+}
+
+// SetModuleLevels replaces the module levels with a comma-separated list of
+// module=level, such as "consensus=debug,p2p=error". An empty list resets
+// every module to the default level.
+func (h *ModuleHandler) SetModuleLevels(ruleset string) error {
+	levels, err := ParseModuleLevels(ruleset)
+	if err != nil {
+		return err
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.modules.Store(levels)
+	return nil
+}
+
+// ModuleLevels returns the module levels in the syntax of SetModuleLevels,
+// sorted by module.
+func (h *ModuleHandler) ModuleLevels() string {
+	modules := h.modules.Load().(map[string]Lvl)
+	rules := make([]string, 0, len(modules))
+	for module, level := range modules {
+		rules = append(rules, module+"="+level.String())
+	}
+	sort.Strings(rules)
+	return strings.Join(rules, ",")
+}
+
+// update applies fn to a copy of the module levels and stores it.
+func (h *ModuleHandler) update(fn func(map[string]Lvl)) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	current := h.modules.Load().(map[string]Lvl)
+	modules := make(map[string]Lvl, len(current)+1)
+	for module, level := range current {
+		modules[module] = level
+	}
+	fn(modules)
+	h.modules.Store(modules)
+}
+
+// Log implements Handler.Log, filtering a log record by the level of its
+// module, or the default level.
+func (h *ModuleHandler) Log(r *Record) error {
+	level := h.Level()
+	if modules := h.modules.Load().(map[string]Lvl); len(modules) > 0 {
+		if module := recordModule(r); module != "" {
+			if lvl, ok := modules[strings.ToLower(module)]; ok {
+				level = lvl
+			}
+		}
+	}
+	if r.Lvl > level {
+		return nil
+	}
+	return h.origin.Log(r)
+}
+
+// recordModule returns the module of r, or "" if it has none.
+func recordModule(r *Record) string {
+	for i := len(r.Ctx) - 2; i >= 0; i -= 2 {
+		if key, ok := r.Ctx[i].(string); ok && key == ModuleKey {
+			return fmt.Sprint(r.Ctx[i+1])
+		}
+	}
+	if r.Tag != nil && len(r.Tag.tags) > 0 {
+		return r.Tag.tags[len(r.Tag.tags)-1]
+	}
+	return ""
+}
+
+// ParseModuleLevels parses a comma-separated list of module=level, such as
+// "consensus=debug,p2p=error".
+func ParseModuleLevels(ruleset string) (map[string]Lvl, error) {
+	levels := make(map[string]Lvl)
+	for _, rule := range strings.Split(ruleset, ",") {
+		if strings.TrimSpace(rule) == "" {
+			continue
+		}
+		parts := strings.Split(rule, "=")
+		if len(parts) != 2 {
+			return nil, errModuleSyntax
+		}
+		module := strings.ToLower(strings.TrimSpace(parts[0]))
+		if module == "" {
+			return nil, errModuleSyntax
+		}
+		level, err := LvlFromString(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, err
+		}
+		levels[module] = level
+	}
+	return levels, nil
+}
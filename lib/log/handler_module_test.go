@@ -0,0 +1,49 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleHandler(t *testing.T) {
+	var records []*Record
+	h := NewModuleHandler(LvlInfo, FuncHandler(func(r *Record) error {
+		records = append(records, r)
+		return nil
+	}))
+	l := &logger{[]interface{}{}, new(swapHandler), nil}
+	l.SetHandler(h)
+
+	consensus := l.New(ModuleKey, "consensus", "height", 1)
+	p2p := l.New(ModuleKey, "p2p")
+	tagged := l.New()
+	tagged.AddTag("BCR")
+
+	emit := func() {
+		records = nil
+		for _, logger := range []Logger{l, consensus, consensus.New("round", 0), p2p, tagged} {
+			logger.Debug("debug")
+			logger.Warn("warn")
+		}
+	}
+	emit()
+	require.Len(t, records, 5)
+
+	require.NoError(t, h.SetModuleLevels("Consensus=debug, p2p=error,bcr=crit"))
+	require.Equal(t, "bcr=crit,consensus=dbug,p2p=eror", h.ModuleLevels())
+	emit()
+	require.Len(t, records, 5)
+	require.Equal(t, LvlDebug, records[1].Lvl)
+	require.Equal(t, []interface{}{ModuleKey, "consensus", "height", 1, "round", 0}, records[3].Ctx)
+
+	h.ResetModuleLevel("p2p")
+	h.SetLevel(LvlDebug)
+	emit()
+	require.Len(t, records, 8)
+
+	require.Error(t, h.SetModuleLevels("consensus"))
+	require.Error(t, h.SetModuleLevels("consensus=loud"))
+	require.NoError(t, h.SetModuleLevels(""))
+	require.Equal(t, "", h.ModuleLevels())
+}
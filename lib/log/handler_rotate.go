@@ -0,0 +1,230 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the time format of the suffix of rotated log files,
+// which sorts in chronological order.
+const backupTimeFormat = "20060102T150405.000000000"
+
+// RotateConfig configures when RotatingFileHandler rotates its file and how
+// many rotated files it keeps.
+type RotateConfig struct {
+	MaxSize    int64         // Size in bytes past which the file is rotated, 0 for no limit
+	MaxAge     time.Duration // Time since opening past which the file is rotated, 0 for no limit
+	MaxBackups int           // Number of rotated files to keep, 0 to keep them all
+	Compress   bool          // Whether rotated files are gzipped
+}
+
+// RotatingFileHandler writes log records to a file, which it renames with
+// a timestamp suffix and replaces by a new one once it grows too large or
+// too old. Rotated files are compressed and pruned in the background.
+type RotatingFileHandler struct {
+	path   string
+	fmtr   Format
+	config RotateConfig
+	h      Handler
+
+	lock   sync.Mutex // Lock protecting the file
+	file   *os.File
+	size   int64
+	opened time.Time
+
+	mill sync.Mutex     // Lock serializing compression and pruning
+	wg   sync.WaitGroup // Background compression and pruning
+}
+
+// NewRotatingFileHandler returns a handler which writes log records to the
+// file at path using the given format, appending to it if it exists.
+func NewRotatingFileHandler(path string, fmtr Format, config RotateConfig) (*RotatingFileHandler, error) {
+	h := &RotatingFileHandler{path: path, fmtr: fmtr, config: config}
+	if err := h.open(); err != nil {
+		return nil, err
+	}
+	h.h = LazyHandler(FuncHandler(h.write))
+	return h, nil
+}
+
+// Log implements Handler.Log.
+func (h *RotatingFileHandler) Log(r *Record) error {
+	return h.h.Log(r)
+}
+
+// Rotate rotates the file now.
+func (h *RotatingFileHandler) Rotate() error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.rotate()
+}
+
+// Reopen closes the file and opens the file at path again, which lets
+// external tools move it away, such as on SIGHUP.
+func (h *RotatingFileHandler) Reopen() error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if err := h.file.Close(); err != nil {
+		return err
+	}
+	return h.open()
+}
+
+// Close closes the file, waiting for the rotated files to be processed.
+func (h *RotatingFileHandler) Close() error {
+	h.lock.Lock()
+	err := h.file.Close()
+	h.lock.Unlock()
+	h.wg.Wait()
+	return err
+}
+
+func (h *RotatingFileHandler) write(r *Record) error {
+	b := h.fmtr.Format(r)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.size > 0 && h.shouldRotate(int64(len(b))) {
+		if err := h.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := h.file.Write(b)
+	h.size += int64(n)
+	return err
+}
+
+// shouldRotate reports whether the file must be rotated before writing n
+// bytes to it.
+func (h *RotatingFileHandler) shouldRotate(n int64) bool {
+	if h.config.MaxSize > 0 && h.size+n > h.config.MaxSize {
+		return true
+	}
+	return h.config.MaxAge > 0 && time.Since(h.opened) >= h.config.MaxAge
+}
+
+// open opens the file at path for appending.
+func (h *RotatingFileHandler) open() error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	h.file, h.size, h.opened = f, info.Size(), time.Now()
+	return nil
+}
+
+// rotate renames the file and opens a new one. The lock must be held.
+func (h *RotatingFileHandler) rotate() error {
+	if err := h.file.Close(); err != nil {
+		return err
+	}
+	backup := h.path + "." + time.Now().UTC().Format(backupTimeFormat)
+	if err := os.Rename(h.path, backup); err != nil {
+		// Keep writing to the current file rather than losing records.
+		if openErr := h.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	if err := h.open(); err != nil {
+		return err
+	}
+	h.wg.Add(1)
+	go h.processBackup(backup)
+	return nil
+}
+
+// processBackup compresses a rotated file if needed, and removes the oldest
+// rotated files past the maximum number of backups.
+func (h *RotatingFileHandler) processBackup(backup string) {
+	defer h.wg.Done()
+	h.mill.Lock()
+	defer h.mill.Unlock()
+
+	if h.config.Compress {
+		if err := compressFile(backup); err != nil {
+			Error("Failed to compress rotated log file", "file", backup, "err", err)
+		}
+	}
+	if h.config.MaxBackups <= 0 {
+		return
+	}
+	backups, err := h.backups()
+	if err != nil {
+		Error("Failed to list rotated log files", "err", err)
+		return
+	}
+	for len(backups) > h.config.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			Error("Failed to remove rotated log file", "file", backups[0], "err", err)
+		}
+		backups = backups[1:]
+	}
+}
+
+// backups returns the rotated files, from the oldest to the newest.
+func (h *RotatingFileHandler) backups() ([]string, error) {
+	dir, prefix := filepath.Dir(h.path), filepath.Base(h.path)+"."
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz")
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// compressFile gzips the file at path and removes it.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,73 @@
+package log
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFileHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kai-log-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "node.log")
+	format := FormatFunc(func(r *Record) []byte { return []byte(r.Msg + "\n") })
+	h, err := NewRotatingFileHandler(path, format, RotateConfig{MaxSize: 20, MaxBackups: 2, Compress: true})
+	require.NoError(t, err)
+
+	for _, msg := range []string{"0123456789", "abcdefghi", "second", "third", "fourth!!!!!!!!"} {
+		require.NoError(t, h.Log(&Record{Msg: msg}))
+	}
+	require.NoError(t, h.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "fourth!!!!!!!!\n", string(data))
+
+	backups, err := h.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	var contents []string
+	for _, backup := range backups {
+		require.True(t, strings.HasSuffix(backup, ".gz"))
+		f, err := os.Open(backup)
+		require.NoError(t, err)
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(gz)
+		require.NoError(t, err)
+		f.Close()
+		contents = append(contents, string(data))
+	}
+	require.Equal(t, []string{"abcdefghi\nsecond\n", "third\n"}, contents)
+}
+
+func TestRotatingFileHandlerReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kai-log-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "node.log")
+	format := FormatFunc(func(r *Record) []byte { return []byte(r.Msg + "\n") })
+	h, err := NewRotatingFileHandler(path, format, RotateConfig{})
+	require.NoError(t, err)
+	defer h.Close()
+
+	require.NoError(t, h.Log(&Record{Msg: "before"}))
+	require.NoError(t, os.Rename(path, path+".old"))
+	require.NoError(t, h.Reopen())
+	require.NoError(t, h.Log(&Record{Msg: "after"}))
+
+	data, err := ioutil.ReadFile(path + ".old")
+	require.NoError(t, err)
+	require.Equal(t, "before\n", string(data))
+	data, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "after\n", string(data))
+}
@@ -12,6 +12,7 @@ const timeKey = "t"
 const lvlKey = "lvl"
 const msgKey = "msg"
 const ctxKey = "ctx"
+const tagKey = "tag"
 const errorKey = "LOG15_ERROR"
 const skipLevel = 2
 
@@ -0,0 +1,121 @@
+package prometheus
+
+import (
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kardiachain/go-kardia/lib/metrics"
+)
+
+func TestMain(m *testing.M) {
+	metrics.Enabled = true
+	os.Exit(m.Run())
+}
+
+func TestCollector(t *testing.T) {
+	c := newCollector()
+
+	counter := metrics.NewCounter()
+	counter.Inc(12345)
+	c.addCounter("test/counter", counter)
+
+	gauge := metrics.NewGauge()
+	gauge.Update(23456)
+	c.addGauge("test/gauge", gauge)
+
+	gaugeFloat64 := metrics.NewGaugeFloat64()
+	gaugeFloat64.Update(34567.89)
+	c.addGaugeFloat64("test/gauge_float64", gaugeFloat64)
+
+	histogram := metrics.NewHistogram(&metrics.NilSample{})
+	c.addHistogram("test/histogram", histogram)
+
+	meter := metrics.NewMeter()
+	defer meter.Stop()
+	meter.Mark(9999999)
+	c.addMeter("test/meter", meter)
+
+	timer := metrics.NewTimer()
+	defer timer.Stop()
+	timer.Update(20 * 1000)
+	timer.Update(21 * 1000)
+	timer.Update(22 * 1000)
+	timer.Update(120 * 1000)
+	timer.Update(23 * 1000)
+	timer.Update(24 * 1000)
+	c.addTimer("test/timer", timer)
+
+	resettingTimer := metrics.NewResettingTimer()
+	resettingTimer.Update(10)
+	resettingTimer.Update(1000)
+	resettingTimer.Update(10000)
+	resettingTimer.Update(100000)
+	c.addResettingTimer("test/resetting_timer", resettingTimer.Snapshot())
+
+	emptyResettingTimer := metrics.NewResettingTimer().Snapshot()
+	c.addResettingTimer("test/empty_resetting_timer", emptyResettingTimer)
+
+	const expectedOutput = `# TYPE test_counter gauge
+test_counter 12345
+
+# TYPE test_gauge gauge
+test_gauge 23456
+
+# TYPE test_gauge_float64 gauge
+test_gauge_float64 34567.89
+
+# TYPE test_histogram_count counter
+test_histogram_count 0
+
+# TYPE test_histogram summary
+test_histogram {quantile="0.5"} 0
+test_histogram {quantile="0.75"} 0
+test_histogram {quantile="0.95"} 0
+test_histogram {quantile="0.99"} 0
+test_histogram {quantile="0.999"} 0
+test_histogram {quantile="0.9999"} 0
+
+# TYPE test_meter gauge
+test_meter 9999999
+
+# TYPE test_timer_count counter
+test_timer_count 6
+
+# TYPE test_timer summary
+test_timer {quantile="0.5"} 22500
+test_timer {quantile="0.75"} 48000
+test_timer {quantile="0.95"} 120000
+test_timer {quantile="0.99"} 120000
+test_timer {quantile="0.999"} 120000
+test_timer {quantile="0.9999"} 120000
+
+# TYPE test_resetting_timer_count counter
+test_resetting_timer_count 4
+
+# TYPE test_resetting_timer summary
+test_resetting_timer {quantile="0.50"} 1000
+test_resetting_timer {quantile="0.95"} 100000
+test_resetting_timer {quantile="0.99"} 100000
+
+`
+	exp := c.buff.String()
+	if exp != expectedOutput {
+		t.Log("Expected Output:\n", expectedOutput)
+		t.Log("Actual Output:\n", exp)
+		t.Fatal("unexpected collector output")
+	}
+}
+
+func TestHandlerPrefixedRegistry(t *testing.T) {
+	registry := metrics.NewPrefixedRegistry("test/")
+	metrics.NewRegisteredGauge("pending", registry).Update(42)
+
+	rec := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(rec, httptest.NewRequest("GET", "/debug/metrics/prometheus", nil))
+
+	if body := rec.Body.String(); !strings.Contains(body, "test_pending 42\n") {
+		t.Fatalf("prefixed gauge missing from output:\n%s", body)
+	}
+}
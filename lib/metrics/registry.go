@@ -329,11 +329,13 @@ func (r *PrefixedRegistry) UnregisterAll() {
 }
 
 var (
-	DefaultRegistry = NewRegistry()
-	SystemRegistry  = NewPrefixedRegistry("system/")
-	DBRegistry      = NewPrefixedRegistry("db/")
-	TxPoolRegistry  = NewPrefixedRegistry("tx_pool/")
-	P2PRegistry     = NewPrefixedRegistry("p2p/")
+	DefaultRegistry   = NewRegistry()
+	SystemRegistry    = NewPrefixedRegistry("system/")
+	DBRegistry        = NewPrefixedRegistry("db/")
+	TxPoolRegistry    = NewPrefixedRegistry("tx_pool/")
+	P2PRegistry       = NewPrefixedRegistry("p2p/")
+	ConsensusRegistry = NewPrefixedRegistry("consensus/")
+	EvidenceRegistry  = NewPrefixedRegistry("evidence/")
 )
 
 // Call the given function for each registered metric.
@@ -360,6 +360,11 @@ func (c *MConnection) Send(chID byte, msgBytes []byte) bool {
 		c.Logger.Error(fmt.Sprintf("Cannot send bytes, unknown channel %X", chID))
 		return false
 	}
+	if !channel.canHold(msgBytes) {
+		c.Logger.Error("Cannot send bytes, message exceeds channel capacity", "channel", chID,
+			"size", len(msgBytes), "capacity", channel.desc.RecvMessageCapacity)
+		return false
+	}
 
 	success := channel.sendBytes(msgBytes)
 	if success {
@@ -389,6 +394,11 @@ func (c *MConnection) TrySend(chID byte, msgBytes []byte) bool {
 		c.Logger.Error(fmt.Sprintf("Cannot send bytes, unknown channel %X", chID))
 		return false
 	}
+	if !channel.canHold(msgBytes) {
+		c.Logger.Error("Cannot send bytes, message exceeds channel capacity", "channel", chID,
+			"size", len(msgBytes), "capacity", channel.desc.RecvMessageCapacity)
+		return false
+	}
 
 	ok = channel.trySendBytes(msgBytes)
 	if ok {
@@ -518,9 +528,11 @@ func (c *MConnection) sendSomePacketMsgs() bool {
 // Returns true if messages from channels were exhausted.
 func (c *MConnection) sendPacketMsg() bool {
 	// Choose a channel to create a PacketMsg from.
-	// The chosen channel will be the one whose recentlySent/priority is the least.
-	var leastRatio float32 = math.MaxFloat32
-	var leastChannel *Channel
+	// The chosen channel will be the one whose recentlySent/priority is the least,
+	// among the channels which have not used up their send window. Channels
+	// over their window are only picked when nothing else is pending.
+	var leastRatio, leastExceededRatio float32 = math.MaxFloat32, math.MaxFloat32
+	var leastChannel, leastExceededChannel *Channel
 	for _, channel := range c.channels {
 		// If nothing to send, skip this channel
 		if !channel.isSendPending() {
@@ -528,11 +540,21 @@ func (c *MConnection) sendPacketMsg() bool {
 		}
 		// Get ratio, and keep track of lowest ratio.
 		ratio := float32(channel.recentlySent) / float32(channel.desc.Priority)
+		if channel.windowExceeded() {
+			if ratio < leastExceededRatio {
+				leastExceededRatio = ratio
+				leastExceededChannel = channel
+			}
+			continue
+		}
 		if ratio < leastRatio {
 			leastRatio = ratio
 			leastChannel = channel
 		}
 	}
+	if leastChannel == nil {
+		leastChannel = leastExceededChannel
+	}
 
 	// Nothing to send?
 	if leastChannel == nil {
@@ -692,6 +714,7 @@ type ChannelStatus struct {
 	SendQueueSize     int
 	Priority          int
 	RecentlySent      int64
+	WindowSent        int64
 }
 
 func (c *MConnection) Status() ConnectionStatus {
@@ -707,6 +730,7 @@ func (c *MConnection) Status() ConnectionStatus {
 			SendQueueSize:     int(atomic.LoadInt32(&channel.sendQueueSize)),
 			Priority:          channel.desc.Priority,
 			RecentlySent:      atomic.LoadInt64(&channel.recentlySent),
+			WindowSent:        atomic.LoadInt64(&channel.windowSent),
 		}
 	}
 	return status
@@ -721,6 +745,12 @@ type ChannelDescriptor struct {
 	SendQueueCapacity   int
 	RecvMessageCapacity int
 
+	// SendWindow is the number of bytes the channel may write during a stats
+	// period (see updateStats) before yielding to other channels with pending
+	// packets. It keeps a saturated channel, e.g. block parts during catch-up,
+	// from delaying more urgent traffic such as votes. 0 means no window.
+	SendWindow int
+
 	// RecvBufferCapacity defines the max buffer size of inbound messages for a
 	// given p2p Channel queue.
 	RecvBufferCapacity int
@@ -736,6 +766,9 @@ func (chDesc ChannelDescriptor) FillDefaults() (filled ChannelDescriptor) {
 	if chDesc.RecvMessageCapacity == 0 {
 		chDesc.RecvMessageCapacity = defaultRecvMessageCapacity
 	}
+	if chDesc.SendWindow < 0 {
+		chDesc.SendWindow = 0
+	}
 	filled = chDesc
 	return
 }
@@ -750,6 +783,7 @@ type Channel struct {
 	recving       []byte
 	sending       []byte
 	recentlySent  int64 // exponential moving average
+	windowSent    int64 // bytes written in the current stats period
 
 	maxPacketMsgPayloadSize int
 
@@ -805,6 +839,19 @@ func (ch *Channel) loadSendQueueSize() (size int) {
 	return int(atomic.LoadInt32(&ch.sendQueueSize))
 }
 
+// canHold returns true if a message of this size can be received by the remote
+// end of the channel, assuming it uses the same channel descriptor.
+// Goroutine-safe
+func (ch *Channel) canHold(bytes []byte) bool {
+	return len(bytes) <= ch.desc.RecvMessageCapacity
+}
+
+// windowExceeded returns true if the channel used up its send window.
+// Goroutine-safe
+func (ch *Channel) windowExceeded() bool {
+	return ch.desc.SendWindow > 0 && atomic.LoadInt64(&ch.windowSent) >= int64(ch.desc.SendWindow)
+}
+
 // Goroutine-safe
 // Use only as a heuristic.
 func (ch *Channel) canSend() bool {
@@ -847,6 +894,7 @@ func (ch *Channel) writePacketMsgTo(w io.Writer) (n int, err error) {
 	packet := ch.nextPacketMsg()
 	n, err = protoio.NewDelimitedWriter(w).WriteMsg(mustWrapPacket(&packet))
 	atomic.AddInt64(&ch.recentlySent, int64(n))
+	atomic.AddInt64(&ch.windowSent, int64(n))
 	return
 }
 
@@ -855,6 +903,10 @@ func (ch *Channel) writePacketMsgTo(w io.Writer) (n int, err error) {
 // Not goroutine-safe
 func (ch *Channel) recvPacketMsg(packet kp2p.PacketMsg) ([]byte, error) {
 	ch.Logger.Debug("Read PacketMsg", "conn", ch.conn, "packet", packet)
+	if len(packet.Data) > ch.maxPacketMsgPayloadSize {
+		return nil, fmt.Errorf("received packet exceeds max payload size: %v < %v",
+			ch.maxPacketMsgPayloadSize, len(packet.Data))
+	}
 	var recvCap, recvReceived = ch.desc.RecvMessageCapacity, len(ch.recving) + len(packet.Data)
 	if recvCap < recvReceived {
 		return nil, fmt.Errorf("received message exceeds available capacity: %v < %v", recvCap, recvReceived)
@@ -874,6 +926,8 @@ func (ch *Channel) updateStats() {
 	// Exponential decay of stats.
 	// TODO: optimize.
 	atomic.StoreInt64(&ch.recentlySent, int64(float64(atomic.LoadInt64(&ch.recentlySent))*0.8))
+	// Start a new send window.
+	atomic.StoreInt64(&ch.windowSent, 0)
 }
 
 //----------------------------------------
@@ -1,7 +1,9 @@
 package conn
 
 import (
+	"bufio"
 	"encoding/hex"
+	"io/ioutil"
 	"net"
 	"testing"
 	"time"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/kardiachain/go-kardia/lib/log"
 	"github.com/kardiachain/go-kardia/lib/protoio"
+	"github.com/kardiachain/go-kardia/lib/timer"
 	kp2p "github.com/kardiachain/go-kardia/proto/kardiachain/p2p"
 	"github.com/kardiachain/go-kardia/proto/kardiachain/types"
 )
@@ -563,6 +566,64 @@ func TestMConnectionTrySend(t *testing.T) {
 	assert.Equal(t, "TrySend", <-resultCh)
 }
 
+func TestMConnectionSendRejectsOversizedMessage(t *testing.T) {
+	server, client := NetPipe()
+	defer server.Close()
+	defer client.Close()
+
+	chDescs := []*ChannelDescriptor{{ID: 0x01, Priority: 1, SendQueueCapacity: 1, RecvMessageCapacity: 16}}
+	mconn := NewMConnection(client, chDescs, func(byte, []byte) {}, func(interface{}) {})
+	mconn.SetLogger(log.TestingLogger())
+	require.NoError(t, mconn.Start())
+	defer mconn.Stop() // nolint:errcheck // ignore for tests
+
+	assert.False(t, mconn.Send(0x01, make([]byte, 17)))
+	assert.False(t, mconn.TrySend(0x01, make([]byte, 17)))
+	assert.True(t, mconn.TrySend(0x01, make([]byte, 16)))
+}
+
+func TestMConnectionSendWindow(t *testing.T) {
+	server, client := NetPipe()
+	defer server.Close()
+	defer client.Close()
+
+	chDescs := []*ChannelDescriptor{
+		{ID: 0x01, Priority: 100, SendQueueCapacity: 10, SendWindow: 1},
+		{ID: 0x02, Priority: 1, SendQueueCapacity: 10},
+	}
+	mconn := NewMConnection(client, chDescs, func(byte, []byte) {}, func(interface{}) {})
+	mconn.SetLogger(log.TestingLogger())
+	// drive sendPacketMsg by hand without starting the connection
+	mconn.bufConnWriter = bufio.NewWriter(ioutil.Discard)
+	mconn.flushTimer = timer.NewThrottleTimer("flush", time.Hour)
+	defer mconn.flushTimer.Stop()
+
+	saturated, other := mconn.channelsIdx[0x01], mconn.channelsIdx[0x02]
+	for i := 0; i < 3; i++ {
+		require.True(t, saturated.trySendBytes([]byte("block part")))
+	}
+
+	// the saturated channel keeps sending while it is the only one pending
+	assert.False(t, mconn.sendPacketMsg())
+	assert.True(t, saturated.windowExceeded())
+	assert.False(t, mconn.sendPacketMsg())
+	assert.Equal(t, 1, saturated.loadSendQueueSize())
+
+	// but yields to the other channel once its window is used up, despite
+	// its higher priority
+	require.True(t, other.trySendBytes([]byte("vote")))
+	assert.False(t, mconn.sendPacketMsg())
+	assert.Equal(t, 0, other.loadSendQueueSize())
+	assert.Equal(t, 1, saturated.loadSendQueueSize())
+
+	// a new stats period opens a new window
+	saturated.updateStats()
+	assert.False(t, saturated.windowExceeded())
+	assert.False(t, mconn.sendPacketMsg())
+	assert.Equal(t, 0, saturated.loadSendQueueSize())
+	assert.True(t, mconn.sendPacketMsg())
+}
+
 // nolint:lll //ignore line length for tests
 func TestConnVectors(t *testing.T) {
 
@@ -0,0 +1,161 @@
+package p2p
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// ConnGater is consulted by the transport at each stage of setting up a
+// connection. Returning an error from any of the hooks rejects the connection.
+type ConnGater interface {
+	// InterceptDial is called before dialing addr.
+	InterceptDial(addr NetAddress) error
+	// InterceptAccept is called for an inbound connection before the secret
+	// connection upgrade, with all the IPs resolved for it.
+	InterceptAccept(c net.Conn, ips []net.IP) error
+	// InterceptHandshake is called once the remote NodeInfo is known.
+	InterceptHandshake(c net.Conn, nodeInfo NodeInfo, outbound bool) error
+}
+
+// IPFilter is a ConnGater rejecting connections based on CIDR allow and deny
+// lists. A deny match always wins; when the allow list is non-empty only
+// matching IPs are let through. Both lists can be replaced at runtime.
+type IPFilter struct {
+	mtx   sync.RWMutex
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+var _ ConnGater = (*IPFilter)(nil)
+
+// NewIPFilter returns an IPFilter using the given CIDR lists. Plain IPs are
+// accepted as single-host ranges.
+func NewIPFilter(allow, deny []string) (*IPFilter, error) {
+	f := &IPFilter{}
+	if err := f.SetAllowed(allow); err != nil {
+		return nil, err
+	}
+	if err := f.SetDenied(deny); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// SetAllowed replaces the allow list.
+func (f *IPFilter) SetAllowed(cidrs []string) error {
+	nets, err := parseCIDRs(cidrs)
+	if err != nil {
+		return err
+	}
+	f.mtx.Lock()
+	f.allow = nets
+	f.mtx.Unlock()
+	return nil
+}
+
+// SetDenied replaces the deny list.
+func (f *IPFilter) SetDenied(cidrs []string) error {
+	nets, err := parseCIDRs(cidrs)
+	if err != nil {
+		return err
+	}
+	f.mtx.Lock()
+	f.deny = nets
+	f.mtx.Unlock()
+	return nil
+}
+
+// Allowed returns the allow list in CIDR notation.
+func (f *IPFilter) Allowed() []string {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	return cidrStrings(f.allow)
+}
+
+// Denied returns the deny list in CIDR notation.
+func (f *IPFilter) Denied() []string {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	return cidrStrings(f.deny)
+}
+
+// Allow reports whether connections from or to ip are permitted.
+func (f *IPFilter) Allow(ip net.IP) bool {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	for _, n := range f.deny {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, n := range f.allow {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// InterceptDial implements ConnGater.
+func (f *IPFilter) InterceptDial(addr NetAddress) error {
+	if !f.Allow(addr.IP) {
+		return fmt.Errorf("ip<%v> is not allowed by the ip filter", addr.IP)
+	}
+	return nil
+}
+
+// InterceptAccept implements ConnGater.
+func (f *IPFilter) InterceptAccept(_ net.Conn, ips []net.IP) error {
+	for _, ip := range ips {
+		if !f.Allow(ip) {
+			return fmt.Errorf("ip<%v> is not allowed by the ip filter", ip)
+		}
+	}
+	return nil
+}
+
+// InterceptHandshake implements ConnGater. IP filtering is already done by
+// the time the handshake completes.
+func (f *IPFilter) InterceptHandshake(net.Conn, NodeInfo, bool) error {
+	return nil
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, s := range cidrs {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip %q", s)
+			}
+			if ip.To4() != nil {
+				s += "/32"
+			} else {
+				s += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func cidrStrings(nets []*net.IPNet) []string {
+	res := make([]string, len(nets))
+	for i, n := range nets {
+		res[i] = n.String()
+	}
+	return res
+}
@@ -0,0 +1,82 @@
+package p2p
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/lib/crypto"
+)
+
+func TestIPFilterAllow(t *testing.T) {
+	testCases := []struct {
+		allow, deny []string
+		ip          string
+		expected    bool
+	}{
+		{nil, nil, "10.0.0.1", true},
+		{[]string{"10.0.0.0/8"}, nil, "10.0.0.1", true},
+		{[]string{"10.0.0.0/8"}, nil, "192.168.0.1", false},
+		{nil, []string{"10.0.0.0/8"}, "10.0.0.1", false},
+		{nil, []string{"10.0.0.0/8"}, "192.168.0.1", true},
+		{[]string{"10.0.0.0/8"}, []string{"10.1.0.0/16"}, "10.1.2.3", false},
+		{[]string{"10.0.0.0/8"}, []string{"10.1.0.0/16"}, "10.2.2.3", true},
+		{[]string{"192.168.0.1"}, nil, "192.168.0.1", true},
+		{[]string{"192.168.0.1"}, nil, "192.168.0.2", false},
+		{nil, []string{"::1"}, "::1", false},
+	}
+
+	for _, tc := range testCases {
+		f, err := NewIPFilter(tc.allow, tc.deny)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, f.Allow(net.ParseIP(tc.ip)), "allow=%v deny=%v ip=%s", tc.allow, tc.deny, tc.ip)
+	}
+}
+
+func TestIPFilterInvalidCIDR(t *testing.T) {
+	_, err := NewIPFilter([]string{"10.0.0.0/33"}, nil)
+	assert.Error(t, err)
+	_, err = NewIPFilter(nil, []string{"not-an-ip"})
+	assert.Error(t, err)
+
+	f, err := NewIPFilter(nil, []string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	assert.Error(t, f.SetDenied([]string{"bogus"}))
+	// a failed update keeps the previous list
+	assert.Equal(t, []string{"10.0.0.0/8"}, f.Denied())
+}
+
+func TestIPFilterRuntimeUpdate(t *testing.T) {
+	f, err := NewIPFilter(nil, nil)
+	require.NoError(t, err)
+	ip := net.ParseIP("172.16.0.5")
+	assert.True(t, f.Allow(ip))
+
+	require.NoError(t, f.SetDenied([]string{"172.16.0.0/12"}))
+	assert.False(t, f.Allow(ip))
+	assert.Error(t, f.InterceptAccept(nil, []net.IP{net.ParseIP("127.0.0.1"), ip}))
+
+	require.NoError(t, f.SetDenied(nil))
+	assert.True(t, f.Allow(ip))
+	assert.NoError(t, f.InterceptAccept(nil, []net.IP{ip}))
+}
+
+func TestTransportMultiplexConnGaterRejectsDial(t *testing.T) {
+	priv, _ := crypto.GenerateKey()
+	mt := newMultiplexTransport(emptyNodeInfo(), NodeKey{PrivKey: priv})
+
+	f, err := NewIPFilter(nil, []string{"127.0.0.0/8"})
+	require.NoError(t, err)
+	MultiplexTransportConnGater(f)(mt)
+
+	addr, err := NewNetAddressString(IDAddressString(mt.nodeKey.ID(), "127.0.0.1:26656"))
+	require.NoError(t, err)
+
+	_, err = mt.Dial(*addr, peerConfig{})
+	require.Error(t, err)
+	e, ok := err.(ErrRejected)
+	require.True(t, ok, "expected ErrRejected, got %T", err)
+	assert.True(t, e.IsFiltered())
+}
@@ -29,6 +29,14 @@ type Metrics struct {
 	PeerPendingSendBytes metrics.Gauge
 	// Number of transactions submitted by each peer.
 	NumTxs metrics.Gauge
+	// Number of messages received from a given peer, per channel.
+	PeerReceiveMessagesTotal metrics.Counter
+	// Number of messages sent to a given peer, per channel.
+	PeerSendMessagesTotal metrics.Counter
+	// Number of messages that could not be queued for a given peer, per channel.
+	PeerSendFailuresTotal metrics.Counter
+	// Number of messages waiting in the send queue of a given peer, per channel.
+	PeerChannelSendQueueSize metrics.Gauge
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
@@ -70,6 +78,30 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "num_txs",
 			Help:      "Number of transactions submitted by each peer.",
 		}, append(labels, "peer_id")).With(labelsAndValues...),
+		PeerReceiveMessagesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_receive_messages_total",
+			Help:      "Number of messages received from a given peer, per channel.",
+		}, append(labels, "peer_id", "chID")).With(labelsAndValues...),
+		PeerSendMessagesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_send_messages_total",
+			Help:      "Number of messages sent to a given peer, per channel.",
+		}, append(labels, "peer_id", "chID")).With(labelsAndValues...),
+		PeerSendFailuresTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_send_failures_total",
+			Help:      "Number of messages that could not be queued for a given peer, per channel.",
+		}, append(labels, "peer_id", "chID")).With(labelsAndValues...),
+		PeerChannelSendQueueSize: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_channel_send_queue_size",
+			Help:      "Number of messages waiting in the send queue of a given peer, per channel.",
+		}, append(labels, "peer_id", "chID")).With(labelsAndValues...),
 	}
 }
 
@@ -81,5 +113,10 @@ func InitMetrics() *Metrics {
 		PeerSendBytesTotal:    discard.NewCounter(),
 		PeerPendingSendBytes:  discard.NewGauge(),
 		NumTxs:                discard.NewGauge(),
+
+		PeerReceiveMessagesTotal: discard.NewCounter(),
+		PeerSendMessagesTotal:    discard.NewCounter(),
+		PeerSendFailuresTotal:    discard.NewCounter(),
+		PeerChannelSendQueueSize: discard.NewGauge(),
 	}
 }
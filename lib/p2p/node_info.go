@@ -44,10 +44,14 @@ type nodeInfoTransport interface {
 //-------------------------------------------------------------
 
 // ProtocolVersion contains the protocol versions for the software.
+// Consensus and Dualnode are zero when the node doesn't advertise them,
+// either because it predates them or doesn't run the reactor.
 type ProtocolVersion struct {
-	P2P   uint64 `json:"p2p"`
-	Block uint64 `json:"block"`
-	App   uint64 `json:"app"`
+	P2P       uint64 `json:"p2p"`
+	Block     uint64 `json:"block"`
+	App       uint64 `json:"app"`
+	Consensus uint64 `json:"consensus"`
+	Dualnode  uint64 `json:"dualnode"`
 }
 
 // defaultProtocolVersion populates the Block and P2P versions using
@@ -67,6 +71,69 @@ func NewProtocolVersion(p2p, block, app uint64) ProtocolVersion {
 	}
 }
 
+// VersionRange is an inclusive range of protocol versions. A zero Max means
+// there is no upper bound.
+type VersionRange struct {
+	Min uint64 `json:"min"`
+	Max uint64 `json:"max"`
+}
+
+// NewVersionRange returns the range [min, max].
+func NewVersionRange(min, max uint64) VersionRange {
+	return VersionRange{Min: min, Max: max}
+}
+
+// IsZero returns true if the range was left unset.
+func (r VersionRange) IsZero() bool {
+	return r.Min == 0 && r.Max == 0
+}
+
+// Contains returns true if v falls into the range.
+func (r VersionRange) Contains(v uint64) bool {
+	return v >= r.Min && (r.Max == 0 || v <= r.Max)
+}
+
+func (r VersionRange) String() string {
+	if r.Max == 0 {
+		return fmt.Sprintf("[%d, ∞)", r.Min)
+	}
+	return fmt.Sprintf("[%d, %d]", r.Min, r.Max)
+}
+
+// ProtocolCompatibility holds the versions of each protocol a node accepts
+// from its peers. An unset range only accepts the node's own version.
+type ProtocolCompatibility struct {
+	P2P       VersionRange `json:"p2p"`
+	Block     VersionRange `json:"block"`
+	Consensus VersionRange `json:"consensus"`
+	Dualnode  VersionRange `json:"dualnode"`
+}
+
+// ErrIncompatibleProtocol is returned when a peer speaks a version of a
+// protocol out of the range we support.
+type ErrIncompatibleProtocol struct {
+	Protocol  string
+	Version   uint64
+	Supported VersionRange
+}
+
+func (e ErrIncompatibleProtocol) Error() string {
+	return fmt.Sprintf("peer speaks %s protocol v%d, supported versions are %v",
+		e.Protocol, e.Version, e.Supported)
+}
+
+// checkProtocolVersion checks the version of a peer against the supported
+// range, defaulting to our own version when the range is unset.
+func checkProtocolVersion(protocol string, ours, theirs uint64, supported VersionRange) error {
+	if supported.IsZero() {
+		supported = NewVersionRange(ours, ours)
+	}
+	if !supported.Contains(theirs) {
+		return ErrIncompatibleProtocol{Protocol: protocol, Version: theirs, Supported: supported}
+	}
+	return nil
+}
+
 //-------------------------------------------------------------
 
 // Assert DefaultNodeInfo satisfies NodeInfo
@@ -91,6 +158,10 @@ type DefaultNodeInfo struct {
 	// ASCIIText fields
 	Moniker string               `json:"moniker"` // arbitrary moniker
 	Other   DefaultNodeInfoOther `json:"other"`   // other application specific data
+
+	// Versions of the peers' protocols we accept. Local only, never sent
+	// over the wire.
+	Compatibility ProtocolCompatibility `json:"-"`
 }
 
 // DefaultNodeInfoOther is the misc. applcation specific data
@@ -172,17 +243,18 @@ func (info DefaultNodeInfo) Validate() error {
 }
 
 // CompatibleWith checks if two DefaultNodeInfo are compatible with eachother.
-// CONTRACT: two nodes are compatible if the Block version and network match
-// and they have at least one channel in common.
+// CONTRACT: two nodes are compatible if their P2P and Block versions are in
+// our supported ranges, the network matches and they have at least one
+// channel in common. Consensus and Dualnode versions are only checked when
+// both sides advertise them, so older peers can still connect.
 func (info DefaultNodeInfo) CompatibleWith(otherInfo NodeInfo) error {
 	other, ok := otherInfo.(DefaultNodeInfo)
 	if !ok {
 		return fmt.Errorf("wrong NodeInfo type. Expected DefaultNodeInfo, got %v", reflect.TypeOf(otherInfo))
 	}
 
-	if info.ProtocolVersion.Block != other.ProtocolVersion.Block {
-		return fmt.Errorf("peer is on a different Block version. Got %v, expected %v",
-			other.ProtocolVersion.Block, info.ProtocolVersion.Block)
+	if err := info.compatibleProtocolWith(other.ProtocolVersion); err != nil {
+		return err
 	}
 
 	// nodes must be on the same network
@@ -212,6 +284,27 @@ OUTER_LOOP:
 	return nil
 }
 
+func (info DefaultNodeInfo) compatibleProtocolWith(other ProtocolVersion) error {
+	ours, compat := info.ProtocolVersion, info.Compatibility
+	if err := checkProtocolVersion("p2p", ours.P2P, other.P2P, compat.P2P); err != nil {
+		return err
+	}
+	if err := checkProtocolVersion("block", ours.Block, other.Block, compat.Block); err != nil {
+		return err
+	}
+	if ours.Consensus != 0 && other.Consensus != 0 {
+		if err := checkProtocolVersion("consensus", ours.Consensus, other.Consensus, compat.Consensus); err != nil {
+			return err
+		}
+	}
+	if ours.Dualnode != 0 && other.Dualnode != 0 {
+		if err := checkProtocolVersion("dualnode", ours.Dualnode, other.Dualnode, compat.Dualnode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // NetAddress returns a NetAddress derived from the DefaultNodeInfo -
 // it includes the authenticated peer ID and the self-reported
 // ListenAddr. Note that the ListenAddr is not authenticated and
@@ -225,9 +318,11 @@ func (info DefaultNodeInfo) ToProto() *kp2p.DefaultNodeInfo {
 
 	dni := new(kp2p.DefaultNodeInfo)
 	dni.ProtocolVersion = kp2p.ProtocolVersion{
-		P2P:   info.ProtocolVersion.P2P,
-		Block: info.ProtocolVersion.Block,
-		App:   info.ProtocolVersion.App,
+		P2P:       info.ProtocolVersion.P2P,
+		Block:     info.ProtocolVersion.Block,
+		App:       info.ProtocolVersion.App,
+		Consensus: info.ProtocolVersion.Consensus,
+		Dualnode:  info.ProtocolVersion.Dualnode,
 	}
 
 	dni.DefaultNodeID = string(info.DefaultNodeID)
@@ -250,9 +345,11 @@ func DefaultNodeInfoFromToProto(pb *kp2p.DefaultNodeInfo) (DefaultNodeInfo, erro
 	}
 	dni := DefaultNodeInfo{
 		ProtocolVersion: ProtocolVersion{
-			P2P:   pb.ProtocolVersion.P2P,
-			Block: pb.ProtocolVersion.Block,
-			App:   pb.ProtocolVersion.App,
+			P2P:       pb.ProtocolVersion.P2P,
+			Block:     pb.ProtocolVersion.Block,
+			App:       pb.ProtocolVersion.App,
+			Consensus: pb.ProtocolVersion.Consensus,
+			Dualnode:  pb.ProtocolVersion.Dualnode,
 		},
 		DefaultNodeID: ID(pb.DefaultNodeID),
 		ListenAddr:    pb.ListenAddr,
@@ -126,3 +126,47 @@ func TestNodeInfoCompatible(t *testing.T) {
 		assert.Error(t, ni1.CompatibleWith(ni))
 	}
 }
+
+func TestNodeInfoCompatibleProtocolRanges(t *testing.T) {
+	priv1, _ := crypto.GenerateKey()
+	priv2, _ := crypto.GenerateKey()
+	name := "testing"
+
+	nodeKey1 := NodeKey{PrivKey: priv1}
+	nodeKey2 := NodeKey{PrivKey: priv2}
+	ni1 := testNodeInfo(nodeKey1.ID(), name).(DefaultNodeInfo)
+	ni1.ProtocolVersion.Consensus = 2
+	ni1.ProtocolVersion.Dualnode = 1
+	ni1.Compatibility = ProtocolCompatibility{
+		Block:     NewVersionRange(1, 2),
+		Consensus: NewVersionRange(1, 2),
+	}
+
+	testCases := []struct {
+		testName         string
+		malleateNodeInfo func(*DefaultNodeInfo)
+		expectErr        bool
+	}{
+		{"Same versions", func(ni *DefaultNodeInfo) { ni.ProtocolVersion = ni1.ProtocolVersion }, false},
+		{"Legacy peer without consensus version", func(ni *DefaultNodeInfo) {}, false},
+		{"Block version in range", func(ni *DefaultNodeInfo) { ni.ProtocolVersion.Block = 2 }, false},
+		{"Block version out of range", func(ni *DefaultNodeInfo) { ni.ProtocolVersion.Block = 3 }, true},
+		{"Different p2p version", func(ni *DefaultNodeInfo) { ni.ProtocolVersion.P2P++ }, true},
+		{"Consensus version in range", func(ni *DefaultNodeInfo) { ni.ProtocolVersion.Consensus = 1 }, false},
+		{"Consensus version out of range", func(ni *DefaultNodeInfo) { ni.ProtocolVersion.Consensus = 3 }, true},
+		{"Different dualnode version", func(ni *DefaultNodeInfo) { ni.ProtocolVersion.Dualnode = 2 }, true},
+	}
+
+	for _, tc := range testCases {
+		ni := testNodeInfo(nodeKey2.ID(), name).(DefaultNodeInfo)
+		tc.malleateNodeInfo(&ni)
+		err := ni1.CompatibleWith(ni)
+		if tc.expectErr {
+			assert.Error(t, err, tc.testName)
+			_, ok := err.(ErrIncompatibleProtocol)
+			assert.True(t, ok, tc.testName)
+		} else {
+			assert.NoError(t, err, tc.testName)
+		}
+	}
+}
@@ -250,13 +250,7 @@ func (p *peer) Send(chID byte, msgBytes []byte) bool {
 		return false
 	}
 	res := p.mconn.Send(chID, msgBytes)
-	if res {
-		labels := []string{
-			"peer_id", string(p.ID()),
-			"chID", fmt.Sprintf("%#x", chID),
-		}
-		p.metrics.PeerSendBytesTotal.With(labels...).Add(float64(len(msgBytes)))
-	}
+	p.recordSend(chID, msgBytes, res)
 	return res
 }
 
@@ -269,13 +263,7 @@ func (p *peer) TrySend(chID byte, msgBytes []byte) bool {
 		return false
 	}
 	res := p.mconn.TrySend(chID, msgBytes)
-	if res {
-		labels := []string{
-			"peer_id", string(p.ID()),
-			"chID", fmt.Sprintf("%#x", chID),
-		}
-		p.metrics.PeerSendBytesTotal.With(labels...).Add(float64(len(msgBytes)))
-	}
+	p.recordSend(chID, msgBytes, res)
 	return res
 }
 
@@ -344,6 +332,21 @@ func PeerMetrics(metrics *Metrics) PeerOption {
 	}
 }
 
+// recordSend updates the per-channel send metrics after a message was handed
+// to the MConnection. ok is false if the message could not be queued.
+func (p *peer) recordSend(chID byte, msgBytes []byte, ok bool) {
+	labels := []string{
+		"peer_id", string(p.ID()),
+		"chID", fmt.Sprintf("%#x", chID),
+	}
+	if !ok {
+		p.metrics.PeerSendFailuresTotal.With(labels...).Add(1)
+		return
+	}
+	p.metrics.PeerSendMessagesTotal.With(labels...).Add(1)
+	p.metrics.PeerSendBytesTotal.With(labels...).Add(float64(len(msgBytes)))
+}
+
 func (p *peer) metricsReporter() {
 	for {
 		select {
@@ -352,6 +355,10 @@ func (p *peer) metricsReporter() {
 			var sendQueueSize float64
 			for _, chStatus := range status.Channels {
 				sendQueueSize += float64(chStatus.SendQueueSize)
+				p.metrics.PeerChannelSendQueueSize.With(
+					"peer_id", string(p.ID()),
+					"chID", fmt.Sprintf("%#x", chStatus.ID),
+				).Set(float64(chStatus.SendQueueSize))
 			}
 
 			p.metrics.PeerPendingSendBytes.With("peer_id", string(p.ID())).Set(sendQueueSize)
@@ -385,6 +392,7 @@ func createMConnection(
 			"chID", fmt.Sprintf("%#x", chID),
 		}
 		p.metrics.PeerReceiveBytesTotal.With(labels...).Add(float64(len(msgBytes)))
+		p.metrics.PeerReceiveMessagesTotal.With(labels...).Add(1)
 		reactor.Receive(chID, p, msgBytes)
 	}
 
@@ -61,6 +61,7 @@ type AddrBook interface {
 	// Mark address
 	MarkGood(p2p.ID)
 	MarkAttempt(*p2p.NetAddress)
+	MarkFailed(*p2p.NetAddress)             // Record a failed dial
 	MarkBad(*p2p.NetAddress, time.Duration) // Move peer to bad peers list
 	// Add bad peers back to addrBook
 	ReinstateBadPeers()
@@ -345,6 +346,19 @@ func (a *addrBook) MarkAttempt(addr *p2p.NetAddress) {
 	ka.markAttempt()
 }
 
+// MarkFailed implements AddrBook - it marks that an attempt to connect to the
+// address failed.
+func (a *addrBook) MarkFailed(addr *p2p.NetAddress) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	ka := a.addrLookup[addr.ID]
+	if ka == nil {
+		return
+	}
+	ka.markFailed()
+}
+
 // MarkBad implements AddrBook. Kicks address out from book, places
 // the address in the badPeers pool.
 func (a *addrBook) MarkBad(addr *p2p.NetAddress, banTime time.Duration) {
@@ -497,6 +511,7 @@ out:
 	for {
 		select {
 		case <-saveFileTicker.C:
+			a.pruneStale()
 			a.saveToFile(a.filePath)
 		case <-a.Quit():
 			break out
@@ -506,6 +521,40 @@ out:
 	a.saveToFile(a.filePath)
 }
 
+// pruneStale removes bad addresses from the new buckets and demotes old
+// addresses we have not connected to for a long time, so the book doesn't
+// keep vouching for peers that have left the network.
+func (a *addrBook) pruneStale() {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	var demoted, removed int
+	for _, ka := range a.addrLookup {
+		if !ka.isStale() {
+			continue
+		}
+		a.removeFromAllBuckets(ka)
+		ka.BucketType = bucketTypeNew
+		bucket, err := a.calcNewBucket(ka.Addr, ka.Src)
+		if err != nil {
+			continue
+		}
+		if err := a.addToNewBucket(ka, bucket); err != nil {
+			continue
+		}
+		demoted++
+	}
+	for _, ka := range a.addrLookup {
+		if ka.isNew() && ka.isBad() {
+			a.removeFromAllBuckets(ka)
+			removed++
+		}
+	}
+	if demoted > 0 || removed > 0 {
+		a.Logger.Info("Pruned stale addresses", "demoted", demoted, "removed", removed)
+	}
+}
+
 //----------------------------------------------------------
 
 func (a *addrBook) getBucket(bucketType byte, bucketIdx int) map[string]*knownAddress {
@@ -668,6 +717,7 @@ func (a *addrBook) addAddress(addr, src *p2p.NetAddress) error {
 
 	ka := a.addrLookup[addr.ID]
 	if ka != nil {
+		ka.markSeen()
 		// If its already old and the address ID's are the same, ignore it.
 		// Thereby avoiding issues with a node on the network attempting to change
 		// the IP of a known node ID. (Which could yield an eclipse attack on the node)
@@ -814,6 +864,7 @@ func (a *addrBook) addBadPeer(addr *p2p.NetAddress, banTime time.Duration) bool
 
 	if _, alreadyBadPeer := a.badPeers[addr.ID]; !alreadyBadPeer {
 		// add to bad peer list
+		ka.Failures++
 		ka.ban(banTime)
 		a.badPeers[addr.ID] = ka
 		a.Logger.Info("Add address to blacklist", "addr", addr)
@@ -440,6 +440,73 @@ func TestBanBadPeers(t *testing.T) {
 	assert.False(t, book.IsGood(addr))
 }
 
+func TestAddrBookSaveLoadBadPeersAndHistory(t *testing.T) {
+	fname := createTempFileName("addrbook_test")
+	defer deleteTempFile(fname)
+
+	book := NewAddrBook(fname, true)
+	book.SetLogger(log.TestingLogger())
+
+	randAddrs := randNetAddressPairs(t, 2)
+	good, bad := randAddrs[0], randAddrs[1]
+	require.NoError(t, book.AddAddress(good.addr, good.src))
+	require.NoError(t, book.AddAddress(bad.addr, bad.src))
+	book.MarkFailed(good.addr)
+	book.MarkAttempt(good.addr)
+	book.MarkGood(good.addr.ID)
+	book.MarkBad(bad.addr, time.Hour)
+	book.Save()
+
+	book = NewAddrBook(fname, true)
+	book.SetLogger(log.TestingLogger())
+	require.NoError(t, book.Start())
+	defer book.Stop() // nolint:errcheck
+
+	assert.True(t, book.IsBanned(bad.addr))
+	assert.False(t, book.HasAddress(bad.addr))
+	assert.Error(t, book.AddAddress(bad.addr, bad.src))
+
+	ka := book.(*addrBook).addrLookup[good.addr.ID]
+	require.NotNil(t, ka)
+	assert.EqualValues(t, 1, ka.Successes)
+	// only the failed dial counts as a failure
+	assert.EqualValues(t, 1, ka.Failures)
+	assert.True(t, ka.isOld())
+	assert.EqualValues(t, 1, book.(*addrBook).badPeers[bad.addr.ID].Failures)
+}
+
+func TestAddrBookPruneStale(t *testing.T) {
+	fname := createTempFileName("addrbook_test")
+	defer deleteTempFile(fname)
+
+	book := NewAddrBook(fname, true).(*addrBook)
+	book.SetLogger(log.TestingLogger())
+
+	randAddrs := randNetAddressPairs(t, 3)
+	for _, addrSrc := range randAddrs {
+		require.NoError(t, book.AddAddress(addrSrc.addr, addrSrc.src))
+	}
+	fresh, vanished, stale := randAddrs[0], randAddrs[1], randAddrs[2]
+
+	// never seen again in a long time, and never connected to
+	ka := book.addrLookup[vanished.addr.ID]
+	ka.LastSeen = time.Now().Add(-(numMissingDays + 1) * 24 * time.Hour)
+	ka.LastAttempt = ka.LastSeen
+
+	// proven once, but not connected to for a long time
+	book.MarkGood(stale.addr.ID)
+	ka = book.addrLookup[stale.addr.ID]
+	ka.LastSuccess = time.Now().Add(-(maxOldAddressAgeDays + 1) * 24 * time.Hour)
+
+	book.pruneStale()
+
+	assert.True(t, book.HasAddress(fresh.addr))
+	assert.False(t, book.HasAddress(vanished.addr))
+	assert.True(t, book.HasAddress(stale.addr))
+	assert.False(t, book.IsGood(stale.addr), "stale address should be demoted to a new bucket")
+	assert.Equal(t, 2, book.Size())
+}
+
 func TestAddrBookEmpty(t *testing.T) {
 	fname := createTempFileName("addrbook_test")
 	defer deleteTempFile(fname)
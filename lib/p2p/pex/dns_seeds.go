@@ -0,0 +1,184 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pex
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/p2p"
+)
+
+const (
+	// dnsSeedRecordPrefix marks a TXT record as a signed list of seed addresses.
+	// A record looks like:
+	//
+	//	kardia-seeds=<hex signature>;<id@host:port>,<id@host:port>,...
+	//
+	// The signature is made over keccak256(domain || ";" || addresses) so a
+	// record cannot be replayed under another domain.
+	dnsSeedRecordPrefix = "kardia-seeds="
+
+	// defaultDNSSeedsRefreshPeriod is how often DNS seeds are resolved again.
+	defaultDNSSeedsRefreshPeriod = 30 * time.Minute
+)
+
+var (
+	errDNSSeedNoRecords = errors.New("no signed seed records found")
+)
+
+// ErrDNSSeedBadSignature is returned when a seed record is not signed by the
+// configured signer.
+type ErrDNSSeedBadSignature struct {
+	Domain string
+	Signer common.Address
+}
+
+func (err ErrDNSSeedBadSignature) Error() string {
+	return fmt.Sprintf("seed record of %s is not signed by %s", err.Domain, err.Signer.Hex())
+}
+
+// DNSSeed is a domain publishing a signed list of seed node addresses in its
+// TXT records.
+type DNSSeed struct {
+	Domain string
+	Signer common.Address
+}
+
+// ParseDNSSeed parses a DNS seed in the "<signer address>@<domain>" form.
+func ParseDNSSeed(s string) (DNSSeed, error) {
+	parts := strings.SplitN(s, "@", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return DNSSeed{}, fmt.Errorf("invalid dns seed %q, expected <signer>@<domain>", s)
+	}
+	if !common.IsHexAddress(parts[0]) {
+		return DNSSeed{}, fmt.Errorf("invalid dns seed signer %q", parts[0])
+	}
+	return DNSSeed{
+		Domain: strings.TrimSuffix(parts[1], "."),
+		Signer: common.HexToAddress(parts[0]),
+	}, nil
+}
+
+// String returns the "<signer address>@<domain>" form of the seed.
+func (s DNSSeed) String() string {
+	return s.Signer.Hex() + "@" + s.Domain
+}
+
+// dnsSeedHash returns the hash signed by the record publisher.
+func dnsSeedHash(domain string, addrs string) []byte {
+	return crypto.Keccak256([]byte(domain + ";" + addrs))
+}
+
+// SignDNSSeedRecord builds a TXT record value for domain listing addrs and
+// signed with priv. It is meant to be used by seed operators.
+func SignDNSSeedRecord(domain string, addrs []string, priv *ecdsa.PrivateKey) (string, error) {
+	joined := strings.Join(addrs, ",")
+	sig, err := crypto.Sign(dnsSeedHash(strings.TrimSuffix(domain, "."), joined), priv)
+	if err != nil {
+		return "", err
+	}
+	return dnsSeedRecordPrefix + hex.EncodeToString(sig) + ";" + joined, nil
+}
+
+// dnsSeedResolver resolves DNS seeds into verified network addresses.
+type dnsSeedResolver struct {
+	seeds     []DNSSeed
+	lookupTXT func(domain string) ([]string, error)
+}
+
+func newDNSSeedResolver(seeds []DNSSeed) *dnsSeedResolver {
+	return &dnsSeedResolver{
+		seeds:     seeds,
+		lookupTXT: net.LookupTXT,
+	}
+}
+
+// Resolve returns the addresses of all seeds that could be resolved and
+// verified, along with the errors of those that could not.
+func (r *dnsSeedResolver) Resolve() ([]*p2p.NetAddress, []error) {
+	var (
+		netAddrs []*p2p.NetAddress
+		errs     []error
+	)
+	for _, seed := range r.seeds {
+		addrs, err := r.resolve(seed)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("dns seed %s: %w", seed.Domain, err))
+			continue
+		}
+		netAddrs = append(netAddrs, addrs...)
+	}
+	return netAddrs, errs
+}
+
+func (r *dnsSeedResolver) resolve(seed DNSSeed) ([]*p2p.NetAddress, error) {
+	records, err := r.lookupTXT(seed.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var addrStrings []string
+	for _, record := range records {
+		if !strings.HasPrefix(record, dnsSeedRecordPrefix) {
+			continue
+		}
+		addrs, err := verifyDNSSeedRecord(seed, strings.TrimPrefix(record, dnsSeedRecordPrefix))
+		if err != nil {
+			return nil, err
+		}
+		addrStrings = append(addrStrings, addrs...)
+	}
+	if len(addrStrings) == 0 {
+		return nil, errDNSSeedNoRecords
+	}
+
+	netAddrs, errs := p2p.NewNetAddressStrings(addrStrings)
+	if len(netAddrs) == 0 && len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return netAddrs, nil
+}
+
+// verifyDNSSeedRecord checks the signature of a record (without its prefix)
+// and returns the addresses it lists.
+func verifyDNSSeedRecord(seed DNSSeed, record string) ([]string, error) {
+	parts := strings.SplitN(record, ";", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed seed record %q", record)
+	}
+	sig, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("malformed seed record signature: %w", err)
+	}
+	if !crypto.VerifySignature(seed.Signer, dnsSeedHash(seed.Domain, parts[1]), sig) {
+		return nil, ErrDNSSeedBadSignature{Domain: seed.Domain, Signer: seed.Signer}
+	}
+	if parts[1] == "" {
+		return nil, nil
+	}
+	return strings.Split(parts[1], ","), nil
+}
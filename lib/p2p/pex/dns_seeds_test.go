@@ -0,0 +1,95 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pex
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/lib/crypto"
+)
+
+const (
+	testSeedAddr1 = "0c2a5bd3a3b2a8d9f4b0c1e2d3f4a5b6c7d8e9f0@127.0.0.1:26656"
+	testSeedAddr2 = "1c2a5bd3a3b2a8d9f4b0c1e2d3f4a5b6c7d8e9f0@127.0.0.2:26656"
+)
+
+func TestParseDNSSeed(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	seed, err := ParseDNSSeed(signer.Hex() + "@seeds.kardiachain.io.")
+	require.NoError(t, err)
+	assert.Equal(t, "seeds.kardiachain.io", seed.Domain)
+	assert.Equal(t, signer, seed.Signer)
+
+	for _, s := range []string{"", "seeds.kardiachain.io", "@seeds.kardiachain.io", "0xzz@seeds.kardiachain.io", signer.Hex() + "@"} {
+		_, err := ParseDNSSeed(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestDNSSeedResolver(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	good := DNSSeed{Domain: "seeds.kardiachain.io", Signer: crypto.PubkeyToAddress(key.PublicKey)}
+	goodRecord, err := SignDNSSeedRecord(good.Domain, []string{testSeedAddr1, testSeedAddr2}, key)
+	require.NoError(t, err)
+	forged, err := SignDNSSeedRecord(good.Domain, []string{testSeedAddr1}, other)
+	require.NoError(t, err)
+	replayed, err := SignDNSSeedRecord("other.kardiachain.io", []string{testSeedAddr1}, key)
+	require.NoError(t, err)
+
+	records := map[string][]string{
+		good.Domain:               {"v=spf1 -all", goodRecord},
+		"forged.kardiachain.io":   {forged},
+		"replayed.kardiachain.io": {replayed},
+		"empty.kardiachain.io":    {"v=spf1 -all"},
+	}
+	lookup := func(domain string) ([]string, error) {
+		rs, ok := records[domain]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		return rs, nil
+	}
+
+	r := newDNSSeedResolver([]DNSSeed{good})
+	r.lookupTXT = lookup
+	addrs, errs := r.Resolve()
+	assert.Empty(t, errs)
+	require.Len(t, addrs, 2)
+	assert.Equal(t, testSeedAddr1, addrs[0].String())
+	assert.Equal(t, testSeedAddr2, addrs[1].String())
+
+	for _, domain := range []string{"forged.kardiachain.io", "replayed.kardiachain.io", "empty.kardiachain.io", "missing.kardiachain.io"} {
+		r := newDNSSeedResolver([]DNSSeed{{Domain: domain, Signer: good.Signer}})
+		r.lookupTXT = lookup
+		addrs, errs := r.Resolve()
+		assert.Empty(t, addrs, domain)
+		assert.Len(t, errs, 1, domain)
+	}
+}
@@ -11,8 +11,9 @@ import (
 /* Loading & Saving */
 
 type addrBookJSON struct {
-	Key   string          `json:"key"`
-	Addrs []*knownAddress `json:"addrs"`
+	Key      string          `json:"key"`
+	Addrs    []*knownAddress `json:"addrs"`
+	BadPeers []*knownAddress `json:"bad_peers,omitempty"`
 }
 
 func (a *addrBook) saveToFile(filePath string) {
@@ -25,9 +26,14 @@ func (a *addrBook) saveToFile(filePath string) {
 	for _, ka := range a.addrLookup {
 		addrs = append(addrs, ka)
 	}
+	badPeers := make([]*knownAddress, 0, len(a.badPeers))
+	for _, ka := range a.badPeers {
+		badPeers = append(badPeers, ka)
+	}
 	aJSON := &addrBookJSON{
-		Key:   a.key,
-		Addrs: addrs,
+		Key:      a.key,
+		Addrs:    addrs,
+		BadPeers: badPeers,
 	}
 
 	jsonBytes, err := json.MarshalIndent(aJSON, "", "\t")
@@ -79,5 +85,12 @@ func (a *addrBook) loadFromFile(filePath string) bool {
 			a.nOld++
 		}
 	}
+	// Restore the bans, ReinstateBadPeers puts expired ones back into the book
+	for _, ka := range aJSON.BadPeers {
+		if _, ok := a.addrLookup[ka.ID()]; ok {
+			continue
+		}
+		a.badPeers[ka.ID()] = ka
+	}
 	return true
 }
@@ -17,6 +17,9 @@ type knownAddress struct {
 	LastAttempt time.Time       `json:"last_attempt"`
 	LastSuccess time.Time       `json:"last_success"`
 	LastBanTime time.Time       `json:"last_ban_time"`
+	LastSeen    time.Time       `json:"last_seen"`
+	Successes   uint32          `json:"successes"`
+	Failures    uint32          `json:"failures"`
 }
 
 func newKnownAddress(addr *p2p.NetAddress, src *p2p.NetAddress) *knownAddress {
@@ -25,6 +28,7 @@ func newKnownAddress(addr *p2p.NetAddress, src *p2p.NetAddress) *knownAddress {
 		Src:         src,
 		Attempts:    0,
 		LastAttempt: time.Now(),
+		LastSeen:    time.Now(),
 		BucketType:  bucketTypeNew,
 		Buckets:     nil,
 	}
@@ -48,11 +52,33 @@ func (ka *knownAddress) markAttempt() {
 	ka.Attempts++
 }
 
+// markFailed records a failed dial of the address.
+func (ka *knownAddress) markFailed() {
+	ka.markAttempt()
+	ka.Failures++
+}
+
 func (ka *knownAddress) markGood() {
 	now := time.Now()
 	ka.LastAttempt = now
 	ka.Attempts = 0
 	ka.LastSuccess = now
+	ka.LastSeen = now
+	ka.Successes++
+}
+
+// markSeen records that the address was announced to us again.
+func (ka *knownAddress) markSeen() {
+	ka.LastSeen = time.Now()
+}
+
+// lastSeen returns the last time the address was announced or connected to.
+// Books written before LastSeen was tracked fall back to LastAttempt.
+func (ka *knownAddress) lastSeen() time.Time {
+	if ka.LastSeen.IsZero() {
+		return ka.LastAttempt
+	}
+	return ka.LastSeen
 }
 
 func (ka *knownAddress) ban(banTime time.Duration) {
@@ -120,8 +146,7 @@ func (ka *knownAddress) isBad() bool {
 	// TODO: From the future?
 
 	// Too old?
-	// TODO: should be a timestamp of last seen, not just last attempt
-	if ka.LastAttempt.Before(time.Now().Add(-1 * numMissingDays * time.Hour * 24)) {
+	if ka.lastSeen().Before(time.Now().Add(-1 * numMissingDays * time.Hour * 24)) {
 		return true
 	}
 
@@ -138,3 +163,16 @@ func (ka *knownAddress) isBad() bool {
 
 	return false
 }
+
+// isStale returns true if an old address has not been connected to for
+// maxOldAddressAgeDays and should be demoted back to a new bucket.
+func (ka *knownAddress) isStale() bool {
+	if ka.BucketType != bucketTypeOld {
+		return false
+	}
+	last := ka.LastSuccess
+	if last.IsZero() {
+		last = ka.lastSeen()
+	}
+	return last.Before(time.Now().Add(-1 * maxOldAddressAgeDays * time.Hour * 24))
+}
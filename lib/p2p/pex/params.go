@@ -43,6 +43,10 @@ const (
 	// days since the last success before we will consider evicting an address.
 	minBadDays = 7
 
+	// days since the last success before an old address is demoted to a new
+	// bucket, where it can be pruned like any other unproven address.
+	maxOldAddressAgeDays = 30
+
 	// % of total addresses known returned by GetSelection.
 	getSelectionPercent = 23
 
@@ -94,6 +94,11 @@ type Reactor struct {
 
 	seedAddrs []*p2p.NetAddress
 
+	// addresses learned from DNS seeds, refreshed periodically
+	dnsSeeds     *dnsSeedResolver
+	dnsSeedsMtx  sync.RWMutex
+	dnsSeedAddrs []*p2p.NetAddress
+
 	attemptsToDial sync.Map // address (string) -> {number of attempts (int), last time dialed (time.Time)}
 
 	// seed/crawled mode fields
@@ -122,6 +127,14 @@ type ReactorConfig struct {
 	// Seeds is a list of addresses reactor may use
 	// if it can't connect to peers in the addrbook.
 	Seeds []string
+
+	// DNSSeeds is a list of "<signer>@<domain>" entries whose TXT records hold
+	// signed seed lists. They are used alongside Seeds.
+	DNSSeeds []string
+
+	// DNSSeedsRefreshPeriod is how often DNS seeds are resolved again
+	// (defaults to 30 minutes).
+	DNSSeedsRefreshPeriod time.Duration
 }
 
 type _attemptsToDial struct {
@@ -153,11 +166,19 @@ func (r *Reactor) OnStart() error {
 	numOnline, seedAddrs, err := r.checkSeeds()
 	if err != nil {
 		return err
-	} else if numOnline == 0 && r.book.Empty() {
+	}
+	numDNSOnline, err := r.checkDNSSeeds()
+	if err != nil {
+		return err
+	}
+	if numOnline == 0 && numDNSOnline <= 0 && r.book.Empty() {
 		return errors.New("address book is empty and couldn't resolve any seed nodes")
 	}
 
 	r.seedAddrs = seedAddrs
+	if r.dnsSeeds != nil {
+		go r.dnsSeedsRoutine()
+	}
 
 	// Check if this node should run
 	// in seed/crawler mode
@@ -369,7 +390,7 @@ func (r *Reactor) ReceiveAddrs(addrs []*p2p.NetAddress, src Peer) error {
 	}
 
 	srcIsSeed := false
-	for _, seedAddr := range r.seedAddrs {
+	for _, seedAddr := range r.allSeedAddrs() {
 		if seedAddr.Equals(srcAddr) {
 			srcIsSeed = true
 			break
@@ -620,13 +641,81 @@ func (r *Reactor) checkSeeds() (numOnline int, netAddrs []*p2p.NetAddress, err e
 	return numOnline, netAddrs, nil
 }
 
+// checkDNSSeeds parses the configured DNS seeds and resolves them once.
+// Returns the number of addresses resolved, or -1 if no DNS seeds were
+// configured. Doesn't error if the domains can't be resolved.
+func (r *Reactor) checkDNSSeeds() (numOnline int, err error) {
+	if len(r.config.DNSSeeds) == 0 {
+		return -1, nil
+	}
+	seeds := make([]DNSSeed, 0, len(r.config.DNSSeeds))
+	for _, s := range r.config.DNSSeeds {
+		seed, err := ParseDNSSeed(s)
+		if err != nil {
+			return 0, fmt.Errorf("dns seed configuration has error: %w", err)
+		}
+		seeds = append(seeds, seed)
+	}
+	r.dnsSeeds = newDNSSeedResolver(seeds)
+	return r.refreshDNSSeeds(), nil
+}
+
+// refreshDNSSeeds resolves the DNS seeds and replaces the known DNS seed
+// addresses if anything could be resolved. Returns the number of addresses.
+func (r *Reactor) refreshDNSSeeds() int {
+	netAddrs, errs := r.dnsSeeds.Resolve()
+	for _, err := range errs {
+		r.Logger.Error("Resolving dns seed failed", "err", err)
+	}
+	if len(netAddrs) == 0 {
+		return 0
+	}
+	r.dnsSeedsMtx.Lock()
+	r.dnsSeedAddrs = netAddrs
+	r.dnsSeedsMtx.Unlock()
+	r.Logger.Info("Resolved dns seeds", "numAddrs", len(netAddrs))
+	return len(netAddrs)
+}
+
+// Resolves the DNS seeds periodically. (continuous)
+func (r *Reactor) dnsSeedsRoutine() {
+	period := r.config.DNSSeedsRefreshPeriod
+	if period <= 0 {
+		period = defaultDNSSeedsRefreshPeriod
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.refreshDNSSeeds()
+		case <-r.Quit():
+			return
+		}
+	}
+}
+
+// allSeedAddrs returns the configured seeds followed by those learned from
+// DNS seeds.
+func (r *Reactor) allSeedAddrs() []*p2p.NetAddress {
+	r.dnsSeedsMtx.RLock()
+	defer r.dnsSeedsMtx.RUnlock()
+	if len(r.dnsSeedAddrs) == 0 {
+		return r.seedAddrs
+	}
+	addrs := make([]*p2p.NetAddress, 0, len(r.seedAddrs)+len(r.dnsSeedAddrs))
+	addrs = append(addrs, r.seedAddrs...)
+	return append(addrs, r.dnsSeedAddrs...)
+}
+
 // randomly dial seeds until we connect to one or exhaust them
 func (r *Reactor) dialSeeds() {
-	perm := krand.NewRand().Perm(len(r.seedAddrs))
+	seedAddrs := r.allSeedAddrs()
+	perm := krand.NewRand().Perm(len(seedAddrs))
 	// perm := r.Switch.rng.Perm(lSeeds)
 	for _, i := range perm {
 		// dial a random seed
-		seedAddr := r.seedAddrs[i]
+		seedAddr := seedAddrs[i]
 		err := r.Switch.DialPeerWithAddress(seedAddr)
 
 		switch err.(type) {
@@ -636,7 +725,7 @@ func (r *Reactor) dialSeeds() {
 		r.Switch.Logger.Error("Error dialing seed", "err", err, "seed", seedAddr)
 	}
 	// do not write error message if there were no seeds specified in config
-	if len(r.seedAddrs) > 0 {
+	if len(seedAddrs) > 0 {
 		r.Switch.Logger.Error("Couldn't connect to any seeds")
 	}
 }
@@ -658,7 +747,7 @@ func (r *Reactor) AttemptsToDial(addr *p2p.NetAddress) int {
 // from peers, except other seed nodes.
 func (r *Reactor) crawlPeersRoutine() {
 	// If we have any seed nodes, consult them first
-	if len(r.seedAddrs) > 0 {
+	if len(r.allSeedAddrs()) > 0 {
 		r.dialSeeds()
 	} else {
 		// Do an initial crawl
@@ -764,7 +853,7 @@ func markAddrInBookBasedOnErr(addr *p2p.NetAddress, book AddrBook, err error) {
 	case p2p.ErrSwitchAuthenticationFailure:
 		book.MarkBad(addr, defaultBanTime)
 	default:
-		book.MarkAttempt(addr)
+		book.MarkFailed(addr)
 	}
 }
 
@@ -81,6 +81,9 @@ type Switch struct {
 	// peers addresses with whom we'll maintain constant connection
 	persistentPeersAddrs []*NetAddress
 	unconditionalPeerIDs map[ID]struct{}
+	// IDs of the peers run by active validators
+	validatorPeersMtx sync.RWMutex
+	validatorPeerIDs  map[ID]struct{}
 
 	transport Transport
 
@@ -120,6 +123,7 @@ func NewSwitch(
 		filterTimeout:        defaultFilterTimeout,
 		persistentPeersAddrs: make([]*NetAddress, 0),
 		unconditionalPeerIDs: make(map[ID]struct{}),
+		validatorPeerIDs:     make(map[ID]struct{}),
 	}
 
 	// Ensure we have a completely undeterministic PRNG.
@@ -260,10 +264,11 @@ func (sw *Switch) OnStop() {
 // closed once msg bytes are sent to all peers (or time out).
 //
 // NOTE: Broadcast uses goroutines, so order of broadcast may not be preserved.
+// Sends to validator peers are started first.
 func (sw *Switch) Broadcast(chID byte, msgBytes []byte) chan bool {
 	sw.Logger.Debug("Broadcast", "channel", chID, "msgBytes", fmt.Sprintf("%X", msgBytes))
 
-	peers := sw.peers.List()
+	peers := sw.validatorPeersFirst(sw.peers.List())
 	var wg sync.WaitGroup
 	wg.Add(len(peers))
 	successChan := make(chan bool, len(peers))
@@ -319,7 +324,7 @@ func (sw *Switch) Peers() IPeerSet {
 }
 
 // StopPeerForError disconnects from a peer due to external error.
-// If the peer is persistent, it will attempt to reconnect.
+// If the peer is persistent or a validator, it will attempt to reconnect.
 // TODO: make record depending on reason.
 func (sw *Switch) StopPeerForError(peer Peer, reason interface{}) {
 	if !peer.IsRunning() {
@@ -329,7 +334,7 @@ func (sw *Switch) StopPeerForError(peer Peer, reason interface{}) {
 	sw.Logger.Error("Stopping peer for error", "peer", peer, "err", reason)
 	sw.stopAndRemovePeer(peer, reason)
 
-	if peer.IsPersistent() {
+	if peer.IsPersistent() || sw.IsPeerValidator(peer.ID()) {
 		var addr *NetAddress
 		if peer.IsOutbound() { // socket address for outbound peers
 			addr = peer.SocketAddr()
@@ -673,6 +678,15 @@ func (sw *Switch) acceptRoutine() {
 		if !sw.IsPeerUnconditional(p.NodeInfo().ID()) {
 			// Ignore connection if we already have enough peers.
 			_, in, _ := sw.NumPeers()
+			if in >= sw.config.MaxNumInboundPeers && sw.IsPeerValidator(p.NodeInfo().ID()) {
+				// Make room for validators at the expense of ordinary full nodes.
+				if evicted := sw.evictableInboundPeer(); evicted != nil {
+					sw.Logger.Info("Evicting inbound peer to make room for validator",
+						"evicted", evicted.ID(), "validator", p.NodeInfo().ID())
+					sw.StopPeerGracefully(evicted)
+					in--
+				}
+			}
 			if in >= sw.config.MaxNumInboundPeers {
 				sw.Logger.Info(
 					"Ignoring inbound connection: already have enough inbound peers",
@@ -118,6 +118,12 @@ func MultiplexTransportFilterTimeout(
 	return func(mt *MultiplexTransport) { mt.filterTimeout = timeout }
 }
 
+// MultiplexTransportConnGater sets the gater consulted when dialing,
+// accepting and handshaking connections.
+func MultiplexTransportConnGater(gater ConnGater) MultiplexTransportOption {
+	return func(mt *MultiplexTransport) { mt.gater = gater }
+}
+
 // MultiplexTransportResolver sets the Resolver used for ip lokkups, defaults to
 // net.DefaultResolver.
 func MultiplexTransportResolver(resolver IPResolver) MultiplexTransportOption {
@@ -143,6 +149,7 @@ type MultiplexTransport struct {
 	// Lookup table for duplicate ip and id checks.
 	conns       ConnSet
 	connFilters []ConnFilterFunc
+	gater       ConnGater
 
 	dialTimeout      time.Duration
 	filterTimeout    time.Duration
@@ -209,13 +216,19 @@ func (mt *MultiplexTransport) Dial(
 	addr NetAddress,
 	cfg peerConfig,
 ) (Peer, error) {
+	if mt.gater != nil {
+		if err := mt.gater.InterceptDial(addr); err != nil {
+			return nil, ErrRejected{addr: addr, id: addr.ID, err: err, isFiltered: true}
+		}
+	}
+
 	c, err := addr.DialTimeout(mt.dialTimeout)
 	if err != nil {
 		return nil, err
 	}
 
 	// TODO(xla): Evaluate if we should apply filters if we explicitly dial.
-	if err := mt.filterConn(c); err != nil {
+	if err := mt.filterConn(c, true); err != nil {
 		return nil, err
 	}
 
@@ -307,7 +320,7 @@ func (mt *MultiplexTransport) acceptPeers() {
 				netAddr    *NetAddress
 			)
 
-			err := mt.filterConn(c)
+			err := mt.filterConn(c, false)
 			if err == nil {
 				secretConn, nodeInfo, err = mt.upgrade(c, nil)
 				if err == nil {
@@ -342,7 +355,7 @@ func (mt *MultiplexTransport) cleanup(c net.Conn) error {
 	return c.Close()
 }
 
-func (mt *MultiplexTransport) filterConn(c net.Conn) (err error) {
+func (mt *MultiplexTransport) filterConn(c net.Conn, outbound bool) (err error) {
 	defer func() {
 		if err != nil {
 			_ = c.Close()
@@ -360,6 +373,12 @@ func (mt *MultiplexTransport) filterConn(c net.Conn) (err error) {
 		return err
 	}
 
+	if mt.gater != nil && !outbound {
+		if err := mt.gater.InterceptAccept(c, ips); err != nil {
+			return ErrRejected{conn: c, err: err, isFiltered: true}
+		}
+	}
+
 	errc := make(chan error, len(mt.connFilters))
 
 	for _, f := range mt.connFilters {
@@ -470,6 +489,17 @@ func (mt *MultiplexTransport) upgrade(
 		}
 	}
 
+	if mt.gater != nil {
+		if err := mt.gater.InterceptHandshake(c, nodeInfo, dialedAddr != nil); err != nil {
+			return nil, nil, ErrRejected{
+				conn:       c,
+				err:        err,
+				id:         nodeInfo.ID(),
+				isFiltered: true,
+			}
+		}
+	}
+
 	return secretConn, nodeInfo, nil
 }
 
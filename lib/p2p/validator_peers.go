@@ -0,0 +1,80 @@
+package p2p
+
+import (
+	"encoding/hex"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+)
+
+// ValidatorAddressToID returns the peer ID of the node run by a validator.
+// Validators sign with their node key, and the secret connection handshake has
+// the remote sign a challenge, so a peer with this ID proved it holds the
+// validator key.
+func ValidatorAddressToID(addr common.Address) ID {
+	return ID(hex.EncodeToString(addr.Bytes()))
+}
+
+// SetValidatorAddresses replaces the set of active validators. Connected peers
+// whose authenticated ID matches one of the addresses are tagged as validator
+// peers: they get inbound slots even when the switch is full, are reconnected
+// to like persistent peers, and are served first on broadcasts.
+func (sw *Switch) SetValidatorAddresses(addrs []common.Address) {
+	ids := make(map[ID]struct{}, len(addrs))
+	for _, addr := range addrs {
+		ids[ValidatorAddressToID(addr)] = struct{}{}
+	}
+
+	sw.validatorPeersMtx.Lock()
+	sw.validatorPeerIDs = ids
+	sw.validatorPeersMtx.Unlock()
+}
+
+// IsPeerValidator returns true if the peer with the given ID belongs to an
+// active validator.
+func (sw *Switch) IsPeerValidator(id ID) bool {
+	sw.validatorPeersMtx.RLock()
+	defer sw.validatorPeersMtx.RUnlock()
+	_, ok := sw.validatorPeerIDs[id]
+	return ok
+}
+
+// NumValidatorPeers returns the number of connected validator peers.
+func (sw *Switch) NumValidatorPeers() int {
+	n := 0
+	for _, peer := range sw.peers.List() {
+		if sw.IsPeerValidator(peer.ID()) {
+			n++
+		}
+	}
+	return n
+}
+
+// validatorPeersFirst returns a copy of peers with validator peers first.
+func (sw *Switch) validatorPeersFirst(peers []Peer) []Peer {
+	sorted := make([]Peer, 0, len(peers))
+	others := make([]Peer, 0, len(peers))
+	for _, peer := range peers {
+		if sw.IsPeerValidator(peer.ID()) {
+			sorted = append(sorted, peer)
+		} else {
+			others = append(others, peer)
+		}
+	}
+	return append(sorted, others...)
+}
+
+// evictableInboundPeer returns an inbound peer which can be disconnected to
+// make room for a validator, or nil if there is none. Validator, persistent
+// and unconditional peers are never evicted.
+func (sw *Switch) evictableInboundPeer() Peer {
+	peers := sw.peers.List()
+	for _, i := range sw.rng.Perm(len(peers)) {
+		peer := peers[i]
+		if peer.IsOutbound() || peer.IsPersistent() ||
+			sw.IsPeerUnconditional(peer.ID()) || sw.IsPeerValidator(peer.ID()) {
+			continue
+		}
+		return peer
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package p2p
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+)
+
+func TestValidatorAddressToID(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	nodeKey := NodeKey{PrivKey: priv}
+	assert.Equal(t, nodeKey.ID(), ValidatorAddressToID(crypto.PubkeyToAddress(priv.PublicKey)))
+}
+
+func TestSwitchValidatorPeers(t *testing.T) {
+	sw := NewSwitch(cfg, nil)
+
+	var peers []Peer
+	for i := 0; i < 4; i++ {
+		p := newMockPeer(net.IP{127, 0, 0, byte(i)})
+		require.NoError(t, sw.peers.Add(p))
+		peers = append(peers, p)
+	}
+	assert.Equal(t, 0, sw.NumValidatorPeers())
+
+	validator := peers[2]
+	addr := common.HexToAddress(string(validator.ID()))
+	sw.SetValidatorAddresses([]common.Address{addr})
+
+	assert.True(t, sw.IsPeerValidator(validator.ID()))
+	assert.False(t, sw.IsPeerValidator(peers[0].ID()))
+	assert.Equal(t, 1, sw.NumValidatorPeers())
+
+	sorted := sw.validatorPeersFirst(sw.peers.List())
+	require.Len(t, sorted, len(peers))
+	assert.Equal(t, validator.ID(), sorted[0].ID())
+	// the peer set itself is left untouched
+	assert.Equal(t, peers[0].ID(), sw.peers.List()[0].ID())
+
+	// mock peers are persistent, so none of them can be evicted
+	assert.Nil(t, sw.evictableInboundPeer())
+
+	sw.SetValidatorAddresses(nil)
+	assert.False(t, sw.IsPeerValidator(validator.ID()))
+}
@@ -47,6 +47,8 @@ var (
 	ErrElemTooLarge     = errors.New("rlp: element is larger than containing list")
 	ErrValueTooLarge    = errors.New("rlp: value size exceeds available input length")
 	ErrMoreThanOneValue = errors.New("rlp: input contains more than one value")
+	ErrSizeLimit        = errors.New("rlp: value size exceeds decoding limit")
+	ErrDepthLimit       = errors.New("rlp: lists nested deeper than decoding limit")
 
 	// internal errors
 	errNotInList     = errors.New("rlp: call of ListEnd outside of any list")
@@ -60,6 +62,22 @@ var (
 	}
 )
 
+// Limits bounds the resources used to decode untrusted input. A zero field
+// means no limit.
+type Limits struct {
+	MaxSize  uint64 // Maximum size of a string or list, in bytes
+	MaxDepth int    // Maximum number of nested lists
+}
+
+// DefaultLimits are the limits of streams which are not given other ones with
+// SetLimits. They protect readers without a known input length, which would
+// otherwise allocate buffers of any size declared by the input. DefaultLimits
+// may be changed at startup, before any decoding.
+var DefaultLimits = Limits{
+	MaxSize:  128 * 1024 * 1024,
+	MaxDepth: 256,
+}
+
 // Decoder is implemented by types that require custom RLP decoding rules or need to decode
 // into private fields.
 //
@@ -90,12 +108,19 @@ func Decode(r io.Reader, val interface{}) error {
 // DecodeBytes parses RLP data from b into val. Please see package-level documentation for
 // the decoding rules. The input must contain exactly one value and no trailing data.
 func DecodeBytes(b []byte, val interface{}) error {
+	return DecodeBytesWithLimits(b, val, DefaultLimits)
+}
+
+// DecodeBytesWithLimits is like DecodeBytes, with the given decoding limits
+// instead of DefaultLimits.
+func DecodeBytesWithLimits(b []byte, val interface{}, limits Limits) error {
 	r := bytes.NewReader(b)
 
 	stream := streamPool.Get().(*Stream)
 	defer streamPool.Put(stream)
 
 	stream.Reset(r, uint64(len(b)))
+	stream.SetLimits(limits)
 	if err := stream.Decode(val); err != nil {
 		return err
 	}
@@ -606,6 +631,7 @@ type Stream struct {
 	kind      Kind     // kind of value ahead
 	byteval   byte     // value of single byte in type tag
 	limited   bool     // true if input limit is in effect
+	limits    Limits   // decoding limits, checked regardless of input limit
 }
 
 // NewStream creates a new decoding stream reading from r.
@@ -625,6 +651,8 @@ type Stream struct {
 // If r is a bytes.Reader or strings.Reader, the input limit is set to
 // the length of r's underlying data unless an explicit limit is
 // provided.
+//
+// The stream also enforces DefaultLimits, see SetLimits.
 func NewStream(r io.Reader, inputLimit uint64) *Stream {
 	s := new(Stream)
 	s.Reset(r, inputLimit)
@@ -716,6 +744,10 @@ func (s *Stream) Raw() ([]byte, error) {
 		return nil, err
 	}
 	if kind == String {
+		// Reject cases where single byte encoding should have been used.
+		if size == 1 && buf[start] < 128 {
+			return nil, ErrCanonSize
+		}
 		puthead(buf, 0x80, 0xB7, size)
 	} else {
 		puthead(buf, 0xC0, 0xF7, size)
@@ -792,6 +824,9 @@ func (s *Stream) List() (size uint64, err error) {
 	if kind != List {
 		return 0, ErrExpectedList
 	}
+	if s.limits.MaxDepth > 0 && len(s.stack) >= s.limits.MaxDepth {
+		return 0, ErrDepthLimit
+	}
 
 	// Remove size of inner list from outer list before pushing the new size
 	// onto the stack. This ensures that the remaining outer list size will
@@ -888,6 +923,14 @@ func (s *Stream) Reset(r io.Reader, inputLimit uint64) {
 	s.kinderr = nil
 	s.byteval = 0
 	s.uintbuf = [32]byte{}
+	s.limits = DefaultLimits
+}
+
+// SetLimits sets the decoding limits of the stream, until the next Reset.
+// Values larger than limits.MaxSize fail with ErrSizeLimit and lists nested
+// deeper than limits.MaxDepth with ErrDepthLimit.
+func (s *Stream) SetLimits(limits Limits) {
+	s.limits = limits
 }
 
 // Kind returns the kind and size of the next value in the
@@ -923,6 +966,8 @@ func (s *Stream) Kind() (kind Kind, size uint64, err error) {
 			s.kinderr = ErrElemTooLarge
 		} else if s.limited && s.size > s.remaining {
 			s.kinderr = ErrValueTooLarge
+		} else if s.limits.MaxSize > 0 && s.size > s.limits.MaxSize {
+			s.kinderr = ErrSizeLimit
 		}
 	}
 	return s.kind, s.size, s.kinderr
@@ -51,8 +51,9 @@ func TestStreamKind(t *testing.T) {
 	}
 
 	for i, test := range tests {
-		// using plainReader to inhibit input limit errors.
+		// using plainReader and no decoding limits to inhibit input limit errors.
 		s := NewStream(newPlainReader(unhex(test.input)), 0)
+		s.SetLimits(Limits{})
 		kind, len, err := s.Kind()
 		if err != nil {
 			t.Errorf("test %d: Kind returned error: %v", i, err)
@@ -94,6 +95,13 @@ func TestStreamErrors(t *testing.T) {
 			return NewStream(bytes.NewReader(b), limit)
 		}
 	}
+	withLimits := func(limits Limits) func([]byte) *Stream {
+		return func(b []byte) *Stream {
+			s := NewStream(newPlainReader(b), 0)
+			s.SetLimits(limits)
+			return s
+		}
+	}
 
 	type calls []string
 	tests := []struct {
@@ -170,6 +178,19 @@ func TestStreamErrors(t *testing.T) {
 		{"C40102030401", calls{"Raw", "Uint"}, withoutInputLimit, nil},
 		{"C4010203048180", calls{"Raw", "Uint"}, withoutInputLimit, nil},
 
+		// Decoding limits, which also apply without an input limit.
+		{"8401020304", calls{"Bytes"}, withLimits(Limits{MaxSize: 3}), ErrSizeLimit},
+		{"8401020304", calls{"Bytes"}, withLimits(Limits{MaxSize: 4}), nil},
+		{"BFFFFFFFFFFFFFFFFF", calls{"Bytes"}, withoutInputLimit, ErrSizeLimit},
+		{"FFFFFFFFFFFFFFFFFF", calls{"List"}, withoutInputLimit, ErrSizeLimit},
+		{"C3C2C101", calls{"List", "List", "List"}, withLimits(Limits{MaxDepth: 2}), ErrDepthLimit},
+		{"C3C2C101", calls{"List", "List", "List", "Uint"}, withLimits(Limits{MaxDepth: 3}), nil},
+		{"C3C2C101", calls{"List", "List", "List", "Uint"}, withLimits(Limits{}), nil},
+
+		// Raw rejects non-canonical single byte strings.
+		{"8101", calls{"Raw"}, nil, ErrCanonSize},
+		{"C28101", calls{"List", "Raw"}, nil, ErrCanonSize},
+
 		// Unexpected EOF. This only happens when there is
 		// no input limit, so the reader needs to be 'dumbed down'.
 		{"81", calls{"Bytes"}, withoutInputLimit, io.ErrUnexpectedEOF},
@@ -286,6 +307,25 @@ func TestStreamRaw(t *testing.T) {
 	}
 }
 
+func TestDecodeBytesWithLimits(t *testing.T) {
+	var nested []interface{}
+	input := unhex("C4C3C2C101")
+	if err := DecodeBytesWithLimits(input, &nested, Limits{MaxDepth: 3}); err != ErrDepthLimit {
+		t.Errorf("wrong error for nested lists: %v", err)
+	}
+	if err := DecodeBytesWithLimits(input, &nested, Limits{MaxDepth: 4}); err != nil {
+		t.Errorf("unexpected error for nested lists: %v", err)
+	}
+	var b []byte
+	if err := DecodeBytesWithLimits(unhex("83010203"), &b, Limits{MaxSize: 2}); err != ErrSizeLimit {
+		t.Errorf("wrong error for large string: %v", err)
+	}
+	// The limits of a pooled stream must not leak into the next decoding.
+	if err := DecodeBytes(unhex("83010203"), &b); err != nil {
+		t.Errorf("unexpected error after limited decoding: %v", err)
+	}
+}
+
 func TestStreamReadBytes(t *testing.T) {
 	tests := []struct {
 		input string
@@ -0,0 +1,233 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package light implements a light client, which follows the chain by
+// verifying the validator signatures of headers instead of executing blocks.
+//
+// The client starts from a header the user trusts. Newer headers are verified
+// sequentially: each header must link to the previous one, be signed by more
+// than 2/3 of the voting power of the validators the previous header named as
+// next validators. Older headers are verified backwards through the hash
+// chain. Once a header is trusted, data committed to by its hashes, such as
+// the state behind its app hash, can be verified with merkle proofs.
+package light
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// Provider provides the signed headers and validator sets of a chain, usually
+// from the RPC of a full node. Providers are not trusted.
+type Provider interface {
+	// SignedHeader returns the header at height with its commit. Height 0
+	// means the latest header which has a commit.
+	SignedHeader(ctx context.Context, height uint64) (*SignedHeader, error)
+	// ValidatorSet returns the validators of the block at height.
+	ValidatorSet(ctx context.Context, height uint64) (*types.ValidatorSet, error)
+}
+
+// Client is a light client verifying the headers of a chain.
+type Client struct {
+	logger         log.Logger
+	chainID        string
+	trustingPeriod time.Duration
+	provider       Provider
+	store          *store
+
+	mu     sync.Mutex
+	latest *SignedHeader
+
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
+}
+
+// NewClient returns a client verifying the headers of chain chainID served
+// by provider, and storing verified headers in db.
+//
+// If db holds headers verified before, the client resumes from the latest of
+// them, provided the header of opts matches what was verified. Otherwise the
+// header of opts is fetched and checked against its hash.
+func NewClient(
+	ctx context.Context,
+	logger log.Logger,
+	chainID string,
+	opts TrustOptions,
+	provider Provider,
+	db kaidb.Database,
+) (*Client, error) {
+	if err := opts.ValidateBasic(); err != nil {
+		return nil, fmt.Errorf("invalid trust options: %w", err)
+	}
+	c := &Client{
+		logger:         logger.New("module", "light"),
+		chainID:        chainID,
+		trustingPeriod: opts.Period,
+		provider:       provider,
+		store:          &store{db: db},
+		now:            time.Now,
+	}
+
+	trusted, err := c.store.get(opts.Height)
+	if err != nil {
+		return nil, err
+	}
+	if trusted != nil {
+		if hash := trusted.Hash(); !hash.Equal(opts.Hash) {
+			return nil, fmt.Errorf("trusted header %d hash %v conflicts with verified header hash %v",
+				opts.Height, opts.Hash.Hex(), hash.Hex())
+		}
+	} else if err := c.initializeWithTrustOptions(ctx, opts); err != nil {
+		return nil, err
+	}
+
+	if c.latest, err = c.store.latest(); err != nil {
+		return nil, err
+	}
+	c.logger.Info("Light client initialized", "height", c.latest.Height(), "hash", c.latest.Hash().Hex())
+	return c, nil
+}
+
+func (c *Client) initializeWithTrustOptions(ctx context.Context, opts TrustOptions) error {
+	sh, err := c.provider.SignedHeader(ctx, opts.Height)
+	if err != nil {
+		return err
+	}
+	if hash := sh.Hash(); !hash.Equal(opts.Hash) {
+		return fmt.Errorf("expected header %d hash %v, got %v", opts.Height, opts.Hash.Hex(), hash.Hex())
+	}
+	if HeaderExpired(sh.Header, opts.Period, c.now()) {
+		return ErrOldHeaderExpired{sh.Header.Time.Add(opts.Period), c.now()}
+	}
+	vals, err := c.provider.ValidatorSet(ctx, opts.Height)
+	if err != nil {
+		return err
+	}
+	// The hash is trusted, so the commit only has to be valid.
+	if err := VerifyCommit(c.chainID, sh.Header, vals, sh.Commit); err != nil {
+		return ErrInvalidHeader{err}
+	}
+	return c.store.save(sh)
+}
+
+// ChainID returns the chain the client verifies.
+func (c *Client) ChainID() string { return c.chainID }
+
+// LatestTrustedHeader returns the highest verified header.
+func (c *Client) LatestTrustedHeader() *SignedHeader {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latest
+}
+
+// TrustedHeader returns the verified header at height, or nil if it was not
+// verified yet.
+func (c *Client) TrustedHeader(height uint64) (*SignedHeader, error) {
+	return c.store.get(height)
+}
+
+// Update verifies the latest header of the provider and returns it.
+func (c *Client) Update(ctx context.Context) (*SignedHeader, error) {
+	sh, err := c.provider.SignedHeader(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	return c.VerifyHeaderAtHeight(ctx, sh.Height())
+}
+
+// VerifyHeaderAtHeight returns the header at height, verifying it and the
+// headers between it and the closest trusted header if needed.
+func (c *Client) VerifyHeaderAtHeight(ctx context.Context, height uint64) (*SignedHeader, error) {
+	if height == 0 {
+		return nil, errors.New("height must be positive")
+	}
+	if sh, err := c.store.get(height); sh != nil || err != nil {
+		return sh, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if height > c.latest.Height() {
+		return c.verifySequential(ctx, height)
+	}
+	return c.verifyBackwards(ctx, height)
+}
+
+// verifySequential verifies the headers from the latest trusted header up to
+// height.
+func (c *Client) verifySequential(ctx context.Context, height uint64) (*SignedHeader, error) {
+	trusted := c.latest
+	for h := trusted.Height() + 1; h <= height; h++ {
+		sh, err := c.provider.SignedHeader(ctx, h)
+		if err != nil {
+			return nil, err
+		}
+		vals, err := c.provider.ValidatorSet(ctx, h)
+		if err != nil {
+			return nil, err
+		}
+		if err := VerifyAdjacent(c.chainID, trusted, sh, vals, c.trustingPeriod, c.now()); err != nil {
+			c.logger.Error("Failed to verify header", "height", h, "err", err)
+			return nil, err
+		}
+		if err := c.store.save(sh); err != nil {
+			return nil, err
+		}
+		trusted = sh
+		c.latest = sh
+	}
+	c.logger.Debug("Verified headers", "height", height)
+	return trusted, nil
+}
+
+// verifyBackwards verifies the headers from the closest trusted header above
+// height down to height.
+func (c *Client) verifyBackwards(ctx context.Context, height uint64) (*SignedHeader, error) {
+	if HeaderExpired(c.latest.Header, c.trustingPeriod, c.now()) {
+		return nil, ErrOldHeaderExpired{c.latest.Header.Time.Add(c.trustingPeriod), c.now()}
+	}
+	trusted, err := c.store.firstAbove(height)
+	if err != nil {
+		return nil, err
+	}
+	if trusted == nil {
+		return nil, fmt.Errorf("no trusted header above %d", height)
+	}
+	for h := trusted.Height() - 1; h >= height; h-- {
+		sh, err := c.provider.SignedHeader(ctx, h)
+		if err != nil {
+			return nil, err
+		}
+		if err := VerifyBackwards(sh.Header, trusted.Header); err != nil {
+			return nil, err
+		}
+		// The commit was not verified, so only the header is kept.
+		trusted = &SignedHeader{Header: sh.Header}
+		if err := c.store.save(trusted); err != nil {
+			return nil, err
+		}
+	}
+	return trusted, nil
+}
@@ -0,0 +1,207 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package light
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+const testChainID = "kai-test"
+
+type mockProvider struct {
+	headers map[uint64]*SignedHeader
+	vals    map[uint64]*types.ValidatorSet
+	latest  uint64
+}
+
+func (p *mockProvider) SignedHeader(ctx context.Context, height uint64) (*SignedHeader, error) {
+	if height == 0 {
+		height = p.latest
+	}
+	sh, ok := p.headers[height]
+	if !ok {
+		return nil, fmt.Errorf("header %d: %w", height, ErrNotFound)
+	}
+	return sh, nil
+}
+
+func (p *mockProvider) ValidatorSet(ctx context.Context, height uint64) (*types.ValidatorSet, error) {
+	vals, ok := p.vals[height]
+	if !ok {
+		return nil, fmt.Errorf("validator set %d: %w", height, ErrNotFound)
+	}
+	return vals, nil
+}
+
+// signHeader returns header with a commit of privVals.
+func signHeader(t *testing.T, header *types.Header, vals *types.ValidatorSet, privVals []types.PrivValidator) *SignedHeader {
+	hash := header.Hash()
+	blockID := types.BlockID{Hash: hash, PartsHeader: types.PartSetHeader{Total: 1, Hash: hash}}
+	voteSet := types.NewVoteSet(testChainID, header.Height, 0, kproto.PrecommitType, vals)
+	commit, err := types.MakeCommit(blockID, header.Height, 0, voteSet, privVals, header.Time.Add(time.Second))
+	require.NoError(t, err)
+	return &SignedHeader{Header: header, Commit: commit}
+}
+
+// newMockProvider returns a provider of a chain of n blocks signed by the
+// same validators.
+func newMockProvider(t *testing.T, n uint64) (*mockProvider, []types.PrivValidator) {
+	vals, privVals := types.RandValidatorSet(4, 10)
+	p := &mockProvider{
+		headers: make(map[uint64]*SignedHeader),
+		vals:    make(map[uint64]*types.ValidatorSet),
+		latest:  n,
+	}
+	start := time.Now().Add(-time.Hour)
+	var last types.BlockID
+	for h := uint64(1); h <= n; h++ {
+		header := &types.Header{
+			Height:             h,
+			Time:               start.Add(time.Duration(h) * time.Second),
+			LastBlockID:        last,
+			ValidatorsHash:     vals.Hash(),
+			NextValidatorsHash: vals.Hash(),
+			AppHash:            common.BytesToHash([]byte{byte(h)}),
+		}
+		sh := signHeader(t, header, vals, privVals)
+		p.headers[h] = sh
+		p.vals[h] = vals
+		last = sh.Commit.BlockID
+	}
+	return p, privVals
+}
+
+func newTestClient(t *testing.T, p *mockProvider, height uint64) *Client {
+	opts := TrustOptions{Period: 24 * time.Hour, Height: height, Hash: p.headers[height].Hash()}
+	c, err := NewClient(context.Background(), log.New(), testChainID, opts, p, memorydb.New())
+	require.NoError(t, err)
+	return c
+}
+
+func TestClientVerifiesSequentially(t *testing.T) {
+	p, _ := newMockProvider(t, 10)
+	c := newTestClient(t, p, 1)
+
+	sh, err := c.VerifyHeaderAtHeight(context.Background(), 5)
+	require.NoError(t, err)
+	require.Equal(t, p.headers[5].Hash(), sh.Hash())
+	require.EqualValues(t, 5, c.LatestTrustedHeader().Height())
+
+	sh, err = c.Update(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 10, sh.Height())
+
+	trusted, err := c.TrustedHeader(7)
+	require.NoError(t, err)
+	require.Equal(t, p.headers[7].Hash(), trusted.Hash())
+}
+
+func TestClientRejectsHeaderOfOtherValidators(t *testing.T) {
+	p, _ := newMockProvider(t, 5)
+	c := newTestClient(t, p, 1)
+
+	// Header 3 signed by validators which are not the next validators of
+	// header 2.
+	vals, privVals := types.RandValidatorSet(4, 10)
+	header := *p.headers[3].Header
+	header.ValidatorsHash = vals.Hash()
+	p.headers[3] = signHeader(t, &header, vals, privVals)
+	p.vals[3] = vals
+
+	_, err := c.VerifyHeaderAtHeight(context.Background(), 4)
+	var invalid ErrInvalidHeader
+	require.True(t, errors.As(err, &invalid), "got %v", err)
+	require.EqualValues(t, 2, c.LatestTrustedHeader().Height())
+}
+
+func TestClientRejectsForgedCommit(t *testing.T) {
+	p, privVals := newMockProvider(t, 5)
+	c := newTestClient(t, p, 1)
+
+	// Header 2 with a different app hash, signed by a minority.
+	header := *p.headers[2].Header
+	header.AppHash = common.BytesToHash([]byte("forged"))
+	forged := signHeader(t, &header, p.vals[2], privVals)
+	for i := 1; i < len(forged.Commit.Signatures); i++ {
+		forged.Commit.Signatures[i] = types.NewCommitSigAbsent()
+	}
+	p.headers[2] = forged
+
+	_, err := c.VerifyHeaderAtHeight(context.Background(), 2)
+	var invalid ErrInvalidHeader
+	require.True(t, errors.As(err, &invalid), "got %v", err)
+}
+
+func TestClientVerifiesBackwards(t *testing.T) {
+	p, _ := newMockProvider(t, 10)
+	c := newTestClient(t, p, 8)
+
+	sh, err := c.VerifyHeaderAtHeight(context.Background(), 3)
+	require.NoError(t, err)
+	require.Equal(t, p.headers[3].Hash(), sh.Hash())
+	require.EqualValues(t, 8, c.LatestTrustedHeader().Height())
+
+	// A header not linked to the trusted ones is rejected.
+	header := *p.headers[2].Header
+	header.AppHash = common.BytesToHash([]byte("forged"))
+	p.headers[2] = &SignedHeader{Header: &header, Commit: p.headers[2].Commit}
+	_, err = c.VerifyHeaderAtHeight(context.Background(), 2)
+	var invalid ErrInvalidHeader
+	require.True(t, errors.As(err, &invalid), "got %v", err)
+}
+
+func TestClientRejectsExpiredHeader(t *testing.T) {
+	p, _ := newMockProvider(t, 5)
+	c := newTestClient(t, p, 1)
+	c.now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+
+	_, err := c.VerifyHeaderAtHeight(context.Background(), 3)
+	var expired ErrOldHeaderExpired
+	require.True(t, errors.As(err, &expired), "got %v", err)
+}
+
+func TestClientResumes(t *testing.T) {
+	p, _ := newMockProvider(t, 5)
+	db := memorydb.New()
+	opts := TrustOptions{Period: 24 * time.Hour, Height: 1, Hash: p.headers[1].Hash()}
+	c, err := NewClient(context.Background(), log.New(), testChainID, opts, p, db)
+	require.NoError(t, err)
+	_, err = c.VerifyHeaderAtHeight(context.Background(), 4)
+	require.NoError(t, err)
+
+	c, err = NewClient(context.Background(), log.New(), testChainID, opts, p, db)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, c.LatestTrustedHeader().Height())
+
+	opts.Hash = p.headers[2].Hash()
+	_, err = NewClient(context.Background(), log.New(), testChainID, opts, p, db)
+	require.Error(t, err)
+}
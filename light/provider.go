@@ -0,0 +1,95 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package light
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/rpc"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// ErrNotFound means the provider has no data at the requested height.
+var ErrNotFound = errors.New("not found")
+
+// RPCProvider is a Provider querying the RPC of a full node.
+type RPCProvider struct {
+	client *rpc.Client
+}
+
+// NewRPCProvider returns a provider querying client.
+func NewRPCProvider(client *rpc.Client) *RPCProvider {
+	return &RPCProvider{client: client}
+}
+
+// Client returns the RPC client of the provider.
+func (p *RPCProvider) Client() *rpc.Client { return p.client }
+
+// SignedHeader implements Provider.
+func (p *RPCProvider) SignedHeader(ctx context.Context, height uint64) (*SignedHeader, error) {
+	if height > 0 {
+		return p.signedHeader(ctx, rpc.BlockHeight(height))
+	}
+	sh, err := p.signedHeader(ctx, rpc.LatestBlockHeight)
+	if !errors.Is(err, ErrNotFound) {
+		return sh, err
+	}
+	// The head of a full node has no commit until the next block, so the
+	// latest signed header is the one before it.
+	var head uint64
+	if err := p.client.CallContext(ctx, &head, "kai_blockNumber"); err != nil {
+		return nil, err
+	}
+	if head < 2 {
+		return nil, err
+	}
+	return p.signedHeader(ctx, rpc.BlockHeight(head-1))
+}
+
+func (p *RPCProvider) signedHeader(ctx context.Context, blockHeight rpc.BlockHeight) (*SignedHeader, error) {
+	var header *types.Header
+	if err := p.client.CallContext(ctx, &header, "kai_getBlockHeaderByNumber", blockHeight); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("header %d: %w", blockHeight, ErrNotFound)
+	}
+	var commit *types.Commit
+	if err := p.client.CallContext(ctx, &commit, "kai_getCommit", rpc.BlockHeight(header.Height)); err != nil {
+		return nil, err
+	}
+	if commit == nil {
+		return nil, fmt.Errorf("commit %d: %w", header.Height, ErrNotFound)
+	}
+	return &SignedHeader{Header: header, Commit: commit}, nil
+}
+
+// ValidatorSet implements Provider.
+func (p *RPCProvider) ValidatorSet(ctx context.Context, height uint64) (*types.ValidatorSet, error) {
+	var vals *types.ValidatorSet
+	if err := p.client.CallContext(ctx, &vals, "kai_getValidatorSet", rpc.BlockHeight(height)); err != nil {
+		return nil, err
+	}
+	if vals == nil {
+		return nil, fmt.Errorf("validator set %d: %w", height, ErrNotFound)
+	}
+	return vals, nil
+}
@@ -0,0 +1,222 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/light"
+	"github.com/kardiachain/go-kardia/rpc"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+var errHashNotSupported = errors.New("blocks must be requested by height")
+
+// PublicKaiAPI serves verified headers, commits and validator sets.
+type PublicKaiAPI struct {
+	p *Proxy
+}
+
+// BlockNumber returns the height of the latest verified header.
+func (s *PublicKaiAPI) BlockNumber(ctx context.Context) (uint64, error) {
+	sh, err := s.p.client.Update(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return sh.Height(), nil
+}
+
+// GetBlockHeaderByNumber returns the verified header at height.
+func (s *PublicKaiAPI) GetBlockHeaderByNumber(ctx context.Context, blockHeight rpc.BlockHeight) (*types.Header, error) {
+	sh, err := s.p.verifiedHeader(ctx, blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	return sh.Header, nil
+}
+
+// GetCommit returns the commit of the block at height, verified against
+// its validators.
+func (s *PublicKaiAPI) GetCommit(ctx context.Context, blockHeight rpc.BlockHeight) (*types.Commit, error) {
+	sh, err := s.p.verifiedHeader(ctx, blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	if sh.Commit != nil {
+		return sh.Commit, nil
+	}
+	// Headers verified backwards are stored without their commit.
+	var commit *types.Commit
+	if err := s.p.primary.CallContext(ctx, &commit, "kai_getCommit", rpc.BlockHeight(sh.Height())); err != nil {
+		return nil, err
+	}
+	vals, err := s.p.validatorSet(ctx, sh)
+	if err != nil {
+		return nil, err
+	}
+	if err := light.VerifyCommit(s.p.client.ChainID(), sh.Header, vals, commit); err != nil {
+		return nil, fmt.Errorf("invalid commit %d: %w", sh.Height(), err)
+	}
+	return commit, nil
+}
+
+// GetValidatorSet returns the validators of the block at height, verified
+// against its header.
+func (s *PublicKaiAPI) GetValidatorSet(ctx context.Context, blockHeight rpc.BlockHeight) (*types.ValidatorSet, error) {
+	sh, err := s.p.verifiedHeader(ctx, blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	return s.p.validatorSet(ctx, sh)
+}
+
+// PublicAccountAPI serves account state verified with merkle proofs.
+type PublicAccountAPI struct {
+	p *Proxy
+}
+
+// Balance returns the verified balance of address.
+func (s *PublicAccountAPI) Balance(ctx context.Context, address common.Address, blockHeightOrHash rpc.BlockHeightOrHash) (string, error) {
+	account, err := s.p.verifiedAccount(ctx, address, nil, blockHeightOrHash)
+	if err != nil {
+		return "", err
+	}
+	return account.Balance.ToInt().String(), nil
+}
+
+// NonceAtHeight returns the verified nonce of address.
+func (s *PublicAccountAPI) NonceAtHeight(ctx context.Context, address common.Address, blockHeightOrHash rpc.BlockHeightOrHash) (uint64, error) {
+	account, err := s.p.verifiedAccount(ctx, address, nil, blockHeightOrHash)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(account.Nonce), nil
+}
+
+// GetCode returns the code of address, verified against its code hash.
+func (s *PublicAccountAPI) GetCode(ctx context.Context, address common.Address, blockHeightOrHash rpc.BlockHeightOrHash) (common.Bytes, error) {
+	account, err := s.p.verifiedAccount(ctx, address, nil, blockHeightOrHash)
+	if err != nil {
+		return nil, err
+	}
+	var code common.Bytes
+	if err := s.p.primary.CallContext(ctx, &code, "account_getCode", address, account.blockHeightOrHash()); err != nil {
+		return nil, err
+	}
+	if hash := crypto.Keccak256Hash(code); !hash.Equal(account.CodeHash) {
+		return nil, fmt.Errorf("code hash %v does not match proven code hash %v", hash.Hex(), account.CodeHash.Hex())
+	}
+	return code, nil
+}
+
+// GetStorageAt returns the verified storage of address at key.
+func (s *PublicAccountAPI) GetStorageAt(ctx context.Context, address common.Address, key string, blockHeightOrHash rpc.BlockHeightOrHash) (common.Bytes, error) {
+	account, err := s.p.verifiedAccount(ctx, address, []string{key}, blockHeightOrHash)
+	if err != nil {
+		return nil, err
+	}
+	value := common.BigToHash(account.StorageProof[0].Value.ToInt())
+	return value[:], nil
+}
+
+// PublicTransactionAPI forwards transactions to the full node.
+type PublicTransactionAPI struct {
+	p *Proxy
+}
+
+// SendRawTransaction forwards the encoded transaction to the full node.
+func (s *PublicTransactionAPI) SendRawTransaction(ctx context.Context, tx string) (string, error) {
+	var hash string
+	err := s.p.primary.CallContext(ctx, &hash, "tx_sendRawTransaction", tx)
+	return hash, err
+}
+
+// verifiedHeader returns the verified header at blockHeight, the latest
+// one for the latest and pending heights.
+func (p *Proxy) verifiedHeader(ctx context.Context, blockHeight rpc.BlockHeight) (*light.SignedHeader, error) {
+	if blockHeight == rpc.LatestBlockHeight || blockHeight == rpc.PendingBlockHeight {
+		return p.client.Update(ctx)
+	}
+	return p.client.VerifyHeaderAtHeight(ctx, blockHeight.Uint64())
+}
+
+// validatorSet returns the validators of the block of sh.
+func (p *Proxy) validatorSet(ctx context.Context, sh *light.SignedHeader) (*types.ValidatorSet, error) {
+	var vals *types.ValidatorSet
+	if err := p.primary.CallContext(ctx, &vals, "kai_getValidatorSet", rpc.BlockHeight(sh.Height())); err != nil {
+		return nil, err
+	}
+	if err := light.VerifyValidators(sh.Header, vals); err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// verifiedAccount returns the proof of address and storageKeys at
+// blockHeightOrHash, verified against the app hash of the next header.
+func (p *Proxy) verifiedAccount(ctx context.Context, address common.Address, storageKeys []string, blockHeightOrHash rpc.BlockHeightOrHash) (*accountResult, error) {
+	blockHeight, ok := blockHeightOrHash.Height()
+	if !ok {
+		return nil, errHashNotSupported
+	}
+	// The state after a block is committed to by the app hash of the next
+	// header, so the latest verifiable state is the one before the latest
+	// verified header.
+	var next *light.SignedHeader
+	if blockHeight == rpc.LatestBlockHeight || blockHeight == rpc.PendingBlockHeight {
+		latest, err := p.client.Update(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if latest.Height() < 2 {
+			return nil, errors.New("no verifiable state yet")
+		}
+		blockHeight = rpc.BlockHeight(latest.Height() - 1)
+		next = latest
+	} else {
+		var err error
+		if next, err = p.client.VerifyHeaderAtHeight(ctx, blockHeight.Uint64()+1); err != nil {
+			return nil, err
+		}
+	}
+
+	account := &accountResult{height: blockHeight}
+	if storageKeys == nil {
+		storageKeys = []string{}
+	}
+	if err := p.primary.CallContext(ctx, account, "kai_getProof", address, storageKeys, account.blockHeightOrHash()); err != nil {
+		return nil, err
+	}
+	if err := account.verify(address, next.Header.AppHash); err != nil {
+		return nil, fmt.Errorf("invalid proof of %v at %d: %w", address.Hex(), blockHeight, err)
+	}
+	if len(account.StorageProof) != len(storageKeys) {
+		return nil, fmt.Errorf("expected %d storage proofs, got %d", len(storageKeys), len(account.StorageProof))
+	}
+	for i, key := range storageKeys {
+		if account.StorageProof[i].Key != key {
+			return nil, fmt.Errorf("expected storage proof of %s, got %s", key, account.StorageProof[i].Key)
+		}
+	}
+	return account, nil
+}
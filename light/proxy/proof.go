@@ -0,0 +1,150 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proxy
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/kai/state"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+	"github.com/kardiachain/go-kardia/rpc"
+	"github.com/kardiachain/go-kardia/trie"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+var emptyCodeHash = crypto.Keccak256Hash(nil)
+
+// accountResult is the result of kai_getProof.
+type accountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []string        `json:"accountProof"`
+	Balance      *common.Big     `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        common.Uint64   `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []storageResult `json:"storageProof"`
+
+	height rpc.BlockHeight
+}
+
+type storageResult struct {
+	Key   string      `json:"key"`
+	Value *common.Big `json:"value"`
+	Proof []string    `json:"proof"`
+}
+
+func (r *accountResult) blockHeightOrHash() rpc.BlockHeightOrHash {
+	return rpc.BlockHeightOrHashWithHeight(r.height)
+}
+
+// verify checks the account and storage proofs against the state root.
+func (r *accountResult) verify(address common.Address, root common.Hash) error {
+	if r.Address != address {
+		return fmt.Errorf("proof of %v instead of %v", r.Address.Hex(), address.Hex())
+	}
+	if r.Balance == nil {
+		return fmt.Errorf("missing balance")
+	}
+	proof, err := proofDB(r.AccountProof)
+	if err != nil {
+		return err
+	}
+	value, err := trie.VerifyProof(root, crypto.Keccak256(address.Bytes()), proof)
+	if err != nil {
+		return err
+	}
+
+	account := state.Account{Balance: new(big.Int), Root: types.EmptyRootHash, CodeHash: emptyCodeHash.Bytes()}
+	if value != nil {
+		if err := rlp.DecodeBytes(value, &account); err != nil {
+			return fmt.Errorf("invalid account: %w", err)
+		}
+	}
+	if account.Nonce != uint64(r.Nonce) {
+		return fmt.Errorf("nonce %d does not match proven nonce %d", r.Nonce, account.Nonce)
+	}
+	if account.Balance.Cmp(r.Balance.ToInt()) != 0 {
+		return fmt.Errorf("balance %v does not match proven balance %v", r.Balance.ToInt(), account.Balance)
+	}
+	if !bytes.Equal(account.CodeHash, r.CodeHash.Bytes()) {
+		return fmt.Errorf("code hash %v does not match proven code hash %x", r.CodeHash.Hex(), account.CodeHash)
+	}
+	if !account.Root.Equal(r.StorageHash) {
+		return fmt.Errorf("storage hash %v does not match proven storage hash %v", r.StorageHash.Hex(), account.Root.Hex())
+	}
+
+	for _, s := range r.StorageProof {
+		if err := s.verify(account.Root); err != nil {
+			return fmt.Errorf("storage %s: %w", s.Key, err)
+		}
+	}
+	return nil
+}
+
+// verify checks the storage proof against the storage root of the account.
+func (s *storageResult) verify(root common.Hash) error {
+	if s.Value == nil {
+		return fmt.Errorf("missing value")
+	}
+	want := new(big.Int)
+	if root != types.EmptyRootHash {
+		proof, err := proofDB(s.Proof)
+		if err != nil {
+			return err
+		}
+		key := common.HexToHash(s.Key)
+		value, err := trie.VerifyProof(root, crypto.Keccak256(key.Bytes()), proof)
+		if err != nil {
+			return err
+		}
+		if value != nil {
+			// Slots are stored as RLP encoded trimmed bytes.
+			var content []byte
+			if err := rlp.DecodeBytes(value, &content); err != nil {
+				return fmt.Errorf("invalid value: %w", err)
+			}
+			want.SetBytes(content)
+		}
+	}
+	if want.Cmp(s.Value.ToInt()) != 0 {
+		return fmt.Errorf("value %v does not match proven value %v", s.Value.ToInt(), want)
+	}
+	return nil
+}
+
+// proofDB returns the hex encoded proof nodes keyed by hash.
+func proofDB(nodes []string) (kaidb.KeyValueReader, error) {
+	db := memorydb.New()
+	for _, n := range nodes {
+		enc, err := common.Decode(n)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proof node: %w", err)
+		}
+		if err := db.Put(crypto.Keccak256(enc), enc); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
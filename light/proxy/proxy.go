@@ -0,0 +1,120 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package proxy serves the RPC of a full node through a light client, which
+// verifies every answer before returning it.
+//
+// Headers, commits and validator sets are verified by the light client.
+// Account balances, nonces, code and storage are verified with merkle proofs
+// against the app hash of a verified header. Transactions are forwarded to the
+// full node as is.
+package proxy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/light"
+	"github.com/kardiachain/go-kardia/rpc"
+)
+
+// Proxy is an RPC server answering with data verified by a light client.
+type Proxy struct {
+	logger  log.Logger
+	client  *light.Client
+	primary *rpc.Client
+	server  *rpc.Server
+	http    *http.Server
+}
+
+// New returns a proxy verifying the answers of primary with client.
+func New(logger log.Logger, client *light.Client, primary *rpc.Client) (*Proxy, error) {
+	p := &Proxy{
+		logger:  logger.New("module", "proxy"),
+		client:  client,
+		primary: primary,
+		server:  rpc.NewServer(),
+	}
+	for _, api := range p.APIs() {
+		if err := p.server.RegisterName(api.Namespace, api.Service); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// APIs returns the RPC services of the proxy.
+func (p *Proxy) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "kai",
+			Version:   "1.0",
+			Service:   &PublicKaiAPI{p},
+			Public:    true,
+		},
+		{
+			Namespace: "account",
+			Version:   "1.0",
+			Service:   &PublicAccountAPI{p},
+			Public:    true,
+		},
+		{
+			Namespace: "tx",
+			Version:   "1.0",
+			Service:   &PublicTransactionAPI{p},
+			Public:    true,
+		},
+	}
+}
+
+// ListenAndServe serves the RPC over HTTP on addr until Stop is called.
+func (p *Proxy) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	p.http = &http.Server{
+		Handler:     p.server,
+		ReadTimeout: rpc.DefaultHTTPTimeouts.ReadTimeout,
+		IdleTimeout: rpc.DefaultHTTPTimeouts.IdleTimeout,
+	}
+	p.logger.Info("Light client proxy started", "addr", listener.Addr())
+	if err := p.http.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Stop stops serving the RPC.
+func (p *Proxy) Stop() error {
+	if p.http != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := p.http.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	p.server.Stop()
+	return nil
+}
+
+// Server returns the RPC server of the proxy.
+func (p *Proxy) Server() *rpc.Server { return p.server }
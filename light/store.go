@@ -0,0 +1,109 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package light
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+)
+
+var (
+	// headerPrefix + height (uint64 big endian) -> signed header
+	headerPrefix = []byte("lc/h/")
+	// latestKey -> height of the latest trusted header
+	latestKey = []byte("lc/latest")
+)
+
+// store persists verified headers. Headers verified backwards are stored
+// without commit.
+type store struct {
+	db kaidb.Database
+}
+
+func (s *store) save(sh *SignedHeader) error {
+	data, err := json.Marshal(sh)
+	if err != nil {
+		return err
+	}
+	batch := s.db.NewBatch()
+	if err := batch.Put(headerKey(sh.Height()), data); err != nil {
+		return err
+	}
+	if latest, ok := s.latestHeight(); !ok || sh.Height() > latest {
+		if err := batch.Put(latestKey, encodeHeight(sh.Height())); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+func (s *store) get(height uint64) (*SignedHeader, error) {
+	data, _ := s.db.Get(headerKey(height))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return decodeSignedHeader(data)
+}
+
+func (s *store) latest() (*SignedHeader, error) {
+	height, ok := s.latestHeight()
+	if !ok {
+		return nil, nil
+	}
+	return s.get(height)
+}
+
+func (s *store) latestHeight() (uint64, bool) {
+	data, _ := s.db.Get(latestKey)
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// firstAbove returns the lowest stored header above height.
+func (s *store) firstAbove(height uint64) (*SignedHeader, error) {
+	it := s.db.NewIterator(headerPrefix, encodeHeight(height+1))
+	defer it.Release()
+	if !it.Next() {
+		return nil, it.Error()
+	}
+	return decodeSignedHeader(it.Value())
+}
+
+func decodeSignedHeader(data []byte) (*SignedHeader, error) {
+	var sh SignedHeader
+	if err := json.Unmarshal(data, &sh); err != nil {
+		return nil, fmt.Errorf("invalid stored header: %w", err)
+	}
+	return &sh, nil
+}
+
+func headerKey(height uint64) []byte {
+	return append(append([]byte{}, headerPrefix...), encodeHeight(height)...)
+}
+
+func encodeHeight(height uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, height)
+	return enc
+}
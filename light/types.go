@@ -0,0 +1,89 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package light
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// SignedHeader is a header with the commit of the validators who signed it.
+type SignedHeader struct {
+	Header *types.Header `json:"header"`
+	Commit *types.Commit `json:"commit"`
+}
+
+// Height returns the height of the header.
+func (sh *SignedHeader) Height() uint64 { return sh.Header.Height }
+
+// Hash returns the hash of the header.
+func (sh *SignedHeader) Hash() common.Hash { return sh.Header.Hash() }
+
+// ValidateBasic checks that the commit is a well formed commit of the header.
+// It does not verify the signatures of the commit.
+func (sh *SignedHeader) ValidateBasic() error {
+	if sh.Header == nil {
+		return errors.New("missing header")
+	}
+	if sh.Commit == nil {
+		return errors.New("missing commit")
+	}
+	if err := sh.Commit.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid commit: %w", err)
+	}
+	if sh.Commit.Height != sh.Header.Height {
+		return fmt.Errorf("header and commit height mismatch: %d vs %d", sh.Header.Height, sh.Commit.Height)
+	}
+	if hash := sh.Header.Hash(); !sh.Commit.BlockID.Hash.Equal(hash) {
+		return fmt.Errorf("commit signs block %v, header is block %v", sh.Commit.BlockID.Hash.Hex(), hash.Hex())
+	}
+	return nil
+}
+
+// TrustOptions are the header a client starts from, obtained from a source
+// the user trusts such as a block explorer or a validator, and how long
+// headers are trusted.
+type TrustOptions struct {
+	// Period is the time during which a verified header can be trusted to
+	// verify new headers. It must be shorter than the unbonding period of
+	// validators, so that validators who sign conflicting headers can still
+	// be slashed.
+	Period time.Duration
+	// Height and Hash identify the trusted header.
+	Height uint64
+	Hash   common.Hash
+}
+
+// ValidateBasic checks the trust options are set.
+func (opts TrustOptions) ValidateBasic() error {
+	if opts.Period <= 0 {
+		return errors.New("trusting period must be positive")
+	}
+	if opts.Height == 0 {
+		return errors.New("trusted height must be positive")
+	}
+	if opts.Hash.IsZero() {
+		return errors.New("trusted hash is empty")
+	}
+	return nil
+}
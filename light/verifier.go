@@ -0,0 +1,150 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package light
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// maxClockDrift is how far in the future a header time may be.
+const maxClockDrift = 10 * time.Second
+
+// ErrOldHeaderExpired means the latest trusted header is older than the
+// trusting period, so the client must be started again from a new trusted
+// header.
+type ErrOldHeaderExpired struct {
+	At  time.Time
+	Now time.Time
+}
+
+func (e ErrOldHeaderExpired) Error() string {
+	return fmt.Sprintf("old header has expired at %v (now: %v)", e.At, e.Now)
+}
+
+// ErrInvalidHeader means a header provided by the primary failed
+// verification.
+type ErrInvalidHeader struct {
+	Reason error
+}
+
+func (e ErrInvalidHeader) Error() string {
+	return fmt.Sprintf("invalid header: %v", e.Reason)
+}
+
+func (e ErrInvalidHeader) Unwrap() error { return e.Reason }
+
+// HeaderExpired returns true if the header was signed more than
+// trustingPeriod ago.
+func HeaderExpired(h *types.Header, trustingPeriod time.Duration, now time.Time) bool {
+	return !h.Time.Add(trustingPeriod).After(now)
+}
+
+// VerifyAdjacent verifies the header following trusted, signed by vals.
+//
+// The header must be linked to trusted, its validators must be the next
+// validators of trusted, and more than 2/3 of their voting power must have
+// signed it.
+func VerifyAdjacent(
+	chainID string,
+	trusted *SignedHeader,
+	untrusted *SignedHeader,
+	vals *types.ValidatorSet,
+	trustingPeriod time.Duration,
+	now time.Time,
+) error {
+	if untrusted.Height() != trusted.Height()+1 {
+		return errors.New("headers must be adjacent in height")
+	}
+	if HeaderExpired(trusted.Header, trustingPeriod, now) {
+		return ErrOldHeaderExpired{trusted.Header.Time.Add(trustingPeriod), now}
+	}
+	if err := verifyNewHeaderAndVals(untrusted, vals, trusted.Header, now); err != nil {
+		return ErrInvalidHeader{err}
+	}
+	if !untrusted.Header.LastBlockID.Hash.Equal(trusted.Hash()) {
+		return ErrInvalidHeader{fmt.Errorf("header %d does not follow trusted header %v",
+			untrusted.Height(), trusted.Hash().Hex())}
+	}
+	if !untrusted.Header.ValidatorsHash.Equal(trusted.Header.NextValidatorsHash) {
+		return ErrInvalidHeader{fmt.Errorf("validators %v of header %d are not the next validators %v of the trusted header",
+			untrusted.Header.ValidatorsHash.Hex(), untrusted.Height(), trusted.Header.NextValidatorsHash.Hex())}
+	}
+	if err := vals.VerifyCommit(chainID, untrusted.Commit.BlockID, untrusted.Height(), untrusted.Commit); err != nil {
+		return ErrInvalidHeader{err}
+	}
+	return nil
+}
+
+// VerifyBackwards verifies the header preceding trusted, which commits to
+// its hash.
+func VerifyBackwards(untrusted, trusted *types.Header) error {
+	if untrusted.Height+1 != trusted.Height {
+		return errors.New("headers must be adjacent in height")
+	}
+	if !untrusted.Time.Before(trusted.Time) {
+		return ErrInvalidHeader{fmt.Errorf("header %d time %v is not before trusted header time %v",
+			untrusted.Height, untrusted.Time, trusted.Time)}
+	}
+	if hash := untrusted.Hash(); !trusted.LastBlockID.Hash.Equal(hash) {
+		return ErrInvalidHeader{fmt.Errorf("header %d hash %v does not match trusted header last block %v",
+			untrusted.Height, hash.Hex(), trusted.LastBlockID.Hash.Hex())}
+	}
+	return nil
+}
+
+// VerifyValidators checks that vals are the validators of header.
+func VerifyValidators(header *types.Header, vals *types.ValidatorSet) error {
+	if vals == nil {
+		return errors.New("missing validator set")
+	}
+	if hash := vals.Hash(); !header.ValidatorsHash.Equal(hash) {
+		return fmt.Errorf("validators hash %v does not match header %d validators hash %v",
+			hash.Hex(), header.Height, header.ValidatorsHash.Hex())
+	}
+	return nil
+}
+
+// VerifyCommit checks that commit is a commit of header by its validators.
+func VerifyCommit(chainID string, header *types.Header, vals *types.ValidatorSet, commit *types.Commit) error {
+	sh := &SignedHeader{Header: header, Commit: commit}
+	if err := sh.ValidateBasic(); err != nil {
+		return err
+	}
+	if err := VerifyValidators(header, vals); err != nil {
+		return err
+	}
+	return vals.VerifyCommit(chainID, commit.BlockID, header.Height, commit)
+}
+
+func verifyNewHeaderAndVals(untrusted *SignedHeader, vals *types.ValidatorSet, trusted *types.Header, now time.Time) error {
+	if err := untrusted.ValidateBasic(); err != nil {
+		return err
+	}
+	if !untrusted.Header.Time.After(trusted.Time) {
+		return fmt.Errorf("header time %v is not after trusted header time %v", untrusted.Header.Time, trusted.Time)
+	}
+	if untrusted.Header.Time.After(now.Add(maxClockDrift)) {
+		return fmt.Errorf("header time %v is in the future (now: %v)", untrusted.Header.Time, now)
+	}
+	return VerifyValidators(untrusted.Header, vals)
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/crypto"
 	"github.com/kardiachain/go-kardia/lib/log"
+	kquery "github.com/kardiachain/go-kardia/lib/pubsub/query"
 	"github.com/kardiachain/go-kardia/lib/rlp"
 	"github.com/kardiachain/go-kardia/rpc"
 	"github.com/kardiachain/go-kardia/types"
@@ -331,6 +332,168 @@ func (s *PublicKaiAPI) Validators(ctx context.Context, isGetDelegators bool) ([]
 	return validators, nil
 }
 
+type UnbondingEntry struct {
+	Amount         string `json:"amount"`
+	CompletionTime uint64 `json:"completionTime"`
+}
+
+type Delegation struct {
+	SmcAddress       string            `json:"smcAddress"`
+	StakedAmount     string            `json:"stakedAmount"`
+	Reward           string            `json:"reward"`
+	UnbondingEntries []*UnbondingEntry `json:"unbondingEntries"`
+}
+
+// Delegations returns the delegations of an address to all validators,
+// including amounts still unbonding
+func (s *PublicKaiAPI) Delegations(ctx context.Context, delAddr common.Address) ([]*Delegation, error) {
+	delegations, err := s.kaiService.GetDelegationsByDelegator(delAddr)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Delegation, len(delegations))
+	for i, d := range delegations {
+		entries := make([]*UnbondingEntry, len(d.UnbondingEntries))
+		for j, e := range d.UnbondingEntries {
+			entries[j] = &UnbondingEntry{
+				Amount:         e.Amount.String(),
+				CompletionTime: e.CompletionTime.Uint64(),
+			}
+		}
+		result[i] = &Delegation{
+			SmcAddress:       d.ValStakingSmc.Hex(),
+			StakedAmount:     d.StakedAmount.String(),
+			Reward:           d.Reward.String(),
+			UnbondingEntries: entries,
+		}
+	}
+	return result, nil
+}
+
+// TotalBonded returns the amount bonded by all validators and delegators
+func (s *PublicKaiAPI) TotalBonded(ctx context.Context) (string, error) {
+	total, err := s.kaiService.GetTotalBonded()
+	if err != nil {
+		return "", err
+	}
+	return total.String(), nil
+}
+
+// MissedBlocks returns the number of blocks missed by a validator in the
+// current signed block window, and the window itself
+func (s *PublicKaiAPI) MissedBlocks(ctx context.Context, valAddr common.Address) (*MissedBlocks, error) {
+	window, err := s.kaiService.GetMissedBlocks(valAddr)
+	if err != nil {
+		return nil, err
+	}
+	missed := &MissedBlocks{Window: window}
+	for _, m := range window {
+		if m {
+			missed.Count++
+		}
+	}
+	return missed, nil
+}
+
+type MissedBlocks struct {
+	Count  uint64 `json:"count"`
+	Window []bool `json:"window"`
+}
+
+// SlashingParams returns the params of downtime and double sign slashing
+func (s *PublicKaiAPI) SlashingParams(ctx context.Context) (*SlashingParams, error) {
+	params, err := s.kaiService.GetSlashingParams()
+	if err != nil {
+		return nil, err
+	}
+	return &SlashingParams{
+		SignedBlockWindow:       params.SignedBlockWindow.Uint64(),
+		MinSignedPerWindow:      params.MinSignedPerWindow.String(),
+		DowntimeJailDuration:    params.DowntimeJailDuration.Uint64(),
+		SlashFractionDowntime:   params.SlashFractionDowntime.String(),
+		SlashFractionDoubleSign: params.SlashFractionDoubleSign.String(),
+	}, nil
+}
+
+type SlashingParams struct {
+	SignedBlockWindow       uint64 `json:"signedBlockWindow"`
+	MinSignedPerWindow      string `json:"minSignedPerWindow"`
+	DowntimeJailDuration    uint64 `json:"downtimeJailDuration"`
+	SlashFractionDowntime   string `json:"slashFractionDowntime"`
+	SlashFractionDoubleSign string `json:"slashFractionDoubleSign"`
+}
+
+// BlockSearchResult is a page of the blocks matching a query
+type BlockSearchResult struct {
+	Blocks []*SearchedBlock `json:"blocks"`
+	Total  int              `json:"total"`
+}
+
+type SearchedBlock struct {
+	Height uint64              `json:"height"`
+	Hash   string              `json:"hash"`
+	Events map[string][]string `json:"events"`
+}
+
+// BlockSearch returns a page of the blocks whose events match query, e.g.
+// "block.proposer='0x…' AND block.height>100". Pages start at 1.
+func (s *PublicKaiAPI) BlockSearch(ctx context.Context, query string, page, perPage int) (*BlockSearchResult, error) {
+	q, err := kquery.New(query)
+	if err != nil {
+		return nil, err
+	}
+	results, total, err := s.kaiService.eventIndexer.SearchBlocks(ctx, q, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]*SearchedBlock, 0, len(results))
+	for _, r := range results {
+		blocks = append(blocks, &SearchedBlock{
+			Height: r.Height,
+			Hash:   s.kaiService.blockchain.DB().ReadCanonicalHash(r.Height).Hex(),
+			Events: r.Events,
+		})
+	}
+	return &BlockSearchResult{Blocks: blocks, Total: total}, nil
+}
+
+// Proposal returns the governance proposal with the given id
+func (s *PublicKaiAPI) Proposal(ctx context.Context, id uint64) (*Proposal, error) {
+	p, err := s.kaiService.GetProposal(id)
+	if err != nil {
+		return nil, err
+	}
+	proposal := &Proposal{
+		ID:                p.ID,
+		Proposer:          p.Proposer.Hex(),
+		Changes:           p.Changes,
+		Status:            p.Status.String(),
+		TotalDeposit:      p.TotalDeposit.String(),
+		Votes:             make(map[string]string, len(p.Votes)),
+		SubmitHeight:      p.SubmitHeight,
+		VotingStartHeight: p.VotingStartHeight,
+		VotingEndHeight:   p.VotingEndHeight,
+		ActivationHeight:  p.ActivationHeight,
+	}
+	for _, v := range p.Votes {
+		proposal.Votes[v.Voter.Hex()] = v.Option.String()
+	}
+	return proposal, nil
+}
+
+type Proposal struct {
+	ID                uint64                       `json:"id"`
+	Proposer          string                       `json:"proposer"`
+	Changes           []types.ConsensusParamChange `json:"changes"`
+	Status            string                       `json:"status"`
+	TotalDeposit      string                       `json:"totalDeposit"`
+	Votes             map[string]string            `json:"votes"`
+	SubmitHeight      uint64                       `json:"submitHeight"`
+	VotingStartHeight uint64                       `json:"votingStartHeight"`
+	VotingEndHeight   uint64                       `json:"votingEndHeight"`
+	ActivationHeight  uint64                       `json:"activationHeight"`
+}
+
 type PublicTransaction struct {
 	BlockHash        string       `json:"blockHash"`
 	BlockHeight      uint64       `json:"blockNumber"`
@@ -571,6 +734,67 @@ func (a *PublicTransactionAPI) GetTransaction(hash string) (*PublicTransaction,
 	return publicTx, nil
 }
 
+// GetTransactionByHash gets transaction by transaction hash
+func (a *PublicTransactionAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) (*PublicTransaction, error) {
+	return a.GetTransaction(hash.Hex())
+}
+
+// txsPerPage is the number of transactions returned per page by
+// GetTransactionsByAddress.
+const txsPerPage = 20
+
+// GetTransactionsByAddress returns a page of the transactions sent or received
+// by addr, latest first. Pages start at 0.
+func (a *PublicTransactionAPI) GetTransactionsByAddress(ctx context.Context, addr common.Address, page uint64) ([]*PublicTransaction, error) {
+	hashes := a.s.kaiDb.ReadAddressTxHashes(addr, int(page)*txsPerPage, txsPerPage)
+	transactions := make([]*PublicTransaction, 0, len(hashes))
+	for _, hash := range hashes {
+		tx, blockHash, height, index := a.s.kaiDb.ReadTransaction(hash)
+		if tx == nil {
+			continue
+		}
+		publicTx := NewPublicTransaction(a.s.Config(), tx, blockHash, height, index)
+		if header := a.s.blockchain.GetHeaderByHeight(height); header != nil {
+			publicTx.Time = header.Time
+		}
+		transactions = append(transactions, publicTx)
+	}
+	return transactions, nil
+}
+
+// TxSearchResult is a page of the transactions matching a query
+type TxSearchResult struct {
+	Txs   []*PublicTransaction `json:"txs"`
+	Total int                  `json:"total"`
+}
+
+// Search returns a page of the transactions whose events match query, e.g.
+// "transfer.to='0x…' AND tx.height>100". Addresses and hashes are matched as
+// lower case hex. Pages start at 1.
+func (a *PublicTransactionAPI) Search(ctx context.Context, query string, page, perPage int) (*TxSearchResult, error) {
+	q, err := kquery.New(query)
+	if err != nil {
+		return nil, err
+	}
+	results, total, err := a.s.eventIndexer.SearchTxs(ctx, q, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*PublicTransaction, 0, len(results))
+	for _, r := range results {
+		tx, blockHash, height, index := a.s.kaiDb.ReadTransaction(r.Hash)
+		if tx == nil {
+			continue
+		}
+		publicTx := NewPublicTransaction(a.s.Config(), tx, blockHash, height, index)
+		if header := a.s.blockchain.GetHeaderByHeight(height); header != nil {
+			publicTx.Time = header.Time
+		}
+		txs = append(txs, publicTx)
+	}
+	return &TxSearchResult{Txs: txs, Total: total}, nil
+}
+
 // getReceiptLogs gets logs from receipt
 func getReceiptLogs(receipt types.Receipt) []Log {
 	if receipt.Logs != nil {
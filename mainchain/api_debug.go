@@ -0,0 +1,46 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kai
+
+import (
+	"github.com/kardiachain/go-kardia/consensus"
+	cstypes "github.com/kardiachain/go-kardia/consensus/types"
+)
+
+// PublicDebugAPI exposes internal node state to help diagnosing issues.
+type PublicDebugAPI struct {
+	kaiService *KardiaService
+}
+
+// NewPublicDebugAPI creates a new debug API.
+func NewPublicDebugAPI(kaiService *KardiaService) *PublicDebugAPI {
+	return &PublicDebugAPI{kaiService}
+}
+
+// DumpConsensusState returns the current round state, including the votes
+// received from each validator, along with the round state of every peer.
+func (s *PublicDebugAPI) DumpConsensusState() *consensus.ConsensusStateDump {
+	return s.kaiService.csManager.DumpConsensusState()
+}
+
+// ConsensusState returns the current round state, including the votes
+// received from each validator.
+func (s *PublicDebugAPI) ConsensusState() *cstypes.RoundStateDump {
+	return s.kaiService.csManager.RoundState()
+}
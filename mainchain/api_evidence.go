@@ -0,0 +1,65 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kai
+
+import (
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
+	"github.com/kardiachain/go-kardia/types"
+	"github.com/kardiachain/go-kardia/types/evidence"
+)
+
+// PublicEvidenceAPI offers an API to submit evidence of byzantine behavior and
+// to report on the evidence pool.
+type PublicEvidenceAPI struct {
+	kaiService *KardiaService
+}
+
+// NewPublicEvidenceAPI creates a new evidence API.
+func NewPublicEvidenceAPI(kaiService *KardiaService) *PublicEvidenceAPI {
+	return &PublicEvidenceAPI{kaiService}
+}
+
+// BroadcastEvidence verifies the proto encoded evidence, submitted by tools
+// monitoring the validators or light clients, adds it to the pool and gossips
+// it to peers. It returns the hash of the evidence.
+func (s *PublicEvidenceAPI) BroadcastEvidence(input common.Bytes) (common.Hash, error) {
+	var evp kproto.Evidence
+	if err := evp.Unmarshal(input); err != nil {
+		return common.Hash{}, fmt.Errorf("error decoding evidence: %w", err)
+	}
+	ev, err := types.EvidenceFromProto(&evp)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid evidence: %w", err)
+	}
+	if err := ev.ValidateBasic(); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid evidence: %w", err)
+	}
+	if err := s.kaiService.evPool.AddEvidence(ev); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to add evidence: %w", err)
+	}
+	return ev.Hash(), nil
+}
+
+// Status returns the number, size and age of the pending evidence.
+func (s *PublicEvidenceAPI) Status() (evidence.Stats, error) {
+	return s.kaiService.evPool.Stats()
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/event"
 	"github.com/kardiachain/go-kardia/mainchain/blockchain"
+	"github.com/kardiachain/go-kardia/mainchain/gov"
 	vm "github.com/kardiachain/go-kardia/mainchain/kvm"
 	"github.com/kardiachain/go-kardia/mainchain/staking"
 	"github.com/kardiachain/go-kardia/rpc"
@@ -271,6 +272,93 @@ func (k *KardiaService) GetDelegationsByValidator(valContractAddr common.Address
 	return k.validator.GetDelegators(st, header, k.blockchain, kvmConfig, valContractAddr)
 }
 
+// GetDelegationsByDelegator returns the delegations of delAddr to all validators,
+// with their pending rewards and unbonding entries
+func (k *KardiaService) GetDelegationsByDelegator(delAddr common.Address) ([]*staking.Delegation, error) {
+	block := k.blockchain.CurrentBlock()
+	st, header, kvmConfig, err := k.getValidatorInfoParams(block)
+	if err != nil {
+		return nil, err
+	}
+	valContractAddrs, err := k.staking.GetValidatorsByDelegator(st, header, k.blockchain, kvmConfig, delAddr)
+	if err != nil {
+		return nil, err
+	}
+	delegations := make([]*staking.Delegation, 0, len(valContractAddrs))
+	for _, valContractAddr := range valContractAddrs {
+		stakedAmount, err := k.validator.GetDelegatorStakedAmount(st, header, k.blockchain, kvmConfig, valContractAddr, delAddr)
+		if err != nil {
+			return nil, err
+		}
+		reward, err := k.validator.GetDelegationRewards(st, header, k.blockchain, kvmConfig, valContractAddr, delAddr)
+		if err != nil {
+			return nil, err
+		}
+		ubdEntries, err := k.validator.GetUBDEntries(st, header, k.blockchain, kvmConfig, valContractAddr, delAddr)
+		if err != nil {
+			return nil, err
+		}
+		delegations = append(delegations, &staking.Delegation{
+			ValStakingSmc:    valContractAddr,
+			StakedAmount:     stakedAmount,
+			Reward:           reward,
+			UnbondingEntries: ubdEntries,
+		})
+	}
+	return delegations, nil
+}
+
+// GetTotalBonded returns the amount currently bonded on the staking contract
+func (k *KardiaService) GetTotalBonded() (*big.Int, error) {
+	block := k.blockchain.CurrentBlock()
+	st, header, kvmConfig, err := k.getValidatorInfoParams(block)
+	if err != nil {
+		return nil, err
+	}
+	return k.staking.GetTotalBonded(st, header, k.blockchain, kvmConfig)
+}
+
+// GetMissedBlocks returns the sliding window of blocks missed by the validator
+// valAddr, used to decide whether it is jailed for downtime
+func (k *KardiaService) GetMissedBlocks(valAddr common.Address) ([]bool, error) {
+	block := k.blockchain.CurrentBlock()
+	st, header, kvmConfig, err := k.getValidatorInfoParams(block)
+	if err != nil {
+		return nil, err
+	}
+	valContractAddr, err := k.staking.GetValFromOwner(st, header, k.blockchain, kvmConfig, valAddr)
+	if err != nil {
+		return nil, err
+	}
+	return k.validator.GetMissedBlocks(st, header, k.blockchain, kvmConfig, valContractAddr)
+}
+
+// GetSlashingParams returns the downtime and double sign slashing params
+func (k *KardiaService) GetSlashingParams() (*staking.SlashingParams, error) {
+	block := k.blockchain.CurrentBlock()
+	st, header, kvmConfig, err := k.getValidatorInfoParams(block)
+	if err != nil {
+		return nil, err
+	}
+	return k.params.GetSlashingParams(st, header, k.blockchain, kvmConfig)
+}
+
+// GetProposal returns the governance proposal with the given id
+func (k *KardiaService) GetProposal(id uint64) (*gov.Proposal, error) {
+	st, err := k.blockchain.State()
+	if err != nil {
+		return nil, err
+	}
+	proposal, err := gov.GetProposal(st, id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal == nil {
+		return nil, gov.ErrUnknownProposal
+	}
+	return proposal, nil
+}
+
 // getValidatorInfoParams returns params for getting validators info on
 // staking and validator contract
 func (k *KardiaService) getValidatorInfoParams(block *types.Block) (*state.StateDB, *types.Header, kvm.Config, error) {
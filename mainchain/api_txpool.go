@@ -74,6 +74,13 @@ func (s *PublicTxPoolAPI) Status() map[string]common.Uint {
 	}
 }
 
+// GasPrice returns the minimum gas price of the remote transactions accepted by
+// the pool. When the pool is full, the lowest priced transactions are evicted
+// first.
+func (s *PublicTxPoolAPI) GasPrice() *common.Big {
+	return (*common.Big)(s.kaiService.TxPool().GasPrice())
+}
+
 // Inspect retrieves the content of the transaction pool and flattens it into an
 // easily inspectable list.
 func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
@@ -23,10 +23,12 @@ import (
 	"time"
 
 	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/indexer"
 	"github.com/kardiachain/go-kardia/kai/state/cstate"
 	"github.com/kardiachain/go-kardia/kvm"
 	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/mainchain/gov"
 	"github.com/kardiachain/go-kardia/mainchain/staking"
 	"github.com/kardiachain/go-kardia/mainchain/staking/misc"
 	stypes "github.com/kardiachain/go-kardia/mainchain/staking/types"
@@ -52,15 +54,35 @@ type BlockOperations struct {
 	blockchain *BlockChain
 	txPool     *tx_pool.TxPool
 	evPool     EvidencePool
-	base       uint64
 	height     uint64
 	staking    *staking.StakingSmcUtil
+	gov        *gov.Keeper
+	indexer    indexer.EventIndexer
+
+	// Committed blocks are indexed in the background.
+	indexQueue chan indexJob
+	indexQuit  chan struct{}
+	indexWg    sync.WaitGroup
 
 	proposalBlock *proposalBlock
 }
 
+// indexQueueSize is the number of committed blocks which can wait to be
+// indexed before commits block on the indexer.
+const indexQueueSize = 64
+
+// indexJob is a committed block whose events are waiting to be indexed.
+type indexJob struct {
+	block    *types.Block
+	receipts types.Receipts
+}
+
 // NewBlockOperations returns a new BlockOperations with reference to the latest state of blockchain.
-func NewBlockOperations(logger log.Logger, blockchain *BlockChain, txPool *tx_pool.TxPool, evpool EvidencePool, staking *staking.StakingSmcUtil) *BlockOperations {
+func NewBlockOperations(logger log.Logger, blockchain *BlockChain, txPool *tx_pool.TxPool, evpool EvidencePool, staking *staking.StakingSmcUtil) (*BlockOperations, error) {
+	govKeeper, err := gov.NewKeeper(logger, gov.DefaultParams())
+	if err != nil {
+		return nil, err
+	}
 	return &BlockOperations{
 		logger:        logger,
 		blockchain:    blockchain,
@@ -68,15 +90,53 @@ func NewBlockOperations(logger log.Logger, blockchain *BlockChain, txPool *tx_po
 		height:        blockchain.CurrentBlock().Height(),
 		evPool:        evpool,
 		staking:       staking,
+		gov:           govKeeper,
 		proposalBlock: &proposalBlock{},
+	}, nil
+}
+
+// SetEventIndexer sets the indexer of the events emitted by committed blocks
+// and their transactions, and starts indexing them in the background.
+func (bo *BlockOperations) SetEventIndexer(idx indexer.EventIndexer) {
+	bo.indexer = idx
+	bo.indexQueue = make(chan indexJob, indexQueueSize)
+	bo.indexQuit = make(chan struct{})
+	bo.indexWg.Add(1)
+	go bo.indexLoop()
+}
+
+// StopEventIndexer indexes the blocks already committed and stops indexing.
+func (bo *BlockOperations) StopEventIndexer() {
+	if bo.indexer == nil {
+		return
 	}
+	close(bo.indexQuit)
+	bo.indexWg.Wait()
 }
 
-// Base returns the first known contiguous block height, or 0 for empty block stores.
+func (bo *BlockOperations) indexLoop() {
+	defer bo.indexWg.Done()
+	for {
+		select {
+		case job := <-bo.indexQueue:
+			bo.indexEvents(job.block, job.receipts)
+		case <-bo.indexQuit:
+			for {
+				select {
+				case job := <-bo.indexQueue:
+					bo.indexEvents(job.block, job.receipts)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Base returns the first known contiguous block height, or 0 for empty or
+// never pruned block stores.
 func (bo *BlockOperations) Base() uint64 {
-	bo.mtx.RLock()
-	defer bo.mtx.RUnlock()
-	return bo.base
+	return bo.blockchain.Base()
 }
 
 func (bo *BlockOperations) Config() *configs.ChainConfig {
@@ -148,8 +208,16 @@ func (bo *BlockOperations) CommitAndValidateBlockTxs(block *types.Block, lastCom
 	bo.saveBlockInfo(blockInfo, block)
 	bo.blockchain.DB().WriteHeadBlockHash(block.Hash())
 	bo.blockchain.DB().WriteTxLookupEntries(block)
+	height := block.Height()
+	bo.blockchain.DB().WriteAddressTxIndex(block, types.MakeSigner(bo.blockchain.chainConfig, &height))
 	bo.blockchain.DB().WriteAppHash(block.Height(), root)
 	bo.blockchain.InsertHeadBlock(block)
+	if bo.indexer != nil {
+		select {
+		case bo.indexQueue <- indexJob{block: block, receipts: blockInfo.Receipts}:
+		case <-bo.indexQuit:
+		}
+	}
 
 	// send logs of emitted events to logs feed for collecting
 	var logs []*types.Log
@@ -161,6 +229,35 @@ func (bo *BlockOperations) CommitAndValidateBlockTxs(block *types.Block, lastCom
 	return vals, root, nil
 }
 
+// indexEvents indexes the events emitted by block and its transactions.
+func (bo *BlockOperations) indexEvents(block *types.Block, receipts types.Receipts) {
+	byHash := make(map[common.Hash]*types.Receipt, len(receipts))
+	for _, r := range receipts {
+		byHash[r.TxHash] = r
+	}
+	height := block.Height()
+	signer := types.MakeSigner(bo.blockchain.chainConfig, &height)
+	results := make([]*indexer.TxResult, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			continue
+		}
+		results = append(results, &indexer.TxResult{
+			Hash:   tx.Hash(),
+			Height: height,
+			Index:  uint32(i),
+			Events: indexer.TxEvents(tx, byHash[tx.Hash()], from, height),
+		})
+	}
+	if err := bo.indexer.IndexTxs(results); err != nil {
+		bo.logger.Error("Failed to index txs", "height", height, "err", err)
+	}
+	if err := bo.indexer.IndexBlock(&indexer.BlockResult{Height: height, Events: indexer.BlockEvents(block)}); err != nil {
+		bo.logger.Error("Failed to index block", "height", height, "err", err)
+	}
+}
+
 // CommitBlockTxsIfNotFound executes and commits block txs if the block state root is not found in storage.
 // Proposer and validators should already commit the block txs, so this function prevents double tx execution.
 func (bo *BlockOperations) CommitBlockTxsIfNotFound(block *types.Block, lastCommit stypes.LastCommitInfo, byzVals []stypes.Evidence) ([]*types.Validator, common.Hash, error) {
@@ -230,6 +327,16 @@ func (bo *BlockOperations) LoadSeenCommit(height uint64) *types.Commit {
 	return commit
 }
 
+// ConsensusParamUpdates returns the consensus param changes activated by
+// governance at the given height.
+func (bo *BlockOperations) ConsensusParamUpdates(height uint64) ([]types.ConsensusParamChange, error) {
+	st, err := bo.blockchain.StateAt(height)
+	if err != nil {
+		return nil, err
+	}
+	return gov.ParamChangesAt(st, height)
+}
+
 // newHeader creates new block header from given data.
 // Some header fields are not ready at this point.
 func (bo *BlockOperations) newHeader(time time.Time, height uint64, numTxs uint64, blockID types.BlockID,
@@ -300,6 +407,8 @@ func (bo *BlockOperations) commitBlock(txs types.Transactions, header *types.Hea
 		return nil, common.Hash{}, nil, err
 	}
 
+	signer := types.MakeSigner(bo.blockchain.chainConfig, &header.Height)
+	isGovernance := bo.blockchain.chainConfig.IsGovernance(&header.Height)
 LOOP:
 	for i, tx := range txs {
 		state.Prepare(tx.Hash(), header.Hash(), i)
@@ -310,10 +419,24 @@ LOOP:
 			state.RevertToSnapshot(snap)
 			continue LOOP
 		}
+		if isGovernance && tx.To() != nil && *tx.To() == gov.Address && receipt.Status == types.ReceiptStatusSuccessful {
+			// The sender was already recovered by ApplyTransaction.
+			sender, _ := types.Sender(signer, tx)
+			if err := bo.gov.DeliverTx(state, header.Height, sender, tx.Value(), tx.Data()); err != nil {
+				bo.logger.Info("Invalid governance transaction", "tx", tx.Hash().Hex(), "err", err)
+			}
+		}
 		i++
 		receipts = append(receipts, receipt)
 	}
 
+	if isGovernance {
+		if err := bo.gov.EndBlock(state, header.Height, lastCommit); err != nil {
+			bo.logger.Error("Fail to end governance block", "err", err)
+			return nil, common.Hash{}, nil, err
+		}
+	}
+
 	vals, err := bo.staking.ApplyAndReturnValidatorSets(state, header, bo.blockchain, kvmConfig)
 	if err != nil {
 		return nil, common.Hash{}, nil, err
@@ -28,6 +28,7 @@ import (
 	"github.com/kardiachain/go-kardia/configs"
 	"github.com/kardiachain/go-kardia/kai/events"
 	"github.com/kardiachain/go-kardia/kai/state"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
 	"github.com/kardiachain/go-kardia/kvm"
 	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/event"
@@ -52,7 +53,7 @@ type BlockChain struct {
 	db types.StoreDB // Blockchain database
 	hc *HeaderChain
 
-	chainHeadFeed event.Feed
+	chainHeadFeed events.ChainHeadFeed
 	logsFeed      event.Feed
 	scope         event.SubscriptionScope
 
@@ -70,6 +71,8 @@ type BlockChain struct {
 
 	processor *StateProcessor // block processor
 	vmConfig  kvm.Config      // vm configurations
+
+	commitHook func(root common.Hash, commit func() error) error // wraps the commits of state tries, see SetTrieCommitHook
 }
 
 func (bc *BlockChain) P2P() *configs.P2PConfig {
@@ -106,6 +109,12 @@ func (bc *BlockChain) DB() types.StoreDB {
 	return bc.db
 }
 
+// Base returns the lowest height whose block is kept, 0 if the block store has
+// never been pruned.
+func (bc *BlockChain) Base() uint64 {
+	return kvstore.ReadBlockStoreBase(bc.db.DB())
+}
+
 // Config retrieves the blockchain's chain configuration.
 func (bc *BlockChain) Config() *configs.ChainConfig { return bc.chainConfig }
 
@@ -257,6 +266,17 @@ func (bc *BlockChain) loadLastState() error {
 	return nil
 }
 
+// Reload reloads the head of the chain from the database, after it was
+// written behind the back of the blockchain such as by a state sync.
+func (bc *BlockChain) Reload() error {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	bc.blockCache.Purge()
+	bc.futureBlocks.Purge()
+	return bc.loadLastState()
+}
+
 // Reset purges the entire blockchain, restoring it to its genesis state.
 func (bc *BlockChain) Reset() error {
 	return bc.ResetWithGenesisBlock(bc.genesisBlock)
@@ -392,9 +412,19 @@ func (bc *BlockChain) WriteBlockInfo(block *types.Block, blockInfo *types.BlockI
 // CommitTrie commits trie node such as statedb forcefully to disk.
 func (bc *BlockChain) CommitTrie(root common.Hash) error {
 	triedb := bc.stateCache.TrieDB()
+	if bc.commitHook != nil {
+		return bc.commitHook(root, func() error { return triedb.Commit(root, false) })
+	}
 	return triedb.Commit(root, false)
 }
 
+// SetTrieCommitHook sets the hook wrapping the commits of state tries to disk,
+// which the state pruner uses to keep the tries committed while it prunes. It
+// must be set before blocks are committed.
+func (bc *BlockChain) SetTrieCommitHook(hook func(root common.Hash, commit func() error) error) {
+	bc.commitHook = hook
+}
+
 // insert injects a new head block into the current block chain. This method
 // assumes that the block is indeed a true head. It will also reset the head
 // header to this very same block if they are older
@@ -0,0 +1,47 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kai
+
+import (
+	"os"
+
+	bcReactor "github.com/kardiachain/go-kardia/blockchain"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/mainchain/blockchain"
+)
+
+// importChain imports the blocks of the chain file on top of state and
+// returns the state after them. A failed import keeps the blocks applied so
+// far, it resumes from them when the node is restarted with the same file.
+func (s *KardiaService) importChain(file string, state cstate.LatestBlockState, blockExec *cstate.BlockExecutor,
+	bOper *blockchain.BlockOperations) (cstate.LatestBlockState, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return state, err
+	}
+	defer f.Close()
+	s.logger.Info("Importing chain file", "file", file, "height", state.LastBlockHeight)
+	state, imported, err := bcReactor.ImportChain(f, state, blockExec, bOper)
+	if err != nil {
+		s.logger.Error("Failed to import chain file", "file", file, "imported", imported, "err", err)
+		return state, err
+	}
+	s.logger.Info("Imported chain file", "file", file, "imported", imported, "height", state.LastBlockHeight)
+	return state, nil
+}
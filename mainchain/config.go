@@ -22,6 +22,7 @@ import (
 	"math/big"
 
 	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/chainfeed"
 	"github.com/kardiachain/go-kardia/kai/storage"
 	"github.com/kardiachain/go-kardia/mainchain/genesis"
 	"github.com/kardiachain/go-kardia/mainchain/oracles"
@@ -65,5 +66,30 @@ type Config struct {
 
 	FastSync *configs.FastSyncConfig
 
+	// StateSync restores the state of an empty node from a snapshot of its
+	// peers, and sets up the snapshots served to them.
+	StateSync *configs.StateSyncConfig
+
+	// ImportChain is a chain file whose blocks are imported at startup,
+	// before syncing with peers.
+	ImportChain string
+
+	// Evidence sets how long the committed evidence is kept.
+	Evidence *configs.EvidenceConfig
+
+	// State sets how long the validators and consensus params records are kept.
+	State *configs.StateConfig
+
+	// Compaction sets how often the stores of the chain database are compacted.
+	Compaction *configs.CompactionConfig
+
+	// Pruning sets which blocks and states are kept.
+	Pruning *configs.PruningConfig
+
+	// TraceIndex indexes the call traces of committed blocks if set.
+	TraceIndex *configs.TraceIndexConfig
+
 	GasOracle *oracles.Config
+
+	ChainFeed *chainfeed.Config
 }
@@ -24,7 +24,6 @@ import (
 	"sort"
 	"time"
 
-	mapset "github.com/deckarep/golang-set"
 	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/log"
 	"github.com/kardiachain/go-kardia/lib/mclock"
@@ -56,6 +55,10 @@ const (
 	// re-request them.
 	maxTxUnderpricedSetSize = 32768
 
+	// txUnderpricedSetTTL is the time after which an underpriced transaction
+	// may be requested again, as the gas price of the pool may have dropped.
+	txUnderpricedSetTTL = 10 * time.Minute
+
 	// txArriveTimeout is the time allowance before an announced transaction is
 	// explicitly requested.
 	txArriveTimeout = 500 * time.Millisecond
@@ -151,7 +154,7 @@ type TxFetcher struct {
 	drop    chan *txDrop
 	quit    chan struct{}
 
-	underpriced mapset.Set // Transactions discarded as too cheap (don't re-fetch)
+	underpriced *common.LRUSet // Transactions discarded as too cheap (don't re-fetch)
 
 	// Stage 1: Waiting lists for newly discovered transactions that might be
 	// broadcast without needing explicit request/reply round trips.
@@ -205,7 +208,7 @@ func NewTxFetcherForTests(
 		fetching:    make(map[common.Hash]string),
 		requests:    make(map[string]*txRequest),
 		alternates:  make(map[common.Hash]map[string]struct{}),
-		underpriced: mapset.NewSet(),
+		underpriced: common.NewLRUSet(maxTxUnderpricedSetSize, txUnderpricedSetTTL),
 		hasTx:       hasTx,
 		addTxs:      addTxs,
 		fetchTxs:    fetchTxs,
@@ -285,9 +288,6 @@ func (f *TxFetcher) Enqueue(peer string, txs []*types.Transaction, direct bool)
 		// Avoid re-request this transaction when we receive another
 		// announcement.
 		if errors.Is(err, ErrUnderpriced) || errors.Is(err, ErrReplaceUnderpriced) {
-			for f.underpriced.Cardinality() >= maxTxUnderpricedSetSize {
-				f.underpriced.Pop()
-			}
 			f.underpriced.Add(txs[i].Hash())
 		}
 		// Track a few interesting failure types
@@ -0,0 +1,186 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package genesis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	kaiproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
+)
+
+// Builder builds a genesis specification programmatically. Its setters may
+// be chained, and Build validates the result.
+type Builder struct {
+	genesis *Genesis
+}
+
+// NewBuilder returns a builder of a genesis for chainID, with the default
+// gas limit and consensus parameters.
+func NewBuilder(chainID string, config *configs.ChainConfig) *Builder {
+	return &Builder{genesis: &Genesis{
+		ChainID:         chainID,
+		InitialHeight:   1,
+		Config:          config,
+		Timestamp:       time.Now().UTC(),
+		GasLimit:        configs.BlockGasLimit,
+		Alloc:           make(GenesisAlloc),
+		ConsensusParams: configs.DefaultConsensusParams(),
+		Consensus:       configs.DefaultConsensusConfig(),
+	}}
+}
+
+// Timestamp sets the time of the genesis block.
+func (b *Builder) Timestamp(t time.Time) *Builder {
+	b.genesis.Timestamp = t.UTC()
+	return b
+}
+
+// GasLimit sets the gas limit of the genesis block.
+func (b *Builder) GasLimit(gasLimit uint64) *Builder {
+	b.genesis.GasLimit = gasLimit
+	return b
+}
+
+// InitialHeight sets the height of the genesis block.
+func (b *Builder) InitialHeight(height uint64) *Builder {
+	b.genesis.InitialHeight = height
+	return b
+}
+
+// ConsensusParams sets the consensus parameters.
+func (b *Builder) ConsensusParams(params *kaiproto.ConsensusParams) *Builder {
+	b.genesis.ConsensusParams = params
+	return b
+}
+
+// Fund adds balance to the genesis balance of addr.
+func (b *Builder) Fund(addr common.Address, balance *big.Int) *Builder {
+	account := b.genesis.Alloc[addr]
+	if account.Balance == nil {
+		account.Balance = new(big.Int)
+	}
+	account.Balance = new(big.Int).Add(account.Balance, balance)
+	b.genesis.Alloc[addr] = account
+	return b
+}
+
+// Contract deploys code at addr in the genesis state.
+func (b *Builder) Contract(addr common.Address, code []byte, balance *big.Int) *Builder {
+	b.Fund(addr, balance)
+	account := b.genesis.Alloc[addr]
+	account.Code = common.CopyBytes(code)
+	b.genesis.Alloc[addr] = account
+	return b
+}
+
+// Validator appends validators to the genesis validators.
+func (b *Builder) Validator(vals ...*GenesisValidator) *Builder {
+	b.genesis.Validators = append(b.genesis.Validators, vals...)
+	return b
+}
+
+// Build returns the genesis, or an error if it does not validate.
+func (b *Builder) Build() (*Genesis, error) {
+	if err := b.genesis.Validate(); err != nil {
+		return nil, err
+	}
+	return b.genesis, nil
+}
+
+// CanonicalJSON returns the canonical JSON encoding of the genesis: alloc
+// entries are sorted by address, validators keep their order and the
+// timestamp is in UTC.
+func (g *Genesis) CanonicalJSON() ([]byte, error) {
+	canonical := *g
+	canonical.Timestamp = g.Timestamp.UTC()
+	return json.Marshal(&canonical)
+}
+
+// Hash returns the keccak256 hash of the canonical JSON encoding of the
+// genesis, which identifies the document. It is not the hash of the genesis
+// block.
+func (g *Genesis) Hash() (common.Hash, error) {
+	enc, err := g.CanonicalJSON()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(enc), nil
+}
+
+// MergeValidators appends validators to the genesis, such as those submitted
+// by the operators of the initial validators. Every validator must be valid
+// and its address unused by the other validators.
+func (g *Genesis) MergeValidators(vals ...*GenesisValidator) error {
+	seen := make(map[common.Address]bool, len(g.Validators)+len(vals))
+	for _, val := range g.Validators {
+		seen[common.HexToAddress(val.Address)] = true
+	}
+	for _, val := range vals {
+		if err := val.Validate(); err != nil {
+			return fmt.Errorf("validator %s: %w", val.Name, err)
+		}
+		addr := common.HexToAddress(val.Address)
+		if seen[addr] {
+			return fmt.Errorf("%w %v", ErrDuplicateValidator, addr.Hex())
+		}
+		seen[addr] = true
+	}
+	g.Validators = append(g.Validators, vals...)
+	return nil
+}
+
+// CollectValidators reads the validators in the JSON files of dir, one per
+// file, in the order of their file names.
+func CollectValidators(dir string) ([]*GenesisValidator, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+			names = append(names, file.Name())
+		}
+	}
+	sort.Strings(names)
+
+	vals := make([]*GenesisValidator, 0, len(names))
+	for _, name := range names {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		val := new(GenesisValidator)
+		if err := json.Unmarshal(data, val); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		vals = append(vals, val)
+	}
+	return vals, nil
+}
@@ -0,0 +1,133 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package genesis
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/lib/common"
+	kmath "github.com/kardiachain/go-kardia/lib/math"
+)
+
+func testValidator(name string, addr common.Address, selfDelegate *big.Int) *GenesisValidator {
+	return &GenesisValidator{
+		Name:             name,
+		Address:          addr.Hex(),
+		CommissionRate:   "100000000000000000",
+		MaxRate:          "250000000000000000",
+		MaxChangeRate:    "50000000000000000",
+		SelfDelegate:     selfDelegate.String(),
+		StartWithGenesis: true,
+	}
+}
+
+func testBuilder() *Builder {
+	stake := ToCell(1000000)
+	return NewBuilder("kai-test", configs.TestnetChainConfig).
+		Timestamp(time.Unix(1600000000, 0)).
+		Fund(common.HexToAddress("0x01"), stake).
+		Fund(common.HexToAddress("0x02"), stake).
+		Validator(testValidator("val1", common.HexToAddress("0x01"), stake))
+}
+
+func TestBuilderValidate(t *testing.T) {
+	g, err := testBuilder().Build()
+	require.NoError(t, err)
+	require.Len(t, g.Validators, 1)
+
+	_, err = testBuilder().Validator(testValidator("val1bis", common.HexToAddress("0x01"), ToCell(1))).Build()
+	require.ErrorIs(t, err, ErrDuplicateValidator)
+
+	_, err = testBuilder().Validator(testValidator("val2", common.HexToAddress("0x02"), big.NewInt(1e9))).Build()
+	require.ErrorIs(t, err, ErrZeroPower)
+
+	_, err = testBuilder().Validator(testValidator("val2", common.HexToAddress("0x02"), ToCell(2000000))).Build()
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = testBuilder().Fund(common.HexToAddress("0x03"), kmath.MaxBig256).Build()
+	require.ErrorIs(t, err, ErrAllocOverflow)
+
+	val := testValidator("val2", common.HexToAddress("0x02"), ToCell(1000))
+	val.CommissionRate = "300000000000000000"
+	_, err = testBuilder().Validator(val).Build()
+	require.Error(t, err)
+}
+
+func TestGenesisHash(t *testing.T) {
+	g1, err := testBuilder().Build()
+	require.NoError(t, err)
+	g2, err := testBuilder().Build()
+	require.NoError(t, err)
+	g2.Timestamp = g2.Timestamp.In(time.FixedZone("UTC+7", 7*3600))
+
+	h1, err := g1.Hash()
+	require.NoError(t, err)
+	h2, err := g2.Hash()
+	require.NoError(t, err)
+	require.Equal(t, h1, h2)
+
+	enc, err := g1.CanonicalJSON()
+	require.NoError(t, err)
+	var decoded Genesis
+	require.NoError(t, json.Unmarshal(enc, &decoded))
+	h3, err := decoded.Hash()
+	require.NoError(t, err)
+	require.Equal(t, h1, h3)
+
+	g2.GasLimit++
+	h2, err = g2.Hash()
+	require.NoError(t, err)
+	require.NotEqual(t, h1, h2)
+}
+
+func TestCollectValidators(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kai-gentx-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for i, name := range []string{"b", "a"} {
+		addr := common.BigToAddress(big.NewInt(int64(i + 2)))
+		enc, err := json.Marshal(testValidator(name, addr, ToCell(1000)))
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name+".json"), enc, 0644))
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README"), []byte("ignored"), 0644))
+
+	vals, err := CollectValidators(dir)
+	require.NoError(t, err)
+	require.Len(t, vals, 2)
+	require.Equal(t, "a", vals[0].Name)
+	require.Equal(t, "b", vals[1].Name)
+
+	g, err := testBuilder().Build()
+	require.NoError(t, err)
+	require.NoError(t, g.MergeValidators(vals...))
+	require.Len(t, g.Validators, 3)
+	require.ErrorIs(t, g.MergeValidators(vals[0]), ErrDuplicateValidator)
+	require.Len(t, g.Validators, 3)
+}
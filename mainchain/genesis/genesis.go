@@ -57,6 +57,7 @@ type GenesisValidator struct {
 	MaxChangeRate    string `json:"maxChangeRate" yaml:"MaxChangeRate"`
 	SelfDelegate     string `json:"selfDelegate" yaml:"SelfDelegate"`
 	StartWithGenesis bool   `json:"startWithGenesis" yaml:"StartWithGenesis"`
+	BLSPubKey        string `json:"blsPubKey,omitempty" yaml:"BLSPubKey,omitempty"` // optional, hex encoded
 	Delegators       []*struct {
 		Address string `json:"address" yaml:"Address"`
 		Amount  string `json:"amount" yaml:"Amount"`
@@ -0,0 +1,165 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package genesis
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/lib/common"
+	kmath "github.com/kardiachain/go-kardia/lib/math"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// maxValidatorNameLength is the length of the name field of the staking
+// contract.
+const maxValidatorNameLength = 32
+
+var (
+	// ErrDuplicateValidator is returned when two validators share an address.
+	ErrDuplicateValidator = errors.New("duplicate validator")
+	// ErrZeroPower is returned when a validator starting with genesis has no
+	// voting power.
+	ErrZeroPower = errors.New("validator has zero voting power")
+	// ErrAllocOverflow is returned when the allocated balances do not fit in
+	// 256 bits.
+	ErrAllocOverflow = errors.New("genesis alloc overflows 256 bits")
+	// ErrInsufficientFunds is returned when an account delegates more than
+	// its allocated balance.
+	ErrInsufficientFunds = errors.New("insufficient funds for genesis delegations")
+
+	// rateDenominator is the denominator of commission rates, which are
+	// fixed point numbers with 18 decimals.
+	rateDenominator = big.NewInt(1e18)
+)
+
+// Validate checks that the genesis specification is consistent: it must have
+// a chain id and config, its alloc must fit in 256 bits, validators must be
+// unique with valid rates and voting power, and their self delegations and
+// delegations must be funded by the alloc.
+func (g *Genesis) Validate() error {
+	if g.ChainID == "" {
+		return errors.New("genesis has no chain id")
+	}
+	if g.Config == nil {
+		return errGenesisNoConfig
+	}
+	if g.ConsensusParams != nil {
+		if err := types.ValidateConsensusParams(*g.ConsensusParams); err != nil {
+			return fmt.Errorf("invalid consensus params: %w", err)
+		}
+	}
+
+	total := new(big.Int)
+	for addr, account := range g.Alloc {
+		if account.Balance == nil || account.Balance.Sign() < 0 {
+			return fmt.Errorf("invalid balance of %v", addr.Hex())
+		}
+		total.Add(total, account.Balance)
+		if total.Cmp(kmath.MaxBig256) > 0 {
+			return ErrAllocOverflow
+		}
+	}
+
+	var (
+		seen  = make(map[common.Address]bool, len(g.Validators))
+		spent = make(map[common.Address]*big.Int)
+	)
+	spend := func(addr common.Address, amount *big.Int) {
+		if spent[addr] == nil {
+			spent[addr] = new(big.Int)
+		}
+		spent[addr].Add(spent[addr], amount)
+	}
+	for i, val := range g.Validators {
+		if err := val.Validate(); err != nil {
+			return fmt.Errorf("validator %d (%s): %w", i, val.Name, err)
+		}
+		addr := common.HexToAddress(val.Address)
+		if seen[addr] {
+			return fmt.Errorf("validator %d (%s): %w %v", i, val.Name, ErrDuplicateValidator, addr.Hex())
+		}
+		seen[addr] = true
+
+		selfDelegate, _ := new(big.Int).SetString(val.SelfDelegate, 10)
+		spend(addr, selfDelegate)
+		for _, del := range val.Delegators {
+			amount, _ := new(big.Int).SetString(del.Amount, 10)
+			spend(common.HexToAddress(del.Address), amount)
+		}
+	}
+	for addr, amount := range spent {
+		balance := new(big.Int)
+		if account, ok := g.Alloc[addr]; ok {
+			balance = account.Balance
+		}
+		if balance.Cmp(amount) < 0 {
+			return fmt.Errorf("%w: %v delegates %v but has %v", ErrInsufficientFunds, addr.Hex(), amount, balance)
+		}
+	}
+	return nil
+}
+
+// Validate checks that a validator has a valid address, name and rates, and
+// that it has voting power if it starts with genesis.
+func (val *GenesisValidator) Validate() error {
+	if !common.IsHexAddress(val.Address) {
+		return fmt.Errorf("invalid address %q", val.Address)
+	}
+	if val.Name == "" || len(val.Name) > maxValidatorNameLength {
+		return fmt.Errorf("name must have 1 to %d bytes", maxValidatorNameLength)
+	}
+	rates := make([]*big.Int, 3)
+	for i, rate := range []string{val.CommissionRate, val.MaxRate, val.MaxChangeRate} {
+		n, ok := new(big.Int).SetString(rate, 10)
+		if !ok || n.Sign() < 0 {
+			return fmt.Errorf("invalid rate %q", rate)
+		}
+		rates[i] = n
+	}
+	commission, maxRate, maxChangeRate := rates[0], rates[1], rates[2]
+	switch {
+	case maxRate.Cmp(rateDenominator) > 0:
+		return fmt.Errorf("max rate %v is above 100%%", maxRate)
+	case commission.Cmp(maxRate) > 0:
+		return fmt.Errorf("commission rate %v is above the max rate %v", commission, maxRate)
+	case maxChangeRate.Cmp(maxRate) > 0:
+		return fmt.Errorf("max change rate %v is above the max rate %v", maxChangeRate, maxRate)
+	}
+
+	selfDelegate, ok := new(big.Int).SetString(val.SelfDelegate, 10)
+	if !ok || selfDelegate.Sign() < 0 {
+		return fmt.Errorf("invalid self delegation %q", val.SelfDelegate)
+	}
+	if val.StartWithGenesis && new(big.Int).Div(selfDelegate, configs.PowerReduction).Sign() == 0 {
+		return ErrZeroPower
+	}
+	for _, del := range val.Delegators {
+		if del == nil || !common.IsHexAddress(del.Address) {
+			return errors.New("invalid delegator address")
+		}
+		amount, ok := new(big.Int).SetString(del.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			return fmt.Errorf("invalid delegation %q of %s", del.Amount, del.Address)
+		}
+	}
+	return nil
+}
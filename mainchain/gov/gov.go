@@ -0,0 +1,250 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package gov implements on-chain governance of the consensus params.
+//
+// Governance actions are plain transactions sent to Address, with the RLP
+// encoded Action as data and the deposit, if any, as value. A proposal enters
+// its voting period once its deposits reach Params.MinDeposit, and must be
+// submitted with at least Params.MinInitialDeposit. Validators then
+// vote with the voting power they had in the last commit. When the voting
+// period ends the proposal is tallied, and the param changes of a passed
+// proposal are applied to the consensus params at its activation height.
+// Governance transactions are processed from the GovernanceBlock of the chain
+// config on.
+package gov
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// Address is the address governance transactions are sent to. Deposits are
+// held in its balance.
+var Address = common.HexToAddress("0x0000000000000000000000000000000000001339")
+
+var (
+	ErrUnknownAction    = errors.New("unknown governance action")
+	ErrNoParamChanges   = errors.New("proposal has no param changes")
+	ErrUnknownProposal  = errors.New("unknown proposal")
+	ErrInvalidVote      = errors.New("invalid vote option")
+	ErrNotDepositPeriod = errors.New("proposal is not in deposit period")
+	ErrNotVotingPeriod  = errors.New("proposal is not in voting period")
+	ErrVoteWithValue    = errors.New("votes must not transfer value")
+	ErrInitialDeposit   = errors.New("initial deposit is below the minimum")
+)
+
+// ActionType is the type of a governance action.
+type ActionType uint8
+
+const (
+	ActionSubmitProposal ActionType = iota + 1
+	ActionDeposit
+	ActionVote
+)
+
+// Action is the data of a governance transaction.
+type Action struct {
+	Type       ActionType
+	ProposalID uint64
+	Changes    []types.ConsensusParamChange
+	Option     VoteOption
+}
+
+// EncodeSubmitProposal returns the data of a transaction proposing changes.
+func EncodeSubmitProposal(changes []types.ConsensusParamChange) ([]byte, error) {
+	return rlp.EncodeToBytes(&Action{Type: ActionSubmitProposal, Changes: changes})
+}
+
+// EncodeDeposit returns the data of a transaction depositing on a proposal.
+func EncodeDeposit(proposalID uint64) ([]byte, error) {
+	return rlp.EncodeToBytes(&Action{Type: ActionDeposit, ProposalID: proposalID})
+}
+
+// EncodeVote returns the data of a transaction voting on a proposal.
+func EncodeVote(proposalID uint64, option VoteOption) ([]byte, error) {
+	return rlp.EncodeToBytes(&Action{Type: ActionVote, ProposalID: proposalID, Option: option})
+}
+
+// DecodeAction decodes the data of a governance transaction.
+func DecodeAction(data []byte) (*Action, error) {
+	var action Action
+	if err := rlp.DecodeBytes(data, &action); err != nil {
+		return nil, fmt.Errorf("invalid governance action: %w", err)
+	}
+	return &action, nil
+}
+
+// VoteOption is a vote on a proposal.
+type VoteOption uint8
+
+const (
+	VoteYes VoteOption = iota + 1
+	VoteNo
+	VoteAbstain
+)
+
+func (o VoteOption) String() string {
+	switch o {
+	case VoteYes:
+		return "yes"
+	case VoteNo:
+		return "no"
+	case VoteAbstain:
+		return "abstain"
+	default:
+		return "unknown"
+	}
+}
+
+// ProposalStatus is the stage of a proposal.
+type ProposalStatus uint8
+
+const (
+	StatusDepositPeriod ProposalStatus = iota + 1
+	StatusVotingPeriod
+	StatusPassed
+	StatusRejected
+)
+
+func (s ProposalStatus) String() string {
+	switch s {
+	case StatusDepositPeriod:
+		return "deposit_period"
+	case StatusVotingPeriod:
+		return "voting_period"
+	case StatusPassed:
+		return "passed"
+	case StatusRejected:
+		return "rejected"
+	default:
+		return "unknown"
+	}
+}
+
+// Deposit is an amount deposited on a proposal.
+type Deposit struct {
+	Depositor common.Address
+	Amount    *big.Int
+}
+
+// Vote is the vote of an address on a proposal.
+type Vote struct {
+	Voter  common.Address
+	Option VoteOption
+}
+
+// Proposal is a proposal to change consensus params.
+type Proposal struct {
+	ID       uint64
+	Proposer common.Address
+	Changes  []types.ConsensusParamChange
+	Status   ProposalStatus
+
+	Deposits     []Deposit
+	TotalDeposit *big.Int
+	Votes        []Vote
+
+	SubmitHeight      uint64
+	VotingStartHeight uint64
+	VotingEndHeight   uint64
+	ActivationHeight  uint64
+}
+
+// addDeposit records amount deposited by depositor.
+func (p *Proposal) addDeposit(depositor common.Address, amount *big.Int) {
+	p.TotalDeposit = new(big.Int).Add(p.TotalDeposit, amount)
+	for i := range p.Deposits {
+		if p.Deposits[i].Depositor == depositor {
+			p.Deposits[i].Amount = new(big.Int).Add(p.Deposits[i].Amount, amount)
+			return
+		}
+	}
+	p.Deposits = append(p.Deposits, Deposit{Depositor: depositor, Amount: new(big.Int).Set(amount)})
+}
+
+// setVote records the vote of voter, replacing any previous one.
+func (p *Proposal) setVote(voter common.Address, option VoteOption) {
+	for i := range p.Votes {
+		if p.Votes[i].Voter == voter {
+			p.Votes[i].Option = option
+			return
+		}
+	}
+	p.Votes = append(p.Votes, Vote{Voter: voter, Option: option})
+}
+
+// Params are the parameters of the governance process.
+type Params struct {
+	// MinDeposit is the deposit required for a proposal to enter voting.
+	MinDeposit *big.Int
+	// MinInitialDeposit is the deposit required to submit a proposal.
+	MinInitialDeposit *big.Int
+	// MaxDepositPeriod is the number of blocks a proposal can wait for
+	// deposits before its deposits are burnt.
+	MaxDepositPeriod uint64
+	// VotingPeriod is the number of blocks a proposal is voted on.
+	VotingPeriod uint64
+	// ActivationDelay is the number of blocks between the end of the voting
+	// period and the activation of passed changes.
+	ActivationDelay uint64
+	// Quorum is the percentage of voting power which must vote for the tally
+	// to be valid. Deposits of proposals without quorum are burnt.
+	Quorum uint64
+	// Threshold is the percentage of yes votes, abstentions excluded, needed
+	// for a proposal to pass.
+	Threshold uint64
+}
+
+// DefaultParams returns the default governance params.
+func DefaultParams() Params {
+	return Params{
+		MinDeposit:        new(big.Int).Mul(big.NewInt(1000000), big.NewInt(1e18)), // 1M KAI
+		MinInitialDeposit: new(big.Int).Mul(big.NewInt(100000), big.NewInt(1e18)),  // 100K KAI
+		MaxDepositPeriod:  172800,                                                  // ~2 days
+		VotingPeriod:      172800,                                                  // ~2 days
+		ActivationDelay:   100,
+		Quorum:            33,
+		Threshold:         50,
+	}
+}
+
+// ValidateBasic validates the params.
+func (p Params) ValidateBasic() error {
+	if p.MinDeposit == nil || p.MinDeposit.Sign() < 0 {
+		return errors.New("min deposit must not be negative")
+	}
+	if p.MinInitialDeposit == nil || p.MinInitialDeposit.Sign() <= 0 || p.MinInitialDeposit.Cmp(p.MinDeposit) > 0 {
+		return errors.New("min initial deposit must be positive and not exceed min deposit")
+	}
+	if p.VotingPeriod == 0 || p.MaxDepositPeriod == 0 {
+		return errors.New("deposit and voting periods must be positive")
+	}
+	if p.ActivationDelay == 0 {
+		return errors.New("activation delay must be positive")
+	}
+	if p.Quorum > 100 || p.Threshold > 100 {
+		return errors.New("quorum and threshold must be percentages")
+	}
+	return nil
+}
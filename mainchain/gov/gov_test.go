@@ -0,0 +1,194 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gov
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/kai/state"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	stypes "github.com/kardiachain/go-kardia/mainchain/staking/types"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+var (
+	val1 = common.HexToAddress("0x01")
+	val2 = common.HexToAddress("0x02")
+	val3 = common.HexToAddress("0x03")
+
+	lastCommit = stypes.LastCommitInfo{Votes: []stypes.VoteInfo{
+		{Address: val1, VotingPower: big.NewInt(10), SignedLastBlock: true},
+		{Address: val2, VotingPower: big.NewInt(10), SignedLastBlock: true},
+		{Address: val3, VotingPower: big.NewInt(10), SignedLastBlock: true},
+	}}
+	changes = []types.ConsensusParamChange{{Key: types.ParamBlockMaxGas, Value: "30000000"}}
+)
+
+func newTestKeeper(t *testing.T) (*Keeper, *state.StateDB) {
+	params := DefaultParams()
+	params.MinDeposit = big.NewInt(100)
+	params.MinInitialDeposit = big.NewInt(10)
+	params.MaxDepositPeriod = 10
+	params.VotingPeriod = 10
+	params.ActivationDelay = 5
+	k, err := NewKeeper(log.New(), params)
+	require.NoError(t, err)
+	st, err := state.New(log.New(), common.Hash{}, state.NewDatabase(memorydb.New()))
+	require.NoError(t, err)
+	return k, st
+}
+
+// deliver simulates a governance tx of value sent by from.
+func deliver(k *Keeper, st *state.StateDB, height uint64, from common.Address, value int64, data []byte) error {
+	st.SubBalance(from, big.NewInt(value))
+	st.AddBalance(Address, big.NewInt(value))
+	return k.DeliverTx(st, height, from, big.NewInt(value), data)
+}
+
+func TestProposalPasses(t *testing.T) {
+	k, st := newTestKeeper(t)
+	st.AddBalance(val1, big.NewInt(1000))
+
+	data, err := EncodeSubmitProposal(changes)
+	require.NoError(t, err)
+	require.NoError(t, deliver(k, st, 1, val1, 60, data))
+	p, err := GetProposal(st, 1)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDepositPeriod, p.Status)
+
+	data, err = EncodeDeposit(1)
+	require.NoError(t, err)
+	require.NoError(t, deliver(k, st, 2, val1, 40, data))
+	p, err = GetProposal(st, 1)
+	require.NoError(t, err)
+	assert.Equal(t, StatusVotingPeriod, p.Status)
+	assert.Equal(t, uint64(12), p.VotingEndHeight)
+	assert.Equal(t, big.NewInt(100), p.TotalDeposit)
+
+	for _, val := range []common.Address{val1, val2} {
+		data, err = EncodeVote(1, VoteYes)
+		require.NoError(t, err)
+		require.NoError(t, deliver(k, st, 3, val, 0, data))
+	}
+	data, err = EncodeVote(1, VoteNo)
+	require.NoError(t, err)
+	require.NoError(t, deliver(k, st, 3, val3, 0, data))
+
+	require.NoError(t, k.EndBlock(st, 11, lastCommit))
+	p, err = GetProposal(st, 1)
+	require.NoError(t, err)
+	assert.Equal(t, StatusVotingPeriod, p.Status)
+
+	require.NoError(t, k.EndBlock(st, 12, lastCommit))
+	p, err = GetProposal(st, 1)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPassed, p.Status)
+	assert.Equal(t, uint64(17), p.ActivationHeight)
+	// deposits are refunded
+	assert.Equal(t, big.NewInt(1000), st.GetBalance(val1))
+	assert.Equal(t, 0, st.GetBalance(Address).Sign())
+
+	scheduled, err := ParamChangesAt(st, 17)
+	require.NoError(t, err)
+	assert.Equal(t, changes, scheduled)
+	pending, err := deadlines(st, 12)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestProposalWithoutQuorum(t *testing.T) {
+	k, st := newTestKeeper(t)
+	st.AddBalance(val1, big.NewInt(1000))
+
+	data, err := EncodeSubmitProposal(changes)
+	require.NoError(t, err)
+	require.NoError(t, deliver(k, st, 1, val1, 100, data))
+	// only validators in the last commit count towards the quorum
+	data, err = EncodeVote(1, VoteYes)
+	require.NoError(t, err)
+	require.NoError(t, deliver(k, st, 2, common.HexToAddress("0x04"), 0, data))
+
+	require.NoError(t, k.EndBlock(st, 11, lastCommit))
+	p, err := GetProposal(st, 1)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRejected, p.Status)
+	// deposits are burnt
+	assert.Equal(t, big.NewInt(900), st.GetBalance(val1))
+	assert.Equal(t, 0, st.GetBalance(Address).Sign())
+	scheduled, err := ParamChangesAt(st, 16)
+	require.NoError(t, err)
+	assert.Empty(t, scheduled)
+}
+
+func TestInvalidActionsAreRefunded(t *testing.T) {
+	k, st := newTestKeeper(t)
+	st.AddBalance(val1, big.NewInt(1000))
+
+	data, err := EncodeSubmitProposal([]types.ConsensusParamChange{{Key: types.ParamBlockMaxBytes, Value: "0"}})
+	require.NoError(t, err)
+	assert.Error(t, deliver(k, st, 1, val1, 100, data))
+
+	data, err = EncodeDeposit(42)
+	require.NoError(t, err)
+	assert.Equal(t, ErrUnknownProposal, deliver(k, st, 1, val1, 100, data))
+
+	data, err = EncodeSubmitProposal(changes)
+	require.NoError(t, err)
+	assert.Equal(t, ErrInitialDeposit, deliver(k, st, 1, val1, 9, data))
+	require.NoError(t, deliver(k, st, 1, val1, 10, data))
+	data, err = EncodeVote(1, VoteYes)
+	require.NoError(t, err)
+	assert.Equal(t, ErrNotVotingPeriod, deliver(k, st, 1, val1, 0, data))
+	assert.Equal(t, ErrVoteWithValue, deliver(k, st, 1, val1, 5, data))
+
+	assert.Equal(t, big.NewInt(990), st.GetBalance(val1))
+	assert.Equal(t, big.NewInt(10), st.GetBalance(Address))
+}
+
+func TestProposalExpires(t *testing.T) {
+	k, st := newTestKeeper(t)
+	st.AddBalance(val1, big.NewInt(1000))
+
+	data, err := EncodeSubmitProposal(changes)
+	require.NoError(t, err)
+	require.NoError(t, deliver(k, st, 1, val1, 10, data))
+	pending, err := deadlines(st, 11)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1}, pending)
+
+	require.NoError(t, k.EndBlock(st, 10, lastCommit))
+	p, err := GetProposal(st, 1)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDepositPeriod, p.Status)
+
+	require.NoError(t, k.EndBlock(st, 11, lastCommit))
+	p, err = GetProposal(st, 1)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRejected, p.Status)
+	assert.Equal(t, big.NewInt(990), st.GetBalance(val1))
+	pending, err = deadlines(st, 11)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
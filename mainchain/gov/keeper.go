@@ -0,0 +1,272 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gov
+
+import (
+	"math/big"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	stypes "github.com/kardiachain/go-kardia/mainchain/staking/types"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// Keeper processes governance transactions and tallies proposals.
+type Keeper struct {
+	params Params
+	logger log.Logger
+}
+
+// NewKeeper returns a Keeper using the given params.
+func NewKeeper(logger log.Logger, params Params) (*Keeper, error) {
+	if err := params.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	return &Keeper{params: params, logger: logger}, nil
+}
+
+// Params returns the governance params.
+func (k *Keeper) Params() Params {
+	return k.params
+}
+
+// DeliverTx applies a transaction sent to Address by from, after its value has
+// been transferred. If the action is invalid the value is refunded and the
+// error returned.
+func (k *Keeper) DeliverTx(st StateDB, height uint64, from common.Address, value *big.Int, data []byte) error {
+	err := k.deliverTx(st, height, from, value, data)
+	if err != nil && value != nil && value.Sign() > 0 {
+		st.SubBalance(Address, value)
+		st.AddBalance(from, value)
+	}
+	return err
+}
+
+func (k *Keeper) deliverTx(st StateDB, height uint64, from common.Address, value *big.Int, data []byte) error {
+	if value == nil {
+		value = new(big.Int)
+	}
+	action, err := DecodeAction(data)
+	if err != nil {
+		return err
+	}
+	switch action.Type {
+	case ActionSubmitProposal:
+		return k.submitProposal(st, height, from, value, action.Changes)
+	case ActionDeposit:
+		return k.deposit(st, height, from, value, action.ProposalID)
+	case ActionVote:
+		if value.Sign() > 0 {
+			return ErrVoteWithValue
+		}
+		return k.vote(st, from, action.ProposalID, action.Option)
+	default:
+		return ErrUnknownAction
+	}
+}
+
+func (k *Keeper) submitProposal(st StateDB, height uint64, proposer common.Address, deposit *big.Int, changes []types.ConsensusParamChange) error {
+	if len(changes) == 0 {
+		return ErrNoParamChanges
+	}
+	// Reject changes which can't be applied on their own. The params may
+	// still change before activation, in which case they are checked again.
+	if _, err := types.UpdateConsensusParams(*types.DefaultConsensusParams(), changes); err != nil {
+		return err
+	}
+	if deposit.Cmp(k.params.MinInitialDeposit) < 0 {
+		return ErrInitialDeposit
+	}
+
+	p := &Proposal{
+		ID:           nextProposalID(st),
+		Proposer:     proposer,
+		Changes:      changes,
+		Status:       StatusDepositPeriod,
+		TotalDeposit: new(big.Int),
+		SubmitHeight: height,
+	}
+	p.addDeposit(proposer, deposit)
+	deadline := height + k.params.MaxDepositPeriod
+	if k.maybeStartVoting(p, height) {
+		deadline = p.VotingEndHeight
+	}
+	if err := addDeadline(st, deadline, p.ID); err != nil {
+		return err
+	}
+	if err := setProposal(st, p); err != nil {
+		return err
+	}
+	k.logger.Info("Submitted governance proposal", "id", p.ID, "proposer", proposer, "changes", changes)
+	return nil
+}
+
+func (k *Keeper) deposit(st StateDB, height uint64, depositor common.Address, amount *big.Int, id uint64) error {
+	p, err := GetProposal(st, id)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return ErrUnknownProposal
+	}
+	if p.Status != StatusDepositPeriod {
+		return ErrNotDepositPeriod
+	}
+	if amount.Sign() > 0 {
+		p.addDeposit(depositor, amount)
+	}
+	if k.maybeStartVoting(p, height) {
+		if err := addDeadline(st, p.VotingEndHeight, p.ID); err != nil {
+			return err
+		}
+	}
+	return setProposal(st, p)
+}
+
+// maybeStartVoting moves p to its voting period if its deposits are enough,
+// and reports whether it did.
+func (k *Keeper) maybeStartVoting(p *Proposal, height uint64) bool {
+	if p.Status != StatusDepositPeriod || p.TotalDeposit.Cmp(k.params.MinDeposit) < 0 {
+		return false
+	}
+	p.Status = StatusVotingPeriod
+	p.VotingStartHeight = height
+	p.VotingEndHeight = height + k.params.VotingPeriod
+	return true
+}
+
+func (k *Keeper) vote(st StateDB, voter common.Address, id uint64, option VoteOption) error {
+	if option < VoteYes || option > VoteAbstain {
+		return ErrInvalidVote
+	}
+	p, err := GetProposal(st, id)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return ErrUnknownProposal
+	}
+	if p.Status != StatusVotingPeriod {
+		return ErrNotVotingPeriod
+	}
+	p.setVote(voter, option)
+	return setProposal(st, p)
+}
+
+// EndBlock closes the deposit and voting periods ending at height. Votes are
+// weighted with the voting power of the validators in lastCommit. Only the
+// proposals with a deadline at height are loaded.
+func (k *Keeper) EndBlock(st StateDB, height uint64, lastCommit stypes.LastCommitInfo) error {
+	ids, err := deadlines(st, height)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		p, err := GetProposal(st, id)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			continue
+		}
+		switch {
+		case p.Status == StatusDepositPeriod && height >= p.SubmitHeight+k.params.MaxDepositPeriod:
+			p.Status = StatusRejected
+			k.burnDeposits(st, p)
+			k.logger.Info("Governance proposal expired without enough deposits", "id", p.ID)
+		case p.Status == StatusVotingPeriod && height >= p.VotingEndHeight:
+			if err := k.tally(st, p, height, lastCommit); err != nil {
+				return err
+			}
+		default:
+			// the deposit deadline of a proposal which entered voting
+			continue
+		}
+		if err := setProposal(st, p); err != nil {
+			return err
+		}
+	}
+	if len(ids) > 0 {
+		clearDeadlines(st, height)
+	}
+	return nil
+}
+
+// tally counts the votes of p and settles its deposits.
+func (k *Keeper) tally(st StateDB, p *Proposal, height uint64, lastCommit stypes.LastCommitInfo) error {
+	var (
+		total   = new(big.Int)
+		powers  = make(map[common.Address]*big.Int, len(lastCommit.Votes))
+		results = map[VoteOption]*big.Int{
+			VoteYes:     new(big.Int),
+			VoteNo:      new(big.Int),
+			VoteAbstain: new(big.Int),
+		}
+	)
+	for _, v := range lastCommit.Votes {
+		if v.VotingPower == nil {
+			continue
+		}
+		powers[v.Address] = v.VotingPower
+		total.Add(total, v.VotingPower)
+	}
+	voted := new(big.Int)
+	for _, v := range p.Votes {
+		power, ok := powers[v.Voter]
+		if !ok {
+			continue // only validators have voting power
+		}
+		results[v.Option].Add(results[v.Option], power)
+		voted.Add(voted, power)
+	}
+
+	// voted/total < quorum%
+	if total.Sign() == 0 || new(big.Int).Mul(voted, big.NewInt(100)).Cmp(new(big.Int).Mul(total, new(big.Int).SetUint64(k.params.Quorum))) < 0 {
+		p.Status = StatusRejected
+		k.burnDeposits(st, p)
+		k.logger.Info("Governance proposal rejected, quorum not reached", "id", p.ID, "voted", voted, "total", total)
+		return nil
+	}
+
+	k.refundDeposits(st, p)
+	// yes/(yes+no) > threshold%
+	yes, no := results[VoteYes], results[VoteNo]
+	nonAbstain := new(big.Int).Add(yes, no)
+	if nonAbstain.Sign() == 0 || new(big.Int).Mul(yes, big.NewInt(100)).Cmp(new(big.Int).Mul(nonAbstain, new(big.Int).SetUint64(k.params.Threshold))) <= 0 {
+		p.Status = StatusRejected
+		k.logger.Info("Governance proposal rejected", "id", p.ID, "yes", yes, "no", no)
+		return nil
+	}
+
+	p.Status = StatusPassed
+	p.ActivationHeight = height + k.params.ActivationDelay
+	k.logger.Info("Governance proposal passed", "id", p.ID, "activationHeight", p.ActivationHeight, "changes", p.Changes)
+	return scheduleParamChanges(st, p.ActivationHeight, p.Changes)
+}
+
+func (k *Keeper) refundDeposits(st StateDB, p *Proposal) {
+	for _, d := range p.Deposits {
+		st.SubBalance(Address, d.Amount)
+		st.AddBalance(d.Depositor, d.Amount)
+	}
+}
+
+func (k *Keeper) burnDeposits(st StateDB, p *Proposal) {
+	st.SubBalance(Address, p.TotalDeposit)
+}
@@ -0,0 +1,175 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gov
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// StateDB is the part of the state governance reads and writes.
+type StateDB interface {
+	GetState(addr common.Address, key common.Hash) common.Hash
+	SetState(addr common.Address, key, value common.Hash)
+	GetBalance(addr common.Address) *big.Int
+	AddBalance(addr common.Address, amount *big.Int)
+	SubBalance(addr common.Address, amount *big.Int)
+	GetNonce(addr common.Address) uint64
+	SetNonce(addr common.Address, nonce uint64)
+}
+
+// Governance state lives in the storage of Address. Values bigger than a slot
+// are RLP encoded and split in chunks, see loadBytes and storeBytes.
+var (
+	proposalCountKey = crypto.Keccak256Hash([]byte("proposalCount"))
+)
+
+func proposalKey(id uint64) common.Hash {
+	return crypto.Keccak256Hash([]byte("proposal"), uint64Bytes(id))
+}
+
+func deadlinesKey(height uint64) common.Hash {
+	return crypto.Keccak256Hash([]byte("deadlines"), uint64Bytes(height))
+}
+
+func paramChangesKey(height uint64) common.Hash {
+	return crypto.Keccak256Hash([]byte("paramChanges"), uint64Bytes(height))
+}
+
+func uint64Bytes(n uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	return b[:]
+}
+
+// loadBytes reads a value stored with storeBytes.
+func loadBytes(st StateDB, key common.Hash) []byte {
+	size := new(big.Int).SetBytes(st.GetState(Address, key).Bytes()).Uint64()
+	data := make([]byte, 0, size)
+	for i := uint64(0); uint64(len(data)) < size; i++ {
+		chunk := st.GetState(Address, crypto.Keccak256Hash(key.Bytes(), uint64Bytes(i)))
+		data = append(data, chunk.Bytes()...)
+	}
+	return data[:size]
+}
+
+// storeBytes writes data as its length at key followed by 32 bytes chunks.
+func storeBytes(st StateDB, key common.Hash, data []byte) {
+	// Keep the account non-empty so its storage survives state commits even
+	// when no deposits are held.
+	if st.GetNonce(Address) == 0 {
+		st.SetNonce(Address, 1)
+	}
+	st.SetState(Address, key, common.BigToHash(new(big.Int).SetUint64(uint64(len(data)))))
+	for i := 0; i*common.HashLength < len(data); i++ {
+		end := (i + 1) * common.HashLength
+		if end > len(data) {
+			end = len(data)
+		}
+		var chunk common.Hash
+		copy(chunk[:], data[i*common.HashLength:end])
+		st.SetState(Address, crypto.Keccak256Hash(key.Bytes(), uint64Bytes(uint64(i))), chunk)
+	}
+}
+
+// deleteBytes clears a value stored with storeBytes.
+func deleteBytes(st StateDB, key common.Hash) {
+	size := new(big.Int).SetBytes(st.GetState(Address, key).Bytes()).Uint64()
+	for i := uint64(0); i*common.HashLength < size; i++ {
+		st.SetState(Address, crypto.Keccak256Hash(key.Bytes(), uint64Bytes(i)), common.Hash{})
+	}
+	st.SetState(Address, key, common.Hash{})
+}
+
+func loadRLP(st StateDB, key common.Hash, val interface{}) (bool, error) {
+	data := loadBytes(st, key)
+	if len(data) == 0 {
+		return false, nil
+	}
+	return true, rlp.DecodeBytes(data, val)
+}
+
+func storeRLP(st StateDB, key common.Hash, val interface{}) error {
+	data, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return err
+	}
+	storeBytes(st, key, data)
+	return nil
+}
+
+func nextProposalID(st StateDB) uint64 {
+	id := new(big.Int).SetBytes(st.GetState(Address, proposalCountKey).Bytes()).Uint64() + 1
+	st.SetState(Address, proposalCountKey, common.BigToHash(new(big.Int).SetUint64(id)))
+	return id
+}
+
+// GetProposal returns the proposal with the given id, or nil if there is none.
+func GetProposal(st StateDB, id uint64) (*Proposal, error) {
+	var p Proposal
+	ok, err := loadRLP(st, proposalKey(id), &p)
+	if !ok || err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func setProposal(st StateDB, p *Proposal) error {
+	return storeRLP(st, proposalKey(p.ID), p)
+}
+
+// deadlines returns the ids of the proposals whose deposit or voting period
+// ends at height.
+func deadlines(st StateDB, height uint64) ([]uint64, error) {
+	var ids []uint64
+	_, err := loadRLP(st, deadlinesKey(height), &ids)
+	return ids, err
+}
+
+func addDeadline(st StateDB, height uint64, id uint64) error {
+	ids, err := deadlines(st, height)
+	if err != nil {
+		return err
+	}
+	return storeRLP(st, deadlinesKey(height), append(ids, id))
+}
+
+func clearDeadlines(st StateDB, height uint64) {
+	deleteBytes(st, deadlinesKey(height))
+}
+
+// ParamChangesAt returns the param changes activated at height.
+func ParamChangesAt(st StateDB, height uint64) ([]types.ConsensusParamChange, error) {
+	var changes []types.ConsensusParamChange
+	_, err := loadRLP(st, paramChangesKey(height), &changes)
+	return changes, err
+}
+
+func scheduleParamChanges(st StateDB, height uint64, changes []types.ConsensusParamChange) error {
+	scheduled, err := ParamChangesAt(st, height)
+	if err != nil {
+		return err
+	}
+	return storeRLP(st, paramChangesKey(height), append(scheduled, changes...))
+}
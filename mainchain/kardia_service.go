@@ -20,11 +20,16 @@
 package kai
 
 import (
+	"fmt"
+
 	bcReactor "github.com/kardiachain/go-kardia/blockchain"
 	"github.com/kardiachain/go-kardia/configs"
 	"github.com/kardiachain/go-kardia/consensus"
 	"github.com/kardiachain/go-kardia/kai/accounts"
+	"github.com/kardiachain/go-kardia/kai/chainfeed"
+	"github.com/kardiachain/go-kardia/kai/indexer"
 	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/kai/storage/maintenance"
 	"github.com/kardiachain/go-kardia/lib/bloombits"
 	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/log"
@@ -37,7 +42,9 @@ import (
 	"github.com/kardiachain/go-kardia/mainchain/tracers"
 	"github.com/kardiachain/go-kardia/mainchain/tx_pool"
 	"github.com/kardiachain/go-kardia/node"
+	"github.com/kardiachain/go-kardia/privval"
 	"github.com/kardiachain/go-kardia/rpc"
+	"github.com/kardiachain/go-kardia/statesync"
 	"github.com/kardiachain/go-kardia/types"
 	"github.com/kardiachain/go-kardia/types/evidence"
 )
@@ -68,8 +75,12 @@ type KardiaService struct {
 	blockchain *blockchain.BlockChain
 	csManager  *consensus.ConsensusManager
 	txpoolR    *tx_pool.Reactor
+	evPool     *evidence.Pool
 	evR        *evidence.Reactor
-	bcR        p2p.Reactor // for fast-syncing
+	bcR        *bcReactor.BlockchainReactor // for fast-syncing
+	stateSyncR *statesync.Reactor           // for state-syncing
+	stateSync  bool                         // whether to state sync on start
+	snapshots  *statesync.Store             // snapshots served to peers
 
 	subService KardiaSubService
 
@@ -79,6 +90,14 @@ type KardiaService struct {
 
 	staking   *staking.StakingSmcUtil
 	validator *staking.ValidatorSmcUtil
+	params    *staking.ParamsSmcUtil
+
+	eventIndexer indexer.EventIndexer
+	blockOper    *blockchain.BlockOperations
+	traceIndex   *tracers.TraceIndexService
+	chainFeed    *chainfeed.Feed
+	compactor    *maintenance.Compactor
+	pruner       *maintenance.Pruner
 
 	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer      *BloomIndexer                  // Bloom indexer operating during block imports
@@ -111,6 +130,10 @@ func newKardiaService(ctx *node.ServiceContext, config *Config) (*KardiaService,
 	if err != nil {
 		return nil, err
 	}
+	params, err := staking.NewSmcParamsUtil()
+	if err != nil {
+		return nil, err
+	}
 
 	chainConfig, _, genesisErr := genesis.SetupGenesisBlock(logger, kaiDb, config.Genesis, stakingUtil)
 	if genesisErr != nil {
@@ -138,6 +161,7 @@ func newKardiaService(ctx *node.ServiceContext, config *Config) (*KardiaService,
 		eventBus:     eventBus,
 		staking:      stakingUtil,
 		validator:    validator,
+		params:       params,
 		bloomIndexer: NewBloomIndexer(kaiDb.DB(), configs.BloomBitsBlocksClient, configs.HelperTrieConfirmations),
 	}
 
@@ -152,15 +176,54 @@ func newKardiaService(ctx *node.ServiceContext, config *Config) (*KardiaService,
 	if err != nil {
 		return nil, err
 	}
+	// The pruning manager replaces the retention of the stores it prunes
+	pruning := config.Pruning != nil && config.Pruning.Mode != maintenance.PruningArchive
+	if pruning && (config.Evidence != nil && config.Evidence.CommittedRetainBlocks > 0 ||
+		config.State != nil && config.State.RetainBlocks > 0) {
+		logger.Warn("Pruning is enabled, ignoring the evidence and state retentions", "mode", config.Pruning.Mode)
+	}
+	if config.Evidence != nil && !pruning {
+		evPool.SetCommittedRetention(config.Evidence.CommittedRetainBlocks)
+	}
+	// Local transactions are journaled in the node directory to survive restarts
+	if config.TxPool.Journal != "" {
+		config.TxPool.Journal = ctx.Config.ResolvePath(config.TxPool.Journal)
+	}
 	kai.txPool = tx_pool.NewTxPool(config.TxPool, kai.chainConfig, kai.blockchain)
 	kai.txpoolR = tx_pool.NewReactor(config.TxPool, kai.txPool)
-	kai.txpoolR.SetLogger(kai.logger)
+	kai.txpoolR.SetLogger(kai.logger.New(log.ModuleKey, "txpool"))
 
-	bOper := blockchain.NewBlockOperations(kai.logger, kai.blockchain, kai.txPool, evPool, stakingUtil)
+	bOper, err := blockchain.NewBlockOperations(kai.logger, kai.blockchain, kai.txPool, evPool, stakingUtil)
+	if err != nil {
+		return nil, err
+	}
+	kai.eventIndexer = indexer.NewKVIndexer(kaiDb.DB())
+	bOper.SetEventIndexer(kai.eventIndexer)
+	kai.blockOper = bOper
+	if config.TraceIndex != nil {
+		kai.traceIndex, err = tracers.NewTraceIndexService(logger, *config.TraceIndex, kai, kai.blockchain, indexer.NewTraceIndexer(kaiDb.DB()))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.Compaction != nil {
+		kai.compactor = maintenance.NewCompactor(kaiDb.DB(), *config.Compaction)
+		kai.compactor.SetLogger(kai.logger.New(log.ModuleKey, "compactor"))
+	}
+	if config.ChainFeed != nil {
+		kai.chainFeed, err = chainfeed.NewFromConfig(logger, *config.ChainFeed, kai.blockchain, kai.chainConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
 
+	kai.evPool = evPool
 	kai.evR = evidence.NewReactor(evPool)
-	kai.evR.SetLogger(kai.logger)
-	blockExec := cstate.NewBlockExecutor(ctx.StateDB, logger, evPool, bOper)
+	kai.evR.SetLogger(kai.logger.New(log.ModuleKey, "evidence"))
+	blockExec := cstate.NewBlockExecutor(ctx.StateDB, logger.New(log.ModuleKey, "state"), evPool, bOper)
+	if config.State != nil && !pruning {
+		blockExec.SetRetention(config.State.RetainBlocks)
+	}
 
 	state, err := ctx.StateDB.LoadStateFromDBOrGenesisDoc(config.Genesis)
 	if err != nil {
@@ -169,22 +232,83 @@ func newKardiaService(ctx *node.ServiceContext, config *Config) (*KardiaService,
 
 	// state starting configs
 	// Set private validator for consensus manager.
-	privValidator := types.NewDefaultPrivValidator(ctx.Config.NodeKey())
+	var privValidator types.PrivValidator = types.NewDefaultPrivValidator(ctx.Config.NodeKey())
+	if ctx.Config.PrivValidatorKeyFile != "" {
+		stateFile := ctx.Config.PrivValidatorStateFile
+		if stateFile == "" {
+			stateFile = privval.DefaultStateFile
+		}
+		filePV, err := privval.LoadOrGenFilePV(
+			ctx.Config.ResolvePath(ctx.Config.PrivValidatorKeyFile),
+			ctx.Config.ResolvePath(stateFile),
+			ctx.Config.PrivValidatorPassword,
+		)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Loaded file private validator", "address", filePV.GetAddress().Hex())
+		privValidator = filePV
+	}
+	if ctx.Config.PrivValidatorBLSKeyFile != "" {
+		blsKey, err := privval.LoadOrGenBLSKey(ctx.Config.ResolvePath(ctx.Config.PrivValidatorBLSKeyFile))
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Loaded BLS key", "pubKey", common.Encode(blsKey.PublicKey()))
+		privValidator = types.NewBLSPrivValidator(privValidator, blsKey)
+	}
+	if config.ImportChain != "" {
+		blockExec.SetEventBus(kai.eventBus)
+		state, err = kai.importChain(ctx.Config.ResolvePath(config.ImportChain), state, blockExec, bOper)
+		if err != nil {
+			return nil, err
+		}
+	}
 	// Determine whether we should do fast sync. This must happen after the handshake, since the
 	// app may modify the validator set, specifying ourself as the only validator.
 	config.FastSync.Enable = config.FastSync.Enable && !onlyValidatorIsUs(state, privValidator.GetAddress())
+	// Only an empty node may state sync, then it fast syncs the blocks after the snapshot.
+	kai.stateSync = config.StateSync != nil && config.StateSync.Enable && state.LastBlockHeight == 0 &&
+		!onlyValidatorIsUs(state, privValidator.GetAddress())
+	if config.StateSync != nil {
+		kai.snapshots, err = statesync.NewStore(ctx.Config.ResolvePath(config.StateSync.SnapshotDir))
+		if err != nil {
+			return nil, err
+		}
+		kai.stateSyncR = statesync.NewReactor(config.StateSync, kaiDb.DB(), kai.snapshots)
+		kai.stateSyncR.SetLogger(kai.logger.New(log.ModuleKey, "statesync"))
+	}
+	if pruning {
+		kai.pruner, err = maintenance.NewPruner(kaiDb.DB(), *config.Pruning, kai.chainConfig, ctx.StateDB, evPool)
+		if err != nil {
+			return nil, err
+		}
+		if config.Pruning.Mode == maintenance.PruningEverythingButSnapshots {
+			if config.StateSync == nil || config.StateSync.SnapshotInterval == 0 {
+				return nil, fmt.Errorf("pruning mode %v requires state sync snapshots", config.Pruning.Mode)
+			}
+			kai.pruner.SetSnapshots(kai.snapshotHeights)
+		}
+		kai.pruner.SetLogger(kai.logger.New(log.ModuleKey, "pruner"))
+		kai.blockchain.SetTrieCommitHook(kai.pruner.CommitTrie)
+	}
 	// Make BlockchainReactor. Don't start fast sync if we're doing a state sync first.
-	bcR := bcReactor.NewBlockchainReactor(state, blockExec, bOper, config.FastSync)
+	fastSync := *config.FastSync
+	fastSync.Enable = fastSync.Enable && !kai.stateSync
+	bcR := bcReactor.NewBlockchainReactor(state, blockExec, bOper, ctx.StateDB, &fastSync)
 	kai.bcR = bcR
 	consensusState := consensus.NewConsensusState(
-		kai.logger,
+		kai.logger.New(log.ModuleKey, "consensus"),
 		config.Consensus,
 		state,
 		bOper,
 		blockExec,
 		evPool,
 	)
-	kai.csManager = consensus.NewConsensusManager(consensusState, config.FastSync)
+	// Consensus waits for the state sync to switch to it as well.
+	waitSync := *config.FastSync
+	waitSync.Enable = waitSync.Enable || kai.stateSync
+	kai.csManager = consensus.NewConsensusManager(consensusState, &waitSync)
 	// Set private validator for consensus manager.
 	kai.csManager.SetPrivValidator(privValidator)
 	kai.csManager.SetEventBus(kai.eventBus)
@@ -209,7 +333,15 @@ func NewKardiaService(ctx *node.ServiceContext) (node.Service, error) {
 		AcceptTxs:   chainConfig.AcceptTxs,
 		Consensus:   chainConfig.Consensus,
 		FastSync:    chainConfig.FastSync,
+		StateSync:   chainConfig.StateSync,
+		ImportChain: chainConfig.ImportChain,
+		Evidence:    chainConfig.Evidence,
+		State:       chainConfig.State,
+		Compaction:  chainConfig.Compaction,
+		Pruning:     chainConfig.Pruning,
+		TraceIndex:  chainConfig.TraceIndex,
 		GasOracle:   chainConfig.GasOracle,
+		ChainFeed:   chainConfig.ChainFeed,
 	})
 
 	if err != nil {
@@ -236,12 +368,33 @@ func (s *KardiaService) Start(srvr *p2p.Switch) error {
 	srvr.AddReactor("CONSENSUS", s.csManager)
 	srvr.AddReactor("TXPOOL", s.txpoolR)
 	srvr.AddReactor("EVIDENCE", s.evR)
+	if s.stateSyncR != nil {
+		srvr.AddReactor("STATESYNC", s.stateSyncR)
+		if s.stateSync {
+			go s.syncState()
+		}
+		if s.config.StateSync.SnapshotInterval > 0 {
+			go s.snapshotLoop()
+		}
+	}
+	if s.chainFeed != nil {
+		s.chainFeed.Start()
+	}
+	if s.traceIndex != nil {
+		s.traceIndex.Start()
+	}
+	if s.compactor != nil {
+		s.compactor.Start()
+	}
+	if s.pruner != nil {
+		s.pruner.Start()
+	}
 	return nil
 }
 
 func createAndStartEventBus(logger log.Logger) (*types.EventBus, error) {
 	eventBus := types.NewEventBus()
-	eventBus.SetLogger(logger.New("module", "events"))
+	eventBus.SetLogger(logger.New(log.ModuleKey, "events"))
 	if err := eventBus.Start(); err != nil {
 		return nil, err
 	}
@@ -254,12 +407,29 @@ func (s *KardiaService) Stop() error {
 	if s.subService != nil {
 		s.subService.Stop()
 	}
+	if s.chainFeed != nil {
+		if err := s.chainFeed.Stop(); err != nil {
+			s.logger.Error("Failed to stop chain feed", "err", err)
+		}
+	}
+	if s.traceIndex != nil {
+		s.traceIndex.Stop()
+	}
+	s.blockOper.StopEventIndexer()
+	if s.compactor != nil {
+		s.compactor.Stop()
+	}
+	if s.pruner != nil {
+		s.pruner.Stop()
+	}
+	// Stop the pool last to close the local transaction journal
+	s.txPool.Stop()
 	close(s.shutdownChan)
 	return nil
 }
 
 func (s *KardiaService) APIs() []rpc.API {
-	return []rpc.API{
+	apis := []rpc.API{
 		{
 			Namespace: "kai",
 			Version:   "1.0",
@@ -290,6 +460,18 @@ func (s *KardiaService) APIs() []rpc.API {
 			Service:   tracers.NewTracerAPI(s),
 			Public:    true,
 		},
+		{
+			Namespace: "evidence",
+			Version:   "1.0",
+			Service:   NewPublicEvidenceAPI(s),
+			Public:    true,
+		},
+		{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   NewPublicDebugAPI(s),
+			Public:    true,
+		},
 		// Web3 endpoints support
 		{
 			Namespace: "eth",
@@ -340,6 +522,15 @@ func (s *KardiaService) APIs() []rpc.API {
 			Public:    true,
 		},
 	}
+	if s.traceIndex != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   tracers.NewTraceIndexAPI(s.traceIndex),
+			Public:    true,
+		})
+	}
+	return apis
 }
 
 func (s *KardiaService) TxPool() *tx_pool.TxPool            { return s.txPool }
@@ -37,18 +37,20 @@ const sampleNumber = 3 // Number of transactions sampled in a block
 var DefaultMaxPrice = big.NewInt(500 * configs.OXY) // max acceptable gas price is 500 OXY
 
 type Config struct {
-	Blocks     int
-	Percentile int
-	Default    *big.Int `toml:",omitempty"`
-	MaxPrice   *big.Int `toml:",omitempty"`
+	Blocks      int
+	Percentile  int
+	PoolSamples int      // Number of pending pool transactions sampled alongside blocks, 0 disables
+	Default     *big.Int `toml:",omitempty"`
+	MaxPrice    *big.Int `toml:",omitempty"`
 }
 
 func DefaultOracleConfig() *Config {
 	return &Config{
-		Blocks:     10,
-		Percentile: 10,
-		Default:    big.NewInt(1 * configs.OXY),
-		MaxPrice:   DefaultMaxPrice,
+		Blocks:      10,
+		Percentile:  10,
+		PoolSamples: 30,
+		Default:     big.NewInt(1 * configs.OXY),
+		MaxPrice:    DefaultMaxPrice,
 	}
 }
 
@@ -56,11 +58,12 @@ func DefaultOracleConfig() *Config {
 type OracleBackend interface {
 	HeaderByHeight(ctx context.Context, height rpc.BlockHeight) *types.Header
 	BlockByHeight(ctx context.Context, height rpc.BlockHeight) *types.Block
+	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
 	Config() *configs.ChainConfig
 }
 
 // Oracle recommends gas prices based on the content of recent
-// blocks. Suitable for both light and full clients.
+// blocks and the transactions currently pending in the pool.
 type Oracle struct {
 	backend   OracleBackend
 	lastHead  common.Hash
@@ -71,6 +74,7 @@ type Oracle struct {
 
 	checkBlocks int
 	percentile  int
+	poolSamples int
 }
 
 // NewGasPriceOracle returns a new gasprice oracle which can recommend suitable
@@ -90,6 +94,11 @@ func NewGasPriceOracle(backend OracleBackend, params *Config) *Oracle {
 		percent = 100
 		log.Warn("Sanitizing invalid gasprice oracle sample percentile", "provided", params.Percentile, "updated", percent)
 	}
+	samples := params.PoolSamples
+	if samples < 0 {
+		samples = 0
+		log.Warn("Sanitizing invalid gasprice oracle pool samples", "provided", params.PoolSamples, "updated", samples)
+	}
 	maxPrice := params.MaxPrice
 	if maxPrice == nil || maxPrice.Int64() <= 0 {
 		maxPrice = DefaultMaxPrice
@@ -101,6 +110,7 @@ func NewGasPriceOracle(backend OracleBackend, params *Config) *Oracle {
 		maxPrice:    maxPrice,
 		checkBlocks: blocks,
 		percentile:  percent,
+		poolSamples: samples,
 	}
 }
 
@@ -165,6 +175,8 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 		}
 		txPrices = append(txPrices, res.prices...)
 	}
+	txPrices = append(txPrices, gpo.getPoolPrices()...)
+
 	price := lastPrice
 	if len(txPrices) > 0 {
 		sort.Sort(bigIntArray(txPrices))
@@ -228,6 +240,29 @@ func (gpo *Oracle) getBlockPrices(ctx context.Context, signer types.Signer, bloc
 	}
 }
 
+// getPoolPrices samples the lowest gas prices offered by the accounts with
+// executable transactions in the pool. Only the first pending transaction of
+// each account is considered, as that's the one blocking the rest.
+func (gpo *Oracle) getPoolPrices() []*big.Int {
+	if gpo.poolSamples == 0 {
+		return nil
+	}
+	pending, _ := gpo.backend.TxPoolContent()
+
+	var prices []*big.Int
+	for _, txs := range pending {
+		if len(txs) == 0 || txs[0].GasPriceIntCmp(common.Big1) <= 0 {
+			continue
+		}
+		prices = append(prices, txs[0].GasPrice())
+	}
+	sort.Sort(bigIntArray(prices))
+	if len(prices) > gpo.poolSamples {
+		prices = prices[:gpo.poolSamples]
+	}
+	return prices
+}
+
 type bigIntArray []*big.Int
 
 func (s bigIntArray) Len() int           { return len(s) }
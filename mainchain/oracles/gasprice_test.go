@@ -17,3 +17,103 @@
  */
 
 package oracles
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/rpc"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+type testBackend struct {
+	blocks  []*types.Block
+	pending map[common.Address]types.Transactions
+}
+
+func (b *testBackend) HeaderByHeight(ctx context.Context, height rpc.BlockHeight) *types.Header {
+	if block := b.BlockByHeight(ctx, height); block != nil {
+		return block.Header()
+	}
+	return nil
+}
+
+func (b *testBackend) BlockByHeight(ctx context.Context, height rpc.BlockHeight) *types.Block {
+	if height == rpc.LatestBlockHeight {
+		return b.blocks[len(b.blocks)-1]
+	}
+	if h := int(height); h >= 0 && h < len(b.blocks) {
+		return b.blocks[h]
+	}
+	return nil
+}
+
+func (b *testBackend) TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
+	return b.pending, nil
+}
+
+func (b *testBackend) Config() *configs.ChainConfig {
+	return configs.TestChainConfig
+}
+
+func newTestBackend(t *testing.T, pooled int) *testBackend {
+	signer := types.LatestSigner(configs.TestChainConfig)
+	key, _ := crypto.GenerateKey()
+	sign := func(nonce uint64, price int64) *types.Transaction {
+		tx, err := types.SignTx(signer, types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 21000, big.NewInt(price), nil), key)
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		return tx
+	}
+	backend := &testBackend{pending: make(map[common.Address]types.Transactions)}
+	for height := uint64(0); height <= 3; height++ {
+		var txs []*types.Transaction
+		if height > 0 {
+			txs = []*types.Transaction{sign(height*3, 10), sign(height*3+1, 20), sign(height*3+2, 30)}
+		}
+		backend.blocks = append(backend.blocks, types.NewBlock(&types.Header{Height: height}, txs, nil, nil))
+	}
+	for i := 0; i < pooled; i++ {
+		key, _ := crypto.GenerateKey()
+		tx, _ := types.SignTx(signer, types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(100), nil), key)
+		backend.pending[crypto.PubkeyToAddress(key.PublicKey)] = types.Transactions{tx}
+	}
+	return backend
+}
+
+func TestSuggestPrice(t *testing.T) {
+	tests := []struct {
+		name    string
+		pooled  int
+		samples int
+		want    int64
+	}{
+		{"blocks only", 0, 5, 20},
+		{"pool disabled", 10, 0, 20},
+		{"blocks and pool", 10, 5, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Blocks:      3,
+				Percentile:  50,
+				PoolSamples: tt.samples,
+				Default:     big.NewInt(1),
+				MaxPrice:    DefaultMaxPrice,
+			}
+			oracle := NewGasPriceOracle(newTestBackend(t, tt.pooled), config)
+			price, err := oracle.SuggestPrice(context.Background())
+			if err != nil {
+				t.Fatalf("failed to suggest price: %v", err)
+			}
+			if price.Int64() != tt.want {
+				t.Fatalf("suggested price mismatch: have %v, want %v", price, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package staking
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/accounts/abi"
+	"github.com/kardiachain/go-kardia/kai/state"
+	"github.com/kardiachain/go-kardia/kvm"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	vm "github.com/kardiachain/go-kardia/mainchain/kvm"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// ParamKey is the key of a param in the params contract. Values must match
+// the ParamKey enum of Params.sol.
+type ParamKey uint8
+
+const (
+	ParamBaseProposerReward ParamKey = iota
+	ParamBonusProposerReward
+	ParamMaxProposers
+	ParamDowntimeJailDuration
+	ParamSlashFractionDowntime
+	ParamUnbondingTime
+	ParamSlashFractionDoubleSign
+	ParamSignedBlockWindow
+	ParamMinSignedPerWindow
+	ParamMinStake
+	ParamMinValidatorStake
+	ParamMinAmountChangeName
+	ParamMinSelfDelegation
+	ParamInflationRateChange
+	ParamGoalBonded
+	ParamBlocksPerYear
+	ParamInflationMax
+	ParamInflationMin
+	ParamDeposit
+	ParamVotingPeriod
+)
+
+// ParamsVoteOption is a vote on a params proposal. Values must match the
+// VoteOption enum of Params.sol.
+type ParamsVoteOption uint8
+
+const (
+	ParamsVoteAbstain ParamsVoteOption = iota
+	ParamsVoteYes
+	ParamsVoteNo
+)
+
+// SlashingParams are the params of downtime and double sign slashing.
+// Fractions are scaled by 1e18.
+type SlashingParams struct {
+	SignedBlockWindow       *big.Int `json:"signedBlockWindow"`
+	MinSignedPerWindow      *big.Int `json:"minSignedPerWindow"`
+	DowntimeJailDuration    *big.Int `json:"downtimeJailDuration"`
+	SlashFractionDowntime   *big.Int `json:"slashFractionDowntime"`
+	SlashFractionDoubleSign *big.Int `json:"slashFractionDoubleSign"`
+}
+
+// ParamsSmcUtil wraps the params contract, which holds the staking params
+// and lets validators change them through proposals.
+type ParamsSmcUtil struct {
+	Abi             *abi.ABI
+	ContractAddress common.Address
+	logger          log.Logger
+}
+
+// NewSmcParamsUtil returns a ParamsSmcUtil for the params contract.
+func NewSmcParamsUtil() (*ParamsSmcUtil, error) {
+	paramsSmcAbi := configs.GetContractABIByType(configs.ParamsContractKey)
+	abi, err := abi.JSON(strings.NewReader(paramsSmcAbi))
+	if err != nil {
+		log.Error("Error reading abi", "err", err)
+		return nil, err
+	}
+	return &ParamsSmcUtil{Abi: &abi, ContractAddress: configs.ParamsSMCAddress}, nil
+}
+
+// GetParam returns the value of the param key
+func (s *ParamsSmcUtil) GetParam(statedb *state.StateDB, header *types.Header, bc vm.ChainContext, cfg kvm.Config, key ParamKey) (*big.Int, error) {
+	return s.getUint256(statedb, header, bc, cfg, "getParam", uint8(key))
+}
+
+// GetSlashingParams returns the params of downtime and double sign slashing
+func (s *ParamsSmcUtil) GetSlashingParams(statedb *state.StateDB, header *types.Header, bc vm.ChainContext, cfg kvm.Config) (*SlashingParams, error) {
+	var (
+		params SlashingParams
+		err    error
+	)
+	if params.SignedBlockWindow, err = s.getUint256(statedb, header, bc, cfg, "getSignedBlockWindow"); err != nil {
+		return nil, err
+	}
+	if params.MinSignedPerWindow, err = s.getUint256(statedb, header, bc, cfg, "getMinSignedPerWindow"); err != nil {
+		return nil, err
+	}
+	if params.DowntimeJailDuration, err = s.getUint256(statedb, header, bc, cfg, "getDowntimeJailDuration"); err != nil {
+		return nil, err
+	}
+	if params.SlashFractionDowntime, err = s.getUint256(statedb, header, bc, cfg, "getSlashFractionDowntime"); err != nil {
+		return nil, err
+	}
+	if params.SlashFractionDoubleSign, err = s.getUint256(statedb, header, bc, cfg, "getSlashFractionDoubleSign"); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// PackAddProposal returns the data of a transaction proposing to set the
+// params keys to values. The transaction value must cover the proposal
+// deposit.
+func (s *ParamsSmcUtil) PackAddProposal(keys []ParamKey, values []*big.Int) ([]byte, error) {
+	rawKeys := make([]uint8, len(keys))
+	for i, key := range keys {
+		rawKeys[i] = uint8(key)
+	}
+	return s.Abi.Pack("addProposal", rawKeys, values)
+}
+
+// PackAddVote returns the data of a transaction voting on a params proposal
+func (s *ParamsSmcUtil) PackAddVote(proposalID *big.Int, option ParamsVoteOption) ([]byte, error) {
+	return s.Abi.Pack("addVote", proposalID, uint8(option))
+}
+
+// PackConfirmProposal returns the data of a transaction tallying a params
+// proposal whose voting period is over
+func (s *ParamsSmcUtil) PackConfirmProposal(proposalID *big.Int) ([]byte, error) {
+	return s.Abi.Pack("confirmProposal", proposalID)
+}
+
+// getUint256 calls a view method of the params contract returning a uint256
+func (s *ParamsSmcUtil) getUint256(statedb *state.StateDB, header *types.Header, bc vm.ChainContext, cfg kvm.Config, method string, args ...interface{}) (*big.Int, error) {
+	payload, err := s.Abi.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.ConstructAndApplySmcCallMsg(statedb, header, bc, cfg, payload)
+	if err != nil {
+		return nil, err
+	}
+	var value *big.Int
+	if err := s.Abi.UnpackIntoInterface(&value, method, res); err != nil {
+		log.Error("Error unpacking params", "method", method, "err", err)
+		return nil, err
+	}
+	return value, nil
+}
+
+func (s *ParamsSmcUtil) ConstructAndApplySmcCallMsg(statedb *state.StateDB, header *types.Header, bc vm.ChainContext, cfg kvm.Config, payload []byte) ([]byte, error) {
+	msg := types.NewMessage(
+		s.ContractAddress,
+		&s.ContractAddress,
+		0,
+		big.NewInt(0),
+		100000000,
+		big.NewInt(0),
+		payload,
+		false,
+	)
+	return Apply(s.logger, bc, statedb, header, cfg, msg)
+}
@@ -61,6 +61,21 @@ type Delegator struct {
 	Reward       *big.Int       `json:"reward"`
 }
 
+// Delegation is the stake of a delegator in one validator
+type Delegation struct {
+	ValStakingSmc    common.Address    `json:"valStakingSmc"`
+	StakedAmount     *big.Int          `json:"stakedAmount"`
+	Reward           *big.Int          `json:"reward"`
+	UnbondingEntries []*UnbondingEntry `json:"unbondingEntries"`
+}
+
+// UnbondingEntry is an undelegated amount which can be withdrawn once the
+// unbonding period is over.
+type UnbondingEntry struct {
+	Amount         *big.Int `json:"amount"`
+	CompletionTime *big.Int `json:"completionTime"`
+}
+
 // NewSmcStakingUtil ...
 func NewSmcStakingUtil() (*StakingSmcUtil, error) {
 	stakingSmcAbi := configs.GetContractABIByAddress(configs.DefaultStakingContractAddress)
@@ -299,6 +314,27 @@ func (s *StakingSmcUtil) GetValSmcAddr(statedb *state.StateDB, header *types.Hea
 	return valSmc.AddrValSmc, nil
 }
 
+// GetTotalBonded returns the amount bonded by all validators and delegators
+func (s *StakingSmcUtil) GetTotalBonded(statedb *state.StateDB, header *types.Header, bc vm.ChainContext, cfg kvm.Config) (*big.Int, error) {
+	payload, err := s.Abi.Pack("totalBonded")
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.ConstructAndApplySmcCallMsg(statedb, header, bc, cfg, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalBonded *big.Int
+	// unpack result
+	err = s.Abi.UnpackIntoInterface(&totalBonded, "totalBonded", res)
+	if err != nil {
+		log.Error("Error unpacking total bonded", "err", err)
+		return nil, err
+	}
+	return totalBonded, nil
+}
+
 // GetValidatorsByDelegator returns all validators to whom this delegator delegated
 func (s *StakingSmcUtil) GetValidatorsByDelegator(statedb *state.StateDB, header *types.Header, bc vm.ChainContext, cfg kvm.Config, delAddr common.Address) ([]common.Address, error) {
 	payload, err := s.Abi.Pack("getValidatorsByDelegator", delAddr)
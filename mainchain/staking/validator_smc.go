@@ -219,6 +219,34 @@ func (s *ValidatorSmcUtil) GetDelegatorStakedAmount(statedb *state.StateDB, head
 	return delegation.DelStake, nil
 }
 
+// GetUBDEntries returns the unbonding entries of a delegator
+func (s *ValidatorSmcUtil) GetUBDEntries(statedb *state.StateDB, header *types.Header, bc vm.ChainContext, cfg kvm.Config, valSmcAddr common.Address, delegatorAddr common.Address) ([]*UnbondingEntry, error) {
+	payload, err := s.Abi.Pack("getUBDEntries", delegatorAddr)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.ConstructAndApplySmcCallMsg(statedb, header, bc, cfg, payload, valSmcAddr, valSmcAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// outputs of getUBDEntries are unnamed, so they can't be unpacked into a struct
+	out, err := s.Abi.Unpack("getUBDEntries", res)
+	if err != nil {
+		log.Error("Error unpacking unbonding entries", "err", err)
+		return nil, err
+	}
+	balances, completionTimes := out[0].([]*big.Int), out[1].([]*big.Int)
+	entries := make([]*UnbondingEntry, len(balances))
+	for i := range balances {
+		entries[i] = &UnbondingEntry{
+			Amount:         balances[i],
+			CompletionTime: completionTimes[i],
+		}
+	}
+	return entries, nil
+}
+
 // GetSigningInfo returns signing info of this validator
 func (s *ValidatorSmcUtil) GetSigningInfo(statedb *state.StateDB, header *types.Header, bc vm.ChainContext, cfg kvm.Config, valSmcAddr common.Address) (*SigningInfo, error) {
 	payload, err := s.Abi.Pack("signingInfo")
@@ -239,6 +267,27 @@ func (s *ValidatorSmcUtil) GetSigningInfo(statedb *state.StateDB, header *types.
 	return &result, nil
 }
 
+// GetMissedBlocks returns the sliding window of blocks this validator missed,
+// indexed by signing info index offset modulo the signed block window
+func (s *ValidatorSmcUtil) GetMissedBlocks(statedb *state.StateDB, header *types.Header, bc vm.ChainContext, cfg kvm.Config, valSmcAddr common.Address) ([]bool, error) {
+	payload, err := s.Abi.Pack("getMissedBlock")
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.ConstructAndApplySmcCallMsg(statedb, header, bc, cfg, payload, valSmcAddr, valSmcAddr)
+	if err != nil {
+		return nil, err
+	}
+	var missedBlocks []bool
+	// unpack result
+	err = s.Abi.UnpackIntoInterface(&missedBlocks, "getMissedBlock", res)
+	if err != nil {
+		log.Error("Error unpacking missed blocks of validator: ", "err", err)
+		return nil, err
+	}
+	return missedBlocks, nil
+}
+
 func (s *ValidatorSmcUtil) ConstructAndApplySmcCallMsg(statedb *state.StateDB, header *types.Header, bc vm.ChainContext, cfg kvm.Config, payload []byte, valSmcAddr common.Address, valAddr common.Address) ([]byte, error) {
 	msg := types.NewMessage(
 		valAddr,
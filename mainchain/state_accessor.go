@@ -23,7 +23,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/kardiachain/go-kardia/configs"
 	"github.com/kardiachain/go-kardia/kai/state"
 	"github.com/kardiachain/go-kardia/kvm"
 	"github.com/kardiachain/go-kardia/lib/common"
@@ -69,7 +68,7 @@ func (k *KardiaService) stateAtBlock(block *types.Block, reexec uint64, base *st
 		// we would rewind past a persisted block (specific corner case is chain
 		// tracing from the genesis).
 		if !checkLive {
-			statedb, err = state.New(log.New(), current.AppHash(), database)
+			statedb, err = state.New(log.New(), k.blockchain.DB().ReadAppHash(current.Height()), database)
 			if err == nil {
 				return statedb, nil
 			}
@@ -79,13 +78,15 @@ func (k *KardiaService) stateAtBlock(block *types.Block, reexec uint64, base *st
 			if current.Height() == 0 {
 				return nil, errors.New("genesis state is missing")
 			}
-			parent := k.blockchain.GetBlockByHeight(block.Height() - 1)
+			parent := k.blockchain.GetBlockByHeight(current.Height() - 1)
 			if parent == nil {
 				return nil, fmt.Errorf("missing block %d", current.Height()-1)
 			}
 			current = parent
 
-			statedb, err = state.New(log.New(), current.AppHash(), database)
+			// The app hash in a header is the state root after its parent,
+			// the one committed after the block itself is stored by height.
+			statedb, err = state.New(log.New(), k.blockchain.DB().ReadAppHash(current.Height()), database)
 			if err == nil {
 				break
 			}
@@ -173,7 +174,7 @@ func (k *KardiaService) stateAtTransaction(block *types.Block, txIndex int, reex
 			return msg, context, statedb, nil
 		}
 		// Not yet the searched for transaction, execute on top of the current state
-		vmenv := kvm.NewKVM(context, txContext, statedb, configs.MainnetChainConfig, kvm.Config{})
+		vmenv := kvm.NewKVM(context, txContext, statedb, k.blockchain.Config(), kvm.Config{})
 		statedb.Prepare(tx.Hash(), block.Hash(), idx)
 		if _, err := blockchain.ApplyMessage(vmenv, msg, new(types.GasPool).AddGas(tx.Gas())); err != nil {
 			k.logger.Warn("failed to apply transaction while tracing", "hash", tx.Hash(), "err", err)
@@ -0,0 +1,144 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kai
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/kardiachain/go-kardia/kai/events"
+	"github.com/kardiachain/go-kardia/statesync"
+)
+
+// syncState restores the state from a snapshot of the peers, then fast syncs
+// the blocks after it or switches to consensus. The node falls back to fast
+// sync from genesis if the state sync fails.
+func (s *KardiaService) syncState() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	provider, err := statesync.NewLightStateProvider(ctx, s.logger, s.config.Genesis.ChainID, s.config.StateSync)
+	cancel()
+	if err != nil {
+		s.logger.Error("Failed to set up state sync", "err", err)
+		s.fallbackToFastSync()
+		return
+	}
+
+	state, err := s.stateSyncR.Sync(provider)
+	if err != nil {
+		s.logger.Error("State sync failed", "err", err)
+		s.fallbackToFastSync()
+		return
+	}
+	if err := s.blockchain.Reload(); err != nil {
+		s.logger.Error("Failed to reload the restored chain", "err", err)
+		s.fallbackToFastSync()
+		return
+	}
+	s.logger.Info("State sync complete", "height", state.LastBlockHeight, "appHash", state.AppHash.Hex())
+
+	if s.config.FastSync.Enable {
+		if err := s.bcR.SwitchToFastSync(state); err != nil {
+			s.logger.Error("Failed to switch to fast sync", "err", err)
+		}
+		return
+	}
+	s.csManager.SwitchToConsensus(state, true)
+}
+
+// fallbackToFastSync syncs from the state the node started with.
+func (s *KardiaService) fallbackToFastSync() {
+	state, err := s.stateDB.Load()
+	if err != nil {
+		s.logger.Error("Failed to load state", "err", err)
+		return
+	}
+	if s.config.FastSync.Enable {
+		if err := s.bcR.SwitchToFastSync(state); err != nil {
+			s.logger.Error("Failed to switch to fast sync", "err", err)
+		}
+		return
+	}
+	s.csManager.SwitchToConsensus(state, false)
+}
+
+// snapshotLoop creates a snapshot every SnapshotInterval blocks, keeping the
+// SnapshotKeepRecent latest ones.
+func (s *KardiaService) snapshotLoop() {
+	headCh := make(chan events.ChainHeadEvent, 10)
+	sub := s.blockchain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	var busy int32
+	interval := s.config.StateSync.SnapshotInterval
+	for {
+		select {
+		case ev := <-headCh:
+			height := ev.Block.Height()
+			if height%interval != 0 {
+				continue
+			}
+			// Exporting takes a while, skip this snapshot if the previous
+			// one is still being created.
+			if !atomic.CompareAndSwapInt32(&busy, 0, 1) {
+				s.logger.Info("Skipping snapshot, previous one in progress", "height", height)
+				continue
+			}
+			go func() {
+				defer atomic.StoreInt32(&busy, 0)
+				s.createSnapshot(height)
+			}()
+		case <-sub.Err():
+			return
+		case <-s.shutdownChan:
+			return
+		}
+	}
+}
+
+// snapshotHeights returns the heights of the stored snapshots and of the one
+// which may be being created, whose blocks and states the pruner keeps.
+func (s *KardiaService) snapshotHeights() ([]uint64, error) {
+	snapshots, err := s.snapshots.List()
+	if err != nil {
+		return nil, err
+	}
+	head := s.blockchain.CurrentBlock().Height()
+	heights := []uint64{head - head%s.config.StateSync.SnapshotInterval}
+	for _, sn := range snapshots {
+		heights = append(heights, sn.Height)
+	}
+	return heights, nil
+}
+
+func (s *KardiaService) createSnapshot(height uint64) {
+	start := time.Now()
+	sn, err := s.snapshots.Create(s.kaiDb.DB(), height)
+	if err != nil {
+		s.logger.Error("Failed to create snapshot", "height", height, "err", err)
+		return
+	}
+	s.logger.Info("Created snapshot", "height", sn.Height, "chunks", sn.Chunks, "hash", sn.Hash.Hex(),
+		"elapsed", time.Since(start))
+	if keep := s.config.StateSync.SnapshotKeepRecent; keep > 0 {
+		if err := s.snapshots.Prune(keep); err != nil {
+			s.logger.Error("Failed to prune snapshots", "err", err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/mainchain/staking"
+)
+
+func TestPackAddProposal(t *testing.T) {
+	configs.AddDefaultContract()
+	paramsUtil, err := staking.NewSmcParamsUtil()
+	require.NoError(t, err)
+	assert.Equal(t, configs.ParamsSMCAddress, paramsUtil.ContractAddress)
+
+	keys := []staking.ParamKey{staking.ParamSignedBlockWindow, staking.ParamMinSignedPerWindow}
+	values := []*big.Int{big.NewInt(20000), big.NewInt(6e17)}
+	data, err := paramsUtil.PackAddProposal(keys, values)
+	require.NoError(t, err)
+
+	method := paramsUtil.Abi.Methods["addProposal"]
+	assert.Equal(t, method.ID, data[:4])
+	args, err := method.Inputs.Unpack(data[4:])
+	require.NoError(t, err)
+	assert.Equal(t, []uint8{7, 8}, args[0])
+	assert.Equal(t, values, args[1])
+}
@@ -52,3 +52,28 @@ func TestGetDelegators(t *testing.T) {
 	assert.Equal(t, address.Hex(), delegators[0].Address.Hex())
 	assert.Equal(t, selfDelegate, delegators[0].StakedAmount.String())
 }
+
+func TestGetUBDEntries(t *testing.T) {
+	_, stateDB, stakingUtil, valUtil, block, err := setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := common.HexToAddress("0x7cefC13B6E2aedEeDFB7Cb6c32457240746BAEe5")
+	err = stakingUtil.CreateGenesisValidator(stateDB, block.Header(), nil, kvm.Config{}, address, "Val1", "10", "20", "1", selfDelegate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valSmcAddr, err := stakingUtil.GetValSmcAddr(stateDB, block.Header(), nil, kvm.Config{}, big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing has been undelegated yet
+	entries, err := valUtil.GetUBDEntries(stateDB, block.Header(), nil, kvm.Config{}, valSmcAddr, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, entries)
+}
@@ -97,11 +97,11 @@ type StdTraceConfig struct {
 	TxHash common.Hash
 }
 
-// // txTraceResult is the result of a single transaction trace.
-// type txTraceResult struct {
-// 	Result interface{} `json:"result,omitempty"` // Trace results produced by the tracer
-// 	Error  string      `json:"error,omitempty"`  // Trace failure produced by the tracer
-// }
+// txTraceResult is the result of a single transaction trace.
+type txTraceResult struct {
+	Result interface{} `json:"result,omitempty"` // Trace results produced by the tracer
+	Error  string      `json:"error,omitempty"`  // Trace failure produced by the tracer
+}
 
 // // blockTraceTask represents a single block trace task when an entire chain is
 // // being traced.
@@ -224,6 +224,75 @@ func (t *TracerAPI) TraceTransaction(ctx context.Context, hash common.Hash, conf
 	return t.traceTx(ctx, msg, txctx, vmctx, statedb, config)
 }
 
+// TraceBlockByNumber returns the structured logs created during the execution of
+// KVM and returns them as a JSON object.
+func (t *TracerAPI) TraceBlockByNumber(ctx context.Context, number rpc.BlockHeight, config *TraceConfig) ([]*txTraceResult, error) {
+	block, err := t.blockByHeight(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return t.traceBlock(ctx, block, config)
+}
+
+// TraceBlockByHash returns the structured logs created during the execution of
+// KVM and returns them as a JSON object.
+func (t *TracerAPI) TraceBlockByHash(ctx context.Context, hash common.Hash, config *TraceConfig) ([]*txTraceResult, error) {
+	block, err := t.blockByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return t.traceBlock(ctx, block, config)
+}
+
+// traceBlock re-executes all the transactions of the block on top of the state
+// of its parent, regenerated if it isn't available anymore, and traces each of
+// them with the requested tracer.
+func (t *TracerAPI) traceBlock(ctx context.Context, block *types.Block, config *TraceConfig) ([]*txTraceResult, error) {
+	if block.Height() == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	parent, err := t.blockByHeightAndHash(ctx, rpc.BlockHeight(block.Height()-1), block.LastBlockHash())
+	if err != nil {
+		return nil, err
+	}
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	statedb, err := t.b.StateAtBlock(ctx, parent, reexec, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		txs      = block.Transactions()
+		results  = make([]*txTraceResult, len(txs))
+		height   = block.Height()
+		signer   = types.MakeSigner(t.b.ChainConfig(), &height)
+		blockCtx = blockchain.NewKVMBlockContext(block.Header(), t.chainContext(ctx), nil)
+	)
+	for i, tx := range txs {
+		msg, err := tx.AsMessage(signer)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		txctx := &Context{
+			BlockHash: block.Hash(),
+			TxIndex:   i,
+			TxHash:    tx.Hash(),
+		}
+		res, err := t.traceTx(ctx, msg, txctx, blockCtx, statedb, config)
+		if err != nil {
+			results[i] = &txTraceResult{Error: err.Error()}
+		} else {
+			results[i] = &txTraceResult{Result: res}
+		}
+		// Ensure any modifications are committed to the state before the next
+		// transaction runs on top of it
+		statedb.Finalise(true)
+	}
+	return results, nil
+}
+
 // TraceCall lets you trace a given eth_call. It collects the structured logs
 // created during the execution of KVM if the given transaction was added on
 // top of the provided block and returns them as a JSON object.
@@ -0,0 +1,350 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/events"
+	"github.com/kardiachain/go-kardia/kai/indexer"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/event"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/rpc"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+const (
+	// indexTracer is the tracer whose call frames are indexed.
+	indexTracer = "callTracer"
+
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+
+	// indexRetryInterval is the delay before retrying a block which failed
+	// to be indexed.
+	indexRetryInterval = 5 * time.Second
+)
+
+// IndexChain is the blockchain a trace index service follows.
+type IndexChain interface {
+	CurrentBlock() *types.Block
+	GetBlockByHeight(height uint64) *types.Block
+	SubscribeChainHeadEvent(ch chan<- events.ChainHeadEvent) event.Subscription
+}
+
+// TraceIndexService runs the call tracer on every new block in the
+// background, and stores the calls of its transactions in a trace index so
+// that they can be looked up without re-tracing the block.
+type TraceIndexService struct {
+	logger log.Logger
+	config configs.TraceIndexConfig
+	api    *TracerAPI
+	chain  IndexChain
+	index  *indexer.TraceIndexer
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewTraceIndexService returns a service tracing the blocks of chain with
+// backend and storing their calls in index.
+func NewTraceIndexService(logger log.Logger, config configs.TraceIndexConfig, backend Backend, chain IndexChain, index *indexer.TraceIndexer) (*TraceIndexService, error) {
+	// The native tracers register themselves, make sure they are linked in.
+	if _, err := New(indexTracer, nil); err != nil {
+		return nil, fmt.Errorf("trace index: %w", err)
+	}
+	if config.StartHeight == 0 {
+		// The genesis block is not traceable.
+		config.StartHeight = 1
+	}
+	return &TraceIndexService{
+		logger: logger.New("module", "traceindex"),
+		config: config,
+		api:    NewTracerAPI(backend),
+		chain:  chain,
+		index:  index,
+		quit:   make(chan struct{}),
+	}, nil
+}
+
+// Index returns the trace index the service writes to.
+func (s *TraceIndexService) Index() *indexer.TraceIndexer {
+	return s.index
+}
+
+// Start catches up with the chain head and keeps following it.
+func (s *TraceIndexService) Start() {
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop waits for the block being indexed to be done.
+func (s *TraceIndexService) Stop() {
+	close(s.quit)
+	s.wg.Wait()
+}
+
+func (s *TraceIndexService) loop() {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	headCh := make(chan events.ChainHeadEvent, chainHeadChanSize)
+	sub := s.chain.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	for {
+		var retry <-chan time.Time
+		if err := s.catchUp(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Failed to index traces", "err", err, "retry", indexRetryInterval)
+			retry = time.After(indexRetryInterval)
+		}
+		select {
+		case <-headCh:
+		case <-retry:
+		case <-sub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// catchUp indexes the blocks from the cursor of the index to the chain head.
+func (s *TraceIndexService) catchUp(ctx context.Context) error {
+	next := s.config.StartHeight
+	if cursor, ok := s.index.Cursor(); ok && cursor+1 > next {
+		next = cursor + 1
+	}
+	head := s.chain.CurrentBlock().Height()
+	for height := next; height <= head; height++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		block := s.chain.GetBlockByHeight(height)
+		if block == nil {
+			return fmt.Errorf("block %d not found", height)
+		}
+		records, err := s.traceBlock(ctx, block)
+		if err != nil {
+			return fmt.Errorf("trace block %d: %w", height, err)
+		}
+		if err := s.index.IndexBlock(height, records); err != nil {
+			return err
+		}
+		s.logger.Trace("Indexed block traces", "height", height, "calls", len(records))
+	}
+	return nil
+}
+
+// traceBlock runs the call tracer on the transactions of block and flattens
+// their call frames.
+func (s *TraceIndexService) traceBlock(ctx context.Context, block *types.Block) ([]*indexer.CallRecord, error) {
+	if len(block.Transactions()) == 0 {
+		return nil, nil
+	}
+	tracer := indexTracer
+	results, err := s.api.traceBlock(ctx, block, &TraceConfig{Tracer: &tracer, Reexec: &s.config.Reexec})
+	if err != nil {
+		return nil, err
+	}
+	var records []*indexer.CallRecord
+	for i, tx := range block.Transactions() {
+		if ctx.Err() != nil {
+			// The tracer of the transaction was interrupted.
+			return nil, ctx.Err()
+		}
+		if results[i].Error != "" {
+			s.logger.Warn("Failed to trace transaction", "height", block.Height(), "hash", tx.Hash(), "err", results[i].Error)
+			continue
+		}
+		raw, ok := results[i].Result.(json.RawMessage)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %s result %T", indexTracer, results[i].Result)
+		}
+		var frame indexedFrame
+		if err := json.Unmarshal(raw, &frame); err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		records = append(records, frame.flatten(nil, block.Height(), tx.Hash(), uint32(i))...)
+	}
+	return records, nil
+}
+
+// indexedFrame is the part of a call frame of the call tracer which is
+// indexed.
+type indexedFrame struct {
+	Type  string         `json:"type"`
+	From  string         `json:"from"`
+	To    string         `json:"to"`
+	Value string         `json:"value"`
+	Input string         `json:"input"`
+	Error string         `json:"error"`
+	Calls []indexedFrame `json:"calls"`
+}
+
+// flatten appends the records of f and its sub calls in depth first order to
+// the records of the transaction made before f.
+func (f *indexedFrame) flatten(records []*indexer.CallRecord, height uint64, txHash common.Hash, txIndex uint32) []*indexer.CallRecord {
+	var (
+		input = common.FromHex(f.Input)
+		value = new(big.Int)
+	)
+	if v := strings.TrimPrefix(f.Value, "0x"); v != "" {
+		value.SetString(v, 16)
+	}
+	r := &indexer.CallRecord{
+		Height:    height,
+		TxHash:    txHash,
+		TxIndex:   txIndex,
+		CallIndex: uint32(len(records)),
+		Type:      f.Type,
+		From:      common.HexToAddress(f.From),
+		Value:     value,
+		Error:     f.Error,
+	}
+	if f.To != "" {
+		r.To = common.HexToAddress(f.To)
+	}
+	if len(input) >= 4 {
+		r.Selector = input[:4]
+	}
+	records = append(records, r)
+	for i := range f.Calls {
+		records = f.Calls[i].flatten(records, height, txHash, txIndex)
+	}
+	return records
+}
+
+// TraceIndexAPI provides APIs to look up the calls stored by a trace index
+// service.
+type TraceIndexAPI struct {
+	s *TraceIndexService
+}
+
+// NewTraceIndexAPI creates a new API definition for the trace index of the
+// KardiaChain service.
+func NewTraceIndexAPI(s *TraceIndexService) *TraceIndexAPI {
+	return &TraceIndexAPI{s: s}
+}
+
+// callRecordResult is an indexed call as returned by the RPC API.
+type callRecordResult struct {
+	BlockHeight uint64          `json:"blockHeight"`
+	TxHash      common.Hash     `json:"txHash"`
+	TxIndex     uint32          `json:"transactionIndex"`
+	CallIndex   uint32          `json:"callIndex"`
+	Type        string          `json:"type"`
+	From        common.Address  `json:"from"`
+	To          *common.Address `json:"to,omitempty"`
+	Value       string          `json:"value"`
+	Selector    common.Bytes    `json:"selector,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+func newCallRecordResult(r *indexer.CallRecord) *callRecordResult {
+	result := &callRecordResult{
+		BlockHeight: r.Height,
+		TxHash:      r.TxHash,
+		TxIndex:     r.TxIndex,
+		CallIndex:   r.CallIndex,
+		Type:        r.Type,
+		From:        r.From,
+		Value:       r.Value.String(),
+		Selector:    r.Selector,
+		Error:       r.Error,
+	}
+	if r.To != (common.Address{}) {
+		to := r.To
+		result.To = &to
+	}
+	return result
+}
+
+func newCallRecordResults(records []*indexer.CallRecord) []*callRecordResult {
+	results := make([]*callRecordResult, len(records))
+	for i, r := range records {
+		results[i] = newCallRecordResult(r)
+	}
+	return results
+}
+
+// GetTracesByBlock returns the indexed calls of the transactions of a block.
+func (api *TraceIndexAPI) GetTracesByBlock(ctx context.Context, height rpc.BlockHeight) ([]*callRecordResult, error) {
+	h, err := api.indexedHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	records, err := api.s.index.GetTracesByBlock(h)
+	if err != nil {
+		return nil, err
+	}
+	return newCallRecordResults(records), nil
+}
+
+// GetTracesByAddress returns the indexed calls made from or to an address
+// between two blocks inclusive.
+func (api *TraceIndexAPI) GetTracesByAddress(ctx context.Context, address common.Address, fromHeight, toHeight rpc.BlockHeight) ([]*callRecordResult, error) {
+	from, err := api.indexedHeight(fromHeight)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.indexedHeight(toHeight)
+	if err != nil {
+		return nil, err
+	}
+	records, err := api.s.index.GetTracesByAddress(ctx, address, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return newCallRecordResults(records), nil
+}
+
+// indexedHeight resolves height to a height of the index, the latest one
+// being the last indexed block.
+func (api *TraceIndexAPI) indexedHeight(height rpc.BlockHeight) (uint64, error) {
+	cursor, ok := api.s.index.Cursor()
+	if !ok {
+		return 0, errors.New("no block is indexed yet")
+	}
+	if height == rpc.LatestBlockHeight || height == rpc.PendingBlockHeight {
+		return cursor, nil
+	}
+	if height.Uint64() > cursor {
+		return 0, fmt.Errorf("block #%d is not indexed yet", height)
+	}
+	return height.Uint64(), nil
+}
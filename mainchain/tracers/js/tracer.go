@@ -25,10 +25,10 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
-	"sync/atomic"
 	"time"
 	"unicode"
-	"unsafe"
+
+	"github.com/dop251/goja"
 
 	"github.com/kardiachain/go-kardia/kvm"
 	"github.com/kardiachain/go-kardia/lib/common"
@@ -37,8 +37,6 @@ import (
 	tracers2 "github.com/kardiachain/go-kardia/mainchain/tracers"
 	"github.com/kardiachain/go-kardia/mainchain/tracers/js/internal/tracers"
 	"github.com/kardiachain/go-kardia/mainchain/tx_pool"
-
-	"gopkg.in/olebedev/go-duktape.v3"
 )
 
 // camel converts a snake cased input string into a camel cased output.
@@ -61,821 +59,955 @@ func init() {
 	tracers2.RegisterLookup(true, newJsTracer)
 }
 
-// makeSlice convert an unsafe memory pointer with the given type into a Go byte
-// slice.
-//
-// Note, the returned slice uses the same memory area as the input arguments.
-// If those are duktape stack items, popping them off **will** make the slice
-// contents change.
-func makeSlice(ptr unsafe.Pointer, size uint) []byte {
-	var sl = struct {
-		addr uintptr
-		len  int
-		cap  int
-	}{uintptr(ptr), int(size), int(size)}
-
-	return *(*[]byte)(unsafe.Pointer(&sl))
-}
-
-// popSlice pops a buffer off the JavaScript stack and returns it as a slice.
-func popSlice(ctx *duktape.Context) []byte {
-	blob := common.CopyBytes(makeSlice(ctx.GetBuffer(-1)))
-	ctx.Pop()
-	return blob
-}
+// bigIntProgram is compiled once and the exported function mostly invoked to
+// convert hex strings into big ints.
+var bigIntProgram = goja.MustCompile("bigInt", bigIntegerJS, false)
 
-// pushBigInt create a JavaScript BigInteger in the vm.
-func pushBigInt(n *big.Int, ctx *duktape.Context) {
-	ctx.GetGlobalString("bigInt")
-	ctx.PushString(n.String())
-	ctx.Call(1)
-}
+type toBigFn = func(vm *goja.Runtime, val string) (goja.Value, error)
+type toBufFn = func(vm *goja.Runtime, val []byte) (goja.Value, error)
+type fromBufFn = func(vm *goja.Runtime, buf goja.Value, allowString bool) ([]byte, error)
 
-// opWrapper provides a JavaScript wrapper around OpCode.
-type opWrapper struct {
-	op kvm.OpCode
+// toBuf wraps the bytes in a new JS buffer of the given type, usually
+// Uint8Array. This is equivalent to `new Uint8Array(val)` in JS.
+func toBuf(vm *goja.Runtime, bufType goja.Value, val []byte) (goja.Value, error) {
+	return vm.New(bufType, vm.ToValue(vm.NewArrayBuffer(val)))
 }
 
-// pushObject assembles a JSVM object wrapping a swappable opcode and pushes it
-// onto the VM stack.
-func (ow *opWrapper) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushInt(int(ow.op)); return 1 })
-	vm.PutPropString(obj, "toNumber")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushString(ow.op.String()); return 1 })
-	vm.PutPropString(obj, "toString")
+// fromBuf returns the bytes of a JS buffer, an array of bytes or, if allowed,
+// of a hex string.
+func fromBuf(vm *goja.Runtime, bufType goja.Value, buf goja.Value, allowString bool) ([]byte, error) {
+	obj := buf.ToObject(vm)
+	switch obj.ClassName() {
+	case "String":
+		if !allowString {
+			break
+		}
+		return common.FromHex(obj.String()), nil
 
-	vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushBoolean(ow.op.IsPush()); return 1 })
-	vm.PutPropString(obj, "isPush")
-}
+	case "Array":
+		var b []byte
+		if err := vm.ExportTo(buf, &b); err != nil {
+			return nil, err
+		}
+		return b, nil
 
-// memoryWrapper provides a JavaScript wrapper around kvm.Memory.
-type memoryWrapper struct {
-	memory *kvm.Memory
+	case "Object":
+		if !obj.Get("constructor").SameAs(bufType) {
+			break
+		}
+		b := obj.Get("buffer").Export().(goja.ArrayBuffer).Bytes()
+		return b, nil
+	}
+	return nil, errors.New("invalid buffer type")
 }
 
-// slice returns the requested range of memory as a byte slice.
-func (mw *memoryWrapper) slice(begin, end int64) []byte {
-	if end == begin {
-		return []byte{}
+// jsTracer provides an implementation of Tracer that evaluates a Javascript
+// function for each VM execution step. It runs on the goja engine, whose
+// execution can be interrupted by Stop.
+type jsTracer struct {
+	vm                *goja.Runtime         // Javascript VM instance
+	env               *kvm.KVM              // KVM instance executing the code being traced
+	toBig             toBigFn               // Converts a hex string into a JS bigint
+	toBuf             toBufFn               // Converts a []byte into a JS buffer
+	fromBuf           fromBufFn             // Converts an array, hex string or Uint8Array to a []byte
+	ctx               map[string]goja.Value // Transaction context passed to JS in `result`
+	activePrecompiles []common.Address      // Updated on CaptureStart based on given rules
+	traceStep         bool                  // When true, will invoke step() on each opcode
+	traceFrame        bool                  // When true, will invoke enter() and exit() js funcs
+	err               error                 // Error, if one has occurred
+	obj               *goja.Object          // Tracer JavaScript object
+
+	// Methods exposed by the tracer object
+	result, fault, step, enter, exit goja.Callable
+
+	// Swappable structs wrapped by the objects passed to JS
+	log         *steplog
+	frame       *callframe
+	frameResult *callframeResult
+
+	// JS objects wrapping the structs above, created once and reused
+	logValue         goja.Value
+	dbValue          goja.Value
+	frameValue       goja.Value
+	frameResultValue goja.Value
+}
+
+// newJsTracer instantiates a new tracer instance. code specifies a Javascript
+// snippet, which must evaluate to an expression returning an object with
+// 'step', 'fault' and 'result' functions.
+func newJsTracer(code string, ctx *tracers2.Context) (tracers2.Tracer, error) {
+	if c, ok := assetTracers[code]; ok {
+		code = c
 	}
-	if end < begin || begin < 0 {
-		// TODO: We can't js-throw from Go inside duktape inside Go. The Go
-		// runtime goes belly up https://github.com/golang/go/issues/15639.
-		log.Warn("Tracer accessed out of bound memory", "offset", begin, "end", end)
-		return nil
+	if ctx == nil {
+		ctx = new(tracers2.Context)
 	}
-	if mw.memory.Len() < int(end) {
-		// TODO: We can't js-throw from Go inside duktape inside Go. The Go
-		// runtime goes belly up https://github.com/golang/go/issues/15639.
-		log.Warn("Tracer accessed out of bound memory", "available", mw.memory.Len(), "offset", begin, "size", end-begin)
-		return nil
+	vm := goja.New()
+	// By default field names are exported to JS as is, i.e. capitalized.
+	vm.SetFieldNameMapper(goja.UncapFieldNameMapper())
+	t := &jsTracer{
+		vm:  vm,
+		ctx: make(map[string]goja.Value),
 	}
-	return mw.memory.GetCopy(begin, end-begin)
-}
-
-// getUint returns the 32 bytes at the specified address interpreted as a uint.
-func (mw *memoryWrapper) getUint(addr int64) *big.Int {
-	if mw.memory.Len() < int(addr)+32 || addr < 0 {
-		// TODO: We can't js-throw from Go inside duktape inside Go. The Go
-		// runtime goes belly up https://github.com/golang/go/issues/15639.
-		log.Warn("Tracer accessed out of bound memory", "available", mw.memory.Len(), "offset", addr, "size", 32)
-		return new(big.Int)
+	if err := t.setTypeConverters(); err != nil {
+		return nil, err
 	}
-	return new(big.Int).SetBytes(mw.memory.GetPtr(addr, 32))
-}
-
-// pushObject assembles a JSVM object wrapping a swappable memory and pushes it
-// onto the VM stack.
-func (mw *memoryWrapper) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
-
-	// Generate the `slice` method which takes two ints and returns a buffer
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		blob := mw.slice(int64(ctx.GetInt(-2)), int64(ctx.GetInt(-1)))
-		ctx.Pop2()
-
-		ptr := ctx.PushFixedBuffer(len(blob))
-		copy(makeSlice(ptr, uint(len(blob))), blob)
-		return 1
-	})
-	vm.PutPropString(obj, "slice")
-
-	// Generate the `getUint` method which takes an int and returns a bigint
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		offset := int64(ctx.GetInt(-1))
-		ctx.Pop()
+	if err := t.setBuiltinFunctions(); err != nil {
+		return nil, err
+	}
+	if ctx.BlockHash != (common.Hash{}) {
+		blockHash, err := t.toBuf(vm, ctx.BlockHash.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		t.ctx["blockHash"] = blockHash
+		if ctx.TxHash != (common.Hash{}) {
+			t.ctx["txIndex"] = vm.ToValue(ctx.TxIndex)
+			txHash, err := t.toBuf(vm, ctx.TxHash.Bytes())
+			if err != nil {
+				return nil, err
+			}
+			t.ctx["txHash"] = txHash
+		}
+	}
+	ret, err := vm.RunString("(" + code + ")")
+	if err != nil {
+		return nil, err
+	}
+	// Check tracer's interface for required and optional methods.
+	obj := ret.ToObject(vm)
+	result, ok := goja.AssertFunction(obj.Get("result"))
+	if !ok {
+		return nil, errors.New("trace object must expose a function result()")
+	}
+	fault, ok := goja.AssertFunction(obj.Get("fault"))
+	if !ok {
+		return nil, errors.New("trace object must expose a function fault()")
+	}
+	step, ok := goja.AssertFunction(obj.Get("step"))
+	t.traceStep = ok
+	enter, hasEnter := goja.AssertFunction(obj.Get("enter"))
+	exit, hasExit := goja.AssertFunction(obj.Get("exit"))
+	if hasEnter != hasExit {
+		return nil, errors.New("trace object must expose either both or none of enter() and exit()")
+	}
+	t.traceFrame = hasEnter
+	t.obj = obj
+	t.step = step
+	t.enter = enter
+	t.exit = exit
+	t.result = result
+	t.fault = fault
+
+	// Setup the objects carrying data to JS.
+	t.log = &steplog{
+		vm:       vm,
+		op:       &opObj{vm: vm},
+		memory:   &memoryObj{vm: vm, toBig: t.toBig, toBuf: t.toBuf},
+		stack:    &stackObj{vm: vm, toBig: t.toBig},
+		contract: &contractObj{vm: vm, toBig: t.toBig, toBuf: t.toBuf},
+	}
+	t.frame = &callframe{vm: vm, toBig: t.toBig, toBuf: t.toBuf}
+	t.frameResult = &callframeResult{vm: vm, toBuf: t.toBuf}
+	t.frameValue = t.frame.setupObject()
+	t.frameResultValue = t.frameResult.setupObject()
+	t.logValue = t.log.setupObject()
+	return t, nil
+}
+
+// CaptureStart implements the KVMLogger interface to initialize the tracing operation.
+func (t *jsTracer) CaptureStart(env *kvm.KVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	db := &dbObj{db: env.StateDB, vm: t.vm, toBig: t.toBig, toBuf: t.toBuf, fromBuf: t.fromBuf}
+	t.dbValue = db.setupObject()
+	if create {
+		t.ctx["type"] = t.vm.ToValue("CREATE")
+	} else {
+		t.ctx["type"] = t.vm.ToValue("CALL")
+	}
+	var err error
+	if t.ctx["from"], err = t.toBuf(t.vm, from.Bytes()); err != nil {
+		t.err = err
+		return
+	}
+	if t.ctx["to"], err = t.toBuf(t.vm, to.Bytes()); err != nil {
+		t.err = err
+		return
+	}
+	if t.ctx["input"], err = t.toBuf(t.vm, input); err != nil {
+		t.err = err
+		return
+	}
+	t.ctx["gas"] = t.vm.ToValue(gas)
+	if t.ctx["gasPrice"], err = t.toBig(t.vm, env.TxContext.GasPrice.String()); err != nil {
+		t.err = err
+		return
+	}
+	if t.ctx["value"], err = t.toBig(t.vm, value.String()); err != nil {
+		t.err = err
+		return
+	}
+	t.ctx["block"] = t.vm.ToValue(env.BlockContext.BlockHeight.Uint64())
+	// Update list of precompiles based on current block
+	t.activePrecompiles = kvm.ActivePrecompiles()
 
-		pushBigInt(mw.getUint(offset), ctx)
-		return 1
-	})
-	vm.PutPropString(obj, "getUint")
+	// Compute intrinsic gas
+	height := env.BlockContext.BlockHeight.Uint64()
+	isGalaxias := env.ChainConfig().IsGalaxias(&height)
+	intrinsicGas, err := tx_pool.IntrinsicGas(input, create, !isGalaxias)
+	if err != nil {
+		return
+	}
+	t.ctx["intrinsicGas"] = t.vm.ToValue(intrinsicGas)
 }
 
-// stackWrapper provides a JavaScript wrapper around kvm.Stack.
-type stackWrapper struct {
-	stack *kvm.Stack
-}
+// CaptureState implements the KVMLogger interface to trace a single step of VM execution.
+func (t *jsTracer) CaptureState(pc uint64, op kvm.OpCode, gas, cost uint64, scope *kvm.ScopeContext, rData []byte, depth int, err error) {
+	if !t.traceStep {
+		return
+	}
+	if t.err != nil {
+		return
+	}
 
-// peek returns the nth-from-the-top element of the stack.
-func (sw *stackWrapper) peek(idx int) *big.Int {
-	if len(sw.stack.Data()) <= idx || idx < 0 {
-		// TODO: We can't js-throw from Go inside duktape inside Go. The Go
-		// runtime goes belly up https://github.com/golang/go/issues/15639.
-		log.Warn("Tracer accessed out of bound stack", "size", len(sw.stack.Data()), "index", idx)
-		return new(big.Int)
+	log := t.log
+	log.op.op = op
+	log.memory.memory = scope.Memory
+	log.stack.stack = scope.Stack
+	log.contract.contract = scope.Contract
+	log.pc = uint(pc)
+	log.gas = uint(gas)
+	log.cost = uint(cost)
+	log.refund = uint(t.env.StateDB.GetRefund())
+	log.depth = uint(depth)
+	log.err = err
+	if _, err := t.step(t.obj, t.logValue, t.dbValue); err != nil {
+		t.onError("step", err)
 	}
-	return sw.stack.Back(idx).ToBig()
 }
 
-// pushObject assembles a JSVM object wrapping a swappable stack and pushes it
-// onto the VM stack.
-func (sw *stackWrapper) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushInt(len(sw.stack.Data())); return 1 })
-	vm.PutPropString(obj, "length")
-
-	// Generate the `peek` method which takes an int and returns a bigint
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		offset := ctx.GetInt(-1)
-		ctx.Pop()
-
-		pushBigInt(sw.peek(offset), ctx)
-		return 1
-	})
-	vm.PutPropString(obj, "peek")
+// CaptureFault implements the KVMLogger interface to trace an execution fault.
+func (t *jsTracer) CaptureFault(pc uint64, op kvm.OpCode, gas, cost uint64, scope *kvm.ScopeContext, depth int, err error) {
+	if t.err != nil {
+		return
+	}
+	// Other log fields have been already set as part of the last CaptureState.
+	t.log.err = err
+	if _, err := t.fault(t.obj, t.logValue, t.dbValue); err != nil {
+		t.onError("fault", err)
+	}
 }
 
-// dbWrapper provides a JavaScript wrapper around kvm.Database.
-type dbWrapper struct {
-	db kvm.StateDB
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *jsTracer) CaptureEnd(output []byte, gasUsed uint64, duration time.Duration, err error) {
+	var cerr error
+	if t.ctx["output"], cerr = t.toBuf(t.vm, output); cerr != nil {
+		t.err = cerr
+		return
+	}
+	t.ctx["time"] = t.vm.ToValue(duration.String())
+	t.ctx["gasUsed"] = t.vm.ToValue(gasUsed)
+	if err != nil {
+		t.ctx["error"] = t.vm.ToValue(err.Error())
+	}
 }
 
-// pushObject assembles a JSVM object wrapping a swappable database and pushes it
-// onto the VM stack.
-func (dw *dbWrapper) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
-
-	// Push the wrapper for statedb.GetBalance
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		pushBigInt(dw.db.GetBalance(common.BytesToAddress(popSlice(ctx))), ctx)
-		return 1
-	})
-	vm.PutPropString(obj, "getBalance")
-
-	// Push the wrapper for statedb.GetNonce
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		ctx.PushInt(int(dw.db.GetNonce(common.BytesToAddress(popSlice(ctx)))))
-		return 1
-	})
-	vm.PutPropString(obj, "getNonce")
-
-	// Push the wrapper for statedb.GetCode
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		code := dw.db.GetCode(common.BytesToAddress(popSlice(ctx)))
-
-		ptr := ctx.PushFixedBuffer(len(code))
-		copy(makeSlice(ptr, uint(len(code))), code)
-		return 1
-	})
-	vm.PutPropString(obj, "getCode")
-
-	// Push the wrapper for statedb.GetState
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		hash := popSlice(ctx)
-		addr := popSlice(ctx)
-
-		state := dw.db.GetState(common.BytesToAddress(addr), common.BytesToHash(hash))
-
-		ptr := ctx.PushFixedBuffer(len(state))
-		copy(makeSlice(ptr, uint(len(state))), state[:])
-		return 1
-	})
-	vm.PutPropString(obj, "getState")
+// CaptureEnter is called when KVM enters a new scope (via call, create or selfdestruct).
+func (t *jsTracer) CaptureEnter(typ kvm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if !t.traceFrame {
+		return
+	}
+	if t.err != nil {
+		return
+	}
 
-	// Push the wrapper for statedb.Exists
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		ctx.PushBoolean(dw.db.Exist(common.BytesToAddress(popSlice(ctx))))
-		return 1
-	})
-	vm.PutPropString(obj, "exists")
-}
+	t.frame.typ = typ.String()
+	t.frame.from = from
+	t.frame.to = to
+	t.frame.input = common.CopyBytes(input)
+	t.frame.gas = uint(gas)
+	t.frame.value = nil
+	if value != nil {
+		t.frame.value = new(big.Int).SetBytes(value.Bytes())
+	}
 
-// contractWrapper provides a JavaScript wrapper around kvm.Contract
-type contractWrapper struct {
-	contract *kvm.Contract
+	if _, err := t.enter(t.obj, t.frameValue); err != nil {
+		t.onError("enter", err)
+	}
 }
 
-// pushObject assembles a JSVM object wrapping a swappable contract and pushes it
-// onto the VM stack.
-func (cw *contractWrapper) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
-
-	// Push the wrapper for contract.Caller
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		ptr := ctx.PushFixedBuffer(20)
-		copy(makeSlice(ptr, 20), cw.contract.Caller().Bytes())
-		return 1
-	})
-	vm.PutPropString(obj, "getCaller")
-
-	// Push the wrapper for contract.Address
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		ptr := ctx.PushFixedBuffer(20)
-		copy(makeSlice(ptr, 20), cw.contract.Address().Bytes())
-		return 1
-	})
-	vm.PutPropString(obj, "getAddress")
-
-	// Push the wrapper for contract.Value
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		pushBigInt(cw.contract.Value(), ctx)
-		return 1
-	})
-	vm.PutPropString(obj, "getValue")
-
-	// Push the wrapper for contract.Input
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		blob := cw.contract.Input
-
-		ptr := ctx.PushFixedBuffer(len(blob))
-		copy(makeSlice(ptr, uint(len(blob))), blob)
-		return 1
-	})
-	vm.PutPropString(obj, "getInput")
-}
+// CaptureExit is called when KVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *jsTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if !t.traceFrame {
+		return
+	}
+	if t.err != nil {
+		return
+	}
 
-type frame struct {
-	typ   *string
-	from  *common.Address
-	to    *common.Address
-	input []byte
-	gas   *uint
-	value *big.Int
-}
+	t.frameResult.gasUsed = uint(gasUsed)
+	t.frameResult.output = common.CopyBytes(output)
+	t.frameResult.err = err
 
-func newFrame() *frame {
-	return &frame{
-		typ:  new(string),
-		from: new(common.Address),
-		to:   new(common.Address),
-		gas:  new(uint),
+	if _, err := t.exit(t.obj, t.frameResultValue); err != nil {
+		t.onError("exit", err)
 	}
 }
 
-func (f *frame) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, *f.typ); return 1 })
-	vm.PutPropString(obj, "getType")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, *f.from); return 1 })
-	vm.PutPropString(obj, "getFrom")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, *f.to); return 1 })
-	vm.PutPropString(obj, "getTo")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, f.input); return 1 })
-	vm.PutPropString(obj, "getInput")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, *f.gas); return 1 })
-	vm.PutPropString(obj, "getGas")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		if f.value != nil {
-			pushValue(ctx, f.value)
-		} else {
-			ctx.PushUndefined()
+// GetResult calls the Javascript 'result' function and returns its value, or
+// any accumulated error.
+func (t *jsTracer) GetResult() (json.RawMessage, error) {
+	ctx := t.vm.NewObject()
+	for key, val := range t.ctx {
+		if err := ctx.Set(key, val); err != nil {
+			return nil, err
 		}
-		return 1
-	})
-	vm.PutPropString(obj, "getValue")
+	}
+	res, err := t.result(t.obj, ctx, t.dbValue)
+	if err != nil {
+		return nil, wrapError("result", err)
+	}
+	var encoded []byte
+	if obj, ok := res.(*goja.Object); ok {
+		encoded, err = obj.MarshalJSON()
+	} else {
+		encoded, err = json.Marshal(res.Export())
+	}
+	if err != nil {
+		return nil, wrapError("result", err)
+	}
+	return json.RawMessage(encoded), t.err
 }
 
-type frameResult struct {
-	gasUsed    *uint
-	output     []byte
-	errorValue *string
+// Stop terminates execution of the tracer at the first opportune moment, the
+// JS code being run is interrupted.
+func (t *jsTracer) Stop(err error) {
+	t.vm.Interrupt(err)
 }
 
-func newFrameResult() *frameResult {
-	return &frameResult{
-		gasUsed: new(uint),
+// onError is called anytime the running JS code is interrupted and returns an
+// error. It in turn pings the KVM to cancel its execution.
+func (t *jsTracer) onError(context string, err error) {
+	t.err = wrapError(context, err)
+	// env is set on CaptureStart which comes before any JS execution, it is
+	// only missing when a tracer is driven directly.
+	if t.env != nil {
+		t.env.Cancel()
 	}
 }
 
-func (r *frameResult) pushObject(vm *duktape.Context) {
-	obj := vm.PushObject()
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, *r.gasUsed); return 1 })
-	vm.PutPropString(obj, "getGasUsed")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int { pushValue(ctx, r.output); return 1 })
-	vm.PutPropString(obj, "getOutput")
-
-	vm.PushGoFunction(func(ctx *duktape.Context) int {
-		if r.errorValue != nil {
-			pushValue(ctx, *r.errorValue)
-		} else {
-			ctx.PushUndefined()
-		}
-		return 1
-	})
-	vm.PutPropString(obj, "getError")
-}
-
-// jsTracer provides an implementation of Tracer that evaluates a Javascript
-// function for each VM execution step.
-type jsTracer struct {
-	vm  *duktape.Context // Javascript VM instance
-	env *kvm.KVM         // EVM instance executing the code being traced
-
-	tracerObject int // Stack index of the tracer JavaScript object
-	stateObject  int // Stack index of the global state to pull arguments from
-
-	opWrapper       *opWrapper       // Wrapper around the VM opcode
-	stackWrapper    *stackWrapper    // Wrapper around the VM stack
-	memoryWrapper   *memoryWrapper   // Wrapper around the VM memory
-	contractWrapper *contractWrapper // Wrapper around the contract object
-	dbWrapper       *dbWrapper       // Wrapper around the VM environment
-
-	pcValue     *uint   // Swappable pc value wrapped by a log accessor
-	gasValue    *uint   // Swappable gas value wrapped by a log accessor
-	costValue   *uint   // Swappable cost value wrapped by a log accessor
-	depthValue  *uint   // Swappable depth value wrapped by a log accessor
-	errorValue  *string // Swappable error value wrapped by a log accessor
-	refundValue *uint   // Swappable refund value wrapped by a log accessor
-
-	frame       *frame       // Represents entry into call frame. Fields are swappable
-	frameResult *frameResult // Represents exit from a call frame. Fields are swappable
-
-	ctx map[string]interface{} // Transaction context gathered throughout execution
-	err error                  // Error, if one has occurred
-
-	interrupt uint32 // Atomic flag to signal execution interruption
-	reason    error  // Textual reason for the interruption
-
-	activePrecompiles []common.Address // Updated on CaptureStart based on given rules
-	traceSteps        bool             // When true, will invoke step() on each opcode
-	traceCallFrames   bool             // When true, will invoke enter() and exit() js funcs
+func wrapError(context string, err error) error {
+	return fmt.Errorf("%v    in server-side tracer function '%v'", err, context)
 }
 
-// New instantiates a new tracer instance. code specifies a Javascript snippet,
-// which must evaluate to an expression returning an object with 'step', 'fault'
-// and 'result' functions.
-func newJsTracer(code string, ctx *tracers2.Context) (tracers2.Tracer, error) {
-	if c, ok := assetTracers[code]; ok {
-		code = c
-	}
-	if ctx == nil {
-		ctx = new(tracers2.Context)
-	}
-	tracer := &jsTracer{
-		vm:              duktape.New(),
-		ctx:             make(map[string]interface{}),
-		opWrapper:       new(opWrapper),
-		stackWrapper:    new(stackWrapper),
-		memoryWrapper:   new(memoryWrapper),
-		contractWrapper: new(contractWrapper),
-		dbWrapper:       new(dbWrapper),
-		pcValue:         new(uint),
-		gasValue:        new(uint),
-		costValue:       new(uint),
-		depthValue:      new(uint),
-		refundValue:     new(uint),
-		frame:           newFrame(),
-		frameResult:     newFrameResult(),
-	}
-	if ctx.BlockHash != (common.Hash{}) {
-		tracer.ctx["blockHash"] = ctx.BlockHash
-
-		if ctx.TxHash != (common.Hash{}) {
-			tracer.ctx["txIndex"] = ctx.TxIndex
-			tracer.ctx["txHash"] = ctx.TxHash
+// setBuiltinFunctions injects Go functions which are available to tracers
+// into the environment. It depends on type converters having been set up.
+func (t *jsTracer) setBuiltinFunctions() error {
+	vm := t.vm
+	// TODO: load console from goja-nodejs
+	if err := vm.Set("toHex", func(v goja.Value) string {
+		b, err := t.fromBuf(vm, v, false)
+		if err != nil {
+			vm.Interrupt(err)
+			return ""
 		}
-	}
-	// Set up builtins for this environment
-	tracer.vm.PushGlobalGoFunction("toHex", func(ctx *duktape.Context) int {
-		ctx.PushString(common.Encode(popSlice(ctx)))
-		return 1
-	})
-	tracer.vm.PushGlobalGoFunction("toWord", func(ctx *duktape.Context) int {
-		var word common.Hash
-		if ptr, size := ctx.GetBuffer(-1); ptr != nil {
-			word = common.BytesToHash(makeSlice(ptr, size))
-		} else {
-			word = common.HexToHash(ctx.GetString(-1))
+		return common.Encode(b)
+	}); err != nil {
+		return err
+	}
+	if err := vm.Set("toWord", func(v goja.Value) goja.Value {
+		// TODO: add test with []byte len < 32 or > 32
+		b, err := t.fromBuf(vm, v, true)
+		if err != nil {
+			vm.Interrupt(err)
+			return nil
 		}
-		ctx.Pop()
-		copy(makeSlice(ctx.PushFixedBuffer(32), 32), word[:])
-		return 1
-	})
-	tracer.vm.PushGlobalGoFunction("toAddress", func(ctx *duktape.Context) int {
-		var addr common.Address
-		if ptr, size := ctx.GetBuffer(-1); ptr != nil {
-			addr = common.BytesToAddress(makeSlice(ptr, size))
-		} else {
-			addr = common.HexToAddress(ctx.GetString(-1))
+		b = common.BytesToHash(b).Bytes()
+		res, err := t.toBuf(vm, b)
+		if err != nil {
+			vm.Interrupt(err)
+			return nil
 		}
-		ctx.Pop()
-		copy(makeSlice(ctx.PushFixedBuffer(20), 20), addr[:])
-		return 1
-	})
-	tracer.vm.PushGlobalGoFunction("toContract", func(ctx *duktape.Context) int {
-		var from common.Address
-		if ptr, size := ctx.GetBuffer(-2); ptr != nil {
-			from = common.BytesToAddress(makeSlice(ptr, size))
-		} else {
-			from = common.HexToAddress(ctx.GetString(-2))
+		return res
+	}); err != nil {
+		return err
+	}
+	if err := vm.Set("toAddress", func(v goja.Value) goja.Value {
+		a, err := t.fromBuf(vm, v, true)
+		if err != nil {
+			vm.Interrupt(err)
+			return nil
 		}
-		nonce := uint64(ctx.GetInt(-1))
-		ctx.Pop2()
-
-		contract := crypto.CreateAddress(from, nonce)
-		copy(makeSlice(ctx.PushFixedBuffer(20), 20), contract[:])
-		return 1
-	})
-	tracer.vm.PushGlobalGoFunction("toContract2", func(ctx *duktape.Context) int {
-		var from common.Address
-		if ptr, size := ctx.GetBuffer(-3); ptr != nil {
-			from = common.BytesToAddress(makeSlice(ptr, size))
-		} else {
-			from = common.HexToAddress(ctx.GetString(-3))
+		a = common.BytesToAddress(a).Bytes()
+		res, err := t.toBuf(vm, a)
+		if err != nil {
+			vm.Interrupt(err)
+			return nil
+		}
+		return res
+	}); err != nil {
+		return err
+	}
+	if err := vm.Set("toContract", func(from goja.Value, nonce uint) goja.Value {
+		a, err := t.fromBuf(vm, from, true)
+		if err != nil {
+			vm.Interrupt(err)
+			return nil
 		}
-		// Retrieve salt hex string from js stack
-		salt := common.HexToHash(ctx.GetString(-2))
-		// Retrieve code slice from js stack
-		var code []byte
-		if ptr, size := ctx.GetBuffer(-1); ptr != nil {
-			code = common.CopyBytes(makeSlice(ptr, size))
-		} else {
-			code = common.FromHex(ctx.GetString(-1))
+		addr := common.BytesToAddress(a)
+		b := crypto.CreateAddress(addr, uint64(nonce)).Bytes()
+		res, err := t.toBuf(vm, b)
+		if err != nil {
+			vm.Interrupt(err)
+			return nil
 		}
+		return res
+	}); err != nil {
+		return err
+	}
+	if err := vm.Set("toContract2", func(from goja.Value, salt string, initcode goja.Value) goja.Value {
+		a, err := t.fromBuf(vm, from, true)
+		if err != nil {
+			vm.Interrupt(err)
+			return nil
+		}
+		addr := common.BytesToAddress(a)
+		code, err := t.fromBuf(vm, initcode, true)
+		if err != nil {
+			vm.Interrupt(err)
+			return nil
+		}
+		code = common.CopyBytes(code)
 		codeHash := crypto.Keccak256(code)
-		ctx.Pop3()
-		contract := crypto.CreateAddress2(from, salt, codeHash)
-		copy(makeSlice(ctx.PushFixedBuffer(20), 20), contract[:])
-		return 1
-	})
-	tracer.vm.PushGlobalGoFunction("isPrecompiled", func(ctx *duktape.Context) int {
-		addr := common.BytesToAddress(popSlice(ctx))
-		for _, p := range tracer.activePrecompiles {
+		b := crypto.CreateAddress2(addr, common.HexToHash(salt), codeHash).Bytes()
+		res, err := t.toBuf(vm, b)
+		if err != nil {
+			vm.Interrupt(err)
+			return nil
+		}
+		return res
+	}); err != nil {
+		return err
+	}
+	if err := vm.Set("isPrecompiled", func(v goja.Value) bool {
+		a, err := t.fromBuf(vm, v, true)
+		if err != nil {
+			vm.Interrupt(err)
+			return false
+		}
+		addr := common.BytesToAddress(a)
+		for _, p := range t.activePrecompiles {
 			if p == addr {
-				ctx.PushBoolean(true)
-				return 1
+				return true
 			}
 		}
-		ctx.PushBoolean(false)
-		return 1
-	})
-	tracer.vm.PushGlobalGoFunction("slice", func(ctx *duktape.Context) int {
-		start, end := ctx.GetInt(-2), ctx.GetInt(-1)
-		ctx.Pop2()
-
-		blob := popSlice(ctx)
-		size := end - start
-
-		if start < 0 || start > end || end > len(blob) {
-			// TODO: We can't js-throw from Go inside duktape inside Go. The Go
-			// runtime goes belly up https://github.com/golang/go/issues/15639.
-			log.Warn("Tracer accessed out of bound memory", "available", len(blob), "offset", start, "size", size)
-			ctx.PushFixedBuffer(0)
-			return 1
+		return false
+	}); err != nil {
+		return err
+	}
+	return vm.Set("slice", func(slice goja.Value, start, end int) goja.Value {
+		b, err := t.fromBuf(vm, slice, false)
+		if err != nil {
+			vm.Interrupt(err)
+			return nil
 		}
-		copy(makeSlice(ctx.PushFixedBuffer(size), uint(size)), blob[start:end])
-		return 1
+		if start < 0 || start > end || end > len(b) {
+			vm.Interrupt(fmt.Sprintf("Tracer accessed out of bound memory: available %d, offset %d, size %d", len(b), start, end-start))
+			return nil
+		}
+		res, err := t.toBuf(vm, b[start:end])
+		if err != nil {
+			vm.Interrupt(err)
+			return nil
+		}
+		return res
 	})
-	// Push the JavaScript tracer as object #0 onto the JSVM stack and validate it
-	if err := tracer.vm.PevalString("(" + code + ")"); err != nil {
-		log.Warn("Failed to compile tracer", "err", err)
-		return nil, err
-	}
-	tracer.tracerObject = 0 // yeah, nice, eval can't return the index itself
-
-	hasStep := tracer.vm.GetPropString(tracer.tracerObject, "step")
-	tracer.vm.Pop()
+}
 
-	if !tracer.vm.GetPropString(tracer.tracerObject, "fault") {
-		return nil, fmt.Errorf("trace object must expose a function fault()")
+// setTypeConverters sets up utilities for converting Go types into those
+// suitable for JS consumption.
+func (t *jsTracer) setTypeConverters() error {
+	// Inject bigint logic.
+	// TODO: To be replaced after goja adds support for native JS bigint.
+	toBigCode, err := t.vm.RunProgram(bigIntProgram)
+	if err != nil {
+		return err
 	}
-	tracer.vm.Pop()
-
-	if !tracer.vm.GetPropString(tracer.tracerObject, "result") {
-		return nil, fmt.Errorf("trace object must expose a function result()")
+	// Used to create JS bigint objects from go.
+	toBigFn, ok := goja.AssertFunction(toBigCode)
+	if !ok {
+		return errors.New("failed to bind bigInt func")
 	}
-	tracer.vm.Pop()
-
-	hasEnter := tracer.vm.GetPropString(tracer.tracerObject, "enter")
-	tracer.vm.Pop()
-	hasExit := tracer.vm.GetPropString(tracer.tracerObject, "exit")
-	tracer.vm.Pop()
-	if hasEnter != hasExit {
-		return nil, fmt.Errorf("trace object must expose either both or none of enter() and exit()")
+	toBigWrapper := func(vm *goja.Runtime, val string) (goja.Value, error) {
+		return toBigFn(goja.Undefined(), vm.ToValue(val))
+	}
+	t.toBig = toBigWrapper
+	// NOTE: We need this workaround to create JS buffers because
+	// goja doesn't at the moment expose constructors for typed arrays.
+	//
+	// Cache uint8ArrayType once to be used every time for less overhead.
+	uint8ArrayType := t.vm.Get("Uint8Array")
+	toBufWrapper := func(vm *goja.Runtime, val []byte) (goja.Value, error) {
+		return toBuf(vm, uint8ArrayType, val)
 	}
-	tracer.traceCallFrames = hasEnter && hasExit
-	tracer.traceSteps = hasStep
+	t.toBuf = toBufWrapper
+	fromBufWrapper := func(vm *goja.Runtime, buf goja.Value, allowString bool) ([]byte, error) {
+		return fromBuf(vm, uint8ArrayType, buf, allowString)
+	}
+	t.fromBuf = fromBufWrapper
+	// The JS tracers call bigInt() themselves.
+	return t.vm.Set("bigInt", toBigCode)
+}
 
-	// Tracer is valid, inject the big int library to access large numbers
-	tracer.vm.EvalString(bigIntegerJS)
-	tracer.vm.PutGlobalString("bigInt")
+// opObj provides a JavaScript wrapper around kvm.OpCode.
+type opObj struct {
+	vm *goja.Runtime
+	op kvm.OpCode
+}
 
-	// Push the global environment state as object #1 into the JSVM stack
-	tracer.stateObject = tracer.vm.PushObject()
+func (o *opObj) ToNumber() int {
+	return int(o.op)
+}
 
-	logObject := tracer.vm.PushObject()
+func (o *opObj) ToString() string {
+	return o.op.String()
+}
 
-	tracer.opWrapper.pushObject(tracer.vm)
-	tracer.vm.PutPropString(logObject, "op")
+func (o *opObj) IsPush() bool {
+	return o.op.IsPush()
+}
 
-	tracer.stackWrapper.pushObject(tracer.vm)
-	tracer.vm.PutPropString(logObject, "stack")
+func (o *opObj) setupObject() *goja.Object {
+	obj := o.vm.NewObject()
+	obj.Set("toNumber", o.vm.ToValue(o.ToNumber))
+	obj.Set("toString", o.vm.ToValue(o.ToString))
+	obj.Set("isPush", o.vm.ToValue(o.IsPush))
+	return obj
+}
 
-	tracer.memoryWrapper.pushObject(tracer.vm)
-	tracer.vm.PutPropString(logObject, "memory")
+// memoryObj provides a JavaScript wrapper around kvm.Memory.
+type memoryObj struct {
+	memory *kvm.Memory
+	vm     *goja.Runtime
+	toBig  toBigFn
+	toBuf  toBufFn
+}
 
-	tracer.contractWrapper.pushObject(tracer.vm)
-	tracer.vm.PutPropString(logObject, "contract")
+// Slice returns the requested range of memory as a JS buffer.
+func (mo *memoryObj) Slice(begin, end int64) goja.Value {
+	res, err := mo.toBuf(mo.vm, mo.slice(begin, end))
+	if err != nil {
+		mo.vm.Interrupt(err)
+		return nil
+	}
+	return res
+}
 
-	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushUint(*tracer.pcValue); return 1 })
-	tracer.vm.PutPropString(logObject, "getPC")
+// slice returns the requested range of memory as a byte slice.
+func (mo *memoryObj) slice(begin, end int64) []byte {
+	if end == begin {
+		return []byte{}
+	}
+	if end < begin || begin < 0 {
+		log.Warn("Tracer accessed out of bound memory", "offset", begin, "end", end)
+		return []byte{}
+	}
+	if mo.memory.Len() < int(end) {
+		log.Warn("Tracer accessed out of bound memory", "available", mo.memory.Len(), "offset", begin, "size", end-begin)
+		return []byte{}
+	}
+	return mo.memory.GetCopy(begin, end-begin)
+}
 
-	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushUint(*tracer.gasValue); return 1 })
-	tracer.vm.PutPropString(logObject, "getGas")
+// GetUint returns the 32 bytes at the specified address interpreted as a uint.
+func (mo *memoryObj) GetUint(addr int64) goja.Value {
+	res, err := mo.toBig(mo.vm, mo.getUint(addr).String())
+	if err != nil {
+		mo.vm.Interrupt(err)
+		return nil
+	}
+	return res
+}
 
-	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushUint(*tracer.costValue); return 1 })
-	tracer.vm.PutPropString(logObject, "getCost")
+// getUint returns the 32 bytes at the specified address interpreted as a uint.
+func (mo *memoryObj) getUint(addr int64) *big.Int {
+	if mo.memory.Len() < int(addr)+32 || addr < 0 {
+		log.Warn("Tracer accessed out of bound memory", "available", mo.memory.Len(), "offset", addr, "size", 32)
+		return new(big.Int)
+	}
+	return new(big.Int).SetBytes(mo.memory.GetPtr(addr, 32))
+}
 
-	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushUint(*tracer.depthValue); return 1 })
-	tracer.vm.PutPropString(logObject, "getDepth")
+func (mo *memoryObj) Length() int {
+	return mo.memory.Len()
+}
 
-	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int { ctx.PushUint(*tracer.refundValue); return 1 })
-	tracer.vm.PutPropString(logObject, "getRefund")
+func (mo *memoryObj) setupObject() *goja.Object {
+	o := mo.vm.NewObject()
+	o.Set("slice", mo.vm.ToValue(mo.Slice))
+	o.Set("getUint", mo.vm.ToValue(mo.GetUint))
+	o.Set("length", mo.vm.ToValue(mo.Length))
+	return o
+}
 
-	tracer.vm.PushGoFunction(func(ctx *duktape.Context) int {
-		if tracer.errorValue != nil {
-			ctx.PushString(*tracer.errorValue)
-		} else {
-			ctx.PushUndefined()
-		}
-		return 1
-	})
-	tracer.vm.PutPropString(logObject, "getError")
+// stackObj provides a JavaScript wrapper around kvm.Stack.
+type stackObj struct {
+	stack *kvm.Stack
+	vm    *goja.Runtime
+	toBig toBigFn
+}
 
-	tracer.vm.PutPropString(tracer.stateObject, "log")
+// Peek returns the nth-from-the-top element of the stack.
+func (s *stackObj) Peek(idx int) goja.Value {
+	res, err := s.toBig(s.vm, s.peek(idx).String())
+	if err != nil {
+		s.vm.Interrupt(err)
+		return nil
+	}
+	return res
+}
 
-	tracer.frame.pushObject(tracer.vm)
-	tracer.vm.PutPropString(tracer.stateObject, "frame")
+// peek returns the nth-from-the-top element of the stack.
+func (s *stackObj) peek(idx int) *big.Int {
+	if len(s.stack.Data()) <= idx || idx < 0 {
+		log.Warn("Tracer accessed out of bound stack", "size", len(s.stack.Data()), "index", idx)
+		return new(big.Int)
+	}
+	return s.stack.Back(idx).ToBig()
+}
 
-	tracer.frameResult.pushObject(tracer.vm)
-	tracer.vm.PutPropString(tracer.stateObject, "frameResult")
+func (s *stackObj) Length() int {
+	return len(s.stack.Data())
+}
 
-	tracer.dbWrapper.pushObject(tracer.vm)
-	tracer.vm.PutPropString(tracer.stateObject, "db")
+func (s *stackObj) setupObject() *goja.Object {
+	o := s.vm.NewObject()
+	o.Set("peek", s.vm.ToValue(s.Peek))
+	o.Set("length", s.vm.ToValue(s.Length))
+	return o
+}
 
-	return tracer, nil
+// dbObj provides a JavaScript wrapper around kvm.StateDB.
+type dbObj struct {
+	db      kvm.StateDB
+	vm      *goja.Runtime
+	toBig   toBigFn
+	toBuf   toBufFn
+	fromBuf fromBufFn
 }
 
-// Stop terminates execution of the tracer at the first opportune moment.
-func (jst *jsTracer) Stop(err error) {
-	jst.reason = err
-	atomic.StoreUint32(&jst.interrupt, 1)
+func (do *dbObj) GetBalance(addrSlice goja.Value) goja.Value {
+	a, err := do.fromBuf(do.vm, addrSlice, false)
+	if err != nil {
+		do.vm.Interrupt(err)
+		return nil
+	}
+	addr := common.BytesToAddress(a)
+	value := do.db.GetBalance(addr)
+	res, err := do.toBig(do.vm, value.String())
+	if err != nil {
+		do.vm.Interrupt(err)
+		return nil
+	}
+	return res
 }
 
-// call executes a method on a JS object, catching any errors, formatting and
-// returning them as error objects.
-func (jst *jsTracer) call(noret bool, method string, args ...string) (json.RawMessage, error) {
-	// Execute the JavaScript call and return any error
-	jst.vm.PushString(method)
-	for _, arg := range args {
-		jst.vm.GetPropString(jst.stateObject, arg)
+func (do *dbObj) GetNonce(addrSlice goja.Value) uint64 {
+	a, err := do.fromBuf(do.vm, addrSlice, false)
+	if err != nil {
+		do.vm.Interrupt(err)
+		return 0
 	}
-	code := jst.vm.PcallProp(jst.tracerObject, len(args))
-	defer jst.vm.Pop()
+	addr := common.BytesToAddress(a)
+	return do.db.GetNonce(addr)
+}
 
-	if code != 0 {
-		err := jst.vm.SafeToString(-1)
-		return nil, errors.New(err)
+func (do *dbObj) GetCode(addrSlice goja.Value) goja.Value {
+	a, err := do.fromBuf(do.vm, addrSlice, false)
+	if err != nil {
+		do.vm.Interrupt(err)
+		return nil
 	}
-	// No error occurred, extract return value and return
-	if noret {
-		return nil, nil
+	addr := common.BytesToAddress(a)
+	code := do.db.GetCode(addr)
+	res, err := do.toBuf(do.vm, code)
+	if err != nil {
+		do.vm.Interrupt(err)
+		return nil
 	}
-	// Push a JSON marshaller onto the stack. We can't marshal from the out-
-	// side because duktape can crash on large nestings and we can't catch
-	// C++ exceptions ourselves from Go.
-	jst.vm.PushString("(JSON.stringify)")
-	jst.vm.Eval()
+	return res
+}
 
-	jst.vm.Swap(-1, -2)
-	if code = jst.vm.Pcall(1); code != 0 {
-		err := jst.vm.SafeToString(-1)
-		return nil, errors.New(err)
+func (do *dbObj) GetState(addrSlice goja.Value, hashSlice goja.Value) goja.Value {
+	a, err := do.fromBuf(do.vm, addrSlice, false)
+	if err != nil {
+		do.vm.Interrupt(err)
+		return nil
 	}
-	return json.RawMessage(jst.vm.SafeToString(-1)), nil
+	addr := common.BytesToAddress(a)
+	h, err := do.fromBuf(do.vm, hashSlice, false)
+	if err != nil {
+		do.vm.Interrupt(err)
+		return nil
+	}
+	hash := common.BytesToHash(h)
+	state := do.db.GetState(addr, hash).Bytes()
+	res, err := do.toBuf(do.vm, state)
+	if err != nil {
+		do.vm.Interrupt(err)
+		return nil
+	}
+	return res
 }
 
-func wrapError(context string, err error) error {
-	return fmt.Errorf("%v    in server-side tracer function '%v'", err, context)
+func (do *dbObj) Exists(addrSlice goja.Value) bool {
+	a, err := do.fromBuf(do.vm, addrSlice, false)
+	if err != nil {
+		do.vm.Interrupt(err)
+		return false
+	}
+	addr := common.BytesToAddress(a)
+	return do.db.Exist(addr)
 }
 
-// CaptureStart implements the Tracer interface to initialize the tracing operation.
-func (jst *jsTracer) CaptureStart(env *kvm.KVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
-	jst.env = env
-	jst.ctx["type"] = "CALL"
-	if create {
-		jst.ctx["type"] = "CREATE"
-	}
-	jst.ctx["from"] = from
-	jst.ctx["to"] = to
-	jst.ctx["input"] = input
-	jst.ctx["gas"] = gas
-	jst.ctx["gasPrice"] = env.TxContext.GasPrice
-	jst.ctx["value"] = value
-
-	// Initialize the context
-	jst.ctx["block"] = env.BlockContext.BlockHeight.Uint64()
-	jst.dbWrapper.db = env.StateDB
-	// Update list of precompiles based on current block
-	jst.activePrecompiles = kvm.ActivePrecompiles()
+func (do *dbObj) setupObject() *goja.Object {
+	o := do.vm.NewObject()
+	o.Set("getBalance", do.vm.ToValue(do.GetBalance))
+	o.Set("getNonce", do.vm.ToValue(do.GetNonce))
+	o.Set("getCode", do.vm.ToValue(do.GetCode))
+	o.Set("getState", do.vm.ToValue(do.GetState))
+	o.Set("exists", do.vm.ToValue(do.Exists))
+	return o
+}
 
-	// Compute intrinsic gas
-	height := env.BlockContext.BlockHeight.Uint64()
-	isGalaxias := env.ChainConfig().IsGalaxias(&height)
-	intrinsicGas, err := tx_pool.IntrinsicGas(input, jst.ctx["type"] == "CREATE", !isGalaxias)
+// contractObj provides a JavaScript wrapper around kvm.Contract.
+type contractObj struct {
+	contract *kvm.Contract
+	vm       *goja.Runtime
+	toBig    toBigFn
+	toBuf    toBufFn
+}
+
+func (co *contractObj) GetCaller() goja.Value {
+	caller := co.contract.Caller().Bytes()
+	res, err := co.toBuf(co.vm, caller)
 	if err != nil {
-		return
+		co.vm.Interrupt(err)
+		return nil
 	}
-	jst.ctx["intrinsicGas"] = intrinsicGas
+	return res
 }
 
-// CaptureState implements the Tracer interface to trace a single step of VM execution.
-func (jst *jsTracer) CaptureState(pc uint64, op kvm.OpCode, gas, cost uint64, scope *kvm.ScopeContext, rData []byte, depth int, err error) {
-	if !jst.traceSteps {
-		return
-	}
-	if jst.err != nil {
-		return
-	}
-	// If tracing was interrupted, set the error and stop
-	if atomic.LoadUint32(&jst.interrupt) > 0 {
-		jst.err = jst.reason
-		jst.env.Cancel()
-		return
+func (co *contractObj) GetAddress() goja.Value {
+	addr := co.contract.Address().Bytes()
+	res, err := co.toBuf(co.vm, addr)
+	if err != nil {
+		co.vm.Interrupt(err)
+		return nil
 	}
-	jst.opWrapper.op = op
-	jst.stackWrapper.stack = scope.Stack
-	jst.memoryWrapper.memory = scope.Memory
-	jst.contractWrapper.contract = scope.Contract
-
-	*jst.pcValue = uint(pc)
-	*jst.gasValue = uint(gas)
-	*jst.costValue = uint(cost)
-	*jst.depthValue = uint(depth)
-	*jst.refundValue = uint(jst.env.StateDB.GetRefund())
+	return res
+}
 
-	jst.errorValue = nil
+func (co *contractObj) GetValue() goja.Value {
+	value := co.contract.Value()
+	res, err := co.toBig(co.vm, value.String())
 	if err != nil {
-		jst.errorValue = new(string)
-		*jst.errorValue = err.Error()
+		co.vm.Interrupt(err)
+		return nil
 	}
+	return res
+}
 
-	if _, err := jst.call(true, "step", "log", "db"); err != nil {
-		jst.err = wrapError("step", err)
+func (co *contractObj) GetInput() goja.Value {
+	input := common.CopyBytes(co.contract.Input)
+	res, err := co.toBuf(co.vm, input)
+	if err != nil {
+		co.vm.Interrupt(err)
+		return nil
 	}
+	return res
 }
 
-// CaptureFault implements the Tracer interface to trace an execution fault
-func (jst *jsTracer) CaptureFault(pc uint64, op kvm.OpCode, gas, cost uint64, scope *kvm.ScopeContext, depth int, err error) {
-	if jst.err != nil {
-		return
-	}
-	// Apart from the error, everything matches the previous invocation
-	jst.errorValue = new(string)
-	*jst.errorValue = err.Error()
+func (co *contractObj) setupObject() *goja.Object {
+	o := co.vm.NewObject()
+	o.Set("getCaller", co.vm.ToValue(co.GetCaller))
+	o.Set("getAddress", co.vm.ToValue(co.GetAddress))
+	o.Set("getValue", co.vm.ToValue(co.GetValue))
+	o.Set("getInput", co.vm.ToValue(co.GetInput))
+	return o
+}
 
-	if _, err := jst.call(true, "fault", "log", "db"); err != nil {
-		jst.err = wrapError("fault", err)
-	}
+// callframe represents the entry into a call frame, passed to enter().
+type callframe struct {
+	vm    *goja.Runtime
+	toBig toBigFn
+	toBuf toBufFn
+
+	typ   string
+	from  common.Address
+	to    common.Address
+	input []byte
+	gas   uint
+	value *big.Int
 }
 
-// CaptureEnd is called after the call finishes to finalize the tracing.
-func (jst *jsTracer) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) {
-	jst.ctx["output"] = output
-	jst.ctx["time"] = t.String()
-	jst.ctx["gasUsed"] = gasUsed
+func (f *callframe) GetType() string {
+	return f.typ
+}
 
+func (f *callframe) GetFrom() goja.Value {
+	from := f.from.Bytes()
+	res, err := f.toBuf(f.vm, from)
 	if err != nil {
-		jst.ctx["error"] = err.Error()
+		f.vm.Interrupt(err)
+		return nil
 	}
+	return res
 }
 
-// CaptureEnter is called when EVM enters a new scope (via call, create or selfdestruct).
-func (jst *jsTracer) CaptureEnter(typ kvm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
-	if !jst.traceCallFrames {
-		return
-	}
-	if jst.err != nil {
-		return
-	}
-	// If tracing was interrupted, set the error and stop
-	if atomic.LoadUint32(&jst.interrupt) > 0 {
-		jst.err = jst.reason
-		return
+func (f *callframe) GetTo() goja.Value {
+	to := f.to.Bytes()
+	res, err := f.toBuf(f.vm, to)
+	if err != nil {
+		f.vm.Interrupt(err)
+		return nil
 	}
+	return res
+}
 
-	*jst.frame.typ = typ.String()
-	*jst.frame.from = from
-	*jst.frame.to = to
-	jst.frame.input = common.CopyBytes(input)
-	*jst.frame.gas = uint(gas)
-	jst.frame.value = nil
-	if value != nil {
-		jst.frame.value = new(big.Int).SetBytes(value.Bytes())
+func (f *callframe) GetInput() goja.Value {
+	input := f.input
+	res, err := f.toBuf(f.vm, input)
+	if err != nil {
+		f.vm.Interrupt(err)
+		return nil
 	}
+	return res
+}
 
-	if _, err := jst.call(true, "enter", "frame"); err != nil {
-		jst.err = wrapError("enter", err)
-	}
+func (f *callframe) GetGas() uint {
+	return f.gas
 }
 
-// CaptureExit is called when EVM exits a scope, even if the scope didn't
-// execute any code.
-func (jst *jsTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
-	if !jst.traceCallFrames {
-		return
+func (f *callframe) GetValue() goja.Value {
+	if f.value == nil {
+		return goja.Undefined()
 	}
-	// If tracing was interrupted, set the error and stop
-	if atomic.LoadUint32(&jst.interrupt) > 0 {
-		jst.err = jst.reason
-		return
+	res, err := f.toBig(f.vm, f.value.String())
+	if err != nil {
+		f.vm.Interrupt(err)
+		return nil
 	}
+	return res
+}
+
+func (f *callframe) setupObject() *goja.Object {
+	o := f.vm.NewObject()
+	o.Set("getType", f.vm.ToValue(f.GetType))
+	o.Set("getFrom", f.vm.ToValue(f.GetFrom))
+	o.Set("getTo", f.vm.ToValue(f.GetTo))
+	o.Set("getInput", f.vm.ToValue(f.GetInput))
+	o.Set("getGas", f.vm.ToValue(f.GetGas))
+	o.Set("getValue", f.vm.ToValue(f.GetValue))
+	return o
+}
+
+// callframeResult represents the exit from a call frame, passed to exit().
+type callframeResult struct {
+	vm    *goja.Runtime
+	toBuf toBufFn
+
+	gasUsed uint
+	output  []byte
+	err     error
+}
 
-	jst.frameResult.output = common.CopyBytes(output)
-	*jst.frameResult.gasUsed = uint(gasUsed)
-	jst.frameResult.errorValue = nil
+func (r *callframeResult) GetGasUsed() uint {
+	return r.gasUsed
+}
+
+func (r *callframeResult) GetOutput() goja.Value {
+	res, err := r.toBuf(r.vm, r.output)
 	if err != nil {
-		jst.frameResult.errorValue = new(string)
-		*jst.frameResult.errorValue = err.Error()
+		r.vm.Interrupt(err)
+		return nil
 	}
+	return res
+}
 
-	if _, err := jst.call(true, "exit", "frameResult"); err != nil {
-		jst.err = wrapError("exit", err)
+func (r *callframeResult) GetError() goja.Value {
+	if r.err != nil {
+		return r.vm.ToValue(r.err.Error())
 	}
+	return goja.Undefined()
 }
 
-// GetResult calls the Javascript 'result' function and returns its value, or any accumulated error
-func (jst *jsTracer) GetResult() (json.RawMessage, error) {
-	// Transform the context into a JavaScript object and inject into the state
-	obj := jst.vm.PushObject()
+func (r *callframeResult) setupObject() *goja.Object {
+	o := r.vm.NewObject()
+	o.Set("getGasUsed", r.vm.ToValue(r.GetGasUsed))
+	o.Set("getOutput", r.vm.ToValue(r.GetOutput))
+	o.Set("getError", r.vm.ToValue(r.GetError))
+	return o
+}
 
-	for key, val := range jst.ctx {
-		jst.addToObj(obj, key, val)
-	}
-	jst.vm.PutPropString(jst.stateObject, "ctx")
+// steplog is the log of a single step, passed to step() and fault().
+type steplog struct {
+	vm *goja.Runtime
 
-	// Finalize the trace and return the results
-	result, err := jst.call(false, "result", "ctx", "db")
-	if err != nil {
-		jst.err = wrapError("result", err)
-	}
-	// Clean up the JavaScript environment
-	jst.vm.DestroyHeap()
-	jst.vm.Destroy()
-
-	return result, jst.err
-}
-
-// addToObj pushes a field to a JS object.
-func (jst *jsTracer) addToObj(obj int, key string, val interface{}) {
-	pushValue(jst.vm, val)
-	jst.vm.PutPropString(obj, key)
-}
-
-func pushValue(ctx *duktape.Context, val interface{}) {
-	switch val := val.(type) {
-	case uint64:
-		ctx.PushUint(uint(val))
-	case string:
-		ctx.PushString(val)
-	case []byte:
-		ptr := ctx.PushFixedBuffer(len(val))
-		copy(makeSlice(ptr, uint(len(val))), val)
-	case common.Address:
-		ptr := ctx.PushFixedBuffer(20)
-		copy(makeSlice(ptr, 20), val[:])
-	case *big.Int:
-		pushBigInt(val, ctx)
-	case int:
-		ctx.PushInt(val)
-	case uint:
-		ctx.PushUint(val)
-	case common.Hash:
-		ptr := ctx.PushFixedBuffer(32)
-		copy(makeSlice(ptr, 32), val[:])
-	default:
-		panic(fmt.Sprintf("unsupported type: %T", val))
+	op       *opObj
+	memory   *memoryObj
+	stack    *stackObj
+	contract *contractObj
+
+	pc     uint
+	gas    uint
+	cost   uint
+	depth  uint
+	refund uint
+	err    error
+}
+
+func (l *steplog) GetPC() uint {
+	return l.pc
+}
+
+func (l *steplog) GetGas() uint {
+	return l.gas
+}
+
+func (l *steplog) GetCost() uint {
+	return l.cost
+}
+
+func (l *steplog) GetDepth() uint {
+	return l.depth
+}
+
+func (l *steplog) GetRefund() uint {
+	return l.refund
+}
+
+func (l *steplog) GetError() goja.Value {
+	if l.err != nil {
+		return l.vm.ToValue(l.err.Error())
 	}
+	return goja.Undefined()
+}
+
+func (l *steplog) setupObject() *goja.Object {
+	o := l.vm.NewObject()
+	// Setup basic fields.
+	o.Set("getPC", l.vm.ToValue(l.GetPC))
+	o.Set("getGas", l.vm.ToValue(l.GetGas))
+	o.Set("getCost", l.vm.ToValue(l.GetCost))
+	o.Set("getDepth", l.vm.ToValue(l.GetDepth))
+	o.Set("getRefund", l.vm.ToValue(l.GetRefund))
+	o.Set("getError", l.vm.ToValue(l.GetError))
+	// Setup nested objects.
+	o.Set("op", l.op.setupObject())
+	o.Set("stack", l.stack.setupObject())
+	o.Set("memory", l.memory.setupObject())
+	o.Set("contract", l.contract.setupObject())
+	return o
 }
@@ -52,6 +52,7 @@ type LogConfig struct {
 	DisableStack     bool // disable stack capture
 	DisableStorage   bool // disable storage capture
 	EnableReturnData bool // enable return data capture
+	StorageDiff      bool // capture only the slot accessed by SLOAD/SSTORE instead of all the storage seen so far
 	Debug            bool // print output during capture end
 	Limit            int  // maximum length of output, but zero means unlimited
 }
@@ -102,13 +103,16 @@ func (s *StructLog) ErrorString() string {
 //
 // StructLogger can capture state based on the given Log configuration and also keeps
 // a track record of modified storage which is used in reporting snapshots of the
-// contract their storage.
+// contract their storage. The logs are either kept in memory or, for a streaming
+// logger, written out as soon as they are captured.
 type StructLogger struct {
 	cfg LogConfig
 	env *kvm.KVM
+	out io.Writer // destination of the logs of a streaming logger
 
 	storage map[common.Address]Storage
 	logs    []StructLog
+	count   int // number of logs captured, written out or not
 	output  []byte
 	err     error
 }
@@ -124,6 +128,16 @@ func NewStructLogger(cfg *LogConfig) *StructLogger {
 	return logger
 }
 
+// NewStreamingStructLogger returns a logger writing each log to the given writer,
+// in the WriteTrace format, as soon as it is captured instead of keeping it, so
+// that the memory used doesn't grow with the length of the trace. The
+// StructLogs of such a logger are always empty.
+func NewStreamingStructLogger(cfg *LogConfig, writer io.Writer) *StructLogger {
+	logger := NewStructLogger(cfg)
+	logger.out = writer
+	return logger
+}
+
 // CaptureStart implements the KVMLogger interface to initialize the tracing operation.
 func (l *StructLogger) CaptureStart(env *kvm.KVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
 	l.env = env
@@ -137,7 +151,7 @@ func (l *StructLogger) CaptureState(pc uint64, op kvm.OpCode, gas, cost uint64,
 	stack := scope.Stack
 	contract := scope.Contract
 	// check if already accumulated the specified number of logs
-	if l.cfg.Limit != 0 && l.cfg.Limit <= len(l.logs) {
+	if l.cfg.Limit != 0 && l.cfg.Limit <= l.count {
 		return
 	}
 	// Copy a snapshot of the current memory state to a new buffer
@@ -171,7 +185,7 @@ func (l *StructLogger) CaptureState(pc uint64, op kvm.OpCode, gas, cost uint64,
 				value   = l.env.StateDB.GetState(contract.Address(), address)
 			)
 			l.storage[contract.Address()][address] = value
-			storage = l.storage[contract.Address()].Copy()
+			storage = l.storageSnapshot(contract.Address(), address)
 		} else if op == kvm.SSTORE && stackLen >= 2 {
 			// capture SSTORE opcodes and record the written entry in the local storage.
 			var (
@@ -179,7 +193,7 @@ func (l *StructLogger) CaptureState(pc uint64, op kvm.OpCode, gas, cost uint64,
 				address = common.Hash(stackData[stackLen-1].Bytes32())
 			)
 			l.storage[contract.Address()][address] = value
-			storage = l.storage[contract.Address()].Copy()
+			storage = l.storageSnapshot(contract.Address(), address)
 		}
 	}
 	var rdata []byte
@@ -189,9 +203,23 @@ func (l *StructLogger) CaptureState(pc uint64, op kvm.OpCode, gas, cost uint64,
 	}
 	// create a new snapshot of the KVM.
 	log := StructLog{pc, op, gas, cost, mem, memory.Len(), stck, rdata, storage, depth, l.env.StateDB.GetRefund(), err}
+	l.count++
+	if l.out != nil {
+		WriteTrace(l.out, []StructLog{log})
+		return
+	}
 	l.logs = append(l.logs, log)
 }
 
+// storageSnapshot returns the storage of the contract reported by a log, only
+// the accessed slot if configured so.
+func (l *StructLogger) storageSnapshot(addr common.Address, slot common.Hash) Storage {
+	if l.cfg.StorageDiff {
+		return Storage{slot: l.storage[addr][slot]}
+	}
+	return l.storage[addr].Copy()
+}
+
 // CaptureFault implements the KVMLogger interface to trace an execution fault
 // while running an opcode.
 func (l *StructLogger) CaptureFault(pc uint64, op kvm.OpCode, gas, cost uint64, scope *kvm.ScopeContext, depth int, err error) {
@@ -275,6 +303,7 @@ func (l *StructLogger) Reset() {
 	l.storage = make(map[common.Address]Storage)
 	l.output = make([]byte, 0)
 	l.logs = l.logs[:0]
+	l.count = 0
 	l.err = nil
 }
 
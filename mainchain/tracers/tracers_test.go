@@ -19,7 +19,10 @@
 package tracers
 
 import (
+	"bytes"
+	"encoding/json"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/kardiachain/go-kardia/configs"
@@ -119,3 +122,92 @@ func BenchmarkTransactionTrace(b *testing.B) {
 		tracer.Reset()
 	}
 }
+
+func TestStreamingStructLogger(t *testing.T) {
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	gas := uint64(1000000)
+	to := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	signer := types.HomesteadSigner{}
+	tx, err := types.SignTx(signer, types.NewTransaction(0, to, big.NewInt(0), gas, big.NewInt(500), nil), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	context := kvm.BlockContext{
+		CanTransfer: vm.CanTransfer,
+		Transfer:    vm.Transfer,
+		BlockHeight: new(big.Int).SetUint64(uint64(5)),
+		Time:        new(big.Int).SetUint64(uint64(5)),
+		GasLimit:    gas,
+	}
+	// An endless loop, stopped by running out of gas
+	alloc := map[common.Address]genesis.GenesisAccount{
+		to: {
+			Nonce:   1,
+			Code:    []byte{byte(kvm.JUMPDEST), byte(kvm.PUSH1), 0, byte(kvm.JUMP)},
+			Balance: big.NewInt(1),
+		},
+		from: {
+			Nonce:   1,
+			Balance: big.NewInt(500000000000000),
+		},
+	}
+	statedb := tests.MakePreState(storage.NewMemoryDatabase().DB(), alloc)
+
+	var out bytes.Buffer
+	tracer := logger.NewStreamingStructLogger(&logger.LogConfig{Limit: 10}, &out)
+	txContext := kvm.TxContext{Origin: from, GasPrice: tx.GasPrice()}
+	evm := kvm.NewKVM(context, txContext, statedb, configs.TestChainConfig, kvm.Config{Debug: true, Tracer: tracer})
+	msg, err := tx.AsMessage(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blockchain.NewStateTransition(evm, msg, new(types.GasPool).AddGas(tx.Gas())).TransitionDb(); err != nil {
+		t.Fatal(err)
+	}
+	if have := len(tracer.StructLogs()); have != 0 {
+		t.Fatalf("streaming logger kept %d logs", have)
+	}
+	if have, want := strings.Count(out.String(), "pc="), 10; have != want {
+		t.Fatalf("wrong number of streamed logs, want %d, have %d", want, have)
+	}
+}
+
+func TestIndexedFrameFlatten(t *testing.T) {
+	var frame indexedFrame
+	err := json.Unmarshal([]byte(`{
+		"type": "CALL", "from": "0x00000000000000000000000000000000000000a1", "to": "0x00000000000000000000000000000000000000b2",
+		"value": "0x64", "input": "0xa9059cbb0000",
+		"calls": [
+			{"type": "DELEGATECALL", "from": "0x00000000000000000000000000000000000000b2", "to": "0x00000000000000000000000000000000000000c3", "input": "0x", "error": "execution reverted",
+			 "calls": [{"type": "STATICCALL", "from": "0x00000000000000000000000000000000000000c3", "to": "0x00000000000000000000000000000000000000d4", "input": "0x01"}]},
+			{"type": "CREATE", "from": "0x00000000000000000000000000000000000000b2", "input": "0x6080604052", "error": "out of gas"}
+		]
+	}`), &frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHash := common.HexToHash("0x1234")
+	records := frame.flatten(nil, 7, txHash, 2)
+	if len(records) != 4 {
+		t.Fatalf("have %d records, want 4", len(records))
+	}
+	for i, want := range []string{"CALL", "DELEGATECALL", "STATICCALL", "CREATE"} {
+		r := records[i]
+		if r.Type != want || r.CallIndex != uint32(i) || r.Height != 7 || r.TxIndex != 2 || r.TxHash != txHash {
+			t.Errorf("record %d: have %s #%d at %d/%d, want %s #%d at 7/2", i, r.Type, r.CallIndex, r.Height, r.TxIndex, want, i)
+		}
+	}
+	if !bytes.Equal(records[0].Selector, []byte{0xa9, 0x05, 0x9c, 0xbb}) {
+		t.Errorf("have selector %x, want a9059cbb", records[0].Selector)
+	}
+	if records[0].Value.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("have value %v, want 100", records[0].Value)
+	}
+	if records[1].Error != "execution reverted" || records[1].Selector != nil {
+		t.Errorf("unexpected delegate call record %+v", records[1])
+	}
+	if records[3].To != (common.Address{}) || records[3].Value.Sign() != 0 {
+		t.Errorf("unexpected failed create record %+v", records[3])
+	}
+}
@@ -56,6 +56,14 @@ var (
 	// another remote transaction.
 	ErrTxPoolOverflow = errors.New("txpool is full")
 
+	// ErrAccountRateLimited is returned if a remote account sends transactions
+	// faster than the pool accepts them.
+	ErrAccountRateLimited = errors.New("account rate limit exceeded")
+
+	// ErrAccountBytesExceeded is returned if a remote account's pooled
+	// transactions would grow over the per-account size limit.
+	ErrAccountBytesExceeded = errors.New("account pool size limit exceeded")
+
 	// ErrInvalidSender is returned if the transaction contains an invalid signature.
 	ErrInvalidSender = errors.New("invalid sender")
 
@@ -80,6 +88,18 @@ var (
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
 
+	// ErrSenderNotAllowed is returned if the pool's TxFilter rejects the
+	// transaction sender.
+	ErrSenderNotAllowed = errors.New("sender not allowed")
+
+	// ErrRecipientNotAllowed is returned if the pool's TxFilter rejects the
+	// transaction recipient.
+	ErrRecipientNotAllowed = errors.New("recipient not allowed")
+
+	// ErrContractCreationNotAllowed is returned if the pool's TxFilter rejects
+	// contract creation transactions.
+	ErrContractCreationNotAllowed = errors.New("contract creation not allowed")
+
 	// errNoActiveJournal is returned if a transaction is attempted to be inserted
 	// into the journal, but no such file is currently open.
 	errNoActiveJournal = errors.New("no active journal")
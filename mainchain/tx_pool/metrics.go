@@ -32,6 +32,8 @@ var (
 	MetricPendingReplace   = metricName("pending", "replace")
 	MetricPendingRateLimit = metricName("pending", "rate_limit")
 	MetricPendingNoFunds   = metricName("pending", "no_funds")
+	MetricPendingPromote   = metricName("pending", "promote")
+	MetricPendingEviction  = metricName("pending", "eviction")
 
 	MetricQueuedDiscard   = metricName("queued", "discard")
 	MetricQueuedReplace   = metricName("queued", "replace")
@@ -43,6 +45,7 @@ var (
 	MetricValid            = metricName("", "valid")
 	MetricInvalid          = metricName("", "invalid")
 	MetricUnderPriced      = metricName("", "under_priced")
+	MetricNonceTooLow      = metricName("", "nonce_too_low")
 	MetricOveflowedTx      = metricName("", "overflowed")
 	MetricThrottleTx       = metricName("", "throttle")
 	MetricDropBetweenReorg = metricName("", "dropbetweenreorg")
@@ -56,6 +59,8 @@ var (
 	MetricReorgTime     = metricName("", "reorgtime")
 	MetricReheapTime    = metricName("", "reheap")
 	MetricLockedTxsTime = metricName("time", "locked_txs")
+	MetricAddTime       = metricName("time", "add")
+	MetricRemoveTime    = metricName("time", "remove")
 )
 
 // Setup metrics
@@ -66,6 +71,8 @@ var (
 	pendingReplaceMeter   = metrics.NewRegisteredMeter(MetricPendingReplace, metrics.TxPoolRegistry)
 	pendingRateLimitMeter = metrics.NewRegisteredMeter(MetricPendingRateLimit, metrics.TxPoolRegistry) // Dropped due to rate limiting
 	pendingNoFundsMeter   = metrics.NewRegisteredMeter(MetricPendingNoFunds, metrics.TxPoolRegistry)   // Dropped due to out-of-funds
+	promotedMeter         = metrics.NewRegisteredMeter(MetricPendingPromote, metrics.TxPoolRegistry)   // Moved from queued to pending
+	pendingEvictionMeter  = metrics.NewRegisteredMeter(MetricPendingEviction, metrics.TxPoolRegistry)  // Dropped due to pending lifetime
 
 	// Metrics for the queued pool
 	queuedDiscardMeter   = metrics.NewRegisteredMeter(MetricQueuedDiscard, metrics.TxPoolRegistry)
@@ -79,6 +86,7 @@ var (
 	validTxMeter       = metrics.NewRegisteredMeter(MetricValid, metrics.TxPoolRegistry)
 	invalidTxMeter     = metrics.NewRegisteredMeter(MetricInvalid, metrics.TxPoolRegistry)
 	underpricedTxMeter = metrics.NewRegisteredMeter(MetricUnderPriced, metrics.TxPoolRegistry)
+	nonceTooLowMeter   = metrics.NewRegisteredMeter(MetricNonceTooLow, metrics.TxPoolRegistry)
 	overflowedTxMeter  = metrics.NewRegisteredMeter(MetricOveflowedTx, metrics.TxPoolRegistry)
 	// throttleTxMeter counts how many transactions are rejected due to too-many-changes between
 	// txpool reorgs.
@@ -95,6 +103,8 @@ var (
 	txsTimer           = metrics.NewRegisteredTimer(MetricTxsTime, metrics.TxPoolRegistry)
 	reorgDurationTimer = metrics.NewRegisteredTimer(MetricReheapTime, metrics.TxPoolRegistry)
 	lockedTxsTimer     = metrics.NewRegisteredTimer(MetricLockedTxsTime, metrics.TxPoolRegistry)
+	addTimer           = metrics.NewRegisteredTimer(MetricAddTime, metrics.TxPoolRegistry)
+	removeTimer        = metrics.NewRegisteredTimer(MetricRemoveTime, metrics.TxPoolRegistry)
 	reheapTimer        = metrics.NewRegisteredTimer("txpool/reheap", nil)
 )
 
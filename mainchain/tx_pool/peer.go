@@ -23,7 +23,6 @@ import (
 	"fmt"
 	"sync"
 
-	mapset "github.com/deckarep/golang-set"
 	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/log"
 	"github.com/kardiachain/go-kardia/lib/p2p"
@@ -48,14 +47,6 @@ const (
 	maxQueuedTxAnns = 4096
 )
 
-// max is a helper function which returns the larger of the two given integers.
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
 // PeerInfo represents a short summary of the Kardia sub-protocol metadata known
 // about a connected peer.
 type PeerInfo struct {
@@ -72,7 +63,7 @@ type peer struct {
 	version int // Protocol version negotiated
 
 	txpool      *TxPool            // Transaction pool used by the broadcasters for liveness checks
-	knownTxs    mapset.Set         // Set of transaction hashes known to be known by this peer
+	knownTxs    *common.LRUSet     // Set of transaction hashes known to be known by this peer
 	txBroadcast chan []common.Hash // Channel used to queue transaction propagation requests
 	txAnnounce  chan []common.Hash // Channel used to queue transaction announcement requests
 
@@ -85,7 +76,7 @@ func newPeer(logger log.Logger, p p2p.Peer, txpool *TxPool) *peer {
 		logger:      logger,
 		id:          p.ID(),
 		peer:        p,
-		knownTxs:    mapset.NewSet(),
+		knownTxs:    common.NewLRUSet(maxKnownTxs, 0),
 		txBroadcast: make(chan []common.Hash),
 		txAnnounce:  make(chan []common.Hash),
 		txpool:      txpool,
@@ -271,11 +262,6 @@ func (ps *peerSet) peersWithoutTx(hash common.Hash) []*peer {
 
 // SendTransactions sends transactions to the peer, adds the txn hashes to known txn set.
 func (p *peer) sendTransactions(txs types.Transactions) error {
-	// If we reached the memory allowance, drop a previously known transaction hash
-	for p.knownTxs.Cardinality() > max(0, maxKnownTxs-len(txs)) {
-		p.knownTxs.Pop()
-	}
-
 	for _, tx := range txs {
 		p.knownTxs.Add(tx.Hash())
 	}
@@ -312,11 +298,7 @@ func (p *peer) sendTransactions(txs types.Transactions) error {
 // directly as the queueing (memory) and transmission (bandwidth) costs should
 // not be managed directly.
 func (p *peer) sendPooledTransactionHashes(hashes []common.Hash) error {
-	// Mark all the transactions as known, but ensure we don't overflow our limits
-	for p.knownTxs.Cardinality() > max(0, maxKnownTxs-len(hashes)) {
-		p.knownTxs.Pop()
-	}
-	// Mark all the transactions as known, but ensure we don't overflow our limits
+	// Mark all the transactions as known, the set drops the oldest ones
 	for _, hash := range hashes {
 		p.knownTxs.Add(hash)
 	}
@@ -330,10 +312,6 @@ func (p *peer) sendPooledTransactionHashes(hashes []common.Hash) error {
 // markTransaction marks a transaction as known for the peer, ensuring that it
 // will never be propagated to this particular peer.
 func (p *peer) markTransaction(hash common.Hash) {
-	// If we reached the memory allowance, drop a previously known transaction hash
-	for p.knownTxs.Cardinality() >= maxKnownTxs {
-		p.knownTxs.Pop()
-	}
 	p.knownTxs.Add(hash)
 }
 
@@ -343,10 +321,6 @@ func (p *peer) AsyncSendTransactions(hashes []common.Hash) {
 	// Tx will be actually sent in SendTransactions() trigger by broadcast() routine
 	select {
 	case p.txBroadcast <- hashes:
-		// Mark all the transactions as known, but ensure we don't overflow our limits
-		for p.knownTxs.Cardinality() > max(0, maxKnownTxs-len(hashes)) {
-			p.knownTxs.Pop()
-		}
 		for _, hash := range hashes {
 			p.knownTxs.Add(hash)
 		}
@@ -361,11 +335,7 @@ func (p *peer) AsyncSendTransactions(hashes []common.Hash) {
 func (p *peer) AsyncSendPooledTransactionHashes(hashes []common.Hash) {
 	select {
 	case p.txAnnounce <- hashes:
-		// Mark all the transactions as known, but ensure we don't overflow our limits
-		for p.knownTxs.Cardinality() > max(0, maxKnownTxs-len(hashes)) {
-			p.knownTxs.Pop()
-		}
-		// Mark all the transactions as known, but ensure we don't overflow our limits
+		// Mark all the transactions as known, the set drops the oldest ones
 		for _, hash := range hashes {
 			p.knownTxs.Add(hash)
 		}
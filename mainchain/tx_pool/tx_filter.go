@@ -0,0 +1,80 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tx_pool
+
+import (
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// TxFilter is a policy hook consulted for every transaction entering the pool,
+// local or remote. Returning an error rejects the transaction with it.
+type TxFilter func(tx *types.Transaction, sender common.Address) error
+
+// AllowlistFilter returns a TxFilter accepting only transactions sent by one of
+// senders and, for calls, addressed to one of recipients. An empty list leaves
+// that side unrestricted. Contract creations are rejected if noCreate is set.
+func AllowlistFilter(senders, recipients []common.Address, noCreate bool) TxFilter {
+	allowedSenders, allowedRecipients := addressSet(senders), addressSet(recipients)
+	return func(tx *types.Transaction, sender common.Address) error {
+		if len(allowedSenders) > 0 && !allowedSenders[sender] {
+			return ErrSenderNotAllowed
+		}
+		if tx.To() == nil {
+			if noCreate {
+				return ErrContractCreationNotAllowed
+			}
+			return nil
+		}
+		if len(allowedRecipients) > 0 && !allowedRecipients[*tx.To()] {
+			return ErrRecipientNotAllowed
+		}
+		return nil
+	}
+}
+
+// DenylistFilter returns a TxFilter rejecting transactions sent by any of
+// senders or addressed to any of recipients. Contract creations are rejected
+// too if noCreate is set.
+func DenylistFilter(senders, recipients []common.Address, noCreate bool) TxFilter {
+	deniedSenders, deniedRecipients := addressSet(senders), addressSet(recipients)
+	return func(tx *types.Transaction, sender common.Address) error {
+		if deniedSenders[sender] {
+			return ErrSenderNotAllowed
+		}
+		if tx.To() == nil {
+			if noCreate {
+				return ErrContractCreationNotAllowed
+			}
+			return nil
+		}
+		if deniedRecipients[*tx.To()] {
+			return ErrRecipientNotAllowed
+		}
+		return nil
+	}
+}
+
+func addressSet(addrs []common.Address) map[common.Address]bool {
+	set := make(map[common.Address]bool, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = true
+	}
+	return set
+}
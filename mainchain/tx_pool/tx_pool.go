@@ -92,7 +92,14 @@ type TxPoolConfig struct {
 	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
-	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+	AccountRate  uint64 // Maximum number of transactions accepted per second from a remote account (0 = unlimited)
+	AccountBytes uint64 // Maximum total size of the transactions pooled for a remote account (0 = unlimited)
+	GlobalBytes  uint64 // Maximum total size of all pooled transactions, enforced on remotes (0 = unlimited)
+
+	Lifetime        time.Duration // Maximum amount of time non-executable transaction are queued
+	PendingLifetime time.Duration // Maximum amount of time executable transactions wait for inclusion
+
+	TxFilter TxFilter // Optional policy rejecting transactions at the pool boundary
 
 	// TxReactor
 	Broadcast bool
@@ -115,7 +122,8 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	AccountQueue: 64,
 	GlobalQueue:  1024,
 
-	Lifetime: 1 * time.Hour,
+	Lifetime:        1 * time.Hour,
+	PendingLifetime: 3 * time.Hour,
 
 	Broadcast: true,
 	// Maximum bytes for batch of transactions, this must syncup with the proto txpool reactor
@@ -159,6 +167,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultTxPoolConfig.Lifetime)
 		conf.Lifetime = DefaultTxPoolConfig.Lifetime
 	}
+	if conf.PendingLifetime < 1 {
+		log.Warn("Sanitizing invalid txpool pending lifetime", "provided", conf.PendingLifetime, "updated", DefaultTxPoolConfig.PendingLifetime)
+		conf.PendingLifetime = DefaultTxPoolConfig.PendingLifetime
+	}
 	return conf
 }
 
@@ -176,11 +188,16 @@ func GetDefaultTxPoolConfig(path string) *TxPoolConfig {
 // current state) and future transactions. Transactions move between those
 // two states over time as they are received and processed.
 type TxPool struct {
+	pendingCount int64 // Number of pending transactions, kept first for 64-bit atomic alignment
+	queuedCount  int64 // Number of queued transactions
+
 	config   TxPoolConfig
 	chainCfg *configs.ChainConfig
 	chain    blockChain
 	gasPrice *big.Int
-	txFeed   event.Feed
+	txFeed   events.NewTxsFeed
+	dropFeed events.DroppedTxFeed
+	replFeed events.ReplacedTxFeed
 	scope    event.SubscriptionScope
 	signer   types.Signer
 	mu       sync.RWMutex
@@ -197,6 +214,7 @@ type TxPool struct {
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
 	beats   map[common.Address]time.Time // Last heartbeat from each known account
+	rates   map[common.Address]*txRate   // Transactions accepted from each remote account in the current second
 	all     *txLookup                    // All transactions to allow lookups
 	priced  *txPricedList                // All transactions sorted by price
 
@@ -206,9 +224,11 @@ type TxPool struct {
 	reqPromoteCh      chan *accountSet
 	queueTxEventCh    chan *types.Transaction
 	reorgDoneCh       chan chan struct{}
-	reorgShutdownCh   chan struct{}  // requests shutdown of scheduleReorgLoop
-	changesSinceReorg int            // A counter for how many drops we've performed in-between reorg.
-	wg                sync.WaitGroup // tracks loop, scheduleReorgLoop
+	reorgShutdownCh   chan struct{}            // requests shutdown of scheduleReorgLoop
+	changesSinceReorg int                      // A counter for how many drops we've performed in-between reorg.
+	droppedEvents     []events.DroppedTxEvent  // Drops recorded under the pool lock, sent by sendTxEvents
+	replacedEvents    []events.ReplacedTxEvent // Replacements recorded under the pool lock, sent by sendTxEvents
+	wg                sync.WaitGroup           // tracks loop, scheduleReorgLoop
 
 	// notify listeners (ie. consensus) when txs are available
 	notifiedTxsAvailable bool
@@ -233,6 +253,7 @@ func NewTxPool(config TxPoolConfig, chainCfg *configs.ChainConfig, chain blockCh
 		signer:          types.LatestSigner(chainCfg),
 		pending:         make(map[common.Address]*txList),
 		queue:           make(map[common.Address]*txList),
+		rates:           make(map[common.Address]*txRate),
 		beats:           make(map[common.Address]time.Time),
 		all:             newTxLookup(),
 		chainHeadCh:     make(chan events.ChainHeadEvent, chainHeadChanSize),
@@ -303,13 +324,25 @@ func (pool *TxPool) GetBlockChain() blockChain {
 	return pool.chain
 }
 
+// PendingSize returns the number of processable transactions in the pool.
+// The count is maintained incrementally, so no locking or scanning is needed.
 func (pool *TxPool) PendingSize() int {
-	pending, _ := pool.Pending()
-	pendingSize := 0
-	for _, txs := range pending {
-		pendingSize += txs.Len()
-	}
-	return pendingSize
+	return int(atomic.LoadInt64(&pool.pendingCount))
+}
+
+// QueuedSize returns the number of non-processable transactions in the pool.
+func (pool *TxPool) QueuedSize() int {
+	return int(atomic.LoadInt64(&pool.queuedCount))
+}
+
+// updatePending adjusts the pending transaction counter and its gauge.
+func (pool *TxPool) updatePending(delta int64) {
+	pendingGauge.Update(atomic.AddInt64(&pool.pendingCount, delta))
+}
+
+// updateQueued adjusts the queued transaction counter and its gauge.
+func (pool *TxPool) updateQueued(delta int64) {
+	queuedGauge.Update(atomic.AddInt64(&pool.queuedCount, delta))
 }
 
 // GetPendingData collects transactions from pending and remove them.
@@ -370,6 +403,11 @@ func (pool *TxPool) loop() {
 		// Handle inactive account transaction eviction
 		case <-evict.C:
 			pool.mu.Lock()
+			for addr, rate := range pool.rates {
+				if time.Since(rate.window) > time.Second {
+					delete(pool.rates, addr)
+				}
+			}
 			for addr := range pool.queue {
 				// Skip local transactions from the eviction mechanism
 				if pool.locals.contains(addr) {
@@ -381,10 +419,28 @@ func (pool *TxPool) loop() {
 					for _, tx := range list {
 						pool.removeTx(tx.Hash(), true)
 					}
+					pool.txDropped(events.TxDropExpired, list...)
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
 			}
+			for addr, list := range pool.pending {
+				// Skip local transactions from the eviction mechanism
+				if pool.locals.contains(addr) {
+					continue
+				}
+				// Any non-locals stuck at the same nonce for too long are removed,
+				// highest nonce first to avoid demoting the rest into the queue
+				txs := list.Flatten()
+				if time.Since(txs[0].Time()) > pool.config.PendingLifetime {
+					for i := len(txs) - 1; i >= 0; i-- {
+						pool.removeTx(txs[i].Hash(), true)
+					}
+					pool.txDropped(events.TxDropExpired, txs...)
+					pendingEvictionMeter.Mark(int64(len(txs)))
+				}
+			}
 			pool.mu.Unlock()
+			pool.sendTxEvents()
 
 		// Handle local transaction journal rotation
 		case <-journal.C:
@@ -420,6 +476,54 @@ func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- events.NewTxsEvent) event.Sub
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeDroppedTxEvent registers a subscription of DroppedTxEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeDroppedTxEvent(ch chan<- events.DroppedTxEvent) event.Subscription {
+	return pool.scope.Track(pool.dropFeed.Subscribe(ch))
+}
+
+// SubscribeReplacedTxEvent registers a subscription of ReplacedTxEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeReplacedTxEvent(ch chan<- events.ReplacedTxEvent) event.Subscription {
+	return pool.scope.Track(pool.replFeed.Subscribe(ch))
+}
+
+// txDropped records transactions leaving the pool for the dropped tx feed.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) txDropped(reason events.TxDropReason, txs ...*types.Transaction) {
+	if len(txs) == 0 {
+		return
+	}
+	pool.droppedEvents = append(pool.droppedEvents, events.DroppedTxEvent{Txs: txs, Reason: reason})
+}
+
+// txReplaced records old being replaced by new for the replaced and dropped
+// tx feeds.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) txReplaced(old, new *types.Transaction) {
+	pool.replacedEvents = append(pool.replacedEvents, events.ReplacedTxEvent{Old: old, New: new})
+	pool.txDropped(events.TxDropReplaced, old)
+}
+
+// sendTxEvents delivers the recorded drop and replace events to subscribers.
+// It must be called without holding the pool lock, as sending blocks until
+// the subscribers receive.
+func (pool *TxPool) sendTxEvents() {
+	pool.mu.Lock()
+	dropped, replaced := pool.droppedEvents, pool.replacedEvents
+	pool.droppedEvents, pool.replacedEvents = nil, nil
+	pool.mu.Unlock()
+
+	for _, ev := range replaced {
+		pool.replFeed.Send(ev)
+	}
+	for _, ev := range dropped {
+		pool.dropFeed.Send(ev)
+	}
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -431,6 +535,8 @@ func (pool *TxPool) GasPrice() *big.Int {
 // SetGasPrice updates the minimum price required by the transaction pool for a
 // new transaction, and drops all transactions below this threshold.
 func (pool *TxPool) SetGasPrice(price *big.Int) {
+	defer pool.sendTxEvents()
+
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
@@ -442,6 +548,7 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 			pool.removeTx(tx.Hash(), false)
 		}
 		pool.priced.Removed(len(drop))
+		pool.txDropped(events.TxDropUnderpriced, drop...)
 	}
 
 	log.Info("Transaction pool price threshold updated", "price", price)
@@ -516,17 +623,38 @@ func (pool *TxPool) ContentFrom(addr common.Address) (types.Transactions, types.
 // Pending retrieves all currently processable transactions, grouped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
+//
+// Only the contiguous run of transactions starting at each account's current
+// state nonce is returned, so stale nonces not yet swept by a pool reset and
+// anything behind a nonce gap never reach block proposal. Ordering by price
+// across accounts is left to types.NewTransactionsByPriceAndNonce.
 func (pool *TxPool) Pending() (map[common.Address]types.Transactions, error) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
 	pending := make(map[common.Address]types.Transactions)
 	for addr, list := range pool.pending {
-		pending[addr] = list.Flatten()
+		if txs := contiguousTxs(list.Flatten(), pool.currentState.GetNonce(addr)); len(txs) > 0 {
+			pending[addr] = txs
+		}
 	}
 	return pending, nil
 }
 
+// contiguousTxs returns the longest run of the nonce-sorted txs starting
+// exactly at nonce, skipping the ones below it.
+func contiguousTxs(txs types.Transactions, nonce uint64) types.Transactions {
+	start := 0
+	for start < len(txs) && txs[start].Nonce() < nonce {
+		start++
+	}
+	end := start
+	for end < len(txs) && txs[end].Nonce() == nonce+uint64(end-start) {
+		end++
+	}
+	return txs[start:end]
+}
+
 // Locals retrieves the accounts currently considered local by the pool.
 func (pool *TxPool) Locals() []common.Address {
 	pool.mu.Lock()
@@ -593,6 +721,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if tx.Gas() < intrGas {
 		return ErrIntrinsicGas
 	}
+	// Apply the operator's policy last, once the transaction is known sound
+	if pool.config.TxFilter != nil {
+		return pool.config.TxFilter(tx, from)
+	}
 	return nil
 }
 
@@ -619,8 +751,20 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 	if err := pool.validateTx(tx, isLocal); err != nil {
 		log.Trace("Discarding invalid transaction", "hash", hash, "err", err)
 		invalidTxMeter.Mark(1)
+		if err == ErrNonceTooLow {
+			nonceTooLowMeter.Mark(1)
+		}
 		return false, err
 	}
+	// If the transaction exceeds the remote throttling limits, discard it
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	if !isLocal {
+		if err := pool.throttle(from, tx); err != nil {
+			log.Trace("Discarding throttled transaction", "hash", hash, "from", from, "err", err)
+			throttleTxMeter.Mark(1)
+			return false, err
+		}
+	}
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(pool.all.Slots()+numSlots(tx)) > pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
@@ -656,9 +800,9 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 			underpricedTxMeter.Mark(1)
 			pool.removeTx(tx.Hash(), false)
 		}
+		pool.txDropped(events.TxDropUnderpriced, drop...)
 	}
 	// Try to replace an existing transaction in the pending pool
-	from, _ := types.Sender(pool.signer, tx) // already validated
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
 		// Nonce already pending, check if required price bump is met
 		inserted, old := list.Add(tx, pool.config.PriceBump)
@@ -671,6 +815,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed(1)
 			pendingReplaceMeter.Mark(1)
+			pool.txReplaced(old, tx)
 		}
 		pool.all.Add(tx, isLocal)
 		pool.priced.Put(tx, isLocal)
@@ -702,6 +847,59 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 	return replaced, nil
 }
 
+// txRate counts the transactions accepted from an account within a one second
+// window.
+type txRate struct {
+	window time.Time
+	count  uint64
+}
+
+// throttle checks a remote transaction against the per-account rate and size
+// limits and the global pool size limit, counting it towards the account rate
+// if it passes.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) throttle(from common.Address, tx *types.Transaction) error {
+	size := uint64(tx.Size())
+	if limit := pool.config.GlobalBytes; limit > 0 && pool.all.Size()+size > limit {
+		return ErrTxPoolOverflow
+	}
+	if limit := pool.config.AccountBytes; limit > 0 && pool.accountBytes(from, tx.Nonce())+size > limit {
+		return ErrAccountBytesExceeded
+	}
+	if limit := pool.config.AccountRate; limit > 0 {
+		rate := pool.rates[from]
+		if rate == nil || time.Since(rate.window) > time.Second {
+			rate = &txRate{window: time.Now()}
+			pool.rates[from] = rate
+		}
+		if rate.count >= limit {
+			return ErrAccountRateLimited
+		}
+		rate.count++
+	}
+	return nil
+}
+
+// accountBytes returns the total size of the transactions pooled for addr,
+// leaving out the one with the given nonce as it'd be replaced.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) accountBytes(addr common.Address, nonce uint64) uint64 {
+	var size uint64
+	for _, list := range []*txList{pool.pending[addr], pool.queue[addr]} {
+		if list == nil {
+			continue
+		}
+		for _, tx := range list.txs.items {
+			if tx.Nonce() != nonce {
+				size += uint64(tx.Size())
+			}
+		}
+	}
+	return size
+}
+
 // enqueueTx inserts a new transaction into the non-executable transaction queue.
 //
 // Note, this method assumes the pool lock is held!
@@ -722,9 +920,10 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction, local boo
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		queuedReplaceMeter.Mark(1)
+		pool.txReplaced(old, tx)
 	} else {
 		// Nothing was replaced, bump the queued counter
-		queuedGauge.Inc(1)
+		pool.updateQueued(1)
 	}
 	// If the transaction isn't in lookup set but it's expected to be there,
 	// show the error log.
@@ -771,6 +970,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.all.Remove(hash)
 		pool.priced.Removed(1)
 		pendingDiscardMeter.Mark(1)
+		pool.txDropped(events.TxDropReplaced, tx)
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -778,9 +978,10 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		pendingReplaceMeter.Mark(1)
+		pool.txReplaced(old, tx)
 	} else {
 		// Nothing was replaced, bump the pending counter
-		pendingGauge.Inc(1)
+		pool.updatePending(1)
 	}
 	// Set the potentially new pending nonce and notify any subsystems of the new tx
 	pool.pendingNonces.set(addr, tx.Nonce()+1)
@@ -866,9 +1067,12 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 	}
 
 	// Process all the new transaction and merge any errors into the original slice
+	start := time.Now()
 	pool.mu.Lock()
 	newErrs, dirtyAddrs := pool.addTxsLocked(news, local)
 	pool.mu.Unlock()
+	pool.sendTxEvents()
+	addTimer.UpdateSince(start)
 
 	var nilSlot = 0
 	for _, err := range newErrs {
@@ -942,6 +1146,8 @@ func (pool *TxPool) Has(hash common.Hash) bool {
 // removeTx removes a single transaction from the queue, moving all subsequent
 // transactions back to the future queue.
 func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
+	defer removeTimer.UpdateSince(time.Now())
+
 	// Fetch the transaction we wish to delete
 	tx := pool.all.Get(hash)
 	if tx == nil {
@@ -972,7 +1178,7 @@ func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
 			// Update the account nonce if needed
 			pool.pendingNonces.setIfLower(addr, tx.Nonce())
 			// Reduce the pending counter
-			pendingGauge.Dec(int64(1 + len(invalids)))
+			pool.updatePending(-int64(1 + len(invalids)))
 			return
 		}
 	}
@@ -980,7 +1186,7 @@ func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
 	if future := pool.queue[addr]; future != nil {
 		if removed, _ := future.Remove(tx); removed {
 			// Reduce the queued counter
-			queuedGauge.Dec(1)
+			pool.updateQueued(-1)
 		}
 		if future.Empty() {
 			delete(pool.queue, addr)
@@ -1146,6 +1352,7 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 	dropBetweenReorgHistogram.Update(int64(pool.changesSinceReorg))
 	pool.changesSinceReorg = 0 // Reset counter
 	pool.mu.Unlock()
+	pool.sendTxEvents()
 
 	// Notify subsystems for newly added transactions
 	for _, tx := range promoted {
@@ -1273,6 +1480,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 			hash := tx.Hash()
 			pool.all.Remove(hash)
 		}
+		pool.txDropped(events.TxDropIncluded, forwards...)
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Drop all transactions that are too costly (low balance or out of gas)
 		drops, _ := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
@@ -1280,6 +1488,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 			hash := tx.Hash()
 			pool.all.Remove(hash)
 		}
+		pool.txDropped(events.TxDropInvalid, drops...)
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNoFundsMeter.Mark(int64(len(drops)))
 
@@ -1292,7 +1501,8 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 			}
 		}
 		log.Trace("Promoted queued transactions", "count", len(promoted))
-		queuedGauge.Dec(int64(len(readies)))
+		promotedMeter.Mark(int64(len(promoted)))
+		pool.updateQueued(-int64(len(readies)))
 
 		// Drop all transactions over the allowed limit
 		var caps types.Transactions
@@ -1303,11 +1513,12 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 				pool.all.Remove(hash)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
+			pool.txDropped(events.TxDropOverflow, caps...)
 			queuedRateLimitMeter.Mark(int64(len(caps)))
 		}
 		// Mark all the items dropped as removed
 		pool.priced.Removed(len(forwards) + len(drops) + len(caps))
-		queuedGauge.Dec(int64(len(forwards) + len(drops) + len(caps)))
+		pool.updateQueued(-int64(len(forwards) + len(drops) + len(caps)))
 		if pool.locals.contains(addr) {
 			localGauge.Dec(int64(len(forwards) + len(drops) + len(caps)))
 		}
@@ -1369,7 +1580,8 @@ func (pool *TxPool) truncatePending() {
 						log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 					}
 					pool.priced.Removed(len(caps))
-					pendingGauge.Dec(int64(len(caps)))
+					pool.txDropped(events.TxDropOverflow, caps...)
+					pool.updatePending(-int64(len(caps)))
 					if pool.locals.contains(offenders[i]) {
 						localGauge.Dec(int64(len(caps)))
 					}
@@ -1396,7 +1608,8 @@ func (pool *TxPool) truncatePending() {
 					log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 				}
 				pool.priced.Removed(len(caps))
-				pendingGauge.Dec(int64(len(caps)))
+				pool.txDropped(events.TxDropOverflow, caps...)
+				pool.updatePending(-int64(len(caps)))
 				if pool.locals.contains(addr) {
 					localGauge.Dec(int64(len(caps)))
 				}
@@ -1435,9 +1648,11 @@ func (pool *TxPool) truncateQueue() {
 
 		// Drop all transactions if they are less than the overflow
 		if size := uint64(list.Len()); size <= drop {
-			for _, tx := range list.Flatten() {
+			txs := list.Flatten()
+			for _, tx := range txs {
 				pool.removeTx(tx.Hash(), true)
 			}
+			pool.txDropped(events.TxDropOverflow, txs...)
 			drop -= size
 			queuedRateLimitMeter.Mark(int64(size))
 			continue
@@ -1446,6 +1661,7 @@ func (pool *TxPool) truncateQueue() {
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
 			pool.removeTx(txs[i].Hash(), true)
+			pool.txDropped(events.TxDropOverflow, txs[i])
 			drop--
 			queuedRateLimitMeter.Mark(1)
 		}
@@ -1467,6 +1683,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			pool.all.Remove(hash)
 			log.Trace("Removed old pending transaction", "hash", hash)
 		}
+		pool.txDropped(events.TxDropIncluded, olds...)
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
 		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
 		for _, tx := range drops {
@@ -1474,6 +1691,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
 			pool.all.Remove(hash)
 		}
+		pool.txDropped(events.TxDropInvalid, drops...)
 		pendingNoFundsMeter.Mark(int64(len(drops)))
 
 		for _, tx := range invalids {
@@ -1483,7 +1701,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			// Internal shuffle shouldn't touch the lookup set.
 			pool.enqueueTx(hash, tx, false, false)
 		}
-		pendingGauge.Dec(int64(len(olds) + len(drops) + len(invalids)))
+		pool.updatePending(-int64(len(olds) + len(drops) + len(invalids)))
 		if pool.locals.contains(addr) {
 			localGauge.Dec(int64(len(olds) + len(drops) + len(invalids)))
 		}
@@ -1497,7 +1715,7 @@ func (pool *TxPool) demoteUnexecutables() {
 				// Internal shuffle shouldn't touch the lookup set.
 				pool.enqueueTx(hash, tx, false, false)
 			}
-			pendingGauge.Dec(int64(len(gapped)))
+			pool.updatePending(-int64(len(gapped)))
 		}
 		// Delete the entire pending entry if it became empty.
 		if list.Empty() {
@@ -1606,6 +1824,7 @@ func (as *accountSet) merge(other *accountSet) {
 // to build upper-level structure.
 type txLookup struct {
 	slots   int
+	size    uint64
 	lock    sync.RWMutex
 	locals  map[common.Hash]*types.Transaction
 	remotes map[common.Hash]*types.Transaction
@@ -1701,12 +1920,21 @@ func (t *txLookup) Slots() int {
 	return t.slots
 }
 
+// Size returns the total size in bytes of the transactions in the lookup.
+func (t *txLookup) Size() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.size
+}
+
 // Add adds a transaction to the lookup.
 func (t *txLookup) Add(tx *types.Transaction, local bool) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
 	t.slots += numSlots(tx)
+	t.size += uint64(tx.Size())
 	slotsGauge.Update(int64(t.slots))
 
 	if local {
@@ -1730,6 +1958,7 @@ func (t *txLookup) Remove(hash common.Hash) {
 		return
 	}
 	t.slots -= numSlots(tx)
+	t.size -= uint64(tx.Size())
 	slotsGauge.Update(int64(t.slots))
 
 	delete(t.locals, hash)
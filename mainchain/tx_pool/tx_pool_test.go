@@ -110,6 +110,13 @@ func validateTxPoolInternals(pool *TxPool) error {
 	if total := pool.all.Count(); total != pending+queued {
 		return fmt.Errorf("total transaction count %d != %d pending + %d queued", total, pending, queued)
 	}
+	// Ensure the incremental counters match the actual lists
+	if size := pool.PendingSize(); size != pending {
+		return fmt.Errorf("pending counter mismatch: have %d, want %d", size, pending)
+	}
+	if size := pool.QueuedSize(); size != queued {
+		return fmt.Errorf("queued counter mismatch: have %d, want %d", size, queued)
+	}
 	pool.priced.Reheap()
 	priced, remote := pool.priced.remotes.Len(), pool.all.CountRemote()
 	if priced != remote {
@@ -236,6 +243,67 @@ func TestStateChangeDuringTransactionPoolReset(t *testing.T) {
 	}
 }
 
+// reorgChain is a test chain serving a fixed set of blocks by hash, so that the
+// pool can walk back the old and new branches of a reorg.
+type reorgChain struct {
+	*testBlockChain
+	blocks map[common.Hash]*types.Block
+}
+
+func (c *reorgChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	if block := c.blocks[hash]; block != nil && block.Height() == number {
+		return block
+	}
+	return nil
+}
+
+func (c *reorgChain) newBlock(parent *types.Block, height uint64, txs []*types.Transaction) *types.Block {
+	header := &types.Header{Height: height, GasLimit: c.gasLimit, Time: time.Unix(int64(len(c.blocks)), 0)}
+	if parent != nil {
+		header.LastBlockID = types.BlockID{Hash: parent.Hash()}
+	}
+	block := types.NewBlock(header, txs, nil, nil)
+	c.blocks[block.Hash()] = block
+	return block
+}
+
+// Tests that transactions from blocks abandoned by a reorg are reinjected into
+// the pool, unless the new branch included them as well.
+func TestTransactionReorgReinjection(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(nil, common.Hash{}, state.NewDatabase(memorydb.New()))
+	chain := &reorgChain{&testBlockChain{statedb, 1000000, new(event.Feed)}, make(map[common.Hash]*types.Block)}
+
+	pool := NewTxPool(testTxPoolConfig, configs.TestChainConfig, chain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(account, big.NewInt(1000000000))
+
+	tx0, tx1, tx2 := transaction(0, 100000, key), transaction(1, 100000, key), transaction(2, 100000, key)
+
+	// The old branch included all three transactions, the new one only the first
+	genesis := chain.newBlock(nil, 0, nil)
+	oldHead := chain.newBlock(genesis, 1, []*types.Transaction{tx0, tx1, tx2})
+	newHead := chain.newBlock(chain.newBlock(genesis, 1, []*types.Transaction{tx0}), 2, nil)
+
+	statedb.SetNonce(account, 1)
+	<-pool.requestReset(oldHead.Header(), newHead.Header())
+
+	pending, queued := pool.Content()
+	if len(queued) != 0 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", len(queued), 0)
+	}
+	if txs := pending[account]; len(txs) != 2 || txs[0].Hash() != tx1.Hash() || txs[1].Hash() != tx2.Hash() {
+		t.Fatalf("reinjected transactions mismatched: have %v, want [%x %x]", txs, tx1.Hash(), tx2.Hash())
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 func TestInvalidTransactions(t *testing.T) {
 	t.Parallel()
 
@@ -714,6 +782,203 @@ func TestTransactionGapFilling(t *testing.T) {
 	}
 }
 
+// Tests that the content of the pool is reported per account, with the pending
+// and queued transactions sorted by nonce.
+func TestTransactionContent(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	other, _ := crypto.GenerateKey()
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	otherAccount := crypto.PubkeyToAddress(other.PublicKey)
+	pool.currentState.AddBalance(account, big.NewInt(1000000))
+	pool.currentState.AddBalance(otherAccount, big.NewInt(1000000))
+
+	pool.AddRemotesSync([]*types.Transaction{
+		transaction(1, 100000, key),
+		transaction(0, 100000, key),
+		transaction(3, 100000, key),
+		transaction(1, 100000, other),
+	})
+	pending, queued := pool.Content()
+	if len(pending) != 1 || len(pending[account]) != 2 {
+		t.Fatalf("pending content mismatched: have %v", pending)
+	}
+	for i, tx := range pending[account] {
+		if tx.Nonce() != uint64(i) {
+			t.Errorf("pending transaction %d: nonce mismatch: have %d, want %d", i, tx.Nonce(), i)
+		}
+	}
+	if len(queued) != 2 || len(queued[account]) != 1 || len(queued[otherAccount]) != 1 {
+		t.Fatalf("queued content mismatched: have %v", queued)
+	}
+	if nonce := queued[account][0].Nonce(); nonce != 3 {
+		t.Errorf("queued transaction nonce mismatch: have %d, want %d", nonce, 3)
+	}
+
+	accountPending, accountQueued := pool.ContentFrom(otherAccount)
+	if len(accountPending) != 0 || len(accountQueued) != 1 {
+		t.Fatalf("account content mismatched: have %d pending, %d queued, want 0 and 1", len(accountPending), len(accountQueued))
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 2 {
+		t.Fatalf("stats mismatched: have %d pending, %d queued, want 2 and 2", pending, queued)
+	}
+}
+
+// Tests that Pending only hands out the contiguous nonce run starting at the
+// account's current state nonce, even before a reset sweeps stale or gapped
+// transactions out of the pending lists.
+func TestTransactionPendingContiguous(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(account, big.NewInt(1000000))
+
+	for i := uint64(0); i < 5; i++ {
+		if err := pool.addRemoteSync(transaction(i, 100000, key)); err != nil {
+			t.Fatalf("tx %d: failed to add transaction: %v", i, err)
+		}
+	}
+	// Move the state nonce past the first transaction and punch a gap into the
+	// pending list without letting the pool reorganise itself
+	pool.mu.Lock()
+	pool.currentState.SetNonce(account, 1)
+	pool.pending[account].txs.Remove(3)
+	pool.mu.Unlock()
+
+	pending, err := pool.Pending()
+	if err != nil {
+		t.Fatalf("failed to retrieve pending transactions: %v", err)
+	}
+	txs := pending[account]
+	if len(txs) != 2 {
+		t.Fatalf("pending transaction count mismatch: have %d, want %d", len(txs), 2)
+	}
+	for i, tx := range txs {
+		if want := uint64(i + 1); tx.Nonce() != want {
+			t.Errorf("pending transaction %d: nonce mismatch: have %d, want %d", i, tx.Nonce(), want)
+		}
+	}
+	// An account whose run doesn't start at the state nonce is left out entirely
+	pool.mu.Lock()
+	pool.currentState.SetNonce(account, 3)
+	pool.mu.Unlock()
+
+	if pending, _ := pool.Pending(); len(pending) != 0 {
+		t.Fatalf("pending accounts mismatch: have %d, want %d", len(pending), 0)
+	}
+}
+
+// Tests that the configured TxFilter rejects transactions at the pool boundary
+// according to the allowlist and denylist policies.
+func TestTransactionFilter(t *testing.T) {
+	t.Parallel()
+
+	allowed, _ := crypto.GenerateKey()
+	denied, _ := crypto.GenerateKey()
+	allowedAddr, deniedAddr := crypto.PubkeyToAddress(allowed.PublicKey), crypto.PubkeyToAddress(denied.PublicKey)
+	target := common.HexToAddress("0x0000000000000000000000000000000000000abc")
+
+	call := func(nonce uint64, to common.Address, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignTx(types.HomesteadSigner{}, types.NewTransaction(nonce, to, big.NewInt(0), 100000, big.NewInt(1), nil), key)
+		return tx
+	}
+	create := func(nonce uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignTx(types.HomesteadSigner{}, types.NewContractCreation(nonce, big.NewInt(0), 100000, big.NewInt(1), nil), key)
+		return tx
+	}
+	tests := []struct {
+		name   string
+		filter TxFilter
+		tx     *types.Transaction
+		err    error
+	}{
+		{"allowlist sender", AllowlistFilter([]common.Address{allowedAddr}, nil, false), call(0, target, allowed), nil},
+		{"allowlist other sender", AllowlistFilter([]common.Address{allowedAddr}, nil, false), call(0, target, denied), ErrSenderNotAllowed},
+		{"allowlist other recipient", AllowlistFilter(nil, []common.Address{deniedAddr}, false), call(0, target, allowed), ErrRecipientNotAllowed},
+		{"allowlist creation", AllowlistFilter(nil, []common.Address{deniedAddr}, false), create(0, allowed), nil},
+		{"allowlist no creation", AllowlistFilter(nil, nil, true), create(0, allowed), ErrContractCreationNotAllowed},
+		{"denylist sender", DenylistFilter([]common.Address{deniedAddr}, nil, false), call(0, target, denied), ErrSenderNotAllowed},
+		{"denylist recipient", DenylistFilter(nil, []common.Address{target}, false), call(0, target, allowed), ErrRecipientNotAllowed},
+		{"denylist other", DenylistFilter([]common.Address{deniedAddr}, []common.Address{deniedAddr}, false), call(0, target, allowed), nil},
+		{"denylist no creation", DenylistFilter(nil, nil, true), create(0, allowed), ErrContractCreationNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statedb, _ := state.New(nil, common.Hash{}, state.NewDatabase(memorydb.New()))
+			blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+			config := testTxPoolConfig
+			config.TxFilter = tt.filter
+			pool := NewTxPool(config, configs.TestChainConfig, blockchain)
+			defer pool.Stop()
+
+			pool.currentState.AddBalance(allowedAddr, big.NewInt(1000000000))
+			pool.currentState.AddBalance(deniedAddr, big.NewInt(1000000000))
+
+			if err := pool.AddLocal(tt.tx); err != tt.err {
+				t.Fatalf("filter error mismatch: have %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// Tests that remote transactions are throttled by the per-account rate and size
+// limits and the global size limit, while local ones are exempt.
+func TestTransactionThrottling(t *testing.T) {
+	t.Parallel()
+
+	key, _ := crypto.GenerateKey()
+	size := uint64(transaction(0, 100000, key).Size())
+	tests := []struct {
+		name   string
+		config func(*TxPoolConfig)
+		err    error
+	}{
+		{"rate", func(c *TxPoolConfig) { c.AccountRate = 2 }, ErrAccountRateLimited},
+		{"account bytes", func(c *TxPoolConfig) { c.AccountBytes = 2*size + size/2 }, ErrAccountBytesExceeded},
+		{"global bytes", func(c *TxPoolConfig) { c.GlobalBytes = 2*size + size/2 }, ErrTxPoolOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statedb, _ := state.New(nil, common.Hash{}, state.NewDatabase(memorydb.New()))
+			blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+			config := testTxPoolConfig
+			tt.config(&config)
+			pool := NewTxPool(config, configs.TestChainConfig, blockchain)
+			defer pool.Stop()
+
+			remote, _ := crypto.GenerateKey()
+			local, _ := crypto.GenerateKey()
+			pool.currentState.AddBalance(crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+			pool.currentState.AddBalance(crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+
+			for i := uint64(0); i < 2; i++ {
+				if err := pool.AddRemote(transaction(i, 100000, remote)); err != nil {
+					t.Fatalf("tx %d: failed to add remote transaction: %v", i, err)
+				}
+			}
+			if err := pool.AddRemote(transaction(2, 100000, remote)); err != tt.err {
+				t.Fatalf("throttled remote transaction error mismatch: have %v, want %v", err, tt.err)
+			}
+			for i := uint64(0); i < 3; i++ {
+				if err := pool.AddLocal(transaction(i, 100000, local)); err != nil {
+					t.Fatalf("tx %d: failed to add local transaction: %v", i, err)
+				}
+			}
+			if err := validateTxPoolInternals(pool); err != nil {
+				t.Fatalf("pool internal state corrupted: %v", err)
+			}
+		})
+	}
+}
+
 // Tests that if the transaction count belonging to a single account goes above
 // some threshold, the higher transactions are dropped to prevent DOS attacks.
 func TestTransactionQueueAccountLimiting(t *testing.T) {
@@ -994,6 +1259,67 @@ func testTransactionQueueTimeLimiting(t *testing.T, nolocals bool) {
 	}
 }
 
+// Tests that remote executable transactions stuck in the pending pool for longer
+// than the pending lifetime are evicted and announced as expired, while local
+// ones are kept.
+func TestTransactionPendingTimeLimiting(t *testing.T) {
+	// Reduce the eviction interval to a testable amount
+	defer func(old time.Duration) { evictionInterval = old }(evictionInterval)
+	evictionInterval = time.Millisecond * 100
+
+	statedb, _ := state.New(nil, common.Hash{}, state.NewDatabase(memorydb.New()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.PendingLifetime = time.Second
+
+	pool := NewTxPool(config, configs.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	dropped := make(chan events.DroppedTxEvent, 8)
+	sub := pool.SubscribeDroppedTxEvent(dropped)
+	defer sub.Unsubscribe()
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+
+	if err := pool.AddLocal(transaction(0, 100000, local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	stale := []*types.Transaction{transaction(0, 100000, remote), transaction(1, 100000, remote)}
+	for _, err := range pool.AddRemotesSync(stale) {
+		if err != nil {
+			t.Fatalf("failed to add remote transaction: %v", err)
+		}
+	}
+	if pending, _ := pool.Stats(); pending != 3 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 3)
+	}
+	// Wait for the lifetime to pass and ensure only the local remains
+	time.Sleep(2 * config.PendingLifetime)
+
+	pending, queued := pool.Stats()
+	if pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	if queued != 0 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 0)
+	}
+	select {
+	case ev := <-dropped:
+		if ev.Reason != events.TxDropExpired || len(ev.Txs) != len(stale) {
+			t.Fatalf("drop event mismatch: have %d txs %s, want %d txs %s", len(ev.Txs), ev.Reason, len(stale), events.TxDropExpired)
+		}
+	default:
+		t.Fatalf("expired drop event not fired")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that even if the transaction count belonging to a single account goes
 // above some threshold, as long as the transactions are executable, they are
 // accepted.
@@ -1720,6 +2046,118 @@ func TestTransactionReplacement(t *testing.T) {
 	}
 }
 
+// Tests that adding a batch of transactions synchronously reports why each one
+// was rejected, aligned with the input batch.
+func TestTransactionBatchErrors(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(account, big.NewInt(1000000000))
+	pool.currentState.SetNonce(account, 1)
+	pool.pendingNonces.set(account, 1)
+
+	broke, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(broke.PublicKey), big.NewInt(1))
+	valid := transaction(1, 100000, key)
+
+	txs := []*types.Transaction{
+		valid,
+		transaction(0, 100000, key),
+		valid,
+		transaction(0, 100000, broke),
+		transaction(2, 100000, key),
+	}
+	want := []error{nil, ErrNonceTooLow, ErrAlreadyKnown, ErrInsufficientFunds, nil}
+
+	errs := pool.AddRemotesSync(txs)
+	if len(errs) != len(txs) {
+		t.Fatalf("error count mismatch: have %d, want %d", len(errs), len(txs))
+	}
+	for i, err := range errs {
+		if err != want[i] {
+			t.Errorf("tx %d: error mismatch: have %v, want %v", i, err, want[i])
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pool stats mismatch: have %d pending, %d queued, want 2 and 0", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that transactions leaving the pool are announced on the dropped and
+// replaced transaction feeds along with the reason why.
+func TestTransactionDropReplaceEvents(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	dropped := make(chan events.DroppedTxEvent, 8)
+	dropSub := pool.SubscribeDroppedTxEvent(dropped)
+	defer dropSub.Unsubscribe()
+
+	replaced := make(chan events.ReplacedTxEvent, 8)
+	replSub := pool.SubscribeReplacedTxEvent(replaced)
+	defer replSub.Unsubscribe()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	cheap := pricedTransaction(0, 100000, big.NewInt(1), key)
+	better := pricedTransaction(0, 100000, big.NewInt(2), key)
+	if err := pool.addRemoteSync(cheap); err != nil {
+		t.Fatalf("failed to add original transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(better); err != nil {
+		t.Fatalf("failed to replace original transaction: %v", err)
+	}
+	select {
+	case ev := <-replaced:
+		if ev.Old.Hash() != cheap.Hash() || ev.New.Hash() != better.Hash() {
+			t.Fatalf("replaced event mismatch: have %x -> %x, want %x -> %x", ev.Old.Hash(), ev.New.Hash(), cheap.Hash(), better.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("replaced event not fired")
+	}
+	if err := validateDropEvent(dropped, events.TxDropReplaced, cheap); err != nil {
+		t.Fatalf("replacement drop event failed: %v", err)
+	}
+	// Raising the price limit evicts the remote transaction as underpriced
+	pool.SetGasPrice(big.NewInt(3))
+	if err := validateDropEvent(dropped, events.TxDropUnderpriced, better); err != nil {
+		t.Fatalf("underpriced drop event failed: %v", err)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// validateDropEvent checks that a single drop event for tx with the given reason
+// has been fired on the dropped transaction feed.
+func validateDropEvent(dropped chan events.DroppedTxEvent, reason events.TxDropReason, tx *types.Transaction) error {
+	select {
+	case ev := <-dropped:
+		if ev.Reason != reason {
+			return fmt.Errorf("drop reason mismatch: have %s, want %s", ev.Reason, reason)
+		}
+		if len(ev.Txs) != 1 || ev.Txs[0].Hash() != tx.Hash() {
+			return fmt.Errorf("dropped transactions mismatch: have %v, want %x", ev.Txs, tx.Hash())
+		}
+	case <-time.After(time.Second):
+		return fmt.Errorf("drop event not fired")
+	}
+	select {
+	case ev := <-dropped:
+		return fmt.Errorf("more than one drop event fired: %v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+	return nil
+}
+
 // Tests that local transactions are journaled to disk, but remote transactions
 // get discarded between restarts.
 func TestTransactionJournaling(t *testing.T)         { testTransactionJournaling(t, false) }
@@ -0,0 +1,165 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/kardiachain/go-kardia/kai/accounts"
+	"github.com/kardiachain/go-kardia/kai/accounts/keystore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// AccountBackend is the key management of the node, shared by its RPC APIs
+// and services. It signs with the wallets of the account manager, which
+// always include the keystore of the node.
+//
+// Keystore accounts sign once unlocked, or with their passphrase if one is
+// given.
+type AccountBackend struct {
+	am *accounts.Manager
+	ks *keystore.KeyStore
+}
+
+// newAccountBackend adds a keystore at keydir to am.
+func newAccountBackend(am *accounts.Manager, keydir string, lightKDF bool) *AccountBackend {
+	scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+	if lightKDF {
+		scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	}
+	ks := keystore.NewKeyStore(keydir, scryptN, scryptP)
+	am.AddBackend(ks)
+	return &AccountBackend{am: am, ks: ks}
+}
+
+// Manager returns the account manager.
+func (b *AccountBackend) Manager() *accounts.Manager {
+	return b.am
+}
+
+// KeyStore returns the keystore of the node.
+func (b *AccountBackend) KeyStore() *keystore.KeyStore {
+	return b.ks
+}
+
+// Wallets returns the wallets of every backend, sorted by URL.
+func (b *AccountBackend) Wallets() []accounts.Wallet {
+	return b.am.Wallets()
+}
+
+// Accounts returns the addresses of the accounts of every wallet.
+func (b *AccountBackend) Accounts() []common.Address {
+	return b.am.Accounts()
+}
+
+// NewAccount creates a keystore account encrypted with passphrase.
+func (b *AccountBackend) NewAccount(passphrase string) (common.Address, error) {
+	account, err := b.ks.NewAccount(passphrase)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return account.Address, nil
+}
+
+// Unlock unlocks a keystore account for duration, or until the node stops if
+// duration is 0.
+func (b *AccountBackend) Unlock(addr common.Address, passphrase string, duration time.Duration) error {
+	return b.ks.TimedUnlock(accounts.Account{Address: addr}, passphrase, duration)
+}
+
+// Lock locks a keystore account.
+func (b *AccountBackend) Lock(addr common.Address) error {
+	return b.ks.Lock(addr)
+}
+
+// Derive derives the account at path, such as "m/44'/60'/0'/0/0", from the
+// wallet at url, pinning it to the wallet accounts if pin.
+func (b *AccountBackend) Derive(url string, path string, pin bool) (accounts.Account, error) {
+	wallet, err := b.am.Wallet(url)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	return wallet.Derive(derivationPath, pin)
+}
+
+// SignTx signs tx for chainID with the account addr.
+func (b *AccountBackend) SignTx(addr common.Address, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	account := accounts.Account{Address: addr}
+	wallet, err := b.find(account)
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return wallet.SignTx(account, tx, chainID)
+	}
+	return wallet.SignTxWithPassphrase(account, passphrase, tx, chainID)
+}
+
+// SignTypedData signs EIP-712 typed data with the account addr. The recovery
+// id of the signature is 27 or 28.
+func (b *AccountBackend) SignTypedData(addr common.Address, passphrase string, data *accounts.TypedData) ([]byte, error) {
+	_, raw, err := accounts.TypedDataAndHash(data)
+	if err != nil {
+		return nil, err
+	}
+	account := accounts.Account{Address: addr}
+	wallet, err := b.find(account)
+	if err != nil {
+		return nil, err
+	}
+	var signature []byte
+	if passphrase == "" {
+		signature, err = wallet.SignData(account, accounts.MimetypeTypedData, raw)
+	} else {
+		signature, err = wallet.SignDataWithPassphrase(account, passphrase, accounts.MimetypeTypedData, raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// find returns the wallet of account. The keystore is looked up first as
+// the manager only learns of its new accounts asynchronously.
+func (b *AccountBackend) find(account accounts.Account) (accounts.Wallet, error) {
+	if b.ks.HasAddress(account.Address) {
+		for _, wallet := range b.ks.Wallets() {
+			if wallet.Contains(account) {
+				return wallet, nil
+			}
+		}
+	}
+	return b.am.Find(account)
+}
+
+// TxSigner returns a function signing transactions for chainID with the
+// unlocked account addr, for services submitting transactions on their own.
+func (b *AccountBackend) TxSigner(addr common.Address, chainID *big.Int) func(*types.Transaction) (*types.Transaction, error) {
+	return func(tx *types.Transaction) (*types.Transaction, error) {
+		return b.SignTx(addr, "", tx, chainID)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/accounts"
+	"github.com/kardiachain/go-kardia/kai/accounts/keystore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+func newTestAccountBackend(t *testing.T) *AccountBackend {
+	dir, err := ioutil.TempDir("", "kai-accounts-test")
+	require.NoError(t, err)
+	am := accounts.NewManager(&accounts.Config{})
+	t.Cleanup(func() {
+		am.Close()
+		os.RemoveAll(dir)
+	})
+	return newAccountBackend(am, dir, true)
+}
+
+func TestAccountBackendSignTx(t *testing.T) {
+	b := newTestAccountBackend(t)
+	addr, err := b.NewAccount("secret")
+	require.NoError(t, err)
+	require.True(t, b.KeyStore().HasAddress(addr))
+
+	// Transactions are signed without replay protection, like the rest of
+	// the node does.
+	var chainID *big.Int
+	tx := types.NewTransaction(0, common.HexToAddress("0x1234"), big.NewInt(1), 21000, big.NewInt(1), nil)
+
+	_, err = b.SignTx(addr, "", tx, chainID)
+	require.Equal(t, keystore.ErrLocked, err)
+	_, err = b.SignTx(addr, "wrong", tx, chainID)
+	require.Error(t, err)
+
+	signed, err := b.SignTx(addr, "secret", tx, chainID)
+	require.NoError(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	require.NoError(t, err)
+	require.Equal(t, addr, sender)
+
+	require.NoError(t, b.Unlock(addr, "secret", 0))
+	signed, err = b.TxSigner(addr, chainID)(tx)
+	require.NoError(t, err)
+	sender, err = types.Sender(types.LatestSignerForChainID(chainID), signed)
+	require.NoError(t, err)
+	require.Equal(t, addr, sender)
+
+	require.NoError(t, b.Lock(addr))
+	_, err = b.SignTx(addr, "", tx, chainID)
+	require.Equal(t, keystore.ErrLocked, err)
+
+	_, err = b.SignTx(common.HexToAddress("0x5678"), "secret", tx, chainID)
+	require.Equal(t, accounts.ErrUnknownAccount, err)
+}
+
+func TestAccountBackendSignTypedData(t *testing.T) {
+	b := newTestAccountBackend(t)
+	addr, err := b.NewAccount("secret")
+	require.NoError(t, err)
+
+	data := &accounts.TypedData{
+		Types: accounts.TypedDataTypes{
+			"EIP712Domain": {{Name: "name", Type: "string"}, {Name: "chainId", Type: "uint256"}},
+			"Transfer":     {{Name: "to", Type: "address"}, {Name: "amount", Type: "uint256"}},
+		},
+		PrimaryType: "Transfer",
+		Domain:      map[string]interface{}{"name": "KardiaChain", "chainId": float64(24)},
+		Message:     map[string]interface{}{"to": "0x0000000000000000000000000000000000001234", "amount": "1000"},
+	}
+	signature, err := b.SignTypedData(addr, "secret", data)
+	require.NoError(t, err)
+	require.Contains(t, []byte{27, 28}, signature[crypto.RecoveryIDOffset])
+
+	hash, _, err := accounts.TypedDataAndHash(data)
+	require.NoError(t, err)
+	signature[crypto.RecoveryIDOffset] -= 27
+	pub, err := crypto.SigToPub(hash, signature)
+	require.NoError(t, err)
+	require.Equal(t, addr, crypto.PubkeyToAddress(*pub))
+}
@@ -19,10 +19,23 @@
 package node
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"os"
+	"time"
 
+	"github.com/kardiachain/go-kardia/kai/accounts"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/snapshot"
+	"github.com/kardiachain/go-kardia/kai/storage/kvstore"
+	"github.com/kardiachain/go-kardia/kai/storage/maintenance"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
 	"github.com/kardiachain/go-kardia/lib/p2p"
+	"github.com/kardiachain/go-kardia/lib/rlp"
 	"github.com/kardiachain/go-kardia/rpc"
+	"github.com/kardiachain/go-kardia/types"
 )
 
 // apis returns the collection of built-in RPC APIs.
@@ -33,6 +46,16 @@ func (n *Node) apis() []rpc.API {
 			Version:   "1.0",
 			Service:   &publicAdminAPI{n},
 		},
+		{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   &privateAdminAPI{n},
+		},
+		{
+			Namespace: "personal",
+			Version:   "1.0",
+			Service:   &privatePersonalAPI{n},
+		},
 	}
 }
 
@@ -82,3 +105,308 @@ func (api *publicAdminAPI) NodeInfo() (p2p.NodeInfo, error) {
 	nodeInfo := api.node.sw.NodeInfo()
 	return nodeInfo, nil
 }
+
+// privateAdminAPI is the collection of administrative API methods exposed only
+// over a secure RPC channel.
+type privateAdminAPI struct {
+	node *Node // Node interfaced by this API
+}
+
+// IPFilter is the current state of the p2p ip filter.
+type IPFilter struct {
+	Allowed []string `json:"allowed"`
+	Denied  []string `json:"denied"`
+}
+
+// IPFilter returns the CIDR lists peers are currently filtered with.
+func (api *privateAdminAPI) IPFilter() IPFilter {
+	return IPFilter{
+		Allowed: api.node.ipFilter.Allowed(),
+		Denied:  api.node.ipFilter.Denied(),
+	}
+}
+
+// SetAllowedCIDRs replaces the list of CIDRs peers may connect from. An empty
+// list allows any address. Already connected peers are not affected.
+func (api *privateAdminAPI) SetAllowedCIDRs(cidrs []string) (IPFilter, error) {
+	if err := api.node.ipFilter.SetAllowed(cidrs); err != nil {
+		return IPFilter{}, err
+	}
+	return api.IPFilter(), nil
+}
+
+// SetDeniedCIDRs replaces the list of CIDRs peers are rejected from. Already
+// connected peers are not affected.
+func (api *privateAdminAPI) SetDeniedCIDRs(cidrs []string) (IPFilter, error) {
+	if err := api.node.ipFilter.SetDenied(cidrs); err != nil {
+		return IPFilter{}, err
+	}
+	return api.IPFilter(), nil
+}
+
+// ExportSnapshot writes an archive of the chain data at height to file, at
+// the latest height if 0. The returned manifest holds the checksum which
+// importers should verify the archive against.
+func (api *privateAdminAPI) ExportSnapshot(file string, height uint64) (*snapshot.Manifest, error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := snapshot.Export(api.node.blockStore.DB(), height, f)
+	if err != nil {
+		f.Close()
+		os.Remove(file)
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	api.node.log.Info("Exported snapshot", "file", file, "height", manifest.Height, "checksum", manifest.Checksum.Hex())
+	return manifest, nil
+}
+
+// BackupDatabase writes a backup of the chain database to file, which
+// dbtool restores into the data directory of a stopped node. The database is
+// read from a snapshot, so the node keeps running while it is backed up.
+func (api *privateAdminAPI) BackupDatabase(file string) (*kaidb.BackupStats, error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := kaidb.Backup(api.node.blockStore.DB(), f)
+	if err != nil {
+		f.Close()
+		os.Remove(file)
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	api.node.log.Info("Backed up chain database", "file", file, "count", stats.Count, "size", stats.Size,
+		"checksum", stats.Checksum.Hex())
+	return stats, nil
+}
+
+// ExportChain writes the blocks from height from to height to, up to the head
+// block if to is 0, with their commits to file. Nodes import the chain file at
+// startup with the import flag.
+func (api *privateAdminAPI) ExportChain(file string, from, to uint64) (*kvstore.ChainFileStats, error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := kvstore.ExportChain(api.node.blockStore.DB(), from, to, f)
+	if err != nil {
+		f.Close()
+		os.Remove(file)
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	api.node.log.Info("Exported chain", "file", file, "from", stats.From, "to", stats.To, "checksum", stats.Checksum.Hex())
+	return stats, nil
+}
+
+// InspectDatabase returns the number and size of the keys of every category
+// of the chain database.
+func (api *privateAdminAPI) InspectDatabase() (*maintenance.Inspection, error) {
+	return maintenance.Inspect(api.node.blockStore.DB())
+}
+
+// FindOrphans returns the block store data which does not belong to the
+// canonical chain.
+func (api *privateAdminAPI) FindOrphans() (*maintenance.OrphanReport, error) {
+	return maintenance.FindOrphans(api.node.blockStore.DB())
+}
+
+// CompactDatabase compacts the whole chain database.
+func (api *privateAdminAPI) CompactDatabase() (bool, error) {
+	if err := maintenance.Compact(api.node.blockStore.DB()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CompactStore compacts a store of the chain database: "block", "state" or
+// "evidence".
+func (api *privateAdminAPI) CompactStore(store string) (bool, error) {
+	if err := maintenance.CompactStore(api.node.blockStore.DB(), store); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CompactRange compacts the keys of the chain database between start and end,
+// up to the last key if end is empty.
+func (api *privateAdminAPI) CompactRange(start, end common.Bytes) (bool, error) {
+	if len(end) == 0 {
+		end = nil
+	}
+	if err := api.node.blockStore.DB().Compact(start, end); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CheckDatabase checks the consistency of the block store, the consensus
+// state store and the transaction index between heights from and to, up to
+// the head block if to is 0.
+func (api *privateAdminAPI) CheckDatabase(from, to uint64) (*maintenance.Report, error) {
+	report, err := maintenance.Check(api.node.blockStore.DB(), from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(report.Problems) > 0 {
+		api.node.log.Warn("Chain database is inconsistent", "from", report.From, "to", report.To, "problems", len(report.Problems))
+	}
+	return report, nil
+}
+
+// errLogLevelsUnavailable is returned when the node has no log handler to
+// configure.
+var errLogLevelsUnavailable = errors.New("log levels are not configurable")
+
+// LogLevels is the current state of the log levels.
+type LogLevels struct {
+	Level   string `json:"level"`
+	Modules string `json:"modules"`
+}
+
+// LogLevels returns the default log level and the per-module levels.
+func (api *privateAdminAPI) LogLevels() (LogLevels, error) {
+	h := api.node.config.LogHandler
+	if h == nil {
+		return LogLevels{}, errLogLevelsUnavailable
+	}
+	return LogLevels{Level: h.Level().String(), Modules: h.ModuleLevels()}, nil
+}
+
+// SetLogLevel sets the default log level, such as "info".
+func (api *privateAdminAPI) SetLogLevel(level string) (LogLevels, error) {
+	h := api.node.config.LogHandler
+	if h == nil {
+		return LogLevels{}, errLogLevelsUnavailable
+	}
+	lvl, err := log.LvlFromString(level)
+	if err != nil {
+		return LogLevels{}, err
+	}
+	h.SetLevel(lvl)
+	return api.LogLevels()
+}
+
+// SetModuleLevels replaces the per-module log levels with a comma-separated
+// list of module=level, such as "consensus=debug,p2p=error".
+func (api *privateAdminAPI) SetModuleLevels(ruleset string) (LogLevels, error) {
+	h := api.node.config.LogHandler
+	if h == nil {
+		return LogLevels{}, errLogLevelsUnavailable
+	}
+	if err := h.SetModuleLevels(ruleset); err != nil {
+		return LogLevels{}, err
+	}
+	return api.LogLevels()
+}
+
+// privatePersonalAPI is the collection of key management API methods, backed
+// by the account backend of the node.
+type privatePersonalAPI struct {
+	node *Node // Node interfaced by this API
+}
+
+// Wallet is the status and accounts of a wallet.
+type Wallet struct {
+	URL      string             `json:"url"`
+	Status   string             `json:"status"`
+	Failure  string             `json:"failure,omitempty"`
+	Accounts []accounts.Account `json:"accounts,omitempty"`
+}
+
+// ListAccounts returns the addresses of the accounts of every wallet.
+func (api *privatePersonalAPI) ListAccounts() []common.Address {
+	return api.node.accounts.Accounts()
+}
+
+// ListWallets returns the status and accounts of every wallet.
+func (api *privatePersonalAPI) ListWallets() []Wallet {
+	wallets := make([]Wallet, 0)
+	for _, wallet := range api.node.accounts.Wallets() {
+		status, failure := wallet.Status()
+		raw := Wallet{
+			URL:      wallet.URL().String(),
+			Status:   status,
+			Accounts: wallet.Accounts(),
+		}
+		if failure != nil {
+			raw.Failure = failure.Error()
+		}
+		wallets = append(wallets, raw)
+	}
+	return wallets
+}
+
+// NewAccount creates a keystore account encrypted with password.
+func (api *privatePersonalAPI) NewAccount(password string) (common.Address, error) {
+	addr, err := api.node.accounts.NewAccount(password)
+	if err != nil {
+		return common.Address{}, err
+	}
+	api.node.log.Info("Created account", "address", addr.Hex())
+	return addr, nil
+}
+
+// UnlockAccount unlocks a keystore account for duration seconds, 300 by
+// default and until the node stops if 0.
+func (api *privatePersonalAPI) UnlockAccount(addr common.Address, password string, duration *uint64) (bool, error) {
+	conf := api.node.config
+	if (conf.HTTPHost != "" || conf.WSHost != "") && !api.node.accMan.Config().InsecureUnlockAllowed {
+		return false, errors.New("account unlock with HTTP access is forbidden")
+	}
+	const max = uint64(time.Duration(math.MaxInt64) / time.Second)
+	var d time.Duration
+	switch {
+	case duration == nil:
+		d = 300 * time.Second
+	case *duration > max:
+		return false, errors.New("unlock duration too large")
+	default:
+		d = time.Duration(*duration) * time.Second
+	}
+	if err := api.node.accounts.Unlock(addr, password, d); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LockAccount locks a keystore account.
+func (api *privatePersonalAPI) LockAccount(addr common.Address) bool {
+	return api.node.accounts.Lock(addr) == nil
+}
+
+// DeriveAccount derives the account at path from the wallet at url, pinning
+// it to the wallet accounts if pin is set.
+func (api *privatePersonalAPI) DeriveAccount(url string, path string, pin *bool) (accounts.Account, error) {
+	return api.node.accounts.Derive(url, path, pin != nil && *pin)
+}
+
+// SignTransaction signs an RLP encoded transaction with the account addr for
+// the chain of the node, and returns the signed transaction RLP encoded. An
+// empty password requires the account to be unlocked.
+func (api *privatePersonalAPI) SignTransaction(addr common.Address, encoded common.Bytes, password string) (common.Bytes, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(encoded, tx); err != nil {
+		return nil, err
+	}
+	signed, err := api.node.accounts.SignTx(addr, password, tx, api.node.config.MainChainConfig.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(signed)
+}
+
+// SignTypedData signs EIP-712 typed data with the account addr. An empty
+// password requires the account to be unlocked.
+func (api *privatePersonalAPI) SignTypedData(addr common.Address, data accounts.TypedData, password string) (common.Bytes, error) {
+	return api.node.accounts.SignTypedData(addr, password, &data)
+}
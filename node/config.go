@@ -32,6 +32,7 @@ import (
 	"github.com/kardiachain/go-kardia/configs"
 	"github.com/kardiachain/go-kardia/consensus"
 	"github.com/kardiachain/go-kardia/dualchain/event_pool"
+	"github.com/kardiachain/go-kardia/kai/chainfeed"
 	"github.com/kardiachain/go-kardia/kai/storage"
 	"github.com/kardiachain/go-kardia/lib/crypto"
 	"github.com/kardiachain/go-kardia/lib/log"
@@ -77,7 +78,33 @@ type MainChainConfig struct {
 	// and verifying their commits
 	FastSync *configs.FastSyncConfig
 
+	// StateSync restores the state of an empty node from a snapshot of its
+	// peers, and sets up the snapshots served to them.
+	StateSync *configs.StateSyncConfig
+
+	// ImportChain is a chain file whose blocks are imported at startup,
+	// before syncing with peers.
+	ImportChain string
+
+	// Evidence sets how long the committed evidence is kept.
+	Evidence *configs.EvidenceConfig
+
+	// State sets how long the validators and consensus params records are kept.
+	State *configs.StateConfig
+
+	// Compaction sets how often the stores of the chain database are compacted.
+	Compaction *configs.CompactionConfig
+
+	// Pruning sets which blocks and states are kept.
+	Pruning *configs.PruningConfig
+
+	// TraceIndex indexes the call traces of committed blocks if set
+	TraceIndex *configs.TraceIndexConfig
+
 	GasOracle *oracles.Config
+
+	// ChainFeed streams committed blocks to an external sink if set
+	ChainFeed *chainfeed.Config
 }
 
 // Dualchain configs
@@ -107,6 +134,9 @@ type DualChainConfig struct {
 	// allows them to catchup quickly by downloading blocks in parallel
 	// and verifying their commits
 	FastSync *configs.FastSyncConfig
+
+	// ChainFeed streams committed dual blocks to an external sink if set
+	ChainFeed *chainfeed.Config
 }
 
 // NodeMetadata contains privateKey and votingPower and function that get coinbase
@@ -162,6 +192,22 @@ type Config struct {
 	// InsecureUnlockAllowed allows user to unlock accounts in unsafe http environment.
 	InsecureUnlockAllowed bool
 
+	// PrivValidatorKeyFile is the path of the encrypted validator key file. If it
+	// is empty, the node key is used to sign votes and proposals without any
+	// double sign protection. Relative paths are resolved in the instance directory.
+	PrivValidatorKeyFile string
+
+	// PrivValidatorStateFile is the path of the file recording the last signed
+	// height/round/step of the validator.
+	PrivValidatorStateFile string
+
+	// PrivValidatorBLSKeyFile is the path of the BLS key of the validator, used
+	// to sign its precommits for aggregation. It is generated if missing.
+	PrivValidatorBLSKeyFile string
+
+	// PrivValidatorPassword decrypts the validator key file.
+	PrivValidatorPassword string `toml:"-"`
+
 	// IPCPath is the requested location to place the IPC endpoint. If the path is
 	// a simple file name, it is placed inside the data directory (or on the root
 	// pipe path on Windows), whereas if it's a resolvable path name (absolute or
@@ -229,6 +275,10 @@ type Config struct {
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 
+	// LogHandler is the root log handler, whose levels the admin API may
+	// change at runtime if set.
+	LogHandler *log.ModuleHandler `toml:"-"`
+
 	// Configuration of the Kardia's blockchain (or main chain).
 	MainChainConfig MainChainConfig
 
@@ -40,12 +40,12 @@ const (
 var DefaultConfig = Config{
 	DataDir:          configs.DefaultDataDir(),
 	HTTPPort:         DefaultHTTPPort,
-	HTTPModules:      []string{"node", "kai", "tx", "account"},
+	HTTPModules:      []string{"node", "kai", "tx", "account", "txpool"},
 	HTTPVirtualHosts: []string{"0.0.0.0", "localhost"},
 	HTTPCors:         []string{"*"},
 	HTTPTimeouts:     rpc.DefaultHTTPTimeouts,
 	WSPort:           DefaultWSPort,
-	WSModules:        []string{"node", "kai", "tx", "account"},
+	WSModules:        []string{"node", "kai", "tx", "account", "txpool"},
 	WSOrigins:        []string{"*"},
 	P2P:              configs.DefaultP2PConfig(),
 	MainChainConfig: MainChainConfig{
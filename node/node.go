@@ -44,6 +44,7 @@ import (
 	bs "github.com/kardiachain/go-kardia/lib/service"
 	"github.com/kardiachain/go-kardia/mainchain/tx_pool"
 	"github.com/kardiachain/go-kardia/rpc"
+	"github.com/kardiachain/go-kardia/statesync"
 	"github.com/kardiachain/go-kardia/types"
 	"github.com/kardiachain/go-kardia/types/evidence"
 )
@@ -52,11 +53,28 @@ var (
 	nodeVersion = "1.5.1"
 )
 
+// Versions of the protocols spoken by this node, advertised in the p2p
+// handshake. Bump the version and the lower bound of its compatibility range
+// together when a change can't talk to older peers anymore.
+const (
+	p2pProtocolVersion       uint64 = 1
+	blockProtocolVersion     uint64 = 1
+	appProtocolVersion       uint64 = 1
+	consensusProtocolVersion uint64 = 1
+)
+
+var protocolCompatibility = p2p.ProtocolCompatibility{
+	P2P:       p2p.NewVersionRange(1, p2pProtocolVersion),
+	Block:     p2p.NewVersionRange(1, blockProtocolVersion),
+	Consensus: p2p.NewVersionRange(1, consensusProtocolVersion),
+}
+
 // Node is a container on which services can be registered.
 type Node struct {
 	bs.BaseService
-	sw     *p2p.Switch // p2p connections
-	accMan *accounts.Manager
+	sw       *p2p.Switch // p2p connections
+	accMan   *accounts.Manager
+	accounts *AccountBackend // key management shared by the APIs and services
 
 	eventmux *event.TypeMux // Event multiplexer used between the services of a stack
 	config   *Config
@@ -83,6 +101,7 @@ type Node struct {
 	stateDB    cstate.Store
 	nodeKey    *p2p.NodeKey
 	transport  *p2p.MultiplexTransport
+	ipFilter   *p2p.IPFilter
 	addrBook   pex.AddrBook // known peers
 	pexReactor *pex.Reactor
 }
@@ -150,13 +169,17 @@ func New(conf *Config) (*Node, error) {
 	}
 	node.keyDir = keyDir
 	node.keyDirTemp = isEphem
-	// Creates an empty AccountManager with no backends. Callers (e.g. cmd/main.go)
-	// are required to add the backends later on.
+	// Creates the AccountManager with the keystore backend. Callers may add
+	// other backends later on.
 	node.accMan = accounts.NewManager(&accounts.Config{InsecureUnlockAllowed: conf.InsecureUnlockAllowed})
+	node.accounts = newAccountBackend(node.accMan, keyDir, conf.UseLightweightKDF)
 
 	// Setting up the p2p server
 	nodeKey := &p2p.NodeKey{PrivKey: conf.NodeKey()}
 	state, err := stateDB.LoadStateFromDBOrGenesisDoc(conf.Genesis)
+	if errors.As(err, &cstate.ErrCorruptState{}) {
+		return nil, fmt.Errorf("%w, run dbtool repair-state with the node stopped", err)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -167,11 +190,16 @@ func New(conf *Config) (*Node, error) {
 	}
 
 	// Setup Transport.
-	transport, peerFilters := createTransport(conf, nodeInfo, nodeKey)
+	ipFilter, err := p2p.NewIPFilter(conf.P2P.AllowedCIDRs, conf.P2P.DeniedCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("could not create ip filter: %w", err)
+	}
+	transport, peerFilters := createTransport(conf, nodeInfo, nodeKey, ipFilter)
 
 	// Setup Switch.
+	p2pLogger := logger.New(log.ModuleKey, "p2p")
 	sw := createSwitch(
-		conf, transport, peerFilters, nodeInfo, nodeKey, logger,
+		conf, transport, peerFilters, nodeInfo, nodeKey, p2pLogger,
 	)
 
 	err = sw.AddPersistentPeers(splitAndTrimEmpty(conf.P2P.PersistentPeers, ",", " "))
@@ -184,20 +212,21 @@ func New(conf *Config) (*Node, error) {
 		return nil, fmt.Errorf("could not add peer ids from unconditional_peer_ids field: %w", err)
 	}
 
-	addrBook, err := createAddrBookAndSetOnSwitch(conf, sw, logger, nodeKey)
+	addrBook, err := createAddrBookAndSetOnSwitch(conf, sw, p2pLogger, nodeKey)
 	if err != nil {
 		return nil, fmt.Errorf("could not create addrbook: %w", err)
 	}
 
 	var pexReactor *pex.Reactor
 	if conf.P2P.PexReactor {
-		pexReactor = createPEXReactorAndAddToSwitch(addrBook, conf, sw, logger)
+		pexReactor = createPEXReactorAndAddToSwitch(addrBook, conf, sw, p2pLogger)
 	}
 
 	node.sw = sw
 	node.blockStore = db
 	node.nodeKey = nodeKey
 	node.transport = transport
+	node.ipFilter = ipFilter
 	node.addrBook = addrBook
 	node.pexReactor = pexReactor
 	node.BaseService = *bs.NewBaseService(logger, "Node", node)
@@ -248,6 +277,7 @@ func (n *Node) OnStart() error {
 			BlockStore: n.blockStore,
 			StateDB:    n.stateDB,
 			AccMan:     n.accMan,
+			Accounts:   n.accounts,
 		}
 		for kind, s := range services { // copy needed for threaded access
 			ctx.services[kind] = s
@@ -578,6 +608,11 @@ func (n *Node) AccountManager() *accounts.Manager {
 	return n.accMan
 }
 
+// Accounts retrieves the key management backend of the node.
+func (n *Node) Accounts() *AccountBackend {
+	return n.accounts
+}
+
 // OpenDatabase opens an existing database with the given name (or creates one if no
 // previous can be found) from within the node's instance directory. If the node is
 // ephemeral, a memory database is returned.
@@ -597,6 +632,7 @@ func createTransport(
 	config *Config,
 	nodeInfo p2p.NodeInfo,
 	nodeKey *p2p.NodeKey,
+	gater p2p.ConnGater,
 ) (
 	*p2p.MultiplexTransport,
 	[]p2p.PeerFilterFunc,
@@ -610,6 +646,7 @@ func createTransport(
 	// Limit the number of incoming connections.
 	max := config.P2P.MaxNumInboundPeers + len(splitAndTrimEmpty(config.P2P.UnconditionalPeerIDs, ",", " "))
 	p2p.MultiplexTransportMaxIncomingConnections(max)(transport)
+	p2p.MultiplexTransportConnGater(gater)(transport)
 
 	return transport, peerFilters
 }
@@ -643,11 +680,13 @@ func makeNodeInfo(
 	txIndexerStatus := "on"
 
 	nodeInfo := p2p.DefaultNodeInfo{
-		ProtocolVersion: p2p.NewProtocolVersion(
-			uint64(1), // global
-			uint64(1),
-			uint64(1),
-		),
+		ProtocolVersion: p2p.ProtocolVersion{
+			P2P:       p2pProtocolVersion,
+			Block:     blockProtocolVersion,
+			App:       appProtocolVersion,
+			Consensus: consensusProtocolVersion,
+		},
+		Compatibility: protocolCompatibility,
 		DefaultNodeID: nodeKey.ID(),
 		Network:       state.ChainID,
 		Version:       nodeVersion,
@@ -667,6 +706,9 @@ func makeNodeInfo(
 	if config.FastSync != nil {
 		nodeInfo.Channels = append(nodeInfo.Channels, blockchain.BlockchainChannel)
 	}
+	if config.MainChainConfig.StateSync != nil {
+		nodeInfo.Channels = append(nodeInfo.Channels, statesync.SnapshotChannel, statesync.ChunkChannel)
+	}
 
 	lAddr := config.P2P.ExternalAddress
 
@@ -732,8 +774,10 @@ func createPEXReactorAndAddToSwitch(addrBook pex.AddrBook, config *Config,
 	// TODO persistent peers ? so we can have their DNS addrs saved
 	pexReactor := pex.NewReactor(addrBook,
 		&pex.ReactorConfig{
-			Seeds:    config.P2P.Seeds,
-			SeedMode: config.P2P.SeedMode,
+			Seeds:                 config.P2P.Seeds,
+			DNSSeeds:              config.P2P.DNSSeeds,
+			DNSSeedsRefreshPeriod: config.P2P.DNSSeedsRefreshPeriod,
+			SeedMode:              config.P2P.SeedMode,
 			// blocksToContributeToBecomeGoodPeer 10000
 			// blocks assuming 5s+ blocks ~ 14 hours.
 			SeedDisconnectWaitPeriod:     14 * time.Hour,
@@ -40,6 +40,7 @@ type ServiceContext struct {
 	BlockStore types.StoreDB
 	StateDB    cstate.Store
 	AccMan     *accounts.Manager
+	Accounts   *AccountBackend
 }
 
 // ResolvePath resolves a user path into the data directory if that was relative
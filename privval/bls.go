@@ -0,0 +1,63 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package privval
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/kardiachain/go-kardia/lib/crypto/bls"
+	"github.com/kardiachain/go-kardia/lib/tempfile"
+)
+
+// LoadBLSKey loads the hex encoded BLS secret key of filePath.
+func LoadBLSKey(filePath string) (*bls.SecretKey, error) {
+	keyHex, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	keyBytes, err := hex.DecodeString(strings.TrimSpace(string(keyHex)))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding BLS key from %v: %w", filePath, err)
+	}
+	key, err := bls.SecretKeyFromBytes(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding BLS key from %v: %w", filePath, err)
+	}
+	return key, nil
+}
+
+// LoadOrGenBLSKey loads the BLS secret key of filePath or else generates a
+// new one and saves it to filePath.
+func LoadOrGenBLSKey(filePath string) (*bls.SecretKey, error) {
+	if _, err := os.Stat(filePath); err == nil {
+		return LoadBLSKey(filePath)
+	}
+	key, err := bls.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := tempfile.WriteFileAtomic(filePath, []byte(hex.EncodeToString(key.Bytes())), 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
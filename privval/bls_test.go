@@ -0,0 +1,69 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package privval
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/lib/crypto/bls"
+	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+func TestLoadOrGenBLSKey(t *testing.T) {
+	keyFile, stateFile, cleanup := tempPVFiles(t)
+	defer cleanup()
+	blsFile := filepath.Join(filepath.Dir(keyFile), "bls_key")
+
+	key, err := LoadOrGenBLSKey(blsFile)
+	require.NoError(t, err)
+	loaded, err := LoadOrGenBLSKey(blsFile)
+	require.NoError(t, err)
+	assert.Equal(t, key.PublicKey(), loaded.PublicKey())
+
+	require.NoError(t, ioutil.WriteFile(blsFile, []byte("zz"), 0600))
+	_, err = LoadBLSKey(blsFile)
+	assert.Error(t, err)
+
+	// Precommits re-signed by the file validator with an earlier timestamp
+	// are BLS signed with that timestamp.
+	pv, err := LoadOrGenFilePV(keyFile, stateFile, "secret")
+	require.NoError(t, err)
+	blsPV := types.NewBLSPrivValidator(pv, key)
+	val := blsPV.ExtractIntoValidator(10)
+	assert.Equal(t, key.PublicKey(), val.BLSPubKey)
+
+	vote := newVote(pv.GetAddress(), 10, 1, kproto.PrecommitType, newBlockID(1))
+	require.NoError(t, blsPV.SignVote(chainID, vote))
+	later := newVote(pv.GetAddress(), 10, 1, kproto.PrecommitType, newBlockID(1))
+	later.Timestamp = vote.Timestamp.Add(time.Second)
+	require.NoError(t, blsPV.SignVote(chainID, later))
+	assert.Equal(t, vote.BlsSignature, later.BlsSignature)
+	assert.True(t, bls.Verify(val.BLSPubKey, types.VoteSignBytes(chainID, later), later.BlsSignature))
+
+	prevote := newVote(pv.GetAddress(), 11, 1, kproto.PrevoteType, newBlockID(1))
+	require.NoError(t, blsPV.SignVote(chainID, prevote))
+	assert.Empty(t, prevote.BlsSignature)
+}
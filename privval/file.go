@@ -0,0 +1,474 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package privval
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/google/uuid"
+
+	"github.com/kardiachain/go-kardia/kai/accounts/keystore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/protoio"
+	"github.com/kardiachain/go-kardia/lib/tempfile"
+	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+// DefaultStateFile is the name of the state file used when none is configured.
+const DefaultStateFile = "priv_validator_state.json"
+
+// Signing steps, ordered as they happen within a round.
+const (
+	stepNone      int8 = 0 // Used to distinguish the initial state
+	stepPropose   int8 = 1
+	stepPrevote   int8 = 2
+	stepPrecommit int8 = 3
+)
+
+// Scrypt parameters used to encrypt the validator key. Tests lower them to
+// keep key generation fast.
+var (
+	scryptN = keystore.StandardScryptN
+	scryptP = keystore.StandardScryptP
+)
+
+var (
+	// ErrHeightRegression is returned when asked to sign below the last signed height.
+	ErrHeightRegression = errors.New("height regression")
+	// ErrRoundRegression is returned when asked to sign below the last signed round.
+	ErrRoundRegression = errors.New("round regression")
+	// ErrStepRegression is returned when asked to sign below the last signed step.
+	ErrStepRegression = errors.New("step regression")
+	// ErrConflictingData is returned when asked to sign different data at the
+	// last signed height/round/step.
+	ErrConflictingData = errors.New("conflicting data")
+)
+
+func voteToStep(vote *kproto.Vote) int8 {
+	switch vote.Type {
+	case kproto.PrevoteType:
+		return stepPrevote
+	case kproto.PrecommitType:
+		return stepPrecommit
+	default:
+		panic(fmt.Sprintf("Unknown vote type: %v", vote.Type))
+	}
+}
+
+//-------------------------------------------------------------------------------
+
+// FilePVKey stores the validator private key, encrypted at rest using the
+// keystore (scrypt + AES-128-CTR) format.
+type FilePVKey struct {
+	Address common.Address
+	PrivKey *ecdsa.PrivateKey
+
+	filePath string
+	password string
+}
+
+// Save encrypts the key with the password and persists it to filePath.
+func (pvKey FilePVKey) Save() error {
+	if pvKey.filePath == "" {
+		return errors.New("cannot save PrivValidator key: filePath not set")
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+	key := &keystore.Key{Id: id, Address: pvKey.Address, PrivateKey: pvKey.PrivKey}
+	keyJSON, err := keystore.EncryptKey(key, pvKey.password, scryptN, scryptP)
+	if err != nil {
+		return err
+	}
+	return tempfile.WriteFileAtomic(pvKey.filePath, keyJSON, 0600)
+}
+
+//-------------------------------------------------------------------------------
+
+// FilePVLastSignState stores the mutable part of PrivValidator, i.e. the last
+// height/round/step signed along with the sign bytes and signature, so that
+// the validator never double signs across restarts.
+type FilePVLastSignState struct {
+	Height    uint64 `json:"height"`
+	Round     uint32 `json:"round"`
+	Step      int8   `json:"step"`
+	Signature []byte `json:"signature,omitempty"`
+	SignBytes []byte `json:"signbytes,omitempty"`
+
+	filePath string
+}
+
+// CheckHRS checks the given height, round, step (HRS) against that of the
+// FilePVLastSignState. It returns an error if the arguments constitute a
+// regression, or if they match but the SignBytes are empty.
+// The returned boolean indicates whether the last Signature should be reused -
+// it returns true if the HRS matches the arguments and the SignBytes are not
+// empty (indicating we have already signed for this HRS, and can reuse the
+// existing signature).
+func (lss *FilePVLastSignState) CheckHRS(height uint64, round uint32, step int8) (bool, error) {
+	if lss.Height > height {
+		return false, fmt.Errorf("%w: got %v, last height %v", ErrHeightRegression, height, lss.Height)
+	}
+	if lss.Height == height {
+		if lss.Round > round {
+			return false, fmt.Errorf("%w at height %v: got %v, last round %v", ErrRoundRegression, height, round, lss.Round)
+		}
+		if lss.Round == round {
+			if lss.Step > step {
+				return false, fmt.Errorf("%w at height %v round %v: got %v, last step %v",
+					ErrStepRegression, height, round, step, lss.Step)
+			} else if lss.Step == step {
+				if lss.SignBytes != nil {
+					if lss.Signature == nil {
+						panic("pv: Signature is nil but SignBytes is not!")
+					}
+					return true, nil
+				}
+				return false, errors.New("no SignBytes found")
+			}
+		}
+	}
+	return false, nil
+}
+
+// Save persists the FilePVLastSignState to its filePath.
+func (lss *FilePVLastSignState) Save() error {
+	if lss.filePath == "" {
+		return errors.New("cannot save FilePVLastSignState: filePath not set")
+	}
+	jsonBytes, err := json.MarshalIndent(lss, "", "  ")
+	if err != nil {
+		return err
+	}
+	return tempfile.WriteFileAtomic(lss.filePath, jsonBytes, 0600)
+}
+
+//-------------------------------------------------------------------------------
+
+// FilePV implements types.PrivValidator using an encrypted key file and a
+// separate state file. The key file is written once and never modified, the
+// state file is updated every time a vote or proposal is signed.
+type FilePV struct {
+	Key           FilePVKey
+	LastSignState FilePVLastSignState
+}
+
+// NewFilePV generates a new validator from the given key and paths.
+func NewFilePV(privKey *ecdsa.PrivateKey, keyFilePath, stateFilePath, password string) *FilePV {
+	return &FilePV{
+		Key: FilePVKey{
+			Address:  crypto.PubkeyToAddress(privKey.PublicKey),
+			PrivKey:  privKey,
+			filePath: keyFilePath,
+			password: password,
+		},
+		LastSignState: FilePVLastSignState{
+			Step:     stepNone,
+			filePath: stateFilePath,
+		},
+	}
+}
+
+// GenFilePV generates a new validator with a randomly generated private key
+// and sets the filePaths, but does not call Save().
+func GenFilePV(keyFilePath, stateFilePath, password string) (*FilePV, error) {
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return NewFilePV(privKey, keyFilePath, stateFilePath, password), nil
+}
+
+// LoadFilePV loads a FilePV from the key and state files, decrypting the key
+// with the given password.
+func LoadFilePV(keyFilePath, stateFilePath, password string) (*FilePV, error) {
+	keyJSON, err := ioutil.ReadFile(keyFilePath)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting PrivValidator key from %v: %w", keyFilePath, err)
+	}
+
+	pvState := FilePVLastSignState{}
+	stateJSON, err := ioutil.ReadFile(stateFilePath)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(stateJSON, &pvState); err != nil {
+			return nil, fmt.Errorf("error reading PrivValidator state from %v: %w", stateFilePath, err)
+		}
+	case os.IsNotExist(err):
+		// A missing state file means nothing has been signed yet.
+	default:
+		return nil, err
+	}
+	pvState.filePath = stateFilePath
+
+	return &FilePV{
+		Key: FilePVKey{
+			Address:  key.Address,
+			PrivKey:  key.PrivateKey,
+			filePath: keyFilePath,
+			password: password,
+		},
+		LastSignState: pvState,
+	}, nil
+}
+
+// LoadOrGenFilePV loads a FilePV from the given filePaths or else generates a
+// new one and saves it to the filePaths.
+func LoadOrGenFilePV(keyFilePath, stateFilePath, password string) (*FilePV, error) {
+	if _, err := os.Stat(keyFilePath); err == nil {
+		return LoadFilePV(keyFilePath, stateFilePath, password)
+	}
+	pv, err := GenFilePV(keyFilePath, stateFilePath, password)
+	if err != nil {
+		return nil, err
+	}
+	if err := pv.Save(); err != nil {
+		return nil, err
+	}
+	return pv, nil
+}
+
+// GetAddress returns the address of the validator.
+// Implements PrivValidator.
+func (pv *FilePV) GetAddress() common.Address {
+	return pv.Key.Address
+}
+
+// GetPubKey returns the public key of the validator.
+// Implements PrivValidator.
+func (pv *FilePV) GetPubKey() ecdsa.PublicKey {
+	return pv.Key.PrivKey.PublicKey
+}
+
+// SignVote signs a canonical representation of the vote, along with the
+// chainID. Implements PrivValidator.
+func (pv *FilePV) SignVote(chainID string, vote *kproto.Vote) error {
+	if err := pv.signVote(chainID, vote); err != nil {
+		return fmt.Errorf("error signing vote: %w", err)
+	}
+	return nil
+}
+
+// SignProposal signs a canonical representation of the proposal, along with
+// the chainID. Implements PrivValidator.
+func (pv *FilePV) SignProposal(chainID string, proposal *kproto.Proposal) error {
+	if err := pv.signProposal(chainID, proposal); err != nil {
+		return fmt.Errorf("error signing proposal: %w", err)
+	}
+	return nil
+}
+
+// ExtractIntoValidator implements PrivValidator.
+func (pv *FilePV) ExtractIntoValidator(votingPower int64) *types.Validator {
+	return &types.Validator{
+		Address:     pv.GetAddress(),
+		VotingPower: votingPower,
+	}
+}
+
+// Save persists the FilePV key and state to disk.
+func (pv *FilePV) Save() error {
+	if err := pv.Key.Save(); err != nil {
+		return err
+	}
+	return pv.LastSignState.Save()
+}
+
+// String returns a string representation of the FilePV.
+func (pv *FilePV) String() string {
+	return fmt.Sprintf(
+		"PrivValidator{%v LH:%v, LR:%v, LS:%v}",
+		pv.GetAddress().Hex(),
+		pv.LastSignState.Height,
+		pv.LastSignState.Round,
+		pv.LastSignState.Step,
+	)
+}
+
+//------------------------------------------------------------------------------------
+
+// signVote checks if the vote is good to sign and sets the vote signature.
+// It may need to set the timestamp as well if the vote is otherwise the same as
+// a previously signed vote (ie. we crashed after signing but before the vote hit the WAL).
+func (pv *FilePV) signVote(chainID string, vote *kproto.Vote) error {
+	height, round, step := vote.Height, vote.Round, voteToStep(vote)
+
+	lss := pv.LastSignState
+
+	sameHRS, err := lss.CheckHRS(height, round, step)
+	if err != nil {
+		return err
+	}
+
+	signBytes := types.VoteSignBytes(chainID, vote)
+
+	// We might crash before writing to the wal,
+	// causing us to try to re-sign for the same HRS.
+	// If signbytes are the same, use the last signature.
+	// If they only differ by timestamp, use last timestamp and signature
+	// Otherwise, return error
+	if sameHRS {
+		if bytes.Equal(signBytes, lss.SignBytes) {
+			vote.Signature = lss.Signature
+		} else if timestamp, ok := checkVotesOnlyDifferByTimestamp(lss.SignBytes, signBytes); ok {
+			vote.Timestamp = timestamp
+			vote.Signature = lss.Signature
+		} else {
+			err = ErrConflictingData
+		}
+		return err
+	}
+
+	// It passed the checks. Sign the vote
+	sig, err := crypto.Sign(crypto.Keccak256(signBytes), pv.Key.PrivKey)
+	if err != nil {
+		return err
+	}
+	if err := pv.saveSigned(height, round, step, signBytes, sig); err != nil {
+		return err
+	}
+	vote.Signature = sig
+	return nil
+}
+
+// signProposal checks if the proposal is good to sign and sets the proposal signature.
+// It may need to set the timestamp as well if the proposal is otherwise the same as
+// a previously signed proposal ie. we crashed after signing but before the proposal hit the WAL).
+func (pv *FilePV) signProposal(chainID string, proposal *kproto.Proposal) error {
+	height, round, step := proposal.Height, proposal.Round, stepPropose
+
+	lss := pv.LastSignState
+
+	sameHRS, err := lss.CheckHRS(height, round, step)
+	if err != nil {
+		return err
+	}
+
+	signBytes := types.ProposalSignBytes(chainID, proposal)
+
+	// We might crash before writing to the wal,
+	// causing us to try to re-sign for the same HRS.
+	// If signbytes are the same, use the last signature.
+	// If they only differ by timestamp, use last timestamp and signature
+	// Otherwise, return error
+	if sameHRS {
+		if bytes.Equal(signBytes, lss.SignBytes) {
+			proposal.Signature = lss.Signature
+		} else if timestamp, ok := checkProposalsOnlyDifferByTimestamp(lss.SignBytes, signBytes); ok {
+			proposal.Timestamp = timestamp
+			proposal.Signature = lss.Signature
+		} else {
+			err = ErrConflictingData
+		}
+		return err
+	}
+
+	// It passed the checks. Sign the proposal
+	sig, err := crypto.Sign(crypto.Keccak256(signBytes), pv.Key.PrivKey)
+	if err != nil {
+		return err
+	}
+	if err := pv.saveSigned(height, round, step, signBytes, sig); err != nil {
+		return err
+	}
+	proposal.Signature = sig
+	return nil
+}
+
+// saveSigned persists the height/round/step and signature before the
+// signature is handed back to consensus.
+func (pv *FilePV) saveSigned(height uint64, round uint32, step int8, signBytes []byte, sig []byte) error {
+	pv.LastSignState.Height = height
+	pv.LastSignState.Round = round
+	pv.LastSignState.Step = step
+	pv.LastSignState.Signature = sig
+	pv.LastSignState.SignBytes = signBytes
+	return pv.LastSignState.Save()
+}
+
+//-----------------------------------------------------------------------------------------
+
+// checkVotesOnlyDifferByTimestamp returns the timestamp from the lastSignBytes
+// and true if the only difference in the votes is their timestamp.
+func checkVotesOnlyDifferByTimestamp(lastSignBytes, newSignBytes []byte) (time.Time, bool) {
+	var lastVote, newVote kproto.CanonicalVote
+	if err := protoio.UnmarshalDelimited(lastSignBytes, &lastVote); err != nil {
+		panic(fmt.Sprintf("LastSignBytes cannot be unmarshalled into vote: %v", err))
+	}
+	if err := protoio.UnmarshalDelimited(newSignBytes, &newVote); err != nil {
+		panic(fmt.Sprintf("signBytes cannot be unmarshalled into vote: %v", err))
+	}
+
+	lastTime := lastVote.Timestamp
+	// set the times to the same value and check equality
+	now := time.Now()
+	lastVote.Timestamp = now
+	newVote.Timestamp = now
+
+	return lastTime, equalMsgs(&lastVote, &newVote)
+}
+
+// checkProposalsOnlyDifferByTimestamp returns the timestamp from the
+// lastSignBytes and true if the only difference in the proposals is their
+// timestamp.
+func checkProposalsOnlyDifferByTimestamp(lastSignBytes, newSignBytes []byte) (time.Time, bool) {
+	var lastProposal, newProposal kproto.CanonicalProposal
+	if err := protoio.UnmarshalDelimited(lastSignBytes, &lastProposal); err != nil {
+		panic(fmt.Sprintf("LastSignBytes cannot be unmarshalled into proposal: %v", err))
+	}
+	if err := protoio.UnmarshalDelimited(newSignBytes, &newProposal); err != nil {
+		panic(fmt.Sprintf("signBytes cannot be unmarshalled into proposal: %v", err))
+	}
+
+	lastTime := lastProposal.Timestamp
+	// set the times to the same value and check equality
+	now := time.Now()
+	lastProposal.Timestamp = now
+	newProposal.Timestamp = now
+
+	return lastTime, equalMsgs(&lastProposal, &newProposal)
+}
+
+// equalMsgs reports whether both messages have the same encoding.
+func equalMsgs(a, b proto.Message) bool {
+	aBytes, err := protoio.MarshalDelimited(a)
+	if err != nil {
+		panic(err)
+	}
+	bBytes, err := protoio.MarshalDelimited(b)
+	if err != nil {
+		panic(err)
+	}
+	return bytes.Equal(aBytes, bBytes)
+}
@@ -0,0 +1,159 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package privval
+
+import (
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/accounts/keystore"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
+	"github.com/kardiachain/go-kardia/types"
+)
+
+const chainID = "kai-test"
+
+func init() {
+	scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+}
+
+func tempPVFiles(t *testing.T) (string, string, func()) {
+	dir, err := ioutil.TempDir("", "privval")
+	require.NoError(t, err)
+	return filepath.Join(dir, "priv_validator_key.json"), filepath.Join(dir, DefaultStateFile), func() { os.RemoveAll(dir) }
+}
+
+func newBlockID(seed byte) types.BlockID {
+	return types.BlockID{
+		Hash:        common.BytesToHash([]byte{seed}),
+		PartsHeader: types.PartSetHeader{Total: 1, Hash: common.BytesToHash([]byte{seed, seed})},
+	}
+}
+
+func newVote(addr common.Address, height uint64, round uint32, typ kproto.SignedMsgType, blockID types.BlockID) *kproto.Vote {
+	return &kproto.Vote{
+		Type:             typ,
+		Height:           height,
+		Round:            round,
+		BlockID:          blockID.ToProto(),
+		Timestamp:        time.Now().UTC(),
+		ValidatorAddress: addr.Bytes(),
+	}
+}
+
+func TestLoadOrGenFilePV(t *testing.T) {
+	keyFile, stateFile, cleanup := tempPVFiles(t)
+	defer cleanup()
+
+	pv, err := LoadOrGenFilePV(keyFile, stateFile, "secret")
+	require.NoError(t, err)
+
+	// The key must only be stored encrypted
+	keyJSON, err := ioutil.ReadFile(keyFile)
+	require.NoError(t, err)
+	assert.False(t, strings.Contains(string(keyJSON), hex.EncodeToString(crypto.FromECDSA(pv.Key.PrivKey))))
+
+	loaded, err := LoadOrGenFilePV(keyFile, stateFile, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, pv.GetAddress(), loaded.GetAddress())
+	assert.Equal(t, pv.GetPubKey(), loaded.GetPubKey())
+
+	_, err = LoadOrGenFilePV(keyFile, stateFile, "wrong")
+	assert.True(t, errors.Is(err, keystore.ErrDecrypt))
+}
+
+func TestSignVote(t *testing.T) {
+	keyFile, stateFile, cleanup := tempPVFiles(t)
+	defer cleanup()
+
+	pv, err := LoadOrGenFilePV(keyFile, stateFile, "secret")
+	require.NoError(t, err)
+	addr := pv.GetAddress()
+	blockID, otherID := newBlockID(1), newBlockID(2)
+
+	vote := newVote(addr, 10, 1, kproto.PrevoteType, blockID)
+	require.NoError(t, pv.SignVote(chainID, vote))
+	signer, err := crypto.SigToPub(crypto.Keccak256(types.VoteSignBytes(chainID, vote)), vote.Signature)
+	require.NoError(t, err)
+	assert.Equal(t, addr, crypto.PubkeyToAddress(*signer))
+
+	// Signing the same vote again reuses the signature
+	again := newVote(addr, 10, 1, kproto.PrevoteType, blockID)
+	again.Timestamp = vote.Timestamp
+	require.NoError(t, pv.SignVote(chainID, again))
+	assert.Equal(t, vote.Signature, again.Signature)
+
+	// A vote differing only by timestamp gets the previous timestamp and signature
+	later := newVote(addr, 10, 1, kproto.PrevoteType, blockID)
+	later.Timestamp = vote.Timestamp.Add(time.Second)
+	require.NoError(t, pv.SignVote(chainID, later))
+	assert.Equal(t, vote.Timestamp, later.Timestamp)
+	assert.Equal(t, vote.Signature, later.Signature)
+
+	// A conflicting vote at the same height/round/step is refused
+	conflict := newVote(addr, 10, 1, kproto.PrevoteType, otherID)
+	assert.True(t, errors.Is(pv.SignVote(chainID, conflict), ErrConflictingData))
+
+	// Regressions are refused
+	assert.True(t, errors.Is(pv.SignVote(chainID, newVote(addr, 9, 1, kproto.PrevoteType, otherID)), ErrHeightRegression))
+	assert.True(t, errors.Is(pv.SignVote(chainID, newVote(addr, 10, 0, kproto.PrevoteType, otherID)), ErrRoundRegression))
+	require.NoError(t, pv.SignVote(chainID, newVote(addr, 10, 1, kproto.PrecommitType, blockID)))
+	assert.True(t, errors.Is(pv.SignVote(chainID, newVote(addr, 10, 1, kproto.PrevoteType, blockID)), ErrStepRegression))
+
+	// The last signed state survives a restart
+	loaded, err := LoadFilePV(keyFile, stateFile, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), loaded.LastSignState.Height)
+	assert.Equal(t, uint32(1), loaded.LastSignState.Round)
+	assert.Equal(t, stepPrecommit, loaded.LastSignState.Step)
+	assert.True(t, errors.Is(loaded.SignVote(chainID, newVote(addr, 10, 1, kproto.PrecommitType, otherID)), ErrConflictingData))
+}
+
+func TestSignProposal(t *testing.T) {
+	keyFile, stateFile, cleanup := tempPVFiles(t)
+	defer cleanup()
+
+	pv, err := LoadOrGenFilePV(keyFile, stateFile, "secret")
+	require.NoError(t, err)
+
+	proposal := types.NewProposal(5, 0, 0, newBlockID(1)).ToProto()
+	require.NoError(t, pv.SignProposal(chainID, proposal))
+	signer, err := crypto.SigToPub(crypto.Keccak256(types.ProposalSignBytes(chainID, proposal)), proposal.Signature)
+	require.NoError(t, err)
+	assert.Equal(t, pv.GetAddress(), crypto.PubkeyToAddress(*signer))
+
+	// Proposing a different block in the same round is refused
+	conflict := types.NewProposal(5, 0, 0, newBlockID(2)).ToProto()
+	assert.True(t, errors.Is(pv.SignProposal(chainID, conflict), ErrConflictingData))
+
+	// Proposals can't be signed after voting in the same round
+	require.NoError(t, pv.SignVote(chainID, newVote(pv.GetAddress(), 5, 0, kproto.PrevoteType, newBlockID(1))))
+	assert.True(t, errors.Is(pv.SignProposal(chainID, proposal), ErrStepRegression))
+}
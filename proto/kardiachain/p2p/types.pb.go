@@ -84,9 +84,11 @@ func (m *NetAddress) GetPort() uint32 {
 }
 
 type ProtocolVersion struct {
-	P2P   uint64 `protobuf:"varint,1,opt,name=p2p,proto3" json:"p2p,omitempty"`
-	Block uint64 `protobuf:"varint,2,opt,name=block,proto3" json:"block,omitempty"`
-	App   uint64 `protobuf:"varint,3,opt,name=app,proto3" json:"app,omitempty"`
+	P2P       uint64 `protobuf:"varint,1,opt,name=p2p,proto3" json:"p2p,omitempty"`
+	Block     uint64 `protobuf:"varint,2,opt,name=block,proto3" json:"block,omitempty"`
+	App       uint64 `protobuf:"varint,3,opt,name=app,proto3" json:"app,omitempty"`
+	Consensus uint64 `protobuf:"varint,4,opt,name=consensus,proto3" json:"consensus,omitempty"`
+	Dualnode  uint64 `protobuf:"varint,5,opt,name=dualnode,proto3" json:"dualnode,omitempty"`
 }
 
 func (m *ProtocolVersion) Reset()         { *m = ProtocolVersion{} }
@@ -143,6 +145,20 @@ func (m *ProtocolVersion) GetApp() uint64 {
 	return 0
 }
 
+func (m *ProtocolVersion) GetConsensus() uint64 {
+	if m != nil {
+		return m.Consensus
+	}
+	return 0
+}
+
+func (m *ProtocolVersion) GetDualnode() uint64 {
+	if m != nil {
+		return m.Dualnode
+	}
+	return 0
+}
+
 type DefaultNodeInfo struct {
 	ProtocolVersion ProtocolVersion      `protobuf:"bytes,1,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version"`
 	DefaultNodeID   string               `protobuf:"bytes,2,opt,name=default_node_id,json=defaultNodeId,proto3" json:"default_node_id,omitempty"`
@@ -305,38 +321,39 @@ func init() {
 func init() { proto.RegisterFile("kardiachain/p2p/types.proto", fileDescriptor_6cbe2e01d4b0a5bd) }
 
 var fileDescriptor_6cbe2e01d4b0a5bd = []byte{
-	// 486 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x53, 0x4d, 0x8b, 0xdb, 0x30,
-	0x10, 0x8d, 0x1d, 0xe7, 0x63, 0x27, 0x4d, 0xb3, 0x15, 0xa1, 0x78, 0xb7, 0x60, 0x87, 0x40, 0x21,
-	0x97, 0xc6, 0x90, 0x42, 0xa1, 0xc7, 0x4d, 0x73, 0xc9, 0x65, 0xeb, 0x8a, 0xd2, 0x43, 0x2f, 0xc1,
-	0xb1, 0xb4, 0x89, 0x48, 0x56, 0x12, 0xb2, 0xb6, 0x4d, 0xff, 0x45, 0x7f, 0xd6, 0x1e, 0xf7, 0xd8,
-	0x53, 0x28, 0xce, 0xb9, 0xff, 0xa1, 0x58, 0xf2, 0x16, 0xe3, 0xee, 0x6d, 0xde, 0x3c, 0xcd, 0xcc,
-	0x9b, 0xc7, 0x08, 0x5e, 0xed, 0x12, 0x45, 0x58, 0x92, 0x6e, 0x13, 0xc6, 0x23, 0x39, 0x93, 0x91,
-	0xfe, 0x21, 0x69, 0x36, 0x95, 0x4a, 0x68, 0x81, 0x06, 0x15, 0x72, 0x2a, 0x67, 0xf2, 0x72, 0xb8,
-	0x11, 0x1b, 0x61, 0xb8, 0xa8, 0x88, 0xec, 0xb3, 0x71, 0x0c, 0x70, 0x4d, 0xf5, 0x15, 0x21, 0x8a,
-	0x66, 0x19, 0x7a, 0x09, 0x2e, 0x23, 0xbe, 0x33, 0x72, 0x26, 0x67, 0xf3, 0x76, 0x7e, 0x0c, 0xdd,
-	0xe5, 0x02, 0xbb, 0x8c, 0x98, 0xbc, 0xf4, 0xdd, 0x4a, 0x3e, 0xc6, 0x2e, 0x93, 0x08, 0x81, 0x27,
-	0x85, 0xd2, 0x7e, 0x73, 0xe4, 0x4c, 0xfa, 0xd8, 0xc4, 0xe3, 0xcf, 0x30, 0x88, 0x8b, 0xd6, 0xa9,
-	0xd8, 0x7f, 0xa1, 0x2a, 0x63, 0x82, 0xa3, 0x0b, 0x68, 0xca, 0x99, 0x34, 0x7d, 0xbd, 0x79, 0x27,
-	0x3f, 0x86, 0xcd, 0x78, 0x16, 0xe3, 0x22, 0x87, 0x86, 0xd0, 0x5a, 0xef, 0x45, 0xba, 0x33, 0xcd,
-	0x3d, 0x6c, 0x01, 0x3a, 0x87, 0x66, 0x22, 0xa5, 0x69, 0xeb, 0xe1, 0x22, 0x1c, 0xff, 0x71, 0x61,
-	0xb0, 0xa0, 0x37, 0xc9, 0xdd, 0x5e, 0x5f, 0x0b, 0x42, 0x97, 0xfc, 0x46, 0xa0, 0x4f, 0x70, 0x2e,
-	0xcb, 0x49, 0xab, 0x6f, 0x76, 0x94, 0x99, 0xd1, 0x9b, 0x8d, 0xa6, 0xb5, 0xed, 0xa7, 0x35, 0x49,
-	0x73, 0xef, 0xfe, 0x18, 0x36, 0xf0, 0x40, 0xd6, 0x94, 0xbe, 0x87, 0x01, 0xb1, 0x53, 0x56, 0x5c,
-	0x10, 0xba, 0x62, 0xa4, 0xdc, 0xfa, 0x45, 0x7e, 0x0c, 0xfb, 0x55, 0x01, 0x0b, 0xdc, 0x27, 0x15,
-	0x48, 0x50, 0x08, 0xbd, 0x3d, 0xcb, 0x34, 0xe5, 0xab, 0x84, 0x10, 0x65, 0xb4, 0x9f, 0x61, 0xb0,
-	0xa9, 0xc2, 0x5f, 0xe4, 0x43, 0x87, 0x53, 0xfd, 0x5d, 0xa8, 0x9d, 0xef, 0x19, 0xf2, 0x11, 0x16,
-	0xcc, 0xa3, 0xfe, 0x96, 0x65, 0x4a, 0x88, 0x2e, 0xa1, 0x9b, 0x6e, 0x13, 0xce, 0xe9, 0x3e, 0xf3,
-	0xdb, 0x23, 0x67, 0xf2, 0x0c, 0xff, 0xc3, 0x45, 0xd5, 0xad, 0xe0, 0x6c, 0x47, 0x95, 0xdf, 0xb1,
-	0x55, 0x25, 0x44, 0x57, 0xd0, 0x12, 0x7a, 0x4b, 0x95, 0xdf, 0x35, 0x6e, 0xbc, 0xfe, 0xcf, 0x8d,
-	0x9a, 0x93, 0x1f, 0x8b, 0xc7, 0xa5, 0x25, 0xb6, 0x72, 0xbc, 0x86, 0xe1, 0x53, 0x8f, 0xd0, 0x05,
-	0x74, 0xf5, 0x61, 0xc5, 0x38, 0xa1, 0x07, 0x7b, 0x27, 0xb8, 0xa3, 0x0f, 0xcb, 0x02, 0xa2, 0x08,
-	0x7a, 0x4a, 0xa6, 0x66, 0x7b, 0x9a, 0x65, 0xa5, 0x6f, 0xcf, 0xf3, 0x63, 0x08, 0x38, 0xfe, 0x50,
-	0x5e, 0x18, 0x06, 0x25, 0xd3, 0x32, 0x9e, 0xc7, 0xf7, 0x79, 0xe0, 0x3c, 0xe4, 0x81, 0xf3, 0x3b,
-	0x0f, 0x9c, 0x9f, 0xa7, 0xa0, 0xf1, 0x70, 0x0a, 0x1a, 0xbf, 0x4e, 0x41, 0xe3, 0xeb, 0xbb, 0x0d,
-	0xd3, 0xdb, 0xbb, 0xf5, 0x34, 0x15, 0xb7, 0x51, 0xf5, 0xc8, 0x37, 0xe2, 0x8d, 0x85, 0x91, 0x3d,
-	0xe4, 0xda, 0x07, 0x58, 0xb7, 0x4d, 0xfa, 0xed, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x58, 0xfc,
-	0x76, 0xf6, 0x1a, 0x03, 0x00, 0x00,
+	// 499 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6d, 0x53, 0x4d, 0x6f, 0xd3, 0x40,
+	0x10, 0x6d, 0x12, 0xe7, 0x6b, 0x42, 0x70, 0x59, 0x45, 0xc8, 0x14, 0xd4, 0x54, 0x91, 0x90, 0x7a,
+	0xc1, 0x96, 0x82, 0x84, 0xc4, 0xb1, 0x21, 0x97, 0x5c, 0x8a, 0xd9, 0x03, 0x07, 0x2e, 0x91, 0x63,
+	0x6f, 0x13, 0x2b, 0xee, 0xee, 0xca, 0xde, 0xd0, 0xf4, 0x4f, 0x20, 0x7e, 0x56, 0x8f, 0x3d, 0x72,
+	0xaa, 0x50, 0x39, 0xf3, 0x1f, 0x98, 0xdd, 0x75, 0x8b, 0x65, 0x38, 0x8c, 0x34, 0xef, 0xcd, 0xec,
+	0xec, 0xcc, 0xdb, 0x59, 0x78, 0xb9, 0x8d, 0xf2, 0x24, 0x8d, 0xe2, 0x4d, 0x94, 0xf2, 0x40, 0x4e,
+	0x65, 0xa0, 0xae, 0x25, 0x2b, 0x7c, 0x99, 0x0b, 0x25, 0x88, 0x5b, 0x09, 0xfa, 0x18, 0x3c, 0x1a,
+	0xad, 0xc5, 0x5a, 0x98, 0x58, 0xa0, 0x3d, 0x9b, 0x36, 0x09, 0x01, 0xce, 0x99, 0x3a, 0x4b, 0x92,
+	0x9c, 0x15, 0x05, 0x79, 0x0e, 0xcd, 0x34, 0xf1, 0x1a, 0x27, 0x8d, 0xd3, 0xfe, 0xac, 0x73, 0x7f,
+	0x37, 0x6e, 0x2e, 0xe6, 0x14, 0x19, 0xc3, 0x4b, 0xaf, 0x59, 0xe1, 0x43, 0xe4, 0x25, 0x21, 0xe0,
+	0x48, 0x91, 0x2b, 0xaf, 0x85, 0x91, 0x21, 0x35, 0xfe, 0xe4, 0x5b, 0x03, 0xdc, 0x50, 0xd7, 0x8e,
+	0x45, 0xf6, 0x99, 0xe5, 0x45, 0x2a, 0x38, 0x79, 0x01, 0x2d, 0x6c, 0xc1, 0x14, 0x76, 0x66, 0x5d,
+	0x2c, 0xd0, 0x0a, 0xa7, 0x21, 0xd5, 0x1c, 0x19, 0x41, 0x7b, 0x95, 0x89, 0x78, 0x6b, 0xaa, 0x3b,
+	0xd4, 0x02, 0x72, 0x08, 0xad, 0x48, 0x4a, 0x53, 0xd7, 0xa1, 0xda, 0x25, 0xaf, 0xa0, 0x1f, 0x0b,
+	0x5e, 0x30, 0x5e, 0xec, 0x0a, 0xcf, 0x31, 0xfc, 0x5f, 0x82, 0x1c, 0x41, 0x2f, 0xd9, 0x45, 0x19,
+	0x17, 0x09, 0xf3, 0xda, 0x26, 0xf8, 0x88, 0x27, 0xbf, 0x9b, 0xe0, 0xce, 0xd9, 0x45, 0xb4, 0xcb,
+	0xd4, 0x39, 0xe2, 0x05, 0xbf, 0x10, 0xe4, 0x13, 0x1c, 0xca, 0xb2, 0xc7, 0xe5, 0x57, 0xdb, 0xa4,
+	0xe9, 0x6e, 0x30, 0x3d, 0xf1, 0x6b, 0xc2, 0xf9, 0xb5, 0x61, 0x66, 0xce, 0xcd, 0xdd, 0xf8, 0x80,
+	0xba, 0xb2, 0x36, 0xe3, 0x7b, 0x70, 0x13, 0x7b, 0xcb, 0x52, 0x5f, 0xbb, 0x44, 0x21, 0xad, 0x60,
+	0xcf, 0x70, 0xde, 0x61, 0xb5, 0x81, 0x39, 0x1d, 0x26, 0x15, 0x98, 0x90, 0x31, 0x0c, 0xb2, 0xb4,
+	0x50, 0x8c, 0x2f, 0x23, 0x7c, 0x08, 0x33, 0x75, 0x9f, 0x82, 0xa5, 0xf4, 0xd3, 0x10, 0x0f, 0xba,
+	0x9c, 0xa9, 0x2b, 0x91, 0x6f, 0xcd, 0xe8, 0x7d, 0xfa, 0x00, 0x75, 0xe4, 0xa1, 0xff, 0xb6, 0x8d,
+	0x94, 0x50, 0x4b, 0x82, 0x33, 0x70, 0xce, 0xb2, 0xc2, 0xeb, 0x60, 0xe8, 0x09, 0x7d, 0xc4, 0xfa,
+	0xd4, 0xa5, 0xe0, 0xe9, 0x96, 0xe5, 0x5e, 0xd7, 0x9e, 0x2a, 0x21, 0x39, 0x83, 0xb6, 0x50, 0x1b,
+	0xe4, 0x7b, 0x46, 0x8d, 0xd7, 0xff, 0xa8, 0x51, 0x53, 0xf2, 0xa3, 0x4e, 0x2e, 0x25, 0xb1, 0x27,
+	0x27, 0x2b, 0x18, 0xfd, 0x2f, 0x09, 0x97, 0xa0, 0xa7, 0xf6, 0xcb, 0x94, 0x27, 0x6c, 0x6f, 0x57,
+	0x8c, 0x76, 0xd5, 0x7e, 0xa1, 0x21, 0x09, 0x60, 0x90, 0xcb, 0xd8, 0x4c, 0x8f, 0x6b, 0x58, 0xea,
+	0xf6, 0x14, 0x75, 0x03, 0x1a, 0x7e, 0x28, 0x97, 0x93, 0x02, 0xa6, 0x94, 0xfe, 0x2c, 0xbc, 0xb9,
+	0x3f, 0x6e, 0xdc, 0xa2, 0xfd, 0x44, 0xfb, 0xfe, 0xeb, 0xf8, 0xe0, 0x16, 0xed, 0x07, 0xda, 0x97,
+	0x77, 0xeb, 0x54, 0x6d, 0x76, 0x2b, 0x3f, 0x16, 0x97, 0x41, 0xf5, 0x7f, 0xac, 0xc5, 0x1b, 0x0b,
+	0x03, 0xfb, 0x07, 0x6a, 0x7f, 0x67, 0xd5, 0x31, 0xf4, 0xdb, 0x3f, 0x02, 0x7e, 0xc6, 0x62, 0x55,
+	0x03, 0x00, 0x00,
 }
 
 func (m *NetAddress) Marshal() (dAtA []byte, err error) {
@@ -401,6 +418,16 @@ func (m *ProtocolVersion) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Dualnode != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.Dualnode))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Consensus != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.Consensus))
+		i--
+		dAtA[i] = 0x20
+	}
 	if m.App != 0 {
 		i = encodeVarintTypes(dAtA, i, uint64(m.App))
 		i--
@@ -587,6 +614,12 @@ func (m *ProtocolVersion) Size() (n int) {
 	if m.App != 0 {
 		n += 1 + sovTypes(uint64(m.App))
 	}
+	if m.Consensus != 0 {
+		n += 1 + sovTypes(uint64(m.Consensus))
+	}
+	if m.Dualnode != 0 {
+		n += 1 + sovTypes(uint64(m.Dualnode))
+	}
 	return n
 }
 
@@ -869,6 +902,44 @@ func (m *ProtocolVersion) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Consensus", wireType)
+			}
+			m.Consensus = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Consensus |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dualnode", wireType)
+			}
+			m.Dualnode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Dualnode |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
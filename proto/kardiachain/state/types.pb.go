@@ -32,6 +32,8 @@ const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 type ValidatorsInfo struct {
 	ValidatorSet      *types.ValidatorSet `protobuf:"bytes,1,opt,name=validator_set,json=validatorSet,proto3" json:"validator_set,omitempty"`
 	LastHeightChanged uint64              `protobuf:"varint,2,opt,name=last_height_changed,json=lastHeightChanged,proto3" json:"last_height_changed,omitempty"`
+	// version of the record layout, see cstate.storeVersion
+	Version uint32 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
 }
 
 func (m *ValidatorsInfo) Reset()         { *m = ValidatorsInfo{} }
@@ -81,10 +83,19 @@ func (m *ValidatorsInfo) GetLastHeightChanged() uint64 {
 	return 0
 }
 
+func (m *ValidatorsInfo) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
 // ConsensusParamsInfo represents the latest consensus params, or the last height it changed
 type ConsensusParamsInfo struct {
 	ConsensusParams   types.ConsensusParams `protobuf:"bytes,1,opt,name=consensus_params,json=consensusParams,proto3" json:"consensus_params"`
 	LastHeightChanged uint64                `protobuf:"varint,2,opt,name=last_height_changed,json=lastHeightChanged,proto3" json:"last_height_changed,omitempty"`
+	// version of the record layout, see cstate.storeVersion
+	Version uint32 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
 }
 
 func (m *ConsensusParamsInfo) Reset()         { *m = ConsensusParamsInfo{} }
@@ -134,7 +145,16 @@ func (m *ConsensusParamsInfo) GetLastHeightChanged() uint64 {
 	return 0
 }
 
+func (m *ConsensusParamsInfo) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
 type State struct {
+	// version of the record layout, see cstate.storeVersion
+	Version         uint32        `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
 	ChainID         string        `protobuf:"bytes,2,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
 	InitialHeight   uint64        `protobuf:"varint,14,opt,name=initial_height,json=initialHeight,proto3" json:"initial_height,omitempty"`
 	LastBlockHeight uint64        `protobuf:"varint,3,opt,name=last_block_height,json=lastBlockHeight,proto3" json:"last_block_height,omitempty"`
@@ -189,6 +209,13 @@ func (m *State) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_State proto.InternalMessageInfo
 
+func (m *State) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
 func (m *State) GetChainID() string {
 	if m != nil {
 		return m.ChainID
@@ -282,44 +309,45 @@ func init() {
 func init() { proto.RegisterFile("kardiachain/state/types.proto", fileDescriptor_4621b08820a993df) }
 
 var fileDescriptor_4621b08820a993df = []byte{
-	// 580 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x94, 0x4f, 0x8f, 0xd2, 0x4e,
-	0x18, 0xc7, 0xe9, 0xef, 0x07, 0x0b, 0x3b, 0x2c, 0xe0, 0x16, 0x0f, 0x15, 0x63, 0x8b, 0x44, 0x0d,
-	0x31, 0xb1, 0x4d, 0xf4, 0xe2, 0xcd, 0xa4, 0x70, 0xa0, 0xc9, 0xc6, 0x98, 0x62, 0x3c, 0x78, 0x69,
-	0x86, 0x76, 0xb6, 0x9d, 0x2c, 0x74, 0x1a, 0x66, 0xd8, 0xe8, 0x1b, 0xf0, 0xbc, 0x67, 0x5f, 0xd1,
-	0x1e, 0xf7, 0xe8, 0x09, 0x4d, 0x79, 0x21, 0x9a, 0x99, 0xfe, 0x61, 0x58, 0x38, 0x60, 0xbc, 0x75,
-	0xe6, 0xfb, 0x3c, 0x9f, 0xe7, 0x3b, 0xed, 0xb7, 0x03, 0x9e, 0x5c, 0xc1, 0x65, 0x80, 0xa1, 0x1f,
-	0x41, 0x1c, 0x5b, 0x94, 0x41, 0x86, 0x2c, 0xf6, 0x35, 0x41, 0xd4, 0x4c, 0x96, 0x84, 0x11, 0xf5,
-	0x5c, 0x92, 0x4d, 0x21, 0xf7, 0x1e, 0x86, 0x24, 0x24, 0x42, 0xb5, 0xf8, 0x53, 0x56, 0xd8, 0x33,
-	0x42, 0x42, 0xc2, 0x39, 0xb2, 0xc4, 0x6a, 0xb6, 0xba, 0xb4, 0x18, 0x5e, 0x20, 0xca, 0xe0, 0x22,
-	0xc9, 0x0b, 0x76, 0x06, 0x89, 0x11, 0xf2, 0xa0, 0xde, 0xd3, 0x7d, 0xf9, 0x1a, 0xce, 0x71, 0x00,
-	0x19, 0x59, 0xe6, 0x25, 0xfa, 0x7e, 0x49, 0x02, 0x97, 0x70, 0x91, 0x23, 0x06, 0xdf, 0x14, 0xd0,
-	0xfe, 0x54, 0xf4, 0x50, 0x27, 0xbe, 0x24, 0xea, 0x18, 0xb4, 0x4a, 0x8a, 0x47, 0x11, 0xd3, 0x94,
-	0xbe, 0x32, 0x6c, 0xbe, 0x36, 0x4c, 0xf9, 0x58, 0x99, 0x8d, 0xb2, 0x73, 0x8a, 0x98, 0x7b, 0x76,
-	0x2d, 0xad, 0x54, 0x13, 0x74, 0xe7, 0x90, 0x32, 0x2f, 0x42, 0x38, 0x8c, 0x98, 0xe7, 0x47, 0x30,
-	0x0e, 0x51, 0xa0, 0xfd, 0xd7, 0x57, 0x86, 0x55, 0xf7, 0x9c, 0x4b, 0x13, 0xa1, 0x8c, 0x32, 0x61,
-	0xf0, 0x5d, 0x01, 0xdd, 0x11, 0x89, 0x29, 0x8a, 0xe9, 0x8a, 0x7e, 0x10, 0x16, 0x85, 0x9b, 0x29,
-	0x78, 0xe0, 0x17, 0xdb, 0x5e, 0x66, 0x3d, 0x37, 0x34, 0x38, 0x60, 0xe8, 0x1e, 0xc1, 0xae, 0xde,
-	0xae, 0x8d, 0x8a, 0xdb, 0xf1, 0x77, 0xb7, 0xff, 0xda, 0xdc, 0xef, 0x1a, 0xa8, 0x4d, 0xf9, 0x87,
-	0x54, 0x5f, 0x80, 0x86, 0x98, 0xe7, 0xe1, 0xac, 0xfc, 0xd4, 0x6e, 0xa6, 0x6b, 0xa3, 0x3e, 0xe2,
-	0x7b, 0xce, 0xd8, 0xad, 0x0b, 0xd1, 0x09, 0xd4, 0xe7, 0xa0, 0x8d, 0x63, 0xcc, 0x30, 0x9c, 0xe7,
-	0x43, 0xb4, 0xb6, 0x80, 0xb7, 0xf2, 0xdd, 0x8c, 0xaf, 0xbe, 0x04, 0x62, 0x9a, 0x37, 0x9b, 0x13,
-	0xff, 0xaa, 0xa8, 0xfc, 0x5f, 0x54, 0x76, 0xb8, 0x60, 0xf3, 0xfd, 0xbc, 0x76, 0x0a, 0x5a, 0x52,
-	0x2d, 0x0e, 0xb4, 0xaa, 0x78, 0x0d, 0xbd, 0x03, 0xaf, 0x41, 0xb4, 0x39, 0x63, 0xbb, 0xcb, 0x8f,
-	0x9f, 0xae, 0x8d, 0xe6, 0x45, 0xc1, 0x72, 0xc6, 0x6e, 0xb3, 0x04, 0x3b, 0x81, 0x7a, 0x01, 0x3a,
-	0x12, 0x94, 0xe7, 0x4f, 0xab, 0xe5, 0xd8, 0x2c, 0x9c, 0x66, 0x11, 0x4e, 0xf3, 0x63, 0x11, 0x4e,
-	0xbb, 0xc1, 0xb1, 0x37, 0x3f, 0x0d, 0xc5, 0x6d, 0x95, 0x2c, 0xae, 0xaa, 0x13, 0xd0, 0x89, 0xd1,
-	0x17, 0xe6, 0x95, 0x49, 0xa0, 0xda, 0xc9, 0x71, 0xe1, 0x69, 0xf3, 0xbe, 0x6d, 0x10, 0xd5, 0x77,
-	0x00, 0x48, 0x90, 0xfa, 0x71, 0x10, 0xa9, 0x85, 0x5b, 0x11, 0x07, 0x93, 0x28, 0x8d, 0x23, 0xad,
-	0xf0, 0x3e, 0xc9, 0xca, 0x08, 0xe8, 0x72, 0x58, 0xb6, 0xc0, 0x32, 0x37, 0xa7, 0xe2, 0x83, 0x3d,
-	0xde, 0xe6, 0x66, 0xdb, 0x9d, 0x27, 0x48, 0x7d, 0x0f, 0x9e, 0xed, 0x24, 0xee, 0x5e, 0xa4, 0x4b,
-	0x54, 0x53, 0xa0, 0xfa, 0x52, 0x04, 0x77, 0xa3, 0x5b, 0xf0, 0x0e, 0xfd, 0x16, 0xe0, 0x5f, 0x7f,
-	0x8b, 0x47, 0xa0, 0x01, 0x93, 0xc4, 0x8b, 0x20, 0x8d, 0xb4, 0x56, 0x5f, 0x19, 0x9e, 0xb9, 0x75,
-	0x98, 0x24, 0x13, 0x48, 0x23, 0xdb, 0xbd, 0x4d, 0x75, 0xe5, 0x2e, 0xd5, 0x95, 0x5f, 0xa9, 0xae,
-	0xdc, 0x6c, 0xf4, 0xca, 0xdd, 0x46, 0xaf, 0xfc, 0xd8, 0xe8, 0x95, 0xcf, 0x6f, 0x43, 0xcc, 0xa2,
-	0xd5, 0xcc, 0xf4, 0xc9, 0xc2, 0x92, 0x2f, 0x9b, 0x90, 0xbc, 0xca, 0x96, 0xd9, 0xf5, 0x66, 0xed,
-	0xdd, 0x99, 0xb3, 0x13, 0x21, 0xbc, 0xf9, 0x13, 0x00, 0x00, 0xff, 0xff, 0xeb, 0xd4, 0xc4, 0xa7,
-	0x4f, 0x05, 0x00, 0x00,
+	// 597 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x54, 0x4f, 0x8f, 0xd2, 0x40,
+	0x1c, 0x65, 0xdc, 0x3f, 0xb0, 0xc3, 0x16, 0xdc, 0xe2, 0xa1, 0x62, 0x6c, 0x91, 0xa8, 0x21, 0x26,
+	0xb6, 0x89, 0x5e, 0xbc, 0x99, 0x14, 0x0e, 0x34, 0xd9, 0x18, 0x53, 0x8c, 0x07, 0x2f, 0xcd, 0xd0,
+	0x76, 0xdb, 0xc9, 0x42, 0xa7, 0x61, 0x06, 0xa2, 0xdf, 0x62, 0xaf, 0xde, 0xfc, 0x10, 0x7e, 0x88,
+	0x3d, 0xee, 0xd1, 0x13, 0x1a, 0xf8, 0x22, 0x66, 0xa6, 0x7f, 0x18, 0x16, 0x0e, 0x24, 0x7a, 0xeb,
+	0xfc, 0xde, 0xfb, 0xbd, 0xdf, 0x9b, 0xe9, 0x9b, 0x81, 0x4f, 0xaf, 0xd1, 0x2c, 0xc0, 0xc8, 0x8f,
+	0x11, 0x4e, 0x2c, 0xca, 0x10, 0x0b, 0x2d, 0xf6, 0x2d, 0x0d, 0xa9, 0x99, 0xce, 0x08, 0x23, 0xea,
+	0x85, 0x04, 0x9b, 0x02, 0x6e, 0x3f, 0x8a, 0x48, 0x44, 0x04, 0x6a, 0xf1, 0xaf, 0x8c, 0xd8, 0x36,
+	0x22, 0x42, 0xa2, 0x49, 0x68, 0x89, 0xd5, 0x78, 0x7e, 0x65, 0x31, 0x3c, 0x0d, 0x29, 0x43, 0xd3,
+	0x34, 0x27, 0x6c, 0x0d, 0x12, 0x23, 0xe4, 0x41, 0xed, 0x67, 0xbb, 0xf0, 0x02, 0x4d, 0x70, 0x80,
+	0x18, 0x99, 0xe5, 0x14, 0x7d, 0x97, 0x92, 0xa2, 0x19, 0x9a, 0xe6, 0x12, 0xdd, 0x1f, 0x00, 0x36,
+	0x3e, 0x17, 0x3d, 0xd4, 0x49, 0xae, 0x88, 0x3a, 0x80, 0x4a, 0xa9, 0xe2, 0xd1, 0x90, 0x69, 0xa0,
+	0x03, 0x7a, 0xf5, 0x37, 0x86, 0x29, 0x6f, 0x2b, 0xb3, 0x51, 0x76, 0x8e, 0x42, 0xe6, 0x9e, 0x2f,
+	0xa4, 0x95, 0x6a, 0xc2, 0xd6, 0x04, 0x51, 0xe6, 0xc5, 0x21, 0x8e, 0x62, 0xe6, 0xf9, 0x31, 0x4a,
+	0xa2, 0x30, 0xd0, 0x1e, 0x74, 0x40, 0xef, 0xd8, 0xbd, 0xe0, 0xd0, 0x50, 0x20, 0xfd, 0x0c, 0x50,
+	0x35, 0x58, 0x5d, 0x84, 0x33, 0x8a, 0x49, 0xa2, 0x1d, 0x75, 0x40, 0x4f, 0x71, 0x8b, 0x65, 0xf7,
+	0x27, 0x80, 0xad, 0x3e, 0x49, 0x68, 0x98, 0xd0, 0x39, 0xfd, 0x28, 0xcc, 0x0b, 0x9f, 0x23, 0xf8,
+	0xd0, 0x2f, 0xca, 0x5e, 0xb6, 0xa9, 0xdc, 0x6a, 0x77, 0x8f, 0xd5, 0x7b, 0x0a, 0xf6, 0xf1, 0xed,
+	0xd2, 0xa8, 0xb8, 0x4d, 0x7f, 0xbb, 0xfc, 0x1f, 0x6d, 0x7f, 0x3f, 0x85, 0x27, 0x23, 0xfe, 0xf3,
+	0x65, 0x0e, 0xd8, 0xe2, 0xa8, 0x2f, 0x61, 0x4d, 0x78, 0xf4, 0x70, 0x36, 0xe2, 0xcc, 0xae, 0xaf,
+	0x96, 0x46, 0xb5, 0xcf, 0x6b, 0xce, 0xc0, 0xad, 0x0a, 0xd0, 0x09, 0xd4, 0x17, 0xb0, 0x81, 0x13,
+	0xcc, 0x30, 0x9a, 0xe4, 0xc6, 0xb4, 0x86, 0x30, 0xa4, 0xe4, 0xd5, 0xcc, 0x93, 0xfa, 0x0a, 0x0a,
+	0x87, 0xde, 0x78, 0x42, 0xfc, 0xeb, 0x82, 0x79, 0x24, 0x98, 0x4d, 0x0e, 0xd8, 0xbc, 0x9e, 0x73,
+	0x47, 0x50, 0x91, 0xb8, 0x38, 0xd0, 0x8e, 0xc5, 0xd1, 0xb5, 0xf7, 0x1c, 0x9d, 0x68, 0x73, 0x06,
+	0x76, 0x8b, 0x1f, 0xd9, 0x6a, 0x69, 0xd4, 0x2f, 0x0b, 0x2d, 0x67, 0xe0, 0xd6, 0x4b, 0x61, 0x27,
+	0x50, 0x2f, 0x61, 0x53, 0x12, 0xe5, 0x69, 0xd6, 0x4e, 0x72, 0xd9, 0x2c, 0xea, 0x66, 0x11, 0x75,
+	0xf3, 0x53, 0x11, 0x75, 0xbb, 0xc6, 0x65, 0x6f, 0x7e, 0x1b, 0xc0, 0x55, 0x4a, 0x2d, 0x8e, 0xaa,
+	0x43, 0xd8, 0x4c, 0xc2, 0xaf, 0xcc, 0x2b, 0x73, 0x45, 0xb5, 0xd3, 0xc3, 0xa2, 0xd8, 0xe0, 0x7d,
+	0x65, 0x85, 0xaa, 0xef, 0x21, 0x94, 0x44, 0xaa, 0x87, 0x89, 0x48, 0x2d, 0xdc, 0x8a, 0xd8, 0x98,
+	0xa4, 0x52, 0x3b, 0xd0, 0x0a, 0xef, 0x93, 0xac, 0xf4, 0xa1, 0x2e, 0x07, 0x6c, 0x23, 0x58, 0x66,
+	0xed, 0x4c, 0xfc, 0xb0, 0x27, 0x9b, 0xac, 0x6d, 0xba, 0x8b, 0xd4, 0x7d, 0x80, 0xcf, 0xb7, 0x52,
+	0x7a, 0xef, 0x1a, 0x94, 0x52, 0x75, 0x21, 0xd5, 0x91, 0x62, 0xbb, 0x1d, 0xf7, 0x42, 0x6f, 0xdf,
+	0x55, 0x82, 0xff, 0x7a, 0x95, 0x1e, 0xc3, 0x1a, 0x4a, 0x53, 0x2f, 0x46, 0x34, 0xd6, 0x94, 0x0e,
+	0xe8, 0x9d, 0xbb, 0x55, 0x94, 0xa6, 0x43, 0x44, 0x63, 0xdb, 0xbd, 0x5d, 0xe9, 0xe0, 0x6e, 0xa5,
+	0x83, 0x3f, 0x2b, 0x1d, 0xdc, 0xac, 0xf5, 0xca, 0xdd, 0x5a, 0xaf, 0xfc, 0x5a, 0xeb, 0x95, 0x2f,
+	0xef, 0x22, 0xcc, 0xe2, 0xf9, 0xd8, 0xf4, 0xc9, 0xd4, 0x92, 0x9f, 0xae, 0x88, 0xbc, 0xce, 0x96,
+	0xd9, 0x63, 0x69, 0xed, 0xbc, 0xc0, 0xe3, 0x53, 0x01, 0xbc, 0xfd, 0x3b, 0x00, 0x3e, 0x83, 0xb5,
+	0x18, 0x9d, 0x05, 0x00, 0x00,
 }
 
 func (m *ValidatorsInfo) Marshal() (dAtA []byte, err error) {
@@ -342,6 +370,11 @@ func (m *ValidatorsInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Version != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.Version))
+		i--
+		dAtA[i] = 0x18
+	}
 	if m.LastHeightChanged != 0 {
 		i = encodeVarintTypes(dAtA, i, uint64(m.LastHeightChanged))
 		i--
@@ -382,6 +415,11 @@ func (m *ConsensusParamsInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Version != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.Version))
+		i--
+		dAtA[i] = 0x18
+	}
 	if m.LastHeightChanged != 0 {
 		i = encodeVarintTypes(dAtA, i, uint64(m.LastHeightChanged))
 		i--
@@ -518,6 +556,11 @@ func (m *State) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x12
 	}
+	if m.Version != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.Version))
+		i--
+		dAtA[i] = 0x8
+	}
 	return len(dAtA) - i, nil
 }
 
@@ -545,6 +588,9 @@ func (m *ValidatorsInfo) Size() (n int) {
 	if m.LastHeightChanged != 0 {
 		n += 1 + sovTypes(uint64(m.LastHeightChanged))
 	}
+	if m.Version != 0 {
+		n += 1 + sovTypes(uint64(m.Version))
+	}
 	return n
 }
 
@@ -559,6 +605,9 @@ func (m *ConsensusParamsInfo) Size() (n int) {
 	if m.LastHeightChanged != 0 {
 		n += 1 + sovTypes(uint64(m.LastHeightChanged))
 	}
+	if m.Version != 0 {
+		n += 1 + sovTypes(uint64(m.Version))
+	}
 	return n
 }
 
@@ -568,6 +617,9 @@ func (m *State) Size() (n int) {
 	}
 	var l int
 	_ = l
+	if m.Version != 0 {
+		n += 1 + sovTypes(uint64(m.Version))
+	}
 	l = len(m.ChainID)
 	if l > 0 {
 		n += 1 + l + sovTypes(uint64(l))
@@ -699,6 +751,25 @@ func (m *ValidatorsInfo) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -801,6 +872,25 @@ func (m *ConsensusParamsInfo) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -851,6 +941,25 @@ func (m *State) Unmarshal(dAtA []byte) error {
 			return fmt.Errorf("proto: State: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		case 2:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field ChainID", wireType)
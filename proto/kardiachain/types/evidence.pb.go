@@ -105,9 +105,347 @@ func (m *DuplicateVoteEvidence) GetTimestamp() time.Time {
 	return time.Time{}
 }
 
+// LightClientAttackEvidence contains evidence of a set of validators attempting
+// to mislead a light client with a header conflicting with the chain.
+type LightClientAttackEvidence struct {
+	ConflictingHeader     *SignedHeader `protobuf:"bytes,1,opt,name=conflicting_header,json=conflictingHeader,proto3" json:"conflicting_header,omitempty"`
+	ConflictingValidators *ValidatorSet `protobuf:"bytes,2,opt,name=conflicting_validators,json=conflictingValidators,proto3" json:"conflicting_validators,omitempty"`
+	CommonHeight          uint64        `protobuf:"varint,3,opt,name=common_height,json=commonHeight,proto3" json:"common_height,omitempty"`
+	ByzantineValidators   []*Validator  `protobuf:"bytes,4,rep,name=byzantine_validators,json=byzantineValidators,proto3" json:"byzantine_validators,omitempty"`
+	TotalVotingPower      int64         `protobuf:"varint,5,opt,name=total_voting_power,json=totalVotingPower,proto3" json:"total_voting_power,omitempty"`
+	Timestamp             time.Time     `protobuf:"bytes,6,opt,name=timestamp,proto3,stdtime" json:"timestamp"`
+}
+
+func (m *LightClientAttackEvidence) Reset()         { *m = LightClientAttackEvidence{} }
+func (m *LightClientAttackEvidence) String() string { return proto.CompactTextString(m) }
+func (*LightClientAttackEvidence) ProtoMessage()    {}
+func (*LightClientAttackEvidence) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9916f59e043142ef, []int{1}
+}
+func (m *LightClientAttackEvidence) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *LightClientAttackEvidence) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_LightClientAttackEvidence.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *LightClientAttackEvidence) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LightClientAttackEvidence.Merge(m, src)
+}
+func (m *LightClientAttackEvidence) XXX_Size() int {
+	return m.Size()
+}
+func (m *LightClientAttackEvidence) XXX_DiscardUnknown() {
+	xxx_messageInfo_LightClientAttackEvidence.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LightClientAttackEvidence proto.InternalMessageInfo
+
+func (m *LightClientAttackEvidence) GetConflictingHeader() *SignedHeader {
+	if m != nil {
+		return m.ConflictingHeader
+	}
+	return nil
+}
+
+func (m *LightClientAttackEvidence) GetConflictingValidators() *ValidatorSet {
+	if m != nil {
+		return m.ConflictingValidators
+	}
+	return nil
+}
+
+func (m *LightClientAttackEvidence) GetCommonHeight() uint64 {
+	if m != nil {
+		return m.CommonHeight
+	}
+	return 0
+}
+
+func (m *LightClientAttackEvidence) GetByzantineValidators() []*Validator {
+	if m != nil {
+		return m.ByzantineValidators
+	}
+	return nil
+}
+
+func (m *LightClientAttackEvidence) GetTotalVotingPower() int64 {
+	if m != nil {
+		return m.TotalVotingPower
+	}
+	return 0
+}
+
+func (m *LightClientAttackEvidence) GetTimestamp() time.Time {
+	if m != nil {
+		return m.Timestamp
+	}
+	return time.Time{}
+}
+
+// BridgeVote is the vote of a bridge validator for a dual node proposal.
+type BridgeVote struct {
+	ProposalHash     []byte `protobuf:"bytes,1,opt,name=proposal_hash,json=proposalHash,proto3" json:"proposal_hash,omitempty"`
+	ValidatorAddress []byte `protobuf:"bytes,2,opt,name=validator_address,json=validatorAddress,proto3" json:"validator_address,omitempty"`
+	Signature        []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *BridgeVote) Reset()         { *m = BridgeVote{} }
+func (m *BridgeVote) String() string { return proto.CompactTextString(m) }
+func (*BridgeVote) ProtoMessage()    {}
+func (*BridgeVote) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9916f59e043142ef, []int{2}
+}
+func (m *BridgeVote) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BridgeVote) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BridgeVote.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *BridgeVote) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BridgeVote.Merge(m, src)
+}
+func (m *BridgeVote) XXX_Size() int {
+	return m.Size()
+}
+func (m *BridgeVote) XXX_DiscardUnknown() {
+	xxx_messageInfo_BridgeVote.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BridgeVote proto.InternalMessageInfo
+
+func (m *BridgeVote) GetProposalHash() []byte {
+	if m != nil {
+		return m.ProposalHash
+	}
+	return nil
+}
+
+func (m *BridgeVote) GetValidatorAddress() []byte {
+	if m != nil {
+		return m.ValidatorAddress
+	}
+	return nil
+}
+
+func (m *BridgeVote) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// ConflictingBridgeVoteEvidence contains evidence a bridge validator voted
+// for two proposals releasing the same deposit.
+type ConflictingBridgeVoteEvidence struct {
+	VoteA            *BridgeVote `protobuf:"bytes,1,opt,name=vote_a,json=voteA,proto3" json:"vote_a,omitempty"`
+	VoteB            *BridgeVote `protobuf:"bytes,2,opt,name=vote_b,json=voteB,proto3" json:"vote_b,omitempty"`
+	ProposalA        []byte      `protobuf:"bytes,3,opt,name=proposal_a,json=proposalA,proto3" json:"proposal_a,omitempty"`
+	ProposalB        []byte      `protobuf:"bytes,4,opt,name=proposal_b,json=proposalB,proto3" json:"proposal_b,omitempty"`
+	TotalVotingPower int64       `protobuf:"varint,5,opt,name=total_voting_power,json=totalVotingPower,proto3" json:"total_voting_power,omitempty"`
+	ValidatorPower   int64       `protobuf:"varint,6,opt,name=validator_power,json=validatorPower,proto3" json:"validator_power,omitempty"`
+	Height           uint64      `protobuf:"varint,7,opt,name=height,proto3" json:"height,omitempty"`
+	Timestamp        time.Time   `protobuf:"bytes,8,opt,name=timestamp,proto3,stdtime" json:"timestamp"`
+}
+
+func (m *ConflictingBridgeVoteEvidence) Reset()         { *m = ConflictingBridgeVoteEvidence{} }
+func (m *ConflictingBridgeVoteEvidence) String() string { return proto.CompactTextString(m) }
+func (*ConflictingBridgeVoteEvidence) ProtoMessage()    {}
+func (*ConflictingBridgeVoteEvidence) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9916f59e043142ef, []int{3}
+}
+func (m *ConflictingBridgeVoteEvidence) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ConflictingBridgeVoteEvidence) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ConflictingBridgeVoteEvidence.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ConflictingBridgeVoteEvidence) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConflictingBridgeVoteEvidence.Merge(m, src)
+}
+func (m *ConflictingBridgeVoteEvidence) XXX_Size() int {
+	return m.Size()
+}
+func (m *ConflictingBridgeVoteEvidence) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConflictingBridgeVoteEvidence.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConflictingBridgeVoteEvidence proto.InternalMessageInfo
+
+func (m *ConflictingBridgeVoteEvidence) GetVoteA() *BridgeVote {
+	if m != nil {
+		return m.VoteA
+	}
+	return nil
+}
+
+func (m *ConflictingBridgeVoteEvidence) GetVoteB() *BridgeVote {
+	if m != nil {
+		return m.VoteB
+	}
+	return nil
+}
+
+func (m *ConflictingBridgeVoteEvidence) GetProposalA() []byte {
+	if m != nil {
+		return m.ProposalA
+	}
+	return nil
+}
+
+func (m *ConflictingBridgeVoteEvidence) GetProposalB() []byte {
+	if m != nil {
+		return m.ProposalB
+	}
+	return nil
+}
+
+func (m *ConflictingBridgeVoteEvidence) GetTotalVotingPower() int64 {
+	if m != nil {
+		return m.TotalVotingPower
+	}
+	return 0
+}
+
+func (m *ConflictingBridgeVoteEvidence) GetValidatorPower() int64 {
+	if m != nil {
+		return m.ValidatorPower
+	}
+	return 0
+}
+
+func (m *ConflictingBridgeVoteEvidence) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *ConflictingBridgeVoteEvidence) GetTimestamp() time.Time {
+	if m != nil {
+		return m.Timestamp
+	}
+	return time.Time{}
+}
+
+// UnbackedReleaseEvidence contains evidence a bridge validator voted for a
+// proposal releasing a deposit which is not in its source chain.
+type UnbackedReleaseEvidence struct {
+	Vote             *BridgeVote `protobuf:"bytes,1,opt,name=vote,proto3" json:"vote,omitempty"`
+	Proposal         []byte      `protobuf:"bytes,2,opt,name=proposal,proto3" json:"proposal,omitempty"`
+	TotalVotingPower int64       `protobuf:"varint,3,opt,name=total_voting_power,json=totalVotingPower,proto3" json:"total_voting_power,omitempty"`
+	ValidatorPower   int64       `protobuf:"varint,4,opt,name=validator_power,json=validatorPower,proto3" json:"validator_power,omitempty"`
+	Height           uint64      `protobuf:"varint,5,opt,name=height,proto3" json:"height,omitempty"`
+	Timestamp        time.Time   `protobuf:"bytes,6,opt,name=timestamp,proto3,stdtime" json:"timestamp"`
+}
+
+func (m *UnbackedReleaseEvidence) Reset()         { *m = UnbackedReleaseEvidence{} }
+func (m *UnbackedReleaseEvidence) String() string { return proto.CompactTextString(m) }
+func (*UnbackedReleaseEvidence) ProtoMessage()    {}
+func (*UnbackedReleaseEvidence) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9916f59e043142ef, []int{4}
+}
+func (m *UnbackedReleaseEvidence) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *UnbackedReleaseEvidence) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_UnbackedReleaseEvidence.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *UnbackedReleaseEvidence) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnbackedReleaseEvidence.Merge(m, src)
+}
+func (m *UnbackedReleaseEvidence) XXX_Size() int {
+	return m.Size()
+}
+func (m *UnbackedReleaseEvidence) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnbackedReleaseEvidence.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnbackedReleaseEvidence proto.InternalMessageInfo
+
+func (m *UnbackedReleaseEvidence) GetVote() *BridgeVote {
+	if m != nil {
+		return m.Vote
+	}
+	return nil
+}
+
+func (m *UnbackedReleaseEvidence) GetProposal() []byte {
+	if m != nil {
+		return m.Proposal
+	}
+	return nil
+}
+
+func (m *UnbackedReleaseEvidence) GetTotalVotingPower() int64 {
+	if m != nil {
+		return m.TotalVotingPower
+	}
+	return 0
+}
+
+func (m *UnbackedReleaseEvidence) GetValidatorPower() int64 {
+	if m != nil {
+		return m.ValidatorPower
+	}
+	return 0
+}
+
+func (m *UnbackedReleaseEvidence) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *UnbackedReleaseEvidence) GetTimestamp() time.Time {
+	if m != nil {
+		return m.Timestamp
+	}
+	return time.Time{}
+}
+
 type Evidence struct {
 	// Types that are valid to be assigned to Sum:
 	//	*Evidence_DuplicateVoteEvidence
+	//	*Evidence_LightClientAttackEvidence
+	//	*Evidence_ConflictingBridgeVoteEvidence
+	//	*Evidence_UnbackedReleaseEvidence
 	Sum isEvidence_Sum `protobuf_oneof:"sum"`
 }
 
@@ -115,7 +453,7 @@ func (m *Evidence) Reset()         { *m = Evidence{} }
 func (m *Evidence) String() string { return proto.CompactTextString(m) }
 func (*Evidence) ProtoMessage()    {}
 func (*Evidence) Descriptor() ([]byte, []int) {
-	return fileDescriptor_9916f59e043142ef, []int{1}
+	return fileDescriptor_9916f59e043142ef, []int{5}
 }
 func (m *Evidence) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -153,8 +491,20 @@ type isEvidence_Sum interface {
 type Evidence_DuplicateVoteEvidence struct {
 	DuplicateVoteEvidence *DuplicateVoteEvidence `protobuf:"bytes,1,opt,name=duplicate_vote_evidence,json=duplicateVoteEvidence,proto3,oneof" json:"duplicate_vote_evidence,omitempty"`
 }
+type Evidence_LightClientAttackEvidence struct {
+	LightClientAttackEvidence *LightClientAttackEvidence `protobuf:"bytes,2,opt,name=light_client_attack_evidence,json=lightClientAttackEvidence,proto3,oneof" json:"light_client_attack_evidence,omitempty"`
+}
+type Evidence_ConflictingBridgeVoteEvidence struct {
+	ConflictingBridgeVoteEvidence *ConflictingBridgeVoteEvidence `protobuf:"bytes,3,opt,name=conflicting_bridge_vote_evidence,json=conflictingBridgeVoteEvidence,proto3,oneof" json:"conflicting_bridge_vote_evidence,omitempty"`
+}
+type Evidence_UnbackedReleaseEvidence struct {
+	UnbackedReleaseEvidence *UnbackedReleaseEvidence `protobuf:"bytes,4,opt,name=unbacked_release_evidence,json=unbackedReleaseEvidence,proto3,oneof" json:"unbacked_release_evidence,omitempty"`
+}
 
-func (*Evidence_DuplicateVoteEvidence) isEvidence_Sum() {}
+func (*Evidence_DuplicateVoteEvidence) isEvidence_Sum()         {}
+func (*Evidence_LightClientAttackEvidence) isEvidence_Sum()     {}
+func (*Evidence_ConflictingBridgeVoteEvidence) isEvidence_Sum() {}
+func (*Evidence_UnbackedReleaseEvidence) isEvidence_Sum()       {}
 
 func (m *Evidence) GetSum() isEvidence_Sum {
 	if m != nil {
@@ -170,10 +520,34 @@ func (m *Evidence) GetDuplicateVoteEvidence() *DuplicateVoteEvidence {
 	return nil
 }
 
+func (m *Evidence) GetLightClientAttackEvidence() *LightClientAttackEvidence {
+	if x, ok := m.GetSum().(*Evidence_LightClientAttackEvidence); ok {
+		return x.LightClientAttackEvidence
+	}
+	return nil
+}
+
+func (m *Evidence) GetConflictingBridgeVoteEvidence() *ConflictingBridgeVoteEvidence {
+	if x, ok := m.GetSum().(*Evidence_ConflictingBridgeVoteEvidence); ok {
+		return x.ConflictingBridgeVoteEvidence
+	}
+	return nil
+}
+
+func (m *Evidence) GetUnbackedReleaseEvidence() *UnbackedReleaseEvidence {
+	if x, ok := m.GetSum().(*Evidence_UnbackedReleaseEvidence); ok {
+		return x.UnbackedReleaseEvidence
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*Evidence) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
 		(*Evidence_DuplicateVoteEvidence)(nil),
+		(*Evidence_LightClientAttackEvidence)(nil),
+		(*Evidence_ConflictingBridgeVoteEvidence)(nil),
+		(*Evidence_UnbackedReleaseEvidence)(nil),
 	}
 }
 
@@ -186,7 +560,7 @@ func (m *EvidenceData) Reset()         { *m = EvidenceData{} }
 func (m *EvidenceData) String() string { return proto.CompactTextString(m) }
 func (*EvidenceData) ProtoMessage()    {}
 func (*EvidenceData) Descriptor() ([]byte, []int) {
-	return fileDescriptor_9916f59e043142ef, []int{2}
+	return fileDescriptor_9916f59e043142ef, []int{6}
 }
 func (m *EvidenceData) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -224,6 +598,10 @@ func (m *EvidenceData) GetEvidence() []Evidence {
 
 func init() {
 	proto.RegisterType((*DuplicateVoteEvidence)(nil), "kardiachain.types.DuplicateVoteEvidence")
+	proto.RegisterType((*LightClientAttackEvidence)(nil), "kardiachain.types.LightClientAttackEvidence")
+	proto.RegisterType((*BridgeVote)(nil), "kardiachain.types.BridgeVote")
+	proto.RegisterType((*ConflictingBridgeVoteEvidence)(nil), "kardiachain.types.ConflictingBridgeVoteEvidence")
+	proto.RegisterType((*UnbackedReleaseEvidence)(nil), "kardiachain.types.UnbackedReleaseEvidence")
 	proto.RegisterType((*Evidence)(nil), "kardiachain.types.Evidence")
 	proto.RegisterType((*EvidenceData)(nil), "kardiachain.types.EvidenceData")
 }
@@ -231,32 +609,58 @@ func init() {
 func init() { proto.RegisterFile("kardiachain/types/evidence.proto", fileDescriptor_9916f59e043142ef) }
 
 var fileDescriptor_9916f59e043142ef = []byte{
-	// 388 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0xcd, 0x4a, 0xc3, 0x40,
-	0x10, 0xc7, 0x93, 0xa6, 0x2d, 0x75, 0x2b, 0x7e, 0x04, 0x4b, 0x43, 0xc5, 0xb4, 0xf4, 0x62, 0x0e,
-	0xba, 0x81, 0x7a, 0xf1, 0xe2, 0xc1, 0x50, 0xc1, 0x8b, 0x20, 0x41, 0x7a, 0xf0, 0x12, 0x36, 0xc9,
-	0x9a, 0x2e, 0x26, 0xdd, 0x90, 0x6e, 0x22, 0xbe, 0x45, 0xf1, 0xa9, 0x7a, 0xec, 0xd1, 0x93, 0x4a,
-	0xfb, 0x22, 0x92, 0xcd, 0x87, 0x85, 0xe6, 0xe0, 0x25, 0x64, 0xe7, 0xff, 0x9b, 0x9d, 0x99, 0xff,
-	0x0e, 0x18, 0xbc, 0xa2, 0xc8, 0x25, 0xc8, 0x99, 0x22, 0x32, 0xd3, 0xd9, 0x7b, 0x88, 0xe7, 0x3a,
-	0x4e, 0x88, 0x8b, 0x67, 0x0e, 0x86, 0x61, 0x44, 0x19, 0x95, 0x8f, 0xb7, 0x08, 0xc8, 0x89, 0xde,
-	0x89, 0x47, 0x3d, 0xca, 0x55, 0x3d, 0xfd, 0xcb, 0xc0, 0xde, 0xd9, 0xee, 0x55, 0xfc, 0x9b, 0xcb,
-	0x7d, 0x8f, 0x52, 0xcf, 0xc7, 0x3a, 0x3f, 0xd9, 0xf1, 0x8b, 0xce, 0x48, 0x80, 0xe7, 0x0c, 0x05,
-	0x61, 0x06, 0x0c, 0x3f, 0x6a, 0xa0, 0x33, 0x8e, 0x43, 0x9f, 0x38, 0x88, 0xe1, 0x09, 0x65, 0xf8,
-	0x2e, 0x6f, 0x44, 0x86, 0xa0, 0x99, 0x50, 0x86, 0x2d, 0xa4, 0x88, 0x03, 0x51, 0x6b, 0x8f, 0xba,
-	0x70, 0xa7, 0x27, 0x98, 0x26, 0x98, 0x8d, 0x14, 0xbb, 0x2d, 0x79, 0x5b, 0xa9, 0xfd, 0x83, 0x37,
-	0xe4, 0x0b, 0x20, 0x33, 0xca, 0x90, 0x6f, 0x25, 0x94, 0x91, 0x99, 0x67, 0x85, 0xf4, 0x0d, 0x47,
-	0x8a, 0x34, 0x10, 0x35, 0xc9, 0x3c, 0xe2, 0xca, 0x84, 0x0b, 0x8f, 0x69, 0x5c, 0x3e, 0x07, 0x87,
-	0x09, 0xf2, 0x89, 0x8b, 0x18, 0x8d, 0x72, 0xb4, 0xce, 0xd1, 0x83, 0x32, 0x9c, 0x81, 0x06, 0xd8,
-	0x2b, 0x67, 0x54, 0x1a, 0xbc, 0x93, 0x1e, 0xcc, 0x5c, 0x80, 0x85, 0x0b, 0xf0, 0xa9, 0x20, 0x8c,
-	0xd6, 0xf2, 0xab, 0x2f, 0x2c, 0xbe, 0xfb, 0xa2, 0xf9, 0x97, 0x36, 0x8c, 0x41, 0xab, 0xb4, 0xc1,
-	0x06, 0x5d, 0xb7, 0xf0, 0xc7, 0xe2, 0x03, 0x16, 0x4f, 0x95, 0xfb, 0xa2, 0x55, 0xcc, 0x59, 0xe9,
-	0xe8, 0xbd, 0x60, 0x76, 0xdc, 0x2a, 0xc1, 0x68, 0x00, 0x69, 0x1e, 0x07, 0xc3, 0x07, 0xb0, 0x5f,
-	0x84, 0xc6, 0x88, 0x21, 0xf9, 0x06, 0xb4, 0xb6, 0x6a, 0x49, 0x5a, 0x7b, 0x74, 0x5a, 0x51, 0xab,
-	0xbc, 0xa5, 0x9e, 0x8e, 0x62, 0x96, 0x29, 0x86, 0xb9, 0x5c, 0xab, 0xe2, 0x6a, 0xad, 0x8a, 0x3f,
-	0x6b, 0x55, 0x5c, 0x6c, 0x54, 0x61, 0xb5, 0x51, 0x85, 0xcf, 0x8d, 0x2a, 0x3c, 0x5f, 0x7b, 0x84,
-	0x4d, 0x63, 0x1b, 0x3a, 0x34, 0xd0, 0xb7, 0xf7, 0xc7, 0xa3, 0x97, 0xd9, 0x31, 0xdb, 0x17, 0x7d,
-	0x67, 0xb7, 0xec, 0x26, 0x17, 0xae, 0x7e, 0x03, 0x00, 0x00, 0xff, 0xff, 0xeb, 0x0a, 0x1a, 0xc5,
-	0xc2, 0x02, 0x00, 0x00,
+	// 806 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x56, 0x4b, 0x6f, 0xd3, 0x40,
+	0x10, 0x76, 0x9e, 0xa4, 0xdb, 0x00, 0xed, 0xd2, 0x36, 0x69, 0x68, 0x1e, 0x84, 0x03, 0x11, 0x14,
+	0x07, 0x4a, 0x0f, 0x5c, 0x38, 0xc4, 0x2d, 0x52, 0x0e, 0xbc, 0xb4, 0x85, 0x1c, 0xb8, 0x58, 0x6b,
+	0x7b, 0x6b, 0x5b, 0x75, 0xbc, 0x91, 0xbd, 0x09, 0x2a, 0xf0, 0x23, 0x2a, 0xae, 0xfc, 0x04, 0xfe,
+	0x48, 0x2f, 0x48, 0x3d, 0x72, 0x02, 0xd4, 0xfe, 0x0d, 0x0e, 0xc8, 0xeb, 0x67, 0x1a, 0xa7, 0x2a,
+	0x95, 0xb8, 0x44, 0xd9, 0x99, 0x6f, 0x76, 0x66, 0xbe, 0x79, 0x78, 0x41, 0xeb, 0x00, 0x3b, 0x9a,
+	0x89, 0x55, 0x03, 0x9b, 0x76, 0x97, 0x1d, 0x8e, 0x88, 0xdb, 0x25, 0x13, 0x53, 0x23, 0xb6, 0x4a,
+	0xc4, 0x91, 0x43, 0x19, 0x85, 0xcb, 0x09, 0x84, 0xc8, 0x11, 0xb5, 0x15, 0x9d, 0xea, 0x94, 0x6b,
+	0xbb, 0xde, 0x3f, 0x1f, 0x58, 0xab, 0xcf, 0x5e, 0xc5, 0x7f, 0x03, 0xf5, 0x9d, 0x59, 0xf5, 0x04,
+	0x5b, 0xa6, 0x86, 0x19, 0x75, 0x02, 0x48, 0x53, 0xa7, 0x54, 0xb7, 0x48, 0x97, 0x9f, 0x94, 0xf1,
+	0x7e, 0x97, 0x99, 0x43, 0xe2, 0x32, 0x3c, 0x1c, 0xf9, 0x80, 0xf6, 0x97, 0x2c, 0x58, 0xdd, 0x1d,
+	0x8f, 0x2c, 0x53, 0xc5, 0x8c, 0x0c, 0x28, 0x23, 0xcf, 0x83, 0x58, 0xa1, 0x08, 0x8a, 0x13, 0xca,
+	0x88, 0x8c, 0xab, 0x99, 0x56, 0xa6, 0xb3, 0xb8, 0x55, 0x11, 0x67, 0xc2, 0x16, 0x3d, 0x03, 0x54,
+	0xf0, 0x60, 0xbd, 0x08, 0xaf, 0x54, 0xb3, 0x97, 0xc0, 0x4b, 0x70, 0x13, 0x40, 0x46, 0x19, 0xb6,
+	0xe4, 0x09, 0x65, 0xa6, 0xad, 0xcb, 0x23, 0xfa, 0x81, 0x38, 0xd5, 0x5c, 0x2b, 0xd3, 0xc9, 0xa1,
+	0x25, 0xae, 0x19, 0x70, 0xc5, 0x1b, 0x4f, 0x0e, 0xef, 0x81, 0x9b, 0x51, 0x6e, 0x01, 0x34, 0xcf,
+	0xa1, 0x37, 0x22, 0xb1, 0x0f, 0x94, 0xc0, 0x42, 0x94, 0x63, 0xb5, 0xc0, 0x23, 0xa9, 0x89, 0x3e,
+	0x0b, 0x62, 0xc8, 0x82, 0xf8, 0x36, 0x44, 0x48, 0xa5, 0xe3, 0x9f, 0x4d, 0xe1, 0xe8, 0x57, 0x33,
+	0x83, 0x62, 0xb3, 0xf6, 0xb7, 0x1c, 0x58, 0x7f, 0x61, 0xea, 0x06, 0xdb, 0xb1, 0x4c, 0x62, 0xb3,
+	0x1e, 0x63, 0x58, 0x3d, 0x88, 0x88, 0x79, 0x05, 0xa0, 0x4a, 0xed, 0x7d, 0xcb, 0x54, 0x79, 0xdc,
+	0x06, 0xc1, 0x1a, 0x71, 0x02, 0x92, 0x9a, 0x29, 0x49, 0xef, 0x99, 0xba, 0x4d, 0xb4, 0x3e, 0x87,
+	0xa1, 0xe5, 0x84, 0xa9, 0x2f, 0x82, 0x03, 0xb0, 0x96, 0xbc, 0x2f, 0xca, 0xc7, 0xad, 0x66, 0xe7,
+	0xde, 0x39, 0x08, 0x41, 0x7b, 0x84, 0xa1, 0xd5, 0x84, 0x79, 0xa4, 0x70, 0xe1, 0x5d, 0x70, 0x5d,
+	0xa5, 0xc3, 0x21, 0xb5, 0x65, 0x83, 0x78, 0xc9, 0x70, 0x6e, 0xf3, 0xa8, 0xec, 0x0b, 0xfb, 0x5c,
+	0x06, 0x5f, 0x83, 0x15, 0xe5, 0xf0, 0x23, 0xb6, 0x99, 0x69, 0x93, 0xa4, 0xeb, 0x7c, 0x2b, 0xd7,
+	0x59, 0xdc, 0xda, 0xb8, 0xc8, 0x35, 0xba, 0x15, 0x59, 0x26, 0xbc, 0xa6, 0x97, 0xb5, 0x30, 0xa7,
+	0xac, 0x53, 0xd5, 0x2a, 0x5e, 0xad, 0x5a, 0x9f, 0x01, 0x90, 0x1c, 0x53, 0xd3, 0x79, 0xfb, 0x7a,
+	0x59, 0x8f, 0x1c, 0x3a, 0xa2, 0x2e, 0xb6, 0x64, 0x03, 0xbb, 0x06, 0x2f, 0x4c, 0x19, 0x95, 0x43,
+	0x61, 0x1f, 0xbb, 0x06, 0x7c, 0x00, 0x96, 0xe3, 0x6e, 0xc2, 0x9a, 0xe6, 0x10, 0xd7, 0x67, 0xbb,
+	0x8c, 0x96, 0x22, 0x45, 0xcf, 0x97, 0xc3, 0x0d, 0xb0, 0xe0, 0x9a, 0xba, 0x8d, 0xd9, 0xd8, 0x21,
+	0x9c, 0xc3, 0x32, 0x8a, 0x05, 0xed, 0x3f, 0x59, 0x50, 0xdf, 0x89, 0xf9, 0x8f, 0x23, 0x89, 0xfa,
+	0x65, 0xfb, 0xdc, 0x20, 0xd5, 0x53, 0x48, 0x8d, 0xcd, 0xc2, 0x71, 0xda, 0x3e, 0x37, 0x4e, 0x97,
+	0xb1, 0x92, 0x60, 0x1d, 0x80, 0x28, 0x7b, 0x1c, 0x06, 0x1b, 0x4a, 0x7a, 0x53, 0x6a, 0xa5, 0x9a,
+	0x9f, 0x56, 0x4b, 0xff, 0x58, 0xbb, 0x94, 0x91, 0x2c, 0xa6, 0x8e, 0xe4, 0x1a, 0x28, 0x06, 0x1d,
+	0x78, 0x8d, 0x77, 0x60, 0x70, 0x9a, 0x2e, 0x7e, 0xe9, 0x6a, 0xc5, 0xff, 0x9a, 0x05, 0x95, 0x77,
+	0xb6, 0x82, 0xd5, 0x03, 0xa2, 0x21, 0x62, 0x11, 0xec, 0xc6, 0xc4, 0x3f, 0x06, 0x79, 0x8f, 0x95,
+	0xcb, 0xd1, 0xce, 0xa1, 0xb0, 0x06, 0x4a, 0x21, 0x1d, 0x41, 0x3f, 0x44, 0xe7, 0xff, 0xb5, 0xb0,
+	0x62, 0x76, 0x0a, 0xf3, 0xd9, 0xb9, 0xe2, 0x68, 0x7c, 0xcf, 0x81, 0x52, 0x44, 0x87, 0x02, 0x2a,
+	0x5a, 0xb8, 0xe9, 0x65, 0xde, 0x5b, 0xe1, 0x77, 0x29, 0x60, 0xa8, 0x93, 0xc2, 0x50, 0xea, 0xb7,
+	0xa1, 0x2f, 0xa0, 0x55, 0x2d, 0x4d, 0x01, 0x29, 0xd8, 0xb0, 0xbc, 0xe8, 0x65, 0x95, 0x6f, 0x4e,
+	0x19, 0xf3, 0xd5, 0x19, 0x3b, 0xf2, 0x7b, 0x79, 0x33, 0xc5, 0xd1, 0xdc, 0x7d, 0xdb, 0x17, 0xd0,
+	0xba, 0x35, 0x4f, 0x09, 0x3f, 0x81, 0x56, 0x72, 0x79, 0x2a, 0xbc, 0xa0, 0xe7, 0xb2, 0xcb, 0x71,
+	0xa7, 0x8f, 0x52, 0x9c, 0x5e, 0x38, 0xb8, 0x7d, 0x01, 0xd5, 0xd5, 0x0b, 0x27, 0xdb, 0x00, 0xeb,
+	0xe3, 0xa0, 0xf7, 0x64, 0xc7, 0x6f, 0xbe, 0xd8, 0x6b, 0x9e, 0x7b, 0xbd, 0x9f, 0xe2, 0x75, 0x4e,
+	0xbf, 0xf6, 0x05, 0x54, 0x19, 0xa7, 0xab, 0xa4, 0x02, 0xc8, 0xb9, 0xe3, 0x61, 0xfb, 0x25, 0x28,
+	0x87, 0xa2, 0x5d, 0xcc, 0x30, 0x7c, 0x06, 0x4a, 0x89, 0x1a, 0x7a, 0x1b, 0xfb, 0x76, 0x8a, 0xbf,
+	0xe8, 0x96, 0xbc, 0xd7, 0x23, 0x28, 0x32, 0x91, 0xd0, 0xf1, 0x69, 0x23, 0x73, 0x72, 0xda, 0xc8,
+	0xfc, 0x3e, 0x6d, 0x64, 0x8e, 0xce, 0x1a, 0xc2, 0xc9, 0x59, 0x43, 0xf8, 0x71, 0xd6, 0x10, 0xde,
+	0x3f, 0xd5, 0x4d, 0x66, 0x8c, 0x15, 0x51, 0xa5, 0xc3, 0x6e, 0xf2, 0x95, 0xa1, 0xd3, 0x87, 0xfe,
+	0xd1, 0x7f, 0x51, 0x74, 0x67, 0x5e, 0x20, 0x4a, 0x91, 0x2b, 0x9e, 0xfc, 0x1d, 0x00, 0xfe, 0x8d,
+	0x1b, 0x36, 0x07, 0x09, 0x00, 0x00,
 }
 
 func (m *DuplicateVoteEvidence) Marshal() (dAtA []byte, err error) {
@@ -324,7 +728,7 @@ func (m *DuplicateVoteEvidence) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *Evidence) Marshal() (dAtA []byte, err error) {
+func (m *LightClientAttackEvidence) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -334,31 +738,303 @@ func (m *Evidence) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *Evidence) MarshalTo(dAtA []byte) (int, error) {
+func (m *LightClientAttackEvidence) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *Evidence) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *LightClientAttackEvidence) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if m.Sum != nil {
-		{
-			size := m.Sum.Size()
-			i -= size
-			if _, err := m.Sum.MarshalTo(dAtA[i:]); err != nil {
-				return 0, err
+	n4, err4 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Timestamp, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Timestamp):])
+	if err4 != nil {
+		return 0, err4
+	}
+	i -= n4
+	i = encodeVarintEvidence(dAtA, i, uint64(n4))
+	i--
+	dAtA[i] = 0x32
+	if m.TotalVotingPower != 0 {
+		i = encodeVarintEvidence(dAtA, i, uint64(m.TotalVotingPower))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.ByzantineValidators) > 0 {
+		for iNdEx := len(m.ByzantineValidators) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.ByzantineValidators[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintEvidence(dAtA, i, uint64(size))
 			}
+			i--
+			dAtA[i] = 0x22
 		}
 	}
-	return len(dAtA) - i, nil
-}
-
-func (m *Evidence_DuplicateVoteEvidence) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+	if m.CommonHeight != 0 {
+		i = encodeVarintEvidence(dAtA, i, uint64(m.CommonHeight))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.ConflictingValidators != nil {
+		{
+			size, err := m.ConflictingValidators.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintEvidence(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.ConflictingHeader != nil {
+		{
+			size, err := m.ConflictingHeader.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintEvidence(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *BridgeVote) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *BridgeVote) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *BridgeVote) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Signature) > 0 {
+		i -= len(m.Signature)
+		copy(dAtA[i:], m.Signature)
+		i = encodeVarintEvidence(dAtA, i, uint64(len(m.Signature)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.ValidatorAddress) > 0 {
+		i -= len(m.ValidatorAddress)
+		copy(dAtA[i:], m.ValidatorAddress)
+		i = encodeVarintEvidence(dAtA, i, uint64(len(m.ValidatorAddress)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.ProposalHash) > 0 {
+		i -= len(m.ProposalHash)
+		copy(dAtA[i:], m.ProposalHash)
+		i = encodeVarintEvidence(dAtA, i, uint64(len(m.ProposalHash)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ConflictingBridgeVoteEvidence) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ConflictingBridgeVoteEvidence) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ConflictingBridgeVoteEvidence) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	n7, err7 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Timestamp, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Timestamp):])
+	if err7 != nil {
+		return 0, err7
+	}
+	i -= n7
+	i = encodeVarintEvidence(dAtA, i, uint64(n7))
+	i--
+	dAtA[i] = 0x42
+	if m.Height != 0 {
+		i = encodeVarintEvidence(dAtA, i, uint64(m.Height))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.ValidatorPower != 0 {
+		i = encodeVarintEvidence(dAtA, i, uint64(m.ValidatorPower))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.TotalVotingPower != 0 {
+		i = encodeVarintEvidence(dAtA, i, uint64(m.TotalVotingPower))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.ProposalB) > 0 {
+		i -= len(m.ProposalB)
+		copy(dAtA[i:], m.ProposalB)
+		i = encodeVarintEvidence(dAtA, i, uint64(len(m.ProposalB)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.ProposalA) > 0 {
+		i -= len(m.ProposalA)
+		copy(dAtA[i:], m.ProposalA)
+		i = encodeVarintEvidence(dAtA, i, uint64(len(m.ProposalA)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.VoteB != nil {
+		{
+			size, err := m.VoteB.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintEvidence(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.VoteA != nil {
+		{
+			size, err := m.VoteA.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintEvidence(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *UnbackedReleaseEvidence) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *UnbackedReleaseEvidence) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *UnbackedReleaseEvidence) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	n10, err10 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Timestamp, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Timestamp):])
+	if err10 != nil {
+		return 0, err10
+	}
+	i -= n10
+	i = encodeVarintEvidence(dAtA, i, uint64(n10))
+	i--
+	dAtA[i] = 0x32
+	if m.Height != 0 {
+		i = encodeVarintEvidence(dAtA, i, uint64(m.Height))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.ValidatorPower != 0 {
+		i = encodeVarintEvidence(dAtA, i, uint64(m.ValidatorPower))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.TotalVotingPower != 0 {
+		i = encodeVarintEvidence(dAtA, i, uint64(m.TotalVotingPower))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Proposal) > 0 {
+		i -= len(m.Proposal)
+		copy(dAtA[i:], m.Proposal)
+		i = encodeVarintEvidence(dAtA, i, uint64(len(m.Proposal)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Vote != nil {
+		{
+			size, err := m.Vote.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintEvidence(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Evidence) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Evidence) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Evidence) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Sum != nil {
+		{
+			size := m.Sum.Size()
+			i -= size
+			if _, err := m.Sum.MarshalTo(dAtA[i:]); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Evidence_DuplicateVoteEvidence) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
 func (m *Evidence_DuplicateVoteEvidence) MarshalToSizedBuffer(dAtA []byte) (int, error) {
@@ -377,6 +1053,69 @@ func (m *Evidence_DuplicateVoteEvidence) MarshalToSizedBuffer(dAtA []byte) (int,
 	}
 	return len(dAtA) - i, nil
 }
+func (m *Evidence_LightClientAttackEvidence) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Evidence_LightClientAttackEvidence) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.LightClientAttackEvidence != nil {
+		{
+			size, err := m.LightClientAttackEvidence.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintEvidence(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	return len(dAtA) - i, nil
+}
+func (m *Evidence_ConflictingBridgeVoteEvidence) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Evidence_ConflictingBridgeVoteEvidence) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.ConflictingBridgeVoteEvidence != nil {
+		{
+			size, err := m.ConflictingBridgeVoteEvidence.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintEvidence(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1a
+	}
+	return len(dAtA) - i, nil
+}
+func (m *Evidence_UnbackedReleaseEvidence) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Evidence_UnbackedReleaseEvidence) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.UnbackedReleaseEvidence != nil {
+		{
+			size, err := m.UnbackedReleaseEvidence.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintEvidence(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x22
+	}
+	return len(dAtA) - i, nil
+}
 func (m *EvidenceData) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -404,98 +1143,1102 @@ func (m *EvidenceData) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 				if err != nil {
 					return 0, err
 				}
-				i -= size
-				i = encodeVarintEvidence(dAtA, i, uint64(size))
+				i -= size
+				i = encodeVarintEvidence(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintEvidence(dAtA []byte, offset int, v uint64) int {
+	offset -= sovEvidence(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *DuplicateVoteEvidence) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.VoteA != nil {
+		l = m.VoteA.Size()
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	if m.VoteB != nil {
+		l = m.VoteB.Size()
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	if m.TotalVotingPower != 0 {
+		n += 1 + sovEvidence(uint64(m.TotalVotingPower))
+	}
+	if m.ValidatorPower != 0 {
+		n += 1 + sovEvidence(uint64(m.ValidatorPower))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdTime(m.Timestamp)
+	n += 1 + l + sovEvidence(uint64(l))
+	return n
+}
+
+func (m *LightClientAttackEvidence) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ConflictingHeader != nil {
+		l = m.ConflictingHeader.Size()
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	if m.ConflictingValidators != nil {
+		l = m.ConflictingValidators.Size()
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	if m.CommonHeight != 0 {
+		n += 1 + sovEvidence(uint64(m.CommonHeight))
+	}
+	if len(m.ByzantineValidators) > 0 {
+		for _, e := range m.ByzantineValidators {
+			l = e.Size()
+			n += 1 + l + sovEvidence(uint64(l))
+		}
+	}
+	if m.TotalVotingPower != 0 {
+		n += 1 + sovEvidence(uint64(m.TotalVotingPower))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdTime(m.Timestamp)
+	n += 1 + l + sovEvidence(uint64(l))
+	return n
+}
+
+func (m *BridgeVote) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.ProposalHash)
+	if l > 0 {
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	l = len(m.ValidatorAddress)
+	if l > 0 {
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	return n
+}
+
+func (m *ConflictingBridgeVoteEvidence) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.VoteA != nil {
+		l = m.VoteA.Size()
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	if m.VoteB != nil {
+		l = m.VoteB.Size()
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	l = len(m.ProposalA)
+	if l > 0 {
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	l = len(m.ProposalB)
+	if l > 0 {
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	if m.TotalVotingPower != 0 {
+		n += 1 + sovEvidence(uint64(m.TotalVotingPower))
+	}
+	if m.ValidatorPower != 0 {
+		n += 1 + sovEvidence(uint64(m.ValidatorPower))
+	}
+	if m.Height != 0 {
+		n += 1 + sovEvidence(uint64(m.Height))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdTime(m.Timestamp)
+	n += 1 + l + sovEvidence(uint64(l))
+	return n
+}
+
+func (m *UnbackedReleaseEvidence) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Vote != nil {
+		l = m.Vote.Size()
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	l = len(m.Proposal)
+	if l > 0 {
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	if m.TotalVotingPower != 0 {
+		n += 1 + sovEvidence(uint64(m.TotalVotingPower))
+	}
+	if m.ValidatorPower != 0 {
+		n += 1 + sovEvidence(uint64(m.ValidatorPower))
+	}
+	if m.Height != 0 {
+		n += 1 + sovEvidence(uint64(m.Height))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdTime(m.Timestamp)
+	n += 1 + l + sovEvidence(uint64(l))
+	return n
+}
+
+func (m *Evidence) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Sum != nil {
+		n += m.Sum.Size()
+	}
+	return n
+}
+
+func (m *Evidence_DuplicateVoteEvidence) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.DuplicateVoteEvidence != nil {
+		l = m.DuplicateVoteEvidence.Size()
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	return n
+}
+func (m *Evidence_LightClientAttackEvidence) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.LightClientAttackEvidence != nil {
+		l = m.LightClientAttackEvidence.Size()
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	return n
+}
+func (m *Evidence_ConflictingBridgeVoteEvidence) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ConflictingBridgeVoteEvidence != nil {
+		l = m.ConflictingBridgeVoteEvidence.Size()
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	return n
+}
+func (m *Evidence_UnbackedReleaseEvidence) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.UnbackedReleaseEvidence != nil {
+		l = m.UnbackedReleaseEvidence.Size()
+		n += 1 + l + sovEvidence(uint64(l))
+	}
+	return n
+}
+func (m *EvidenceData) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Evidence) > 0 {
+		for _, e := range m.Evidence {
+			l = e.Size()
+			n += 1 + l + sovEvidence(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovEvidence(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozEvidence(x uint64) (n int) {
+	return sovEvidence(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *DuplicateVoteEvidence) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowEvidence
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DuplicateVoteEvidence: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DuplicateVoteEvidence: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VoteA", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.VoteA == nil {
+				m.VoteA = &Vote{}
+			}
+			if err := m.VoteA.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VoteB", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.VoteB == nil {
+				m.VoteB = &Vote{}
+			}
+			if err := m.VoteB.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalVotingPower", wireType)
+			}
+			m.TotalVotingPower = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TotalVotingPower |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ValidatorPower", wireType)
+			}
+			m.ValidatorPower = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ValidatorPower |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.Timestamp, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipEvidence(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LightClientAttackEvidence) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowEvidence
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LightClientAttackEvidence: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LightClientAttackEvidence: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConflictingHeader", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ConflictingHeader == nil {
+				m.ConflictingHeader = &SignedHeader{}
+			}
+			if err := m.ConflictingHeader.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConflictingValidators", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ConflictingValidators == nil {
+				m.ConflictingValidators = &ValidatorSet{}
+			}
+			if err := m.ConflictingValidators.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CommonHeight", wireType)
+			}
+			m.CommonHeight = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CommonHeight |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ByzantineValidators", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ByzantineValidators = append(m.ByzantineValidators, &Validator{})
+			if err := m.ByzantineValidators[len(m.ByzantineValidators)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalVotingPower", wireType)
+			}
+			m.TotalVotingPower = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TotalVotingPower |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.Timestamp, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipEvidence(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *BridgeVote) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowEvidence
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: BridgeVote: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: BridgeVote: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProposalHash", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProposalHash = append(m.ProposalHash[:0], dAtA[iNdEx:postIndex]...)
+			if m.ProposalHash == nil {
+				m.ProposalHash = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ValidatorAddress", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ValidatorAddress = append(m.ValidatorAddress[:0], dAtA[iNdEx:postIndex]...)
+			if m.ValidatorAddress == nil {
+				m.ValidatorAddress = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipEvidence(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ConflictingBridgeVoteEvidence) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowEvidence
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ConflictingBridgeVoteEvidence: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ConflictingBridgeVoteEvidence: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VoteA", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.VoteA == nil {
+				m.VoteA = &BridgeVote{}
+			}
+			if err := m.VoteA.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field VoteB", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.VoteB == nil {
+				m.VoteB = &BridgeVote{}
+			}
+			if err := m.VoteB.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProposalA", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProposalA = append(m.ProposalA[:0], dAtA[iNdEx:postIndex]...)
+			if m.ProposalA == nil {
+				m.ProposalA = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProposalB", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProposalB = append(m.ProposalB[:0], dAtA[iNdEx:postIndex]...)
+			if m.ProposalB == nil {
+				m.ProposalB = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalVotingPower", wireType)
+			}
+			m.TotalVotingPower = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TotalVotingPower |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ValidatorPower", wireType)
+			}
+			m.ValidatorPower = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ValidatorPower |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Height", wireType)
+			}
+			m.Height = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Height |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			i--
-			dAtA[i] = 0xa
+			if msglen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.Timestamp, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipEvidence(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
 		}
 	}
-	return len(dAtA) - i, nil
-}
-
-func encodeVarintEvidence(dAtA []byte, offset int, v uint64) int {
-	offset -= sovEvidence(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
-	}
-	dAtA[offset] = uint8(v)
-	return base
-}
-func (m *DuplicateVoteEvidence) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.VoteA != nil {
-		l = m.VoteA.Size()
-		n += 1 + l + sovEvidence(uint64(l))
-	}
-	if m.VoteB != nil {
-		l = m.VoteB.Size()
-		n += 1 + l + sovEvidence(uint64(l))
-	}
-	if m.TotalVotingPower != 0 {
-		n += 1 + sovEvidence(uint64(m.TotalVotingPower))
-	}
-	if m.ValidatorPower != 0 {
-		n += 1 + sovEvidence(uint64(m.ValidatorPower))
-	}
-	l = github_com_gogo_protobuf_types.SizeOfStdTime(m.Timestamp)
-	n += 1 + l + sovEvidence(uint64(l))
-	return n
-}
-
-func (m *Evidence) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.Sum != nil {
-		n += m.Sum.Size()
-	}
-	return n
-}
 
-func (m *Evidence_DuplicateVoteEvidence) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.DuplicateVoteEvidence != nil {
-		l = m.DuplicateVoteEvidence.Size()
-		n += 1 + l + sovEvidence(uint64(l))
-	}
-	return n
-}
-func (m *EvidenceData) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if len(m.Evidence) > 0 {
-		for _, e := range m.Evidence {
-			l = e.Size()
-			n += 1 + l + sovEvidence(uint64(l))
-		}
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return n
-}
-
-func sovEvidence(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
-}
-func sozEvidence(x uint64) (n int) {
-	return sovEvidence(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+	return nil
 }
-func (m *DuplicateVoteEvidence) Unmarshal(dAtA []byte) error {
+func (m *UnbackedReleaseEvidence) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -518,15 +2261,15 @@ func (m *DuplicateVoteEvidence) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: DuplicateVoteEvidence: wiretype end group for non-group")
+			return fmt.Errorf("proto: UnbackedReleaseEvidence: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: DuplicateVoteEvidence: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: UnbackedReleaseEvidence: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field VoteA", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Vote", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -553,18 +2296,18 @@ func (m *DuplicateVoteEvidence) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.VoteA == nil {
-				m.VoteA = &Vote{}
+			if m.Vote == nil {
+				m.Vote = &BridgeVote{}
 			}
-			if err := m.VoteA.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Vote.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field VoteB", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Proposal", wireType)
 			}
-			var msglen int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowEvidence
@@ -574,26 +2317,24 @@ func (m *DuplicateVoteEvidence) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthEvidence
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + byteLen
 			if postIndex < 0 {
 				return ErrInvalidLengthEvidence
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.VoteB == nil {
-				m.VoteB = &Vote{}
-			}
-			if err := m.VoteB.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.Proposal = append(m.Proposal[:0], dAtA[iNdEx:postIndex]...)
+			if m.Proposal == nil {
+				m.Proposal = []byte{}
 			}
 			iNdEx = postIndex
 		case 3:
@@ -635,6 +2376,25 @@ func (m *DuplicateVoteEvidence) Unmarshal(dAtA []byte) error {
 				}
 			}
 		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Height", wireType)
+			}
+			m.Height = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Height |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
 			if wireType != 2 {
 				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
 			}
@@ -752,6 +2512,111 @@ func (m *Evidence) Unmarshal(dAtA []byte) error {
 			}
 			m.Sum = &Evidence_DuplicateVoteEvidence{v}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LightClientAttackEvidence", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &LightClientAttackEvidence{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Sum = &Evidence_LightClientAttackEvidence{v}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConflictingBridgeVoteEvidence", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &ConflictingBridgeVoteEvidence{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Sum = &Evidence_ConflictingBridgeVoteEvidence{v}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UnbackedReleaseEvidence", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEvidence
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthEvidence
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &UnbackedReleaseEvidence{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Sum = &Evidence_UnbackedReleaseEvidence{v}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipEvidence(dAtA[iNdEx:])
@@ -33,6 +33,7 @@ type ConsensusParams struct {
 	Block     BlockParams     `protobuf:"bytes,1,opt,name=block,proto3" json:"block"`
 	Evidence  EvidenceParams  `protobuf:"bytes,2,opt,name=evidence,proto3" json:"evidence"`
 	Validator ValidatorParams `protobuf:"bytes,3,opt,name=validator,proto3" json:"validator"`
+	Timeout   TimeoutParams   `protobuf:"bytes,4,opt,name=timeout,proto3" json:"timeout"`
 }
 
 func (m *ConsensusParams) Reset()         { *m = ConsensusParams{} }
@@ -89,6 +90,13 @@ func (m *ConsensusParams) GetValidator() ValidatorParams {
 	return ValidatorParams{}
 }
 
+func (m *ConsensusParams) GetTimeout() TimeoutParams {
+	if m != nil {
+		return m.Timeout
+	}
+	return TimeoutParams{}
+}
+
 // BlockParams contains limits on the block size.
 type BlockParams struct {
 	// Max block size, in bytes.
@@ -235,6 +243,9 @@ func (m *EvidenceParams) GetMaxBytes() int64 {
 // NOTE: uses ABCI pubkey naming, not Amino names.
 type ValidatorParams struct {
 	PubKeyTypes []string `protobuf:"bytes,1,rep,name=pub_key_types,json=pubKeyTypes,proto3" json:"pub_key_types,omitempty"`
+	// Aggregate the precommits of validators with a BLS key into a single
+	// signature in the commits.
+	AggregatePrecommits bool `protobuf:"varint,2,opt,name=aggregate_precommits,json=aggregatePrecommits,proto3" json:"aggregate_precommits,omitempty"`
 }
 
 func (m *ValidatorParams) Reset()         { *m = ValidatorParams{} }
@@ -277,47 +288,159 @@ func (m *ValidatorParams) GetPubKeyTypes() []string {
 	return nil
 }
 
+func (m *ValidatorParams) GetAggregatePrecommits() bool {
+	if m != nil {
+		return m.AggregatePrecommits
+	}
+	return false
+}
+
+// TimeoutParams determine how long the consensus waits in each step of a round.
+// The timeout of a step in round r is the base timeout plus r times its delta.
+// Unset (zero) timeouts fall back to the node's consensus config.
+type TimeoutParams struct {
+	Propose        time.Duration `protobuf:"bytes,1,opt,name=propose,proto3,stdduration" json:"propose"`
+	ProposeDelta   time.Duration `protobuf:"bytes,2,opt,name=propose_delta,json=proposeDelta,proto3,stdduration" json:"propose_delta"`
+	Prevote        time.Duration `protobuf:"bytes,3,opt,name=prevote,proto3,stdduration" json:"prevote"`
+	PrevoteDelta   time.Duration `protobuf:"bytes,4,opt,name=prevote_delta,json=prevoteDelta,proto3,stdduration" json:"prevote_delta"`
+	Precommit      time.Duration `protobuf:"bytes,5,opt,name=precommit,proto3,stdduration" json:"precommit"`
+	PrecommitDelta time.Duration `protobuf:"bytes,6,opt,name=precommit_delta,json=precommitDelta,proto3,stdduration" json:"precommit_delta"`
+	// Time to wait after committing a block, before starting the next height.
+	Commit time.Duration `protobuf:"bytes,7,opt,name=commit,proto3,stdduration" json:"commit"`
+}
+
+func (m *TimeoutParams) Reset()         { *m = TimeoutParams{} }
+func (m *TimeoutParams) String() string { return proto.CompactTextString(m) }
+func (*TimeoutParams) ProtoMessage()    {}
+func (*TimeoutParams) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c77c4fff20abe978, []int{4}
+}
+func (m *TimeoutParams) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TimeoutParams) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TimeoutParams.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TimeoutParams) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TimeoutParams.Merge(m, src)
+}
+func (m *TimeoutParams) XXX_Size() int {
+	return m.Size()
+}
+func (m *TimeoutParams) XXX_DiscardUnknown() {
+	xxx_messageInfo_TimeoutParams.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TimeoutParams proto.InternalMessageInfo
+
+func (m *TimeoutParams) GetPropose() time.Duration {
+	if m != nil {
+		return m.Propose
+	}
+	return 0
+}
+
+func (m *TimeoutParams) GetProposeDelta() time.Duration {
+	if m != nil {
+		return m.ProposeDelta
+	}
+	return 0
+}
+
+func (m *TimeoutParams) GetPrevote() time.Duration {
+	if m != nil {
+		return m.Prevote
+	}
+	return 0
+}
+
+func (m *TimeoutParams) GetPrevoteDelta() time.Duration {
+	if m != nil {
+		return m.PrevoteDelta
+	}
+	return 0
+}
+
+func (m *TimeoutParams) GetPrecommit() time.Duration {
+	if m != nil {
+		return m.Precommit
+	}
+	return 0
+}
+
+func (m *TimeoutParams) GetPrecommitDelta() time.Duration {
+	if m != nil {
+		return m.PrecommitDelta
+	}
+	return 0
+}
+
+func (m *TimeoutParams) GetCommit() time.Duration {
+	if m != nil {
+		return m.Commit
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*ConsensusParams)(nil), "kardiachain.types.ConsensusParams")
 	proto.RegisterType((*BlockParams)(nil), "kardiachain.types.BlockParams")
 	proto.RegisterType((*EvidenceParams)(nil), "kardiachain.types.EvidenceParams")
 	proto.RegisterType((*ValidatorParams)(nil), "kardiachain.types.ValidatorParams")
+	proto.RegisterType((*TimeoutParams)(nil), "kardiachain.types.TimeoutParams")
 }
 
 func init() { proto.RegisterFile("kardiachain/types/params.proto", fileDescriptor_c77c4fff20abe978) }
 
 var fileDescriptor_c77c4fff20abe978 = []byte{
-	// 465 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x52, 0xcd, 0x6e, 0xd3, 0x40,
-	0x10, 0xce, 0xe2, 0x52, 0x92, 0x0d, 0x34, 0xb0, 0x42, 0x22, 0x14, 0x69, 0x13, 0x7c, 0xaa, 0x84,
-	0xb0, 0x25, 0xb8, 0xa0, 0x5e, 0x10, 0x2e, 0x3f, 0x42, 0xa8, 0x08, 0x59, 0xa8, 0x07, 0x2e, 0xd6,
-	0x38, 0x59, 0xb6, 0x56, 0xb2, 0x5e, 0xcb, 0xbb, 0xae, 0x92, 0xb7, 0xe0, 0xc8, 0xb1, 0x47, 0x78,
-	0x03, 0x1e, 0xa1, 0xc7, 0x1e, 0x11, 0x07, 0x40, 0xc9, 0x85, 0xc7, 0x40, 0xbb, 0x1b, 0x43, 0xdc,
-	0xe6, 0xb6, 0xb3, 0xdf, 0x37, 0xdf, 0xcc, 0x37, 0x33, 0x98, 0x4e, 0xa0, 0x1c, 0x67, 0x30, 0x3a,
-	0x86, 0x2c, 0x0f, 0xf5, 0xbc, 0x60, 0x2a, 0x2c, 0xa0, 0x04, 0xa1, 0x82, 0xa2, 0x94, 0x5a, 0x92,
-	0x5b, 0x6b, 0x78, 0x60, 0xf1, 0xdd, 0xdb, 0x5c, 0x72, 0x69, 0xd1, 0xd0, 0xbc, 0x1c, 0x71, 0x97,
-	0x72, 0x29, 0xf9, 0x94, 0x85, 0x36, 0x4a, 0xab, 0x8f, 0xe1, 0xb8, 0x2a, 0x41, 0x67, 0x32, 0x77,
-	0xb8, 0xff, 0x03, 0xe1, 0xde, 0x81, 0xcc, 0x15, 0xcb, 0x55, 0xa5, 0xde, 0xd9, 0x12, 0x64, 0x1f,
-	0x5f, 0x4d, 0xa7, 0x72, 0x34, 0xe9, 0xa3, 0x21, 0xda, 0xeb, 0x3e, 0xa2, 0xc1, 0xa5, 0x62, 0x41,
-	0x64, 0x70, 0x47, 0x8f, 0xb6, 0xce, 0x7e, 0x0e, 0x5a, 0xb1, 0x4b, 0x21, 0x07, 0xb8, 0xcd, 0x4e,
-	0xb2, 0x31, 0xcb, 0x47, 0xac, 0x7f, 0xc5, 0xa6, 0xdf, 0xdf, 0x90, 0xfe, 0x62, 0x45, 0x69, 0x28,
-	0xfc, 0x4b, 0x24, 0x2f, 0x71, 0xe7, 0x04, 0xa6, 0xd9, 0x18, 0xb4, 0x2c, 0xfb, 0x9e, 0x55, 0xf1,
-	0x37, 0xa8, 0x1c, 0xd5, 0x9c, 0x86, 0xcc, 0xff, 0x54, 0x9f, 0xe1, 0xee, 0x5a, 0xa3, 0xe4, 0x1e,
-	0xee, 0x08, 0x98, 0x25, 0xe9, 0x5c, 0x33, 0x65, 0xbd, 0x79, 0x71, 0x5b, 0xc0, 0x2c, 0x32, 0x31,
-	0xb9, 0x83, 0xaf, 0x19, 0x90, 0x83, 0xb2, 0x7d, 0x6f, 0xc5, 0xdb, 0x02, 0x66, 0xaf, 0x40, 0x91,
-	0x21, 0xbe, 0xae, 0x33, 0xc1, 0x92, 0x4c, 0x6a, 0x48, 0x84, 0xb2, 0xfd, 0x78, 0x31, 0x36, 0x7f,
-	0xaf, 0xa5, 0x86, 0x43, 0xe5, 0x7f, 0x45, 0x78, 0xa7, 0xe9, 0x88, 0x3c, 0xc0, 0xc4, 0xa8, 0x01,
-	0x67, 0x49, 0x5e, 0x89, 0xc4, 0xce, 0xa6, 0xae, 0xd9, 0x13, 0x30, 0x7b, 0xc6, 0xd9, 0xdb, 0x4a,
-	0xd8, 0xe6, 0x14, 0x39, 0xc4, 0x37, 0x6b, 0x72, 0xbd, 0x9d, 0xd5, 0xec, 0xee, 0x06, 0x6e, 0x7d,
-	0x41, 0xbd, 0xbe, 0xe0, 0xf9, 0x8a, 0x10, 0xb5, 0x8d, 0xd9, 0xcf, 0xbf, 0x06, 0x28, 0xde, 0x71,
-	0x7a, 0x35, 0xd2, 0xb4, 0xe9, 0x35, 0x6d, 0xfa, 0x4f, 0x71, 0xef, 0xc2, 0xd8, 0x88, 0x8f, 0x6f,
-	0x14, 0x55, 0x9a, 0x4c, 0xd8, 0x3c, 0xb1, 0x73, 0xed, 0xa3, 0xa1, 0xb7, 0xd7, 0x89, 0xbb, 0x45,
-	0x95, 0xbe, 0x61, 0xf3, 0xf7, 0xe6, 0x6b, 0xbf, 0xfd, 0xed, 0x74, 0x80, 0xfe, 0x9c, 0x0e, 0x50,
-	0x74, 0xf4, 0x65, 0x41, 0xd1, 0xd9, 0x82, 0xa2, 0xf3, 0x05, 0x45, 0xbf, 0x17, 0x14, 0x7d, 0x5a,
-	0xd2, 0xd6, 0xf9, 0x92, 0xb6, 0xbe, 0x2f, 0x69, 0xeb, 0xc3, 0x13, 0x9e, 0xe9, 0xe3, 0x2a, 0x0d,
-	0x46, 0x52, 0x84, 0xeb, 0x27, 0xcc, 0xe5, 0x43, 0x17, 0xba, 0x43, 0x0c, 0x2f, 0x9d, 0x77, 0xba,
-	0x6d, 0x81, 0xc7, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0xa9, 0xfa, 0xfd, 0x18, 0xfa, 0x02, 0x00,
-	0x00,
+	// 608 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0x3d, 0x6f, 0xd4, 0x30,
+	0x18, 0x3e, 0x37, 0xd7, 0xfb, 0x70, 0x3f, 0x0e, 0x4c, 0x25, 0x42, 0x91, 0x72, 0x47, 0xa6, 0x4a,
+	0x88, 0x44, 0xc0, 0x82, 0x8a, 0x90, 0xe8, 0xb5, 0x7c, 0x09, 0x8a, 0xaa, 0xa8, 0xea, 0xc0, 0x12,
+	0x39, 0x77, 0xc6, 0x8d, 0x7a, 0x8e, 0xa3, 0xd8, 0xa9, 0xee, 0xfe, 0x05, 0x23, 0x03, 0x43, 0x47,
+	0xf8, 0x07, 0xfc, 0x84, 0x8e, 0x1d, 0x99, 0x00, 0x5d, 0x17, 0x36, 0xfe, 0x02, 0x8a, 0x9d, 0xa4,
+	0x0d, 0xed, 0x70, 0xb7, 0xf9, 0xf5, 0xf3, 0x3e, 0x8f, 0x9f, 0xf7, 0xb1, 0x65, 0x68, 0x1d, 0xe1,
+	0x64, 0x18, 0xe2, 0xc1, 0x21, 0x0e, 0x23, 0x57, 0x4e, 0x62, 0x22, 0xdc, 0x18, 0x27, 0x98, 0x09,
+	0x27, 0x4e, 0xb8, 0xe4, 0xe8, 0xe6, 0x25, 0xdc, 0x51, 0xf8, 0xfa, 0x1a, 0xe5, 0x94, 0x2b, 0xd4,
+	0xcd, 0x56, 0xba, 0x71, 0xdd, 0xa2, 0x9c, 0xd3, 0x11, 0x71, 0x55, 0x15, 0xa4, 0x1f, 0xdd, 0x61,
+	0x9a, 0x60, 0x19, 0xf2, 0x48, 0xe3, 0xf6, 0x97, 0x05, 0xd8, 0xd9, 0xe6, 0x91, 0x20, 0x91, 0x48,
+	0xc5, 0x9e, 0x3a, 0x02, 0x6d, 0xc2, 0xc5, 0x60, 0xc4, 0x07, 0x47, 0x26, 0xe8, 0x81, 0x8d, 0xa5,
+	0x47, 0x96, 0x73, 0xe5, 0x30, 0xa7, 0x9f, 0xe1, 0xba, 0xbd, 0x5f, 0x3f, 0xfd, 0xd9, 0xad, 0x79,
+	0x9a, 0x82, 0xb6, 0x61, 0x8b, 0x1c, 0x87, 0x43, 0x12, 0x0d, 0x88, 0xb9, 0xa0, 0xe8, 0xf7, 0xae,
+	0xa1, 0xbf, 0xc8, 0x5b, 0x2a, 0x0a, 0x25, 0x11, 0xbd, 0x84, 0xed, 0x63, 0x3c, 0x0a, 0x87, 0x58,
+	0xf2, 0xc4, 0x34, 0x94, 0x8a, 0x7d, 0x8d, 0xca, 0x41, 0xd1, 0x53, 0x91, 0xb9, 0xa0, 0xa2, 0xe7,
+	0xb0, 0x29, 0x43, 0x46, 0x78, 0x2a, 0xcd, 0xba, 0x52, 0xe9, 0x5d, 0xa3, 0xb2, 0xaf, 0x3b, 0x2a,
+	0x1a, 0x05, 0xcd, 0x26, 0x70, 0xe9, 0xd2, 0xa8, 0xe8, 0x2e, 0x6c, 0x33, 0x3c, 0xf6, 0x83, 0x89,
+	0x24, 0x42, 0xa5, 0x63, 0x78, 0x2d, 0x86, 0xc7, 0xfd, 0xac, 0x46, 0xb7, 0x61, 0x33, 0x03, 0x29,
+	0x16, 0x6a, 0xf2, 0xba, 0xd7, 0x60, 0x78, 0xfc, 0x0a, 0x0b, 0xd4, 0x83, 0xcb, 0x99, 0x9e, 0x1f,
+	0x72, 0x89, 0x7d, 0x26, 0xd4, 0x44, 0x86, 0x07, 0xb3, 0xbd, 0x37, 0x5c, 0xe2, 0x5d, 0x61, 0x7f,
+	0x03, 0x70, 0xb5, 0x9a, 0x09, 0xba, 0x0f, 0x51, 0xa6, 0x86, 0x29, 0xf1, 0xa3, 0x94, 0xf9, 0x2a,
+	0xdd, 0xe2, 0xcc, 0x0e, 0xc3, 0xe3, 0x2d, 0x4a, 0xde, 0xa7, 0x4c, 0x99, 0x13, 0x68, 0x17, 0xde,
+	0x28, 0x9a, 0x8b, 0xfb, 0xcd, 0xd3, 0xbf, 0xe3, 0xe8, 0x07, 0xe0, 0x14, 0x0f, 0xc0, 0xd9, 0xc9,
+	0x1b, 0xfa, 0xad, 0x6c, 0xd4, 0xcf, 0xbf, 0xba, 0xc0, 0x5b, 0xd5, 0x7a, 0x05, 0x52, 0x1d, 0xd3,
+	0xa8, 0x8e, 0x69, 0x27, 0xb0, 0xf3, 0x5f, 0xf0, 0xc8, 0x86, 0x2b, 0x71, 0x1a, 0xf8, 0x47, 0x64,
+	0xe2, 0xab, 0x4c, 0x4d, 0xd0, 0x33, 0x36, 0xda, 0xde, 0x52, 0x9c, 0x06, 0x6f, 0xc9, 0x64, 0x3f,
+	0xdb, 0x42, 0x0f, 0xe1, 0x1a, 0xa6, 0x34, 0x21, 0x14, 0x4b, 0xe2, 0xc7, 0x09, 0x19, 0x70, 0xc6,
+	0x42, 0xa9, 0xa3, 0x6a, 0x79, 0xb7, 0x4a, 0x6c, 0xaf, 0x84, 0x36, 0x5b, 0xdf, 0x4f, 0xba, 0xe0,
+	0xcf, 0x49, 0x17, 0xd8, 0x7f, 0x0d, 0xb8, 0x52, 0xb9, 0x27, 0xf4, 0x0c, 0x36, 0xe3, 0x84, 0xc7,
+	0x5c, 0x10, 0x13, 0xcc, 0x3e, 0x68, 0xc1, 0x41, 0xaf, 0xe1, 0x4a, 0xbe, 0xf4, 0x87, 0x64, 0x24,
+	0xf1, 0x3c, 0x69, 0x2d, 0xe7, 0xcc, 0x9d, 0x8c, 0xa8, 0x8d, 0x90, 0x63, 0x2e, 0x89, 0x69, 0xcc,
+	0xae, 0x51, 0x70, 0xb4, 0x11, 0xb5, 0xcc, 0x8d, 0xd4, 0xe7, 0x32, 0xa2, 0x98, 0xda, 0xc8, 0x16,
+	0x6c, 0x97, 0xb1, 0x9a, 0x8b, 0xb3, 0xab, 0x5c, 0xb0, 0xd0, 0x3b, 0xd8, 0x29, 0x8b, 0xdc, 0x4e,
+	0x63, 0x8e, 0x57, 0x54, 0x72, 0xb5, 0xa1, 0xa7, 0xb0, 0x91, 0xbb, 0x69, 0xce, 0x2e, 0x92, 0x53,
+	0xfa, 0x07, 0x5f, 0xa7, 0x16, 0x38, 0x9d, 0x5a, 0xe0, 0x6c, 0x6a, 0x81, 0xdf, 0x53, 0x0b, 0x7c,
+	0x3a, 0xb7, 0x6a, 0x67, 0xe7, 0x56, 0xed, 0xc7, 0xb9, 0x55, 0xfb, 0xf0, 0x84, 0x86, 0xf2, 0x30,
+	0x0d, 0x9c, 0x01, 0x67, 0xee, 0xe5, 0x9f, 0x92, 0xf2, 0x07, 0xba, 0xd4, 0xff, 0x9d, 0x7b, 0xe5,
+	0x17, 0x0d, 0x1a, 0x0a, 0x78, 0xfc, 0x6f, 0x00, 0xd8, 0x20, 0xa3, 0x32, 0x61, 0x05, 0x00, 0x00,
 }
 
 func (this *ConsensusParams) Equal(that interface{}) bool {
@@ -348,6 +471,9 @@ func (this *ConsensusParams) Equal(that interface{}) bool {
 	if !this.Validator.Equal(&that1.Validator) {
 		return false
 	}
+	if !this.Timeout.Equal(&that1.Timeout) {
+		return false
+	}
 	return true
 }
 func (this *BlockParams) Equal(that interface{}) bool {
@@ -437,6 +563,51 @@ func (this *ValidatorParams) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if this.AggregatePrecommits != that1.AggregatePrecommits {
+		return false
+	}
+	return true
+}
+func (this *TimeoutParams) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*TimeoutParams)
+	if !ok {
+		that2, ok := that.(TimeoutParams)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Propose != that1.Propose {
+		return false
+	}
+	if this.ProposeDelta != that1.ProposeDelta {
+		return false
+	}
+	if this.Prevote != that1.Prevote {
+		return false
+	}
+	if this.PrevoteDelta != that1.PrevoteDelta {
+		return false
+	}
+	if this.Precommit != that1.Precommit {
+		return false
+	}
+	if this.PrecommitDelta != that1.PrecommitDelta {
+		return false
+	}
+	if this.Commit != that1.Commit {
+		return false
+	}
 	return true
 }
 func (m *ConsensusParams) Marshal() (dAtA []byte, err error) {
@@ -459,6 +630,16 @@ func (m *ConsensusParams) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	{
+		size, err := m.Timeout.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintParams(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x22
 	{
 		size, err := m.Validator.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -555,12 +736,12 @@ func (m *EvidenceParams) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x18
 	}
-	n4, err4 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.MaxAgeDuration, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.MaxAgeDuration):])
-	if err4 != nil {
-		return 0, err4
+	n5, err5 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.MaxAgeDuration, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.MaxAgeDuration):])
+	if err5 != nil {
+		return 0, err5
 	}
-	i -= n4
-	i = encodeVarintParams(dAtA, i, uint64(n4))
+	i -= n5
+	i = encodeVarintParams(dAtA, i, uint64(n5))
 	i--
 	dAtA[i] = 0x12
 	if m.MaxAgeNumBlocks != 0 {
@@ -591,6 +772,16 @@ func (m *ValidatorParams) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.AggregatePrecommits {
+		i--
+		if m.AggregatePrecommits {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
 	if len(m.PubKeyTypes) > 0 {
 		for iNdEx := len(m.PubKeyTypes) - 1; iNdEx >= 0; iNdEx-- {
 			i -= len(m.PubKeyTypes[iNdEx])
@@ -603,6 +794,85 @@ func (m *ValidatorParams) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *TimeoutParams) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TimeoutParams) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TimeoutParams) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	n6, err6 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Commit, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.Commit):])
+	if err6 != nil {
+		return 0, err6
+	}
+	i -= n6
+	i = encodeVarintParams(dAtA, i, uint64(n6))
+	i--
+	dAtA[i] = 0x3a
+	n7, err7 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.PrecommitDelta, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.PrecommitDelta):])
+	if err7 != nil {
+		return 0, err7
+	}
+	i -= n7
+	i = encodeVarintParams(dAtA, i, uint64(n7))
+	i--
+	dAtA[i] = 0x32
+	n8, err8 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Precommit, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.Precommit):])
+	if err8 != nil {
+		return 0, err8
+	}
+	i -= n8
+	i = encodeVarintParams(dAtA, i, uint64(n8))
+	i--
+	dAtA[i] = 0x2a
+	n9, err9 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.PrevoteDelta, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.PrevoteDelta):])
+	if err9 != nil {
+		return 0, err9
+	}
+	i -= n9
+	i = encodeVarintParams(dAtA, i, uint64(n9))
+	i--
+	dAtA[i] = 0x22
+	n10, err10 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Prevote, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.Prevote):])
+	if err10 != nil {
+		return 0, err10
+	}
+	i -= n10
+	i = encodeVarintParams(dAtA, i, uint64(n10))
+	i--
+	dAtA[i] = 0x1a
+	n11, err11 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.ProposeDelta, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.ProposeDelta):])
+	if err11 != nil {
+		return 0, err11
+	}
+	i -= n11
+	i = encodeVarintParams(dAtA, i, uint64(n11))
+	i--
+	dAtA[i] = 0x12
+	n12, err12 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.Propose, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.Propose):])
+	if err12 != nil {
+		return 0, err12
+	}
+	i -= n12
+	i = encodeVarintParams(dAtA, i, uint64(n12))
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintParams(dAtA []byte, offset int, v uint64) int {
 	offset -= sovParams(v)
 	base := offset
@@ -621,6 +891,7 @@ func NewPopulatedValidatorParams(r randyParams, easy bool) *ValidatorParams {
 	for i := 0; i < v1; i++ {
 		this.PubKeyTypes[i] = string(randStringParams(r))
 	}
+	this.AggregatePrecommits = bool(bool(r.Intn(2) == 0))
 	if !easy && r.Intn(10) != 0 {
 	}
 	return this
@@ -710,6 +981,8 @@ func (m *ConsensusParams) Size() (n int) {
 	n += 1 + l + sovParams(uint64(l))
 	l = m.Validator.Size()
 	n += 1 + l + sovParams(uint64(l))
+	l = m.Timeout.Size()
+	n += 1 + l + sovParams(uint64(l))
 	return n
 }
 
@@ -760,6 +1033,32 @@ func (m *ValidatorParams) Size() (n int) {
 			n += 1 + l + sovParams(uint64(l))
 		}
 	}
+	if m.AggregatePrecommits {
+		n += 2
+	}
+	return n
+}
+
+func (m *TimeoutParams) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.Propose)
+	n += 1 + l + sovParams(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.ProposeDelta)
+	n += 1 + l + sovParams(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.Prevote)
+	n += 1 + l + sovParams(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.PrevoteDelta)
+	n += 1 + l + sovParams(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.Precommit)
+	n += 1 + l + sovParams(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.PrecommitDelta)
+	n += 1 + l + sovParams(uint64(l))
+	l = github_com_gogo_protobuf_types.SizeOfStdDuration(m.Commit)
+	n += 1 + l + sovParams(uint64(l))
 	return n
 }
 
@@ -897,6 +1196,39 @@ func (m *ConsensusParams) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timeout", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthParams
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthParams
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Timeout.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipParams(dAtA[iNdEx:])
@@ -1207,6 +1539,307 @@ func (m *ValidatorParams) Unmarshal(dAtA []byte) error {
 			}
 			m.PubKeyTypes = append(m.PubKeyTypes, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AggregatePrecommits", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.AggregatePrecommits = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipParams(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthParams
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TimeoutParams) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowParams
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TimeoutParams: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TimeoutParams: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Propose", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthParams
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthParams
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.Propose, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProposeDelta", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthParams
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthParams
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.ProposeDelta, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Prevote", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthParams
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthParams
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.Prevote, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PrevoteDelta", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthParams
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthParams
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.PrevoteDelta, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Precommit", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthParams
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthParams
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.Precommit, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PrecommitDelta", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthParams
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthParams
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.PrecommitDelta, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Commit", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthParams
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthParams
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdDurationUnmarshal(&m.Commit, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipParams(dAtA[iNdEx:])
@@ -425,6 +425,7 @@ type Vote struct {
 	ValidatorAddress []byte        `protobuf:"bytes,6,opt,name=validator_address,json=validatorAddress,proto3" json:"validator_address,omitempty"`
 	ValidatorIndex   uint32        `protobuf:"varint,7,opt,name=validator_index,json=validatorIndex,proto3" json:"validator_index,omitempty"`
 	Signature        []byte        `protobuf:"bytes,8,opt,name=signature,proto3" json:"signature,omitempty"`
+	BlsSignature     []byte        `protobuf:"bytes,9,opt,name=bls_signature,json=blsSignature,proto3" json:"bls_signature,omitempty"`
 }
 
 func (m *Vote) Reset()         { *m = Vote{} }
@@ -516,12 +517,20 @@ func (m *Vote) GetSignature() []byte {
 	return nil
 }
 
+func (m *Vote) GetBlsSignature() []byte {
+	if m != nil {
+		return m.BlsSignature
+	}
+	return nil
+}
+
 // Commit contains the evidence that a block was committed by a set of validators.
 type Commit struct {
-	Height     uint64      `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
-	Round      uint32      `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
-	BlockID    BlockID     `protobuf:"bytes,3,opt,name=block_id,json=blockId,proto3" json:"block_id"`
-	Signatures []CommitSig `protobuf:"bytes,4,rep,name=signatures,proto3" json:"signatures"`
+	Height              uint64      `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Round               uint32      `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
+	BlockID             BlockID     `protobuf:"bytes,3,opt,name=block_id,json=blockId,proto3" json:"block_id"`
+	Signatures          []CommitSig `protobuf:"bytes,4,rep,name=signatures,proto3" json:"signatures"`
+	AggregatedSignature []byte      `protobuf:"bytes,5,opt,name=aggregated_signature,json=aggregatedSignature,proto3" json:"aggregated_signature,omitempty"`
 }
 
 func (m *Commit) Reset()         { *m = Commit{} }
@@ -585,6 +594,13 @@ func (m *Commit) GetSignatures() []CommitSig {
 	return nil
 }
 
+func (m *Commit) GetAggregatedSignature() []byte {
+	if m != nil {
+		return m.AggregatedSignature
+	}
+	return nil
+}
+
 // CommitSig is a part of the Vote included in a Commit.
 type CommitSig struct {
 	BlockIdFlag      BlockIDFlag `protobuf:"varint,1,opt,name=block_id_flag,json=blockIdFlag,proto3,enum=kardiachain.types.BlockIDFlag" json:"block_id_flag,omitempty"`
@@ -868,80 +884,81 @@ func init() {
 func init() { proto.RegisterFile("kardiachain/types/types.proto", fileDescriptor_6f03c926763cb388) }
 
 var fileDescriptor_6f03c926763cb388 = []byte{
-	// 1153 bytes of a gzipped FileDescriptorProto
+	// 1183 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0xcd, 0x6e, 0xdb, 0x46,
-	0x17, 0x15, 0x25, 0x5a, 0x3f, 0x57, 0x96, 0x25, 0x13, 0x4e, 0x22, 0x2b, 0xf9, 0x64, 0x42, 0x1f,
-	0xda, 0x3a, 0xfd, 0x91, 0x92, 0xb4, 0x45, 0xd3, 0xa5, 0x65, 0x3b, 0x89, 0x10, 0x5b, 0x12, 0x28,
-	0xc5, 0x45, 0xbb, 0x21, 0x46, 0xe2, 0x98, 0x22, 0x4c, 0x71, 0x08, 0x72, 0xe4, 0xda, 0x6f, 0x50,
-	0x08, 0x5d, 0xe4, 0x05, 0xb4, 0x6a, 0x17, 0x5d, 0x17, 0xe8, 0x0b, 0x74, 0x95, 0x65, 0x76, 0xed,
-	0xca, 0x2d, 0xec, 0x5d, 0x9f, 0xa2, 0x98, 0x19, 0x8a, 0xa2, 0x2c, 0x19, 0x29, 0x9a, 0xa0, 0x1b,
-	0x41, 0x73, 0xef, 0x39, 0xc3, 0x7b, 0xcf, 0x3d, 0x33, 0x24, 0xfc, 0xef, 0x04, 0x79, 0x86, 0x85,
-	0xfa, 0x03, 0x64, 0x39, 0x35, 0x7a, 0xee, 0x62, 0x5f, 0xfc, 0x56, 0x5d, 0x8f, 0x50, 0xa2, 0xac,
-	0x47, 0xd2, 0x55, 0x9e, 0x28, 0x6d, 0x98, 0xc4, 0x24, 0x3c, 0x5b, 0x63, 0xff, 0x04, 0xb0, 0x54,
-	0x8e, 0xee, 0xd3, 0xf7, 0xce, 0x5d, 0x4a, 0x6a, 0xae, 0x47, 0xc8, 0x71, 0x90, 0xdf, 0x32, 0x09,
-	0x31, 0x6d, 0x5c, 0xe3, 0xab, 0xde, 0xe8, 0xb8, 0x46, 0xad, 0x21, 0xf6, 0x29, 0x1a, 0xba, 0x02,
-	0x50, 0xf9, 0x12, 0x72, 0x6d, 0xe4, 0xd1, 0x0e, 0xa6, 0xcf, 0x30, 0x32, 0xb0, 0xa7, 0x6c, 0xc0,
-	0x0a, 0x25, 0x14, 0xd9, 0x45, 0x49, 0x95, 0xb6, 0x73, 0x9a, 0x58, 0x28, 0x0a, 0xc8, 0x03, 0xe4,
-	0x0f, 0x8a, 0x71, 0x55, 0xda, 0x5e, 0xd5, 0xf8, 0xff, 0x8a, 0x05, 0x32, 0xa3, 0x32, 0x86, 0xe5,
-	0x18, 0xf8, 0x6c, 0xca, 0xe0, 0x0b, 0x16, 0xed, 0x9d, 0x53, 0xec, 0x07, 0x14, 0xb1, 0x50, 0x3e,
-	0x87, 0x15, 0x5e, 0x5e, 0x31, 0xa1, 0x4a, 0xdb, 0xd9, 0x47, 0x9b, 0xd5, 0x68, 0xa3, 0xa2, 0xfe,
-	0x6a, 0x9b, 0x01, 0xea, 0xf2, 0xab, 0x8b, 0xad, 0x98, 0x26, 0xd0, 0x95, 0x21, 0xa4, 0xea, 0x36,
-	0xe9, 0x9f, 0x34, 0xf6, 0xc2, 0x4a, 0xa4, 0x59, 0x25, 0x4a, 0x13, 0xf2, 0x2e, 0xf2, 0xa8, 0xee,
-	0x63, 0xaa, 0x0f, 0x78, 0x1b, 0xfc, 0xa9, 0xd9, 0x47, 0x6a, 0x75, 0x41, 0xc8, 0xea, 0x5c, 0xbb,
-	0xc1, 0x63, 0x72, 0x6e, 0x34, 0x58, 0xf9, 0x59, 0x86, 0x64, 0x20, 0xc7, 0xfb, 0x90, 0xe6, 0x64,
-	0xdd, 0x32, 0xf8, 0x9e, 0x99, 0x7a, 0xf6, 0xf2, 0x62, 0x2b, 0xb5, 0xcb, 0x62, 0x8d, 0x3d, 0x2d,
-	0xc5, 0x93, 0x0d, 0x43, 0xb9, 0x0d, 0xc9, 0x01, 0xb6, 0xcc, 0x01, 0xe5, 0x9d, 0xc9, 0x5a, 0xb0,
-	0x52, 0xee, 0x42, 0xc6, 0x44, 0xbe, 0x6e, 0x5b, 0x43, 0x8b, 0x16, 0xf3, 0x3c, 0x95, 0x36, 0x91,
-	0x7f, 0xc0, 0xd6, 0xca, 0x63, 0x90, 0xd9, 0x3c, 0x8a, 0x32, 0x2f, 0xb6, 0x54, 0x15, 0xc3, 0xaa,
-	0x4e, 0x87, 0x55, 0xed, 0x4e, 0x87, 0x55, 0x4f, 0xb3, 0x32, 0x5f, 0xfe, 0xb1, 0x25, 0x69, 0x9c,
-	0xa1, 0xec, 0x41, 0xce, 0x46, 0x3e, 0xd5, 0x7b, 0x4c, 0x15, 0x56, 0xdb, 0x4a, 0xb0, 0xc5, 0x62,
-	0xbf, 0x81, 0x70, 0x41, 0xa7, 0x59, 0x46, 0x13, 0x21, 0x43, 0xd9, 0x86, 0x02, 0xdf, 0xa5, 0x4f,
-	0x86, 0x43, 0x8b, 0xea, 0x5c, 0xd7, 0x24, 0xd7, 0x75, 0x8d, 0xc5, 0x77, 0x79, 0xf8, 0x19, 0x53,
-	0xf8, 0x2e, 0x64, 0x0c, 0x44, 0x91, 0x80, 0xa4, 0x38, 0x24, 0xcd, 0x02, 0x3c, 0xf9, 0x01, 0xe4,
-	0x4f, 0x91, 0x6d, 0x19, 0x88, 0x12, 0xcf, 0x17, 0x90, 0xb4, 0xd8, 0x65, 0x16, 0xe6, 0xc0, 0x07,
-	0xb0, 0xe1, 0xe0, 0x33, 0xaa, 0x5f, 0x47, 0x67, 0x38, 0x5a, 0x61, 0xb9, 0xa3, 0x79, 0xc6, 0x7b,
-	0xb0, 0xd6, 0x27, 0x8e, 0x8f, 0x1d, 0x7f, 0x14, 0x60, 0x81, 0x63, 0x73, 0x61, 0x94, 0xc3, 0x36,
-	0x21, 0x8d, 0x5c, 0x57, 0x00, 0xb2, 0x1c, 0x90, 0x42, 0xae, 0xcb, 0x53, 0xff, 0x87, 0x1c, 0x3e,
-	0xb5, 0x0c, 0xec, 0xf4, 0xb1, 0xc8, 0xe7, 0x78, 0x7e, 0x75, 0x1a, 0xe4, 0xa0, 0xfb, 0x50, 0x70,
-	0x3d, 0xe2, 0x12, 0x1f, 0x7b, 0x3a, 0x32, 0x0c, 0x0f, 0xfb, 0x7e, 0x71, 0x8d, 0xe3, 0xf2, 0xd3,
-	0xf8, 0x8e, 0x08, 0x2b, 0x77, 0x20, 0xe5, 0x8c, 0x86, 0x3a, 0x3d, 0xf3, 0x8b, 0x05, 0x31, 0x69,
-	0x67, 0x34, 0xec, 0x9e, 0xf9, 0x95, 0xbf, 0xe2, 0x20, 0x1f, 0x11, 0x8a, 0x95, 0xcf, 0x40, 0x66,
-	0xca, 0x73, 0x87, 0xae, 0x2d, 0xb5, 0x60, 0xc7, 0x32, 0x1d, 0x6c, 0x1c, 0xfa, 0x66, 0xf7, 0xdc,
-	0xc5, 0x1a, 0x47, 0x47, 0x0c, 0x14, 0x9f, 0x33, 0xd0, 0x06, 0xac, 0x78, 0x64, 0xe4, 0x18, 0xdc,
-	0x57, 0x39, 0x4d, 0x2c, 0x94, 0x27, 0x90, 0x0e, 0x47, 0x2f, 0xbf, 0x71, 0xf4, 0x79, 0x36, 0x7a,
-	0x66, 0xdb, 0x20, 0xa0, 0xa5, 0x7a, 0x81, 0x03, 0xea, 0x90, 0x09, 0x6f, 0x84, 0xd0, 0x43, 0xff,
-	0xc4, 0x86, 0x33, 0x9a, 0xf2, 0x11, 0xac, 0x87, 0x03, 0x0d, 0xd5, 0x13, 0x36, 0x2a, 0x84, 0x89,
-	0xa9, 0x7c, 0x51, 0xaf, 0xe8, 0xe2, 0xda, 0x48, 0xf1, 0xc6, 0x66, 0x5e, 0x69, 0xf0, 0xfb, 0xe3,
-	0x1e, 0x64, 0x7c, 0xcb, 0x74, 0x10, 0x1d, 0x79, 0x38, 0xb0, 0xd3, 0x2c, 0x50, 0xf9, 0x55, 0x82,
-	0xa4, 0xb0, 0x67, 0x44, 0x38, 0x69, 0xb9, 0x70, 0xf1, 0x9b, 0x84, 0x4b, 0xbc, 0x95, 0x70, 0x10,
-	0x56, 0xe3, 0x17, 0x65, 0x35, 0xb1, 0x9d, 0x7d, 0x74, 0x6f, 0xc9, 0x4e, 0xa2, 0xc8, 0x8e, 0x65,
-	0x06, 0xe7, 0x2f, 0xc2, 0xaa, 0x5c, 0x48, 0x90, 0x09, 0xf3, 0x4a, 0x1d, 0x72, 0xd3, 0xca, 0xf4,
-	0x63, 0x1b, 0x99, 0x81, 0x7f, 0xca, 0x37, 0x97, 0xf7, 0xc4, 0x46, 0xa6, 0x96, 0x0d, 0x2a, 0x62,
-	0x8b, 0xe5, 0xa3, 0x88, 0xdf, 0x30, 0x8a, 0xb9, 0xd9, 0x27, 0xfe, 0xdd, 0xec, 0xe7, 0xa6, 0x24,
-	0x5f, 0x9f, 0xd2, 0x2f, 0x71, 0x48, 0xb7, 0xf9, 0xf9, 0x41, 0xf6, 0x7f, 0x72, 0x2c, 0xee, 0x42,
-	0xc6, 0x25, 0xb6, 0x2e, 0x32, 0x32, 0xcf, 0xa4, 0x5d, 0x62, 0x6b, 0x0b, 0xa3, 0x5f, 0x79, 0x57,
-	0x67, 0x26, 0xf9, 0x0e, 0x74, 0x4b, 0x5d, 0xd7, 0x8d, 0xc2, 0xaa, 0xd0, 0x22, 0x78, 0x09, 0x3d,
-	0x64, 0x22, 0xf0, 0xd7, 0x9a, 0xb4, 0xe4, 0xb5, 0x29, 0xea, 0x16, 0x50, 0x2d, 0x00, 0x32, 0x8a,
-	0xb8, 0xd5, 0x83, 0x37, 0xe1, 0xe6, 0x8d, 0xde, 0xd4, 0x02, 0x60, 0xe5, 0x7b, 0x09, 0x32, 0xbc,
-	0xd9, 0x43, 0x4c, 0xd1, 0x9c, 0x5a, 0xd2, 0x5b, 0xa8, 0xf5, 0x45, 0x58, 0x7b, 0xe2, 0x0d, 0xb5,
-	0x07, 0x27, 0x24, 0x80, 0x7f, 0xf8, 0x9b, 0x04, 0xd9, 0x88, 0xd1, 0x95, 0x87, 0x70, 0xab, 0x7e,
-	0xd0, 0xda, 0x7d, 0xae, 0x37, 0xf6, 0xf4, 0x27, 0x07, 0x3b, 0x4f, 0xf5, 0x17, 0xcd, 0xe7, 0xcd,
-	0xd6, 0x57, 0xcd, 0x42, 0xac, 0x74, 0x7b, 0x3c, 0x51, 0x95, 0x08, 0xf6, 0x85, 0x73, 0xe2, 0x90,
-	0x6f, 0x1d, 0xa5, 0x06, 0x1b, 0xf3, 0x94, 0x9d, 0x7a, 0x67, 0xbf, 0xd9, 0x2d, 0x48, 0xa5, 0x5b,
-	0xe3, 0x89, 0xba, 0x1e, 0x61, 0xec, 0xf4, 0x7c, 0xec, 0xd0, 0x45, 0xc2, 0x6e, 0xeb, 0xf0, 0xb0,
-	0xd1, 0x2d, 0xc4, 0x17, 0x08, 0xc1, 0xe5, 0x73, 0x1f, 0xd6, 0xe7, 0x09, 0xcd, 0xc6, 0x41, 0x21,
-	0x51, 0x52, 0xc6, 0x13, 0x75, 0x2d, 0x82, 0x6e, 0x5a, 0x76, 0x29, 0xfd, 0xdd, 0x0f, 0xe5, 0xd8,
-	0x4f, 0x3f, 0x96, 0x25, 0xd6, 0x59, 0x6e, 0xce, 0xeb, 0xca, 0xc7, 0x70, 0xa7, 0xd3, 0x78, 0xda,
-	0xdc, 0xdf, 0xd3, 0x0f, 0x3b, 0x4f, 0xf5, 0xee, 0xd7, 0xed, 0xfd, 0x48, 0x77, 0xf9, 0xf1, 0x44,
-	0xcd, 0x06, 0x2d, 0xdd, 0x84, 0x6e, 0x6b, 0xfb, 0x47, 0xad, 0xee, 0x7e, 0x41, 0x12, 0xe8, 0xb6,
-	0x87, 0x4f, 0x09, 0xc5, 0x1c, 0xfd, 0x00, 0x36, 0x97, 0xa0, 0xc3, 0xc6, 0xd6, 0xc7, 0x13, 0x35,
-	0xd7, 0xf6, 0xb0, 0x30, 0x01, 0x67, 0x54, 0xa1, 0xb8, 0xc8, 0x68, 0xb5, 0x5b, 0x9d, 0x9d, 0x83,
-	0x82, 0x5a, 0x2a, 0x8c, 0x27, 0xea, 0xea, 0xf4, 0x54, 0x33, 0xfc, 0xac, 0xb3, 0xba, 0xf6, 0xea,
-	0xb2, 0x2c, 0xbd, 0xbe, 0x2c, 0x4b, 0x7f, 0x5e, 0x96, 0xa5, 0x97, 0x57, 0xe5, 0xd8, 0xeb, 0xab,
-	0x72, 0xec, 0xf7, 0xab, 0x72, 0xec, 0x9b, 0xc7, 0xa6, 0x45, 0x07, 0xa3, 0x5e, 0xb5, 0x4f, 0x86,
-	0xb5, 0xe8, 0x37, 0xab, 0x49, 0x3e, 0x11, 0x4b, 0xf1, 0x89, 0x5a, 0x5b, 0xf8, 0x2e, 0xee, 0x25,
-	0x79, 0xe2, 0xd3, 0xbf, 0x03, 0x00, 0x00, 0xff, 0xff, 0xce, 0x5f, 0xb4, 0xa6, 0x33, 0x0b, 0x00,
-	0x00,
+	0x10, 0x16, 0x25, 0xea, 0x6f, 0x65, 0x59, 0xf2, 0x56, 0x49, 0x18, 0x25, 0x95, 0x09, 0x15, 0x6d,
+	0x9d, 0xfe, 0x48, 0x49, 0xda, 0xa2, 0xe9, 0xd1, 0xb2, 0x9d, 0x44, 0x88, 0x2d, 0x09, 0x94, 0xe2,
+	0xa2, 0xbd, 0x10, 0x2b, 0x71, 0x4d, 0x11, 0xa6, 0xb8, 0x04, 0xb9, 0x72, 0xed, 0x37, 0x28, 0x84,
+	0x02, 0xcd, 0x0b, 0xe8, 0xd4, 0x1e, 0x7a, 0x2e, 0xd0, 0x77, 0xc8, 0x31, 0xb7, 0xf6, 0xe4, 0x16,
+	0xf6, 0x33, 0xf4, 0x5e, 0xec, 0x2e, 0x45, 0x51, 0x96, 0x8c, 0xa0, 0x4d, 0xd0, 0x8b, 0xa1, 0x99,
+	0xf9, 0x66, 0x3d, 0xdf, 0x37, 0xdf, 0x92, 0x04, 0xef, 0x1e, 0x23, 0xcf, 0xb0, 0xd0, 0x60, 0x88,
+	0x2c, 0xa7, 0x4e, 0xcf, 0x5c, 0xec, 0x8b, 0xbf, 0x35, 0xd7, 0x23, 0x94, 0xc0, 0x8d, 0x48, 0xb9,
+	0xc6, 0x0b, 0xe5, 0x92, 0x49, 0x4c, 0xc2, 0xab, 0x75, 0xf6, 0x4b, 0x00, 0xcb, 0x95, 0xe8, 0x39,
+	0x03, 0xef, 0xcc, 0xa5, 0xa4, 0xee, 0x7a, 0x84, 0x1c, 0x05, 0xf5, 0x4d, 0x93, 0x10, 0xd3, 0xc6,
+	0x75, 0x1e, 0xf5, 0xc7, 0x47, 0x75, 0x6a, 0x8d, 0xb0, 0x4f, 0xd1, 0xc8, 0x15, 0x80, 0xea, 0x57,
+	0x20, 0xdf, 0x41, 0x1e, 0xed, 0x62, 0xfa, 0x14, 0x23, 0x03, 0x7b, 0xb0, 0x04, 0x92, 0x94, 0x50,
+	0x64, 0x2b, 0x92, 0x2a, 0x6d, 0xe5, 0x35, 0x11, 0x40, 0x08, 0xe4, 0x21, 0xf2, 0x87, 0x4a, 0x5c,
+	0x95, 0xb6, 0xd6, 0x34, 0xfe, 0xbb, 0x6a, 0x01, 0x99, 0xb5, 0xb2, 0x0e, 0xcb, 0x31, 0xf0, 0xe9,
+	0xac, 0x83, 0x07, 0x2c, 0xdb, 0x3f, 0xa3, 0xd8, 0x0f, 0x5a, 0x44, 0x00, 0xbf, 0x00, 0x49, 0x3e,
+	0x9e, 0x92, 0x50, 0xa5, 0xad, 0xdc, 0xc3, 0xdb, 0xb5, 0x28, 0x51, 0x31, 0x7f, 0xad, 0xc3, 0x00,
+	0x0d, 0xf9, 0xe5, 0xf9, 0x66, 0x4c, 0x13, 0xe8, 0xea, 0x08, 0xa4, 0x1b, 0x36, 0x19, 0x1c, 0x37,
+	0x77, 0xc3, 0x49, 0xa4, 0xf9, 0x24, 0xb0, 0x05, 0x0a, 0x2e, 0xf2, 0xa8, 0xee, 0x63, 0xaa, 0x0f,
+	0x39, 0x0d, 0xfe, 0x5f, 0x73, 0x0f, 0xd5, 0xda, 0x92, 0x90, 0xb5, 0x05, 0xba, 0xc1, 0xbf, 0xc9,
+	0xbb, 0xd1, 0x64, 0xf5, 0x57, 0x19, 0xa4, 0x02, 0x39, 0x3e, 0x00, 0x19, 0xde, 0xac, 0x5b, 0x06,
+	0x3f, 0x33, 0xdb, 0xc8, 0x5d, 0x9c, 0x6f, 0xa6, 0x77, 0x58, 0xae, 0xb9, 0xab, 0xa5, 0x79, 0xb1,
+	0x69, 0xc0, 0x9b, 0x20, 0x35, 0xc4, 0x96, 0x39, 0xa4, 0x9c, 0x99, 0xac, 0x05, 0x11, 0xbc, 0x03,
+	0xb2, 0x26, 0xf2, 0x75, 0xdb, 0x1a, 0x59, 0x54, 0x29, 0xf0, 0x52, 0xc6, 0x44, 0xfe, 0x3e, 0x8b,
+	0xe1, 0x23, 0x20, 0xb3, 0x7d, 0x28, 0x32, 0x1f, 0xb6, 0x5c, 0x13, 0xcb, 0xaa, 0xcd, 0x96, 0x55,
+	0xeb, 0xcd, 0x96, 0xd5, 0xc8, 0xb0, 0x31, 0x5f, 0xfc, 0xb9, 0x29, 0x69, 0xbc, 0x03, 0xee, 0x82,
+	0xbc, 0x8d, 0x7c, 0xaa, 0xf7, 0x99, 0x2a, 0x6c, 0xb6, 0x64, 0x70, 0xc4, 0x32, 0xdf, 0x40, 0xb8,
+	0x80, 0x69, 0x8e, 0xb5, 0x89, 0x94, 0x01, 0xb7, 0x40, 0x91, 0x9f, 0x32, 0x20, 0xa3, 0x91, 0x45,
+	0x75, 0xae, 0x6b, 0x8a, 0xeb, 0xba, 0xce, 0xf2, 0x3b, 0x3c, 0xfd, 0x94, 0x29, 0x7c, 0x07, 0x64,
+	0x0d, 0x44, 0x91, 0x80, 0xa4, 0x39, 0x24, 0xc3, 0x12, 0xbc, 0xf8, 0x21, 0x28, 0x9c, 0x20, 0xdb,
+	0x32, 0x10, 0x25, 0x9e, 0x2f, 0x20, 0x19, 0x71, 0xca, 0x3c, 0xcd, 0x81, 0xf7, 0x41, 0xc9, 0xc1,
+	0xa7, 0x54, 0xbf, 0x8a, 0xce, 0x72, 0x34, 0x64, 0xb5, 0xc3, 0xc5, 0x8e, 0xf7, 0xc1, 0xfa, 0x80,
+	0x38, 0x3e, 0x76, 0xfc, 0x71, 0x80, 0x05, 0x1c, 0x9b, 0x0f, 0xb3, 0x1c, 0x76, 0x1b, 0x64, 0x90,
+	0xeb, 0x0a, 0x40, 0x8e, 0x03, 0xd2, 0xc8, 0x75, 0x79, 0xe9, 0x3d, 0x90, 0xc7, 0x27, 0x96, 0x81,
+	0x9d, 0x01, 0x16, 0xf5, 0x3c, 0xaf, 0xaf, 0xcd, 0x92, 0x1c, 0x74, 0x0f, 0x14, 0x5d, 0x8f, 0xb8,
+	0xc4, 0xc7, 0x9e, 0x8e, 0x0c, 0xc3, 0xc3, 0xbe, 0xaf, 0xac, 0x73, 0x5c, 0x61, 0x96, 0xdf, 0x16,
+	0x69, 0x78, 0x0b, 0xa4, 0x9d, 0xf1, 0x48, 0xa7, 0xa7, 0xbe, 0x52, 0x14, 0x9b, 0x76, 0xc6, 0xa3,
+	0xde, 0xa9, 0x5f, 0xfd, 0x31, 0x01, 0xe4, 0x43, 0x42, 0x31, 0xfc, 0x1c, 0xc8, 0x4c, 0x79, 0xee,
+	0xd0, 0xf5, 0x95, 0x16, 0xec, 0x5a, 0xa6, 0x83, 0x8d, 0x03, 0xdf, 0xec, 0x9d, 0xb9, 0x58, 0xe3,
+	0xe8, 0x88, 0x81, 0xe2, 0x0b, 0x06, 0x2a, 0x81, 0xa4, 0x47, 0xc6, 0x8e, 0xc1, 0x7d, 0x95, 0xd7,
+	0x44, 0x00, 0x1f, 0x83, 0x4c, 0xb8, 0x7a, 0xf9, 0xb5, 0xab, 0x2f, 0xb0, 0xd5, 0x33, 0xdb, 0x06,
+	0x09, 0x2d, 0xdd, 0x0f, 0x1c, 0xd0, 0x00, 0xd9, 0xf0, 0x89, 0xa0, 0x24, 0xff, 0x85, 0x0d, 0xe7,
+	0x6d, 0xf0, 0x63, 0xb0, 0x11, 0x2e, 0x34, 0x54, 0x4f, 0xd8, 0xa8, 0x18, 0x16, 0x66, 0xf2, 0x45,
+	0xbd, 0xa2, 0x8b, 0xc7, 0x46, 0x9a, 0x13, 0x9b, 0x7b, 0xa5, 0xc9, 0xb2, 0xf0, 0x2e, 0xc8, 0xfa,
+	0x96, 0xe9, 0x20, 0x3a, 0xf6, 0x70, 0x60, 0xa7, 0x79, 0x82, 0x6d, 0xb5, 0x6f, 0xfb, 0xfa, 0x1c,
+	0x21, 0x2c, 0xb4, 0xd6, 0xb7, 0xfd, 0xee, 0x2c, 0x57, 0xfd, 0x5b, 0x02, 0x29, 0xe1, 0xe1, 0x88,
+	0xba, 0xd2, 0x6a, 0x75, 0xe3, 0xd7, 0xa9, 0x9b, 0x78, 0x23, 0x75, 0x41, 0x38, 0xa1, 0xaf, 0xc8,
+	0x6a, 0x62, 0x2b, 0xf7, 0xf0, 0xee, 0x8a, 0x93, 0xc4, 0x90, 0x5d, 0xcb, 0x0c, 0x2e, 0x69, 0xa4,
+	0x0b, 0x3e, 0x00, 0x25, 0x64, 0x9a, 0x1e, 0x36, 0x11, 0xc5, 0x46, 0x84, 0x70, 0x92, 0x13, 0x7e,
+	0x67, 0x5e, 0x9b, 0xf3, 0x3e, 0x97, 0x40, 0x36, 0x3c, 0x12, 0x36, 0x40, 0x7e, 0x46, 0x46, 0x3f,
+	0xb2, 0x91, 0x19, 0xf8, 0xb2, 0x72, 0x3d, 0xa3, 0xc7, 0x36, 0x32, 0xb5, 0x5c, 0x40, 0x82, 0x05,
+	0xab, 0x57, 0x1c, 0xbf, 0x66, 0xc5, 0x0b, 0x9e, 0x4a, 0xfc, 0x37, 0x4f, 0x2d, 0x6c, 0x5f, 0xbe,
+	0xb2, 0xfd, 0xea, 0x6f, 0x71, 0x90, 0xe9, 0xf0, 0x7b, 0x89, 0xec, 0xff, 0xe5, 0xba, 0xdd, 0x01,
+	0x59, 0x97, 0xd8, 0xba, 0xa8, 0xc8, 0xbc, 0x92, 0x71, 0x89, 0xad, 0x2d, 0xb9, 0x25, 0xf9, 0xb6,
+	0xee, 0x62, 0xea, 0x2d, 0xe8, 0x96, 0xbe, 0xaa, 0x1b, 0x05, 0x6b, 0x42, 0x8b, 0xe0, 0xe5, 0xf6,
+	0x80, 0x89, 0xc0, 0x7e, 0x29, 0xd2, 0x8a, 0xd7, 0xb1, 0x98, 0x5b, 0x40, 0xb5, 0xd4, 0x30, 0x6c,
+	0x11, 0x6f, 0x0b, 0x25, 0x7e, 0x6d, 0x8b, 0xf0, 0x9e, 0x16, 0x00, 0xab, 0x3f, 0x48, 0x20, 0xcb,
+	0xc9, 0x1e, 0x60, 0x8a, 0x16, 0xd4, 0x92, 0xde, 0x40, 0xad, 0x2f, 0xc3, 0xd9, 0x13, 0xaf, 0x99,
+	0x3d, 0xb8, 0x54, 0x01, 0xfc, 0xa3, 0xdf, 0x25, 0x90, 0x8b, 0x18, 0x1d, 0x3e, 0x00, 0x37, 0x1a,
+	0xfb, 0xed, 0x9d, 0x67, 0x7a, 0x73, 0x57, 0x7f, 0xbc, 0xbf, 0xfd, 0x44, 0x7f, 0xde, 0x7a, 0xd6,
+	0x6a, 0x7f, 0xdd, 0x2a, 0xc6, 0xca, 0x37, 0x27, 0x53, 0x15, 0x46, 0xb0, 0xcf, 0x9d, 0x63, 0x87,
+	0x7c, 0xe7, 0xc0, 0x3a, 0x28, 0x2d, 0xb6, 0x6c, 0x37, 0xba, 0x7b, 0xad, 0x5e, 0x51, 0x2a, 0xdf,
+	0x98, 0x4c, 0xd5, 0x8d, 0x48, 0xc7, 0x76, 0xdf, 0xc7, 0x0e, 0x5d, 0x6e, 0xd8, 0x69, 0x1f, 0x1c,
+	0x34, 0x7b, 0xc5, 0xf8, 0x52, 0x43, 0xf0, 0xbc, 0xba, 0x07, 0x36, 0x16, 0x1b, 0x5a, 0xcd, 0xfd,
+	0x62, 0xa2, 0x0c, 0x27, 0x53, 0x75, 0x3d, 0x82, 0x6e, 0x59, 0x76, 0x39, 0xf3, 0xfd, 0x4f, 0x95,
+	0xd8, 0x2f, 0x3f, 0x57, 0x24, 0xc6, 0x2c, 0xbf, 0xe0, 0x75, 0xf8, 0x09, 0xb8, 0xd5, 0x6d, 0x3e,
+	0x69, 0xed, 0xed, 0xea, 0x07, 0xdd, 0x27, 0x7a, 0xef, 0x9b, 0xce, 0x5e, 0x84, 0x5d, 0x61, 0x32,
+	0x55, 0x73, 0x01, 0xa5, 0xeb, 0xd0, 0x1d, 0x6d, 0xef, 0xb0, 0xdd, 0xdb, 0x2b, 0x4a, 0x02, 0xdd,
+	0xf1, 0xf0, 0x09, 0xa1, 0x98, 0xa3, 0xef, 0x83, 0xdb, 0x2b, 0xd0, 0x21, 0xb1, 0x8d, 0xc9, 0x54,
+	0xcd, 0x77, 0x3c, 0x2c, 0x4c, 0xc0, 0x3b, 0x6a, 0x40, 0x59, 0xee, 0x68, 0x77, 0xda, 0xdd, 0xed,
+	0xfd, 0xa2, 0x5a, 0x2e, 0x4e, 0xa6, 0xea, 0xda, 0xec, 0x56, 0x33, 0xfc, 0x9c, 0x59, 0x43, 0x7b,
+	0x79, 0x51, 0x91, 0x5e, 0x5d, 0x54, 0xa4, 0xbf, 0x2e, 0x2a, 0xd2, 0x8b, 0xcb, 0x4a, 0xec, 0xd5,
+	0x65, 0x25, 0xf6, 0xc7, 0x65, 0x25, 0xf6, 0xed, 0x23, 0xd3, 0xa2, 0xc3, 0x71, 0xbf, 0x36, 0x20,
+	0xa3, 0x7a, 0xf4, 0x5b, 0xd8, 0x24, 0x9f, 0x8a, 0x50, 0x7c, 0xfa, 0xd6, 0x97, 0xbe, 0xb7, 0xfb,
+	0x29, 0x5e, 0xf8, 0xec, 0x9f, 0x01, 0x00, 0xbe, 0x4c, 0x8f, 0xfa, 0x8b, 0x0b, 0x00, 0x00,
 }
 
 func (m *PartSetHeader) Marshal() (dAtA []byte, err error) {
@@ -1205,6 +1222,13 @@ func (m *Vote) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.BlsSignature) > 0 {
+		i -= len(m.BlsSignature)
+		copy(dAtA[i:], m.BlsSignature)
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.BlsSignature)))
+		i--
+		dAtA[i] = 0x4a
+	}
 	if len(m.Signature) > 0 {
 		i -= len(m.Signature)
 		copy(dAtA[i:], m.Signature)
@@ -1280,6 +1304,13 @@ func (m *Commit) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.AggregatedSignature) > 0 {
+		i -= len(m.AggregatedSignature)
+		copy(dAtA[i:], m.AggregatedSignature)
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.AggregatedSignature)))
+		i--
+		dAtA[i] = 0x2a
+	}
 	if len(m.Signatures) > 0 {
 		for iNdEx := len(m.Signatures) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -1673,6 +1704,10 @@ func (m *Vote) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovTypes(uint64(l))
 	}
+	l = len(m.BlsSignature)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
 	return n
 }
 
@@ -1696,6 +1731,10 @@ func (m *Commit) Size() (n int) {
 			n += 1 + l + sovTypes(uint64(l))
 		}
 	}
+	l = len(m.AggregatedSignature)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
 	return n
 }
 
@@ -2858,6 +2897,40 @@ func (m *Vote) Unmarshal(dAtA []byte) error {
 				m.Signature = []byte{}
 			}
 			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlsSignature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BlsSignature = append(m.BlsSignature[:0], dAtA[iNdEx:postIndex]...)
+			if m.BlsSignature == nil {
+				m.BlsSignature = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -3013,6 +3086,40 @@ func (m *Commit) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AggregatedSignature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AggregatedSignature = append(m.AggregatedSignature[:0], dAtA[iNdEx:postIndex]...)
+			if m.AggregatedSignature == nil {
+				m.AggregatedSignature = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -86,6 +86,7 @@ type Validator struct {
 	Address          []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
 	VotingPower      int64  `protobuf:"varint,3,opt,name=voting_power,json=votingPower,proto3" json:"voting_power,omitempty"`
 	ProposerPriority int64  `protobuf:"varint,4,opt,name=proposer_priority,json=proposerPriority,proto3" json:"proposer_priority,omitempty"`
+	BlsPubKey        []byte `protobuf:"bytes,5,opt,name=bls_pub_key,json=blsPubKey,proto3" json:"bls_pub_key,omitempty"`
 }
 
 func (m *Validator) Reset()         { *m = Validator{} }
@@ -142,9 +143,17 @@ func (m *Validator) GetProposerPriority() int64 {
 	return 0
 }
 
+func (m *Validator) GetBlsPubKey() []byte {
+	if m != nil {
+		return m.BlsPubKey
+	}
+	return nil
+}
+
 type SimpleValidator struct {
 	Address     []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
 	VotingPower int64  `protobuf:"varint,3,opt,name=voting_power,json=votingPower,proto3" json:"voting_power,omitempty"`
+	BlsPubKey   []byte `protobuf:"bytes,4,opt,name=bls_pub_key,json=blsPubKey,proto3" json:"bls_pub_key,omitempty"`
 }
 
 func (m *SimpleValidator) Reset()         { *m = SimpleValidator{} }
@@ -194,6 +203,13 @@ func (m *SimpleValidator) GetVotingPower() int64 {
 	return 0
 }
 
+func (m *SimpleValidator) GetBlsPubKey() []byte {
+	if m != nil {
+		return m.BlsPubKey
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*ValidatorSet)(nil), "kardiachain.types.ValidatorSet")
 	proto.RegisterType((*Validator)(nil), "kardiachain.types.Validator")
@@ -203,26 +219,27 @@ func init() {
 func init() { proto.RegisterFile("kardiachain/types/validator.proto", fileDescriptor_950167e526683cc0) }
 
 var fileDescriptor_950167e526683cc0 = []byte{
-	// 290 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x52, 0xcc, 0x4e, 0x2c, 0x4a,
-	0xc9, 0x4c, 0x4c, 0xce, 0x48, 0xcc, 0xcc, 0xd3, 0x2f, 0xa9, 0x2c, 0x48, 0x2d, 0xd6, 0x2f, 0x4b,
-	0xcc, 0xc9, 0x4c, 0x49, 0x2c, 0xc9, 0x2f, 0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x12, 0x44,
-	0x52, 0xa2, 0x07, 0x56, 0xa2, 0xb4, 0x85, 0x91, 0x8b, 0x27, 0x0c, 0xa6, 0x2c, 0x38, 0xb5, 0x44,
-	0xc8, 0x86, 0x8b, 0x0b, 0xae, 0xad, 0x58, 0x82, 0x51, 0x81, 0x59, 0x83, 0xdb, 0x48, 0x46, 0x0f,
-	0x43, 0xa3, 0x1e, 0x5c, 0x53, 0x10, 0x92, 0x7a, 0x21, 0x0b, 0x2e, 0x8e, 0x82, 0xa2, 0xfc, 0x82,
-	0xfc, 0xe2, 0xd4, 0x22, 0x09, 0x26, 0x05, 0x46, 0x82, 0x7a, 0xe1, 0xaa, 0x85, 0x74, 0xb8, 0x84,
-	0x4a, 0xf2, 0x4b, 0x12, 0x73, 0xe2, 0xcb, 0xf2, 0x4b, 0x32, 0xf3, 0xd2, 0xe3, 0x0b, 0xf2, 0xcb,
-	0x53, 0x8b, 0x24, 0x98, 0x15, 0x18, 0x35, 0x98, 0x83, 0x04, 0xc0, 0x32, 0x61, 0x60, 0x89, 0x00,
-	0x90, 0xb8, 0x52, 0x29, 0x17, 0x27, 0xdc, 0x10, 0x21, 0x09, 0x2e, 0xf6, 0xc4, 0x94, 0x94, 0xa2,
-	0xd4, 0x62, 0x90, 0x7b, 0x19, 0x35, 0x78, 0x82, 0x60, 0x5c, 0x21, 0x45, 0x2e, 0x1e, 0x2c, 0xc6,
-	0x71, 0x97, 0x21, 0x4c, 0x12, 0xd2, 0xe6, 0x12, 0x84, 0xb9, 0x21, 0xbe, 0xa0, 0x28, 0x33, 0xbf,
-	0x28, 0xb3, 0xa4, 0x52, 0x82, 0x05, 0x62, 0x2d, 0x4c, 0x22, 0x00, 0x2a, 0xae, 0xe4, 0xc7, 0xc5,
-	0x1f, 0x9c, 0x99, 0x5b, 0x90, 0x93, 0x4a, 0x1d, 0xcb, 0x9d, 0x82, 0x4e, 0x3c, 0x92, 0x63, 0xbc,
-	0xf0, 0x48, 0x8e, 0xf1, 0xc1, 0x23, 0x39, 0xc6, 0x09, 0x8f, 0xe5, 0x18, 0x2e, 0x3c, 0x96, 0x63,
-	0xb8, 0xf1, 0x58, 0x8e, 0x21, 0xca, 0x22, 0x3d, 0xb3, 0x24, 0xa3, 0x34, 0x49, 0x2f, 0x39, 0x3f,
-	0x57, 0x1f, 0x39, 0x62, 0xd3, 0xf3, 0x75, 0x21, 0x5c, 0x7d, 0x70, 0x94, 0xea, 0x63, 0x44, 0x7a,
-	0x12, 0x1b, 0x58, 0xc2, 0x18, 0x10, 0x00, 0x00, 0xff, 0xff, 0x9a, 0x40, 0xab, 0x70, 0x10, 0x02,
-	0x00, 0x00,
+	// 316 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x52, 0xbf, 0x4e, 0x02, 0x31,
+	0x18, 0xa7, 0x82, 0x7f, 0xf8, 0x20, 0x51, 0x3a, 0x75, 0x30, 0x0d, 0x30, 0x91, 0xa8, 0x77, 0x89,
+	0x2e, 0x0c, 0x4e, 0xae, 0x2e, 0xe4, 0x48, 0x18, 0x5c, 0x2e, 0x2d, 0xd7, 0x40, 0xc3, 0x41, 0x9b,
+	0xb6, 0x60, 0xee, 0x2d, 0x5c, 0x7c, 0x13, 0x1f, 0xc2, 0x91, 0xd1, 0xd1, 0xc0, 0x8b, 0x18, 0x8a,
+	0x77, 0x12, 0x30, 0x71, 0x71, 0xfc, 0xbe, 0xdf, 0xdf, 0xe1, 0x07, 0xad, 0x09, 0x33, 0x89, 0x64,
+	0xc3, 0x31, 0x93, 0xb3, 0xd0, 0x65, 0x5a, 0xd8, 0x70, 0xc1, 0x52, 0x99, 0x30, 0xa7, 0x4c, 0xa0,
+	0x8d, 0x72, 0x0a, 0x37, 0x76, 0x28, 0x81, 0xa7, 0xb4, 0xdf, 0x10, 0xd4, 0x07, 0x39, 0xad, 0x2f,
+	0x1c, 0xbe, 0x07, 0x28, 0x64, 0x96, 0xa0, 0x66, 0xb9, 0x53, 0xbb, 0xbd, 0x0c, 0x0e, 0x84, 0x41,
+	0x21, 0x8a, 0x76, 0xf8, 0xb8, 0x0b, 0x67, 0xda, 0x28, 0xad, 0xac, 0x30, 0xe4, 0xa8, 0x89, 0xfe,
+	0xd4, 0x16, 0x6c, 0x7c, 0x0d, 0xd8, 0x29, 0xc7, 0xd2, 0x78, 0xa1, 0x9c, 0x9c, 0x8d, 0x62, 0xad,
+	0x9e, 0x85, 0x21, 0xe5, 0x26, 0xea, 0x94, 0xa3, 0x0b, 0x8f, 0x0c, 0x3c, 0xd0, 0xdb, 0xfc, 0xdb,
+	0xaf, 0x08, 0xaa, 0x85, 0x0b, 0x26, 0x70, 0xca, 0x92, 0xc4, 0x08, 0xbb, 0x29, 0x8c, 0x3a, 0xf5,
+	0x28, 0x3f, 0x71, 0x0b, 0xea, 0xbf, 0xf8, 0xd5, 0x16, 0x3f, 0x56, 0xf8, 0x0a, 0x1a, 0x79, 0x89,
+	0x58, 0x1b, 0xa9, 0x8c, 0x74, 0x19, 0xa9, 0x6c, 0x73, 0x73, 0xa0, 0xf7, 0xfd, 0xc7, 0x14, 0x6a,
+	0x3c, 0xb5, 0xb1, 0x9e, 0xf3, 0x78, 0x22, 0x32, 0x72, 0xec, 0xd3, 0xaa, 0x3c, 0xb5, 0xbd, 0x39,
+	0x7f, 0x14, 0x59, 0x7b, 0x06, 0xe7, 0x7d, 0x39, 0xd5, 0xa9, 0xf8, 0xa7, 0x72, 0x7b, 0x79, 0x95,
+	0xbd, 0xbc, 0x87, 0xe8, 0x7d, 0x45, 0xd1, 0x72, 0x45, 0xd1, 0xe7, 0x8a, 0xa2, 0x97, 0x35, 0x2d,
+	0x2d, 0xd7, 0xb4, 0xf4, 0xb1, 0xa6, 0xa5, 0xa7, 0xee, 0x48, 0xba, 0xf1, 0x9c, 0x07, 0x43, 0x35,
+	0x0d, 0x77, 0x97, 0x31, 0x52, 0x37, 0xdb, 0x33, 0xf4, 0x9b, 0x08, 0x0f, 0x56, 0xc3, 0x4f, 0x3c,
+	0x70, 0xf7, 0x35, 0x00, 0x2e, 0xad, 0x16, 0x57, 0x51, 0x02, 0x00, 0x00,
 }
 
 func (m *ValidatorSet) Marshal() (dAtA []byte, err error) {
@@ -299,6 +316,13 @@ func (m *Validator) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.BlsPubKey) > 0 {
+		i -= len(m.BlsPubKey)
+		copy(dAtA[i:], m.BlsPubKey)
+		i = encodeVarintValidator(dAtA, i, uint64(len(m.BlsPubKey)))
+		i--
+		dAtA[i] = 0x2a
+	}
 	if m.ProposerPriority != 0 {
 		i = encodeVarintValidator(dAtA, i, uint64(m.ProposerPriority))
 		i--
@@ -339,6 +363,13 @@ func (m *SimpleValidator) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.BlsPubKey) > 0 {
+		i -= len(m.BlsPubKey)
+		copy(dAtA[i:], m.BlsPubKey)
+		i = encodeVarintValidator(dAtA, i, uint64(len(m.BlsPubKey)))
+		i--
+		dAtA[i] = 0x22
+	}
 	if m.VotingPower != 0 {
 		i = encodeVarintValidator(dAtA, i, uint64(m.VotingPower))
 		i--
@@ -403,6 +434,10 @@ func (m *Validator) Size() (n int) {
 	if m.ProposerPriority != 0 {
 		n += 1 + sovValidator(uint64(m.ProposerPriority))
 	}
+	l = len(m.BlsPubKey)
+	if l > 0 {
+		n += 1 + l + sovValidator(uint64(l))
+	}
 	return n
 }
 
@@ -419,6 +454,10 @@ func (m *SimpleValidator) Size() (n int) {
 	if m.VotingPower != 0 {
 		n += 1 + sovValidator(uint64(m.VotingPower))
 	}
+	l = len(m.BlsPubKey)
+	if l > 0 {
+		n += 1 + l + sovValidator(uint64(l))
+	}
 	return n
 }
 
@@ -668,6 +707,40 @@ func (m *Validator) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlsPubKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowValidator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthValidator
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthValidator
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BlsPubKey = append(m.BlsPubKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.BlsPubKey == nil {
+				m.BlsPubKey = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipValidator(dAtA[iNdEx:])
@@ -771,6 +844,40 @@ func (m *SimpleValidator) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlsPubKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowValidator
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthValidator
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthValidator
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BlsPubKey = append(m.BlsPubKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.BlsPubKey == nil {
+				m.BlsPubKey = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipValidator(dAtA[iNdEx:])
@@ -0,0 +1,267 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package statesync lets a new node restore the state of the chain from a
+// snapshot served by its peers, instead of replaying every block.
+//
+// Full nodes create snapshots at checkpoint heights and offer them on the
+// snapshot channel. A syncing node collects the offers, verifies the block and
+// app hash of the best snapshot with a light client, fetches its chunks on the
+// chunk channel and imports the archive they make up. The node then fast
+// syncs the blocks after the snapshot and switches to consensus.
+package statesync
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/snapshot"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/lib/p2p"
+	ssproto "github.com/kardiachain/go-kardia/proto/kardiachain/statesync"
+)
+
+const (
+	// SnapshotChannel exchanges snapshot offers.
+	SnapshotChannel = byte(0x60)
+	// ChunkChannel exchanges snapshot chunks.
+	ChunkChannel = byte(0x61)
+
+	// recentSnapshots is the number of snapshots offered to a peer.
+	recentSnapshots = 10
+)
+
+// Reactor serves the snapshots of the node to its peers, and restores the
+// state from a snapshot of the peers when syncing.
+type Reactor struct {
+	p2p.BaseReactor
+
+	config *configs.StateSyncConfig
+	db     kaidb.Database
+	store  *Store // snapshots served to peers, nil if none
+
+	mtx    sync.RWMutex
+	syncer *syncer // non-nil during a state sync
+}
+
+// NewReactor returns a reactor serving the snapshots of store, which may be
+// nil, and restoring snapshots into db.
+func NewReactor(config *configs.StateSyncConfig, db kaidb.Database, store *Store) *Reactor {
+	r := &Reactor{
+		config: config,
+		db:     db,
+		store:  store,
+	}
+	r.BaseReactor = *p2p.NewBaseReactor("StateSync", r)
+	return r
+}
+
+// GetChannels implements Reactor.
+func (r *Reactor) GetChannels() []*p2p.ChannelDescriptor {
+	return []*p2p.ChannelDescriptor{
+		{
+			ID:                  SnapshotChannel,
+			Priority:            5,
+			SendQueueCapacity:   10,
+			RecvMessageCapacity: snapshotMsgSize,
+		},
+		{
+			ID:                  ChunkChannel,
+			Priority:            3,
+			SendQueueCapacity:   4,
+			RecvMessageCapacity: chunkMsgSize,
+		},
+	}
+}
+
+// AddPeer implements Reactor, asking the peer for its snapshots during a
+// sync.
+func (r *Reactor) AddPeer(peer p2p.Peer) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if r.syncer != nil {
+		r.send(peer, SnapshotChannel, &ssproto.SnapshotsRequest{})
+	}
+}
+
+// RemovePeer implements Reactor.
+func (r *Reactor) RemovePeer(peer p2p.Peer, reason interface{}) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if r.syncer != nil {
+		r.syncer.removePeer(peer.ID())
+	}
+}
+
+// Receive implements Reactor.
+func (r *Reactor) Receive(chID byte, src p2p.Peer, msgBytes []byte) {
+	msg, err := decodeMsg(msgBytes)
+	if err != nil {
+		r.Logger.Error("Error decoding message", "src", src, "chId", chID, "err", err)
+		r.Switch.StopPeerForError(src, err)
+		return
+	}
+	if err := validateMsg(msg); err != nil {
+		r.Logger.Error("Invalid message", "peer", src, "msg", msg, "err", err)
+		r.Switch.StopPeerForError(src, err)
+		return
+	}
+
+	switch msg := msg.(type) {
+	case *ssproto.SnapshotsRequest:
+		r.sendSnapshots(src)
+
+	case *ssproto.SnapshotsResponse:
+		r.mtx.RLock()
+		defer r.mtx.RUnlock()
+		if r.syncer == nil {
+			return
+		}
+		sn, _ := SnapshotFromProto(msg)
+		r.syncer.addSnapshot(src, sn)
+
+	case *ssproto.ChunkRequest:
+		r.sendChunk(src, msg)
+
+	case *ssproto.ChunkResponse:
+		r.mtx.RLock()
+		defer r.mtx.RUnlock()
+		if r.syncer == nil {
+			return
+		}
+		r.syncer.addChunk(chunk{
+			peer:    src.ID(),
+			height:  msg.Height,
+			format:  msg.Format,
+			index:   msg.Index,
+			data:    msg.Chunk,
+			missing: msg.Missing,
+		})
+
+	default:
+		r.Logger.Error("Received unknown message", "msg", fmt.Sprintf("%T", msg))
+	}
+}
+
+// sendSnapshots offers the recent snapshots of the store to a peer.
+func (r *Reactor) sendSnapshots(peer p2p.Peer) {
+	if r.store == nil {
+		return
+	}
+	snapshots, err := r.store.List()
+	if err != nil {
+		r.Logger.Error("Failed to list snapshots", "err", err)
+		return
+	}
+	if len(snapshots) > recentSnapshots {
+		snapshots = snapshots[:recentSnapshots]
+	}
+	for _, sn := range snapshots {
+		r.send(peer, SnapshotChannel, sn.ToProto())
+	}
+}
+
+// sendChunk sends a chunk of the store to a peer.
+func (r *Reactor) sendChunk(peer p2p.Peer, req *ssproto.ChunkRequest) {
+	resp := &ssproto.ChunkResponse{Height: req.Height, Format: req.Format, Index: req.Index}
+	if r.store != nil {
+		data, err := r.store.LoadChunk(req.Height, req.Format, req.Index)
+		if err != nil {
+			r.Logger.Error("Failed to load chunk", "height", req.Height, "index", req.Index, "err", err)
+		}
+		resp.Chunk = data
+	}
+	resp.Missing = len(resp.Chunk) == 0
+	r.send(peer, ChunkChannel, resp)
+}
+
+func (r *Reactor) send(peer p2p.Peer, chID byte, pb proto.Message) {
+	msgBytes, err := encodeMsg(pb)
+	if err != nil {
+		r.Logger.Error("Failed to encode message", "msg", pb, "err", err)
+		return
+	}
+	if !peer.Send(chID, msgBytes) {
+		r.Logger.Debug("Failed to send message", "peer", peer.ID(), "chId", chID)
+	}
+}
+
+// requestChunk implements requestFunc.
+func (r *Reactor) requestChunk(peer p2p.Peer, sn *Snapshot, index uint32) {
+	r.send(peer, ChunkChannel, &ssproto.ChunkRequest{Height: sn.Height, Format: sn.Format, Index: index})
+}
+
+// restore implements restoreFunc, importing the archive of a snapshot into
+// the database of the reactor.
+func (r *Reactor) restore(rd io.ReadSeeker, sn *Snapshot) (cstate.LatestBlockState, error) {
+	// The archive matches its checksum once imported, make sure beforehand
+	// that it holds the verified snapshot.
+	manifest, err := snapshot.ReadManifest(rd)
+	if err != nil {
+		return cstate.LatestBlockState{}, err
+	}
+	if manifest.Height != sn.Height || !manifest.BlockHash.Equal(sn.BlockHash) || !manifest.AppHash.Equal(sn.AppHash) {
+		return cstate.LatestBlockState{}, fmt.Errorf("archive of block %d %v does not match the snapshot", manifest.Height, manifest.BlockHash.Hex())
+	}
+	if _, err := rd.Seek(0, io.SeekStart); err != nil {
+		return cstate.LatestBlockState{}, err
+	}
+	if _, err := snapshot.Import(r.db, rd, sn.Hash); err != nil {
+		return cstate.LatestBlockState{}, err
+	}
+	return cstate.NewStore(r.db).Load()
+}
+
+// Sync restores the state from a snapshot of the peers, verified against
+// provider. It returns once the state is restored, or the reactor stopped.
+func (r *Reactor) Sync(provider StateProvider) (cstate.LatestBlockState, error) {
+	r.mtx.Lock()
+	if r.syncer != nil {
+		r.mtx.Unlock()
+		return cstate.LatestBlockState{}, errors.New("a state sync is already running")
+	}
+	r.syncer = newSyncer(r.Logger, r.config, provider, r.restore, r.requestChunk)
+	r.mtx.Unlock()
+
+	defer func() {
+		r.mtx.Lock()
+		r.syncer = nil
+		r.mtx.Unlock()
+	}()
+
+	// Ask the peers already connected, the next ones are asked in AddPeer.
+	msgBytes, err := encodeMsg(&ssproto.SnapshotsRequest{})
+	if err != nil {
+		return cstate.LatestBlockState{}, err
+	}
+	r.Switch.Broadcast(SnapshotChannel, msgBytes)
+
+	return r.syncer.SyncAny(r.config.DiscoveryTime, r.Quit())
+}
+
+// SetLogger sets the logger of the reactor.
+func (r *Reactor) SetLogger(l log.Logger) {
+	r.Logger = l
+}
@@ -0,0 +1,290 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package statesync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb"
+	"github.com/kardiachain/go-kardia/kai/snapshot"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	ssproto "github.com/kardiachain/go-kardia/proto/kardiachain/statesync"
+)
+
+// chunkSize is the size of the chunks of a snapshot archive, all chunks but
+// the last one are full.
+var chunkSize = 4 * 1024 * 1024
+
+// Snapshot is an archive of the chain data at a height, served to peers in
+// chunks.
+type Snapshot struct {
+	Height      uint64        `json:"height"`
+	Format      uint32        `json:"format"` // version of the archive format
+	Chunks      uint32        `json:"chunks"`
+	Hash        common.Hash   `json:"hash"` // checksum of the archive records
+	BlockHash   common.Hash   `json:"blockHash"`
+	AppHash     common.Hash   `json:"appHash"` // state root after the block
+	ChunkHashes []common.Hash `json:"chunkHashes"`
+}
+
+// Key identifies a snapshot. Peers offering the same key serve the same
+// chunks.
+func (s *Snapshot) Key() common.Hash {
+	bz, _ := s.ToProto().Marshal()
+	return crypto.Keccak256Hash(bz)
+}
+
+// ToProto converts the snapshot to its protobuf message.
+func (s *Snapshot) ToProto() *ssproto.SnapshotsResponse {
+	chunkHashes := make([][]byte, len(s.ChunkHashes))
+	for i, hash := range s.ChunkHashes {
+		chunkHashes[i] = hash.Bytes()
+	}
+	return &ssproto.SnapshotsResponse{
+		Height:      s.Height,
+		Format:      s.Format,
+		Chunks:      s.Chunks,
+		Hash:        s.Hash.Bytes(),
+		BlockHash:   s.BlockHash.Bytes(),
+		AppHash:     s.AppHash.Bytes(),
+		ChunkHashes: chunkHashes,
+	}
+}
+
+// SnapshotFromProto converts a protobuf message to a snapshot, checking it is
+// well formed.
+func SnapshotFromProto(pb *ssproto.SnapshotsResponse) (*Snapshot, error) {
+	if pb == nil {
+		return nil, errors.New("nil snapshot")
+	}
+	if pb.Height == 0 {
+		return nil, errors.New("snapshot height must be positive")
+	}
+	if pb.Chunks == 0 {
+		return nil, errors.New("snapshot has no chunks")
+	}
+	if len(pb.ChunkHashes) != int(pb.Chunks) {
+		return nil, fmt.Errorf("snapshot has %d chunk hashes for %d chunks", len(pb.ChunkHashes), pb.Chunks)
+	}
+	for _, hash := range append([][]byte{pb.Hash, pb.BlockHash, pb.AppHash}, pb.ChunkHashes...) {
+		if len(hash) != common.HashLength {
+			return nil, fmt.Errorf("invalid snapshot hash %x", hash)
+		}
+	}
+	chunkHashes := make([]common.Hash, len(pb.ChunkHashes))
+	for i, hash := range pb.ChunkHashes {
+		chunkHashes[i] = common.BytesToHash(hash)
+	}
+	return &Snapshot{
+		Height:      pb.Height,
+		Format:      pb.Format,
+		Chunks:      pb.Chunks,
+		Hash:        common.BytesToHash(pb.Hash),
+		BlockHash:   common.BytesToHash(pb.BlockHash),
+		AppHash:     common.BytesToHash(pb.AppHash),
+		ChunkHashes: chunkHashes,
+	}, nil
+}
+
+// Store keeps the snapshots of a node in a directory. Every snapshot is an
+// archive file along with a JSON file describing it.
+type Store struct {
+	dir string
+	mtx sync.RWMutex
+}
+
+// NewStore returns a store keeping its snapshots in dir.
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	// Remove the archives left over by snapshots interrupted by a restart.
+	tmps, err := filepath.Glob(filepath.Join(dir, "tmp-*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, tmp := range tmps {
+		os.Remove(tmp)
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) path(height uint64, format uint32) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d-%d", height, format))
+}
+
+// Create exports a snapshot of the chain data of db at height.
+func (s *Store) Create(db kaidb.Database, height uint64) (*Snapshot, error) {
+	f, err := ioutil.TempFile(s.dir, "tmp-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	manifest, err := snapshot.Export(db, height, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return s.save(manifest, f.Name())
+}
+
+// save moves the archive file of manifest into the store.
+func (s *Store) save(manifest *snapshot.Manifest, file string) (*Snapshot, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sn := &Snapshot{
+		Height:    manifest.Height,
+		Format:    manifest.Version,
+		Hash:      manifest.Checksum,
+		BlockHash: manifest.BlockHash,
+		AppHash:   manifest.AppHash,
+	}
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 {
+			sn.ChunkHashes = append(sn.ChunkHashes, crypto.Keccak256Hash(buf[:n]))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	sn.Chunks = uint32(len(sn.ChunkHashes))
+	enc, err := json.Marshal(sn)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	path := s.path(sn.Height, sn.Format)
+	if err := os.Rename(file, path+".archive"); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path+".json", enc, 0644); err != nil {
+		return nil, err
+	}
+	return sn, nil
+}
+
+// Get returns the snapshot at height, or nil if there is none.
+func (s *Store) Get(height uint64, format uint32) (*Snapshot, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.load(s.path(height, format) + ".json")
+}
+
+func (s *Store) load(file string) (*Snapshot, error) {
+	enc, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sn := new(Snapshot)
+	if err := json.Unmarshal(enc, sn); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", file, err)
+	}
+	return sn, nil
+}
+
+// List returns the snapshots of the store, newest first.
+func (s *Store) List() ([]*Snapshot, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	// File names start with the padded height.
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	snapshots := make([]*Snapshot, 0, len(files))
+	for _, file := range files {
+		sn, err := s.load(file)
+		if err != nil {
+			return nil, err
+		}
+		if sn != nil {
+			snapshots = append(snapshots, sn)
+		}
+	}
+	return snapshots, nil
+}
+
+// LoadChunk returns a chunk of the snapshot at height, or nil if there is
+// none.
+func (s *Store) LoadChunk(height uint64, format, index uint32) ([]byte, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	f, err := os.Open(s.path(height, format) + ".archive")
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, chunkSize)
+	n, err := f.ReadAt(buf, int64(index)*int64(chunkSize))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	return buf[:n], nil
+}
+
+// Prune deletes all but the keep most recent snapshots.
+func (s *Store) Prune(keep int) error {
+	snapshots, err := s.List()
+	if err != nil || len(snapshots) <= keep {
+		return err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, sn := range snapshots[keep:] {
+		path := s.path(sn.Height, sn.Format)
+		for _, file := range []string{path + ".json", path + ".archive"} {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
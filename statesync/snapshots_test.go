@@ -0,0 +1,164 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package statesync
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/kai/snapshot"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+)
+
+// setChunkSize shrinks the chunks for a test.
+func setChunkSize(t *testing.T, size int) {
+	old := chunkSize
+	chunkSize = size
+	t.Cleanup(func() { chunkSize = old })
+}
+
+// saveArchive saves data as the archive of a snapshot at height.
+func saveArchive(t *testing.T, store *Store, height uint64, data []byte) *Snapshot {
+	f, err := ioutil.TempFile(store.dir, "tmp-")
+	require.NoError(t, err)
+	_, err = f.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	sn, err := store.save(&snapshot.Manifest{
+		Version:   1,
+		Height:    height,
+		BlockHash: common.BytesToHash([]byte{byte(height)}),
+		AppHash:   common.BytesToHash([]byte{byte(height), 1}),
+		Checksum:  crypto.Keccak256Hash(data),
+	}, f.Name())
+	require.NoError(t, err)
+	return sn
+}
+
+func TestStore(t *testing.T) {
+	setChunkSize(t, 4)
+	dir, err := ioutil.TempDir("", "snapshots")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Leftover archives are removed.
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "tmp-1"), []byte("x"), 0644))
+	store, err := NewStore(dir)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "tmp-1"))
+	assert.True(t, os.IsNotExist(err))
+
+	data := []byte("0123456789")
+	sn := saveArchive(t, store, 10, data)
+	assert.EqualValues(t, 3, sn.Chunks)
+	assert.Equal(t, []common.Hash{
+		crypto.Keccak256Hash([]byte("0123")),
+		crypto.Keccak256Hash([]byte("4567")),
+		crypto.Keccak256Hash([]byte("89")),
+	}, sn.ChunkHashes)
+
+	got, err := store.Get(10, 1)
+	require.NoError(t, err)
+	assert.Equal(t, sn, got)
+	got, err = store.Get(11, 1)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	var chunks [][]byte
+	for i := uint32(0); i < sn.Chunks; i++ {
+		chunk, err := store.LoadChunk(10, 1, i)
+		require.NoError(t, err)
+		assert.Equal(t, sn.ChunkHashes[i], crypto.Keccak256Hash(chunk))
+		chunks = append(chunks, chunk)
+	}
+	assert.Equal(t, data, bytes.Join(chunks, nil))
+
+	chunk, err := store.LoadChunk(10, 1, sn.Chunks)
+	require.NoError(t, err)
+	assert.Nil(t, chunk)
+	chunk, err = store.LoadChunk(11, 1, 0)
+	require.NoError(t, err)
+	assert.Nil(t, chunk)
+}
+
+func TestStoreListPrune(t *testing.T) {
+	setChunkSize(t, 4)
+	dir, err := ioutil.TempDir("", "snapshots")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store, err := NewStore(dir)
+	require.NoError(t, err)
+
+	for _, height := range []uint64{100, 9, 20} {
+		saveArchive(t, store, height, []byte{byte(height)})
+	}
+	heights := func() []uint64 {
+		snapshots, err := store.List()
+		require.NoError(t, err)
+		var heights []uint64
+		for _, sn := range snapshots {
+			heights = append(heights, sn.Height)
+		}
+		return heights
+	}
+	assert.Equal(t, []uint64{100, 20, 9}, heights())
+
+	require.NoError(t, store.Prune(2))
+	assert.Equal(t, []uint64{100, 20}, heights())
+	chunk, err := store.LoadChunk(9, 1, 0)
+	require.NoError(t, err)
+	assert.Nil(t, chunk)
+
+	require.NoError(t, store.Prune(5))
+	assert.Equal(t, []uint64{100, 20}, heights())
+}
+
+func TestSnapshotProto(t *testing.T) {
+	sn := &Snapshot{
+		Height:      5,
+		Format:      1,
+		Chunks:      1,
+		Hash:        common.BytesToHash([]byte{1}),
+		BlockHash:   common.BytesToHash([]byte{2}),
+		AppHash:     common.BytesToHash([]byte{3}),
+		ChunkHashes: []common.Hash{common.BytesToHash([]byte{4})},
+	}
+	got, err := SnapshotFromProto(sn.ToProto())
+	require.NoError(t, err)
+	assert.Equal(t, sn, got)
+	assert.Equal(t, sn.Key(), got.Key())
+
+	pb := sn.ToProto()
+	pb.Chunks = 2
+	_, err = SnapshotFromProto(pb)
+	assert.Error(t, err)
+
+	pb = sn.ToProto()
+	pb.AppHash = []byte{1}
+	_, err = SnapshotFromProto(pb)
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package statesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/light"
+	"github.com/kardiachain/go-kardia/rpc"
+)
+
+// lightStateProvider is a StateProvider verifying headers with a light client.
+type lightStateProvider struct {
+	client *light.Client
+}
+
+// NewLightStateProvider returns a StateProvider verifying the headers served
+// by the RPC servers of config from its trusted header. The servers are tried
+// in turn until one initializes the light client.
+func NewLightStateProvider(ctx context.Context, logger log.Logger, chainID string, config *configs.StateSyncConfig) (StateProvider, error) {
+	if len(config.RPCServers) == 0 {
+		return nil, errors.New("at least one RPC server is required")
+	}
+	opts := light.TrustOptions{
+		Period: config.TrustPeriod,
+		Height: config.TrustHeight,
+		Hash:   config.TrustHash,
+	}
+	var errs []error
+	for _, server := range config.RPCServers {
+		client, err := rpc.DialContext(ctx, server)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", server, err))
+			continue
+		}
+		lc, err := light.NewClient(ctx, logger, chainID, opts, light.NewRPCProvider(client), memorydb.New())
+		if err != nil {
+			client.Close()
+			errs = append(errs, fmt.Errorf("%s: %w", server, err))
+			continue
+		}
+		return &lightStateProvider{client: lc}, nil
+	}
+	return nil, fmt.Errorf("failed to initialize light client: %v", errs)
+}
+
+// BlockHash implements StateProvider.
+func (p *lightStateProvider) BlockHash(ctx context.Context, height uint64) (common.Hash, error) {
+	sh, err := p.client.VerifyHeaderAtHeight(ctx, height)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return sh.Hash(), nil
+}
+
+// AppHash implements StateProvider. The state root after a block is in the
+// header of the next one.
+func (p *lightStateProvider) AppHash(ctx context.Context, height uint64) (common.Hash, error) {
+	sh, err := p.client.VerifyHeaderAtHeight(ctx, height+1)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return sh.Header.AppHash, nil
+}
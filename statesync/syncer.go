@@ -0,0 +1,363 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package statesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/lib/p2p"
+)
+
+var (
+	// errAbort is returned when the sync is stopped.
+	errAbort = errors.New("state sync aborted")
+	// errNoSnapshots is returned when no snapshot can be restored.
+	errNoSnapshots = errors.New("no suitable snapshots found")
+	// errNoPeers is returned when no peer serves the chunks of a snapshot
+	// any more.
+	errNoPeers = errors.New("no peers left serving the snapshot")
+)
+
+// StateProvider provides the trusted hashes snapshots are verified against,
+// usually through a light client.
+type StateProvider interface {
+	// BlockHash returns the hash of the block at height.
+	BlockHash(ctx context.Context, height uint64) (common.Hash, error)
+	// AppHash returns the state root after the block at height.
+	AppHash(ctx context.Context, height uint64) (common.Hash, error)
+}
+
+// restoreFunc restores the verified archive of a snapshot, returning the
+// restored state.
+type restoreFunc func(r io.ReadSeeker, sn *Snapshot) (cstate.LatestBlockState, error)
+
+// requestFunc requests a chunk of a snapshot from a peer.
+type requestFunc func(peer p2p.Peer, sn *Snapshot, index uint32)
+
+// offer is a snapshot along with the peers offering it.
+type offer struct {
+	snapshot *Snapshot
+	peers    map[p2p.ID]p2p.Peer
+}
+
+// chunk is a chunk received from a peer.
+type chunk struct {
+	peer    p2p.ID
+	height  uint64
+	format  uint32
+	index   uint32
+	data    []byte
+	missing bool
+}
+
+// syncer restores the state from a snapshot offered by peers.
+type syncer struct {
+	logger   log.Logger
+	config   *configs.StateSyncConfig
+	provider StateProvider
+	restore  restoreFunc
+	request  requestFunc
+
+	mtx      sync.Mutex
+	offers   map[common.Hash]*offer
+	rejected map[common.Hash]bool
+	chunks   chan chunk
+}
+
+func newSyncer(logger log.Logger, config *configs.StateSyncConfig, provider StateProvider,
+	restore restoreFunc, request requestFunc) *syncer {
+	return &syncer{
+		logger:   logger,
+		config:   config,
+		provider: provider,
+		restore:  restore,
+		request:  request,
+		offers:   make(map[common.Hash]*offer),
+		rejected: make(map[common.Hash]bool),
+		chunks:   make(chan chunk, 4*config.ChunkFetchers),
+	}
+}
+
+// addSnapshot adds a snapshot offered by peer. It returns false if the
+// snapshot was already offered by the peer or rejected.
+func (s *syncer) addSnapshot(peer p2p.Peer, sn *Snapshot) bool {
+	key := sn.Key()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.rejected[key] {
+		return false
+	}
+	o := s.offers[key]
+	if o == nil {
+		o = &offer{snapshot: sn, peers: make(map[p2p.ID]p2p.Peer)}
+		s.offers[key] = o
+		s.logger.Info("Discovered new snapshot", "height", sn.Height, "format", sn.Format, "hash", sn.Hash.Hex())
+	}
+	if _, ok := o.peers[peer.ID()]; ok {
+		return false
+	}
+	o.peers[peer.ID()] = peer
+	return true
+}
+
+// removePeer removes the snapshots offered by peer.
+func (s *syncer) removePeer(id p2p.ID) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for key, o := range s.offers {
+		delete(o.peers, id)
+		if len(o.peers) == 0 {
+			delete(s.offers, key)
+		}
+	}
+}
+
+// reject removes a snapshot and ignores further offers of it.
+func (s *syncer) reject(sn *Snapshot) {
+	key := sn.Key()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.rejected[key] = true
+	delete(s.offers, key)
+}
+
+// best returns the highest snapshot offered by the most peers, or nil if
+// there is none.
+func (s *syncer) best() *Snapshot {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	offers := make([]*offer, 0, len(s.offers))
+	for _, o := range s.offers {
+		offers = append(offers, o)
+	}
+	if len(offers) == 0 {
+		return nil
+	}
+	sort.Slice(offers, func(i, j int) bool {
+		a, b := offers[i], offers[j]
+		if a.snapshot.Height != b.snapshot.Height {
+			return a.snapshot.Height > b.snapshot.Height
+		}
+		if a.snapshot.Format != b.snapshot.Format {
+			return a.snapshot.Format > b.snapshot.Format
+		}
+		return len(a.peers) > len(b.peers)
+	})
+	return offers[0].snapshot
+}
+
+// peers returns the peers offering a snapshot.
+func (s *syncer) peers(sn *Snapshot) []p2p.Peer {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	o := s.offers[sn.Key()]
+	if o == nil {
+		return nil
+	}
+	peers := make([]p2p.Peer, 0, len(o.peers))
+	for _, peer := range o.peers {
+		peers = append(peers, peer)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].ID() < peers[j].ID() })
+	return peers
+}
+
+// removeOffer removes a peer from the peers offering a snapshot, after it
+// failed to serve it.
+func (s *syncer) removeOffer(sn *Snapshot, id p2p.ID) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if o := s.offers[sn.Key()]; o != nil {
+		delete(o.peers, id)
+	}
+}
+
+// addChunk queues a chunk received from a peer.
+func (s *syncer) addChunk(c chunk) {
+	select {
+	case s.chunks <- c:
+	default:
+		s.logger.Debug("Dropping chunk, queue is full", "height", c.height, "index", c.index, "peer", c.peer)
+	}
+}
+
+// SyncAny restores the best snapshot offered by peers, trying the next one if
+// it fails. It waits discoveryTime for offers whenever there is none.
+func (s *syncer) SyncAny(discoveryTime time.Duration, quit <-chan struct{}) (cstate.LatestBlockState, error) {
+	for {
+		sn := s.best()
+		if sn == nil {
+			if discoveryTime == 0 {
+				return cstate.LatestBlockState{}, errNoSnapshots
+			}
+			s.logger.Info("Discovering snapshots", "time", discoveryTime)
+			select {
+			case <-time.After(discoveryTime):
+				continue
+			case <-quit:
+				return cstate.LatestBlockState{}, errAbort
+			}
+		}
+
+		state, err := s.Sync(sn, quit)
+		if err == nil {
+			return state, nil
+		}
+		if errors.Is(err, errAbort) {
+			return cstate.LatestBlockState{}, err
+		}
+		s.logger.Error("Failed to restore snapshot, rejecting it", "height", sn.Height, "hash", sn.Hash.Hex(), "err", err)
+		s.reject(sn)
+	}
+}
+
+// Sync verifies a snapshot against the state provider, fetches its chunks
+// and restores it.
+func (s *syncer) Sync(sn *Snapshot, quit <-chan struct{}) (cstate.LatestBlockState, error) {
+	if err := s.verify(sn); err != nil {
+		return cstate.LatestBlockState{}, err
+	}
+	s.logger.Info("Fetching snapshot chunks", "height", sn.Height, "chunks", sn.Chunks)
+	f, err := ioutil.TempFile("", "kai-statesync-")
+	if err != nil {
+		return cstate.LatestBlockState{}, err
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+	if err := s.fetchChunks(sn, f, quit); err != nil {
+		return cstate.LatestBlockState{}, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return cstate.LatestBlockState{}, err
+	}
+	s.logger.Info("Restoring snapshot", "height", sn.Height, "hash", sn.Hash.Hex())
+	return s.restore(f, sn)
+}
+
+// verify checks the block and app hash of a snapshot are the trusted ones.
+func (s *syncer) verify(sn *Snapshot) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	blockHash, err := s.provider.BlockHash(ctx, sn.Height)
+	if err != nil {
+		return fmt.Errorf("failed to verify block %d: %w", sn.Height, err)
+	}
+	if !blockHash.Equal(sn.BlockHash) {
+		return fmt.Errorf("snapshot block hash %v does not match the trusted %v", sn.BlockHash.Hex(), blockHash.Hex())
+	}
+	appHash, err := s.provider.AppHash(ctx, sn.Height)
+	if err != nil {
+		return fmt.Errorf("failed to verify app hash %d: %w", sn.Height, err)
+	}
+	if !appHash.Equal(sn.AppHash) {
+		return fmt.Errorf("snapshot app hash %v does not match the trusted %v", sn.AppHash.Hex(), appHash.Hex())
+	}
+	return nil
+}
+
+// pendingChunk is a chunk requested from a peer.
+type pendingChunk struct {
+	peer     p2p.ID
+	deadline time.Time
+}
+
+// fetchChunks requests the chunks of a snapshot from the peers offering it,
+// ChunkFetchers at a time, and writes them to w as they are verified.
+func (s *syncer) fetchChunks(sn *Snapshot, w io.WriterAt, quit <-chan struct{}) error {
+	var (
+		queue    = make([]uint32, 0, sn.Chunks)
+		pending  = make(map[uint32]pendingChunk)
+		received uint32
+		next     int // round robin over the peers
+	)
+	for i := uint32(0); i < sn.Chunks; i++ {
+		queue = append(queue, i)
+	}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for received < sn.Chunks {
+		for len(queue) > 0 && len(pending) < s.config.ChunkFetchers {
+			peers := s.peers(sn)
+			if len(peers) == 0 {
+				return errNoPeers
+			}
+			peer := peers[next%len(peers)]
+			next++
+			index := queue[0]
+			queue = queue[1:]
+			pending[index] = pendingChunk{peer: peer.ID(), deadline: time.Now().Add(s.config.ChunkRequestTimeout)}
+			s.request(peer, sn, index)
+		}
+
+		select {
+		case c := <-s.chunks:
+			req, ok := pending[c.index]
+			if !ok || c.height != sn.Height || c.format != sn.Format || c.peer != req.peer {
+				continue
+			}
+			delete(pending, c.index)
+			if c.missing {
+				s.logger.Debug("Peer does not have chunk", "peer", c.peer, "index", c.index)
+				s.removeOffer(sn, c.peer)
+				queue = append(queue, c.index)
+				continue
+			}
+			if hash := crypto.Keccak256Hash(c.data); !hash.Equal(sn.ChunkHashes[c.index]) {
+				s.logger.Error("Received invalid chunk", "peer", c.peer, "index", c.index)
+				s.removeOffer(sn, c.peer)
+				queue = append(queue, c.index)
+				continue
+			}
+			if _, err := w.WriteAt(c.data, int64(c.index)*int64(chunkSize)); err != nil {
+				return err
+			}
+			received++
+			s.logger.Debug("Applied snapshot chunk", "height", sn.Height, "index", c.index, "total", sn.Chunks)
+
+		case now := <-ticker.C:
+			for index, req := range pending {
+				if now.After(req.deadline) {
+					s.logger.Debug("Chunk request timed out", "peer", req.peer, "index", index)
+					delete(pending, index)
+					queue = append(queue, index)
+				}
+			}
+
+		case <-quit:
+			return errAbort
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package statesync
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kardiachain/go-kardia/configs"
+	"github.com/kardiachain/go-kardia/kai/state/cstate"
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/log"
+	"github.com/kardiachain/go-kardia/lib/p2p"
+	"github.com/kardiachain/go-kardia/lib/p2p/mock"
+)
+
+// trustedProvider is a StateProvider trusting the hashes of a snapshot.
+type trustedProvider struct {
+	sn *Snapshot
+}
+
+func (p trustedProvider) BlockHash(ctx context.Context, height uint64) (common.Hash, error) {
+	return p.sn.BlockHash, nil
+}
+
+func (p trustedProvider) AppHash(ctx context.Context, height uint64) (common.Hash, error) {
+	return p.sn.AppHash, nil
+}
+
+// newTestSnapshot returns a snapshot of data split in chunks.
+func newTestSnapshot(height uint64, data []byte) *Snapshot {
+	sn := &Snapshot{
+		Height:    height,
+		Format:    1,
+		Hash:      crypto.Keccak256Hash(data),
+		BlockHash: common.BytesToHash([]byte{byte(height)}),
+		AppHash:   common.BytesToHash([]byte{byte(height), 1}),
+	}
+	for i := 0; i < len(data); i += chunkSize {
+		end := i + chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		sn.ChunkHashes = append(sn.ChunkHashes, crypto.Keccak256Hash(data[i:end]))
+	}
+	sn.Chunks = uint32(len(sn.ChunkHashes))
+	return sn
+}
+
+func testStateSyncConfig() *configs.StateSyncConfig {
+	config := configs.DefaultStateSyncConfig()
+	config.ChunkFetchers = 2
+	config.ChunkRequestTimeout = time.Second
+	return config
+}
+
+func TestSyncerSync(t *testing.T) {
+	setChunkSize(t, 4)
+	data := []byte("the archive of a snapshot")
+	sn := newTestSnapshot(10, data)
+
+	var restored []byte
+	restore := func(r io.ReadSeeker, got *Snapshot) (cstate.LatestBlockState, error) {
+		assert.Equal(t, sn, got)
+		var err error
+		restored, err = ioutil.ReadAll(r)
+		return cstate.LatestBlockState{LastBlockHeight: got.Height}, err
+	}
+
+	// The bad peer serves corrupted chunks, it must be dropped and its
+	// chunks fetched from the good one.
+	good, bad := mock.NewPeer(nil), mock.NewPeer(nil)
+	var s *syncer
+	requests := make(map[p2p.ID]int)
+	request := func(peer p2p.Peer, sn *Snapshot, index uint32) {
+		requests[peer.ID()]++
+		begin := int(index) * chunkSize
+		end := begin + chunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		c := chunk{peer: peer.ID(), height: sn.Height, format: sn.Format, index: index, data: data[begin:end]}
+		if peer.ID() == bad.ID() {
+			c.data = []byte("bad")
+		}
+		s.addChunk(c)
+	}
+	s = newSyncer(log.New(), testStateSyncConfig(), trustedProvider{sn}, restore, request)
+	assert.True(t, s.addSnapshot(good, sn))
+	assert.True(t, s.addSnapshot(bad, sn))
+	assert.False(t, s.addSnapshot(good, sn))
+
+	state, err := s.SyncAny(0, make(chan struct{}))
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, state.LastBlockHeight)
+	assert.Equal(t, data, restored)
+	assert.Equal(t, []p2p.Peer{good}, s.peers(sn))
+	assert.Equal(t, 1, requests[bad.ID()])
+}
+
+func TestSyncerRejectsUntrustedSnapshot(t *testing.T) {
+	setChunkSize(t, 4)
+	trusted := newTestSnapshot(10, []byte("trusted"))
+	untrusted := newTestSnapshot(20, []byte("untrusted"))
+
+	var restoredHeights []uint64
+	restore := func(r io.ReadSeeker, sn *Snapshot) (cstate.LatestBlockState, error) {
+		restoredHeights = append(restoredHeights, sn.Height)
+		return cstate.LatestBlockState{LastBlockHeight: sn.Height}, nil
+	}
+	data := map[uint64][]byte{10: []byte("trusted"), 20: []byte("untrusted")}
+	var s *syncer
+	request := func(peer p2p.Peer, sn *Snapshot, index uint32) {
+		bz := data[sn.Height][int(index)*chunkSize:]
+		if len(bz) > chunkSize {
+			bz = bz[:chunkSize]
+		}
+		s.addChunk(chunk{peer: peer.ID(), height: sn.Height, format: sn.Format, index: index, data: bz})
+	}
+	s = newSyncer(log.New(), testStateSyncConfig(), trustedProvider{trusted}, restore, request)
+	peer := mock.NewPeer(nil)
+	s.addSnapshot(peer, untrusted)
+	s.addSnapshot(peer, trusted)
+
+	// The highest snapshot is tried first, but does not match the trusted
+	// hashes.
+	state, err := s.SyncAny(0, make(chan struct{}))
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, state.LastBlockHeight)
+	assert.Equal(t, []uint64{10}, restoredHeights)
+	assert.False(t, s.addSnapshot(peer, untrusted))
+}
+
+func TestSyncerNoPeers(t *testing.T) {
+	setChunkSize(t, 4)
+	sn := newTestSnapshot(10, []byte("snapshot"))
+	restore := func(r io.ReadSeeker, sn *Snapshot) (cstate.LatestBlockState, error) {
+		t.Fatal("restored a snapshot without chunks")
+		return cstate.LatestBlockState{}, nil
+	}
+	var s *syncer
+	request := func(peer p2p.Peer, sn *Snapshot, index uint32) {
+		s.addChunk(chunk{peer: peer.ID(), height: sn.Height, format: sn.Format, index: index, missing: true})
+	}
+	s = newSyncer(log.New(), testStateSyncConfig(), trustedProvider{sn}, restore, request)
+	s.addSnapshot(mock.NewPeer(nil), sn)
+
+	_, err := s.Sync(sn, make(chan struct{}))
+	assert.Equal(t, errNoPeers, err)
+	_, err = s.SyncAny(0, make(chan struct{}))
+	assert.Equal(t, errNoSnapshots, err)
+}
+
+func TestSyncerAbort(t *testing.T) {
+	sn := newTestSnapshot(10, []byte("snapshot"))
+	request := func(peer p2p.Peer, sn *Snapshot, index uint32) {}
+	s := newSyncer(log.New(), testStateSyncConfig(), trustedProvider{sn}, nil, request)
+
+	quit := make(chan struct{})
+	close(quit)
+	_, err := s.SyncAny(time.Minute, quit)
+	assert.Equal(t, errAbort, err)
+
+	s.addSnapshot(mock.NewPeer(nil), sn)
+	_, err = s.SyncAny(time.Minute, quit)
+	assert.Equal(t, errAbort, err)
+}
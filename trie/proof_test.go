@@ -0,0 +1,92 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package trie
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/kardiachain/go-kardia/kai/kaidb/memorydb"
+)
+
+func TestProof(t *testing.T) {
+	trie := newEmpty()
+	values := make(map[string][]byte)
+	for i := 0; i < 200; i++ {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		value := []byte(fmt.Sprintf("value-%d", i))
+		trie.Update(key, value)
+		values[string(key)] = value
+	}
+	root := trie.Hash()
+
+	for key, want := range values {
+		proof := memorydb.New()
+		if err := trie.Prove([]byte(key), 0, proof); err != nil {
+			t.Fatalf("prove %q: %v", key, err)
+		}
+		got, err := VerifyProof(root, []byte(key), proof)
+		if err != nil {
+			t.Fatalf("verify %q: %v", key, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("verify %q: got %x, want %x", key, got, want)
+		}
+	}
+}
+
+func TestProofOfAbsence(t *testing.T) {
+	trie := newEmpty()
+	trie.Update([]byte("key-1"), []byte("value-1"))
+	trie.Update([]byte("key-2"), []byte("value-2"))
+	root := trie.Hash()
+
+	proof := memorydb.New()
+	if err := trie.Prove([]byte("key-3"), 0, proof); err != nil {
+		t.Fatal(err)
+	}
+	got, err := VerifyProof(root, []byte("key-3"), proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != nil {
+		t.Fatalf("got value %x for absent key", got)
+	}
+}
+
+func TestBadProof(t *testing.T) {
+	trie := newEmpty()
+	trie.Update([]byte("key-1"), []byte("value-1"))
+	trie.Update([]byte("key-2"), []byte("value-2"))
+	root := trie.Hash()
+
+	proof := memorydb.New()
+	if err := trie.Prove([]byte("key-1"), 0, proof); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyProof(root, []byte("key-1"), memorydb.New()); err == nil {
+		t.Fatal("expected error for empty proof")
+	}
+	other := newEmpty()
+	other.Update([]byte("key-1"), []byte("value-3"))
+	if _, err := VerifyProof(other.Hash(), []byte("key-1"), proof); err == nil {
+		t.Fatal("expected error for proof of another root")
+	}
+}
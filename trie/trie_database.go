@@ -17,6 +17,7 @@
 package trie
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"sync"
@@ -49,6 +50,11 @@ var secureKeyPrefix = []byte("secure-key-")
 // secureKeyLength is the length of the above prefix + 32byte hash.
 const secureKeyLength = 11 + 32
 
+// IsPreimageKey reports whether key is the key of a trie node preimage.
+func IsPreimageKey(key []byte) bool {
+	return len(key) == secureKeyLength && bytes.HasPrefix(key, secureKeyPrefix)
+}
+
 // TrieDatabase is an intermediate write layer between the trie data structures and
 // the disk database. The aim is to accumulate trie writes in-memory and only
 // periodically flush a couple tries to disk, garbage collecting the remainder.
@@ -0,0 +1,572 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/merkle"
+	"github.com/kardiachain/go-kardia/lib/rlp"
+	"github.com/kardiachain/go-kardia/mainchain/staking/types"
+	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
+)
+
+// Bridge validators vote for the proposals of dual nodes, the encodings below must match the ones of
+// dualnode/proposal.
+const (
+	bridgeProposalDeposit = 0 // releases one deposit, its argument is the RLP encoded deposit
+	bridgeProposalBatch   = 5 // releases deposits at once, its arguments are their Merkle root and the deposits
+)
+
+// bridgeVoteDomain separates the signatures of bridge votes from other signatures of validators.
+var bridgeVoteDomain = []byte("dualnode-vote")
+
+var ErrBridgeVoteInvalidSignature = errors.New("invalid bridge vote signature")
+
+// BridgeVote is the vote of a bridge validator for a dual node proposal.
+type BridgeVote struct {
+	ProposalHash     common.Hash
+	ValidatorAddress common.Address
+	Signature        []byte // [R || S || V] signature of BridgeVoteSignBytes
+}
+
+// BridgeVoteSignBytes returns the hash signed by a bridge validator voting for the proposal with the given hash.
+func BridgeVoteSignBytes(proposalHash common.Hash) []byte {
+	return crypto.Keccak256(bridgeVoteDomain, proposalHash.Bytes())
+}
+
+// Verify checks that v is signed by ValidatorAddress.
+func (v *BridgeVote) Verify() error {
+	if !VerifySignature(v.ValidatorAddress, BridgeVoteSignBytes(v.ProposalHash), v.Signature) {
+		return fmt.Errorf("%w of %v on %v", ErrBridgeVoteInvalidSignature, v.ValidatorAddress.Hex(), v.ProposalHash.Hex())
+	}
+	return nil
+}
+
+// ValidateBasic performs basic validation.
+func (v *BridgeVote) ValidateBasic() error {
+	if v == nil {
+		return errors.New("empty bridge vote")
+	}
+	if len(v.Signature) != 65 {
+		return fmt.Errorf("bridge vote signature is %d bytes, expected 65", len(v.Signature))
+	}
+	return nil
+}
+
+func (v *BridgeVote) String() string {
+	return fmt.Sprintf("BridgeVote{%v on %v}", v.ValidatorAddress.Hex(), v.ProposalHash.Hex())
+}
+
+// ToProto encodes BridgeVote to protobuf
+func (v *BridgeVote) ToProto() *kproto.BridgeVote {
+	if v == nil {
+		return nil
+	}
+	return &kproto.BridgeVote{
+		ProposalHash:     v.ProposalHash.Bytes(),
+		ValidatorAddress: v.ValidatorAddress.Bytes(),
+		Signature:        v.Signature,
+	}
+}
+
+// BridgeVoteFromProto decodes protobuf into BridgeVote
+func BridgeVoteFromProto(pb *kproto.BridgeVote) (*BridgeVote, error) {
+	if pb == nil {
+		return nil, errors.New("nil bridge vote")
+	}
+	if len(pb.ProposalHash) != common.HashLength {
+		return nil, fmt.Errorf("bridge vote proposal hash is %d bytes", len(pb.ProposalHash))
+	}
+	if len(pb.ValidatorAddress) != common.AddressLength {
+		return nil, fmt.Errorf("bridge vote validator address is %d bytes", len(pb.ValidatorAddress))
+	}
+	v := &BridgeVote{
+		ProposalHash:     common.BytesToHash(pb.ProposalHash),
+		ValidatorAddress: common.BytesToAddress(pb.ValidatorAddress),
+		Signature:        pb.Signature,
+	}
+	return v, v.ValidateBasic()
+}
+
+// BridgeDeposit is a deposit released by a bridge proposal.
+type BridgeDeposit struct {
+	Chain       string
+	TxHash      string
+	LogIndex    uint
+	BlockNumber uint64
+	Data        []byte // RLP encoded deposit, see dualnode.Deposit
+}
+
+// key identifies the deposit in a block of its source chain, tx hashes are matched case-insensitively.
+func (d *BridgeDeposit) key() string {
+	return fmt.Sprintf("%s/%s/%d/%d", d.Chain, strings.ToLower(d.TxHash), d.LogIndex, d.BlockNumber)
+}
+
+// bridgeProposal and bridgeDeposit are the fields of dual node proposals and deposits which evidence relies on.
+type bridgeProposal struct {
+	Type        uint8
+	SourceChain string
+	DestChain   string
+	Args        [][]byte
+}
+
+type bridgeDeposit struct {
+	Chain       string
+	TxHash      string
+	LogIndex    uint
+	BlockNumber uint64
+	Rest        []rlp.RawValue `rlp:"tail"`
+}
+
+// DecodeBridgeProposal returns the hash of the RLP encoded proposal and the deposits it releases. An error is
+// returned if the proposal is not a deposit or batch proposal.
+func DecodeBridgeProposal(data []byte) (common.Hash, []*BridgeDeposit, error) {
+	var p bridgeProposal
+	if err := rlp.DecodeBytes(data, &p); err != nil {
+		return common.Hash{}, nil, fmt.Errorf("invalid bridge proposal: %w", err)
+	}
+	var args [][]byte
+	switch {
+	case p.Type == bridgeProposalDeposit && len(p.Args) == 1:
+		args = p.Args
+	case p.Type == bridgeProposalBatch && len(p.Args) > 1:
+		args = p.Args[1:]
+		if !bytes.Equal(merkle.SimpleHashFromByteSlices(args), p.Args[0]) {
+			return common.Hash{}, nil, errors.New("root of bridge batch doesn't match its deposits")
+		}
+	default:
+		return common.Hash{}, nil, fmt.Errorf("bridge proposal of type %d doesn't release deposits", p.Type)
+	}
+	deposits := make([]*BridgeDeposit, len(args))
+	for i, arg := range args {
+		var d bridgeDeposit
+		if err := rlp.DecodeBytes(arg, &d); err != nil {
+			return common.Hash{}, nil, fmt.Errorf("invalid deposit %d of bridge proposal: %w", i, err)
+		}
+		if d.Chain != p.SourceChain {
+			return common.Hash{}, nil, fmt.Errorf("deposit %d of bridge proposal is from %v, not %v", i, d.Chain,
+				p.SourceChain)
+		}
+		deposits[i] = &BridgeDeposit{Chain: d.Chain, TxHash: d.TxHash, LogIndex: d.LogIndex, BlockNumber: d.BlockNumber,
+			Data: arg}
+	}
+	return hash(data), deposits, nil
+}
+
+// decodeVotedProposal decodes the proposal voted by v.
+func decodeVotedProposal(v *BridgeVote, proposal []byte) ([]*BridgeDeposit, error) {
+	proposalHash, deposits, err := DecodeBridgeProposal(proposal)
+	if err != nil {
+		return nil, err
+	}
+	if proposalHash != v.ProposalHash {
+		return nil, fmt.Errorf("proposal %v is not the one of %v", proposalHash.Hex(), v)
+	}
+	return deposits, nil
+}
+
+//-------------------------------------------
+
+// ConflictingBridgeVoteEvidence contains evidence a bridge validator voted for two proposals releasing the same
+// deposit, so that it would be released twice. A deposit proposed again after being reorganised into another block
+// of its source chain is not a conflict, since the block number is part of the deposit.
+type ConflictingBridgeVoteEvidence struct {
+	VoteA     *BridgeVote
+	VoteB     *BridgeVote
+	ProposalA []byte // RLP encoded proposal of VoteA
+	ProposalB []byte // RLP encoded proposal of VoteB
+
+	TotalVotingPower int64     // total voting power of the validators at ObservedHeight
+	ValidatorPower   int64     // voting power of the validator at ObservedHeight
+	ObservedHeight   uint64    // height at which the votes are observed, whose validators are punished
+	Timestamp        time.Time // time of the block at ObservedHeight
+}
+
+// NewConflictingBridgeVoteEvidence creates ConflictingBridgeVoteEvidence with right ordering given two conflicting
+// votes and their proposals, observed at height. If one of the votes is nil or its validator is not in valSet,
+// evidence returned is nil as well.
+func NewConflictingBridgeVoteEvidence(vote1, vote2 *BridgeVote, proposal1, proposal2 []byte, height uint64,
+	blockTime time.Time, valSet *ValidatorSet) *ConflictingBridgeVoteEvidence {
+	if vote1 == nil || vote2 == nil || valSet == nil {
+		return nil
+	}
+	_, val := valSet.GetByAddress(vote1.ValidatorAddress)
+	if val == nil {
+		return nil
+	}
+	if bytes.Compare(vote1.ProposalHash.Bytes(), vote2.ProposalHash.Bytes()) > 0 {
+		vote1, vote2 = vote2, vote1
+		proposal1, proposal2 = proposal2, proposal1
+	}
+	return &ConflictingBridgeVoteEvidence{
+		VoteA:            vote1,
+		VoteB:            vote2,
+		ProposalA:        proposal1,
+		ProposalB:        proposal2,
+		TotalVotingPower: valSet.TotalVotingPower(),
+		ValidatorPower:   val.VotingPower,
+		ObservedHeight:   height,
+		Timestamp:        blockTime,
+	}
+}
+
+// String returns a string representation of the evidence.
+func (e *ConflictingBridgeVoteEvidence) String() string {
+	return fmt.Sprintf("ConflictingBridgeVoteEvidence{VoteA: %v, VoteB: %v}", e.VoteA, e.VoteB)
+}
+
+// Height returns the height at which the votes are observed.
+func (e *ConflictingBridgeVoteEvidence) Height() uint64 {
+	return e.ObservedHeight
+}
+
+// Time returns the time of the block at the observed height.
+func (e *ConflictingBridgeVoteEvidence) Time() time.Time {
+	return e.Timestamp
+}
+
+// Bytes returns the proto-encoded evidence as a byte array.
+func (e *ConflictingBridgeVoteEvidence) Bytes() []byte {
+	bz, err := e.ToProto().Marshal()
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// Hash returns the hash of the evidence.
+func (e *ConflictingBridgeVoteEvidence) Hash() common.Hash {
+	return hash(e.Bytes())
+}
+
+// VM returns the evidence of the validator who voted for both proposals.
+func (e *ConflictingBridgeVoteEvidence) VM() []types.Evidence {
+	return []types.Evidence{{
+		Address:          e.VoteA.ValidatorAddress,
+		Height:           e.ObservedHeight,
+		Time:             e.Timestamp,
+		TotalVotingPower: uint64(e.TotalVotingPower),
+		VotingPower:      big.NewInt(e.ValidatorPower),
+	}}
+}
+
+// Deposits returns the deposit released by both proposals, and the other deposits of the proposals when one of them
+// is a batch.
+func (e *ConflictingBridgeVoteEvidence) Deposits() (*BridgeDeposit, []*BridgeDeposit, error) {
+	depositsA, err := decodeVotedProposal(e.VoteA, e.ProposalA)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid proposal A: %w", err)
+	}
+	depositsB, err := decodeVotedProposal(e.VoteB, e.ProposalB)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid proposal B: %w", err)
+	}
+	released := make(map[string]bool, len(depositsA))
+	for _, d := range depositsA {
+		released[d.key()] = true
+	}
+	var conflicting *BridgeDeposit
+	for _, d := range depositsB {
+		if released[d.key()] {
+			conflicting = d
+			break
+		}
+	}
+	if conflicting == nil {
+		return nil, nil, errors.New("proposals don't release a same deposit")
+	}
+	var others []*BridgeDeposit
+	for _, d := range append(depositsA, depositsB...) {
+		if d.key() != conflicting.key() {
+			others = append(others, d)
+		}
+	}
+	return conflicting, others, nil
+}
+
+// ValidateBasic performs basic validation.
+func (e *ConflictingBridgeVoteEvidence) ValidateBasic() error {
+	if e == nil {
+		return errors.New("empty conflicting bridge vote evidence")
+	}
+	if e.VoteA == nil || e.VoteB == nil {
+		return fmt.Errorf("one or both of the votes are empty %v, %v", e.VoteA, e.VoteB)
+	}
+	if err := e.VoteA.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid VoteA: %w", err)
+	}
+	if err := e.VoteB.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid VoteB: %w", err)
+	}
+	if !e.VoteA.ValidatorAddress.Equal(e.VoteB.ValidatorAddress) {
+		return fmt.Errorf("validator addresses do not match: %v vs %v", e.VoteA.ValidatorAddress.Hex(),
+			e.VoteB.ValidatorAddress.Hex())
+	}
+	// Enforce votes are sorted on proposal hash, which also rejects votes for the same proposal
+	if bytes.Compare(e.VoteA.ProposalHash.Bytes(), e.VoteB.ProposalHash.Bytes()) >= 0 {
+		return errors.New("bridge votes in invalid order")
+	}
+	if _, _, err := e.Deposits(); err != nil {
+		return err
+	}
+	if e.ObservedHeight == 0 {
+		return errors.New("zero observed height")
+	}
+	if e.TotalVotingPower <= 0 || e.ValidatorPower <= 0 {
+		return errors.New("negative or zero voting power")
+	}
+	return nil
+}
+
+// ToProto encodes ConflictingBridgeVoteEvidence to protobuf
+func (e *ConflictingBridgeVoteEvidence) ToProto() *kproto.ConflictingBridgeVoteEvidence {
+	return &kproto.ConflictingBridgeVoteEvidence{
+		VoteA:            e.VoteA.ToProto(),
+		VoteB:            e.VoteB.ToProto(),
+		ProposalA:        e.ProposalA,
+		ProposalB:        e.ProposalB,
+		TotalVotingPower: e.TotalVotingPower,
+		ValidatorPower:   e.ValidatorPower,
+		Height:           e.ObservedHeight,
+		Timestamp:        e.Timestamp,
+	}
+}
+
+// ConflictingBridgeVoteEvidenceFromProto decodes protobuf into ConflictingBridgeVoteEvidence
+func ConflictingBridgeVoteEvidenceFromProto(pb *kproto.ConflictingBridgeVoteEvidence) (*ConflictingBridgeVoteEvidence, error) {
+	if pb == nil {
+		return nil, errors.New("nil conflicting bridge vote evidence")
+	}
+	vA, err := BridgeVoteFromProto(pb.VoteA)
+	if err != nil {
+		return nil, err
+	}
+	vB, err := BridgeVoteFromProto(pb.VoteB)
+	if err != nil {
+		return nil, err
+	}
+	e := &ConflictingBridgeVoteEvidence{
+		VoteA:            vA,
+		VoteB:            vB,
+		ProposalA:        pb.ProposalA,
+		ProposalB:        pb.ProposalB,
+		TotalVotingPower: pb.TotalVotingPower,
+		ValidatorPower:   pb.ValidatorPower,
+		ObservedHeight:   pb.Height,
+		Timestamp:        pb.Timestamp,
+	}
+	return e, e.ValidateBasic()
+}
+
+//-------------------------------------------
+
+// UnbackedReleaseEvidence contains evidence a bridge validator voted for a proposal releasing a deposit which is not
+// in its source chain. Validators vote for deposits once they reach the confirmation depth of their chain, so the
+// evidence is only valid if the deposit is still missing once its block is final.
+type UnbackedReleaseEvidence struct {
+	Vote     *BridgeVote
+	Proposal []byte // RLP encoded proposal of Vote
+
+	TotalVotingPower int64     // total voting power of the validators at ObservedHeight
+	ValidatorPower   int64     // voting power of the validator at ObservedHeight
+	ObservedHeight   uint64    // height at which the vote is observed, whose validators are punished
+	Timestamp        time.Time // time of the block at ObservedHeight
+}
+
+// NewUnbackedReleaseEvidence creates UnbackedReleaseEvidence given a vote and its proposal observed at height. If
+// the vote is nil or its validator is not in valSet, evidence returned is nil as well.
+func NewUnbackedReleaseEvidence(vote *BridgeVote, proposal []byte, height uint64, blockTime time.Time,
+	valSet *ValidatorSet) *UnbackedReleaseEvidence {
+	if vote == nil || valSet == nil {
+		return nil
+	}
+	_, val := valSet.GetByAddress(vote.ValidatorAddress)
+	if val == nil {
+		return nil
+	}
+	return &UnbackedReleaseEvidence{
+		Vote:             vote,
+		Proposal:         proposal,
+		TotalVotingPower: valSet.TotalVotingPower(),
+		ValidatorPower:   val.VotingPower,
+		ObservedHeight:   height,
+		Timestamp:        blockTime,
+	}
+}
+
+// String returns a string representation of the evidence.
+func (e *UnbackedReleaseEvidence) String() string {
+	return fmt.Sprintf("UnbackedReleaseEvidence{Vote: %v}", e.Vote)
+}
+
+// Height returns the height at which the vote is observed.
+func (e *UnbackedReleaseEvidence) Height() uint64 {
+	return e.ObservedHeight
+}
+
+// Time returns the time of the block at the observed height.
+func (e *UnbackedReleaseEvidence) Time() time.Time {
+	return e.Timestamp
+}
+
+// Bytes returns the proto-encoded evidence as a byte array.
+func (e *UnbackedReleaseEvidence) Bytes() []byte {
+	bz, err := e.ToProto().Marshal()
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// Hash returns the hash of the evidence.
+func (e *UnbackedReleaseEvidence) Hash() common.Hash {
+	return hash(e.Bytes())
+}
+
+// VM returns the evidence of the validator who voted for the proposal.
+func (e *UnbackedReleaseEvidence) VM() []types.Evidence {
+	return []types.Evidence{{
+		Address:          e.Vote.ValidatorAddress,
+		Height:           e.ObservedHeight,
+		Time:             e.Timestamp,
+		TotalVotingPower: uint64(e.TotalVotingPower),
+		VotingPower:      big.NewInt(e.ValidatorPower),
+	}}
+}
+
+// Deposits returns the deposits released by the proposal, one of which at least is not in its source chain.
+func (e *UnbackedReleaseEvidence) Deposits() ([]*BridgeDeposit, error) {
+	return decodeVotedProposal(e.Vote, e.Proposal)
+}
+
+// ValidateBasic performs basic validation.
+func (e *UnbackedReleaseEvidence) ValidateBasic() error {
+	if e == nil {
+		return errors.New("empty unbacked release evidence")
+	}
+	if err := e.Vote.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid vote: %w", err)
+	}
+	if _, err := e.Deposits(); err != nil {
+		return err
+	}
+	if e.ObservedHeight == 0 {
+		return errors.New("zero observed height")
+	}
+	if e.TotalVotingPower <= 0 || e.ValidatorPower <= 0 {
+		return errors.New("negative or zero voting power")
+	}
+	return nil
+}
+
+// ToProto encodes UnbackedReleaseEvidence to protobuf
+func (e *UnbackedReleaseEvidence) ToProto() *kproto.UnbackedReleaseEvidence {
+	return &kproto.UnbackedReleaseEvidence{
+		Vote:             e.Vote.ToProto(),
+		Proposal:         e.Proposal,
+		TotalVotingPower: e.TotalVotingPower,
+		ValidatorPower:   e.ValidatorPower,
+		Height:           e.ObservedHeight,
+		Timestamp:        e.Timestamp,
+	}
+}
+
+// UnbackedReleaseEvidenceFromProto decodes protobuf into UnbackedReleaseEvidence
+func UnbackedReleaseEvidenceFromProto(pb *kproto.UnbackedReleaseEvidence) (*UnbackedReleaseEvidence, error) {
+	if pb == nil {
+		return nil, errors.New("nil unbacked release evidence")
+	}
+	v, err := BridgeVoteFromProto(pb.Vote)
+	if err != nil {
+		return nil, err
+	}
+	e := &UnbackedReleaseEvidence{
+		Vote:             v,
+		Proposal:         pb.Proposal,
+		TotalVotingPower: pb.TotalVotingPower,
+		ValidatorPower:   pb.ValidatorPower,
+		ObservedHeight:   pb.Height,
+		Timestamp:        pb.Timestamp,
+	}
+	return e, e.ValidateBasic()
+}
+
+//-------------------------------------------- MOCKING --------------------------------------
+
+// unstable - use only for testing
+
+// mockBridgeDeposit has the leading fields of dualnode.Deposit.
+type mockBridgeDeposit struct {
+	Chain       string
+	TxHash      string
+	LogIndex    uint
+	BlockNumber uint64
+	Token       string
+	From        string
+	To          string
+	Amount      *big.Int
+}
+
+// MockBridgeDeposit returns the RLP encoded deposit at logIndex of the ETH transaction txHash in block blockNumber.
+func MockBridgeDeposit(txHash string, logIndex uint, blockNumber uint64) []byte {
+	data, err := rlp.EncodeToBytes(&mockBridgeDeposit{
+		Chain:       "ETH",
+		TxHash:      txHash,
+		LogIndex:    logIndex,
+		BlockNumber: blockNumber,
+		Amount:      big.NewInt(100),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// MockBridgeProposal returns the RLP encoded proposal releasing the deposits on destChain, a batch if there are more
+// than one.
+func MockBridgeProposal(destChain string, deposits ...[]byte) []byte {
+	p := &bridgeProposal{Type: bridgeProposalDeposit, SourceChain: "ETH", DestChain: destChain, Args: deposits}
+	if len(deposits) > 1 {
+		p.Type = bridgeProposalBatch
+		p.Args = append([][]byte{merkle.SimpleHashFromByteSlices(deposits)}, deposits...)
+	}
+	data, err := rlp.EncodeToBytes(p)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// MockBridgeVote returns the vote of pv for the RLP encoded proposal.
+func MockBridgeVote(pv *MockPV, proposal []byte) *BridgeVote {
+	proposalHash := hash(proposal)
+	sig, err := crypto.Sign(BridgeVoteSignBytes(proposalHash), pv.privKey)
+	if err != nil {
+		panic(err)
+	}
+	return &BridgeVote{ProposalHash: proposalHash, ValidatorAddress: pv.GetAddress(), Signature: sig}
+}
@@ -0,0 +1,108 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConflictingBridgeVoteEvidence(t *testing.T) {
+	pv := NewMockPV()
+	valSet := NewValidatorSet([]*Validator{NewValidator(pv.GetAddress(), 10), NewValidator(NewMockPV().GetAddress(), 30)})
+	deposit := MockBridgeDeposit("0x01", 0, 10)
+	proposalA := MockBridgeProposal("KAI", deposit)
+	proposalB := MockBridgeProposal("TRX", deposit)
+
+	ev := NewConflictingBridgeVoteEvidence(MockBridgeVote(pv, proposalA), MockBridgeVote(pv, proposalB), proposalA,
+		proposalB, 5, defaultVoteTime, valSet)
+	require.NotNil(t, ev)
+	require.NoError(t, ev.ValidateBasic())
+	assert.Equal(t, uint64(5), ev.Height())
+	require.NoError(t, ev.VoteA.Verify())
+	require.NoError(t, ev.VoteB.Verify())
+	vm := ev.VM()
+	require.Len(t, vm, 1)
+	assert.Equal(t, pv.GetAddress(), vm[0].Address)
+	assert.Equal(t, int64(10), vm[0].VotingPower.Int64())
+	assert.Equal(t, uint64(40), vm[0].TotalVotingPower)
+
+	pb, err := EvidenceToProto(ev)
+	require.NoError(t, err)
+	decoded, err := EvidenceFromProto(pb)
+	require.NoError(t, err)
+	assert.Equal(t, ev.Hash(), decoded.Hash())
+
+	// the votes must be sorted on proposal hash
+	ev.VoteA, ev.VoteB = ev.VoteB, ev.VoteA
+	ev.ProposalA, ev.ProposalB = ev.ProposalB, ev.ProposalA
+	assert.Error(t, ev.ValidateBasic())
+
+	// a deposit reorganised into another block is proposed again honestly
+	reproposed := MockBridgeProposal("KAI", MockBridgeDeposit("0x01", 0, 11))
+	ev = NewConflictingBridgeVoteEvidence(MockBridgeVote(pv, proposalA), MockBridgeVote(pv, reproposed), proposalA,
+		reproposed, 5, defaultVoteTime, valSet)
+	assert.Error(t, ev.ValidateBasic())
+
+	// a batch releasing the deposit conflicts too, its other deposits are returned
+	batch := MockBridgeProposal("KAI", MockBridgeDeposit("0x02", 1, 10), deposit)
+	ev = NewConflictingBridgeVoteEvidence(MockBridgeVote(pv, proposalA), MockBridgeVote(pv, batch), proposalA, batch,
+		5, defaultVoteTime, valSet)
+	require.NoError(t, ev.ValidateBasic())
+	conflicting, others, err := ev.Deposits()
+	require.NoError(t, err)
+	assert.Equal(t, "0x01", conflicting.TxHash)
+	require.Len(t, others, 1)
+	assert.Equal(t, "0x02", others[0].TxHash)
+
+	// the votes must be for the given proposals, of a validator
+	ev.ProposalA = reproposed
+	assert.Error(t, ev.ValidateBasic())
+	assert.Nil(t, NewConflictingBridgeVoteEvidence(MockBridgeVote(NewMockPV(), proposalA),
+		MockBridgeVote(pv, proposalB), proposalA, proposalB, 5, defaultVoteTime, valSet))
+}
+
+func TestUnbackedReleaseEvidence(t *testing.T) {
+	pv := NewMockPV()
+	valSet := NewValidatorSet([]*Validator{NewValidator(pv.GetAddress(), 10)})
+	proposal := MockBridgeProposal("KAI", MockBridgeDeposit("0x01", 0, 10), MockBridgeDeposit("0x02", 3, 12))
+
+	ev := NewUnbackedReleaseEvidence(MockBridgeVote(pv, proposal), proposal, 5, defaultVoteTime, valSet)
+	require.NotNil(t, ev)
+	require.NoError(t, ev.ValidateBasic())
+	deposits, err := ev.Deposits()
+	require.NoError(t, err)
+	require.Len(t, deposits, 2)
+	assert.Equal(t, uint(3), deposits[1].LogIndex)
+	assert.Equal(t, uint64(12), deposits[1].BlockNumber)
+
+	pb, err := EvidenceToProto(ev)
+	require.NoError(t, err)
+	decoded, err := EvidenceFromProto(pb)
+	require.NoError(t, err)
+	assert.Equal(t, ev.Hash(), decoded.Hash())
+
+	// proposals which don't release deposits are rejected
+	ev.Proposal = []byte{0xc0}
+	assert.Error(t, ev.ValidateBasic())
+	ev.Proposal = MockBridgeProposal("KAI")
+	assert.Error(t, ev.ValidateBasic())
+}
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto/bls"
 	"github.com/kardiachain/go-kardia/lib/merkle"
 	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
 )
@@ -107,6 +108,12 @@ func (cs CommitSig) BlockID(commitBlockID BlockID) BlockID {
 
 // ValidateBasic performs basic validation.
 func (cs CommitSig) ValidateBasic() error {
+	return cs.validateBasic(false)
+}
+
+// validateBasic performs basic validation. The signatures of the validators
+// voting are aggregated into the one of the commit if aggregated is set.
+func (cs CommitSig) validateBasic(aggregated bool) error {
 	switch cs.BlockIDFlag {
 	case BlockIDFlagAbsent:
 	case BlockIDFlagCommit:
@@ -128,7 +135,10 @@ func (cs CommitSig) ValidateBasic() error {
 		}
 	default:
 		// NOTE: Timestamp validation is subtle and handled elsewhere.
-		if len(cs.Signature) == 0 {
+		if aggregated && len(cs.Signature) != 0 {
+			return errors.New("signature is present in an aggregated commit")
+		}
+		if !aggregated && len(cs.Signature) == 0 {
 			return errors.New("signature is missing")
 		}
 	}
@@ -153,13 +163,15 @@ func (cs *CommitSig) ToProto() *kproto.CommitSig {
 // FromProto sets a protobuf CommitSig to the given pointer.
 // It returns an error if the CommitSig is invalid.
 func (cs *CommitSig) FromProto(csp kproto.CommitSig) error {
+	cs.fromProto(csp)
+	return cs.ValidateBasic()
+}
 
+func (cs *CommitSig) fromProto(csp kproto.CommitSig) {
 	cs.BlockIDFlag = BlockIDFlag(csp.BlockIdFlag)
 	cs.ValidatorAddress = common.BytesToAddress(csp.ValidatorAddress)
 	cs.Timestamp = csp.Timestamp
 	cs.Signature = csp.Signature
-
-	return cs.ValidateBasic()
 }
 
 // Commit contains the evidence that a block was committed by a set of validators.
@@ -172,6 +184,9 @@ type Commit struct {
 	Signatures []CommitSig `json:"signatures"`
 	Height     uint64      `json:"height"`
 	Round      uint32      `json:"round"`
+	// AggregatedSignature is the BLS aggregate of the precommits, replacing
+	// their signatures if set.
+	AggregatedSignature []byte `json:"aggregated_signature,omitempty"`
 
 	// Volatile
 	hash     common.Hash
@@ -189,9 +204,13 @@ func NewCommit(height uint64, round uint32, blockID BlockID, commitSigs []Commit
 }
 
 // CommitToVoteSet constructs a VoteSet from the Commit and validator set.
-// Panics if signatures from the commit can't be added to the voteset.
+// Returns an error if the commit is aggregated, since its precommits carry no
+// individual signatures, or if a signature can't be added to the voteset.
 // Inverse of VoteSet.MakeCommit().
-func CommitToVoteSet(chainID string, commit *Commit, vals *ValidatorSet) *VoteSet {
+func CommitToVoteSet(chainID string, commit *Commit, vals *ValidatorSet) (*VoteSet, error) {
+	if commit.IsAggregated() {
+		return nil, errors.New("votes of an aggregated commit are not available")
+	}
 	height, round := commit.GetHeight(), commit.GetRound()
 	voteSet := NewVoteSet(chainID, height, round, kproto.PrecommitType, vals)
 	for idx, commitSig := range commit.Signatures {
@@ -199,11 +218,14 @@ func CommitToVoteSet(chainID string, commit *Commit, vals *ValidatorSet) *VoteSe
 			continue // OK, some precommits can be missing.
 		}
 		added, err := voteSet.AddVote(commit.GetVote(uint32(idx)))
-		if !added || err != nil {
-			panic(fmt.Sprintf("Failed to reconstruct LastCommit: %v", err))
+		if err != nil {
+			return nil, err
+		}
+		if !added {
+			return nil, fmt.Errorf("failed to add vote of validator #%d", idx)
 		}
 	}
-	return voteSet
+	return voteSet, nil
 }
 
 // VoteSignBytes constructs the SignBytes for the given CommitSig.
@@ -275,11 +297,23 @@ func (commit *Commit) BitArray() *common.BitArray {
 	return commit.bitArray
 }
 
-// GetByIndex returns the vote corresponding to a given validator index
+// GetByIndex returns the vote corresponding to a given validator index.
+// Returns nil for aggregated commits.
+// Implements VoteSetReader.
 func (commit *Commit) GetByIndex(valIdx uint32) *Vote {
+	if commit.IsAggregated() {
+		// The precommits have no signatures of their own, peers would reject them.
+		return nil
+	}
 	return commit.GetVote(valIdx)
 }
 
+// IsAggregated returns true if the signatures of the precommits are
+// aggregated into AggregatedSignature.
+func (commit *Commit) IsAggregated() bool {
+	return len(commit.AggregatedSignature) != 0
+}
+
 // IsCommit returns true if there is at least one signature.
 // Implements VoteSetReader.
 func (commit *Commit) IsCommit() bool {
@@ -302,6 +336,9 @@ func (commit *Commit) Hash() common.Hash {
 
 			bs[i] = bz
 		}
+		if commit.IsAggregated() {
+			bs = append(bs, commit.AggregatedSignature)
+		}
 		commit.hash = common.BytesToHash(merkle.SimpleHashFromByteSlices(bs))
 	}
 	return commit.hash
@@ -316,8 +353,12 @@ func (commit *Commit) ValidateBasic() error {
 		if len(commit.Signatures) == 0 {
 			return errors.New("no signatures in commit")
 		}
+		aggregated := commit.IsAggregated()
+		if aggregated && len(commit.AggregatedSignature) != bls.SignatureLength {
+			return fmt.Errorf("wrong aggregated signature length: %d", len(commit.AggregatedSignature))
+		}
 		for i, commitSig := range commit.Signatures {
-			if err := commitSig.ValidateBasic(); err != nil {
+			if err := commitSig.validateBasic(aggregated); err != nil {
 				return fmt.Errorf("wrong CommitSig #%d: %v", i, err)
 			}
 		}
@@ -374,6 +415,7 @@ func (commit *Commit) ToProto() *kproto.Commit {
 	c.Height = commit.Height
 	c.Round = commit.Round
 	c.BlockID = commit.BlockID.ToProto()
+	c.AggregatedSignature = commit.AggregatedSignature
 
 	return c
 }
@@ -396,11 +438,10 @@ func CommitFromProto(cp *kproto.Commit) (*Commit, error) {
 
 	sigs := make([]CommitSig, len(cp.Signatures))
 	for i := range cp.Signatures {
-		if err := sigs[i].FromProto(cp.Signatures[i]); err != nil {
-			return nil, err
-		}
+		sigs[i].fromProto(cp.Signatures[i])
 	}
 	commit.Signatures = sigs
+	commit.AggregatedSignature = cp.AggregatedSignature
 
 	commit.Height = cp.Height
 	commit.Round = cp.Round
@@ -20,13 +20,16 @@ package types
 
 import (
 	"math/big"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/crypto/bls"
 	"github.com/kardiachain/go-kardia/lib/rand"
 	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
 )
@@ -101,6 +104,62 @@ func TestCommitGetByIndex(t *testing.T) {
 	assert.Equal(t, vote.Signature, commit.Signatures[0].Signature)
 }
 
+func TestAggregatedCommit(t *testing.T) {
+	var (
+		blockID  = makeBlockIDRandom()
+		vals     = make([]*Validator, 4)
+		privVals = make([]PrivValidator, 4)
+	)
+	for i := range vals {
+		blsKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		privVals[i] = NewBLSPrivValidator(NewMockPV(), blsKey)
+		vals[i] = privVals[i].ExtractIntoValidator(1)
+	}
+	valSet := NewValidatorSet(vals)
+	sort.Sort(PrivValidatorsByAddress(privVals))
+
+	voteSet := NewVoteSet("test_chain_id", 2, 1, kproto.PrecommitType, valSet)
+	_, err := MakeCommit(blockID, 2, 1, voteSet, privVals, time.Now())
+	require.NoError(t, err)
+
+	commit := voteSet.MakeAggregatedCommit()
+	require.NotNil(t, commit)
+	assert.True(t, commit.IsAggregated())
+	require.NoError(t, commit.ValidateBasic())
+	assert.NoError(t, valSet.VerifyCommit("test_chain_id", blockID, 2, commit))
+
+	// The aggregated signature survives the proto round trip.
+	decoded, err := CommitFromProto(commit.ToProto())
+	require.NoError(t, err)
+	assert.Equal(t, commit.Hash(), decoded.Hash())
+
+	// Changing a precommit invalidates the aggregated signature.
+	other := commit.Copy()
+	other.Signatures = append([]CommitSig{}, commit.Signatures...)
+	other.Signatures[0].Timestamp = other.Signatures[0].Timestamp.Add(time.Second)
+	assert.Error(t, valSet.VerifyCommit("test_chain_id", blockID, 2, other))
+
+	// Votes of an aggregated commit have no signatures, so they are neither
+	// gossiped nor turned back into a VoteSet.
+	assert.Nil(t, commit.GetByIndex(0))
+	_, err = CommitToVoteSet("test_chain_id", commit, valSet)
+	assert.Error(t, err)
+
+	// The same precommits without aggregation are.
+	plain := voteSet.MakeCommit()
+	assert.NotNil(t, plain.GetByIndex(0))
+	reconstructed, err := CommitToVoteSet("test_chain_id", plain, valSet)
+	require.NoError(t, err)
+	assert.True(t, reconstructed.HasTwoThirdsMajority())
+
+	// Precommits without BLS signatures can't be aggregated.
+	voteSet, _, privVals = randVoteSet(2, 1, kproto.PrecommitType, 4, 1)
+	_, err = MakeCommit(blockID, 2, 1, voteSet, privVals, time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, voteSet.MakeAggregatedCommit())
+}
+
 func CreateNewCommit() *Commit {
 	block := CreateNewBlockWithTwoVotes(1)
 	block.lastCommit.BlockID = createBlockIDRandom()
@@ -20,9 +20,11 @@ package types
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
 	"time"
 
@@ -38,9 +40,12 @@ type EvidenceType uint8
 
 // EvidenceType
 const (
-	EvidenceDuplicateVote       = EvidenceType(0x01)
-	EvidenceMock                = EvidenceType(0x02)
-	MaxEvidenceBytesDenominator = 10
+	EvidenceDuplicateVote         = EvidenceType(0x01)
+	EvidenceMock                  = EvidenceType(0x02)
+	EvidenceLightClientAttack     = EvidenceType(0x03)
+	EvidenceConflictingBridgeVote = EvidenceType(0x04)
+	EvidenceUnbackedRelease       = EvidenceType(0x05)
+	MaxEvidenceBytesDenominator   = 10
 	// MaxEvidenceBytes is a maximum size of any evidence
 	MaxEvidenceBytes int64 = 484
 )
@@ -85,6 +90,31 @@ func EvidenceToProto(evidence Evidence) (*kproto.Evidence, error) {
 			},
 		}, nil
 
+	case *LightClientAttackEvidence:
+		pbev, err := evi.ToProto()
+		if err != nil {
+			return nil, err
+		}
+		return &kproto.Evidence{
+			Sum: &kproto.Evidence_LightClientAttackEvidence{
+				LightClientAttackEvidence: pbev,
+			},
+		}, nil
+
+	case *ConflictingBridgeVoteEvidence:
+		return &kproto.Evidence{
+			Sum: &kproto.Evidence_ConflictingBridgeVoteEvidence{
+				ConflictingBridgeVoteEvidence: evi.ToProto(),
+			},
+		}, nil
+
+	case *UnbackedReleaseEvidence:
+		return &kproto.Evidence{
+			Sum: &kproto.Evidence_UnbackedReleaseEvidence{
+				UnbackedReleaseEvidence: evi.ToProto(),
+			},
+		}, nil
+
 	default:
 		return nil, fmt.Errorf("toproto: evidence is not recognized: %T", evi)
 	}
@@ -100,6 +130,12 @@ func EvidenceFromProto(evidence *kproto.Evidence) (Evidence, error) {
 	switch evi := evidence.Sum.(type) {
 	case *kproto.Evidence_DuplicateVoteEvidence:
 		return DuplicateVoteEvidenceFromProto(evi.DuplicateVoteEvidence)
+	case *kproto.Evidence_LightClientAttackEvidence:
+		return LightClientAttackEvidenceFromProto(evi.LightClientAttackEvidence)
+	case *kproto.Evidence_ConflictingBridgeVoteEvidence:
+		return ConflictingBridgeVoteEvidenceFromProto(evi.ConflictingBridgeVoteEvidence)
+	case *kproto.Evidence_UnbackedReleaseEvidence:
+		return UnbackedReleaseEvidenceFromProto(evi.UnbackedReleaseEvidence)
 	default:
 		return nil, errors.New("evidence is not recognized")
 	}
@@ -316,6 +352,237 @@ func DuplicateVoteEvidenceFromProto(pb *kproto.DuplicateVoteEvidence) (*Duplicat
 	return dve, dve.ValidateBasic()
 }
 
+//-------------------------------------------
+
+// LightClientAttackEvidence contains evidence that a set of validators signed
+// a header conflicting with the chain, which a light client could be misled
+// into trusting. The conflicting header is either invalid at its height
+// (lunatic attack), or valid but committed in the same round as the block of
+// the chain (equivocation) or in another round (amnesia).
+type LightClientAttackEvidence struct {
+	ConflictingHeader     *Header
+	ConflictingCommit     *Commit
+	ConflictingValidators *ValidatorSet
+	// CommonHeight is the last height at which the conflicting header and
+	// the chain share the same validators.
+	CommonHeight uint64
+
+	ByzantineValidators []*Validator // validators of the common height who signed the conflicting header
+	TotalVotingPower    int64        // total voting power of the validators of the common height
+	Timestamp           time.Time    // time of the block at the common height
+}
+
+// String returns a string representation of the evidence.
+func (l *LightClientAttackEvidence) String() string {
+	return fmt.Sprintf("LightClientAttackEvidence{ConflictingHeader: %v, CommonHeight: %d}",
+		l.ConflictingHeader.Hash().Hex(), l.CommonHeight)
+}
+
+// Height returns the common height of the evidence, whose validators are
+// punished.
+func (l *LightClientAttackEvidence) Height() uint64 {
+	return l.CommonHeight
+}
+
+// Time returns the time of the block at the common height.
+func (l *LightClientAttackEvidence) Time() time.Time {
+	return l.Timestamp
+}
+
+// Bytes returns the proto-encoded evidence as a byte array.
+func (l *LightClientAttackEvidence) Bytes() []byte {
+	pbe, err := l.ToProto()
+	if err != nil {
+		panic(err)
+	}
+	bz, err := pbe.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// Hash returns the hash of the conflicting header and the common height, so
+// that evidence of the same attack gathered from different peers is equal.
+func (l *LightClientAttackEvidence) Hash() common.Hash {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, l.CommonHeight)
+	return hash(append(l.ConflictingHeader.Hash().Bytes(), buf[:n]...))
+}
+
+// VM returns an evidence for each byzantine validator.
+func (l *LightClientAttackEvidence) VM() []types.Evidence {
+	evs := make([]types.Evidence, len(l.ByzantineValidators))
+	for i, val := range l.ByzantineValidators {
+		evs[i] = types.Evidence{
+			Address:          val.Address,
+			Height:           l.CommonHeight,
+			Time:             l.Timestamp,
+			TotalVotingPower: uint64(l.TotalVotingPower),
+			VotingPower:      big.NewInt(val.VotingPower),
+		}
+	}
+	return evs
+}
+
+// ConflictingHeaderIsInvalid returns true if the conflicting header differs
+// from the trusted header of the same height in fields derived from the
+// previous block, which can only be the case of a lunatic attack.
+func (l *LightClientAttackEvidence) ConflictingHeaderIsInvalid(trustedHeader *Header) bool {
+	return !trustedHeader.ValidatorsHash.Equal(l.ConflictingHeader.ValidatorsHash) ||
+		!trustedHeader.NextValidatorsHash.Equal(l.ConflictingHeader.NextValidatorsHash) ||
+		!trustedHeader.ConsensusHash.Equal(l.ConflictingHeader.ConsensusHash) ||
+		!trustedHeader.AppHash.Equal(l.ConflictingHeader.AppHash)
+}
+
+// GetByzantineValidators finds the validators of commonVals who signed the
+// conflicting header, given the trusted header and commit of the same
+// height. In the case of a lunatic attack, those are all the validators of
+// commonVals who signed the conflicting commit. In the case of an
+// equivocation, those are the validators who signed both commits. In the case
+// of an amnesia attack, validators can't be told apart and none is returned.
+// The validators are sorted by voting power.
+func (l *LightClientAttackEvidence) GetByzantineValidators(commonVals *ValidatorSet,
+	trustedHeader *Header, trustedCommit *Commit) []*Validator {
+	var validators []*Validator
+	switch {
+	case l.ConflictingHeaderIsInvalid(trustedHeader):
+		for idx, commitSig := range l.ConflictingCommit.Signatures {
+			if !commitSig.ForBlock() {
+				continue
+			}
+			_, val := commonVals.GetByAddress(l.ConflictingValidators.Validators[idx].Address)
+			if val == nil {
+				continue
+			}
+			validators = append(validators, val)
+		}
+	case trustedCommit != nil && trustedCommit.Round == l.ConflictingCommit.Round:
+		// Both headers are valid, the validators of the height signed both.
+		for idx, commitSig := range l.ConflictingCommit.Signatures {
+			if !commitSig.ForBlock() || idx >= len(trustedCommit.Signatures) ||
+				!trustedCommit.Signatures[idx].ForBlock() {
+				continue
+			}
+			_, val := commonVals.GetByAddress(l.ConflictingValidators.Validators[idx].Address)
+			if val == nil {
+				continue
+			}
+			validators = append(validators, val)
+		}
+	}
+	sort.Sort(ValidatorsByVotingPower(validators))
+	return validators
+}
+
+// ValidateBasic performs basic validation.
+func (l *LightClientAttackEvidence) ValidateBasic() error {
+	if l == nil {
+		return errors.New("empty light client attack evidence")
+	}
+	if l.ConflictingHeader == nil {
+		return errors.New("conflicting header is missing")
+	}
+	if err := l.ConflictingHeader.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid conflicting header: %w", err)
+	}
+	if l.ConflictingCommit == nil {
+		return errors.New("conflicting commit is missing")
+	}
+	if err := l.ConflictingCommit.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid conflicting commit: %w", err)
+	}
+	if l.ConflictingCommit.Height != l.ConflictingHeader.Height {
+		return fmt.Errorf("conflicting header and commit height mismatch: %d vs %d",
+			l.ConflictingHeader.Height, l.ConflictingCommit.Height)
+	}
+	if hash := l.ConflictingHeader.Hash(); !l.ConflictingCommit.BlockID.Hash.Equal(hash) {
+		return fmt.Errorf("conflicting commit signs block %v, conflicting header is block %v",
+			l.ConflictingCommit.BlockID.Hash.Hex(), hash.Hex())
+	}
+	if l.ConflictingValidators.IsNilOrEmpty() {
+		return errors.New("conflicting validators are missing")
+	}
+	if hash := l.ConflictingValidators.Hash(); !l.ConflictingHeader.ValidatorsHash.Equal(hash) {
+		return fmt.Errorf("conflicting validators hash %v does not match conflicting header validators hash %v",
+			hash.Hex(), l.ConflictingHeader.ValidatorsHash.Hex())
+	}
+	if l.CommonHeight == 0 || l.CommonHeight > l.ConflictingHeader.Height {
+		return fmt.Errorf("invalid common height %d for conflicting header at height %d",
+			l.CommonHeight, l.ConflictingHeader.Height)
+	}
+	if l.TotalVotingPower <= 0 {
+		return errors.New("negative or zero total voting power")
+	}
+	return nil
+}
+
+// ToProto encodes LightClientAttackEvidence to protobuf
+func (l *LightClientAttackEvidence) ToProto() (*kproto.LightClientAttackEvidence, error) {
+	conflictingVals, err := l.ConflictingValidators.ToProto()
+	if err != nil {
+		return nil, err
+	}
+	byzVals := make([]*kproto.Validator, len(l.ByzantineValidators))
+	for i, val := range l.ByzantineValidators {
+		if byzVals[i], err = val.ToProto(); err != nil {
+			return nil, err
+		}
+	}
+	return &kproto.LightClientAttackEvidence{
+		ConflictingHeader: &kproto.SignedHeader{
+			Header: l.ConflictingHeader.ToProto(),
+			Commit: l.ConflictingCommit.ToProto(),
+		},
+		ConflictingValidators: conflictingVals,
+		CommonHeight:          l.CommonHeight,
+		ByzantineValidators:   byzVals,
+		TotalVotingPower:      l.TotalVotingPower,
+		Timestamp:             l.Timestamp,
+	}, nil
+}
+
+// LightClientAttackEvidenceFromProto decodes protobuf into LightClientAttackEvidence
+func LightClientAttackEvidenceFromProto(pb *kproto.LightClientAttackEvidence) (*LightClientAttackEvidence, error) {
+	if pb == nil {
+		return nil, errors.New("nil light client attack evidence")
+	}
+	if pb.ConflictingHeader == nil {
+		return nil, errors.New("nil conflicting header")
+	}
+
+	header, err := HeaderFromProto(pb.ConflictingHeader.Header)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := CommitFromProto(pb.ConflictingHeader.Commit)
+	if err != nil {
+		return nil, err
+	}
+	vals, err := ValidatorSetFromProto(pb.ConflictingValidators)
+	if err != nil {
+		return nil, err
+	}
+	byzVals := make([]*Validator, len(pb.ByzantineValidators))
+	for i, vp := range pb.ByzantineValidators {
+		if byzVals[i], err = ValidatorFromProto(vp); err != nil {
+			return nil, err
+		}
+	}
+
+	l := &LightClientAttackEvidence{
+		ConflictingHeader:     &header,
+		ConflictingCommit:     commit,
+		ConflictingValidators: vals,
+		CommonHeight:          pb.CommonHeight,
+		ByzantineValidators:   byzVals,
+		TotalVotingPower:      pb.TotalVotingPower,
+		Timestamp:             pb.Timestamp,
+	}
+
+	return l, l.ValidateBasic()
+}
+
 //-------------------------------------------- MOCKING --------------------------------------
 
 // unstable - use only for testing
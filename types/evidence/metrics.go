@@ -0,0 +1,41 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package evidence
+
+import (
+	"github.com/kardiachain/go-kardia/lib/metrics"
+)
+
+var (
+	MetricPending   = "pending"
+	MetricAdded     = "added"
+	MetricCommitted = "committed"
+	MetricExpired   = "expired"
+	MetricRejected  = "rejected"
+)
+
+// Setup metrics
+var (
+	pendingGauge = metrics.NewRegisteredGauge(MetricPending, metrics.EvidenceRegistry)
+
+	addedMeter     = metrics.NewRegisteredMeter(MetricAdded, metrics.EvidenceRegistry)     // Evidence verified and added to the pending list
+	committedMeter = metrics.NewRegisteredMeter(MetricCommitted, metrics.EvidenceRegistry) // Evidence included in committed blocks
+	expiredMeter   = metrics.NewRegisteredMeter(MetricExpired, metrics.EvidenceRegistry)   // Pending evidence pruned before being committed
+	rejectedMeter  = metrics.NewRegisteredMeter(MetricRejected, metrics.EvidenceRegistry)  // Evidence failing verification
+)
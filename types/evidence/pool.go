@@ -19,8 +19,12 @@
 package evidence
 
 import (
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"runtime"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -37,11 +41,23 @@ import (
 	"github.com/kardiachain/go-kardia/types"
 )
 
+// The pending and committed evidence are stored in the namespaces of these
+// prefixes, keyed by height and hash.
 const (
 	baseKeyCommitted = "evidence-committed"
 	baseKeyPending   = "evidence-pending"
 )
 
+// KeyPrefixes returns the prefixes of the keys of the committed and pending
+// evidence.
+func KeyPrefixes() [][]byte {
+	return [][]byte{[]byte(baseKeyCommitted), []byte(baseKeyPending)}
+}
+
+// checkEvidenceWorkers bounds the number of evidence verified concurrently
+// by CheckEvidence.
+var checkEvidenceWorkers = runtime.NumCPU()
+
 // Pool maintains a pool of valid evidence
 // in an Store.
 type Pool struct {
@@ -53,7 +69,8 @@ type Pool struct {
 	// needed to load validators to verify evidence
 	blockStore BlockStore
 	stateDB    cstate.Store
-	evidenceDB kaidb.Database
+	pending    *kaidb.PrefixStore
+	committed  *kaidb.PrefixStore
 
 	// latest state
 	mtx   sync.Mutex
@@ -61,28 +78,47 @@ type Pool struct {
 
 	pruningHeight uint64
 	pruningTime   time.Time
+
+	// number of blocks the committed evidence is kept for, 0 to keep all
+	committedRetainBlocks uint64
+
+	// checks deposits of bridge evidence, nil if the node doesn't run a dual node
+	bridgeVerifier BridgeVerifier
 }
 
 // NewPool creates an evidence pool. If using an existing evidence store,
 // it will add all pending evidence to the concurrent list.
 func NewPool(stateDB cstate.Store, evidenceDB kaidb.Database, blockStore BlockStore) (*Pool, error) {
+	state, err := stateDB.Load()
+	if err != nil {
+		return nil, err
+	}
 	evpool := &Pool{
 		stateDB:      stateDB,
-		state:        stateDB.Load(),
+		state:        state,
 		logger:       log.New(),
 		evidenceList: clist.New(),
 		blockStore:   blockStore,
-		evidenceDB:   evidenceDB,
+		pending:      kaidb.NewPrefixStore(evidenceDB, []byte(baseKeyPending)),
+		committed:    kaidb.NewPrefixStore(evidenceDB, []byte(baseKeyCommitted)),
+	}
+
+	// rewrite the keys of the evidence stored before the heights were encoded big endian
+	for _, store := range []*kaidb.PrefixStore{evpool.pending, evpool.committed} {
+		if err := evpool.migrateLegacyKeys(store); err != nil {
+			return nil, fmt.Errorf("can't migrate evidence keys: %w", err)
+		}
 	}
 
 	// if pending evidence already in db, in event of prior failure, then check for expiration,
 	// update the size and load it back to the evidenceList
 	evpool.pruningHeight, evpool.pruningTime = evpool.removeExpiredPendingEvidence()
-	evList, _, err := evpool.listEvidence([]byte(baseKeyPending), -1)
+	evList, _, err := evpool.listEvidence(evpool.pending, -1)
 	if err != nil {
 		return nil, err
 	}
 	atomic.StoreUint32(&evpool.evidenceSize, uint32(len(evList)))
+	pendingGauge.Update(int64(len(evList)))
 	for _, ev := range evList {
 		evpool.evidenceList.PushBack(ev)
 	}
@@ -95,7 +131,7 @@ func (evpool *Pool) PendingEvidence(maxBytes int64) ([]types.Evidence, int64) {
 	if evpool.Size() == 0 {
 		return nil, 0
 	}
-	evidence, size, err := evpool.listEvidence([]byte(baseKeyPending), maxBytes)
+	evidence, size, err := evpool.listEvidence(evpool.pending, maxBytes)
 	if err != nil {
 		evpool.logger.Error("Unable to retrieve pending evidence", "err", err)
 	}
@@ -125,11 +161,53 @@ func (evpool *Pool) Update(state cstate.LatestBlockState, ev types.EvidenceList)
 		state.LastBlockTime.After(evpool.pruningTime) {
 		evpool.pruningHeight, evpool.pruningTime = evpool.removeExpiredPendingEvidence()
 	}
+
+	// prune the committed evidence falling out of the retention window
+	if retain := evpool.committedRetainBlocks; retain > 0 && state.LastBlockHeight > retain {
+		if err := evpool.Prune(state.LastBlockHeight - retain); err != nil {
+			evpool.logger.Debug("Unable to prune committed evidence", "err", err)
+		}
+	}
+}
+
+// SetCommittedRetention sets the number of blocks the committed evidence is
+// kept for, the older one is pruned as blocks are committed. 0 keeps all.
+func (evpool *Pool) SetCommittedRetention(blocks uint64) {
+	evpool.committedRetainBlocks = blocks
+}
+
+// SetBridgeVerifier sets the verifier of the deposits of bridge evidence.
+// Without it, evidence which can't be verified from the proposals alone is
+// rejected.
+func (evpool *Pool) SetBridgeVerifier(verifier BridgeVerifier) {
+	evpool.bridgeVerifier = verifier
+}
+
+// Prune deletes the committed evidence below the given height. The evidence
+// just below that height must have expired, otherwise the committed evidence
+// is still needed to reject it from being proposed again.
+func (evpool *Pool) Prune(height uint64) error {
+	if height == 0 {
+		return nil
+	}
+	blockMeta := evpool.blockStore.LoadBlockMeta(height - 1)
+	if blockMeta == nil {
+		return fmt.Errorf("don't have header at height #%d", height-1)
+	}
+	if !evpool.isExpired(height-1, blockMeta.Header.Time) {
+		return fmt.Errorf("evidence at height #%d hasn't expired yet", height-1)
+	}
+
+	if err := evpool.committed.DeleteRange(nil, heightKey(height)); err != nil {
+		return fmt.Errorf("can't delete committed evidence: %w", err)
+	}
+	return nil
 }
 
 // markEvidenceAsCommitted processes all the evidence in the block, marking it as
 // committed and removing it from the pending database.
 func (evpool *Pool) markEvidenceAsCommitted(evidence types.EvidenceList) {
+	committedMeter.Mark(int64(len(evidence)))
 	blockEvidenceMap := make(map[string]struct{}, len(evidence))
 	for _, ev := range evidence {
 		if evpool.isPending(ev) {
@@ -139,7 +217,7 @@ func (evpool *Pool) markEvidenceAsCommitted(evidence types.EvidenceList) {
 
 		// Add evidence to the committed list. As the evidence is stored in the block store
 		// we only need to record the height that it was saved at.
-		key := keyCommitted(ev)
+		key := evidenceKey(ev)
 
 		h := gogotypes.UInt64Value{Value: ev.Height()}
 		evBytes, err := proto.Marshal(&h)
@@ -148,7 +226,7 @@ func (evpool *Pool) markEvidenceAsCommitted(evidence types.EvidenceList) {
 			continue
 		}
 
-		if err := evpool.evidenceDB.Put(key, evBytes); err != nil {
+		if err := evpool.committed.Put(key, evBytes); err != nil {
 			evpool.logger.Error("Unable to save committed evidence", "err", err, "key(height/hash)", key)
 		}
 	}
@@ -163,6 +241,32 @@ func (evpool *Pool) Size() uint32 {
 	return atomic.LoadUint32(&evpool.evidenceSize)
 }
 
+// Stats describes the pending evidence of the pool.
+type Stats struct {
+	Pending      uint32    `json:"pending"`
+	Bytes        int64     `json:"bytes"`        // size of the pending evidence when proposed in a block
+	OldestHeight uint64    `json:"oldestHeight"` // height of the oldest pending evidence, or 0 if none
+	OldestTime   time.Time `json:"oldestTime"`   // time of the oldest pending evidence
+}
+
+// Stats returns the number, size and age of the pending evidence.
+func (evpool *Pool) Stats() (Stats, error) {
+	evidence, size, err := evpool.listEvidence(evpool.pending, -1)
+	if err != nil {
+		return Stats{}, err
+	}
+	stats := Stats{
+		Pending: uint32(len(evidence)),
+		Bytes:   size,
+	}
+	// Pending evidence is listed by height, from oldest to newest.
+	if len(evidence) != 0 {
+		stats.OldestHeight = evidence[0].Height()
+		stats.OldestTime = evidence[0].Time()
+	}
+	return stats, nil
+}
+
 // IsExpired checks whether evidence or a polc is expired by checking whether a height and time is older
 // than set by the evidence consensus parameters
 func (evpool *Pool) isExpired(height uint64, time time.Time) bool {
@@ -177,8 +281,7 @@ func (evpool *Pool) isExpired(height uint64, time time.Time) bool {
 
 // IsPending checks whether the evidence is already pending. DB errors are passed to the logger.
 func (evpool *Pool) isPending(evidence types.Evidence) bool {
-	key := keyPending(evidence)
-	ok, err := evpool.evidenceDB.Has(key)
+	ok, err := evpool.pending.Has(evidenceKey(evidence))
 	if err != nil {
 		evpool.logger.Error("Unable to find pending evidence", "err", err)
 	}
@@ -187,8 +290,7 @@ func (evpool *Pool) isPending(evidence types.Evidence) bool {
 
 // IsCommitted returns true if we have already seen this exact evidence and it is already marked as committed.
 func (evpool *Pool) isCommitted(evidence types.Evidence) bool {
-	key := keyCommitted(evidence)
-	ok, err := evpool.evidenceDB.Has(key)
+	ok, err := evpool.committed.Has(evidenceKey(evidence))
 	if err != nil {
 		evpool.logger.Error("Unable to find committed evidence", "err", err)
 	}
@@ -237,23 +339,29 @@ func (evpool *Pool) removeEvidenceFromList(
 }
 
 func (evpool *Pool) removePendingEvidence(evidence types.Evidence) {
-	key := keyPending(evidence)
-	if err := evpool.evidenceDB.Delete(key); err != nil {
+	if err := evpool.pending.Delete(evidenceKey(evidence)); err != nil {
 		evpool.logger.Error("Unable to delete pending evidence", "err", err)
 	} else {
-		atomic.AddUint32(&evpool.evidenceSize, ^uint32(0))
+		pendingGauge.Update(int64(atomic.AddUint32(&evpool.evidenceSize, ^uint32(0))))
 		evpool.logger.Info("Deleted pending evidence", "evidence", evidence)
 	}
 }
 
-// listEvidence retrieves lists evidence from oldest to newest within maxBytes.
-// If maxBytes is -1, there's no cap on the size of returned evidence.
-func (evpool *Pool) listEvidence(prefixKey []byte, maxBytes int64) ([]types.Evidence, int64, error) {
+// listEvidence retrieves lists evidence from oldest to newest within maxBytes,
+// the keys being ordered by height (see heightKey). If maxBytes is -1, there's no cap on the size of returned evidence. The
+// evidence is read from a snapshot, unaffected by concurrent writes.
+func (evpool *Pool) listEvidence(store *kaidb.PrefixStore, maxBytes int64) ([]types.Evidence, int64, error) {
 	var evidence []types.Evidence
 	var evList kproto.EvidenceData // used for calculating the bytes size
 	var evSize int64
 	var totalSize int64
-	iter := evpool.evidenceDB.NewIterator(prefixKey, nil)
+	snap, err := store.NewSnapshot()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer snap.Release()
+	iter := snap.NewIterator(nil, nil)
+	defer iter.Release()
 	for iter.Next() {
 		var evp kproto.Evidence
 		if err := evp.Unmarshal(iter.Value()); err != nil {
@@ -276,12 +384,24 @@ func (evpool *Pool) listEvidence(prefixKey []byte, maxBytes int64) ([]types.Evid
 		totalSize = evSize
 		evidence = append(evidence, ev)
 	}
+	if err := iter.Error(); err != nil {
+		return evidence, totalSize, err
+	}
 	return evidence, totalSize, nil
 }
 
+// removeExpiredPendingEvidence removes the expired pending evidence and returns
+// the height and time at which the oldest remaining one expires. Pending
+// evidence is keyed by height, the expired evidence is the range of keys before
+// the first unexpired one and is deleted at once.
 func (evpool *Pool) removeExpiredPendingEvidence() (uint64, time.Time) {
-	iter := evpool.evidenceDB.NewIterator([]byte(baseKeyPending), nil)
-	blockEvidenceMap := make(map[string]struct{})
+	var (
+		limit            []byte // up to the last key if nil
+		pruneHeight      = evpool.State().LastBlockHeight
+		pruneTime        = evpool.State().LastBlockTime
+		blockEvidenceMap = make(map[string]struct{})
+	)
+	iter := evpool.pending.NewIterator(nil, nil)
 	for iter.Next() {
 		ev, err := bytesToEv(iter.Value())
 		if err != nil {
@@ -290,22 +410,34 @@ func (evpool *Pool) removeExpiredPendingEvidence() (uint64, time.Time) {
 		}
 
 		if !evpool.isExpired(ev.Height(), ev.Time()) {
-			if len(blockEvidenceMap) != 0 {
-				evpool.removeEvidenceFromList(blockEvidenceMap)
-			}
+			limit = common.CopyBytes(iter.Key())
 			maxAgeNumBlocks := evpool.State().ConsensusParams.Evidence.MaxAgeNumBlocks
-			// return the height and time with which this evidence will have expired so we know when to prune next
-			return ev.Height() + uint64(maxAgeNumBlocks) + 1,
-				ev.Time().Add(evpool.State().ConsensusParams.Evidence.MaxAgeDuration).Add(time.Second)
+			// the height and time with which this evidence will have expired so we know when to prune next
+			pruneHeight = ev.Height() + uint64(maxAgeNumBlocks) + 1
+			pruneTime = ev.Time().Add(evpool.State().ConsensusParams.Evidence.MaxAgeDuration).Add(time.Second)
+			break
 		}
-		evpool.removePendingEvidence(ev)
 		blockEvidenceMap[evMapKey(ev)] = struct{}{}
 	}
-	// We either have no pending evidence or all evidence has expired
+	err := iter.Error()
+	iter.Release()
+	if err != nil {
+		evpool.logger.Error("Unable to iterate pending evidence", "err", err)
+		return pruneHeight, pruneTime
+	}
+
+	// We either have no expired pending evidence or delete all of it at once
 	if len(blockEvidenceMap) != 0 {
+		if err := evpool.pending.DeleteRange(nil, limit); err != nil {
+			evpool.logger.Error("Unable to delete expired pending evidence", "err", err)
+			return pruneHeight, pruneTime
+		}
+		pendingGauge.Update(int64(atomic.AddUint32(&evpool.evidenceSize, ^uint32(len(blockEvidenceMap)-1))))
+		expiredMeter.Mark(int64(len(blockEvidenceMap)))
+		evpool.logger.Info("Deleted expired pending evidence", "count", len(blockEvidenceMap))
 		evpool.removeEvidenceFromList(blockEvidenceMap)
 	}
-	return evpool.State().LastBlockHeight, evpool.State().LastBlockTime
+	return pruneHeight, pruneTime
 }
 
 // AddEvidence checks the evidence is valid and adds it to the pool.
@@ -327,6 +459,7 @@ func (evpool *Pool) AddEvidence(ev types.Evidence) error {
 	}
 
 	if err := evpool.verify(ev); err != nil {
+		rejectedMeter.Mark(1)
 		return types.NewErrInvalidEvidence(ev, err)
 	}
 
@@ -367,41 +500,100 @@ func (evpool *Pool) AddEvidenceFromConsensus(ev types.Evidence) error {
 // evidence has already been committed or is being proposed twice. It also adds any
 // evidence that it doesn't currently have so that it can quickly form ABCI Evidence later.
 func (evpool *Pool) CheckEvidence(evList types.EvidenceList) error {
-	hashes := make([]common.Hash, len(evList))
-	for idx, ev := range evList {
-		ok := evpool.fastCheck(ev)
-
-		if !ok {
-			// check that the evidence isn't already committed
-			if evpool.isCommitted(ev) {
-				return types.NewErrInvalidEvidence(ev, errors.New("evidence was already committed"))
-			}
+	var (
+		hashes   = make(map[common.Hash]struct{}, len(evList))
+		toVerify = make([]types.Evidence, 0, len(evList))
+	)
+	for _, ev := range evList {
+		// check for duplicate evidence
+		hash := ev.Hash()
+		if _, ok := hashes[hash]; ok {
+			return types.NewErrInvalidEvidence(ev, errors.New("duplicate evidence"))
+		}
+		hashes[hash] = struct{}{}
 
-			if err := evpool.verify(ev); err != nil {
-				return types.NewErrInvalidEvidence(ev, err)
-			}
+		if evpool.fastCheck(ev) {
+			continue
+		}
+		// check that the evidence isn't already committed
+		if evpool.isCommitted(ev) {
+			return types.NewErrInvalidEvidence(ev, errors.New("evidence was already committed"))
+		}
+		toVerify = append(toVerify, ev)
+	}
+	if len(toVerify) == 0 {
+		return nil
+	}
 
-			if err := evpool.addPendingEvidence(ev); err != nil {
-				// Something went wrong with adding the evidence but we already know it is valid
-				// hence we log an error and continue
-				evpool.logger.Error("Can't add evidence to pending list", "err", err, "ev", ev)
-			}
+	// verify the evidence we haven't seen before concurrently, each piece is
+	// independent of the others
+	var (
+		errs = make([]error, len(toVerify))
+		sem  = make(chan struct{}, checkEvidenceWorkers)
+		wg   sync.WaitGroup
+	)
+	for i, ev := range toVerify {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, ev types.Evidence) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = evpool.verify(ev)
+		}(i, ev)
+	}
+	wg.Wait()
 
-			evpool.logger.Info("Verified new evidence of byzantine behavior", "evidence", ev)
+	// persist all the valid evidence in one batch, even if some other piece
+	// is invalid we already know these are valid
+	var (
+		batch    = evpool.pending.NewBatch()
+		verified []types.Evidence
+		firstErr error
+	)
+	for i, ev := range toVerify {
+		if errs[i] != nil {
+			rejectedMeter.Mark(1)
+			if firstErr == nil {
+				firstErr = types.NewErrInvalidEvidence(ev, errs[i])
+			}
+			continue
 		}
-
-		// check for duplicate evidence. We cache hashes so we don't have to work them out again.
-		hashes[idx] = ev.Hash()
-		for i := idx - 1; i >= 0; i-- {
-			if hashes[i].Equal(hashes[idx]) {
-				return types.NewErrInvalidEvidence(ev, errors.New("duplicate evidence"))
+		if err := putPendingEvidence(batch, ev); err != nil {
+			// Something went wrong with adding the evidence but we already know it is valid
+			// hence we log an error and continue
+			evpool.logger.Error("Can't add evidence to pending list", "err", err, "ev", ev)
+			continue
+		}
+		verified = append(verified, ev)
+	}
+	if len(verified) > 0 {
+		if err := batch.Write(); err != nil {
+			evpool.logger.Error("Can't persist pending evidence", "err", err, "count", len(verified))
+		} else {
+			pendingGauge.Update(int64(atomic.AddUint32(&evpool.evidenceSize, uint32(len(verified)))))
+			addedMeter.Mark(int64(len(verified)))
+			for _, ev := range verified {
+				evpool.logger.Info("Verified new evidence of byzantine behavior", "evidence", ev)
 			}
 		}
 	}
-	return nil
+	return firstErr
 }
 
 func (evpool *Pool) addPendingEvidence(ev types.Evidence) error {
+	if err := putPendingEvidence(evpool.pending, ev); err != nil {
+		return err
+	}
+	pendingGauge.Update(int64(atomic.AddUint32(&evpool.evidenceSize, 1)))
+	addedMeter.Mark(1)
+	return nil
+}
+
+// putPendingEvidence writes the evidence to either the pending evidence
+// namespace or a batch of it.
+func putPendingEvidence(w kaidb.KeyValueWriter, ev types.Evidence) error {
 	evpb, err := types.EvidenceToProto(ev)
 	if err != nil {
 		return fmt.Errorf("unable to convert to proto, err: %w", err)
@@ -412,13 +604,9 @@ func (evpool *Pool) addPendingEvidence(ev types.Evidence) error {
 		return fmt.Errorf("unable to marshal evidence: %w", err)
 	}
 
-	key := keyPending(ev)
-
-	err = evpool.evidenceDB.Put(key, evBytes)
-	if err != nil {
+	if err := w.Put(evidenceKey(ev), evBytes); err != nil {
 		return fmt.Errorf("can't persist evidence: %w", err)
 	}
-	atomic.AddUint32(&evpool.evidenceSize, 1)
 	return nil
 }
 
@@ -443,19 +631,66 @@ func evMapKey(ev types.Evidence) string {
 	return ev.Hash().String()
 }
 
-// big endian padded hex
-func bE(h uint64) string {
-	return fmt.Sprintf("%0.16X", h)
+// heightKey encodes the height big endian on a fixed 8 bytes, so that the
+// byte-wise order of the keys, which every kaidb backend iterates in, is the
+// order of the heights and the evidence is listed from oldest to newest.
+func heightKey(height uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, height)
+	return key
 }
 
-func keyCommitted(evidence types.Evidence) []byte {
-	return append([]byte(baseKeyCommitted), keySuffix(evidence)...)
+// evidenceKey returns the key of the evidence in the pending and committed
+// namespaces.
+func evidenceKey(evidence types.Evidence) []byte {
+	hash := evidence.Hash()
+	return append(heightKey(evidence.Height()), hash[:]...)
 }
 
-func keyPending(evidence types.Evidence) []byte {
-	return append([]byte(baseKeyPending), keySuffix(evidence)...)
-}
+// legacyKeyLength is the length of the keys formatted as the height in 16 hex
+// digits, a slash and the hex hash, as stored by former versions.
+const legacyKeyLength = 16 + 1 + 2*common.HashLength
 
-func keySuffix(evidence types.Evidence) []byte {
-	return []byte(fmt.Sprintf("%s/%X", bE(evidence.Height()), evidence.Hash()))
+// migrateLegacyKeys rewrites the keys of a namespace that are in the hex
+// format of former versions to the big endian one.
+func (evpool *Pool) migrateLegacyKeys(store *kaidb.PrefixStore) error {
+	var (
+		batch = store.NewBatch()
+		count int
+	)
+	iter := store.NewIterator(nil, nil)
+	for iter.Next() {
+		legacy := iter.Key()
+		if len(legacy) != legacyKeyLength || legacy[16] != '/' {
+			continue
+		}
+		height, err := strconv.ParseUint(string(legacy[:16]), 16, 64)
+		if err != nil {
+			continue
+		}
+		hash, err := hex.DecodeString(string(legacy[17:]))
+		if err != nil {
+			continue
+		}
+		key := append(heightKey(height), hash...)
+		if err := batch.Put(key, common.CopyBytes(iter.Value())); err != nil {
+			iter.Release()
+			return err
+		}
+		if err := batch.Delete(common.CopyBytes(iter.Key())); err != nil {
+			iter.Release()
+			return err
+		}
+		count++
+	}
+	err := iter.Error()
+	iter.Release()
+	if err != nil || count == 0 {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	evpool.logger.Info("Migrated evidence keys", "prefix", string(store.Prefix()), "count", count)
+	return nil
 }
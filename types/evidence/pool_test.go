@@ -19,6 +19,9 @@
 package evidence
 
 import (
+	"bytes"
+	"fmt"
+	"sort"
 	"testing"
 	"time"
 
@@ -118,4 +121,128 @@ func TestEvidencePool(t *testing.T) {
 	err = pool.AddEvidence(goodEvidence)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, pool.evidenceList.Len())
+
+	stats, err := pool.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), stats.Pending)
+	assert.Equal(t, height, stats.OldestHeight)
+	assert.Equal(t, evidenceTime, stats.OldestTime)
+	assert.True(t, stats.Bytes > 0)
+}
+
+func TestCheckEvidence(t *testing.T) {
+	_, privVals := types.RandValidatorSet(3, 10)
+	var (
+		height       = uint64(100002)
+		chainid      = "kai"
+		stateDB      = initializeValidatorState(privVals[0], height)
+		evidenceTime = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("LoadBlockMeta", mock.AnythingOfType("uint64")).Return(
+		&types.BlockMeta{Header: &types.Header{Time: evidenceTime}},
+	)
+
+	pool, err := NewPool(stateDB, memorydb.New(), blockStore)
+	require.NoError(t, err)
+
+	evList := make(types.EvidenceList, 10)
+	for i := range evList {
+		evList[i] = types.NewMockDuplicateVoteEvidenceWithValidator(height, evidenceTime, privVals[0], chainid)
+	}
+	require.NoError(t, pool.CheckEvidence(evList))
+	assert.Equal(t, uint32(len(evList)), pool.Size())
+	for _, ev := range evList {
+		assert.True(t, pool.isPending(ev))
+	}
+
+	// already verified evidence is accepted again
+	require.NoError(t, pool.CheckEvidence(evList[:3]))
+	assert.Equal(t, uint32(len(evList)), pool.Size())
+
+	// the same evidence can't be proposed twice
+	assert.Error(t, pool.CheckEvidence(types.EvidenceList{evList[0], evList[1], evList[0]}))
+
+	// an invalid piece fails the check but the valid ones are still stored
+	goodEvidence := types.NewMockDuplicateVoteEvidenceWithValidator(height, evidenceTime, privVals[0], chainid)
+	badEvidence := types.NewMockDuplicateVoteEvidenceWithValidator(1, evidenceTime, privVals[0], chainid)
+	assert.Error(t, pool.CheckEvidence(types.EvidenceList{badEvidence, goodEvidence}))
+	assert.True(t, pool.isPending(goodEvidence))
+	assert.False(t, pool.isPending(badEvidence))
+	assert.Equal(t, uint32(len(evList)+1), pool.Size())
+}
+
+func TestEvidenceKeysOrderedByHeight(t *testing.T) {
+	_, privVals := types.RandValidatorSet(1, 10)
+	heights := []uint64{0xff, 1, 0x100, 0x1000000, 2}
+	keys := make([][]byte, len(heights))
+	for i, height := range heights {
+		keys[i] = evidenceKey(types.NewMockDuplicateVoteEvidenceWithValidator(height, defaultEvidenceTime, privVals[0], "kai"))
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	for i, key := range keys {
+		assert.Equal(t, heightKey(heights[i]), key[:8])
+	}
+}
+
+func TestMigrateLegacyKeys(t *testing.T) {
+	_, privVals := types.RandValidatorSet(3, 10)
+	var (
+		height       = uint64(100002)
+		stateDB      = initializeValidatorState(privVals[0], height)
+		evidenceDB   = memorydb.New()
+		evidenceTime = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("LoadBlockMeta", mock.AnythingOfType("uint64")).Return(
+		&types.BlockMeta{Header: &types.Header{Time: evidenceTime}},
+	)
+
+	// store evidence the way former versions did
+	ev := types.NewMockDuplicateVoteEvidenceWithValidator(height, evidenceTime, privVals[0], "kai")
+	evpb, err := types.EvidenceToProto(ev)
+	require.NoError(t, err)
+	evBytes, err := evpb.Marshal()
+	require.NoError(t, err)
+	legacyKey := []byte(fmt.Sprintf("%s%0.16X/%X", baseKeyPending, ev.Height(), ev.Hash()))
+	require.NoError(t, evidenceDB.Put(legacyKey, evBytes))
+
+	pool, err := NewPool(stateDB, evidenceDB, blockStore)
+	require.NoError(t, err)
+	assert.True(t, pool.isPending(ev))
+	assert.Equal(t, uint32(1), pool.Size())
+	ok, err := evidenceDB.Has(legacyKey)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestPruneCommittedEvidence(t *testing.T) {
+	_, privVals := types.RandValidatorSet(3, 10)
+	var (
+		height       = uint64(100002)
+		stateDB      = initializeValidatorState(privVals[0], height)
+		evidenceTime = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("LoadBlockMeta", mock.AnythingOfType("uint64")).Return(
+		&types.BlockMeta{Header: &types.Header{Time: evidenceTime}},
+	)
+
+	pool, err := NewPool(stateDB, memorydb.New(), blockStore)
+	require.NoError(t, err)
+
+	oldEv := types.NewMockDuplicateVoteEvidenceWithValidator(10, evidenceTime, privVals[0], "kai")
+	newEv := types.NewMockDuplicateVoteEvidenceWithValidator(height-1, evidenceTime, privVals[0], "kai")
+	pool.markEvidenceAsCommitted(types.EvidenceList{oldEv, newEv})
+	require.True(t, pool.isCommitted(oldEv))
+
+	// the evidence at the latest heights hasn't expired yet
+	assert.Error(t, pool.Prune(height))
+	assert.True(t, pool.isCommitted(newEv))
+
+	require.NoError(t, pool.Prune(11))
+	assert.False(t, pool.isCommitted(oldEv))
+	assert.True(t, pool.isCommitted(newEv))
 }
@@ -132,7 +132,8 @@ func TestReactorsGossipNoCommittedEvidence(t *testing.T) {
 	// DB1 is ahead of DB2
 	stateDB1 := initializeValidatorState(val, height)
 	stateDB2 := initializeValidatorState(val, height-2)
-	state := stateDB1.Load()
+	state, err := stateDB1.Load()
+	require.NoError(t, err)
 	state.LastBlockHeight++
 
 	// make reactors from statedb
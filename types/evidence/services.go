@@ -6,3 +6,14 @@ type BlockStore interface {
 	LoadBlockMeta(height uint64) *types.BlockMeta
 	LoadBlockCommit(height uint64) *types.Commit
 }
+
+// BridgeVerifier checks the deposits of bridge proposals against their source
+// chains, which evidence of bridge validators relies on. It is called
+// concurrently when verifying the evidence of a block.
+type BridgeVerifier interface {
+	// HasDeposit reports whether the deposit is in its source chain, in a
+	// block which reached the confirmation depth. An error is returned if it
+	// can't be known yet, eg: the block is not final or the chain is
+	// unreachable.
+	HasDeposit(d *types.BridgeDeposit) (bool, error)
+}
@@ -2,8 +2,10 @@ package evidence
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 
+	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/crypto"
 	"github.com/kardiachain/go-kardia/types"
 )
@@ -55,6 +57,38 @@ func (evpool *Pool) verify(evidence types.Evidence) error {
 			return err
 		}
 		return VerifyDuplicateVote(ev, state.ChainID, valSet)
+	case *types.LightClientAttackEvidence:
+		commonVals, err := evpool.stateDB.LoadValidators(ev.Height())
+		if err != nil {
+			return err
+		}
+		// A conflicting header beyond our latest height (forward lunatic
+		// attack) is checked against our latest header.
+		trustedHeight := ev.ConflictingHeader.Height
+		if trustedHeight > state.LastBlockHeight {
+			trustedHeight = state.LastBlockHeight
+		}
+		trustedMeta := evpool.blockStore.LoadBlockMeta(trustedHeight)
+		if trustedMeta == nil {
+			return fmt.Errorf("don't have header at height #%d", trustedHeight)
+		}
+		trustedCommit := evpool.blockStore.LoadBlockCommit(trustedHeight)
+		if trustedCommit == nil && trustedHeight == ev.ConflictingHeader.Height {
+			return fmt.Errorf("don't have commit at height #%d", trustedHeight)
+		}
+		return VerifyLightClientAttack(ev, state.ChainID, trustedMeta.Header, trustedCommit, commonVals)
+	case *types.ConflictingBridgeVoteEvidence:
+		valSet, err := evpool.stateDB.LoadValidators(ev.Height())
+		if err != nil {
+			return err
+		}
+		return VerifyConflictingBridgeVote(ev, valSet, evpool.bridgeVerifier)
+	case *types.UnbackedReleaseEvidence:
+		valSet, err := evpool.stateDB.LoadValidators(ev.Height())
+		if err != nil {
+			return err
+		}
+		return VerifyUnbackedRelease(ev, valSet, evpool.bridgeVerifier)
 	default:
 		return fmt.Errorf("unrecognized evidence type: %T", evidence)
 	}
@@ -119,3 +153,156 @@ func VerifyDuplicateVote(e *types.DuplicateVoteEvidence, chainID string, valSet
 
 	return nil
 }
+
+// VerifyLightClientAttack verifies LightClientAttackEvidence against the state
+// of full node. This involves the following checks:
+//      - the conflicting commit is signed by +2/3 of the conflicting validators
+//      - if the common height is below the conflicting height (lunatic attack),
+//        +1/3 of the validators of the common height signed the conflicting
+//        commit, else the conflicting header is correctly derived
+//      - the total voting power matches the validators of the common height
+//      - the conflicting header differs from the trusted header, or is beyond
+//        it but not after it in time
+//      - the byzantine validators are the ones of the common height who signed
+//        the conflicting header
+func VerifyLightClientAttack(e *types.LightClientAttackEvidence, chainID string, trustedHeader *types.Header,
+	trustedCommit *types.Commit, commonVals *types.ValidatorSet) error {
+	if err := e.ConflictingValidators.VerifyCommit(chainID, e.ConflictingCommit.BlockID,
+		e.ConflictingHeader.Height, e.ConflictingCommit); err != nil {
+		return fmt.Errorf("invalid commit from conflicting header: %w", err)
+	}
+
+	if e.CommonHeight != e.ConflictingHeader.Height {
+		if err := commonVals.VerifyCommitTrusting(e.ConflictingCommit, e.ConflictingValidators); err != nil {
+			return fmt.Errorf("skipping verification of conflicting header failed: %w", err)
+		}
+	} else if e.ConflictingHeaderIsInvalid(trustedHeader) {
+		return errors.New("common height is the same as conflicting header height so expected the conflicting" +
+			" header to be correctly derived yet it wasn't")
+	}
+
+	if e.TotalVotingPower != commonVals.TotalVotingPower() {
+		return fmt.Errorf("total voting power from the evidence and our validator set does not match (%d != %d)",
+			e.TotalVotingPower, commonVals.TotalVotingPower())
+	}
+
+	if e.ConflictingHeader.Height > trustedHeader.Height {
+		if e.ConflictingHeader.Time.After(trustedHeader.Time) {
+			return fmt.Errorf("conflicting header %d doesn't violate monotonically increasing time (%v is after %v)",
+				e.ConflictingHeader.Height, e.ConflictingHeader.Time, trustedHeader.Time)
+		}
+	} else if hash := trustedHeader.Hash(); hash.Equal(e.ConflictingHeader.Hash()) {
+		return fmt.Errorf("trusted header hash matches the conflicting header hash: %v", hash.Hex())
+	}
+
+	expected := e.GetByzantineValidators(commonVals, trustedHeader, trustedCommit)
+	if len(expected) != len(e.ByzantineValidators) {
+		return fmt.Errorf("expected %d byzantine validators, got %d", len(expected), len(e.ByzantineValidators))
+	}
+	for i, val := range expected {
+		if !val.Address.Equal(e.ByzantineValidators[i].Address) ||
+			val.VotingPower != e.ByzantineValidators[i].VotingPower {
+			return fmt.Errorf("byzantine validator #%d does not match: expected %v, got %v",
+				i, val, e.ByzantineValidators[i])
+		}
+	}
+	return nil
+}
+
+// VerifyConflictingBridgeVote verifies ConflictingBridgeVoteEvidence against the
+// state of full node. This involves the following checks:
+//      - the validator is in the validator set at the height of the evidence
+//      - the validator power and total voting power match the validator set
+//      - the votes are for the given proposals, which release a same deposit
+//      - the signatures must both be valid
+//      - if a proposal is a batch, its other deposits are in their source
+//        chain, otherwise the batch may have been cancelled by a reorg and its
+//        deposits proposed again honestly
+func VerifyConflictingBridgeVote(e *types.ConflictingBridgeVoteEvidence, valSet *types.ValidatorSet,
+	verifier BridgeVerifier) error {
+	if err := verifyBridgeValidator(e.VoteA.ValidatorAddress, e.ValidatorPower, e.TotalVotingPower, e.Height(),
+		valSet); err != nil {
+		return err
+	}
+	_, others, err := e.Deposits()
+	if err != nil {
+		return err
+	}
+	if err := e.VoteA.Verify(); err != nil {
+		return fmt.Errorf("verifying VoteA: %w", err)
+	}
+	if err := e.VoteB.Verify(); err != nil {
+		return fmt.Errorf("verifying VoteB: %w", err)
+	}
+	if len(others) == 0 {
+		return nil
+	}
+	if verifier == nil {
+		return errors.New("can't verify the deposits of batched bridge proposals without a bridge verifier")
+	}
+	for _, d := range others {
+		ok, err := verifier.HasDeposit(d)
+		if err != nil {
+			return fmt.Errorf("can't verify deposit %v %v %v: %w", d.Chain, d.TxHash, d.LogIndex, err)
+		}
+		if !ok {
+			return fmt.Errorf("deposit %v %v %v is not in its source chain, its batch may be proposed again",
+				d.Chain, d.TxHash, d.LogIndex)
+		}
+	}
+	return nil
+}
+
+// VerifyUnbackedRelease verifies UnbackedReleaseEvidence against the state of
+// full node. This involves the following checks:
+//      - the validator is in the validator set at the height of the evidence
+//      - the validator power and total voting power match the validator set
+//      - the vote is for the given proposal and its signature is valid
+//      - a deposit released by the proposal is not in its source chain, which
+//        requires a bridge verifier
+func VerifyUnbackedRelease(e *types.UnbackedReleaseEvidence, valSet *types.ValidatorSet,
+	verifier BridgeVerifier) error {
+	if err := verifyBridgeValidator(e.Vote.ValidatorAddress, e.ValidatorPower, e.TotalVotingPower, e.Height(),
+		valSet); err != nil {
+		return err
+	}
+	deposits, err := e.Deposits()
+	if err != nil {
+		return err
+	}
+	if err := e.Vote.Verify(); err != nil {
+		return err
+	}
+	if verifier == nil {
+		return errors.New("can't verify unbacked releases without a bridge verifier")
+	}
+	for _, d := range deposits {
+		ok, err := verifier.HasDeposit(d)
+		if err != nil {
+			return fmt.Errorf("can't verify deposit %v %v %v: %w", d.Chain, d.TxHash, d.LogIndex, err)
+		}
+		if !ok {
+			return nil
+		}
+	}
+	return errors.New("all the deposits of the release are in their source chain")
+}
+
+// verifyBridgeValidator checks that addr is a validator at height, with the
+// voting power of the evidence.
+func verifyBridgeValidator(addr common.Address, power, totalPower int64, height uint64,
+	valSet *types.ValidatorSet) error {
+	_, val := valSet.GetByAddress(addr)
+	if val == nil {
+		return fmt.Errorf("address %X was not a validator at height %d", addr, height)
+	}
+	if val.VotingPower != power {
+		return fmt.Errorf("validator power from evidence and our validator set does not match (%d != %d)",
+			power, val.VotingPower)
+	}
+	if valSet.TotalVotingPower() != totalPower {
+		return fmt.Errorf("total voting power from the evidence and our validator set does not match (%d != %d)",
+			totalPower, valSet.TotalVotingPower())
+	}
+	return nil
+}
@@ -116,6 +116,86 @@ func TestVerifyDuplicateVoteEvidence(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestVerifyLightClientAttack(t *testing.T) {
+	const (
+		chainID      = "mychain"
+		commonHeight = uint64(9)
+		height       = uint64(10)
+	)
+	valSet, privVals := types.RandValidatorSet(4, 10)
+
+	trustedHeader := &types.Header{
+		Height:         height,
+		Time:           defaultEvidenceTime,
+		ValidatorsHash: valSet.Hash(),
+		AppHash:        common.BytesToHash([]byte("app hash")),
+	}
+	// The conflicting header changes the app hash, a lunatic attack.
+	conflictingHeader := *trustedHeader
+	conflictingHeader.AppHash = common.BytesToHash([]byte("forged app hash"))
+	blockID := makeBlockID(conflictingHeader.Hash().Bytes(), 1, []byte("partshash"))
+	voteSet := types.NewVoteSet(chainID, height, 1, kproto.PrecommitType, valSet)
+	commit, err := types.MakeCommit(blockID, height, 1, voteSet, privVals, defaultEvidenceTime)
+	require.NoError(t, err)
+
+	ev := &types.LightClientAttackEvidence{
+		ConflictingHeader:     &conflictingHeader,
+		ConflictingCommit:     commit,
+		ConflictingValidators: valSet,
+		CommonHeight:          commonHeight,
+		TotalVotingPower:      valSet.TotalVotingPower(),
+		Timestamp:             defaultEvidenceTime,
+	}
+	ev.ByzantineValidators = ev.GetByzantineValidators(valSet, trustedHeader, nil)
+	require.Len(t, ev.ByzantineValidators, 4)
+	require.NoError(t, ev.ValidateBasic())
+
+	assert.NoError(t, VerifyLightClientAttack(ev, chainID, trustedHeader, nil, valSet))
+	assert.Error(t, VerifyLightClientAttack(ev, "mychain2", trustedHeader, nil, valSet), "wrong chain id")
+
+	// the validators of the common height didn't sign the conflicting header
+	otherVals, _ := types.RandValidatorSet(4, 10)
+	ev.TotalVotingPower = otherVals.TotalVotingPower()
+	assert.Error(t, VerifyLightClientAttack(ev, chainID, trustedHeader, nil, otherVals))
+	ev.TotalVotingPower = valSet.TotalVotingPower()
+
+	// a byzantine validator is missing
+	byzVals := ev.ByzantineValidators
+	ev.ByzantineValidators = byzVals[1:]
+	assert.Error(t, VerifyLightClientAttack(ev, chainID, trustedHeader, nil, valSet))
+	ev.ByzantineValidators = byzVals
+
+	// the total voting power doesn't match
+	ev.TotalVotingPower++
+	assert.Error(t, VerifyLightClientAttack(ev, chainID, trustedHeader, nil, valSet))
+	ev.TotalVotingPower--
+
+	// a header at the same height as the common height must be derived correctly
+	ev.CommonHeight = height
+	assert.Error(t, VerifyLightClientAttack(ev, chainID, trustedHeader, nil, valSet))
+	ev.CommonHeight = commonHeight
+
+	// the pool loads the validators of the common height and the trusted header
+	state := cstate.LatestBlockState{
+		ChainID:         chainID,
+		InitialHeight:   1,
+		LastBlockTime:   defaultEvidenceTime.Add(1 * time.Minute),
+		LastBlockHeight: 11,
+		ConsensusParams: *types.DefaultConsensusParams(),
+	}
+	stateStore := &smocks.Store{}
+	stateStore.On("LoadValidators", commonHeight).Return(valSet, nil)
+	stateStore.On("Load").Return(state, nil)
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("LoadBlockMeta", commonHeight).Return(&types.BlockMeta{Header: &types.Header{Time: defaultEvidenceTime}})
+	blockStore.On("LoadBlockMeta", height).Return(&types.BlockMeta{Header: trustedHeader})
+	blockStore.On("LoadBlockCommit", height).Return(commit)
+
+	pool, err := NewPool(stateStore, memorydb.New(), blockStore)
+	require.NoError(t, err)
+	assert.NoError(t, pool.CheckEvidence(types.EvidenceList{ev}))
+}
+
 func makeVote(
 	t *testing.T, val types.PrivValidator, chainID string, valIndex uint32, height uint64,
 	round uint32, step int, blockID types.BlockID, time time.Time) *types.Vote {
@@ -149,3 +229,68 @@ func makeBlockID(hash []byte, partSetSize uint32, partSetHash []byte) types.Bloc
 		},
 	}
 }
+
+// bridgeVerifier reports the deposits of the listed transactions as in their
+// source chain.
+type bridgeVerifier map[string]bool
+
+func (v bridgeVerifier) HasDeposit(d *types.BridgeDeposit) (bool, error) {
+	return v[d.TxHash], nil
+}
+
+func TestVerifyBridgeEvidence(t *testing.T) {
+	pv := types.NewMockPV()
+	valSet := types.NewValidatorSet([]*types.Validator{pv.ExtractIntoValidator(10)})
+	deposit := types.MockBridgeDeposit("0x01", 0, 10)
+	single := types.MockBridgeProposal("KAI", deposit)
+	other := types.MockBridgeProposal("TRX", deposit)
+	batch := types.MockBridgeProposal("TRX", types.MockBridgeDeposit("0x02", 0, 10), deposit)
+
+	// votes for two proposals of a same deposit don't need the source chain
+	conflicting := types.NewConflictingBridgeVoteEvidence(types.MockBridgeVote(pv, single),
+		types.MockBridgeVote(pv, other), single, other, 10, defaultEvidenceTime, valSet)
+	require.NoError(t, conflicting.ValidateBasic())
+	assert.NoError(t, VerifyConflictingBridgeVote(conflicting, valSet, nil))
+
+	// the powers must match our validator set
+	otherVals := types.NewValidatorSet([]*types.Validator{pv.ExtractIntoValidator(20)})
+	assert.Error(t, VerifyConflictingBridgeVote(conflicting, otherVals, nil))
+
+	// a batch may be proposed again if one of its deposits is reorganised
+	conflicting = types.NewConflictingBridgeVoteEvidence(types.MockBridgeVote(pv, single),
+		types.MockBridgeVote(pv, batch), single, batch, 10, defaultEvidenceTime, valSet)
+	assert.Error(t, VerifyConflictingBridgeVote(conflicting, valSet, nil))
+	assert.Error(t, VerifyConflictingBridgeVote(conflicting, valSet, bridgeVerifier{}))
+	assert.NoError(t, VerifyConflictingBridgeVote(conflicting, valSet, bridgeVerifier{"0x02": true}))
+
+	// a signature of another validator is rejected
+	conflicting.VoteB.Signature = types.MockBridgeVote(types.NewMockPV(), batch).Signature
+	assert.Error(t, VerifyConflictingBridgeVote(conflicting, valSet, bridgeVerifier{"0x02": true}))
+
+	// a release is unbacked if one of its deposits is missing
+	unbacked := types.NewUnbackedReleaseEvidence(types.MockBridgeVote(pv, batch), batch, 10, defaultEvidenceTime, valSet)
+	require.NoError(t, unbacked.ValidateBasic())
+	assert.Error(t, VerifyUnbackedRelease(unbacked, valSet, nil))
+	assert.Error(t, VerifyUnbackedRelease(unbacked, valSet, bridgeVerifier{"0x01": true, "0x02": true}))
+	assert.NoError(t, VerifyUnbackedRelease(unbacked, valSet, bridgeVerifier{"0x01": true}))
+
+	// the pool verifies bridge evidence with its bridge verifier
+	state := cstate.LatestBlockState{
+		ChainID:         "mychain",
+		InitialHeight:   1,
+		LastBlockTime:   defaultEvidenceTime.Add(1 * time.Minute),
+		LastBlockHeight: 11,
+		ConsensusParams: *types.DefaultConsensusParams(),
+	}
+	stateStore := &smocks.Store{}
+	stateStore.On("LoadValidators", uint64(10)).Return(valSet, nil)
+	stateStore.On("Load").Return(state, nil)
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("LoadBlockMeta", uint64(10)).Return(&types.BlockMeta{Header: &types.Header{Time: defaultEvidenceTime}})
+
+	pool, err := NewPool(stateStore, memorydb.New(), blockStore)
+	require.NoError(t, err)
+	assert.Error(t, pool.CheckEvidence(types.EvidenceList{unbacked}))
+	pool.SetBridgeVerifier(bridgeVerifier{"0x02": true})
+	assert.NoError(t, pool.CheckEvidence(types.EvidenceList{unbacked}))
+}
@@ -161,6 +161,69 @@ func TestEvidenceProto(t *testing.T) {
 	}
 }
 
+func makeLightClientAttack(t *testing.T, chainID string, height uint64, round uint32,
+	valSet *ValidatorSet, privVals []PrivValidator) *LightClientAttackEvidence {
+	header := makeHeaderRandom()
+	header.Height = height
+	header.ValidatorsHash = valSet.Hash()
+	blockID := BlockID{Hash: header.Hash(), PartsHeader: PartSetHeader{Total: 1, Hash: common.BytesToHash(crypto.CRandBytes(merkle.Size))}}
+	voteSet := NewVoteSet(chainID, height, round, kproto.PrecommitType, valSet)
+	commit, err := MakeCommit(blockID, height, round, voteSet, privVals, defaultVoteTime)
+	require.NoError(t, err)
+	return &LightClientAttackEvidence{
+		ConflictingHeader:     header,
+		ConflictingCommit:     commit,
+		ConflictingValidators: valSet,
+		CommonHeight:          height - 1,
+		TotalVotingPower:      valSet.TotalVotingPower(),
+		Timestamp:             defaultVoteTime,
+	}
+}
+
+func TestLightClientAttackEvidence(t *testing.T) {
+	const chainID = "mychain"
+	valSet, privVals := RandValidatorSet(4, 10)
+	ev := makeLightClientAttack(t, chainID, 10, 1, valSet, privVals)
+	require.NoError(t, ev.ValidateBasic())
+	assert.Equal(t, uint64(9), ev.Height())
+
+	pb, err := EvidenceToProto(ev)
+	require.NoError(t, err)
+	decoded, err := EvidenceFromProto(pb)
+	require.NoError(t, err)
+	assert.Equal(t, ev.Hash(), decoded.Hash())
+
+	// A lunatic header: all the validators who signed it are byzantine.
+	trustedHeader := *ev.ConflictingHeader
+	trustedHeader.AppHash = common.BytesToHash(crypto.CRandBytes(merkle.Size))
+	assert.True(t, ev.ConflictingHeaderIsInvalid(&trustedHeader))
+	assert.Len(t, ev.GetByzantineValidators(valSet, &trustedHeader, nil), 4)
+
+	// An equivocation: the validators who signed both headers are byzantine.
+	trustedHeader = *ev.ConflictingHeader
+	trustedHeader.EvidenceHash = common.BytesToHash(crypto.CRandBytes(merkle.Size))
+	assert.False(t, ev.ConflictingHeaderIsInvalid(&trustedHeader))
+	trustedBlockID := BlockID{Hash: trustedHeader.Hash(), PartsHeader: ev.ConflictingCommit.BlockID.PartsHeader}
+	voteSet := NewVoteSet(chainID, 10, 1, kproto.PrecommitType, valSet)
+	_, err = MakeCommit(trustedBlockID, 10, 1, voteSet, privVals[:3], defaultVoteTime)
+	require.NoError(t, err)
+	trustedCommit := voteSet.MakeCommit()
+	byzVals := ev.GetByzantineValidators(valSet, &trustedHeader, trustedCommit)
+	require.Len(t, byzVals, 3)
+	for _, val := range byzVals {
+		assert.NotEqual(t, privVals[3].GetAddress(), val.Address)
+	}
+
+	// An amnesia attack: the byzantine validators can't be told apart.
+	trustedCommit.Round = 2
+	assert.Empty(t, ev.GetByzantineValidators(valSet, &trustedHeader, trustedCommit))
+
+	// The conflicting validators must be the ones of the conflicting header.
+	otherVals, _ := RandValidatorSet(4, 10)
+	ev.ConflictingValidators = otherVals
+	assert.Error(t, ev.ValidateBasic())
+}
+
 func makeVote(t *testing.T, val PrivValidator, chainID string, valIndex uint32, height uint64, round uint32, step int, blockID BlockID, time time.Time) *Vote {
 	address := val.GetAddress()
 	v := &Vote{
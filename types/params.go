@@ -19,6 +19,8 @@
 package types
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
@@ -41,6 +43,7 @@ func DefaultConsensusParams() *kproto.ConsensusParams {
 		Block:     DefaultBlockParams(),
 		Evidence:  DefaultEvidenceParams(),
 		Validator: DefaultValidatorParams(),
+		Timeout:   DefaultTimeoutParams(),
 	}
 }
 
@@ -67,3 +70,144 @@ func DefaultEvidenceParams() kproto.EvidenceParams {
 func DefaultValidatorParams() kproto.ValidatorParams {
 	return kproto.ValidatorParams{}
 }
+
+// DefaultTimeoutParams returns a default TimeoutParams, matching the default
+// consensus config.
+func DefaultTimeoutParams() kproto.TimeoutParams {
+	return kproto.TimeoutParams{
+		Propose:        3000 * time.Millisecond,
+		ProposeDelta:   500 * time.Millisecond,
+		Prevote:        1000 * time.Millisecond,
+		PrevoteDelta:   500 * time.Millisecond,
+		Precommit:      1000 * time.Millisecond,
+		PrecommitDelta: 500 * time.Millisecond,
+		Commit:         1000 * time.Millisecond,
+	}
+}
+
+// Keys of the consensus params which can be changed by governance.
+const (
+	ParamBlockMaxBytes           = "block.max_bytes"
+	ParamBlockMaxGas             = "block.max_gas"
+	ParamBlockTimeIotaMs         = "block.time_iota_ms"
+	ParamEvidenceMaxAgeNumBlocks = "evidence.max_age_num_blocks"
+	ParamEvidenceMaxAgeDuration  = "evidence.max_age_duration"
+	ParamEvidenceMaxBytes        = "evidence.max_bytes"
+	ParamValidatorAggregate      = "validator.aggregate_precommits"
+	ParamTimeoutPropose          = "timeout.propose"
+	ParamTimeoutProposeDelta     = "timeout.propose_delta"
+	ParamTimeoutPrevote          = "timeout.prevote"
+	ParamTimeoutPrevoteDelta     = "timeout.prevote_delta"
+	ParamTimeoutPrecommit        = "timeout.precommit"
+	ParamTimeoutPrecommitDelta   = "timeout.precommit_delta"
+	ParamTimeoutCommit           = "timeout.commit"
+)
+
+// ConsensusParamChange sets the consensus param Key to Value. Durations are
+// written in time.ParseDuration format, flags as booleans and other values as
+// base 10 integers.
+type ConsensusParamChange struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// UpdateConsensusParams returns a copy of params with changes applied in
+// order. The result is validated with ValidateConsensusParams.
+func UpdateConsensusParams(params kproto.ConsensusParams, changes []ConsensusParamChange) (kproto.ConsensusParams, error) {
+	res := params
+	for _, c := range changes {
+		var err error
+		switch c.Key {
+		case ParamBlockMaxBytes:
+			res.Block.MaxBytes, err = strconv.ParseInt(c.Value, 10, 64)
+		case ParamBlockMaxGas:
+			res.Block.MaxGas, err = strconv.ParseUint(c.Value, 10, 64)
+		case ParamBlockTimeIotaMs:
+			res.Block.TimeIotaMs, err = strconv.ParseInt(c.Value, 10, 64)
+		case ParamEvidenceMaxAgeNumBlocks:
+			res.Evidence.MaxAgeNumBlocks, err = strconv.ParseInt(c.Value, 10, 64)
+		case ParamEvidenceMaxAgeDuration:
+			res.Evidence.MaxAgeDuration, err = time.ParseDuration(c.Value)
+		case ParamEvidenceMaxBytes:
+			res.Evidence.MaxBytes, err = strconv.ParseInt(c.Value, 10, 64)
+		case ParamValidatorAggregate:
+			res.Validator.AggregatePrecommits, err = strconv.ParseBool(c.Value)
+		case ParamTimeoutPropose:
+			res.Timeout.Propose, err = time.ParseDuration(c.Value)
+		case ParamTimeoutProposeDelta:
+			res.Timeout.ProposeDelta, err = time.ParseDuration(c.Value)
+		case ParamTimeoutPrevote:
+			res.Timeout.Prevote, err = time.ParseDuration(c.Value)
+		case ParamTimeoutPrevoteDelta:
+			res.Timeout.PrevoteDelta, err = time.ParseDuration(c.Value)
+		case ParamTimeoutPrecommit:
+			res.Timeout.Precommit, err = time.ParseDuration(c.Value)
+		case ParamTimeoutPrecommitDelta:
+			res.Timeout.PrecommitDelta, err = time.ParseDuration(c.Value)
+		case ParamTimeoutCommit:
+			res.Timeout.Commit, err = time.ParseDuration(c.Value)
+		default:
+			return params, fmt.Errorf("unknown consensus param %q", c.Key)
+		}
+		if err != nil {
+			return params, fmt.Errorf("invalid value %q for consensus param %s: %w", c.Value, c.Key, err)
+		}
+	}
+	if err := ValidateConsensusParams(res); err != nil {
+		return params, err
+	}
+	return res, nil
+}
+
+// ValidateConsensusParams validates the consensus params.
+func ValidateConsensusParams(params kproto.ConsensusParams) error {
+	if params.Block.MaxBytes <= 0 {
+		return fmt.Errorf("block.MaxBytes must be greater than 0. Got %d", params.Block.MaxBytes)
+	}
+	if params.Block.MaxBytes > MaxBlockSizeBytes {
+		return fmt.Errorf("block.MaxBytes is too big. %d > %d", params.Block.MaxBytes, MaxBlockSizeBytes)
+	}
+	if params.Block.TimeIotaMs <= 0 {
+		return fmt.Errorf("block.TimeIotaMs must be greater than 0. Got %v", params.Block.TimeIotaMs)
+	}
+	if params.Evidence.MaxAgeNumBlocks <= 0 {
+		return fmt.Errorf("evidence.MaxAgeNumBlocks must be greater than 0. Got %d", params.Evidence.MaxAgeNumBlocks)
+	}
+	if params.Evidence.MaxAgeDuration <= 0 {
+		return fmt.Errorf("evidence.MaxAgeDuration must be greater than 0. Got %v", params.Evidence.MaxAgeDuration)
+	}
+	if params.Evidence.MaxBytes > params.Block.MaxBytes {
+		return fmt.Errorf("evidence.MaxBytes is greater than upper bound, %d > %d",
+			params.Evidence.MaxBytes, params.Block.MaxBytes)
+	}
+	if params.Evidence.MaxBytes < 0 {
+		return fmt.Errorf("evidence.MaxBytes must be non negative. Got: %d", params.Evidence.MaxBytes)
+	}
+	return validateTimeoutParams(params.Timeout)
+}
+
+// validateTimeoutParams validates the timeout params. A zero timeout is unset
+// and falls back to the node's consensus config, so its delta must be unset too.
+func validateTimeoutParams(params kproto.TimeoutParams) error {
+	timeouts := []struct {
+		name        string
+		base, delta time.Duration
+	}{
+		{"Propose", params.Propose, params.ProposeDelta},
+		{"Prevote", params.Prevote, params.PrevoteDelta},
+		{"Precommit", params.Precommit, params.PrecommitDelta},
+		{"Commit", params.Commit, 0},
+	}
+	for _, t := range timeouts {
+		if t.base < 0 {
+			return fmt.Errorf("timeout.%s must be non negative. Got %v", t.name, t.base)
+		}
+		if t.delta < 0 {
+			return fmt.Errorf("timeout.%sDelta must be non negative. Got %v", t.name, t.delta)
+		}
+		if t.base == 0 && t.delta > 0 {
+			return fmt.Errorf("timeout.%sDelta is set but timeout.%s is not", t.name, t.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
+)
+
+func TestUpdateConsensusParams(t *testing.T) {
+	params := *DefaultConsensusParams()
+	updated, err := UpdateConsensusParams(params, []ConsensusParamChange{
+		{Key: ParamBlockMaxGas, Value: "30000000"},
+		{Key: ParamEvidenceMaxAgeDuration, Value: "24h"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(30000000), updated.Block.MaxGas)
+	assert.Equal(t, "24h0m0s", updated.Evidence.MaxAgeDuration.String())
+	assert.Equal(t, params.Block.MaxBytes, updated.Block.MaxBytes)
+
+	_, err = UpdateConsensusParams(params, []ConsensusParamChange{{Key: "block.unknown", Value: "1"}})
+	assert.Error(t, err)
+	_, err = UpdateConsensusParams(params, []ConsensusParamChange{{Key: ParamBlockMaxBytes, Value: "abc"}})
+	assert.Error(t, err)
+}
+
+func TestUpdateTimeoutParams(t *testing.T) {
+	params := *DefaultConsensusParams()
+	updated, err := UpdateConsensusParams(params, []ConsensusParamChange{
+		{Key: ParamTimeoutPropose, Value: "5s"},
+		{Key: ParamTimeoutProposeDelta, Value: "1s"},
+		{Key: ParamTimeoutCommit, Value: "2s"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, updated.Timeout.Propose)
+	assert.Equal(t, time.Second, updated.Timeout.ProposeDelta)
+	assert.Equal(t, 2*time.Second, updated.Timeout.Commit)
+	assert.Equal(t, params.Timeout.Prevote, updated.Timeout.Prevote)
+
+	// Unset timeouts are valid, they fall back to the consensus config
+	params.Timeout = kproto.TimeoutParams{}
+	require.NoError(t, ValidateConsensusParams(params))
+
+	_, err = UpdateConsensusParams(params, []ConsensusParamChange{{Key: ParamTimeoutPrevoteDelta, Value: "1s"}})
+	assert.Error(t, err)
+	_, err = UpdateConsensusParams(params, []ConsensusParamChange{{Key: ParamTimeoutPrecommit, Value: "-1s"}})
+	assert.Error(t, err)
+}
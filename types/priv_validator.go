@@ -25,6 +25,7 @@ import (
 
 	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/crypto/bls"
 	"github.com/kardiachain/go-kardia/lib/log"
 	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
 )
@@ -112,6 +113,48 @@ func (privVal DefaultPrivValidator) ExtractIntoValidator(votingPower int64) *Val
 	}
 }
 
+//----------------------------------------
+// BLSPrivValidator
+
+// BLSPrivValidator wraps a PrivValidator with a BLS key, additionally signing
+// its precommits so they can be aggregated into the commits.
+type BLSPrivValidator struct {
+	PrivValidator
+	blsKey *bls.SecretKey
+}
+
+// NewBLSPrivValidator ...
+func NewBLSPrivValidator(privVal PrivValidator, blsKey *bls.SecretKey) *BLSPrivValidator {
+	return &BLSPrivValidator{
+		PrivValidator: privVal,
+		blsKey:        blsKey,
+	}
+}
+
+// GetBLSPubKey returns the BLS public key of the validator.
+func (privVal *BLSPrivValidator) GetBLSPubKey() []byte {
+	return privVal.blsKey.PublicKey()
+}
+
+// SignVote signs the vote with the wrapped PrivValidator, and with the BLS
+// key if it is a precommit.
+func (privVal *BLSPrivValidator) SignVote(chainID string, vote *kproto.Vote) error {
+	if err := privVal.PrivValidator.SignVote(chainID, vote); err != nil {
+		return err
+	}
+	if vote.Type == kproto.PrecommitType {
+		vote.BlsSignature = privVal.blsKey.Sign(VoteSignBytes(chainID, vote))
+	}
+	return nil
+}
+
+// ExtractIntoValidator ...
+func (privVal *BLSPrivValidator) ExtractIntoValidator(votingPower int64) *Validator {
+	val := privVal.PrivValidator.ExtractIntoValidator(votingPower)
+	val.BLSPubKey = privVal.GetBLSPubKey()
+	return val
+}
+
 //func (privVal *PrivValidator) SignHeartbeat(chainID string, heartbeat *Heartbeat) error {
 //	panic("SignHeartbeat - not yet implemented")
 //}
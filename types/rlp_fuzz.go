@@ -0,0 +1,69 @@
+//go:build gofuzz
+// +build gofuzz
+
+/*
+ *  Copyright 2021 KardiaChain
+ *  This file is part of the go-kardia library.
+ *
+ *  The go-kardia library is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Lesser General Public License as published by
+ *  the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  The go-kardia library is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ *  GNU Lesser General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Lesser General Public License
+ *  along with the go-kardia library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/kardiachain/go-kardia/lib/rlp"
+)
+
+// rlpFuzzTargets are the RLP decoded block data types, selected by the first
+// byte of the fuzzer input. The seed corpus is in testdata/fuzz/rlp/corpus.
+var rlpFuzzTargets = []func() interface{}{
+	func() interface{} { return new(Header) },
+	func() interface{} { return new(Transaction) },
+	func() interface{} { return new(Receipt) },
+	func() interface{} { return new(ReceiptForStorage) },
+	func() interface{} { return new(BlockInfo) },
+	func() interface{} { return new(LogForStorage) },
+}
+
+// Fuzz implements a go-fuzz fuzzer method to test the RLP decoding of block
+// data. Decoded values must encode to a stable canonical form.
+func Fuzz(data []byte) int {
+	if len(data) == 0 {
+		return -1
+	}
+	newValue := rlpFuzzTargets[int(data[0])%len(rlpFuzzTargets)]
+	val := newValue()
+	if err := rlp.DecodeBytes(data[1:], val); err != nil {
+		return 0
+	}
+	enc, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return 0
+	}
+	val2 := newValue()
+	if err := rlp.DecodeBytes(enc, val2); err != nil {
+		panic(fmt.Sprintf("failed to decode re-encoded %T: %v", val, err))
+	}
+	enc2, err := rlp.EncodeToBytes(val2)
+	if err != nil {
+		panic(fmt.Sprintf("failed to encode decoded %T: %v", val2, err))
+	}
+	if !bytes.Equal(enc, enc2) {
+		panic(fmt.Sprintf("unstable encoding of %T: %x != %x", val, enc, enc2))
+	}
+	return 1
+}
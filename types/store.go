@@ -34,6 +34,7 @@ type StoreDB interface {
 	WriteCanonicalHash(hash common.Hash, height uint64)
 	WriteEvent(smartcontract *KardiaSmartcontract)
 	WriteTxLookupEntries(block *Block)
+	WriteAddressTxIndex(block *Block, signer Signer)
 	WriteHeadBlockHash(common.Hash)
 	WriteAppHash(uint64, common.Hash)
 
@@ -55,6 +56,7 @@ type StoreDB interface {
 	ReadDualEventLookupEntry(hash common.Hash) (common.Hash, uint64, uint64)
 	ReadBlockInfo(hash common.Hash, number uint64, config *configs.ChainConfig) *BlockInfo
 	ReadTxLookupEntry(hash common.Hash) (common.Hash, uint64, uint64)
+	ReadAddressTxHashes(addr common.Address, offset, limit int) []common.Hash
 	ReadSmartContractAbi(address string) *abi.ABI
 	ReadEvent(address string, method string) *Watcher
 	ReadEvents(address string) (string, []*Watcher)
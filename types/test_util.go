@@ -38,5 +38,6 @@ func signAddVote(privVal PrivValidator, vote *Vote, voteSet *VoteSet) (signed bo
 		return false, err
 	}
 	vote.Signature = v.Signature
+	vote.BLSSignature = v.BlsSignature
 	return voteSet.AddVote(vote)
 }
@@ -30,6 +30,7 @@ import (
 
 	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/crypto/bls"
 	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
 )
 
@@ -46,6 +47,9 @@ type Validator struct {
 	CommissionRate   *big.Int       `json:"commissionRate,omitempty"`
 	MaxRate          *big.Int       `json:"maxRate,omitempty"`
 	MaxChangeRate    *big.Int       `json:"maxChangeRate,omitempty"`
+	// BLSPubKey is the optional BLS key of the validator, allowing its
+	// precommits to be aggregated.
+	BLSPubKey []byte `json:"blsPubKey,omitempty"`
 }
 
 // NewValidator ...
@@ -71,6 +75,12 @@ func (v *Validator) ValidateBasic() error {
 		return fmt.Errorf("wrong validator address: %v", v.Address)
 	}
 
+	if len(v.BLSPubKey) != 0 {
+		if err := bls.ValidatePublicKey(v.BLSPubKey); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -80,6 +90,7 @@ func (v *Validator) Bytes() []byte {
 	pbv := kproto.SimpleValidator{
 		Address:     v.Address.Bytes(),
 		VotingPower: v.VotingPower,
+		BlsPubKey:   v.BLSPubKey,
 	}
 
 	bz, err := pbv.Marshal()
@@ -99,6 +110,7 @@ func (v *Validator) Copy() *Validator {
 		Address:          v.Address,
 		VotingPower:      v.VotingPower,
 		ProposerPriority: v.ProposerPriority,
+		BLSPubKey:        v.BLSPubKey,
 	}
 	return vCopy
 }
@@ -161,6 +173,7 @@ func ValidatorFromProto(vp *kproto.Validator) (*Validator, error) {
 	v.Address = common.BytesToAddress(vp.GetAddress())
 	v.VotingPower = vp.GetVotingPower()
 	v.ProposerPriority = vp.GetProposerPriority()
+	v.BLSPubKey = vp.GetBlsPubKey()
 
 	return v, nil
 }
@@ -212,6 +225,7 @@ func (v *Validator) ToProto() (*kproto.Validator, error) {
 		Address:          v.Address.Bytes(),
 		VotingPower:      v.VotingPower,
 		ProposerPriority: v.ProposerPriority,
+		BlsPubKey:        v.BLSPubKey,
 	}
 
 	return &vp, nil
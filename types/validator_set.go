@@ -30,6 +30,7 @@ import (
 
 	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/crypto/bls"
 	"github.com/kardiachain/go-kardia/lib/merkle"
 	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
 )
@@ -508,7 +509,11 @@ func (vs *ValidatorSet) applyUpdates(updates []*Validator) {
 			// Apply add or update.
 			merged[i] = updates[0]
 			if existing[0].Address.Equal(updates[0].Address) {
-				// Validator is present in both, advance existing.
+				// Validator is present in both, keep its BLS key unless the
+				// update changes it, and advance existing.
+				if len(updates[0].BLSPubKey) == 0 {
+					updates[0].BLSPubKey = existing[0].BLSPubKey
+				}
 				existing = existing[1:]
 			}
 			updates = updates[1:]
@@ -671,6 +676,11 @@ func (vs *ValidatorSet) VerifyCommit(chainID string, blockID BlockID, height uin
 			blockID, commit.BlockID)
 	}
 
+	var (
+		aggregated = commit.IsAggregated()
+		pubKeys    [][]byte
+		msgs       [][]byte
+	)
 	talliedVotingPower := int64(0)
 	votingPowerNeeded := vs.TotalVotingPower() * 2 / 3
 	for idx, commitSig := range commit.Signatures {
@@ -681,9 +691,15 @@ func (vs *ValidatorSet) VerifyCommit(chainID string, blockID BlockID, height uin
 		// This means we don't need the validator address or to do any lookup.
 		val := vs.Validators[idx]
 
-		// Validate signature.
+		// Validate signature, or collect it for the aggregated one.
 		signBytes := commit.VoteSignBytes(chainID, uint32(idx))
-		if !VerifySignature(val.Address, crypto.Keccak256(signBytes), commitSig.Signature) {
+		if aggregated {
+			if len(val.BLSPubKey) == 0 {
+				return errors.Errorf("validator #%d has no BLS key in an aggregated commit", idx)
+			}
+			pubKeys = append(pubKeys, val.BLSPubKey)
+			msgs = append(msgs, signBytes)
+		} else if !VerifySignature(val.Address, crypto.Keccak256(signBytes), commitSig.Signature) {
 			return errors.Errorf("wrong signature (#%d): %X", idx, commitSig.Signature)
 		}
 		// Good precommit!
@@ -692,12 +708,63 @@ func (vs *ValidatorSet) VerifyCommit(chainID string, blockID BlockID, height uin
 		}
 	}
 
+	if aggregated && !bls.VerifyAggregate(pubKeys, msgs, commit.AggregatedSignature) {
+		return errors.Errorf("wrong aggregated signature: %X", commit.AggregatedSignature)
+	}
+
 	if got, needed := talliedVotingPower, votingPowerNeeded; got <= needed {
 		return ErrNotEnoughVotingPowerSigned{Got: got, Needed: needed}
 	}
 	return nil
 }
 
+// VerifyCommitTrusting verifies that validators of the set holding more than
+// 1/3 of its voting power signed the commit of another validator set,
+// commitVals. The commit must already be verified against commitVals, as the
+// signatures are only attributed to the validators of the set by address.
+func (vs *ValidatorSet) VerifyCommitTrusting(commit *Commit, commitVals *ValidatorSet) error {
+	if vs == nil || commitVals == nil {
+		return ErrNilValidatorSet
+	}
+	if commit == nil {
+		return ErrNilCommit
+	}
+	if commitVals.Size() != len(commit.Signatures) {
+		return NewErrInvalidCommitSignatures(uint64(commitVals.Size()), uint64(len(commit.Signatures)))
+	}
+
+	var (
+		aggregated         = commit.IsAggregated()
+		seenVals           = make(map[int]int, len(commit.Signatures))
+		talliedVotingPower = int64(0)
+		votingPowerNeeded  = vs.TotalVotingPower() / 3
+	)
+	for idx, commitSig := range commit.Signatures {
+		if !commitSig.ForBlock() {
+			continue
+		}
+		commitVal := commitVals.Validators[idx]
+		valIdx, val := vs.GetByAddress(commitVal.Address)
+		if val == nil {
+			continue
+		}
+		if firstIdx, ok := seenVals[valIdx]; ok {
+			return errors.Errorf("double vote from %v (#%d and #%d)", val.Address.Hex(), firstIdx, idx)
+		}
+		seenVals[valIdx] = idx
+		// An aggregated signature was verified with the BLS keys of
+		// commitVals, which must be the ones of the validators of the set.
+		if aggregated && !bytes.Equal(val.BLSPubKey, commitVal.BLSPubKey) {
+			return errors.Errorf("validator %v signed with another BLS key", val.Address.Hex())
+		}
+		talliedVotingPower += val.VotingPower
+		if talliedVotingPower > votingPowerNeeded {
+			return nil
+		}
+	}
+	return ErrNotEnoughVotingPowerSigned{Got: talliedVotingPower, Needed: votingPowerNeeded}
+}
+
 // IsErrTooMuchChange returns too much change error
 func IsErrTooMuchChange(err error) bool {
 	_, ok := errors.Cause(err).(errTooMuchChange)
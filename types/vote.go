@@ -25,6 +25,7 @@ import (
 
 	"github.com/kardiachain/go-kardia/lib/common"
 	"github.com/kardiachain/go-kardia/lib/crypto"
+	"github.com/kardiachain/go-kardia/lib/crypto/bls"
 	"github.com/kardiachain/go-kardia/lib/protoio"
 	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
 )
@@ -62,6 +63,9 @@ type Vote struct {
 	Type             kproto.SignedMsgType `json:"type"`
 	BlockID          BlockID              `json:"block_id"` // zero if vote is nil.
 	Signature        []byte               `json:"signature"`
+	// BLSSignature is the optional BLS signature of a precommit, aggregated
+	// into the commit.
+	BLSSignature []byte `json:"bls_signature,omitempty"`
 }
 
 // CreateEmptyVote ...
@@ -158,6 +162,22 @@ func (vote *Vote) Verify(chainID string, address common.Address) error {
 	return nil
 }
 
+// VerifyBLS verifies the BLS signature of the vote, if any, against the BLS
+// key of the validator.
+func (vote *Vote) VerifyBLS(chainID string, pubKey []byte) error {
+	if len(vote.BLSSignature) == 0 {
+		return nil
+	}
+	if len(pubKey) == 0 {
+		return ErrVoteInvalidBLSSignature
+	}
+	signBytes := VoteSignBytes(chainID, vote.ToProto())
+	if !bls.Verify(pubKey, signBytes, vote.BLSSignature) {
+		return ErrVoteInvalidBLSSignature
+	}
+	return nil
+}
+
 // ValidateBasic performs basic validation.
 func (vote *Vote) ValidateBasic() error {
 	if !IsVoteTypeValid(vote.Type) {
@@ -178,6 +198,14 @@ func (vote *Vote) ValidateBasic() error {
 	if len(vote.Signature) == 0 {
 		return errors.New("signature is missing")
 	}
+	if len(vote.BLSSignature) != 0 {
+		if vote.Type != kproto.PrecommitType {
+			return errors.New("BLS signature is only allowed in precommits")
+		}
+		if len(vote.BLSSignature) != bls.SignatureLength {
+			return fmt.Errorf("wrong BLS signature length: %d", len(vote.BLSSignature))
+		}
+	}
 	return nil
 }
 
@@ -197,6 +225,7 @@ func (vote *Vote) ToProto() *kproto.Vote {
 		ValidatorAddress: vote.ValidatorAddress.Bytes(),
 		ValidatorIndex:   vote.ValidatorIndex,
 		Signature:        vote.Signature,
+		BlsSignature:     vote.BLSSignature,
 	}
 }
 
@@ -221,6 +250,7 @@ func VoteFromProto(pv *kproto.Vote) (*Vote, error) {
 	vote.ValidatorAddress = common.BytesToAddress(pv.ValidatorAddress)
 	vote.ValidatorIndex = pv.ValidatorIndex
 	vote.Signature = pv.Signature
+	vote.BLSSignature = pv.BlsSignature
 
 	return vote, vote.ValidateBasic()
 }
@@ -24,6 +24,7 @@ import (
 	"sync"
 
 	cmn "github.com/kardiachain/go-kardia/lib/common"
+	"github.com/kardiachain/go-kardia/lib/crypto/bls"
 	"github.com/kardiachain/go-kardia/lib/p2p"
 	kproto "github.com/kardiachain/go-kardia/proto/kardiachain/types"
 	"github.com/pkg/errors"
@@ -169,6 +170,9 @@ func (voteSet *VoteSet) addVote(vote *Vote) (bool, error) {
 	if err := vote.Verify(voteSet.chainID, val.Address); err != nil {
 		return false, errors.Wrapf(err, "Failed to verify vote with ChainID %s and PubKey %s", voteSet.chainID, val.Address)
 	}
+	if err := vote.VerifyBLS(voteSet.chainID, val.BLSPubKey); err != nil {
+		return false, errors.Wrapf(err, "Failed to verify BLS signature of vote from %s", val.Address)
+	}
 
 	// Add vote and get conflicting vote if any
 	added, conflicting := voteSet.addVerifiedVote(vote, blockKey, val.VotingPower)
@@ -458,7 +462,41 @@ func (voteSet *VoteSet) MakeCommit() *Commit {
 	}
 	voteSet.mtx.Lock()
 	defer voteSet.mtx.Unlock()
+	return voteSet.makeCommit()
+}
+
+// MakeAggregatedCommit constructs a Commit from the VoteSet whose precommit
+// signatures are replaced by the aggregate of their BLS signatures. It returns
+// nil if a precommit in the commit has no BLS signature.
+func (voteSet *VoteSet) MakeAggregatedCommit() *Commit {
+	if voteSet.signedMsgType != kproto.PrecommitType {
+		cmn.PanicSanity("Cannot MakeAggregatedCommit() unless VoteSet.Type is VoteTypePrecommit")
+	}
+	voteSet.mtx.Lock()
+	defer voteSet.mtx.Unlock()
+
+	commit := voteSet.makeCommit()
+	sigs := make([][]byte, 0, len(commit.Signatures))
+	for i := range commit.Signatures {
+		commitSig := &commit.Signatures[i]
+		if commitSig.Absent() {
+			continue
+		}
+		if len(voteSet.votes[i].BLSSignature) == 0 {
+			return nil
+		}
+		sigs = append(sigs, voteSet.votes[i].BLSSignature)
+		commitSig.Signature = nil
+	}
+	aggregated, err := bls.Aggregate(sigs)
+	if err != nil {
+		return nil
+	}
+	commit.AggregatedSignature = aggregated
+	return commit
+}
 
+func (voteSet *VoteSet) makeCommit() *Commit {
 	// Make sure we have a 2/3 majority
 	if voteSet.maj23 == nil {
 		cmn.PanicSanity("Cannot MakeCommit() unless a blockhash has +2/3")